@@ -7,7 +7,9 @@ package main
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
@@ -26,11 +28,15 @@ import (
 	"extend-challenge-service/pkg/cache"
 	"extend-challenge-service/pkg/client"
 	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/common/version"
 	"extend-challenge-service/pkg/handler"
 	"extend-challenge-service/pkg/mapper"
 	"extend-challenge-service/pkg/migrations"
 	pb "extend-challenge-service/pkg/pb"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/selftest"
 	"extend-challenge-service/pkg/server"
+	"extend-challenge-service/pkg/service"
 
 	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
 	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
@@ -43,6 +49,7 @@ import (
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/platform"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
@@ -50,7 +57,6 @@ import (
 	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -66,6 +72,9 @@ const (
 	metricsPort         = 8080
 	grpcServerPort      = 6565
 	grpcGatewayHTTPPort = 8000
+
+	// How often the cached IAM token status used by /healthz is re-evaluated
+	tokenCheckInterval = 30 * time.Second
 )
 
 var (
@@ -75,7 +84,36 @@ var (
 )
 
 func main() {
-	logrus.Infof("Starting %s...", serviceName)
+	startedAt := time.Now().UTC()
+	selfTest := flag.Bool("self-test", false, "run the startup checks, print a JSON report and exit without serving")
+	goalIDCollisions := flag.Bool("goal-id-collisions", false,
+		"print the goal IDs the challenge config reuses across challenges as JSON and exit, non-zero if there are any")
+	encodeUserIDs := flag.Bool("encode-user-ids", false,
+		"convert the user IDs stored in user_goal_progress to the USER_ID_CODEC key, print the counts as JSON and exit")
+	optimizedHandlersFlag := flag.String("optimized-handlers", common.GetEnv("OPTIMIZED_HANDLERS", handler.OptimizedHandlersAll),
+		"optimized HTTP handlers to register: off, challenges, initialize or all; the other routes are served by the gRPC-Gateway")
+	flag.Parse()
+	if *selfTest {
+		os.Exit(runSelfTest(context.Background()))
+	}
+	if *goalIDCollisions {
+		os.Exit(runGoalIDCollisionReport())
+	}
+	if *encodeUserIDs {
+		os.Exit(runUserIDBackfill(context.Background()))
+	}
+
+	optimizedHandlers, err := handler.ParseOptimizedHandlers(*optimizedHandlersFlag)
+	if err != nil {
+		logrus.Fatalf("Invalid OPTIMIZED_HANDLERS: %v", err)
+	}
+	strictRequestBodies, err := handler.ParseRequestBodyDecoding(common.GetEnv("REQUEST_BODY_DECODING",
+		handler.DefaultRequestBodyDecoding(common.GetEnv("REWARD_CLIENT_MODE", "real"))))
+	if err != nil {
+		logrus.Fatalf("Invalid REQUEST_BODY_DECODING: %v", err)
+	}
+
+	logrus.Infof("Starting %s %s...", serviceName, version.String())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -87,26 +125,54 @@ func main() {
 	logrusLogger := logrus.New()
 	logrusLogger.SetLevel(logrusLevel)
 
+	// Payloads are only logged with LOG_PAYLOADS=true, cut to LOG_PAYLOAD_MAX_BYTES; credentials are redacted
+	payloadLogger := common.NewPayloadLogger(common.InterceptorLogger(logrusLogger), common.NewPayloadLogConfigFromEnv())
+
+	// Client IPs are read from X-Forwarded-For only when the peer is a trusted proxy (TRUSTED_PROXY_CIDRS)
+	trustedProxies := common.NewTrustedProxiesFromEnv()
+
 	loggingOptions := []logging.Option{
-		logging.WithLogOnEvents(logging.StartCall, logging.FinishCall, logging.PayloadReceived, logging.PayloadSent),
+		logging.WithLogOnEvents(payloadLogger.LoggableEvents()...),
 		logging.WithFieldsFromContext(func(ctx context.Context) logging.Fields {
-			if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
-				return logging.Fields{"traceID", span.TraceID().String()}
+			fields := logging.Fields{"client_ip", trustedProxies.GRPCClientIP(ctx)}
+			if traceID, ok := common.SampledTraceID(ctx); ok {
+				fields = append(fields, "traceID", traceID)
 			}
 
-			return nil
+			return fields
 		}),
 		logging.WithLevels(logging.DefaultClientCodeToLevel),
 		logging.WithDurationField(logging.DurationToDurationField),
 	}
 
+	// Server-side deadlines for calls that arrive without one (RPC_DEFAULT_TIMEOUT, RPC_METHOD_TIMEOUTS, RPC_MAX_DEADLINE)
+	deadlineConfig := common.NewDeadlineConfigFromEnv()
+
+	// Max-in-flight budgets for reads and writes; excess requests are shed before auth and DB work
+	// (MAX_IN_FLIGHT_READS, MAX_IN_FLIGHT_WRITES, LOAD_SHED_RETRY_AFTER)
+	loadShedder := common.NewLoadShedder(common.NewLoadShedConfigFromEnv())
+	eventSignatures := common.NewEventSignatureVerifierFromEnv()
+	if eventSignatures == nil {
+		logrus.Warn("EVENT_SIGNING_SECRET is not set; /v1/events/ requests are not signature-checked")
+	}
+
+	// requests_total{method, class}: per-method outcomes split into user and server errors for SLO burn alerts;
+	// request_duration_seconds{method}: latency, with trace_id exemplars of sampled traces
+	requestMetrics := common.NewRequestMetrics()
+
 	unaryServerInterceptors := []grpc.UnaryServerInterceptor{
 		prometheusGrpc.UnaryServerInterceptor,
-		logging.UnaryServerInterceptor(common.InterceptorLogger(logrusLogger), loggingOptions...),
+		requestMetrics.UnaryServerInterceptor(),
+		loadShedder.UnaryServerInterceptor(),
+		trustedProxies.UnaryServerInterceptor(),
+		logging.UnaryServerInterceptor(payloadLogger, loggingOptions...),
+		common.NewUnaryDeadlineServerIntercept(deadlineConfig),
 	}
 	streamServerInterceptors := []grpc.StreamServerInterceptor{
 		prometheusGrpc.StreamServerInterceptor,
-		logging.StreamServerInterceptor(common.InterceptorLogger(logrusLogger), loggingOptions...),
+		requestMetrics.StreamServerInterceptor(),
+		loadShedder.StreamServerInterceptor(),
+		logging.StreamServerInterceptor(payloadLogger, loggingOptions...),
 	}
 
 	// Preparing the IAM authorization
@@ -122,11 +188,38 @@ func main() {
 	}
 
 	// Always register auth interceptor (it handles both enabled and disabled auth modes)
+	// HealthCheck, the gRPC health service and reflection skip auth (plus AUTH_ALLOW_METHODS)
 	permissionExtractor := common.NewProtoPermissionExtractor()
-	unaryServerInterceptor := common.NewUnaryAuthServerIntercept(permissionExtractor)
-	serverServerInterceptor := common.NewStreamAuthServerIntercept(permissionExtractor)
-
-	unaryServerInterceptors = append(unaryServerInterceptors, unaryServerInterceptor)
+	authAllowList := common.NewAuthAllowListFromEnv()
+	unaryServerInterceptor := common.NewUnaryAuthServerIntercept(permissionExtractor, authAllowList)
+	serverServerInterceptor := common.NewStreamAuthServerIntercept(permissionExtractor, authAllowList)
+
+	// Player segments select per-goal targetOverrides (SEGMENT_JWT_CLAIM or SEGMENT_HEADER, none when unset);
+	// resolved after auth
+	segmentResolver := common.NewSegmentResolverFromEnv()
+
+	// X-Debug-Timing: 1 gets a Server-Timing phase breakdown for namespaces in DEBUG_TIMING_NAMESPACES
+	// and admin tokens; timed before auth, so the first phase includes it
+	debugTiming := common.NewDebugTimingFromEnv()
+	if debugTiming.Namespaces() > 0 {
+		logrus.Infof("Debug timing enabled for %d namespaces", debugTiming.Namespaces())
+	}
+	// With EVENT_SIGNING_SECRET set, ReportMatchResult is only accepted from the gateway after its HTTP
+	// request was signature-checked; native gRPC and gRPC-Web calls cannot be signed and are rejected
+	unaryServerInterceptors = append(unaryServerInterceptors,
+		debugTiming.UnaryServerInterceptor(), eventSignatures.UnaryServerInterceptor(pb.Service_ReportMatchResult_FullMethodName),
+		unaryServerInterceptor, common.SegmentUnaryServerInterceptor(segmentResolver))
+
+	// challenge_config_info{version, path}, goal/challenge counts and cache size; set once the config is loaded
+	configInfo := service.NewConfigInfo(logrusLogger)
+
+	// DEBUG_RESPONSE_METADATA=true sends the resolved user, namespace, config version and handler back as
+	// x-debug-* trailers (unary gRPC) and X-Debug-* headers (optimized handlers); never enable it in production
+	debugMetadata := common.NewDebugMetadataFromEnv(configInfo.Version)
+	if debugMetadata.Enabled() {
+		logrus.Warn("DEBUG_RESPONSE_METADATA is enabled: responses carry the resolved user ID and namespace")
+	}
+	unaryServerInterceptors = append(unaryServerInterceptors, debugMetadata.UnaryServerInterceptor())
 	streamServerInterceptors = append(streamServerInterceptors, serverServerInterceptor)
 
 	if strings.ToLower(common.GetEnv("PLUGIN_GRPC_SERVER_AUTH_ENABLED", "true")) == "true" {
@@ -144,6 +237,7 @@ func main() {
 	// Create gRPC Server
 	s := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.InTapHandle(loadShedder.TapHandle),
 		grpc.ChainUnaryInterceptor(unaryServerInterceptors...),
 		grpc.ChainStreamInterceptor(streamServerInterceptors...),
 	)
@@ -191,17 +285,109 @@ func main() {
 	}
 	logrus.Infof("Database migrations completed successfully")
 
-	// Load challenge configuration from challenges.json
+	// Load challenge configuration from challenges.json. Each successful load is cached as
+	// last-known-good; with CONFIG_FALLBACK=true a corrupt file boots from that copy instead
 	configPath := common.GetEnv("CHALLENGE_CONFIG_PATH", "config/challenges.json")
-	slogLogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-	configLoader := commonConfig.NewConfigLoader(configPath, slogLogger)
-	challengeConfig, err := configLoader.LoadConfig()
+	slogLogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: common.SlogLevel(logrusLevel)}))
+	configFallback := service.NewConfigFallbackFromEnv(logrusLogger)
+	var (
+		challengeConfig  *commonConfig.Config
+		hiddenGoals      service.HiddenGoals
+		inactivePolicy   service.InactiveProgressPolicy
+		latePolicies     service.LateEventPolicies
+		challengePrereqs service.ChallengePrerequisites
+		rollouts         service.ChallengeRollouts
+		targetOverrides  service.TargetOverrides
+		matchGoals       service.MatchGoals
+		goalSteps        service.GoalSteps
+		rewardCaps       service.RewardCaps
+		rewardDeliveries *service.RewardDeliveries
+		autoDeactivation service.AutoDeactivation
+		repeatableGoals  service.RepeatableGoals
+		autoSelections   service.AutoRandomSelections
+		loadedPath       string
+	)
+	err = configFallback.Load(configPath, func(path string) error {
+		var err error
+		loadedPath = path
+		// Checked first: the common loader stops at the first error, without a location
+		if err = service.CheckConfigSchema(path); err != nil {
+			return fmt.Errorf("failed to load challenge config: %w", err)
+		}
+		// The common loader only names the first reused goal ID
+		if err = service.CheckGoalIDCollisions(path); err != nil {
+			return fmt.Errorf("failed to load challenge config: %w", err)
+		}
+		if challengeConfig, err = commonConfig.NewConfigLoader(path, slogLogger).LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load challenge config: %w", err)
+		}
+		// Goals marked "hidden" stay out of challenge listings until the user unlocks them
+		if hiddenGoals, err = service.LoadHiddenGoals(path); err != nil {
+			return fmt.Errorf("failed to load hidden goals from challenge config: %w", err)
+		}
+		// Challenges with "trackInactiveProgress": false only advance goals the user has activated
+		if inactivePolicy, err = service.LoadInactiveProgressPolicy(path); err != nil {
+			return fmt.Errorf("failed to load inactive progress policy from challenge config: %w", err)
+		}
+		// Challenges with "lateEventPolicy" drop or backdate events reported after a rotation boundary
+		if latePolicies, err = service.LoadLateEventPolicies(path); err != nil {
+			return fmt.Errorf("failed to load late event policies from challenge config: %w", err)
+		}
+		// Challenges with "prerequisiteChallengeIds" stay locked until those challenges are completed
+		if challengePrereqs, err = service.LoadChallengePrerequisites(path); err != nil {
+			return fmt.Errorf("failed to load challenge prerequisites from challenge config: %w", err)
+		}
+		// Challenges with "rolloutPercentage" are only available to that share of players
+		if rollouts, err = service.LoadChallengeRollouts(path); err != nil {
+			return fmt.Errorf("failed to load challenge rollouts from challenge config: %w", err)
+		}
+		// Lowering one would take the challenge away from players who had it with the last config
+		if previous, ok := configFallback.LastKnownGood(); ok && previous != path {
+			if err = service.CheckConfigRolloutsNotLowered(previous, path); err != nil {
+				return fmt.Errorf("failed to load challenge rollouts from challenge config: %w", err)
+			}
+		}
+		// Goals with "targetOverrides" have easier or harder targets for some player segments
+		if targetOverrides, err = service.LoadTargetOverrides(path); err != nil {
+			return fmt.Errorf("failed to load target overrides from challenge config: %w", err)
+		}
+		// Goals with a match stat code (and optional "matchFilter") count reported match results
+		if matchGoals, err = service.LoadMatchGoals(path); err != nil {
+			return fmt.Errorf("failed to load match goals from challenge config: %w", err)
+		}
+		// Goals with "requirements" advance each step on its own stat code
+		if goalSteps, err = service.LoadGoalSteps(path); err != nil {
+			return fmt.Errorf("failed to load goal steps from challenge config: %w", err)
+		}
+		// Goals with "rewardCap" only grant their reward to the first claims
+		if rewardCaps, err = service.LoadRewardCaps(path); err != nil {
+			return fmt.Errorf("failed to load reward caps from challenge config: %w", err)
+		}
+		// Goals with "delivery" grant their item into a collection; claims may pick one from "rewardDelivery"
+		if rewardDeliveries, err = service.LoadRewardDeliveries(path); err != nil {
+			return fmt.Errorf("failed to load reward deliveries from challenge config: %w", err)
+		}
+		// Challenges with "autoDeactivateAfterDays" deactivate goals left without progress that long
+		if autoDeactivation, err = service.LoadAutoDeactivation(path); err != nil {
+			return fmt.Errorf("failed to load auto deactivation from challenge config: %w", err)
+		}
+		// Goals with "repeatable" reset on claim and can be claimed again after "cooldownHours"
+		if repeatableGoals, err = service.LoadRepeatableGoals(path); err != nil {
+			return fmt.Errorf("failed to load repeatable goals from challenge config: %w", err)
+		}
+		// Challenges with "autoRandomSelect" randomly activate goals for new players at initialization
+		if autoSelections, err = service.LoadAutoRandomSelections(path); err != nil {
+			return fmt.Errorf("failed to load auto random selections from challenge config: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		logrus.Fatalf("Failed to load challenge config: %v", err)
 	}
 	logrus.Infof("Loaded %d challenges from config", len(challengeConfig.Challenges))
 
-	// Initialize GoalCache with in-memory implementation
+	// Initialize GoalCache with in-memory implementation. It keeps the configured path, so an
+	// admin reload after a fallback boot reads the fixed file
 	goalCache := commonCache.NewInMemoryGoalCache(challengeConfig, configPath, slogLogger)
 	logrus.Infof("GoalCache initialized with %d challenges", len(challengeConfig.Challenges))
 
@@ -209,11 +395,25 @@ func main() {
 	// This cache stores pre-marshaled JSON for static challenge data, reducing CPU by ~40%
 	serializedCache := cache.NewSerializedChallengeCache()
 
+	serializedCache.SetHiddenGoals(hiddenGoals)
+	serializedCache.SetTargetOverrides(targetOverrides)
+	logrus.Infof("Loaded %d hidden goals from config", len(hiddenGoals))
+	logrus.Infof("Loaded %d challenges that do not track inactive progress", len(inactivePolicy))
+	logrus.Infof("Loaded %d challenges with prerequisite challenges", len(challengePrereqs))
+	logrus.Infof("Loaded %d challenges with a rollout percentage", len(rollouts))
+	logrus.Infof("Loaded %d goals with segment target overrides", len(targetOverrides))
+	logrus.Infof("Loaded %d match goals", len(matchGoals))
+	logrus.Infof("Loaded %d multi-step goals", len(goalSteps))
+	logrus.Infof("Loaded %d goals with reward caps", len(rewardCaps))
+	logrus.Infof("Loaded %d goals with a default reward delivery", rewardDeliveries.Len())
+	logrus.Infof("Loaded %d challenges that deactivate abandoned goals", len(autoDeactivation))
+	logrus.Infof("Loaded %d repeatable goals", len(repeatableGoals))
+
 	// Convert domain challenges to protobuf format for cache warm-up
 	pbChallenges := make([]*pb.Challenge, 0, len(challengeConfig.Challenges))
 	for _, domainChallenge := range goalCache.GetAllChallenges() {
 		// Convert without user progress (progress will be injected at request time)
-		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, time.Now().UTC())
+		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, nil, time.Now().UTC())
 		if err != nil {
 			logrus.Warnf("Failed to convert challenge %s for serialization cache: %v", domainChallenge.ID, err)
 			continue
@@ -226,13 +426,45 @@ func main() {
 		logrus.Fatalf("Failed to warm up serialization cache: %v", err)
 	}
 
-	challengeCount, goalCount, totalBytes := serializedCache.GetStats()
-	logrus.Infof("Serialization cache warmed up: %d challenges, %d goals, %d bytes cached", challengeCount, goalCount, totalBytes)
+	cacheStats := serializedCache.GetStats()
+	logrus.Infof("Serialization cache warmed up: %d challenge fragments, %d goal fragments, %d bytes cached",
+		cacheStats.ChallengeFragments, cacheStats.GoalFragments, cacheStats.TotalBytes)
+
+	// Config info metrics and version are refreshed by reloads
+	configInfo.Update(loadedPath, goalCache.GetAllChallenges(), cacheStats.TotalBytes)
 
-	// Initialize GoalRepository with PostgreSQL implementation
-	goalRepo := commonRepo.NewPostgresGoalRepository(db)
+	// Unpaginated GET /v1/challenges responses above CHALLENGES_RESPONSE_MAX_BYTES are
+	// rejected unless the client sets allow_large=true
+	responseSizeGuard := handler.NewResponseSizeGuardFromEnv(serializedCache)
+	responseSizeGuard.WarnIfOverCap()
+	degradedMode := handler.NewDegradedModeFromEnv()
+
+	// Pseudonymized user IDs in user_goal_progress (USER_ID_CODEC, disabled when unset);
+	// the event handler must run with the same codec settings
+	userIDs, err := newUserIDMapper(db)
+	if err != nil {
+		logrus.Fatalf("Invalid USER_ID_CODEC settings: %v", err)
+	}
+
+	// Initialize GoalRepository with PostgreSQL implementation, instrumented per method
+	// (repository_query_duration_seconds; calls slower than DB_SLOW_QUERY_THRESHOLD are logged).
+	// Its upserts keep completion times (see NewCompletionGuardGoalRepository)
+	queryMetrics := serviceRepo.NewQueryMetricsFromEnv()
+	goalRepo := serviceRepo.NewInstrumentedGoalRepository(
+		serviceRepo.NewUserIDCodecGoalRepository(
+			serviceRepo.NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db), userIDs), queryMetrics)
 	logrus.Infof("GoalRepository initialized")
 
+	// Keyset-paginated progress queries for GET /v1/challenges?limit=N. The repositories
+	// of the request paths are instrumented like the goal repository, so a request's
+	// query count covers them all
+	progressQueries := serviceRepo.NewInstrumentedProgressQueryRepository(
+		serviceRepo.NewUserIDCodecProgressQueryRepository(serviceRepo.NewPostgresProgressQueryRepository(db), userIDs), queryMetrics)
+	activationSources := serviceRepo.NewInstrumentedActivationSourceRepository(
+		serviceRepo.NewUserIDCodecActivationSourceRepository(serviceRepo.NewPostgresActivationSourceRepository(db), userIDs), queryMetrics)
+	eventOutbox := serviceRepo.NewInstrumentedEventOutboxRepository(
+		serviceRepo.NewUserIDCodecEventOutboxRepository(serviceRepo.NewPostgresEventOutboxRepository(db), userIDs), queryMetrics)
+
 	// Initialize Platform SDK services for reward granting (Phase 7)
 	platformClient := factory.NewPlatformClient(configRepo)
 	entitlementService := &platform.EntitlementService{
@@ -249,13 +481,32 @@ func main() {
 
 	// Create RewardClient based on REWARD_CLIENT_MODE environment variable (already read above)
 	var rewardClient commonClient.RewardClient
+	var tokenMonitor *client.TokenMonitor
 
 	switch rewardMode {
 	case "mock":
-		rewardClient = commonClient.NewDevMockRewardClient()
+		// Fake grant IDs let clients exercise the claim response fields locally
+		rewardClient = client.WithMockGrantResults(commonClient.NewDevMockRewardClient())
 		logrus.Warnf("Using DevMockRewardClient (for local development only - rewards will be logged but not granted)")
 	case "real":
-		rewardClient = client.NewAGSRewardClient(entitlementService, walletService, logrusLogger)
+		// Watches the IAM token for /healthz and logs in again when it lapses; a grant rejected
+		// with 401 refreshes it through the monitor and retries once
+		tokenMonitor = client.NewTokenMonitor(tokenRepo, logrusLogger)
+		tokenMonitor.SetLogin(func() error {
+			clientId := configRepo.GetClientId()
+			clientSecret := configRepo.GetClientSecret()
+			return oauthService.LoginClient(&clientId, &clientSecret)
+		})
+		itemGranter := client.NewItemGranter(entitlementService, logrusLogger)
+		itemGranter.SetTokenRefresher(tokenMonitor)
+		walletGranter := client.NewWalletGranter(walletService, logrusLogger)
+		walletGranter.SetTokenRefresher(tokenMonitor)
+
+		// One granter per reward type; register new types (e.g. "SEASON_XP") here
+		rewardClient = client.NewAGSRewardClient(client.RewardGranters{
+			client.RewardTypeItem:   itemGranter,
+			client.RewardTypeWallet: walletGranter,
+		}, logrusLogger)
 		logrus.Infof("AGSRewardClient initialized")
 	default:
 		logrus.Fatalf("Invalid REWARD_CLIENT_MODE: %s (must be 'mock' or 'real')", rewardMode)
@@ -270,6 +521,187 @@ func main() {
 		namespace,
 	)
 
+	challengeServiceServer.SetLogger(logrusLogger)
+	challengeServiceServer.SetUserIDs(userIDs)
+	challengeServiceServer.SetQueryMetrics(queryMetrics)
+	challengeServiceServer.SetHiddenGoals(hiddenGoals)
+	challengeServiceServer.SetInactiveProgressPolicy(inactivePolicy)
+	challengeServiceServer.SetLateEventPolicies(latePolicies)
+	challengeServiceServer.SetChallengePrerequisites(challengePrereqs)
+	challengeServiceServer.SetChallengeRollouts(rollouts)
+	challengeServiceServer.SetTargetOverrides(targetOverrides)
+	challengeServiceServer.SetMatchGoals(matchGoals)
+	challengeServiceServer.SetGoalSteps(goalSteps)
+	challengeServiceServer.SetRewardCaps(rewardCaps,
+		serviceRepo.NewInstrumentedRewardCapRepository(serviceRepo.NewPostgresRewardCapRepository(db), queryMetrics))
+	challengeServiceServer.SetRewardDeliveries(rewardDeliveries)
+	repeatableGoalRepo := serviceRepo.NewInstrumentedRepeatableGoalRepository(
+		serviceRepo.NewUserIDCodecRepeatableGoalRepository(serviceRepo.NewPostgresRepeatableGoalRepository(db), userIDs), queryMetrics)
+	challengeServiceServer.SetRepeatableGoals(repeatableGoals, repeatableGoalRepo)
+
+	// POST /v1/admin/config/reload swaps the config and returns a diff of what changed
+	configReloader := service.NewConfigReloader(goalCache, serializedCache, progressQueries, namespace, configPath, hiddenGoals, inactivePolicy, challengePrereqs, logrusLogger)
+	configReloader.SetFallback(configFallback)
+	configReloader.SetChallengeRollouts(rollouts)
+	configReloader.SetTargetOverrides(targetOverrides)
+	configReloader.SetMatchGoals(matchGoals)
+	configReloader.SetGoalSteps(goalSteps)
+	configReloader.SetRewardCaps(rewardCaps)
+	configReloader.SetRewardDeliveries(rewardDeliveries)
+	configReloader.SetAutoDeactivation(autoDeactivation)
+	configReloader.SetRepeatableGoals(repeatableGoals)
+	configReloader.SetAutoRandomSelections(autoSelections)
+	configReloader.SetConfigInfo(configInfo)
+
+	// Lock-free goal lookups for the optimized handlers, refreshed by every config reload
+	goalSnapshots := cache.NewGoalSnapshots(goalCache)
+	configReloader.SetGoalSnapshots(goalSnapshots)
+	challengeServiceServer.SetConfigReloader(configReloader)
+
+	// Per-user rolling 24h claim cap (CLAIM_CAP_PER_DAY, disabled when unset)
+	claimCap := service.NewClaimCapFromEnv(
+		serviceRepo.NewInstrumentedClaimCounterRepository(serviceRepo.NewPostgresClaimCounterRepository(db), queryMetrics), logrusLogger)
+	challengeServiceServer.SetClaimCap(claimCap)
+	if claimCap.Enabled() {
+		logrus.Infof("Claim cap enabled: %d claims per user per %s", claimCap.Limit(), service.ClaimCapWindow)
+	}
+
+	// Goal selection history (goal_selection_events), written with each selection;
+	// the janitor deletes it after SELECTION_HISTORY_RETENTION
+	goalSelections := serviceRepo.NewUserIDCodecGoalSelectionRepository(serviceRepo.NewPostgresGoalSelectionRepository(db), userIDs)
+	challengeServiceServer.SetGoalSelections(goalSelections)
+	janitor := service.NewJanitorFromEnv(goalSelections, logrusLogger)
+	go janitor.Run(ctx)
+
+	// New players get goals of the challenges with autoRandomSelect randomly activated at initialization
+	autoRandomSelector := service.NewAutoRandomSelector(autoSelections, challengePrereqs)
+	autoRandomSelector.SetChallengeRollouts(rollouts)
+	autoRandomSelector.SetGoalSelections(goalSelections)
+	autoRandomSelector.SetRepeatableGoals(repeatableGoals, repeatableGoalRepo)
+	autoRandomSelector.SetActivationSources(activationSources)
+	autoRandomSelector.SetEventOutbox(eventOutbox)
+	challengeServiceServer.SetAutoRandomSelector(autoRandomSelector)
+	if autoRandomSelector.Enabled() {
+		logrus.Infof("Auto random selection enabled for %d challenges", len(autoSelections))
+	}
+
+	// Deactivates goals left without progress past their challenge's autoDeactivateAfterDays
+	// (ABANDONED_GOAL_SWEEP_INTERVAL); does nothing when no challenge sets it
+	abandonedGoals := service.NewAbandonedGoalSweeperFromEnv(serviceRepo.NewPostgresAbandonedGoalRepository(db), namespace, autoDeactivation, logrusLogger)
+	go abandonedGoals.Run(ctx)
+
+	// Admin claim freezes for accounts flagged by anti-cheat, checked before every claim
+	challengeServiceServer.SetClaimFreezes(service.NewClaimFreezes(
+		serviceRepo.NewInstrumentedClaimFreezeRepository(serviceRepo.NewPostgresClaimFreezeRepository(db), queryMetrics), logrusLogger))
+
+	// Bounded pool for the background work of requests (BACKGROUND_WORKERS, BACKGROUND_QUEUE_SIZE)
+	workerPool := common.NewWorkerPool(common.NewWorkerPoolConfigFromEnv())
+	challengeServiceServer.SetWorkerPool(workerPool)
+	go workerPool.Run(ctx)
+
+	// Claims whose reward grant AGS rejected permanently, listed and retried by admins
+	challengeServiceServer.SetFailedGrants(service.NewFailedGrants(
+		serviceRepo.NewInstrumentedFailedGrantRepository(serviceRepo.NewPostgresFailedGrantRepository(db), queryMetrics), logrusLogger))
+
+	// Players of the namespaces in REWARD_GRANT_NAMESPACES get rewards granted in their token namespace
+	grantNamespaces := service.NewGrantNamespacesFromEnv(namespace)
+	challengeServiceServer.SetGrantNamespaces(grantNamespaces)
+	if grantNamespaces.Enabled() {
+		logrus.Infof("Rewards also grantable in namespaces: %s", strings.Join(grantNamespaces.Namespaces(), ", "))
+	}
+
+	// GET /v1/admin/stats/goals, cached for GOAL_STATS_CACHE_TTL; per-goal gauges behind GOAL_STATS_METRICS_ENABLED
+	goalStats := service.NewGoalStatsFromEnv(progressQueries, namespace, logrusLogger)
+	challengeServiceServer.SetGoalStats(goalStats)
+
+	// GET /v1/admin/debug/state, only with SERVICE_STATE_RPC_ENABLED=true
+	serviceState := service.NewServiceStateReporterFromEnv(configInfo, goalCache, serializedCache, db, rewardMode, authEnabled, startedAt, logrusLogger)
+	challengeServiceServer.SetServiceState(serviceState)
+	if serviceState.Enabled() {
+		logrus.Info("Service state RPC enabled")
+	}
+	if goalStats.MetricsEnabled() {
+		go goalStats.Run(ctx)
+		logrus.Infof("Goal stats metrics enabled, refreshed every %s", goalStats.TTL())
+	}
+
+	// Default goal backfills: POST /v1/admin/goals/{goal_id}/backfill and the goals
+	// listed in BACKFILL_DEFAULT_GOALS (BACKFILL_BATCH_SIZE, BACKFILL_USERS_PER_SECOND)
+	backfills := service.NewBackfills(serviceRepo.NewPostgresBackfillRepository(db), goalCache, namespace, service.NewBackfillConfigFromEnv(logrusLogger), logrusLogger)
+	backfills.SetChallengeRollouts(rollouts)
+	challengeServiceServer.SetBackfills(backfills)
+	backfills.StartFromEnv(ctx)
+	go backfills.Run(ctx)
+
+	// Admin bulk goal activations: POST /v1/admin/goals/{goal_id}/bulk-activate, paced
+	// like the backfills (BACKFILL_BATCH_SIZE, BACKFILL_USERS_PER_SECOND)
+	bulkActivations := service.NewBulkActivations(
+		serviceRepo.NewUserIDCodecBulkActivationRepository(serviceRepo.NewPostgresBulkActivationRepository(db), userIDs), goalCache, namespace, service.NewBackfillConfigFromEnv(logrusLogger), logrusLogger)
+	challengeServiceServer.SetBulkActivations(bulkActivations)
+	go bulkActivations.Run(ctx)
+
+	// GET /v1/challenges/unclaimed-count, cached per user for UNCLAIMED_COUNT_CACHE_TTL
+	unclaimedCounts := service.NewUnclaimedCountsFromEnv(progressQueries, namespace, logrusLogger)
+	challengeServiceServer.SetUnclaimedCounts(unclaimedCounts)
+	challengeMetrics := service.NewChallengeMetricsFromEnv(goalCache, namespace)
+	challengeServiceServer.SetChallengeMetrics(challengeMetrics)
+
+	// POST /v1/session/start: initialize, summary and unclaimed counts in one call (SESSION_START_ENABLED)
+	if strings.ToLower(common.GetEnv("SESSION_START_ENABLED", "false")) == "true" {
+		challengeServiceServer.SetSessionStart(true)
+		logrus.Info("Session start endpoint enabled")
+	}
+
+	// Admin status recomputes: POST /v1/admin/users/{user_id}/statuses/recompute, and
+	// users queued by POST /v1/admin/config/reload with recompute_statuses
+	statusRecomputes := service.NewStatusRecomputes(
+		serviceRepo.NewUserIDCodecGoalAdminRepository(serviceRepo.NewPostgresGoalAdminRepository(db), userIDs), progressQueries, goalCache, goalSteps, inactivePolicy, namespace, logrusLogger)
+	statusRecomputes.SetUnclaimedCounts(unclaimedCounts)
+	statusRecomputes.SetChallengeMetrics(challengeMetrics)
+	challengeServiceServer.SetStatusRecomputes(statusRecomputes)
+	go statusRecomputes.Run(ctx)
+
+	// Resolves claim_outbox entries left by claims interrupted between the AGS grant
+	// and marking the goal claimed (CLAIM_RECOVERY_INTERVAL, CLAIM_RECOVERY_STALE_AFTER)
+	claimRecovery := service.NewClaimRecoveryFromEnv(goalRepo,
+		serviceRepo.NewInstrumentedClaimOutboxRepository(serviceRepo.NewPostgresClaimOutboxRepository(db), queryMetrics), logrusLogger)
+	go claimRecovery.Run(ctx)
+	// A claim that finds an entry idle that long resumes it instead of waiting for recovery
+	challengeServiceServer.SetClaimStaleAfter(claimRecovery.StaleAfter())
+
+	// Publishes event_outbox (goal.completed, goal.claimed, goals.selected, player.initialized)
+	// to the EVENT_PUBLISHER; without one the outbox is drained unpublished
+	eventPublisher, err := client.NewEventPublisherFromEnv(logrusLogger)
+	if err != nil {
+		logrus.Fatalf("Failed to create event publisher: %v", err)
+	}
+	eventRelay := service.NewEventRelayFromEnv(eventOutbox, eventPublisher, logrusLogger)
+	go eventRelay.Run(ctx)
+
+	// POST /v1/namespaces/{namespace}/progress/batch limits (BATCH_PROGRESS_MAX_EVENTS, BATCH_PROGRESS_CHUNK_SIZE)
+	challengeServiceServer.SetBatchProgressConfig(service.NewBatchProgressConfigFromEnv())
+
+	// Extra dependencies reported by HealthCheck (/healthz, /readyz) besides the database and GoalCache
+	challengeServiceServer.AddHealthComponents(server.HealthComponent{
+		Name:     "serialized_cache",
+		Critical: true,
+		Check:    serializedCache.Check,
+	}, server.HealthComponent{
+		// Degraded, not down, while running on the last-known-good config (CONFIG_FALLBACK)
+		Name:     "config",
+		Critical: false,
+		Check:    configFallback.Check,
+	})
+	if tokenMonitor != nil {
+		go tokenMonitor.Run(ctx, tokenCheckInterval)
+
+		challengeServiceServer.AddHealthComponents(server.HealthComponent{
+			Name:     "iam_token",
+			Critical: true,
+			Check:    tokenMonitor.Check,
+		})
+	}
+
 	// Register Challenge Service with gRPC server
 	pb.RegisterServiceServer(s, challengeServiceServer)
 	logrus.Infof("ChallengeService registered with gRPC server")
@@ -281,7 +713,8 @@ func main() {
 	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
 
 	// Create a new HTTP server for the gRPC-Gateway
-	grpcGateway, err := common.NewGateway(ctx, fmt.Sprintf("localhost:%d", grpcServerPort), basePath)
+	grpcGateway, err := common.NewGateway(ctx, fmt.Sprintf("localhost:%d", grpcServerPort), basePath,
+		runtime.WithMetadata(eventSignatures.GatewayMetadata))
 	if err != nil {
 		logrus.Fatalf("Failed to create gRPC-Gateway: %v", err)
 	}
@@ -294,11 +727,26 @@ func main() {
 		optimizedChallengesHandler := handler.NewOptimizedChallengesHandler(
 			goalCache,
 			goalRepo,
+			progressQueries,
 			serializedCache,
 			namespace,
 			authEnabled,
 			common.Validator, // Token validator (may be nil if auth disabled)
 		)
+		optimizedChallengesHandler.SetHiddenGoals(hiddenGoals)
+		optimizedChallengesHandler.SetChallengePrerequisites(challengePrereqs)
+		optimizedChallengesHandler.SetChallengeRollouts(rollouts)
+		optimizedChallengesHandler.SetTargetOverrides(targetOverrides)
+		optimizedChallengesHandler.SetActivationSources(activationSources)
+		optimizedChallengesHandler.SetGoalSteps(goalSteps,
+			serviceRepo.NewInstrumentedStepProgressRepository(
+				serviceRepo.NewUserIDCodecStepProgressRepository(serviceRepo.NewPostgresStepProgressRepository(db), userIDs), queryMetrics))
+		optimizedChallengesHandler.SetRepeatableGoals(repeatableGoals, repeatableGoalRepo)
+		optimizedChallengesHandler.SetResponseSizeGuard(responseSizeGuard)
+		optimizedChallengesHandler.SetDegradedMode(degradedMode)
+		optimizedChallengesHandler.SetGoalSnapshots(goalSnapshots)
+		optimizedChallengesHandler.SetDebugMetadata(debugMetadata)
+		optimizedChallengesHandler.SetDebugTiming(debugTiming)
 
 		// Create optimized initialize handler (bypasses Protobuf marshaling for 50% CPU reduction)
 		optimizedInitializeHandler := handler.NewOptimizedInitializeHandler(
@@ -308,6 +756,28 @@ func main() {
 			authEnabled,
 			common.Validator, // Token validator (may be nil if auth disabled)
 		)
+		optimizedInitializeHandler.SetLogger(logrusLogger)
+		optimizedInitializeHandler.SetActivationSources(activationSources)
+		optimizedInitializeHandler.SetUnclaimedCounts(unclaimedCounts)
+		optimizedInitializeHandler.SetTargetOverrides(targetOverrides)
+		optimizedInitializeHandler.SetEventOutbox(eventOutbox)
+		optimizedInitializeHandler.SetAutoRandomSelector(autoRandomSelector)
+		optimizedInitializeHandler.SetChallengeRollouts(rollouts)
+		optimizedInitializeHandler.SetGoalSnapshots(goalSnapshots)
+		optimizedInitializeHandler.SetDebugMetadata(debugMetadata)
+		optimizedInitializeHandler.SetDebugTiming(debugTiming)
+		optimizedInitializeHandler.SetStrictRequestBodies(strictRequestBodies)
+		// A default goal row that already exists or violates a constraint does not fail the login
+		optimizedInitializeHandler.SetProgressInserter(
+			serviceRepo.NewInstrumentedProgressInsertRepository(
+				serviceRepo.NewUserIDCodecProgressInsertRepository(serviceRepo.NewPostgresProgressInsertRepository(db), userIDs), queryMetrics))
+
+		// gRPC-Web for browser clients, served in-process by the gRPC server (same interceptors)
+		grpcWebConfig := common.NewGRPCWebConfigFromEnv()
+		var grpcWebHandler http.Handler
+		if grpcWebConfig.Enabled {
+			grpcWebHandler = common.NewGRPCWebHandler(s, grpcWebConfig)
+		}
 
 		grpcGatewayHTTPServer := newGRPCGatewayHTTPServer(
 			fmt.Sprintf(":%d", grpcGatewayHTTPPort),
@@ -316,9 +786,17 @@ func main() {
 			swaggerDir,
 			optimizedChallengesHandler, // Pass optimized challenges handler
 			optimizedInitializeHandler, // Pass optimized initialize handler
+			optimizedHandlers,          // Routes served by the optimized handlers (OPTIMIZED_HANDLERS)
+			grpcWebHandler,             // nil when GRPC_WEB_ENABLED=false
+			grpcWebConfig.Path,
 			basePath,
+			loadShedder,
+			eventSignatures,
+			requestMetrics,
+			trustedProxies,
+			segmentResolver,
 		)
-		logrus.Infof("Starting gRPC-Gateway HTTP server on port %d (with optimized /v1/challenges and /v1/challenges/initialize endpoints)", grpcGatewayHTTPPort)
+		logrus.Infof("Starting gRPC-Gateway HTTP server on port %d (optimized handlers: %s)", grpcGatewayHTTPPort, optimizedHandlers)
 		if err := grpcGatewayHTTPServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logrus.Fatalf("Failed to run gRPC-Gateway HTTP server: %v", err)
 		}
@@ -332,11 +810,39 @@ func main() {
 		prometheusCollectors.NewGoCollector(),
 		prometheusCollectors.NewProcessCollector(prometheusCollectors.ProcessCollectorOpts{}),
 		prometheusGrpc.DefaultServerMetrics,
+		version.NewBuildInfoCollector(),
 	)
+	prometheusRegistry.MustRegister(configReloader.Collectors()...)
+	prometheusRegistry.MustRegister(configInfo.Collectors()...)
+	prometheusRegistry.MustRegister(configFallback.Collectors()...)
+	prometheusRegistry.MustRegister(claimCap.Collectors()...)
+	prometheusRegistry.MustRegister(claimRecovery.Collectors()...)
+	prometheusRegistry.MustRegister(loadShedder.Collectors()...)
+	prometheusRegistry.MustRegister(eventSignatures.Collectors()...)
+	prometheusRegistry.MustRegister(workerPool.Collectors()...)
+	prometheusRegistry.MustRegister(requestMetrics.Collectors()...)
+	prometheusRegistry.MustRegister(queryMetrics.Collectors()...)
+	prometheusRegistry.MustRegister(payloadLogger.Collectors()...)
+	prometheusRegistry.MustRegister(goalStats.Collectors()...)
+	prometheusRegistry.MustRegister(eventRelay.Collectors()...)
+	prometheusRegistry.MustRegister(janitor.Collectors()...)
+	prometheusRegistry.MustRegister(abandonedGoals.Collectors()...)
+	prometheusRegistry.MustRegister(backfills.Collectors()...)
+	prometheusRegistry.MustRegister(bulkActivations.Collectors()...)
+	prometheusRegistry.MustRegister(statusRecomputes.Collectors()...)
+	prometheusRegistry.MustRegister(challengeMetrics.Collectors()...)
+	prometheusRegistry.MustRegister(service.GoalConfigGuardCollectors()...)
+	prometheusRegistry.MustRegister(client.RateLimitCollectors()...)
+	if tokenMonitor != nil {
+		prometheusRegistry.MustRegister(tokenMonitor.Collectors()...)
+	}
+	prometheusRegistry.MustRegister(responseSizeGuard.Collectors()...)
+	prometheusRegistry.MustRegister(degradedMode.Collectors()...)
 
 	go func() {
 		mux := http.NewServeMux()
-		mux.Handle(metricsEndpoint, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}))
+		// OpenMetrics carries the trace_id exemplars of request_duration_seconds to scrapers that ask for it
+		mux.Handle(metricsEndpoint, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true}))
 
 		// Register pprof handlers
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -405,6 +911,247 @@ func main() {
 	logrus.Infof("SIGTERM received")
 }
 
+// runGoalIDCollisionReport prints the goal IDs that CHALLENGE_CONFIG_PATH uses
+// for goals of more than one challenge, as a JSON array of {goalId,
+// challengeIds}, so a config rejected at startup can be fixed in one pass.
+// It returns the process exit code: 0 without collisions, 1 with, 2 if the
+// file cannot be read.
+func runGoalIDCollisionReport() int {
+	configPath := common.GetEnv("CHALLENGE_CONFIG_PATH", "config/challenges.json")
+	collisions, err := service.LoadGoalIDCollisions(configPath)
+	if err != nil {
+		logrus.Errorf("Failed to check goal IDs: %v", err)
+		return 2
+	}
+	if collisions == nil {
+		collisions = []service.GoalIDCollision{}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collisions); err != nil {
+		logrus.Errorf("Failed to write goal ID collision report: %v", err)
+		return 2
+	}
+	if len(collisions) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// newUserIDMapper returns the USER_ID_CODEC mapper, nil when the codec is disabled.
+func newUserIDMapper(db *sql.DB) (*serviceRepo.UserIDMapper, error) {
+	codec, err := serviceRepo.NewUserIDCodecFromEnv()
+	if err != nil || codec == nil {
+		return nil, err
+	}
+	logrus.Infof("User IDs are stored encoded (reversible: %v)", codec.Reversible())
+	return serviceRepo.NewUserIDMapper(codec, serviceRepo.NewPostgresUserIDRekeyRepository(db)), nil
+}
+
+// runUserIDBackfill handles --encode-user-ids: it converts the user IDs stored
+// before USER_ID_CODEC was enabled, or under a previous AES-GCM key, in batches
+// of USER_ID_BACKFILL_BATCH_SIZE users and prints the counts as JSON. The exit
+// code is 0 when every stored ID is under the current key.
+func runUserIDBackfill(ctx context.Context) int {
+	codec, err := serviceRepo.NewUserIDCodecFromEnv()
+	if err != nil {
+		logrus.Errorf("Invalid USER_ID_CODEC settings: %v", err)
+		return 2
+	}
+	if codec == nil {
+		logrus.Errorf("USER_ID_CODEC is not set")
+		return 2
+	}
+
+	db, err := commonDB.Connect(commonDB.NewConfigFromEnv())
+	if err != nil {
+		logrus.Errorf("Failed to connect to database: %v", err)
+		return 2
+	}
+	defer func() { _ = db.Close() }()
+
+	batchSize := common.GetEnvInt("USER_ID_BACKFILL_BATCH_SIZE", 500)
+	result, err := serviceRepo.EncodeStoredUserIDs(ctx, codec, serviceRepo.NewPostgresUserIDRekeyRepository(db), batchSize)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(result); encodeErr != nil {
+		logrus.Errorf("Failed to write user ID backfill report: %v", encodeErr)
+		return 2
+	}
+	if err != nil {
+		logrus.Errorf("User ID backfill stopped: %v", err)
+		return 2
+	}
+	if result.Skipped > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runSelfTest handles --self-test: it connects to the database, checks the
+// migrations without applying them, loads the challenge config, warms the
+// serialization cache and, when startup would, logs in to IAM. No port is bound.
+// The JSON report goes to stdout (logs go to stderr) and the exit code is 0 only
+// if every applicable check passed. Each check is bounded by
+// SELF_TEST_CHECK_TIMEOUT seconds.
+func runSelfTest(ctx context.Context) int {
+	timeout := time.Duration(common.GetEnvInt("SELF_TEST_CHECK_TIMEOUT", 30)) * time.Second
+	slogLogger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	configPath := common.GetEnv("CHALLENGE_CONFIG_PATH", "config/challenges.json")
+
+	var db *sql.DB
+	defer func() {
+		if db != nil {
+			_ = db.Close()
+		}
+	}()
+	var challengeConfig *commonConfig.Config
+
+	checks := []selftest.Check{
+		{
+			Name:    "database",
+			Timeout: timeout,
+			Run: func(ctx context.Context) (string, error) {
+				conn, err := commonDB.Connect(commonDB.NewConfigFromEnv())
+				if err != nil {
+					return "", err
+				}
+				db = conn
+				if err := db.PingContext(ctx); err != nil {
+					return "", fmt.Errorf("failed to ping database: %w", err)
+				}
+				return "connected", nil
+			},
+		},
+		{
+			Name:    "migrations",
+			Timeout: timeout,
+			Run: func(ctx context.Context) (string, error) {
+				migrationsPath := common.GetEnv("MIGRATIONS_PATH", "file:///app/migrations")
+				pending, err := migrations.PendingMigrations(db, migrationsPath)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d pending migrations (dry run, none applied)", pending), nil
+			},
+		},
+		{
+			Name:    "challenge_config",
+			Timeout: timeout,
+			Run: func(ctx context.Context) (string, error) {
+				if err := service.CheckConfigSchema(configPath); err != nil {
+					return "", err
+				}
+				if err := service.CheckGoalIDCollisions(configPath); err != nil {
+					return "", err
+				}
+				cfg, err := commonConfig.NewConfigLoader(configPath, slogLogger).LoadConfig()
+				if err != nil {
+					return "", err
+				}
+				// Service-local flags read from the same file; startup refuses invalid ones too
+				if _, err := service.LoadHiddenGoals(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadInactiveProgressPolicy(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadLateEventPolicies(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadChallengePrerequisites(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadChallengeRollouts(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadTargetOverrides(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadMatchGoals(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadGoalSteps(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadRewardCaps(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadRewardDeliveries(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadAutoDeactivation(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadRepeatableGoals(configPath); err != nil {
+					return "", err
+				}
+				if _, err := service.LoadAutoRandomSelections(configPath); err != nil {
+					return "", err
+				}
+				challengeConfig = cfg
+				return fmt.Sprintf("%d challenges", len(cfg.Challenges)), nil
+			},
+		},
+		{
+			Name:    "serialization_cache",
+			Timeout: timeout,
+			Run: func(ctx context.Context) (string, error) {
+				goalCache := commonCache.NewInMemoryGoalCache(challengeConfig, configPath, slogLogger)
+				pbChallenges, err := mapper.ChallengesToProto(goalCache.GetAllChallenges(), nil, nil, time.Now().UTC())
+				if err != nil {
+					return "", err
+				}
+				serializedCache := cache.NewSerializedChallengeCache()
+				if err := serializedCache.WarmUp(pbChallenges); err != nil {
+					return "", err
+				}
+				if err := serializedCache.Check(ctx); err != nil {
+					return "", err
+				}
+				stats := serializedCache.GetStats()
+				return fmt.Sprintf("%d challenge fragments, %d goal fragments", stats.ChallengeFragments, stats.GoalFragments), nil
+			},
+		},
+		{
+			// Only validates the client credentials; no reward is granted
+			Name:    "iam_login",
+			Timeout: timeout,
+			Run: func(ctx context.Context) (string, error) {
+				rewardMode := common.GetEnv("REWARD_CLIENT_MODE", "real")
+				authEnabled := strings.ToLower(common.GetEnv("PLUGIN_GRPC_SERVER_AUTH_ENABLED", "true")) == "true"
+				if rewardMode != "mock" && rewardMode != "real" {
+					return "", fmt.Errorf("invalid REWARD_CLIENT_MODE: %s (must be 'mock' or 'real')", rewardMode)
+				}
+				if rewardMode != "real" && !authEnabled {
+					return "mock reward mode with auth disabled", selftest.ErrSkipped
+				}
+
+				configRepo := sdkAuth.DefaultConfigRepositoryImpl()
+				oauthService := iam.OAuth20Service{
+					Client:           factory.NewIamClient(configRepo),
+					TokenRepository:  sdkAuth.DefaultTokenRepositoryImpl(),
+					ConfigRepository: configRepo,
+				}
+				clientId := configRepo.GetClientId()
+				clientSecret := configRepo.GetClientSecret()
+				if err := oauthService.LoginClient(&clientId, &clientSecret); err != nil {
+					return "", fmt.Errorf("unable to login using clientId and clientSecret: %w", err)
+				}
+				return "logged in as client " + clientId, nil
+			},
+		},
+	}
+
+	report := selftest.Run(ctx, checks)
+	if err := report.Write(os.Stdout); err != nil {
+		logrus.Errorf("Failed to write self-test report: %v", err)
+		return 1
+	}
+	return report.ExitCode()
+}
+
 func newGRPCGatewayHTTPServer(
 	addr string,
 	grpcGatewayHandler http.Handler,
@@ -412,39 +1159,65 @@ func newGRPCGatewayHTTPServer(
 	swaggerDir string,
 	optimizedChallengesHandler *handler.OptimizedChallengesHandler,
 	optimizedInitializeHandler *handler.OptimizedInitializeHandler,
+	optimizedHandlers handler.OptimizedHandlers,
+	grpcWebHandler http.Handler,
+	grpcWebPath string,
 	basePath string,
+	loadShedder *common.LoadShedder,
+	eventSignatures *common.EventSignatureVerifier,
+	requestMetrics *common.RequestMetrics,
+	trustedProxies common.TrustedProxies,
+	segmentResolver common.SegmentResolver,
 ) *http.Server {
 	// Create a new ServeMux
 	mux := http.NewServeMux()
 
-	// Register optimized challenges endpoint BEFORE the catch-all gRPC-Gateway handler
-	// This endpoint uses pre-serialized challenge data for ~40% CPU reduction
-	// Path must match the protobuf definition: GET /v1/challenges
-	optimizedChallengesPath := basePath + "/v1/challenges"
-	mux.Handle(optimizedChallengesPath, optimizedChallengesHandler)
-	logger.Infof("Registered optimized handler for %s (pre-serialization enabled)", optimizedChallengesPath)
-
-	// Register optimized initialize endpoint BEFORE the catch-all gRPC-Gateway handler
-	// This endpoint bypasses Protobuf marshaling for ~50% CPU reduction
-	// Path must match the protobuf definition: POST /v1/challenges/initialize
-	optimizedInitializePath := basePath + "/v1/challenges/initialize"
-	mux.Handle(optimizedInitializePath, optimizedInitializeHandler)
-	logger.Infof("Registered optimized handler for %s (direct JSON encoding enabled)", optimizedInitializePath)
-
-	// Add the gRPC-Gateway handler as catch-all (must be last)
-	// This handles all other endpoints including /v1/challenges/{id}/goals/{id}/claim
-	mux.Handle("/", grpcGatewayHandler)
+	// Register the enabled optimized endpoints with the gRPC-Gateway as catch-all.
+	// This handles all other endpoints including /v1/challenges/{id}/goals/{id}/claim,
+	// and the optimized ones that OPTIMIZED_HANDLERS turns off
+	handler.RegisterChallengeRoutes(mux, basePath, optimizedHandlers, handler.ChallengeRoutes{
+		Gateway:         grpcGatewayHandler,
+		Challenges:      optimizedChallengesHandler,
+		ChallengeDetail: http.HandlerFunc(optimizedChallengesHandler.ServeChallenge),
+		Initialize:      optimizedInitializeHandler,
+	}, requestMetrics)
+	logger.Infof("Registered optimized handlers: %s", optimizedHandlers)
+
+	// Build information for on-call (GET /version)
+	versionPath := basePath + "/version"
+	mux.Handle(versionPath, handler.NewVersionHandler())
+	logger.Infof("Registered version handler for %s", versionPath)
+
+	// Subtree paths that must keep their trailing slash, or ServeMux redirects them back to it
+	keepTrailingSlash := []string{basePath + "/apidocs/"}
+
+	// gRPC-Web endpoint: <basePath><grpcWebPath>/service.Service/<Method>
+	if grpcWebHandler != nil {
+		grpcWebPrefix := basePath + grpcWebPath
+		mux.Handle(grpcWebPrefix+"/", http.StripPrefix(grpcWebPrefix, grpcWebHandler))
+		keepTrailingSlash = append(keepTrailingSlash, grpcWebPrefix+"/")
+		logger.Infof("Registered gRPC-Web handler for %s/", grpcWebPrefix)
+	}
 
 	// Serve Swagger UI and JSON
 	serveSwaggerUI(mux)
 	serveSwaggerJSON(mux, swaggerDir)
 
-	// Add logging middleware
-	loggedMux := loggingMiddleware(logger, mux)
+	// Shed excess load with 503 before any handler runs; health probes are never shed
+	// The optimized handlers read the player segment from the request context
+	// Server-to-server event requests must be signed (EVENT_SIGNING_SECRET)
+	signedMux := eventSignatures.HTTPMiddleware(common.SegmentHTTPMiddleware(segmentResolver, mux), basePath+"/v1/events/")
+	shedMux := loadShedder.HTTPMiddleware(signedMux, basePath+"/healthz", basePath+"/readyz", versionPath)
+
+	// Add logging middleware; the client IP is resolved first for the log and the handlers
+	loggedMux := trustedProxies.HTTPMiddleware(loggingMiddleware(logger, handler.VersionHeaders(shedMux)))
+
+	// Clean trailing and doubled slashes before anything matches on the path
+	normalizedMux := handler.NormalizePath(loggedMux, keepTrailingSlash...)
 
 	return &http.Server{
 		Addr:              addr,
-		Handler:           loggedMux,
+		Handler:           normalizedMux,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      30 * time.Second,
@@ -459,9 +1232,10 @@ func loggingMiddleware(logger *logrus.Logger, next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 		duration := time.Since(start)
 		logger.WithFields(logrus.Fields{
-			"method":   r.Method,
-			"path":     r.URL.Path,
-			"duration": duration,
+			"method":    r.Method,
+			"path":      r.URL.Path,
+			"duration":  duration,
+			"client_ip": common.GetClientIPFromContext(r.Context()),
 		}).Info("HTTP request")
 	})
 }
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_user_goal_progress_active_keyset;
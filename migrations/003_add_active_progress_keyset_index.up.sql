@@ -0,0 +1,6 @@
+-- Keyset pagination over a user's active goals (GetUserProgressPage with activeOnly=true)
+-- Serves: WHERE user_id = $1 AND goal_id > $2 AND is_active = true ORDER BY goal_id LIMIT $3
+-- The non-active case is already served by the (user_id, goal_id) primary key.
+CREATE INDEX IF NOT EXISTS idx_user_goal_progress_active_keyset
+ON user_goal_progress(user_id, goal_id)
+WHERE is_active = true;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_claim_events_user_claimed_at;
+DROP TABLE IF EXISTS user_claim_events;
@@ -0,0 +1,14 @@
+-- Claim log for the per-user rolling claim cap (CLAIM_CAP_PER_DAY)
+-- One row per successful claim while the cap is enabled. Rows older than the
+-- window are pruned when the same user claims again.
+CREATE TABLE IF NOT EXISTS user_claim_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    goal_id VARCHAR(100) NOT NULL,
+    claimed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Serves: WHERE user_id = $1 AND namespace = $2 AND claimed_at > $3
+CREATE INDEX IF NOT EXISTS idx_user_claim_events_user_claimed_at
+ON user_claim_events(user_id, namespace, claimed_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_claim_outbox_updated_at;
+DROP TABLE IF EXISTS claim_outbox;
@@ -0,0 +1,22 @@
+-- Claim outbox: guards a reward grant that runs outside the claim transaction
+-- One row per claim in flight. ClaimGoalReward reserves the row (state 'pending')
+-- in the transaction that validates the goal, grants the reward after that
+-- transaction commits, marks the row 'granted', marks the goal claimed and then
+-- deletes the row. The primary key makes the reservation the single guard
+-- against double grants. Rows left behind by a crash are resolved by the claim
+-- recovery worker.
+CREATE TABLE IF NOT EXISTS claim_outbox (
+    user_id VARCHAR(100) NOT NULL,
+    goal_id VARCHAR(100) NOT NULL,
+    challenge_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    state VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, goal_id),
+    CONSTRAINT check_claim_outbox_state CHECK (state IN ('pending', 'granted'))
+);
+
+-- Serves the recovery scan: WHERE updated_at < $1 ORDER BY updated_at
+CREATE INDEX IF NOT EXISTS idx_claim_outbox_updated_at
+ON claim_outbox(updated_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_goal_admin_audit_user_created_at;
+DROP TABLE IF EXISTS goal_admin_audit;
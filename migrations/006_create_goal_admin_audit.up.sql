@@ -0,0 +1,23 @@
+-- Audit log for admin overrides of user goal progress
+-- One row per override (action 'force_complete' for ForceCompleteGoal), written
+-- in the transaction that changes the progress row. previous_status and
+-- previous_progress are NULL when the user had no row for the goal.
+CREATE TABLE IF NOT EXISTS goal_admin_audit (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    goal_id VARCHAR(100) NOT NULL,
+    challenge_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor_user_id VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL,
+    forced BOOLEAN NOT NULL DEFAULT false,
+    auto_claim BOOLEAN NOT NULL DEFAULT false,
+    previous_status VARCHAR(20) NULL,
+    previous_progress INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Serves support lookups: WHERE user_id = $1 ORDER BY created_at
+CREATE INDEX IF NOT EXISTS idx_goal_admin_audit_user_created_at
+ON goal_admin_audit(user_id, created_at);
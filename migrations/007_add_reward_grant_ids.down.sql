@@ -0,0 +1,2 @@
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS reward_wallet_id;
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS reward_entitlement_id;
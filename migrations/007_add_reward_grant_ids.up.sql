@@ -0,0 +1,9 @@
+-- IDs AGS Platform assigned to the reward grant of a claimed goal, for support
+-- lookups when a player disputes a reward. Set after the goal is marked claimed;
+-- NULL for claims made before this migration or by reward clients that do not
+-- report them. Only the column for the reward type is set.
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS reward_entitlement_id VARCHAR(100) NULL;
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS reward_wallet_id VARCHAR(100) NULL;
+
+COMMENT ON COLUMN user_goal_progress.reward_entitlement_id IS 'Entitlement granted for an ITEM reward at claim time';
+COMMENT ON COLUMN user_goal_progress.reward_wallet_id IS 'Wallet credited for a WALLET reward at claim time (AGS returns no credit transaction ID)';
@@ -0,0 +1,2 @@
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_activation_source;
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS activation_source;
@@ -0,0 +1,8 @@
+-- How the goal was last activated, for analytics: 'default' (InitializePlayer),
+-- 'random' (RandomSelectGoals), 'manual' (BatchSelectGoals, SetGoalActive) or
+-- 'admin' (ForceCompleteGoal with force). Deactivation keeps the value. NULL for
+-- rows activated before this migration.
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS activation_source VARCHAR(20) NULL;
+
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_activation_source
+    CHECK (activation_source IN ('default', 'random', 'manual', 'admin'));
@@ -0,0 +1,4 @@
+-- Client address of the admin request behind an override, resolved through the
+-- trusted proxies (TRUSTED_PROXY_CIDRS). NULL for overrides recorded before this
+-- migration or when the address is unknown.
+ALTER TABLE goal_admin_audit ADD COLUMN IF NOT EXISTS client_ip VARCHAR(45) NULL;
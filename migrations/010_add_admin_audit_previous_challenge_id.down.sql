@@ -0,0 +1 @@
+ALTER TABLE goal_admin_audit DROP COLUMN IF EXISTS previous_challenge_id;
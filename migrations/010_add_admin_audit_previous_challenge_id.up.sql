@@ -0,0 +1,3 @@
+-- challenge_id of the progress row before an admin rewrote it (action
+-- 'fix_challenge_id'). NULL for every other action.
+ALTER TABLE goal_admin_audit ADD COLUMN IF NOT EXISTS previous_challenge_id VARCHAR(100) NULL;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS user_goal_progress_status_event_update ON user_goal_progress;
+DROP TRIGGER IF EXISTS user_goal_progress_status_event_insert ON user_goal_progress;
+DROP FUNCTION IF EXISTS enqueue_goal_status_event();
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,54 @@
+-- Event outbox: domain events waiting to be published to other Extend apps
+-- One row per event. goal.completed and goal.claimed rows are written by the
+-- triggers below, in the transaction that changes the goal's status, whichever
+-- app writes it. goals.selected and player.initialized rows are written by the
+-- service after the selection or initialization. The event relay publishes rows
+-- in id order and deletes them once the publisher has accepted them.
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Payloads follow pkg/events (GoalCompleted, GoalClaimed); timestamps are UTC
+-- like every other timestamp column
+CREATE OR REPLACE FUNCTION enqueue_goal_status_event() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'completed' THEN
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.completed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'progress', NEW.progress,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ), COALESCE(NEW.completed_at, NOW()));
+    ELSE
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.claimed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+            'claimedAt', to_char(NEW.claimed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ), COALESCE(NEW.claimed_at, NOW()));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The WHEN clauses keep progress updates that do not change the status off the function
+CREATE TRIGGER user_goal_progress_status_event_insert
+AFTER INSERT ON user_goal_progress
+FOR EACH ROW
+WHEN (NEW.status IN ('completed', 'claimed'))
+EXECUTE FUNCTION enqueue_goal_status_event();
+
+CREATE TRIGGER user_goal_progress_status_event_update
+AFTER UPDATE ON user_goal_progress
+FOR EACH ROW
+WHEN (NEW.status IN ('completed', 'claimed') AND OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION enqueue_goal_status_event();
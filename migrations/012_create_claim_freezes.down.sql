@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_claim_freeze_audit_user_created_at;
+DROP TABLE IF EXISTS claim_freeze_audit;
+DROP INDEX IF EXISTS idx_claim_freezes_namespace_frozen_until;
+DROP TABLE IF EXISTS claim_freezes;
@@ -0,0 +1,35 @@
+-- Claim freezes set by trust & safety for accounts flagged by anti-cheat
+-- One row per frozen user. Claims are refused while frozen_until is in the
+-- future; expired rows are ignored, so nothing has to clean them up.
+CREATE TABLE IF NOT EXISTS claim_freezes (
+    user_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    frozen_until TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL,
+    actor_user_id VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, namespace)
+);
+
+-- Serves the admin list: WHERE namespace = $1 AND frozen_until > $2
+CREATE INDEX IF NOT EXISTS idx_claim_freezes_namespace_frozen_until
+ON claim_freezes(namespace, frozen_until);
+
+-- Audit log of freezes and removals ('freeze' / 'unfreeze'), written in the
+-- transaction that changes claim_freezes
+CREATE TABLE IF NOT EXISTS claim_freeze_audit (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    frozen_until TIMESTAMP NULL,
+    reason TEXT NOT NULL,
+    actor_user_id VARCHAR(100) NOT NULL,
+    client_ip VARCHAR(45) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Serves support lookups: WHERE user_id = $1 ORDER BY created_at
+CREATE INDEX IF NOT EXISTS idx_claim_freeze_audit_user_created_at
+ON claim_freeze_audit(user_id, created_at);
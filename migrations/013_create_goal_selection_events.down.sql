@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_goal_selection_events_created_at;
+DROP INDEX IF EXISTS idx_goal_selection_events_namespace_created_at;
+DROP INDEX IF EXISTS idx_goal_selection_events_user_created_at;
+DROP TABLE IF EXISTS goal_selection_events;
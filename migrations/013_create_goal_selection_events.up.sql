@@ -0,0 +1,27 @@
+-- Goal selection history for design analysis
+-- One row per RandomSelectGoals / BatchSelectGoals call, including calls that
+-- changed nothing, written in the transaction that activates and deactivates
+-- the goals. source is 'random' or 'manual'. Rows older than the retention
+-- period (SELECTION_HISTORY_RETENTION) are deleted by the janitor.
+CREATE TABLE IF NOT EXISTS goal_selection_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    challenge_id VARCHAR(100) NOT NULL,
+    selected_goal_ids TEXT[] NOT NULL,
+    replaced_goal_ids TEXT[] NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Serves the per-user history: WHERE user_id = $1 AND namespace = $2 ORDER BY created_at DESC
+CREATE INDEX IF NOT EXISTS idx_goal_selection_events_user_created_at
+ON goal_selection_events(user_id, namespace, created_at);
+
+-- Serves the per-goal stats: WHERE namespace = $1 AND created_at >= $2
+CREATE INDEX IF NOT EXISTS idx_goal_selection_events_namespace_created_at
+ON goal_selection_events(namespace, created_at);
+
+-- Serves the janitor: WHERE created_at < $1
+CREATE INDEX IF NOT EXISTS idx_goal_selection_events_created_at
+ON goal_selection_events(created_at);
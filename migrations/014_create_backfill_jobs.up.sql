@@ -0,0 +1,21 @@
+-- Default goal backfill jobs (BackfillDefaultGoal)
+-- One row per goal: the job creates the goal's progress row for every user who
+-- has progress rows but not this one. last_user_id is the keyset cursor over
+-- user_goal_progress.user_id, advanced in the transaction that inserts the
+-- batch, so an interrupted or failed job resumes after the last batch.
+-- status is 'running', 'completed' or 'failed'.
+CREATE TABLE IF NOT EXISTS backfill_jobs (
+    goal_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    last_user_id VARCHAR(100) NOT NULL DEFAULT '',
+    users_scanned BIGINT NOT NULL DEFAULT 0,
+    users_skipped BIGINT NOT NULL DEFAULT 0,
+    rows_inserted BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    actor_user_id VARCHAR(100) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP NULL,
+    PRIMARY KEY (goal_id, namespace)
+);
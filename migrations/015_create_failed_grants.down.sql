@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_failed_grants_namespace_last_failed_at;
+DROP TABLE IF EXISTS failed_grants;
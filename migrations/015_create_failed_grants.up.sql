@@ -0,0 +1,26 @@
+-- Dead letters of reward grants that failed permanently (RetryFailedGrant)
+-- One row per user goal: a claim whose grant AGS rejected with a
+-- non-retryable client error, e.g. a store item deleted after the goal was
+-- configured. Retrying the same grant fails identically until the store is
+-- fixed, so the row keeps the reward snapshot for support and counts repeated
+-- failures in attempts. The row is deleted once the goal is claimed.
+CREATE TABLE IF NOT EXISTS failed_grants (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL,
+    goal_id VARCHAR(100) NOT NULL,
+    challenge_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    reward_type VARCHAR(20) NOT NULL,
+    reward_id VARCHAR(100) NOT NULL,
+    reward_quantity INT NOT NULL,
+    error_class VARCHAR(50) NOT NULL,
+    last_error TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 1,
+    first_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, goal_id)
+);
+
+-- Serves the admin list: WHERE namespace = $1 ORDER BY last_failed_at DESC
+CREATE INDEX IF NOT EXISTS idx_failed_grants_namespace_last_failed_at
+ON failed_grants(namespace, last_failed_at);
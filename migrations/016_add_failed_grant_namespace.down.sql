@@ -0,0 +1 @@
+ALTER TABLE failed_grants DROP COLUMN IF EXISTS grant_namespace;
@@ -0,0 +1,7 @@
+-- Namespace the failed grant was attempted in, so RetryFailedGrant grants in the
+-- same one. It differs from namespace (the service namespace the row is listed
+-- under) only for players of a namespace in REWARD_GRANT_NAMESPACES. Empty for
+-- rows recorded before this migration, which were granted in namespace.
+ALTER TABLE failed_grants ADD COLUMN IF NOT EXISTS grant_namespace VARCHAR(100) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN failed_grants.grant_namespace IS 'AGS namespace of the failed grant; empty means namespace';
@@ -0,0 +1,7 @@
+-- Per-step progress of multi-step goals (goals with "requirements" in the
+-- challenge config), as a JSON array of step values in config order. progress
+-- mirrors the first step, so readers unaware of steps still see a value. NULL
+-- for goals with a single requirement and for step goals without progress yet.
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS step_progress JSONB NULL;
+
+COMMENT ON COLUMN user_goal_progress.step_progress IS 'Step values of a multi-step goal, in config order; NULL for single-requirement goals';
@@ -0,0 +1,7 @@
+UPDATE user_goal_progress SET activation_source = NULL WHERE activation_source = 'auto_expired';
+
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_activation_source;
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_activation_source
+    CHECK (activation_source IN ('default', 'random', 'manual', 'admin'));
+
+DROP INDEX IF EXISTS idx_user_goal_progress_abandoned;
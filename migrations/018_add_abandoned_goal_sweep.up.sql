@@ -0,0 +1,13 @@
+-- Abandoned goal sweep (autoDeactivateAfterDays in the challenge config): active
+-- rows without progress, oldest assignment first.
+-- Serves: WHERE namespace = $1 AND challenge_id = $2 AND is_active = true AND progress = 0
+--         AND assigned_at < $3 ORDER BY assigned_at LIMIT $5
+CREATE INDEX IF NOT EXISTS idx_user_goal_progress_abandoned
+ON user_goal_progress(namespace, challenge_id, assigned_at)
+WHERE is_active = true AND progress = 0;
+
+-- 'auto_expired' marks goals deactivated by the sweep. Unlike other deactivations,
+-- the sweep replaces the source, so clients can tell the player why the goal left.
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_activation_source;
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_activation_source
+    CHECK (activation_source IN ('default', 'random', 'manual', 'admin', 'auto_expired'));
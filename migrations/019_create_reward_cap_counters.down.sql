@@ -0,0 +1,2 @@
+ALTER TABLE claim_outbox DROP COLUMN IF EXISTS reward_cap_slot;
+DROP TABLE IF EXISTS reward_cap_counters;
@@ -0,0 +1,15 @@
+-- Reward caps ("first N claimants", rewardCap in the challenge config)
+-- One row per capped goal, created by its first claim. claimed counts the claims
+-- holding one of the goal's capped rewards, including claims still in flight.
+-- A claim takes a slot with a single UPDATE ... WHERE claimed < limit RETURNING,
+-- so concurrent claims never take more than limit slots.
+CREATE TABLE IF NOT EXISTS reward_cap_counters (
+    goal_id VARCHAR(100) PRIMARY KEY,
+    claimed INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT check_reward_cap_claimed CHECK (claimed >= 0)
+);
+
+-- Whether the claim in flight holds a slot of reward_cap_counters: it grants the
+-- capped reward, and gives the slot back if it is released without granting
+ALTER TABLE claim_outbox ADD COLUMN IF NOT EXISTS reward_cap_slot BOOLEAN NOT NULL DEFAULT false;
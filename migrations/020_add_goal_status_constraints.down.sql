@@ -0,0 +1,2 @@
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_claimed_at_only_when_claimed;
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_claimed_has_claimed_at;
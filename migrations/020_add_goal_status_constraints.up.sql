@@ -0,0 +1,14 @@
+-- Backstop for the goal status state machine (pkg/progressstate): a row is
+-- claimed exactly when it has a claimed_at. Every write path checks its status
+-- change before persisting, but bulk writes decided in SQL (extend-challenge-common)
+-- do not go through it.
+-- NOT VALID skips checking existing rows, so the migration does not scan the
+-- table; new and updated rows are checked. Run VALIDATE CONSTRAINT separately
+-- once existing rows are known to comply.
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_claimed_has_claimed_at;
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_claimed_has_claimed_at
+    CHECK (status <> 'claimed' OR claimed_at IS NOT NULL) NOT VALID;
+
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_claimed_at_only_when_claimed;
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_claimed_at_only_when_claimed
+    CHECK (claimed_at IS NULL OR status = 'claimed') NOT VALID;
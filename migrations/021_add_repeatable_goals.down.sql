@@ -0,0 +1,3 @@
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_times_claimed_non_negative;
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS times_claimed;
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS last_claimed_at;
@@ -0,0 +1,10 @@
+-- Repeatable goals ("repeatable" and "cooldownHours" in the challenge config)
+-- A claim of a repeatable goal resets its row to not_started and inactive, keeping
+-- when it was claimed and how often. The goal can be activated again once
+-- last_claimed_at + cooldown has passed.
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS last_claimed_at TIMESTAMP NULL;
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS times_claimed INT NOT NULL DEFAULT 0;
+
+ALTER TABLE user_goal_progress DROP CONSTRAINT IF EXISTS check_times_claimed_non_negative;
+ALTER TABLE user_goal_progress ADD CONSTRAINT check_times_claimed_non_negative
+    CHECK (times_claimed >= 0);
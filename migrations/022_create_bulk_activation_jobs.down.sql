@@ -0,0 +1,2 @@
+ALTER TABLE goal_admin_audit DROP COLUMN IF EXISTS bulk_activation_job_id;
+DROP TABLE IF EXISTS bulk_activation_jobs;
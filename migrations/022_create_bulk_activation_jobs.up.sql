@@ -0,0 +1,32 @@
+-- Admin bulk goal activation jobs (BulkActivateGoal)
+-- One row per job: the job activates a goal for every user in user_ids, sorted
+-- and without duplicates. users_processed is the cursor into user_ids, advanced
+-- in the transaction that activates the batch, so an interrupted or failed job
+-- resumes after the last batch. status is 'running', 'completed' or 'failed'.
+CREATE TABLE IF NOT EXISTS bulk_activation_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    goal_id VARCHAR(100) NOT NULL,
+    namespace VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    user_ids TEXT[] NOT NULL,
+    users_total BIGINT NOT NULL,
+    users_processed BIGINT NOT NULL DEFAULT 0,
+    users_activated BIGINT NOT NULL DEFAULT 0,
+    users_already_active BIGINT NOT NULL DEFAULT 0,
+    users_claimed BIGINT NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    actor_user_id VARCHAR(100) NOT NULL,
+    client_ip VARCHAR(45) NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP NULL
+);
+
+-- Serves the runner: WHERE namespace = $1 AND status = 'running'
+CREATE INDEX IF NOT EXISTS idx_bulk_activation_jobs_namespace_status
+ON bulk_activation_jobs(namespace, status);
+
+-- Job whose batch activated the row (action 'bulk_activate'). NULL for every
+-- other action.
+ALTER TABLE goal_admin_audit ADD COLUMN IF NOT EXISTS bulk_activation_job_id BIGINT NULL;
@@ -0,0 +1,25 @@
+CREATE OR REPLACE FUNCTION enqueue_goal_status_event() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'completed' THEN
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.completed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'progress', NEW.progress,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ), COALESCE(NEW.completed_at, NOW()));
+    ELSE
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.claimed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+            'claimedAt', to_char(NEW.claimed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ), COALESCE(NEW.claimed_at, NOW()));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE claim_outbox DROP COLUMN IF EXISTS client_context;
+ALTER TABLE goal_selection_events DROP COLUMN IF EXISTS client_context;
@@ -0,0 +1,35 @@
+-- Analytics metadata clients send with claims and selections (client_context)
+-- A JSON object of at most 5 string keys and values, NULL when the client sent
+-- none. goal_selection_events keeps it with the selection. claim_outbox holds
+-- it while the claim is in flight, so the trigger that writes the goal.claimed
+-- event, in the transaction that marks the goal claimed, can add it to the
+-- payload as clientContext.
+ALTER TABLE goal_selection_events ADD COLUMN IF NOT EXISTS client_context JSONB NULL;
+ALTER TABLE claim_outbox ADD COLUMN IF NOT EXISTS client_context JSONB NULL;
+
+CREATE OR REPLACE FUNCTION enqueue_goal_status_event() RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'completed' THEN
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.completed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'progress', NEW.progress,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ), COALESCE(NEW.completed_at, NOW()));
+    ELSE
+        -- Claims without an outbox entry or client context add no clientContext
+        INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at)
+        VALUES ('goal.claimed', NEW.namespace, NEW.user_id, jsonb_build_object(
+            'goalId', NEW.goal_id,
+            'challengeId', NEW.challenge_id,
+            'completedAt', to_char(NEW.completed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+            'claimedAt', to_char(NEW.claimed_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+        ) || jsonb_strip_nulls(jsonb_build_object('clientContext', (
+            SELECT client_context FROM claim_outbox
+            WHERE user_id = NEW.user_id AND goal_id = NEW.goal_id
+        ))), COALESCE(NEW.claimed_at, NOW()));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,6 @@
+-- Fails while encoded user IDs longer than 100 characters are stored
+ALTER TABLE backfill_jobs ALTER COLUMN last_user_id TYPE VARCHAR(100);
+ALTER TABLE event_outbox ALTER COLUMN user_id TYPE VARCHAR(100);
+ALTER TABLE goal_admin_audit ALTER COLUMN user_id TYPE VARCHAR(100);
+ALTER TABLE goal_selection_events ALTER COLUMN user_id TYPE VARCHAR(100);
+ALTER TABLE user_goal_progress ALTER COLUMN user_id TYPE VARCHAR(100);
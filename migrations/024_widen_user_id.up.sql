@@ -0,0 +1,8 @@
+-- Room for user IDs encoded by USER_ID_CODEC ("enc:<key ID>:<base64url>"): an
+-- AES-GCM encoding is about 4/3 of the plain ID plus 40 bytes. Widening a
+-- VARCHAR does not rewrite the table or its indexes.
+ALTER TABLE user_goal_progress ALTER COLUMN user_id TYPE VARCHAR(255);
+ALTER TABLE goal_selection_events ALTER COLUMN user_id TYPE VARCHAR(255);
+ALTER TABLE goal_admin_audit ALTER COLUMN user_id TYPE VARCHAR(255);
+ALTER TABLE event_outbox ALTER COLUMN user_id TYPE VARCHAR(255);
+ALTER TABLE backfill_jobs ALTER COLUMN last_user_id TYPE VARCHAR(255);
@@ -0,0 +1 @@
+ALTER TABLE user_goal_progress DROP COLUMN IF EXISTS first_completed_at;
@@ -0,0 +1,9 @@
+-- When the user first completed a goal (first_completed_at)
+-- completed_at belongs to the row's current completion: it is set once when the
+-- row becomes completed and kept by later writes, but a reset (a claimed
+-- repeatable goal, an abandoned goal, a rotation, a step or admin regression)
+-- clears it so the next completion gets its own time. Those writes move the
+-- completion into first_completed_at first, unless it is already set, so the
+-- first completion survives them. NULL means the first completion is
+-- completed_at: rows never reset need no backfill.
+ALTER TABLE user_goal_progress ADD COLUMN IF NOT EXISTS first_completed_at TIMESTAMP NULL;
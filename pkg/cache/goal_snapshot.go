@@ -0,0 +1,130 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package cache
+
+import (
+	"errors"
+	"sync/atomic"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// errSnapshotViewReadOnly is returned by SnapshotView.Reload.
+var errSnapshotViewReadOnly = errors.New("snapshot view is read-only; reload the goal cache instead")
+
+// SnapshotView is a read-only copy of a goal cache's lookups, taken once per
+// config load and shared by every request until the next one.
+//
+// The common InMemoryGoalCache takes its read lock on every call, so request
+// handlers that look up each goal of a large challenge in a loop contend on
+// that lock under parallel load. A SnapshotView holds the same indexes in plain
+// maps that are never written after NewSnapshotView, so lookups take no lock.
+//
+// SnapshotView implements GoalCache, so it can be passed to any helper that
+// takes one. A request obtains the current view once, from GoalSnapshots.View,
+// and uses it throughout, so all of its lookups see the same config even if a
+// reload happens mid-request.
+type SnapshotView struct {
+	challenges      []*domain.Challenge
+	challengesByID  map[string]*domain.Challenge
+	goals           []*domain.Goal
+	goalsByID       map[string]*domain.Goal
+	goalsByStatCode map[string][]*domain.Goal
+	defaultGoals    []*domain.Goal
+}
+
+// NewSnapshotView copies the lookups of goalCache, in the order of its
+// challenges and their goals.
+func NewSnapshotView(goalCache commonCache.GoalCache) *SnapshotView {
+	challenges := goalCache.GetAllChallenges()
+	v := &SnapshotView{
+		challenges:      challenges,
+		challengesByID:  make(map[string]*domain.Challenge, len(challenges)),
+		goalsByID:       make(map[string]*domain.Goal),
+		goalsByStatCode: make(map[string][]*domain.Goal),
+		defaultGoals:    make([]*domain.Goal, 0),
+	}
+
+	for _, challenge := range challenges {
+		v.challengesByID[challenge.ID] = challenge
+		for _, goal := range challenge.Goals {
+			v.goals = append(v.goals, goal)
+			v.goalsByID[goal.ID] = goal
+			v.goalsByStatCode[goal.Requirement.StatCode] = append(v.goalsByStatCode[goal.Requirement.StatCode], goal)
+			if goal.DefaultAssigned {
+				v.defaultGoals = append(v.defaultGoals, goal)
+			}
+		}
+	}
+
+	return v
+}
+
+// GetGoalByID returns the goal with the given ID, or nil.
+func (v *SnapshotView) GetGoalByID(goalID string) *domain.Goal {
+	return v.goalsByID[goalID]
+}
+
+// GetGoalsByStatCode returns the goals tracking statCode.
+func (v *SnapshotView) GetGoalsByStatCode(statCode string) []*domain.Goal {
+	return v.goalsByStatCode[statCode]
+}
+
+// GetChallengeByChallengeID returns the challenge with the given ID, or nil.
+func (v *SnapshotView) GetChallengeByChallengeID(challengeID string) *domain.Challenge {
+	return v.challengesByID[challengeID]
+}
+
+// GetAllChallenges returns the challenges in config order.
+func (v *SnapshotView) GetAllChallenges() []*domain.Challenge {
+	return v.challenges
+}
+
+// GetAllGoals returns the goals of all challenges in config order. Callers
+// must not modify the returned slice.
+func (v *SnapshotView) GetAllGoals() []*domain.Goal {
+	return v.goals
+}
+
+// GetGoalsWithDefaultAssigned returns the goals assigned to new players, in
+// config order. Callers must not modify the returned slice.
+func (v *SnapshotView) GetGoalsWithDefaultAssigned() []*domain.Goal {
+	return v.defaultGoals
+}
+
+// Reload fails: a view never changes. Reload the goal cache it was taken from
+// and refresh GoalSnapshots instead.
+func (v *SnapshotView) Reload() error {
+	return errSnapshotViewReadOnly
+}
+
+// GoalSnapshots holds the SnapshotView of a goal cache's current config.
+//
+// Refresh must be called after every reload of the goal cache (see
+// service.ConfigReloader); until then, View returns the previous config.
+// Thread-safety: View and Refresh are safe for concurrent use.
+type GoalSnapshots struct {
+	goalCache commonCache.GoalCache
+	current   atomic.Pointer[SnapshotView]
+}
+
+// NewGoalSnapshots creates the snapshots of goalCache, with a view of its
+// current config.
+func NewGoalSnapshots(goalCache commonCache.GoalCache) *GoalSnapshots {
+	s := &GoalSnapshots{goalCache: goalCache}
+	s.Refresh()
+	return s
+}
+
+// Refresh takes a new view of the goal cache.
+func (s *GoalSnapshots) Refresh() {
+	s.current.Store(NewSnapshotView(s.goalCache))
+}
+
+// View returns the current view, to be used for the whole request.
+func (s *GoalSnapshots) View() *SnapshotView {
+	return s.current.Load()
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSnapshotTestChallenges returns one challenge per entry of goalCounts, with
+// that many goals; every other goal is default-assigned.
+func newSnapshotTestChallenges(goalCounts ...int) []*domain.Challenge {
+	challenges := make([]*domain.Challenge, 0, len(goalCounts))
+	for c, count := range goalCounts {
+		challenge := &domain.Challenge{ID: fmt.Sprintf("challenge-%d", c), Name: "Challenge"}
+		for g := 0; g < count; g++ {
+			challenge.Goals = append(challenge.Goals, &domain.Goal{
+				ID:              fmt.Sprintf("challenge-%d-goal-%04d", c, g),
+				ChallengeID:     challenge.ID,
+				Name:            "Goal",
+				DefaultAssigned: g%2 == 0,
+				Requirement:     domain.Requirement{StatCode: fmt.Sprintf("stat-%d", g%3), Operator: ">=", TargetValue: 10},
+			})
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges
+}
+
+func TestSnapshotView_MatchesGoalCache(t *testing.T) {
+	challenges := newSnapshotTestChallenges(5, 3)
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+
+	view := NewSnapshotView(goalCache)
+
+	assert.Equal(t, goalCache.GetAllChallenges(), view.GetAllChallenges())
+	assert.ElementsMatch(t, goalCache.GetAllGoals(), view.GetAllGoals())
+	assert.ElementsMatch(t, goalCache.GetGoalsWithDefaultAssigned(), view.GetGoalsWithDefaultAssigned())
+	for _, challenge := range challenges {
+		assert.Same(t, challenge, view.GetChallengeByChallengeID(challenge.ID))
+		for _, goal := range challenge.Goals {
+			assert.Same(t, goal, view.GetGoalByID(goal.ID))
+		}
+	}
+	assert.Equal(t, goalCache.GetGoalsByStatCode("stat-1"), view.GetGoalsByStatCode("stat-1"))
+	assert.Nil(t, view.GetGoalByID("missing"))
+	assert.Nil(t, view.GetChallengeByChallengeID("missing"))
+	assert.Error(t, view.Reload())
+}
+
+func TestGoalSnapshots_Refresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	writeConfig := func(goalID string) {
+		data, err := json.Marshal(&commonConfig.Config{Challenges: []*domain.Challenge{{
+			ID: "daily", Name: "Daily",
+			Goals: []*domain.Goal{{
+				ID: goalID, Name: "Goal", ChallengeID: "daily", EventSource: domain.EventSourceStatistic,
+				Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+				Reward:      domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 1},
+			}},
+		}}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+	writeConfig("goal-a")
+	cfg, err := commonConfig.NewConfigLoader(path, slog.Default()).LoadConfig()
+	require.NoError(t, err)
+	goalCache := commonCache.NewInMemoryGoalCache(cfg, path, slog.Default())
+
+	snapshots := NewGoalSnapshots(goalCache)
+	before := snapshots.View()
+	require.NotNil(t, before.GetGoalByID("goal-a"))
+
+	writeConfig("goal-b")
+	require.NoError(t, goalCache.Reload())
+
+	// A view taken before the reload keeps the old config until Refresh
+	assert.Same(t, before, snapshots.View())
+	assert.NotNil(t, before.GetGoalByID("goal-a"))
+
+	snapshots.Refresh()
+	after := snapshots.View()
+	assert.Nil(t, after.GetGoalByID("goal-a"))
+	assert.NotNil(t, after.GetGoalByID("goal-b"))
+	assert.NotNil(t, before.GetGoalByID("goal-a"), "views in use are not modified")
+}
+
+// benchmarkGoalLookups looks up every goal of a 1,000-goal challenge per
+// iteration, as the challenge handlers do for a user with full progress, from
+// GOMAXPROCS goroutines at once. lookupsFor returns what one request looks goals up in.
+func benchmarkGoalLookups(b *testing.B, lookupsFor func(commonCache.GoalCache) func() commonCache.GoalCache) {
+	challenges := newSnapshotTestChallenges(1000)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", logger)
+	goalIDs := make([]string, 0, len(challenges[0].Goals))
+	for _, goal := range challenges[0].Goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+
+	requestLookups := lookupsFor(goalCache)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lookups := requestLookups()
+			for _, goalID := range goalIDs {
+				if lookups.GetGoalByID(goalID) == nil {
+					b.Fatalf("goal %s not found", goalID)
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkGoalLookups_GoalCache takes the goal cache's read lock on every lookup.
+func BenchmarkGoalLookups_GoalCache(b *testing.B) {
+	benchmarkGoalLookups(b, func(goalCache commonCache.GoalCache) func() commonCache.GoalCache {
+		return func() commonCache.GoalCache { return goalCache }
+	})
+}
+
+// BenchmarkGoalLookups_SnapshotView obtains the current view once per request,
+// as the handlers do with GoalSnapshots set, and takes no lock.
+func BenchmarkGoalLookups_SnapshotView(b *testing.B) {
+	benchmarkGoalLookups(b, func(goalCache commonCache.GoalCache) func() commonCache.GoalCache {
+		snapshots := NewGoalSnapshots(goalCache)
+		return func() commonCache.GoalCache { return snapshots.View() }
+	})
+}
@@ -5,11 +5,15 @@
 package cache
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	pb "extend-challenge-service/pkg/pb"
 )
@@ -20,6 +24,14 @@ import (
 // at startup instead of marshaling them on every request. Since challenges are static
 // (only user progress changes), this optimization can reduce marshaling overhead by ~40%.
 //
+// Data is stored as addressable fragments rather than whole challenge documents:
+//   - one ChallengeFragment per challenge (the challenge fields plus its goal IDs)
+//   - one goal fragment per goal (GetGoalFragment), with a slot for the user's
+//     progress fields
+//
+// Responses for any set of challenges (the full list, a page, a single challenge)
+// are assembled from the same fragments, see response.ChallengeResponseBuilder.
+//
 // Performance impact (expected at 200 RPS):
 //   - CPU reduction: ~40% (protojson marshaling overhead eliminated for static data)
 //   - Memory: ~100KB for cached JSON (negligible)
@@ -27,19 +39,71 @@ import (
 //
 // Thread-safety: Uses RWMutex for concurrent access (many readers, rare writers)
 type SerializedChallengeCache struct {
-	mu         sync.RWMutex
-	challenges map[string][]byte // challengeID -> pre-serialized JSON
-	goals      map[string][]byte // goalID -> pre-serialized JSON
-	goalCounts map[string]int    // challengeID -> goal count
-	marshaler  protojson.MarshalOptions
+	mu        sync.RWMutex
+	fragments map[string]*ChallengeFragment       // challengeID -> challenge fragment
+	goals     map[goalKey]GoalFragment            // (challengeID, goalID) -> pre-serialized goal
+	hidden    map[string]bool                     // goalID -> hidden until unlocked (see SetHiddenGoals)
+	targets   map[string]map[string]int           // goalID -> segment -> target (see SetTargetOverrides)
+	segments  map[string]map[goalKey]GoalFragment // segment -> (challengeID, goalID) -> pre-serialized goal with the segment's target
+	marshaler protojson.MarshalOptions
+}
+
+// goalKey identifies a goal fragment by its challenge and goal IDs, so a goal
+// ID reused by another challenge cannot replace the fragment of the first.
+type goalKey struct {
+	challengeID string
+	goalID      string
+}
+
+// GoalFragment is a pre-serialized goal without user progress.
+//
+// The progress fields of a goal are written at Slot, right before the closing
+// brace of JSON: a goal with progress is JSON[:Slot], the fields, then
+// JSON[Slot:]. Responses never re-encode the static part.
+type GoalFragment struct {
+	// JSON is the goal object as protojson writes it without progress
+	JSON []byte
+	// Slot is the offset in JSON where the progress fields go
+	Slot int
+	// HasPrerequisites reports whether the goal lists prerequisites, which
+	// locks it while the user has no progress
+	HasPrerequisites bool
+}
+
+// ChallengeFragment is the pre-serialized part of a challenge that is not a goal.
+//
+// A challenge document is assembled as Header, then `,"goals":[`, the goal
+// fragments joined by commas, and `]}`. A challenge without goals is Header
+// followed by `}`, matching protojson, which omits empty repeated fields.
+type ChallengeFragment struct {
+	// Header is the challenge JSON object without its goals and closing brace,
+	// e.g. {"challengeId":"daily","name":"Daily"
+	Header []byte
+	// GoalIDs lists the goals in the static challenge JSON, in config order.
+	// Hidden goals are not listed.
+	GoalIDs []string
+	// Size is the length of the challenge assembled with its listed goals and
+	// without user progress, which only adds to it.
+	Size int
+}
+
+// SerializedCacheStats describes the cache contents for monitoring.
+type SerializedCacheStats struct {
+	// ChallengeFragments is the number of challenges in cache
+	ChallengeFragments int
+	// GoalFragments is the number of goals in cache, hidden goals included
+	GoalFragments int
+	// ListedGoals is the number of goal references across challenge fragments
+	ListedGoals int
+	// TotalBytes is the total size of cached JSON in bytes
+	TotalBytes int
 }
 
 // NewSerializedChallengeCache creates a new serialized challenge cache.
 func NewSerializedChallengeCache() *SerializedChallengeCache {
 	return &SerializedChallengeCache{
-		challenges: make(map[string][]byte),
-		goals:      make(map[string][]byte),
-		goalCounts: make(map[string]int),
+		fragments: make(map[string]*ChallengeFragment),
+		goals:     make(map[goalKey]GoalFragment),
 		marshaler: protojson.MarshalOptions{
 			UseProtoNames:   false, // Use camelCase (default) instead of proto snake_case names
 			EmitUnpopulated: false,
@@ -49,11 +113,32 @@ func NewSerializedChallengeCache() *SerializedChallengeCache {
 	}
 }
 
+// SetHiddenGoals marks goals that are hidden until the user unlocks them.
+//
+// Hidden goals are still pre-serialized individually (GetGoalFragment), so handlers can
+// append them for users who unlocked them, but they are left out of the challenge
+// fragments and goal counts. Call it before WarmUp or Refresh.
+func (c *SerializedChallengeCache) SetHiddenGoals(hidden map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hidden = hidden
+}
+
+// SetTargetOverrides sets the per-segment goal targets (goal ID -> segment ->
+// target). Each overridden goal is also pre-serialized with each of its segment
+// targets, served by GetGoalFragment. Call it before WarmUp or Refresh.
+func (c *SerializedChallengeCache) SetTargetOverrides(targets map[string]map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.targets = targets
+}
+
 // WarmUp pre-serializes all challenges and goals at startup.
 //
 // This method should be called once during application initialization with all
 // challenges loaded from the configuration file. It pre-marshals each challenge
-// and goal to JSON, storing the results in memory for fast lookup during requests.
+// and goal to JSON fragments, storing the results in memory for fast lookup during
+// requests.
 //
 // Args:
 //   - challenges: All challenges from the configuration file (without user progress)
@@ -67,67 +152,208 @@ func (c *SerializedChallengeCache) WarmUp(challenges []*pb.Challenge) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	fragments, goals, err := c.serialize(challenges, c.hidden)
+	if err != nil {
+		return err
+	}
+	segments, err := c.serializeSegmentGoals(challenges, c.targets)
+	if err != nil {
+		return err
+	}
+	c.segments = segments
+
+	for challengeID, fragment := range fragments {
+		c.fragments[challengeID] = fragment
+	}
+	for key, goal := range goals {
+		c.goals[key] = goal
+	}
+
+	return nil
+}
+
+// Refresh rebuilds the cache with new challenges.
+//
+// This method should be called if the challenge configuration changes at runtime.
+// It's thread-safe and will atomically replace the entire cache.
+//
+// Args:
+//   - challenges: New challenges to cache
+//
+// Returns:
+//   - error: If any challenge or goal fails to marshal
+//
+// Note: In production, this should be coordinated with config file monitoring
+// (e.g., using fsnotify) to automatically refresh when config changes.
+func (c *SerializedChallengeCache) Refresh(challenges []*pb.Challenge) error {
+	c.mu.RLock()
+	hidden := c.hidden
+	targets := c.targets
+	c.mu.RUnlock()
+
+	fragments, goals, err := c.serialize(challenges, hidden)
+	if err != nil {
+		return fmt.Errorf("failed to refresh serialization cache: %w", err)
+	}
+	segments, err := c.serializeSegmentGoals(challenges, targets)
+	if err != nil {
+		return fmt.Errorf("failed to refresh serialization cache: %w", err)
+	}
+
+	// Atomically replace the cache
+	c.mu.Lock()
+	c.fragments = fragments
+	c.goals = goals
+	c.segments = segments
+	c.mu.Unlock()
+
+	return nil
+}
+
+// serialize builds the challenge and goal fragments for challenges.
+func (c *SerializedChallengeCache) serialize(
+	challenges []*pb.Challenge,
+	hidden map[string]bool,
+) (map[string]*ChallengeFragment, map[goalKey]GoalFragment, error) {
+	fragments := make(map[string]*ChallengeFragment, len(challenges))
+	goals := make(map[goalKey]GoalFragment)
+
 	for _, challenge := range challenges {
 		if challenge == nil {
 			continue
 		}
 
-		// Store goal count for optimal buffer sizing
-		c.goalCounts[challenge.ChallengeId] = len(challenge.Goals)
-
 		// Pre-serialize each goal (without user progress - will be injected later)
 		for _, goal := range challenge.Goals {
 			if goal == nil {
 				continue
 			}
 
-			// Create a copy of the goal with default progress values
-			// This is what we'll serialize and store in cache
-			goalTemplate := &pb.Goal{
-				GoalId:        goal.GoalId,
-				Name:          goal.Name,
-				Description:   goal.Description,
-				Requirement:   goal.Requirement,
-				Reward:        goal.Reward,
-				Prerequisites: goal.Prerequisites,
-				// Progress fields left at defaults (will be injected at request time):
-				Progress:    0,
-				Status:      "",
-				Locked:      false,
-				CompletedAt: "",
-				ClaimedAt:   "",
-			}
-
-			goalJSON, err := c.marshaler.Marshal(goalTemplate)
+			fragment, err := c.marshalGoal(goal, goal.Requirement)
 			if err != nil {
-				return fmt.Errorf("failed to pre-serialize goal %s: %w", goal.GoalId, err)
+				return nil, nil, err
 			}
-			c.goals[goal.GoalId] = goalJSON
+			goals[goalKey{challenge.ChallengeId, goal.GoalId}] = fragment
 		}
 
-		// Pre-serialize challenge (with goal references)
-		// Note: The goals in the serialized challenge will have default progress values
-		// We'll inject actual progress values at request time
-		challengeTemplate := &pb.Challenge{
+		// Pre-serialize the challenge without goals; they are assembled from the
+		// goal fragments at request time
+		challengeJSON, err := c.marshaler.Marshal(&pb.Challenge{
 			ChallengeId: challenge.ChallengeId,
 			Name:        challenge.Name,
 			Description: challenge.Description,
-			Goals:       challenge.Goals, // Goals with default progress
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to pre-serialize challenge %s: %w", challenge.ChallengeId, err)
 		}
 
-		challengeJSON, err := c.marshaler.Marshal(challengeTemplate)
-		if err != nil {
-			return fmt.Errorf("failed to pre-serialize challenge %s: %w", challenge.ChallengeId, err)
+		listedGoals := visibleGoals(challenge.Goals, hidden)
+		goalIDs := make([]string, 0, len(listedGoals))
+		for _, goal := range listedGoals {
+			if goal != nil {
+				goalIDs = append(goalIDs, goal.GoalId)
+			}
 		}
-		c.challenges[challenge.ChallengeId] = challengeJSON
+
+		fragment := &ChallengeFragment{
+			Header:  bytes.TrimSuffix(bytes.TrimSpace(challengeJSON), []byte("}")),
+			GoalIDs: goalIDs,
+		}
+		fragment.Size = fragment.assembledSize(challenge.ChallengeId, goals)
+		fragments[challenge.ChallengeId] = fragment
 	}
 
-	return nil
+	return fragments, goals, nil
+}
+
+// serializeSegmentGoals pre-serializes each overridden goal with each of its
+// segment targets.
+func (c *SerializedChallengeCache) serializeSegmentGoals(
+	challenges []*pb.Challenge,
+	targets map[string]map[string]int,
+) (map[string]map[goalKey]GoalFragment, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	segments := make(map[string]map[goalKey]GoalFragment)
+	for _, challenge := range challenges {
+		if challenge == nil {
+			continue
+		}
+		for _, goal := range challenge.Goals {
+			if goal == nil || goal.Requirement == nil {
+				continue
+			}
+			for segment, target := range targets[goal.GoalId] {
+				requirement := proto.Clone(goal.Requirement).(*pb.Requirement)
+				requirement.TargetValue = int32(target) // #nosec G115 - targets are validated at config load time
+
+				fragment, err := c.marshalGoal(goal, requirement)
+				if err != nil {
+					return nil, err
+				}
+				if segments[segment] == nil {
+					segments[segment] = make(map[goalKey]GoalFragment)
+				}
+				segments[segment][goalKey{challenge.ChallengeId, goal.GoalId}] = fragment
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// marshalGoal pre-serializes a goal with the given requirement and without user progress.
+func (c *SerializedChallengeCache) marshalGoal(goal *pb.Goal, requirement *pb.Requirement) (GoalFragment, error) {
+	// Create a copy of the goal with default progress values
+	// This is what we'll serialize and store in cache
+	goalTemplate := &pb.Goal{
+		GoalId:        goal.GoalId,
+		Name:          goal.Name,
+		Description:   goal.Description,
+		Requirement:   requirement,
+		Reward:        goal.Reward,
+		Prerequisites: goal.Prerequisites,
+		// Progress fields left at defaults (will be injected at request time):
+		Progress:    0,
+		Status:      "",
+		Locked:      false,
+		CompletedAt: nil,
+		ClaimedAt:   nil,
+	}
+
+	goalJSON, err := c.marshaler.Marshal(goalTemplate)
+	if err != nil {
+		return GoalFragment{}, fmt.Errorf("failed to pre-serialize goal %s: %w", goal.GoalId, err)
+	}
+	slot := bytes.LastIndexByte(goalJSON, '}')
+	if slot == -1 {
+		return GoalFragment{}, fmt.Errorf("failed to pre-serialize goal %s: no closing brace", goal.GoalId)
+	}
+	return GoalFragment{JSON: goalJSON, Slot: slot, HasPrerequisites: len(goal.Prerequisites) > 0}, nil
+}
+
+// visibleGoals returns the goals listed in the challenge fragment.
+func visibleGoals(goals []*pb.Goal, hidden map[string]bool) []*pb.Goal {
+	if len(hidden) == 0 {
+		return goals
+	}
+
+	visible := make([]*pb.Goal, 0, len(goals))
+	for _, goal := range goals {
+		if goal != nil && hidden[goal.GoalId] {
+			continue
+		}
+		visible = append(visible, goal)
+	}
+	return visible
 }
 
 // GetGoalJSON returns pre-serialized goal JSON.
 //
 // Args:
+//   - challengeID: The challenge the goal belongs to
 //   - goalID: The unique identifier for the goal
 //
 // Returns:
@@ -135,129 +361,162 @@ func (c *SerializedChallengeCache) WarmUp(challenges []*pb.Challenge) error {
 //   - bool: True if goal was found in cache, false otherwise
 //
 // Thread-safety: Safe for concurrent access (read lock)
-func (c *SerializedChallengeCache) GetGoalJSON(goalID string) ([]byte, bool) {
+func (c *SerializedChallengeCache) GetGoalJSON(challengeID, goalID string) ([]byte, bool) {
+	fragment, ok := c.GetGoalFragment(challengeID, goalID, "")
+	return fragment.JSON, ok
+}
+
+// GetSegmentGoalJSON returns the pre-serialized goal JSON with the segment's
+// target, or the GetGoalJSON JSON when the goal has no override for the segment.
+//
+// Thread-safety: Safe for concurrent access (read lock)
+func (c *SerializedChallengeCache) GetSegmentGoalJSON(challengeID, goalID, segment string) ([]byte, bool) {
+	fragment, ok := c.GetGoalFragment(challengeID, goalID, segment)
+	return fragment.JSON, ok
+}
+
+// GetGoalFragment returns the pre-serialized goal with the segment's target,
+// or with the configured target for segment "" and goals without an override
+// for the segment. The fragment must not be modified.
+//
+// Thread-safety: Safe for concurrent access (read lock)
+func (c *SerializedChallengeCache) GetGoalFragment(challengeID, goalID, segment string) (GoalFragment, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	jsonData, ok := c.goals[goalID]
-	return jsonData, ok
+	key := goalKey{challengeID, goalID}
+	if segment != "" {
+		if fragment, ok := c.segments[segment][key]; ok {
+			return fragment, true
+		}
+	}
+	fragment, ok := c.goals[key]
+	return fragment, ok
 }
 
-// GetChallengeJSON returns pre-serialized challenge JSON.
+// GetChallengeFragment returns the pre-serialized challenge fragment.
 //
 // Args:
 //   - challengeID: The unique identifier for the challenge
 //
 // Returns:
-//   - []byte: Pre-serialized JSON for the challenge (with goals, but without user progress)
+//   - *ChallengeFragment: Challenge header and listed goal IDs (must not be modified)
 //   - bool: True if challenge was found in cache, false otherwise
 //
-// Thread-safety: Safe for concurrent access (read lock)
-func (c *SerializedChallengeCache) GetChallengeJSON(challengeID string) ([]byte, bool) {
+// Thread-safety: Safe for concurrent access (read lock). Fragments are never
+// modified after they are built; Refresh replaces them.
+func (c *SerializedChallengeCache) GetChallengeFragment(challengeID string) (*ChallengeFragment, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	jsonData, ok := c.challenges[challengeID]
-	return jsonData, ok
+	fragment, ok := c.fragments[challengeID]
+	return fragment, ok
 }
 
-// Refresh rebuilds the cache with new challenges.
-//
-// This method should be called if the challenge configuration changes at runtime.
-// It's thread-safe and will atomically replace the entire cache.
+// GetChallengeJSON returns the static challenge JSON assembled from its fragments.
 //
 // Args:
-//   - challenges: New challenges to cache
+//   - challengeID: The unique identifier for the challenge
 //
 // Returns:
-//   - error: If any challenge or goal fails to marshal
+//   - []byte: JSON for the challenge (with listed goals, but without user progress)
+//   - bool: True if challenge was found in cache, false otherwise
 //
-// Note: In production, this should be coordinated with config file monitoring
-// (e.g., using fsnotify) to automatically refresh when config changes.
-func (c *SerializedChallengeCache) Refresh(challenges []*pb.Challenge) error {
-	// Create new maps for atomic replacement
-	newChallenges := make(map[string][]byte)
-	newGoals := make(map[string][]byte)
-	newGoalCounts := make(map[string]int)
-
-	// Pre-serialize all challenges and goals (same logic as WarmUp)
-	for _, challenge := range challenges {
-		if challenge == nil {
-			continue
-		}
+// Thread-safety: Safe for concurrent access (read lock)
+func (c *SerializedChallengeCache) GetChallengeJSON(challengeID string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-		// Store goal count for optimal buffer sizing
-		newGoalCounts[challenge.ChallengeId] = len(challenge.Goals)
+	fragment, ok := c.fragments[challengeID]
+	if !ok {
+		return nil, false
+	}
 
-		for _, goal := range challenge.Goals {
-			if goal == nil {
-				continue
-			}
+	goals := make([][]byte, 0, len(fragment.GoalIDs))
+	for _, goalID := range fragment.GoalIDs {
+		goals = append(goals, c.goals[goalKey{challengeID, goalID}].JSON)
+	}
+	return fragment.Assemble(goals), true
+}
 
-			goalTemplate := &pb.Goal{
-				GoalId:        goal.GoalId,
-				Name:          goal.Name,
-				Description:   goal.Description,
-				Requirement:   goal.Requirement,
-				Reward:        goal.Reward,
-				Prerequisites: goal.Prerequisites,
-				Progress:      0,
-				Status:        "",
-				Locked:        false,
-				CompletedAt:   "",
-				ClaimedAt:     "",
-			}
+// Assemble joins the fragment with the given goal JSON objects.
+func (f *ChallengeFragment) Assemble(goals [][]byte) []byte {
+	size := len(f.Header) + len(`,"goals":[]}`)
+	for _, goal := range goals {
+		size += len(goal) + 1
+	}
 
-			goalJSON, err := c.marshaler.Marshal(goalTemplate)
-			if err != nil {
-				return fmt.Errorf("failed to pre-serialize goal %s during refresh: %w", goal.GoalId, err)
-			}
-			newGoals[goal.GoalId] = goalJSON
-		}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	f.WriteJSON(buf, goals)
+	return buf.Bytes()
+}
 
-		challengeTemplate := &pb.Challenge{
-			ChallengeId: challenge.ChallengeId,
-			Name:        challenge.Name,
-			Description: challenge.Description,
-			Goals:       challenge.Goals,
-		}
+// assembledSize returns the length of the fragment assembled with its listed
+// goals, looked up in goals under challengeID, as WriteJSON writes it.
+func (f *ChallengeFragment) assembledSize(challengeID string, goals map[goalKey]GoalFragment) int {
+	if len(f.GoalIDs) == 0 {
+		return len(f.Header) + 1
+	}
 
-		challengeJSON, err := c.marshaler.Marshal(challengeTemplate)
-		if err != nil {
-			return fmt.Errorf("failed to pre-serialize challenge %s during refresh: %w", challenge.ChallengeId, err)
-		}
-		newChallenges[challenge.ChallengeId] = challengeJSON
+	size := len(f.Header) + len(`"goals":[]}`) + len(f.GoalIDs) - 1
+	if len(f.Header) > 1 {
+		size++
+	}
+	for _, goalID := range f.GoalIDs {
+		size += len(goals[goalKey{challengeID, goalID}].JSON)
 	}
+	return size
+}
 
-	// Atomically replace the cache
-	c.mu.Lock()
-	c.challenges = newChallenges
-	c.goals = newGoals
-	c.goalCounts = newGoalCounts
-	c.mu.Unlock()
+// WriteJSON writes the fragment with the given goal JSON objects to buf.
+func (f *ChallengeFragment) WriteJSON(buf *bytes.Buffer, goals [][]byte) {
+	buf.Write(f.Header)
+	if len(goals) == 0 {
+		buf.WriteByte('}')
+		return
+	}
 
-	return nil
+	if len(f.Header) > 1 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"goals":[`)
+	for i, goal := range goals {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(goal)
+	}
+	buf.WriteString(`]}`)
 }
 
 // GetStats returns cache statistics for monitoring.
-//
-// Returns:
-//   - challengeCount: Number of challenges in cache
-//   - goalCount: Number of goals in cache
-//   - totalBytes: Total size of cached JSON in bytes (approximate)
-func (c *SerializedChallengeCache) GetStats() (challengeCount, goalCount, totalBytes int) {
+func (c *SerializedChallengeCache) GetStats() SerializedCacheStats {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	challengeCount = len(c.challenges)
-	goalCount = len(c.goals)
+	stats := SerializedCacheStats{
+		ChallengeFragments: len(c.fragments),
+		GoalFragments:      len(c.goals),
+	}
 
-	for _, data := range c.challenges {
-		totalBytes += len(data)
+	for _, fragment := range c.fragments {
+		stats.ListedGoals += len(fragment.GoalIDs)
+		stats.TotalBytes += len(fragment.Header)
+	}
+	for _, goal := range c.goals {
+		stats.TotalBytes += len(goal.JSON)
 	}
-	for _, data := range c.goals {
-		totalBytes += len(data)
+
+	return stats
+}
+
+// Check reports the cache as unhealthy when it holds no pre-serialized data,
+// e.g. when warm-up or a refresh produced an empty cache.
+func (c *SerializedChallengeCache) Check(ctx context.Context) error {
+	stats := c.GetStats()
+	if stats.ChallengeFragments == 0 || stats.TotalBytes == 0 {
+		return errors.New("serialization cache is empty")
 	}
 
-	return
+	return nil
 }
 
 // ParseAndMerge is a helper method that parses pre-serialized JSON and merges it with user progress.
@@ -299,5 +558,8 @@ func (c *SerializedChallengeCache) ParseAndMerge(
 func (c *SerializedChallengeCache) GetGoalCount(challengeID string) int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.goalCounts[challengeID]
+	if fragment, ok := c.fragments[challengeID]; ok {
+		return len(fragment.GoalIDs)
+	}
+	return 0
 }
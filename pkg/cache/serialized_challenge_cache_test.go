@@ -5,6 +5,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -83,9 +84,9 @@ func TestNewSerializedChallengeCache(t *testing.T) {
 	cache := NewSerializedChallengeCache()
 
 	assert.NotNil(t, cache)
-	assert.NotNil(t, cache.challenges)
+	assert.NotNil(t, cache.fragments)
 	assert.NotNil(t, cache.goals)
-	assert.Equal(t, 0, len(cache.challenges), "Cache should be empty initially")
+	assert.Equal(t, 0, len(cache.fragments), "Cache should be empty initially")
 	assert.Equal(t, 0, len(cache.goals), "Cache should be empty initially")
 }
 
@@ -107,15 +108,15 @@ func TestWarmUp_Success(t *testing.T) {
 	assert.NotEmpty(t, challengeJSON2)
 
 	// Verify all goals are cached
-	goal1JSON, ok := cache.GetGoalJSON("goal1")
+	goal1JSON, ok := cache.GetGoalJSON("challenge1", "goal1")
 	assert.True(t, ok, "Goal1 should be in cache")
 	assert.NotEmpty(t, goal1JSON)
 
-	goal2JSON, ok := cache.GetGoalJSON("goal2")
+	goal2JSON, ok := cache.GetGoalJSON("challenge1", "goal2")
 	assert.True(t, ok, "Goal2 should be in cache")
 	assert.NotEmpty(t, goal2JSON)
 
-	goal3JSON, ok := cache.GetGoalJSON("goal3")
+	goal3JSON, ok := cache.GetGoalJSON("challenge2", "goal3")
 	assert.True(t, ok, "Goal3 should be in cache")
 	assert.NotEmpty(t, goal3JSON)
 }
@@ -126,9 +127,9 @@ func TestWarmUp_EmptyChallenges(t *testing.T) {
 	err := cache.WarmUp([]*pb.Challenge{})
 
 	require.NoError(t, err)
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Equal(t, 0, challengeCount)
-	assert.Equal(t, 0, goalCount)
+	stats := cache.GetStats()
+	assert.Equal(t, 0, stats.ChallengeFragments)
+	assert.Equal(t, 0, stats.GoalFragments)
 }
 
 func TestWarmUp_NilChallenges(t *testing.T) {
@@ -158,9 +159,9 @@ func TestWarmUp_NilChallenges(t *testing.T) {
 	_, ok := cache.GetChallengeJSON("challenge1")
 	assert.True(t, ok, "Valid challenge should be cached")
 
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Equal(t, 1, challengeCount)
-	assert.Equal(t, 1, goalCount)
+	stats := cache.GetStats()
+	assert.Equal(t, 1, stats.ChallengeFragments)
+	assert.Equal(t, 1, stats.GoalFragments)
 }
 
 func TestWarmUp_NilGoals(t *testing.T) {
@@ -190,12 +191,12 @@ func TestWarmUp_NilGoals(t *testing.T) {
 	assert.True(t, ok)
 
 	// Only the valid goal should be cached
-	_, ok = cache.GetGoalJSON("goal1")
+	_, ok = cache.GetGoalJSON("challenge1", "goal1")
 	assert.True(t, ok)
 
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Equal(t, 1, challengeCount)
-	assert.Equal(t, 1, goalCount)
+	stats := cache.GetStats()
+	assert.Equal(t, 1, stats.ChallengeFragments)
+	assert.Equal(t, 1, stats.GoalFragments)
 }
 
 func TestWarmUp_JSONFormat(t *testing.T) {
@@ -206,7 +207,7 @@ func TestWarmUp_JSONFormat(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify goal JSON has correct structure
-	goalJSON, ok := cache.GetGoalJSON("goal1")
+	goalJSON, ok := cache.GetGoalJSON("challenge1", "goal1")
 	require.True(t, ok)
 
 	var goal map[string]interface{}
@@ -244,7 +245,7 @@ func TestGetGoalJSON_Found(t *testing.T) {
 	challenges := createTestChallenges()
 	_ = cache.WarmUp(challenges)
 
-	goalJSON, ok := cache.GetGoalJSON("goal1")
+	goalJSON, ok := cache.GetGoalJSON("challenge1", "goal1")
 
 	assert.True(t, ok)
 	assert.NotEmpty(t, goalJSON)
@@ -256,12 +257,62 @@ func TestGetGoalJSON_Found(t *testing.T) {
 	assert.Equal(t, "goal1", goal["goalId"]) // camelCase
 }
 
+func TestGetSegmentGoalJSON(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	cache.SetTargetOverrides(map[string]map[string]int{"goal1": {"new_player": 3}})
+	challenges := createTestChallenges()
+	require.NoError(t, cache.WarmUp(challenges))
+
+	targetValue := func(goalJSON []byte) float64 {
+		var goal struct {
+			Requirement map[string]interface{} `json:"requirement"`
+		}
+		require.NoError(t, json.Unmarshal(goalJSON, &goal))
+		return goal.Requirement["targetValue"].(float64)
+	}
+
+	segmentJSON, ok := cache.GetSegmentGoalJSON("challenge1", "goal1", "new_player")
+	require.True(t, ok)
+	assert.Equal(t, 3.0, targetValue(segmentJSON))
+
+	// Other segments and goals fall back to the configured target
+	defaultJSON, ok := cache.GetSegmentGoalJSON("challenge1", "goal1", "veteran")
+	require.True(t, ok)
+	assert.Equal(t, 10.0, targetValue(defaultJSON))
+	goalJSON, _ := cache.GetGoalJSON("challenge1", "goal1")
+	assert.Equal(t, goalJSON, defaultJSON)
+
+	// The challenge template is not modified
+	assert.Equal(t, int32(10), challenges[0].Goals[0].Requirement.TargetValue)
+}
+
+func TestGetGoalFragment_Slot(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	challenges := createTestChallenges()
+	challenges[0].Goals[1].Prerequisites = []string{"goal1"}
+	require.NoError(t, cache.WarmUp(challenges))
+
+	fragment, ok := cache.GetGoalFragment("challenge1", "goal1", "")
+	require.True(t, ok)
+	goalJSON, _ := cache.GetGoalJSON("challenge1", "goal1")
+	assert.Equal(t, goalJSON, fragment.JSON)
+	assert.Equal(t, "}", string(fragment.JSON[fragment.Slot:]), "progress fields go before the closing brace")
+	assert.False(t, fragment.HasPrerequisites)
+
+	fragment, ok = cache.GetGoalFragment("challenge1", "goal2", "")
+	require.True(t, ok)
+	assert.True(t, fragment.HasPrerequisites)
+
+	_, ok = cache.GetGoalFragment("challenge1", "nonexistent", "")
+	assert.False(t, ok)
+}
+
 func TestGetGoalJSON_NotFound(t *testing.T) {
 	cache := NewSerializedChallengeCache()
 	challenges := createTestChallenges()
 	_ = cache.WarmUp(challenges)
 
-	goalJSON, ok := cache.GetGoalJSON("nonexistent")
+	goalJSON, ok := cache.GetGoalJSON("challenge1", "nonexistent")
 
 	assert.False(t, ok)
 	assert.Nil(t, goalJSON)
@@ -270,7 +321,7 @@ func TestGetGoalJSON_NotFound(t *testing.T) {
 func TestGetGoalJSON_EmptyCache(t *testing.T) {
 	cache := NewSerializedChallengeCache()
 
-	goalJSON, ok := cache.GetGoalJSON("goal1")
+	goalJSON, ok := cache.GetGoalJSON("challenge1", "goal1")
 
 	assert.False(t, ok)
 	assert.Nil(t, goalJSON)
@@ -348,14 +399,14 @@ func TestRefresh_Success(t *testing.T) {
 	assert.False(t, ok, "Old challenge should be removed after refresh")
 
 	// Old goals should be gone
-	_, ok = cache.GetGoalJSON("goal1")
+	_, ok = cache.GetGoalJSON("challenge1", "goal1")
 	assert.False(t, ok, "Old goal should be removed after refresh")
 
 	// New challenges should exist
 	_, ok = cache.GetChallengeJSON("challenge3")
 	assert.True(t, ok, "New challenge should exist after refresh")
 
-	_, ok = cache.GetGoalJSON("goal4")
+	_, ok = cache.GetGoalJSON("challenge3", "goal4")
 	assert.True(t, ok, "New goal should exist after refresh")
 }
 
@@ -364,18 +415,18 @@ func TestRefresh_EmptyChallenges(t *testing.T) {
 	_ = cache.WarmUp(createTestChallenges())
 
 	// Verify initial state has challenges
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Greater(t, challengeCount, 0)
-	assert.Greater(t, goalCount, 0)
+	stats := cache.GetStats()
+	assert.Greater(t, stats.ChallengeFragments, 0)
+	assert.Greater(t, stats.GoalFragments, 0)
 
 	// Refresh with empty list
 	err := cache.Refresh([]*pb.Challenge{})
 	require.NoError(t, err)
 
 	// Cache should be empty
-	challengeCount, goalCount, _ = cache.GetStats()
-	assert.Equal(t, 0, challengeCount)
-	assert.Equal(t, 0, goalCount)
+	stats = cache.GetStats()
+	assert.Equal(t, 0, stats.ChallengeFragments)
+	assert.Equal(t, 0, stats.GoalFragments)
 }
 
 func TestRefresh_NilChallenges(t *testing.T) {
@@ -403,19 +454,19 @@ func TestRefresh_NilChallenges(t *testing.T) {
 	_, ok := cache.GetChallengeJSON("challenge3")
 	assert.True(t, ok)
 
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Equal(t, 1, challengeCount)
-	assert.Equal(t, 1, goalCount)
+	stats := cache.GetStats()
+	assert.Equal(t, 1, stats.ChallengeFragments)
+	assert.Equal(t, 1, stats.GoalFragments)
 }
 
 func TestGetStats_EmptyCache(t *testing.T) {
 	cache := NewSerializedChallengeCache()
 
-	challengeCount, goalCount, totalBytes := cache.GetStats()
+	stats := cache.GetStats()
 
-	assert.Equal(t, 0, challengeCount)
-	assert.Equal(t, 0, goalCount)
-	assert.Equal(t, 0, totalBytes)
+	assert.Equal(t, 0, stats.ChallengeFragments)
+	assert.Equal(t, 0, stats.GoalFragments)
+	assert.Equal(t, 0, stats.TotalBytes)
 }
 
 func TestGetStats_PopulatedCache(t *testing.T) {
@@ -423,12 +474,25 @@ func TestGetStats_PopulatedCache(t *testing.T) {
 	challenges := createTestChallenges()
 	_ = cache.WarmUp(challenges)
 
-	challengeCount, goalCount, totalBytes := cache.GetStats()
+	stats := cache.GetStats()
+
+	assert.Equal(t, 2, stats.ChallengeFragments)
+	assert.Equal(t, 3, stats.GoalFragments)
+	assert.Equal(t, 3, stats.ListedGoals)
+	assert.Greater(t, stats.TotalBytes, 0, "Total bytes should be > 0 for populated cache")
+	assert.Greater(t, stats.TotalBytes, 100, "JSON should be at least 100 bytes")
+}
+
+func TestCheck(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	assert.EqualError(t, cache.Check(context.Background()), "serialization cache is empty")
+
+	require.NoError(t, cache.WarmUp(createTestChallenges()))
+	assert.NoError(t, cache.Check(context.Background()))
 
-	assert.Equal(t, 2, challengeCount)
-	assert.Equal(t, 3, goalCount)
-	assert.Greater(t, totalBytes, 0, "Total bytes should be > 0 for populated cache")
-	assert.Greater(t, totalBytes, 100, "JSON should be at least 100 bytes")
+	// A refresh with no challenges empties the cache again
+	require.NoError(t, cache.Refresh(nil))
+	assert.Error(t, cache.Check(context.Background()))
 }
 
 func TestGetStats_AfterRefresh(t *testing.T) {
@@ -436,9 +500,9 @@ func TestGetStats_AfterRefresh(t *testing.T) {
 	_ = cache.WarmUp(createTestChallenges())
 
 	// Get initial stats
-	initialChallenges, initialGoals, initialBytes := cache.GetStats()
-	assert.Equal(t, 2, initialChallenges)
-	assert.Equal(t, 3, initialGoals)
+	initial := cache.GetStats()
+	assert.Equal(t, 2, initial.ChallengeFragments)
+	assert.Equal(t, 3, initial.GoalFragments)
 
 	// Refresh with smaller dataset
 	newChallenges := []*pb.Challenge{
@@ -456,10 +520,10 @@ func TestGetStats_AfterRefresh(t *testing.T) {
 	_ = cache.Refresh(newChallenges)
 
 	// Stats should reflect new data
-	challengeCount, goalCount, totalBytes := cache.GetStats()
-	assert.Equal(t, 1, challengeCount)
-	assert.Equal(t, 1, goalCount)
-	assert.Less(t, totalBytes, initialBytes, "New cache should be smaller")
+	stats := cache.GetStats()
+	assert.Equal(t, 1, stats.ChallengeFragments)
+	assert.Equal(t, 1, stats.GoalFragments)
+	assert.Less(t, stats.TotalBytes, initial.TotalBytes, "New cache should be smaller")
 }
 
 func TestParseAndMerge_ValidJSON(t *testing.T) {
@@ -539,8 +603,8 @@ func TestConcurrentAccess(t *testing.T) {
 		go func() {
 			for j := 0; j < 100; j++ {
 				_, _ = cache.GetChallengeJSON("challenge1")
-				_, _ = cache.GetGoalJSON("goal1")
-				_, _, _ = cache.GetStats()
+				_, _ = cache.GetGoalJSON("challenge1", "goal1")
+				_ = cache.GetStats()
 			}
 			done <- true
 		}()
@@ -552,9 +616,9 @@ func TestConcurrentAccess(t *testing.T) {
 	}
 
 	// Cache should still be consistent
-	challengeCount, goalCount, _ := cache.GetStats()
-	assert.Equal(t, 2, challengeCount)
-	assert.Equal(t, 3, goalCount)
+	stats := cache.GetStats()
+	assert.Equal(t, 2, stats.ChallengeFragments)
+	assert.Equal(t, 3, stats.GoalFragments)
 }
 
 // TestConcurrentRefresh tests thread-safety during refresh operations
@@ -570,7 +634,7 @@ func TestConcurrentRefresh(t *testing.T) {
 		go func() {
 			for j := 0; j < 50; j++ {
 				_, _ = cache.GetChallengeJSON("challenge1")
-				_, _ = cache.GetGoalJSON("goal1")
+				_, _ = cache.GetGoalJSON("challenge1", "goal1")
 			}
 			done <- true
 		}()
@@ -698,3 +762,105 @@ func TestGetGoalCount_AfterRefresh(t *testing.T) {
 	count := cache.GetGoalCount("challenge1")
 	assert.Equal(t, 4, count)
 }
+
+func TestWarmUp_HiddenGoalsExcludedFromChallengeJSON(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	cache.SetHiddenGoals(map[string]bool{"goal2": true, "goal3": true})
+
+	require.NoError(t, cache.WarmUp(createTestChallenges()))
+
+	challengeJSON, ok := cache.GetChallengeJSON("challenge1")
+	require.True(t, ok)
+	assert.Contains(t, string(challengeJSON), `"goal1"`)
+	assert.NotContains(t, string(challengeJSON), `"goal2"`)
+	assert.Equal(t, 1, cache.GetGoalCount("challenge1"))
+
+	// challenge2 only has a hidden goal: protojson omits the empty goals array
+	challengeJSON, ok = cache.GetChallengeJSON("challenge2")
+	require.True(t, ok)
+	assert.NotContains(t, string(challengeJSON), `"goals"`)
+	assert.Equal(t, 0, cache.GetGoalCount("challenge2"))
+
+	// Hidden goals stay available individually for unlocked users
+	_, ok = cache.GetGoalJSON("challenge1", "goal2")
+	assert.True(t, ok)
+	_, ok = cache.GetGoalJSON("challenge2", "goal3")
+	assert.True(t, ok)
+}
+
+func TestRefresh_HiddenGoalsExcludedFromChallengeJSON(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	cache.SetHiddenGoals(map[string]bool{"goal1": true})
+
+	require.NoError(t, cache.Refresh(createTestChallenges()))
+
+	challengeJSON, ok := cache.GetChallengeJSON("challenge1")
+	require.True(t, ok)
+	assert.NotContains(t, string(challengeJSON), `"goal1"`)
+	assert.Equal(t, 1, cache.GetGoalCount("challenge1"))
+	_, ok = cache.GetGoalJSON("challenge1", "goal1")
+	assert.True(t, ok)
+}
+
+func TestGetChallengeFragment(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	cache.SetHiddenGoals(map[string]bool{"goal2": true})
+	require.NoError(t, cache.WarmUp(createTestChallenges()))
+
+	fragment, ok := cache.GetChallengeFragment("challenge1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"goal1"}, fragment.GoalIDs, "hidden goals are not listed")
+	assert.NotContains(t, string(fragment.Header), `"goals"`)
+	assert.Contains(t, string(fragment.Header), `"Test Challenge 1"`)
+
+	_, ok = cache.GetChallengeFragment("nonexistent")
+	assert.False(t, ok)
+
+	stats := cache.GetStats()
+	assert.Equal(t, 2, stats.ChallengeFragments)
+	assert.Equal(t, 3, stats.GoalFragments, "hidden goals keep their goal fragment")
+	assert.Equal(t, 2, stats.ListedGoals)
+}
+
+func TestGetChallengeJSON_MatchesProtojson(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	challenges := createTestChallenges()
+	require.NoError(t, cache.WarmUp(challenges))
+
+	for _, challenge := range challenges {
+		expected, err := cache.marshaler.Marshal(challenge)
+		require.NoError(t, err)
+
+		assembled, ok := cache.GetChallengeJSON(challenge.ChallengeId)
+		require.True(t, ok)
+		assert.JSONEq(t, string(expected), string(assembled), challenge.ChallengeId)
+	}
+}
+
+func TestChallengeFragment_Assemble(t *testing.T) {
+	fragment := &ChallengeFragment{Header: []byte(`{"challengeId":"c"`)}
+
+	assert.Equal(t, `{"challengeId":"c"}`, string(fragment.Assemble(nil)))
+	assert.Equal(t, `{"challengeId":"c","goals":[{"goalId":"g1"},{"goalId":"g2"}]}`,
+		string(fragment.Assemble([][]byte{[]byte(`{"goalId":"g1"}`), []byte(`{"goalId":"g2"}`)})))
+
+	empty := &ChallengeFragment{Header: []byte(`{`)}
+	assert.Equal(t, `{"goals":[{"goalId":"g1"}]}`, string(empty.Assemble([][]byte{[]byte(`{"goalId":"g1"}`)})))
+}
+
+func TestChallengeFragment_Size(t *testing.T) {
+	cache := NewSerializedChallengeCache()
+	cache.SetHiddenGoals(map[string]bool{"hidden": true})
+	challenges := append(createTestChallenges(),
+		&pb.Challenge{ChallengeId: "empty", Name: "Empty"},
+		&pb.Challenge{ChallengeId: "quest", Name: "Quest", Goals: []*pb.Goal{{GoalId: "listed"}, {GoalId: "hidden"}}},
+	)
+	require.NoError(t, cache.WarmUp(challenges))
+
+	for _, challenge := range challenges {
+		fragment, ok := cache.GetChallengeFragment(challenge.ChallengeId)
+		require.True(t, ok)
+		assembled, _ := cache.GetChallengeJSON(challenge.ChallengeId)
+		assert.Equal(t, len(assembled), fragment.Size, challenge.ChallengeId)
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"extend-challenge-service/pkg/common/version"
+)
+
+const (
+	sourceHeader      = "X-Ab-Source"
+	challengeIDHeader = "X-Ab-Challenge-Id"
+	goalIDHeader      = "X-Ab-Goal-Id"
+	// idempotencyKeyHeader lets AGS recognise a grant it already applied
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// requestIDHeaders are the AGS response headers that identify a request, in lookup order.
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Trace-Id"}
+
+type grantMetadataKey struct{}
+
+type grantMetadata struct {
+	challengeID string
+	goalID      string
+}
+
+// WithGrantMetadata returns a context that tags AGS reward calls made with it
+// with the claim's challenge and goal IDs.
+func WithGrantMetadata(ctx context.Context, challengeID, goalID string) context.Context {
+	return context.WithValue(ctx, grantMetadataKey{}, grantMetadata{challengeID: challengeID, goalID: goalID})
+}
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey returns a context that tags AGS reward calls made with it
+// with key, so a grant repeated with the same key is applied once.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set by WithIdempotencyKey, or "".
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// agsHeaderTransport attaches the trace context, X-Ab-Source, grant metadata and
+// idempotency key headers to outgoing AGS requests, and remembers the request ID of the last
+// failed response so it can be logged alongside the SDK error, and the
+// Retry-After of the last 429 response.
+type agsHeaderTransport struct {
+	base http.RoundTripper
+
+	mu         sync.Mutex
+	requestID  string
+	retryAfter time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *agsHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	req = req.Clone(ctx)
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req.Header.Set(sourceHeader, "extend-challenge-service/"+version.Version)
+	if md, ok := ctx.Value(grantMetadataKey{}).(grantMetadata); ok {
+		if md.challengeID != "" {
+			req.Header.Set(challengeIDHeader, md.challengeID)
+		}
+		if md.goalID != "" {
+			req.Header.Set(goalIDHeader, md.goalID)
+		}
+	}
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+		for _, h := range requestIDHeaders {
+			if id := resp.Header.Get(h); id != "" {
+				t.mu.Lock()
+				t.requestID = id
+				t.mu.Unlock()
+				break
+			}
+		}
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); retryAfter > 0 {
+			t.mu.Lock()
+			t.retryAfter = retryAfter
+			t.mu.Unlock()
+		}
+	}
+	return resp, err
+}
+
+// RequestID returns the AGS request ID of the last failed response, if any.
+func (t *agsHeaderTransport) RequestID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requestID
+}
+
+// RetryAfter returns the Retry-After of the last 429 response, or 0 if none.
+func (t *agsHeaderTransport) RetryAfter() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.retryAfter
+}
+
+// newRequestTransport builds the per-call transport for an SDK request on top of
+// base (http.DefaultTransport when nil). The returned retry policy mirrors the
+// SDK default but routes through the header transport, so it can be set as the
+// params' RetryPolicy.
+func newRequestTransport(base http.RoundTripper) (*agsHeaderTransport, *utils.Retry) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport := &agsHeaderTransport{base: base}
+	return transport, &utils.Retry{
+		MaxTries:   utils.MaxTries,
+		Backoff:    utils.NewConstantBackoff(0),
+		Transport:  transport,
+		RetryCodes: utils.RetryCodes,
+	}
+}
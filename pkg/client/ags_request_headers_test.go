@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AccelByte/accelbyte-go-sdk/iam-sdk/pkg/iamclientmodels"
+	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclient"
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/platform"
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"extend-challenge-service/pkg/common/version"
+)
+
+// fakeTransport records outgoing requests and answers them with a canned response.
+type fakeTransport struct {
+	mu       sync.Mutex
+	requests []*http.Request
+
+	status  int
+	body    string
+	headers http.Header
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	for k, v := range f.headers {
+		header[k] = v
+	}
+	return &http.Response{
+		StatusCode: f.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeTransport) lastRequest(t *testing.T) *http.Request {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(t, f.requests, "expected at least one AGS request")
+	return f.requests[len(f.requests)-1]
+}
+
+// newFakeAGSClient builds an AGSRewardClient backed by real SDK services whose
+// HTTP transport is the given fake.
+func newFakeAGSClient(t *testing.T, transport http.RoundTripper, logger *logrus.Logger) *AGSRewardClient {
+	t.Helper()
+
+	platformClient := platformclient.NewHTTPClientWithConfig(nil, &platformclient.TransportConfig{
+		Host:    "ags.example.com",
+		Schemes: []string{"https"},
+	})
+	platformClient.Runtime.Transport = transport
+
+	tokenRepo := auth.DefaultTokenRepositoryImpl()
+	accessToken := "test-token"
+	require.NoError(t, tokenRepo.Store(iamclientmodels.OauthmodelTokenResponseV3{AccessToken: &accessToken}))
+
+	return newTestAGSClient(
+		&platform.EntitlementService{Client: platformClient, TokenRepository: tokenRepo},
+		&platform.WalletService{Client: platformClient, TokenRepository: tokenRepo},
+		logger,
+	)
+}
+
+// tracedContext returns a context carrying a sampled span context and installs
+// the W3C trace context propagator for the duration of the test.
+func tracedContext(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc), sc
+}
+
+func TestGrantItemReward_AttachesRequestHeaders(t *testing.T) {
+	ctx, sc := tracedContext(t)
+	ctx = WithGrantMetadata(ctx, "winter-challenge", "kill-10-snowmen")
+	ctx = WithIdempotencyKey(ctx, "claim-key")
+
+	transport := &fakeTransport{status: http.StatusCreated, body: "[]"}
+	c := newFakeAGSClient(t, transport, logrus.New())
+
+	err := c.GrantItemReward(ctx, "test-namespace", "user123", "item123", 1)
+	require.NoError(t, err)
+
+	req := transport.lastRequest(t)
+	assert.Equal(t, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-01", req.Header.Get("traceparent"))
+	assert.Equal(t, "extend-challenge-service/"+version.Version, req.Header.Get("X-Ab-Source"))
+	assert.Equal(t, "winter-challenge", req.Header.Get("X-Ab-Challenge-Id"))
+	assert.Equal(t, "kill-10-snowmen", req.Header.Get("X-Ab-Goal-Id"))
+	assert.Equal(t, "claim-key", req.Header.Get("Idempotency-Key"))
+	assert.Equal(t, "Bearer test-token", req.Header.Get("Authorization"))
+}
+
+func TestGrantWalletReward_AttachesRequestHeaders(t *testing.T) {
+	ctx, sc := tracedContext(t)
+
+	transport := &fakeTransport{status: http.StatusOK, body: `{"balance":100}`}
+	c := newFakeAGSClient(t, transport, logrus.New())
+
+	err := c.GrantReward(ctx, "test-namespace", "user123", commonDomain.Reward{
+		Type:     "WALLET",
+		RewardID: "GOLD",
+		Quantity: 100,
+	})
+	require.NoError(t, err)
+
+	req := transport.lastRequest(t)
+	assert.Contains(t, req.Header.Get("traceparent"), sc.TraceID().String())
+	assert.Equal(t, "extend-challenge-service/"+version.Version, req.Header.Get("X-Ab-Source"))
+	// No grant metadata on the context, so no ID headers
+	assert.Empty(t, req.Header.Get("X-Ab-Challenge-Id"))
+	assert.Empty(t, req.Header.Get("X-Ab-Goal-Id"))
+	assert.Empty(t, req.Header.Get("Idempotency-Key"))
+}
+
+func TestGrantItemReward_LogsAGSRequestID(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	transport := &fakeTransport{
+		status:  http.StatusNotFound,
+		body:    `{"errorCode":30341,"errorMessage":"Item [item123] does not exist"}`,
+		headers: http.Header{"X-Request-Id": []string{"ags-req-42"}},
+	}
+	c := newFakeAGSClient(t, transport, logger)
+
+	err := c.GrantItemReward(context.Background(), "test-namespace", "user123", "item123", 1)
+	require.Error(t, err)
+
+	var found bool
+	for _, entry := range hook.AllEntries() {
+		if entry.Data["agsRequestID"] == "ags-req-42" {
+			found = true
+			assert.Equal(t, "grant_item", entry.Data["operation"])
+		}
+	}
+	assert.True(t, found, "expected AGS request ID to be logged")
+}
+
+func TestAGSHeaderTransport_NoRequestIDOnSuccess(t *testing.T) {
+	base := &fakeTransport{
+		status:  http.StatusOK,
+		headers: http.Header{"X-Request-Id": []string{"ags-req-ok"}},
+	}
+	transport, _ := newRequestTransport(base)
+
+	req, err := http.NewRequest(http.MethodGet, "https://ags.example.com/", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Empty(t, transport.RequestID())
+	// The caller's request must not be mutated
+	assert.Empty(t, req.Header.Get("X-Ab-Source"))
+	assert.NotEmpty(t, base.lastRequest(t).Header.Get("X-Ab-Source"))
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -10,8 +11,6 @@ import (
 
 	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclient/entitlement"
 	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclient/wallet"
-	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclientmodels"
-	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/platform"
 	"github.com/sirupsen/logrus"
 
 	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
@@ -19,38 +18,26 @@ import (
 )
 
 // AGSRewardClient implements RewardClient interface using AccelByte Gaming Services (AGS) Platform SDK.
-// It provides retry logic for reliability and proper error handling for AGS-specific errors.
+// It grants each reward through the RewardGranter registered for its type, so a
+// new reward type only needs a granter registered in main.go.
 type AGSRewardClient struct {
-	entitlementService *platform.EntitlementService
-	walletService      *platform.WalletService
-	logger             *logrus.Logger
+	granters RewardGranters
+	logger   *logrus.Logger
 }
 
-// NewAGSRewardClient creates a new AGSRewardClient with AGS Platform SDK services.
+// NewAGSRewardClient creates a new AGSRewardClient granting rewards through the given granters.
 //
 // Parameters:
-//   - entitlementService: AGS Platform EntitlementService for granting items
-//   - walletService: AGS Platform WalletService for crediting virtual currency
+//   - granters: Granter per reward type, e.g. NewItemGranter for "ITEM" and NewWalletGranter for "WALLET"
 //   - logger: Logger for structured logging
-func NewAGSRewardClient(
-	entitlementService *platform.EntitlementService,
-	walletService *platform.WalletService,
-	logger *logrus.Logger,
-) commonClient.RewardClient {
+func NewAGSRewardClient(granters RewardGranters, logger *logrus.Logger) commonClient.RewardClient {
 	return &AGSRewardClient{
-		entitlementService: entitlementService,
-		walletService:      walletService,
-		logger:             logger,
+		granters: granters,
+		logger:   logger,
 	}
 }
 
-// GrantItemReward grants an item entitlement to a user using AGS Platform Service.
-//
-// This method:
-//   - Creates an EntitlementGrant request with itemID, namespace, and quantity
-//   - Calls GrantUserEntitlementShort SDK function
-//   - Retries on transient failures (502/503, timeouts) with exponential backoff
-//   - Fails immediately on non-retryable errors (400, 404, 403)
+// GrantItemReward grants an item entitlement to a user through the "ITEM" granter.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -61,56 +48,14 @@ func NewAGSRewardClient(
 //
 // Returns error if grant fails after retries or on non-retryable errors.
 func (c *AGSRewardClient) GrantItemReward(ctx context.Context, namespace, userID, itemID string, quantity int) error {
-	// Validate quantity is within int32 range to prevent overflow
-	if quantity < 0 || quantity > 2147483647 {
-		return &commonClient.BadRequestError{
-			Message: fmt.Sprintf("quantity %d out of range for int32", quantity),
-		}
-	}
-
-	return c.withRetry(ctx, "grant_item", func() error {
-		// Create entitlement grant request
-		// NOTE: ItemNamespace must equal deployment namespace (no cross-namespace grants)
-		//nolint:gosec // G115: Safe conversion after range validation above
-		quantity32 := int32(quantity)
-		grant := &platformclientmodels.EntitlementGrant{
-			ItemID:        &itemID,
-			ItemNamespace: &namespace,
-			Quantity:      &quantity32,
-		}
-
-		params := &entitlement.GrantUserEntitlementParams{
-			Namespace: namespace,
-			UserID:    userID,
-			Body:      []*platformclientmodels.EntitlementGrant{grant}, // NOTE: Body is array, not single grant
-		}
-
-		// Call AGS Platform SDK
-		response, err := c.entitlementService.GrantUserEntitlementShort(params)
-		if err != nil {
-			return c.wrapSDKError(err, "failed to grant item reward")
-		}
-
-		// Log response for audit (don't validate, just log)
-		c.logger.WithFields(logrus.Fields{
-			"namespace": namespace,
-			"userID":    userID,
-			"itemID":    itemID,
-			"quantity":  quantity,
-			"response":  response,
-		}).Info("Item reward granted successfully")
-
-		return nil
+	return c.GrantReward(ctx, namespace, userID, commonDomain.Reward{
+		Type:     RewardTypeItem,
+		RewardID: itemID,
+		Quantity: quantity,
 	})
 }
 
-// GrantWalletReward credits a user's wallet with virtual currency using AGS Platform Service.
-//
-// This method:
-//   - Creates a CreditRequest with amount and currencyCode
-//   - Calls CreditUserWalletShort SDK function (creates wallet if not exists)
-//   - Retries on transient failures (502/503, timeouts) with exponential backoff
-//   - Fails immediately on non-retryable errors (400, 404, 403)
+// GrantWalletReward credits a user's wallet with virtual currency through the "WALLET" granter.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -121,50 +66,14 @@ func (c *AGSRewardClient) GrantItemReward(ctx context.Context, namespace, userID
 //
 // Returns error if grant fails after retries or on non-retryable errors.
 func (c *AGSRewardClient) GrantWalletReward(ctx context.Context, namespace, userID, currencyCode string, amount int) error {
-	// Validate amount is non-negative (int64 range is much larger than int, so only check negative)
-	if amount < 0 {
-		return &commonClient.BadRequestError{
-			Message: fmt.Sprintf("amount %d cannot be negative", amount),
-		}
-	}
-
-	return c.withRetry(ctx, "grant_wallet", func() error {
-		// Create credit request
-		// NOTE: Amount is int64, not int
-		amount64 := int64(amount) // Safe conversion from int to int64
-		creditReq := &platformclientmodels.CreditRequest{
-			Amount: &amount64,
-			// Optional fields can be added here: Reason, Source, Origin, Metadata
-		}
-
-		params := &wallet.CreditUserWalletParams{
-			Namespace:    namespace,
-			UserID:       userID,
-			CurrencyCode: currencyCode,
-			Body:         creditReq,
-		}
-
-		// Call AGS Platform SDK
-		response, err := c.walletService.CreditUserWalletShort(params)
-		if err != nil {
-			return c.wrapSDKError(err, "failed to credit wallet")
-		}
-
-		// Log response for audit (don't validate, just log)
-		c.logger.WithFields(logrus.Fields{
-			"namespace":    namespace,
-			"userID":       userID,
-			"currencyCode": currencyCode,
-			"amount":       amount,
-			"response":     response,
-		}).Info("Wallet credited successfully")
-
-		return nil
+	return c.GrantReward(ctx, namespace, userID, commonDomain.Reward{
+		Type:     RewardTypeWallet,
+		RewardID: currencyCode,
+		Quantity: amount,
 	})
 }
 
-// GrantReward is a convenience method that dispatches to the appropriate grant method
-// based on the reward type (ITEM or WALLET).
+// GrantReward grants the reward through the granter registered for its type.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout
@@ -172,21 +81,67 @@ func (c *AGSRewardClient) GrantWalletReward(ctx context.Context, namespace, user
 //   - userID: User's unique identifier
 //   - reward: Reward configuration from goal
 //
-// Returns error if reward type is unsupported or grant fails after retries.
+// Returns a BadRequestError if no granter handles the reward type, or the
+// granter's error if the grant fails after retries.
 func (c *AGSRewardClient) GrantReward(ctx context.Context, namespace, userID string, reward commonDomain.Reward) error {
-	switch reward.Type {
-	case "ITEM":
-		return c.GrantItemReward(ctx, namespace, userID, reward.RewardID, reward.Quantity)
-	case "WALLET":
-		return c.GrantWalletReward(ctx, namespace, userID, reward.RewardID, reward.Quantity)
-	default:
+	_, err := c.GrantRewardWithResult(ctx, namespace, userID, reward)
+	return err
+}
+
+// GrantRewardWithResult is GrantReward returning the IDs reported by the
+// granter, e.g. the entitlement ID (ITEM) or the credited wallet ID (WALLET).
+func (c *AGSRewardClient) GrantRewardWithResult(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+	granter, ok := c.granters[reward.Type]
+	if !ok {
 		c.logger.WithFields(logrus.Fields{
 			"namespace":  namespace,
 			"userID":     userID,
 			"rewardType": reward.Type,
 		}).Warn("Unknown reward type")
-		return fmt.Errorf("unsupported reward type: %s", reward.Type)
+		return GrantResult{}, &commonClient.BadRequestError{
+			Message: fmt.Sprintf("unsupported reward type: %s", reward.Type),
+		}
+	}
+
+	return granter.Grant(ctx, namespace, userID, reward)
+}
+
+// undocumentedStatusPattern matches the status code of the SDK error for a
+// response its spec does not document.
+var undocumentedStatusPattern = regexp.MustCompile(`returns an error (\d{3}):`)
+
+// TokenRefresher logs the service in to IAM again after AGS rejected its token.
+// TokenMonitor implements it.
+type TokenRefresher interface {
+	RefreshToken(ctx context.Context) error
+}
+
+// agsCaller holds the retry and error handling shared by the granters that
+// call the AGS Platform SDK.
+type agsCaller struct {
+	logger *logrus.Logger
+	tokens TokenRefresher
+}
+
+// SetTokenRefresher makes a grant rejected with 401 refresh the IAM token and
+// retry once instead of failing. Without one, 401 fails the grant.
+func (c *agsCaller) SetTokenRefresher(tokens TokenRefresher) {
+	c.tokens = tokens
+}
+
+// logAGSRequestID logs the AGS request ID of a failed SDK call so the failure
+// can be matched with AGS-side logs. Nothing is logged if AGS did not return one.
+func (c *agsCaller) logAGSRequestID(operation string, transport *agsHeaderTransport, err error) {
+	requestID := transport.RequestID()
+	if requestID == "" {
+		return
 	}
+
+	c.logger.WithFields(logrus.Fields{
+		"operation":    operation,
+		"agsRequestID": requestID,
+		"error":        err,
+	}).Warn("AGS request failed")
 }
 
 // withRetry executes the given function with retry logic for transient failures.
@@ -197,10 +152,14 @@ func (c *AGSRewardClient) GrantReward(ctx context.Context, namespace, userID str
 //   - Exponential backoff: 500ms, 1s, 2s
 //   - Total timeout: 10 seconds (prevents transaction timeout)
 //   - Context cancellation check before each retry
+//   - 429: waits for the Retry-After AGS sent if longer than the backoff,
+//     capped at 5s, and counts the response in ags_rate_limited_total
 //
 // The function will:
 //   - Retry on transient failures: 502/503, timeouts, network errors
 //   - Fail immediately on non-retryable errors: 400, 404, 403
+//   - On the first 401, refresh the IAM token (SetTokenRefresher) and retry at
+//     once; the retry does not count as an attempt
 //   - Respect context cancellation during retry delays
 //
 // Parameters:
@@ -209,7 +168,7 @@ func (c *AGSRewardClient) GrantReward(ctx context.Context, namespace, userID str
 //   - fn: Function to execute with retry logic
 //
 // Returns error if all retries are exhausted or on non-retryable errors.
-func (c *AGSRewardClient) withRetry(ctx context.Context, operation string, fn func() error) error {
+func (c *agsCaller) withRetry(ctx context.Context, operation string, fn func() error) error {
 	const maxRetries = 3
 	const baseDelay = 500 * time.Millisecond
 	const totalTimeout = 10 * time.Second // NQ8: 10s total timeout to prevent transaction timeout
@@ -219,6 +178,7 @@ func (c *AGSRewardClient) withRetry(ctx context.Context, operation string, fn fu
 	defer cancel()
 
 	var lastErr error
+	refreshed := false
 	for attempt := 1; attempt <= maxRetries+1; attempt++ {
 		// Check context cancellation before retry (NQ4: always check ctx.Err())
 		if err := timeoutCtx.Err(); err != nil {
@@ -245,6 +205,27 @@ func (c *AGSRewardClient) withRetry(ctx context.Context, operation string, fn fu
 
 		lastErr = err
 
+		var rateLimitedErr *RateLimitedError
+		if errors.As(err, &rateLimitedErr) {
+			rateLimited.WithLabelValues(operation).Inc()
+		}
+
+		var authErr *commonClient.AuthenticationError
+		if errors.As(err, &authErr) && c.tokens != nil && !refreshed {
+			refreshed = true
+			refreshErr := c.tokens.RefreshToken(timeoutCtx)
+			c.logger.WithFields(logrus.Fields{
+				"operation":    operation,
+				"attempt":      attempt,
+				"error":        err,
+				"refreshError": refreshErr,
+			}).Warn("AGS rejected the IAM token, retrying after refresh")
+			if refreshErr == nil {
+				attempt--
+				continue
+			}
+		}
+
 		// Check if error is retryable (uses commonClient.IsRetryableError)
 		if !commonClient.IsRetryableError(err) {
 			c.logger.WithFields(logrus.Fields{
@@ -258,6 +239,7 @@ func (c *AGSRewardClient) withRetry(ctx context.Context, operation string, fn fu
 		// Don't sleep after last attempt
 		if attempt <= maxRetries {
 			delay := baseDelay * time.Duration(1<<(attempt-1)) // Exponential backoff: 500ms, 1s, 2s
+			delay = retryDelay(delay, err)                     // Longer if AGS asked to back off
 			c.logger.WithFields(logrus.Fields{
 				"operation": operation,
 				"attempt":   attempt,
@@ -299,7 +281,7 @@ func (c *AGSRewardClient) withRetry(ctx context.Context, operation string, fn fu
 //   - message: Prefix message for the error
 //
 // Returns a wrapped error with HTTP status code (if available).
-func (c *AGSRewardClient) wrapSDKError(err error, message string) error {
+func (c *agsCaller) wrapSDKError(err error, message string) error {
 	if err == nil {
 		return nil
 	}
@@ -329,6 +311,11 @@ func (c *AGSRewardClient) wrapSDKError(err error, message string) error {
 		return &commonClient.NotFoundError{
 			Resource: err.Error(),
 		}
+	case 429:
+		return &RateLimitedError{
+			RetryAfter: retryAfterFromMessage(err.Error()),
+			Message:    err.Error(),
+		}
 	default:
 		return &commonClient.AGSError{
 			StatusCode: statusCode,
@@ -348,13 +335,14 @@ func (c *AGSRewardClient) wrapSDKError(err error, message string) error {
 //   - wallet.CreditUserWalletBadRequest (400)
 //   - wallet.CreditUserWalletUnprocessableEntity (422)
 //
-// Fallback: Parses generic SDK error message format "[METHOD /path][CODE] errorName {...}"
+// Fallback: Parses generic SDK error message formats "[METHOD /path][CODE] errorName {...}"
+// and, for codes without a typed SDK response such as 429, "Requested METHOD /path returns an error CODE: body"
 //
 // Parameters:
 //   - err: Error from AGS SDK
 //
 // Returns (statusCode, true) if extraction successful, (0, false) otherwise.
-func (c *AGSRewardClient) extractStatusCode(err error) (int, bool) {
+func (c *agsCaller) extractStatusCode(err error) (int, bool) {
 	if err == nil {
 		return 0, false
 	}
@@ -384,6 +372,13 @@ func (c *AGSRewardClient) extractStatusCode(err error) (int, bool) {
 		}
 	}
 
+	// Undocumented responses: "Requested POST /platform/... returns an error 429: {...}"
+	if matches := undocumentedStatusPattern.FindStringSubmatch(errMsg); len(matches) > 1 {
+		if code, parseErr := strconv.Atoi(matches[1]); parseErr == nil {
+			return code, true
+		}
+	}
+
 	// Could not extract status code from error
 	c.logger.WithFields(logrus.Fields{
 		"errorType": fmt.Sprintf("%T", err),
@@ -108,6 +108,18 @@ func (e *mockGenericSDKError) Error() string {
 	return fmt.Sprintf("[POST /platform/some/endpoint][%d] someSDKError {\"errorCode\":99999,\"errorMessage\":\"Generic SDK error\"}", e.statusCode)
 }
 
+// newTestAGSClient builds an AGSRewardClient with the ITEM and WALLET granters
+// registered as in main.go.
+func newTestAGSClient(entitlementService *platform.EntitlementService, walletService *platform.WalletService, logger *logrus.Logger) *AGSRewardClient {
+	return &AGSRewardClient{
+		granters: RewardGranters{
+			RewardTypeItem:   NewItemGranter(entitlementService, logger),
+			RewardTypeWallet: NewWalletGranter(walletService, logger),
+		},
+		logger: logger,
+	}
+}
+
 // TestNewAGSRewardClient tests the constructor
 func TestNewAGSRewardClient(t *testing.T) {
 	logger := logrus.New()
@@ -117,13 +129,15 @@ func TestNewAGSRewardClient(t *testing.T) {
 	entitlementService := &platform.EntitlementService{}
 	walletService := &platform.WalletService{}
 
-	client := NewAGSRewardClient(entitlementService, walletService, logger)
+	client := NewAGSRewardClient(RewardGranters{
+		RewardTypeItem:   NewItemGranter(entitlementService, logger),
+		RewardTypeWallet: NewWalletGranter(walletService, logger),
+	}, logger)
 
 	assert.NotNil(t, client)
 	agsClient, ok := client.(*AGSRewardClient)
 	assert.True(t, ok)
-	assert.NotNil(t, agsClient.entitlementService)
-	assert.NotNil(t, agsClient.walletService)
+	assert.Len(t, agsClient.granters, 2)
 	assert.NotNil(t, agsClient.logger)
 }
 
@@ -132,11 +146,7 @@ func TestGrantReward_UnknownType(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: &platform.EntitlementService{},
-		walletService:      &platform.WalletService{},
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 	reward := commonDomain.Reward{
@@ -149,6 +159,35 @@ func TestGrantReward_UnknownType(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unsupported reward type")
+
+	var badReqErr *commonClient.BadRequestError
+	assert.ErrorAs(t, err, &badReqErr)
+}
+
+// TestGrantReward_RegisteredGranter tests that a pluggable reward type is
+// granted through its registered granter
+func TestGrantReward_RegisteredGranter(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	var granted commonDomain.Reward
+	client := NewAGSRewardClient(RewardGranters{
+		"SEASON_XP": RewardGranterFunc(func(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+			granted = reward
+			return GrantResult{}, nil
+		}),
+	}, logger)
+
+	reward := commonDomain.Reward{Type: "SEASON_XP", RewardID: "season-1", Quantity: 250}
+	err := client.GrantReward(context.Background(), "test-namespace", "user123", reward)
+
+	assert.NoError(t, err)
+	assert.Equal(t, reward, granted)
+
+	// ITEM is not registered on this client
+	err = client.GrantItemReward(context.Background(), "test-namespace", "user123", "sword", 1)
+	var badReqErr *commonClient.BadRequestError
+	assert.ErrorAs(t, err, &badReqErr)
 }
 
 // TestWrapSDKError_BadRequest tests 400 error mapping using wallet.CreditUserWalletBadRequest
@@ -156,7 +195,7 @@ func TestWrapSDKError_BadRequest(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -179,7 +218,7 @@ func TestWrapSDKError_NotFound(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -202,7 +241,7 @@ func TestWrapSDKError_Unauthorized(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -226,7 +265,7 @@ func TestWrapSDKError_Forbidden(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -250,7 +289,7 @@ func TestWrapSDKError_AGSError(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -274,7 +313,7 @@ func TestWrapSDKError_NoStatusCode(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -291,7 +330,7 @@ func TestWrapSDKError_NilError(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -305,7 +344,7 @@ func TestWrapSDKError_503ServiceUnavailable(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -329,7 +368,7 @@ func TestExtractStatusCode_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -349,7 +388,7 @@ func TestExtractStatusCode_Failure(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -366,7 +405,7 @@ func TestExtractStatusCode_Nil(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -381,7 +420,7 @@ func TestExtractStatusCode_RegexFallback_404(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -399,7 +438,7 @@ func TestExtractStatusCode_RegexFallback_422(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -417,7 +456,7 @@ func TestExtractStatusCode_RegexFallback_Entitlement422(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -435,7 +474,7 @@ func TestExtractStatusCode_RegexFallback_MultipleDigits(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -456,7 +495,7 @@ func TestExtractStatusCode_InvalidFormat(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -474,7 +513,7 @@ func TestWithRetry_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -495,7 +534,7 @@ func TestWithRetry_SuccessAfterRetries(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -523,7 +562,7 @@ func TestWithRetry_NonRetryableError(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -543,12 +582,87 @@ func TestWithRetry_NonRetryableError(t *testing.T) {
 	assert.Contains(t, err.Error(), "non-retryable error")
 }
 
+// stubTokenRefresher counts refreshes and fails them with err.
+type stubTokenRefresher struct {
+	calls int
+	err   error
+}
+
+func (s *stubTokenRefresher) RefreshToken(ctx context.Context) error {
+	s.calls++
+	return s.err
+}
+
+// TestWithRetry_UnauthorizedRefreshesToken tests one retry after a token refresh on 401
+func TestWithRetry_UnauthorizedRefreshesToken(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	tokens := &stubTokenRefresher{}
+	client := &agsCaller{logger: logger}
+	client.SetTokenRefresher(tokens)
+
+	callCount := 0
+	err := client.withRetry(context.Background(), "test_op", func() error {
+		callCount++
+		if callCount == 1 {
+			return &commonClient.AuthenticationError{Message: "token expired"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, callCount)
+	assert.Equal(t, 1, tokens.calls)
+}
+
+// TestWithRetry_UnauthorizedAfterRefresh tests that a second 401 fails without another refresh
+func TestWithRetry_UnauthorizedAfterRefresh(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	tokens := &stubTokenRefresher{}
+	client := &agsCaller{logger: logger}
+	client.SetTokenRefresher(tokens)
+
+	callCount := 0
+	err := client.withRetry(context.Background(), "test_op", func() error {
+		callCount++
+		return &commonClient.AuthenticationError{Message: "token expired"}
+	})
+
+	var authErr *commonClient.AuthenticationError
+	assert.ErrorAs(t, err, &authErr)
+	assert.Equal(t, 2, callCount)
+	assert.Equal(t, 1, tokens.calls)
+}
+
+// TestWithRetry_UnauthorizedRefreshFails tests that a failed refresh fails the grant without a retry
+func TestWithRetry_UnauthorizedRefreshFails(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	tokens := &stubTokenRefresher{err: errors.New("iam unreachable")}
+	client := &agsCaller{logger: logger}
+	client.SetTokenRefresher(tokens)
+
+	callCount := 0
+	err := client.withRetry(context.Background(), "test_op", func() error {
+		callCount++
+		return &commonClient.AuthenticationError{Message: "token expired"}
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, callCount)
+	assert.Equal(t, 1, tokens.calls)
+}
+
 // TestWithRetry_MaxRetriesExceeded tests failure after max retries
 func TestWithRetry_MaxRetriesExceeded(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -574,7 +688,7 @@ func TestWithRetry_ContextCancelled(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -607,7 +721,7 @@ func TestWithRetry_ContextCancelledDuringBackoff(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -637,7 +751,7 @@ func TestWithRetry_ExponentialBackoff(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -679,7 +793,7 @@ func TestWithRetry_TotalTimeout(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
+	client := &agsCaller{
 		logger: logger,
 	}
 
@@ -740,11 +854,7 @@ func TestGrantItemReward_QuantityNegative(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: (*platform.EntitlementService)(nil),
-		walletService:      (*platform.WalletService)(nil),
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 
@@ -767,11 +877,7 @@ func TestGrantItemReward_QuantityOverflow(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: (*platform.EntitlementService)(nil),
-		walletService:      (*platform.WalletService)(nil),
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 
@@ -799,7 +905,7 @@ func TestGrantWalletReward_ValidAmount(t *testing.T) {
 		amount int
 		valid  bool
 	}{
-		{0, true},
+		{0, false}, // zero credits nothing
 		{1, true},
 		{100, true},
 		{1000, true},
@@ -811,21 +917,36 @@ func TestGrantWalletReward_ValidAmount(t *testing.T) {
 
 	for _, tc := range testCases {
 		// Test the validation logic
-		isValid := tc.amount >= 0
+		isValid := tc.amount > 0
 		assert.Equal(t, tc.valid, isValid, "amount %d validation should be %v", tc.amount, tc.valid)
 	}
 }
 
+// TestGrantReward_ZeroQuantity tests that zero quantities are rejected before any AGS call
+func TestGrantReward_ZeroQuantity(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	client := newTestAGSClient(nil, nil, logger)
+
+	ctx := context.Background()
+
+	for _, rewardType := range []string{"ITEM", "WALLET"} {
+		err := client.GrantReward(ctx, "test-ns", "user-123", commonDomain.Reward{Type: rewardType, RewardID: "reward-1"})
+
+		var badReqErr *commonClient.BadRequestError
+		assert.ErrorAs(t, err, &badReqErr, "zero %s reward should return BadRequestError", rewardType)
+		assert.False(t, commonClient.IsRetryableError(err), "zero %s reward must not be retried", rewardType)
+		assert.Contains(t, err.Error(), "is 0")
+	}
+}
+
 // TestGrantWalletReward_AmountNegative tests that negative amount fails validation
 func TestGrantWalletReward_AmountNegative(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: (*platform.EntitlementService)(nil),
-		walletService:      (*platform.WalletService)(nil),
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 
@@ -852,11 +973,7 @@ func TestGrantReward_ItemTypeRouting(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: (*platform.EntitlementService)(nil),
-		walletService:      (*platform.WalletService)(nil),
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 	// Use invalid quantity to trigger GrantItemReward validation (not SDK call)
@@ -881,11 +998,7 @@ func TestGrantReward_WalletTypeRouting(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.PanicLevel)
 
-	client := &AGSRewardClient{
-		entitlementService: (*platform.EntitlementService)(nil),
-		walletService:      (*platform.WalletService)(nil),
-		logger:             logger,
-	}
+	client := newTestAGSClient(nil, nil, logger)
 
 	ctx := context.Background()
 	// Use invalid amount to trigger GrantWalletReward validation (not SDK call)
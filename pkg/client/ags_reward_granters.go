@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclient/entitlement"
+	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclient/wallet"
+	"github.com/AccelByte/accelbyte-go-sdk/platform-sdk/pkg/platformclientmodels"
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/platform"
+	"github.com/sirupsen/logrus"
+
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// ItemGranter grants ITEM rewards as AGS Platform entitlements.
+type ItemGranter struct {
+	agsCaller
+	entitlementService *platform.EntitlementService
+}
+
+// NewItemGranter creates an ItemGranter using the AGS Platform EntitlementService.
+func NewItemGranter(entitlementService *platform.EntitlementService, logger *logrus.Logger) *ItemGranter {
+	return &ItemGranter{
+		agsCaller:          agsCaller{logger: logger},
+		entitlementService: entitlementService,
+	}
+}
+
+// Grant grants reward.Quantity of the item reward.RewardID and returns the ID
+// of the granted entitlement.
+//
+// This method:
+//   - Creates an EntitlementGrant request with itemID, namespace, and quantity,
+//     delivered as the RewardDelivery in ctx, if any (see WithRewardDelivery)
+//   - Calls GrantUserEntitlementShort SDK function
+//   - Retries on transient failures (502/503, timeouts) with exponential backoff
+//   - Fails immediately on non-retryable errors (400, 404, 403)
+func (g *ItemGranter) Grant(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+	itemID, quantity := reward.RewardID, reward.Quantity
+
+	// A zero quantity would grant nothing while the goal is marked claimed
+	if quantity == 0 {
+		return GrantResult{}, &commonClient.BadRequestError{Message: "item quantity is 0, nothing to grant"}
+	}
+
+	// Validate quantity is within int32 range to prevent overflow
+	if quantity < 0 || quantity > 2147483647 {
+		return GrantResult{}, &commonClient.BadRequestError{
+			Message: fmt.Sprintf("quantity %d out of range for int32", quantity),
+		}
+	}
+
+	delivery, _ := RewardDeliveryFrom(ctx)
+
+	var entitlementID string
+	err := g.withRetry(ctx, "grant_item", func() error {
+		// Create entitlement grant request
+		// NOTE: ItemNamespace must equal deployment namespace (no cross-namespace grants)
+		//nolint:gosec // G115: Safe conversion after range validation above
+		quantity32 := int32(quantity)
+		grant := &platformclientmodels.EntitlementGrant{
+			ItemID:        &itemID,
+			ItemNamespace: &namespace,
+			Quantity:      &quantity32,
+			CollectionID:  delivery.CollectionID,
+			StoreID:       delivery.StoreID,
+		}
+
+		transport, retryPolicy := newRequestTransport(g.transport())
+		params := &entitlement.GrantUserEntitlementParams{
+			Namespace:   namespace,
+			UserID:      userID,
+			Body:        []*platformclientmodels.EntitlementGrant{grant}, // NOTE: Body is array, not single grant
+			Context:     ctx,
+			RetryPolicy: retryPolicy,
+		}
+
+		// Call AGS Platform SDK
+		response, err := g.entitlementService.GrantUserEntitlementShort(params)
+		if err != nil {
+			g.logAGSRequestID("grant_item", transport, err)
+			return withRetryAfterHeader(g.wrapSDKError(err, "failed to grant item reward"), transport)
+		}
+
+		// One grant in the body, so one entitlement in the response
+		if len(response) > 0 && response[0] != nil && response[0].ID != nil {
+			entitlementID = *response[0].ID
+		}
+
+		// Log response for audit (don't validate, just log)
+		g.logger.WithFields(logrus.Fields{
+			"namespace":     namespace,
+			"userID":        userID,
+			"itemID":        itemID,
+			"quantity":      quantity,
+			"collectionID":  delivery.CollectionID,
+			"storeID":       delivery.StoreID,
+			"entitlementID": entitlementID,
+			"response":      response,
+		}).Info("Item reward granted successfully")
+
+		return nil
+	})
+
+	return GrantResult{EntitlementID: entitlementID}, err
+}
+
+// transport returns the HTTP transport configured on the entitlement service's
+// SDK client, or nil if none is set.
+func (g *ItemGranter) transport() http.RoundTripper {
+	if g.entitlementService == nil || g.entitlementService.Client == nil || g.entitlementService.Client.Runtime == nil {
+		return nil
+	}
+	return g.entitlementService.Client.Runtime.Transport
+}
+
+// WalletGranter grants WALLET rewards as AGS Platform wallet credits.
+type WalletGranter struct {
+	agsCaller
+	walletService *platform.WalletService
+}
+
+// NewWalletGranter creates a WalletGranter using the AGS Platform WalletService.
+func NewWalletGranter(walletService *platform.WalletService, logger *logrus.Logger) *WalletGranter {
+	return &WalletGranter{
+		agsCaller:     agsCaller{logger: logger},
+		walletService: walletService,
+	}
+}
+
+// Grant credits reward.Quantity of the currency reward.RewardID and returns the
+// ID of the credited wallet.
+//
+// This method:
+//   - Creates a CreditRequest with amount and currencyCode; a RewardDelivery
+//     in ctx does not apply to wallets and is ignored with a warning
+//   - Calls CreditUserWalletShort SDK function (creates wallet if not exists)
+//   - Retries on transient failures (502/503, timeouts) with exponential backoff
+//   - Fails immediately on non-retryable errors (400, 404, 403)
+func (g *WalletGranter) Grant(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+	currencyCode, amount := reward.RewardID, reward.Quantity
+
+	// A zero amount would credit nothing while the goal is marked claimed
+	if amount == 0 {
+		return GrantResult{}, &commonClient.BadRequestError{Message: "wallet amount is 0, nothing to credit"}
+	}
+
+	// Validate amount is non-negative (int64 range is much larger than int, so only check negative)
+	if amount < 0 {
+		return GrantResult{}, &commonClient.BadRequestError{
+			Message: fmt.Sprintf("amount %d cannot be negative", amount),
+		}
+	}
+
+	if delivery, ok := RewardDeliveryFrom(ctx); ok {
+		g.logger.WithFields(logrus.Fields{
+			"namespace":    namespace,
+			"userID":       userID,
+			"currencyCode": currencyCode,
+			"collectionID": delivery.CollectionID,
+			"storeID":      delivery.StoreID,
+		}).Warn("Reward delivery ignored for wallet reward")
+	}
+
+	var walletID string
+	err := g.withRetry(ctx, "grant_wallet", func() error {
+		// Create credit request
+		// NOTE: Amount is int64, not int
+		amount64 := int64(amount) // Safe conversion from int to int64
+		creditReq := &platformclientmodels.CreditRequest{
+			Amount: &amount64,
+			// Optional fields can be added here: Reason, Source, Origin, Metadata
+		}
+
+		transport, retryPolicy := newRequestTransport(g.transport())
+		params := &wallet.CreditUserWalletParams{
+			Namespace:    namespace,
+			UserID:       userID,
+			CurrencyCode: currencyCode,
+			Body:         creditReq,
+			Context:      ctx,
+			RetryPolicy:  retryPolicy,
+		}
+
+		// Call AGS Platform SDK
+		response, err := g.walletService.CreditUserWalletShort(params)
+		if err != nil {
+			g.logAGSRequestID("grant_wallet", transport, err)
+			return withRetryAfterHeader(g.wrapSDKError(err, "failed to credit wallet"), transport)
+		}
+
+		if response != nil && response.ID != nil {
+			walletID = *response.ID
+		}
+
+		// Log response for audit (don't validate, just log)
+		g.logger.WithFields(logrus.Fields{
+			"namespace":    namespace,
+			"userID":       userID,
+			"currencyCode": currencyCode,
+			"amount":       amount,
+			"walletID":     walletID,
+			"response":     response,
+		}).Info("Wallet credited successfully")
+
+		return nil
+	})
+
+	return GrantResult{WalletID: walletID}, err
+}
+
+// transport returns the HTTP transport configured on the wallet service's SDK
+// client, or nil if none is set.
+func (g *WalletGranter) transport() http.RoundTripper {
+	if g.walletService == nil || g.walletService.Client == nil || g.walletService.Client.Runtime == nil {
+		return nil
+	}
+	return g.walletService.Client.Runtime.Transport
+}
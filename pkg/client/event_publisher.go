@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/events"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event publisher modes (EVENT_PUBLISHER).
+const (
+	// EventPublisherLog logs each event instead of sending it anywhere.
+	EventPublisherLog = "log"
+	// EventPublisherKafka produces each event to a Kafka topic through a Kafka REST Proxy.
+	EventPublisherKafka = "kafka"
+
+	// DefaultEventTopic is the Kafka topic events are produced to.
+	DefaultEventTopic = "challenge-events"
+
+	kafkaPublishTimeout = 10 * time.Second
+)
+
+// EventPublisher delivers domain events to other Extend apps.
+type EventPublisher interface {
+	// Publish delivers events in order. It returns nil only once every event was
+	// accepted; after an error the caller publishes all of them again.
+	Publish(ctx context.Context, events []events.Envelope) error
+}
+
+// NewEventPublisherFromEnv returns the publisher selected by EVENT_PUBLISHER:
+//   - unset: nil, events are not published
+//   - "log": LogEventPublisher
+//   - "kafka": KafkaEventPublisher for the REST Proxy at EVENT_KAFKA_REST_URL
+//     and the topic EVENT_KAFKA_TOPIC (default "challenge-events")
+func NewEventPublisherFromEnv(logger *logrus.Logger) (EventPublisher, error) {
+	switch mode := strings.TrimSpace(common.GetEnv("EVENT_PUBLISHER", "")); mode {
+	case "":
+		return nil, nil
+	case EventPublisherLog:
+		return NewLogEventPublisher(logger), nil
+	case EventPublisherKafka:
+		restURL := strings.TrimSpace(common.GetEnv("EVENT_KAFKA_REST_URL", ""))
+		if restURL == "" {
+			return nil, fmt.Errorf("EVENT_KAFKA_REST_URL is required when EVENT_PUBLISHER is %q", EventPublisherKafka)
+		}
+		topic := strings.TrimSpace(common.GetEnv("EVENT_KAFKA_TOPIC", ""))
+		if topic == "" {
+			topic = DefaultEventTopic
+		}
+		return NewKafkaEventPublisher(restURL, topic, &http.Client{Timeout: kafkaPublishTimeout}), nil
+	default:
+		return nil, fmt.Errorf("invalid EVENT_PUBLISHER: %s (must be empty, %q or %q)", mode, EventPublisherLog, EventPublisherKafka)
+	}
+}
+
+// LogEventPublisher logs events at Info level, for local development and for
+// checking what would be published.
+type LogEventPublisher struct {
+	logger *logrus.Logger
+}
+
+// NewLogEventPublisher creates a log-only event publisher.
+func NewLogEventPublisher(logger *logrus.Logger) *LogEventPublisher {
+	return &LogEventPublisher{logger: logger}
+}
+
+// Publish logs each event; it never fails.
+func (p *LogEventPublisher) Publish(_ context.Context, envelopes []events.Envelope) error {
+	for _, envelope := range envelopes {
+		p.logger.WithFields(logrus.Fields{
+			"event_id":    envelope.ID,
+			"event_type":  envelope.Type,
+			"namespace":   envelope.Namespace,
+			"user_id":     envelope.UserID,
+			"occurred_at": envelope.OccurredAt,
+			"data":        string(envelope.Data),
+		}).Info("Published domain event")
+	}
+	return nil
+}
+
+// KafkaEventPublisher produces events through the Kafka REST Proxy v2 API
+// (POST /topics/{topic}), keyed by user ID so a player's events stay in one
+// partition and in order.
+type KafkaEventPublisher struct {
+	produceURL string
+	httpClient *http.Client
+}
+
+// kafkaProduceRequest is the body of a REST Proxy produce call with JSON values.
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value events.Envelope `json:"value"`
+}
+
+// kafkaProduceResponse has one offset per record, in request order.
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaEventPublisher creates a publisher producing to topic through the
+// REST Proxy at restURL.
+func NewKafkaEventPublisher(restURL, topic string, httpClient *http.Client) *KafkaEventPublisher {
+	return &KafkaEventPublisher{
+		produceURL: strings.TrimRight(restURL, "/") + "/topics/" + url.PathEscape(topic),
+		httpClient: httpClient,
+	}
+}
+
+// Publish produces the events in one request. The proxy accepts or rejects
+// each record; any rejected record fails the whole call.
+func (p *KafkaEventPublisher) Publish(ctx context.Context, envelopes []events.Envelope) error {
+	if len(envelopes) == 0 {
+		return nil
+	}
+
+	records := make([]kafkaRecord, len(envelopes))
+	for i, envelope := range envelopes {
+		records[i] = kafkaRecord{Key: envelope.UserID, Value: envelope}
+	}
+	body, err := json.Marshal(kafkaProduceRequest{Records: records})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.produceURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create produce request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce events: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to decode produce response: %w", err)
+	}
+	if len(produced.Offsets) != len(envelopes) {
+		return fmt.Errorf("kafka rest proxy returned %d offsets for %d events", len(produced.Offsets), len(envelopes))
+	}
+	for i, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			message := ""
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+			return fmt.Errorf("kafka rejected event %s: %s", envelopes[i].ID, message)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/events"
+)
+
+func testEnvelopes() []events.Envelope {
+	occurredAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return []events.Envelope{
+		{ID: "e1", Type: events.TypeGoalCompleted, SchemaVersion: events.SchemaVersion, Source: events.Source,
+			Namespace: "ns", UserID: "user-1", OccurredAt: occurredAt, Data: json.RawMessage(`{"goalId":"g1"}`)},
+		{ID: "e2", Type: events.TypeGoalClaimed, SchemaVersion: events.SchemaVersion, Source: events.Source,
+			Namespace: "ns", UserID: "user-2", OccurredAt: occurredAt, Data: json.RawMessage(`{"goalId":"g2"}`)},
+	}
+}
+
+func TestNewEventPublisherFromEnv(t *testing.T) {
+	logger := logrus.New()
+
+	t.Setenv("EVENT_PUBLISHER", "")
+	publisher, err := NewEventPublisherFromEnv(logger)
+	require.NoError(t, err)
+	assert.Nil(t, publisher)
+
+	t.Setenv("EVENT_PUBLISHER", "log")
+	publisher, err = NewEventPublisherFromEnv(logger)
+	require.NoError(t, err)
+	assert.IsType(t, &LogEventPublisher{}, publisher)
+
+	t.Setenv("EVENT_PUBLISHER", "kafka")
+	t.Setenv("EVENT_KAFKA_REST_URL", "")
+	_, err = NewEventPublisherFromEnv(logger)
+	assert.ErrorContains(t, err, "EVENT_KAFKA_REST_URL is required")
+
+	t.Setenv("EVENT_KAFKA_REST_URL", "http://kafka-rest:8082/")
+	t.Setenv("EVENT_KAFKA_TOPIC", "")
+	publisher, err = NewEventPublisherFromEnv(logger)
+	require.NoError(t, err)
+	require.IsType(t, &KafkaEventPublisher{}, publisher)
+	assert.Equal(t, "http://kafka-rest:8082/topics/challenge-events", publisher.(*KafkaEventPublisher).produceURL)
+
+	t.Setenv("EVENT_PUBLISHER", "sqs")
+	_, err = NewEventPublisherFromEnv(logger)
+	assert.ErrorContains(t, err, "invalid EVENT_PUBLISHER")
+}
+
+func TestLogEventPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+
+	err := NewLogEventPublisher(logger).Publish(context.Background(), testEnvelopes())
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "event_type=goal.completed")
+	assert.Contains(t, buf.String(), "event_id=e2")
+}
+
+func TestKafkaEventPublisher_Publish(t *testing.T) {
+	var got kafkaProduceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/topics/challenge-events", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &got))
+
+		w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":10},{"partition":1,"offset":4}]}`))
+	}))
+	defer server.Close()
+
+	publisher := NewKafkaEventPublisher(server.URL, DefaultEventTopic, server.Client())
+	err := publisher.Publish(context.Background(), testEnvelopes())
+
+	require.NoError(t, err)
+	require.Len(t, got.Records, 2)
+	assert.Equal(t, "user-1", got.Records[0].Key, "records are keyed by user ID")
+	assert.Equal(t, "e1", got.Records[0].Value.ID)
+	assert.Equal(t, events.TypeGoalClaimed, got.Records[1].Value.Type)
+	assert.JSONEq(t, `{"goalId":"g2"}`, string(got.Records[1].Value.Data))
+}
+
+func TestKafkaEventPublisher_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "record rejected",
+			status:  http.StatusOK,
+			body:    `{"offsets":[{"partition":0,"offset":10},{"error_code":50002,"error":"leader not available"}]}`,
+			wantErr: "kafka rejected event e2: leader not available",
+		},
+		{
+			name:    "missing offsets",
+			status:  http.StatusOK,
+			body:    `{"offsets":[{"partition":0,"offset":10}]}`,
+			wantErr: "returned 1 offsets for 2 events",
+		},
+		{
+			name:    "proxy error",
+			status:  http.StatusNotFound,
+			body:    `{"error_code":40401,"message":"Topic not found."}`,
+			wantErr: "kafka rest proxy returned 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewKafkaEventPublisher(server.URL, DefaultEventTopic, server.Client()).
+				Publish(context.Background(), testEnvelopes())
+
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestKafkaEventPublisher_NoEvents(t *testing.T) {
+	publisher := NewKafkaEventPublisher("http://127.0.0.1:0", DefaultEventTopic, http.DefaultClient)
+
+	assert.NoError(t, publisher.Publish(context.Background(), nil))
+}
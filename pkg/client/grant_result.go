@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// GrantResult identifies what AGS Platform created for a reward grant, so
+// support can find it when a player disputes a reward. Only the field for the
+// reward type is set.
+type GrantResult struct {
+	// EntitlementID is the entitlement granted for an ITEM reward.
+	EntitlementID string
+	// WalletID is the wallet credited for a WALLET reward. AGS returns the wallet,
+	// not the credit transaction, so the transaction is found in the wallet's
+	// history at the claim time.
+	WalletID string
+}
+
+// IsZero reports whether the grant returned no IDs.
+func (r GrantResult) IsZero() bool {
+	return r.EntitlementID == "" && r.WalletID == ""
+}
+
+// GrantResultRewardClient is a RewardClient that also reports what it granted.
+// The RewardClient interface from extend-challenge-common only returns an error,
+// so clients in this package implement this extension.
+type GrantResultRewardClient interface {
+	commonClient.RewardClient
+
+	// GrantRewardWithResult grants the reward like GrantReward and returns the
+	// IDs AGS assigned to the grant.
+	GrantRewardWithResult(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error)
+}
+
+// GrantRewardWithResult grants the reward through rewardClient. Clients that do
+// not implement GrantResultRewardClient are called through GrantReward and
+// yield a zero GrantResult.
+func GrantRewardWithResult(
+	ctx context.Context,
+	rewardClient commonClient.RewardClient,
+	namespace, userID string,
+	reward commonDomain.Reward,
+) (GrantResult, error) {
+	if withResult, ok := rewardClient.(GrantResultRewardClient); ok {
+		return withResult.GrantRewardWithResult(ctx, namespace, userID, reward)
+	}
+
+	return GrantResult{}, rewardClient.GrantReward(ctx, namespace, userID, reward)
+}
+
+// MockGrantResult fabricates the IDs of a grant for clients that do not call
+// AGS. The IDs are derived from the inputs, so tests can predict them.
+func MockGrantResult(namespace, userID string, reward commonDomain.Reward) GrantResult {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s/%s/%s/%s/%d", namespace, userID, reward.Type, reward.RewardID, reward.Quantity))
+	id := hex.EncodeToString(sum[:8])
+
+	switch reward.Type {
+	case RewardTypeItem:
+		return GrantResult{EntitlementID: "mock-entitlement-" + id}
+	case RewardTypeWallet:
+		return GrantResult{WalletID: "mock-wallet-" + id}
+	default:
+		return GrantResult{}
+	}
+}
+
+// mockGrantResultClient adds fabricated grant IDs to a RewardClient that does
+// not call AGS.
+type mockGrantResultClient struct {
+	commonClient.RewardClient
+}
+
+// WithMockGrantResults wraps a mock RewardClient (such as the common
+// DevMockRewardClient) so that its grants report MockGrantResult IDs.
+func WithMockGrantResults(rewardClient commonClient.RewardClient) GrantResultRewardClient {
+	return &mockGrantResultClient{RewardClient: rewardClient}
+}
+
+// GrantRewardWithResult grants through the wrapped client and fabricates the
+// IDs. The wrapped client does not know about reward deliveries, so the
+// delivery in ctx, if any, is logged here.
+func (c *mockGrantResultClient) GrantRewardWithResult(
+	ctx context.Context,
+	namespace, userID string,
+	reward commonDomain.Reward,
+) (GrantResult, error) {
+	if err := c.GrantReward(ctx, namespace, userID, reward); err != nil {
+		return GrantResult{}, err
+	}
+	if fields := deliveryLogFields(ctx); fields != nil {
+		logrus.WithFields(logrus.Fields{
+			"namespace":   namespace,
+			"user_id":     userID,
+			"reward_type": reward.Type,
+			"reward_id":   reward.RewardID,
+		}).WithFields(fields).Info("[MOCK] Reward delivered")
+	}
+
+	return MockGrantResult(namespace, userID, reward), nil
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+)
+
+func TestMockGrantResult(t *testing.T) {
+	item := commonDomain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}
+	wallet := commonDomain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 100}
+
+	itemResult := MockGrantResult("ns", "user1", item)
+	assert.Regexp(t, `^mock-entitlement-[0-9a-f]{16}$`, itemResult.EntitlementID)
+	assert.Empty(t, itemResult.WalletID)
+	assert.Equal(t, itemResult, MockGrantResult("ns", "user1", item), "IDs are deterministic")
+	assert.NotEqual(t, itemResult, MockGrantResult("ns", "user2", item))
+
+	walletResult := MockGrantResult("ns", "user1", wallet)
+	assert.Regexp(t, `^mock-wallet-[0-9a-f]{16}$`, walletResult.WalletID)
+	assert.Empty(t, walletResult.EntitlementID)
+
+	assert.True(t, MockGrantResult("ns", "user1", commonDomain.Reward{Type: "UNKNOWN"}).IsZero())
+}
+
+func TestGrantRewardWithResult_PlainClientYieldsZeroResult(t *testing.T) {
+	reward := commonDomain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}
+	rewardClient := new(mocks.RewardClient)
+	rewardClient.On("GrantReward", context.Background(), "ns", "user1", reward).Return(nil)
+
+	result, err := GrantRewardWithResult(context.Background(), rewardClient, "ns", "user1", reward)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsZero())
+	rewardClient.AssertExpectations(t)
+}
+
+func TestGrantRewardWithResult_UsesResultClient(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	reward := commonDomain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 100}
+
+	result, err := GrantRewardWithResult(context.Background(), NewNoOpRewardClient(logger), "ns", "user1", reward)
+
+	require.NoError(t, err)
+	assert.Equal(t, MockGrantResult("ns", "user1", reward), result)
+}
+
+func TestWithMockGrantResults(t *testing.T) {
+	reward := commonDomain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}
+
+	t.Run("success", func(t *testing.T) {
+		rewardClient := new(mocks.RewardClient)
+		rewardClient.On("GrantReward", context.Background(), "ns", "user1", reward).Return(nil)
+
+		result, err := WithMockGrantResults(rewardClient).GrantRewardWithResult(context.Background(), "ns", "user1", reward)
+
+		require.NoError(t, err)
+		assert.Equal(t, MockGrantResult("ns", "user1", reward), result)
+	})
+
+	t.Run("grant fails", func(t *testing.T) {
+		rewardClient := new(mocks.RewardClient)
+		rewardClient.On("GrantReward", context.Background(), "ns", "user1", reward).Return(errors.New("boom"))
+
+		result, err := WithMockGrantResults(rewardClient).GrantRewardWithResult(context.Background(), "ns", "user1", reward)
+
+		assert.EqualError(t, err, "boom")
+		assert.True(t, result.IsZero())
+	})
+
+	t.Run("logs delivery", func(t *testing.T) {
+		hook := test.NewGlobal()
+		defer hook.Reset()
+		ctx := WithRewardDelivery(context.Background(), RewardDelivery{CollectionID: "season-stash"})
+		rewardClient := new(mocks.RewardClient)
+		rewardClient.On("GrantReward", ctx, "ns", "user1", reward).Return(nil)
+
+		_, err := WithMockGrantResults(rewardClient).GrantRewardWithResult(ctx, "ns", "user1", reward)
+
+		require.NoError(t, err)
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, "season-stash", entry.Data["delivery_collection_id"])
+	})
+}
@@ -52,19 +52,37 @@ func (c *NoOpRewardClient) GrantWalletReward(ctx context.Context, namespace, use
 	return nil
 }
 
-// GrantReward dispatches to the appropriate grant method based on reward type
+// GrantReward logs the reward grant, with its delivery (see WithRewardDelivery),
+// instead of calling AGS. Every reward type is accepted, so new types need no
+// change here.
 func (c *NoOpRewardClient) GrantReward(ctx context.Context, namespace, userID string, reward commonDomain.Reward) error {
-	switch reward.Type {
-	case "ITEM":
-		return c.GrantItemReward(ctx, namespace, userID, reward.RewardID, reward.Quantity)
-	case "WALLET":
-		return c.GrantWalletReward(ctx, namespace, userID, reward.RewardID, reward.Quantity)
-	default:
-		c.logger.WithFields(logrus.Fields{
-			"namespace":   namespace,
-			"user_id":     userID,
-			"reward_type": reward.Type,
-		}).Warn("[NO-OP] Unknown reward type")
+	c.logger.WithFields(logrus.Fields{
+		"namespace":   namespace,
+		"user_id":     userID,
+		"reward_type": reward.Type,
+		"reward_id":   reward.RewardID,
+		"quantity":    reward.Quantity,
+	}).WithFields(deliveryLogFields(ctx)).Info("[NO-OP] Would grant reward (AGS integration in Phase 7)")
+	return nil
+}
+
+// deliveryLogFields returns the log fields of the reward delivery in ctx, if any.
+func deliveryLogFields(ctx context.Context) logrus.Fields {
+	delivery, ok := RewardDeliveryFrom(ctx)
+	if !ok {
 		return nil
 	}
+	return logrus.Fields{
+		"delivery_collection_id": delivery.CollectionID,
+		"delivery_store_id":      delivery.StoreID,
+	}
+}
+
+// GrantRewardWithResult logs the reward grant like GrantReward and returns
+// deterministic fake IDs (see MockGrantResult)
+func (c *NoOpRewardClient) GrantRewardWithResult(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+	if err := c.GrantReward(ctx, namespace, userID, reward); err != nil {
+		return GrantResult{}, err
+	}
+	return MockGrantResult(namespace, userID, reward), nil
 }
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxRetryAfterDelay caps how long withRetry waits for a Retry-After, so a
+// large value cannot hold a claim past the retry loop's total timeout.
+var maxRetryAfterDelay = 5 * time.Second
+
+// retryAfterPattern matches a Retry-After value echoed in an AGS error body,
+// e.g. "Retry-After: 2" or {"retryAfter":2}, in seconds.
+var retryAfterPattern = regexp.MustCompile(`(?i)retry[-_]?after"?\s*[:=]\s*"?(\d+)`)
+
+// rateLimited counts AGS 429 responses. The granters share it, so it is a
+// package variable; main registers it through RateLimitCollectors.
+var rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ags_rate_limited_total",
+	Help: "AGS Platform calls answered with 429 Too Many Requests, by operation.",
+}, []string{"operation"})
+
+// RateLimitCollectors returns the AGS rate limit metrics for registration.
+func RateLimitCollectors() []prometheus.Collector {
+	return []prometheus.Collector{rateLimited}
+}
+
+// RateLimitedError is returned when AGS answers 429 Too Many Requests.
+// RetryAfter is the wait AGS asked for, or 0 if it did not send one.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("AGS rate limited (retry after %s): %s", e.RetryAfter, e.Message)
+	}
+	return fmt.Sprintf("AGS rate limited: %s", e.Message)
+}
+
+// HTTPStatusCode returns 429, so commonClient.IsRetryableError retries it.
+func (e *RateLimitedError) HTTPStatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// parseRetryAfter parses a Retry-After header value, in seconds or as an HTTP
+// date. It returns 0 if value is empty, invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryAfterFromMessage returns the Retry-After echoed in an SDK error message,
+// or 0 if there is none.
+func retryAfterFromMessage(message string) time.Duration {
+	matches := retryAfterPattern.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return 0
+	}
+	return parseRetryAfter(matches[1], time.Now())
+}
+
+// withRetryAfterHeader fills in the Retry-After header of the failed response
+// seen by transport when err is a RateLimitedError whose message carried none.
+func withRetryAfterHeader(err error, transport *agsHeaderTransport) error {
+	var rateLimitedErr *RateLimitedError
+	if errors.As(err, &rateLimitedErr) && rateLimitedErr.RetryAfter == 0 {
+		rateLimitedErr.RetryAfter = transport.RetryAfter()
+	}
+	return err
+}
+
+// retryDelay returns how long withRetry waits after err: the backoff delay,
+// or the Retry-After of a RateLimitedError if longer, capped at maxRetryAfterDelay.
+func retryDelay(backoff time.Duration, err error) time.Duration {
+	var rateLimitedErr *RateLimitedError
+	if !errors.As(err, &rateLimitedErr) {
+		return backoff
+	}
+	return max(backoff, min(rateLimitedErr.RetryAfter, maxRetryAfterDelay))
+}
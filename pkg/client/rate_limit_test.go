@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+)
+
+// undocumented429 fabricates the SDK error for a 429 response, which the AGS
+// Platform spec does not document, with the given body.
+func undocumented429(body string) error {
+	return errors.New("Requested POST /platform/admin/namespaces/{namespace}/users/{userId}/entitlements returns an error 429: " + body)
+}
+
+func TestExtractStatusCode_Undocumented429(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	caller := &agsCaller{logger: logger}
+
+	statusCode, ok := caller.extractStatusCode(undocumented429(`{"errorCode":20007,"errorMessage":"too many requests"}`))
+
+	assert.True(t, ok)
+	assert.Equal(t, 429, statusCode)
+}
+
+func TestWrapSDKError_RateLimited(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	caller := &agsCaller{logger: logger}
+
+	tests := []struct {
+		name           string
+		body           string
+		wantRetryAfter time.Duration
+	}{
+		{name: "no retry after", body: `{"errorCode":20007,"errorMessage":"too many requests"}`, wantRetryAfter: 0},
+		{name: "retryAfter field", body: `{"errorCode":20007,"errorMessage":"too many requests","retryAfter":3}`, wantRetryAfter: 3 * time.Second},
+		{name: "header echoed in body", body: `Too Many Requests (Retry-After: 2)`, wantRetryAfter: 2 * time.Second},
+		{name: "quoted header name", body: `{"Retry-After":"4"}`, wantRetryAfter: 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := caller.wrapSDKError(undocumented429(tt.body), "failed to grant item reward")
+
+			var rateLimitedErr *RateLimitedError
+			require.ErrorAs(t, err, &rateLimitedErr)
+			assert.Equal(t, tt.wantRetryAfter, rateLimitedErr.RetryAfter)
+			assert.Equal(t, http.StatusTooManyRequests, rateLimitedErr.HTTPStatusCode())
+			assert.True(t, commonClient.IsRetryableError(err))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("0", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now), "a date in the past")
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff := 500 * time.Millisecond
+
+	assert.Equal(t, backoff, retryDelay(backoff, &commonClient.AGSError{StatusCode: 503}))
+	assert.Equal(t, backoff, retryDelay(backoff, &RateLimitedError{}), "no Retry-After keeps the backoff")
+	assert.Equal(t, backoff, retryDelay(backoff, &RateLimitedError{RetryAfter: 100 * time.Millisecond}), "a shorter Retry-After keeps the backoff")
+	assert.Equal(t, 2*time.Second, retryDelay(backoff, &RateLimitedError{RetryAfter: 2 * time.Second}))
+	assert.Equal(t, maxRetryAfterDelay, retryDelay(backoff, &RateLimitedError{RetryAfter: time.Hour}), "capped")
+}
+
+func TestWithRetry_HonorsRetryAfter(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	caller := &agsCaller{logger: logger}
+	before := testutil.ToFloat64(rateLimited.WithLabelValues("test_rate_limited"))
+
+	var callTimes []time.Time
+	err := caller.withRetry(context.Background(), "test_rate_limited", func() error {
+		callTimes = append(callTimes, time.Now())
+		if len(callTimes) == 1 {
+			return &RateLimitedError{RetryAfter: 1200 * time.Millisecond, Message: "too many requests"}
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, callTimes, 2)
+	// Retry-After (1.2s) is longer than the first backoff (500ms)
+	assert.GreaterOrEqual(t, callTimes[1].Sub(callTimes[0]), 1200*time.Millisecond)
+	assert.Equal(t, before+1, testutil.ToFloat64(rateLimited.WithLabelValues("test_rate_limited")))
+}
+
+func TestWithRetry_RetryAfterCapped(t *testing.T) {
+	previous := maxRetryAfterDelay
+	maxRetryAfterDelay = 700 * time.Millisecond
+	t.Cleanup(func() { maxRetryAfterDelay = previous })
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	caller := &agsCaller{logger: logger}
+
+	var callTimes []time.Time
+	err := caller.withRetry(context.Background(), "test_rate_limited_cap", func() error {
+		callTimes = append(callTimes, time.Now())
+		if len(callTimes) == 1 {
+			return &RateLimitedError{RetryAfter: time.Minute, Message: "too many requests"}
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, callTimes, 2)
+	assert.InDelta(t, 700*time.Millisecond, callTimes[1].Sub(callTimes[0]), float64(200*time.Millisecond))
+}
+
+// rateLimitOnceTransport answers the first request with 429 and the given
+// Retry-After header, and every later one with 201.
+type rateLimitOnceTransport struct {
+	retryAfter string
+
+	mu        sync.Mutex
+	callTimes []time.Time
+}
+
+func (f *rateLimitOnceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.callTimes = append(f.callTimes, time.Now())
+	first := len(f.callTimes) == 1
+	f.mu.Unlock()
+
+	resp := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`[{"id":"entitlement-1"}]`)),
+		Request:    req,
+	}
+	if first {
+		resp.StatusCode = http.StatusTooManyRequests
+		resp.Header.Set("Retry-After", f.retryAfter)
+		resp.Body = io.NopCloser(strings.NewReader(`{"errorCode":20007,"errorMessage":"too many requests"}`))
+	}
+	return resp, nil
+}
+
+func TestGrantItemReward_RateLimitedRetryAfterHeader(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	transport := &rateLimitOnceTransport{retryAfter: "1"}
+	c := newFakeAGSClient(t, transport, logger)
+	before := testutil.ToFloat64(rateLimited.WithLabelValues("grant_item"))
+
+	err := c.GrantItemReward(context.Background(), "test-namespace", "user123", "item123", 1)
+
+	require.NoError(t, err)
+	require.Len(t, transport.callTimes, 2)
+	// The header's 1s is longer than the first backoff (500ms)
+	assert.GreaterOrEqual(t, transport.callTimes[1].Sub(transport.callTimes[0]), time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(rateLimited.WithLabelValues("grant_item")))
+}
+
+func TestAGSHeaderTransport_RetryAfter(t *testing.T) {
+	base := &fakeTransport{
+		status:  http.StatusTooManyRequests,
+		headers: http.Header{"Retry-After": []string{"3"}},
+	}
+	transport, _ := newRequestTransport(base)
+
+	req, err := http.NewRequest(http.MethodPost, "https://ags.example.com/", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, 3*time.Second, transport.RetryAfter())
+}
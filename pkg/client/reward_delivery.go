@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+)
+
+// RewardDelivery says where the entitlement of an ITEM reward is delivered,
+// e.g. a season stash collection instead of the main inventory. The fields
+// are passed to AGS Platform as the EntitlementGrant's collectionId and
+// storeId; empty fields are left to AGS defaults. WALLET rewards ignore it.
+type RewardDelivery struct {
+	CollectionID string `json:"collectionId,omitempty"`
+	StoreID      string `json:"storeId,omitempty"`
+}
+
+// IsZero reports whether the delivery leaves everything to AGS defaults.
+func (d RewardDelivery) IsZero() bool {
+	return d == RewardDelivery{}
+}
+
+type rewardDeliveryKey struct{}
+
+// WithRewardDelivery returns a context whose ITEM reward grants are delivered
+// as d. A zero delivery leaves ctx unchanged.
+func WithRewardDelivery(ctx context.Context, d RewardDelivery) context.Context {
+	if d.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, rewardDeliveryKey{}, d)
+}
+
+// RewardDeliveryFrom returns the delivery set by WithRewardDelivery, if any.
+func RewardDeliveryFrom(ctx context.Context) (RewardDelivery, bool) {
+	d, ok := ctx.Value(rewardDeliveryKey{}).(RewardDelivery)
+	return d, ok
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+func TestWithRewardDelivery(t *testing.T) {
+	ctx := context.Background()
+	_, ok := RewardDeliveryFrom(WithRewardDelivery(ctx, RewardDelivery{}))
+	assert.False(t, ok, "a zero delivery is not set")
+
+	got, ok := RewardDeliveryFrom(WithRewardDelivery(ctx, RewardDelivery{CollectionID: "season-stash"}))
+	assert.True(t, ok)
+	assert.Equal(t, RewardDelivery{CollectionID: "season-stash"}, got)
+}
+
+func TestGrantItemReward_Delivery(t *testing.T) {
+	transport := &fakeTransport{status: http.StatusCreated, body: `[{"id":"ent-1"}]`}
+	c := newFakeAGSClient(t, transport, logrus.New())
+
+	ctx := WithRewardDelivery(context.Background(), RewardDelivery{CollectionID: "season-stash", StoreID: "store-1"})
+	err := c.GrantItemReward(ctx, "test-namespace", "user123", "item123", 2)
+	require.NoError(t, err)
+
+	req := transport.lastRequest(t)
+	require.NotNil(t, req.GetBody)
+	body, err := req.GetBody()
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var grants []map[string]any
+	require.NoError(t, json.Unmarshal(data, &grants))
+	require.Len(t, grants, 1)
+	assert.Equal(t, "item123", grants[0]["itemId"])
+	assert.Equal(t, "season-stash", grants[0]["collectionId"])
+	assert.Equal(t, "store-1", grants[0]["storeId"])
+}
+
+func TestGrantItemReward_NoDelivery(t *testing.T) {
+	transport := &fakeTransport{status: http.StatusCreated, body: `[]`}
+	c := newFakeAGSClient(t, transport, logrus.New())
+
+	require.NoError(t, c.GrantItemReward(context.Background(), "test-namespace", "user123", "item123", 1))
+
+	body, err := transport.lastRequest(t).GetBody()
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "collectionId")
+	assert.NotContains(t, string(data), "storeId")
+}
+
+func TestGrantWalletReward_IgnoresDelivery(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	transport := &fakeTransport{status: http.StatusOK, body: `{"id":"wallet-1"}`}
+	c := newFakeAGSClient(t, transport, logger)
+
+	ctx := WithRewardDelivery(context.Background(), RewardDelivery{CollectionID: "season-stash"})
+	err := c.GrantReward(ctx, "test-namespace", "user123", commonDomain.Reward{Type: RewardTypeWallet, RewardID: "GOLD", Quantity: 10})
+	require.NoError(t, err)
+
+	var warned bool
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel && entry.Message == "Reward delivery ignored for wallet reward" {
+			warned = true
+			assert.Equal(t, "season-stash", entry.Data["collectionID"])
+		}
+	}
+	assert.True(t, warned)
+}
+
+func TestNoOpRewardClient_LogsDelivery(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	c := NewNoOpRewardClient(logger)
+
+	ctx := WithRewardDelivery(context.Background(), RewardDelivery{CollectionID: "season-stash", StoreID: "store-1"})
+	require.NoError(t, c.GrantReward(ctx, "test-namespace", "user123", commonDomain.Reward{Type: RewardTypeItem, RewardID: "item123", Quantity: 1}))
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "season-stash", entry.Data["delivery_collection_id"])
+	assert.Equal(t, "store-1", entry.Data["delivery_store_id"])
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// Reward types granted by the granters in this package.
+const (
+	RewardTypeItem   = "ITEM"
+	RewardTypeWallet = "WALLET"
+)
+
+// RewardGranter grants rewards of one type. It validates the reward itself and
+// returns a *commonClient.BadRequestError for rewards it cannot grant.
+type RewardGranter interface {
+	Grant(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error)
+}
+
+// RewardGranterFunc adapts a function to a RewardGranter.
+type RewardGranterFunc func(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error)
+
+// Grant calls f.
+func (f RewardGranterFunc) Grant(ctx context.Context, namespace, userID string, reward commonDomain.Reward) (GrantResult, error) {
+	return f(ctx, namespace, userID, reward)
+}
+
+// RewardGranters maps a reward type (Reward.Type) to the granter for it.
+type RewardGranters map[string]RewardGranter
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// loginRetryBaseDelay is the wait after the first failed re-login; it
+	// doubles per failure up to loginRetryMaxDelay.
+	loginRetryBaseDelay = time.Second
+	loginRetryMaxDelay  = time.Minute
+)
+
+// TokenMonitor tracks whether the service's IAM client token is still valid.
+//
+// The SDK refreshes the token in the background; TokenMonitor only inspects the
+// locally stored token on a timer and caches the result, so health probes can
+// report IAM status without calling AGS.
+//
+// The SDK's refresh gives up after a failed attempt, so with a login function
+// (SetLogin) the monitor also repairs the token: while it is invalid, Run
+// re-runs the login with backoff until it succeeds.
+type TokenMonitor struct {
+	tokenRepo repository.TokenRepository
+	logger    *logrus.Logger
+	now       func() time.Time
+	login     func() error
+
+	// wake makes Run evaluate the token before the next tick
+	wake chan struct{}
+
+	mu  sync.RWMutex
+	err error
+
+	// loginMu serializes logins; lastLogin is when the last one succeeded
+	loginMu   sync.Mutex
+	lastLogin time.Time
+
+	valid   prometheus.GaugeFunc
+	relogin *prometheus.CounterVec
+}
+
+// NewTokenMonitor creates a token monitor and records the current token status.
+func NewTokenMonitor(tokenRepo repository.TokenRepository, logger *logrus.Logger) *TokenMonitor {
+	m := &TokenMonitor{
+		tokenRepo: tokenRepo,
+		logger:    logger,
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+		relogin: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iam_token_relogin_total",
+			Help: "IAM client logins re-run by the token monitor, by result (success, error).",
+		}, []string{"result"}),
+	}
+	m.valid = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "iam_token_valid",
+		Help: "1 while the service's IAM client token is valid, 0 while AGS calls would be rejected as unauthenticated.",
+	}, func() float64 {
+		if m.Check(context.Background()) != nil {
+			return 0
+		}
+		return 1
+	})
+	m.Refresh()
+	return m
+}
+
+// SetLogin sets the function that logs the service in to IAM again, e.g.
+// OAuth20Service.LoginClient. Without one the monitor only reports the status.
+func (m *TokenMonitor) SetLogin(login func() error) {
+	m.login = login
+}
+
+// Collectors returns the token metrics for registration.
+func (m *TokenMonitor) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.valid, m.relogin}
+}
+
+// Run refreshes the token status every interval until ctx is cancelled. While
+// the token is invalid and a login function is set, it re-runs the login,
+// waiting from loginRetryBaseDelay up to loginRetryMaxDelay between failures.
+func (m *TokenMonitor) Run(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	var backoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-m.wake:
+		}
+
+		next := interval
+		m.Refresh()
+		if m.Check(ctx) != nil && m.login != nil {
+			if err := m.loginAfter(time.Time{}); err != nil {
+				backoff = min(max(2*backoff, loginRetryBaseDelay), loginRetryMaxDelay)
+				next = backoff
+			} else {
+				backoff = 0
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// Refresh re-evaluates the stored token and updates the cached status.
+func (m *TokenMonitor) Refresh() {
+	err := m.evaluate()
+
+	m.mu.Lock()
+	previous := m.err
+	m.err = err
+	m.mu.Unlock()
+
+	if err != nil && previous == nil {
+		m.logger.WithError(err).Error("IAM token is no longer valid")
+	} else if err == nil && previous != nil {
+		m.logger.Info("IAM token is valid again")
+	}
+}
+
+// Check returns the cached token status. It satisfies the health check signature.
+func (m *TokenMonitor) Check(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
+
+// RefreshToken logs in again now, for a caller whose AGS request was rejected
+// with 401, and wakes Run. Callers rejected at the same time share one login:
+// one that waited for another caller's successful login returns at once.
+// Without a login function it only wakes Run and returns an error.
+func (m *TokenMonitor) RefreshToken(ctx context.Context) error {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+
+	if m.login == nil {
+		return errors.New("no IAM login configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.loginAfter(m.now())
+}
+
+// loginAfter runs the login unless one succeeded after since, and re-evaluates
+// the token.
+func (m *TokenMonitor) loginAfter(since time.Time) error {
+	m.loginMu.Lock()
+	defer m.loginMu.Unlock()
+
+	if !since.IsZero() && m.lastLogin.After(since) {
+		return nil
+	}
+
+	if err := m.login(); err != nil {
+		m.relogin.WithLabelValues("error").Inc()
+		m.logger.WithError(err).Warn("IAM re-login failed")
+		return err
+	}
+
+	m.relogin.WithLabelValues("success").Inc()
+	m.lastLogin = m.now()
+	m.logger.Info("Re-logged in to AGS IAM")
+	m.Refresh()
+	return nil
+}
+
+func (m *TokenMonitor) evaluate() error {
+	token, err := m.tokenRepo.GetToken()
+	if err != nil || token == nil || token.AccessToken == nil || *token.AccessToken == "" {
+		return errors.New("no IAM token available")
+	}
+
+	if token.ExpiresIn == nil {
+		return nil
+	}
+
+	expiresAt := m.tokenRepo.TokenIssuedTimeUTC().Add(time.Duration(*token.ExpiresIn) * time.Second)
+	if !m.now().UTC().Before(expiresAt) {
+		return errors.New("IAM token expired and has not been refreshed")
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/iam-sdk/pkg/iamclientmodels"
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storeToken(t *testing.T, tokenRepo *auth.TokenRepositoryImpl, expiresIn int32) {
+	t.Helper()
+	accessToken := "test-token"
+	require.NoError(t, tokenRepo.Store(iamclientmodels.OauthmodelTokenResponseV3{
+		AccessToken: &accessToken,
+		ExpiresIn:   &expiresIn,
+	}))
+}
+
+func TestTokenMonitor_NoToken(t *testing.T) {
+	monitor := NewTokenMonitor(&auth.TokenRepositoryImpl{}, logrus.New())
+
+	assert.EqualError(t, monitor.Check(context.Background()), "no IAM token available")
+}
+
+func TestTokenMonitor_ValidToken(t *testing.T) {
+	tokenRepo := &auth.TokenRepositoryImpl{}
+	storeToken(t, tokenRepo, 3600)
+
+	monitor := NewTokenMonitor(tokenRepo, logrus.New())
+
+	assert.NoError(t, monitor.Check(context.Background()))
+}
+
+func TestTokenMonitor_ExpiredTokenCachedUntilRefresh(t *testing.T) {
+	tokenRepo := &auth.TokenRepositoryImpl{}
+	storeToken(t, tokenRepo, 3600)
+
+	monitor := NewTokenMonitor(tokenRepo, logrus.New())
+	require.NoError(t, monitor.Check(context.Background()))
+
+	// Two hours later the token has expired, but Check keeps the cached status until Refresh
+	monitor.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.NoError(t, monitor.Check(context.Background()))
+
+	monitor.Refresh()
+	assert.EqualError(t, monitor.Check(context.Background()), "IAM token expired and has not been refreshed")
+
+	// The SDK refreshes the token; the next evaluation picks it up
+	monitor.now = time.Now
+	storeToken(t, tokenRepo, 3600)
+	monitor.Refresh()
+	assert.NoError(t, monitor.Check(context.Background()))
+}
+
+func TestTokenMonitor_RunStopsOnCancel(t *testing.T) {
+	tokenRepo := &auth.TokenRepositoryImpl{}
+	monitor := NewTokenMonitor(tokenRepo, logrus.New())
+	require.Error(t, monitor.Check(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		monitor.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	storeToken(t, tokenRepo, 3600)
+	assert.Eventually(t, func() bool { return monitor.Check(context.Background()) == nil },
+		time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+}
+
+func TestTokenMonitor_RunLogsInAgain(t *testing.T) {
+	tokenRepo := &auth.TokenRepositoryImpl{}
+	monitor := NewTokenMonitor(tokenRepo, logrus.New())
+	require.Error(t, monitor.Check(context.Background()))
+
+	var mu sync.Mutex
+	logins := 0
+	monitor.SetLogin(func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		logins++
+		if logins == 1 {
+			return errors.New("network blip")
+		}
+		storeToken(t, tokenRepo, 3600)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Run(ctx, 10*time.Millisecond)
+
+	// The first login fails; the retry after loginRetryBaseDelay succeeds
+	assert.Eventually(t, func() bool { return monitor.Check(context.Background()) == nil },
+		3*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 2, logins)
+	mu.Unlock()
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitor.relogin.WithLabelValues("error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitor.relogin.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitor.valid))
+}
+
+func TestTokenMonitor_RefreshToken(t *testing.T) {
+	tokenRepo := &auth.TokenRepositoryImpl{}
+	monitor := NewTokenMonitor(tokenRepo, logrus.New())
+	assert.Equal(t, 0.0, testutil.ToFloat64(monitor.valid))
+	assert.Error(t, monitor.RefreshToken(context.Background()))
+
+	logins := 0
+	monitor.SetLogin(func() error {
+		logins++
+		storeToken(t, tokenRepo, 3600)
+		return nil
+	})
+
+	require.NoError(t, monitor.RefreshToken(context.Background()))
+	assert.Equal(t, 1, logins)
+	assert.NoError(t, monitor.Check(context.Background()))
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitor.valid))
+
+	// A caller rejected before the last login shares it
+	assert.NoError(t, monitor.loginAfter(monitor.lastLogin.Add(-time.Second)))
+	assert.Equal(t, 1, logins)
+
+	// A caller rejected after it logs in again
+	require.NoError(t, monitor.RefreshToken(context.Background()))
+	assert.Equal(t, 2, logins)
+}
+
+func TestTokenMonitor_RefreshTokenLoginFails(t *testing.T) {
+	monitor := NewTokenMonitor(&auth.TokenRepositoryImpl{}, logrus.New())
+	monitor.SetLogin(func() error { return errors.New("invalid client secret") })
+
+	assert.EqualError(t, monitor.RefreshToken(context.Background()), "invalid client secret")
+	assert.Equal(t, 1.0, testutil.ToFloat64(monitor.relogin.WithLabelValues("error")))
+	assert.Error(t, monitor.Check(context.Background()))
+}
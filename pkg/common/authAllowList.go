@@ -0,0 +1,78 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// defaultAuthAllowedMethods are served without authentication. Health probes
+// must not depend on IAM: a probe failing while the token validator refreshes
+// would get the pod restarted.
+var defaultAuthAllowedMethods = []string{
+	pb.Service_HealthCheck_FullMethodName,
+	grpc_health_v1.Health_Check_FullMethodName,
+	grpc_health_v1.Health_List_FullMethodName,
+	grpc_health_v1.Health_Watch_FullMethodName,
+	reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName,
+	reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName,
+}
+
+// AuthAllowList is the set of full gRPC method names (e.g.
+// "/service.Service/HealthCheck") that the auth interceptors pass through
+// without checking the authorization metadata. Handlers of allowed methods get
+// no user claims in their context.
+type AuthAllowList map[string]bool
+
+// NewAuthAllowList returns the default allow-list (HealthCheck, the gRPC health
+// service and server reflection) extended with the given full method names.
+func NewAuthAllowList(extraMethods ...string) AuthAllowList {
+	allowList := make(AuthAllowList, len(defaultAuthAllowedMethods)+len(extraMethods))
+	for _, method := range defaultAuthAllowedMethods {
+		allowList[method] = true
+	}
+	for _, method := range extraMethods {
+		allowList[method] = true
+	}
+	return allowList
+}
+
+// NewAuthAllowListFromEnv returns the default allow-list extended with
+// AUTH_ALLOW_METHODS: comma-separated full method names, for deployments that
+// add their own unauthenticated RPCs. Invalid names are logged and ignored.
+func NewAuthAllowListFromEnv() AuthAllowList {
+	return NewAuthAllowList(parseAuthAllowMethods(GetEnv("AUTH_ALLOW_METHODS", ""))...)
+}
+
+// Allows reports whether fullMethod skips authentication.
+func (a AuthAllowList) Allows(fullMethod string) bool {
+	return a[fullMethod]
+}
+
+func parseAuthAllowMethods(value string) []string {
+	var methods []string
+	for _, method := range strings.Split(value, ",") {
+		method = strings.TrimSpace(method)
+		if method == "" {
+			continue
+		}
+
+		if _, _, err := parseFullMethod(method); err != nil {
+			logrus.Warnf("Ignoring AUTH_ALLOW_METHODS entry %q: expected a full method name like /service.Service/HealthCheck", method)
+			continue
+		}
+
+		methods = append(methods, method)
+	}
+
+	return methods
+}
@@ -148,12 +148,15 @@ func (p *ProtoPermissionExtractorImpl) ExtractPermission(infoUnary *grpc.UnarySe
 	return &permission, nil
 }
 
+// NewUnaryAuthServerIntercept returns the unary auth interceptor. Methods in
+// allowList are passed through without authentication.
 func NewUnaryAuthServerIntercept(
 	permissionExtractor ProtoPermissionExtractor,
+	allowList AuthAllowList,
 ) func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) { // nolint
 
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if !skipCheckAuthorizationMetadata(info.FullMethod) {
+		if !allowList.Allows(info.FullMethod) {
 			// Extract permission stated in the proto file
 			permission, err := permissionExtractor.ExtractPermission(info, nil)
 			if err != nil {
@@ -195,11 +198,14 @@ func parseFullMethod(fullMethod string) (string, string, error) {
 	return serviceName, methodName, nil
 }
 
+// NewStreamAuthServerIntercept returns the stream auth interceptor. Methods in
+// allowList are passed through without authentication.
 func NewStreamAuthServerIntercept(
 	permissionExtractor ProtoPermissionExtractor,
+	allowList AuthAllowList,
 ) func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		if !skipCheckAuthorizationMetadata(info.FullMethod) {
+		if !allowList.Allows(info.FullMethod) {
 			// Extract permission stated in the proto file
 			permission, err := permissionExtractor.ExtractPermission(nil, info)
 			if err != nil {
@@ -220,18 +226,6 @@ func NewStreamAuthServerIntercept(
 	}
 }
 
-func skipCheckAuthorizationMetadata(fullMethod string) bool {
-	if strings.HasPrefix(fullMethod, "/grpc.reflection.v1alpha.ServerReflection/") {
-		return true
-	}
-
-	if strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/") {
-		return true
-	}
-
-	return false
-}
-
 // checkAuthorizationMetadata validates the JWT token and extracts user claims into the context.
 // It performs two key operations:
 // 1. Validates the JWT token using the AccelByte validator (signature, expiration, permissions)
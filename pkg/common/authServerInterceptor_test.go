@@ -0,0 +1,102 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// useTestValidator enables auth for the test with a validator accepting token.
+func useTestValidator(t *testing.T, token string) {
+	t.Helper()
+	previous := Validator
+	Validator = grpcWebTestValidator{token: token}
+	t.Cleanup(func() { Validator = previous })
+}
+
+// callUnary runs the unary auth interceptor for fullMethod and reports whether
+// the handler was reached.
+func callUnary(ctx context.Context, allowList AuthAllowList, fullMethod string) (bool, error) {
+	intercept := NewUnaryAuthServerIntercept(NewProtoPermissionExtractor(), allowList)
+	called := false
+	_, err := intercept(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	return called, err
+}
+
+func TestUnaryAuthServerIntercept_HealthCheckNeedsNoMetadata(t *testing.T) {
+	useTestValidator(t, "valid-token")
+
+	called, err := callUnary(context.Background(), NewAuthAllowList(), pb.Service_HealthCheck_FullMethodName)
+
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestUnaryAuthServerIntercept_GetUserChallengesRequiresAuth(t *testing.T) {
+	useTestValidator(t, testJWT(t, "user-1"))
+
+	called, err := callUnary(context.Background(), NewAuthAllowList(), pb.Service_GetUserChallenges_FullMethodName)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.False(t, called)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+testJWT(t, "user-1")))
+	called, err = callUnary(ctx, NewAuthAllowList(), pb.Service_GetUserChallenges_FullMethodName)
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestUnaryAuthServerIntercept_ExtraAllowedMethod(t *testing.T) {
+	useTestValidator(t, "valid-token")
+
+	called, err := callUnary(context.Background(), NewAuthAllowList(pb.Service_GetRotationStatus_FullMethodName), pb.Service_GetRotationStatus_FullMethodName)
+
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+// noMetadataStream is a server stream whose context carries no metadata.
+type noMetadataStream struct {
+	grpc.ServerStream
+}
+
+func (noMetadataStream) Context() context.Context { return context.Background() }
+
+func TestStreamAuthServerIntercept_HealthWatchNeedsNoMetadata(t *testing.T) {
+	useTestValidator(t, "valid-token")
+	intercept := NewStreamAuthServerIntercept(NewProtoPermissionExtractor(), NewAuthAllowList())
+	handler := func(srv interface{}, stream grpc.ServerStream) error { return nil }
+
+	err := intercept(nil, noMetadataStream{}, &grpc.StreamServerInfo{FullMethod: grpc_health_v1.Health_Watch_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	err = intercept(nil, noMetadataStream{}, &grpc.StreamServerInfo{FullMethod: pb.Service_GetUserChallenges_FullMethodName}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestNewAuthAllowListFromEnv(t *testing.T) {
+	t.Setenv("AUTH_ALLOW_METHODS", " /custom.Service/Ping ,not-a-method,,/service.Service/GetRotationStatus")
+
+	allowList := NewAuthAllowListFromEnv()
+
+	assert.True(t, allowList.Allows("/custom.Service/Ping"))
+	assert.True(t, allowList.Allows(pb.Service_GetRotationStatus_FullMethodName))
+	assert.True(t, allowList.Allows(pb.Service_HealthCheck_FullMethodName), "defaults are kept")
+	assert.False(t, allowList.Allows("not-a-method"))
+	assert.False(t, allowList.Allows(pb.Service_ClaimGoalReward_FullMethodName))
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ContextKeyClientIP is the context key for the client IP resolved by
+// TrustedProxies.HTTPMiddleware and TrustedProxies.UnaryServerInterceptor.
+const ContextKeyClientIP contextKey = "client_ip"
+
+// forwardedForHeader is the header (and, through grpc-gateway, the metadata
+// key) listing the addresses a request was forwarded for.
+const forwardedForHeader = "X-Forwarded-For"
+
+// loopbackPrefixes are always trusted: the grpc-gateway runs in-process and
+// reaches the gRPC server over localhost, adding the HTTP peer to
+// x-forwarded-for.
+var loopbackPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// TrustedProxies is the set of networks whose X-Forwarded-For entries are
+// believed, such as the ingress load balancers. Entries added by any other peer
+// may be spoofed by the client and are ignored.
+type TrustedProxies []netip.Prefix
+
+// NewTrustedProxies returns loopback plus the given CIDRs (e.g. "10.0.0.0/8").
+// A bare IP trusts that single address.
+func NewTrustedProxies(cidrs ...string) (TrustedProxies, error) {
+	proxies := append(TrustedProxies{}, loopbackPrefixes...)
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, prefix)
+	}
+
+	return proxies, nil
+}
+
+// NewTrustedProxiesFromEnv returns loopback plus TRUSTED_PROXY_CIDRS:
+// comma-separated CIDRs of the proxies in front of the service (default none).
+// Invalid entries are logged and ignored.
+func NewTrustedProxiesFromEnv() TrustedProxies {
+	proxies := append(TrustedProxies{}, loopbackPrefixes...)
+	for _, cidr := range strings.Split(GetEnv("TRUSTED_PROXY_CIDRS", ""), ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			logrus.Warnf("Ignoring TRUSTED_PROXY_CIDRS entry %q: %v", cidr, err)
+			continue
+		}
+		proxies = append(proxies, prefix)
+	}
+
+	return proxies
+}
+
+// Trusts reports whether addr belongs to a trusted proxy.
+func (t TrustedProxies) Trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP resolves the client address of a request received from remoteAddr
+// ("host:port" or a bare IP) with the given X-Forwarded-For values.
+//
+// X-Forwarded-For is only read when remoteAddr is trusted. Entries are walked
+// from the nearest hop back and the first address that is not a trusted proxy
+// is the client; what lies before it was written by the client and may be
+// forged. An unparsable entry stops the walk at the last valid hop. Returns ""
+// when remoteAddr is not an IP (e.g. an in-memory listener).
+func (t TrustedProxies) ClientIP(remoteAddr string, forwardedFor []string) string {
+	client, ok := parseHostAddr(remoteAddr)
+	if !ok {
+		return ""
+	}
+	if !t.Trusts(client) {
+		return client.String()
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHostAddr(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+		client = hop
+		if !t.Trusts(hop) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// HTTPClientIP resolves the client address of an HTTP request.
+func (t TrustedProxies) HTTPClientIP(r *http.Request) string {
+	return t.ClientIP(r.RemoteAddr, r.Header.Values(forwardedForHeader))
+}
+
+// GRPCClientIP resolves the client address of a gRPC call from its peer and the
+// x-forwarded-for metadata.
+func (t TrustedProxies) GRPCClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	return t.ClientIP(p.Addr.String(), md.Get(forwardedForHeader))
+}
+
+// HTTPMiddleware stores the client IP in the request context for the handlers
+// and the request log.
+func (t TrustedProxies) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ContextKeyClientIP, t.HTTPClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// UnaryServerInterceptor stores the client IP in the call context.
+func (t TrustedProxies) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(context.WithValue(ctx, ContextKeyClientIP, t.GRPCClientIP(ctx)), req)
+	}
+}
+
+// GetClientIPFromContext returns the client IP stored by the HTTP middleware or
+// the gRPC interceptor, or "" when it is unknown.
+func GetClientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(ContextKeyClientIP).(string)
+	return clientIP
+}
+
+func parsePrefix(cidr string) (netip.Prefix, error) {
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return prefix.Masked(), nil
+}
+
+// parseHostAddr parses "ip", "ip:port" or "[ipv6]:port".
+func parseHostAddr(value string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
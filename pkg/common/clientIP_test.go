@@ -0,0 +1,171 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8", "2001:db8::/32")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{
+			name:       "direct client without header",
+			remoteAddr: "203.0.113.7:51234",
+			want:       "203.0.113.7",
+		},
+		{
+			name:         "spoofed header from untrusted peer is ignored",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:         "single trusted load balancer",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "client-supplied entries before the real client are ignored",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "chain of trusted proxies",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"198.51.100.1, 10.9.9.9", "10.4.4.4"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "all hops trusted returns the first",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"10.5.5.5, 10.6.6.6"},
+			want:         "10.5.5.5",
+		},
+		{
+			name:         "unparsable entry stops at the last valid hop",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: []string{"198.51.100.1, not-an-ip, 10.6.6.6"},
+			want:         "10.6.6.6",
+		},
+		{
+			name:         "trusted proxy without header",
+			remoteAddr:   "10.1.2.3:443",
+			forwardedFor: nil,
+			want:         "10.1.2.3",
+		},
+		{
+			name:         "IPv6 chain",
+			remoteAddr:   "[2001:db8::1]:443",
+			forwardedFor: []string{"2001:db8:ffff::9, [2001:db8::2]:8080"},
+			want:         "2001:db8:ffff::9",
+		},
+		{
+			name:         "IPv4-mapped IPv6 peer",
+			remoteAddr:   "[::ffff:10.1.2.3]:443",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:         "loopback is always trusted",
+			remoteAddr:   "127.0.0.1:40000",
+			forwardedFor: []string{"198.51.100.1, 10.1.2.3"},
+			want:         "198.51.100.1",
+		},
+		{
+			name:       "non-IP remote address",
+			remoteAddr: "bufconn",
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, proxies.ClientIP(tt.remoteAddr, tt.forwardedFor))
+		})
+	}
+}
+
+func TestTrustedProxies_OnlyLoopbackByDefault(t *testing.T) {
+	proxies, err := NewTrustedProxies()
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.1.2.3", proxies.ClientIP("10.1.2.3:443", []string{"198.51.100.1"}),
+		"the load balancer is not trusted until configured")
+	assert.True(t, proxies.Trusts(netip.MustParseAddr("::1")))
+}
+
+func TestNewTrustedProxies_Invalid(t *testing.T) {
+	_, err := NewTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
+
+func TestNewTrustedProxiesFromEnv(t *testing.T) {
+	t.Setenv("TRUSTED_PROXY_CIDRS", "10.0.0.0/8, bogus ,192.168.1.10")
+
+	proxies := NewTrustedProxiesFromEnv()
+
+	assert.True(t, proxies.Trusts(netip.MustParseAddr("10.20.30.40")))
+	assert.True(t, proxies.Trusts(netip.MustParseAddr("192.168.1.10")))
+	assert.False(t, proxies.Trusts(netip.MustParseAddr("192.168.1.11")))
+	assert.Len(t, proxies, len(loopbackPrefixes)+2, "the invalid entry is ignored")
+}
+
+func TestTrustedProxies_HTTPMiddleware(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+
+	var got string
+	handler := proxies.HTTPMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = GetClientIPFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.RemoteAddr = "10.1.2.3:443"
+	req.Header.Add("X-Forwarded-For", "1.2.3.4, 198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.1", got)
+}
+
+func TestTrustedProxies_UnaryServerInterceptor(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+
+	// Shape of a call proxied by the in-process grpc-gateway: the load balancer
+	// hop is appended to x-forwarded-for and the peer is loopback
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 40000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "198.51.100.1, 10.1.2.3"))
+
+	var got string
+	_, err = proxies.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		got = GetClientIPFromContext(ctx)
+		return nil, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.1", got)
+	assert.Empty(t, GetClientIPFromContext(context.Background()))
+}
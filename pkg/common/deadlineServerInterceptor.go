@@ -0,0 +1,117 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRPCTimeout        = "10s"
+	defaultRPCMethodTimeouts = "ClaimGoalReward=8s,GetUserChallenges=3s"
+)
+
+// DeadlineConfig controls the server-side deadlines applied by the deadline interceptor.
+type DeadlineConfig struct {
+	// DefaultTimeout applies to methods without an entry in MethodTimeouts. Zero disables it.
+	DefaultTimeout time.Duration
+	// MethodTimeouts maps a method name (e.g. "ClaimGoalReward") to its timeout.
+	MethodTimeouts map[string]time.Duration
+	// MaxDeadline rejects requests whose deadline is further away than this. Zero disables the check.
+	MaxDeadline time.Duration
+}
+
+// NewDeadlineConfigFromEnv reads the deadline configuration from:
+//   - RPC_DEFAULT_TIMEOUT: timeout for methods not listed in RPC_METHOD_TIMEOUTS (default "10s", "0" disables)
+//   - RPC_METHOD_TIMEOUTS: comma-separated Method=duration pairs (default "ClaimGoalReward=8s,GetUserChallenges=3s")
+//   - RPC_MAX_DEADLINE: reject client deadlines further away than this (default unset = no limit)
+//
+// Invalid values are logged and ignored.
+func NewDeadlineConfigFromEnv() DeadlineConfig {
+	return DeadlineConfig{
+		DefaultTimeout: parseDurationEnv("RPC_DEFAULT_TIMEOUT", defaultRPCTimeout),
+		MethodTimeouts: parseMethodTimeouts(GetEnv("RPC_METHOD_TIMEOUTS", defaultRPCMethodTimeouts)),
+		MaxDeadline:    parseDurationEnv("RPC_MAX_DEADLINE", "0"),
+	}
+}
+
+// TimeoutFor returns the server-side timeout for a full gRPC method name
+// (e.g. "/service.Service/ClaimGoalReward").
+func (c DeadlineConfig) TimeoutFor(fullMethod string) time.Duration {
+	if timeout, ok := c.MethodTimeouts[path.Base(fullMethod)]; ok {
+		return timeout
+	}
+
+	return c.DefaultTimeout
+}
+
+// NewUnaryDeadlineServerIntercept returns a unary interceptor that bounds every call:
+//   - Calls without a deadline get the configured per-method (or default) timeout.
+//   - Calls whose deadline is further away than MaxDeadline are rejected with InvalidArgument.
+//   - Calls with an acceptable deadline keep it unchanged.
+func NewUnaryDeadlineServerIntercept(cfg DeadlineConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			if cfg.MaxDeadline > 0 && time.Until(deadline) > cfg.MaxDeadline {
+				return nil, status.Errorf(codes.InvalidArgument,
+					"deadline exceeds the maximum of %s for %s", cfg.MaxDeadline, path.Base(info.FullMethod))
+			}
+
+			return handler(ctx, req)
+		}
+
+		timeout := cfg.TimeoutFor(info.FullMethod)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+func parseDurationEnv(key, fallback string) time.Duration {
+	value := GetEnv(key, fallback)
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		logrus.Warnf("Invalid %s %q, using %s", key, value, fallback)
+		d, _ = time.ParseDuration(fallback)
+	}
+
+	return d
+}
+
+// parseMethodTimeouts parses "Method=duration,Method=duration".
+func parseMethodTimeouts(value string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		method, durationStr, found := strings.Cut(entry, "=")
+		method = strings.TrimSpace(method)
+		d, err := time.ParseDuration(strings.TrimSpace(durationStr))
+		if !found || method == "" || err != nil || d < 0 {
+			logrus.Warnf("Ignoring invalid RPC_METHOD_TIMEOUTS entry %q", entry)
+			continue
+		}
+
+		timeouts[method] = d
+	}
+
+	return timeouts
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// deadlineOf runs the interceptor for fullMethod and returns the remaining time
+// on the context the handler received (0 if it had no deadline).
+func deadlineOf(t *testing.T, cfg DeadlineConfig, ctx context.Context, fullMethod string) (time.Duration, error) {
+	t.Helper()
+
+	var remaining time.Duration
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining = time.Until(deadline)
+		}
+		return "ok", nil
+	}
+
+	interceptor := NewUnaryDeadlineServerIntercept(cfg)
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+
+	return remaining, err
+}
+
+func TestDeadlineInterceptor_InjectsPerMethodDeadline(t *testing.T) {
+	cfg := DeadlineConfig{
+		DefaultTimeout: 10 * time.Second,
+		MethodTimeouts: map[string]time.Duration{
+			"ClaimGoalReward":   8 * time.Second,
+			"GetUserChallenges": 3 * time.Second,
+		},
+	}
+
+	tests := []struct {
+		method   string
+		expected time.Duration
+	}{
+		{pb.Service_ClaimGoalReward_FullMethodName, 8 * time.Second},
+		{pb.Service_GetUserChallenges_FullMethodName, 3 * time.Second},
+		{pb.Service_InitializePlayer_FullMethodName, 10 * time.Second},
+		{pb.Service_SetGoalActive_FullMethodName, 10 * time.Second},
+		{pb.Service_HealthCheck_FullMethodName, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			remaining, err := deadlineOf(t, cfg, context.Background(), tt.method)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, remaining, float64(100*time.Millisecond))
+		})
+	}
+}
+
+func TestDeadlineInterceptor_KeepsClientDeadline(t *testing.T) {
+	cfg := DeadlineConfig{
+		DefaultTimeout: 10 * time.Second,
+		MethodTimeouts: map[string]time.Duration{"ClaimGoalReward": 8 * time.Second},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	remaining, err := deadlineOf(t, cfg, ctx, pb.Service_ClaimGoalReward_FullMethodName)
+	require.NoError(t, err)
+	assert.InDelta(t, 20*time.Second, remaining, float64(100*time.Millisecond))
+}
+
+func TestDeadlineInterceptor_NoDefaultTimeout(t *testing.T) {
+	remaining, err := deadlineOf(t, DeadlineConfig{}, context.Background(), pb.Service_InitializePlayer_FullMethodName)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+}
+
+func TestDeadlineInterceptor_RejectsDeadlineAboveMax(t *testing.T) {
+	cfg := DeadlineConfig{MaxDeadline: 30 * time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := deadlineOf(t, cfg, ctx, pb.Service_ClaimGoalReward_FullMethodName)
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = deadlineOf(t, cfg, ctx, pb.Service_ClaimGoalReward_FullMethodName)
+	assert.NoError(t, err)
+}
+
+func TestNewDeadlineConfigFromEnv(t *testing.T) {
+	t.Setenv("RPC_DEFAULT_TIMEOUT", "5s")
+	t.Setenv("RPC_METHOD_TIMEOUTS", "ClaimGoalReward=8s, GetUserChallenges=2500ms,bogus,Broken=abc")
+	t.Setenv("RPC_MAX_DEADLINE", "1m")
+
+	cfg := NewDeadlineConfigFromEnv()
+
+	assert.Equal(t, 5*time.Second, cfg.DefaultTimeout)
+	assert.Equal(t, time.Minute, cfg.MaxDeadline)
+	assert.Equal(t, map[string]time.Duration{
+		"ClaimGoalReward":   8 * time.Second,
+		"GetUserChallenges": 2500 * time.Millisecond,
+	}, cfg.MethodTimeouts)
+}
+
+func TestNewDeadlineConfigFromEnv_Defaults(t *testing.T) {
+	cfg := NewDeadlineConfigFromEnv()
+
+	assert.Equal(t, 10*time.Second, cfg.DefaultTimeout)
+	assert.Zero(t, cfg.MaxDeadline)
+	assert.Equal(t, 8*time.Second, cfg.TimeoutFor(pb.Service_ClaimGoalReward_FullMethodName))
+	assert.Equal(t, 3*time.Second, cfg.TimeoutFor(pb.Service_GetUserChallenges_FullMethodName))
+}
+
+func TestNewDeadlineConfigFromEnv_InvalidDefault(t *testing.T) {
+	t.Setenv("RPC_DEFAULT_TIMEOUT", "soon")
+
+	cfg := NewDeadlineConfigFromEnv()
+
+	assert.Equal(t, 10*time.Second, cfg.DefaultTimeout)
+}
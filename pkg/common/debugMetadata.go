@@ -0,0 +1,122 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Keys of the debug trailers set by DebugMetadata on gRPC calls. The optimized
+// HTTP handlers send the same values as the X-Debug-* headers below.
+const (
+	DebugTrailerUserID        = "x-debug-user-id"
+	DebugTrailerNamespace     = "x-debug-namespace"
+	DebugTrailerConfigVersion = "x-debug-config-version"
+	DebugTrailerHandler       = "x-debug-handler"
+)
+
+// Debug response headers set by DebugMetadata on the optimized HTTP handlers.
+const (
+	DebugHeaderUserID        = "X-Debug-User-Id"
+	DebugHeaderNamespace     = "X-Debug-Namespace"
+	DebugHeaderConfigVersion = "X-Debug-Config-Version"
+	DebugHeaderHandler       = "X-Debug-Handler"
+)
+
+// DebugMetadata attaches what the server resolved for a call to its response,
+// so a response shared by QA shows which user and namespace the token (or the
+// x-mock-user-id header) resolved to, the challenge config version and the
+// handler that served it.
+//
+// It is off by default and must stay off in production: the values identify
+// the caller and the deployment. A nil *DebugMetadata attaches nothing.
+type DebugMetadata struct {
+	enabled       bool
+	configVersion func() string
+}
+
+// NewDebugMetadataFromEnv reads DEBUG_RESPONSE_METADATA ("true" enables it,
+// default off). configVersion returns the version of the loaded challenge
+// config; it may be nil.
+func NewDebugMetadataFromEnv(configVersion func() string) *DebugMetadata {
+	enabled, err := strconv.ParseBool(GetEnv("DEBUG_RESPONSE_METADATA", "false"))
+	if err != nil {
+		logrus.Warnf("Invalid DEBUG_RESPONSE_METADATA %q, leaving it off", GetEnv("DEBUG_RESPONSE_METADATA", ""))
+		enabled = false
+	}
+
+	return NewDebugMetadata(enabled, configVersion)
+}
+
+// NewDebugMetadata creates debug metadata, attached only when enabled.
+func NewDebugMetadata(enabled bool, configVersion func() string) *DebugMetadata {
+	return &DebugMetadata{enabled: enabled, configVersion: configVersion}
+}
+
+// Enabled reports whether debug metadata is attached to responses.
+func (d *DebugMetadata) Enabled() bool {
+	return d != nil && d.enabled
+}
+
+// version returns the challenge config version, "" when unknown.
+func (d *DebugMetadata) version() string {
+	if d.configVersion == nil {
+		return ""
+	}
+	return d.configVersion()
+}
+
+// UnaryServerInterceptor sets the debug trailers on every unary call, including
+// failed ones. It must run after the auth interceptor, which resolves the user
+// and namespace; calls rejected before it get no trailers. Streaming calls get
+// none either, since the stream auth interceptor does not pass what it resolves
+// on to the handler.
+func (d *DebugMetadata) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !d.Enabled() {
+			return handler(ctx, req)
+		}
+
+		userID, _ := ctx.Value(ContextKeyUserID).(string)
+		trailer := metadata.Pairs(
+			DebugTrailerUserID, userID,
+			DebugTrailerNamespace, GetNamespaceFromContext(ctx),
+			DebugTrailerConfigVersion, d.version(),
+			DebugTrailerHandler, info.FullMethod,
+		)
+		if err := grpc.SetTrailer(ctx, trailer); err != nil {
+			logrus.WithError(err).WithField("method", info.FullMethod).Debug("Failed to set debug trailers")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// SetHTTPHeaders sets the X-Debug-* headers for a request an optimized handler
+// resolved to userID in namespace. The handler is the route pattern that served
+// r, or its path when it was not routed by a ServeMux. It must be called before
+// the response is written.
+func (d *DebugMetadata) SetHTTPHeaders(w http.ResponseWriter, r *http.Request, userID, namespace string) {
+	if !d.Enabled() {
+		return
+	}
+
+	route := r.Pattern
+	if route == "" {
+		route = r.URL.Path
+	}
+
+	header := w.Header()
+	header.Set(DebugHeaderUserID, userID)
+	header.Set(DebugHeaderNamespace, namespace)
+	header.Set(DebugHeaderConfigVersion, d.version())
+	header.Set(DebugHeaderHandler, route)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// trailerStream records the trailers set on a unary call.
+type trailerStream struct {
+	trailer metadata.MD
+}
+
+func (s *trailerStream) Method() string               { return pb.Service_GetUserChallenges_FullMethodName }
+func (s *trailerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *trailerStream) SendHeader(metadata.MD) error { return nil }
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+// debugTrailers runs the debug interceptor as a call authenticated as user-1 in
+// ns and returns the trailers it set.
+func debugTrailers(t *testing.T, debug *DebugMetadata, handlerErr error) metadata.MD {
+	t.Helper()
+
+	stream := &trailerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	ctx = context.WithValue(ctx, ContextKeyUserID, "user-1")
+	ctx = context.WithValue(ctx, ContextKeyNamespace, "ns")
+
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", handlerErr }
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Service_GetUserChallenges_FullMethodName}
+	_, err := debug.UnaryServerInterceptor()(ctx, nil, info, handler)
+	require.ErrorIs(t, err, handlerErr)
+
+	return stream.trailer
+}
+
+func TestDebugMetadata_TrailersOnlyWhenEnabled(t *testing.T) {
+	version := func() string { return "abc123def456" }
+
+	trailer := debugTrailers(t, NewDebugMetadata(true, version), nil)
+	assert.Equal(t, []string{"user-1"}, trailer.Get(DebugTrailerUserID))
+	assert.Equal(t, []string{"ns"}, trailer.Get(DebugTrailerNamespace))
+	assert.Equal(t, []string{"abc123def456"}, trailer.Get(DebugTrailerConfigVersion))
+	assert.Equal(t, []string{pb.Service_GetUserChallenges_FullMethodName}, trailer.Get(DebugTrailerHandler))
+
+	assert.Empty(t, debugTrailers(t, NewDebugMetadata(false, version), nil))
+	assert.Empty(t, debugTrailers(t, nil, nil))
+}
+
+func TestDebugMetadata_TrailersOnError(t *testing.T) {
+	trailer := debugTrailers(t, NewDebugMetadata(true, nil), errors.New("boom"))
+
+	assert.Equal(t, []string{"user-1"}, trailer.Get(DebugTrailerUserID))
+	assert.Equal(t, []string{""}, trailer.Get(DebugTrailerConfigVersion))
+}
+
+func TestDebugMetadata_FromEnv(t *testing.T) {
+	t.Setenv("DEBUG_RESPONSE_METADATA", "")
+	assert.False(t, NewDebugMetadataFromEnv(nil).Enabled())
+
+	t.Setenv("DEBUG_RESPONSE_METADATA", "true")
+	assert.True(t, NewDebugMetadataFromEnv(nil).Enabled())
+
+	t.Setenv("DEBUG_RESPONSE_METADATA", "yes please")
+	assert.False(t, NewDebugMetadataFromEnv(nil).Enabled())
+}
+
+func TestDebugMetadata_SetHTTPHeaders(t *testing.T) {
+	version := func() string { return "abc123def456" }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/challenges/{challenge_id}", func(w http.ResponseWriter, r *http.Request) {
+		NewDebugMetadata(true, version).SetHTTPHeaders(w, r, "user-1", "ns")
+	})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/challenges/daily", nil))
+
+	assert.Equal(t, "user-1", rec.Header().Get(DebugHeaderUserID))
+	assert.Equal(t, "ns", rec.Header().Get(DebugHeaderNamespace))
+	assert.Equal(t, "abc123def456", rec.Header().Get(DebugHeaderConfigVersion))
+	assert.Equal(t, "GET /v1/challenges/{challenge_id}", rec.Header().Get(DebugHeaderHandler))
+
+	// Outside a ServeMux, the path is reported
+	rec = httptest.NewRecorder()
+	NewDebugMetadata(true, version).SetHTTPHeaders(rec, httptest.NewRequest(http.MethodGet, "/v1/challenges", nil), "user-1", "ns")
+	assert.Equal(t, "/v1/challenges", rec.Header().Get(DebugHeaderHandler))
+
+	rec = httptest.NewRecorder()
+	NewDebugMetadata(false, version).SetHTTPHeaders(rec, httptest.NewRequest(http.MethodGet, "/v1/challenges", nil), "user-1", "ns")
+	assert.Empty(t, rec.Header().Get(DebugHeaderUserID))
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth/validator"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// Request header (HTTP) and metadata key (gRPC) asking for a timing breakdown,
+// and the response header and metadata key carrying it.
+const (
+	DebugTimingHeader     = "X-Debug-Timing"
+	DebugTimingMetadata   = "x-debug-timing"
+	ServerTimingHeader    = "Server-Timing"
+	ServerTimingMetadata  = "server-timing"
+	debugTimingRequested  = "1"
+	debugTimingPermission = "ADMIN:NAMESPACE:{namespace}:CHALLENGE:DEBUG"
+)
+
+// DebugTiming decides which requests get a breakdown of where their time went,
+// for looking into one customer's slow responses without enabling pprof.
+//
+// A request asks with "X-Debug-Timing: 1" (or x-debug-timing metadata on
+// gRPC). It is answered when the namespace is in DEBUG_TIMING_NAMESPACES, or
+// when the request's bearer token has the ADMIN:NAMESPACE:{namespace}:CHALLENGE:DEBUG
+// [READ] permission; other requests are served as if they had not asked. The
+// phases the handler marked, and the number of repository queries made with
+// the request's context (see QueryCounter), go into a Server-Timing
+// response header and a "Request timing" log entry.
+//
+// Requests that do not ask allocate nothing: Start returns nil, and a nil
+// *Timings records nothing. A nil *DebugTiming answers no request.
+type DebugTiming struct {
+	namespaces map[string]bool
+}
+
+// NewDebugTimingFromEnv reads DEBUG_TIMING_NAMESPACES, a comma-separated list
+// of namespaces whose requests may ask for timings (default none; admin tokens
+// may always ask).
+func NewDebugTimingFromEnv() *DebugTiming {
+	var namespaces []string
+	for _, namespace := range strings.Split(GetEnv("DEBUG_TIMING_NAMESPACES", ""), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return NewDebugTiming(namespaces...)
+}
+
+// NewDebugTiming creates a DebugTiming answering the requests of namespaces,
+// and those with an admin token.
+func NewDebugTiming(namespaces ...string) *DebugTiming {
+	allowed := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		allowed[namespace] = true
+	}
+	return &DebugTiming{namespaces: allowed}
+}
+
+// Namespaces returns the number of namespaces whose requests may ask for timings.
+func (d *DebugTiming) Namespaces() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.namespaces)
+}
+
+// Start returns the timings of a request in namespace whose X-Debug-Timing
+// value is requested, or nil when it did not ask or may not ask.
+// authorization is its Authorization value; tokenValidator, nil when auth is
+// disabled, checks it for the admin permission.
+func (d *DebugTiming) Start(requested, namespace, authorization string, tokenValidator validator.AuthTokenValidator) *Timings {
+	if d == nil || requested != debugTimingRequested {
+		return nil
+	}
+
+	start := time.Now()
+	if !d.namespaces[namespace] && !hasDebugTimingPermission(authorization, namespace, tokenValidator) {
+		logrus.WithField("namespace", namespace).Debug("Ignoring X-Debug-Timing from a namespace that is not allowed")
+		return nil
+	}
+	return &Timings{start: start, last: start, queries: NewQueryCounter()}
+}
+
+func hasDebugTimingPermission(authorization, namespace string, tokenValidator validator.AuthTokenValidator) bool {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || tokenValidator == nil {
+		return false
+	}
+	permission := wrapPermission(debugTimingPermission, int(pb.Action_READ))
+	return tokenValidator.Validate(token, &permission, &namespace, nil) == nil
+}
+
+// UnaryServerInterceptor answers unary calls with x-debug-timing metadata: it
+// puts Timings in the context for the handler to mark phases on, and sends them
+// back as server-timing header metadata, which the gateway forwards as the
+// Server-Timing header. It must run before the auth interceptor, so the first
+// phase the handler marks includes authentication. Calls are gated on the
+// service namespace (AB_NAMESPACE) and the global Validator.
+func (d *DebugTiming) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requested := metadata.ValueFromIncomingContext(ctx, DebugTimingMetadata)
+		if d == nil || len(requested) == 0 {
+			return handler(ctx, req)
+		}
+
+		var authorization string
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+		timings := d.Start(requested[0], getNamespace(), authorization, Validator)
+		if timings == nil {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(WithTimings(ctx, timings), req)
+
+		value := timings.finish(info.FullMethod, nil)
+		if setErr := grpc.SetHeader(ctx, metadata.Pairs(ServerTimingMetadata, value)); setErr != nil {
+			logrus.WithError(setErr).WithField("method", info.FullMethod).Debug("Failed to set server-timing header")
+		}
+		return resp, err
+	}
+}
+
+// Timings records the phases of one request that asked for them, and counts
+// its queries. The methods of a nil *Timings do nothing.
+type Timings struct {
+	start   time.Time
+	last    time.Time
+	phases  []timingPhase
+	queries *QueryCounter
+}
+
+type timingPhase struct {
+	name     string
+	duration time.Duration
+}
+
+type timingsContextKey struct{}
+
+// WithTimings returns a context carrying timings, whose repository calls are
+// counted in them. A nil timings returns ctx unchanged.
+func WithTimings(ctx context.Context, timings *Timings) context.Context {
+	if timings == nil {
+		return ctx
+	}
+	return WithQueryCounter(context.WithValue(ctx, timingsContextKey{}, timings), timings.queries)
+}
+
+// TimingsFromContext returns the timings of the request, nil when it did not
+// ask for them.
+func TimingsFromContext(ctx context.Context) *Timings {
+	timings, _ := ctx.Value(timingsContextKey{}).(*Timings)
+	return timings
+}
+
+// Mark ends the current phase, naming it phase. Marking a phase again adds to
+// it, so a phase may be split around others.
+func (t *Timings) Mark(phase string) {
+	if t == nil {
+		return
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(t.last)
+	t.last = now
+	for i := range t.phases {
+		if t.phases[i].name == phase {
+			t.phases[i].duration += elapsed
+			return
+		}
+	}
+	t.phases = append(t.phases, timingPhase{name: phase, duration: elapsed})
+}
+
+// WriteHeader sets the Server-Timing header for the phases marked so far and
+// logs them with the route and user. It must be called before the response is
+// written.
+func (t *Timings) WriteHeader(header http.Header, route, userID string) {
+	if t == nil {
+		return
+	}
+	header.Set(ServerTimingHeader, t.finish(route, logrus.Fields{"user_id": userID}))
+}
+
+// finish returns the Server-Timing value of the phases marked so far and the
+// query count, ending with the total, and logs it with fields.
+func (t *Timings) finish(route string, fields logrus.Fields) string {
+	total := time.Since(t.start)
+
+	var b strings.Builder
+	entry := logrus.WithFields(fields).WithField("route", route)
+	for _, phase := range t.phases {
+		appendServerTiming(&b, phase.name, phase.duration)
+		entry = entry.WithField(phase.name+"_ms", milliseconds(phase.duration))
+	}
+	queries := t.queries.Total()
+	appendServerTimingCount(&b, "queries", queries)
+	appendServerTiming(&b, "total", total)
+
+	entry.WithFields(logrus.Fields{
+		"queries":  queries,
+		"total_ms": milliseconds(total),
+	}).Info("Request timing")
+	return b.String()
+}
+
+// appendServerTiming appends a Server-Timing metric, e.g. "db;dur=1.234".
+func appendServerTiming(b *strings.Builder, name string, duration time.Duration) {
+	if b.Len() > 0 {
+		b.WriteString(", ")
+	}
+	b.WriteString(name)
+	b.WriteString(";dur=")
+	b.WriteString(strconv.FormatFloat(milliseconds(duration), 'f', 3, 64))
+}
+
+// appendServerTimingCount appends a Server-Timing metric carrying a count in
+// its description, e.g. `queries;desc="3"`.
+func appendServerTimingCount(b *strings.Builder, name string, count int) {
+	if b.Len() > 0 {
+		b.WriteString(", ")
+	}
+	b.WriteString(name)
+	b.WriteString(`;desc="`)
+	b.WriteString(strconv.Itoa(count))
+	b.WriteString(`"`)
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// serverTimingPattern matches a Server-Timing value of named phases and the
+// query count, ending with the total.
+var serverTimingPattern = regexp.MustCompile(`^([a-z]+;dur=\d+\.\d{3}, )*queries;desc="\d+", total;dur=\d+\.\d{3}$`)
+
+// debugTimingValidator accepts one token, and only with the debug permission.
+type debugTimingValidator struct {
+	token string
+}
+
+func (v debugTimingValidator) Initialize(ctx ...context.Context) error { return nil }
+
+func (v debugTimingValidator) Validate(token string, permission *iam.Permission, namespace *string, userID *string) error {
+	if token != v.token || permission == nil || permission.Resource != debugTimingPermission || permission.Action != int(pb.Action_READ) {
+		return assert.AnError
+	}
+	return nil
+}
+
+func TestDebugTiming_Start(t *testing.T) {
+	timing := NewDebugTiming("allowed")
+	admin := debugTimingValidator{token: "admin-token"}
+
+	assert.NotNil(t, timing.Start("1", "allowed", "", nil))
+	assert.Nil(t, timing.Start("", "allowed", "", nil), "requests that do not ask get no timings")
+	assert.Nil(t, timing.Start("true", "allowed", "", nil))
+	assert.Nil(t, timing.Start("1", "other", "", nil), "namespace is not allowed")
+
+	assert.NotNil(t, timing.Start("1", "other", "Bearer admin-token", admin), "admin tokens may ask in any namespace")
+	assert.Nil(t, timing.Start("1", "other", "Bearer player-token", admin))
+	assert.Nil(t, timing.Start("1", "other", "admin-token", admin), "not a bearer token")
+	assert.Nil(t, timing.Start("1", "other", "Bearer admin-token", nil), "auth disabled")
+
+	var disabled *DebugTiming
+	assert.Nil(t, disabled.Start("1", "allowed", "", nil))
+	assert.Equal(t, 0, disabled.Namespaces())
+}
+
+func TestDebugTiming_FromEnv(t *testing.T) {
+	t.Setenv("DEBUG_TIMING_NAMESPACES", " game-a, ,game-b")
+
+	timing := NewDebugTimingFromEnv()
+
+	assert.Equal(t, 2, timing.Namespaces())
+	assert.NotNil(t, timing.Start("1", "game-b", "", nil))
+}
+
+func TestTimings_WriteHeader(t *testing.T) {
+	timings := NewDebugTiming("ns").Start("1", "ns", "", nil)
+	timings.Mark("auth")
+	timings.Mark("db")
+	timings.Mark("assembly")
+	timings.Mark("db")
+
+	header := http.Header{}
+	timings.WriteHeader(header, "/v1/challenges", "user-1")
+
+	value := header.Get(ServerTimingHeader)
+	assert.Regexp(t, serverTimingPattern, value)
+	assert.Regexp(t, `^auth;dur=[0-9.]+, db;dur=[0-9.]+, assembly;dur=[0-9.]+, queries;desc="0", total;dur=[0-9.]+$`, value,
+		"a phase marked twice is reported once")
+}
+
+func TestTimings_Nil(t *testing.T) {
+	var timings *Timings
+	timings.Mark("auth")
+
+	header := http.Header{}
+	timings.WriteHeader(header, "/v1/challenges", "user-1")
+
+	assert.Empty(t, header)
+	assert.Nil(t, TimingsFromContext(context.Background()))
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithTimings(ctx, nil), "a request without timings gets no counter")
+}
+
+// headerStream records the headers set on a unary call.
+type headerStream struct {
+	trailerStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+// timedCall runs the timing interceptor with the incoming metadata md and
+// returns the header metadata it set and whether the handler got timings.
+func timedCall(t *testing.T, timing *DebugTiming, md metadata.MD) (metadata.MD, bool) {
+	t.Helper()
+
+	stream := &headerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	var timed bool
+	handler := func(ctx context.Context, req any) (any, error) {
+		timings := TimingsFromContext(ctx)
+		timed = timings != nil
+		timings.Mark("auth")
+		QueryCounterFromContext(ctx).Count("GetProgress")
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Service_ClaimGoalReward_FullMethodName}
+	_, err := timing.UnaryServerInterceptor()(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	return stream.header, timed
+}
+
+func TestDebugTiming_UnaryServerInterceptor(t *testing.T) {
+	t.Setenv("AB_NAMESPACE", "ns")
+
+	header, timed := timedCall(t, NewDebugTiming("ns"), metadata.Pairs(DebugTimingMetadata, "1"))
+	assert.True(t, timed)
+	require.Len(t, header.Get(ServerTimingMetadata), 1)
+	assert.Regexp(t, `^auth;dur=[0-9.]+, queries;desc="1", total;dur=[0-9.]+$`, header.Get(ServerTimingMetadata)[0],
+		"queries made with the handler's context are counted")
+
+	header, timed = timedCall(t, NewDebugTiming("other"), metadata.Pairs(DebugTimingMetadata, "1"))
+	assert.False(t, timed, "namespace is not allowed")
+	assert.Empty(t, header)
+
+	header, timed = timedCall(t, NewDebugTiming("ns"), metadata.MD{})
+	assert.False(t, timed, "the call did not ask")
+	assert.Empty(t, header)
+
+	_, timed = timedCall(t, nil, metadata.Pairs(DebugTimingMetadata, "1"))
+	assert.False(t, timed)
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Headers of signed event requests.
+const (
+	EventSignatureHeader = "X-Event-Signature"
+	EventTimestampHeader = "X-Event-Timestamp"
+	EventNonceHeader     = "X-Event-Nonce"
+)
+
+// Reasons a signed event request is rejected, the reason label of
+// event_signature_rejections_total.
+const (
+	EventRejectMissingHeaders = "missing_headers"
+	EventRejectBadNonce       = "bad_nonce"
+	EventRejectBadTimestamp   = "bad_timestamp"
+	EventRejectClockSkew      = "clock_skew"
+	EventRejectBadSignature   = "bad_signature"
+	EventRejectReplay         = "replay"
+	EventRejectBodyTooLarge   = "body_too_large"
+	EventRejectUnsigned       = "unsigned_transport"
+)
+
+// EventVerifiedMetadata is the gRPC metadata key the gateway sets on calls
+// whose HTTP request passed signature verification.
+const EventVerifiedMetadata = "x-event-verified"
+
+const (
+	defaultEventSignatureWindow = "5m"
+	defaultEventNonceCacheSize  = 100000
+
+	// maxSignedEventBodyBytes bounds the bodies read to verify their signature.
+	maxSignedEventBodyBytes = 1 << 20
+
+	// maxEventNonceBytes bounds the nonces kept by the replay cache.
+	maxEventNonceBytes = 128
+
+	eventSignaturePrefix = "sha256="
+)
+
+// EventSignatureVerifier authenticates server-to-server event requests with a
+// shared secret, on top of their permission check:
+//   - X-Event-Signature is "sha256=" and the hex HMAC-SHA256 of
+//     "<timestamp>.<nonce>.<body>" under the secret, compared in constant time
+//   - X-Event-Timestamp is Unix seconds within window of the server clock
+//   - X-Event-Nonce is a unique value per request (at most 128 bytes); a nonce
+//     seen within the window is rejected as a replay
+//
+// Rejected requests get 401 with a gRPC-style JSON error and are counted in
+// event_signature_rejections_total by reason. Nonces are only remembered once
+// the signature is valid, in an LRU cache of maxNonces entries that also drops
+// nonces older than twice the window; a replay of a nonce evicted early by a
+// burst of more than maxNonces requests is not detected.
+type EventSignatureVerifier struct {
+	secret    []byte
+	window    time.Duration
+	maxNonces int
+	now       func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]*list.Element
+	order  *list.List // *seenNonce, most recent first
+
+	// token marks gateway calls verified over HTTP; it is random per process
+	// so that no client can forge it.
+	token string
+
+	rejected *prometheus.CounterVec
+}
+
+// eventVerifiedKey marks the context of an HTTP request that passed
+// verification.
+type eventVerifiedKey struct{}
+
+type seenNonce struct {
+	nonce  string
+	seenAt time.Time
+}
+
+// NewEventSignatureVerifier creates a verifier for secret that accepts
+// timestamps within window and remembers up to maxNonces nonces.
+func NewEventSignatureVerifier(secret []byte, window time.Duration, maxNonces int) *EventSignatureVerifier {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		panic("event signature token: " + err.Error())
+	}
+
+	return &EventSignatureVerifier{
+		secret:    secret,
+		window:    window,
+		maxNonces: maxNonces,
+		now:       time.Now,
+		nonces:    make(map[string]*list.Element),
+		order:     list.New(),
+		token:     hex.EncodeToString(token),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_signature_rejections_total",
+			Help: "Event requests rejected by signature verification, by reason.",
+		}, []string{"reason"}),
+	}
+}
+
+// NewEventSignatureVerifierFromEnv creates a verifier configured by:
+//   - EVENT_SIGNING_SECRET: the shared secret; empty returns nil, which leaves
+//     the event endpoints unsigned
+//   - EVENT_SIGNATURE_WINDOW: accepted clock skew either way (default "5m")
+//   - EVENT_NONCE_CACHE_SIZE: nonces remembered for replay detection (default 100000)
+func NewEventSignatureVerifierFromEnv() *EventSignatureVerifier {
+	secret := GetEnv("EVENT_SIGNING_SECRET", "")
+	if secret == "" {
+		return nil
+	}
+
+	maxNonces := GetEnvInt("EVENT_NONCE_CACHE_SIZE", defaultEventNonceCacheSize)
+	if maxNonces <= 0 {
+		maxNonces = defaultEventNonceCacheSize
+	}
+	return NewEventSignatureVerifier([]byte(secret), parseDurationEnv("EVENT_SIGNATURE_WINDOW", defaultEventSignatureWindow), maxNonces)
+}
+
+// Collectors returns the signature verification metrics for registration,
+// none for a nil verifier.
+func (v *EventSignatureVerifier) Collectors() []prometheus.Collector {
+	if v == nil {
+		return nil
+	}
+	return []prometheus.Collector{v.rejected}
+}
+
+// HTTPMiddleware verifies the requests to paths under pathPrefix before next
+// serves them; other requests are passed through. A nil verifier passes every
+// request through.
+func (v *EventSignatureVerifier) HTTPMiddleware(next http.Handler, pathPrefix string) http.Handler {
+	if v == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, pathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedEventBodyBytes))
+		if err != nil {
+			v.reject(w, r, EventRejectBodyTooLarge, "request body is too large or unreadable")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if reason, message := v.verify(r.Header, body); reason != "" {
+			v.reject(w, r, reason, message)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), eventVerifiedKey{}, true)))
+	})
+}
+
+// GatewayMetadata is a gRPC-Gateway metadata annotator that passes the
+// verification of HTTPMiddleware on to UnaryServerInterceptor: requests that
+// passed it carry the verifier's token as x-event-verified metadata.
+func (v *EventSignatureVerifier) GatewayMetadata(ctx context.Context, r *http.Request) metadata.MD {
+	if v == nil || r.Context().Value(eventVerifiedKey{}) == nil {
+		return nil
+	}
+	return metadata.Pairs(EventVerifiedMetadata, v.token)
+}
+
+// UnaryServerInterceptor rejects calls to methods (full gRPC method names)
+// that did not come through the gateway after HTTPMiddleware verified them,
+// so native gRPC and gRPC-Web cannot skip the signature. Other methods are
+// passed through, as is every call with a nil verifier.
+func (v *EventSignatureVerifier) UnaryServerInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	signed := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		signed[method] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if v == nil || !signed[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, token := range md.Get(EventVerifiedMetadata) {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(v.token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+
+		v.rejected.WithLabelValues(EventRejectUnsigned).Inc()
+		logrus.WithFields(logrus.Fields{
+			"method":    info.FullMethod,
+			"reason":    EventRejectUnsigned,
+			"client_ip": GetClientIPFromContext(ctx),
+		}).Warn("Rejected unsigned event call")
+		return nil, status.Error(codes.Unauthenticated, "event calls must be signed and sent over HTTP under /v1/events/")
+	}
+}
+
+// verify checks the signature headers of a request with body. It returns the
+// rejection reason and message, or "" for a valid request, whose nonce is
+// then remembered.
+func (v *EventSignatureVerifier) verify(header http.Header, body []byte) (string, string) {
+	signature := header.Get(EventSignatureHeader)
+	timestamp := header.Get(EventTimestampHeader)
+	nonce := header.Get(EventNonceHeader)
+	if signature == "" || timestamp == "" || nonce == "" {
+		return EventRejectMissingHeaders, EventSignatureHeader + ", " + EventTimestampHeader + " and " + EventNonceHeader + " are required"
+	}
+	if len(nonce) > maxEventNonceBytes {
+		return EventRejectBadNonce, EventNonceHeader + " is longer than " + strconv.Itoa(maxEventNonceBytes) + " bytes"
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return EventRejectBadTimestamp, EventTimestampHeader + " must be Unix seconds"
+	}
+	now := v.now()
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > v.window || skew < -v.window {
+		return EventRejectClockSkew, EventTimestampHeader + " is outside the accepted window"
+	}
+
+	if !v.validSignature(signature, timestamp, nonce, body) {
+		return EventRejectBadSignature, "invalid " + EventSignatureHeader
+	}
+
+	if !v.rememberNonce(nonce, now) {
+		return EventRejectReplay, EventNonceHeader + " was already used"
+	}
+	return "", ""
+}
+
+// validSignature compares signature with the HMAC of the request in constant time.
+func (v *EventSignatureVerifier) validSignature(signature, timestamp, nonce string, body []byte) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, eventSignaturePrefix))
+	if err != nil || !strings.HasPrefix(signature, eventSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal(got, SignEvent(v.secret, timestamp, nonce, body))
+}
+
+// SignEvent returns the HMAC-SHA256 of a signed event request; callers send
+// it hex-encoded with the "sha256=" prefix in X-Event-Signature.
+func SignEvent(secret []byte, timestamp, nonce string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write([]byte(nonce))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// rememberNonce records nonce as seen at now, and reports false if it was
+// already seen within twice the window.
+func (v *EventSignatureVerifier) rememberNonce(nonce string, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Nonces older than twice the window can only come with a rejected timestamp
+	for oldest := v.order.Back(); oldest != nil; oldest = v.order.Back() {
+		seen := oldest.Value.(*seenNonce)
+		if now.Sub(seen.seenAt) <= 2*v.window {
+			break
+		}
+		v.order.Remove(oldest)
+		delete(v.nonces, seen.nonce)
+	}
+
+	if _, ok := v.nonces[nonce]; ok {
+		return false
+	}
+
+	v.nonces[nonce] = v.order.PushFront(&seenNonce{nonce: nonce, seenAt: now})
+	if v.order.Len() > v.maxNonces {
+		oldest := v.order.Back()
+		v.order.Remove(oldest)
+		delete(v.nonces, oldest.Value.(*seenNonce).nonce)
+	}
+	return true
+}
+
+// reject answers a request that failed verification with 401.
+func (v *EventSignatureVerifier) reject(w http.ResponseWriter, r *http.Request, reason, message string) {
+	v.rejected.WithLabelValues(reason).Inc()
+	logrus.WithFields(logrus.Fields{
+		"path":      r.URL.Path,
+		"reason":    reason,
+		"client_ip": GetClientIPFromContext(r.Context()),
+	}).Warn("Rejected event request signature")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = w.Write([]byte(`{"code":16,"message":"` + message + `","details":[]}`))
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	pb "extend-challenge-service/pkg/pb"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	eventTestSecret = []byte("shared-secret")
+	eventTestNow    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+const eventTestBody = `{"userId":"user-1","stats":{"kills":3}}`
+
+// newTestEventVerifier returns a verifier at eventTestNow with a 5 minute
+// window and room for maxNonces nonces, and its middleware in front of a
+// handler that echoes the body it receives.
+func newTestEventVerifier(maxNonces int) (*EventSignatureVerifier, http.Handler) {
+	verifier := NewEventSignatureVerifier(eventTestSecret, 5*time.Minute, maxNonces)
+	verifier.now = func() time.Time { return eventTestNow }
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	})
+	return verifier, verifier.HTTPMiddleware(echo, "/v1/events/")
+}
+
+// signedEventRequest builds a request signed with secret at timestamp.
+func signedEventRequest(secret []byte, timestamp time.Time, nonce, body string) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/v1/events/match", strings.NewReader(body))
+	req.Header.Set(EventTimestampHeader, ts)
+	req.Header.Set(EventNonceHeader, nonce)
+	req.Header.Set(EventSignatureHeader, "sha256="+hex.EncodeToString(SignEvent(secret, ts, nonce, []byte(body))))
+	return req
+}
+
+func serveEvent(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventSignatureVerifier_ValidRequest(t *testing.T) {
+	verifier, h := newTestEventVerifier(10)
+
+	w := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, eventTestBody, w.Body.String(), "the verified body is passed on")
+	assert.Equal(t, 0, testutil.CollectAndCount(verifier.rejected))
+}
+
+func TestEventSignatureVerifier_ClockSkew(t *testing.T) {
+	tests := map[string]struct {
+		skew time.Duration
+		want int
+	}{
+		"within window behind": {skew: -4 * time.Minute, want: http.StatusOK},
+		"within window ahead":  {skew: 5 * time.Minute, want: http.StatusOK},
+		"too old":              {skew: -6 * time.Minute, want: http.StatusUnauthorized},
+		"too far ahead":        {skew: 6 * time.Minute, want: http.StatusUnauthorized},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			verifier, h := newTestEventVerifier(10)
+
+			w := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow.Add(tt.skew), "nonce-1", eventTestBody))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusUnauthorized {
+				assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectClockSkew)))
+			}
+		})
+	}
+}
+
+func TestEventSignatureVerifier_TamperedRequests(t *testing.T) {
+	tests := map[string]func(req *http.Request){
+		"tampered body": func(req *http.Request) {
+			req.Body = io.NopCloser(strings.NewReader(`{"userId":"user-1","stats":{"kills":300}}`))
+		},
+		"tampered timestamp": func(req *http.Request) {
+			req.Header.Set(EventTimestampHeader, strconv.FormatInt(eventTestNow.Unix()-1, 10))
+		},
+		"tampered nonce": func(req *http.Request) {
+			req.Header.Set(EventNonceHeader, "nonce-2")
+		},
+		"missing prefix": func(req *http.Request) {
+			req.Header.Set(EventSignatureHeader, strings.TrimPrefix(req.Header.Get(EventSignatureHeader), "sha256="))
+		},
+		"not hex": func(req *http.Request) {
+			req.Header.Set(EventSignatureHeader, "sha256=zz")
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			verifier, h := newTestEventVerifier(10)
+			req := signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody)
+			tamper(req)
+
+			w := serveEvent(h, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.JSONEq(t, `{"code":16,"message":"invalid X-Event-Signature","details":[]}`, w.Body.String())
+			assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectBadSignature)))
+		})
+	}
+}
+
+func TestEventSignatureVerifier_WrongSecret(t *testing.T) {
+	_, h := newTestEventVerifier(10)
+
+	w := serveEvent(h, signedEventRequest([]byte("other-secret"), eventTestNow, "nonce-1", eventTestBody))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestEventSignatureVerifier_DuplicateNonce(t *testing.T) {
+	verifier, h := newTestEventVerifier(10)
+
+	first := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody))
+	replay := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody))
+	other := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, "nonce-2", eventTestBody))
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusUnauthorized, replay.Code)
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectReplay)))
+}
+
+func TestEventSignatureVerifier_InvalidSignatureDoesNotConsumeNonce(t *testing.T) {
+	_, h := newTestEventVerifier(10)
+
+	forged := serveEvent(h, signedEventRequest([]byte("other-secret"), eventTestNow, "nonce-1", eventTestBody))
+	genuine := serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody))
+
+	assert.Equal(t, http.StatusUnauthorized, forged.Code)
+	assert.Equal(t, http.StatusOK, genuine.Code)
+}
+
+func TestEventSignatureVerifier_NonceCacheBounds(t *testing.T) {
+	verifier, _ := newTestEventVerifier(2)
+
+	require.True(t, verifier.rememberNonce("a", eventTestNow))
+	require.True(t, verifier.rememberNonce("b", eventTestNow))
+	require.True(t, verifier.rememberNonce("c", eventTestNow))
+	assert.Equal(t, 2, verifier.order.Len(), "the oldest nonce is evicted past the cap")
+	assert.True(t, verifier.rememberNonce("a", eventTestNow))
+	assert.False(t, verifier.rememberNonce("c", eventTestNow))
+
+	// Past twice the window every nonce has expired
+	later := eventTestNow.Add(11 * time.Minute)
+	assert.True(t, verifier.rememberNonce("c", later))
+	assert.Equal(t, 1, verifier.order.Len())
+}
+
+func TestEventSignatureVerifier_MissingHeaders(t *testing.T) {
+	for _, header := range []string{EventSignatureHeader, EventTimestampHeader, EventNonceHeader} {
+		t.Run(header, func(t *testing.T) {
+			verifier, h := newTestEventVerifier(10)
+			req := signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody)
+			req.Header.Del(header)
+
+			w := serveEvent(h, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectMissingHeaders)))
+		})
+	}
+}
+
+func TestEventSignatureVerifier_BadTimestampAndNonce(t *testing.T) {
+	verifier, h := newTestEventVerifier(10)
+
+	req := signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", eventTestBody)
+	req.Header.Set(EventTimestampHeader, "2025-06-01T12:00:00Z")
+	assert.Equal(t, http.StatusUnauthorized, serveEvent(h, req).Code)
+
+	long := strings.Repeat("n", maxEventNonceBytes+1)
+	assert.Equal(t, http.StatusUnauthorized, serveEvent(h, signedEventRequest(eventTestSecret, eventTestNow, long, eventTestBody)).Code)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectBadTimestamp)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectBadNonce)))
+}
+
+func TestEventSignatureVerifier_OtherPathsPassThrough(t *testing.T) {
+	_, h := newTestEventVerifier(10)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/challenges/claim-all", strings.NewReader("{}"))
+
+	assert.Equal(t, http.StatusOK, serveEvent(h, req).Code)
+}
+
+func TestEventSignatureVerifier_Disabled(t *testing.T) {
+	assert.Nil(t, NewEventSignatureVerifierFromEnv())
+
+	var verifier *EventSignatureVerifier
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	req := httptest.NewRequest(http.MethodPost, "/v1/events/match", nil)
+	assert.Equal(t, http.StatusOK, serveEvent(verifier.HTTPMiddleware(next, "/v1/events/"), req).Code)
+	assert.Empty(t, verifier.Collectors())
+}
+
+func TestNewEventSignatureVerifierFromEnv(t *testing.T) {
+	t.Setenv("EVENT_SIGNING_SECRET", "secret")
+	t.Setenv("EVENT_SIGNATURE_WINDOW", "2m")
+	t.Setenv("EVENT_NONCE_CACHE_SIZE", "0")
+
+	verifier := NewEventSignatureVerifierFromEnv()
+
+	require.NotNil(t, verifier)
+	assert.Equal(t, []byte("secret"), verifier.secret)
+	assert.Equal(t, 2*time.Minute, verifier.window)
+	assert.Equal(t, defaultEventNonceCacheSize, verifier.maxNonces)
+}
+
+// matchResultServer answers ReportMatchResult with status "applied".
+type matchResultServer struct {
+	pb.UnimplementedServiceServer
+}
+
+func (matchResultServer) ReportMatchResult(_ context.Context, req *pb.ReportMatchResultRequest) (*pb.ReportMatchResultResponse, error) {
+	return &pb.ReportMatchResultResponse{UserId: req.UserId, Status: "applied"}, nil
+}
+
+// newEventGRPCStack serves matchResultServer behind verifier's interceptor
+// over an in-memory listener, and returns a client connection to it and the
+// signed gateway in front of it.
+func newEventGRPCStack(t *testing.T, verifier *EventSignatureVerifier) (*grpc.ClientConn, http.Handler) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(verifier.UnaryServerInterceptor(pb.Service_ReportMatchResult_FullMethodName)))
+	pb.RegisterServiceServer(s, matchResultServer{})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	mux := NewGatewayServeMux(runtime.WithMetadata(verifier.GatewayMetadata))
+	require.NoError(t, pb.RegisterServiceHandlerClient(context.Background(), mux, pb.NewServiceClient(conn)))
+	return conn, verifier.HTTPMiddleware(mux, "/v1/events/")
+}
+
+func TestEventSignatureVerifier_RejectsUnsignedGRPC(t *testing.T) {
+	verifier, _ := newTestEventVerifier(10)
+	conn, _ := newEventGRPCStack(t, verifier)
+
+	_, err := pb.NewServiceClient(conn).ReportMatchResult(context.Background(), &pb.ReportMatchResultRequest{UserId: "user-1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, 1.0, testutil.ToFloat64(verifier.rejected.WithLabelValues(EventRejectUnsigned)))
+}
+
+func TestEventSignatureVerifier_RejectsForgedGRPCToken(t *testing.T) {
+	verifier, _ := newTestEventVerifier(10)
+	conn, _ := newEventGRPCStack(t, verifier)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), EventVerifiedMetadata, "forged")
+	_, err := pb.NewServiceClient(conn).ReportMatchResult(ctx, &pb.ReportMatchResultRequest{UserId: "user-1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestEventSignatureVerifier_SignedGatewayCallReachesGRPC(t *testing.T) {
+	verifier, _ := newTestEventVerifier(10)
+	_, gateway := newEventGRPCStack(t, verifier)
+
+	w := serveEvent(gateway, signedEventRequest(eventTestSecret, eventTestNow, "nonce-1", `{"userId":"user-1"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"applied"`)
+	assert.Equal(t, 0, testutil.CollectAndCount(verifier.rejected))
+}
+
+func TestEventSignatureVerifier_InterceptorPassesOtherMethods(t *testing.T) {
+	verifier, _ := newTestEventVerifier(10)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	resp, err := verifier.UnaryServerInterceptor(pb.Service_ReportMatchResult_FullMethodName)(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_BatchReportProgress_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	var disabled *EventSignatureVerifier
+	resp, err = disabled.UnaryServerInterceptor(pb.Service_ReportMatchResult_FullMethodName)(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_ReportMatchResult_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp, "a nil verifier accepts unsigned calls")
+}
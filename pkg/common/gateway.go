@@ -22,27 +22,9 @@ type Gateway struct {
 	basePath string
 }
 
-func NewGateway(ctx context.Context, grpcServerEndpoint string, basePath string) (*Gateway, error) {
-	// Configure gRPC Gateway to forward x-mock-user-id header to gRPC metadata
-	// This enables E2E testing with different user IDs when backend auth is disabled
-	headerMatcher := func(key string) (string, bool) {
-		switch strings.ToLower(key) {
-		case "x-mock-user-id":
-			// Forward mock user ID header for testing
-			return key, true
-		default:
-			// Use default behavior for other headers
-			return runtime.DefaultHeaderMatcher(key)
-		}
-	}
-
-	// Use sonic marshaler for 2-3x faster JSON encoding (52% CPU time reduction)
-	sonicMarshaler := NewSonicMarshaler()
+func NewGateway(ctx context.Context, grpcServerEndpoint string, basePath string, muxOpts ...runtime.ServeMuxOption) (*Gateway, error) {
+	mux := NewGatewayServeMux(muxOpts...)
 
-	mux := runtime.NewServeMux(
-		runtime.WithIncomingHeaderMatcher(headerMatcher),
-		runtime.WithMarshalerOption(runtime.MIMEWildcard, sonicMarshaler),
-	)
 	// Configure gRPC buffer sizes to reduce reallocations
 	// Typical challenge list response: ~10-20KB, 32KB buffers provide headroom
 	opts := []grpc.DialOption{
@@ -63,6 +45,45 @@ func NewGateway(ctx context.Context, grpcServerEndpoint string, basePath string)
 	}, nil
 }
 
+// NewGatewayServeMux returns the gRPC-Gateway mux with the service's header
+// matching and JSON marshaling, before any handler is registered. opts are
+// applied after the service's own.
+func NewGatewayServeMux(opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	// Configure gRPC Gateway to forward x-mock-user-id header to gRPC metadata
+	// This enables E2E testing with different user IDs when backend auth is disabled
+	headerMatcher := func(key string) (string, bool) {
+		switch strings.ToLower(key) {
+		case "x-mock-user-id":
+			// Forward mock user ID header for testing
+			return key, true
+		case "x-debug-timing":
+			// Forwarded as x-debug-timing metadata (see DebugTiming)
+			return DebugTimingMetadata, true
+		default:
+			// Use default behavior for other headers
+			return runtime.DefaultHeaderMatcher(key)
+		}
+	}
+
+	// server-timing header metadata goes back as the standard Server-Timing
+	// header rather than Grpc-Metadata-Server-Timing
+	outgoingHeaderMatcher := func(key string) (string, bool) {
+		if key == ServerTimingMetadata {
+			return ServerTimingHeader, true
+		}
+		return runtime.MetadataHeaderPrefix + key, true
+	}
+
+	// Use sonic marshaler for 2-3x faster JSON encoding (52% CPU time reduction)
+	sonicMarshaler := NewSonicMarshaler()
+
+	return runtime.NewServeMux(append([]runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, sonicMarshaler),
+	}, opts...)...)
+}
+
 func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Strip the base path, since the base_path configuration in protofile won't actually do the routing
 	// Reference: https://github.com/grpc-ecosystem/grpc-gateway/pull/919/commits/1c34df861cfc0d6cb19ea617921d7d9eaa209977
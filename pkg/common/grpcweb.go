@@ -0,0 +1,84 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+const defaultGRPCWebPath = "/grpc-web"
+
+// GRPCWebConfig controls the gRPC-Web endpoint served by the gateway HTTP server.
+type GRPCWebConfig struct {
+	// Enabled mounts the endpoint on the gateway HTTP server.
+	Enabled bool
+	// Path is the prefix the endpoint is mounted under (after the base path).
+	// Clients call <basePath><Path>/service.Service/<Method>.
+	Path string
+	// AllowedOrigins lists the origins allowed to make cross-origin calls.
+	// "*" allows any origin; empty allows same-origin calls only.
+	AllowedOrigins []string
+}
+
+// NewGRPCWebConfigFromEnv reads the gRPC-Web configuration from:
+//   - GRPC_WEB_ENABLED: "true" to serve gRPC-Web (default "true")
+//   - GRPC_WEB_PATH: path prefix of the endpoint (default "/grpc-web")
+//   - CORS_ALLOWED_ORIGINS: comma-separated origins allowed for cross-origin calls (default unset = same-origin only)
+func NewGRPCWebConfigFromEnv() GRPCWebConfig {
+	path := "/" + strings.Trim(GetEnv("GRPC_WEB_PATH", defaultGRPCWebPath), "/")
+	if path == "/" {
+		path = defaultGRPCWebPath
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(GetEnv("CORS_ALLOWED_ORIGINS", ""), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return GRPCWebConfig{
+		Enabled:        strings.ToLower(GetEnv("GRPC_WEB_ENABLED", "true")) == "true",
+		Path:           path,
+		AllowedOrigins: origins,
+	}
+}
+
+// IsOriginAllowed reports whether cross-origin calls from origin are allowed.
+func (c GRPCWebConfig) IsOriginAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewGRPCWebHandler serves gRPC-Web calls (application/grpc-web and
+// application/grpc-web-text) by passing them to the gRPC server in-process.
+// Calls run through the server's interceptor chain, so the Authorization
+// header is checked exactly like on native gRPC.
+//
+// The handler expects paths with prefix already stripped, i.e. /service.Service/<Method>.
+// Requests that are neither gRPC-Web calls nor their CORS preflight are rejected
+// with 415 instead of reaching the gRPC server.
+func NewGRPCWebHandler(server *grpc.Server, config GRPCWebConfig) http.Handler {
+	wrapped := grpcweb.WrapServer(server,
+		grpcweb.WithOriginFunc(config.IsOriginAllowed),
+		grpcweb.WithCorsForRegisteredEndpointsOnly(true),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !wrapped.IsGrpcWebRequest(r) && !wrapped.IsAcceptableGrpcCorsRequest(r) {
+			http.Error(w, "expected a gRPC-Web request", http.StatusUnsupportedMediaType)
+			return
+		}
+		wrapped.ServeHTTP(w, r)
+	})
+}
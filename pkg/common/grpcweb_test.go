@@ -0,0 +1,274 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web+proto"
+	grpcWebTextContentType = "application/grpc-web-text+proto"
+)
+
+// grpcWebTestService answers GetUserChallenges with one challenge named after
+// the user ID the auth interceptor put in the context.
+type grpcWebTestService struct {
+	pb.UnimplementedServiceServer
+}
+
+func (s *grpcWebTestService) GetUserChallenges(ctx context.Context, req *pb.GetChallengesRequest) (*pb.GetChallengesResponse, error) {
+	userID, err := GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetChallengesResponse{
+		Challenges: []*pb.Challenge{{ChallengeId: "daily", Name: userID}},
+	}, nil
+}
+
+// grpcWebTestValidator accepts a single token.
+type grpcWebTestValidator struct {
+	token string
+}
+
+func (v grpcWebTestValidator) Initialize(ctx ...context.Context) error { return nil }
+
+func (v grpcWebTestValidator) Validate(token string, permission *iam.Permission, namespace *string, userID *string) error {
+	if token != v.token {
+		return assert.AnError
+	}
+	return nil
+}
+
+// testJWT builds an unsigned JWT carrying the given subject; signatures are
+// checked by the validator, which is faked here.
+func testJWT(t *testing.T, sub string) string {
+	t.Helper()
+	payload, err := json.Marshal(JWTClaims{Sub: sub, Namespace: "test-namespace"})
+	require.NoError(t, err)
+	return "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// newGRPCWebTestServer serves the gRPC-Web handler under /grpc-web, the same
+// way newGRPCGatewayHTTPServer mounts it. An empty validToken leaves auth disabled.
+func newGRPCWebTestServer(t *testing.T, validToken string, config GRPCWebConfig) *httptest.Server {
+	t.Helper()
+
+	if validToken != "" {
+		previous := Validator
+		Validator = grpcWebTestValidator{token: validToken}
+		t.Cleanup(func() { Validator = previous })
+	}
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(NewUnaryAuthServerIntercept(NewProtoPermissionExtractor(), NewAuthAllowList())))
+	pb.RegisterServiceServer(grpcServer, &grpcWebTestService{})
+
+	mux := http.NewServeMux()
+	mux.Handle("/grpc-web/", http.StripPrefix("/grpc-web", NewGRPCWebHandler(grpcServer, config)))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// grpcWebResult is a decoded gRPC-Web response.
+type grpcWebResult struct {
+	messages [][]byte
+	status   codes.Code
+	message  string
+}
+
+// callGRPCWeb makes a unary gRPC-Web call the way a browser client would:
+// one length-prefixed message in the body, base64-encoded for the text format.
+func callGRPCWeb(t *testing.T, url, contentType, authorization string, req proto.Message) grpcWebResult {
+	t.Helper()
+
+	data, err := proto.Marshal(req)
+	require.NoError(t, err)
+	body := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(body[1:5], uint32(len(data)))
+	copy(body[5:], data)
+
+	text := strings.HasPrefix(contentType, "application/grpc-web-text")
+	if text {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("X-Grpc-Web", "1")
+	if authorization != "" {
+		httpReq.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	payload, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if text {
+		payload = decodeGRPCWebText(t, payload)
+	}
+
+	result := grpcWebResult{status: codes.Unknown}
+	trailers := http.Header{}
+	for k, v := range resp.Header {
+		trailers[k] = v
+	}
+
+	for len(payload) > 0 {
+		require.GreaterOrEqual(t, len(payload), 5, "truncated frame header")
+		flag := payload[0]
+		length := binary.BigEndian.Uint32(payload[1:5])
+		frame := payload[5 : 5+length]
+		payload = payload[5+length:]
+
+		if flag&0x80 == 0 {
+			result.messages = append(result.messages, frame)
+			continue
+		}
+		for _, line := range strings.Split(string(frame), "\r\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				trailers.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+			}
+		}
+	}
+
+	if code, err := strconv.Atoi(trailers.Get("Grpc-Status")); err == nil {
+		result.status = codes.Code(code)
+	}
+	result.message = trailers.Get("Grpc-Message")
+	return result
+}
+
+// decodeGRPCWebText decodes a grpc-web-text body. The server encodes every
+// flush separately, so the body may hold several padded base64 chunks.
+func decodeGRPCWebText(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	var decoded []byte
+	for i := 0; i+4 <= len(body); i += 4 {
+		quantum, err := base64.StdEncoding.DecodeString(string(body[i : i+4]))
+		require.NoError(t, err)
+		decoded = append(decoded, quantum...)
+	}
+	return decoded
+}
+
+func TestGRPCWeb_GetUserChallengesRoundTrip(t *testing.T) {
+	token := testJWT(t, "user-42")
+	server := newGRPCWebTestServer(t, token, GRPCWebConfig{Enabled: true, Path: "/grpc-web"})
+	url := server.URL + "/grpc-web" + pb.Service_GetUserChallenges_FullMethodName
+
+	for _, contentType := range []string{grpcWebContentType, grpcWebTextContentType} {
+		t.Run(contentType, func(t *testing.T) {
+			result := callGRPCWeb(t, url, contentType, "Bearer "+token, &pb.GetChallengesRequest{ActiveOnly: true})
+
+			require.Equal(t, codes.OK, result.status, result.message)
+			require.Len(t, result.messages, 1)
+
+			var resp pb.GetChallengesResponse
+			require.NoError(t, proto.Unmarshal(result.messages[0], &resp))
+			require.Len(t, resp.Challenges, 1)
+			assert.Equal(t, "daily", resp.Challenges[0].ChallengeId)
+			assert.Equal(t, "user-42", resp.Challenges[0].Name, "user ID comes from the Authorization header")
+		})
+	}
+}
+
+func TestGRPCWeb_RejectsInvalidToken(t *testing.T) {
+	server := newGRPCWebTestServer(t, testJWT(t, "user-42"), GRPCWebConfig{Enabled: true, Path: "/grpc-web"})
+	url := server.URL + "/grpc-web" + pb.Service_GetUserChallenges_FullMethodName
+
+	missing := callGRPCWeb(t, url, grpcWebContentType, "", &pb.GetChallengesRequest{})
+	assert.Equal(t, codes.Unauthenticated, missing.status)
+	assert.Empty(t, missing.messages)
+
+	invalid := callGRPCWeb(t, url, grpcWebContentType, "Bearer wrong", &pb.GetChallengesRequest{})
+	assert.Equal(t, codes.PermissionDenied, invalid.status)
+	assert.Empty(t, invalid.messages)
+}
+
+func TestGRPCWeb_CORSPreflight(t *testing.T) {
+	server := newGRPCWebTestServer(t, "", GRPCWebConfig{
+		Enabled:        true,
+		Path:           "/grpc-web",
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+	url := server.URL + "/grpc-web" + pb.Service_GetUserChallenges_FullMethodName
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization,content-type,x-grpc-web")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	allowed := preflight("https://app.example.com")
+	assert.Equal(t, "https://app.example.com", allowed.Header.Get("Access-Control-Allow-Origin"))
+
+	denied := preflight("https://evil.example.com")
+	assert.Empty(t, denied.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestGRPCWeb_RejectsNonGRPCWebRequests(t *testing.T) {
+	server := newGRPCWebTestServer(t, "", GRPCWebConfig{Enabled: true, Path: "/grpc-web"})
+
+	resp, err := http.Post(server.URL+"/grpc-web"+pb.Service_GetUserChallenges_FullMethodName, "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestNewGRPCWebConfigFromEnv(t *testing.T) {
+	for _, key := range []string{"GRPC_WEB_ENABLED", "GRPC_WEB_PATH", "CORS_ALLOWED_ORIGINS"} {
+		t.Setenv(key, "") // restores the variable after the test
+		require.NoError(t, os.Unsetenv(key))
+	}
+	defaults := NewGRPCWebConfigFromEnv()
+	assert.True(t, defaults.Enabled)
+	assert.Equal(t, "/grpc-web", defaults.Path)
+	assert.Empty(t, defaults.AllowedOrigins)
+
+	t.Setenv("GRPC_WEB_ENABLED", "false")
+	t.Setenv("GRPC_WEB_PATH", "web/")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+	custom := NewGRPCWebConfigFromEnv()
+	assert.False(t, custom.Enabled)
+	assert.Equal(t, "/web", custom.Path)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, custom.AllowedOrigins)
+	assert.True(t, custom.IsOriginAllowed("https://B.example.com"))
+	assert.False(t, custom.IsOriginAllowed("https://c.example.com"))
+}
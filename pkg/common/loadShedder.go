@@ -0,0 +1,308 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultMaxInFlightReads  = 512
+	defaultMaxInFlightWrites = 128
+	defaultLoadShedRetry     = "1s"
+
+	transportHTTP = "http"
+	transportGRPC = "grpc"
+
+	overloadedMessage = "server is overloaded, retry later"
+)
+
+// RequestClass is the concurrency budget a request counts against.
+type RequestClass string
+
+const (
+	// RequestClassRead covers GET (and HEAD/OPTIONS) endpoints.
+	RequestClassRead RequestClass = "read"
+	// RequestClassWrite covers every mutating endpoint.
+	RequestClassWrite RequestClass = "write"
+)
+
+// LoadShedConfig sizes the in-flight budgets of the load shedder.
+type LoadShedConfig struct {
+	// MaxInFlightReads is the number of concurrent read requests per transport. Zero disables the limit.
+	MaxInFlightReads int
+	// MaxInFlightWrites is the number of concurrent mutating requests per transport. Zero disables the limit.
+	MaxInFlightWrites int
+	// RetryAfter is the delay suggested to shed clients (Retry-After header, gRPC RetryInfo).
+	RetryAfter time.Duration
+}
+
+// NewLoadShedConfigFromEnv reads the load shedding configuration from:
+//   - MAX_IN_FLIGHT_READS: concurrent read requests per transport (default 512, "0" disables)
+//   - MAX_IN_FLIGHT_WRITES: concurrent mutating requests per transport (default 128, "0" disables)
+//   - LOAD_SHED_RETRY_AFTER: delay suggested to shed clients (default "1s")
+//
+// Invalid values fall back to the defaults.
+func NewLoadShedConfigFromEnv() LoadShedConfig {
+	return LoadShedConfig{
+		MaxInFlightReads:  max(GetEnvInt("MAX_IN_FLIGHT_READS", defaultMaxInFlightReads), 0),
+		MaxInFlightWrites: max(GetEnvInt("MAX_IN_FLIGHT_WRITES", defaultMaxInFlightWrites), 0),
+		RetryAfter:        parseDurationEnv("LOAD_SHED_RETRY_AFTER", defaultLoadShedRetry),
+	}
+}
+
+// LoadShedder bounds the number of requests in flight and rejects the excess
+// immediately, before any authentication or database work, instead of letting
+// them queue on the database pool.
+//
+// The HTTP gateway and the gRPC server have separate budgets: gateway calls are
+// proxied to the gRPC server, so sharing a budget would count them twice.
+// Within each transport, reads and writes have separate budgets so a burst of
+// one cannot starve the other.
+type LoadShedder struct {
+	config  LoadShedConfig
+	budgets map[string]chan struct{}
+
+	inFlight *prometheus.GaugeVec
+	shed     *prometheus.CounterVec
+
+	grpcClasses sync.Map // full method -> grpcMethodClass
+}
+
+type grpcMethodClass struct {
+	class   RequestClass
+	limited bool
+}
+
+// NewLoadShedder creates a load shedder with the given budgets.
+func NewLoadShedder(config LoadShedConfig) *LoadShedder {
+	l := &LoadShedder{
+		config:  config,
+		budgets: make(map[string]chan struct{}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "in_flight_requests",
+			Help: "Requests currently being served, by transport and request class.",
+		}, []string{"transport", "class"}),
+		shed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "shed_requests_total",
+			Help: "Requests rejected because the in-flight budget was exhausted, by transport and request class.",
+		}, []string{"transport", "class"}),
+	}
+
+	for _, transport := range []string{transportHTTP, transportGRPC} {
+		if config.MaxInFlightReads > 0 {
+			l.budgets[budgetKey(transport, RequestClassRead)] = make(chan struct{}, config.MaxInFlightReads)
+		}
+		if config.MaxInFlightWrites > 0 {
+			l.budgets[budgetKey(transport, RequestClassWrite)] = make(chan struct{}, config.MaxInFlightWrites)
+		}
+	}
+
+	return l
+}
+
+// Collectors returns the load shedding metrics for registration.
+func (l *LoadShedder) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{l.inFlight, l.shed}
+}
+
+// HTTPMiddleware limits the requests served by next. Excess requests get
+// 503 Service Unavailable with a Retry-After header.
+//
+// Requests to exemptPaths (health probes) are never limited. gRPC-Web calls are
+// passed through: they are limited by the gRPC interceptors, which know the method.
+func (l *LoadShedder) HTTPMiddleware(next http.Handler, exemptPaths ...string) http.Handler {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, p := range exemptPaths {
+		exempt[p] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if exempt[r.URL.Path] || strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		release, ok := l.acquire(transportHTTP, httpRequestClass(r.Method))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(l.retryAfterSeconds()))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":14,"message":"` + overloadedMessage + `","details":[]}`))
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TapHandle rejects calls whose budget is already exhausted before the server
+// reads the request. Use it with grpc.InTapHandle; the interceptors still do
+// the actual accounting.
+func (l *LoadShedder) TapHandle(ctx context.Context, info *tap.Info) (context.Context, error) {
+	method := l.grpcMethodClass(info.FullMethodName)
+	if !method.limited {
+		return ctx, nil
+	}
+
+	budget := l.budgets[budgetKey(transportGRPC, method.class)]
+	if budget != nil && len(budget) == cap(budget) {
+		l.shed.WithLabelValues(transportGRPC, string(method.class)).Inc()
+		return nil, l.overloadedError()
+	}
+
+	return ctx, nil
+}
+
+// UnaryServerInterceptor limits unary calls. Excess calls fail with
+// codes.Unavailable and a RetryInfo detail.
+func (l *LoadShedder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := l.grpcMethodClass(info.FullMethod)
+		if !method.limited {
+			return handler(ctx, req)
+		}
+
+		release, ok := l.acquire(transportGRPC, method.class)
+		if !ok {
+			return nil, l.overloadedError()
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor limits streaming calls like UnaryServerInterceptor.
+func (l *LoadShedder) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		method := l.grpcMethodClass(info.FullMethod)
+		if !method.limited {
+			return handler(srv, ss)
+		}
+
+		release, ok := l.acquire(transportGRPC, method.class)
+		if !ok {
+			return l.overloadedError()
+		}
+		defer release()
+
+		return handler(srv, ss)
+	}
+}
+
+// acquire takes a slot from the budget without waiting. It returns false, and
+// counts the request as shed, when the budget is exhausted.
+func (l *LoadShedder) acquire(transport string, class RequestClass) (func(), bool) {
+	budget := l.budgets[budgetKey(transport, class)]
+	if budget != nil {
+		select {
+		case budget <- struct{}{}:
+		default:
+			l.shed.WithLabelValues(transport, string(class)).Inc()
+			return nil, false
+		}
+	}
+
+	gauge := l.inFlight.WithLabelValues(transport, string(class))
+	gauge.Inc()
+
+	return func() {
+		gauge.Dec()
+		if budget != nil {
+			<-budget
+		}
+	}, true
+}
+
+func (l *LoadShedder) overloadedError() error {
+	st := status.New(codes.Unavailable, overloadedMessage)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(l.config.RetryAfter)}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+func (l *LoadShedder) retryAfterSeconds() int {
+	return max(int(math.Ceil(l.config.RetryAfter.Seconds())), 1)
+}
+
+// grpcMethodClass returns the cached classification of a gRPC method.
+func (l *LoadShedder) grpcMethodClass(fullMethod string) grpcMethodClass {
+	if cached, ok := l.grpcClasses.Load(fullMethod); ok {
+		return cached.(grpcMethodClass)
+	}
+
+	result := classifyGRPCMethod(fullMethod)
+	l.grpcClasses.Store(fullMethod, result)
+
+	return result
+}
+
+// classifyGRPCMethod classifies a method by its google.api.http rule: GET rules are
+// reads, other rules are writes. Methods without a rule (reflection, grpc.health)
+// and HealthCheck, which backs the probes, are not limited.
+func classifyGRPCMethod(fullMethod string) grpcMethodClass {
+	serviceName, methodName, err := parseFullMethod(fullMethod)
+	if err != nil || methodName == "HealthCheck" {
+		return grpcMethodClass{}
+	}
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return grpcMethodClass{}
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return grpcMethodClass{}
+	}
+	method := serviceDesc.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return grpcMethodClass{}
+	}
+
+	rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return grpcMethodClass{}
+	}
+	if rule.GetGet() != "" {
+		return grpcMethodClass{class: RequestClassRead, limited: true}
+	}
+
+	return grpcMethodClass{class: RequestClassWrite, limited: true}
+}
+
+func httpRequestClass(method string) RequestClass {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RequestClassRead
+	default:
+		return RequestClassWrite
+	}
+}
+
+func budgetKey(transport string, class RequestClass) string {
+	return transport + "/" + string(class)
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// blockingHandler holds every request until release is closed.
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestNewLoadShedConfigFromEnv(t *testing.T) {
+	t.Setenv("MAX_IN_FLIGHT_READS", "64")
+	t.Setenv("MAX_IN_FLIGHT_WRITES", "-1")
+	t.Setenv("LOAD_SHED_RETRY_AFTER", "2500ms")
+
+	cfg := NewLoadShedConfigFromEnv()
+
+	assert.Equal(t, 64, cfg.MaxInFlightReads)
+	assert.Equal(t, 0, cfg.MaxInFlightWrites)
+	assert.Equal(t, 2500*time.Millisecond, cfg.RetryAfter)
+}
+
+func TestNewLoadShedConfigFromEnv_Defaults(t *testing.T) {
+	cfg := NewLoadShedConfigFromEnv()
+
+	assert.Equal(t, 512, cfg.MaxInFlightReads)
+	assert.Equal(t, 128, cfg.MaxInFlightWrites)
+	assert.Equal(t, time.Second, cfg.RetryAfter)
+}
+
+func TestLoadShedder_HTTPShedsExcessWithRetryAfter(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: 1, MaxInFlightWrites: 1, RetryAfter: 1500 * time.Millisecond})
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	h := shedder.HTTPMiddleware(blockingHandler(started, release))
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/challenges", nil))
+		close(done)
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/challenges", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"code":14,"message":"server is overloaded, retry later","details":[]}`, rec.Body.String())
+	assert.Equal(t, 1.0, testutil.ToFloat64(shedder.shed.WithLabelValues("http", "read")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(shedder.inFlight.WithLabelValues("http", "read")))
+
+	close(release)
+	<-done
+	assert.Equal(t, 0.0, testutil.ToFloat64(shedder.inFlight.WithLabelValues("http", "read")))
+}
+
+func TestLoadShedder_HTTPSeparateReadAndWriteBudgets(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: 1, MaxInFlightWrites: 1, RetryAfter: time.Second})
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	h := shedder.HTTPMiddleware(blockingHandler(started, release))
+
+	var wg sync.WaitGroup
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		wg.Add(1)
+		go func(method string) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/v1/challenges", nil))
+			assert.Equal(t, http.StatusOK, rec.Code, method)
+		}(method)
+	}
+	<-started
+	<-started
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/v1/challenges", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, method)
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1.0, testutil.ToFloat64(shedder.shed.WithLabelValues("http", "read")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(shedder.shed.WithLabelValues("http", "write")))
+}
+
+func TestLoadShedder_HTTPExemptions(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: 1, MaxInFlightWrites: 1, RetryAfter: time.Second})
+	// Exhaust both budgets
+	_, ok := shedder.acquire(transportHTTP, RequestClassRead)
+	require.True(t, ok)
+	_, ok = shedder.acquire(transportHTTP, RequestClassWrite)
+	require.True(t, ok)
+
+	h := shedder.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "/healthz")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "health probes are never shed")
+
+	req := httptest.NewRequest(http.MethodPost, "/grpc-web/service.Service/ClaimGoalReward", nil)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, "gRPC-Web calls are limited by the gRPC interceptors")
+}
+
+func TestLoadShedder_DisabledLimit(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{})
+
+	for i := 0; i < 1000; i++ {
+		_, ok := shedder.acquire(transportGRPC, RequestClassWrite)
+		require.True(t, ok)
+	}
+	assert.Equal(t, 1000.0, testutil.ToFloat64(shedder.inFlight.WithLabelValues("grpc", "write")))
+}
+
+func TestLoadShedder_GRPCMethodClass(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{})
+
+	tests := []struct {
+		method  string
+		class   RequestClass
+		limited bool
+	}{
+		{pb.Service_GetUserChallenges_FullMethodName, RequestClassRead, true},
+		{pb.Service_GetRotationStatus_FullMethodName, RequestClassRead, true},
+		{pb.Service_ClaimGoalReward_FullMethodName, RequestClassWrite, true},
+		{pb.Service_SetGoalActive_FullMethodName, RequestClassWrite, true},
+		{pb.Service_ResetClaimCap_FullMethodName, RequestClassWrite, true},
+		{pb.Service_HealthCheck_FullMethodName, "", false},
+		{"/grpc.health.v1.Health/Check", "", false},
+		{"invalid", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			got := shedder.grpcMethodClass(tt.method)
+			assert.Equal(t, tt.limited, got.limited)
+			assert.Equal(t, tt.class, got.class)
+		})
+	}
+}
+
+func TestLoadShedder_UnaryInterceptorSheds(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: 1, MaxInFlightWrites: 1, RetryAfter: 3 * time.Second})
+	interceptor := shedder.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	release, ok := shedder.acquire(transportGRPC, RequestClassWrite)
+	require.True(t, ok)
+
+	_, err := interceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_ClaimGoalReward_FullMethodName}, handler)
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Second, retryInfo.RetryDelay.AsDuration())
+
+	// Reads have their own budget, and HealthCheck is never limited
+	resp, err := interceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_GetUserChallenges_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_HealthCheck_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	release()
+	_, err = interceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: pb.Service_ClaimGoalReward_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(shedder.shed.WithLabelValues("grpc", "write")))
+}
+
+func TestLoadShedder_TapHandleRejectsWhenFull(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: 1, MaxInFlightWrites: 1, RetryAfter: time.Second})
+	info := &tap.Info{FullMethodName: pb.Service_GetUserChallenges_FullMethodName}
+
+	_, err := shedder.TapHandle(context.Background(), info)
+	require.NoError(t, err)
+
+	_, ok := shedder.acquire(transportGRPC, RequestClassRead)
+	require.True(t, ok)
+
+	_, err = shedder.TapHandle(context.Background(), info)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// The HTTP budget is separate
+	_, ok = shedder.acquire(transportHTTP, RequestClassRead)
+	assert.True(t, ok)
+}
+
+// TestLoadShedder_BoundedLatencyUnderOverload sends 5x the read budget of concurrent
+// requests at a handler that takes a fixed time, and checks that the handler never
+// sees more than the budget at once and that no request waits in a queue: served
+// requests take about the handler time and shed requests return immediately.
+func TestLoadShedder_BoundedLatencyUnderOverload(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping load test in short mode")
+	}
+
+	const (
+		limit       = 16
+		clients     = 5 * limit
+		rounds      = 5
+		handlerTime = 20 * time.Millisecond
+	)
+
+	var inFlight, peak int64
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt64(&peak)
+			if current <= observed || atomic.CompareAndSwapInt64(&peak, observed, current) {
+				break
+			}
+		}
+		time.Sleep(handlerTime)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	shedder := NewLoadShedder(LoadShedConfig{MaxInFlightReads: limit, MaxInFlightWrites: limit, RetryAfter: time.Second})
+	server := httptest.NewServer(shedder.HTTPMiddleware(slow))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: clients}}
+
+	var mu sync.Mutex
+	var served, shed []time.Duration
+	for round := 0; round < rounds; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < clients; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start := time.Now()
+				resp, err := httpClient.Get(server.URL + "/v1/challenges")
+				if !assert.NoError(t, err) {
+					return
+				}
+				_ = resp.Body.Close()
+				elapsed := time.Since(start)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch resp.StatusCode {
+				case http.StatusOK:
+					served = append(served, elapsed)
+				case http.StatusServiceUnavailable:
+					shed = append(shed, elapsed)
+				default:
+					t.Errorf("unexpected status %d", resp.StatusCode)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	require.NotEmpty(t, served)
+	require.NotEmpty(t, shed, "5x the budget should shed some requests")
+	assert.LessOrEqual(t, atomic.LoadInt64(&peak), int64(limit), "handler concurrency must stay within the budget")
+	assert.Equal(t, float64(len(shed)), testutil.ToFloat64(shedder.shed.WithLabelValues("http", "read")))
+
+	// Without a limit, the last of 80 requests would queue behind the others.
+	// With it, served requests take about one handler time and shed requests none.
+	assert.Less(t, percentile(served, 0.99), 10*handlerTime, "served p99")
+	assert.Less(t, percentile(shed, 0.99), 10*handlerTime, "shed p99")
+}
+
+func percentile(durations []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
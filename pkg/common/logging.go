@@ -7,9 +7,11 @@ package common
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // InterceptorLogger adapts logrus logger to interceptor logger.
@@ -18,11 +20,11 @@ func InterceptorLogger(l logrus.FieldLogger) logging.Logger {
 	return logging.LoggerFunc(func(_ context.Context, lvl logging.Level, msg string, fields ...any) {
 		f := make(map[string]any, len(fields)/2)
 		i := logging.Fields(fields).Iterator()
-		if i.Next() {
+		for i.Next() {
 			k, v := i.At()
 			f[k] = v
 		}
-		l = l.WithFields(f)
+		l := l.WithFields(f)
 
 		switch lvl {
 		case logging.LevelDebug:
@@ -38,3 +40,29 @@ func InterceptorLogger(l logrus.FieldLogger) logging.Logger {
 		}
 	})
 }
+
+// SlogLevel maps a logrus level to the slog level that lets the same entries
+// through, so slog loggers can follow LOG_LEVEL. Levels above error map to error
+// and levels below debug map to debug.
+func SlogLevel(level logrus.Level) slog.Level {
+	switch {
+	case level <= logrus.ErrorLevel:
+		return slog.LevelError
+	case level == logrus.WarnLevel:
+		return slog.LevelWarn
+	case level == logrus.InfoLevel:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// SampledTraceID returns the trace ID of the span in ctx if it is sampled, so
+// logs and metric exemplars only point at traces that were recorded.
+func SampledTraceID(ctx context.Context) (string, bool) {
+	span := trace.SpanContextFromContext(ctx)
+	if !span.IsSampled() {
+		return "", false
+	}
+	return span.TraceID().String(), true
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLevel(t *testing.T) {
+	tests := []struct {
+		level logrus.Level
+		want  slog.Level
+	}{
+		{logrus.PanicLevel, slog.LevelError},
+		{logrus.ErrorLevel, slog.LevelError},
+		{logrus.WarnLevel, slog.LevelWarn},
+		{logrus.InfoLevel, slog.LevelInfo},
+		{logrus.DebugLevel, slog.LevelDebug},
+		{logrus.TraceLevel, slog.LevelDebug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, SlogLevel(tt.level))
+		})
+	}
+}
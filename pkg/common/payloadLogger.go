@@ -0,0 +1,178 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultLogPayloadMaxBytes = 2048
+
+	// Fields holding the payload in "request received" and "response sent" events
+	requestContentField  = "grpc.request.content"
+	responseContentField = "grpc.response.content"
+
+	redactedValue = "[REDACTED]"
+)
+
+// PayloadLogConfig controls logging of gRPC request and response payloads.
+type PayloadLogConfig struct {
+	// Enabled logs payloads. Start and finish events are always logged.
+	Enabled bool
+	// MaxBytes truncates each logged payload. Zero logs payloads in full.
+	MaxBytes int
+}
+
+// NewPayloadLogConfigFromEnv reads the payload logging configuration from:
+//   - LOG_PAYLOADS: "true" logs request and response payloads (default off)
+//   - LOG_PAYLOAD_MAX_BYTES: bytes kept of each logged payload (default 2048, "0" keeps all)
+//
+// Invalid values fall back to the defaults.
+func NewPayloadLogConfigFromEnv() PayloadLogConfig {
+	enabled, err := strconv.ParseBool(GetEnv("LOG_PAYLOADS", "false"))
+	if err != nil {
+		enabled = false
+	}
+
+	return PayloadLogConfig{
+		Enabled:  enabled,
+		MaxBytes: max(GetEnvInt("LOG_PAYLOAD_MAX_BYTES", defaultLogPayloadMaxBytes), 0),
+	}
+}
+
+// PayloadLogger wraps the gRPC interceptor logger so that logged payloads are
+// bounded and credentials never reach the logs.
+//
+// Payloads are rendered as JSON and cut to MaxBytes; a full challenges response
+// is hundreds of KB and holds the user's progress. Fields named authorization,
+// "Bearer" values and the authorization key of gRPC metadata are redacted.
+type PayloadLogger struct {
+	config PayloadLogConfig
+	next   logging.Logger
+
+	payloads     *prometheus.CounterVec
+	payloadBytes prometheus.Counter
+}
+
+// NewPayloadLogger creates a payload logger that writes to next.
+func NewPayloadLogger(next logging.Logger, config PayloadLogConfig) *PayloadLogger {
+	return &PayloadLogger{
+		config: config,
+		next:   next,
+		payloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logged_payloads_total",
+			Help: "gRPC payloads written to the log, by whether they were truncated.",
+		}, []string{"truncated"}),
+		payloadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "logged_payload_bytes_total",
+			Help: "Bytes of gRPC payloads written to the log, after truncation.",
+		}),
+	}
+}
+
+// LoggableEvents returns the events for logging.WithLogOnEvents: start and
+// finish always, payloads only when enabled.
+func (p *PayloadLogger) LoggableEvents() []logging.LoggableEvent {
+	events := []logging.LoggableEvent{logging.StartCall, logging.FinishCall}
+	if p.config.Enabled {
+		events = append(events, logging.PayloadReceived, logging.PayloadSent)
+	}
+	return events
+}
+
+// Collectors returns the payload logging metrics for registration.
+func (p *PayloadLogger) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{p.payloads, p.payloadBytes}
+}
+
+// Log implements logging.Logger.
+func (p *PayloadLogger) Log(ctx context.Context, lvl logging.Level, msg string, fields ...any) {
+	bounded := make([]any, 0, len(fields))
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		value := fields[i+1]
+
+		switch {
+		case key == requestContentField || key == responseContentField:
+			if !p.config.Enabled {
+				continue
+			}
+			value = p.boundPayload(value)
+		case strings.EqualFold(key, "authorization"):
+			value = redactedValue
+		default:
+			value = redact(value)
+		}
+
+		bounded = append(bounded, fields[i], value)
+	}
+
+	p.next.Log(ctx, lvl, msg, bounded...)
+}
+
+// boundPayload renders a payload as compact JSON, truncated to MaxBytes on a
+// UTF-8 boundary. protojson output is compacted because its whitespace is
+// deliberately unstable.
+func (p *PayloadLogger) boundPayload(payload any) string {
+	var rendered string
+	if message, ok := payload.(proto.Message); ok {
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			rendered = "<unmarshalable payload: " + err.Error() + ">"
+		} else {
+			var compact bytes.Buffer
+			if json.Compact(&compact, data) == nil {
+				data = compact.Bytes()
+			}
+			rendered = string(data)
+		}
+	} else {
+		rendered = "<non-proto payload>"
+	}
+
+	size := len(rendered)
+	if p.config.MaxBytes == 0 || size <= p.config.MaxBytes {
+		p.payloads.WithLabelValues("false").Inc()
+		p.payloadBytes.Add(float64(size))
+		return rendered
+	}
+
+	cut := p.config.MaxBytes
+	for cut > 0 && !utf8.RuneStart(rendered[cut]) {
+		cut--
+	}
+	p.payloads.WithLabelValues("true").Inc()
+	p.payloadBytes.Add(float64(cut))
+	return rendered[:cut] + "...(truncated, " + strconv.Itoa(size) + " bytes)"
+}
+
+// redact hides bearer tokens and the authorization entry of gRPC metadata.
+func redact(value any) any {
+	switch v := value.(type) {
+	case string:
+		if len(v) > len("Bearer ") && strings.EqualFold(v[:len("Bearer ")], "Bearer ") {
+			return redactedValue
+		}
+	case metadata.MD:
+		if _, ok := v["authorization"]; ok {
+			md := v.Copy()
+			md.Set("authorization", redactedValue)
+			return md
+		}
+	}
+	return value
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// logEntry is one call to a capturing logging.Logger.
+type logEntry struct {
+	msg    string
+	fields map[string]any
+}
+
+func capturingLogger(entries *[]logEntry) logging.Logger {
+	return logging.LoggerFunc(func(_ context.Context, _ logging.Level, msg string, fields ...any) {
+		f := make(map[string]any)
+		i := logging.Fields(fields).Iterator()
+		for i.Next() {
+			k, v := i.At()
+			f[k] = v
+		}
+		*entries = append(*entries, logEntry{msg: msg, fields: f})
+	})
+}
+
+// logUnaryCall runs one GetUserChallenges call through the logging interceptor.
+func logUnaryCall(t *testing.T, logger *PayloadLogger, resp *pb.GetChallengesResponse) {
+	t.Helper()
+
+	interceptor := logging.UnaryServerInterceptor(logger, logging.WithLogOnEvents(logger.LoggableEvents()...))
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Service_GetUserChallenges_FullMethodName}
+	_, err := interceptor(context.Background(), &pb.GetChallengesRequest{ActiveOnly: true}, info,
+		func(context.Context, any) (any, error) { return resp, nil })
+	require.NoError(t, err)
+}
+
+func largeChallengesResponse() *pb.GetChallengesResponse {
+	resp := &pb.GetChallengesResponse{}
+	for i := 0; i < 100; i++ {
+		resp.Challenges = append(resp.Challenges, &pb.Challenge{
+			ChallengeId: "challenge",
+			Name:        strings.Repeat("n", 50),
+			Goals:       []*pb.Goal{{GoalId: "goal", Progress: 7, Status: "in_progress"}},
+		})
+	}
+	return resp
+}
+
+func TestNewPayloadLogConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_PAYLOADS", "true")
+	t.Setenv("LOG_PAYLOAD_MAX_BYTES", "512")
+
+	assert.Equal(t, PayloadLogConfig{Enabled: true, MaxBytes: 512}, NewPayloadLogConfigFromEnv())
+}
+
+func TestNewPayloadLogConfigFromEnv_Defaults(t *testing.T) {
+	t.Setenv("LOG_PAYLOADS", "yes please")
+	t.Setenv("LOG_PAYLOAD_MAX_BYTES", "-1")
+
+	assert.Equal(t, PayloadLogConfig{Enabled: false, MaxBytes: 0}, NewPayloadLogConfigFromEnv())
+}
+
+func TestPayloadLogger_DisabledLogsOnlyStartAndFinish(t *testing.T) {
+	var entries []logEntry
+	logger := NewPayloadLogger(capturingLogger(&entries), PayloadLogConfig{MaxBytes: 100})
+
+	logUnaryCall(t, logger, largeChallengesResponse())
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "started call", entries[0].msg)
+	assert.Equal(t, "finished call", entries[1].msg)
+	assert.Equal(t, float64(0), testutil.ToFloat64(logger.payloadBytes))
+}
+
+func TestPayloadLogger_TruncatesPayloads(t *testing.T) {
+	var entries []logEntry
+	logger := NewPayloadLogger(capturingLogger(&entries), PayloadLogConfig{Enabled: true, MaxBytes: 100})
+
+	logUnaryCall(t, logger, largeChallengesResponse())
+
+	var request, response string
+	for _, entry := range entries {
+		switch entry.msg {
+		case "request received":
+			request = entry.fields[requestContentField].(string)
+		case "response sent":
+			response = entry.fields[responseContentField].(string)
+		}
+	}
+
+	assert.Equal(t, `{"activeOnly":true}`, request, "small payloads are logged in full")
+	require.True(t, strings.HasPrefix(response, `{"challenges":[{"challengeId":"challenge"`), response)
+	assert.Contains(t, response, "...(truncated, ")
+	assert.LessOrEqual(t, len(response), 100+len("...(truncated, 999999 bytes)"))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(logger.payloads.WithLabelValues("true")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(logger.payloads.WithLabelValues("false")))
+	assert.Equal(t, float64(len(`{"activeOnly":true}`)+100), testutil.ToFloat64(logger.payloadBytes))
+}
+
+func TestPayloadLogger_TruncatesOnRuneBoundary(t *testing.T) {
+	logger := NewPayloadLogger(capturingLogger(new([]logEntry)), PayloadLogConfig{Enabled: true, MaxBytes: 12})
+
+	// {"name":"é… — the 12th byte falls inside the second "é"
+	got := logger.boundPayload(&pb.Challenge{Name: "ééé"})
+
+	assert.Equal(t, `{"name":"é...(truncated, 17 bytes)`, got)
+}
+
+func TestPayloadLogger_RedactsCredentials(t *testing.T) {
+	var entries []logEntry
+	logger := NewPayloadLogger(capturingLogger(&entries), PayloadLogConfig{})
+
+	md := metadata.Pairs("authorization", "Bearer secret-token", "x-request-id", "abc")
+	logger.Log(context.Background(), logging.LevelInfo, "call",
+		"Authorization", "anything",
+		"grpc.metadata", md,
+		"token", "bearer secret-token",
+		"grpc.method", "GetChallenges",
+	)
+
+	require.Len(t, entries, 1)
+	fields := entries[0].fields
+	assert.Equal(t, redactedValue, fields["Authorization"])
+	assert.Equal(t, []string{redactedValue}, fields["grpc.metadata"].(metadata.MD).Get("authorization"))
+	assert.Equal(t, []string{"abc"}, fields["grpc.metadata"].(metadata.MD).Get("x-request-id"))
+	assert.Equal(t, []string{"Bearer secret-token"}, md.Get("authorization"), "caller's metadata must not be modified")
+	assert.Equal(t, redactedValue, fields["token"])
+	assert.Equal(t, "GetChallenges", fields["grpc.method"])
+}
+
+func TestInterceptorLogger_LogsAllFields(t *testing.T) {
+	logrusLogger, hook := logrusTest.NewNullLogger()
+	logger := InterceptorLogger(logrusLogger)
+
+	logger.Log(context.Background(), logging.LevelInfo, "first", "a", 1, "b", 2)
+	logger.Log(context.Background(), logging.LevelWarn, "second", "c", 3)
+
+	require.Len(t, hook.AllEntries(), 2)
+	assert.Equal(t, logrus.Fields{"a": 1, "b": 2}, hook.AllEntries()[0].Data)
+	assert.Equal(t, logrus.Fields{"c": 3}, hook.LastEntry().Data, "fields must not carry over between calls")
+}
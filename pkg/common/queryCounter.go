@@ -0,0 +1,94 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// QueryCounter counts the repository calls made for one request, by method. The
+// instrumented repositories count into the counter of the call's context
+// (see WithQueryCounter), so the debug timing header can report them and tests
+// can catch flows that query once per goal.
+//
+// It is safe for concurrent use. The methods of a nil *QueryCounter do nothing
+// and count zero, so requests without a counter pay nothing.
+type QueryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewQueryCounter creates an empty QueryCounter.
+func NewQueryCounter() *QueryCounter {
+	return &QueryCounter{counts: make(map[string]int)}
+}
+
+type queryCounterContextKey struct{}
+
+// WithQueryCounter returns a context whose repository calls are counted in
+// counter. A nil counter returns ctx unchanged.
+func WithQueryCounter(ctx context.Context, counter *QueryCounter) context.Context {
+	if counter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryCounterContextKey{}, counter)
+}
+
+// QueryCounterFromContext returns the query counter of the request, nil when
+// its calls are not counted.
+func QueryCounterFromContext(ctx context.Context) *QueryCounter {
+	counter, _ := ctx.Value(queryCounterContextKey{}).(*QueryCounter)
+	return counter
+}
+
+// Count counts one call of method.
+func (c *QueryCounter) Count(method string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.counts[method]++
+	c.mu.Unlock()
+}
+
+// Total returns the number of calls counted.
+func (c *QueryCounter) Total() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, count := range c.counts {
+		total += count
+	}
+	return total
+}
+
+// Counts returns the calls counted by method.
+func (c *QueryCounter) Counts() map[string]int {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counts)
+}
+
+// String lists the calls counted by method, sorted, e.g.
+// "GetGoalsByIDs=1, GetProgress=3".
+func (c *QueryCounter) String() string {
+	counts := c.Counts()
+	parts := make([]string, 0, len(counts))
+	for _, method := range slices.Sorted(maps.Keys(counts)) {
+		parts = append(parts, method+"="+strconv.Itoa(counts[method]))
+	}
+	return strings.Join(parts, ", ")
+}
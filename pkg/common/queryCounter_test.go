@@ -0,0 +1,42 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryCounter(t *testing.T) {
+	counter := NewQueryCounter()
+	ctx := WithQueryCounter(context.Background(), counter)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() { QueryCounterFromContext(ctx).Count("GetProgress") })
+	}
+	wg.Wait()
+	QueryCounterFromContext(ctx).Count("GetGoalsByIDs")
+
+	assert.Equal(t, 11, counter.Total())
+	assert.Equal(t, map[string]int{"GetProgress": 10, "GetGoalsByIDs": 1}, counter.Counts())
+	assert.Equal(t, "GetGoalsByIDs=1, GetProgress=10", counter.String())
+}
+
+func TestQueryCounter_Nil(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithQueryCounter(ctx, nil))
+
+	counter := QueryCounterFromContext(ctx)
+	counter.Count("GetProgress")
+
+	assert.Nil(t, counter)
+	assert.Equal(t, 0, counter.Total())
+	assert.Empty(t, counter.Counts())
+	assert.Empty(t, counter.String())
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Request outcome classes (the class label of requests_total).
+const (
+	// RequestOutcomeSuccess is a request that succeeded.
+	RequestOutcomeSuccess = "success"
+	// RequestOutcomeUserError is a request refused because of the caller or the
+	// player's state (NotFound, FailedPrecondition, AlreadyExists, ...). These
+	// are normal and do not burn the error budget.
+	RequestOutcomeUserError = "user_error"
+	// RequestOutcomeServerError is a request the service failed to serve
+	// (Internal, Unavailable, DeadlineExceeded, ...).
+	RequestOutcomeServerError = "server_error"
+)
+
+// RequestMetrics counts requests per method by outcome class, so error-rate
+// SLOs can exclude user errors:
+//
+//	sum(rate(requests_total{class="server_error"}[5m])) by (method)
+//	  / sum(rate(requests_total[5m])) by (method)
+//
+// gRPC calls are counted by the interceptors, including the gateway calls
+// proxied to the gRPC server. The optimized HTTP handlers, which do not go
+// through gRPC, are counted by HTTPHandler under the name of the RPC they serve.
+//
+// Their latency is observed in request_duration_seconds. Requests of a sampled
+// trace attach its ID as a trace_id exemplar, so a slow bucket links to a trace
+// of one of its requests. Exemplars are only exposed in the OpenMetrics format
+// (promhttp.HandlerOpts.EnableOpenMetrics).
+type RequestMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// traceIDExemplarLabel is the exemplar label holding the trace ID, the name
+// Grafana looks for by default.
+const traceIDExemplarLabel = "trace_id"
+
+// NewRequestMetrics creates the request metrics.
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "requests_total",
+			Help: "Requests served, by gRPC method and outcome class (success, user_error, server_error).",
+		}, []string{"method", "class"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "request_duration_seconds",
+			Help:    "Request latency in seconds, by gRPC method, with trace_id exemplars of sampled traces.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+}
+
+// Collectors returns the request metrics for registration.
+func (m *RequestMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.duration}
+}
+
+// UnaryServerInterceptor counts unary calls by the status code they return.
+// Install it before the load shedder so shed calls count as server errors.
+func (m *RequestMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(ctx, info.FullMethod, RequestOutcomeOf(status.Code(err)), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts streaming calls like UnaryServerInterceptor.
+func (m *RequestMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(ss.Context(), info.FullMethod, RequestOutcomeOf(status.Code(err)), time.Since(start))
+		return err
+	}
+}
+
+// HTTPHandler counts the requests served by next under method, the full name
+// of the RPC it implements (e.g. "/service.Service/GetUserChallenges"), by the
+// HTTP status it writes. These requests have no server span, so the exemplar
+// is the trace propagated by the caller's headers, if sampled.
+func (m *RequestMetrics) HTTPHandler(method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		m.observe(ctx, method, RequestOutcomeOf(codeFromHTTPStatus(recorder.status)), time.Since(start))
+	})
+}
+
+func (m *RequestMetrics) observe(ctx context.Context, method, class string, elapsed time.Duration) {
+	m.requests.WithLabelValues(method, class).Inc()
+
+	observer := m.duration.WithLabelValues(method)
+	traceID, sampled := SampledTraceID(ctx)
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !sampled || !ok {
+		observer.Observe(elapsed.Seconds())
+		return
+	}
+	exemplarObserver.ObserveWithExemplar(elapsed.Seconds(), prometheus.Labels{traceIDExemplarLabel: traceID})
+}
+
+// RequestOutcomeOf classifies a gRPC status code. Codes a caller can cause with
+// a valid request against the player's current state, or with an invalid or
+// unauthenticated request, are user errors; the rest are server errors.
+// DeadlineExceeded counts as a server error since deadlines are set by the
+// service (see the deadline interceptor); Canceled is the caller going away.
+func RequestOutcomeOf(code codes.Code) string {
+	switch code {
+	case codes.OK:
+		return RequestOutcomeSuccess
+	case codes.NotFound,
+		codes.FailedPrecondition,
+		codes.AlreadyExists,
+		codes.InvalidArgument,
+		codes.OutOfRange,
+		codes.Unauthenticated,
+		codes.PermissionDenied,
+		codes.ResourceExhausted,
+		codes.Canceled:
+		return RequestOutcomeUserError
+	default:
+		return RequestOutcomeServerError
+	}
+}
+
+// codeFromHTTPStatus is the gRPC code of an HTTP status, the inverse of the
+// gateway's mapping. Other 4xx statuses are treated as InvalidArgument and
+// other 5xx statuses as Internal.
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // Client closed request
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case httpStatus < http.StatusBadRequest:
+		return codes.OK
+	case httpStatus < http.StatusInternalServerError:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// statusRecorder remembers the status written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
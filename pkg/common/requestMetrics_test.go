@@ -0,0 +1,234 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"extend-challenge-service/pkg/mapper"
+)
+
+func TestRequestOutcomeOf_MapperErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, RequestOutcomeSuccess},
+		{"GoalNotFoundError", &mapper.GoalNotFoundError{GoalID: "g"}, RequestOutcomeUserError},
+		{"GoalNotCompletedError", &mapper.GoalNotCompletedError{GoalID: "g"}, RequestOutcomeUserError},
+		{"GoalAlreadyClaimedError", &mapper.GoalAlreadyClaimedError{GoalID: "g"}, RequestOutcomeUserError},
+		{"GoalNotActiveError", &mapper.GoalNotActiveError{GoalID: "g"}, RequestOutcomeUserError},
+		{"GoalRotatedError", &mapper.GoalRotatedError{GoalID: "g"}, RequestOutcomeUserError},
+		{"GoalOverrideRefusedError", &mapper.GoalOverrideRefusedError{GoalID: "g", Reason: "inactive"}, RequestOutcomeUserError},
+		{"ChallengeMismatchError", &mapper.ChallengeMismatchError{GoalID: "g"}, RequestOutcomeUserError},
+		{"StaleClaimError", &mapper.StaleClaimError{GoalID: "g"}, RequestOutcomeUserError},
+		{"PrerequisitesNotMetError", &mapper.PrerequisitesNotMetError{GoalID: "g"}, RequestOutcomeUserError},
+		{"ChallengeLockedError", &mapper.ChallengeLockedError{ChallengeID: "c"}, RequestOutcomeUserError},
+		{"ClaimCapExceededError", &mapper.ClaimCapExceededError{Limit: 1, Window: time.Hour}, RequestOutcomeUserError},
+		{"ClaimFrozenError", &mapper.ClaimFrozenError{FrozenUntil: time.Now()}, RequestOutcomeUserError},
+		{"RewardGrantError", &mapper.RewardGrantError{GoalID: "g", Err: errors.New("boom")}, RequestOutcomeServerError},
+		{"ErrGoalNotFound", mapper.ErrGoalNotFound, RequestOutcomeUserError},
+		{"ErrGoalNotCompleted", mapper.ErrGoalNotCompleted, RequestOutcomeUserError},
+		{"ErrGoalAlreadyClaimed", mapper.ErrGoalAlreadyClaimed, RequestOutcomeUserError},
+		{"ErrGoalNotActive", mapper.ErrGoalNotActive, RequestOutcomeUserError},
+		{"ErrPrerequisitesNotMet", mapper.ErrPrerequisitesNotMet, RequestOutcomeUserError},
+		{"ErrRewardGrantFailed", mapper.ErrRewardGrantFailed, RequestOutcomeServerError},
+		{"ErrDatabaseError", mapper.ErrDatabaseError, RequestOutcomeServerError},
+		{"ErrInvalidProgressMode", mapper.ErrInvalidProgressMode, RequestOutcomeUserError},
+		{"ErrInvalidRewardType", mapper.ErrInvalidRewardType, RequestOutcomeUserError},
+		{"ErrChallengeNotFound", mapper.ErrChallengeNotFound, RequestOutcomeUserError},
+		{"ErrGoalRotated", mapper.ErrGoalRotated, RequestOutcomeUserError},
+		{"deadline exceeded", fmt.Errorf("claim: %w", context.DeadlineExceeded), RequestOutcomeServerError},
+		{"cancelled", fmt.Errorf("claim: %w", context.Canceled), RequestOutcomeUserError},
+		{"unknown error", errors.New("unexpected"), RequestOutcomeServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := status.Code(mapper.MapErrorToGRPCStatus(tt.err))
+			assert.Equal(t, tt.want, RequestOutcomeOf(code), "code %s", code)
+		})
+	}
+}
+
+func TestRequestOutcomeOf_ServerCodes(t *testing.T) {
+	for _, code := range []codes.Code{
+		codes.Internal, codes.Unavailable, codes.Unknown, codes.DataLoss,
+		codes.Unimplemented, codes.Aborted, codes.DeadlineExceeded,
+	} {
+		assert.Equal(t, RequestOutcomeServerError, RequestOutcomeOf(code), "code %s", code)
+	}
+}
+
+func TestRequestMetrics_UnaryServerInterceptor(t *testing.T) {
+	metrics := NewRequestMetrics()
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/service.Service/ClaimGoalReward"}
+
+	for _, err := range []error{
+		nil,
+		status.Error(codes.FailedPrecondition, "not completed"),
+		status.Error(codes.AlreadyExists, "claimed"),
+		status.Error(codes.Internal, "db"),
+		status.Error(codes.Unavailable, "overloaded"),
+	} {
+		_, got := interceptor(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, err
+		})
+		assert.Equal(t, err, got, "the error is passed through")
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(info.FullMethod, RequestOutcomeSuccess)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(info.FullMethod, RequestOutcomeUserError)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(info.FullMethod, RequestOutcomeServerError)))
+}
+
+func TestRequestMetrics_StreamServerInterceptor(t *testing.T) {
+	metrics := NewRequestMetrics()
+	interceptor := metrics.StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/service.Service/Watch"}
+
+	err := interceptor(nil, noMetadataStream{}, info, func(interface{}, grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "overloaded")
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(info.FullMethod, RequestOutcomeServerError)))
+}
+
+func TestRequestMetrics_HTTPHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"implicit 200", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("{}")) }, RequestOutcomeSuccess},
+		{"401", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "Unauthorized", http.StatusUnauthorized) }, RequestOutcomeUserError},
+		{"404", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "not found", http.StatusNotFound) }, RequestOutcomeUserError},
+		{"405", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}, RequestOutcomeUserError},
+		{"500", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}, RequestOutcomeServerError},
+		{"503", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) }, RequestOutcomeServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewRequestMetrics()
+			method := "/service.Service/GetUserChallenges"
+
+			metrics.HTTPHandler(method, tt.handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/challenges", nil))
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(method, tt.want)))
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.requests))
+		})
+	}
+}
+
+// scrapeOpenMetrics scrapes the metrics in the OpenMetrics format, the only one
+// that carries exemplars.
+func scrapeOpenMetrics(t *testing.T, metrics *RequestMetrics) string {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.Collectors()...)
+	server := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Contains(t, resp.Header.Get("Content-Type"), "application/openmetrics-text")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func sampledSpanContext(t *testing.T, flags trace.TraceFlags) trace.SpanContext {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: flags, Remote: true})
+}
+
+func TestRequestMetrics_UnaryExemplars(t *testing.T) {
+	metrics := NewRequestMetrics()
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/service.Service/ClaimGoalReward"}
+	handler := func(context.Context, interface{}) (interface{}, error) { return nil, nil }
+
+	sampled := trace.ContextWithSpanContext(context.Background(), sampledSpanContext(t, trace.FlagsSampled))
+	_, err := interceptor(sampled, nil, info, handler)
+	require.NoError(t, err)
+
+	body := scrapeOpenMetrics(t, metrics)
+	assert.Contains(t, body, `request_duration_seconds_bucket{method="/service.Service/ClaimGoalReward",le="0.005"} 1 # {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"}`)
+	assert.Contains(t, body, `request_duration_seconds_count{method="/service.Service/ClaimGoalReward"} 1`)
+}
+
+func TestRequestMetrics_NoExemplarWithoutSampledTrace(t *testing.T) {
+	metrics := NewRequestMetrics()
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/service.Service/ClaimGoalReward"}
+	handler := func(context.Context, interface{}) (interface{}, error) { return nil, nil }
+
+	notSampled := trace.ContextWithSpanContext(context.Background(), sampledSpanContext(t, 0))
+	for _, ctx := range []context.Context{context.Background(), notSampled} {
+		_, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+	}
+
+	body := scrapeOpenMetrics(t, metrics)
+	assert.Contains(t, body, `request_duration_seconds_count{method="/service.Service/ClaimGoalReward"} 2`)
+	assert.NotContains(t, body, "# {trace_id")
+}
+
+func TestRequestMetrics_HTTPHandlerExemplars(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	metrics := NewRequestMetrics()
+	method := "/service.Service/GetUserChallenges"
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	metrics.HTTPHandler(method, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("{}"))
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	body := scrapeOpenMetrics(t, metrics)
+	assert.Contains(t, body, `# {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"}`)
+	// Observed in a bucket above the sleep, not the first one
+	assert.Contains(t, body, `request_duration_seconds_bucket{method="/service.Service/GetUserChallenges",le="0.005"} 0`+"\n")
+}
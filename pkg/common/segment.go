@@ -0,0 +1,168 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ContextKeySegment is the context key for the player segment resolved by
+// SegmentHTTPMiddleware and SegmentUnaryServerInterceptor.
+const ContextKeySegment contextKey = "segment"
+
+// gatewayMetadataPrefix is added by grpc-gateway to the metadata keys of
+// forwarded HTTP headers that are not permanent headers.
+const gatewayMetadataPrefix = "grpcgateway-"
+
+// SegmentRequest is what a SegmentResolver resolves a player's segment from.
+type SegmentRequest struct {
+	// Claims is the JWT payload, nil when the request has no bearer token.
+	// Tokens are validated by the auth interceptor or the optimized handlers;
+	// a request with an invalid token is rejected whatever its segment.
+	Claims map[string]any
+	// Header returns the first value of a request header, "" when absent.
+	Header func(name string) string
+}
+
+// SegmentResolver resolves the player segment that selects per-segment goal
+// targets (see service.TargetOverrides). "" means the player gets the configured targets.
+type SegmentResolver interface {
+	ResolveSegment(ctx context.Context, req SegmentRequest) string
+}
+
+// ClaimSegmentResolver reads the segment from a string claim of the JWT.
+type ClaimSegmentResolver struct {
+	Claim string
+}
+
+// ResolveSegment implements SegmentResolver.
+func (r ClaimSegmentResolver) ResolveSegment(_ context.Context, req SegmentRequest) string {
+	segment, _ := req.Claims[r.Claim].(string)
+	return segment
+}
+
+// HeaderSegmentResolver reads the segment from a request header.
+//
+// Clients can set any header they like, so this is only safe behind a gateway
+// that sets the header itself and drops the client's copy.
+type HeaderSegmentResolver struct {
+	Header string
+}
+
+// ResolveSegment implements SegmentResolver.
+func (r HeaderSegmentResolver) ResolveSegment(_ context.Context, req SegmentRequest) string {
+	if req.Header == nil {
+		return ""
+	}
+	return req.Header(r.Header)
+}
+
+// NewSegmentResolverFromEnv returns the resolver configured by SEGMENT_JWT_CLAIM:
+// the JWT claim holding the player's segment, or SEGMENT_HEADER: the request
+// header holding it, set by a trusted gateway. The claim wins when both are set.
+// It returns nil, resolving no segment, when neither is set.
+func NewSegmentResolverFromEnv() SegmentResolver {
+	if claim := strings.TrimSpace(GetEnv("SEGMENT_JWT_CLAIM", "")); claim != "" {
+		return ClaimSegmentResolver{Claim: claim}
+	}
+	if header := strings.TrimSpace(GetEnv("SEGMENT_HEADER", "")); header != "" {
+		return HeaderSegmentResolver{Header: header}
+	}
+	return nil
+}
+
+// SegmentHTTPMiddleware stores the segment resolved by resolver in the request
+// context for the optimized handlers. A nil resolver passes requests through.
+func SegmentHTTPMiddleware(resolver SegmentResolver, next http.Handler) http.Handler {
+	if resolver == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segment := resolver.ResolveSegment(r.Context(), SegmentRequest{
+			Claims: bearerTokenClaims(r.Header.Get("Authorization")),
+			Header: r.Header.Get,
+		})
+		if segment != "" {
+			r = r.WithContext(context.WithValue(r.Context(), ContextKeySegment, segment))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SegmentUnaryServerInterceptor stores the segment resolved by resolver in the
+// call context. It must run after the auth interceptor. A nil resolver resolves
+// no segment.
+func SegmentUnaryServerInterceptor(resolver SegmentResolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if resolver == nil {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		var authorization string
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+
+		segment := resolver.ResolveSegment(ctx, SegmentRequest{
+			Claims: bearerTokenClaims(authorization),
+			Header: func(name string) string {
+				// Headers forwarded by grpc-gateway carry its prefix
+				for _, key := range []string{name, gatewayMetadataPrefix + name} {
+					if values := md.Get(key); len(values) > 0 {
+						return values[0]
+					}
+				}
+				return ""
+			},
+		})
+		if segment != "" {
+			ctx = context.WithValue(ctx, ContextKeySegment, segment)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GetSegmentFromContext returns the segment stored by the HTTP middleware or the
+// gRPC interceptor, or "" when the player has none.
+func GetSegmentFromContext(ctx context.Context) string {
+	segment, _ := ctx.Value(ContextKeySegment).(string)
+	return segment
+}
+
+// bearerTokenClaims decodes the payload of a bearer token without validating it,
+// returning nil when there is no decodable token.
+func bearerTokenClaims(authorization string) map[string]any {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return nil
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		if payload, err = base64.URLEncoding.DecodeString(parts[1]); err != nil {
+			return nil
+		}
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// segmentTestToken returns an unsigned bearer token with the given JWT payload.
+func segmentTestToken(payload string) string {
+	return "Bearer e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestClaimSegmentResolver(t *testing.T) {
+	resolver := ClaimSegmentResolver{Claim: "segment"}
+
+	assert.Equal(t, "new_player", resolver.ResolveSegment(context.Background(), SegmentRequest{
+		Claims: bearerTokenClaims(segmentTestToken(`{"sub":"user","segment":"new_player"}`)),
+	}))
+	assert.Empty(t, resolver.ResolveSegment(context.Background(), SegmentRequest{
+		Claims: bearerTokenClaims(segmentTestToken(`{"sub":"user","segment":3}`)),
+	}))
+	assert.Empty(t, resolver.ResolveSegment(context.Background(), SegmentRequest{}))
+}
+
+func TestBearerTokenClaims_Malformed(t *testing.T) {
+	for _, authorization := range []string{"", "Basic abc", "Bearer abc", "Bearer a.!!!.c", segmentTestToken("not json")} {
+		assert.Nil(t, bearerTokenClaims(authorization), authorization)
+	}
+}
+
+func TestSegmentHTTPMiddleware(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = GetSegmentFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("X-Player-Segment", "veteran")
+	SegmentHTTPMiddleware(HeaderSegmentResolver{Header: "X-Player-Segment"}, next).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "veteran", got)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("Authorization", segmentTestToken(`{"segment":"new_player"}`))
+	SegmentHTTPMiddleware(ClaimSegmentResolver{Claim: "segment"}, next).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "new_player", got)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("X-Player-Segment", "veteran")
+	SegmentHTTPMiddleware(nil, next).ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, got)
+}
+
+func TestSegmentUnaryServerInterceptor(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got = GetSegmentFromContext(ctx)
+		return nil, nil
+	}
+	call := func(resolver SegmentResolver, md metadata.MD) string {
+		got = ""
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := SegmentUnaryServerInterceptor(resolver)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		require.NoError(t, err)
+		return got
+	}
+
+	headerResolver := HeaderSegmentResolver{Header: "x-player-segment"}
+	assert.Equal(t, "veteran", call(headerResolver, metadata.Pairs("x-player-segment", "veteran")))
+	assert.Equal(t, "veteran", call(headerResolver, metadata.Pairs("grpcgateway-x-player-segment", "veteran")), "headers forwarded by grpc-gateway")
+	assert.Equal(t, "new_player", call(ClaimSegmentResolver{Claim: "segment"},
+		metadata.Pairs("authorization", segmentTestToken(`{"segment":"new_player"}`))))
+	assert.Empty(t, call(nil, metadata.Pairs("x-player-segment", "veteran")))
+}
+
+func TestNewSegmentResolverFromEnv(t *testing.T) {
+	t.Setenv("SEGMENT_JWT_CLAIM", "")
+	t.Setenv("SEGMENT_HEADER", "")
+	assert.Nil(t, NewSegmentResolverFromEnv())
+
+	t.Setenv("SEGMENT_HEADER", "X-Player-Segment")
+	assert.Equal(t, HeaderSegmentResolver{Header: "X-Player-Segment"}, NewSegmentResolverFromEnv())
+
+	t.Setenv("SEGMENT_JWT_CLAIM", "segment")
+	assert.Equal(t, ClaimSegmentResolver{Claim: "segment"}, NewSegmentResolverFromEnv())
+}
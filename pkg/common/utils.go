@@ -30,6 +30,9 @@ func GetEnvInt(key string, fallback int) int {
 	return val
 }
 
+// GetBasePath returns BASE_PATH, the prefix of every HTTP route, without a
+// trailing slash. BASE_PATH=/ serves the routes at the root, so the returned
+// base path is empty.
 func GetBasePath() string {
 	basePath := os.Getenv("BASE_PATH")
 	if basePath == "" {
@@ -39,5 +42,5 @@ func GetBasePath() string {
 		logrus.Fatalf("BASE_PATH envar is invalid, no leading '/' found. Valid example: /basePath")
 	}
 
-	return basePath
+	return strings.TrimRight(basePath, "/")
 }
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package version holds build information injected at link time:
+//
+//	go build -ldflags "-X extend-challenge-service/pkg/common/version.Version=1.2.3 \
+//	  -X extend-challenge-service/pkg/common/version.GitSHA=$(git rev-parse --short HEAD) \
+//	  -X extend-challenge-service/pkg/common/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// Version is the service release version.
+	Version = "dev"
+	// GitSHA is the commit the binary was built from.
+	GitSHA = "unknown"
+	// BuildTime is the UTC build timestamp (RFC 3339).
+	BuildTime = "unknown"
+)
+
+// Info is the build information served by GET /version.
+type Info struct {
+	Version   string `json:"version"`
+	GitSHA    string `json:"gitSha"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitSHA:    GitSHA,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String formats the build information for log lines, e.g. "1.2.3 (abc1234, built 2025-01-01T00:00:00Z)".
+func String() string {
+	return fmt.Sprintf("%s (%s, built %s)", Version, GitSHA, BuildTime)
+}
+
+// NewBuildInfoCollector returns a build_info gauge, always 1, labelled with the
+// version, git SHA, build time and Go version.
+func NewBuildInfoCollector() prometheus.Collector {
+	info := Get()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the running service; the value is always 1.",
+	}, []string{"version", "git_sha", "build_time", "go_version"})
+	gauge.WithLabelValues(info.Version, info.GitSHA, info.BuildTime, info.GoVersion).Set(1)
+
+	return gauge
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package version
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setBuildInfo(t *testing.T, version, sha, buildTime string) {
+	t.Helper()
+	prevVersion, prevSHA, prevBuildTime := Version, GitSHA, BuildTime
+	Version, GitSHA, BuildTime = version, sha, buildTime
+	t.Cleanup(func() {
+		Version, GitSHA, BuildTime = prevVersion, prevSHA, prevBuildTime
+	})
+}
+
+func TestGet(t *testing.T) {
+	setBuildInfo(t, "1.2.3", "abc1234", "2025-01-01T00:00:00Z")
+
+	info := Get()
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, "abc1234", info.GitSHA)
+	assert.Equal(t, "2025-01-01T00:00:00Z", info.BuildTime)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, "1.2.3 (abc1234, built 2025-01-01T00:00:00Z)", String())
+}
+
+func TestNewBuildInfoCollector(t *testing.T) {
+	setBuildInfo(t, "1.2.3", "abc1234", "2025-01-01T00:00:00Z")
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewBuildInfoCollector()))
+
+	expected := `
+# HELP build_info Build information of the running service; the value is always 1.
+# TYPE build_info gauge
+build_info{build_time="2025-01-01T00:00:00Z",git_sha="abc1234",go_version="` + runtime.Version() + `",version="1.2.3"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "build_info"))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBackgroundWorkers     = 8
+	defaultBackgroundQueueSize   = 1024
+	defaultBackgroundTaskTimeout = "10s"
+)
+
+// TaskPriority decides what Submit does with a task when the pool is saturated.
+type TaskPriority string
+
+const (
+	// TaskBestEffort tasks are dropped when the queue is full (audit extras,
+	// bookkeeping that a later request repairs).
+	TaskBestEffort TaskPriority = "best_effort"
+	// TaskCritical tasks run inline in the caller when the queue is full, so
+	// they are slower under load but never lost.
+	TaskCritical TaskPriority = "critical"
+)
+
+// WorkerPoolConfig sizes a WorkerPool.
+type WorkerPoolConfig struct {
+	// Workers is the number of goroutines running tasks.
+	Workers int
+	// QueueSize is the number of tasks waiting for a worker before Submit rejects.
+	QueueSize int
+	// TaskTimeout bounds each task's context.
+	TaskTimeout time.Duration
+}
+
+// NewWorkerPoolConfigFromEnv reads the background worker pool configuration from:
+//   - BACKGROUND_WORKERS: goroutines running background tasks (default 8)
+//   - BACKGROUND_QUEUE_SIZE: tasks queued before Submit rejects (default 1024)
+//   - BACKGROUND_TASK_TIMEOUT: deadline of each task (default "10s")
+//
+// Invalid values fall back to the defaults.
+func NewWorkerPoolConfigFromEnv() WorkerPoolConfig {
+	workers := GetEnvInt("BACKGROUND_WORKERS", defaultBackgroundWorkers)
+	if workers <= 0 {
+		workers = defaultBackgroundWorkers
+	}
+
+	return WorkerPoolConfig{
+		Workers:     workers,
+		QueueSize:   max(GetEnvInt("BACKGROUND_QUEUE_SIZE", defaultBackgroundQueueSize), 0),
+		TaskTimeout: parseDurationEnv("BACKGROUND_TASK_TIMEOUT", defaultBackgroundTaskTimeout),
+	}
+}
+
+type workerTask struct {
+	name string
+	ctx  context.Context
+	fn   func(ctx context.Context)
+}
+
+// WorkerPool runs the background work of requests (audit writes, webhooks,
+// cache invalidations) on a fixed number of goroutines instead of one
+// goroutine per request, so a traffic spike cannot grow goroutines without
+// bound.
+//
+// Submit never blocks on the pool: when the queue is full, best-effort tasks
+// are dropped and critical tasks run inline, and both are counted in
+// background_tasks_rejected_total.
+//
+// A nil *WorkerPool runs every task inline.
+type WorkerPool struct {
+	config WorkerPoolConfig
+	tasks  chan workerTask
+
+	queueDepth prometheus.GaugeFunc
+	rejected   *prometheus.CounterVec
+}
+
+// NewWorkerPool creates a worker pool. Tasks queue until Run starts the workers.
+func NewWorkerPool(config WorkerPoolConfig) *WorkerPool {
+	p := &WorkerPool{
+		config: config,
+		tasks:  make(chan workerTask, config.QueueSize),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "background_tasks_rejected_total",
+			Help: "Background tasks rejected because the worker pool queue was full, by task and priority (best_effort tasks are dropped, critical tasks run inline).",
+		}, []string{"task", "priority"}),
+	}
+	p.queueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "background_task_queue_depth",
+		Help: "Background tasks waiting for a worker.",
+	}, func() float64 { return float64(len(p.tasks)) })
+
+	return p
+}
+
+// Collectors returns the worker pool metrics for registration.
+func (p *WorkerPool) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{p.queueDepth, p.rejected}
+}
+
+// Submit queues fn to run on a worker and reports whether it was queued.
+// name labels the task in metrics and logs.
+//
+// fn gets ctx without its cancellation, since the request usually finishes
+// first, bounded by the pool's task timeout. A task that panics is logged
+// and does not take down its worker.
+func (p *WorkerPool) Submit(ctx context.Context, name string, priority TaskPriority, fn func(ctx context.Context)) bool {
+	task := workerTask{name: name, ctx: context.WithoutCancel(ctx), fn: fn}
+	if p == nil {
+		runWorkerTask(task, 0)
+		return false
+	}
+
+	select {
+	case p.tasks <- task:
+		return true
+	default:
+	}
+
+	p.rejected.WithLabelValues(name, string(priority)).Inc()
+	if priority == TaskCritical {
+		runWorkerTask(task, p.config.TaskTimeout)
+		return false
+	}
+
+	logrus.WithField("task", name).Warn("Background task dropped: worker pool saturated")
+	return false
+}
+
+// Run starts the workers and blocks until ctx is done and the tasks already
+// queued have run.
+func (p *WorkerPool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(p.config.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *WorkerPool) work(ctx context.Context) {
+	for {
+		select {
+		case task := <-p.tasks:
+			runWorkerTask(task, p.config.TaskTimeout)
+		case <-ctx.Done():
+			// Drain what was queued before shutdown
+			for {
+				select {
+				case task := <-p.tasks:
+					runWorkerTask(task, p.config.TaskTimeout)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func runWorkerTask(task workerTask, timeout time.Duration) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithFields(logrus.Fields{
+				"task":  task.name,
+				"panic": r,
+			}).Error("Background task panicked")
+		}
+	}()
+
+	ctx := task.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	task.fn(ctx)
+}
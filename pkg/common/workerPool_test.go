@@ -0,0 +1,198 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saturatedWorkerPool returns a pool whose only worker is stuck and whose queue
+// is full. Closing the returned channel unblocks the worker.
+func saturatedWorkerPool(t *testing.T) (*WorkerPool, chan struct{}) {
+	t.Helper()
+
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 1, TaskTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.True(t, pool.Submit(context.Background(), "block", TaskBestEffort, func(context.Context) {
+		close(started)
+		<-release
+	}))
+	<-started
+	require.True(t, pool.Submit(context.Background(), "block", TaskBestEffort, func(context.Context) {}))
+
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+		cancel()
+		<-done
+	})
+	return pool, release
+}
+
+func TestNewWorkerPoolConfigFromEnv(t *testing.T) {
+	t.Setenv("BACKGROUND_WORKERS", "0")
+	t.Setenv("BACKGROUND_QUEUE_SIZE", "64")
+	t.Setenv("BACKGROUND_TASK_TIMEOUT", "2s")
+
+	config := NewWorkerPoolConfigFromEnv()
+
+	assert.Equal(t, defaultBackgroundWorkers, config.Workers)
+	assert.Equal(t, 64, config.QueueSize)
+	assert.Equal(t, 2*time.Second, config.TaskTimeout)
+}
+
+func TestWorkerPool_RunsTasks(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 2, QueueSize: 10, TaskTimeout: time.Second})
+
+	var ran atomic.Int32
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		assert.True(t, pool.Submit(context.Background(), "count", TaskBestEffort, func(context.Context) {
+			ran.Add(1)
+			wg.Done()
+		}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	wg.Wait()
+	assert.Equal(t, int32(5), ran.Load())
+}
+
+func TestWorkerPool_TaskContext(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 1, TaskTimeout: time.Minute})
+
+	requestCtx, cancelRequest := context.WithCancel(context.Background())
+	taskErr := make(chan error, 1)
+	var hasDeadline bool
+	pool.Submit(requestCtx, "ctx", TaskBestEffort, func(ctx context.Context) {
+		_, hasDeadline = ctx.Deadline()
+		taskErr <- ctx.Err()
+	})
+	// The request finishing must not cancel its queued task
+	cancelRequest()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	assert.NoError(t, <-taskErr)
+	assert.True(t, hasDeadline)
+}
+
+func TestWorkerPool_RecoversPanics(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 2, TaskTimeout: time.Second})
+
+	ran := make(chan struct{})
+	pool.Submit(context.Background(), "panic", TaskBestEffort, func(context.Context) { panic("boom") })
+	pool.Submit(context.Background(), "after", TaskBestEffort, func(context.Context) { close(ran) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("worker stopped after a task panicked")
+	}
+}
+
+func TestWorkerPool_DrainsQueueOnShutdown(t *testing.T) {
+	pool := NewWorkerPool(WorkerPoolConfig{Workers: 1, QueueSize: 3, TaskTimeout: time.Second})
+
+	var ran atomic.Int32
+	for range 3 {
+		pool.Submit(context.Background(), "drain", TaskBestEffort, func(context.Context) { ran.Add(1) })
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pool.Run(ctx)
+
+	assert.Equal(t, int32(3), ran.Load())
+}
+
+func TestWorkerPool_Saturated(t *testing.T) {
+	pool, _ := saturatedWorkerPool(t)
+
+	var bestEffortRan bool
+	assert.False(t, pool.Submit(context.Background(), "audit", TaskBestEffort, func(context.Context) { bestEffortRan = true }))
+	assert.False(t, bestEffortRan, "best-effort tasks are dropped")
+
+	var criticalRan bool
+	assert.False(t, pool.Submit(context.Background(), "dead_letter", TaskCritical, func(context.Context) { criticalRan = true }))
+	assert.True(t, criticalRan, "critical tasks run inline")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(pool.rejected.WithLabelValues("audit", string(TaskBestEffort))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pool.rejected.WithLabelValues("dead_letter", string(TaskCritical))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pool.queueDepth))
+}
+
+func TestWorkerPool_SaturatedNeverBlocksHandler(t *testing.T) {
+	pool, release := saturatedWorkerPool(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pool.Submit(r.Context(), "audit", TaskBestEffort, func(context.Context) {})
+		pool.Submit(r.Context(), "dead_letter", TaskCritical, func(context.Context) {})
+		w.WriteHeader(http.StatusOK)
+	})
+
+	served := make(chan int, 50)
+	for range cap(served) {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/claim", nil))
+			served <- rec.Code
+		}()
+	}
+
+	// The worker is still stuck: every request must finish without it
+	deadline := time.After(5 * time.Second)
+	for range cap(served) {
+		select {
+		case code := <-served:
+			assert.Equal(t, http.StatusOK, code)
+		case <-deadline:
+			t.Fatal("request handler blocked on a saturated worker pool")
+		}
+	}
+	close(release)
+
+	assert.Equal(t, 50.0, testutil.ToFloat64(pool.rejected.WithLabelValues("audit", string(TaskBestEffort))))
+	assert.Equal(t, 50.0, testutil.ToFloat64(pool.rejected.WithLabelValues("dead_letter", string(TaskCritical))))
+}
+
+func TestWorkerPool_Nil(t *testing.T) {
+	var pool *WorkerPool
+
+	var ran bool
+	assert.False(t, pool.Submit(context.Background(), "inline", TaskBestEffort, func(context.Context) { ran = true }))
+	assert.True(t, ran)
+}
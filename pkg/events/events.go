@@ -0,0 +1,95 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package events defines the domain events the challenge service publishes for
+// other Extend apps (season pass, analytics). Consumers can import it to decode
+// them; it only depends on the standard library.
+//
+// Every event is an Envelope whose Data holds the type's payload. Timestamps are
+// UTC in whole seconds, RFC3339 in JSON. Events are delivered at least once, so
+// consumers deduplicate by Envelope.ID.
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SchemaVersion is the version of the envelope and payload schemas. It changes
+// only when a field is removed or changes meaning; new fields keep the version.
+const SchemaVersion = 1
+
+// Source is the Envelope.Source of every event published by this service.
+const Source = "extend-challenge-service"
+
+// Event types (Envelope.Type).
+const (
+	// TypeGoalCompleted is published when a goal's status becomes completed.
+	TypeGoalCompleted = "goal.completed"
+	// TypeGoalClaimed is published when a goal's reward is claimed.
+	TypeGoalClaimed = "goal.claimed"
+	// TypeGoalsSelected is published when BatchSelectGoals or RandomSelectGoals
+	// changes a player's active goals.
+	TypeGoalsSelected = "goals.selected"
+	// TypePlayerInitialized is published when InitializePlayer assigns a new
+	// player's default goals.
+	TypePlayerInitialized = "player.initialized"
+)
+
+// Envelope is the message published for every event.
+type Envelope struct {
+	// ID is unique per event; a redelivered event keeps its ID.
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Source        string `json:"source"`
+	Namespace     string `json:"namespace"`
+	UserID        string `json:"userId"`
+	// OccurredAt is when the change happened.
+	OccurredAt time.Time `json:"occurredAt"`
+	// Data is the payload of Type: GoalCompleted, GoalClaimed, GoalsSelected or
+	// PlayerInitialized.
+	Data json.RawMessage `json:"data"`
+}
+
+// GoalCompleted is the payload of goal.completed.
+type GoalCompleted struct {
+	GoalID      string `json:"goalId"`
+	ChallengeID string `json:"challengeId"`
+	// Progress is the stored progress; for relative goals it includes the baseline.
+	Progress    int    `json:"progress"`
+	CompletedAt string `json:"completedAt"`
+}
+
+// GoalClaimed is the payload of goal.claimed.
+type GoalClaimed struct {
+	GoalID      string `json:"goalId"`
+	ChallengeID string `json:"challengeId"`
+	CompletedAt string `json:"completedAt"`
+	ClaimedAt   string `json:"claimedAt"`
+	// ClientContext is the client_context of the ClaimGoalReward request;
+	// omitted when it had none.
+	ClientContext map[string]string `json:"clientContext,omitempty"`
+}
+
+// GoalsSelected is the payload of goals.selected.
+type GoalsSelected struct {
+	ChallengeID string `json:"challengeId"`
+	// Source is "manual" (BatchSelectGoals) or "random" (RandomSelectGoals).
+	Source string `json:"source"`
+	// SelectedGoalIDs are all goals of the selection, including ones already active.
+	SelectedGoalIDs []string `json:"selectedGoalIds"`
+	// ActivatedGoalIDs are the selected goals that were not active before.
+	ActivatedGoalIDs []string `json:"activatedGoalIds"`
+	// ReplacedGoalIDs are the goals the selection deactivated.
+	ReplacedGoalIDs []string `json:"replacedGoalIds"`
+	// ClientContext is the client_context of the selection request; omitted
+	// when it had none.
+	ClientContext map[string]string `json:"clientContext,omitempty"`
+}
+
+// PlayerInitialized is the payload of player.initialized.
+type PlayerInitialized struct {
+	AssignedGoalIDs []string `json:"assignedGoalIds"`
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DegradedHeader is set on GET /v1/challenges responses served without user
+// progress; its value is the reason.
+const DegradedHeader = "X-Challenges-Degraded"
+
+// DegradedMode lets GET /v1/challenges answer from the in-memory challenge
+// config when user progress cannot be loaded because the database is
+// unreachable. The response lists every challenge with zero progress, a
+// top-level "degraded":true field and the DegradedHeader, instead of a 500.
+//
+// Only connection-class errors degrade (see IsConnectionError); query errors
+// and every other endpoint keep failing as before, so claims and mutations
+// never act on missing progress. consistency=strong and paginated (limit)
+// requests are not degraded either.
+type DegradedMode struct {
+	enabled bool
+
+	served prometheus.Counter
+}
+
+// NewDegradedMode creates the degraded mode; it only serves degraded responses
+// when enabled.
+func NewDegradedMode(enabled bool) *DegradedMode {
+	return &DegradedMode{
+		enabled: enabled,
+		served: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "challenges_degraded_responses_total",
+			Help: "GET /v1/challenges responses served without user progress because the database was unreachable.",
+		}),
+	}
+}
+
+// NewDegradedModeFromEnv enables the degraded mode when DEGRADE_ON_DB_ERROR is
+// "true" (default false).
+func NewDegradedModeFromEnv() *DegradedMode {
+	return NewDegradedMode(strings.ToLower(common.GetEnv("DEGRADE_ON_DB_ERROR", "false")) == "true")
+}
+
+// Enabled reports whether degraded responses are served.
+func (d *DegradedMode) Enabled() bool {
+	return d != nil && d.enabled
+}
+
+// Collectors returns the degraded mode metrics for registration.
+func (d *DegradedMode) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{d.served}
+}
+
+// allow reports whether a progress load that failed with err is answered with
+// a degraded response.
+func (d *DegradedMode) allow(err error) bool {
+	return d.Enabled() && IsConnectionError(err)
+}
+
+// write sends the degraded response: body is the challenges response built
+// without progress, one JSON object that gets the "degraded" field.
+func (d *DegradedMode) write(w http.ResponseWriter, body []byte) {
+	d.served.Inc()
+	body = append(append(body[:len(body)-1], `,"degraded":true`...), '}')
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(DegradedHeader, "progress-unavailable")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// IsConnectionError reports whether err means the database could not be
+// reached, as opposed to a query that failed: a broken or closed connection,
+// a network error, or a PostgreSQL connection exception (class 08) or
+// shutdown (57P01-57P03).
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "57P01", "57P02", "57P03":
+			return true
+		}
+		return pqErr.Code.Class() == "08"
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
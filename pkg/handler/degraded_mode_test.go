@@ -0,0 +1,148 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// connRefused is the error GetUserProgress returns when PostgreSQL is down.
+var connRefused = commonErrors.ErrDatabaseError("get user progress", &net.OpError{
+	Op:  "dial",
+	Net: "tcp",
+	Err: fmt.Errorf("connect: connection refused"),
+})
+
+// newDegradedHandler builds a paged test handler with the degraded mode enabled or not.
+func newDegradedHandler(t *testing.T, mockRepo *mocks.GoalRepository, enabled bool) (*OptimizedChallengesHandler, *DegradedMode) {
+	t.Helper()
+
+	handler := newPagedTestHandler(t, mockRepo, new(mocks.ProgressQueryRepository))
+	degraded := NewDegradedMode(enabled)
+	handler.SetDegradedMode(degraded)
+	return handler, degraded
+}
+
+func TestDegradedMode_ServesConfigWithoutProgress(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, degraded := newDegradedHandler(t, mockRepo, true)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return(nil, connRefused)
+
+	w := serveSizeGuarded(handler, "/v1/challenges")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "progress-unavailable", w.Header().Get(DegradedHeader))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body struct {
+		Degraded   bool `json:"degraded"`
+		Challenges []struct {
+			ChallengeID string `json:"challengeId"`
+			Goals       []struct {
+				GoalID   string `json:"goalId"`
+				Progress int    `json:"progress"`
+				Status   string `json:"status"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Degraded)
+	require.Len(t, body.Challenges, 2)
+	goals := 0
+	for _, challenge := range body.Challenges {
+		for _, goal := range challenge.Goals {
+			goals++
+			assert.Zero(t, goal.Progress, goal.GoalID)
+			assert.Equal(t, "not_started", goal.Status, goal.GoalID)
+		}
+	}
+	assert.Equal(t, 3, goals)
+	assert.Equal(t, 1.0, testutil.ToFloat64(degraded.served))
+}
+
+func TestDegradedMode_Disabled(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, degraded := newDegradedHandler(t, mockRepo, false)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return(nil, connRefused)
+
+	w := serveSizeGuarded(handler, "/v1/challenges")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get(DegradedHeader))
+	assert.Equal(t, 0.0, testutil.ToFloat64(degraded.served))
+}
+
+func TestDegradedMode_QueryErrorStillFails(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, degraded := newDegradedHandler(t, mockRepo, true)
+	queryErr := commonErrors.ErrDatabaseError("get user progress", &pq.Error{Code: "42P01", Message: "relation does not exist"})
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return(nil, queryErr)
+
+	w := serveSizeGuarded(handler, "/v1/challenges")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get(DegradedHeader))
+	assert.Equal(t, 0.0, testutil.ToFloat64(degraded.served))
+}
+
+func TestDegradedMode_PagedRequestStillFails(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, degraded := newDegradedHandler(t, mockRepo, true)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", mock.Anything).Return(nil, connRefused)
+
+	w := serveSizeGuarded(handler, "/v1/challenges?limit=5")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 0.0, testutil.ToFloat64(degraded.served))
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "bad connection", err: fmt.Errorf("query: %w", driver.ErrBadConn), want: true},
+		{name: "connection done", err: sql.ErrConnDone, want: true},
+		{name: "network error", err: connRefused, want: true},
+		{name: "connection exception", err: &pq.Error{Code: "08006"}, want: true},
+		{name: "admin shutdown", err: &pq.Error{Code: "57P01"}, want: true},
+		{name: "query canceled", err: &pq.Error{Code: "57014"}, want: false},
+		{name: "undefined table", err: &pq.Error{Code: "42P01"}, want: false},
+		{name: "no rows", err: sql.ErrNoRows, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsConnectionError(tt.err))
+		})
+	}
+}
+
+func TestNewDegradedModeFromEnv(t *testing.T) {
+	assert.False(t, NewDegradedModeFromEnv().Enabled())
+
+	t.Setenv("DEGRADE_ON_DB_ERROR", "true")
+	assert.True(t, NewDegradedModeFromEnv().Enabled())
+
+	var unset *DegradedMode
+	assert.False(t, unset.Enabled())
+}
@@ -5,9 +5,12 @@
 package handler
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -15,7 +18,12 @@ import (
 	"time"
 
 	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/repository"
 	"extend-challenge-service/pkg/response"
+	"extend-challenge-service/pkg/service"
 
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth/validator"
 	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
@@ -24,8 +32,9 @@ import (
 	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
 )
 
-// OptimizedChallengesHandler provides an optimized HTTP endpoint for GET /v1/challenges
-// that uses pre-serialized challenge data to reduce CPU usage by ~40%.
+// OptimizedChallengesHandler provides optimized HTTP endpoints for GET /v1/challenges
+// (ServeHTTP) and GET /v1/challenges/{challenge_id} (ServeChallenge) that use
+// pre-serialized challenge data to reduce CPU usage by ~40%.
 //
 // Performance improvements vs standard gRPC handler:
 //   - CPU: ~40% reduction (eliminates redundant marshaling of static challenge data)
@@ -39,12 +48,28 @@ import (
 //
 // Thread-safety: Safe for concurrent use (all dependencies are thread-safe)
 type OptimizedChallengesHandler struct {
-	goalCache       commonCache.GoalCache
-	repo            commonRepo.GoalRepository
-	responseBuilder *response.ChallengeResponseBuilder
-	namespace       string
-	authEnabled     bool
-	tokenValidator  validator.AuthTokenValidator
+	goalCache              commonCache.GoalCache
+	repo                   commonRepo.GoalRepository
+	progressQueries        repository.ProgressQueryRepository
+	responseBuilder        *response.ChallengeResponseBuilder
+	namespace              string
+	authEnabled            bool
+	tokenValidator         validator.AuthTokenValidator
+	hiddenGoals            service.HiddenGoals
+	challengePrerequisites service.ChallengePrerequisites
+	rollouts               service.ChallengeRollouts
+	targetOverrides        service.TargetOverrides
+	activationSrc          repository.ActivationSourceRepository
+	goalSteps              service.GoalSteps
+	stepProgress           repository.StepProgressRepository
+	repeatableGoals        service.RepeatableGoals
+	repeatableRepo         repository.RepeatableGoalRepository
+	sizeGuard              *ResponseSizeGuard
+	degraded               *DegradedMode
+	snapshots              *cache.GoalSnapshots
+	debug                  *common.DebugMetadata
+	timing                 *common.DebugTiming
+	now                    func() time.Time
 }
 
 // NewOptimizedChallengesHandler creates a new optimized challenges handler.
@@ -52,6 +77,7 @@ type OptimizedChallengesHandler struct {
 // Args:
 //   - goalCache: Challenge configuration cache
 //   - repo: Goal repository for loading user progress
+//   - progressQueries: Keyset-paginated progress queries (nil disables ?limit= pagination)
 //   - serCache: Serialization cache with pre-serialized challenge JSON
 //   - namespace: AGS namespace
 //   - authEnabled: Whether JWT authentication is enabled
@@ -62,6 +88,7 @@ type OptimizedChallengesHandler struct {
 func NewOptimizedChallengesHandler(
 	goalCache commonCache.GoalCache,
 	repo commonRepo.GoalRepository,
+	progressQueries repository.ProgressQueryRepository,
 	serCache *cache.SerializedChallengeCache,
 	namespace string,
 	authEnabled bool,
@@ -70,25 +97,270 @@ func NewOptimizedChallengesHandler(
 	return &OptimizedChallengesHandler{
 		goalCache:       goalCache,
 		repo:            repo,
+		progressQueries: progressQueries,
 		responseBuilder: response.NewChallengeResponseBuilder(serCache),
 		namespace:       namespace,
 		authEnabled:     authEnabled,
 		tokenValidator:  tokenValidator,
+		now:             func() time.Time { return time.Now().UTC() },
 	}
 }
 
+// SetHiddenGoals sets the goals that are only listed once the user unlocks them.
+// The same set must be given to the serialization cache before warm-up.
+func (h *OptimizedChallengesHandler) SetHiddenGoals(hiddenGoals service.HiddenGoals) {
+	h.hiddenGoals = hiddenGoals
+}
+
+// SetChallengePrerequisites sets the challenges that are locked until their
+// prerequisite challenges are completed.
+func (h *OptimizedChallengesHandler) SetChallengePrerequisites(prerequisites service.ChallengePrerequisites) {
+	h.challengePrerequisites = prerequisites
+}
+
+// SetChallengeRollouts sets the challenges available to a percentage of users
+// only; other users do not see them listed and get 404 for them.
+func (h *OptimizedChallengesHandler) SetChallengeRollouts(rollouts service.ChallengeRollouts) {
+	h.rollouts = rollouts
+}
+
+// SetTargetOverrides sets the per-segment goal targets. The segment of each
+// request is resolved by common.SegmentHTTPMiddleware. The same overrides must be
+// given to the serialization cache before warm-up.
+func (h *OptimizedChallengesHandler) SetTargetOverrides(overrides service.TargetOverrides) {
+	h.targetOverrides = overrides
+}
+
+// SetActivationSources sets the repository that goal activation sources are
+// loaded from. Without it, activationSource is empty for every goal.
+func (h *OptimizedChallengesHandler) SetActivationSources(sources repository.ActivationSourceRepository) {
+	h.activationSrc = sources
+}
+
+// SetGoalSteps sets the multi-step goals and the repository their step progress
+// is loaded from; each multi-step goal is listed with its steps. Without it, no
+// goal has steps.
+func (h *OptimizedChallengesHandler) SetGoalSteps(steps service.GoalSteps, stepProgress repository.StepProgressRepository) {
+	h.goalSteps = steps
+	h.stepProgress = stepProgress
+}
+
+// SetRepeatableGoals sets the repeatable goals and the repository their claim
+// history is loaded from; each repeatable goal is listed with it, and is not
+// activatable while it cools down. Without it, no goal is repeatable.
+func (h *OptimizedChallengesHandler) SetRepeatableGoals(goals service.RepeatableGoals, repo repository.RepeatableGoalRepository) {
+	h.repeatableGoals = goals
+	h.repeatableRepo = repo
+}
+
+// SetResponseSizeGuard sets the size cap of unpaginated GET /v1/challenges
+// responses. Without it, responses are not limited.
+func (h *OptimizedChallengesHandler) SetResponseSizeGuard(guard *ResponseSizeGuard) {
+	h.sizeGuard = guard
+}
+
+// SetDegradedMode sets the degraded mode that answers GET /v1/challenges from
+// the challenge config when the database is unreachable. Without it, a failed
+// progress load is a 500.
+func (h *OptimizedChallengesHandler) SetDegradedMode(degraded *DegradedMode) {
+	h.degraded = degraded
+}
+
+// SetGoalSnapshots makes each request look goals up in the current snapshot view
+// instead of the goal cache. Without it, every lookup goes to the goal cache.
+func (h *OptimizedChallengesHandler) SetGoalSnapshots(snapshots *cache.GoalSnapshots) {
+	h.snapshots = snapshots
+}
+
+// SetDebugMetadata sets the X-Debug-* headers sent with each response once the
+// user is resolved. Without it, no debug headers are sent.
+func (h *OptimizedChallengesHandler) SetDebugMetadata(debug *common.DebugMetadata) {
+	h.debug = debug
+}
+
+// SetDebugTiming sets which requests sending X-Debug-Timing get the auth, db,
+// assembly and encode phases back in a Server-Timing header. Without it, the
+// header is ignored.
+func (h *OptimizedChallengesHandler) SetDebugTiming(timing *common.DebugTiming) {
+	h.timing = timing
+}
+
+// goalView returns the goal lookups to use for one request: the current
+// snapshot view if snapshots are set, otherwise the goal cache.
+func (h *OptimizedChallengesHandler) goalView() commonCache.GoalCache {
+	if h.snapshots == nil {
+		return h.goalCache
+	}
+	return h.snapshots.View()
+}
+
+// activationSources loads the activation source of each goal with a progress row.
+func (h *OptimizedChallengesHandler) activationSources(
+	ctx context.Context,
+	userID string,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+) map[string]string {
+	if h.activationSrc == nil || len(progressMap) == 0 {
+		return nil
+	}
+
+	goalIDs := make([]string, 0, len(progressMap))
+	for goalID := range progressMap {
+		goalIDs = append(goalIDs, goalID)
+	}
+	sort.Strings(goalIDs)
+
+	return service.ResolveActivationSources(ctx, h.activationSrc, userID, nil, "", goalIDs, logrus.StandardLogger())
+}
+
+// withFirstCompletions returns the builder with the first completion of the
+// goals whose rows were reset since (see service.ResolveFirstCompletions).
+func (h *OptimizedChallengesHandler) withFirstCompletions(
+	ctx context.Context,
+	builder *response.ChallengeResponseBuilder,
+	userID string,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+) *response.ChallengeResponseBuilder {
+	if h.progressQueries == nil || len(progressMap) == 0 {
+		return builder
+	}
+
+	goalIDs := make([]string, 0, len(progressMap))
+	for goalID := range progressMap {
+		goalIDs = append(goalIDs, goalID)
+	}
+	sort.Strings(goalIDs)
+
+	completions := service.ResolveFirstCompletions(ctx, h.progressQueries, userID, goalIDs, progressMap, logrus.StandardLogger())
+	return builder.WithFirstCompletions(completions)
+}
+
+// builderFor returns the response builder for the segment, with the user's
+// progress on the steps of the multi-step goals. Step progress is only loaded
+// when the config has multi-step goals.
+func (h *OptimizedChallengesHandler) builderFor(
+	ctx context.Context,
+	userID string,
+	segment string,
+) (*response.ChallengeResponseBuilder, error) {
+	builder := h.responseBuilder.ForSegment(segment)
+	if len(h.goalSteps) == 0 || h.stepProgress == nil {
+		return builder, nil
+	}
+
+	progress, err := h.goalSteps.LoadProgress(ctx, h.stepProgress, userID, h.goalSteps.GoalIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make(map[string][]*pb.GoalStep, len(progress))
+	for goalID, values := range progress {
+		for i, step := range h.goalSteps.Steps(goalID) {
+			steps[goalID] = append(steps[goalID], mapper.GoalStepToProto(step.StatCode, step.Operator, step.TargetValue, values[i]))
+		}
+	}
+	return builder.WithSteps(steps), nil
+}
+
+// withRepeats returns the builder with the claim history of the repeatable
+// goals and the first completion of those whose claims reset them, and clears activatable for those cooling down at now. A failure to
+// load the history is logged and lists the goals without it.
+func (h *OptimizedChallengesHandler) withRepeats(
+	ctx context.Context,
+	builder *response.ChallengeResponseBuilder,
+	userID string,
+	activatable map[string]bool,
+	now time.Time,
+) *response.ChallengeResponseBuilder {
+	if len(h.repeatableGoals) == 0 || h.repeatableRepo == nil {
+		return builder
+	}
+
+	goalIDs := make([]string, 0, len(h.repeatableGoals))
+	for goalID := range h.repeatableGoals {
+		goalIDs = append(goalIDs, goalID)
+	}
+
+	ctx = service.WithRepeatableGoals(ctx, h.repeatableGoals, h.repeatableRepo)
+	repeats := make(map[string]*pb.GoalRepeat, len(goalIDs))
+	firstCompletions := make(map[string]time.Time)
+	for goalID, repeat := range service.ResolveGoalRepeats(ctx, userID, goalIDs, logrus.StandardLogger()) {
+		repeats[goalID] = mapper.GoalRepeatToProto(repeat.TimesClaimed, repeat.AvailableAt)
+		if repeat.FirstCompletedAt != nil {
+			firstCompletions[goalID] = *repeat.FirstCompletedAt
+		}
+		if repeat.CoolingDown(now) {
+			delete(activatable, goalID)
+		}
+	}
+	return builder.WithRepeats(repeats).WithFirstCompletions(firstCompletions)
+}
+
+// claimBlockers returns the claim blockers of goals (see service.ClaimBlockers)
+// for include_blockers. progressMap holds the user's rows evaluated against
+// targets; set partial when it may lack rows of the goals' prerequisites.
+func (h *OptimizedChallengesHandler) claimBlockers(
+	ctx context.Context,
+	view commonCache.GoalCache,
+	userID string,
+	goals []*commonDomain.Goal,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+	targets service.SegmentTargets,
+	locks map[string]string,
+	partial bool,
+) (map[string][]string, error) {
+	progress := progressMap
+	if partial {
+		merged, err := service.WithClaimPrerequisites(ctx, h.repo, userID, goals, progressMap)
+		if err != nil {
+			return nil, err
+		}
+		progress = targets.EvaluateAll(merged, view)
+	}
+
+	var stepProgress map[string][]int
+	if h.stepProgress != nil {
+		goalIDs := make([]string, 0, len(goals))
+		for _, goal := range goals {
+			goalIDs = append(goalIDs, goal.ID)
+		}
+		var err error
+		if stepProgress, err = h.goalSteps.LoadProgress(ctx, h.stepProgress, userID, goalIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return service.ClaimBlockers(goals, service.ClaimBlockerState{
+		Progress:     progress,
+		Targets:      targets,
+		Locks:        locks,
+		Steps:        h.goalSteps,
+		StepProgress: stepProgress,
+		Now:          time.Now().UTC(),
+	}), nil
+}
+
 // ServeHTTP handles GET /v1/challenges with optimized pre-serialization.
 //
 // Request:
 //   - Method: GET
 //   - Path: /v1/challenges
 //   - Query Parameters: active_only=true|false (optional, default: false)
+//   - Query Parameters: exclude_claimed=true|false (optional, default: false; leaves out claimed goals)
+//   - Query Parameters: include_blockers=true|false (optional, default: false; adds claimBlockers to goals)
+//   - Query Parameters: limit=N, after_goal_id=X (optional, see servePage)
+//   - Query Parameters: challenge_ids=X (optional, repeatable; not with limit)
+//   - Query Parameters: consistency=strong (optional, not with limit; see service.ConsistencyStrong)
+//   - Query Parameters: allow_large=true (optional, see ResponseSizeGuard)
 //   - Headers: Authorization: Bearer <JWT token> (if auth enabled)
 //
 // Response:
-//   - 200 OK: JSON array of challenges with user progress
+//   - 200 OK: JSON array of challenges with user progress, plus snapshotAt with consistency=strong
+//   - 400 Bad Request: Invalid limit or consistency, or limit combined with challenge_ids or consistency
 //   - 401 Unauthorized: Invalid or missing JWT token
-//   - 500 Internal Server Error: Database or cache errors
+//   - 413 Request Entity Too Large: Unpaginated response above the size cap, without allow_large=true
+//   - 500 Internal Server Error: Database or cache errors (with DegradedMode, an
+//     unreachable database is a 200 with "degraded":true and no progress)
 //
 // Performance characteristics:
 //   - Average latency: <100ms @ 400 RPS (p95: <150ms)
@@ -101,45 +373,115 @@ func (h *OptimizedChallengesHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	timings := h.timing.Start(r.Header.Get(common.DebugTimingHeader), h.namespace, r.Header.Get("Authorization"), h.tokenValidator)
+
 	// Extract user ID from JWT token or test header
 	userID, err := h.extractUserID(r)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to extract user ID")
+		logrus.WithError(err).WithField("client_ip", common.GetClientIPFromContext(r.Context())).Error("Failed to extract user ID")
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
+	h.debug.SetHTTPHeaders(w, r, userID, h.namespace)
+	timings.Mark("auth")
 
 	// M3 Phase 4: Extract active_only query parameter
 	// Default to false (show all goals) if not provided
 	activeOnly := r.URL.Query().Get("active_only") == "true"
+	excludeClaimed := r.URL.Query().Get("exclude_claimed") == "true"
+	includeBlockers := r.URL.Query().Get("include_blockers") == "true"
+	challengeIDFilter := r.URL.Query()["challenge_ids"]
+	strong, err := service.ParseConsistency(r.URL.Query().Get("consistency"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":     userID,
-		"namespace":   h.namespace,
-		"handler":     "optimized",
-		"active_only": activeOnly,
+		"user_id":          userID,
+		"client_ip":        common.GetClientIPFromContext(r.Context()),
+		"namespace":        h.namespace,
+		"handler":          "optimized",
+		"active_only":      activeOnly,
+		"exclude_claimed":  excludeClaimed,
+		"include_blockers": includeBlockers,
+		"challenge_ids":    challengeIDFilter,
+		"consistency":      r.URL.Query().Get("consistency"),
 	}).Info("Getting user challenges (optimized)")
 
+	// Every goal lookup of this request uses one view
+	view := h.goalView()
+
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" && h.progressQueries != nil {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			// Not a number: rejected as out of range
+			limit = 0
+		}
+		if err := service.CheckProgressPage(limit, challengeIDFilter, strong); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.servePage(w, r, view, timings, userID, activeOnly, excludeClaimed, includeBlockers, r.URL.Query().Get("after_goal_id"), limit)
+		return
+	}
+
 	// Get all challenges from cache
-	challenges := h.goalCache.GetAllChallenges()
+	challenges := service.FilterChallenges(view.GetAllChallenges(), challengeIDFilter)
+	challenges = h.rollouts.AvailableChallenges(userID, challenges)
 	if len(challenges) == 0 {
-		// No challenges configured - return empty response
+		// No challenges configured (or matching the filter) - return empty response
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{"challenges":[]}`))
 		return
 	}
 
-	// Get user progress from database
-	ctx := r.Context()
-	// M3 Phase 4: Pass activeOnly parameter from query string
-	allProgress, err := h.repo.GetUserProgress(ctx, userID, activeOnly)
+	// Build challenge IDs list
+	challengeIDs := make([]string, 0, len(challenges))
+	for _, challenge := range challenges {
+		challengeIDs = append(challengeIDs, challenge.ID)
+	}
+
+	// Refuse oversized responses before loading any progress
+	if h.sizeGuard != nil {
+		if size := h.responseBuilder.StaticResponseSize(challengeIDs); !h.sizeGuard.allow(size, r) {
+			logrus.WithFields(logrus.Fields{
+				"user_id":       userID,
+				"namespace":     h.namespace,
+				"response_size": size,
+				"max_bytes":     h.sizeGuard.MaxBytes(),
+			}).Warn("Rejected oversized challenges response")
+			h.sizeGuard.reject(w, size, h.progressQueries != nil)
+			return
+		}
+	}
+
+	// Get user progress from database; a filter loads only its challenges' rows.
+	// The queries made with ctx are counted in timings.
+	ctx := common.WithTimings(r.Context(), timings)
+	filtered := len(challengeIDFilter) > 0
+	var allProgress []*commonDomain.UserGoalProgress
+	var snapshotAt time.Time
+	if strong {
+		allProgress, snapshotAt, err = service.LoadProgressSnapshot(ctx, h.progressQueries, userID, "", activeOnly)
+	} else if filtered {
+		allProgress, err = service.LoadChallengesProgress(ctx, h.repo, userID, challenges, activeOnly)
+	} else {
+		// M3 Phase 4: Pass activeOnly parameter from query string
+		allProgress, err = h.repo.GetUserProgress(ctx, userID, activeOnly)
+	}
+	timings.Mark("db")
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": h.namespace,
 			"error":     err,
 		}).Error("Failed to load user progress")
+		if !strong && h.degraded.allow(err) {
+			h.serveDegraded(ctx, w, userID, challenges, challengeIDs)
+			return
+		}
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -151,34 +493,107 @@ func (h *OptimizedChallengesHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 	}
 
 	// M5: Pre-process progressMap with display rotation adjustments
-	// Creates shallow copies with adjusted progress/status/ExpiresAt for display
-	now := time.Now().UTC()
-	displayMap := make(map[string]*commonDomain.UserGoalProgress, len(progressMap))
-	for goalID, progress := range progressMap {
-		goal := h.goalCache.GetGoalByID(goalID)
-		if goal == nil {
-			displayMap[goalID] = progress
-			continue
-		}
+	// Rows the user's segment has completed at a lower target are shown completed
+	targets := h.targetOverrides.For(common.GetSegmentFromContext(ctx))
+	progressMap = targets.EvaluateAll(progressMap, view)
+	now := h.now()
+	displayMap := h.buildDisplayMap(view, progressMap, now)
+	for _, challenge := range challenges {
+		addNoProgressExpiry(displayMap, challenge.Goals, now)
+	}
 
-		display := *progress // shallow copy
-		displayedProgress, displayStatus, _ := rotation.ApplyDisplayRotation(progress, goal, now)
-		display.Progress = displayedProgress
-		display.Status = displayStatus
-		display.ExpiresAt = rotation.CalculateNextExpiresAt(goal, now)
-		displayMap[goalID] = &display
+	// Hidden goals are not in the pre-serialized challenge JSON; append the ones
+	// this user has unlocked. With active_only or challenge_ids the progress map
+	// is partial, so prerequisite rows may need to be loaded.
+	partial := activeOnly || filtered
+	extraGoals, err := h.unlockedHiddenGoals(ctx, userID, challenges, progressMap, partial)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to load hidden goal prerequisites")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
-	// Build challenge IDs list
-	challengeIDs := make([]string, 0, len(challenges))
-	for _, challenge := range challenges {
-		challengeIDs = append(challengeIDs, challenge.ID)
+	locks, err := h.challengeLocks(ctx, view, userID, challengeIDs, progressMap, partial)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to load prerequisite challenge progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// active_only leaves activatable unset. With challenge_ids, goal
+	// prerequisites outside the filter may need to be loaded. Goals of locked
+	// challenges are never activatable.
+	var activatable map[string]bool
+	if !activeOnly {
+		var goals []*commonDomain.Goal
+		for _, challenge := range challenges {
+			goals = append(goals, challenge.Goals...)
+		}
+		lookup := progressMap
+		if filtered {
+			lookup, err = service.WithActivationPrerequisites(ctx, h.repo, userID, goals, progressMap)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"user_id":   userID,
+					"namespace": h.namespace,
+					"error":     err,
+				}).Error("Failed to load goal prerequisites")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		activatable = service.ActivatableGoals(goals, lookup)
+		service.ClearLockedActivatable(activatable, goals, locks)
 	}
 
 	// Use optimized response builder to create JSON
 	// This uses pre-serialized challenge data and only injects user progress
 	// M5: Use displayMap (rotation-adjusted) instead of raw progressMap
-	responseJSON, err := h.responseBuilder.BuildChallengesResponse(challengeIDs, displayMap)
+	builder, err := h.builderFor(ctx, userID, targets.Segment())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to load goal step progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if excludeClaimed {
+		// Goals displayed claimed are skipped; their challenges stay listed
+		builder = builder.ExcludingClaimed()
+	}
+	if includeBlockers {
+		var goals []*commonDomain.Goal
+		for _, challenge := range challenges {
+			goals = append(goals, challenge.Goals...)
+		}
+		blockers, err := h.claimBlockers(ctx, view, userID, goals, progressMap, targets, locks, partial)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":   userID,
+				"namespace": h.namespace,
+				"error":     err,
+			}).Error("Failed to compute claim blockers")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		builder = builder.WithClaimBlockers(blockers)
+	}
+	builder = h.withRepeats(ctx, builder, userID, activatable, now)
+	builder = h.withFirstCompletions(ctx, builder, userID, progressMap)
+	builder = builder.WithNextResets(service.NextChallengeResets(challenges, now), now)
+	sources := h.activationSources(ctx, userID, progressMap)
+	timings.Mark("assembly")
+	responseJSON, err := builder.BuildChallengesResponseWithGoals(challengeIDs, extraGoals, displayMap, activatable, sources, locks)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":   userID,
@@ -189,6 +604,11 @@ func (h *OptimizedChallengesHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if strong {
+		// The builder's response is one JSON object; add the field before its closing brace
+		responseJSON = append(appendSnapshotAtField(responseJSON[:len(responseJSON)-1], snapshotAt), '}')
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"user_id":         userID,
 		"namespace":       h.namespace,
@@ -196,6 +616,8 @@ func (h *OptimizedChallengesHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		"response_size":   len(responseJSON),
 		"handler":         "optimized",
 	}).Info("Successfully built optimized challenge response")
+	timings.Mark("encode")
+	timings.WriteHeader(w.Header(), r.URL.Path, userID)
 
 	// Return JSON response
 	w.Header().Set("Content-Type", "application/json")
@@ -203,6 +625,578 @@ func (h *OptimizedChallengesHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 	_, _ = w.Write(responseJSON)
 }
 
+// serveDegraded writes the challenges response with no user progress, for a
+// progress load that failed because the database is unreachable (see
+// DegradedMode). Hidden goals, locks, activatable and claim blockers all depend
+// on progress and are left out; segment targets and next resets are kept.
+func (h *OptimizedChallengesHandler) serveDegraded(
+	ctx context.Context,
+	w http.ResponseWriter,
+	userID string,
+	challenges []*commonDomain.Challenge,
+	challengeIDs []string,
+) {
+	now := h.now()
+	segment := h.targetOverrides.For(common.GetSegmentFromContext(ctx)).Segment()
+	builder := h.responseBuilder.ForSegment(segment).WithNextResets(service.NextChallengeResets(challenges, now), now)
+	responseJSON, err := builder.BuildChallengesResponse(challengeIDs, nil, nil, nil, nil)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to build degraded response")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"namespace":       h.namespace,
+		"challenge_count": len(challenges),
+	}).Warn("Serving degraded challenge response without user progress")
+	h.degraded.write(w, responseJSON)
+}
+
+// ServeChallenge handles GET /v1/challenges/{challenge_id}, one challenge with
+// user progress, assembled from the same pre-serialized fragments as ServeHTTP.
+//
+// Request:
+//   - Method: GET
+//   - Path: /v1/challenges/{challenge_id} (read with r.PathValue)
+//   - Query Parameters: active_only=true|false (optional, default: false)
+//   - Query Parameters: consistency=strong (optional, see service.ConsistencyStrong)
+//   - Query Parameters: include_blockers=true|false (optional, default: false; adds claimBlockers to goals)
+//   - Headers: Authorization: Bearer <JWT token> (if auth enabled)
+//
+// Response:
+//   - 200 OK: {"challenge":{...}} with user progress, plus snapshotAt with consistency=strong
+//   - 400 Bad Request: Invalid consistency
+//   - 401 Unauthorized: Invalid or missing JWT token
+//   - 404 Not Found: Challenge is not configured
+//   - 500 Internal Server Error: Database or cache errors
+func (h *OptimizedChallengesHandler) ServeChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	timings := h.timing.Start(r.Header.Get(common.DebugTimingHeader), h.namespace, r.Header.Get("Authorization"), h.tokenValidator)
+	userID, err := h.extractUserID(r)
+	if err != nil {
+		logrus.WithError(err).WithField("client_ip", common.GetClientIPFromContext(r.Context())).Error("Failed to extract user ID")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.debug.SetHTTPHeaders(w, r, userID, h.namespace)
+	timings.Mark("auth")
+
+	challengeID := r.PathValue("challenge_id")
+	activeOnly := r.URL.Query().Get("active_only") == "true"
+	includeBlockers := r.URL.Query().Get("include_blockers") == "true"
+	strong, err := service.ParseConsistency(r.URL.Query().Get("consistency"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"client_ip":        common.GetClientIPFromContext(r.Context()),
+		"namespace":        h.namespace,
+		"challenge_id":     challengeID,
+		"handler":          "optimized",
+		"active_only":      activeOnly,
+		"include_blockers": includeBlockers,
+		"consistency":      r.URL.Query().Get("consistency"),
+	}).Info("Getting user challenge (optimized)")
+
+	view := h.goalView()
+	challenge := view.GetChallengeByChallengeID(challengeID)
+	if challenge == nil || !h.rollouts.IsAvailable(userID, challengeID) {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := common.WithTimings(r.Context(), timings)
+	var challengeProgress []*commonDomain.UserGoalProgress
+	var snapshotAt time.Time
+	if strong {
+		challengeProgress, snapshotAt, err = service.LoadProgressSnapshot(ctx, h.progressQueries, userID, challengeID, activeOnly)
+	} else {
+		challengeProgress, err = h.repo.GetChallengeProgress(ctx, userID, challengeID, activeOnly)
+	}
+	timings.Mark("db")
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"namespace":    h.namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to load challenge progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	progressMap := make(map[string]*commonDomain.UserGoalProgress, len(challengeProgress))
+	for _, row := range challengeProgress {
+		progressMap[row.GoalID] = row
+	}
+
+	// Rows the user's segment has completed at a lower target are shown completed
+	targets := h.targetOverrides.For(common.GetSegmentFromContext(ctx))
+	progressMap = targets.EvaluateAll(progressMap, view)
+	now := h.now()
+	displayMap := h.buildDisplayMap(view, progressMap, now)
+	addNoProgressExpiry(displayMap, challenge.Goals, now)
+
+	// Only this challenge's rows are loaded, so prerequisite rows from other
+	// challenges may be missing. Activatable needs them unless active_only,
+	// which leaves it unset; hidden goals always do.
+	lookup := progressMap
+	var activatable map[string]bool
+	if !activeOnly {
+		lookup, err = service.WithActivationPrerequisites(ctx, h.repo, userID, challenge.Goals, progressMap)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"namespace":    h.namespace,
+				"challenge_id": challengeID,
+				"error":        err,
+			}).Error("Failed to load goal prerequisites")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		activatable = service.ActivatableGoals(challenge.Goals, lookup)
+	}
+
+	locks, err := h.challengeLocks(ctx, view, userID, []string{challengeID}, lookup, true)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"namespace":    h.namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to load prerequisite challenge progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	service.ClearLockedActivatable(activatable, challenge.Goals, locks)
+
+	extraGoals, err := h.unlockedHiddenGoals(ctx, userID, []*commonDomain.Challenge{challenge}, lookup, true)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"namespace":    h.namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to load hidden goal prerequisites")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	builder, err := h.builderFor(ctx, userID, targets.Segment())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"namespace":    h.namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to load goal step progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if includeBlockers {
+		blockers, err := h.claimBlockers(ctx, view, userID, challenge.Goals, progressMap, targets, locks, true)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"namespace":    h.namespace,
+				"challenge_id": challengeID,
+				"error":        err,
+			}).Error("Failed to compute claim blockers")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		builder = builder.WithClaimBlockers(blockers)
+	}
+	builder = h.withRepeats(ctx, builder, userID, activatable, now)
+	builder = h.withFirstCompletions(ctx, builder, userID, progressMap)
+	builder = builder.WithNextResets(service.NextChallengeResets([]*commonDomain.Challenge{challenge}, now), now)
+
+	sources := h.activationSources(ctx, userID, progressMap)
+	timings.Mark("assembly")
+	challengeJSON, err := builder.AssembleChallengeWithGoals(challengeID, extraGoals[challengeID], displayMap, activatable, sources, locks)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"namespace":    h.namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to build optimized response")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	timings.Mark("encode")
+	timings.WriteHeader(w.Header(), r.URL.Path, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"challenge":`))
+	_, _ = w.Write(challengeJSON)
+	if strong {
+		_, _ = w.Write(appendSnapshotAtField(nil, snapshotAt))
+	}
+	_, _ = w.Write([]byte(`}`))
+}
+
+// appendSnapshotAtField appends the "snapshotAt" field of consistency=strong
+// responses, with its leading comma, to dst.
+func appendSnapshotAtField(dst []byte, snapshotAt time.Time) []byte {
+	dst = append(dst, `,"snapshotAt":"`...)
+	dst = mapper.AppendTimestamp(dst, snapshotAt)
+	return append(dst, '"')
+}
+
+// servePage handles GET /v1/challenges?limit=N[&after_goal_id=X].
+//
+// Goals are paged in goal_id order. Each page only loads progress for its own goals:
+//   - active_only=true: keyset query over the user's active rows (GetUserProgressPage)
+//   - active_only=false: page over configured goal IDs, then load progress by ID
+//
+// The response has the usual {"challenges":[...]} shape, with each challenge limited
+// to the goals on the page, plus "nextAfterGoalId" when more goals remain. With
+// exclude_claimed=true claimed goals are left out of the page, so it may list
+// fewer than limit goals while more remain.
+func (h *OptimizedChallengesHandler) servePage(
+	w http.ResponseWriter,
+	r *http.Request,
+	view commonCache.GoalCache,
+	timings *common.Timings,
+	userID string,
+	activeOnly bool,
+	excludeClaimed bool,
+	includeBlockers bool,
+	afterGoalID string,
+	limit int,
+) {
+	ctx := common.WithTimings(r.Context(), timings)
+
+	page, err := service.LoadProgressPage(ctx, h.progressQueries, h.repo, view, userID, activeOnly, afterGoalID, limit)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":       userID,
+			"namespace":     h.namespace,
+			"after_goal_id": afterGoalID,
+			"limit":         limit,
+			"error":         err,
+		}).Error("Failed to load user progress page")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	timings.Mark("db")
+	pageGoalIDs, pageRows := page.GoalIDs, page.Rows
+	// Like hidden goals, goals of challenges not rolled out to the user are
+	// dropped after the cursor is taken
+	pageGoalIDs = h.rollouts.AvailableGoalIDs(view, userID, pageGoalIDs)
+
+	progressMap := make(map[string]*commonDomain.UserGoalProgress, len(pageRows))
+	for _, row := range pageRows {
+		progressMap[row.GoalID] = row
+	}
+	targets := h.targetOverrides.For(common.GetSegmentFromContext(ctx))
+	progressMap = targets.EvaluateAll(progressMap, view)
+
+	// Active rows are always visible; config pages may contain locked hidden
+	// goals. Prerequisites off the page are loaded once for both activatable
+	// and hidden goals; active_only leaves activatable unset.
+	var (
+		activatable map[string]bool
+		goals       []*commonDomain.Goal
+	)
+	if !activeOnly {
+		goals = make([]*commonDomain.Goal, 0, len(pageGoalIDs))
+		for _, goalID := range pageGoalIDs {
+			if goal := view.GetGoalByID(goalID); goal != nil {
+				goals = append(goals, goal)
+			}
+		}
+
+		lookup, err := service.WithActivationPrerequisites(ctx, h.repo, userID, goals, progressMap)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":   userID,
+				"namespace": h.namespace,
+				"error":     err,
+			}).Error("Failed to load goal prerequisites")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		activatable = service.ActivatableGoals(goals, lookup)
+
+		pageGoalIDs, err = h.dropLockedHiddenGoals(ctx, view, userID, pageGoalIDs, lookup)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":   userID,
+				"namespace": h.namespace,
+				"error":     err,
+			}).Error("Failed to load hidden goal prerequisites")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	now := h.now()
+	displayMap := h.buildDisplayMap(view, progressMap, now)
+	addNoProgressExpiry(displayMap, goals, now)
+
+	// Like hidden goals, claimed goals are dropped after the cursor is taken;
+	// a challenge with no goals left is not on the page
+	if excludeClaimed {
+		pageGoalIDs = service.WithoutClaimedGoals(pageGoalIDs, displayMap)
+	}
+
+	pages := groupGoalsByChallenge(view, pageGoalIDs)
+	pageChallengeIDs := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageChallengeIDs = append(pageChallengeIDs, page.ChallengeID)
+	}
+	locks, err := h.challengeLocks(ctx, view, userID, pageChallengeIDs, progressMap, true)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to load prerequisite challenge progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	service.ClearLockedActivatable(activatable, goals, locks)
+
+	builder, err := h.builderFor(ctx, userID, targets.Segment())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to load goal step progress")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if includeBlockers {
+		pageGoals := make([]*commonDomain.Goal, 0, len(pageGoalIDs))
+		for _, goalID := range pageGoalIDs {
+			if goal := view.GetGoalByID(goalID); goal != nil {
+				pageGoals = append(pageGoals, goal)
+			}
+		}
+		blockers, err := h.claimBlockers(ctx, view, userID, pageGoals, progressMap, targets, locks, true)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":   userID,
+				"namespace": h.namespace,
+				"error":     err,
+			}).Error("Failed to compute claim blockers")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		builder = builder.WithClaimBlockers(blockers)
+	}
+	builder = h.withRepeats(ctx, builder, userID, activatable, now)
+	builder = h.withFirstCompletions(ctx, builder, userID, progressMap)
+	builder = builder.WithNextResets(service.PagedChallengeResets(view, pageChallengeIDs, now), now)
+
+	sources := h.activationSources(ctx, userID, progressMap)
+	timings.Mark("assembly")
+	responseJSON, err := builder.BuildChallengesPageResponse(pages, displayMap, activatable, sources, locks, page.NextAfterGoalID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": h.namespace,
+			"error":     err,
+		}).Error("Failed to build paginated response")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":       userID,
+		"namespace":     h.namespace,
+		"goal_count":    len(pageGoalIDs),
+		"has_more":      page.NextAfterGoalID != "",
+		"response_size": len(responseJSON),
+		"handler":       "optimized",
+	}).Info("Successfully built paginated challenge response")
+	timings.Mark("encode")
+	timings.WriteHeader(w.Header(), r.URL.Path, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(responseJSON)
+}
+
+// groupGoalsByChallenge groups page goal IDs by challenge, keeping challenges in
+// config order and goals in page order.
+func groupGoalsByChallenge(view commonCache.GoalCache, goalIDs []string) []response.ChallengePage {
+	challenges := service.PageChallenges(view, goalIDs)
+	pages := make([]response.ChallengePage, 0, len(challenges))
+	for _, challenge := range challenges {
+		ids := make([]string, len(challenge.Goals))
+		for i, goal := range challenge.Goals {
+			ids[i] = goal.ID
+		}
+		pages = append(pages, response.ChallengePage{
+			ChallengeID: challenge.ID,
+			Name:        challenge.Name,
+			Description: challenge.Description,
+			GoalIDs:     ids,
+		})
+	}
+	return pages
+}
+
+// unlockedHiddenGoals returns the hidden goals the user has unlocked, grouped by
+// challenge ID in config order. Set partial when progressMap does not hold all of
+// the user's rows, so missing prerequisite rows are loaded before deciding.
+func (h *OptimizedChallengesHandler) unlockedHiddenGoals(
+	ctx context.Context,
+	userID string,
+	challenges []*commonDomain.Challenge,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+	partial bool,
+) (map[string][]string, error) {
+	if len(h.hiddenGoals) == 0 {
+		return nil, nil
+	}
+
+	var hidden []*commonDomain.Goal
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			if h.hiddenGoals.IsHidden(goal.ID) {
+				hidden = append(hidden, goal)
+			}
+		}
+	}
+
+	lookup := progressMap
+	if partial {
+		var err error
+		if lookup, err = h.hiddenGoals.WithPrerequisites(ctx, h.repo, userID, hidden, progressMap); err != nil {
+			return nil, err
+		}
+	}
+
+	unlocked := make(map[string][]string)
+	for _, goal := range hidden {
+		if h.hiddenGoals.IsVisible(goal, lookup) {
+			unlocked[goal.ChallengeID] = append(unlocked[goal.ChallengeID], goal.ID)
+		}
+	}
+	return unlocked, nil
+}
+
+// dropLockedHiddenGoals removes the hidden goals the user has not unlocked from a page.
+func (h *OptimizedChallengesHandler) dropLockedHiddenGoals(
+	ctx context.Context,
+	view commonCache.GoalCache,
+	userID string,
+	goalIDs []string,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+) ([]string, error) {
+	if len(h.hiddenGoals) == 0 {
+		return goalIDs, nil
+	}
+
+	var hidden []*commonDomain.Goal
+	for _, goalID := range goalIDs {
+		if goal := view.GetGoalByID(goalID); goal != nil && h.hiddenGoals.IsHidden(goalID) {
+			hidden = append(hidden, goal)
+		}
+	}
+	if len(hidden) == 0 {
+		return goalIDs, nil
+	}
+
+	lookup, err := h.hiddenGoals.WithPrerequisites(ctx, h.repo, userID, hidden, progressMap)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]string, 0, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if h.hiddenGoals.IsVisible(view.GetGoalByID(goalID), lookup) {
+			visible = append(visible, goalID)
+		}
+	}
+	return visible, nil
+}
+
+// challengeLocks returns the lock reason of each locked challenge among
+// challengeIDs. Set partial when progressMap does not hold all of the user's
+// rows, so missing rows of prerequisite challenges are loaded before deciding.
+func (h *OptimizedChallengesHandler) challengeLocks(
+	ctx context.Context,
+	view commonCache.GoalCache,
+	userID string,
+	challengeIDs []string,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+	partial bool,
+) (map[string]string, error) {
+	if len(h.challengePrerequisites) == 0 {
+		return nil, nil
+	}
+
+	lookup := progressMap
+	if partial {
+		var err error
+		lookup, err = h.challengePrerequisites.WithPrerequisites(ctx, h.repo, userID, challengeIDs, view, progressMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h.challengePrerequisites.Locks(challengeIDs, view, lookup), nil
+}
+
+// buildDisplayMap applies display rotation adjustments to the progress map.
+// Creates shallow copies with adjusted progress/status/ExpiresAt for display.
+func (h *OptimizedChallengesHandler) buildDisplayMap(
+	view commonCache.GoalCache,
+	progressMap map[string]*commonDomain.UserGoalProgress,
+	now time.Time,
+) map[string]*commonDomain.UserGoalProgress {
+	displayMap := make(map[string]*commonDomain.UserGoalProgress, len(progressMap))
+	for goalID, progress := range progressMap {
+		goal := view.GetGoalByID(goalID)
+		if goal == nil {
+			displayMap[goalID] = progress
+			continue
+		}
+
+		display := *progress // shallow copy
+		displayedProgress, displayStatus, _ := rotation.ApplyDisplayRotation(progress, goal, now)
+		display.Progress = displayedProgress
+		display.Status = displayStatus
+		display.ExpiresAt = rotation.CalculateNextExpiresAt(goal, now)
+		displayMap[goalID] = &display
+	}
+	return displayMap
+}
+
+// addNoProgressExpiry adds a response.NoProgress row for each rotating goal
+// without progress, so the response shows when its period ends, as the
+// gRPC-Gateway route does.
+func addNoProgressExpiry(displayMap map[string]*commonDomain.UserGoalProgress, goals []*commonDomain.Goal, now time.Time) {
+	for _, goal := range goals {
+		if goal.Rotation == nil || !goal.Rotation.Enabled || displayMap[goal.ID] != nil {
+			continue
+		}
+		if expiresAt := rotation.CalculateNextExpiresAt(goal, now); expiresAt != nil {
+			displayMap[goal.ID] = response.NoProgress(goal.ID, expiresAt)
+		}
+	}
+}
+
 // extractUserID extracts the user ID from the request.
 //
 // If authentication is enabled, it validates the JWT token and extracts the user ID.
@@ -6,179 +6,38 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/service"
+	"extend-challenge-service/pkg/testutil"
+	"extend-challenge-service/pkg/testutil/mocks"
 
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
-	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
 )
 
-// MockGoalCache is a mock implementation of commonCache.GoalCache
-type MockGoalCache struct {
-	mock.Mock
-}
-
-func (m *MockGoalCache) GetGoalByID(goalID string) *commonDomain.Goal {
-	args := m.Called(goalID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*commonDomain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsByStatCode(statCode string) []*commonDomain.Goal {
-	args := m.Called(statCode)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).([]*commonDomain.Goal)
-}
-
-func (m *MockGoalCache) GetChallengeByChallengeID(challengeID string) *commonDomain.Challenge {
-	args := m.Called(challengeID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*commonDomain.Challenge)
-}
-
-func (m *MockGoalCache) GetAllChallenges() []*commonDomain.Challenge {
-	args := m.Called()
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).([]*commonDomain.Challenge)
-}
-
-func (m *MockGoalCache) GetAllGoals() []*commonDomain.Goal {
-	args := m.Called()
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).([]*commonDomain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsWithDefaultAssigned() []*commonDomain.Goal {
-	args := m.Called()
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).([]*commonDomain.Goal)
-}
-
-func (m *MockGoalCache) Reload() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-// MockGoalRepository is a mock implementation of commonRepo.GoalRepository
-type MockGoalRepository struct {
-	mock.Mock
-}
-
-func (m *MockGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*commonDomain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*commonDomain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*commonDomain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*commonDomain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*commonDomain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, challengeID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*commonDomain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) UpsertProgress(ctx context.Context, progress *commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, updates)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
-	args := m.Called(ctx, rows)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
-	args := m.Called(ctx, userID, goalID)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BeginTx(ctx context.Context) (commonRepo.TxRepository, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(commonRepo.TxRepository), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*commonDomain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*commonDomain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) BulkInsert(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) UpsertGoalActive(ctx context.Context, progress *commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-// M4: Batch goal activation
-func (m *MockGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-// M3 Phase 9: Fast path optimization methods
-func (m *MockGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*commonDomain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*commonDomain.UserGoalProgress), args.Error(1)
-}
-
 // createTestChallengesWithRotation returns challenges including one with daily rotation (relative mode)
 func createTestChallengesWithRotation() []*commonDomain.Challenge {
 	return []*commonDomain.Challenge{
@@ -267,8 +126,8 @@ func createTestProgress(isActive bool) []*commonDomain.UserGoalProgress {
 
 func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyFalse(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create serialization cache
 	serCache := cache.NewSerializedChallengeCache()
@@ -279,6 +138,7 @@ func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyFalse(t *testing.T) {
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -313,8 +173,8 @@ func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyFalse(t *testing.T) {
 
 func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyTrue(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create serialization cache
 	serCache := cache.NewSerializedChallengeCache()
@@ -323,6 +183,7 @@ func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyTrue(t *testing.T) {
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -357,13 +218,14 @@ func TestOptimizedChallengesHandler_ServeHTTP_ActiveOnlyTrue(t *testing.T) {
 
 func TestOptimizedChallengesHandler_ServeHTTP_MethodNotAllowed(t *testing.T) {
 	// Setup minimal mocks (won't be called)
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -383,13 +245,14 @@ func TestOptimizedChallengesHandler_ServeHTTP_MethodNotAllowed(t *testing.T) {
 
 func TestOptimizedChallengesHandler_ServeHTTP_NoChallenges(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -417,13 +280,14 @@ func TestOptimizedChallengesHandler_ServeHTTP_NoChallenges(t *testing.T) {
 
 func TestOptimizedChallengesHandler_ServeHTTP_DatabaseError(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -453,13 +317,14 @@ func TestOptimizedChallengesHandler_ServeHTTP_DatabaseError(t *testing.T) {
 
 // TestOptimizedChallengesHandler_ExtractUserID_NoAuthNoHeader tests missing user ID header
 func TestOptimizedChallengesHandler_ExtractUserID_NoAuthNoHeader(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -484,13 +349,14 @@ func TestOptimizedChallengesHandler_ExtractUserID_NoAuthNoHeader(t *testing.T) {
 
 // TestOptimizedChallengesHandler_ExtractUserID_WithCustomHeader tests custom user ID header
 func TestOptimizedChallengesHandler_ExtractUserID_WithCustomHeader(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -669,14 +535,15 @@ func (m *MockTokenValidator) Validate(token string, permission *iam.Permission,
 
 // TestExtractUserID_AuthEnabled_MissingAuthHeader tests missing Authorization header
 func TestExtractUserID_AuthEnabled_MissingAuthHeader(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -695,14 +562,15 @@ func TestExtractUserID_AuthEnabled_MissingAuthHeader(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_InvalidFormat tests invalid Authorization header format
 func TestExtractUserID_AuthEnabled_InvalidFormat(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -743,14 +611,15 @@ func TestExtractUserID_AuthEnabled_InvalidFormat(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_TokenValidationFails tests token validation failure
 func TestExtractUserID_AuthEnabled_TokenValidationFails(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -774,14 +643,15 @@ func TestExtractUserID_AuthEnabled_TokenValidationFails(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_InvalidJWT tests JWT decode failure
 func TestExtractUserID_AuthEnabled_InvalidJWT(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -808,14 +678,15 @@ func TestExtractUserID_AuthEnabled_InvalidJWT(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_MissingSubClaim tests JWT without sub claim
 func TestExtractUserID_AuthEnabled_MissingSubClaim(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -844,14 +715,15 @@ func TestExtractUserID_AuthEnabled_MissingSubClaim(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_Success tests successful user ID extraction with auth
 func TestExtractUserID_AuthEnabled_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 	mockValidator := new(MockTokenValidator)
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -878,13 +750,14 @@ func TestExtractUserID_AuthEnabled_Success(t *testing.T) {
 
 // TestExtractUserID_AuthEnabled_NilValidator tests nil token validator
 func TestExtractUserID_AuthEnabled_NilValidator(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		true, // auth enabled
@@ -905,13 +778,14 @@ func TestExtractUserID_AuthEnabled_NilValidator(t *testing.T) {
 // calls GetGoalByID for rotation pre-processing, which triggers display rotation logic
 // for stale progress rows (UpdatedAt = yesterday).
 func TestOptimizedHandler_RotationDisplay_StaleProgressResets(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -954,13 +828,14 @@ func TestOptimizedHandler_RotationDisplay_StaleProgressResets(t *testing.T) {
 // TestOptimizedHandler_RotationDisplay_FreshProgressPreserved verifies that the handler
 // still calls GetGoalByID even when progress is fresh (no rotation boundary crossed).
 func TestOptimizedHandler_RotationDisplay_FreshProgressPreserved(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -1002,13 +877,14 @@ func TestOptimizedHandler_RotationDisplay_FreshProgressPreserved(t *testing.T) {
 // TestOptimizedHandler_RotationDisplay_ClaimedGoalPermanent verifies that claimed goals
 // with allowReselection=false still trigger GetGoalByID for rotation pre-processing.
 func TestOptimizedHandler_RotationDisplay_ClaimedGoalPermanent(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 	serCache := cache.NewSerializedChallengeCache()
 
 	handler := NewOptimizedChallengesHandler(
 		mockCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false,
@@ -1050,3 +926,1293 @@ func TestOptimizedHandler_RotationDisplay_ClaimedGoalPermanent(t *testing.T) {
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
+
+// createPagedTestChallenges returns two challenges with three goals in total,
+// so that goal_id order (a-goal, b-goal, c-goal) crosses challenge boundaries.
+func createPagedTestChallenges() []*commonDomain.Challenge {
+	newGoal := func(id, challengeID string) *commonDomain.Goal {
+		return &commonDomain.Goal{
+			ID:          id,
+			ChallengeID: challengeID,
+			Name:        id,
+			EventSource: commonDomain.EventSourceStatistic,
+			Requirement: commonDomain.Requirement{
+				StatCode:     "kills",
+				Operator:     ">=",
+				TargetValue:  10,
+				ProgressMode: commonDomain.ProgressModeAbsolute,
+			},
+			Reward: commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		}
+	}
+	return []*commonDomain.Challenge{
+		{
+			ID:    "second-challenge",
+			Name:  "Second",
+			Goals: []*commonDomain.Goal{newGoal("b-goal", "second-challenge")},
+		},
+		{
+			ID:          "first-challenge",
+			Name:        "First",
+			Description: "Has two goals",
+			Goals: []*commonDomain.Goal{
+				newGoal("c-goal", "first-challenge"),
+				newGoal("a-goal", "first-challenge"),
+			},
+		},
+	}
+}
+
+// newPagedTestHandler builds a handler backed by a real goal cache and a warmed
+// serialization cache, so paginated responses can be asserted end to end.
+func newPagedTestHandler(t *testing.T, mockRepo *mocks.GoalRepository, mockQueries *mocks.ProgressQueryRepository) *OptimizedChallengesHandler {
+	t.Helper()
+
+	challenges := createPagedTestChallenges()
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	// No row was reset, so every goal was first completed at completed_at
+	var queries repository.ProgressQueryRepository
+	if mockQueries != nil {
+		mockQueries.On("GetFirstCompletions", mock.Anything, mock.Anything, mock.Anything).Return(map[string]time.Time(nil), nil).Maybe()
+		queries = mockQueries
+	}
+	return NewOptimizedChallengesHandler(goalCache, mockRepo, queries, serCache, "test-namespace", false, nil)
+}
+
+type pagedTestResponse struct {
+	Challenges []struct {
+		ChallengeID string `json:"challengeId"`
+		Description string `json:"description"`
+		Goals       []struct {
+			GoalID   string `json:"goalId"`
+			Progress int    `json:"progress"`
+			Status   string `json:"status"`
+		} `json:"goals"`
+	} `json:"challenges"`
+	NextAfterGoalID string `json:"nextAfterGoalId"`
+}
+
+func TestOptimizedChallengesHandler_ChallengeIDsFilter(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newPagedTestHandler(t, mockRepo, mockQueries)
+
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"b-goal", "c-goal", "a-goal"}).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "a-goal", ChallengeID: "first-challenge", Progress: 4, Status: commonDomain.GoalStatusInProgress},
+	}, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "first-challenge", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	challengeIDs := func(url string) []string {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp pagedTestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var ids []string
+		for _, challenge := range resp.Challenges {
+			ids = append(ids, challenge.ChallengeID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"second-challenge", "first-challenge"},
+		challengeIDs("/v1/challenges?challenge_ids=first-challenge&challenge_ids=second-challenge"), "config order is kept")
+	assert.Equal(t, []string{"first-challenge"}, challengeIDs("/v1/challenges?challenge_ids=first-challenge"))
+	assert.Empty(t, challengeIDs("/v1/challenges?challenge_ids=unknown"))
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_ActivationSources(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newPagedTestHandler(t, mockRepo, new(mocks.ProgressQueryRepository))
+	mockSources := new(mocks.ActivationSourceRepository)
+	handler.SetActivationSources(mockSources)
+
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", true).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "a-goal", ChallengeID: "first-challenge", Status: commonDomain.GoalStatusInProgress, IsActive: true},
+		{UserID: "test-user", GoalID: "b-goal", ChallengeID: "second-challenge", Status: commonDomain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+	mockSources.On("GetActivationSources", mock.Anything, "test-user", []string{"a-goal", "b-goal"}).
+		Return(map[string]string{"a-goal": service.ActivationSourceRandom}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges?active_only=true", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Challenges []struct {
+			Goals []struct {
+				GoalID           string `json:"goalId"`
+				ActivationSource string `json:"activationSource"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	got := make(map[string]string)
+	for _, challenge := range resp.Challenges {
+		for _, goal := range challenge.Goals {
+			got[goal.GoalID] = goal.ActivationSource
+		}
+	}
+	assert.Equal(t, map[string]string{"a-goal": "random", "b-goal": "", "c-goal": ""}, got)
+	mockSources.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_ChallengeIDsFilterWithLimit(t *testing.T) {
+	handler := newPagedTestHandler(t, new(mocks.GoalRepository), new(mocks.ProgressQueryRepository))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges?limit=1&challenge_ids=first-challenge", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOptimizedChallengesHandler_Paged_ActiveOnly(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newPagedTestHandler(t, mockRepo, mockQueries)
+
+	// limit=2 asks the repository for 3 rows to detect the next page
+	mockQueries.On("GetUserProgressPage", mock.Anything, "test-user", true, "", 3).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "a-goal", ChallengeID: "first-challenge", Progress: 4, Status: commonDomain.GoalStatusInProgress, IsActive: true},
+		{UserID: "test-user", GoalID: "b-goal", ChallengeID: "second-challenge", Progress: 10, Status: commonDomain.GoalStatusCompleted, IsActive: true},
+		{UserID: "test-user", GoalID: "c-goal", ChallengeID: "first-challenge", IsActive: true},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges?active_only=true&limit=2", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp pagedTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, "b-goal", resp.NextAfterGoalID)
+	require.Len(t, resp.Challenges, 2)
+	// Challenges keep config order; only page goals are included
+	assert.Equal(t, "second-challenge", resp.Challenges[0].ChallengeID)
+	require.Len(t, resp.Challenges[0].Goals, 1)
+	assert.Equal(t, "completed", resp.Challenges[0].Goals[0].Status)
+	assert.Equal(t, "first-challenge", resp.Challenges[1].ChallengeID)
+	assert.Equal(t, "Has two goals", resp.Challenges[1].Description)
+	require.Len(t, resp.Challenges[1].Goals, 1)
+	assert.Equal(t, "a-goal", resp.Challenges[1].Goals[0].GoalID)
+	assert.Equal(t, 4, resp.Challenges[1].Goals[0].Progress)
+
+	mockQueries.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_Paged_AllGoalsLastPage(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newPagedTestHandler(t, mockRepo, mockQueries)
+
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"b-goal", "c-goal"}).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "c-goal", ChallengeID: "first-challenge", Progress: 7, Status: commonDomain.GoalStatusInProgress},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges?limit=5&after_goal_id=a-goal", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp pagedTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Empty(t, resp.NextAfterGoalID)
+	require.Len(t, resp.Challenges, 2)
+	assert.Equal(t, "b-goal", resp.Challenges[0].Goals[0].GoalID)
+	assert.Equal(t, "not_started", resp.Challenges[0].Goals[0].Status)
+	assert.Equal(t, "c-goal", resp.Challenges[1].Goals[0].GoalID)
+	assert.Equal(t, 7, resp.Challenges[1].Goals[0].Progress)
+
+	mockRepo.AssertExpectations(t)
+	mockQueries.AssertNotCalled(t, "GetUserProgressPage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_Paged_InvalidLimit(t *testing.T) {
+	handler := newPagedTestHandler(t, new(mocks.GoalRepository), new(mocks.ProgressQueryRepository))
+
+	for _, limit := range []string{"0", "-1", "abc", "501"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/challenges?limit="+limit, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "limit=%s", limit)
+	}
+}
+
+func TestOptimizedChallengesHandler_Paged_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newPagedTestHandler(t, mockRepo, mockQueries)
+
+	mockQueries.On("GetUserProgressPage", mock.Anything, "test-user-id", true, "", 11).
+		Return(nil, errors.New("connection refused"))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges?active_only=true&limit=10", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockQueries.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_ExcludeClaimed(t *testing.T) {
+	// a-goal and b-goal are claimed, c-goal is in progress; all three are active
+	rows := func(goalIDs ...string) []*commonDomain.UserGoalProgress {
+		all := map[string]*commonDomain.UserGoalProgress{
+			"a-goal": {UserID: "test-user", GoalID: "a-goal", ChallengeID: "first-challenge", Progress: 10, Status: commonDomain.GoalStatusClaimed, IsActive: true},
+			"b-goal": {UserID: "test-user", GoalID: "b-goal", ChallengeID: "second-challenge", Progress: 10, Status: commonDomain.GoalStatusClaimed, IsActive: true},
+			"c-goal": {UserID: "test-user", GoalID: "c-goal", ChallengeID: "first-challenge", Progress: 3, Status: commonDomain.GoalStatusInProgress, IsActive: true},
+		}
+		result := make([]*commonDomain.UserGoalProgress, 0, len(goalIDs))
+		for _, goalID := range goalIDs {
+			result = append(result, all[goalID])
+		}
+		return result
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		setup func(*mocks.GoalRepository, *mocks.ProgressQueryRepository)
+		// want lists each returned challenge as "challenge-id:goal-id,goal-id"
+		want     []string
+		wantNext string
+	}{
+		{
+			name:  "claimed goals listed by default",
+			query: "",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetUserProgress", mock.Anything, "test-user", false).Return(rows("a-goal", "b-goal", "c-goal"), nil)
+			},
+			want: []string{"second-challenge:b-goal", "first-challenge:c-goal,a-goal"},
+		},
+		{
+			name:  "exclude_claimed keeps challenges without goals left",
+			query: "exclude_claimed=true",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetUserProgress", mock.Anything, "test-user", false).Return(rows("a-goal", "b-goal", "c-goal"), nil)
+			},
+			want: []string{"second-challenge:", "first-challenge:c-goal"},
+		},
+		{
+			name:  "exclude_claimed=false",
+			query: "exclude_claimed=false",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetUserProgress", mock.Anything, "test-user", false).Return(rows("a-goal", "b-goal", "c-goal"), nil)
+			},
+			want: []string{"second-challenge:b-goal", "first-challenge:c-goal,a-goal"},
+		},
+		{
+			name:  "with active_only",
+			query: "exclude_claimed=true&active_only=true",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetUserProgress", mock.Anything, "test-user", true).Return(rows("a-goal", "b-goal", "c-goal"), nil)
+			},
+			want: []string{"second-challenge:", "first-challenge:c-goal"},
+		},
+		{
+			name:  "page of claimed goals only",
+			query: "exclude_claimed=true&limit=2",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"a-goal", "b-goal"}).Return(rows("a-goal", "b-goal"), nil)
+			},
+			// The cursor still moves past the claimed goals
+			want:     []string{},
+			wantNext: "b-goal",
+		},
+		{
+			name:  "next page",
+			query: "exclude_claimed=true&limit=2&after_goal_id=b-goal",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"c-goal"}).Return(rows("c-goal"), nil)
+			},
+			want: []string{"first-challenge:c-goal"},
+		},
+		{
+			name:  "page without exclude_claimed",
+			query: "limit=2",
+			setup: func(repo *mocks.GoalRepository, _ *mocks.ProgressQueryRepository) {
+				repo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"a-goal", "b-goal"}).Return(rows("a-goal", "b-goal"), nil)
+			},
+			want:     []string{"second-challenge:b-goal", "first-challenge:a-goal"},
+			wantNext: "b-goal",
+		},
+		{
+			name:  "page with active_only",
+			query: "exclude_claimed=true&active_only=true&limit=3",
+			setup: func(_ *mocks.GoalRepository, queries *mocks.ProgressQueryRepository) {
+				queries.On("GetUserProgressPage", mock.Anything, "test-user", true, "", 4).Return(rows("a-goal", "b-goal", "c-goal"), nil)
+			},
+			want: []string{"first-challenge:c-goal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.GoalRepository)
+			mockQueries := new(mocks.ProgressQueryRepository)
+			tt.setup(mockRepo, mockQueries)
+			handler := newPagedTestHandler(t, mockRepo, mockQueries)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/challenges?"+tt.query, nil)
+			req.Header.Set("x-mock-user-id", "test-user")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var resp pagedTestResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			got := make([]string, 0, len(resp.Challenges))
+			for _, challenge := range resp.Challenges {
+				goalIDs := make([]string, 0, len(challenge.Goals))
+				for _, goal := range challenge.Goals {
+					goalIDs = append(goalIDs, goal.GoalID)
+				}
+				got = append(got, challenge.ChallengeID+":"+strings.Join(goalIDs, ","))
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantNext, resp.NextAfterGoalID)
+			mockRepo.AssertExpectations(t)
+			mockQueries.AssertExpectations(t)
+		})
+	}
+}
+
+// createHiddenGoalChallenges returns one challenge with a regular "intro" goal and
+// a hidden "secret" goal that unlocks once "intro" is claimed.
+func createHiddenGoalChallenges() []*commonDomain.Challenge {
+	newGoal := func(id string, prerequisites ...string) *commonDomain.Goal {
+		return &commonDomain.Goal{
+			ID:            id,
+			ChallengeID:   "quest",
+			Name:          id,
+			EventSource:   commonDomain.EventSourceStatistic,
+			Requirement:   commonDomain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 1, ProgressMode: commonDomain.ProgressModeAbsolute},
+			Reward:        commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			Prerequisites: prerequisites,
+		}
+	}
+	return []*commonDomain.Challenge{
+		{
+			ID:    "quest",
+			Name:  "Quest",
+			Goals: []*commonDomain.Goal{newGoal("intro"), newGoal("secret", "intro")},
+		},
+	}
+}
+
+// newHiddenGoalTestHandler builds a handler whose serialization cache was warmed
+// with "secret" marked hidden, as main does at startup.
+func newHiddenGoalTestHandler(t *testing.T, mockRepo *mocks.GoalRepository, mockQueries *mocks.ProgressQueryRepository) *OptimizedChallengesHandler {
+	t.Helper()
+
+	challenges := createHiddenGoalChallenges()
+	hidden := service.HiddenGoals{"secret": true}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	serCache.SetHiddenGoals(hidden)
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	var queries repository.ProgressQueryRepository
+	if mockQueries != nil {
+		mockQueries.On("GetFirstCompletions", mock.Anything, mock.Anything, mock.Anything).Return(map[string]time.Time(nil), nil).Maybe()
+		queries = mockQueries
+	}
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, queries, serCache, "test-namespace", false, nil)
+	handler.SetHiddenGoals(hidden)
+	return handler
+}
+
+func getGoalIDs(t *testing.T, handler *OptimizedChallengesHandler, url string) []string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp pagedTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	var goalIDs []string
+	for _, challenge := range resp.Challenges {
+		for _, goal := range challenge.Goals {
+			goalIDs = append(goalIDs, goal.GoalID)
+		}
+	}
+	return goalIDs
+}
+
+func TestOptimizedChallengesHandler_HiddenGoal_AppearsAfterPrerequisiteClaimed(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newHiddenGoalTestHandler(t, mockRepo, nil)
+
+	completed := &commonDomain.UserGoalProgress{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Progress: 1, Status: commonDomain.GoalStatusCompleted}
+	claimed := *completed
+	claimed.Status = commonDomain.GoalStatusClaimed
+
+	// Same session: the prerequisite is completed, then claimed between requests
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{completed}, nil).Once()
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{&claimed}, nil).Once()
+
+	assert.Equal(t, []string{"intro"}, getGoalIDs(t, handler, "/v1/challenges"))
+	assert.Equal(t, []string{"intro", "secret"}, getGoalIDs(t, handler, "/v1/challenges"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_HiddenGoal_ActiveOnlyLoadsPrerequisites(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newHiddenGoalTestHandler(t, mockRepo, nil)
+
+	// The claimed prerequisite is inactive, so active_only does not return it
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", true).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"intro"}).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Status: commonDomain.GoalStatusClaimed},
+	}, nil)
+
+	assert.Equal(t, []string{"intro", "secret"}, getGoalIDs(t, handler, "/v1/challenges?active_only=true"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_HiddenGoal_Paged(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newHiddenGoalTestHandler(t, mockRepo, mockQueries)
+
+	// Page 1 holds only "intro"; page 2 holds the hidden goal, whose prerequisite
+	// is not on the page and is loaded separately
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"intro"}).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Status: commonDomain.GoalStatusCompleted},
+	}, nil).Once()
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"secret"}).Return([]*commonDomain.UserGoalProgress{}, nil).Once()
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"intro"}).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Status: commonDomain.GoalStatusCompleted},
+	}, nil).Once()
+
+	assert.Equal(t, []string{"intro"}, getGoalIDs(t, handler, "/v1/challenges?limit=1"))
+	assert.Empty(t, getGoalIDs(t, handler, "/v1/challenges?limit=1&after_goal_id=intro"), "locked hidden goal is dropped from its page")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_Activatable(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newHiddenGoalTestHandler(t, mockRepo, nil)
+
+	claimed := &commonDomain.UserGoalProgress{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Progress: 1, Status: commonDomain.GoalStatusClaimed}
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{claimed}, nil)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", true).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"intro"}).Return([]*commonDomain.UserGoalProgress{claimed}, nil)
+
+	activatable := func(url string) map[string]bool {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Challenges []struct {
+				Goals []struct {
+					GoalID      string `json:"goalId"`
+					Activatable bool   `json:"activatable"`
+				} `json:"goals"`
+			} `json:"challenges"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		goals := make(map[string]bool)
+		for _, challenge := range resp.Challenges {
+			for _, goal := range challenge.Goals {
+				goals[goal.GoalID] = goal.Activatable
+			}
+		}
+		return goals
+	}
+
+	assert.Equal(t, map[string]bool{"intro": false, "secret": true}, activatable("/v1/challenges"))
+	assert.Equal(t, map[string]bool{"intro": false, "secret": false}, activatable("/v1/challenges?active_only=true"),
+		"active_only does not load the rows activatable depends on")
+}
+
+func TestOptimizedChallengesHandler_ChallengeLocks(t *testing.T) {
+	newGoal := func(id, challengeID string) *commonDomain.Goal {
+		return &commonDomain.Goal{
+			ID:          id,
+			ChallengeID: challengeID,
+			Name:        id,
+			EventSource: commonDomain.EventSourceStatistic,
+			Requirement: commonDomain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 1, ProgressMode: commonDomain.ProgressModeAbsolute},
+			Reward:      commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		}
+	}
+	challenges := []*commonDomain.Challenge{
+		{ID: "tutorial", Name: "Tutorial", Goals: []*commonDomain.Goal{newGoal("t1", "tutorial")}},
+		{ID: "season", Name: "Season", Goals: []*commonDomain.Goal{newGoal("s1", "season")}},
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	mockRepo := new(mocks.GoalRepository)
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, nil, serCache, "test-namespace", false, nil)
+	handler.SetChallengePrerequisites(service.ChallengePrerequisites{"season": {"tutorial"}})
+
+	claimed := &commonDomain.UserGoalProgress{UserID: "test-user", GoalID: "t1", ChallengeID: "tutorial", Progress: 1, Status: commonDomain.GoalStatusClaimed}
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil).Once()
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{claimed}, nil).Once()
+
+	type challengeLock struct {
+		Locked       bool   `json:"locked"`
+		LockedReason string `json:"lockedReason"`
+		Goals        []struct {
+			Activatable bool `json:"activatable"`
+		} `json:"goals"`
+	}
+	season := func() challengeLock {
+		req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Challenges []struct {
+				ChallengeID string `json:"challengeId"`
+				challengeLock
+			} `json:"challenges"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		for _, challenge := range resp.Challenges {
+			if challenge.ChallengeID == "season" {
+				return challenge.challengeLock
+			}
+		}
+		t.Fatal("season challenge missing from response")
+		return challengeLock{}
+	}
+
+	locked := season()
+	assert.True(t, locked.Locked)
+	assert.Equal(t, "prerequisite challenges not completed: tutorial", locked.LockedReason)
+	require.Len(t, locked.Goals, 1)
+	assert.False(t, locked.Goals[0].Activatable, "goals of a locked challenge cannot be activated")
+
+	unlocked := season()
+	assert.False(t, unlocked.Locked, "claiming the tutorial unlocks the season")
+	assert.Empty(t, unlocked.LockedReason)
+	assert.True(t, unlocked.Goals[0].Activatable)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// getChallenge calls ServeChallenge for challengeID as "test-user".
+func getChallenge(handler *OptimizedChallengesHandler, challengeID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges/"+challengeID, nil)
+	req.SetPathValue("challenge_id", challengeID)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeChallenge(w, req)
+	return w
+}
+
+type challengeDetailResponse struct {
+	Challenge struct {
+		ChallengeID string `json:"challengeId"`
+		Description string `json:"description"`
+		Goals       []struct {
+			GoalID   string `json:"goalId"`
+			Progress int    `json:"progress"`
+			Status   string `json:"status"`
+		} `json:"goals"`
+	} `json:"challenge"`
+}
+
+func TestOptimizedChallengesHandler_ServeChallenge(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newPagedTestHandler(t, mockRepo, nil)
+
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "first-challenge", false).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "a-goal", ChallengeID: "first-challenge", Progress: 4, Status: commonDomain.GoalStatusInProgress},
+	}, nil)
+
+	w := getChallenge(handler, "first-challenge")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var resp challengeDetailResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, "first-challenge", resp.Challenge.ChallengeID)
+	assert.Equal(t, "Has two goals", resp.Challenge.Description)
+	require.Len(t, resp.Challenge.Goals, 2)
+	assert.Equal(t, "c-goal", resp.Challenge.Goals[0].GoalID)
+	assert.Equal(t, "not_started", resp.Challenge.Goals[0].Status)
+	assert.Equal(t, "a-goal", resp.Challenge.Goals[1].GoalID)
+	assert.Equal(t, 4, resp.Challenge.Goals[1].Progress)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_NextReset(t *testing.T) {
+	// c-goal resets daily and a-goal weekly, so first-challenge resets at the next midnight
+	challenges := createPagedTestChallenges()
+	rotate := func(goal *commonDomain.Goal, schedule commonDomain.RotationSchedule) {
+		goal.Rotation = &commonDomain.RotationConfig{Enabled: true, Type: commonDomain.RotationTypeGlobal, Schedule: schedule}
+	}
+	rotate(challenges[1].Goals[0], commonDomain.RotationScheduleDaily)
+	rotate(challenges[1].Goals[1], commonDomain.RotationScheduleWeekly)
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "first-challenge", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"a-goal"}).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, new(mocks.ProgressQueryRepository), serCache, "test-namespace", false, nil)
+	handler.now = func() time.Time { return time.Date(2025, 3, 4, 23, 0, 0, 0, time.UTC) }
+
+	type resetFields struct {
+		ChallengeID      string `json:"challengeId"`
+		NextResetAt      string `json:"nextResetAt"`
+		SecondsRemaining int    `json:"secondsRemaining"`
+	}
+	list := func(url string) map[string]resetFields {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Challenges []resetFields `json:"challenges"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		byID := make(map[string]resetFields)
+		for _, c := range resp.Challenges {
+			byID[c.ChallengeID] = c
+		}
+		return byID
+	}
+
+	want := resetFields{ChallengeID: "first-challenge", NextResetAt: "2025-03-05T00:00:00Z", SecondsRemaining: 3600}
+	noReset := resetFields{ChallengeID: "second-challenge"}
+
+	challengesByID := list("/v1/challenges")
+	assert.Equal(t, want, challengesByID["first-challenge"])
+	assert.Equal(t, noReset, challengesByID["second-challenge"])
+
+	// The page only holds the weekly a-goal; the reset is still the challenge's
+	assert.Equal(t, map[string]resetFields{"first-challenge": want}, list("/v1/challenges?limit=1"))
+
+	w := getChallenge(handler, "first-challenge")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var detail struct {
+		Challenge resetFields `json:"challenge"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	assert.Equal(t, want, detail.Challenge)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_ServeChallenge_NotFound(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newPagedTestHandler(t, mockRepo, nil)
+
+	w := getChallenge(handler, "unknown")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_ChallengeRollouts(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newPagedTestHandler(t, mockRepo, nil)
+	handler.SetChallengeRollouts(service.ChallengeRollouts{"second-challenge": 0, "first-challenge": 100})
+
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	assert.Equal(t, []string{"c-goal", "a-goal"}, getGoalIDs(t, handler, "/v1/challenges"))
+
+	w := getChallenge(handler, "second-challenge")
+	assert.Equal(t, http.StatusNotFound, w.Code, "a challenge not rolled out to the user is not revealed")
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_ChallengeRollouts_Paged(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newPagedTestHandler(t, mockRepo, mockQueries)
+	handler.SetChallengeRollouts(service.ChallengeRollouts{"second-challenge": 0})
+
+	// The page holds a-goal and b-goal; b-goal's challenge is dropped after the cursor is taken
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"a-goal", "b-goal"}).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	assert.Equal(t, []string{"a-goal"}, getGoalIDs(t, handler, "/v1/challenges?limit=2"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_ServeChallenge_DatabaseError(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newPagedTestHandler(t, mockRepo, nil)
+
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "second-challenge", false).
+		Return(nil, errors.New("connection refused"))
+
+	w := getChallenge(handler, "second-challenge")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOptimizedChallengesHandler_ServeChallenge_HiddenGoal(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newHiddenGoalTestHandler(t, mockRepo, nil)
+
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "quest", false).Return([]*commonDomain.UserGoalProgress{}, nil).Once()
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", []string{"intro"}).Return([]*commonDomain.UserGoalProgress{}, nil).Once()
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "quest", false).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Progress: 1, Status: commonDomain.GoalStatusClaimed},
+	}, nil).Once()
+
+	goalIDs := func() []string {
+		w := getChallenge(handler, "quest")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp challengeDetailResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var ids []string
+		for _, goal := range resp.Challenge.Goals {
+			ids = append(ids, goal.GoalID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"intro"}, goalIDs())
+	assert.Equal(t, []string{"intro", "secret"}, goalIDs(), "hidden goal is appended once its prerequisite is claimed")
+	mockRepo.AssertExpectations(t)
+}
+
+// newDetailBenchmark builds a challenge with 20 goals, half with progress, and a
+// handler serving it from a warmed serialization cache.
+func newDetailBenchmark(b *testing.B) (*OptimizedChallengesHandler, *commonDomain.Challenge, *mocks.GoalRepository) {
+	b.Helper()
+
+	challenge := &commonDomain.Challenge{ID: "season", Name: "Season", Description: "Season pass goals"}
+	var progress []*commonDomain.UserGoalProgress
+	for i := 0; i < 20; i++ {
+		goalID := fmt.Sprintf("goal-%02d", i)
+		challenge.Goals = append(challenge.Goals, &commonDomain.Goal{
+			ID:          goalID,
+			ChallengeID: challenge.ID,
+			Name:        "Goal " + goalID,
+			Description: "Reach the target",
+			EventSource: commonDomain.EventSourceStatistic,
+			Requirement: commonDomain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 100, ProgressMode: commonDomain.ProgressModeAbsolute},
+			Reward:      commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		})
+		if i%2 == 0 {
+			progress = append(progress, &commonDomain.UserGoalProgress{
+				UserID: "test-user", GoalID: goalID, ChallengeID: challenge.ID, Progress: i * 5, Status: commonDomain.GoalStatusInProgress,
+			})
+		}
+	}
+
+	challenges := []*commonDomain.Challenge{challenge}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(b, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(b, serCache.WarmUp(pbChallenges))
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", challenge.ID, false).Return(progress, nil)
+
+	logrus.SetLevel(logrus.WarnLevel)
+	b.Cleanup(func() { logrus.SetLevel(logrus.InfoLevel) })
+
+	return NewOptimizedChallengesHandler(goalCache, mockRepo, nil, serCache, "test-namespace", false, nil), challenge, mockRepo
+}
+
+// BenchmarkServeChallenge_Fragments measures GET /v1/challenges/{challenge_id}
+// assembled from pre-serialized fragments.
+func BenchmarkServeChallenge_Fragments(b *testing.B) {
+	handler, challenge, _ := newDetailBenchmark(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if w := getChallenge(handler, challenge.ID); w.Code != http.StatusOK {
+			b.Fatalf("unexpected status %d", w.Code)
+		}
+	}
+}
+
+// BenchmarkServeChallenge_Protojson measures the same endpoint marshaled per
+// request, as the gRPC-Gateway route does without the fragment path.
+func BenchmarkServeChallenge_Protojson(b *testing.B) {
+	handler, challenge, mockRepo := newDetailBenchmark(b)
+	marshalDetail := func(w http.ResponseWriter, r *http.Request) {
+		rows, err := mockRepo.GetChallengeProgress(r.Context(), "test-user", r.PathValue("challenge_id"), false)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		progressMap := make(map[string]*commonDomain.UserGoalProgress, len(rows))
+		for _, row := range rows {
+			progressMap[row.GoalID] = row
+		}
+		displayMap := handler.buildDisplayMap(handler.goalView(), progressMap, time.Now().UTC())
+		protoChallenge, err := mapper.ChallengeToProto(challenge, displayMap, nil, time.Now().UTC())
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		body, err := protojson.Marshal(&pb.GetChallengeResponse{Challenge: protoChallenge})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/challenges/"+challenge.ID, nil)
+		req.SetPathValue("challenge_id", challenge.ID)
+		w := httptest.NewRecorder()
+		marshalDetail(w, req)
+		if w.Code != http.StatusOK {
+			b.Fatalf("unexpected status %d", w.Code)
+		}
+	}
+}
+
+// newTargetOverrideTestHandler builds a handler for one "wins" goal targeting 5,
+// overridden to 1 for the "new_player" segment and to 8 for "veteran".
+func newTargetOverrideTestHandler(t *testing.T, mockRepo *mocks.GoalRepository) *OptimizedChallengesHandler {
+	t.Helper()
+
+	challenges := []*commonDomain.Challenge{{
+		ID:   "starter",
+		Name: "Starter",
+		Goals: []*commonDomain.Goal{{
+			ID:          "wins",
+			ChallengeID: "starter",
+			Name:        "Win matches",
+			EventSource: commonDomain.EventSourceStatistic,
+			Requirement: commonDomain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 5, ProgressMode: commonDomain.ProgressModeAbsolute},
+			Reward:      commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		}},
+	}}
+	overrides := service.TargetOverrides{"wins": {"new_player": 1, "veteran": 8}}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	serCache.SetTargetOverrides(overrides)
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, nil, serCache, "test-namespace", false, nil)
+	handler.SetTargetOverrides(overrides)
+	return handler
+}
+
+func TestOptimizedChallengesHandler_TargetOverridesBySegment(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "wins", ChallengeID: "starter", Progress: 2, Status: commonDomain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+
+	resolver := common.HeaderSegmentResolver{Header: "X-Segment"}
+	type goalResponse struct {
+		Status      string `json:"status"`
+		Requirement struct {
+			TargetValue int `json:"targetValue"`
+		} `json:"requirement"`
+	}
+	get := func(segment string) goalResponse {
+		req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		req.Header.Set("X-Segment", segment)
+		w := httptest.NewRecorder()
+		common.SegmentHTTPMiddleware(resolver, handler).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Challenges []struct {
+				Goals []goalResponse `json:"goals"`
+			} `json:"challenges"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Challenges, 1)
+		require.Len(t, resp.Challenges[0].Goals, 1)
+		return resp.Challenges[0].Goals[0]
+	}
+
+	// The same row: 2 wins complete the new player's goal, not the default one
+	newPlayer := get("new_player")
+	assert.Equal(t, 1, newPlayer.Requirement.TargetValue)
+	assert.Equal(t, string(commonDomain.GoalStatusCompleted), newPlayer.Status)
+
+	veteran := get("veteran")
+	assert.Equal(t, 8, veteran.Requirement.TargetValue)
+	assert.Equal(t, string(commonDomain.GoalStatusInProgress), veteran.Status)
+
+	unsegmented := get("")
+	assert.Equal(t, 5, unsegmented.Requirement.TargetValue)
+	assert.Equal(t, string(commonDomain.GoalStatusInProgress), unsegmented.Status)
+}
+
+func TestOptimizedChallengesHandler_GoalSteps(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	stepProgress := new(mocks.StepProgressRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	handler.SetGoalSteps(service.GoalSteps{"wins": {
+		{StatCode: "wins", Operator: ">=", TargetValue: 5},
+		{StatCode: "damage", Operator: ">=", TargetValue: 1000},
+	}}, stepProgress)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress(nil), nil)
+	stepProgress.On("GetStepProgress", mock.Anything, "test-user", []string{"wins"}).Return(map[string][]int{"wins": {5, 200}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		Challenges []struct {
+			Goals []struct {
+				Steps []struct {
+					StatCode    string `json:"statCode"`
+					TargetValue int    `json:"targetValue"`
+					Progress    int    `json:"progress"`
+					Completed   bool   `json:"completed"`
+				} `json:"steps"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	steps := resp.Challenges[0].Goals[0].Steps
+	require.Len(t, steps, 2)
+	assert.True(t, steps[0].Completed)
+	assert.Equal(t, "damage", steps[1].StatCode)
+	assert.Equal(t, 200, steps[1].Progress)
+	assert.False(t, steps[1].Completed)
+}
+
+func TestOptimizedChallengesHandler_GoalStepsError(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	stepProgress := new(mocks.StepProgressRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	handler.SetGoalSteps(service.GoalSteps{"wins": {
+		{StatCode: "wins", Operator: ">=", TargetValue: 5},
+		{StatCode: "damage", Operator: ">=", TargetValue: 1000},
+	}}, stepProgress)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress(nil), nil)
+	stepProgress.On("GetStepProgress", mock.Anything, "test-user", []string{"wins"}).Return(nil, errors.New("db down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestOptimizedChallengesHandler_IncludeBlockers(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "wins", ChallengeID: "starter", Progress: 2, Status: commonDomain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+
+	resolver := common.HeaderSegmentResolver{Header: "X-Segment"}
+	get := func(path, segment string) []string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		req.Header.Set("X-Segment", segment)
+		w := httptest.NewRecorder()
+		common.SegmentHTTPMiddleware(resolver, handler).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Challenges []struct {
+				Goals []struct {
+					ClaimBlockers []string `json:"claimBlockers"`
+				} `json:"goals"`
+			} `json:"challenges"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Challenges, 1)
+		require.Len(t, resp.Challenges[0].Goals, 1)
+		return resp.Challenges[0].Goals[0].ClaimBlockers
+	}
+
+	assert.Nil(t, get("/v1/challenges", ""), "blockers are only reported on request")
+	assert.Equal(t, []string{"not_completed"}, get("/v1/challenges?include_blockers=true", ""))
+	assert.Empty(t, get("/v1/challenges?include_blockers=true", "new_player"), "the segment's target is reached")
+	assert.Equal(t, []string{"not_completed"}, get("/v1/challenges?include_blockers=true&limit=10", ""))
+}
+
+func TestOptimizedChallengesHandler_DebugHeaders(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	get := func() http.Header {
+		req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header()
+	}
+
+	assert.Empty(t, get().Get(common.DebugHeaderUserID), "no debug headers without debug metadata")
+
+	handler.SetDebugMetadata(common.NewDebugMetadata(false, nil))
+	assert.Empty(t, get().Get(common.DebugHeaderUserID), "no debug headers while disabled")
+
+	handler.SetDebugMetadata(common.NewDebugMetadata(true, func() string { return "abc123def456" }))
+	header := get()
+	assert.Equal(t, "test-user", header.Get(common.DebugHeaderUserID))
+	assert.Equal(t, "test-namespace", header.Get(common.DebugHeaderNamespace))
+	assert.Equal(t, "abc123def456", header.Get(common.DebugHeaderConfigVersion))
+	assert.Equal(t, "/v1/challenges", header.Get(common.DebugHeaderHandler))
+}
+
+// serverTimingPattern matches the Server-Timing header of the optimized
+// challenge handlers.
+var serverTimingPattern = regexp.MustCompile(`^auth;dur=\d+\.\d{3}, db;dur=\d+\.\d{3}, assembly;dur=\d+\.\d{3}, encode;dur=\d+\.\d{3}, queries;desc="\d+", total;dur=\d+\.\d{3}$`)
+
+func TestOptimizedChallengesHandler_DebugTiming(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	handler.progressQueries = mockQueries
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "starter", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", mock.Anything).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	serverTiming := func(path, timing, authorization string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		if timing != "" {
+			req.Header.Set(common.DebugTimingHeader, timing)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		if strings.HasPrefix(path, "/v1/challenges/") {
+			req.SetPathValue("challenge_id", strings.TrimPrefix(path, "/v1/challenges/"))
+			handler.ServeChallenge(w, req)
+		} else {
+			handler.ServeHTTP(w, req)
+		}
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get(common.ServerTimingHeader)
+	}
+
+	assert.Empty(t, serverTiming("/v1/challenges", "1", ""), "no timings without debug timing")
+
+	handler.SetDebugTiming(common.NewDebugTiming("test-namespace"))
+	assert.Regexp(t, serverTimingPattern, serverTiming("/v1/challenges", "1", ""))
+	assert.Regexp(t, serverTimingPattern, serverTiming("/v1/challenges?limit=10", "1", ""))
+	assert.Regexp(t, serverTimingPattern, serverTiming("/v1/challenges/starter", "1", ""))
+	assert.Empty(t, serverTiming("/v1/challenges", "", ""), "the request did not ask")
+
+	// Other namespaces need a token with the debug permission
+	validator := new(MockTokenValidator)
+	validator.On("Validate", "admin-token", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	validator.On("Validate", "player-token", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+	handler.tokenValidator = validator
+	handler.SetDebugTiming(common.NewDebugTiming("other"))
+	assert.Empty(t, serverTiming("/v1/challenges", "1", "Bearer player-token"))
+	assert.Regexp(t, serverTimingPattern, serverTiming("/v1/challenges", "1", "Bearer admin-token"))
+
+	permission := validator.Calls[0].Arguments.Get(1).(*iam.Permission)
+	assert.Equal(t, "ADMIN:NAMESPACE:{namespace}:CHALLENGE:DEBUG", permission.Resource)
+}
+
+func TestOptimizedChallengesHandler_QueryBudget(t *testing.T) {
+	var challenges []*commonDomain.Challenge
+	var progress []*commonDomain.UserGoalProgress
+	for c := range 4 {
+		challenge := &commonDomain.Challenge{ID: fmt.Sprintf("challenge-%d", c), Name: "Challenge"}
+		for g := range 5 {
+			goal := &commonDomain.Goal{
+				ID:          fmt.Sprintf("goal-%d-%d", c, g),
+				ChallengeID: challenge.ID,
+				Name:        "Goal",
+				EventSource: commonDomain.EventSourceStatistic,
+				Requirement: commonDomain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 5, ProgressMode: commonDomain.ProgressModeAbsolute},
+				Reward:      commonDomain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			}
+			challenge.Goals = append(challenge.Goals, goal)
+			progress = append(progress, &commonDomain.UserGoalProgress{
+				UserID: "test-user", GoalID: goal.ID, ChallengeID: challenge.ID, Progress: 2, Status: commonDomain.GoalStatusInProgress, IsActive: true,
+			})
+		}
+		challenges = append(challenges, challenge)
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return(progress, nil)
+	repo, ctx, queries := testutil.CountQueries(context.Background(), mockRepo)
+	handler := NewOptimizedChallengesHandler(goalCache, repo, nil, serCache, "test-namespace", false, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/challenges", nil).WithContext(ctx)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	testutil.AssertMaxQueries(t, queries, testutil.MaxGetUserChallengesQueries, "GET /v1/challenges")
+}
+
+func TestOptimizedChallengesHandler_StrongConsistency(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	handler := newTargetOverrideTestHandler(t, mockRepo)
+	handler.progressQueries = queries
+	takenAt := time.Date(2025, 6, 1, 12, 0, 0, 500_000_000, time.UTC)
+	rows := []*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "wins", ChallengeID: "starter", Progress: 5, Status: commonDomain.GoalStatusCompleted, IsActive: true},
+	}
+	queries.On("GetUserProgressSnapshot", mock.Anything, "test-user", "", false).
+		Return(&repository.ProgressSnapshot{Rows: rows, TakenAt: takenAt}, nil)
+	queries.On("GetUserProgressSnapshot", mock.Anything, "test-user", "starter", false).
+		Return(&repository.ProgressSnapshot{Rows: rows, TakenAt: takenAt}, nil)
+	// The row was reset by a rotation and completed again
+	firstCompleted := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	queries.On("GetFirstCompletions", mock.Anything, "test-user", []string{"wins"}).
+		Return(map[string]time.Time{"wins": firstCompleted}, nil)
+
+	serve := func(target string, serve func(http.ResponseWriter, *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		req.SetPathValue("challenge_id", "starter")
+		w := httptest.NewRecorder()
+		serve(w, req)
+		return w
+	}
+
+	w := serve("/v1/challenges?consistency=strong", handler.ServeHTTP)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Challenges []struct {
+			Goals []struct {
+				Status           string `json:"status"`
+				FirstCompletedAt string `json:"firstCompletedAt"`
+			} `json:"goals"`
+		} `json:"challenges"`
+		SnapshotAt string `json:"snapshotAt"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, "2025-06-01T12:00:01Z", list.SnapshotAt)
+	require.Len(t, list.Challenges, 1)
+	assert.Equal(t, string(commonDomain.GoalStatusCompleted), list.Challenges[0].Goals[0].Status)
+	assert.Equal(t, "2025-05-01T08:00:00Z", list.Challenges[0].Goals[0].FirstCompletedAt)
+
+	w = serve("/v1/challenges/starter?consistency=strong", handler.ServeChallenge)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var one struct {
+		Challenge  map[string]any `json:"challenge"`
+		SnapshotAt string         `json:"snapshotAt"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &one))
+	assert.Equal(t, "starter", one.Challenge["challengeId"])
+	assert.Equal(t, "2025-06-01T12:00:01Z", one.SnapshotAt)
+
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOptimizedChallengesHandler_StrongConsistencyRejected(t *testing.T) {
+	handler := newTargetOverrideTestHandler(t, new(mocks.GoalRepository))
+	handler.progressQueries = new(mocks.ProgressQueryRepository)
+
+	for _, target := range []string{
+		"/v1/challenges?consistency=eventual",
+		"/v1/challenges?consistency=strong&limit=10",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestOptimizedChallengesHandler_GoalSnapshots(t *testing.T) {
+	challenges := createHiddenGoalChallenges()
+	snapshots := cache.NewGoalSnapshots(commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default()))
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	progress := []*commonDomain.UserGoalProgress{
+		{UserID: "test-user", GoalID: "intro", ChallengeID: "quest", Progress: 1, Status: commonDomain.GoalStatusCompleted},
+	}
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return(progress, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", "quest", false).Return(progress, nil)
+
+	// Every lookup goes to the view; the goal cache mock has no expectations
+	goalCache := new(mocks.GoalCache)
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, nil, serCache, "test-namespace", false, nil)
+	handler.SetGoalSnapshots(snapshots)
+
+	assert.Equal(t, []string{"intro", "secret"}, getGoalIDs(t, handler, "/v1/challenges"))
+	w := getChallenge(handler, "quest")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	goalCache.AssertExpectations(t)
+	assert.Empty(t, goalCache.Calls)
+}
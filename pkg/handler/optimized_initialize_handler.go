@@ -11,6 +11,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/repository"
 	"extend-challenge-service/pkg/service"
 
 	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth/validator"
@@ -38,6 +43,18 @@ type OptimizedInitializeHandler struct {
 	namespace      string
 	authEnabled    bool
 	tokenValidator validator.AuthTokenValidator
+	activationSrc  repository.ActivationSourceRepository
+	unclaimed      *service.UnclaimedCounts
+	targets        service.TargetOverrides
+	events         repository.EventOutboxRepository
+	inserter       repository.ProgressInsertRepository
+	snapshots      *cache.GoalSnapshots
+	autoSelect     *service.AutoRandomSelector
+	rollouts       service.ChallengeRollouts
+	logger         logrus.FieldLogger
+	debug          *common.DebugMetadata
+	timing         *common.DebugTiming
+	strictBodies   bool
 }
 
 // NewOptimizedInitializeHandler creates a new optimized initialize handler.
@@ -64,9 +81,96 @@ func NewOptimizedInitializeHandler(
 		namespace:      namespace,
 		authEnabled:    authEnabled,
 		tokenValidator: tokenValidator,
+		logger:         logrus.StandardLogger(),
 	}
 }
 
+// SetActivationSources sets the repository that goal activation sources are
+// recorded in. Without it, activationSource is empty for every goal.
+func (h *OptimizedInitializeHandler) SetActivationSources(sources repository.ActivationSourceRepository) {
+	h.activationSrc = sources
+}
+
+// SetUnclaimedCounts sets the unclaimed counts cache that initialization
+// invalidates, shared with the gRPC server's GetUnclaimedCount.
+func (h *OptimizedInitializeHandler) SetUnclaimedCounts(unclaimed *service.UnclaimedCounts) {
+	h.unclaimed = unclaimed
+}
+
+// SetTargetOverrides sets the per-segment goal targets. The segment of each
+// request is resolved by common.SegmentHTTPMiddleware.
+func (h *OptimizedInitializeHandler) SetTargetOverrides(overrides service.TargetOverrides) {
+	h.targets = overrides
+}
+
+// SetProgressInserter sets the repository default goal rows are created with, so
+// rows that already exist or violate a constraint do not fail initialization.
+// Without it, the insert is all-or-nothing (BulkInsert).
+func (h *OptimizedInitializeHandler) SetProgressInserter(inserter repository.ProgressInsertRepository) {
+	h.inserter = inserter
+}
+
+// SetEventOutbox sets the outbox player.initialized events are written to.
+// Without it, initialization publishes no event.
+func (h *OptimizedInitializeHandler) SetEventOutbox(outbox repository.EventOutboxRepository) {
+	h.events = outbox
+}
+
+// SetAutoRandomSelector sets the random selections run for new players of
+// challenges with autoRandomSelect. Without it, those challenges are left to the
+// selection endpoints.
+func (h *OptimizedInitializeHandler) SetAutoRandomSelector(selector *service.AutoRandomSelector) {
+	h.autoSelect = selector
+}
+
+// SetChallengeRollouts sets the challenges available to a percentage of users
+// only. Their default goals are not assigned to the other users.
+func (h *OptimizedInitializeHandler) SetChallengeRollouts(rollouts service.ChallengeRollouts) {
+	h.rollouts = rollouts
+}
+
+// SetGoalSnapshots makes each request look goals up in the current snapshot view
+// instead of the goal cache. Without it, every lookup goes to the goal cache.
+func (h *OptimizedInitializeHandler) SetGoalSnapshots(snapshots *cache.GoalSnapshots) {
+	h.snapshots = snapshots
+}
+
+// goalView returns the goal lookups to use for one request: the current
+// snapshot view if snapshots are set, otherwise the goal cache.
+func (h *OptimizedInitializeHandler) goalView() commonCache.GoalCache {
+	if h.snapshots == nil {
+		return h.goalCache
+	}
+	return h.snapshots.View()
+}
+
+// SetLogger sets the logger passed to service.InitializePlayer. Without it, the
+// standard logrus logger is used.
+func (h *OptimizedInitializeHandler) SetLogger(logger logrus.FieldLogger) {
+	h.logger = logger
+}
+
+// SetDebugMetadata sets the X-Debug-* headers sent with each response once the
+// user is resolved. Without it, no debug headers are sent.
+func (h *OptimizedInitializeHandler) SetDebugMetadata(debug *common.DebugMetadata) {
+	h.debug = debug
+}
+
+// SetDebugTiming sets which requests sending X-Debug-Timing get the auth, db
+// and assembly phases back in a Server-Timing header. The body is encoded
+// while it is written, after the header, so it has no encode phase. Without
+// it, the header is ignored.
+func (h *OptimizedInitializeHandler) SetDebugTiming(timing *common.DebugTiming) {
+	h.timing = timing
+}
+
+// SetStrictRequestBodies makes a request body with fields InitializeRequest
+// does not have fail with 400 InvalidArgument listing them (REQUEST_BODY_DECODING
+// strict). Without it, unknown fields are logged and ignored.
+func (h *OptimizedInitializeHandler) SetStrictRequestBodies(strict bool) {
+	h.strictBodies = strict
+}
+
 // ServeHTTP handles POST /v1/challenges/initialize with optimized direct JSON encoding.
 //
 // Request:
@@ -76,6 +180,7 @@ func NewOptimizedInitializeHandler(
 //
 // Response:
 //   - 200 OK: JSON object with assigned goals
+//   - 400 Bad Request: Malformed body, or unknown fields with strict request bodies
 //   - 401 Unauthorized: Invalid or missing JWT token
 //   - 500 Internal Server Error: Database or cache errors
 //
@@ -90,29 +195,50 @@ func (h *OptimizedInitializeHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Decode the body before authentication, as the gRPC-Gateway does
+	unknownFields, err := decodeRequestBody(r, &pb.InitializeRequest{}, h.strictBodies)
+	if err != nil {
+		writeInvalidRequestBody(w, err)
+		return
+	}
+	if len(unknownFields) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"unknown_fields": unknownFields,
+			"client_ip":      common.GetClientIPFromContext(r.Context()),
+		}).Warn("Ignoring unknown request body fields")
+	}
+
+	timings := h.timing.Start(r.Header.Get(common.DebugTimingHeader), h.namespace, r.Header.Get("Authorization"), h.tokenValidator)
+
 	// Extract user ID from JWT token or test header
 	userID, err := h.extractUserID(r)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to extract user ID")
+		logrus.WithError(err).WithField("client_ip", common.GetClientIPFromContext(r.Context())).Error("Failed to extract user ID")
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
+	h.debug.SetHTTPHeaders(w, r, userID, h.namespace)
+	timings.Mark("auth")
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":   userID,
+		"client_ip": common.GetClientIPFromContext(r.Context()),
 		"namespace": h.namespace,
 		"handler":   "optimized",
 	}).Info("Initializing player (optimized)")
 
-	// Call business logic (same as gRPC handler)
-	ctx := r.Context()
+	// Call business logic (same as gRPC handler), counting its queries in timings
+	ctx := common.WithTimings(r.Context(), timings)
 	result, err := service.InitializePlayer(
-		ctx,
+		service.WithChallengeRollouts(service.WithAutoRandomSelector(ctx, h.autoSelect), h.rollouts),
 		userID,
 		h.namespace,
-		h.goalCache,
+		h.goalView(),
 		h.repo,
+		h.inserter,
+		h.logger,
 	)
+	h.unclaimed.Invalidate(userID)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":   userID,
@@ -122,9 +248,15 @@ func (h *OptimizedInitializeHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	service.ResolveInitializeActivationSources(ctx, h.activationSrc, userID, result, h.logger)
+	service.EnqueuePlayerInitialized(ctx, h.events, h.namespace, userID, result, h.logger)
+	timings.Mark("db")
+	h.targets.For(common.GetSegmentFromContext(ctx)).ApplyToAssignedGoals(result.AssignedGoals)
 
 	// Convert to response DTO (optimized structure for JSON encoding)
 	response := toInitializeResponseDTO(result)
+	timings.Mark("assembly")
+	timings.WriteHeader(w.Header(), r.URL.Path, userID)
 
 	// Encode directly to JSON (no Protobuf conversion!)
 	// This is 15-30x faster than Protobuf → JSON marshaling
@@ -219,18 +351,19 @@ type InitializeResponseDTO struct {
 // AssignedGoalDTO represents a single assigned goal in the response.
 // Field names use camelCase to match Protobuf JSON output for API compatibility.
 type AssignedGoalDTO struct {
-	ChallengeID string          `json:"challengeId"`
-	GoalID      string          `json:"goalId"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	IsActive    bool            `json:"isActive"`
-	AssignedAt  string          `json:"assignedAt"` // RFC3339 format
-	ExpiresAt   string          `json:"expiresAt"`  // RFC3339 format or empty
-	Progress    int32           `json:"progress"`
-	Target      int32           `json:"target"`
-	Status      string          `json:"status"`
-	Requirement *RequirementDTO `json:"requirement,omitempty"`
-	Reward      *RewardDTO      `json:"reward,omitempty"`
+	ChallengeID      string          `json:"challengeId"`
+	GoalID           string          `json:"goalId"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	IsActive         bool            `json:"isActive"`
+	AssignedAt       *string         `json:"assignedAt"` // RFC3339 UTC (mapper.FormatTimestamp) or null
+	ExpiresAt        *string         `json:"expiresAt"`  // RFC3339 UTC (mapper.FormatTimestamp) or null
+	Progress         int32           `json:"progress"`
+	Target           int32           `json:"target"`
+	Status           string          `json:"status"`
+	Requirement      *RequirementDTO `json:"requirement,omitempty"`
+	Reward           *RewardDTO      `json:"reward,omitempty"`
+	ActivationSource string          `json:"activationSource"` // default, random, manual, admin, or empty when not recorded
 }
 
 // RequirementDTO represents a goal requirement.
@@ -276,24 +409,20 @@ func toAssignedGoalDTO(goal *service.AssignedGoal) *AssignedGoalDTO {
 	}
 
 	dto := &AssignedGoalDTO{
-		ChallengeID: goal.ChallengeID,
-		GoalID:      goal.GoalID,
-		Name:        goal.Name,
-		Description: goal.Description,
-		IsActive:    goal.IsActive,
-		Progress:    int32(goal.Progress), //nolint:gosec // Progress values are bounded by target values, no overflow risk
-		Target:      int32(goal.Target),   //nolint:gosec // Target values are reasonable (< 1M typically), no overflow risk
-		Status:      goal.Status,
-	}
-
-	// Format timestamps
-	if goal.AssignedAt != nil {
-		dto.AssignedAt = goal.AssignedAt.Format("2006-01-02T15:04:05Z07:00")
+		ChallengeID:      goal.ChallengeID,
+		GoalID:           goal.GoalID,
+		Name:             goal.Name,
+		Description:      goal.Description,
+		IsActive:         goal.IsActive,
+		Progress:         int32(goal.Progress), //nolint:gosec // Progress values are bounded by target values, no overflow risk
+		Target:           int32(goal.Target),   //nolint:gosec // Target values are reasonable (< 1M typically), no overflow risk
+		Status:           goal.Status,
+		ActivationSource: goal.ActivationSource,
 	}
 
-	if goal.ExpiresAt != nil {
-		dto.ExpiresAt = goal.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
-	}
+	// Format timestamps (RFC3339 UTC, null when unset)
+	dto.AssignedAt = mapper.FormatNullableTimestamp(goal.AssignedAt)
+	dto.ExpiresAt = mapper.FormatNullableTimestamp(goal.ExpiresAt)
 
 	// Convert requirement
 	dto.Requirement = &RequirementDTO{
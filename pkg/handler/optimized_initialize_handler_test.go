@@ -5,24 +5,31 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"extend-challenge-service/pkg/common"
 	"extend-challenge-service/pkg/service"
+	"extend-challenge-service/pkg/testutil"
+	"extend-challenge-service/pkg/testutil/mocks"
 
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
 )
 
 func TestOptimizedInitializeHandler_ServeHTTP_Success(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create handler with auth disabled for simplicity
 	handler := NewOptimizedInitializeHandler(
@@ -94,8 +101,8 @@ func TestOptimizedInitializeHandler_ServeHTTP_Success(t *testing.T) {
 
 func TestOptimizedInitializeHandler_ServeHTTP_AlreadyInitialized(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create handler with auth disabled
 	handler := NewOptimizedInitializeHandler(
@@ -146,8 +153,8 @@ func TestOptimizedInitializeHandler_ServeHTTP_AlreadyInitialized(t *testing.T) {
 
 func TestOptimizedInitializeHandler_ServeHTTP_NoDefaultGoals(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create handler
 	handler := NewOptimizedInitializeHandler(
@@ -189,10 +196,65 @@ func TestOptimizedInitializeHandler_ServeHTTP_NoDefaultGoals(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestOptimizedInitializeHandler_ServeHTTP_DebugTiming(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*commonDomain.Goal{})
+	handler := NewOptimizedInitializeHandler(mockCache, new(mocks.GoalRepository), "test-namespace", false, nil)
+
+	serverTiming := func(timing string) string {
+		req := httptest.NewRequest(http.MethodPost, "/v1/challenges/initialize", nil)
+		req.Header.Set("x-mock-user-id", "test-user")
+		req.Header.Set(common.DebugTimingHeader, timing)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		return rr.Header().Get(common.ServerTimingHeader)
+	}
+
+	assert.Empty(t, serverTiming("1"), "no timings without debug timing")
+
+	handler.SetDebugTiming(common.NewDebugTiming("other"))
+	assert.Empty(t, serverTiming("1"), "namespace is not allowed")
+
+	handler.SetDebugTiming(common.NewDebugTiming("test-namespace"))
+	assert.Regexp(t, `^auth;dur=\d+\.\d{3}, db;dur=\d+\.\d{3}, assembly;dur=\d+\.\d{3}, queries;desc="\d+", total;dur=\d+\.\d{3}$`, serverTiming("1"))
+	assert.Empty(t, serverTiming(""), "the request did not ask")
+}
+
+func TestOptimizedInitializeHandler_ServeHTTP_QueryBudget(t *testing.T) {
+	var defaultGoals []*commonDomain.Goal
+	mockCache := new(mocks.GoalCache)
+	for i := range 20 {
+		goal := &commonDomain.Goal{
+			ID:              fmt.Sprintf("goal-%d", i),
+			ChallengeID:     "challenge-1",
+			Name:            "Goal",
+			DefaultAssigned: true,
+			Requirement:     commonDomain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 5},
+		}
+		defaultGoals = append(defaultGoals, goal)
+		mockCache.On("GetGoalByID", goal.ID).Return(goal).Maybe()
+	}
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "test-user").Return(0, nil)
+	mockRepo.On("BulkInsert", mock.Anything, mock.Anything).Return(nil)
+	repo, ctx, queries := testutil.CountQueries(context.Background(), mockRepo)
+	handler := NewOptimizedInitializeHandler(mockCache, repo, "test-namespace", false, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/challenges/initialize", nil).WithContext(ctx)
+	req.Header.Set("x-mock-user-id", "test-user")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	testutil.AssertMaxQueries(t, queries, testutil.MaxFirstLoginQueries, "POST /v1/challenges/initialize (first login)")
+}
+
 func TestOptimizedInitializeHandler_ServeHTTP_MethodNotAllowed(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create handler
 	handler := NewOptimizedInitializeHandler(
@@ -217,10 +279,47 @@ func TestOptimizedInitializeHandler_ServeHTTP_MethodNotAllowed(t *testing.T) {
 	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
 }
 
+func TestOptimizedInitializeHandler_ServeHTTP_RequestBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		strict   bool
+		wantCode int
+	}{
+		{"empty body", "", true, http.StatusOK},
+		{"empty object", "{}", true, http.StatusOK},
+		{"typo rejected when strict", `{"userId": "u1"}`, true, http.StatusBadRequest},
+		{"typo ignored when lenient", `{"userId": "u1"}`, false, http.StatusOK},
+		{"malformed rejected when lenient", `{"userId"`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(mocks.GoalCache)
+			mockRepo := new(mocks.GoalRepository)
+			mockCache.On("GetGoalsWithDefaultAssigned").Return([]*commonDomain.Goal{})
+
+			handler := NewOptimizedInitializeHandler(mockCache, mockRepo, "test-namespace", false, nil)
+			handler.SetStrictRequestBodies(tt.strict)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/challenges/initialize", strings.NewReader(tt.body))
+			req.Header.Set("x-mock-user-id", "test-user")
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantCode == http.StatusBadRequest {
+				mockCache.AssertNotCalled(t, "GetGoalsWithDefaultAssigned")
+			}
+		})
+	}
+}
+
 func TestOptimizedInitializeHandler_ServeHTTP_DatabaseError(t *testing.T) {
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create handler
 	handler := NewOptimizedInitializeHandler(
@@ -374,6 +473,46 @@ func TestToAssignedGoalDTO_WithTimestamps(t *testing.T) {
 	assert.Equal(t, "in_progress", dto.Status)
 }
 
+func TestToAssignedGoalDTO_NonUTCTimestamps(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	original := time.Local
+	time.Local = berlin
+	defer func() { time.Local = original }()
+
+	// Berlin switches from CEST (+02:00) to CET (+01:00) on 2025-10-26
+	assignedAt := time.Date(2025, 10, 25, 12, 0, 0, 0, berlin)
+	expiresAt := time.Date(2025, 10, 27, 12, 0, 0, 0, berlin)
+
+	dto := toAssignedGoalDTO(&service.AssignedGoal{
+		GoalID:     "test-goal",
+		AssignedAt: &assignedAt,
+		ExpiresAt:  &expiresAt,
+	})
+
+	require.NotNil(t, dto)
+	require.NotNil(t, dto.AssignedAt)
+	require.NotNil(t, dto.ExpiresAt)
+	assert.Equal(t, "2025-10-25T10:00:00Z", *dto.AssignedAt)
+	assert.Equal(t, "2025-10-27T11:00:00Z", *dto.ExpiresAt)
+
+	parsed, err := time.Parse(time.RFC3339, *dto.ExpiresAt)
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(parsed))
+}
+
+func TestToAssignedGoalDTO_NilTimestamps(t *testing.T) {
+	dto := toAssignedGoalDTO(&service.AssignedGoal{GoalID: "test-goal"})
+
+	require.NotNil(t, dto)
+	assert.Nil(t, dto.AssignedAt)
+	assert.Nil(t, dto.ExpiresAt)
+
+	encoded, err := json.Marshal(dto)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"assignedAt":null,"expiresAt":null`, "unset timestamps are null, as the gateway writes them")
+}
+
 func TestToAssignedGoalDTO_NilGoal(t *testing.T) {
 	dto := toAssignedGoalDTO(nil)
 	assert.Nil(t, dto)
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Values of REQUEST_BODY_DECODING.
+const (
+	RequestBodyStrict  = "strict"
+	RequestBodyLenient = "lenient"
+)
+
+// maxRequestBodyBytes bounds the request bodies the optimized handlers read.
+const maxRequestBodyBytes = 1 << 20
+
+// DefaultRequestBodyDecoding returns the REQUEST_BODY_DECODING default for
+// rewardMode (REWARD_CLIENT_MODE): lenient with the real reward client, so
+// deployed clients that send extra fields keep working, strict otherwise, so
+// a typo'd field fails during development instead of being ignored.
+func DefaultRequestBodyDecoding(rewardMode string) string {
+	if rewardMode == "real" {
+		return RequestBodyLenient
+	}
+	return RequestBodyStrict
+}
+
+// ParseRequestBodyDecoding parses "strict" or "lenient" and returns whether
+// unknown request body fields are rejected.
+func ParseRequestBodyDecoding(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case RequestBodyStrict:
+		return true, nil
+	case RequestBodyLenient:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid request body decoding %q (must be %q or %q)",
+			value, RequestBodyStrict, RequestBodyLenient)
+	}
+}
+
+// UnknownFieldsError is returned by decodeRequestBody in strict mode for a body
+// with fields the request message does not have.
+type UnknownFieldsError struct {
+	// Fields are the unknown top-level field names, sorted.
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields: " + strings.Join(e.Fields, ", ")
+}
+
+// decodeRequestBody decodes the JSON body of r into msg and returns the
+// top-level fields msg does not have. An empty body leaves msg unchanged.
+//
+// Fields are matched like the gRPC-Gateway matches them (protojson): by JSON
+// name or proto name, case-sensitively. json.Decoder.DisallowUnknownFields is
+// not used because it matches case-insensitively and rejects proto names.
+//
+// With strict, unknown fields fail with *UnknownFieldsError; otherwise they are
+// ignored, as the gateway ignores them.
+func decodeRequestBody(r *http.Request, msg proto.Message, strict bool) ([]string, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(body) > maxRequestBodyBytes {
+		return nil, fmt.Errorf("request body exceeds %d bytes", maxRequestBodyBytes)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, errors.New("request body must be a JSON object")
+	}
+
+	descriptor := msg.ProtoReflect().Descriptor().Fields()
+	var unknown []string
+	for name := range fields {
+		if descriptor.ByJSONName(name) == nil && descriptor.ByName(protoreflect.Name(name)) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+	if strict && len(unknown) > 0 {
+		return unknown, &UnknownFieldsError{Fields: unknown}
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, msg); err != nil {
+		return unknown, fmt.Errorf("invalid request body: %w", err)
+	}
+	return unknown, nil
+}
+
+// writeInvalidRequestBody writes err as a 400 InvalidArgument status in the
+// gRPC-Gateway's error format. An *UnknownFieldsError lists each field as a
+// google.rpc.BadRequest field violation.
+func writeInvalidRequestBody(w http.ResponseWriter, err error) {
+	st := status.New(codes.InvalidArgument, err.Error())
+	var unknownErr *UnknownFieldsError
+	if errors.As(err, &unknownErr) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(unknownErr.Fields))
+		for i, field := range unknownErr.Fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field, Description: "unknown field"}
+		}
+		if detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+			st = detailed
+		}
+	}
+
+	body, marshalErr := protojson.Marshal(st.Proto())
+	if marshalErr != nil {
+		logrus.WithError(marshalErr).Error("Failed to marshal request body error")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+func TestParseRequestBodyDecoding(t *testing.T) {
+	strict, err := ParseRequestBodyDecoding(" Strict ")
+	require.NoError(t, err)
+	assert.True(t, strict)
+
+	strict, err = ParseRequestBodyDecoding("lenient")
+	require.NoError(t, err)
+	assert.False(t, strict)
+
+	_, err = ParseRequestBodyDecoding("loose")
+	assert.Error(t, err)
+
+	assert.Equal(t, RequestBodyLenient, DefaultRequestBodyDecoding("real"))
+	assert.Equal(t, RequestBodyStrict, DefaultRequestBodyDecoding("mock"))
+}
+
+func TestDecodeRequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		strict      bool
+		wantUnknown []string
+		wantErr     string
+		want        *pb.BatchSelectRequest
+	}{
+		{
+			name: "JSON names",
+			body: `{"challengeId": "c1", "goalIds": ["g1"], "replaceExisting": true}`,
+			want: &pb.BatchSelectRequest{ChallengeId: "c1", GoalIds: []string{"g1"}, ReplaceExisting: true},
+		},
+		{
+			name:   "proto names",
+			body:   `{"challenge_id": "c1", "goal_ids": ["g1"]}`,
+			strict: true,
+			want:   &pb.BatchSelectRequest{ChallengeId: "c1", GoalIds: []string{"g1"}},
+		},
+		{
+			name:   "empty body",
+			body:   " ",
+			strict: true,
+			want:   &pb.BatchSelectRequest{},
+		},
+		{
+			name:        "typo rejected when strict",
+			body:        `{"challengeId": "c1", "goalIDs": ["g1"], "replace": true}`,
+			strict:      true,
+			wantUnknown: []string{"goalIDs", "replace"},
+			wantErr:     "unknown fields: goalIDs, replace",
+		},
+		{
+			name:        "wrong case rejected when strict",
+			body:        `{"ChallengeId": "c1"}`,
+			strict:      true,
+			wantUnknown: []string{"ChallengeId"},
+			wantErr:     "unknown fields: ChallengeId",
+		},
+		{
+			name:        "typo ignored when lenient",
+			body:        `{"challengeId": "c1", "goal_IDs": ["g1"]}`,
+			wantUnknown: []string{"goal_IDs"},
+			want:        &pb.BatchSelectRequest{ChallengeId: "c1"},
+		},
+		{
+			name:    "not an object",
+			body:    `["c1"]`,
+			wantErr: "request body must be a JSON object",
+		},
+		{
+			name:    "malformed",
+			body:    `{"challengeId": `,
+			wantErr: "request body must be a JSON object",
+		},
+		{
+			name:    "wrong type",
+			body:    `{"goalIds": "g1"}`,
+			wantErr: "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			msg := &pb.BatchSelectRequest{}
+
+			unknown, err := decodeRequestBody(req, msg, tt.strict)
+
+			assert.Equal(t, tt.wantUnknown, unknown)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.GetChallengeId(), msg.GetChallengeId())
+			assert.Equal(t, tt.want.GetGoalIds(), msg.GetGoalIds())
+			assert.Equal(t, tt.want.GetReplaceExisting(), msg.GetReplaceExisting())
+		})
+	}
+}
+
+func TestDecodeRequestBody_TooLarge(t *testing.T) {
+	body := `{"challengeId": "` + strings.Repeat("c", maxRequestBodyBytes) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	_, err := decodeRequestBody(req, &pb.BatchSelectRequest{}, false)
+
+	assert.ErrorContains(t, err, "request body exceeds")
+}
+
+func TestWriteInvalidRequestBody_UnknownFields(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	writeInvalidRequestBody(rr, &UnknownFieldsError{Fields: []string{"goalIDs", "replace"}})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Type            string `json:"@type"`
+			FieldViolations []struct {
+				Field       string `json:"field"`
+				Description string `json:"description"`
+			} `json:"fieldViolations"`
+		} `json:"details"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, 3, body.Code, "InvalidArgument")
+	assert.Equal(t, "unknown fields: goalIDs, replace", body.Message)
+	require.Len(t, body.Details, 1)
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", body.Details[0].Type)
+	require.Len(t, body.Details[0].FieldViolations, 2)
+	assert.Equal(t, "goalIDs", body.Details[0].FieldViolations[0].Field)
+	assert.Equal(t, "unknown field", body.Details[0].FieldViolations[0].Description)
+	assert.Equal(t, "replace", body.Details[0].FieldViolations[1].Field)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultChallengesResponseMaxBytes caps the unpaginated GET /v1/challenges response.
+const DefaultChallengesResponseMaxBytes = 2 << 20
+
+// ResponseSizeGuard keeps unpaginated GET /v1/challenges responses under a size
+// cap, since clients with little memory fail on multi-megabyte responses.
+//
+// The size is the static part of the response, summed from the pre-serialized
+// fragment sizes before any progress is loaded. Above the cap the request is
+// rejected with 413 unless it sets allow_large=true; paginated requests (limit)
+// are not guarded.
+type ResponseSizeGuard struct {
+	maxBytes int
+	serCache *cache.SerializedChallengeCache
+
+	rejected prometheus.Counter
+	overCap  prometheus.GaugeFunc
+}
+
+// NewResponseSizeGuard creates a guard with a cap of maxBytes, checked against
+// the fragments of serCache. A maxBytes of 0 disables the guard.
+func NewResponseSizeGuard(serCache *cache.SerializedChallengeCache, maxBytes int) *ResponseSizeGuard {
+	g := &ResponseSizeGuard{
+		maxBytes: maxBytes,
+		serCache: serCache,
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "challenges_response_too_large_total",
+			Help: "GET /v1/challenges requests rejected because the response is above CHALLENGES_RESPONSE_MAX_BYTES.",
+		}),
+	}
+	g.overCap = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "challenges_response_over_cap",
+		Help: "1 when the pre-serialized challenge config is above CHALLENGES_RESPONSE_MAX_BYTES, so unpaginated GET /v1/challenges requests are rejected.",
+	}, func() float64 {
+		if g.OverCap() {
+			return 1
+		}
+		return 0
+	})
+	return g
+}
+
+// NewResponseSizeGuardFromEnv reads the cap from CHALLENGES_RESPONSE_MAX_BYTES
+// (default 2 MiB, "0" disables the guard). Negative values fall back to the default.
+func NewResponseSizeGuardFromEnv(serCache *cache.SerializedChallengeCache) *ResponseSizeGuard {
+	maxBytes := common.GetEnvInt("CHALLENGES_RESPONSE_MAX_BYTES", DefaultChallengesResponseMaxBytes)
+	if maxBytes < 0 {
+		maxBytes = DefaultChallengesResponseMaxBytes
+	}
+	return NewResponseSizeGuard(serCache, maxBytes)
+}
+
+// MaxBytes returns the cap, 0 when the guard is disabled.
+func (g *ResponseSizeGuard) MaxBytes() int {
+	return g.maxBytes
+}
+
+// Collectors returns the response size metrics for registration.
+func (g *ResponseSizeGuard) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{g.rejected, g.overCap}
+}
+
+// OverCap reports whether the cached challenge config is above the cap.
+func (g *ResponseSizeGuard) OverCap() bool {
+	return g.maxBytes > 0 && g.serCache.GetStats().TotalBytes > g.maxBytes
+}
+
+// WarnIfOverCap logs a warning when the cached challenge config is above the
+// cap, so the config owner finds out before players do. Call it after the
+// serialization cache is warmed up.
+func (g *ResponseSizeGuard) WarnIfOverCap() {
+	if !g.OverCap() {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"cached_bytes": g.serCache.GetStats().TotalBytes,
+		"max_bytes":    g.maxBytes,
+	}).Warn("Challenge config is larger than CHALLENGES_RESPONSE_MAX_BYTES; unpaginated GET /v1/challenges requests will be rejected unless they set allow_large=true")
+}
+
+// allow reports whether a response of size bytes may be served for r.
+func (g *ResponseSizeGuard) allow(size int, r *http.Request) bool {
+	if g.maxBytes <= 0 || size <= g.maxBytes || r.URL.Query().Get("allow_large") == "true" {
+		return true
+	}
+	g.rejected.Inc()
+	return false
+}
+
+// reject writes the 413 response for a response of size bytes. paginated tells
+// whether the limit parameter is available as an alternative.
+func (g *ResponseSizeGuard) reject(w http.ResponseWriter, size int, paginated bool) {
+	hint := "narrow it with challenge_ids or set allow_large=true"
+	if paginated {
+		hint = "page it with limit and after_goal_id, " + hint
+	}
+	http.Error(w, "response of at least "+strconv.Itoa(size)+" bytes is above the "+
+		strconv.Itoa(g.maxBytes)+" byte limit; "+hint, http.StatusRequestEntityTooLarge)
+}
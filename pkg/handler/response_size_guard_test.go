@@ -0,0 +1,113 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// newSizeGuardedHandler builds a paged test handler whose unpaginated responses
+// are capped at the static size of second-challenge alone.
+func newSizeGuardedHandler(t *testing.T, mockRepo *mocks.GoalRepository) (*OptimizedChallengesHandler, *ResponseSizeGuard) {
+	t.Helper()
+
+	challenges := createPagedTestChallenges()
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: challenges}, "", slog.Default())
+	pbChallenges, err := mapper.ChallengesToProto(challenges, nil, nil, time.Now())
+	require.NoError(t, err)
+	serCache := cache.NewSerializedChallengeCache()
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	handler := NewOptimizedChallengesHandler(goalCache, mockRepo, new(mocks.ProgressQueryRepository), serCache, "test-namespace", false, nil)
+	guard := NewResponseSizeGuard(serCache, handler.responseBuilder.StaticResponseSize([]string{"second-challenge"}))
+	handler.SetResponseSizeGuard(guard)
+	return handler, guard
+}
+
+func serveSizeGuarded(handler *OptimizedChallengesHandler, url string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("x-mock-user-id", "test-user")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseSizeGuard_RejectsOversizedResponse(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, guard := newSizeGuardedHandler(t, mockRepo)
+
+	w := serveSizeGuarded(handler, "/v1/challenges")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "limit and after_goal_id")
+	assert.Contains(t, w.Body.String(), "allow_large=true")
+	assert.Equal(t, 1.0, testutil.ToFloat64(guard.rejected))
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResponseSizeGuard_AllowedRequests(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	handler, guard := newSizeGuardedHandler(t, mockRepo)
+	mockRepo.On("GetUserProgress", mock.Anything, "test-user", false).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "test-user", mock.Anything).Return([]*commonDomain.UserGoalProgress{}, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "test-user", mock.Anything, false).Return([]*commonDomain.UserGoalProgress{}, nil)
+
+	for _, url := range []string{
+		"/v1/challenges?allow_large=true",
+		"/v1/challenges?challenge_ids=second-challenge",
+		"/v1/challenges?limit=5",
+	} {
+		w := serveSizeGuarded(handler, url)
+		assert.Equal(t, http.StatusOK, w.Code, "%s: %s", url, w.Body.String())
+	}
+	assert.Equal(t, 0.0, testutil.ToFloat64(guard.rejected))
+}
+
+func TestResponseSizeGuard_OverCap(t *testing.T) {
+	serCache := cache.NewSerializedChallengeCache()
+	pbChallenges, err := mapper.ChallengesToProto(createPagedTestChallenges(), nil, nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+	total := serCache.GetStats().TotalBytes
+
+	under := NewResponseSizeGuard(serCache, total)
+	assert.False(t, under.OverCap())
+	assert.Equal(t, 0.0, testutil.ToFloat64(under.overCap))
+
+	over := NewResponseSizeGuard(serCache, total-1)
+	assert.True(t, over.OverCap())
+	assert.Equal(t, 1.0, testutil.ToFloat64(over.overCap))
+
+	assert.False(t, NewResponseSizeGuard(serCache, 0).OverCap(), "0 disables the guard")
+}
+
+func TestNewResponseSizeGuardFromEnv(t *testing.T) {
+	serCache := cache.NewSerializedChallengeCache()
+
+	assert.Equal(t, DefaultChallengesResponseMaxBytes, NewResponseSizeGuardFromEnv(serCache).MaxBytes())
+
+	t.Setenv("CHALLENGES_RESPONSE_MAX_BYTES", "0")
+	assert.Equal(t, 0, NewResponseSizeGuardFromEnv(serCache).MaxBytes())
+
+	t.Setenv("CHALLENGES_RESPONSE_MAX_BYTES", "-5")
+	assert.Equal(t, DefaultChallengesResponseMaxBytes, NewResponseSizeGuardFromEnv(serCache).MaxBytes())
+}
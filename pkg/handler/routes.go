@@ -0,0 +1,190 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"extend-challenge-service/pkg/common"
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// HandlerHeader is the response header naming the path that served an HTTP
+// request: HandlerOptimized or HandlerGateway.
+const HandlerHeader = "X-Handler"
+
+// Values of HandlerHeader.
+const (
+	HandlerOptimized = "optimized"
+	HandlerGateway   = "gateway"
+)
+
+// Values of OPTIMIZED_HANDLERS (--optimized-handlers).
+const (
+	OptimizedHandlersOff        = "off"
+	OptimizedHandlersChallenges = "challenges"
+	OptimizedHandlersInitialize = "initialize"
+	OptimizedHandlersAll        = "all"
+)
+
+// OptimizedHandlers selects the routes served by the optimized handlers. The
+// other routes fall through to the gRPC-Gateway, which serves the same API
+// through the gRPC server, e.g. to rule out a bug in an optimized handler.
+type OptimizedHandlers struct {
+	// Challenges serves GET /v1/challenges and GET /v1/challenges/{challenge_id}
+	// with OptimizedChallengesHandler.
+	Challenges bool
+	// Initialize serves POST /v1/challenges/initialize with OptimizedInitializeHandler.
+	Initialize bool
+}
+
+// ParseOptimizedHandlers parses "off", "challenges", "initialize" or "all".
+func ParseOptimizedHandlers(value string) (OptimizedHandlers, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case OptimizedHandlersOff:
+		return OptimizedHandlers{}, nil
+	case OptimizedHandlersChallenges:
+		return OptimizedHandlers{Challenges: true}, nil
+	case OptimizedHandlersInitialize:
+		return OptimizedHandlers{Initialize: true}, nil
+	case OptimizedHandlersAll:
+		return OptimizedHandlers{Challenges: true, Initialize: true}, nil
+	default:
+		return OptimizedHandlers{}, fmt.Errorf("invalid optimized handlers %q (must be %q, %q, %q or %q)",
+			value, OptimizedHandlersOff, OptimizedHandlersChallenges, OptimizedHandlersInitialize, OptimizedHandlersAll)
+	}
+}
+
+// String returns the value ParseOptimizedHandlers parses back.
+func (o OptimizedHandlers) String() string {
+	switch {
+	case o.Challenges && o.Initialize:
+		return OptimizedHandlersAll
+	case o.Challenges:
+		return OptimizedHandlersChallenges
+	case o.Initialize:
+		return OptimizedHandlersInitialize
+	default:
+		return OptimizedHandlersOff
+	}
+}
+
+// ChallengeRoutes are the handlers of the challenge API routes.
+type ChallengeRoutes struct {
+	// Gateway is the gRPC-Gateway, serving every route not taken by an optimized handler.
+	Gateway http.Handler
+	// Challenges serves GET /v1/challenges; ChallengeDetail GET /v1/challenges/{challenge_id}.
+	Challenges      http.Handler
+	ChallengeDetail http.Handler
+	// Initialize serves POST /v1/challenges/initialize.
+	Initialize http.Handler
+}
+
+// RegisterChallengeRoutes registers the optimized handlers enabled in optimized
+// under basePath, and the gRPC-Gateway as the catch-all for everything else.
+// Responses carry HandlerHeader. Requests to the optimized handlers are counted
+// by metrics under the RPC they serve.
+func RegisterChallengeRoutes(
+	mux *http.ServeMux,
+	basePath string,
+	optimized OptimizedHandlers,
+	routes ChallengeRoutes,
+	metrics *common.RequestMetrics,
+) {
+	gateway := ServedBy(HandlerGateway, routes.Gateway)
+
+	if optimized.Challenges {
+		challenges := ServedBy(HandlerOptimized, metrics.HTTPHandler(pb.Service_GetUserChallenges_FullMethodName, routes.Challenges))
+		mux.Handle(basePath+"/v1/challenges", challenges)
+		// Without NormalizePath in front, a trailing slash would otherwise fall through to the gateway catch-all
+		mux.Handle(basePath+"/v1/challenges/{$}", challenges)
+		mux.Handle(basePath+"/v1/challenges/{challenge_id}",
+			ServedBy(HandlerOptimized, metrics.HTTPHandler(pb.Service_GetChallenge_FullMethodName, routes.ChallengeDetail)))
+
+		// The summary, unclaimed-count and claim-all paths also match the detail pattern; keep them on the gRPC-Gateway
+		mux.Handle(basePath+"/v1/challenges/summary", gateway)
+		mux.Handle(basePath+"/v1/challenges/unclaimed-count", gateway)
+		mux.Handle(basePath+"/v1/challenges/claim-all", gateway)
+	}
+
+	initializePath := basePath + "/v1/challenges/initialize"
+	switch {
+	case optimized.Initialize:
+		mux.Handle(initializePath,
+			ServedBy(HandlerOptimized, metrics.HTTPHandler(pb.Service_InitializePlayer_FullMethodName, routes.Initialize)))
+	case optimized.Challenges:
+		// Would otherwise match the optimized detail pattern
+		mux.Handle(initializePath, gateway)
+	}
+
+	// Catch-all (ServeMux prefers the more specific patterns above)
+	mux.Handle("/", gateway)
+}
+
+// NormalizePath cleans the request path before routing, so that messy paths
+// reach the same handler as the clean one instead of a redirect, the catch-all
+// or the gRPC-Gateway in place of an optimized handler: runs of slashes
+// collapse into one ("//v1/challenges") and a trailing slash is dropped, except
+// on the root and on keepTrailingSlash, the subtree paths whose handlers need
+// it (e.g. the Swagger UI directory).
+func NormalizePath(next http.Handler, keepTrailingSlash ...string) http.Handler {
+	keep := make(map[string]bool, len(keepTrailingSlash))
+	for _, path := range keepTrailingSlash {
+		keep[path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := normalizePath(r.URL.Path, keep)
+		if path == r.URL.Path {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Like http.StripPrefix: the caller's request is not modified
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = path
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = normalizePath(r.URL.RawPath, keep)
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// normalizePath collapses runs of slashes in path and drops its trailing
+// slash, unless the result is the root or in keep.
+func normalizePath(path string, keep map[string]bool) string {
+	if !strings.Contains(path, "//") && (len(path) <= 1 || !strings.HasSuffix(path, "/")) {
+		return path
+	}
+
+	var b strings.Builder
+	b.Grow(len(path))
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && i > 0 && path[i-1] == '/' {
+			continue
+		}
+		b.WriteByte(path[i])
+	}
+
+	cleaned := b.String()
+	if len(cleaned) > 1 && strings.HasSuffix(cleaned, "/") && !keep[cleaned] {
+		cleaned = strings.TrimSuffix(cleaned, "/")
+	}
+	return cleaned
+}
+
+// ServedBy sets HandlerHeader to name on every response of next.
+func ServedBy(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HandlerHeader, name)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/common"
+)
+
+func TestParseOptimizedHandlers(t *testing.T) {
+	tests := []struct {
+		value string
+		want  OptimizedHandlers
+	}{
+		{"off", OptimizedHandlers{}},
+		{"challenges", OptimizedHandlers{Challenges: true}},
+		{"initialize", OptimizedHandlers{Initialize: true}},
+		{"all", OptimizedHandlers{Challenges: true, Initialize: true}},
+		{" ALL ", OptimizedHandlers{Challenges: true, Initialize: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOptimizedHandlers(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			roundTrip, err := ParseOptimizedHandlers(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, roundTrip)
+		})
+	}
+
+	_, err := ParseOptimizedHandlers("on")
+	assert.Error(t, err)
+	_, err = ParseOptimizedHandlers("")
+	assert.Error(t, err)
+}
+
+// named answers with its name, so a test can tell which route served a request.
+func named(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(name))
+	})
+}
+
+func TestRegisterChallengeRoutes(t *testing.T) {
+	routes := ChallengeRoutes{
+		Gateway:         named("gateway"),
+		Challenges:      named("challenges"),
+		ChallengeDetail: named("challenge"),
+		Initialize:      named("initialize"),
+	}
+
+	type served struct {
+		by     string
+		header string
+	}
+	tests := []struct {
+		name      string
+		optimized string
+		want      map[string]served
+	}{
+		{
+			name:      "all",
+			optimized: OptimizedHandlersAll,
+			want: map[string]served{
+				"GET /api/v1/challenges":                       {"challenges", HandlerOptimized},
+				"GET /api/v1/challenges/winter":                {"challenge", HandlerOptimized},
+				"GET /api/v1/challenges/summary":               {"gateway", HandlerGateway},
+				"GET /api/v1/challenges/unclaimed-count":       {"gateway", HandlerGateway},
+				"POST /api/v1/challenges/claim-all":            {"gateway", HandlerGateway},
+				"POST /api/v1/challenges/initialize":           {"initialize", HandlerOptimized},
+				"POST /api/v1/challenges/winter/goals/a/claim": {"gateway", HandlerGateway},
+			},
+		},
+		{
+			name:      "off",
+			optimized: OptimizedHandlersOff,
+			want: map[string]served{
+				"GET /api/v1/challenges":             {"gateway", HandlerGateway},
+				"GET /api/v1/challenges/winter":      {"gateway", HandlerGateway},
+				"GET /api/v1/challenges/summary":     {"gateway", HandlerGateway},
+				"POST /api/v1/challenges/initialize": {"gateway", HandlerGateway},
+			},
+		},
+		{
+			name:      "challenges",
+			optimized: OptimizedHandlersChallenges,
+			want: map[string]served{
+				"GET /api/v1/challenges":             {"challenges", HandlerOptimized},
+				"GET /api/v1/challenges/winter":      {"challenge", HandlerOptimized},
+				"POST /api/v1/challenges/initialize": {"gateway", HandlerGateway},
+			},
+		},
+		{
+			name:      "initialize",
+			optimized: OptimizedHandlersInitialize,
+			want: map[string]served{
+				"GET /api/v1/challenges":             {"gateway", HandlerGateway},
+				"GET /api/v1/challenges/winter":      {"gateway", HandlerGateway},
+				"POST /api/v1/challenges/initialize": {"initialize", HandlerOptimized},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimized, err := ParseOptimizedHandlers(tt.optimized)
+			require.NoError(t, err)
+
+			mux := http.NewServeMux()
+			RegisterChallengeRoutes(mux, "/api", optimized, routes, common.NewRequestMetrics())
+
+			for request, want := range tt.want {
+				var method, path string
+				_, err := fmt.Sscan(request, &method, &path)
+				require.NoError(t, err)
+
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+				assert.Equal(t, want.by, w.Body.String(), request)
+				assert.Equal(t, want.header, w.Header().Get(HandlerHeader), request)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "/"},
+		{"//", "/"},
+		{"/api/v1/challenges", "/api/v1/challenges"},
+		{"/api/v1/challenges/", "/api/v1/challenges"},
+		{"//api/v1/challenges", "/api/v1/challenges"},
+		{"/api//v1///challenges//", "/api/v1/challenges"},
+		{"/api/apidocs/", "/api/apidocs/"},
+		{"/api//apidocs//", "/api/apidocs/"},
+		{"/api/apidocs/index.html/", "/api/apidocs/index.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var got string
+			h := NormalizePath(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.URL.Path
+			}), "/api/apidocs/")
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+tt.path, nil)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.path, req.URL.Path, "the caller's request is not modified")
+		})
+	}
+}
+
+func TestNormalizePath_RawPath(t *testing.T) {
+	var path, rawPath string
+	h := NormalizePath(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, rawPath = r.URL.Path, r.URL.RawPath
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api//v1/challenges/a%2Fb/", nil))
+
+	assert.Equal(t, "/api/v1/challenges/a/b", path)
+	assert.Equal(t, "/api/v1/challenges/a%2Fb", rawPath)
+}
+
+func TestRegisterChallengeRoutes_MessyPaths(t *testing.T) {
+	routes := ChallengeRoutes{
+		Gateway:         named("gateway"),
+		Challenges:      named("challenges"),
+		ChallengeDetail: named("challenge"),
+		Initialize:      named("initialize"),
+	}
+
+	tests := []struct {
+		name     string
+		basePath string
+		want     map[string]string
+	}{
+		{
+			name:     "base path",
+			basePath: "/api",
+			want: map[string]string{
+				"GET /api/v1/challenges/":                        "challenges",
+				"GET //api/v1/challenges":                        "challenges",
+				"GET /api//v1//challenges//":                     "challenges",
+				"GET /api/v1/challenges/winter/":                 "challenge",
+				"GET /api/v1/challenges//winter":                 "challenge",
+				"GET /api/v1/challenges/summary/":                "gateway",
+				"POST /api/v1/challenges/initialize/":            "initialize",
+				"POST //api/v1/challenges/claim-all":             "gateway",
+				"POST /api/v1/challenges/winter/goals/a/claim/":  "gateway",
+				"POST /api/v1/challenges/winter//goals/a//claim": "gateway",
+			},
+		},
+		{
+			name:     "empty base path",
+			basePath: "",
+			want: map[string]string{
+				"GET /v1/challenges":                  "challenges",
+				"GET /v1/challenges/":                 "challenges",
+				"GET //v1/challenges":                 "challenges",
+				"GET /v1/challenges/winter/":          "challenge",
+				"GET /v1/challenges/unclaimed-count/": "gateway",
+				"POST //v1/challenges/initialize/":    "initialize",
+				"GET /healthz/":                       "gateway",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			RegisterChallengeRoutes(mux, tt.basePath, OptimizedHandlers{Challenges: true, Initialize: true}, routes, common.NewRequestMetrics())
+			h := NormalizePath(mux)
+
+			for request, want := range tt.want {
+				var method, path string
+				_, err := fmt.Sscan(request, &method, &path)
+				require.NoError(t, err)
+
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest(method, "http://example.com"+path, nil))
+
+				assert.Equal(t, http.StatusOK, w.Code, request)
+				assert.Equal(t, want, w.Body.String(), request)
+			}
+		})
+	}
+}
+
+func TestRegisterChallengeRoutes_TrailingSlashWithoutNormalizePath(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterChallengeRoutes(mux, "/api", OptimizedHandlers{Challenges: true}, ChallengeRoutes{
+		Gateway:         named("gateway"),
+		Challenges:      named("challenges"),
+		ChallengeDetail: named("challenge"),
+	}, common.NewRequestMetrics())
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/challenges/", nil))
+
+	assert.Equal(t, "challenges", w.Body.String())
+	assert.Equal(t, HandlerOptimized, w.Header().Get(HandlerHeader))
+}
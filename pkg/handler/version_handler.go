@@ -0,0 +1,48 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"extend-challenge-service/pkg/common/version"
+)
+
+// VersionHandler serves GET /version with the build information of the running binary.
+// It is unauthenticated so on-call can identify a pod's build without exec-ing in.
+type VersionHandler struct {
+	body []byte
+}
+
+// NewVersionHandler creates a VersionHandler. Build information is fixed at link
+// time, so the response body is encoded once.
+func NewVersionHandler() *VersionHandler {
+	body, _ := json.Marshal(version.Get()) // Info only has string fields; cannot fail
+	return &VersionHandler{body: body}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *VersionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.body)
+}
+
+// VersionHeaders adds the service version to every response via the Server and
+// X-Config-Version headers.
+func VersionHeaders(next http.Handler) http.Handler {
+	server := "extend-challenge-service/" + version.Version
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", server)
+		w.Header().Set("X-Config-Version", version.Version)
+		next.ServeHTTP(w, r)
+	})
+}
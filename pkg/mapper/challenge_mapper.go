@@ -42,7 +42,13 @@ var (
 // Returns error for validation failures (early validation, Decision Q2a)
 // Uses object pooling to reduce allocations
 // M5: now parameter used for rotation display calculations
-func ChallengeToProto(challenge *domain.Challenge, userProgress map[string]*domain.UserGoalProgress, now time.Time) (*pb.Challenge, error) {
+// activatable holds the IDs of goals the user can activate (may be nil)
+func ChallengeToProto(
+	challenge *domain.Challenge,
+	userProgress map[string]*domain.UserGoalProgress,
+	activatable map[string]bool,
+	now time.Time,
+) (*pb.Challenge, error) {
 	if challenge == nil {
 		return nil, fmt.Errorf("challenge cannot be nil")
 	}
@@ -52,6 +58,9 @@ func ChallengeToProto(challenge *domain.Challenge, userProgress map[string]*doma
 	pbChallenge.ChallengeId = challenge.ID
 	pbChallenge.Name = challenge.Name
 	pbChallenge.Description = challenge.Description
+	// Set by the caller (see SetChallengeReset)
+	pbChallenge.NextResetAt = nil
+	pbChallenge.SecondsRemaining = 0
 	// Reuse slice capacity if possible
 	if cap(pbChallenge.Goals) >= len(challenge.Goals) {
 		pbChallenge.Goals = pbChallenge.Goals[:0]
@@ -60,7 +69,7 @@ func ChallengeToProto(challenge *domain.Challenge, userProgress map[string]*doma
 	}
 
 	for _, goal := range challenge.Goals {
-		pbGoal, err := GoalToProto(goal, userProgress, now)
+		pbGoal, err := GoalToProto(goal, userProgress, activatable, now)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert goal %s: %w", goal.ID, err)
 		}
@@ -74,7 +83,13 @@ func ChallengeToProto(challenge *domain.Challenge, userProgress map[string]*doma
 // Computes progress for daily goals from completed_at timestamp (Decision FQ2)
 // Uses object pooling to reduce allocations
 // M5: now parameter used for rotation display calculations (expires_at, displayed progress)
-func GoalToProto(goal *domain.Goal, userProgress map[string]*domain.UserGoalProgress, now time.Time) (*pb.Goal, error) {
+// activatable holds the IDs of goals the user can activate (may be nil), see service.ActivatableGoals
+func GoalToProto(
+	goal *domain.Goal,
+	userProgress map[string]*domain.UserGoalProgress,
+	activatable map[string]bool,
+	now time.Time,
+) (*pb.Goal, error) {
 	if goal == nil {
 		return nil, fmt.Errorf("goal cannot be nil")
 	}
@@ -115,9 +130,11 @@ func GoalToProto(goal *domain.Goal, userProgress map[string]*domain.UserGoalProg
 		pbGoal.Progress = 0
 		pbGoal.Status = string(domain.GoalStatusNotStarted)
 		pbGoal.Locked = len(goal.Prerequisites) > 0 // Will be refined by PrerequisiteChecker
-		pbGoal.CompletedAt = ""
-		pbGoal.ClaimedAt = ""
+		pbGoal.CompletedAt = nil
+		pbGoal.FirstCompletedAt = nil
+		pbGoal.ClaimedAt = nil
 		pbGoal.IsActive = false
+		pbGoal.AssignedAt = nil
 	} else {
 		// M5: Apply display rotation to get the user-visible progress and status
 		displayedProgress, displayedStatus, _ := rotation.ApplyDisplayRotation(progress, goal, now)
@@ -126,27 +143,44 @@ func GoalToProto(goal *domain.Goal, userProgress map[string]*domain.UserGoalProg
 		pbGoal.Status = string(displayedStatus)
 		pbGoal.Locked = false // Will be computed by PrerequisiteChecker
 		pbGoal.IsActive = progress.IsActive
-		pbGoal.CompletedAt = formatTimestamp(progress.CompletedAt)
-		pbGoal.ClaimedAt = formatTimestamp(progress.ClaimedAt)
+		pbGoal.CompletedAt = ToProtoTimestamp(progress.CompletedAt)
+		// Rows never reset were first completed at completed_at; the caller sets
+		// the first completion of rows reset since (see service.ResolveFirstCompletions)
+		pbGoal.FirstCompletedAt = ToProtoTimestamp(progress.CompletedAt)
+		pbGoal.ClaimedAt = ToProtoTimestamp(progress.ClaimedAt)
+		pbGoal.AssignedAt = ToProtoTimestamp(progress.AssignedAt)
 	}
+	pbGoal.Activatable = activatable[goal.ID]
+	// Set by the caller for multi-step goals (see GoalStepToProto)
+	pbGoal.Steps = nil
+	// Set by the caller with include_blockers (see service.ClaimBlockers)
+	pbGoal.ClaimBlockers = nil
 
 	// M5: Set expiry fields from rotation config
 	expiresAt := rotation.CalculateNextExpiresAt(goal, now)
-	if expiresAt != nil {
-		pbGoal.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
-		seconds := int32(expiresAt.Sub(now).Seconds())
-		if seconds < 0 {
-			seconds = 0
-		}
-		pbGoal.ExpiresInSeconds = seconds
-	} else {
-		pbGoal.ExpiresAt = ""
-		pbGoal.ExpiresInSeconds = 0
-	}
+	pbGoal.ExpiresAt = ToProtoTimestamp(expiresAt)
+	pbGoal.ExpiresInSeconds = SecondsUntil(expiresAt, now)
 
 	return pbGoal, nil
 }
 
+// SetChallengeReset sets the challenge's next reset (see
+// service.NextChallengeReset) and the seconds left until it at now. resetAt is
+// nil when none of the challenge's goals rotates.
+func SetChallengeReset(pbChallenge *pb.Challenge, resetAt *time.Time, now time.Time) {
+	pbChallenge.NextResetAt = ToProtoTimestamp(resetAt)
+	pbChallenge.SecondsRemaining = SecondsUntil(resetAt, now)
+}
+
+// SecondsUntil returns the whole seconds from now to t, 0 when t is nil or not
+// after now.
+func SecondsUntil(t *time.Time, now time.Time) int32 {
+	if t == nil {
+		return 0
+	}
+	return max(int32(t.Sub(now).Seconds()), 0)
+}
+
 // ComputeProgress computes the progress value for display.
 // M5: Uses CalculateDisplayedProgress for rotation-aware relative mode support.
 func ComputeProgress(goal *domain.Goal, progress *domain.UserGoalProgress) int32 {
@@ -175,6 +209,31 @@ func RequirementToProto(req *domain.Requirement) (*pb.Requirement, error) {
 	return pbReq, nil
 }
 
+// GoalStepToProto converts one step of a multi-step goal and the user's
+// progress on it to a protobuf GoalStep. The step is completed once progress
+// reaches targetValue.
+func GoalStepToProto(statCode, operator string, targetValue, progress int) *pb.GoalStep {
+	return &pb.GoalStep{
+		StatCode: statCode,
+		Operator: operator,
+		// #nosec G115 - Target values are validated at config load time, safe to convert
+		TargetValue: int32(targetValue),
+		// #nosec G115 - Progress values are validated at config load time, safe to convert
+		Progress:  int32(progress),
+		Completed: progress >= targetValue,
+	}
+}
+
+// GoalRepeatToProto converts the claim history of a repeatable goal to a
+// protobuf GoalRepeat. A zero availableAt (never claimed) is left unset.
+func GoalRepeatToProto(timesClaimed int, availableAt time.Time) *pb.GoalRepeat {
+	return &pb.GoalRepeat{
+		// #nosec G115 - claim counts fit in int32
+		TimesClaimed: int32(timesClaimed),
+		AvailableAt:  ToProtoTimestamp(&availableAt),
+	}
+}
+
 // RewardToProto converts domain Reward to protobuf Reward
 // Uses object pooling to reduce allocations
 func RewardToProto(reward *domain.Reward) (*pb.Reward, error) {
@@ -199,14 +258,19 @@ func RewardToProto(reward *domain.Reward) (*pb.Reward, error) {
 
 // ChallengesToProto converts a slice of domain Challenges to protobuf Challenges
 // M5: now parameter used for rotation display calculations
-func ChallengesToProto(challenges []*domain.Challenge, userProgress map[string]*domain.UserGoalProgress, now time.Time) ([]*pb.Challenge, error) {
+func ChallengesToProto(
+	challenges []*domain.Challenge,
+	userProgress map[string]*domain.UserGoalProgress,
+	activatable map[string]bool,
+	now time.Time,
+) ([]*pb.Challenge, error) {
 	if challenges == nil {
 		return nil, fmt.Errorf("challenges cannot be nil")
 	}
 
 	pbChallenges := make([]*pb.Challenge, 0, len(challenges))
 	for _, challenge := range challenges {
-		pbChallenge, err := ChallengeToProto(challenge, userProgress, now)
+		pbChallenge, err := ChallengeToProto(challenge, userProgress, activatable, now)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert challenge %s: %w", challenge.ID, err)
 		}
@@ -267,12 +331,3 @@ func ReturnRewardToPool(reward *pb.Reward) {
 	}
 	rewardPool.Put(reward)
 }
-
-// formatTimestamp formats a nullable time pointer to RFC3339 string.
-// Returns empty string for nil or zero times.
-func formatTimestamp(t *time.Time) string {
-	if t == nil || t.IsZero() {
-		return ""
-	}
-	return t.UTC().Format(time.RFC3339)
-}
@@ -48,7 +48,7 @@ func TestChallengeToProto_Success(t *testing.T) {
 		},
 	}
 
-	pbChallenge, err := ChallengeToProto(challenge, userProgress, testNow)
+	pbChallenge, err := ChallengeToProto(challenge, userProgress, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Equal(t, "winter-challenge", pbChallenge.ChallengeId)
@@ -60,7 +60,7 @@ func TestChallengeToProto_Success(t *testing.T) {
 }
 
 func TestChallengeToProto_NilChallenge(t *testing.T) {
-	_, err := ChallengeToProto(nil, nil, testNow)
+	_, err := ChallengeToProto(nil, nil, nil, testNow)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "challenge cannot be nil")
@@ -96,7 +96,7 @@ func TestGoalToProto_WithProgress(t *testing.T) {
 		},
 	}
 
-	pbGoal, err := GoalToProto(goal, userProgress, testNow)
+	pbGoal, err := GoalToProto(goal, userProgress, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Equal(t, "goal-1", pbGoal.GoalId)
@@ -104,7 +104,8 @@ func TestGoalToProto_WithProgress(t *testing.T) {
 	assert.Equal(t, int32(10), pbGoal.Progress)
 	assert.Equal(t, string(domain.GoalStatusCompleted), pbGoal.Status)
 	assert.NotEmpty(t, pbGoal.CompletedAt)
-	assert.Empty(t, pbGoal.ClaimedAt)
+	assert.Equal(t, completedAt.Truncate(time.Second), pbGoal.FirstCompletedAt.AsTime(), "a row never reset was first completed at completed_at")
+	assert.Nil(t, pbGoal.ClaimedAt)
 }
 
 func TestGoalToProto_NoProgress(t *testing.T) {
@@ -126,23 +127,35 @@ func TestGoalToProto_NoProgress(t *testing.T) {
 		Prerequisites: []string{},
 	}
 
-	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, testNow)
+	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Equal(t, int32(0), pbGoal.Progress)
 	assert.Equal(t, string(domain.GoalStatusNotStarted), pbGoal.Status)
-	assert.Empty(t, pbGoal.CompletedAt)
-	assert.Empty(t, pbGoal.ClaimedAt)
+	assert.Nil(t, pbGoal.CompletedAt)
+	assert.Nil(t, pbGoal.FirstCompletedAt)
+	assert.Nil(t, pbGoal.ClaimedAt)
 	assert.False(t, pbGoal.Locked) // No prerequisites
 }
 
 func TestGoalToProto_NilGoal(t *testing.T) {
-	_, err := GoalToProto(nil, nil, testNow)
+	_, err := GoalToProto(nil, nil, nil, testNow)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "goal cannot be nil")
 }
 
+func TestGoalStepToProto(t *testing.T) {
+	step := GoalStepToProto("damage", ">=", 10000, 250)
+
+	assert.Equal(t, "damage", step.StatCode)
+	assert.Equal(t, ">=", step.Operator)
+	assert.Equal(t, int32(10000), step.TargetValue)
+	assert.Equal(t, int32(250), step.Progress)
+	assert.False(t, step.Completed)
+	assert.True(t, GoalStepToProto("wins", ">=", 3, 4).Completed)
+}
+
 func TestComputeProgress_DailyGoal_CompletedToday(t *testing.T) {
 	goal := &domain.Goal{
 		Requirement: domain.Requirement{
@@ -351,7 +364,7 @@ func TestChallengesToProto_Success(t *testing.T) {
 		},
 	}
 
-	pbChallenges, err := ChallengesToProto(challenges, map[string]*domain.UserGoalProgress{}, testNow)
+	pbChallenges, err := ChallengesToProto(challenges, map[string]*domain.UserGoalProgress{}, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Len(t, pbChallenges, 2)
@@ -360,14 +373,14 @@ func TestChallengesToProto_Success(t *testing.T) {
 }
 
 func TestChallengesToProto_EmptySlice(t *testing.T) {
-	pbChallenges, err := ChallengesToProto([]*domain.Challenge{}, map[string]*domain.UserGoalProgress{}, testNow)
+	pbChallenges, err := ChallengesToProto([]*domain.Challenge{}, map[string]*domain.UserGoalProgress{}, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Empty(t, pbChallenges)
 }
 
 func TestChallengesToProto_Nil(t *testing.T) {
-	_, err := ChallengesToProto(nil, nil, testNow)
+	_, err := ChallengesToProto(nil, nil, nil, testNow)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "challenges cannot be nil")
@@ -405,7 +418,7 @@ func TestGoalToProto_WithClaimedProgress(t *testing.T) {
 		},
 	}
 
-	pbGoal, err := GoalToProto(goal, userProgress, testNow)
+	pbGoal, err := GoalToProto(goal, userProgress, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Equal(t, string(domain.GoalStatusClaimed), pbGoal.Status)
@@ -432,7 +445,7 @@ func TestGoalToProto_WithPrerequisites(t *testing.T) {
 		Prerequisites: []string{"goal-1"},
 	}
 
-	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, testNow)
+	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, nil, testNow)
 
 	require.NoError(t, err)
 	assert.Len(t, pbGoal.Prerequisites, 1)
@@ -479,12 +492,12 @@ func TestGoalToProto_WithRotation(t *testing.T) {
 		},
 	}
 
-	pbGoal, err := GoalToProto(goal, userProgress, now)
+	pbGoal, err := GoalToProto(goal, userProgress, nil, now)
 
 	require.NoError(t, err)
 	assert.Equal(t, int32(5), pbGoal.Progress)
 	assert.Equal(t, string(domain.GoalStatusInProgress), pbGoal.Status)
-	assert.Equal(t, "2025-06-16T00:00:00Z", pbGoal.ExpiresAt)
+	assert.Equal(t, "2025-06-16T00:00:00Z", FormatTimestamp(pbGoal.ExpiresAt.AsTime()))
 	assert.True(t, pbGoal.ExpiresInSeconds > 0)
 }
 
@@ -527,13 +540,13 @@ func TestGoalToProto_RotatedGoalShowsReset(t *testing.T) {
 		},
 	}
 
-	pbGoal, err := GoalToProto(goal, userProgress, now)
+	pbGoal, err := GoalToProto(goal, userProgress, nil, now)
 
 	require.NoError(t, err)
 	// Rotation + reset_progress → displayed as reset
 	assert.Equal(t, int32(0), pbGoal.Progress)
 	assert.Equal(t, string(domain.GoalStatusNotStarted), pbGoal.Status)
-	assert.Equal(t, "2025-06-16T00:00:00Z", pbGoal.ExpiresAt)
+	assert.Equal(t, "2025-06-16T00:00:00Z", FormatTimestamp(pbGoal.ExpiresAt.AsTime()))
 }
 
 func TestGoalToProto_NoRotation_NoExpiryFields(t *testing.T) {
@@ -554,9 +567,81 @@ func TestGoalToProto_NoRotation_NoExpiryFields(t *testing.T) {
 		},
 	}
 
-	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, testNow)
+	pbGoal, err := GoalToProto(goal, map[string]*domain.UserGoalProgress{}, nil, testNow)
 
 	require.NoError(t, err)
-	assert.Empty(t, pbGoal.ExpiresAt)
+	assert.Nil(t, pbGoal.ExpiresAt)
 	assert.Equal(t, int32(0), pbGoal.ExpiresInSeconds)
 }
+
+// TestGoalToProto_GoalStates covers the states a client renders differently:
+// inactive (activate button), active with no progress, in progress, and completed.
+func TestGoalToProto_GoalStates(t *testing.T) {
+	goal := &domain.Goal{
+		ID:          "goal-1",
+		Name:        "Goal 1",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		Reward:      domain.Reward{Type: string(domain.RewardTypeItem), RewardID: "sword", Quantity: 1},
+	}
+	assignedAt := time.Date(2025, 6, 15, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		progress    *domain.UserGoalProgress
+		activatable bool
+		status      domain.GoalStatus
+		isActive    bool
+		assignedAt  *time.Time
+	}{
+		{
+			name:        "inactive",
+			progress:    nil,
+			activatable: true,
+			status:      domain.GoalStatusNotStarted,
+		},
+		{
+			name:       "active, no progress",
+			progress:   &domain.UserGoalProgress{Status: domain.GoalStatusNotStarted, IsActive: true, AssignedAt: &assignedAt},
+			status:     domain.GoalStatusNotStarted,
+			isActive:   true,
+			assignedAt: &assignedAt,
+		},
+		{
+			name:       "in progress",
+			progress:   &domain.UserGoalProgress{Progress: 4, Status: domain.GoalStatusInProgress, IsActive: true, AssignedAt: &assignedAt},
+			status:     domain.GoalStatusInProgress,
+			isActive:   true,
+			assignedAt: &assignedAt,
+		},
+		{
+			name:       "completed",
+			progress:   &domain.UserGoalProgress{Progress: 10, Status: domain.GoalStatusCompleted, IsActive: true, AssignedAt: &assignedAt, CompletedAt: &completedAt},
+			status:     domain.GoalStatusCompleted,
+			isActive:   true,
+			assignedAt: &assignedAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userProgress := map[string]*domain.UserGoalProgress{}
+			if tt.progress != nil {
+				tt.progress.GoalID = goal.ID
+				userProgress[goal.ID] = tt.progress
+			}
+
+			pbGoal, err := GoalToProto(goal, userProgress, map[string]bool{goal.ID: tt.activatable}, testNow)
+
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.status), pbGoal.Status)
+			assert.Equal(t, tt.isActive, pbGoal.IsActive)
+			assert.Equal(t, tt.activatable, pbGoal.Activatable)
+			if tt.assignedAt == nil {
+				assert.Nil(t, pbGoal.AssignedAt)
+			} else {
+				assert.Equal(t, *tt.assignedAt, pbGoal.AssignedAt.AsTime())
+			}
+		})
+	}
+}
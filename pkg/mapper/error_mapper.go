@@ -1,10 +1,21 @@
 package mapper
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"extend-challenge-service/pkg/progressstate"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // Common domain error types (Decision Q6)
@@ -32,6 +43,31 @@ func (e *GoalNotFoundError) Error() string {
 	return "goal not found: " + e.GoalID
 }
 
+// ChallengeNotFoundError is returned when a request names a challenge that is
+// not in the config or not rolled out to the user; both read the same.
+type ChallengeNotFoundError struct {
+	ChallengeID string
+}
+
+func (e *ChallengeNotFoundError) Error() string {
+	return "challenge not found: " + e.ChallengeID
+}
+
+// GoalInOtherChallengeError is returned when a request names a goal that exists
+// in the config, but under another challenge than the one requested.
+type GoalInOtherChallengeError struct {
+	GoalID string
+	// ChallengeID is the challenge of the request.
+	ChallengeID string
+	// GoalChallengeID is the goal's challenge in the config.
+	GoalChallengeID string
+}
+
+func (e *GoalInOtherChallengeError) Error() string {
+	return "goal '" + e.GoalID + "' does not belong to challenge '" + e.ChallengeID +
+		"' (it belongs to challenge '" + e.GoalChallengeID + "')"
+}
+
 type GoalNotCompletedError struct {
 	GoalID string
 	Status string
@@ -88,12 +124,190 @@ func (e *RewardGrantError) Error() string {
 	return "failed to grant reward for goal " + e.GoalID + ": " + e.Err.Error()
 }
 
+// RewardGrantPermanentError is returned when AGS rejects a reward grant with an
+// error that retrying cannot fix, e.g. the store item was deleted. The goal stays
+// claimable; support retries the grant once the store is fixed.
+type RewardGrantPermanentError struct {
+	GoalID      string
+	ChallengeID string
+	// Reward is the reward the claim tried to grant.
+	Reward domain.Reward
+	// GrantNamespace is the namespace the reward was granted in.
+	GrantNamespace string
+	// ErrorClass is the kind of AGS error, e.g. "not_found".
+	ErrorClass string
+	Err        error
+}
+
+func (e *RewardGrantPermanentError) Error() string {
+	return "reward grant failed permanently for goal " + e.GoalID + " (" + e.ErrorClass + "): " + e.Err.Error()
+}
+
+func (e *RewardGrantPermanentError) Unwrap() error {
+	return e.Err
+}
+
+// ClaimCapExceededError is returned when a user has reached the rolling claim cap.
+type ClaimCapExceededError struct {
+	UserID string
+	Limit  int
+	Window time.Duration
+	// RetryAfter is how long until the oldest claim in the window expires.
+	RetryAfter time.Duration
+}
+
+func (e *ClaimCapExceededError) Error() string {
+	return fmt.Sprintf("claim cap exceeded for user %s: %d claims per %s", e.UserID, e.Limit, e.Window)
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds (at least 1).
+func (e *ClaimCapExceededError) RetryAfterSeconds() int64 {
+	return max(int64(math.Ceil(e.RetryAfter.Seconds())), 1)
+}
+
+// ClaimFrozenError is returned when an admin has frozen the user's claims.
+type ClaimFrozenError struct {
+	UserID string
+	// FrozenUntil is when the freeze expires and claims are allowed again.
+	FrozenUntil time.Time
+}
+
+func (e *ClaimFrozenError) Error() string {
+	return fmt.Sprintf("claims frozen for user %s until %s", e.UserID, e.FrozenUntil.UTC().Format(time.RFC3339))
+}
+
+// GoalCoolingDownError is returned when a repeatable goal was claimed less than
+// its cooldown ago, so it cannot be claimed, activated or selected yet.
+type GoalCoolingDownError struct {
+	GoalID string
+	// AvailableAt is when the cooldown ends.
+	AvailableAt time.Time
+}
+
+func (e *GoalCoolingDownError) Error() string {
+	return fmt.Sprintf("goal %s is cooling down until %s", e.GoalID, e.AvailableAt.UTC().Format(time.RFC3339))
+}
+
+// GrantNamespaceNotAllowedError is returned when a claim's token namespace is
+// neither the service namespace nor one of the grantable namespaces.
+type GrantNamespaceNotAllowedError struct {
+	Namespace string
+}
+
+func (e *GrantNamespaceNotAllowedError) Error() string {
+	return "rewards cannot be granted in namespace " + e.Namespace
+}
+
+// GoalOverrideRefusedError is returned when an admin override targets a goal that
+// is not live for the user and the override was not forced.
+type GoalOverrideRefusedError struct {
+	GoalID      string
+	ChallengeID string
+	// Reason says why the goal is not live, e.g. "inactive".
+	Reason string
+}
+
+func (e *GoalOverrideRefusedError) Error() string {
+	return "goal override refused: " + e.GoalID + " is " + e.Reason
+}
+
+// ProgressResetRefusedError is returned when a deactivation asks to reset the
+// progress of a goal the user already claimed.
+type ProgressResetRefusedError struct {
+	GoalID      string
+	ChallengeID string
+}
+
+func (e *ProgressResetRefusedError) Error() string {
+	return "progress reset refused: " + e.GoalID + " is claimed"
+}
+
+// ChallengeLockedError is returned when a goal's challenge is locked for the user
+// because its prerequisite challenges are not completed.
+type ChallengeLockedError struct {
+	ChallengeID string
+	// LockedBy lists the prerequisite challenges the user has not completed.
+	LockedBy []string
+}
+
+func (e *ChallengeLockedError) Error() string {
+	return "challenge locked: " + e.ChallengeID + " (requires " + strings.Join(e.LockedBy, ", ") + ")"
+}
+
+// ChallengeMismatchError is returned when a user's progress row is stored under a
+// different challenge than the config goal, e.g. after goals were moved between
+// challenges. Admins list and fix such rows with GetChallengeMismatches and
+// FixChallengeMismatches.
+type ChallengeMismatchError struct {
+	GoalID string
+	// ChallengeID is the goal's challenge in the config (and the request).
+	ChallengeID string
+	// StoredChallengeID is the challenge_id of the progress row.
+	StoredChallengeID string
+}
+
+func (e *ChallengeMismatchError) Error() string {
+	return "challenge mismatch: progress of " + e.GoalID + " is stored under " + e.StoredChallengeID +
+		" but the goal belongs to " + e.ChallengeID
+}
+
+// StaleClaimError is returned when a claim's precondition fails: the goal changed
+// since the player saw it, e.g. progress written between listing and claiming.
+type StaleClaimError struct {
+	GoalID string
+	// ExpectedStatus is the status the player saw, empty when not given.
+	ExpectedStatus string
+	// Status is the goal's current status.
+	Status string
+	// UpdatedAt is when the goal's progress was last written, empty without a row.
+	UpdatedAt string
+}
+
+func (e *StaleClaimError) Error() string {
+	return "goal changed since it was listed: " + e.GoalID + " (status: " + e.Status + ", expected: " + e.ExpectedStatus + ")"
+}
+
+// ConfigInvalidError is returned when a goal's config cannot be acted on, e.g. a
+// non-positive target or reward quantity that config validation should have
+// rejected. Retrying does not help until the config is fixed.
+type ConfigInvalidError struct {
+	GoalID      string
+	ChallengeID string
+	// Reason says what is wrong, e.g. "non_positive_target".
+	Reason string
+}
+
+func (e *ConfigInvalidError) Error() string {
+	return "goal config invalid: " + e.GoalID + " (" + e.Reason + ")"
+}
+
+// InsufficientGoalsError is returned by a strict random selection when fewer
+// goals are available than requested.
+type InsufficientGoalsError struct {
+	ChallengeID string
+	Requested   int
+	// Available is the size of the pool the selection would draw from.
+	Available int
+}
+
+func (e *InsufficientGoalsError) Error() string {
+	return fmt.Sprintf("insufficient goals in challenge %s: %d requested, %d available", e.ChallengeID, e.Requested, e.Available)
+}
+
 // MapErrorToGRPCStatus converts domain errors to gRPC status codes (Decision Q6)
 func MapErrorToGRPCStatus(err error) error {
 	if err == nil {
 		return nil
 	}
 
+	// Request deadline or cancellation (claims are rolled back in this case)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "Request deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "Request cancelled")
+	}
+
 	// Check for structured error types
 	var goalNotFound *GoalNotFoundError
 	if errors.As(err, &goalNotFound) {
@@ -102,6 +316,18 @@ func MapErrorToGRPCStatus(err error) error {
 			goalNotFound.GoalID, goalNotFound.ChallengeID)
 	}
 
+	var challengeNotFound *ChallengeNotFoundError
+	if errors.As(err, &challengeNotFound) {
+		return status.Errorf(codes.NotFound, "challenge not found: %s", challengeNotFound.ChallengeID)
+	}
+
+	var goalInOtherChallenge *GoalInOtherChallengeError
+	if errors.As(err, &goalInOtherChallenge) {
+		return status.Errorf(codes.NotFound,
+			"Goal %s not found in challenge %s; it belongs to challenge %s",
+			goalInOtherChallenge.GoalID, goalInOtherChallenge.ChallengeID, goalInOtherChallenge.GoalChallengeID)
+	}
+
 	var goalNotCompleted *GoalNotCompletedError
 	if errors.As(err, &goalNotCompleted) {
 		return status.Errorf(codes.FailedPrecondition,
@@ -132,6 +358,72 @@ func MapErrorToGRPCStatus(err error) error {
 			goalRotated.GoalID, goalRotated.ChallengeID)
 	}
 
+	var overrideRefused *GoalOverrideRefusedError
+	if errors.As(err, &overrideRefused) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Goal is %s for the user; set force to override (goal_id: %s, challenge_id: %s)",
+			overrideRefused.Reason, overrideRefused.GoalID, overrideRefused.ChallengeID)
+	}
+
+	var resetRefused *ProgressResetRefusedError
+	if errors.As(err, &resetRefused) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Claimed goals cannot have their progress reset (goal_id: %s, challenge_id: %s)",
+			resetRefused.GoalID, resetRefused.ChallengeID)
+	}
+
+	var challengeMismatch *ChallengeMismatchError
+	if errors.As(err, &challengeMismatch) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Goal progress is stored under challenge %s but the goal belongs to challenge %s; contact support (goal_id: %s)",
+			challengeMismatch.StoredChallengeID, challengeMismatch.ChallengeID, challengeMismatch.GoalID)
+	}
+
+	var illegalTransition *progressstate.IllegalTransitionError
+	if errors.As(err, &illegalTransition) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Goal cannot move from %s to %s; refresh and try again",
+			illegalTransition.From, illegalTransition.To)
+	}
+
+	var configInvalid *ConfigInvalidError
+	if errors.As(err, &configInvalid) {
+		st := status.Newf(codes.FailedPrecondition,
+			"Goal is misconfigured and cannot be used until the config is fixed; contact support (goal_id: %s, challenge_id: %s, reason: %s)",
+			configInvalid.GoalID, configInvalid.ChallengeID, configInvalid.Reason)
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "CONFIG_INVALID",
+			Metadata: map[string]string{"goal_id": configInvalid.GoalID, "reason": configInvalid.Reason},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	var staleClaim *StaleClaimError
+	if errors.As(err, &staleClaim) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Goal changed since it was listed; refresh and try again (goal_id: %s, status: %s, expected_status: %s, updated_at: %s)",
+			staleClaim.GoalID, staleClaim.Status, staleClaim.ExpectedStatus, staleClaim.UpdatedAt)
+	}
+
+	var insufficientGoals *InsufficientGoalsError
+	if errors.As(err, &insufficientGoals) {
+		st := status.Newf(codes.FailedPrecondition,
+			"Not enough goals available to select (challenge_id: %s, requested: %d, available_pool_size: %d)",
+			insufficientGoals.ChallengeID, insufficientGoals.Requested, insufficientGoals.Available)
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason: "INSUFFICIENT_GOALS",
+			Metadata: map[string]string{
+				"requested":           fmt.Sprint(insufficientGoals.Requested),
+				"available_pool_size": fmt.Sprint(insufficientGoals.Available),
+			},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
 	var prerequisitesNotMet *PrerequisitesNotMetError
 	if errors.As(err, &prerequisitesNotMet) {
 		return status.Errorf(codes.FailedPrecondition,
@@ -139,6 +431,74 @@ func MapErrorToGRPCStatus(err error) error {
 			prerequisitesNotMet.GoalID)
 	}
 
+	var challengeLocked *ChallengeLockedError
+	if errors.As(err, &challengeLocked) {
+		return status.Errorf(codes.FailedPrecondition,
+			"Challenge is locked until its prerequisite challenges are completed (challenge_id: %s, prerequisite_challenge_ids: %s)",
+			challengeLocked.ChallengeID, strings.Join(challengeLocked.LockedBy, ","))
+	}
+
+	var claimCapExceeded *ClaimCapExceededError
+	if errors.As(err, &claimCapExceeded) {
+		retryAfter := claimCapExceeded.RetryAfterSeconds()
+		st := status.Newf(codes.ResourceExhausted,
+			"Claim limit reached: %d claims per %s (retry_after: %ds)",
+			claimCapExceeded.Limit, claimCapExceeded.Window, retryAfter)
+		// RetryInfo lets gRPC clients read the hint without parsing the message
+		if detailed, detailErr := st.WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(retryAfter) * time.Second),
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	var claimFrozen *ClaimFrozenError
+	if errors.As(err, &claimFrozen) {
+		frozenUntil := claimFrozen.FrozenUntil.UTC().Format(time.RFC3339)
+		st := status.Newf(codes.PermissionDenied, "Claiming rewards is suspended for this account (frozen_until: %s)", frozenUntil)
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "CLAIM_FROZEN",
+			Metadata: map[string]string{"frozen_until": frozenUntil},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	var coolingDown *GoalCoolingDownError
+	if errors.As(err, &coolingDown) {
+		availableAt := coolingDown.AvailableAt.UTC().Format(time.RFC3339)
+		st := status.Newf(codes.FailedPrecondition,
+			"Goal was claimed recently and is available again at %s (goal_id: %s)", availableAt, coolingDown.GoalID)
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "GOAL_COOLING_DOWN",
+			Metadata: map[string]string{"goal_id": coolingDown.GoalID, "available_at": availableAt},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	var grantNamespace *GrantNamespaceNotAllowedError
+	if errors.As(err, &grantNamespace) {
+		return status.Errorf(codes.PermissionDenied, "Rewards cannot be granted in namespace %s", grantNamespace.Namespace)
+	}
+
+	var grantPermanent *RewardGrantPermanentError
+	if errors.As(err, &grantPermanent) {
+		st := status.Newf(codes.FailedPrecondition,
+			"Reward cannot be granted at the moment; contact support (goal_id: %s, challenge_id: %s)",
+			grantPermanent.GoalID, grantPermanent.ChallengeID)
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "REWARD_GRANT_FAILED_PERMANENTLY",
+			Metadata: map[string]string{"goal_id": grantPermanent.GoalID, "error_class": grantPermanent.ErrorClass},
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
 	var rewardGrantErr *RewardGrantError
 	if errors.As(err, &rewardGrantErr) {
 		return status.Errorf(codes.Internal,
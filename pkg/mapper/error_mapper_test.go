@@ -1,10 +1,17 @@
 package mapper
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"extend-challenge-service/pkg/progressstate"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -24,6 +31,21 @@ func TestMapErrorToGRPCStatus_GoalNotFoundError(t *testing.T) {
 	assert.Contains(t, st.Message(), "challenge-1")
 }
 
+func TestMapErrorToGRPCStatus_GoalInOtherChallengeError(t *testing.T) {
+	err := &GoalInOtherChallengeError{
+		GoalID:          "daily-login",
+		ChallengeID:     "weekly",
+		GoalChallengeID: "daily",
+	}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "Goal daily-login not found in challenge weekly; it belongs to challenge daily", st.Message())
+}
+
 func TestMapErrorToGRPCStatus_GoalNotCompletedError(t *testing.T) {
 	err := &GoalNotCompletedError{
 		GoalID: "goal-1",
@@ -68,6 +90,91 @@ func TestMapErrorToGRPCStatus_PrerequisitesNotMetError(t *testing.T) {
 	assert.Contains(t, st.Message(), "Prerequisites not completed")
 }
 
+func TestMapErrorToGRPCStatus_IllegalTransitionError(t *testing.T) {
+	err := fmt.Errorf("write progress: %w", &progressstate.IllegalTransitionError{
+		From: domain.GoalStatusClaimed,
+		To:   domain.GoalStatusInProgress,
+	})
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "Goal cannot move from claimed to in_progress")
+}
+
+func TestMapErrorToGRPCStatus_GoalOverrideRefusedError(t *testing.T) {
+	err := &GoalOverrideRefusedError{
+		GoalID:      "goal-1",
+		ChallengeID: "challenge-1",
+		Reason:      "inactive",
+	}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "Goal is inactive for the user; set force to override")
+}
+
+func TestMapErrorToGRPCStatus_ProgressResetRefusedError(t *testing.T) {
+	grpcErr := MapErrorToGRPCStatus(&ProgressResetRefusedError{GoalID: "goal-1", ChallengeID: "challenge-1"})
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "Claimed goals cannot have their progress reset (goal_id: goal-1")
+}
+
+func TestMapErrorToGRPCStatus_StaleClaimError(t *testing.T) {
+	err := &StaleClaimError{
+		GoalID:         "goal-1",
+		ExpectedStatus: "completed",
+		Status:         "in_progress",
+		UpdatedAt:      "2025-06-01T12:00:05Z",
+	}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "Goal changed since it was listed")
+	assert.Contains(t, st.Message(), "status: in_progress, expected_status: completed")
+}
+
+func TestMapErrorToGRPCStatus_ChallengeMismatchError(t *testing.T) {
+	err := &ChallengeMismatchError{
+		GoalID:            "goal-1",
+		ChallengeID:       "weekly",
+		StoredChallengeID: "daily",
+	}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "stored under challenge daily but the goal belongs to challenge weekly")
+}
+
+func TestMapErrorToGRPCStatus_ChallengeLockedError(t *testing.T) {
+	err := fmt.Errorf("claim: %w", &ChallengeLockedError{
+		ChallengeID: "season-2",
+		LockedBy:    []string{"season-1", "tutorial"},
+	})
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "challenge_id: season-2")
+	assert.Contains(t, st.Message(), "prerequisite_challenge_ids: season-1,tutorial")
+}
+
 func TestMapErrorToGRPCStatus_RewardGrantError(t *testing.T) {
 	err := &RewardGrantError{
 		GoalID: "goal-1",
@@ -83,6 +190,137 @@ func TestMapErrorToGRPCStatus_RewardGrantError(t *testing.T) {
 	assert.Contains(t, st.Message(), "3 retries")
 }
 
+func TestMapErrorToGRPCStatus_ClaimCapExceededError(t *testing.T) {
+	err := &ClaimCapExceededError{
+		UserID:     "user-1",
+		Limit:      50,
+		Window:     24 * time.Hour,
+		RetryAfter: 90*time.Minute + 500*time.Millisecond,
+	}
+
+	grpcErr := MapErrorToGRPCStatus(fmt.Errorf("claim rejected: %w", err))
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Contains(t, st.Message(), "retry_after: 5401s")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		retryInfo, ok := details[0].(*errdetails.RetryInfo)
+		assert.True(t, ok)
+		assert.Equal(t, 5401*time.Second, retryInfo.RetryDelay.AsDuration())
+	}
+}
+
+func TestMapErrorToGRPCStatus_ClaimFrozenError(t *testing.T) {
+	err := &ClaimFrozenError{UserID: "user-1", FrozenUntil: time.Date(2026, 3, 12, 8, 30, 0, 0, time.UTC)}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Contains(t, st.Message(), "frozen_until: 2026-03-12T08:30:00Z")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "CLAIM_FROZEN", errorInfo.Reason)
+		assert.Equal(t, "2026-03-12T08:30:00Z", errorInfo.Metadata["frozen_until"])
+	}
+}
+
+func TestMapErrorToGRPCStatus_GoalCoolingDownError(t *testing.T) {
+	err := &GoalCoolingDownError{GoalID: "daily-win", AvailableAt: time.Date(2026, 3, 12, 8, 30, 0, 0, time.UTC)}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "available again at 2026-03-12T08:30:00Z (goal_id: daily-win)")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "GOAL_COOLING_DOWN", errorInfo.Reason)
+		assert.Equal(t, "2026-03-12T08:30:00Z", errorInfo.Metadata["available_at"])
+	}
+}
+
+func TestMapErrorToGRPCStatus_GrantNamespaceNotAllowedError(t *testing.T) {
+	grpcErr := MapErrorToGRPCStatus(&GrantNamespaceNotAllowedError{Namespace: "other-game"})
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Contains(t, st.Message(), "other-game")
+}
+
+func TestMapErrorToGRPCStatus_ConfigInvalidError(t *testing.T) {
+	err := fmt.Errorf("claim failed: %w", &ConfigInvalidError{GoalID: "goal-1", ChallengeID: "daily", Reason: "non_positive_target"})
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "goal_id: goal-1")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "CONFIG_INVALID", errorInfo.Reason)
+		assert.Equal(t, "non_positive_target", errorInfo.Metadata["reason"])
+	}
+}
+
+func TestMapErrorToGRPCStatus_RewardGrantPermanentError(t *testing.T) {
+	err := &RewardGrantPermanentError{
+		GoalID:      "goal-1",
+		ChallengeID: "daily",
+		ErrorClass:  "not_found",
+		Err:         errors.New("resource not found: item sword"),
+	}
+
+	grpcErr := MapErrorToGRPCStatus(err)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "contact support")
+	assert.NotContains(t, st.Message(), "sword", "AGS error stays internal")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "REWARD_GRANT_FAILED_PERMANENTLY", errorInfo.Reason)
+		assert.Equal(t, "not_found", errorInfo.Metadata["error_class"])
+	}
+}
+
+func TestMapErrorToGRPCStatus_InsufficientGoalsError(t *testing.T) {
+	grpcErr := MapErrorToGRPCStatus(&InsufficientGoalsError{ChallengeID: "daily", Requested: 5, Available: 2})
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "requested: 5, available_pool_size: 2")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "INSUFFICIENT_GOALS", errorInfo.Reason)
+		assert.Equal(t, "2", errorInfo.Metadata["available_pool_size"])
+	}
+}
+
 func TestMapErrorToGRPCStatus_SentinelErrGoalNotFound(t *testing.T) {
 	grpcErr := MapErrorToGRPCStatus(ErrGoalNotFound)
 
@@ -123,6 +361,22 @@ func TestMapErrorToGRPCStatus_SentinelErrRewardGrantFailed(t *testing.T) {
 	assert.Equal(t, codes.Internal, st.Code())
 }
 
+func TestMapErrorToGRPCStatus_DeadlineExceeded(t *testing.T) {
+	grpcErr := MapErrorToGRPCStatus(fmt.Errorf("context cancelled during retry: %w", context.DeadlineExceeded))
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.DeadlineExceeded, st.Code())
+}
+
+func TestMapErrorToGRPCStatus_Canceled(t *testing.T) {
+	grpcErr := MapErrorToGRPCStatus(context.Canceled)
+
+	st, ok := status.FromError(grpcErr)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Canceled, st.Code())
+}
+
 func TestMapErrorToGRPCStatus_SentinelErrDatabaseError(t *testing.T) {
 	grpcErr := MapErrorToGRPCStatus(ErrDatabaseError)
 
@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Timestamps in API responses are UTC and truncated to whole seconds, so the gRPC
+// gateway (google.protobuf.Timestamp) and the optimized HTTP handlers (RFC3339
+// strings) render the same instant identically, e.g. "2025-01-15T10:30:00Z".
+// Unset timestamps are null on both, as the gateway writes an unset Timestamp.
+//
+// Times read from PostgreSQL or computed with time.Now() may carry the server's
+// local zone; formatting them without converting to UTC produces offsets such as
+// "+02:00" that change across DST transitions.
+
+// FormatTimestamp formats t as an RFC3339 string in UTC.
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+// AppendTimestamp appends t formatted by FormatTimestamp to dst.
+func AppendTimestamp(dst []byte, t time.Time) []byte {
+	return t.UTC().Truncate(time.Second).AppendFormat(dst, time.RFC3339)
+}
+
+// FormatOptionalTimestamp formats t like FormatTimestamp, or returns "" for nil or zero times.
+func FormatOptionalTimestamp(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return FormatTimestamp(*t)
+}
+
+// FormatNullableTimestamp formats t like FormatTimestamp, or returns nil for nil
+// or zero times, for JSON fields that are null when unset.
+func FormatNullableTimestamp(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	formatted := FormatTimestamp(*t)
+	return &formatted
+}
+
+// AppendNullableTimestamp appends t formatted by FormatTimestamp as a JSON
+// string to dst, or null for nil or zero times.
+func AppendNullableTimestamp(dst []byte, t *time.Time) []byte {
+	if t == nil || t.IsZero() {
+		return append(dst, "null"...)
+	}
+	dst = append(dst, '"')
+	dst = AppendTimestamp(dst, *t)
+	return append(dst, '"')
+}
+
+// ToProtoTimestamp converts t to a google.protobuf.Timestamp in whole seconds.
+// Returns nil for nil or zero times.
+func ToProtoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t.UTC().Truncate(time.Second))
+}
@@ -0,0 +1,138 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+
+	pb "extend-challenge-service/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// setLocalZone makes time.Local a non-UTC zone for the duration of the test, so
+// times created with time.Now() or read from the database carry an offset.
+func setLocalZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+
+	original := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = original })
+
+	return loc
+}
+
+func TestFormatTimestamp_ConvertsToUTC(t *testing.T) {
+	loc := setLocalZone(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		input    time.Time
+		expected string
+	}{
+		{"standard time", time.Date(2025, 1, 15, 5, 30, 0, 0, loc), "2025-01-15T10:30:00Z"},
+		{"daylight saving time", time.Date(2025, 7, 15, 6, 30, 0, 0, loc), "2025-07-15T10:30:00Z"},
+		{"before spring forward", time.Date(2025, 3, 9, 1, 59, 59, 0, loc), "2025-03-09T06:59:59Z"},
+		{"after spring forward", time.Date(2025, 3, 9, 3, 0, 0, 0, loc), "2025-03-09T07:00:00Z"},
+		{"fractional seconds", time.Date(2025, 1, 15, 5, 30, 0, 999999999, loc), "2025-01-15T10:30:00Z"},
+		{"positive offset", time.Date(2025, 1, 15, 17, 30, 0, 0, time.FixedZone("WIB", 7*3600)), "2025-01-15T10:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatTimestamp(tt.input))
+			assert.Equal(t, tt.expected, string(AppendTimestamp(nil, tt.input)))
+		})
+	}
+}
+
+func TestFormatTimestamp_RoundTrip(t *testing.T) {
+	loc := setLocalZone(t, "Europe/Berlin")
+
+	// Fall back: 02:30 occurs twice on 2025-10-26 in Berlin.
+	original := time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC).In(loc)
+
+	parsed, err := time.Parse(time.RFC3339, FormatTimestamp(original))
+
+	require.NoError(t, err)
+	assert.True(t, original.Equal(parsed))
+	assert.Equal(t, time.UTC, parsed.Location())
+}
+
+func TestFormatOptionalTimestamp(t *testing.T) {
+	loc := setLocalZone(t, "Asia/Tokyo")
+	ts := time.Date(2025, 1, 15, 19, 30, 0, 0, loc)
+	var zero time.Time
+
+	assert.Equal(t, "2025-01-15T10:30:00Z", FormatOptionalTimestamp(&ts))
+	assert.Equal(t, "", FormatOptionalTimestamp(nil))
+	assert.Equal(t, "", FormatOptionalTimestamp(&zero))
+}
+
+func TestNullableTimestamp(t *testing.T) {
+	loc := setLocalZone(t, "Asia/Tokyo")
+	ts := time.Date(2025, 1, 15, 19, 30, 0, 0, loc)
+	var zero time.Time
+
+	require.NotNil(t, FormatNullableTimestamp(&ts))
+	assert.Equal(t, "2025-01-15T10:30:00Z", *FormatNullableTimestamp(&ts))
+	assert.Nil(t, FormatNullableTimestamp(nil))
+	assert.Nil(t, FormatNullableTimestamp(&zero))
+
+	assert.Equal(t, `"2025-01-15T10:30:00Z"`, string(AppendNullableTimestamp(nil, &ts)))
+	assert.Equal(t, "null", string(AppendNullableTimestamp(nil, nil)))
+	assert.Equal(t, "null", string(AppendNullableTimestamp(nil, &zero)))
+}
+
+// The Timestamp fields that replaced string fields must not reuse their
+// numbers, which clients built on the old messages still decode as strings.
+func TestTimestampFields_StringNumbersReserved(t *testing.T) {
+	fields := []struct {
+		message protoreflect.ProtoMessage
+		field   protoreflect.Name
+		string  protoreflect.FieldNumber
+	}{
+		{&pb.SetGoalActiveResponse{}, "assigned_at", 4},
+		{&pb.ClaimRewardResponse{}, "claimed_at", 4},
+		{&pb.SelectedGoal{}, "assigned_at", 10},
+		{&pb.SelectedGoal{}, "expires_at", 11},
+		{&pb.Goal{}, "completed_at", 10},
+		{&pb.Goal{}, "claimed_at", 11},
+		{&pb.Goal{}, "expires_at", 13},
+		{&pb.AssignedGoal{}, "assigned_at", 6},
+		{&pb.AssignedGoal{}, "expires_at", 7},
+		{&pb.RotationPeriod{}, "start_time", 1},
+		{&pb.RotationPeriod{}, "end_time", 2},
+	}
+
+	for _, f := range fields {
+		desc := f.message.ProtoReflect().Descriptor()
+		field := desc.Fields().ByName(f.field)
+		require.NotNil(t, field, "%s.%s", desc.Name(), f.field)
+		assert.NotEqual(t, f.string, field.Number(), "%s.%s", desc.Name(), f.field)
+		assert.True(t, desc.ReservedRanges().Has(f.string), "%s reserves %d", desc.Name(), f.string)
+	}
+}
+
+func TestToProtoTimestamp_RoundTrip(t *testing.T) {
+	loc := setLocalZone(t, "America/New_York")
+	ts := time.Date(2025, 3, 9, 3, 0, 0, 500, loc)
+
+	pbTime := ToProtoTimestamp(&ts)
+
+	require.NotNil(t, pbTime)
+	assert.Equal(t, int32(0), pbTime.Nanos)
+	assert.True(t, ts.Truncate(time.Second).Equal(pbTime.AsTime()))
+	assert.Equal(t, FormatTimestamp(ts), FormatTimestamp(pbTime.AsTime()))
+}
+
+func TestToProtoTimestamp_NilAndZero(t *testing.T) {
+	var zero time.Time
+
+	assert.Nil(t, ToProtoTimestamp(nil))
+	assert.Nil(t, ToProtoTimestamp(&zero))
+}
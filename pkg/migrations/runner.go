@@ -6,10 +6,13 @@ package migrations
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
@@ -40,3 +43,42 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 
 	return nil
 }
+
+// PendingMigrations is a dry run of RunMigrations: it reads the migrations at
+// migrationsPath and returns how many are not applied yet, without applying
+// them (the driver only creates its version table if missing). It fails if the
+// database is left dirty by an interrupted migration.
+func PendingMigrations(db *sql.DB, migrationsPath string) (int, error) {
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migrate driver: %w", err)
+	}
+
+	current, dirty, err := driver.Version()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at migration version %d", current)
+	}
+
+	src, err := source.Open(migrationsPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	pending := 0
+	version, err := src.First()
+	for err == nil {
+		if int(version) > current {
+			pending++
+		}
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	return pending, nil
+}
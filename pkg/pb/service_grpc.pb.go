@@ -23,14 +23,46 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Service_GetUserChallenges_FullMethodName = "/service.Service/GetUserChallenges"
-	Service_InitializePlayer_FullMethodName  = "/service.Service/InitializePlayer"
-	Service_SetGoalActive_FullMethodName     = "/service.Service/SetGoalActive"
-	Service_ClaimGoalReward_FullMethodName   = "/service.Service/ClaimGoalReward"
-	Service_BatchSelectGoals_FullMethodName  = "/service.Service/BatchSelectGoals"
-	Service_RandomSelectGoals_FullMethodName = "/service.Service/RandomSelectGoals"
-	Service_GetRotationStatus_FullMethodName = "/service.Service/GetRotationStatus"
-	Service_HealthCheck_FullMethodName       = "/service.Service/HealthCheck"
+	Service_GetUserChallenges_FullMethodName      = "/service.Service/GetUserChallenges"
+	Service_GetChallenge_FullMethodName           = "/service.Service/GetChallenge"
+	Service_GetProgressSummary_FullMethodName     = "/service.Service/GetProgressSummary"
+	Service_GetUnclaimedCount_FullMethodName      = "/service.Service/GetUnclaimedCount"
+	Service_GetActiveGoals_FullMethodName         = "/service.Service/GetActiveGoals"
+	Service_InitializePlayer_FullMethodName       = "/service.Service/InitializePlayer"
+	Service_StartSession_FullMethodName           = "/service.Service/StartSession"
+	Service_SetGoalActive_FullMethodName          = "/service.Service/SetGoalActive"
+	Service_GetActivationPreflight_FullMethodName = "/service.Service/GetActivationPreflight"
+	Service_ClaimGoalReward_FullMethodName        = "/service.Service/ClaimGoalReward"
+	Service_ClaimAllCompleted_FullMethodName      = "/service.Service/ClaimAllCompleted"
+	Service_BatchSelectGoals_FullMethodName       = "/service.Service/BatchSelectGoals"
+	Service_RandomSelectGoals_FullMethodName      = "/service.Service/RandomSelectGoals"
+	Service_GetAvailableGoals_FullMethodName      = "/service.Service/GetAvailableGoals"
+	Service_GetRotationStatus_FullMethodName      = "/service.Service/GetRotationStatus"
+	Service_ReloadConfig_FullMethodName           = "/service.Service/ReloadConfig"
+	Service_GetConfigSchema_FullMethodName        = "/service.Service/GetConfigSchema"
+	Service_GetClaimCap_FullMethodName            = "/service.Service/GetClaimCap"
+	Service_ResetClaimCap_FullMethodName          = "/service.Service/ResetClaimCap"
+	Service_SetClaimFreeze_FullMethodName         = "/service.Service/SetClaimFreeze"
+	Service_ListClaimFreezes_FullMethodName       = "/service.Service/ListClaimFreezes"
+	Service_RemoveClaimFreeze_FullMethodName      = "/service.Service/RemoveClaimFreeze"
+	Service_ListFailedGrants_FullMethodName       = "/service.Service/ListFailedGrants"
+	Service_RetryFailedGrant_FullMethodName       = "/service.Service/RetryFailedGrant"
+	Service_ForceCompleteGoal_FullMethodName      = "/service.Service/ForceCompleteGoal"
+	Service_RecomputeUserStatuses_FullMethodName  = "/service.Service/RecomputeUserStatuses"
+	Service_GetGoalStats_FullMethodName           = "/service.Service/GetGoalStats"
+	Service_GetSelectionHistory_FullMethodName    = "/service.Service/GetSelectionHistory"
+	Service_GetUserRollouts_FullMethodName        = "/service.Service/GetUserRollouts"
+	Service_GetSelectionStats_FullMethodName      = "/service.Service/GetSelectionStats"
+	Service_GetChallengeMismatches_FullMethodName = "/service.Service/GetChallengeMismatches"
+	Service_FixChallengeMismatches_FullMethodName = "/service.Service/FixChallengeMismatches"
+	Service_BackfillDefaultGoal_FullMethodName    = "/service.Service/BackfillDefaultGoal"
+	Service_GetBackfillJob_FullMethodName         = "/service.Service/GetBackfillJob"
+	Service_BulkActivateGoal_FullMethodName       = "/service.Service/BulkActivateGoal"
+	Service_GetBulkActivationJob_FullMethodName   = "/service.Service/GetBulkActivationJob"
+	Service_BatchReportProgress_FullMethodName    = "/service.Service/BatchReportProgress"
+	Service_ReportMatchResult_FullMethodName      = "/service.Service/ReportMatchResult"
+	Service_GetServiceState_FullMethodName        = "/service.Service/GetServiceState"
+	Service_HealthCheck_FullMethodName            = "/service.Service/HealthCheck"
 )
 
 // ServiceClient is the client API for Service service.
@@ -40,18 +72,85 @@ type ServiceClient interface {
 	// Get all challenges with user progress
 	// NOTE: HTTP endpoint uses OptimizedChallengesHandler (see docs/ADR_001_OPTIMIZED_HTTP_HANDLER.md)
 	GetUserChallenges(ctx context.Context, in *GetChallengesRequest, opts ...grpc.CallOption) (*GetChallengesResponse, error)
+	// Get one challenge with user progress
+	// NOTE: HTTP endpoint uses OptimizedChallengesHandler.ServeChallenge. It must stay
+	// declared before GetProgressSummary and GetUnclaimedCount so /v1/challenges/summary
+	// and /v1/challenges/unclaimed-count take precedence.
+	GetChallenge(ctx context.Context, in *GetChallengeRequest, opts ...grpc.CallOption) (*GetChallengeResponse, error)
+	// Account-wide progress summary (goal counts by status per challenge)
+	GetProgressSummary(ctx context.Context, in *GetProgressSummaryRequest, opts ...grpc.CallOption) (*GetProgressSummaryResponse, error)
+	// Badge counts: completed-but-unclaimed and in-progress goals
+	GetUnclaimedCount(ctx context.Context, in *GetUnclaimedCountRequest, opts ...grpc.CallOption) (*GetUnclaimedCountResponse, error)
+	// Get the authenticated user's active goals in assignment order
+	GetActiveGoals(ctx context.Context, in *GetActiveGoalsRequest, opts ...grpc.CallOption) (*GetActiveGoalsResponse, error)
 	// Initialize player with default goals (M3 Phase 2)
 	InitializePlayer(ctx context.Context, in *InitializeRequest, opts ...grpc.CallOption) (*InitializeResponse, error)
+	// Player state for session start: initialize, summary and unclaimed counts in one call
+	StartSession(ctx context.Context, in *StartSessionRequest, opts ...grpc.CallOption) (*StartSessionResponse, error)
 	// Set goal active/inactive (M3 Phase 3)
 	SetGoalActive(ctx context.Context, in *SetGoalActiveRequest, opts ...grpc.CallOption) (*SetGoalActiveResponse, error)
+	// Whether SetGoalActive would activate a goal, before showing an activate toggle
+	GetActivationPreflight(ctx context.Context, in *GetActivationPreflightRequest, opts ...grpc.CallOption) (*GetActivationPreflightResponse, error)
 	// Claim reward for completed goal
 	ClaimGoalReward(ctx context.Context, in *ClaimRewardRequest, opts ...grpc.CallOption) (*ClaimRewardResponse, error)
+	// Claim every completed goal across all challenges
+	ClaimAllCompleted(ctx context.Context, in *ClaimAllCompletedRequest, opts ...grpc.CallOption) (*ClaimAllCompletedResponse, error)
 	// M4: Batch manual goal selection
 	BatchSelectGoals(ctx context.Context, in *BatchSelectRequest, opts ...grpc.CallOption) (*GoalSelectionResponse, error)
 	// M4: Random goal selection
 	RandomSelectGoals(ctx context.Context, in *RandomSelectRequest, opts ...grpc.CallOption) (*GoalSelectionResponse, error)
+	// Goals a random selection could draw from, e.g. to disable a reroll button
+	GetAvailableGoals(ctx context.Context, in *GetAvailableGoalsRequest, opts ...grpc.CallOption) (*GetAvailableGoalsResponse, error)
 	// M5: Get rotation status for a challenge
 	GetRotationStatus(ctx context.Context, in *GetRotationStatusRequest, opts ...grpc.CallOption) (*GetRotationStatusResponse, error)
+	// Admin: reload challenges.json and report what changed
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error)
+	// Admin: JSON Schema of challenges.json, for editors and config tooling
+	GetConfigSchema(ctx context.Context, in *GetConfigSchemaRequest, opts ...grpc.CallOption) (*GetConfigSchemaResponse, error)
+	// Admin: view a user's claim count against the rolling claim cap
+	GetClaimCap(ctx context.Context, in *GetClaimCapRequest, opts ...grpc.CallOption) (*ClaimCapStatus, error)
+	// Admin: clear a user's claim count
+	ResetClaimCap(ctx context.Context, in *ResetClaimCapRequest, opts ...grpc.CallOption) (*ResetClaimCapResponse, error)
+	// Admin: stop a user from claiming rewards until a given time
+	SetClaimFreeze(ctx context.Context, in *SetClaimFreezeRequest, opts ...grpc.CallOption) (*ClaimFreeze, error)
+	// Admin: list users whose claims are frozen
+	ListClaimFreezes(ctx context.Context, in *ListClaimFreezesRequest, opts ...grpc.CallOption) (*ListClaimFreezesResponse, error)
+	// Admin: lift a user's claim freeze before it expires
+	RemoveClaimFreeze(ctx context.Context, in *RemoveClaimFreezeRequest, opts ...grpc.CallOption) (*RemoveClaimFreezeResponse, error)
+	// Admin: list claims whose reward grant failed permanently
+	ListFailedGrants(ctx context.Context, in *ListFailedGrantsRequest, opts ...grpc.CallOption) (*ListFailedGrantsResponse, error)
+	// Admin: retry a failed reward grant, e.g. after the store item is restored
+	RetryFailedGrant(ctx context.Context, in *RetryFailedGrantRequest, opts ...grpc.CallOption) (*RetryFailedGrantResponse, error)
+	// Admin: complete a goal on a user's behalf, e.g. for a lost stat event
+	ForceCompleteGoal(ctx context.Context, in *ForceCompleteGoalRequest, opts ...grpc.CallOption) (*ForceCompleteGoalResponse, error)
+	// Admin: re-evaluate a user's goal statuses against the current config targets
+	RecomputeUserStatuses(ctx context.Context, in *RecomputeUserStatusesRequest, opts ...grpc.CallOption) (*RecomputeUserStatusesResponse, error)
+	// Admin: per-goal completion counts for tuning
+	GetGoalStats(ctx context.Context, in *GetGoalStatsRequest, opts ...grpc.CallOption) (*GetGoalStatsResponse, error)
+	// Admin: list a user's goal selections
+	GetSelectionHistory(ctx context.Context, in *GetSelectionHistoryRequest, opts ...grpc.CallOption) (*GetSelectionHistoryResponse, error)
+	// Admin: show which rolled-out challenges a user is eligible for
+	GetUserRollouts(ctx context.Context, in *GetUserRolloutsRequest, opts ...grpc.CallOption) (*GetUserRolloutsResponse, error)
+	// Admin: count goal selections per goal
+	GetSelectionStats(ctx context.Context, in *GetSelectionStatsRequest, opts ...grpc.CallOption) (*GetSelectionStatsResponse, error)
+	// Admin: list progress rows stored under a different challenge than their goal in the config
+	GetChallengeMismatches(ctx context.Context, in *GetChallengeMismatchesRequest, opts ...grpc.CallOption) (*GetChallengeMismatchesResponse, error)
+	// Admin: rewrite mismatched progress rows to their goal's challenge in the config
+	FixChallengeMismatches(ctx context.Context, in *FixChallengeMismatchesRequest, opts ...grpc.CallOption) (*FixChallengeMismatchesResponse, error)
+	// Admin: create a default goal's progress rows for existing users
+	BackfillDefaultGoal(ctx context.Context, in *BackfillDefaultGoalRequest, opts ...grpc.CallOption) (*BackfillJob, error)
+	// Admin: get the status of a default goal backfill
+	GetBackfillJob(ctx context.Context, in *GetBackfillJobRequest, opts ...grpc.CallOption) (*BackfillJob, error)
+	// Admin: activate a goal for a list of users
+	BulkActivateGoal(ctx context.Context, in *BulkActivateGoalRequest, opts ...grpc.CallOption) (*BulkActivationJob, error)
+	// Admin: get the status of a bulk goal activation
+	GetBulkActivationJob(ctx context.Context, in *GetBulkActivationJobRequest, opts ...grpc.CallOption) (*BulkActivationJob, error)
+	// Game server: report stat updates for many players at once
+	BatchReportProgress(ctx context.Context, in *BatchReportProgressRequest, opts ...grpc.CallOption) (*BatchReportProgressResponse, error)
+	// Game server: report one player's match result to the goals tracking matches
+	ReportMatchResult(ctx context.Context, in *ReportMatchResultRequest, opts ...grpc.CallOption) (*ReportMatchResultResponse, error)
+	// Admin: the instance's config, cache and runtime state, for debugging
+	GetServiceState(ctx context.Context, in *GetServiceStateRequest, opts ...grpc.CallOption) (*GetServiceStateResponse, error)
 	// Health check endpoint (Decision FQ5)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -73,6 +172,42 @@ func (c *serviceClient) GetUserChallenges(ctx context.Context, in *GetChallenges
 	return out, nil
 }
 
+func (c *serviceClient) GetChallenge(ctx context.Context, in *GetChallengeRequest, opts ...grpc.CallOption) (*GetChallengeResponse, error) {
+	out := new(GetChallengeResponse)
+	err := c.cc.Invoke(ctx, Service_GetChallenge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetProgressSummary(ctx context.Context, in *GetProgressSummaryRequest, opts ...grpc.CallOption) (*GetProgressSummaryResponse, error) {
+	out := new(GetProgressSummaryResponse)
+	err := c.cc.Invoke(ctx, Service_GetProgressSummary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetUnclaimedCount(ctx context.Context, in *GetUnclaimedCountRequest, opts ...grpc.CallOption) (*GetUnclaimedCountResponse, error) {
+	out := new(GetUnclaimedCountResponse)
+	err := c.cc.Invoke(ctx, Service_GetUnclaimedCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetActiveGoals(ctx context.Context, in *GetActiveGoalsRequest, opts ...grpc.CallOption) (*GetActiveGoalsResponse, error) {
+	out := new(GetActiveGoalsResponse)
+	err := c.cc.Invoke(ctx, Service_GetActiveGoals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) InitializePlayer(ctx context.Context, in *InitializeRequest, opts ...grpc.CallOption) (*InitializeResponse, error) {
 	out := new(InitializeResponse)
 	err := c.cc.Invoke(ctx, Service_InitializePlayer_FullMethodName, in, out, opts...)
@@ -82,6 +217,15 @@ func (c *serviceClient) InitializePlayer(ctx context.Context, in *InitializeRequ
 	return out, nil
 }
 
+func (c *serviceClient) StartSession(ctx context.Context, in *StartSessionRequest, opts ...grpc.CallOption) (*StartSessionResponse, error) {
+	out := new(StartSessionResponse)
+	err := c.cc.Invoke(ctx, Service_StartSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) SetGoalActive(ctx context.Context, in *SetGoalActiveRequest, opts ...grpc.CallOption) (*SetGoalActiveResponse, error) {
 	out := new(SetGoalActiveResponse)
 	err := c.cc.Invoke(ctx, Service_SetGoalActive_FullMethodName, in, out, opts...)
@@ -91,6 +235,15 @@ func (c *serviceClient) SetGoalActive(ctx context.Context, in *SetGoalActiveRequ
 	return out, nil
 }
 
+func (c *serviceClient) GetActivationPreflight(ctx context.Context, in *GetActivationPreflightRequest, opts ...grpc.CallOption) (*GetActivationPreflightResponse, error) {
+	out := new(GetActivationPreflightResponse)
+	err := c.cc.Invoke(ctx, Service_GetActivationPreflight_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) ClaimGoalReward(ctx context.Context, in *ClaimRewardRequest, opts ...grpc.CallOption) (*ClaimRewardResponse, error) {
 	out := new(ClaimRewardResponse)
 	err := c.cc.Invoke(ctx, Service_ClaimGoalReward_FullMethodName, in, out, opts...)
@@ -100,6 +253,15 @@ func (c *serviceClient) ClaimGoalReward(ctx context.Context, in *ClaimRewardRequ
 	return out, nil
 }
 
+func (c *serviceClient) ClaimAllCompleted(ctx context.Context, in *ClaimAllCompletedRequest, opts ...grpc.CallOption) (*ClaimAllCompletedResponse, error) {
+	out := new(ClaimAllCompletedResponse)
+	err := c.cc.Invoke(ctx, Service_ClaimAllCompleted_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) BatchSelectGoals(ctx context.Context, in *BatchSelectRequest, opts ...grpc.CallOption) (*GoalSelectionResponse, error) {
 	out := new(GoalSelectionResponse)
 	err := c.cc.Invoke(ctx, Service_BatchSelectGoals_FullMethodName, in, out, opts...)
@@ -118,6 +280,15 @@ func (c *serviceClient) RandomSelectGoals(ctx context.Context, in *RandomSelectR
 	return out, nil
 }
 
+func (c *serviceClient) GetAvailableGoals(ctx context.Context, in *GetAvailableGoalsRequest, opts ...grpc.CallOption) (*GetAvailableGoalsResponse, error) {
+	out := new(GetAvailableGoalsResponse)
+	err := c.cc.Invoke(ctx, Service_GetAvailableGoals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) GetRotationStatus(ctx context.Context, in *GetRotationStatusRequest, opts ...grpc.CallOption) (*GetRotationStatusResponse, error) {
 	out := new(GetRotationStatusResponse)
 	err := c.cc.Invoke(ctx, Service_GetRotationStatus_FullMethodName, in, out, opts...)
@@ -127,6 +298,222 @@ func (c *serviceClient) GetRotationStatus(ctx context.Context, in *GetRotationSt
 	return out, nil
 }
 
+func (c *serviceClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error) {
+	out := new(ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, Service_ReloadConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetConfigSchema(ctx context.Context, in *GetConfigSchemaRequest, opts ...grpc.CallOption) (*GetConfigSchemaResponse, error) {
+	out := new(GetConfigSchemaResponse)
+	err := c.cc.Invoke(ctx, Service_GetConfigSchema_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetClaimCap(ctx context.Context, in *GetClaimCapRequest, opts ...grpc.CallOption) (*ClaimCapStatus, error) {
+	out := new(ClaimCapStatus)
+	err := c.cc.Invoke(ctx, Service_GetClaimCap_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ResetClaimCap(ctx context.Context, in *ResetClaimCapRequest, opts ...grpc.CallOption) (*ResetClaimCapResponse, error) {
+	out := new(ResetClaimCapResponse)
+	err := c.cc.Invoke(ctx, Service_ResetClaimCap_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) SetClaimFreeze(ctx context.Context, in *SetClaimFreezeRequest, opts ...grpc.CallOption) (*ClaimFreeze, error) {
+	out := new(ClaimFreeze)
+	err := c.cc.Invoke(ctx, Service_SetClaimFreeze_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ListClaimFreezes(ctx context.Context, in *ListClaimFreezesRequest, opts ...grpc.CallOption) (*ListClaimFreezesResponse, error) {
+	out := new(ListClaimFreezesResponse)
+	err := c.cc.Invoke(ctx, Service_ListClaimFreezes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RemoveClaimFreeze(ctx context.Context, in *RemoveClaimFreezeRequest, opts ...grpc.CallOption) (*RemoveClaimFreezeResponse, error) {
+	out := new(RemoveClaimFreezeResponse)
+	err := c.cc.Invoke(ctx, Service_RemoveClaimFreeze_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ListFailedGrants(ctx context.Context, in *ListFailedGrantsRequest, opts ...grpc.CallOption) (*ListFailedGrantsResponse, error) {
+	out := new(ListFailedGrantsResponse)
+	err := c.cc.Invoke(ctx, Service_ListFailedGrants_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RetryFailedGrant(ctx context.Context, in *RetryFailedGrantRequest, opts ...grpc.CallOption) (*RetryFailedGrantResponse, error) {
+	out := new(RetryFailedGrantResponse)
+	err := c.cc.Invoke(ctx, Service_RetryFailedGrant_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ForceCompleteGoal(ctx context.Context, in *ForceCompleteGoalRequest, opts ...grpc.CallOption) (*ForceCompleteGoalResponse, error) {
+	out := new(ForceCompleteGoalResponse)
+	err := c.cc.Invoke(ctx, Service_ForceCompleteGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RecomputeUserStatuses(ctx context.Context, in *RecomputeUserStatusesRequest, opts ...grpc.CallOption) (*RecomputeUserStatusesResponse, error) {
+	out := new(RecomputeUserStatusesResponse)
+	err := c.cc.Invoke(ctx, Service_RecomputeUserStatuses_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetGoalStats(ctx context.Context, in *GetGoalStatsRequest, opts ...grpc.CallOption) (*GetGoalStatsResponse, error) {
+	out := new(GetGoalStatsResponse)
+	err := c.cc.Invoke(ctx, Service_GetGoalStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetSelectionHistory(ctx context.Context, in *GetSelectionHistoryRequest, opts ...grpc.CallOption) (*GetSelectionHistoryResponse, error) {
+	out := new(GetSelectionHistoryResponse)
+	err := c.cc.Invoke(ctx, Service_GetSelectionHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetUserRollouts(ctx context.Context, in *GetUserRolloutsRequest, opts ...grpc.CallOption) (*GetUserRolloutsResponse, error) {
+	out := new(GetUserRolloutsResponse)
+	err := c.cc.Invoke(ctx, Service_GetUserRollouts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetSelectionStats(ctx context.Context, in *GetSelectionStatsRequest, opts ...grpc.CallOption) (*GetSelectionStatsResponse, error) {
+	out := new(GetSelectionStatsResponse)
+	err := c.cc.Invoke(ctx, Service_GetSelectionStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetChallengeMismatches(ctx context.Context, in *GetChallengeMismatchesRequest, opts ...grpc.CallOption) (*GetChallengeMismatchesResponse, error) {
+	out := new(GetChallengeMismatchesResponse)
+	err := c.cc.Invoke(ctx, Service_GetChallengeMismatches_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) FixChallengeMismatches(ctx context.Context, in *FixChallengeMismatchesRequest, opts ...grpc.CallOption) (*FixChallengeMismatchesResponse, error) {
+	out := new(FixChallengeMismatchesResponse)
+	err := c.cc.Invoke(ctx, Service_FixChallengeMismatches_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) BackfillDefaultGoal(ctx context.Context, in *BackfillDefaultGoalRequest, opts ...grpc.CallOption) (*BackfillJob, error) {
+	out := new(BackfillJob)
+	err := c.cc.Invoke(ctx, Service_BackfillDefaultGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetBackfillJob(ctx context.Context, in *GetBackfillJobRequest, opts ...grpc.CallOption) (*BackfillJob, error) {
+	out := new(BackfillJob)
+	err := c.cc.Invoke(ctx, Service_GetBackfillJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) BulkActivateGoal(ctx context.Context, in *BulkActivateGoalRequest, opts ...grpc.CallOption) (*BulkActivationJob, error) {
+	out := new(BulkActivationJob)
+	err := c.cc.Invoke(ctx, Service_BulkActivateGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetBulkActivationJob(ctx context.Context, in *GetBulkActivationJobRequest, opts ...grpc.CallOption) (*BulkActivationJob, error) {
+	out := new(BulkActivationJob)
+	err := c.cc.Invoke(ctx, Service_GetBulkActivationJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) BatchReportProgress(ctx context.Context, in *BatchReportProgressRequest, opts ...grpc.CallOption) (*BatchReportProgressResponse, error) {
+	out := new(BatchReportProgressResponse)
+	err := c.cc.Invoke(ctx, Service_BatchReportProgress_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ReportMatchResult(ctx context.Context, in *ReportMatchResultRequest, opts ...grpc.CallOption) (*ReportMatchResultResponse, error) {
+	out := new(ReportMatchResultResponse)
+	err := c.cc.Invoke(ctx, Service_ReportMatchResult_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetServiceState(ctx context.Context, in *GetServiceStateRequest, opts ...grpc.CallOption) (*GetServiceStateResponse, error) {
+	out := new(GetServiceStateResponse)
+	err := c.cc.Invoke(ctx, Service_GetServiceState_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	out := new(HealthCheckResponse)
 	err := c.cc.Invoke(ctx, Service_HealthCheck_FullMethodName, in, out, opts...)
@@ -143,18 +530,85 @@ type ServiceServer interface {
 	// Get all challenges with user progress
 	// NOTE: HTTP endpoint uses OptimizedChallengesHandler (see docs/ADR_001_OPTIMIZED_HTTP_HANDLER.md)
 	GetUserChallenges(context.Context, *GetChallengesRequest) (*GetChallengesResponse, error)
+	// Get one challenge with user progress
+	// NOTE: HTTP endpoint uses OptimizedChallengesHandler.ServeChallenge. It must stay
+	// declared before GetProgressSummary and GetUnclaimedCount so /v1/challenges/summary
+	// and /v1/challenges/unclaimed-count take precedence.
+	GetChallenge(context.Context, *GetChallengeRequest) (*GetChallengeResponse, error)
+	// Account-wide progress summary (goal counts by status per challenge)
+	GetProgressSummary(context.Context, *GetProgressSummaryRequest) (*GetProgressSummaryResponse, error)
+	// Badge counts: completed-but-unclaimed and in-progress goals
+	GetUnclaimedCount(context.Context, *GetUnclaimedCountRequest) (*GetUnclaimedCountResponse, error)
+	// Get the authenticated user's active goals in assignment order
+	GetActiveGoals(context.Context, *GetActiveGoalsRequest) (*GetActiveGoalsResponse, error)
 	// Initialize player with default goals (M3 Phase 2)
 	InitializePlayer(context.Context, *InitializeRequest) (*InitializeResponse, error)
+	// Player state for session start: initialize, summary and unclaimed counts in one call
+	StartSession(context.Context, *StartSessionRequest) (*StartSessionResponse, error)
 	// Set goal active/inactive (M3 Phase 3)
 	SetGoalActive(context.Context, *SetGoalActiveRequest) (*SetGoalActiveResponse, error)
+	// Whether SetGoalActive would activate a goal, before showing an activate toggle
+	GetActivationPreflight(context.Context, *GetActivationPreflightRequest) (*GetActivationPreflightResponse, error)
 	// Claim reward for completed goal
 	ClaimGoalReward(context.Context, *ClaimRewardRequest) (*ClaimRewardResponse, error)
+	// Claim every completed goal across all challenges
+	ClaimAllCompleted(context.Context, *ClaimAllCompletedRequest) (*ClaimAllCompletedResponse, error)
 	// M4: Batch manual goal selection
 	BatchSelectGoals(context.Context, *BatchSelectRequest) (*GoalSelectionResponse, error)
 	// M4: Random goal selection
 	RandomSelectGoals(context.Context, *RandomSelectRequest) (*GoalSelectionResponse, error)
+	// Goals a random selection could draw from, e.g. to disable a reroll button
+	GetAvailableGoals(context.Context, *GetAvailableGoalsRequest) (*GetAvailableGoalsResponse, error)
 	// M5: Get rotation status for a challenge
 	GetRotationStatus(context.Context, *GetRotationStatusRequest) (*GetRotationStatusResponse, error)
+	// Admin: reload challenges.json and report what changed
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error)
+	// Admin: JSON Schema of challenges.json, for editors and config tooling
+	GetConfigSchema(context.Context, *GetConfigSchemaRequest) (*GetConfigSchemaResponse, error)
+	// Admin: view a user's claim count against the rolling claim cap
+	GetClaimCap(context.Context, *GetClaimCapRequest) (*ClaimCapStatus, error)
+	// Admin: clear a user's claim count
+	ResetClaimCap(context.Context, *ResetClaimCapRequest) (*ResetClaimCapResponse, error)
+	// Admin: stop a user from claiming rewards until a given time
+	SetClaimFreeze(context.Context, *SetClaimFreezeRequest) (*ClaimFreeze, error)
+	// Admin: list users whose claims are frozen
+	ListClaimFreezes(context.Context, *ListClaimFreezesRequest) (*ListClaimFreezesResponse, error)
+	// Admin: lift a user's claim freeze before it expires
+	RemoveClaimFreeze(context.Context, *RemoveClaimFreezeRequest) (*RemoveClaimFreezeResponse, error)
+	// Admin: list claims whose reward grant failed permanently
+	ListFailedGrants(context.Context, *ListFailedGrantsRequest) (*ListFailedGrantsResponse, error)
+	// Admin: retry a failed reward grant, e.g. after the store item is restored
+	RetryFailedGrant(context.Context, *RetryFailedGrantRequest) (*RetryFailedGrantResponse, error)
+	// Admin: complete a goal on a user's behalf, e.g. for a lost stat event
+	ForceCompleteGoal(context.Context, *ForceCompleteGoalRequest) (*ForceCompleteGoalResponse, error)
+	// Admin: re-evaluate a user's goal statuses against the current config targets
+	RecomputeUserStatuses(context.Context, *RecomputeUserStatusesRequest) (*RecomputeUserStatusesResponse, error)
+	// Admin: per-goal completion counts for tuning
+	GetGoalStats(context.Context, *GetGoalStatsRequest) (*GetGoalStatsResponse, error)
+	// Admin: list a user's goal selections
+	GetSelectionHistory(context.Context, *GetSelectionHistoryRequest) (*GetSelectionHistoryResponse, error)
+	// Admin: show which rolled-out challenges a user is eligible for
+	GetUserRollouts(context.Context, *GetUserRolloutsRequest) (*GetUserRolloutsResponse, error)
+	// Admin: count goal selections per goal
+	GetSelectionStats(context.Context, *GetSelectionStatsRequest) (*GetSelectionStatsResponse, error)
+	// Admin: list progress rows stored under a different challenge than their goal in the config
+	GetChallengeMismatches(context.Context, *GetChallengeMismatchesRequest) (*GetChallengeMismatchesResponse, error)
+	// Admin: rewrite mismatched progress rows to their goal's challenge in the config
+	FixChallengeMismatches(context.Context, *FixChallengeMismatchesRequest) (*FixChallengeMismatchesResponse, error)
+	// Admin: create a default goal's progress rows for existing users
+	BackfillDefaultGoal(context.Context, *BackfillDefaultGoalRequest) (*BackfillJob, error)
+	// Admin: get the status of a default goal backfill
+	GetBackfillJob(context.Context, *GetBackfillJobRequest) (*BackfillJob, error)
+	// Admin: activate a goal for a list of users
+	BulkActivateGoal(context.Context, *BulkActivateGoalRequest) (*BulkActivationJob, error)
+	// Admin: get the status of a bulk goal activation
+	GetBulkActivationJob(context.Context, *GetBulkActivationJobRequest) (*BulkActivationJob, error)
+	// Game server: report stat updates for many players at once
+	BatchReportProgress(context.Context, *BatchReportProgressRequest) (*BatchReportProgressResponse, error)
+	// Game server: report one player's match result to the goals tracking matches
+	ReportMatchResult(context.Context, *ReportMatchResultRequest) (*ReportMatchResultResponse, error)
+	// Admin: the instance's config, cache and runtime state, for debugging
+	GetServiceState(context.Context, *GetServiceStateRequest) (*GetServiceStateResponse, error)
 	// Health check endpoint (Decision FQ5)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedServiceServer()
@@ -167,24 +621,120 @@ type UnimplementedServiceServer struct {
 func (UnimplementedServiceServer) GetUserChallenges(context.Context, *GetChallengesRequest) (*GetChallengesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserChallenges not implemented")
 }
+func (UnimplementedServiceServer) GetChallenge(context.Context, *GetChallengeRequest) (*GetChallengeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChallenge not implemented")
+}
+func (UnimplementedServiceServer) GetProgressSummary(context.Context, *GetProgressSummaryRequest) (*GetProgressSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProgressSummary not implemented")
+}
+func (UnimplementedServiceServer) GetUnclaimedCount(context.Context, *GetUnclaimedCountRequest) (*GetUnclaimedCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnclaimedCount not implemented")
+}
+func (UnimplementedServiceServer) GetActiveGoals(context.Context, *GetActiveGoalsRequest) (*GetActiveGoalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActiveGoals not implemented")
+}
 func (UnimplementedServiceServer) InitializePlayer(context.Context, *InitializeRequest) (*InitializeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InitializePlayer not implemented")
 }
+func (UnimplementedServiceServer) StartSession(context.Context, *StartSessionRequest) (*StartSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartSession not implemented")
+}
 func (UnimplementedServiceServer) SetGoalActive(context.Context, *SetGoalActiveRequest) (*SetGoalActiveResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetGoalActive not implemented")
 }
+func (UnimplementedServiceServer) GetActivationPreflight(context.Context, *GetActivationPreflightRequest) (*GetActivationPreflightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActivationPreflight not implemented")
+}
 func (UnimplementedServiceServer) ClaimGoalReward(context.Context, *ClaimRewardRequest) (*ClaimRewardResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClaimGoalReward not implemented")
 }
+func (UnimplementedServiceServer) ClaimAllCompleted(context.Context, *ClaimAllCompletedRequest) (*ClaimAllCompletedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimAllCompleted not implemented")
+}
 func (UnimplementedServiceServer) BatchSelectGoals(context.Context, *BatchSelectRequest) (*GoalSelectionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchSelectGoals not implemented")
 }
 func (UnimplementedServiceServer) RandomSelectGoals(context.Context, *RandomSelectRequest) (*GoalSelectionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RandomSelectGoals not implemented")
 }
+func (UnimplementedServiceServer) GetAvailableGoals(context.Context, *GetAvailableGoalsRequest) (*GetAvailableGoalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableGoals not implemented")
+}
 func (UnimplementedServiceServer) GetRotationStatus(context.Context, *GetRotationStatusRequest) (*GetRotationStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRotationStatus not implemented")
 }
+func (UnimplementedServiceServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedServiceServer) GetConfigSchema(context.Context, *GetConfigSchemaRequest) (*GetConfigSchemaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfigSchema not implemented")
+}
+func (UnimplementedServiceServer) GetClaimCap(context.Context, *GetClaimCapRequest) (*ClaimCapStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaimCap not implemented")
+}
+func (UnimplementedServiceServer) ResetClaimCap(context.Context, *ResetClaimCapRequest) (*ResetClaimCapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetClaimCap not implemented")
+}
+func (UnimplementedServiceServer) SetClaimFreeze(context.Context, *SetClaimFreezeRequest) (*ClaimFreeze, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetClaimFreeze not implemented")
+}
+func (UnimplementedServiceServer) ListClaimFreezes(context.Context, *ListClaimFreezesRequest) (*ListClaimFreezesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClaimFreezes not implemented")
+}
+func (UnimplementedServiceServer) RemoveClaimFreeze(context.Context, *RemoveClaimFreezeRequest) (*RemoveClaimFreezeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveClaimFreeze not implemented")
+}
+func (UnimplementedServiceServer) ListFailedGrants(context.Context, *ListFailedGrantsRequest) (*ListFailedGrantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFailedGrants not implemented")
+}
+func (UnimplementedServiceServer) RetryFailedGrant(context.Context, *RetryFailedGrantRequest) (*RetryFailedGrantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryFailedGrant not implemented")
+}
+func (UnimplementedServiceServer) ForceCompleteGoal(context.Context, *ForceCompleteGoalRequest) (*ForceCompleteGoalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceCompleteGoal not implemented")
+}
+func (UnimplementedServiceServer) RecomputeUserStatuses(context.Context, *RecomputeUserStatusesRequest) (*RecomputeUserStatusesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecomputeUserStatuses not implemented")
+}
+func (UnimplementedServiceServer) GetGoalStats(context.Context, *GetGoalStatsRequest) (*GetGoalStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGoalStats not implemented")
+}
+func (UnimplementedServiceServer) GetSelectionHistory(context.Context, *GetSelectionHistoryRequest) (*GetSelectionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSelectionHistory not implemented")
+}
+func (UnimplementedServiceServer) GetUserRollouts(context.Context, *GetUserRolloutsRequest) (*GetUserRolloutsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserRollouts not implemented")
+}
+func (UnimplementedServiceServer) GetSelectionStats(context.Context, *GetSelectionStatsRequest) (*GetSelectionStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSelectionStats not implemented")
+}
+func (UnimplementedServiceServer) GetChallengeMismatches(context.Context, *GetChallengeMismatchesRequest) (*GetChallengeMismatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChallengeMismatches not implemented")
+}
+func (UnimplementedServiceServer) FixChallengeMismatches(context.Context, *FixChallengeMismatchesRequest) (*FixChallengeMismatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FixChallengeMismatches not implemented")
+}
+func (UnimplementedServiceServer) BackfillDefaultGoal(context.Context, *BackfillDefaultGoalRequest) (*BackfillJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BackfillDefaultGoal not implemented")
+}
+func (UnimplementedServiceServer) GetBackfillJob(context.Context, *GetBackfillJobRequest) (*BackfillJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBackfillJob not implemented")
+}
+func (UnimplementedServiceServer) BulkActivateGoal(context.Context, *BulkActivateGoalRequest) (*BulkActivationJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkActivateGoal not implemented")
+}
+func (UnimplementedServiceServer) GetBulkActivationJob(context.Context, *GetBulkActivationJobRequest) (*BulkActivationJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBulkActivationJob not implemented")
+}
+func (UnimplementedServiceServer) BatchReportProgress(context.Context, *BatchReportProgressRequest) (*BatchReportProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchReportProgress not implemented")
+}
+func (UnimplementedServiceServer) ReportMatchResult(context.Context, *ReportMatchResultRequest) (*ReportMatchResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportMatchResult not implemented")
+}
+func (UnimplementedServiceServer) GetServiceState(context.Context, *GetServiceStateRequest) (*GetServiceStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceState not implemented")
+}
 func (UnimplementedServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -219,98 +769,242 @@ func _Service_GetUserChallenges_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_InitializePlayer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InitializeRequest)
+func _Service_GetChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChallengeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).InitializePlayer(ctx, in)
+		return srv.(ServiceServer).GetChallenge(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Service_InitializePlayer_FullMethodName,
+		FullMethod: Service_GetChallenge_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).InitializePlayer(ctx, req.(*InitializeRequest))
+		return srv.(ServiceServer).GetChallenge(ctx, req.(*GetChallengeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_SetGoalActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetGoalActiveRequest)
+func _Service_GetProgressSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProgressSummaryRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).SetGoalActive(ctx, in)
+		return srv.(ServiceServer).GetProgressSummary(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Service_SetGoalActive_FullMethodName,
+		FullMethod: Service_GetProgressSummary_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).SetGoalActive(ctx, req.(*SetGoalActiveRequest))
+		return srv.(ServiceServer).GetProgressSummary(ctx, req.(*GetProgressSummaryRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_ClaimGoalReward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ClaimRewardRequest)
+func _Service_GetUnclaimedCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnclaimedCountRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).ClaimGoalReward(ctx, in)
+		return srv.(ServiceServer).GetUnclaimedCount(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Service_ClaimGoalReward_FullMethodName,
+		FullMethod: Service_GetUnclaimedCount_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).ClaimGoalReward(ctx, req.(*ClaimRewardRequest))
+		return srv.(ServiceServer).GetUnclaimedCount(ctx, req.(*GetUnclaimedCountRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_BatchSelectGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BatchSelectRequest)
+func _Service_GetActiveGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActiveGoalsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).BatchSelectGoals(ctx, in)
+		return srv.(ServiceServer).GetActiveGoals(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Service_BatchSelectGoals_FullMethodName,
+		FullMethod: Service_GetActiveGoals_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).BatchSelectGoals(ctx, req.(*BatchSelectRequest))
+		return srv.(ServiceServer).GetActiveGoals(ctx, req.(*GetActiveGoalsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_RandomSelectGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RandomSelectRequest)
+func _Service_InitializePlayer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitializeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).RandomSelectGoals(ctx, in)
+		return srv.(ServiceServer).InitializePlayer(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: Service_RandomSelectGoals_FullMethodName,
+		FullMethod: Service_InitializePlayer_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).RandomSelectGoals(ctx, req.(*RandomSelectRequest))
+		return srv.(ServiceServer).InitializePlayer(ctx, req.(*InitializeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_GetRotationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetRotationStatusRequest)
+func _Service_StartSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).StartSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_StartSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).StartSession(ctx, req.(*StartSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_SetGoalActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetGoalActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SetGoalActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_SetGoalActive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SetGoalActive(ctx, req.(*SetGoalActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetActivationPreflight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActivationPreflightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetActivationPreflight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetActivationPreflight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetActivationPreflight(ctx, req.(*GetActivationPreflightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ClaimGoalReward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimRewardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ClaimGoalReward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ClaimGoalReward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ClaimGoalReward(ctx, req.(*ClaimRewardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ClaimAllCompleted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimAllCompletedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ClaimAllCompleted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ClaimAllCompleted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ClaimAllCompleted(ctx, req.(*ClaimAllCompletedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_BatchSelectGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).BatchSelectGoals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_BatchSelectGoals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).BatchSelectGoals(ctx, req.(*BatchSelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RandomSelectGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomSelectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RandomSelectGoals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_RandomSelectGoals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RandomSelectGoals(ctx, req.(*RandomSelectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetAvailableGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAvailableGoalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetAvailableGoals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetAvailableGoals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetAvailableGoals(ctx, req.(*GetAvailableGoalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetRotationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRotationStatusRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -327,6 +1021,438 @@ func _Service_GetRotationStatus_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetConfigSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetConfigSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetConfigSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetConfigSchema(ctx, req.(*GetConfigSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetClaimCap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimCapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetClaimCap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetClaimCap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetClaimCap(ctx, req.(*GetClaimCapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ResetClaimCap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetClaimCapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ResetClaimCap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ResetClaimCap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ResetClaimCap(ctx, req.(*ResetClaimCapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_SetClaimFreeze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetClaimFreezeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SetClaimFreeze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_SetClaimFreeze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SetClaimFreeze(ctx, req.(*SetClaimFreezeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ListClaimFreezes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClaimFreezesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ListClaimFreezes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ListClaimFreezes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ListClaimFreezes(ctx, req.(*ListClaimFreezesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RemoveClaimFreeze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveClaimFreezeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RemoveClaimFreeze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_RemoveClaimFreeze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RemoveClaimFreeze(ctx, req.(*RemoveClaimFreezeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ListFailedGrants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailedGrantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ListFailedGrants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ListFailedGrants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ListFailedGrants(ctx, req.(*ListFailedGrantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RetryFailedGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryFailedGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RetryFailedGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_RetryFailedGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RetryFailedGrant(ctx, req.(*RetryFailedGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ForceCompleteGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceCompleteGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ForceCompleteGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ForceCompleteGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ForceCompleteGoal(ctx, req.(*ForceCompleteGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RecomputeUserStatuses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecomputeUserStatusesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RecomputeUserStatuses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_RecomputeUserStatuses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RecomputeUserStatuses(ctx, req.(*RecomputeUserStatusesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetGoalStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGoalStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetGoalStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetGoalStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetGoalStats(ctx, req.(*GetGoalStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetSelectionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSelectionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetSelectionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetSelectionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetSelectionHistory(ctx, req.(*GetSelectionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetUserRollouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRolloutsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetUserRollouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetUserRollouts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetUserRollouts(ctx, req.(*GetUserRolloutsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetSelectionStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSelectionStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetSelectionStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetSelectionStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetSelectionStats(ctx, req.(*GetSelectionStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetChallengeMismatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChallengeMismatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetChallengeMismatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetChallengeMismatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetChallengeMismatches(ctx, req.(*GetChallengeMismatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_FixChallengeMismatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FixChallengeMismatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).FixChallengeMismatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_FixChallengeMismatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).FixChallengeMismatches(ctx, req.(*FixChallengeMismatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_BackfillDefaultGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackfillDefaultGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).BackfillDefaultGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_BackfillDefaultGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).BackfillDefaultGoal(ctx, req.(*BackfillDefaultGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetBackfillJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBackfillJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetBackfillJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetBackfillJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetBackfillJob(ctx, req.(*GetBackfillJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_BulkActivateGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkActivateGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).BulkActivateGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_BulkActivateGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).BulkActivateGoal(ctx, req.(*BulkActivateGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetBulkActivationJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBulkActivationJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetBulkActivationJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetBulkActivationJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetBulkActivationJob(ctx, req.(*GetBulkActivationJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_BatchReportProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchReportProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).BatchReportProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_BatchReportProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).BatchReportProgress(ctx, req.(*BatchReportProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ReportMatchResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportMatchResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReportMatchResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReportMatchResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReportMatchResult(ctx, req.(*ReportMatchResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetServiceState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetServiceState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetServiceState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetServiceState(ctx, req.(*GetServiceStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -356,18 +1482,46 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserChallenges",
 			Handler:    _Service_GetUserChallenges_Handler,
 		},
+		{
+			MethodName: "GetChallenge",
+			Handler:    _Service_GetChallenge_Handler,
+		},
+		{
+			MethodName: "GetProgressSummary",
+			Handler:    _Service_GetProgressSummary_Handler,
+		},
+		{
+			MethodName: "GetUnclaimedCount",
+			Handler:    _Service_GetUnclaimedCount_Handler,
+		},
+		{
+			MethodName: "GetActiveGoals",
+			Handler:    _Service_GetActiveGoals_Handler,
+		},
 		{
 			MethodName: "InitializePlayer",
 			Handler:    _Service_InitializePlayer_Handler,
 		},
+		{
+			MethodName: "StartSession",
+			Handler:    _Service_StartSession_Handler,
+		},
 		{
 			MethodName: "SetGoalActive",
 			Handler:    _Service_SetGoalActive_Handler,
 		},
+		{
+			MethodName: "GetActivationPreflight",
+			Handler:    _Service_GetActivationPreflight_Handler,
+		},
 		{
 			MethodName: "ClaimGoalReward",
 			Handler:    _Service_ClaimGoalReward_Handler,
 		},
+		{
+			MethodName: "ClaimAllCompleted",
+			Handler:    _Service_ClaimAllCompleted_Handler,
+		},
 		{
 			MethodName: "BatchSelectGoals",
 			Handler:    _Service_BatchSelectGoals_Handler,
@@ -376,10 +1530,110 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RandomSelectGoals",
 			Handler:    _Service_RandomSelectGoals_Handler,
 		},
+		{
+			MethodName: "GetAvailableGoals",
+			Handler:    _Service_GetAvailableGoals_Handler,
+		},
 		{
 			MethodName: "GetRotationStatus",
 			Handler:    _Service_GetRotationStatus_Handler,
 		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Service_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "GetConfigSchema",
+			Handler:    _Service_GetConfigSchema_Handler,
+		},
+		{
+			MethodName: "GetClaimCap",
+			Handler:    _Service_GetClaimCap_Handler,
+		},
+		{
+			MethodName: "ResetClaimCap",
+			Handler:    _Service_ResetClaimCap_Handler,
+		},
+		{
+			MethodName: "SetClaimFreeze",
+			Handler:    _Service_SetClaimFreeze_Handler,
+		},
+		{
+			MethodName: "ListClaimFreezes",
+			Handler:    _Service_ListClaimFreezes_Handler,
+		},
+		{
+			MethodName: "RemoveClaimFreeze",
+			Handler:    _Service_RemoveClaimFreeze_Handler,
+		},
+		{
+			MethodName: "ListFailedGrants",
+			Handler:    _Service_ListFailedGrants_Handler,
+		},
+		{
+			MethodName: "RetryFailedGrant",
+			Handler:    _Service_RetryFailedGrant_Handler,
+		},
+		{
+			MethodName: "ForceCompleteGoal",
+			Handler:    _Service_ForceCompleteGoal_Handler,
+		},
+		{
+			MethodName: "RecomputeUserStatuses",
+			Handler:    _Service_RecomputeUserStatuses_Handler,
+		},
+		{
+			MethodName: "GetGoalStats",
+			Handler:    _Service_GetGoalStats_Handler,
+		},
+		{
+			MethodName: "GetSelectionHistory",
+			Handler:    _Service_GetSelectionHistory_Handler,
+		},
+		{
+			MethodName: "GetUserRollouts",
+			Handler:    _Service_GetUserRollouts_Handler,
+		},
+		{
+			MethodName: "GetSelectionStats",
+			Handler:    _Service_GetSelectionStats_Handler,
+		},
+		{
+			MethodName: "GetChallengeMismatches",
+			Handler:    _Service_GetChallengeMismatches_Handler,
+		},
+		{
+			MethodName: "FixChallengeMismatches",
+			Handler:    _Service_FixChallengeMismatches_Handler,
+		},
+		{
+			MethodName: "BackfillDefaultGoal",
+			Handler:    _Service_BackfillDefaultGoal_Handler,
+		},
+		{
+			MethodName: "GetBackfillJob",
+			Handler:    _Service_GetBackfillJob_Handler,
+		},
+		{
+			MethodName: "BulkActivateGoal",
+			Handler:    _Service_BulkActivateGoal_Handler,
+		},
+		{
+			MethodName: "GetBulkActivationJob",
+			Handler:    _Service_GetBulkActivationJob_Handler,
+		},
+		{
+			MethodName: "BatchReportProgress",
+			Handler:    _Service_BatchReportProgress_Handler,
+		},
+		{
+			MethodName: "ReportMatchResult",
+			Handler:    _Service_ReportMatchResult_Handler,
+		},
+		{
+			MethodName: "GetServiceState",
+			Handler:    _Service_GetServiceState_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _Service_HealthCheck_Handler,
@@ -0,0 +1,86 @@
+// Package progressstate defines which goal status changes a progress row may
+// make. Every write path in the service that picks a row's new status calls
+// Transition before persisting it.
+//
+// The normal life of a row is not_started -> in_progress -> completed ->
+// claimed; a row may also be written for the first time in any status but
+// claimed, and may skip forward (not_started -> completed). Moving backwards
+// needs a flag: Regress for a multi-step goal whose steps were raised, and
+// Reset for a rotation or the claim of a repeatable goal, the only ways out of
+// claimed.
+//
+// Writes done entirely in SQL are guarded there instead: MarkAsClaimed only
+// claims completed rows and UpsertProgress never touches claimed ones
+// (extend-challenge-common). The user_goal_progress CHECK constraints of
+// migration 020 back both up.
+package progressstate
+
+import (
+	"fmt"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// Flags allow the transitions that go backwards.
+type Flags struct {
+	// Reset allows any status, claimed included, to go back to not_started,
+	// in_progress or completed, as a rotation or a repeatable goal does.
+	Reset bool
+	// Regress allows completed and in_progress to go back among the unclaimed
+	// statuses, e.g. when a multi-step goal's steps are raised.
+	Regress bool
+}
+
+// IllegalTransitionError is returned for a status change the state machine
+// does not allow.
+type IllegalTransitionError struct {
+	From domain.GoalStatus
+	To   domain.GoalStatus
+}
+
+func (e *IllegalTransitionError) Error() string {
+	from := string(e.From)
+	if from == "" {
+		from = "no row"
+	}
+	return fmt.Sprintf("illegal goal status transition from %s to %s", from, e.To)
+}
+
+// rank orders the statuses along the normal life of a row.
+var rank = map[domain.GoalStatus]int{
+	domain.GoalStatusNotStarted: 1,
+	domain.GoalStatusInProgress: 2,
+	domain.GoalStatusCompleted:  3,
+	domain.GoalStatusClaimed:    4,
+}
+
+// Transition returns nil if a row in status from may be written in status to,
+// or an *IllegalTransitionError. An empty from means there is no row yet.
+func Transition(from, to domain.GoalStatus, flags Flags) error {
+	toRank, ok := rank[to]
+	fromRank, known := rank[from]
+	if !ok || (from != "" && !known) {
+		return &IllegalTransitionError{From: from, To: to}
+	}
+
+	switch {
+	case to == domain.GoalStatusClaimed:
+		// Only claiming a completed row reaches claimed; a claimed row stays as it is
+		if from == domain.GoalStatusCompleted || from == domain.GoalStatusClaimed {
+			return nil
+		}
+	case from == "" || toRank >= fromRank:
+		if from != domain.GoalStatusClaimed {
+			return nil
+		}
+		if flags.Reset {
+			return nil
+		}
+	case flags.Reset:
+		return nil
+	case flags.Regress && from != domain.GoalStatusClaimed:
+		return nil
+	}
+
+	return &IllegalTransitionError{From: from, To: to}
+}
@@ -0,0 +1,100 @@
+package progressstate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	none       = domain.GoalStatus("")
+	notStarted = domain.GoalStatusNotStarted
+	inProgress = domain.GoalStatusInProgress
+	completed  = domain.GoalStatusCompleted
+	claimed    = domain.GoalStatusClaimed
+	unknown    = domain.GoalStatus("archived")
+)
+
+var (
+	fromStatuses = []domain.GoalStatus{none, notStarted, inProgress, completed, claimed, unknown}
+	toStatuses   = []domain.GoalStatus{none, notStarted, inProgress, completed, claimed, unknown}
+)
+
+type edge struct{ from, to domain.GoalStatus }
+
+// forward are the transitions legal without flags.
+var forward = []edge{
+	{none, notStarted}, {none, inProgress}, {none, completed},
+	{notStarted, notStarted}, {notStarted, inProgress}, {notStarted, completed},
+	{inProgress, inProgress}, {inProgress, completed},
+	{completed, completed}, {completed, claimed},
+	{claimed, claimed},
+}
+
+var regress = []edge{
+	{inProgress, notStarted},
+	{completed, notStarted}, {completed, inProgress},
+}
+
+var reset = []edge{
+	{inProgress, notStarted},
+	{completed, notStarted}, {completed, inProgress},
+	{claimed, notStarted}, {claimed, inProgress}, {claimed, completed},
+}
+
+// TestTransition_Matrix checks every pair of statuses under every combination
+// of flags against the expected legal edges.
+func TestTransition_Matrix(t *testing.T) {
+	for _, flags := range []Flags{{}, {Regress: true}, {Reset: true}, {Reset: true, Regress: true}} {
+		legal := map[edge]bool{}
+		for _, e := range forward {
+			legal[e] = true
+		}
+		if flags.Regress {
+			for _, e := range regress {
+				legal[e] = true
+			}
+		}
+		if flags.Reset {
+			for _, e := range reset {
+				legal[e] = true
+			}
+		}
+
+		for _, from := range fromStatuses {
+			for _, to := range toStatuses {
+				name := fmt.Sprintf("%+v %q->%q", flags, from, to)
+				err := Transition(from, to, flags)
+				if legal[edge{from, to}] {
+					assert.NoError(t, err, name)
+					continue
+				}
+
+				var illegal *IllegalTransitionError
+				if assert.True(t, errors.As(err, &illegal), name) {
+					assert.Equal(t, from, illegal.From, name)
+					assert.Equal(t, to, illegal.To, name)
+				}
+			}
+		}
+	}
+}
+
+// TestTransition_NothingButCompletedIsClaimed holds whatever the flags.
+func TestTransition_NothingButCompletedIsClaimed(t *testing.T) {
+	for _, flags := range []Flags{{}, {Regress: true}, {Reset: true}, {Reset: true, Regress: true}} {
+		for _, from := range []domain.GoalStatus{none, notStarted, inProgress, unknown} {
+			assert.Error(t, Transition(from, claimed, flags), "%+v %q", flags, from)
+		}
+	}
+}
+
+func TestIllegalTransitionError_Error(t *testing.T) {
+	assert.Equal(t, "illegal goal status transition from claimed to in_progress",
+		(&IllegalTransitionError{From: claimed, To: inProgress}).Error())
+	assert.Equal(t, "illegal goal status transition from no row to claimed",
+		(&IllegalTransitionError{To: claimed}).Error())
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// AbandonedGoalRepository deactivates goals players activated and never made
+// progress on (autoDeactivateAfterDays in the challenge config).
+type AbandonedGoalRepository interface {
+	// DeactivateAbandonedGoals deactivates up to limit of the challenge's active
+	// rows in namespace that have no progress and were assigned before
+	// assignedBefore, oldest assignment first. Their activation source is set to
+	// source. Returns the number of rows deactivated.
+	DeactivateAbandonedGoals(
+		ctx context.Context,
+		namespace string,
+		challengeID string,
+		assignedBefore time.Time,
+		source string,
+		limit int,
+	) (int, error)
+}
+
+// PostgresAbandonedGoalRepository implements AbandonedGoalRepository on PostgreSQL.
+type PostgresAbandonedGoalRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAbandonedGoalRepository creates a new PostgreSQL abandoned goal repository.
+func NewPostgresAbandonedGoalRepository(db *sql.DB) *PostgresAbandonedGoalRepository {
+	return &PostgresAbandonedGoalRepository{db: db}
+}
+
+// DeactivateAbandonedGoals uses the idx_user_goal_progress_abandoned partial index
+// (migration 018). Like FixChallengeMismatches it is safe to run from every
+// replica: rows locked by an in-flight update are skipped (SKIP LOCKED) and
+// deactivated rows no longer match. Rows with progress on any step of a
+// multi-step goal are kept. updated_at is left alone because rotation checks
+// read it.
+func (r *PostgresAbandonedGoalRepository) DeactivateAbandonedGoals(
+	ctx context.Context,
+	namespace string,
+	challengeID string,
+	assignedBefore time.Time,
+	source string,
+	limit int,
+) (int, error) {
+	query := `
+		WITH abandoned AS (
+			SELECT user_id, goal_id
+			FROM user_goal_progress
+			WHERE namespace = $1 AND challenge_id = $2 AND is_active = true AND progress = 0
+			  AND assigned_at < $3
+			  AND status IN ('not_started', 'in_progress')
+			  AND NOT EXISTS (
+			      SELECT 1 FROM jsonb_array_elements_text(COALESCE(step_progress, '[]'::jsonb)) AS step(value)
+			      WHERE step.value <> '0'
+			  )
+			ORDER BY assigned_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE user_goal_progress p
+		SET is_active = false, activation_source = $4
+		FROM abandoned a
+		WHERE p.user_id = a.user_id AND p.goal_id = a.goal_id
+	`
+
+	result, err := r.db.ExecContext(ctx, query, namespace, challengeID, assignedBefore, source, limit)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("deactivate abandoned goals", err)
+	}
+
+	deactivated, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("deactivate abandoned goals", err)
+	}
+
+	return int(deactivated), nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeactivateAbandonedGoals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	before := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	// Rows with progress, on any step, or a finished status are kept
+	mock.ExpectExec(`WHERE namespace = \$1 AND challenge_id = \$2 AND is_active = true AND progress = 0\s+`+
+		`AND assigned_at < \$3\s+AND status IN \('not_started', 'in_progress'\)\s+`+
+		`AND NOT EXISTS \((.|\n)+WHERE step.value <> '0'(.|\n)+`+
+		`ORDER BY assigned_at\s+LIMIT \$5\s+FOR UPDATE SKIP LOCKED(.|\n)+`+
+		`SET is_active = false, activation_source = \$4`).
+		WithArgs("ns", "weekly", before, "auto_expired", 500).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	repo := NewPostgresAbandonedGoalRepository(db)
+	deactivated, err := repo.DeactivateAbandonedGoals(context.Background(), "ns", "weekly", before, "auto_expired", 500)
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, deactivated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeactivateAbandonedGoals_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE user_goal_progress p`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresAbandonedGoalRepository(db)
+	_, err = repo.DeactivateAbandonedGoals(context.Background(), "ns", "weekly", time.Now(), "auto_expired", 500)
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
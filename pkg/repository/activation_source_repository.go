@@ -0,0 +1,101 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// ActivationSourceRepository reads and writes user_goal_progress.activation_source
+// (migration 008): how each goal was last activated.
+//
+// The activation writes in extend-challenge-common (BulkInsert,
+// BatchUpsertGoalActive, UpsertGoalActive) do not know the column, so the
+// source is recorded after them.
+type ActivationSourceRepository interface {
+	// SetActivationSource sets the source of the user's active rows among goalIDs.
+	// Inactive rows keep theirs, so a goal deactivated since is not relabelled.
+	SetActivationSource(ctx context.Context, userID string, goalIDs []string, source string) error
+
+	// GetActivationSources returns goal ID -> source for the user's rows among
+	// goalIDs that have one.
+	GetActivationSources(ctx context.Context, userID string, goalIDs []string) (map[string]string, error)
+}
+
+// PostgresActivationSourceRepository implements ActivationSourceRepository on PostgreSQL.
+type PostgresActivationSourceRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresActivationSourceRepository creates a new PostgreSQL activation source repository.
+func NewPostgresActivationSourceRepository(db *sql.DB) *PostgresActivationSourceRepository {
+	return &PostgresActivationSourceRepository{db: db}
+}
+
+// SetActivationSource updates the rows in one statement.
+func (r *PostgresActivationSourceRepository) SetActivationSource(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+	source string,
+) error {
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE user_goal_progress
+		SET activation_source = $3
+		WHERE user_id = $1 AND goal_id = ANY($2) AND is_active = true
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, pq.Array(goalIDs), source); err != nil {
+		return errors.ErrDatabaseError("set activation source", err)
+	}
+
+	return nil
+}
+
+// GetActivationSources uses the (user_id, goal_id) primary key.
+func (r *PostgresActivationSourceRepository) GetActivationSources(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string]string, error) {
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT goal_id, activation_source
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = ANY($2) AND activation_source IS NOT NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get activation sources", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	sources := make(map[string]string)
+	for rows.Next() {
+		var goalID, source string
+		if err := rows.Scan(&goalID, &source); err != nil {
+			return nil, errors.ErrDatabaseError("scan activation source", err)
+		}
+		sources[goalID] = source
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate activation sources", err)
+	}
+
+	return sources, nil
+}
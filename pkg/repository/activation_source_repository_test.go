@@ -0,0 +1,74 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetActivationSource(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE user_goal_progress\s+SET activation_source = \$3\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\) AND is_active = true`).
+		WithArgs("user-1", pq.Array([]string{"g1", "g2"}), "random").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	repo := NewPostgresActivationSourceRepository(db)
+	err = repo.SetActivationSource(context.Background(), "user-1", []string{"g1", "g2"}, "random")
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetActivationSource_NoGoals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewPostgresActivationSourceRepository(db)
+
+	assert.NoError(t, repo.SetActivationSource(context.Background(), "user-1", nil, "manual"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActivationSources(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id, activation_source\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\) AND activation_source IS NOT NULL`).
+		WithArgs("user-1", pq.Array([]string{"g1", "g2", "g3"})).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "activation_source"}).
+			AddRow("g1", "default").
+			AddRow("g3", "admin"))
+
+	repo := NewPostgresActivationSourceRepository(db)
+	sources, err := repo.GetActivationSources(context.Background(), "user-1", []string{"g1", "g2", "g3"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"g1": "default", "g3": "admin"}, sources)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActivationSources_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id, activation_source`).WillReturnError(sqlmock.ErrCancelled)
+
+	repo := NewPostgresActivationSourceRepository(db)
+	_, err = repo.GetActivationSources(context.Background(), "user-1", []string{"g1"})
+
+	assert.ErrorContains(t, err, "get activation sources")
+}
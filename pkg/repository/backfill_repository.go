@@ -0,0 +1,395 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// Backfill job statuses (backfill_jobs.status).
+const (
+	BackfillStatusRunning   = "running"
+	BackfillStatusCompleted = "completed"
+	BackfillStatusFailed    = "failed"
+)
+
+// BackfillRepository stores default goal backfill jobs (backfill_jobs, migration
+// 014) and inserts the progress rows they create.
+type BackfillRepository interface {
+	// GetBackfillJob returns the goal's job, or nil if it was never started.
+	GetBackfillJob(ctx context.Context, goalID, namespace string) (*BackfillJob, error)
+
+	// ListBackfillJobs returns the jobs with the given status, oldest first.
+	ListBackfillJobs(ctx context.Context, namespace, status string) ([]*BackfillJob, error)
+
+	// SaveBackfillJob creates or replaces the goal's job.
+	SaveBackfillJob(ctx context.Context, job *BackfillJob) error
+
+	// ProcessBackfillBatch runs one batch of a running job in a transaction. It
+	// locks the job, reads the next limit users with progress rows after the
+	// job's cursor (see listProgressUsers), passes the ones without the goal's row
+	// to build and inserts the rows build returns, skipping rows that exist by
+	// then. It advances the cursor and counters, completing the job when fewer
+	// than limit users are left, and returns the updated job.
+	//
+	// It returns nil if the job is not running or another replica is running a
+	// batch of it (the job row is locked with SKIP LOCKED).
+	ProcessBackfillBatch(
+		ctx context.Context,
+		goalID string,
+		namespace string,
+		limit int,
+		build func(userIDs []string) []*domain.UserGoalProgress,
+	) (*BackfillJob, error)
+}
+
+// BackfillJob creates a default goal's progress row for every existing user.
+type BackfillJob struct {
+	GoalID    string
+	Namespace string
+	Status    string
+	// LastUserID is the last user processed, in user_id order ("" before the first batch).
+	LastUserID   string
+	UsersScanned int64
+	// UsersSkipped counts the users who already had the goal's row.
+	UsersSkipped int64
+	RowsInserted int64
+	// Error is why the job failed ("" unless failed).
+	Error string
+	// ActorUserID is the admin who started the job ("" when started by BACKFILL_DEFAULT_GOALS).
+	ActorUserID string
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// ProgressUser is a user with progress rows, and whether one of them is the backfilled goal's.
+type ProgressUser struct {
+	UserID  string
+	HasGoal bool
+}
+
+// PostgresBackfillRepository implements BackfillRepository on PostgreSQL.
+type PostgresBackfillRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresBackfillRepository creates a new PostgreSQL backfill repository.
+func NewPostgresBackfillRepository(db *sql.DB) *PostgresBackfillRepository {
+	return &PostgresBackfillRepository{db: db}
+}
+
+const backfillJobColumns = `goal_id, namespace, status, last_user_id, users_scanned, users_skipped,
+	rows_inserted, error, actor_user_id, started_at, updated_at, completed_at`
+
+func scanBackfillJob(row interface{ Scan(...any) error }) (*BackfillJob, error) {
+	var job BackfillJob
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&job.GoalID,
+		&job.Namespace,
+		&job.Status,
+		&job.LastUserID,
+		&job.UsersScanned,
+		&job.UsersSkipped,
+		&job.RowsInserted,
+		&job.Error,
+		&job.ActorUserID,
+		&job.StartedAt,
+		&job.UpdatedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+	job.StartedAt = job.StartedAt.UTC()
+	job.UpdatedAt = job.UpdatedAt.UTC()
+	if completedAt.Valid {
+		t := completedAt.Time.UTC()
+		job.CompletedAt = &t
+	}
+	return &job, nil
+}
+
+// GetBackfillJob is a primary key lookup.
+func (r *PostgresBackfillRepository) GetBackfillJob(ctx context.Context, goalID, namespace string) (*BackfillJob, error) {
+	query := `
+		SELECT ` + backfillJobColumns + `
+		FROM backfill_jobs
+		WHERE goal_id = $1 AND namespace = $2
+	`
+
+	job, err := scanBackfillJob(r.db.QueryRowContext(ctx, query, goalID, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get backfill job", err)
+	}
+
+	return job, nil
+}
+
+// ListBackfillJobs scans backfill_jobs, which has one row per backfilled goal.
+func (r *PostgresBackfillRepository) ListBackfillJobs(ctx context.Context, namespace, status string) ([]*BackfillJob, error) {
+	query := `
+		SELECT ` + backfillJobColumns + `
+		FROM backfill_jobs
+		WHERE namespace = $1 AND status = $2
+		ORDER BY started_at, goal_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, status)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list backfill jobs", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*BackfillJob
+	for rows.Next() {
+		job, err := scanBackfillJob(rows)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("scan backfill job", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("list backfill jobs", err)
+	}
+
+	return jobs, nil
+}
+
+// SaveBackfillJob is a single upsert.
+func (r *PostgresBackfillRepository) SaveBackfillJob(ctx context.Context, job *BackfillJob) error {
+	query := `
+		INSERT INTO backfill_jobs (` + backfillJobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (goal_id, namespace) DO UPDATE SET
+			status = EXCLUDED.status,
+			last_user_id = EXCLUDED.last_user_id,
+			users_scanned = EXCLUDED.users_scanned,
+			users_skipped = EXCLUDED.users_skipped,
+			rows_inserted = EXCLUDED.rows_inserted,
+			error = EXCLUDED.error,
+			actor_user_id = EXCLUDED.actor_user_id,
+			started_at = EXCLUDED.started_at,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		job.GoalID, job.Namespace, job.Status, job.LastUserID,
+		job.UsersScanned, job.UsersSkipped, job.RowsInserted,
+		job.Error, job.ActorUserID, job.StartedAt, job.UpdatedAt, job.CompletedAt,
+	); err != nil {
+		return errors.ErrDatabaseError("save backfill job", err)
+	}
+
+	return nil
+}
+
+// ProcessBackfillBatch holds the job row lock for the whole batch, so the cursor
+// only advances together with the rows it covers.
+func (r *PostgresBackfillRepository) ProcessBackfillBatch(
+	ctx context.Context,
+	goalID string,
+	namespace string,
+	limit int,
+	build func(userIDs []string) []*domain.UserGoalProgress,
+) (*BackfillJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin backfill batch", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	job, err := scanBackfillJob(tx.QueryRowContext(ctx, `
+		SELECT `+backfillJobColumns+`
+		FROM backfill_jobs
+		WHERE goal_id = $1 AND namespace = $2 AND status = 'running'
+		FOR UPDATE SKIP LOCKED
+	`, goalID, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("lock backfill job", err)
+	}
+
+	users, err := listProgressUsers(ctx, tx, goalID, namespace, job.LastUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, user := range users {
+		if !user.HasGoal {
+			missing = append(missing, user.UserID)
+		}
+	}
+
+	inserted := 0
+	if len(missing) > 0 {
+		inserted, err = copyBackfillRows(ctx, tx, build(missing))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	if len(users) > 0 {
+		job.LastUserID = users[len(users)-1].UserID
+	}
+	job.UsersScanned += int64(len(users))
+	job.UsersSkipped += int64(len(users) - len(missing))
+	job.RowsInserted += int64(inserted)
+	job.UpdatedAt = now
+	if len(users) < limit {
+		job.Status = BackfillStatusCompleted
+		job.CompletedAt = &now
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE backfill_jobs
+		SET status = $3, last_user_id = $4, users_scanned = $5, users_skipped = $6,
+			rows_inserted = $7, updated_at = $8, completed_at = $9
+		WHERE goal_id = $1 AND namespace = $2
+	`, goalID, namespace, job.Status, job.LastUserID, job.UsersScanned, job.UsersSkipped,
+		job.RowsInserted, job.UpdatedAt, job.CompletedAt,
+	); err != nil {
+		return nil, errors.ErrDatabaseError("update backfill job", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit backfill batch", err)
+	}
+
+	return job, nil
+}
+
+// listProgressUsers iterates the users with progress rows in the namespace in
+// keyset batches: it returns up to limit of them after afterUserID ("" for the
+// first batch), in user_id order, and whether each has goalID's row. Pass the
+// last user ID of a batch to get the next one. The distinct users are read in
+// primary key order (user_id, goal_id), so a batch costs one index range scan
+// however many users come before it.
+func listProgressUsers(
+	ctx context.Context,
+	tx *sql.Tx,
+	goalID string,
+	namespace string,
+	afterUserID string,
+	limit int,
+) ([]ProgressUser, error) {
+	query := `
+		SELECT u.user_id, EXISTS (
+			SELECT 1 FROM user_goal_progress g
+			WHERE g.user_id = u.user_id AND g.goal_id = $2
+		)
+		FROM (
+			SELECT DISTINCT user_id
+			FROM user_goal_progress
+			WHERE user_id > $1 AND namespace = $3
+			ORDER BY user_id
+			LIMIT $4
+		) u
+		ORDER BY u.user_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, afterUserID, goalID, namespace, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list progress users", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	users := make([]ProgressUser, 0, limit)
+	for rows.Next() {
+		var user ProgressUser
+		if err := rows.Scan(&user.UserID, &user.HasGoal); err != nil {
+			return nil, errors.ErrDatabaseError("scan progress user", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("list progress users", err)
+	}
+
+	return users, nil
+}
+
+// copyBackfillRows loads rows into a temporary table with COPY and inserts the
+// ones that do not exist yet, returning how many were inserted.
+func copyBackfillRows(ctx context.Context, tx *sql.Tx, rows []*domain.UserGoalProgress) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE IF NOT EXISTS temp_backfill_progress (
+			user_id VARCHAR(255) NOT NULL,
+			goal_id VARCHAR(100) NOT NULL,
+			challenge_id VARCHAR(100) NOT NULL,
+			namespace VARCHAR(100) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			is_active BOOLEAN NOT NULL,
+			assigned_at TIMESTAMP NULL,
+			expires_at TIMESTAMP NULL
+		) ON COMMIT DROP
+	`); err != nil {
+		return 0, errors.ErrDatabaseError("create temp table for backfill", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"temp_backfill_progress",
+		"user_id", "goal_id", "challenge_id", "namespace",
+		"status", "is_active", "assigned_at", "expires_at",
+	))
+	if err != nil {
+		return 0, errors.ErrDatabaseError("prepare COPY for backfill", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, p := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			p.UserID, p.GoalID, p.ChallengeID, p.Namespace,
+			string(p.Status), p.IsActive, p.AssignedAt, p.ExpiresAt,
+		); err != nil {
+			return 0, errors.ErrDatabaseError("execute COPY row for backfill", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, errors.ErrDatabaseError("flush COPY for backfill", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO user_goal_progress (
+			user_id, goal_id, challenge_id, namespace, progress, status,
+			is_active, assigned_at, expires_at, created_at, updated_at
+		)
+		SELECT
+			user_id, goal_id, challenge_id, namespace, 0, status,
+			is_active, assigned_at, expires_at, NOW(), NOW()
+		FROM temp_backfill_progress
+		ON CONFLICT (user_id, goal_id) DO NOTHING
+	`)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("insert backfill rows", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("count backfill rows", err)
+	}
+
+	return int(inserted), nil
+}
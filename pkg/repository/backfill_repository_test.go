@@ -0,0 +1,236 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var backfillJobRowColumns = []string{
+	"goal_id", "namespace", "status", "last_user_id", "users_scanned", "users_skipped",
+	"rows_inserted", "error", "actor_user_id", "started_at", "updated_at", "completed_at",
+}
+
+var backfillStartedAt = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func backfillJobRow(status, lastUserID string, scanned int64) []driver.Value {
+	return []driver.Value{
+		"new-goal", "ns", status, lastUserID, scanned, int64(0),
+		int64(0), "", "admin-1", backfillStartedAt, backfillStartedAt, nil,
+	}
+}
+
+func backfillRows(userIDs []string) []*domain.UserGoalProgress {
+	rows := make([]*domain.UserGoalProgress, len(userIDs))
+	for i, userID := range userIDs {
+		rows[i] = &domain.UserGoalProgress{
+			UserID:      userID,
+			GoalID:      "new-goal",
+			ChallengeID: "c1",
+			Namespace:   "ns",
+			Status:      domain.GoalStatusNotStarted,
+			IsActive:    true,
+		}
+	}
+	return rows
+}
+
+func TestGetBackfillJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT (.|\n)+FROM backfill_jobs\s+WHERE goal_id = \$1 AND namespace = \$2`).
+		WithArgs("new-goal", "ns").
+		WillReturnRows(sqlmock.NewRows(backfillJobRowColumns).AddRow(backfillJobRow(BackfillStatusRunning, "user-5", 5)...))
+
+	repo := NewPostgresBackfillRepository(db)
+	job, err := repo.GetBackfillJob(context.Background(), "new-goal", "ns")
+
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, BackfillStatusRunning, job.Status)
+	assert.Equal(t, "user-5", job.LastUserID)
+	assert.Equal(t, int64(5), job.UsersScanned)
+	assert.Equal(t, backfillStartedAt, job.StartedAt)
+	assert.Nil(t, job.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBackfillJob_NotStarted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM backfill_jobs`).WillReturnRows(sqlmock.NewRows(backfillJobRowColumns))
+
+	repo := NewPostgresBackfillRepository(db)
+	job, err := repo.GetBackfillJob(context.Background(), "new-goal", "ns")
+
+	require.NoError(t, err)
+	assert.Nil(t, job)
+}
+
+func TestListBackfillJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM backfill_jobs\s+WHERE namespace = \$1 AND status = \$2\s+ORDER BY started_at, goal_id`).
+		WithArgs("ns", BackfillStatusRunning).
+		WillReturnRows(sqlmock.NewRows(backfillJobRowColumns).AddRow(backfillJobRow(BackfillStatusRunning, "", 0)...))
+
+	repo := NewPostgresBackfillRepository(db)
+	jobs, err := repo.ListBackfillJobs(context.Background(), "ns", BackfillStatusRunning)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "new-goal", jobs[0].GoalID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveBackfillJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	job := &BackfillJob{
+		GoalID:      "new-goal",
+		Namespace:   "ns",
+		Status:      BackfillStatusRunning,
+		ActorUserID: "admin-1",
+		StartedAt:   backfillStartedAt,
+		UpdatedAt:   backfillStartedAt,
+	}
+	mock.ExpectExec(`INSERT INTO backfill_jobs(.|\n)+ON CONFLICT \(goal_id, namespace\) DO UPDATE`).
+		WithArgs("new-goal", "ns", BackfillStatusRunning, "", int64(0), int64(0), int64(0),
+			"", "admin-1", backfillStartedAt, backfillStartedAt, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresBackfillRepository(db)
+	require.NoError(t, repo.SaveBackfillJob(context.Background(), job))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBackfillBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM backfill_jobs\s+WHERE goal_id = \$1 AND namespace = \$2 AND status = 'running'\s+FOR UPDATE SKIP LOCKED`).
+		WithArgs("new-goal", "ns").
+		WillReturnRows(sqlmock.NewRows(backfillJobRowColumns).AddRow(backfillJobRow(BackfillStatusRunning, "user-0", 1)...))
+	mock.ExpectQuery(`SELECT DISTINCT user_id\s+FROM user_goal_progress\s+WHERE user_id > \$1 AND namespace = \$3`).
+		WithArgs("user-0", "new-goal", "ns", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "exists"}).
+			AddRow("user-1", false).
+			AddRow("user-2", true).
+			AddRow("user-3", false))
+	mock.ExpectExec(`CREATE TEMP TABLE IF NOT EXISTS temp_backfill_progress`).WillReturnResult(sqlmock.NewResult(0, 0))
+	copyStmt := mock.ExpectPrepare(`COPY "temp_backfill_progress"`)
+	copyStmt.ExpectExec().WithArgs("user-1", "new-goal", "c1", "ns", "not_started", true, nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	copyStmt.ExpectExec().WithArgs("user-3", "new-goal", "c1", "ns", "not_started", true, nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	copyStmt.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO user_goal_progress(.|\n)+FROM temp_backfill_progress\s+ON CONFLICT \(user_id, goal_id\) DO NOTHING`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE backfill_jobs`).
+		WithArgs("new-goal", "ns", BackfillStatusRunning, "user-3", int64(4), int64(1), int64(1), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var built []string
+	repo := NewPostgresBackfillRepository(db)
+	job, err := repo.ProcessBackfillBatch(context.Background(), "new-goal", "ns", 3, func(userIDs []string) []*domain.UserGoalProgress {
+		built = userIDs
+		return backfillRows(userIDs)
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, []string{"user-1", "user-3"}, built)
+	assert.Equal(t, BackfillStatusRunning, job.Status)
+	assert.Equal(t, "user-3", job.LastUserID)
+	assert.Equal(t, int64(4), job.UsersScanned)
+	assert.Equal(t, int64(1), job.UsersSkipped)
+	assert.Equal(t, int64(1), job.RowsInserted, "rows created concurrently are not counted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBackfillBatch_LastBatchCompletesJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows(backfillJobRowColumns).AddRow(backfillJobRow(BackfillStatusRunning, "user-3", 4)...))
+	mock.ExpectQuery(`SELECT DISTINCT user_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "exists"}).AddRow("user-4", true))
+	mock.ExpectExec(`UPDATE backfill_jobs`).
+		WithArgs("new-goal", "ns", BackfillStatusCompleted, "user-4", int64(5), int64(1), int64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresBackfillRepository(db)
+	job, err := repo.ProcessBackfillBatch(context.Background(), "new-goal", "ns", 3, func(userIDs []string) []*domain.UserGoalProgress {
+		t.Fatal("build must not be called when every user has the goal")
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, BackfillStatusCompleted, job.Status)
+	assert.NotNil(t, job.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBackfillBatch_NotRunningOrLocked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnRows(sqlmock.NewRows(backfillJobRowColumns))
+	mock.ExpectRollback()
+
+	repo := NewPostgresBackfillRepository(db)
+	job, err := repo.ProcessBackfillBatch(context.Background(), "new-goal", "ns", 3, backfillRows)
+
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBackfillBatch_InsertErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows(backfillJobRowColumns).AddRow(backfillJobRow(BackfillStatusRunning, "", 0)...))
+	mock.ExpectQuery(`SELECT DISTINCT user_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "exists"}).AddRow("user-1", false))
+	mock.ExpectExec(`CREATE TEMP TABLE`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresBackfillRepository(db)
+	_, err = repo.ProcessBackfillBatch(context.Background(), "new-goal", "ns", 3, backfillRows)
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,514 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// GoalAdminActionBulkActivate is the goal_admin_audit.action of BulkActivateGoal.
+const GoalAdminActionBulkActivate = "bulk_activate"
+
+// BulkActivationRepository stores admin bulk goal activation jobs
+// (bulk_activation_jobs, migration 022) and activates the goal for their users.
+//
+// The job statuses are the BackfillStatus values.
+type BulkActivationRepository interface {
+	// CreateBulkActivationJob stores a new job with its users and sets job.ID.
+	CreateBulkActivationJob(ctx context.Context, job *BulkActivationJob, userIDs []string) error
+
+	// GetBulkActivationJob returns the job, or nil if there is none with that ID
+	// in the namespace.
+	GetBulkActivationJob(ctx context.Context, id int64, namespace string) (*BulkActivationJob, error)
+
+	// ListBulkActivationJobs returns the jobs with the given status, oldest first.
+	ListBulkActivationJobs(ctx context.Context, namespace, status string) ([]*BulkActivationJob, error)
+
+	// UpdateBulkActivationJobStatus writes the job's status, error, actor and
+	// timestamps. Its cursor and counters are only written by batches.
+	UpdateBulkActivationJobStatus(ctx context.Context, job *BulkActivationJob) error
+
+	// CountBulkActivation reads how a job over userIDs would find the goal,
+	// without writing: Activated counts the users it would activate.
+	CountBulkActivation(ctx context.Context, goalID string, userIDs []string) (*BulkActivationCounts, error)
+
+	// ProcessBulkActivationBatch runs one batch of a running job in a
+	// transaction. It locks the job, reads the next limit users after its
+	// cursor, passes the ones whose goal is neither active nor claimed to build,
+	// and activates the rows build returns with activation_source 'admin',
+	// writing one goal_admin_audit record per activated row. Rows claimed or
+	// activated by then are left alone. It advances the cursor and counters,
+	// completing the job after its last user, and returns the updated job.
+	//
+	// It returns nil if the job is not running or another replica is running a
+	// batch of it (the job row is locked with SKIP LOCKED).
+	ProcessBulkActivationBatch(
+		ctx context.Context,
+		id int64,
+		namespace string,
+		limit int,
+		build func(userIDs []string) []*domain.UserGoalProgress,
+	) (*BulkActivationJob, error)
+}
+
+// BulkActivationCounts is how the users of a bulk activation found the goal.
+type BulkActivationCounts struct {
+	Activated int64
+	// AlreadyActive counts the users whose goal was active, including ones
+	// activated by someone else between the batch's read and its write.
+	AlreadyActive int64
+	// Claimed counts the users skipped because they claimed the goal.
+	Claimed int64
+}
+
+// BulkActivationJob activates a goal for a list of users.
+type BulkActivationJob struct {
+	ID        int64
+	GoalID    string
+	Namespace string
+	Status    string
+	// UsersTotal is the number of distinct users in the job.
+	UsersTotal int64
+	// UsersProcessed is the cursor: the users before it, in user ID order, are done.
+	UsersProcessed int64
+	BulkActivationCounts
+	// Reason is stored in the audit record of every activated row.
+	Reason string
+	// Error is why the job failed ("" unless failed).
+	Error string
+	// ActorUserID is the admin who started, or last resumed, the job.
+	ActorUserID string
+	// ClientIP is the address the admin's request came from ("" when unknown).
+	ClientIP    string
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// PostgresBulkActivationRepository implements BulkActivationRepository on PostgreSQL.
+type PostgresBulkActivationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresBulkActivationRepository creates a new PostgreSQL bulk activation repository.
+func NewPostgresBulkActivationRepository(db *sql.DB) *PostgresBulkActivationRepository {
+	return &PostgresBulkActivationRepository{db: db}
+}
+
+const bulkActivationJobColumns = `id, goal_id, namespace, status, users_total, users_processed,
+	users_activated, users_already_active, users_claimed, reason, error, actor_user_id,
+	COALESCE(client_ip, ''), started_at, updated_at, completed_at`
+
+func scanBulkActivationJob(row interface{ Scan(...any) error }) (*BulkActivationJob, error) {
+	var job BulkActivationJob
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&job.ID,
+		&job.GoalID,
+		&job.Namespace,
+		&job.Status,
+		&job.UsersTotal,
+		&job.UsersProcessed,
+		&job.Activated,
+		&job.AlreadyActive,
+		&job.Claimed,
+		&job.Reason,
+		&job.Error,
+		&job.ActorUserID,
+		&job.ClientIP,
+		&job.StartedAt,
+		&job.UpdatedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+	job.StartedAt = job.StartedAt.UTC()
+	job.UpdatedAt = job.UpdatedAt.UTC()
+	if completedAt.Valid {
+		t := completedAt.Time.UTC()
+		job.CompletedAt = &t
+	}
+	return &job, nil
+}
+
+// CreateBulkActivationJob is a single insert; the users are stored as one array.
+func (r *PostgresBulkActivationRepository) CreateBulkActivationJob(
+	ctx context.Context,
+	job *BulkActivationJob,
+	userIDs []string,
+) error {
+	query := `
+		INSERT INTO bulk_activation_jobs (
+			goal_id, namespace, status, user_ids, users_total, reason,
+			actor_user_id, client_ip, started_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
+		RETURNING id
+	`
+
+	if err := r.db.QueryRowContext(ctx, query,
+		job.GoalID, job.Namespace, job.Status, pq.Array(userIDs), job.UsersTotal, job.Reason,
+		job.ActorUserID, job.ClientIP, job.StartedAt, job.UpdatedAt,
+	).Scan(&job.ID); err != nil {
+		return errors.ErrDatabaseError("create bulk activation job", err)
+	}
+
+	return nil
+}
+
+// GetBulkActivationJob is a primary key lookup; the user list is not read.
+func (r *PostgresBulkActivationRepository) GetBulkActivationJob(
+	ctx context.Context,
+	id int64,
+	namespace string,
+) (*BulkActivationJob, error) {
+	query := `
+		SELECT ` + bulkActivationJobColumns + `
+		FROM bulk_activation_jobs
+		WHERE id = $1 AND namespace = $2
+	`
+
+	job, err := scanBulkActivationJob(r.db.QueryRowContext(ctx, query, id, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get bulk activation job", err)
+	}
+
+	return job, nil
+}
+
+// ListBulkActivationJobs uses idx_bulk_activation_jobs_namespace_status.
+func (r *PostgresBulkActivationRepository) ListBulkActivationJobs(
+	ctx context.Context,
+	namespace string,
+	status string,
+) ([]*BulkActivationJob, error) {
+	query := `
+		SELECT ` + bulkActivationJobColumns + `
+		FROM bulk_activation_jobs
+		WHERE namespace = $1 AND status = $2
+		ORDER BY started_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, status)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list bulk activation jobs", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var jobs []*BulkActivationJob
+	for rows.Next() {
+		job, err := scanBulkActivationJob(rows)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("scan bulk activation job", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("list bulk activation jobs", err)
+	}
+
+	return jobs, nil
+}
+
+// UpdateBulkActivationJobStatus is a single update by primary key.
+func (r *PostgresBulkActivationRepository) UpdateBulkActivationJobStatus(ctx context.Context, job *BulkActivationJob) error {
+	query := `
+		UPDATE bulk_activation_jobs
+		SET status = $3, error = $4, actor_user_id = $5, client_ip = NULLIF($6, ''),
+			updated_at = $7, completed_at = $8
+		WHERE id = $1 AND namespace = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		job.ID, job.Namespace, job.Status, job.Error, job.ActorUserID, job.ClientIP,
+		job.UpdatedAt, job.CompletedAt,
+	); err != nil {
+		return errors.ErrDatabaseError("update bulk activation job", err)
+	}
+
+	return nil
+}
+
+// CountBulkActivation reads the users' rows in one primary key lookup per user.
+func (r *PostgresBulkActivationRepository) CountBulkActivation(
+	ctx context.Context,
+	goalID string,
+	userIDs []string,
+) (*BulkActivationCounts, error) {
+	states, err := readActivationStates(ctx, r.db, goalID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts BulkActivationCounts
+	for _, userID := range userIDs {
+		switch states[userID] {
+		case activationStateClaimed:
+			counts.Claimed++
+		case activationStateActive:
+			counts.AlreadyActive++
+		default:
+			counts.Activated++
+		}
+	}
+
+	return &counts, nil
+}
+
+// ProcessBulkActivationBatch holds the job row lock for the whole batch, so the
+// cursor only advances together with the rows and audit records it covers.
+func (r *PostgresBulkActivationRepository) ProcessBulkActivationBatch(
+	ctx context.Context,
+	id int64,
+	namespace string,
+	limit int,
+	build func(userIDs []string) []*domain.UserGoalProgress,
+) (*BulkActivationJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin bulk activation batch", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	job, err := scanBulkActivationJob(tx.QueryRowContext(ctx, `
+		SELECT `+bulkActivationJobColumns+`
+		FROM bulk_activation_jobs
+		WHERE id = $1 AND namespace = $2 AND status = 'running'
+		FOR UPDATE SKIP LOCKED
+	`, id, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("lock bulk activation job", err)
+	}
+
+	// Arrays are 1-based and slices include both bounds
+	var userIDs pq.StringArray
+	if err := tx.QueryRowContext(ctx, `
+		SELECT user_ids[$2 + 1 : $2 + $3]
+		FROM bulk_activation_jobs
+		WHERE id = $1
+	`, id, job.UsersProcessed, limit).Scan(&userIDs); err != nil {
+		return nil, errors.ErrDatabaseError("read bulk activation users", err)
+	}
+
+	states, err := readActivationStates(ctx, tx, job.GoalID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var inactive []string
+	var claimed int64
+	for _, userID := range userIDs {
+		switch states[userID] {
+		case activationStateClaimed:
+			claimed++
+		case activationStateActive:
+		default:
+			inactive = append(inactive, userID)
+		}
+	}
+
+	activated := 0
+	if len(inactive) > 0 {
+		activated, err = copyActivationRows(ctx, tx, job, build(inactive))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	job.UsersProcessed += int64(len(userIDs))
+	job.Activated += int64(activated)
+	job.Claimed += claimed
+	job.AlreadyActive += int64(len(userIDs)) - claimed - int64(activated)
+	job.UpdatedAt = now
+	if job.UsersProcessed >= job.UsersTotal {
+		job.Status = BackfillStatusCompleted
+		job.CompletedAt = &now
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE bulk_activation_jobs
+		SET status = $2, users_processed = $3, users_activated = $4, users_already_active = $5,
+			users_claimed = $6, updated_at = $7, completed_at = $8
+		WHERE id = $1
+	`, id, job.Status, job.UsersProcessed, job.Activated, job.AlreadyActive,
+		job.Claimed, job.UpdatedAt, job.CompletedAt,
+	); err != nil {
+		return nil, errors.ErrDatabaseError("update bulk activation job", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit bulk activation batch", err)
+	}
+
+	return job, nil
+}
+
+// activationState is how a bulk activation finds a user's row of the goal.
+type activationState int
+
+const (
+	// activationStateInactive is also the state of users without a row.
+	activationStateInactive activationState = iota
+	activationStateActive
+	activationStateClaimed
+)
+
+// activationStates is user ID -> state; users without a row are absent.
+type activationStates map[string]activationState
+
+// queryer is satisfied by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// readActivationStates reads the state of the users' rows of goalID.
+func readActivationStates(ctx context.Context, q queryer, goalID string, userIDs []string) (activationStates, error) {
+	states := make(activationStates, len(userIDs))
+	if len(userIDs) == 0 {
+		return states, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, status, is_active
+		FROM user_goal_progress
+		WHERE user_id = ANY($1) AND goal_id = $2
+	`, pq.Array(userIDs), goalID)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("read activation states", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var userID, status string
+		var isActive bool
+		if err := rows.Scan(&userID, &status, &isActive); err != nil {
+			return nil, errors.ErrDatabaseError("scan activation state", err)
+		}
+		switch {
+		case status == string(domain.GoalStatusClaimed):
+			states[userID] = activationStateClaimed
+		case isActive:
+			states[userID] = activationStateActive
+		default:
+			states[userID] = activationStateInactive
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("read activation states", err)
+	}
+
+	return states, nil
+}
+
+// copyActivationRows loads rows into a temporary table with COPY, activates the
+// ones whose goal is neither active nor claimed, creating missing rows, and
+// audits each activation, returning how many were activated.
+//
+// TxRepository.BatchUpsertGoalActive (extend-challenge-common) takes the rows
+// of a single user and also reactivates claimed rows, so it cannot be used here.
+func copyActivationRows(ctx context.Context, tx *sql.Tx, job *BulkActivationJob, rows []*domain.UserGoalProgress) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE IF NOT EXISTS temp_bulk_activation (
+			user_id VARCHAR(255) NOT NULL,
+			goal_id VARCHAR(100) NOT NULL,
+			challenge_id VARCHAR(100) NOT NULL,
+			namespace VARCHAR(100) NOT NULL,
+			assigned_at TIMESTAMP NULL,
+			expires_at TIMESTAMP NULL
+		) ON COMMIT DROP
+	`); err != nil {
+		return 0, errors.ErrDatabaseError("create temp table for bulk activation", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"temp_bulk_activation",
+		"user_id", "goal_id", "challenge_id", "namespace", "assigned_at", "expires_at",
+	))
+	if err != nil {
+		return 0, errors.ErrDatabaseError("prepare COPY for bulk activation", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, p := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			p.UserID, p.GoalID, p.ChallengeID, p.Namespace, p.AssignedAt, p.ExpiresAt,
+		); err != nil {
+			return 0, errors.ErrDatabaseError("execute COPY row for bulk activation", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, errors.ErrDatabaseError("flush COPY for bulk activation", err)
+	}
+
+	// The CTEs share one snapshot, so previous holds the rows before activation.
+	// A new row keeps its expires_at; an existing one keeps the one its rotation set.
+	result, err := tx.ExecContext(ctx, `
+		WITH previous AS (
+			SELECT p.user_id, p.status, p.progress
+			FROM user_goal_progress p
+			JOIN temp_bulk_activation t ON t.user_id = p.user_id AND t.goal_id = p.goal_id
+		),
+		activated AS (
+			INSERT INTO user_goal_progress (
+				user_id, goal_id, challenge_id, namespace, progress, status,
+				is_active, assigned_at, expires_at, activation_source, created_at, updated_at
+			)
+			SELECT
+				user_id, goal_id, challenge_id, namespace, 0, 'not_started',
+				true, assigned_at, expires_at, 'admin', NOW(), NOW()
+			FROM temp_bulk_activation
+			ON CONFLICT (user_id, goal_id) DO UPDATE SET
+				is_active = true,
+				assigned_at = EXCLUDED.assigned_at,
+				activation_source = 'admin',
+				updated_at = NOW()
+			WHERE user_goal_progress.status != 'claimed' AND NOT user_goal_progress.is_active
+			RETURNING user_id, goal_id, challenge_id, namespace
+		)
+		INSERT INTO goal_admin_audit (
+			user_id, goal_id, challenge_id, namespace, action, actor_user_id, reason,
+			previous_status, previous_progress, client_ip, bulk_activation_job_id, created_at
+		)
+		SELECT a.user_id, a.goal_id, a.challenge_id, a.namespace, $1, $2, $3,
+		       p.status, p.progress, NULLIF($4, ''), $5, NOW()
+		FROM activated a
+		LEFT JOIN previous p ON p.user_id = a.user_id
+	`,
+		GoalAdminActionBulkActivate,
+		job.ActorUserID,
+		job.Reason,
+		job.ClientIP,
+		job.ID,
+	)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("activate bulk activation rows", err)
+	}
+
+	activated, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("count bulk activation rows", err)
+	}
+
+	return int(activated), nil
+}
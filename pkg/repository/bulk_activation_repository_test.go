@@ -0,0 +1,214 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bulkActivationJobRowColumns = []string{
+	"id", "goal_id", "namespace", "status", "users_total", "users_processed",
+	"users_activated", "users_already_active", "users_claimed", "reason", "error", "actor_user_id",
+	"client_ip", "started_at", "updated_at", "completed_at",
+}
+
+var bulkActivationStartedAt = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func bulkActivationJobRow(status string, total, processed int64) []driver.Value {
+	return []driver.Value{
+		int64(7), "event-goal", "ns", status, total, processed,
+		int64(0), int64(0), int64(0), "tournament", "", "admin-1",
+		"10.0.0.1", bulkActivationStartedAt, bulkActivationStartedAt, nil,
+	}
+}
+
+func activationRows(userIDs []string) []*domain.UserGoalProgress {
+	rows := make([]*domain.UserGoalProgress, len(userIDs))
+	for i, userID := range userIDs {
+		rows[i] = &domain.UserGoalProgress{
+			UserID:      userID,
+			GoalID:      "event-goal",
+			ChallengeID: "c1",
+			Namespace:   "ns",
+			Status:      domain.GoalStatusNotStarted,
+			IsActive:    true,
+		}
+	}
+	return rows
+}
+
+func TestCreateBulkActivationJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	job := &BulkActivationJob{
+		GoalID:      "event-goal",
+		Namespace:   "ns",
+		Status:      BackfillStatusRunning,
+		UsersTotal:  2,
+		Reason:      "tournament",
+		ActorUserID: "admin-1",
+		StartedAt:   bulkActivationStartedAt,
+		UpdatedAt:   bulkActivationStartedAt,
+	}
+	mock.ExpectQuery(`INSERT INTO bulk_activation_jobs(.|\n)+RETURNING id`).
+		WithArgs("event-goal", "ns", BackfillStatusRunning, pq.Array([]string{"user-1", "user-2"}), int64(2),
+			"tournament", "admin-1", "", bulkActivationStartedAt, bulkActivationStartedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	repo := NewPostgresBulkActivationRepository(db)
+	require.NoError(t, repo.CreateBulkActivationJob(context.Background(), job, []string{"user-1", "user-2"}))
+	assert.Equal(t, int64(7), job.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBulkActivationJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM bulk_activation_jobs\s+WHERE id = \$1 AND namespace = \$2`).
+		WithArgs(int64(7), "ns").
+		WillReturnRows(sqlmock.NewRows(bulkActivationJobRowColumns).AddRow(bulkActivationJobRow(BackfillStatusRunning, 5, 2)...))
+	mock.ExpectQuery(`FROM bulk_activation_jobs`).
+		WithArgs(int64(8), "ns").
+		WillReturnRows(sqlmock.NewRows(bulkActivationJobRowColumns))
+
+	repo := NewPostgresBulkActivationRepository(db)
+	job, err := repo.GetBulkActivationJob(context.Background(), 7, "ns")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, int64(5), job.UsersTotal)
+	assert.Equal(t, int64(2), job.UsersProcessed)
+	assert.Equal(t, "10.0.0.1", job.ClientIP)
+
+	job, err = repo.GetBulkActivationJob(context.Background(), 8, "ns")
+	require.NoError(t, err)
+	assert.Nil(t, job, "no job with that ID")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountBulkActivation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT user_id, status, is_active\s+FROM user_goal_progress\s+WHERE user_id = ANY\(\$1\) AND goal_id = \$2`).
+		WithArgs(pq.Array([]string{"user-1", "user-2", "user-3", "user-4"}), "event-goal").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "status", "is_active"}).
+			AddRow("user-1", "claimed", false).
+			AddRow("user-2", "in_progress", true).
+			AddRow("user-3", "completed", false))
+
+	repo := NewPostgresBulkActivationRepository(db)
+	counts, err := repo.CountBulkActivation(context.Background(), "event-goal", []string{"user-1", "user-2", "user-3", "user-4"})
+
+	require.NoError(t, err)
+	assert.Equal(t, &BulkActivationCounts{Activated: 2, AlreadyActive: 1, Claimed: 1}, counts,
+		"an inactive row and a missing row are activated")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBulkActivationBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM bulk_activation_jobs\s+WHERE id = \$1 AND namespace = \$2 AND status = 'running'\s+FOR UPDATE SKIP LOCKED`).
+		WithArgs(int64(7), "ns").
+		WillReturnRows(sqlmock.NewRows(bulkActivationJobRowColumns).AddRow(bulkActivationJobRow(BackfillStatusRunning, 5, 1)...))
+	mock.ExpectQuery(`SELECT user_ids\[\$2 \+ 1 : \$2 \+ \$3\]`).
+		WithArgs(int64(7), int64(1), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"user_ids"}).AddRow("{user-1,user-2,user-3}"))
+	mock.ExpectQuery(`SELECT user_id, status, is_active`).
+		WithArgs(pq.Array([]string{"user-1", "user-2", "user-3"}), "event-goal").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "status", "is_active"}).AddRow("user-2", "claimed", false))
+	mock.ExpectExec(`CREATE TEMP TABLE IF NOT EXISTS temp_bulk_activation`).WillReturnResult(sqlmock.NewResult(0, 0))
+	copyStmt := mock.ExpectPrepare(`COPY "temp_bulk_activation"`)
+	copyStmt.ExpectExec().WithArgs("user-1", "event-goal", "c1", "ns", nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	copyStmt.ExpectExec().WithArgs("user-3", "event-goal", "c1", "ns", nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	copyStmt.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ON CONFLICT \(user_id, goal_id\) DO UPDATE SET(.|\n)+WHERE user_goal_progress.status != 'claimed' AND NOT user_goal_progress.is_active(.|\n)+INSERT INTO goal_admin_audit`).
+		WithArgs(GoalAdminActionBulkActivate, "admin-1", "tournament", "10.0.0.1", int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE bulk_activation_jobs`).
+		WithArgs(int64(7), BackfillStatusRunning, int64(4), int64(1), int64(1), int64(1), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var built []string
+	repo := NewPostgresBulkActivationRepository(db)
+	job, err := repo.ProcessBulkActivationBatch(context.Background(), 7, "ns", 3, func(userIDs []string) []*domain.UserGoalProgress {
+		built = userIDs
+		return activationRows(userIDs)
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, []string{"user-1", "user-3"}, built, "claimed users are skipped")
+	assert.Equal(t, BackfillStatusRunning, job.Status)
+	assert.Equal(t, int64(4), job.UsersProcessed)
+	assert.Equal(t, int64(1), job.Activated)
+	assert.Equal(t, int64(1), job.Claimed)
+	assert.Equal(t, int64(1), job.AlreadyActive, "a row activated concurrently counts as already active")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBulkActivationBatch_LastBatchCompletesJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows(bulkActivationJobRowColumns).AddRow(bulkActivationJobRow(BackfillStatusRunning, 5, 4)...))
+	mock.ExpectQuery(`SELECT user_ids`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_ids"}).AddRow("{user-5}"))
+	mock.ExpectQuery(`SELECT user_id, status, is_active`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "status", "is_active"}).AddRow("user-5", "in_progress", true))
+	mock.ExpectExec(`UPDATE bulk_activation_jobs`).
+		WithArgs(int64(7), BackfillStatusCompleted, int64(5), int64(0), int64(1), int64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresBulkActivationRepository(db)
+	job, err := repo.ProcessBulkActivationBatch(context.Background(), 7, "ns", 3, func(userIDs []string) []*domain.UserGoalProgress {
+		t.Fatal("build must not be called when every user has the goal active")
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, BackfillStatusCompleted, job.Status)
+	assert.NotNil(t, job.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessBulkActivationBatch_NotRunningOrLocked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnRows(sqlmock.NewRows(bulkActivationJobRowColumns))
+	mock.ExpectRollback()
+
+	repo := NewPostgresBulkActivationRepository(db)
+	job, err := repo.ProcessBulkActivationBatch(context.Background(), 7, "ns", 3, activationRows)
+
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// ClaimCounterRepository tracks successful claims per user for the rolling claim cap
+// (user_claim_events, migration 004).
+type ClaimCounterRepository interface {
+	// GetClaimWindow counts a user's claims in a namespace made after since.
+	GetClaimWindow(ctx context.Context, userID, namespace string, since time.Time) (*ClaimWindow, error)
+
+	// RecordClaim logs one claim at claimedAt and deletes the user's claims made
+	// at or before pruneBefore, which no longer count toward any window.
+	RecordClaim(ctx context.Context, userID, namespace, goalID string, claimedAt, pruneBefore time.Time) error
+
+	// ResetClaims deletes all of a user's claims in a namespace and returns how many were deleted.
+	ResetClaims(ctx context.Context, userID, namespace string) (int, error)
+}
+
+// ClaimWindow is a user's claim count within the current window.
+type ClaimWindow struct {
+	Count int
+	// OldestClaimAt is the earliest claim in the window; nil when Count is 0.
+	OldestClaimAt *time.Time
+}
+
+// PostgresClaimCounterRepository implements ClaimCounterRepository on PostgreSQL.
+type PostgresClaimCounterRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresClaimCounterRepository creates a new PostgreSQL claim counter repository.
+func NewPostgresClaimCounterRepository(db *sql.DB) *PostgresClaimCounterRepository {
+	return &PostgresClaimCounterRepository{db: db}
+}
+
+// GetClaimWindow is an index range scan on idx_user_claim_events_user_claimed_at.
+func (r *PostgresClaimCounterRepository) GetClaimWindow(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	since time.Time,
+) (*ClaimWindow, error) {
+	query := `
+		SELECT COUNT(*), MIN(claimed_at)
+		FROM user_claim_events
+		WHERE user_id = $1 AND namespace = $2 AND claimed_at > $3
+	`
+
+	var window ClaimWindow
+	var oldest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID, namespace, since).Scan(&window.Count, &oldest); err != nil {
+		return nil, errors.ErrDatabaseError("get claim window", err)
+	}
+	if oldest.Valid {
+		window.OldestClaimAt = &oldest.Time
+	}
+
+	return &window, nil
+}
+
+// RecordClaim prunes and inserts in one statement, so each user keeps at most
+// one window of rows.
+func (r *PostgresClaimCounterRepository) RecordClaim(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalID string,
+	claimedAt time.Time,
+	pruneBefore time.Time,
+) error {
+	query := `
+		WITH pruned AS (
+			DELETE FROM user_claim_events
+			WHERE user_id = $1 AND namespace = $2 AND claimed_at <= $5
+		)
+		INSERT INTO user_claim_events (user_id, namespace, goal_id, claimed_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, namespace, goalID, claimedAt, pruneBefore); err != nil {
+		return errors.ErrDatabaseError("record claim", err)
+	}
+
+	return nil
+}
+
+// ResetClaims clears a user's claim log.
+func (r *PostgresClaimCounterRepository) ResetClaims(ctx context.Context, userID, namespace string) (int, error) {
+	query := `DELETE FROM user_claim_events WHERE user_id = $1 AND namespace = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, namespace)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("reset claims", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("reset claims", err)
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClaimWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	oldest := since.Add(time.Hour)
+	mock.ExpectQuery(`SELECT COUNT\(\*\), MIN\(claimed_at\)\s+FROM user_claim_events\s+WHERE user_id = \$1 AND namespace = \$2 AND claimed_at > \$3`).
+		WithArgs("user-1", "ns", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(4, oldest))
+
+	repo := NewPostgresClaimCounterRepository(db)
+	window, err := repo.GetClaimWindow(context.Background(), "user-1", "ns", since)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, window.Count)
+	require.NotNil(t, window.OldestClaimAt)
+	assert.Equal(t, oldest, *window.OldestClaimAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClaimWindow_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM user_claim_events`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil))
+
+	repo := NewPostgresClaimCounterRepository(db)
+	window, err := repo.GetClaimWindow(context.Background(), "user-1", "ns", time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, window.Count)
+	assert.Nil(t, window.OldestClaimAt)
+}
+
+func TestRecordClaim_PrunesAndInserts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	claimedAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	pruneBefore := claimedAt.Add(-24 * time.Hour)
+	mock.ExpectExec(`DELETE FROM user_claim_events\s+WHERE user_id = \$1 AND namespace = \$2 AND claimed_at <= \$5\s+\)\s+INSERT INTO user_claim_events`).
+		WithArgs("user-1", "ns", "goal-1", claimedAt, pruneBefore).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	repo := NewPostgresClaimCounterRepository(db)
+	err = repo.RecordClaim(context.Background(), "user-1", "ns", "goal-1", claimedAt, pruneBefore)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetClaims(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`DELETE FROM user_claim_events WHERE user_id = \$1 AND namespace = \$2`).
+		WithArgs("user-1", "ns").
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	repo := NewPostgresClaimCounterRepository(db)
+	cleared, err := repo.ResetClaims(context.Background(), "user-1", "ns")
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, cleared)
+}
+
+func TestResetClaims_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`DELETE FROM user_claim_events`).WillReturnError(errors.New("connection refused"))
+
+	repo := NewPostgresClaimCounterRepository(db)
+	_, err = repo.ResetClaims(context.Background(), "user-1", "ns")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// Claim freeze audit actions (claim_freeze_audit.action).
+const (
+	ClaimFreezeActionFreeze   = "freeze"
+	ClaimFreezeActionUnfreeze = "unfreeze"
+)
+
+// ClaimFreezeRepository stores per-user claim freezes (claim_freezes, migration 012)
+// and records every change in claim_freeze_audit.
+type ClaimFreezeRepository interface {
+	// GetClaimFreeze returns the user's freeze if it is still in effect at now,
+	// or nil.
+	GetClaimFreeze(ctx context.Context, userID, namespace string, now time.Time) (*ClaimFreeze, error)
+
+	// ListClaimFreezes returns up to limit freezes in effect at now, ending soonest first.
+	ListClaimFreezes(ctx context.Context, namespace string, now time.Time, limit int) ([]*ClaimFreeze, error)
+
+	// SetClaimFreeze creates or replaces the user's freeze and writes the audit
+	// record in the same transaction.
+	SetClaimFreeze(ctx context.Context, freeze *ClaimFreeze, clientIP string) error
+
+	// RemoveClaimFreeze deletes the user's freeze and, if there was one in effect
+	// at now, writes the audit record with reason in the same transaction. It
+	// returns the removed freeze, or nil if none was in effect.
+	RemoveClaimFreeze(ctx context.Context, userID, namespace, actorUserID, reason, clientIP string, now time.Time) (*ClaimFreeze, error)
+}
+
+// ClaimFreeze stops a user from claiming rewards until FrozenUntil.
+type ClaimFreeze struct {
+	UserID      string
+	Namespace   string
+	FrozenUntil time.Time
+	Reason      string
+	// ActorUserID is the admin who set the freeze.
+	ActorUserID string
+	CreatedAt   time.Time
+}
+
+// PostgresClaimFreezeRepository implements ClaimFreezeRepository on PostgreSQL.
+type PostgresClaimFreezeRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresClaimFreezeRepository creates a new PostgreSQL claim freeze repository.
+func NewPostgresClaimFreezeRepository(db *sql.DB) *PostgresClaimFreezeRepository {
+	return &PostgresClaimFreezeRepository{db: db}
+}
+
+const claimFreezeColumns = `user_id, namespace, frozen_until, reason, actor_user_id, created_at`
+
+func scanClaimFreeze(row interface{ Scan(...any) error }) (*ClaimFreeze, error) {
+	var freeze ClaimFreeze
+	if err := row.Scan(
+		&freeze.UserID,
+		&freeze.Namespace,
+		&freeze.FrozenUntil,
+		&freeze.Reason,
+		&freeze.ActorUserID,
+		&freeze.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	freeze.FrozenUntil = freeze.FrozenUntil.UTC()
+	freeze.CreatedAt = freeze.CreatedAt.UTC()
+	return &freeze, nil
+}
+
+// GetClaimFreeze is a primary key lookup.
+func (r *PostgresClaimFreezeRepository) GetClaimFreeze(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	now time.Time,
+) (*ClaimFreeze, error) {
+	query := `
+		SELECT ` + claimFreezeColumns + `
+		FROM claim_freezes
+		WHERE user_id = $1 AND namespace = $2 AND frozen_until > $3
+	`
+
+	freeze, err := scanClaimFreeze(r.db.QueryRowContext(ctx, query, userID, namespace, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get claim freeze", err)
+	}
+
+	return freeze, nil
+}
+
+// ListClaimFreezes is an index range scan on idx_claim_freezes_namespace_frozen_until.
+func (r *PostgresClaimFreezeRepository) ListClaimFreezes(
+	ctx context.Context,
+	namespace string,
+	now time.Time,
+	limit int,
+) ([]*ClaimFreeze, error) {
+	query := `
+		SELECT ` + claimFreezeColumns + `
+		FROM claim_freezes
+		WHERE namespace = $1 AND frozen_until > $2
+		ORDER BY frozen_until, user_id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, now, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list claim freezes", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	freezes := []*ClaimFreeze{}
+	for rows.Next() {
+		freeze, err := scanClaimFreeze(rows)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("scan claim freeze", err)
+		}
+		freezes = append(freezes, freeze)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate claim freezes", err)
+	}
+
+	return freezes, nil
+}
+
+// SetClaimFreeze upserts the freeze; a new freeze replaces the previous one,
+// including its expiry.
+func (r *PostgresClaimFreezeRepository) SetClaimFreeze(ctx context.Context, freeze *ClaimFreeze, clientIP string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.ErrDatabaseError("begin set claim freeze", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	upsert := `
+		INSERT INTO claim_freezes (user_id, namespace, frozen_until, reason, actor_user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (user_id, namespace) DO UPDATE SET
+			frozen_until = EXCLUDED.frozen_until,
+			reason = EXCLUDED.reason,
+			actor_user_id = EXCLUDED.actor_user_id,
+			updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, upsert,
+		freeze.UserID, freeze.Namespace, freeze.FrozenUntil, freeze.Reason, freeze.ActorUserID,
+	); err != nil {
+		return errors.ErrDatabaseError("set claim freeze", err)
+	}
+
+	if err := insertClaimFreezeAudit(ctx, tx, freeze.UserID, freeze.Namespace, ClaimFreezeActionFreeze,
+		&freeze.FrozenUntil, freeze.Reason, freeze.ActorUserID, clientIP); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.ErrDatabaseError("commit set claim freeze", err)
+	}
+
+	return nil
+}
+
+// RemoveClaimFreeze deletes expired freezes too, without auditing them.
+func (r *PostgresClaimFreezeRepository) RemoveClaimFreeze(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	actorUserID string,
+	reason string,
+	clientIP string,
+	now time.Time,
+) (*ClaimFreeze, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin remove claim freeze", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		DELETE FROM claim_freezes
+		WHERE user_id = $1 AND namespace = $2
+		RETURNING ` + claimFreezeColumns
+
+	removed, err := scanClaimFreeze(tx.QueryRowContext(ctx, query, userID, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("remove claim freeze", err)
+	}
+
+	if !removed.FrozenUntil.After(now) {
+		removed = nil
+	} else if err := insertClaimFreezeAudit(ctx, tx, userID, namespace, ClaimFreezeActionUnfreeze,
+		nil, reason, actorUserID, clientIP); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit remove claim freeze", err)
+	}
+
+	return removed, nil
+}
+
+func insertClaimFreezeAudit(
+	ctx context.Context,
+	tx *sql.Tx,
+	userID, namespace, action string,
+	frozenUntil *time.Time,
+	reason, actorUserID, clientIP string,
+) error {
+	query := `
+		INSERT INTO claim_freeze_audit (user_id, namespace, action, frozen_until, reason, actor_user_id, client_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+	`
+	if _, err := tx.ExecContext(ctx, query, userID, namespace, action, frozenUntil, reason, actorUserID, clientIP); err != nil {
+		return errors.ErrDatabaseError("insert claim freeze audit", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var claimFreezeRowColumns = []string{"user_id", "namespace", "frozen_until", "reason", "actor_user_id", "created_at"}
+
+func claimFreezeRow(until time.Time) []driver.Value {
+	return []driver.Value{"user-1", "ns", until, "anti-cheat", "admin-1", until.Add(-time.Hour)}
+}
+
+func TestGetClaimFreeze(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	until := now.Add(48 * time.Hour)
+	mock.ExpectQuery(`SELECT (.|\n)+FROM claim_freezes\s+WHERE user_id = \$1 AND namespace = \$2 AND frozen_until > \$3`).
+		WithArgs("user-1", "ns", now).
+		WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns).AddRow(claimFreezeRow(until)...))
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	freeze, err := repo.GetClaimFreeze(context.Background(), "user-1", "ns", now)
+
+	require.NoError(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, until, freeze.FrozenUntil)
+	assert.Equal(t, "anti-cheat", freeze.Reason)
+	assert.Equal(t, "admin-1", freeze.ActorUserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClaimFreeze_NotFrozen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM claim_freezes`).WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns))
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	freeze, err := repo.GetClaimFreeze(context.Background(), "user-1", "ns", time.Now())
+
+	require.NoError(t, err)
+	assert.Nil(t, freeze)
+}
+
+func TestGetClaimFreeze_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM claim_freezes`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	_, err = repo.GetClaimFreeze(context.Background(), "user-1", "ns", time.Now())
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestListClaimFreezes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM claim_freezes\s+WHERE namespace = \$1 AND frozen_until > \$2\s+ORDER BY frozen_until, user_id\s+LIMIT \$3`).
+		WithArgs("ns", now, 50).
+		WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns).
+			AddRow(claimFreezeRow(now.Add(time.Hour))...).
+			AddRow("user-2", "ns", now.Add(2*time.Hour), "", "admin-1", now))
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	freezes, err := repo.ListClaimFreezes(context.Background(), "ns", now, 50)
+
+	require.NoError(t, err)
+	require.Len(t, freezes, 2)
+	assert.Equal(t, "user-1", freezes[0].UserID)
+	assert.Equal(t, "user-2", freezes[1].UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetClaimFreeze(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	until := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO claim_freezes(.|\n)+ON CONFLICT \(user_id, namespace\) DO UPDATE SET`).
+		WithArgs("user-1", "ns", until, "anti-cheat", "admin-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO claim_freeze_audit`).
+		WithArgs("user-1", "ns", ClaimFreezeActionFreeze, &until, "anti-cheat", "admin-1", "10.0.0.1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	err = repo.SetClaimFreeze(context.Background(), &ClaimFreeze{
+		UserID: "user-1", Namespace: "ns", FrozenUntil: until, Reason: "anti-cheat", ActorUserID: "admin-1",
+	}, "10.0.0.1")
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetClaimFreeze_AuditErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO claim_freezes`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO claim_freeze_audit`).WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	err = repo.SetClaimFreeze(context.Background(), &ClaimFreeze{UserID: "user-1", Namespace: "ns", FrozenUntil: time.Now()}, "")
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveClaimFreeze(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`DELETE FROM claim_freezes\s+WHERE user_id = \$1 AND namespace = \$2\s+RETURNING`).
+		WithArgs("user-1", "ns").
+		WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns).AddRow(claimFreezeRow(now.Add(time.Hour))...))
+	mock.ExpectExec(`INSERT INTO claim_freeze_audit`).
+		WithArgs("user-1", "ns", ClaimFreezeActionUnfreeze, nil, "false positive", "admin-2", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	removed, err := repo.RemoveClaimFreeze(context.Background(), "user-1", "ns", "admin-2", "false positive", "", now)
+
+	require.NoError(t, err)
+	require.NotNil(t, removed)
+	assert.Equal(t, "admin-1", removed.ActorUserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveClaimFreeze_ExpiredIsNotAudited(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`DELETE FROM claim_freezes`).
+		WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns).AddRow(claimFreezeRow(now.Add(-time.Hour))...))
+	mock.ExpectCommit()
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	removed, err := repo.RemoveClaimFreeze(context.Background(), "user-1", "ns", "admin-2", "", "", now)
+
+	require.NoError(t, err)
+	assert.Nil(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveClaimFreeze_NotFrozen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`DELETE FROM claim_freezes`).WillReturnRows(sqlmock.NewRows(claimFreezeRowColumns))
+	mock.ExpectRollback()
+
+	repo := NewPostgresClaimFreezeRepository(db)
+	removed, err := repo.RemoveClaimFreeze(context.Background(), "user-1", "ns", "admin-2", "", "", time.Now())
+
+	require.NoError(t, err)
+	assert.Nil(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
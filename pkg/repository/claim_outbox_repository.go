@@ -0,0 +1,216 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// Claim outbox states (claim_outbox.state).
+const (
+	// ClaimOutboxPending means the claim is reserved and the reward may be being granted.
+	ClaimOutboxPending = "pending"
+	// ClaimOutboxGranted means AGS granted the reward but the goal may not be marked claimed yet.
+	ClaimOutboxGranted = "granted"
+)
+
+// ClaimOutboxRepository stores the claims whose reward grant is in flight
+// (claim_outbox, migration 005). A row is the guard that stops two claims of
+// the same goal from both granting while no row lock is held.
+type ClaimOutboxRepository interface {
+	// Reserve inserts a pending entry. It returns false, without error, when the
+	// user already has an entry for the goal.
+	Reserve(ctx context.Context, entry *ClaimOutboxEntry) (bool, error)
+
+	// Get returns the entry for a user's goal, or nil if there is none.
+	Get(ctx context.Context, userID, goalID string) (*ClaimOutboxEntry, error)
+
+	// MarkGranted records that the reward for the entry was granted.
+	MarkGranted(ctx context.Context, userID, goalID string) error
+
+	// Delete removes the entry for a user's goal. Deleting a missing entry is not an error.
+	Delete(ctx context.Context, userID, goalID string) error
+
+	// ListStale returns up to limit entries last updated before the given time, oldest first.
+	ListStale(ctx context.Context, before time.Time, limit int) ([]*ClaimOutboxEntry, error)
+
+	// DeleteIfPending removes the entry only if it is still pending and was last
+	// updated before the given time, giving back its reward cap slot, if any. It
+	// reports whether an entry was deleted.
+	DeleteIfPending(ctx context.Context, userID, goalID string, before time.Time) (bool, error)
+}
+
+// ClaimOutboxEntry is one claim in flight.
+type ClaimOutboxEntry struct {
+	UserID      string
+	GoalID      string
+	ChallengeID string
+	// Namespace is the namespace the reward is granted in (see service.WithGrantNamespace).
+	Namespace string
+	State     string
+	// RewardCapSlot is set when the claim holds a slot of its goal's reward cap
+	// and grants the capped reward (see RewardCapRepository.TakeSlot).
+	RewardCapSlot bool
+	// ClientContext is the analytics metadata the client sent with the claim
+	// (client_context, migration 023). The goal.claimed event of the claim
+	// carries it; it is only written by Reserve.
+	ClientContext map[string]string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// PostgresClaimOutboxRepository implements ClaimOutboxRepository on PostgreSQL.
+type PostgresClaimOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresClaimOutboxRepository creates a new PostgreSQL claim outbox repository.
+func NewPostgresClaimOutboxRepository(db *sql.DB) *PostgresClaimOutboxRepository {
+	return &PostgresClaimOutboxRepository{db: db}
+}
+
+// Reserve relies on the (user_id, goal_id) primary key, so of two concurrent
+// reservations exactly one inserts.
+func (r *PostgresClaimOutboxRepository) Reserve(ctx context.Context, entry *ClaimOutboxEntry) (bool, error) {
+	query := `
+		INSERT INTO claim_outbox (user_id, goal_id, challenge_id, namespace, state, client_context, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'pending', $5::jsonb, NOW(), NOW())
+		ON CONFLICT (user_id, goal_id) DO NOTHING
+	`
+
+	clientContext, err := clientContextJSON(entry.ClientContext)
+	if err != nil {
+		return false, errors.ErrDatabaseError("encode claim client context", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, entry.UserID, entry.GoalID, entry.ChallengeID, entry.Namespace, clientContext)
+	if err != nil {
+		return false, errors.ErrDatabaseError("reserve claim", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.ErrDatabaseError("reserve claim", err)
+	}
+
+	return inserted == 1, nil
+}
+
+// Get is a primary key lookup.
+func (r *PostgresClaimOutboxRepository) Get(ctx context.Context, userID, goalID string) (*ClaimOutboxEntry, error) {
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, state, reward_cap_slot, created_at, updated_at
+		FROM claim_outbox
+		WHERE user_id = $1 AND goal_id = $2
+	`
+
+	var entry ClaimOutboxEntry
+	err := r.db.QueryRowContext(ctx, query, userID, goalID).Scan(
+		&entry.UserID, &entry.GoalID, &entry.ChallengeID, &entry.Namespace,
+		&entry.State, &entry.RewardCapSlot, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get claim outbox entry", err)
+	}
+
+	return &entry, nil
+}
+
+// MarkGranted flips the entry to granted.
+func (r *PostgresClaimOutboxRepository) MarkGranted(ctx context.Context, userID, goalID string) error {
+	query := `
+		UPDATE claim_outbox
+		SET state = 'granted', updated_at = NOW()
+		WHERE user_id = $1 AND goal_id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, goalID); err != nil {
+		return errors.ErrDatabaseError("mark claim granted", err)
+	}
+
+	return nil
+}
+
+// Delete removes the entry by primary key.
+func (r *PostgresClaimOutboxRepository) Delete(ctx context.Context, userID, goalID string) error {
+	query := `DELETE FROM claim_outbox WHERE user_id = $1 AND goal_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, goalID); err != nil {
+		return errors.ErrDatabaseError("delete claim outbox entry", err)
+	}
+
+	return nil
+}
+
+// ListStale is an index range scan on idx_claim_outbox_updated_at.
+func (r *PostgresClaimOutboxRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]*ClaimOutboxEntry, error) {
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, state, reward_cap_slot, created_at, updated_at
+		FROM claim_outbox
+		WHERE updated_at < $1
+		ORDER BY updated_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list stale claims", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]*ClaimOutboxEntry, 0)
+	for rows.Next() {
+		var entry ClaimOutboxEntry
+		if err := rows.Scan(
+			&entry.UserID, &entry.GoalID, &entry.ChallengeID, &entry.Namespace,
+			&entry.State, &entry.RewardCapSlot, &entry.CreatedAt, &entry.UpdatedAt,
+		); err != nil {
+			return nil, errors.ErrDatabaseError("list stale claims", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("list stale claims", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteIfPending leaves the entry alone if the claim marked it granted in the
+// meantime. The reward cap slot is given back in the same statement, so a
+// released claim never keeps one.
+func (r *PostgresClaimOutboxRepository) DeleteIfPending(
+	ctx context.Context,
+	userID string,
+	goalID string,
+	before time.Time,
+) (bool, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM claim_outbox
+			WHERE user_id = $1 AND goal_id = $2 AND state = 'pending' AND updated_at < $3
+			RETURNING goal_id, reward_cap_slot
+		), released AS (
+			UPDATE reward_cap_counters
+			SET claimed = claimed - 1, updated_at = NOW()
+			WHERE goal_id IN (SELECT goal_id FROM deleted WHERE reward_cap_slot)
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var deleted int
+	if err := r.db.QueryRowContext(ctx, query, userID, goalID, before).Scan(&deleted); err != nil {
+		return false, errors.ErrDatabaseError("delete pending claim", err)
+	}
+
+	return deleted == 1, nil
+}
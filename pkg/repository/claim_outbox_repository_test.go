@@ -0,0 +1,185 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var claimOutboxColumns = []string{"user_id", "goal_id", "challenge_id", "namespace", "state", "reward_cap_slot", "created_at", "updated_at"}
+
+func TestReserveClaim(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		want     bool
+	}{
+		{name: "inserted", affected: 1, want: true},
+		{name: "already reserved", affected: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			mock.ExpectExec(`INSERT INTO claim_outbox .+ ON CONFLICT \(user_id, goal_id\) DO NOTHING`).
+				WithArgs("user-1", "goal-1", "challenge-1", "ns", nil).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			repo := NewPostgresClaimOutboxRepository(db)
+			reserved, err := repo.Reserve(context.Background(), &ClaimOutboxEntry{
+				UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns",
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, reserved)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReserveClaim_ClientContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO claim_outbox \(user_id, goal_id, challenge_id, namespace, state, client_context, created_at, updated_at\)\s+VALUES \(\$1, \$2, \$3, \$4, 'pending', \$5::jsonb, NOW\(\), NOW\(\)\)`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", `{"surface":"end_of_match"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	reserved, err := repo.Reserve(context.Background(), &ClaimOutboxEntry{
+		UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns",
+		ClientContext: map[string]string{"surface": "end_of_match"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClaimOutboxEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	updatedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM claim_outbox\s+WHERE user_id = \$1 AND goal_id = \$2`).
+		WithArgs("user-1", "goal-1").
+		WillReturnRows(sqlmock.NewRows(claimOutboxColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", "granted", true, updatedAt, updatedAt))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	entry, err := repo.Get(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, ClaimOutboxGranted, entry.State)
+	assert.Equal(t, "challenge-1", entry.ChallengeID)
+	assert.True(t, entry.RewardCapSlot)
+	assert.Equal(t, updatedAt, entry.UpdatedAt)
+}
+
+func TestGetClaimOutboxEntry_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM claim_outbox`).WillReturnRows(sqlmock.NewRows(claimOutboxColumns))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	entry, err := repo.Get(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestMarkClaimGrantedAndDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE claim_outbox\s+SET state = 'granted', updated_at = NOW\(\)\s+WHERE user_id = \$1 AND goal_id = \$2`).
+		WithArgs("user-1", "goal-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM claim_outbox WHERE user_id = \$1 AND goal_id = \$2`).
+		WithArgs("user-1", "goal-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	require.NoError(t, repo.MarkGranted(context.Background(), "user-1", "goal-1"))
+	require.NoError(t, repo.Delete(context.Background(), "user-1", "goal-1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListStaleClaims(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	before := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := before.Add(-time.Hour)
+	mock.ExpectQuery(`FROM claim_outbox\s+WHERE updated_at < \$1\s+ORDER BY updated_at\s+LIMIT \$2`).
+		WithArgs(before, 100).
+		WillReturnRows(sqlmock.NewRows(claimOutboxColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", "pending", false, old, old).
+			AddRow("user-2", "goal-1", "challenge-1", "ns", "granted", false, old, old))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	entries, err := repo.ListStale(context.Background(), before, 100)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ClaimOutboxPending, entries[0].State)
+	assert.Equal(t, "user-2", entries[1].UserID)
+}
+
+func TestDeleteIfPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	before := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	query := `DELETE FROM claim_outbox\s+WHERE user_id = \$1 AND goal_id = \$2 AND state = 'pending' AND updated_at < \$3\s+` +
+		`RETURNING goal_id, reward_cap_slot(.|\n)+UPDATE reward_cap_counters\s+SET claimed = claimed - 1`
+	mock.ExpectQuery(query).
+		WithArgs("user-1", "goal-1", before).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(query).
+		WithArgs("user-2", "goal-1", before).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	deleted, err := repo.DeleteIfPending(context.Background(), "user-1", "goal-1", before)
+	require.NoError(t, err)
+	assert.False(t, deleted, "an entry marked granted meanwhile is kept")
+
+	deleted, err = repo.DeleteIfPending(context.Background(), "user-2", "goal-1", before)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveClaim_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO claim_outbox`).WillReturnError(errors.New("connection refused"))
+
+	repo := NewPostgresClaimOutboxRepository(db)
+	_, err = repo.Reserve(context.Background(), &ClaimOutboxEntry{UserID: "user-1", GoalID: "goal-1"})
+
+	assert.Error(t, err)
+}
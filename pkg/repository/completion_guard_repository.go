@@ -0,0 +1,185 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/lib/pq"
+)
+
+// maxBatchUpsertRows is the most rows BatchUpsertProgress writes in one
+// statement: 7 parameters per row within PostgreSQL's 65,535.
+const maxBatchUpsertRows = 9000
+
+// NewCompletionGuardGoalRepository wraps next, the PostgreSQL goal repository of
+// extend-challenge-common on db, so that its UpsertProgress and
+// BatchUpsertProgress keep completion times. Theirs set completed_at to the
+// written row's, which can replace the first completion of a row written
+// completed again, or clear it when a rotation reset writes the row back to
+// not_started; these keep the stored completed_at whatever status is written.
+// Clearing it is left to the resets with statements of their own, such as the
+// admin resets of GoalAdminRepository, which keep it in first_completed_at
+// (migration 025). BatchUpsertProgressWithCOPY sets completed_at only when it
+// is unset, but clears or replaces it when a row rotates into a new period;
+// this one first keeps the completion of the rows about to rotate in
+// first_completed_at. The
+// other methods, and the TxRepository returned by BeginTx, are next's: the one
+// transactional UpsertProgress of this service (the claim's completion at a
+// segment target) writes a row it read FOR UPDATE in the same transaction,
+// keeping its completed_at (see service.SegmentTargets.Evaluate).
+//
+// It must wrap the PostgreSQL repository itself, inside any decorator that
+// rewrites rows (such as NewUserIDCodecGoalRepository).
+func NewCompletionGuardGoalRepository(next commonRepo.GoalRepository, db *sql.DB) commonRepo.GoalRepository {
+	return &completionGuardGoalRepository{GoalRepository: next, db: db}
+}
+
+type completionGuardGoalRepository struct {
+	commonRepo.GoalRepository
+	db *sql.DB
+}
+
+// completionGuardSet is the SET list of the guarded upserts for completed_at:
+// a stored completion is kept, even when the row is written back below its
+// target, and the row's own time is only written when none is stored.
+const completionGuardSet = `
+			completed_at = COALESCE(user_goal_progress.completed_at, EXCLUDED.completed_at)`
+
+// UpsertProgress is the UpsertProgress of extend-challenge-common with the
+// completion columns guarded.
+func (r *completionGuardGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
+	query := `
+		INSERT INTO user_goal_progress (
+			user_id, goal_id, challenge_id, namespace,
+			progress, status, completed_at, updated_at,
+			is_active, assigned_at, expires_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, NOW(), $8, $9, $10
+		)
+		ON CONFLICT (user_id, goal_id) DO UPDATE SET
+			progress = EXCLUDED.progress,
+			status = EXCLUDED.status,` + completionGuardSet + `,
+			updated_at = NOW(),
+			is_active = EXCLUDED.is_active,
+			assigned_at = EXCLUDED.assigned_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE user_goal_progress.status != 'claimed'
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		progress.UserID,
+		progress.GoalID,
+		progress.ChallengeID,
+		progress.Namespace,
+		progress.Progress,
+		progress.Status,
+		progress.CompletedAt,
+		progress.IsActive,
+		progress.AssignedAt,
+		progress.ExpiresAt,
+	)
+	if err != nil {
+		return errors.ErrDatabaseError("upsert progress", err)
+	}
+
+	return nil
+}
+
+// BatchUpsertProgress is the BatchUpsertProgress of extend-challenge-common
+// with the completion columns guarded. Like it, only active rows are updated.
+func (r *completionGuardGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*domain.UserGoalProgress) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	if len(updates) > maxBatchUpsertRows {
+		return fmt.Errorf("batch size exceeds PostgreSQL parameter limit: %d rows (max %d)", len(updates), maxBatchUpsertRows)
+	}
+
+	valueStrings := make([]string, 0, len(updates))
+	valueArgs := make([]any, 0, len(updates)*7)
+	for i, update := range updates {
+		valueStrings = append(valueStrings, fmt.Sprintf(
+			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, NOW())",
+			i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7,
+		))
+		valueArgs = append(valueArgs,
+			update.UserID,
+			update.GoalID,
+			update.ChallengeID,
+			update.Namespace,
+			update.Progress,
+			update.Status,
+			update.CompletedAt,
+		)
+	}
+
+	// Only the placeholders are formatted in; the values are parameters
+	// #nosec G201
+	query := fmt.Sprintf(`
+		INSERT INTO user_goal_progress (
+			user_id, goal_id, challenge_id, namespace,
+			progress, status, completed_at, updated_at
+		) VALUES %s
+		ON CONFLICT (user_id, goal_id) DO UPDATE SET
+			progress = EXCLUDED.progress,
+			status = EXCLUDED.status,%s,
+			updated_at = NOW()
+		WHERE user_goal_progress.status != 'claimed'
+		  AND user_goal_progress.is_active = true
+	`, strings.Join(valueStrings, ","), completionGuardSet)
+
+	if _, err := r.db.ExecContext(ctx, query, valueArgs...); err != nil {
+		return errors.ErrDatabaseError("batch upsert progress", err)
+	}
+
+	return nil
+}
+
+// BatchUpsertProgressWithCOPY is next's, after keeping the completion of each
+// row it rotates: a row with a RotationBoundary whose stored row was last
+// written before it. Rows without a boundary cost no extra statement. The copy
+// is harmless if next fails, since a first_completed_at equal to completed_at
+// means what an unset one does.
+func (r *completionGuardGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
+	var userIDs, goalIDs, boundaries []string
+	for _, row := range rows {
+		if row.RotationBoundary == nil {
+			continue
+		}
+		userIDs = append(userIDs, row.UserID)
+		goalIDs = append(goalIDs, row.GoalID)
+		boundaries = append(boundaries, row.RotationBoundary.UTC().Format(time.RFC3339Nano))
+	}
+
+	if len(userIDs) > 0 {
+		query := `
+			UPDATE user_goal_progress AS ugp
+			SET first_completed_at = ugp.completed_at
+			FROM (
+				SELECT UNNEST($1::text[]) AS user_id,
+				       UNNEST($2::text[]) AS goal_id,
+				       UNNEST($3::text[])::timestamptz AS rotation_boundary
+			) AS data
+			WHERE ugp.user_id = data.user_id
+			  AND ugp.goal_id = data.goal_id
+			  AND ugp.first_completed_at IS NULL
+			  AND ugp.completed_at IS NOT NULL
+			  AND ugp.updated_at < data.rotation_boundary
+		`
+		if _, err := r.db.ExecContext(ctx, query, pq.Array(userIDs), pq.Array(goalIDs), pq.Array(boundaries)); err != nil {
+			return errors.ErrDatabaseError("keep first completion before rotation", err)
+		}
+	}
+
+	return r.GoalRepository.BatchUpsertProgressWithCOPY(ctx, rows)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completionGuardPattern matches the guarded completed_at of an upsert.
+const completionGuardPattern = `completed_at = COALESCE\(user_goal_progress.completed_at, EXCLUDED.completed_at\),`
+
+// Re-upserting a completed row keeps its completed_at: the row's own time is
+// written over the stored one only when none is stored.
+func TestCompletionGuard_UpsertProgressKeepsCompletedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	later := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	progress := &domain.UserGoalProgress{
+		UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns",
+		Progress: 10, Status: domain.GoalStatusCompleted, CompletedAt: &later, IsActive: true,
+	}
+	mock.ExpectExec(`INSERT INTO user_goal_progress .+ ON CONFLICT \(user_id, goal_id\) DO UPDATE SET .+`+
+		completionGuardPattern+`.+WHERE user_goal_progress.status != 'claimed'`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 10, domain.GoalStatusCompleted, &later, true, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	require.NoError(t, repo.UpsertProgress(context.Background(), progress))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Writing a completed row back below its target, as a rotation reset does,
+// keeps its completed_at: the nil of the written row is not stored.
+func TestCompletionGuard_UpsertProgressRegressionKeepsCompletedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	progress := &domain.UserGoalProgress{
+		UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns",
+		Progress: 0, Status: domain.GoalStatusNotStarted, IsActive: true,
+	}
+	mock.ExpectExec(`ON CONFLICT \(user_id, goal_id\) DO UPDATE SET progress = EXCLUDED.progress, `+
+		`status = EXCLUDED.status, `+completionGuardPattern+` updated_at = NOW\(\)`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 0, domain.GoalStatusNotStarted, nil, true, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	require.NoError(t, repo.UpsertProgress(context.Background(), progress))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletionGuard_BatchUpsertProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, NOW\(\)\),\(\$8, .+`+completionGuardPattern+
+		`.+WHERE user_goal_progress.status != 'claimed' AND user_goal_progress.is_active = true`).
+		WithArgs("user-1", "goal-1", "c", "ns", 0, domain.GoalStatusNotStarted, nil,
+			"user-1", "goal-2", "c", "ns", 0, domain.GoalStatusNotStarted, nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	err = repo.BatchUpsertProgress(context.Background(), []*domain.UserGoalProgress{
+		{UserID: "user-1", GoalID: "goal-1", ChallengeID: "c", Namespace: "ns", Status: domain.GoalStatusNotStarted},
+		{UserID: "user-1", GoalID: "goal-2", ChallengeID: "c", Namespace: "ns", Status: domain.GoalStatusNotStarted},
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletionGuard_BatchUpsertProgressLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	require.NoError(t, repo.BatchUpsertProgress(context.Background(), nil))
+
+	assert.Error(t, repo.BatchUpsertProgress(context.Background(), make([]*domain.UserGoalProgress, maxBatchUpsertRows+1)))
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is written")
+}
+
+func TestCompletionGuard_UpsertProgressError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	err = repo.UpsertProgress(context.Background(), &domain.UserGoalProgress{UserID: "user-1", GoalID: "goal-1"})
+
+	assert.ErrorContains(t, err, "upsert progress")
+}
+
+// Methods without a completion hazard are next's.
+func TestCompletionGuard_OtherMethodsPassThrough(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM user_goal_progress`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+
+	repo := NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+	progress, err := repo.GetProgress(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "goal-1", progress.GoalID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Rows about to rotate keep their completion in first_completed_at before the
+// COPY upsert clears it.
+func TestCompletionGuard_BatchUpsertProgressWithCOPYKeepsRotatedCompletion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	boundary := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE user_goal_progress AS ugp SET first_completed_at = ugp.completed_at .+`+
+		`ugp.first_completed_at IS NULL AND ugp.completed_at IS NOT NULL AND ugp.updated_at < data.rotation_boundary`).
+		WithArgs(pq.Array([]string{"user-1"}), pq.Array([]string{"daily-win"}), pq.Array([]string{"2026-03-02T00:00:00Z"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	next := &copyRecorder{}
+	repo := NewCompletionGuardGoalRepository(next, db)
+	rows := []commonRepo.CopyRow{
+		{UserID: "user-1", GoalID: "daily-win", RotationBoundary: &boundary},
+		{UserID: "user-1", GoalID: "season-win"},
+	}
+	require.NoError(t, repo.BatchUpsertProgressWithCOPY(context.Background(), rows))
+
+	assert.Equal(t, rows, next.rows, "next writes every row")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompletionGuard_BatchUpsertProgressWithCOPYWithoutRotation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	next := &copyRecorder{}
+	repo := NewCompletionGuardGoalRepository(next, db)
+	rows := []commonRepo.CopyRow{{UserID: "user-1", GoalID: "season-win"}}
+	require.NoError(t, repo.BatchUpsertProgressWithCOPY(context.Background(), rows))
+
+	assert.Equal(t, rows, next.rows)
+	assert.NoError(t, mock.ExpectationsWereMet(), "no statement without a rotation")
+}
+
+func TestCompletionGuard_BatchUpsertProgressWithCOPYError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	boundary := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	next := &copyRecorder{}
+	repo := NewCompletionGuardGoalRepository(next, db)
+	err = repo.BatchUpsertProgressWithCOPY(context.Background(),
+		[]commonRepo.CopyRow{{UserID: "user-1", GoalID: "daily-win", RotationBoundary: &boundary}})
+
+	assert.ErrorContains(t, err, "keep first completion before rotation")
+	assert.Nil(t, next.rows, "rows are not written without their completion kept")
+}
+
+// copyRecorder records the rows of BatchUpsertProgressWithCOPY.
+type copyRecorder struct {
+	commonRepo.GoalRepository
+	rows []commonRepo.CopyRow
+}
+
+func (r *copyRecorder) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
+	r.rows = rows
+	return nil
+}
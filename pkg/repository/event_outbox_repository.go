@@ -0,0 +1,143 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// EventOutboxRepository stores the domain events waiting to be published
+// (event_outbox, migration 011). goal.completed and goal.claimed events are
+// written by database triggers in the transaction that changes the goal's
+// status; the service writes the others with Enqueue.
+type EventOutboxRepository interface {
+	// Enqueue inserts an event. Its ID is assigned by the database.
+	Enqueue(ctx context.Context, event *OutboxEvent) error
+
+	// PublishPending locks up to limit of the oldest events and passes them to
+	// publish, oldest first. If publish returns nil the events are deleted, in
+	// the same transaction; an error from publish is returned unchanged and the
+	// events are kept for the next call. Events locked by another call are
+	// skipped, so several replicas can publish at once. It returns the number
+	// of events published.
+	PublishPending(ctx context.Context, limit int, publish func(events []*OutboxEvent) error) (int, error)
+}
+
+// OutboxEvent is one event in the outbox.
+type OutboxEvent struct {
+	// Seq orders the events; it is the outbox row ID.
+	Seq        int64
+	EventID    string
+	Type       string
+	Namespace  string
+	UserID     string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	CreatedAt  time.Time
+}
+
+// PostgresEventOutboxRepository implements EventOutboxRepository on PostgreSQL.
+type PostgresEventOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEventOutboxRepository creates a new PostgreSQL event outbox repository.
+func NewPostgresEventOutboxRepository(db *sql.DB) *PostgresEventOutboxRepository {
+	return &PostgresEventOutboxRepository{db: db}
+}
+
+// Enqueue is a single insert; event_id defaults to a random UUID.
+func (r *PostgresEventOutboxRepository) Enqueue(ctx context.Context, event *OutboxEvent) error {
+	query := `
+		INSERT INTO event_outbox (event_type, namespace, user_id, payload, occurred_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		event.Type, event.Namespace, event.UserID, []byte(event.Payload), event.OccurredAt,
+	); err != nil {
+		return errors.ErrDatabaseError("enqueue event", err)
+	}
+
+	return nil
+}
+
+// PublishPending holds the row locks (FOR UPDATE SKIP LOCKED) while publish
+// runs, so publish must be bounded by ctx. An event published but not deleted,
+// e.g. when the process stops before the commit, is published again.
+func (r *PostgresEventOutboxRepository) PublishPending(
+	ctx context.Context,
+	limit int,
+	publish func(events []*OutboxEvent) error,
+) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("begin publish events", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		SELECT id, event_id, event_type, namespace, user_id, payload, occurred_at, created_at
+		FROM event_outbox
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("list pending events", err)
+	}
+
+	var events []*OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		var payload []byte
+		if err := rows.Scan(
+			&event.Seq, &event.EventID, &event.Type, &event.Namespace, &event.UserID,
+			&payload, &event.OccurredAt, &event.CreatedAt,
+		); err != nil {
+			_ = rows.Close()
+			return 0, errors.ErrDatabaseError("scan pending event", err)
+		}
+		event.Payload = payload
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, errors.ErrDatabaseError("list pending events", err)
+	}
+	_ = rows.Close()
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(events); err != nil {
+		return 0, err
+	}
+
+	seqs := make([]int64, len(events))
+	for i, event := range events {
+		seqs[i] = event.Seq
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_outbox WHERE id = ANY($1)`, pq.Array(seqs)); err != nil {
+		return 0, errors.ErrDatabaseError("delete published events", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.ErrDatabaseError("commit published events", err)
+	}
+
+	return len(events), nil
+}
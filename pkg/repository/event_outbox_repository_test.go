@@ -0,0 +1,116 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var eventOutboxColumns = []string{"id", "event_id", "event_type", "namespace", "user_id", "payload", "occurred_at", "created_at"}
+
+func TestEnqueueEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	occurredAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`INSERT INTO event_outbox \(event_type, namespace, user_id, payload, occurred_at, created_at\)`).
+		WithArgs("player.initialized", "ns", "user-1", []byte(`{"assignedGoalIds":["g1"]}`), occurredAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	repo := NewPostgresEventOutboxRepository(db)
+	err = repo.Enqueue(context.Background(), &OutboxEvent{
+		Type:       "player.initialized",
+		Namespace:  "ns",
+		UserID:     "user-1",
+		Payload:    json.RawMessage(`{"assignedGoalIds":["g1"]}`),
+		OccurredAt: occurredAt,
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublishPendingEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	occurredAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM event_outbox\s+ORDER BY id\s+LIMIT \$1\s+FOR UPDATE SKIP LOCKED`).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows(eventOutboxColumns).
+			AddRow(int64(7), "id-7", "goal.completed", "ns", "user-1", []byte(`{"goalId":"g1"}`), occurredAt, occurredAt).
+			AddRow(int64(8), "id-8", "goal.claimed", "ns", "user-1", []byte(`{"goalId":"g1"}`), occurredAt, occurredAt))
+	mock.ExpectExec(`DELETE FROM event_outbox WHERE id = ANY\(\$1\)`).
+		WithArgs(`{7,8}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	var published []*OutboxEvent
+	repo := NewPostgresEventOutboxRepository(db)
+	n, err := repo.PublishPending(context.Background(), 100, func(events []*OutboxEvent) error {
+		published = events
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, published, 2)
+	assert.Equal(t, "id-7", published[0].EventID)
+	assert.Equal(t, "goal.claimed", published[1].Type)
+	assert.JSONEq(t, `{"goalId":"g1"}`, string(published[0].Payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPublishPendingEvents_PublishFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM event_outbox`).
+		WillReturnRows(sqlmock.NewRows(eventOutboxColumns).
+			AddRow(int64(7), "id-7", "goal.completed", "ns", "user-1", []byte(`{}`), now, now))
+	mock.ExpectRollback()
+
+	publishErr := errors.New("broker down")
+	repo := NewPostgresEventOutboxRepository(db)
+	n, err := repo.PublishPending(context.Background(), 100, func([]*OutboxEvent) error { return publishErr })
+
+	assert.ErrorIs(t, err, publishErr)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the events are not deleted")
+}
+
+func TestPublishPendingEvents_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM event_outbox`).WillReturnRows(sqlmock.NewRows(eventOutboxColumns))
+	mock.ExpectRollback()
+
+	repo := NewPostgresEventOutboxRepository(db)
+	n, err := repo.PublishPending(context.Background(), 100, func([]*OutboxEvent) error {
+		t.Fatal("publish must not be called without events")
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
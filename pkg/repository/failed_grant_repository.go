@@ -0,0 +1,187 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// FailedGrantRepository stores the dead letters of reward grants that failed
+// permanently (failed_grants, migration 015), one per user goal.
+type FailedGrantRepository interface {
+	// RecordFailedGrant inserts the failure, or, if the user goal already has
+	// one, replaces its reward snapshot and error and increments its attempts.
+	RecordFailedGrant(ctx context.Context, grant *FailedGrant) error
+
+	// GetFailedGrant returns the failed grant with the given ID in namespace, or nil.
+	GetFailedGrant(ctx context.Context, namespace string, id int64) (*FailedGrant, error)
+
+	// ListFailedGrants returns up to limit failed grants, most recently failed first.
+	ListFailedGrants(ctx context.Context, namespace string, limit int) ([]*FailedGrant, error)
+
+	// DeleteFailedGrant deletes the user goal's failed grant, if any.
+	DeleteFailedGrant(ctx context.Context, userID, goalID string) error
+}
+
+// FailedGrant is a reward grant AGS rejected with a non-retryable error, so the
+// goal stays completed but unclaimed until the grant is retried.
+type FailedGrant struct {
+	ID          int64
+	UserID      string
+	GoalID      string
+	ChallengeID string
+	Namespace   string
+	// GrantNamespace is the namespace the grant was attempted in; empty means Namespace.
+	GrantNamespace string
+	// RewardType, RewardID and RewardQuantity are the reward of the last attempt.
+	RewardType     string
+	RewardID       string
+	RewardQuantity int
+	// ErrorClass is the kind of AGS error, e.g. "not_found".
+	ErrorClass string
+	LastError  string
+	// Attempts counts the failed claims, including retries.
+	Attempts      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+}
+
+// PostgresFailedGrantRepository implements FailedGrantRepository on PostgreSQL.
+type PostgresFailedGrantRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresFailedGrantRepository creates a new PostgreSQL failed grant repository.
+func NewPostgresFailedGrantRepository(db *sql.DB) *PostgresFailedGrantRepository {
+	return &PostgresFailedGrantRepository{db: db}
+}
+
+const failedGrantColumns = `id, user_id, goal_id, challenge_id, namespace, grant_namespace, reward_type, reward_id, reward_quantity,
+	error_class, last_error, attempts, first_failed_at, last_failed_at`
+
+func scanFailedGrant(row interface{ Scan(...any) error }) (*FailedGrant, error) {
+	var grant FailedGrant
+	if err := row.Scan(
+		&grant.ID,
+		&grant.UserID,
+		&grant.GoalID,
+		&grant.ChallengeID,
+		&grant.Namespace,
+		&grant.GrantNamespace,
+		&grant.RewardType,
+		&grant.RewardID,
+		&grant.RewardQuantity,
+		&grant.ErrorClass,
+		&grant.LastError,
+		&grant.Attempts,
+		&grant.FirstFailedAt,
+		&grant.LastFailedAt,
+	); err != nil {
+		return nil, err
+	}
+	grant.FirstFailedAt = grant.FirstFailedAt.UTC()
+	grant.LastFailedAt = grant.LastFailedAt.UTC()
+	return &grant, nil
+}
+
+// RecordFailedGrant upserts on (user_id, goal_id); first_failed_at keeps the
+// first failure.
+func (r *PostgresFailedGrantRepository) RecordFailedGrant(ctx context.Context, grant *FailedGrant) error {
+	query := `
+		INSERT INTO failed_grants (
+			user_id, goal_id, challenge_id, namespace, grant_namespace, reward_type, reward_id, reward_quantity,
+			error_class, last_error, attempts, first_failed_at, last_failed_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1, NOW(), NOW())
+		ON CONFLICT (user_id, goal_id) DO UPDATE SET
+			challenge_id = EXCLUDED.challenge_id,
+			namespace = EXCLUDED.namespace,
+			grant_namespace = EXCLUDED.grant_namespace,
+			reward_type = EXCLUDED.reward_type,
+			reward_id = EXCLUDED.reward_id,
+			reward_quantity = EXCLUDED.reward_quantity,
+			error_class = EXCLUDED.error_class,
+			last_error = EXCLUDED.last_error,
+			attempts = failed_grants.attempts + 1,
+			last_failed_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		grant.UserID, grant.GoalID, grant.ChallengeID, grant.Namespace, grant.GrantNamespace,
+		grant.RewardType, grant.RewardID, grant.RewardQuantity,
+		grant.ErrorClass, grant.LastError,
+	); err != nil {
+		return errors.ErrDatabaseError("record failed grant", err)
+	}
+
+	return nil
+}
+
+// GetFailedGrant is a primary key lookup.
+func (r *PostgresFailedGrantRepository) GetFailedGrant(ctx context.Context, namespace string, id int64) (*FailedGrant, error) {
+	query := `
+		SELECT ` + failedGrantColumns + `
+		FROM failed_grants
+		WHERE id = $1 AND namespace = $2
+	`
+
+	grant, err := scanFailedGrant(r.db.QueryRowContext(ctx, query, id, namespace))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get failed grant", err)
+	}
+
+	return grant, nil
+}
+
+// ListFailedGrants is an index range scan on idx_failed_grants_namespace_last_failed_at.
+func (r *PostgresFailedGrantRepository) ListFailedGrants(ctx context.Context, namespace string, limit int) ([]*FailedGrant, error) {
+	query := `
+		SELECT ` + failedGrantColumns + `
+		FROM failed_grants
+		WHERE namespace = $1
+		ORDER BY last_failed_at DESC, id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list failed grants", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	grants := []*FailedGrant{}
+	for rows.Next() {
+		grant, err := scanFailedGrant(rows)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("scan failed grant", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate failed grants", err)
+	}
+
+	return grants, nil
+}
+
+// DeleteFailedGrant is a unique key delete; it is a no-op for user goals
+// without a failed grant.
+func (r *PostgresFailedGrantRepository) DeleteFailedGrant(ctx context.Context, userID, goalID string) error {
+	query := `DELETE FROM failed_grants WHERE user_id = $1 AND goal_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, goalID); err != nil {
+		return errors.ErrDatabaseError("delete failed grant", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var failedGrantRowColumns = []string{
+	"id", "user_id", "goal_id", "challenge_id", "namespace", "grant_namespace", "reward_type", "reward_id", "reward_quantity",
+	"error_class", "last_error", "attempts", "first_failed_at", "last_failed_at",
+}
+
+func failedGrantRow(id int64, lastFailedAt time.Time) []driver.Value {
+	return []driver.Value{
+		id, "user-1", "goal-1", "challenge-1", "ns", "", "ITEM", "sword", 1,
+		"not_found", "resource not found: item sword", 2, lastFailedAt.Add(-time.Hour), lastFailedAt,
+	}
+}
+
+func TestRecordFailedGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO failed_grants(.|\n)+ON CONFLICT \(user_id, goal_id\) DO UPDATE SET(.|\n)+attempts = failed_grants.attempts \+ 1`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", "other-ns", "ITEM", "sword", 1, "not_found", "resource not found: item sword").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	err = repo.RecordFailedGrant(context.Background(), &FailedGrant{
+		UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns", GrantNamespace: "other-ns",
+		RewardType: "ITEM", RewardID: "sword", RewardQuantity: 1,
+		ErrorClass: "not_found", LastError: "resource not found: item sword",
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordFailedGrant_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO failed_grants`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	err = repo.RecordFailedGrant(context.Background(), &FailedGrant{UserID: "user-1", GoalID: "goal-1"})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestGetFailedGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	failedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT (.|\n)+FROM failed_grants\s+WHERE id = \$1 AND namespace = \$2`).
+		WithArgs(int64(7), "ns").
+		WillReturnRows(sqlmock.NewRows(failedGrantRowColumns).AddRow(failedGrantRow(7, failedAt)...))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	grant, err := repo.GetFailedGrant(context.Background(), "ns", 7)
+
+	require.NoError(t, err)
+	require.NotNil(t, grant)
+	assert.Equal(t, int64(7), grant.ID)
+	assert.Equal(t, "sword", grant.RewardID)
+	assert.Equal(t, "not_found", grant.ErrorClass)
+	assert.Equal(t, 2, grant.Attempts)
+	assert.Equal(t, failedAt, grant.LastFailedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFailedGrant_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM failed_grants`).WillReturnRows(sqlmock.NewRows(failedGrantRowColumns))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	grant, err := repo.GetFailedGrant(context.Background(), "ns", 7)
+
+	require.NoError(t, err)
+	assert.Nil(t, grant)
+}
+
+func TestListFailedGrants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	failedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM failed_grants\s+WHERE namespace = \$1\s+ORDER BY last_failed_at DESC, id DESC\s+LIMIT \$2`).
+		WithArgs("ns", 50).
+		WillReturnRows(sqlmock.NewRows(failedGrantRowColumns).
+			AddRow(failedGrantRow(2, failedAt)...).
+			AddRow(failedGrantRow(1, failedAt.Add(-time.Hour))...))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	grants, err := repo.ListFailedGrants(context.Background(), "ns", 50)
+
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, int64(2), grants[0].ID)
+	assert.Equal(t, int64(1), grants[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFailedGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`DELETE FROM failed_grants WHERE user_id = \$1 AND goal_id = \$2`).
+		WithArgs("user-1", "goal-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewPostgresFailedGrantRepository(db)
+	err = repo.DeleteFailedGrant(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// GoalAdminActionForceComplete is the goal_admin_audit.action of ForceComplete.
+const GoalAdminActionForceComplete = "force_complete"
+
+// GoalAdminActionFixChallengeID is the goal_admin_audit.action of FixChallengeMismatches.
+const GoalAdminActionFixChallengeID = "fix_challenge_id"
+
+// GoalAdminActionRecomputeStatus is the goal_admin_audit.action of RecomputeStatuses.
+const GoalAdminActionRecomputeStatus = "recompute_status"
+
+// GoalAdminRepository applies admin overrides to user goal progress and records
+// each one in goal_admin_audit (migration 006).
+type GoalAdminRepository interface {
+	// ForceComplete locks the user's progress row and passes it to check (nil when
+	// the user has no row for the goal). If check returns nil, the goal is marked
+	// completed and active with its progress at the target, and the audit record
+	// is written, in the same transaction. A row completed before keeps its
+	// completed_at; others get the time of the override. An error from check is returned
+	// unchanged and nothing is written. It returns the written row.
+	ForceComplete(
+		ctx context.Context,
+		completion *ForcedCompletion,
+		check func(current *domain.UserGoalProgress) error,
+	) (*domain.UserGoalProgress, error)
+
+	// FixChallengeMismatches rewrites the challenge_id of up to fix.Limit rows
+	// reported by ProgressQueryRepository.GetChallengeMismatches to the config
+	// value, writing one audit record per row in the same transaction. It returns
+	// the number of rows fixed; callers repeat until it returns 0.
+	FixChallengeMismatches(ctx context.Context, fix *ChallengeMismatchFix) (int, error)
+
+	// RecomputeStatuses locks the user's progress rows in the namespace and
+	// passes each to decide, which returns the row's new status. The rows whose
+	// status changes are updated in one statement, with one audit record each,
+	// in the same transaction. It returns the transitions written, ordered by
+	// goal ID.
+	RecomputeStatuses(
+		ctx context.Context,
+		recompute *StatusRecompute,
+		decide func(row *domain.UserGoalProgress) domain.GoalStatus,
+	) ([]StatusTransition, error)
+}
+
+// ForcedCompletion is one admin force-completion of a user's goal.
+type ForcedCompletion struct {
+	UserID       string
+	GoalID       string
+	ChallengeID  string
+	Namespace    string
+	ProgressMode domain.ProgressMode
+	TargetValue  int
+	// StepTargets are the step targets of a multi-step goal, stored as its step
+	// progress; nil for a goal with a single requirement.
+	StepTargets []int
+	// ActorUserID is the admin who forced the completion.
+	ActorUserID string
+	// ClientIP is the address the admin's request came from ("" when unknown).
+	ClientIP string
+	Reason   string
+	// Forced records that the completion overrode the inactive goal check.
+	Forced    bool
+	AutoClaim bool
+}
+
+// ChallengeMismatchFix is one admin batch of challenge_id rewrites.
+type ChallengeMismatchFix struct {
+	Namespace string
+	// GoalChallenges maps goal IDs to their challenge ID in the config.
+	GoalChallenges map[string]string
+	// Limit is the maximum number of rows rewritten.
+	Limit       int
+	ActorUserID string
+	ClientIP    string
+	Reason      string
+}
+
+// StatusRecompute is one re-evaluation of a user's goal statuses.
+type StatusRecompute struct {
+	UserID      string
+	Namespace   string
+	ActorUserID string
+	ClientIP    string
+	Reason      string
+}
+
+// StatusTransition is one status change written by RecomputeStatuses.
+type StatusTransition struct {
+	GoalID      string
+	ChallengeID string
+	From        domain.GoalStatus
+	To          domain.GoalStatus
+	// Progress is the stored progress; for relative goals it includes the baseline.
+	Progress int
+}
+
+// PostgresGoalAdminRepository implements GoalAdminRepository on PostgreSQL.
+type PostgresGoalAdminRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresGoalAdminRepository creates a new PostgreSQL goal admin repository.
+func NewPostgresGoalAdminRepository(db *sql.DB) *PostgresGoalAdminRepository {
+	return &PostgresGoalAdminRepository{db: db}
+}
+
+// ForceComplete holds the row lock from the check to the commit. When the user
+// has no row yet, the upsert's ON CONFLICT ... WHERE status != 'claimed' guards
+// against a row claimed in between; that case returns ErrGoalAlreadyClaimed.
+//
+// Relative goals get progress baseline_value + target, so the goal still reads
+// as complete against its baseline.
+//
+// A goal the completion activates gets activation_source 'admin'; an active
+// goal keeps its source. step_progress is set to the step targets, or NULL for
+// a goal with a single requirement.
+func (r *PostgresGoalAdminRepository) ForceComplete(
+	ctx context.Context,
+	completion *ForcedCompletion,
+	check func(current *domain.UserGoalProgress) error,
+) (*domain.UserGoalProgress, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin force complete", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockProgress(ctx, tx, completion.UserID, completion.GoalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	progress := completion.TargetValue
+	if completion.ProgressMode == domain.ProgressModeRelative && current != nil && current.BaselineValue != nil {
+		progress += *current.BaselineValue
+	}
+
+	var stepProgress sql.NullString
+	if len(completion.StepTargets) > 0 {
+		steps, err := json.Marshal(completion.StepTargets)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("encode step progress", err)
+		}
+		stepProgress = sql.NullString{String: string(steps), Valid: true}
+	}
+
+	upsert := `
+		INSERT INTO user_goal_progress (
+			user_id, goal_id, challenge_id, namespace, progress, status,
+			completed_at, is_active, assigned_at, activation_source, step_progress, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, 'completed', NOW(), true, NOW(), 'admin', $6::jsonb, NOW(), NOW())
+		ON CONFLICT (user_id, goal_id) DO UPDATE SET
+			progress = EXCLUDED.progress,
+			step_progress = EXCLUDED.step_progress,
+			status = 'completed',
+			completed_at = COALESCE(user_goal_progress.completed_at, NOW()),
+			is_active = true,
+			assigned_at = COALESCE(user_goal_progress.assigned_at, NOW()),
+			activation_source = CASE WHEN user_goal_progress.is_active
+				THEN user_goal_progress.activation_source ELSE 'admin' END,
+			updated_at = NOW()
+		WHERE user_goal_progress.status != 'claimed'
+		RETURNING user_id, goal_id, challenge_id, namespace, progress, status,
+		          completed_at, claimed_at, created_at, updated_at,
+		          is_active, assigned_at, expires_at, baseline_value
+	`
+
+	written, err := scanProgressRow(tx.QueryRowContext(ctx, upsert,
+		completion.UserID,
+		completion.GoalID,
+		completion.ChallengeID,
+		completion.Namespace,
+		progress,
+		stepProgress,
+	))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrGoalAlreadyClaimed(completion.GoalID)
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("force complete goal", err)
+	}
+
+	var previousStatus sql.NullString
+	var previousProgress sql.NullInt64
+	if current != nil {
+		previousStatus = sql.NullString{String: string(current.Status), Valid: true}
+		previousProgress = sql.NullInt64{Int64: int64(current.Progress), Valid: true}
+	}
+
+	audit := `
+		INSERT INTO goal_admin_audit (
+			user_id, goal_id, challenge_id, namespace, action, actor_user_id,
+			reason, forced, auto_claim, previous_status, previous_progress, client_ip, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NOW())
+	`
+
+	if _, err := tx.ExecContext(ctx, audit,
+		completion.UserID,
+		completion.GoalID,
+		completion.ChallengeID,
+		completion.Namespace,
+		GoalAdminActionForceComplete,
+		completion.ActorUserID,
+		completion.Reason,
+		completion.Forced,
+		completion.AutoClaim,
+		previousStatus,
+		previousProgress,
+		completion.ClientIP,
+	); err != nil {
+		return nil, errors.ErrDatabaseError("write goal admin audit", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit force complete", err)
+	}
+
+	return written, nil
+}
+
+// FixChallengeMismatches is safe to run while the service is serving and to
+// repeat: each batch only locks the rows it rewrites, rows locked by an
+// in-flight claim or progress update are skipped (SKIP LOCKED) and picked up by a
+// later batch, and fixed rows no longer match. Only challenge_id changes;
+// updated_at is left alone because rotation checks read it.
+func (r *PostgresGoalAdminRepository) FixChallengeMismatches(ctx context.Context, fix *ChallengeMismatchFix) (int, error) {
+	if fix.Limit <= 0 {
+		return 0, fmt.Errorf("limit must be positive, got %d", fix.Limit)
+	}
+	if len(fix.GoalChallenges) == 0 {
+		return 0, nil
+	}
+
+	goalIDs, challengeIDs := goalChallengeArrays(fix.GoalChallenges)
+	query := `
+		WITH candidates AS (
+			SELECT p.user_id, p.goal_id, p.challenge_id AS previous_challenge_id, e.challenge_id
+			FROM user_goal_progress p
+			JOIN unnest($2::text[], $3::text[]) AS e(goal_id, challenge_id) ON e.goal_id = p.goal_id
+			WHERE p.namespace = $1 AND p.challenge_id <> e.challenge_id
+			ORDER BY p.goal_id, p.user_id
+			LIMIT $4
+			FOR UPDATE OF p SKIP LOCKED
+		),
+		fixed AS (
+			UPDATE user_goal_progress p
+			SET challenge_id = c.challenge_id
+			FROM candidates c
+			WHERE p.user_id = c.user_id AND p.goal_id = c.goal_id
+			RETURNING p.user_id, p.goal_id, p.challenge_id, p.namespace, p.status, p.progress,
+			          c.previous_challenge_id
+		)
+		INSERT INTO goal_admin_audit (
+			user_id, goal_id, challenge_id, namespace, action, actor_user_id, reason,
+			previous_status, previous_progress, previous_challenge_id, client_ip, created_at
+		)
+		SELECT user_id, goal_id, challenge_id, namespace, $5, $6, $7,
+		       status, progress, previous_challenge_id, NULLIF($8, ''), NOW()
+		FROM fixed
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		fix.Namespace,
+		pq.Array(goalIDs),
+		pq.Array(challengeIDs),
+		fix.Limit,
+		GoalAdminActionFixChallengeID,
+		fix.ActorUserID,
+		fix.Reason,
+		fix.ClientIP,
+	)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("fix challenge mismatches", err)
+	}
+
+	fixed, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("fix challenge mismatches", err)
+	}
+
+	return int(fixed), nil
+}
+
+// RecomputeStatuses holds the locks of all the user's rows until the commit,
+// so progress written meanwhile waits and is applied to the new status. A row
+// becoming completed gets completed_at now unless already set; a row leaving
+// completed loses it, keeping it in first_completed_at (migration 025).
+func (r *PostgresGoalAdminRepository) RecomputeStatuses(
+	ctx context.Context,
+	recompute *StatusRecompute,
+	decide func(row *domain.UserGoalProgress) domain.GoalStatus,
+) ([]StatusTransition, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin status recompute", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, progress, status,
+		       completed_at, claimed_at, created_at, updated_at,
+		       is_active, assigned_at, expires_at, baseline_value
+		FROM user_goal_progress
+		WHERE user_id = $1 AND namespace = $2
+		ORDER BY goal_id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, recompute.UserID, recompute.Namespace)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("lock progress for status recompute", err)
+	}
+	current, err := scanProgressRows(rows)
+	_ = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var transitions []StatusTransition
+	for _, row := range current {
+		if status := decide(row); status != row.Status {
+			transitions = append(transitions, StatusTransition{
+				GoalID:      row.GoalID,
+				ChallengeID: row.ChallengeID,
+				From:        row.Status,
+				To:          status,
+				Progress:    row.Progress,
+			})
+		}
+	}
+	if len(transitions) == 0 {
+		return nil, nil
+	}
+
+	goalIDs := make([]string, len(transitions))
+	statuses := make([]string, len(transitions))
+	previous := make([]string, len(transitions))
+	for i, transition := range transitions {
+		goalIDs[i] = transition.GoalID
+		statuses[i] = string(transition.To)
+		previous[i] = string(transition.From)
+	}
+
+	update := `
+		WITH updated AS (
+			UPDATE user_goal_progress p
+			SET status = c.status,
+			    completed_at = CASE WHEN c.status = 'completed' THEN COALESCE(p.completed_at, NOW()) END,
+			    first_completed_at = COALESCE(p.first_completed_at, p.completed_at),
+			    updated_at = NOW()
+			FROM unnest($3::text[], $4::text[], $5::text[]) AS c(goal_id, status, previous_status)
+			WHERE p.user_id = $1 AND p.namespace = $2
+			  AND p.goal_id = c.goal_id AND p.status = c.previous_status
+			RETURNING p.user_id, p.goal_id, p.challenge_id, p.namespace, p.progress, c.previous_status
+		)
+		INSERT INTO goal_admin_audit (
+			user_id, goal_id, challenge_id, namespace, action, actor_user_id, reason,
+			previous_status, previous_progress, client_ip, created_at
+		)
+		SELECT user_id, goal_id, challenge_id, namespace, $6, $7, $8,
+		       previous_status, progress, NULLIF($9, ''), NOW()
+		FROM updated
+	`
+
+	result, err := tx.ExecContext(ctx, update,
+		recompute.UserID,
+		recompute.Namespace,
+		pq.Array(goalIDs),
+		pq.Array(statuses),
+		pq.Array(previous),
+		GoalAdminActionRecomputeStatus,
+		recompute.ActorUserID,
+		recompute.Reason,
+		recompute.ClientIP,
+	)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("update recomputed statuses", err)
+	}
+
+	written, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.ErrDatabaseError("update recomputed statuses", err)
+	}
+	// The rows are locked, so every transition is written
+	if int(written) != len(transitions) {
+		return nil, errors.ErrDatabaseError("update recomputed statuses",
+			fmt.Errorf("wrote %d of %d status changes", written, len(transitions)))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit status recompute", err)
+	}
+
+	return transitions, nil
+}
+
+// lockProgress is GetProgressForUpdate on a transaction owned by this package.
+func lockProgress(ctx context.Context, tx *sql.Tx, userID, goalID string) (*domain.UserGoalProgress, error) {
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, progress, status,
+		       completed_at, claimed_at, created_at, updated_at,
+		       is_active, assigned_at, expires_at, baseline_value
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = $2
+		FOR UPDATE
+	`
+
+	progress, err := scanProgressRow(tx.QueryRowContext(ctx, query, userID, goalID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get progress for update", err)
+	}
+
+	return progress, nil
+}
+
+// scanProgressRow scans the columns selected by lockProgress.
+func scanProgressRow(row *sql.Row) (*domain.UserGoalProgress, error) {
+	var progress domain.UserGoalProgress
+	if err := row.Scan(
+		&progress.UserID,
+		&progress.GoalID,
+		&progress.ChallengeID,
+		&progress.Namespace,
+		&progress.Progress,
+		&progress.Status,
+		&progress.CompletedAt,
+		&progress.ClaimedAt,
+		&progress.CreatedAt,
+		&progress.UpdatedAt,
+		&progress.IsActive,
+		&progress.AssignedAt,
+		&progress.ExpiresAt,
+		&progress.BaselineValue,
+	); err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
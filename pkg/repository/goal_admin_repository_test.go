@@ -0,0 +1,379 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var progressRowColumns = []string{
+	"user_id", "goal_id", "challenge_id", "namespace", "progress", "status",
+	"completed_at", "claimed_at", "created_at", "updated_at",
+	"is_active", "assigned_at", "expires_at", "baseline_value",
+}
+
+func newForcedCompletion() *ForcedCompletion {
+	return &ForcedCompletion{
+		UserID:       "user-1",
+		GoalID:       "goal-1",
+		ChallengeID:  "challenge-1",
+		Namespace:    "ns",
+		ProgressMode: domain.ProgressModeAbsolute,
+		TargetValue:  10,
+		ActorUserID:  "admin-1",
+		ClientIP:     "198.51.100.1",
+		Reason:       "ticket 123",
+		Forced:       true,
+	}
+}
+
+func TestForceComplete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_goal_progress\s+WHERE user_id = \$1 AND goal_id = \$2\s+FOR UPDATE`).
+		WithArgs("user-1", "goal-1").
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 7, "in_progress", nil, nil, now, now, false, now, nil, nil))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress .+ ON CONFLICT \(user_id, goal_id\) DO UPDATE SET .+ WHERE user_goal_progress.status != 'claimed'`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 10, nil).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "completed", now, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", GoalAdminActionForceComplete, "admin-1", "ticket 123",
+			true, false, "in_progress", int64(7), "198.51.100.1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	var checked *domain.UserGoalProgress
+	repo := NewPostgresGoalAdminRepository(db)
+	written, err := repo.ForceComplete(context.Background(), newForcedCompletion(), func(current *domain.UserGoalProgress) error {
+		checked = current
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, checked)
+	assert.False(t, checked.IsActive)
+	assert.Equal(t, domain.GoalStatusCompleted, written.Status)
+	assert.True(t, written.IsActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Force-completing a completed row (e.g. to fix its progress) keeps the time
+// the user first completed it.
+func TestForceComplete_CompletedRowKeepsCompletedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	completedAt := time.Date(2024, 12, 24, 18, 30, 0, 0, time.UTC)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 8, "completed", completedAt, nil, now, now, true, now, nil, nil))
+	mock.ExpectQuery(`completed_at = COALESCE\(user_goal_progress\.completed_at, NOW\(\)\)`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 10, nil).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "completed", completedAt, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	written, err := repo.ForceComplete(context.Background(), newForcedCompletion(), func(*domain.UserGoalProgress) error { return nil })
+
+	require.NoError(t, err)
+	require.NotNil(t, written.CompletedAt)
+	assert.Equal(t, completedAt, *written.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceComplete_NoRowRelativeGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 10, nil).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "completed", now, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", GoalAdminActionForceComplete, "admin-1", "ticket 123",
+			true, false, nil, nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	completion := newForcedCompletion()
+	completion.ProgressMode = domain.ProgressModeRelative
+	completion.ClientIP = "" // Unknown address, stored as NULL
+
+	var checked *domain.UserGoalProgress
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.ForceComplete(context.Background(), completion, func(current *domain.UserGoalProgress) error {
+		checked = current
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, checked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceComplete_RelativeGoalAddsBaseline(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 45, "in_progress", nil, nil, now, now, true, now, nil, 40))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 50, nil).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 50, "completed", now, nil, now, now, true, now, nil, 40))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	completion := newForcedCompletion()
+	completion.ProgressMode = domain.ProgressModeRelative
+
+	repo := NewPostgresGoalAdminRepository(db)
+	written, err := repo.ForceComplete(context.Background(), completion, func(*domain.UserGoalProgress) error { return nil })
+
+	require.NoError(t, err)
+	assert.Equal(t, 50, written.Progress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceComplete_StepTargets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress .+ step_progress = EXCLUDED.step_progress`).
+		WithArgs("user-1", "goal-1", "challenge-1", "ns", 10, "[10,5000]").
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "completed", now, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	completion := newForcedCompletion()
+	completion.StepTargets = []int{10, 5000}
+
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.ForceComplete(context.Background(), completion, func(*domain.UserGoalProgress) error { return nil })
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceComplete_CheckRefuses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "claimed", now, now, now, now, true, now, nil, nil))
+	mock.ExpectRollback()
+
+	refused := errors.New("refused")
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.ForceComplete(context.Background(), newForcedCompletion(), func(*domain.UserGoalProgress) error {
+		return refused
+	})
+
+	assert.Same(t, refused, err)
+	assert.NoError(t, mock.ExpectationsWereMet(), "nothing is written")
+}
+
+func TestForceComplete_ClaimedAfterCheck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+	mock.ExpectRollback()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.ForceComplete(context.Background(), newForcedCompletion(), func(*domain.UserGoalProgress) error { return nil })
+
+	assert.ErrorContains(t, err, "already claimed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForceComplete_AuditError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 10, "completed", now, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`INSERT INTO goal_admin_audit`).WillReturnError(errors.New("connection refused"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.ForceComplete(context.Background(), newForcedCompletion(), func(*domain.UserGoalProgress) error { return nil })
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet(), "the completion is rolled back with the audit record")
+}
+
+func TestFixChallengeMismatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`FOR UPDATE OF p SKIP LOCKED(.|\n)+UPDATE user_goal_progress p\s+SET challenge_id = c.challenge_id\s+FROM candidates c(.|\n)+INSERT INTO goal_admin_audit`).
+		WithArgs("ns", pq.Array([]string{"kills"}), pq.Array([]string{"weekly"}), 100,
+			GoalAdminActionFixChallengeID, "admin-1", "moved kills to weekly", "198.51.100.1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := NewPostgresGoalAdminRepository(db)
+	fixed, err := repo.FixChallengeMismatches(context.Background(), &ChallengeMismatchFix{
+		Namespace:      "ns",
+		GoalChallenges: map[string]string{"kills": "weekly"},
+		Limit:          100,
+		ActorUserID:    "admin-1",
+		ClientIP:       "198.51.100.1",
+		Reason:         "moved kills to weekly",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, fixed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFixChallengeMismatches_InvalidLimit(t *testing.T) {
+	repo := NewPostgresGoalAdminRepository(nil)
+	_, err := repo.FixChallengeMismatches(context.Background(), &ChallengeMismatchFix{
+		GoalChallenges: map[string]string{"kills": "weekly"},
+	})
+
+	assert.Error(t, err)
+}
+
+func newStatusRecompute() *StatusRecompute {
+	return &StatusRecompute{
+		UserID:      "user-1",
+		Namespace:   "ns",
+		ActorUserID: "admin-1",
+		ClientIP:    "198.51.100.1",
+		Reason:      "target lowered",
+	}
+}
+
+func TestRecomputeStatuses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_goal_progress\s+WHERE user_id = \$1 AND namespace = \$2\s+ORDER BY goal_id\s+FOR UPDATE`).
+		WithArgs("user-1", "ns").
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 7, "in_progress", nil, nil, now, now, true, now, nil, nil).
+			AddRow("user-1", "goal-2", "challenge-1", "ns", 2, "completed", now, nil, now, now, true, now, nil, nil).
+			AddRow("user-1", "goal-3", "challenge-1", "ns", 1, "claimed", now, now, now, now, true, now, nil, nil))
+	mock.ExpectExec(`UPDATE user_goal_progress p\s+SET status = c.status,\s+`+
+		`completed_at = CASE WHEN c.status = 'completed' THEN COALESCE\(p.completed_at, NOW\(\)\) END,\s+`+
+		`first_completed_at = COALESCE\(p.first_completed_at, p.completed_at\).+INSERT INTO goal_admin_audit`).
+		WithArgs("user-1", "ns", `{"goal-1","goal-2"}`, `{"completed","in_progress"}`, `{"in_progress","completed"}`,
+			GoalAdminActionRecomputeStatus, "admin-1", "target lowered", "198.51.100.1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	transitions, err := repo.RecomputeStatuses(context.Background(), newStatusRecompute(), func(row *domain.UserGoalProgress) domain.GoalStatus {
+		switch row.GoalID {
+		case "goal-1":
+			return domain.GoalStatusCompleted
+		case "goal-2":
+			return domain.GoalStatusInProgress
+		}
+		return row.Status
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []StatusTransition{
+		{GoalID: "goal-1", ChallengeID: "challenge-1", From: domain.GoalStatusInProgress, To: domain.GoalStatusCompleted, Progress: 7},
+		{GoalID: "goal-2", ChallengeID: "challenge-1", From: domain.GoalStatusCompleted, To: domain.GoalStatusInProgress, Progress: 2},
+	}, transitions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecomputeStatuses_NoChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 7, "in_progress", nil, nil, now, now, true, now, nil, nil))
+	mock.ExpectRollback()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	transitions, err := repo.RecomputeStatuses(context.Background(), newStatusRecompute(), func(row *domain.UserGoalProgress) domain.GoalStatus {
+		return row.Status
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecomputeStatuses_PartialWrite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user-1", "goal-1", "challenge-1", "ns", 7, "in_progress", nil, nil, now, now, true, now, nil, nil))
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	repo := NewPostgresGoalAdminRepository(db)
+	_, err = repo.RecomputeStatuses(context.Background(), newStatusRecompute(), func(*domain.UserGoalProgress) domain.GoalStatus {
+		return domain.GoalStatusCompleted
+	})
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// GoalResetRepository discards the progress of goals players abandon
+// (SetGoalActive with reset_progress). UpsertGoalActive in
+// extend-challenge-common keeps progress on deactivation, so the reset is a
+// separate write.
+type GoalResetRepository interface {
+	// DeactivateAndReset deactivates the user's row of goalID and discards its
+	// progress: progress 0, status not_started, and completed_at, baseline_value
+	// and step_progress cleared. assigned_at, the activation source and the first
+	// completion (first_completed_at) are kept.
+	// A claimed row is left as it is. It returns the written row, or nil when
+	// the user has no unclaimed row for the goal.
+	DeactivateAndReset(ctx context.Context, userID string, goalID string) (*domain.UserGoalProgress, error)
+}
+
+// PostgresGoalResetRepository implements GoalResetRepository on PostgreSQL.
+type PostgresGoalResetRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresGoalResetRepository creates a new PostgreSQL goal reset repository.
+func NewPostgresGoalResetRepository(db *sql.DB) *PostgresGoalResetRepository {
+	return &PostgresGoalResetRepository{db: db}
+}
+
+// DeactivateAndReset checks the claimed status in the update itself, so a claim
+// committed after the caller read the row is never reset.
+func (r *PostgresGoalResetRepository) DeactivateAndReset(
+	ctx context.Context,
+	userID string,
+	goalID string,
+) (*domain.UserGoalProgress, error) {
+	query := `
+		UPDATE user_goal_progress
+		SET is_active = false,
+		    progress = 0,
+		    status = 'not_started',
+		    first_completed_at = COALESCE(first_completed_at, completed_at),
+		    completed_at = NULL,
+		    baseline_value = NULL,
+		    step_progress = NULL,
+		    updated_at = NOW()
+		WHERE user_id = $1 AND goal_id = $2 AND status <> 'claimed'
+		RETURNING user_id, goal_id, challenge_id, namespace, progress, status,
+		          completed_at, claimed_at, created_at, updated_at,
+		          is_active, assigned_at, expires_at, baseline_value
+	`
+
+	progress, err := scanProgressRow(r.db.QueryRowContext(ctx, query, userID, goalID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("deactivate and reset goal", err)
+	}
+
+	return progress, nil
+}
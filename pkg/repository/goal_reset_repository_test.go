@@ -0,0 +1,70 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeactivateAndReset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SET is_active = false,\s+progress = 0,\s+status = 'not_started',\s+`+
+		`first_completed_at = COALESCE\(first_completed_at, completed_at\),\s+completed_at = NULL,\s+`+
+		`baseline_value = NULL,\s+step_progress = NULL(.|\n)+WHERE user_id = \$1 AND goal_id = \$2 AND status <> 'claimed'`).
+		WithArgs("user1", "goal1").
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow("user1", "goal1", "c1", "ns", 0, "not_started", nil, nil, now, now, false, now, nil, nil))
+
+	repo := NewPostgresGoalResetRepository(db)
+	progress, err := repo.DeactivateAndReset(context.Background(), "user1", "goal1")
+
+	require.NoError(t, err)
+	require.NotNil(t, progress)
+	assert.Equal(t, 0, progress.Progress)
+	assert.False(t, progress.IsActive)
+	assert.Nil(t, progress.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeactivateAndReset_ClaimedOrMissingRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WillReturnRows(sqlmock.NewRows(progressRowColumns))
+
+	repo := NewPostgresGoalResetRepository(db)
+	progress, err := repo.DeactivateAndReset(context.Background(), "user1", "goal1")
+
+	require.NoError(t, err)
+	assert.Nil(t, progress)
+}
+
+func TestDeactivateAndReset_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresGoalResetRepository(db)
+	_, err = repo.DeactivateAndReset(context.Background(), "user1", "goal1")
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
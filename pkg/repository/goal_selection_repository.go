@@ -0,0 +1,360 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/lib/pq"
+)
+
+// GoalSelectionRepository applies goal selections and keeps their history in
+// goal_selection_events (migration 013).
+//
+// The selection writes of extend-challenge-common (TxRepository.BatchUpsertGoalActive)
+// run in a transaction this package cannot join, so ApplySelection makes the
+// same writes itself to record the event atomically with them.
+type GoalSelectionRepository interface {
+	// ApplySelection deactivates selection.Deactivate, activates selection.Activate
+	// and records the selection event, in one transaction. Activated goals keep
+	// their progress, as with BatchUpsertGoalActive; missing rows are created
+	// not started. It returns the user's active goals in the challenge after
+	// the writes, counted in the transaction.
+	ApplySelection(ctx context.Context, selection *GoalSelection) (int, error)
+
+	// GetSelectionHistory returns up to limit of the user's selections, newest first.
+	GetSelectionHistory(ctx context.Context, userID, namespace string, limit int) ([]*GoalSelectionEvent, error)
+
+	// GetSelectionStats counts, per goal, how often it was selected and replaced
+	// since the given time, optionally for one challenge ("" for all).
+	GetSelectionStats(ctx context.Context, namespace, challengeID string, since time.Time) ([]*GoalSelectionCount, error)
+
+	// DeleteSelectionEventsBefore deletes up to limit of the oldest events created
+	// before the given time and returns the number deleted.
+	DeleteSelectionEventsBefore(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// ActiveGoalCounter counts a user's active goals in a challenge.
+type ActiveGoalCounter interface {
+	CountActiveGoals(ctx context.Context, userID, challengeID string) (int, error)
+}
+
+// TxRepository is a TxRepository of extend-challenge-common that also counts
+// active goals inside the transaction.
+type TxRepository interface {
+	commonRepo.TxRepository
+	ActiveGoalCounter
+}
+
+// CountActiveGoals counts the user's active goals in challengeID inside tx,
+// with one COUNT query when tx is an ActiveGoalCounter. The transactions of
+// extend-challenge-common are not, so their active rows are read instead.
+func CountActiveGoals(ctx context.Context, tx commonRepo.TxRepository, userID, challengeID string) (int, error) {
+	if counter, ok := tx.(ActiveGoalCounter); ok {
+		return counter.CountActiveGoals(ctx, userID, challengeID)
+	}
+
+	active, err := tx.GetChallengeProgress(ctx, userID, challengeID, true)
+	if err != nil {
+		return 0, err
+	}
+	return len(active), nil
+}
+
+// countActiveGoalsQuery counts a user's active goals in a challenge.
+const countActiveGoalsQuery = `
+	SELECT COUNT(*)
+	FROM user_goal_progress
+	WHERE user_id = $1 AND challenge_id = $2 AND is_active = true
+`
+
+// GoalSelection is one RandomSelectGoals or BatchSelectGoals call.
+type GoalSelection struct {
+	UserID      string
+	Namespace   string
+	ChallengeID string
+	// SelectedGoalIDs are the goals the selection picked, including those
+	// already active.
+	SelectedGoalIDs []string
+	// Activate are the selected goals that are not active yet.
+	Activate []string
+	// Deactivate are the active goals the selection replaced.
+	Deactivate []string
+	// Source is "random" or "manual".
+	Source string
+	// ClientContext is the analytics metadata the client sent with the
+	// selection (client_context, migration 023); nil when it sent none.
+	ClientContext map[string]string
+}
+
+// GoalSelectionEvent is a recorded selection.
+type GoalSelectionEvent struct {
+	ID              int64
+	UserID          string
+	ChallengeID     string
+	SelectedGoalIDs []string
+	ReplacedGoalIDs []string
+	Source          string
+	ClientContext   map[string]string
+	CreatedAt       time.Time
+}
+
+// GoalSelectionCount is how often a goal was selected and replaced.
+type GoalSelectionCount struct {
+	ChallengeID string
+	GoalID      string
+	Selected    int64
+	Replaced    int64
+}
+
+// PostgresGoalSelectionRepository implements GoalSelectionRepository on PostgreSQL.
+type PostgresGoalSelectionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresGoalSelectionRepository creates a new PostgreSQL goal selection repository.
+func NewPostgresGoalSelectionRepository(db *sql.DB) *PostgresGoalSelectionRepository {
+	return &PostgresGoalSelectionRepository{db: db}
+}
+
+// ApplySelection issues at most one statement each for the deactivations, the
+// activations and the event, and counts the active goals after them. A
+// selection that changes nothing only writes the event and counts.
+func (r *PostgresGoalSelectionRepository) ApplySelection(ctx context.Context, selection *GoalSelection) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("begin apply goal selection", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	if len(selection.Deactivate) > 0 {
+		query := `
+			UPDATE user_goal_progress
+			SET is_active = false, assigned_at = NULL, updated_at = NOW()
+			WHERE user_id = $1 AND goal_id = ANY($2)
+		`
+		if _, err := tx.ExecContext(ctx, query, selection.UserID, pq.Array(selection.Deactivate)); err != nil {
+			return 0, errors.ErrDatabaseError("deactivate goals", err)
+		}
+	}
+
+	if len(selection.Activate) > 0 {
+		const columns = 4
+		valueStrings := make([]string, 0, len(selection.Activate))
+		valueArgs := make([]interface{}, 0, len(selection.Activate)*columns)
+		for i, goalID := range selection.Activate {
+			n := i * columns
+			valueStrings = append(valueStrings, fmt.Sprintf(
+				"($%d, $%d, $%d, $%d, 0, 'not_started', true, NOW(), NOW(), NOW())",
+				n+1, n+2, n+3, n+4,
+			))
+			valueArgs = append(valueArgs, selection.UserID, goalID, selection.ChallengeID, selection.Namespace)
+		}
+
+		//nolint:gosec // Safe: valueStrings contains only parameterized placeholders like "($1, $2, $3)", not user input
+		query := fmt.Sprintf(`
+			INSERT INTO user_goal_progress (
+				user_id, goal_id, challenge_id, namespace,
+				progress, status, is_active, assigned_at,
+				created_at, updated_at
+			) VALUES %s
+			ON CONFLICT (user_id, goal_id) DO UPDATE SET
+				is_active = true,
+				assigned_at = NOW(),
+				updated_at = NOW()
+		`, strings.Join(valueStrings, ","))
+
+		if _, err := tx.ExecContext(ctx, query, valueArgs...); err != nil {
+			return 0, errors.ErrDatabaseError("activate goals", err)
+		}
+	}
+
+	var totalActive int
+	if err := tx.QueryRowContext(ctx, countActiveGoalsQuery, selection.UserID, selection.ChallengeID).Scan(&totalActive); err != nil {
+		return 0, errors.ErrDatabaseError("count active goals", err)
+	}
+
+	clientContext, err := clientContextJSON(selection.ClientContext)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("encode selection client context", err)
+	}
+
+	event := `
+		INSERT INTO goal_selection_events (
+			user_id, namespace, challenge_id, selected_goal_ids, replaced_goal_ids, source, client_context, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, NOW())
+	`
+	if _, err := tx.ExecContext(ctx, event,
+		selection.UserID,
+		selection.Namespace,
+		selection.ChallengeID,
+		pq.Array(nonNil(selection.SelectedGoalIDs)),
+		pq.Array(nonNil(selection.Deactivate)),
+		selection.Source,
+		clientContext,
+	); err != nil {
+		return 0, errors.ErrDatabaseError("insert goal selection event", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.ErrDatabaseError("commit goal selection", err)
+	}
+
+	return totalActive, nil
+}
+
+// GetSelectionHistory uses idx_goal_selection_events_user_created_at.
+func (r *PostgresGoalSelectionRepository) GetSelectionHistory(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	limit int,
+) ([]*GoalSelectionEvent, error) {
+	query := `
+		SELECT id, user_id, challenge_id, selected_goal_ids, replaced_goal_ids, source, client_context, created_at
+		FROM goal_selection_events
+		WHERE user_id = $1 AND namespace = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, namespace, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get goal selection history", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	history := []*GoalSelectionEvent{}
+	for rows.Next() {
+		var event GoalSelectionEvent
+		var clientContext []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.ChallengeID,
+			pq.Array(&event.SelectedGoalIDs),
+			pq.Array(&event.ReplacedGoalIDs),
+			&event.Source,
+			&clientContext,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, errors.ErrDatabaseError("scan goal selection event", err)
+		}
+		if len(clientContext) > 0 {
+			if err := json.Unmarshal(clientContext, &event.ClientContext); err != nil {
+				return nil, errors.ErrDatabaseError("decode selection client context", err)
+			}
+		}
+		event.CreatedAt = event.CreatedAt.UTC()
+		history = append(history, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate goal selection history", err)
+	}
+
+	return history, nil
+}
+
+// GetSelectionStats scans the namespace's events since the given time through
+// idx_goal_selection_events_namespace_created_at and unnests their goal IDs.
+func (r *PostgresGoalSelectionRepository) GetSelectionStats(
+	ctx context.Context,
+	namespace string,
+	challengeID string,
+	since time.Time,
+) ([]*GoalSelectionCount, error) {
+	query := `
+		WITH events AS (
+			SELECT challenge_id, selected_goal_ids, replaced_goal_ids
+			FROM goal_selection_events
+			WHERE namespace = $1 AND created_at >= $2 AND ($3 = '' OR challenge_id = $3)
+		)
+		SELECT challenge_id, goal_id, SUM(selected), SUM(replaced)
+		FROM (
+			SELECT challenge_id, UNNEST(selected_goal_ids) AS goal_id, 1 AS selected, 0 AS replaced FROM events
+			UNION ALL
+			SELECT challenge_id, UNNEST(replaced_goal_ids) AS goal_id, 0 AS selected, 1 AS replaced FROM events
+		) AS counts
+		GROUP BY challenge_id, goal_id
+		ORDER BY challenge_id, goal_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, since, challengeID)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get goal selection stats", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := []*GoalSelectionCount{}
+	for rows.Next() {
+		var count GoalSelectionCount
+		if err := rows.Scan(&count.ChallengeID, &count.GoalID, &count.Selected, &count.Replaced); err != nil {
+			return nil, errors.ErrDatabaseError("scan goal selection count", err)
+		}
+		stats = append(stats, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate goal selection stats", err)
+	}
+
+	return stats, nil
+}
+
+// DeleteSelectionEventsBefore deletes in batches through
+// idx_goal_selection_events_created_at, so each call holds its locks briefly.
+func (r *PostgresGoalSelectionRepository) DeleteSelectionEventsBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	query := `
+		DELETE FROM goal_selection_events
+		WHERE id IN (
+			SELECT id FROM goal_selection_events
+			WHERE created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("delete goal selection events", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("check deleted goal selection events", err)
+	}
+
+	return int(deleted), nil
+}
+
+// clientContextJSON encodes a client context for a JSONB column, NULL when empty.
+func clientContextJSON(clientContext map[string]string) (sql.NullString, error) {
+	if len(clientContext) == 0 {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(clientContext)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// nonNil returns ids, or an empty slice for nil, so it is stored as '{}' rather than NULL.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
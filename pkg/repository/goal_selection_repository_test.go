@@ -0,0 +1,168 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySelection(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE user_goal_progress\s+SET is_active = false, assigned_at = NULL, updated_at = NOW\(\)\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\)`).
+		WithArgs("user-1", `{"old-goal"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO user_goal_progress(.|\n)+VALUES \(\$1, \$2, \$3, \$4, 0, 'not_started', true, NOW\(\), NOW\(\), NOW\(\)\),\(\$5, \$6, \$7, \$8,(.|\n)+ON CONFLICT \(user_id, goal_id\) DO UPDATE SET\s+is_active = true`).
+		WithArgs("user-1", "goal-a", "challenge-1", "ns", "user-1", "goal-b", "challenge-1", "ns").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`SELECT COUNT\(\*\)\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND challenge_id = \$2 AND is_active = true`).
+		WithArgs("user-1", "challenge-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO goal_selection_events`).
+		WithArgs("user-1", "ns", "challenge-1", `{"goal-a","goal-b","goal-c"}`, `{"old-goal"}`, "manual", `{"surface":"end_of_match"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	totalActive, err := repo.ApplySelection(context.Background(), &GoalSelection{
+		UserID:          "user-1",
+		Namespace:       "ns",
+		ChallengeID:     "challenge-1",
+		SelectedGoalIDs: []string{"goal-a", "goal-b", "goal-c"},
+		Activate:        []string{"goal-a", "goal-b"},
+		Deactivate:      []string{"old-goal"},
+		Source:          "manual",
+		ClientContext:   map[string]string{"surface": "end_of_match"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, totalActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplySelection_NoChangeOnlyRecordsEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs("user-1", "challenge-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO goal_selection_events`).
+		WithArgs("user-1", "ns", "challenge-1", `{"goal-a"}`, "{}", "random", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	totalActive, err := repo.ApplySelection(context.Background(), &GoalSelection{
+		UserID:          "user-1",
+		Namespace:       "ns",
+		ChallengeID:     "challenge-1",
+		SelectedGoalIDs: []string{"goal-a"},
+		Source:          "random",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, totalActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplySelection_EventErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO user_goal_progress`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO goal_selection_events`).WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	_, err = repo.ApplySelection(context.Background(), &GoalSelection{
+		UserID: "user-1", Namespace: "ns", ChallengeID: "challenge-1",
+		SelectedGoalIDs: []string{"goal-a"}, Activate: []string{"goal-a"}, Source: "manual",
+	})
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSelectionHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	createdAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM goal_selection_events\s+WHERE user_id = \$1 AND namespace = \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3`).
+		WithArgs("user-1", "ns", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "challenge_id", "selected_goal_ids", "replaced_goal_ids", "source", "client_context", "created_at"}).
+			AddRow(2, "user-1", "challenge-1", `{goal-a,goal-b}`, `{old-goal}`, "manual", []byte(`{"surface": "menu"}`), createdAt).
+			AddRow(1, "user-1", "challenge-1", `{old-goal}`, `{}`, "random", nil, createdAt.Add(-time.Hour)))
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	history, err := repo.GetSelectionHistory(context.Background(), "user-1", "ns", 50)
+
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, []string{"goal-a", "goal-b"}, history[0].SelectedGoalIDs)
+	assert.Equal(t, []string{"old-goal"}, history[0].ReplacedGoalIDs)
+	assert.Equal(t, createdAt, history[0].CreatedAt)
+	assert.Equal(t, map[string]string{"surface": "menu"}, history[0].ClientContext)
+	assert.Empty(t, history[1].ReplacedGoalIDs)
+	assert.Nil(t, history[1].ClientContext)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSelectionStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`WHERE namespace = \$1 AND created_at >= \$2 AND \(\$3 = '' OR challenge_id = \$3\)(.|\n)+GROUP BY challenge_id, goal_id`).
+		WithArgs("ns", since, "challenge-1").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "goal_id", "selected", "replaced"}).
+			AddRow("challenge-1", "goal-a", 12, 3).
+			AddRow("challenge-1", "goal-b", 4, 0))
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	stats, err := repo.GetSelectionStats(context.Background(), "ns", "challenge-1", since)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*GoalSelectionCount{
+		{ChallengeID: "challenge-1", GoalID: "goal-a", Selected: 12, Replaced: 3},
+		{ChallengeID: "challenge-1", GoalID: "goal-b", Selected: 4, Replaced: 0},
+	}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSelectionEventsBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	before := time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM goal_selection_events\s+WHERE id IN \((.|\n)+WHERE created_at < \$1\s+ORDER BY created_at\s+LIMIT \$2`).
+		WithArgs(before, 1000).
+		WillReturnResult(sqlmock.NewResult(0, 640))
+
+	repo := NewPostgresGoalSelectionRepository(db)
+	deleted, err := repo.DeleteSelectionEventsBefore(context.Background(), before, 1000)
+
+	require.NoError(t, err)
+	assert.Equal(t, 640, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
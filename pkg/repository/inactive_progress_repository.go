@@ -0,0 +1,139 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/lib/pq"
+)
+
+// InactiveProgressRepository writes progress to goals the user has not activated.
+//
+// The shared BatchUpsertProgressWithCOPY (extend-challenge-common) only updates
+// rows with is_active = true. Challenges that track inactive progress use this
+// repository for the remaining rows, so the goal shows its progress once activated.
+type InactiveProgressRepository interface {
+	// ApplyInactiveProgress updates the existing, inactive, unclaimed rows among rows
+	// and returns the ones it wrote. Rows that are missing, active or claimed are left
+	// alone. Rotation metadata on rows is ignored.
+	ApplyInactiveProgress(ctx context.Context, namespace string, rows []commonRepo.CopyRow) ([]UserGoalKey, error)
+}
+
+// PostgresInactiveProgressRepository implements InactiveProgressRepository on PostgreSQL.
+type PostgresInactiveProgressRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresInactiveProgressRepository creates a new PostgreSQL inactive progress repository.
+func NewPostgresInactiveProgressRepository(db *sql.DB) *PostgresInactiveProgressRepository {
+	return &PostgresInactiveProgressRepository{db: db}
+}
+
+// ApplyInactiveProgress computes progress and status the same way as the non-rotating
+// branches of BatchUpsertProgressWithCOPY: absolute rows take the value, relative rows
+// add the increment and set baseline_value on their first update.
+func (r *PostgresInactiveProgressRepository) ApplyInactiveProgress(
+	ctx context.Context,
+	namespace string,
+	rows []commonRepo.CopyRow,
+) ([]UserGoalKey, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]string, len(rows))
+	goalIDs := make([]string, len(rows))
+	modes := make([]string, len(rows))
+	values := make([]int64, len(rows))
+	targets := make([]int64, len(rows))
+	for i, row := range rows {
+		userIDs[i] = row.UserID
+		goalIDs[i] = row.GoalID
+		modes[i] = row.ProgressMode
+		values[i] = int64(row.IncValue)
+		if row.ProgressMode != string(domain.ProgressModeRelative) && row.Progress != nil {
+			values[i] = int64(*row.Progress)
+		}
+		targets[i] = int64(row.TargetValue)
+	}
+
+	query := `
+		UPDATE user_goal_progress AS ugp
+		SET
+			progress = CASE
+				WHEN data.progress_mode = 'relative' THEN ugp.progress + data.value
+				ELSE data.value
+			END,
+			baseline_value = CASE
+				WHEN data.progress_mode = 'relative' AND ugp.baseline_value IS NULL THEN ugp.progress
+				ELSE ugp.baseline_value
+			END,
+			status = CASE
+				WHEN ugp.status = 'completed' THEN 'completed'
+				WHEN data.progress_mode = 'relative'
+				     AND ugp.progress + data.value - COALESCE(ugp.baseline_value, ugp.progress) >= data.target_value
+					THEN 'completed'
+				WHEN data.progress_mode != 'relative' AND data.value >= data.target_value
+					THEN 'completed'
+				ELSE 'in_progress'
+			END,
+			completed_at = CASE
+				WHEN ugp.status = 'completed' THEN ugp.completed_at
+				WHEN data.progress_mode = 'relative'
+				     AND ugp.progress + data.value - COALESCE(ugp.baseline_value, ugp.progress) >= data.target_value
+					THEN COALESCE(ugp.completed_at, NOW())
+				WHEN data.progress_mode != 'relative' AND data.value >= data.target_value
+					THEN COALESCE(ugp.completed_at, NOW())
+				ELSE ugp.completed_at
+			END,
+			updated_at = NOW()
+		FROM (
+			SELECT UNNEST($2::text[]) AS user_id,
+			       UNNEST($3::text[]) AS goal_id,
+			       UNNEST($4::text[]) AS progress_mode,
+			       UNNEST($5::int[]) AS value,
+			       UNNEST($6::int[]) AS target_value
+		) AS data
+		WHERE ugp.namespace = $1
+		  AND ugp.user_id = data.user_id
+		  AND ugp.goal_id = data.goal_id
+		  AND ugp.is_active = false
+		  AND ugp.status != 'claimed'
+		RETURNING ugp.user_id, ugp.goal_id
+	`
+
+	result, err := r.db.QueryContext(ctx, query,
+		namespace,
+		pq.Array(userIDs),
+		pq.Array(goalIDs),
+		pq.Array(modes),
+		pq.Array(values),
+		pq.Array(targets),
+	)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("apply inactive progress", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	var written []UserGoalKey
+	for result.Next() {
+		var key UserGoalKey
+		if err := result.Scan(&key.UserID, &key.GoalID); err != nil {
+			return nil, errors.ErrDatabaseError("scan inactive progress row", err)
+		}
+		written = append(written, key)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate inactive progress rows", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyInactiveProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	value := 40
+	rows := []commonRepo.CopyRow{
+		{UserID: "user-1", GoalID: "kill-100", ProgressMode: "absolute", Progress: &value, TargetValue: 100},
+		{UserID: "user-2", GoalID: "win-5", ProgressMode: "relative", IncValue: 2, TargetValue: 5},
+	}
+
+	mock.ExpectQuery(`UPDATE user_goal_progress AS ugp(.|\n)+THEN COALESCE\(ugp.completed_at, NOW\(\)\)(.|\n)+WHERE ugp.namespace = \$1(.|\n)+AND ugp.is_active = false\s+AND ugp.status != 'claimed'\s+RETURNING ugp.user_id, ugp.goal_id`).
+		WithArgs("ns", `{"user-1","user-2"}`, `{"kill-100","win-5"}`, `{"absolute","relative"}`, "{40,2}", "{100,5}").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}).AddRow("user-2", "win-5"))
+
+	repo := NewPostgresInactiveProgressRepository(db)
+	written, err := repo.ApplyInactiveProgress(context.Background(), "ns", rows)
+
+	require.NoError(t, err)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-2", GoalID: "win-5"}}, written)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyInactiveProgress_NoRows(t *testing.T) {
+	repo := NewPostgresInactiveProgressRepository(nil)
+	written, err := repo.ApplyInactiveProgress(context.Background(), "ns", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+func TestApplyInactiveProgress_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresInactiveProgressRepository(db)
+	_, err = repo.ApplyInactiveProgress(context.Background(), "ns", []commonRepo.CopyRow{
+		{UserID: "user-1", GoalID: "win-5", ProgressMode: "relative", IncValue: 1, TargetValue: 5},
+	})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSlowQueryThreshold is how long a repository call may take before it is
+// logged as slow.
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// QueryMetrics records the duration and errors of repository calls, by
+// method, and logs calls slower than a threshold. Calls made inside a goal
+// repository transaction are labelled "Tx.<method>", and those of the other
+// repositories "<repository>.<method>" (see NewInstrumentedClaimOutboxRepository).
+type QueryMetrics struct {
+	slowThreshold time.Duration
+
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	slow     *prometheus.CounterVec
+}
+
+// NewQueryMetrics creates repository query metrics. A zero slowThreshold
+// disables the slow-query log.
+func NewQueryMetrics(slowThreshold time.Duration) *QueryMetrics {
+	return &QueryMetrics{
+		slowThreshold: slowThreshold,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_query_duration_seconds",
+			Help:    "Duration of repository calls, by method.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "repository_query_errors_total",
+			Help: "Repository calls that returned an error, by method.",
+		}, []string{"method"}),
+		slow: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "repository_slow_queries_total",
+			Help: "Repository calls slower than the slow-query threshold, by method.",
+		}, []string{"method"}),
+	}
+}
+
+// NewQueryMetricsFromEnv creates repository query metrics logging calls slower
+// than DB_SLOW_QUERY_THRESHOLD (default "500ms", "0" disables the log).
+func NewQueryMetricsFromEnv() *QueryMetrics {
+	threshold := DefaultSlowQueryThreshold
+	if value := common.GetEnv("DB_SLOW_QUERY_THRESHOLD", ""); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			logrus.Warnf("Invalid DB_SLOW_QUERY_THRESHOLD %q, using %s", value, DefaultSlowQueryThreshold)
+		} else {
+			threshold = parsed
+		}
+	}
+
+	return NewQueryMetrics(threshold)
+}
+
+// Collectors returns the repository query metrics for registration.
+func (m *QueryMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.duration, m.errors, m.slow}
+}
+
+// observe records one call. userID and goalID identify the rows in the
+// slow-query log; either may be empty, and rows is the batch size (0 for
+// single-row calls).
+func (m *QueryMetrics) observe(method string, start time.Time, err error, userID, goalID string, rows int) {
+	elapsed := time.Since(start)
+	m.duration.WithLabelValues(method).Observe(elapsed.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(method).Inc()
+	}
+
+	if m.slowThreshold <= 0 || elapsed < m.slowThreshold {
+		return
+	}
+	m.slow.WithLabelValues(method).Inc()
+
+	fields := logrus.Fields{
+		"method":      method,
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if userID != "" {
+		fields["user_id"] = userID
+	}
+	if goalID != "" {
+		fields["goal_id"] = goalID
+	}
+	if rows > 0 {
+		fields["rows"] = rows
+	}
+	if err != nil {
+		fields["error"] = err
+	}
+	logrus.WithFields(fields).Warn("Slow repository query")
+}
+
+// InstrumentedGoalRepository decorates a GoalRepository with QueryMetrics, and
+// counts each query in the query counter of its context, if any (see
+// common.QueryCounter); BeginTx, Commit and Rollback are not counted. The
+// TxRepository returned by BeginTx is instrumented too.
+type InstrumentedGoalRepository struct {
+	next    commonRepo.GoalRepository
+	metrics *QueryMetrics
+	// prefix is prepended to the method labels, "Tx." inside a transaction
+	prefix string
+}
+
+// NewInstrumentedGoalRepository wraps next, recording its calls in metrics.
+func NewInstrumentedGoalRepository(next commonRepo.GoalRepository, metrics *QueryMetrics) *InstrumentedGoalRepository {
+	return &InstrumentedGoalRepository{next: next, metrics: metrics}
+}
+
+// observe records one query, and counts it in the query counter of ctx (see
+// common.QueryCounter).
+func (r *InstrumentedGoalRepository) observe(ctx context.Context, method string, start time.Time, err error, userID, goalID string, rows int) {
+	common.QueryCounterFromContext(ctx).Count(r.prefix + method)
+	r.metrics.observe(r.prefix+method, start, err, userID, goalID, rows)
+}
+
+// firstUserID is the user of a batch; batches hold one user's rows.
+func firstUserID(progresses []*domain.UserGoalProgress) string {
+	if len(progresses) == 0 || progresses[0] == nil {
+		return ""
+	}
+	return progresses[0].UserID
+}
+
+func (r *InstrumentedGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetProgress(ctx, userID, goalID)
+	r.observe(ctx, "GetProgress", start, err, userID, goalID, 0)
+	return progress, err
+}
+
+func (r *InstrumentedGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetUserProgress(ctx, userID, activeOnly)
+	r.observe(ctx, "GetUserProgress", start, err, userID, "", 0)
+	return progress, err
+}
+
+func (r *InstrumentedGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetChallengeProgress(ctx, userID, challengeID, activeOnly)
+	r.observe(ctx, "GetChallengeProgress", start, err, userID, "", 0)
+	return progress, err
+}
+
+func (r *InstrumentedGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.UpsertProgress(ctx, progress)
+	r.observe(ctx, "UpsertProgress", start, err, progress.UserID, progress.GoalID, 0)
+	return err
+}
+
+func (r *InstrumentedGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.BatchUpsertProgress(ctx, updates)
+	r.observe(ctx, "BatchUpsertProgress", start, err, firstUserID(updates), "", len(updates))
+	return err
+}
+
+// BatchUpsertProgressWithCOPY rows span many users, so none is logged.
+func (r *InstrumentedGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
+	start := time.Now()
+	err := r.next.BatchUpsertProgressWithCOPY(ctx, rows)
+	r.observe(ctx, "BatchUpsertProgressWithCOPY", start, err, "", "", len(rows))
+	return err
+}
+
+func (r *InstrumentedGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
+	start := time.Now()
+	err := r.next.MarkAsClaimed(ctx, userID, goalID)
+	r.observe(ctx, "MarkAsClaimed", start, err, userID, goalID, 0)
+	return err
+}
+
+// BeginTx returns an instrumented transaction.
+func (r *InstrumentedGoalRepository) BeginTx(ctx context.Context) (commonRepo.TxRepository, error) {
+	start := time.Now()
+	tx, err := r.next.BeginTx(ctx)
+	r.metrics.observe(r.prefix+"BeginTx", start, err, "", "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTxRepository{
+		InstrumentedGoalRepository: InstrumentedGoalRepository{next: tx, metrics: r.metrics, prefix: "Tx."},
+		tx:                         tx,
+	}, nil
+}
+
+func (r *InstrumentedGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetGoalsByIDs(ctx, userID, goalIDs)
+	r.observe(ctx, "GetGoalsByIDs", start, err, userID, "", len(goalIDs))
+	return progress, err
+}
+
+func (r *InstrumentedGoalRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.BulkInsert(ctx, progresses)
+	r.observe(ctx, "BulkInsert", start, err, firstUserID(progresses), "", len(progresses))
+	return err
+}
+
+func (r *InstrumentedGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.BulkInsertWithCOPY(ctx, progresses)
+	r.observe(ctx, "BulkInsertWithCOPY", start, err, firstUserID(progresses), "", len(progresses))
+	return err
+}
+
+func (r *InstrumentedGoalRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.UpsertGoalActive(ctx, progress)
+	r.observe(ctx, "UpsertGoalActive", start, err, progress.UserID, progress.GoalID, 0)
+	return err
+}
+
+func (r *InstrumentedGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	start := time.Now()
+	err := r.next.BatchUpsertGoalActive(ctx, progresses)
+	r.observe(ctx, "BatchUpsertGoalActive", start, err, firstUserID(progresses), "", len(progresses))
+	return err
+}
+
+func (r *InstrumentedGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
+	start := time.Now()
+	count, err := r.next.GetUserGoalCount(ctx, userID)
+	r.observe(ctx, "GetUserGoalCount", start, err, userID, "", 0)
+	return count, err
+}
+
+func (r *InstrumentedGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetActiveGoals(ctx, userID)
+	r.observe(ctx, "GetActiveGoals", start, err, userID, "", 0)
+	return progress, err
+}
+
+// instrumentedTxRepository decorates a TxRepository; its calls are labelled
+// "Tx.<method>".
+type instrumentedTxRepository struct {
+	InstrumentedGoalRepository
+	tx commonRepo.TxRepository
+}
+
+func (r *instrumentedTxRepository) GetProgressForUpdate(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.tx.GetProgressForUpdate(ctx, userID, goalID)
+	r.observe(ctx, "GetProgressForUpdate", start, err, userID, goalID, 0)
+	return progress, err
+}
+
+func (r *instrumentedTxRepository) Commit() error {
+	start := time.Now()
+	err := r.tx.Commit()
+	r.metrics.observe(r.prefix+"Commit", start, err, "", "", 0)
+	return err
+}
+
+// Rollback is not counted as an error when the transaction was already
+// committed; callers defer it unconditionally.
+func (r *instrumentedTxRepository) Rollback() error {
+	start := time.Now()
+	err := r.tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		r.metrics.observe(r.prefix+"Rollback", start, nil, "", "", 0)
+		return err
+	}
+	r.metrics.observe(r.prefix+"Rollback", start, err, "", "", 0)
+	return err
+}
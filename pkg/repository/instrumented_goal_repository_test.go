@@ -0,0 +1,209 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var instrumentedProgressColumns = []string{
+	"user_id", "goal_id", "challenge_id", "namespace", "progress", "status",
+	"completed_at", "claimed_at", "created_at", "updated_at",
+	"is_active", "assigned_at", "expires_at", "baseline_value",
+}
+
+func progressRow() *sqlmock.Rows {
+	now := time.Now().UTC()
+	return sqlmock.NewRows(instrumentedProgressColumns).
+		AddRow("user-1", "goal-1", "challenge-1", "ns", 3, "in_progress", nil, nil, now, now, true, now, nil, nil)
+}
+
+func gatherFamilies(t *testing.T, metrics *QueryMetrics) []*dto.MetricFamily {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.Collectors()...)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	return families
+}
+
+func histogramCount(t *testing.T, metrics *QueryMetrics, method string) int {
+	t.Helper()
+	count := 0
+	for _, family := range gatherFamilies(t, metrics) {
+		if family.GetName() != "repository_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == method {
+				count += int(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return count
+}
+
+func TestInstrumentedGoalRepository_RecordsDurationByMethod(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+
+	metrics := NewQueryMetrics(0)
+	repo := NewInstrumentedGoalRepository(commonRepo.NewPostgresGoalRepository(db), metrics)
+
+	for range 2 {
+		progress, err := repo.GetProgress(context.Background(), "user-1", "goal-1")
+		require.NoError(t, err)
+		assert.Equal(t, "goal-1", progress.GoalID)
+	}
+
+	assert.Equal(t, 2, histogramCount(t, metrics, "GetProgress"))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.errors))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInstrumentedGoalRepository_CountsErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	metrics := NewQueryMetrics(0)
+	repo := NewInstrumentedGoalRepository(commonRepo.NewPostgresGoalRepository(db), metrics)
+
+	_, err = repo.GetUserProgress(context.Background(), "user-1", false)
+
+	require.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.errors.WithLabelValues("GetUserProgress")))
+	assert.Equal(t, 1, histogramCount(t, metrics, "GetUserProgress"))
+}
+
+func TestInstrumentedGoalRepository_InstrumentsTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress .+ FOR UPDATE`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	metrics := NewQueryMetrics(0)
+	repo := NewInstrumentedGoalRepository(commonRepo.NewPostgresGoalRepository(db), metrics)
+
+	tx, err := repo.BeginTx(context.Background())
+	require.NoError(t, err)
+	_, err = tx.GetProgressForUpdate(context.Background(), "user-1", "goal-1")
+	require.NoError(t, err)
+	require.NoError(t, tx.MarkAsClaimed(context.Background(), "user-1", "goal-1"))
+	require.NoError(t, tx.Commit())
+	// The deferred rollback after a commit is not an error
+	assert.Error(t, tx.Rollback())
+
+	assert.Equal(t, 1, histogramCount(t, metrics, "BeginTx"))
+	assert.Equal(t, 1, histogramCount(t, metrics, "Tx.GetProgressForUpdate"))
+	assert.Equal(t, 1, histogramCount(t, metrics, "Tx.MarkAsClaimed"))
+	assert.Equal(t, 1, histogramCount(t, metrics, "Tx.Commit"))
+	assert.Equal(t, 1, histogramCount(t, metrics, "Tx.Rollback"))
+	assert.Equal(t, 0, histogramCount(t, metrics, "MarkAsClaimed"))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.errors))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInstrumentedGoalRepository_CountsQueriesInContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs("user-1", "goal-1").WillReturnRows(progressRow())
+
+	repo := NewInstrumentedGoalRepository(commonRepo.NewPostgresGoalRepository(db), NewQueryMetrics(0))
+	counter := common.NewQueryCounter()
+	ctx := common.WithQueryCounter(context.Background(), counter)
+
+	_, err = repo.GetProgress(ctx, "user-1", "goal-1")
+	require.NoError(t, err)
+	tx, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.MarkAsClaimed(ctx, "user-1", "goal-1"))
+	require.NoError(t, tx.Commit())
+	_ = tx.Rollback()
+	// Calls without a counter are not counted
+	_, err = repo.GetProgress(context.Background(), "user-1", "goal-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"GetProgress": 1, "Tx.MarkAsClaimed": 1}, counter.Counts(),
+		"transaction control is not counted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInstrumentedGoalRepository_SlowQueryLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).
+		WillDelayFor(5 * time.Millisecond).
+		WillReturnRows(progressRow())
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WillReturnRows(progressRow())
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	metrics := NewQueryMetrics(time.Millisecond)
+	repo := NewInstrumentedGoalRepository(commonRepo.NewPostgresGoalRepository(db), metrics)
+
+	_, err = repo.GetProgress(context.Background(), "user-1", "goal-1")
+	require.NoError(t, err)
+	_, err = repo.GetGoalsByIDs(context.Background(), "user-2", []string{"goal-1", "goal-2"})
+	require.NoError(t, err)
+
+	var slow []*logrus.Entry
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Slow repository query" {
+			slow = append(slow, entry)
+		}
+	}
+	require.NotEmpty(t, slow)
+	assert.Equal(t, "GetProgress", slow[0].Data["method"])
+	assert.Equal(t, "user-1", slow[0].Data["user_id"])
+	assert.Equal(t, "goal-1", slow[0].Data["goal_id"])
+	assert.Equal(t, logrus.WarnLevel, slow[0].Level)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.slow.WithLabelValues("GetProgress")))
+}
+
+func TestNewQueryMetricsFromEnv(t *testing.T) {
+	t.Setenv("DB_SLOW_QUERY_THRESHOLD", "250ms")
+	assert.Equal(t, 250*time.Millisecond, NewQueryMetricsFromEnv().slowThreshold)
+
+	t.Setenv("DB_SLOW_QUERY_THRESHOLD", "0")
+	assert.Zero(t, NewQueryMetricsFromEnv().slowThreshold)
+
+	t.Setenv("DB_SLOW_QUERY_THRESHOLD", "soon")
+	assert.Equal(t, DefaultSlowQueryThreshold, NewQueryMetricsFromEnv().slowThreshold)
+}
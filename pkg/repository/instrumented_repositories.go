@@ -0,0 +1,453 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// The decorators below record the calls of the service's other repositories in
+// QueryMetrics and count them in the query counter of their context, like
+// InstrumentedGoalRepository does for the goal repository, so the query count
+// of a request covers every repository it reads and writes. Their calls are
+// labelled "<repository>.<method>", e.g. "ClaimOutbox.Reserve".
+
+// instrumented records the calls of one repository under its name.
+type instrumented struct {
+	name    string
+	metrics *QueryMetrics
+}
+
+// observe records one query, and counts it in the query counter of ctx (see
+// common.QueryCounter).
+func (i instrumented) observe(ctx context.Context, method string, start time.Time, err error, userID, goalID string, rows int) {
+	label := i.name + "." + method
+	common.QueryCounterFromContext(ctx).Count(label)
+	i.metrics.observe(label, start, err, userID, goalID, rows)
+}
+
+// NewInstrumentedProgressQueryRepository wraps next, recording its calls in
+// metrics as "ProgressQuery.<method>".
+func NewInstrumentedProgressQueryRepository(next ProgressQueryRepository, metrics *QueryMetrics) ProgressQueryRepository {
+	return &instrumentedProgressQueries{next: next, instrumented: instrumented{"ProgressQuery", metrics}}
+}
+
+type instrumentedProgressQueries struct {
+	instrumented
+	next ProgressQueryRepository
+}
+
+func (r *instrumentedProgressQueries) GetUserProgressPage(ctx context.Context, userID string, activeOnly bool, afterGoalID string, limit int) ([]*domain.UserGoalProgress, error) {
+	start := time.Now()
+	progress, err := r.next.GetUserProgressPage(ctx, userID, activeOnly, afterGoalID, limit)
+	r.observe(ctx, "GetUserProgressPage", start, err, userID, "", limit)
+	return progress, err
+}
+
+func (r *instrumentedProgressQueries) GetUserProgressSnapshot(ctx context.Context, userID, challengeID string, activeOnly bool) (*ProgressSnapshot, error) {
+	start := time.Now()
+	snapshot, err := r.next.GetUserProgressSnapshot(ctx, userID, challengeID, activeOnly)
+	r.observe(ctx, "GetUserProgressSnapshot", start, err, userID, "", 0)
+	return snapshot, err
+}
+
+func (r *instrumentedProgressQueries) GetUserProgressSummary(ctx context.Context, userID, namespace string, activeOnly bool) ([]*ProgressStatusCount, error) {
+	start := time.Now()
+	counts, err := r.next.GetUserProgressSummary(ctx, userID, namespace, activeOnly)
+	r.observe(ctx, "GetUserProgressSummary", start, err, userID, "", 0)
+	return counts, err
+}
+
+func (r *instrumentedProgressQueries) CountByStatus(ctx context.Context, userID, namespace string, statuses []domain.GoalStatus) (map[domain.GoalStatus]int, error) {
+	start := time.Now()
+	counts, err := r.next.CountByStatus(ctx, userID, namespace, statuses)
+	r.observe(ctx, "CountByStatus", start, err, userID, "", 0)
+	return counts, err
+}
+
+func (r *instrumentedProgressQueries) CountUnclaimedCompleted(ctx context.Context, namespace string, goalIDs []string) (map[string]int, error) {
+	start := time.Now()
+	counts, err := r.next.CountUnclaimedCompleted(ctx, namespace, goalIDs)
+	r.observe(ctx, "CountUnclaimedCompleted", start, err, "", "", len(goalIDs))
+	return counts, err
+}
+
+func (r *instrumentedProgressQueries) GetActiveGoalStatuses(ctx context.Context, namespace string, userIDs, goalIDs []string) (map[UserGoalKey]domain.GoalStatus, error) {
+	start := time.Now()
+	statuses, err := r.next.GetActiveGoalStatuses(ctx, namespace, userIDs, goalIDs)
+	r.observe(ctx, "GetActiveGoalStatuses", start, err, "", "", len(userIDs)*len(goalIDs))
+	return statuses, err
+}
+
+func (r *instrumentedProgressQueries) GetActiveGoalIDsForUser(ctx context.Context, userID string, goalIDs []string) (map[string]bool, error) {
+	start := time.Now()
+	active, err := r.next.GetActiveGoalIDsForUser(ctx, userID, goalIDs)
+	r.observe(ctx, "GetActiveGoalIDsForUser", start, err, userID, "", len(goalIDs))
+	return active, err
+}
+
+func (r *instrumentedProgressQueries) GetFirstCompletions(ctx context.Context, userID string, goalIDs []string) (map[string]time.Time, error) {
+	start := time.Now()
+	completions, err := r.next.GetFirstCompletions(ctx, userID, goalIDs)
+	r.observe(ctx, "GetFirstCompletions", start, err, userID, "", len(goalIDs))
+	return completions, err
+}
+
+func (r *instrumentedProgressQueries) GetGoalCompletionStats(ctx context.Context, namespace string) ([]*GoalCompletionStats, error) {
+	start := time.Now()
+	stats, err := r.next.GetGoalCompletionStats(ctx, namespace)
+	r.observe(ctx, "GetGoalCompletionStats", start, err, "", "", 0)
+	return stats, err
+}
+
+func (r *instrumentedProgressQueries) GetChallengeMismatches(ctx context.Context, namespace string, goalChallenges map[string]string, limit int) ([]*ChallengeMismatch, error) {
+	start := time.Now()
+	mismatches, err := r.next.GetChallengeMismatches(ctx, namespace, goalChallenges, limit)
+	r.observe(ctx, "GetChallengeMismatches", start, err, "", "", limit)
+	return mismatches, err
+}
+
+func (r *instrumentedProgressQueries) GetUsersReachingTargets(ctx context.Context, namespace string, goalTargets map[string]int, limit int) ([]string, error) {
+	start := time.Now()
+	userIDs, err := r.next.GetUsersReachingTargets(ctx, namespace, goalTargets, limit)
+	r.observe(ctx, "GetUsersReachingTargets", start, err, "", "", limit)
+	return userIDs, err
+}
+
+// NewInstrumentedActivationSourceRepository wraps next, recording its calls in
+// metrics as "ActivationSource.<method>".
+func NewInstrumentedActivationSourceRepository(next ActivationSourceRepository, metrics *QueryMetrics) ActivationSourceRepository {
+	return &instrumentedActivationSources{next: next, instrumented: instrumented{"ActivationSource", metrics}}
+}
+
+type instrumentedActivationSources struct {
+	instrumented
+	next ActivationSourceRepository
+}
+
+func (r *instrumentedActivationSources) SetActivationSource(ctx context.Context, userID string, goalIDs []string, source string) error {
+	start := time.Now()
+	err := r.next.SetActivationSource(ctx, userID, goalIDs, source)
+	r.observe(ctx, "SetActivationSource", start, err, userID, "", len(goalIDs))
+	return err
+}
+
+func (r *instrumentedActivationSources) GetActivationSources(ctx context.Context, userID string, goalIDs []string) (map[string]string, error) {
+	start := time.Now()
+	sources, err := r.next.GetActivationSources(ctx, userID, goalIDs)
+	r.observe(ctx, "GetActivationSources", start, err, userID, "", len(goalIDs))
+	return sources, err
+}
+
+// NewInstrumentedStepProgressRepository wraps next, recording its calls in
+// metrics as "StepProgress.<method>".
+func NewInstrumentedStepProgressRepository(next StepProgressRepository, metrics *QueryMetrics) StepProgressRepository {
+	return &instrumentedStepProgress{next: next, instrumented: instrumented{"StepProgress", metrics}}
+}
+
+type instrumentedStepProgress struct {
+	instrumented
+	next StepProgressRepository
+}
+
+// ApplyStepProgress keys span many users, so none is logged.
+func (r *instrumentedStepProgress) ApplyStepProgress(
+	ctx context.Context,
+	namespace string,
+	keys []UserGoalKey,
+	apply func(row *StepProgressRow) bool,
+) ([]UserGoalKey, error) {
+	start := time.Now()
+	applied, err := r.next.ApplyStepProgress(ctx, namespace, keys, apply)
+	r.observe(ctx, "ApplyStepProgress", start, err, "", "", len(keys))
+	return applied, err
+}
+
+func (r *instrumentedStepProgress) GetStepProgress(ctx context.Context, userID string, goalIDs []string) (map[string][]int, error) {
+	start := time.Now()
+	steps, err := r.next.GetStepProgress(ctx, userID, goalIDs)
+	r.observe(ctx, "GetStepProgress", start, err, userID, "", len(goalIDs))
+	return steps, err
+}
+
+// NewInstrumentedProgressInsertRepository wraps next, recording its calls in
+// metrics as "ProgressInsert.<method>".
+func NewInstrumentedProgressInsertRepository(next ProgressInsertRepository, metrics *QueryMetrics) ProgressInsertRepository {
+	return &instrumentedProgressInserts{next: next, instrumented: instrumented{"ProgressInsert", metrics}}
+}
+
+type instrumentedProgressInserts struct {
+	instrumented
+	next ProgressInsertRepository
+}
+
+func (r *instrumentedProgressInserts) BulkInsertReturningConflicts(ctx context.Context, progresses []*domain.UserGoalProgress) (*BulkInsertResult, error) {
+	start := time.Now()
+	result, err := r.next.BulkInsertReturningConflicts(ctx, progresses)
+	r.observe(ctx, "BulkInsertReturningConflicts", start, err, firstUserID(progresses), "", len(progresses))
+	return result, err
+}
+
+// NewInstrumentedClaimOutboxRepository wraps next, recording its calls in
+// metrics as "ClaimOutbox.<method>".
+func NewInstrumentedClaimOutboxRepository(next ClaimOutboxRepository, metrics *QueryMetrics) ClaimOutboxRepository {
+	return &instrumentedClaimOutbox{next: next, instrumented: instrumented{"ClaimOutbox", metrics}}
+}
+
+type instrumentedClaimOutbox struct {
+	instrumented
+	next ClaimOutboxRepository
+}
+
+func (r *instrumentedClaimOutbox) Reserve(ctx context.Context, entry *ClaimOutboxEntry) (bool, error) {
+	start := time.Now()
+	reserved, err := r.next.Reserve(ctx, entry)
+	r.observe(ctx, "Reserve", start, err, entry.UserID, entry.GoalID, 0)
+	return reserved, err
+}
+
+func (r *instrumentedClaimOutbox) Get(ctx context.Context, userID, goalID string) (*ClaimOutboxEntry, error) {
+	start := time.Now()
+	entry, err := r.next.Get(ctx, userID, goalID)
+	r.observe(ctx, "Get", start, err, userID, goalID, 0)
+	return entry, err
+}
+
+func (r *instrumentedClaimOutbox) MarkGranted(ctx context.Context, userID, goalID string) error {
+	start := time.Now()
+	err := r.next.MarkGranted(ctx, userID, goalID)
+	r.observe(ctx, "MarkGranted", start, err, userID, goalID, 0)
+	return err
+}
+
+func (r *instrumentedClaimOutbox) Delete(ctx context.Context, userID, goalID string) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, userID, goalID)
+	r.observe(ctx, "Delete", start, err, userID, goalID, 0)
+	return err
+}
+
+func (r *instrumentedClaimOutbox) ListStale(ctx context.Context, before time.Time, limit int) ([]*ClaimOutboxEntry, error) {
+	start := time.Now()
+	entries, err := r.next.ListStale(ctx, before, limit)
+	r.observe(ctx, "ListStale", start, err, "", "", limit)
+	return entries, err
+}
+
+func (r *instrumentedClaimOutbox) DeleteIfPending(ctx context.Context, userID, goalID string, before time.Time) (bool, error) {
+	start := time.Now()
+	deleted, err := r.next.DeleteIfPending(ctx, userID, goalID, before)
+	r.observe(ctx, "DeleteIfPending", start, err, userID, goalID, 0)
+	return deleted, err
+}
+
+// NewInstrumentedRewardCapRepository wraps next, recording its calls in
+// metrics as "RewardCap.<method>".
+func NewInstrumentedRewardCapRepository(next RewardCapRepository, metrics *QueryMetrics) RewardCapRepository {
+	return &instrumentedRewardCaps{next: next, instrumented: instrumented{"RewardCap", metrics}}
+}
+
+type instrumentedRewardCaps struct {
+	instrumented
+	next RewardCapRepository
+}
+
+func (r *instrumentedRewardCaps) TakeSlot(ctx context.Context, userID, goalID string, limit int) (bool, int, error) {
+	start := time.Now()
+	taken, granted, err := r.next.TakeSlot(ctx, userID, goalID, limit)
+	r.observe(ctx, "TakeSlot", start, err, userID, goalID, 0)
+	return taken, granted, err
+}
+
+func (r *instrumentedRewardCaps) ReleaseSlot(ctx context.Context, userID, goalID string) error {
+	start := time.Now()
+	err := r.next.ReleaseSlot(ctx, userID, goalID)
+	r.observe(ctx, "ReleaseSlot", start, err, userID, goalID, 0)
+	return err
+}
+
+// NewInstrumentedClaimCounterRepository wraps next, recording its calls in
+// metrics as "ClaimCounter.<method>".
+func NewInstrumentedClaimCounterRepository(next ClaimCounterRepository, metrics *QueryMetrics) ClaimCounterRepository {
+	return &instrumentedClaimCounters{next: next, instrumented: instrumented{"ClaimCounter", metrics}}
+}
+
+type instrumentedClaimCounters struct {
+	instrumented
+	next ClaimCounterRepository
+}
+
+func (r *instrumentedClaimCounters) GetClaimWindow(ctx context.Context, userID, namespace string, since time.Time) (*ClaimWindow, error) {
+	start := time.Now()
+	window, err := r.next.GetClaimWindow(ctx, userID, namespace, since)
+	r.observe(ctx, "GetClaimWindow", start, err, userID, "", 0)
+	return window, err
+}
+
+func (r *instrumentedClaimCounters) RecordClaim(ctx context.Context, userID, namespace, goalID string, claimedAt, pruneBefore time.Time) error {
+	start := time.Now()
+	err := r.next.RecordClaim(ctx, userID, namespace, goalID, claimedAt, pruneBefore)
+	r.observe(ctx, "RecordClaim", start, err, userID, goalID, 0)
+	return err
+}
+
+func (r *instrumentedClaimCounters) ResetClaims(ctx context.Context, userID, namespace string) (int, error) {
+	start := time.Now()
+	reset, err := r.next.ResetClaims(ctx, userID, namespace)
+	r.observe(ctx, "ResetClaims", start, err, userID, "", 0)
+	return reset, err
+}
+
+// NewInstrumentedClaimFreezeRepository wraps next, recording its calls in
+// metrics as "ClaimFreeze.<method>".
+func NewInstrumentedClaimFreezeRepository(next ClaimFreezeRepository, metrics *QueryMetrics) ClaimFreezeRepository {
+	return &instrumentedClaimFreezes{next: next, instrumented: instrumented{"ClaimFreeze", metrics}}
+}
+
+type instrumentedClaimFreezes struct {
+	instrumented
+	next ClaimFreezeRepository
+}
+
+func (r *instrumentedClaimFreezes) GetClaimFreeze(ctx context.Context, userID, namespace string, now time.Time) (*ClaimFreeze, error) {
+	start := time.Now()
+	freeze, err := r.next.GetClaimFreeze(ctx, userID, namespace, now)
+	r.observe(ctx, "GetClaimFreeze", start, err, userID, "", 0)
+	return freeze, err
+}
+
+func (r *instrumentedClaimFreezes) ListClaimFreezes(ctx context.Context, namespace string, now time.Time, limit int) ([]*ClaimFreeze, error) {
+	start := time.Now()
+	freezes, err := r.next.ListClaimFreezes(ctx, namespace, now, limit)
+	r.observe(ctx, "ListClaimFreezes", start, err, "", "", limit)
+	return freezes, err
+}
+
+func (r *instrumentedClaimFreezes) SetClaimFreeze(ctx context.Context, freeze *ClaimFreeze, clientIP string) error {
+	start := time.Now()
+	err := r.next.SetClaimFreeze(ctx, freeze, clientIP)
+	r.observe(ctx, "SetClaimFreeze", start, err, freeze.UserID, "", 0)
+	return err
+}
+
+func (r *instrumentedClaimFreezes) RemoveClaimFreeze(ctx context.Context, userID, namespace, actorUserID, reason, clientIP string, now time.Time) (*ClaimFreeze, error) {
+	start := time.Now()
+	freeze, err := r.next.RemoveClaimFreeze(ctx, userID, namespace, actorUserID, reason, clientIP, now)
+	r.observe(ctx, "RemoveClaimFreeze", start, err, userID, "", 0)
+	return freeze, err
+}
+
+// NewInstrumentedRepeatableGoalRepository wraps next, recording its calls in
+// metrics as "RepeatableGoal.<method>".
+func NewInstrumentedRepeatableGoalRepository(next RepeatableGoalRepository, metrics *QueryMetrics) RepeatableGoalRepository {
+	return &instrumentedRepeatableGoals{next: next, instrumented: instrumented{"RepeatableGoal", metrics}}
+}
+
+type instrumentedRepeatableGoals struct {
+	instrumented
+	next RepeatableGoalRepository
+}
+
+func (r *instrumentedRepeatableGoals) ResetClaimed(ctx context.Context, userID, goalID string) (*RepeatState, error) {
+	start := time.Now()
+	state, err := r.next.ResetClaimed(ctx, userID, goalID)
+	r.observe(ctx, "ResetClaimed", start, err, userID, goalID, 0)
+	return state, err
+}
+
+func (r *instrumentedRepeatableGoals) GetRepeatStates(ctx context.Context, userID string, goalIDs []string) (map[string]RepeatState, error) {
+	start := time.Now()
+	states, err := r.next.GetRepeatStates(ctx, userID, goalIDs)
+	r.observe(ctx, "GetRepeatStates", start, err, userID, "", len(goalIDs))
+	return states, err
+}
+
+// NewInstrumentedRewardGrantRepository wraps next, recording its calls in
+// metrics as "RewardGrant.<method>".
+func NewInstrumentedRewardGrantRepository(next RewardGrantRepository, metrics *QueryMetrics) RewardGrantRepository {
+	return &instrumentedRewardGrants{next: next, instrumented: instrumented{"RewardGrant", metrics}}
+}
+
+type instrumentedRewardGrants struct {
+	instrumented
+	next RewardGrantRepository
+}
+
+func (r *instrumentedRewardGrants) RecordGrant(ctx context.Context, userID, goalID, entitlementID, walletID string) error {
+	start := time.Now()
+	err := r.next.RecordGrant(ctx, userID, goalID, entitlementID, walletID)
+	r.observe(ctx, "RecordGrant", start, err, userID, goalID, 0)
+	return err
+}
+
+// NewInstrumentedFailedGrantRepository wraps next, recording its calls in
+// metrics as "FailedGrant.<method>".
+func NewInstrumentedFailedGrantRepository(next FailedGrantRepository, metrics *QueryMetrics) FailedGrantRepository {
+	return &instrumentedFailedGrants{next: next, instrumented: instrumented{"FailedGrant", metrics}}
+}
+
+type instrumentedFailedGrants struct {
+	instrumented
+	next FailedGrantRepository
+}
+
+func (r *instrumentedFailedGrants) RecordFailedGrant(ctx context.Context, grant *FailedGrant) error {
+	start := time.Now()
+	err := r.next.RecordFailedGrant(ctx, grant)
+	r.observe(ctx, "RecordFailedGrant", start, err, grant.UserID, grant.GoalID, 0)
+	return err
+}
+
+func (r *instrumentedFailedGrants) GetFailedGrant(ctx context.Context, namespace string, id int64) (*FailedGrant, error) {
+	start := time.Now()
+	grant, err := r.next.GetFailedGrant(ctx, namespace, id)
+	r.observe(ctx, "GetFailedGrant", start, err, "", "", 0)
+	return grant, err
+}
+
+func (r *instrumentedFailedGrants) ListFailedGrants(ctx context.Context, namespace string, limit int) ([]*FailedGrant, error) {
+	start := time.Now()
+	grants, err := r.next.ListFailedGrants(ctx, namespace, limit)
+	r.observe(ctx, "ListFailedGrants", start, err, "", "", limit)
+	return grants, err
+}
+
+func (r *instrumentedFailedGrants) DeleteFailedGrant(ctx context.Context, userID, goalID string) error {
+	start := time.Now()
+	err := r.next.DeleteFailedGrant(ctx, userID, goalID)
+	r.observe(ctx, "DeleteFailedGrant", start, err, userID, goalID, 0)
+	return err
+}
+
+// NewInstrumentedEventOutboxRepository wraps next, recording its calls in
+// metrics as "EventOutbox.<method>".
+func NewInstrumentedEventOutboxRepository(next EventOutboxRepository, metrics *QueryMetrics) EventOutboxRepository {
+	return &instrumentedEventOutbox{next: next, instrumented: instrumented{"EventOutbox", metrics}}
+}
+
+type instrumentedEventOutbox struct {
+	instrumented
+	next EventOutboxRepository
+}
+
+func (r *instrumentedEventOutbox) Enqueue(ctx context.Context, event *OutboxEvent) error {
+	start := time.Now()
+	err := r.next.Enqueue(ctx, event)
+	r.observe(ctx, "Enqueue", start, err, event.UserID, "", 0)
+	return err
+}
+
+// PublishPending is timed with its publish callback, the whole transaction.
+func (r *instrumentedEventOutbox) PublishPending(ctx context.Context, limit int, publish func(events []*OutboxEvent) error) (int, error) {
+	start := time.Now()
+	published, err := r.next.PublishPending(ctx, limit, publish)
+	r.observe(ctx, "PublishPending", start, err, "", "", published)
+	return published, err
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedClaimCounterRepository_CountsQueries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	mock.ExpectQuery(`FROM user_claim_events`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil))
+	mock.ExpectQuery(`FROM user_claim_events`).WillReturnError(errors.New("connection reset"))
+
+	metrics := NewQueryMetrics(0)
+	repo := NewInstrumentedClaimCounterRepository(NewPostgresClaimCounterRepository(db), metrics)
+	counter := common.NewQueryCounter()
+	ctx := common.WithQueryCounter(context.Background(), counter)
+
+	window, err := repo.GetClaimWindow(ctx, "user-1", "ns", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, window.Count)
+	_, err = repo.GetClaimWindow(ctx, "user-1", "ns", time.Now())
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, counter.Total())
+	assert.Equal(t, "ClaimCounter.GetClaimWindow=2", counter.String())
+	assert.Equal(t, 2, histogramCount(t, metrics, "ClaimCounter.GetClaimWindow"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/lib/pq"
+)
+
+// LateProgressRepository writes progress that occurred in an earlier period of a
+// rotating goal to that period.
+//
+// A row's period is the one its updated_at falls in: BatchUpsertProgressWithCOPY
+// (extend-challenge-common) resets progress on the first write after a rotation
+// boundary by comparing updated_at with it. Late writes therefore leave updated_at
+// alone, so the next write in the current period still resets the row.
+type LateProgressRepository interface {
+	// ApplyLateProgress updates the existing, active, unclaimed rows among rows
+	// whose updated_at is in [RotationBoundary, NewExpiresAt), i.e. that are still
+	// in the period the late events occurred in, and returns the ones it wrote.
+	ApplyLateProgress(ctx context.Context, namespace string, rows []commonRepo.CopyRow) ([]UserGoalKey, error)
+}
+
+// PostgresLateProgressRepository implements LateProgressRepository on PostgreSQL.
+type PostgresLateProgressRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresLateProgressRepository creates a new PostgreSQL late progress repository.
+func NewPostgresLateProgressRepository(db *sql.DB) *PostgresLateProgressRepository {
+	return &PostgresLateProgressRepository{db: db}
+}
+
+// ApplyLateProgress computes progress and status the same way as
+// ApplyInactiveProgress: absolute rows take the value, relative rows add the
+// increment and set baseline_value on their first update.
+func (r *PostgresLateProgressRepository) ApplyLateProgress(
+	ctx context.Context,
+	namespace string,
+	rows []commonRepo.CopyRow,
+) ([]UserGoalKey, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]string, len(rows))
+	goalIDs := make([]string, len(rows))
+	modes := make([]string, len(rows))
+	values := make([]int64, len(rows))
+	targets := make([]int64, len(rows))
+	periodStarts := make([]string, len(rows))
+	periodEnds := make([]string, len(rows))
+	for i, row := range rows {
+		userIDs[i] = row.UserID
+		goalIDs[i] = row.GoalID
+		modes[i] = row.ProgressMode
+		values[i] = int64(row.IncValue)
+		if row.ProgressMode != string(domain.ProgressModeRelative) && row.Progress != nil {
+			values[i] = int64(*row.Progress)
+		}
+		targets[i] = int64(row.TargetValue)
+		if row.RotationBoundary != nil && row.NewExpiresAt != nil {
+			periodStarts[i] = row.RotationBoundary.UTC().Format(time.RFC3339Nano)
+			periodEnds[i] = row.NewExpiresAt.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	// Rows without a period have empty bounds, which match nothing
+	query := `
+		UPDATE user_goal_progress AS ugp
+		SET
+			progress = CASE
+				WHEN data.progress_mode = 'relative' THEN ugp.progress + data.value
+				ELSE data.value
+			END,
+			baseline_value = CASE
+				WHEN data.progress_mode = 'relative' AND ugp.baseline_value IS NULL THEN ugp.progress
+				ELSE ugp.baseline_value
+			END,
+			status = CASE
+				WHEN ugp.status = 'completed' THEN 'completed'
+				WHEN data.progress_mode = 'relative'
+				     AND ugp.progress + data.value - COALESCE(ugp.baseline_value, ugp.progress) >= data.target_value
+					THEN 'completed'
+				WHEN data.progress_mode != 'relative' AND data.value >= data.target_value
+					THEN 'completed'
+				ELSE 'in_progress'
+			END,
+			completed_at = CASE
+				WHEN ugp.status = 'completed' THEN ugp.completed_at
+				WHEN data.progress_mode = 'relative'
+				     AND ugp.progress + data.value - COALESCE(ugp.baseline_value, ugp.progress) >= data.target_value
+					THEN COALESCE(ugp.completed_at, NOW())
+				WHEN data.progress_mode != 'relative' AND data.value >= data.target_value
+					THEN COALESCE(ugp.completed_at, NOW())
+				ELSE ugp.completed_at
+			END
+		FROM (
+			SELECT UNNEST($2::text[]) AS user_id,
+			       UNNEST($3::text[]) AS goal_id,
+			       UNNEST($4::text[]) AS progress_mode,
+			       UNNEST($5::int[]) AS value,
+			       UNNEST($6::int[]) AS target_value,
+			       NULLIF(UNNEST($7::text[]), '')::timestamptz AS period_start,
+			       NULLIF(UNNEST($8::text[]), '')::timestamptz AS period_end
+		) AS data
+		WHERE ugp.namespace = $1
+		  AND ugp.user_id = data.user_id
+		  AND ugp.goal_id = data.goal_id
+		  AND ugp.is_active = true
+		  AND ugp.status != 'claimed'
+		  AND ugp.updated_at >= data.period_start
+		  AND ugp.updated_at < data.period_end
+		RETURNING ugp.user_id, ugp.goal_id
+	`
+
+	result, err := r.db.QueryContext(ctx, query,
+		namespace,
+		pq.Array(userIDs),
+		pq.Array(goalIDs),
+		pq.Array(modes),
+		pq.Array(values),
+		pq.Array(targets),
+		pq.Array(periodStarts),
+		pq.Array(periodEnds),
+	)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("apply late progress", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	var written []UserGoalKey
+	for result.Next() {
+		var key UserGoalKey
+		if err := result.Scan(&key.UserID, &key.GoalID); err != nil {
+			return nil, errors.ErrDatabaseError("scan late progress row", err)
+		}
+		written = append(written, key)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate late progress rows", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLateProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	start := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	rows := []commonRepo.CopyRow{
+		{UserID: "user-1", GoalID: "daily-wins", ProgressMode: "relative", IncValue: 2, TargetValue: 3, RotationBoundary: &start, NewExpiresAt: &end},
+		{UserID: "user-2", GoalID: "daily-wins", ProgressMode: "relative", IncValue: 1, TargetValue: 3},
+	}
+
+	// updated_at is not set, so the row still rotates on its next current-period write
+	mock.ExpectQuery(`UPDATE user_goal_progress AS ugp(.|\n)+THEN COALESCE\(ugp.completed_at, NOW\(\)\)(.|\n)+ELSE ugp.completed_at\s+END\s+FROM(.|\n)+AND ugp.is_active = true\s+AND ugp.status != 'claimed'\s+AND ugp.updated_at >= data.period_start\s+AND ugp.updated_at < data.period_end`).
+		WithArgs("ns", `{"user-1","user-2"}`, `{"daily-wins","daily-wins"}`, `{"relative","relative"}`, "{2,1}", "{3,3}",
+			`{"2026-03-09T00:00:00Z",""}`, `{"2026-03-10T00:00:00Z",""}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}).AddRow("user-1", "daily-wins"))
+
+	repo := NewPostgresLateProgressRepository(db)
+	written, err := repo.ApplyLateProgress(context.Background(), "ns", rows)
+
+	require.NoError(t, err)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "daily-wins"}}, written)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyLateProgress_NoRows(t *testing.T) {
+	repo := NewPostgresLateProgressRepository(nil)
+	written, err := repo.ApplyLateProgress(context.Background(), "ns", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+func TestApplyLateProgress_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	start := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	repo := NewPostgresLateProgressRepository(db)
+	_, err = repo.ApplyLateProgress(context.Background(), "ns", []commonRepo.CopyRow{
+		{UserID: "user-1", GoalID: "daily-wins", ProgressMode: "relative", IncValue: 1, TargetValue: 3, RotationBoundary: &start, NewExpiresAt: &end},
+	})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
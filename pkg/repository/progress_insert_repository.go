@@ -0,0 +1,182 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error classes caused by the values written rather than by the
+// connection or the server.
+const (
+	// dataExceptionClass covers e.g. values too long for their column.
+	dataExceptionClass = "22"
+	// integrityViolationClass is the PostgreSQL error class of constraint violations.
+	integrityViolationClass = "23"
+)
+
+// FailedInsert is a row BulkInsertReturningConflicts could not insert.
+type FailedInsert struct {
+	UserID string
+	GoalID string
+	Err    error
+}
+
+// BulkInsertResult is the per-row outcome of BulkInsertReturningConflicts.
+type BulkInsertResult struct {
+	// Inserted are the rows that were created.
+	Inserted []UserGoalKey
+	// Existing are the rows that already existed and were left alone.
+	Existing []UserGoalKey
+	// Failed are the rows that violate a constraint other than the primary key.
+	Failed []FailedInsert
+}
+
+// ProgressInsertRepository creates progress rows, reporting per row whether it
+// was created.
+//
+// BulkInsert (extend-challenge-common) is all-or-nothing and does not say which
+// rows already existed. It stays the choice for callers that need atomicity.
+type ProgressInsertRepository interface {
+	// BulkInsertReturningConflicts inserts progresses, skipping rows that already
+	// exist. If a row violates another constraint, the rows are inserted one by
+	// one so the others still go in, and the violating rows are reported as
+	// Failed. Other database errors are returned.
+	BulkInsertReturningConflicts(ctx context.Context, progresses []*domain.UserGoalProgress) (*BulkInsertResult, error)
+}
+
+// PostgresProgressInsertRepository implements ProgressInsertRepository on PostgreSQL.
+type PostgresProgressInsertRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresProgressInsertRepository creates a new PostgreSQL progress insert repository.
+func NewPostgresProgressInsertRepository(db *sql.DB) *PostgresProgressInsertRepository {
+	return &PostgresProgressInsertRepository{db: db}
+}
+
+// BulkInsertReturningConflicts inserts with one INSERT ... ON CONFLICT DO NOTHING
+// RETURNING statement, falling back to one statement per row on a constraint
+// violation.
+func (r *PostgresProgressInsertRepository) BulkInsertReturningConflicts(
+	ctx context.Context,
+	progresses []*domain.UserGoalProgress,
+) (*BulkInsertResult, error) {
+	result := &BulkInsertResult{}
+	if len(progresses) == 0 {
+		return result, nil
+	}
+
+	inserted, err := r.insert(ctx, progresses)
+	if err == nil {
+		result.add(progresses, inserted)
+		return result, nil
+	}
+	if !isIntegrityViolation(err) {
+		return nil, errors.ErrDatabaseError("bulk insert goals", err)
+	}
+
+	// Each statement is atomic, so find the violating rows by inserting one at a time
+	for _, progress := range progresses {
+		inserted, err := r.insert(ctx, []*domain.UserGoalProgress{progress})
+		switch {
+		case err == nil:
+			result.add([]*domain.UserGoalProgress{progress}, inserted)
+		case isIntegrityViolation(err):
+			result.Failed = append(result.Failed, FailedInsert{UserID: progress.UserID, GoalID: progress.GoalID, Err: err})
+		default:
+			return nil, errors.ErrDatabaseError("insert goal", err)
+		}
+	}
+
+	return result, nil
+}
+
+// insert runs one INSERT for progresses and returns the keys of the rows created.
+func (r *PostgresProgressInsertRepository) insert(ctx context.Context, progresses []*domain.UserGoalProgress) (map[UserGoalKey]bool, error) {
+	const columns = 12
+	valueStrings := make([]string, 0, len(progresses))
+	valueArgs := make([]interface{}, 0, len(progresses)*columns)
+	for i, p := range progresses {
+		n := i * columns
+		valueStrings = append(valueStrings, fmt.Sprintf(
+			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, NOW(), NOW(), $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12,
+		))
+		valueArgs = append(valueArgs,
+			p.UserID, p.GoalID, p.ChallengeID, p.Namespace,
+			p.Progress, p.Status, p.CompletedAt, p.ClaimedAt,
+			p.IsActive, p.AssignedAt, p.ExpiresAt, p.BaselineValue,
+		)
+	}
+
+	//nolint:gosec // Safe: valueStrings contains only parameterized placeholders like "($1, $2, $3)", not user input
+	query := fmt.Sprintf(`
+		INSERT INTO user_goal_progress (
+			user_id, goal_id, challenge_id, namespace,
+			progress, status, completed_at, claimed_at,
+			created_at, updated_at,
+			is_active, assigned_at, expires_at, baseline_value
+		) VALUES %s
+		ON CONFLICT (user_id, goal_id) DO NOTHING
+		RETURNING user_id, goal_id
+	`, strings.Join(valueStrings, ","))
+
+	rows, err := r.db.QueryContext(ctx, query, valueArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	inserted := make(map[UserGoalKey]bool, len(progresses))
+	for rows.Next() {
+		var key UserGoalKey
+		if err := rows.Scan(&key.UserID, &key.GoalID); err != nil {
+			return nil, err
+		}
+		inserted[key] = true
+	}
+
+	return inserted, rows.Err()
+}
+
+// add records progresses as inserted or existing.
+func (b *BulkInsertResult) add(progresses []*domain.UserGoalProgress, inserted map[UserGoalKey]bool) {
+	for _, p := range progresses {
+		key := UserGoalKey{UserID: p.UserID, GoalID: p.GoalID}
+		if inserted[key] {
+			b.Inserted = append(b.Inserted, key)
+		} else {
+			b.Existing = append(b.Existing, key)
+		}
+	}
+}
+
+// isIntegrityViolation reports whether err is a PostgreSQL constraint violation.
+func isIntegrityViolation(err error) bool {
+	var pqErr *pq.Error
+	return stdErrors.As(err, &pqErr) && pqErr.Code.Class() == integrityViolationClass
+}
+
+// IsRowError reports whether err is a PostgreSQL error caused by the rows
+// written: a data exception or a constraint violation. Writing the same rows
+// again fails the same way; writing the other rows of the batch without the bad
+// ones can succeed.
+func IsRowError(err error) bool {
+	var pqErr *pq.Error
+	if !stdErrors.As(err, &pqErr) {
+		return false
+	}
+	class := pqErr.Code.Class()
+	return class == dataExceptionClass || class == integrityViolationClass
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func defaultProgressRows(goalIDs ...string) []*domain.UserGoalProgress {
+	rows := make([]*domain.UserGoalProgress, len(goalIDs))
+	for i, goalID := range goalIDs {
+		rows[i] = &domain.UserGoalProgress{
+			UserID: "user-1", GoalID: goalID, ChallengeID: "challenge-1", Namespace: "ns",
+			Status: domain.GoalStatusNotStarted, IsActive: true,
+		}
+	}
+	return rows
+}
+
+func TestBulkInsertReturningConflicts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// goal-2 already exists, so only goal-1 is returned
+	mock.ExpectQuery(`INSERT INTO user_goal_progress(.|\n)+ON CONFLICT \(user_id, goal_id\) DO NOTHING\s+RETURNING user_id, goal_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}).AddRow("user-1", "goal-1"))
+
+	repo := NewPostgresProgressInsertRepository(db)
+	result, err := repo.BulkInsertReturningConflicts(context.Background(), defaultProgressRows("goal-1", "goal-2"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "goal-1"}}, result.Inserted)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "goal-2"}}, result.Existing)
+	assert.Empty(t, result.Failed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsertReturningConflicts_ConstraintViolationFallsBackPerRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	violation := &pq.Error{Code: "23514", Message: "violates check constraint"}
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WillReturnError(violation)
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WithArgs(sqlmock.AnyArg(), "goal-1", sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}).AddRow("user-1", "goal-1"))
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WillReturnError(violation)
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}))
+
+	repo := NewPostgresProgressInsertRepository(db)
+	result, err := repo.BulkInsertReturningConflicts(context.Background(), defaultProgressRows("goal-1", "goal-2", "goal-3"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "goal-1"}}, result.Inserted)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "goal-3"}}, result.Existing)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "goal-2", result.Failed[0].GoalID)
+	assert.ErrorIs(t, result.Failed[0].Err, violation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsertReturningConflicts_OtherErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`INSERT INTO user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresProgressInsertRepository(db)
+	_, err = repo.BulkInsertReturningConflicts(context.Background(), defaultProgressRows("goal-1"))
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestBulkInsertReturningConflicts_NoRows(t *testing.T) {
+	result, err := NewPostgresProgressInsertRepository(nil).BulkInsertReturningConflicts(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Inserted)
+}
+
+func TestIsRowError(t *testing.T) {
+	assert.True(t, IsRowError(&pq.Error{Code: "22001"}), "value too long")
+	assert.True(t, IsRowError(commonErrors.ErrDatabaseError("COPY", &pq.Error{Code: "23503"})), "wrapped foreign key violation")
+	assert.False(t, IsRowError(&pq.Error{Code: "40001"}), "serialization failure")
+	assert.False(t, IsRowError(&pq.Error{Code: "57P01"}), "admin shutdown")
+	assert.False(t, IsRowError(errors.New("connection refused")))
+	assert.False(t, IsRowError(nil))
+}
@@ -0,0 +1,672 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// ProgressQueryRepository provides read queries on user_goal_progress that are
+// specific to this service and not part of the shared GoalRepository
+// (extend-challenge-common is shared with the event handler).
+type ProgressQueryRepository interface {
+	// GetUserProgressPage retrieves up to limit progress rows for a user ordered by goal_id,
+	// starting after afterGoalID (keyset pagination). An empty afterGoalID starts from the
+	// beginning. activeOnly filters to is_active = true rows.
+	GetUserProgressPage(ctx context.Context, userID string, activeOnly bool, afterGoalID string, limit int) ([]*domain.UserGoalProgress, error)
+
+	// GetUserProgressSnapshot retrieves a user's progress rows, or one challenge's rows
+	// when challengeID is set, from a single REPEATABLE READ snapshot. activeOnly
+	// filters to is_active = true rows.
+	GetUserProgressSnapshot(ctx context.Context, userID, challengeID string, activeOnly bool) (*ProgressSnapshot, error)
+
+	// GetUserProgressSummary counts a user's progress rows in a namespace grouped by
+	// challenge_id and status. activeOnly filters to is_active = true rows.
+	GetUserProgressSummary(ctx context.Context, userID, namespace string, activeOnly bool) ([]*ProgressStatusCount, error)
+
+	// CountByStatus counts a user's active rows in a namespace with each of the
+	// given statuses. Statuses without rows are absent from the map.
+	CountByStatus(ctx context.Context, userID, namespace string, statuses []domain.GoalStatus) (map[domain.GoalStatus]int, error)
+
+	// CountUnclaimedCompleted counts, per goal, the rows in a namespace that are
+	// completed but not yet claimed. Goals without such rows are absent from the map.
+	CountUnclaimedCompleted(ctx context.Context, namespace string, goalIDs []string) (map[string]int, error)
+
+	// GetActiveGoalStatuses returns the status of every active row in a namespace whose
+	// user is in userIDs and goal is in goalIDs. Inactive and missing rows are absent.
+	GetActiveGoalStatuses(ctx context.Context, namespace string, userIDs, goalIDs []string) (map[UserGoalKey]domain.GoalStatus, error)
+
+	// GetActiveGoalIDsForUser returns the subset of goalIDs whose progress row for the
+	// user has is_active = true. Inactive and missing rows are absent.
+	GetActiveGoalIDsForUser(ctx context.Context, userID string, goalIDs []string) (map[string]bool, error)
+
+	// GetFirstCompletions returns when the user first completed each of goalIDs:
+	// first_completed_at for rows whose completion a reset moved there (migration
+	// 025), completed_at otherwise. Goals never completed and missing rows are absent.
+	GetFirstCompletions(ctx context.Context, userID string, goalIDs []string) (map[string]time.Time, error)
+
+	// GetGoalCompletionStats counts the progress rows in a namespace per goal and status,
+	// ordered by challenge_id, goal_id. Goals without rows are absent.
+	GetGoalCompletionStats(ctx context.Context, namespace string) ([]*GoalCompletionStats, error)
+
+	// GetChallengeMismatches returns up to limit rows in a namespace whose goal is in
+	// goalChallenges (goal ID to its config challenge ID) but whose challenge_id
+	// differs, ordered by goal_id, user_id. Goals absent from the map are skipped.
+	GetChallengeMismatches(ctx context.Context, namespace string, goalChallenges map[string]string, limit int) ([]*ChallengeMismatch, error)
+
+	// GetUsersReachingTargets returns up to limit users in a namespace, ordered by
+	// user_id, with a not_started or in_progress row of a goal in goalTargets (goal
+	// ID to target) whose progress less its baseline reaches the target.
+	GetUsersReachingTargets(ctx context.Context, namespace string, goalTargets map[string]int, limit int) ([]string, error)
+}
+
+// UserGoalKey identifies one user_goal_progress row.
+type UserGoalKey struct {
+	UserID string
+	GoalID string
+}
+
+// ProgressSnapshot is a user's progress rows as of one database snapshot.
+type ProgressSnapshot struct {
+	Rows []*domain.UserGoalProgress
+	// TakenAt is the database clock once the snapshot was taken. Writes from
+	// transactions that started after it are not in Rows, and their rows get
+	// a later updated_at.
+	TakenAt time.Time
+}
+
+// ProgressStatusCount is the number of a user's goals in one challenge with a given status.
+type ProgressStatusCount struct {
+	ChallengeID string
+	Status      domain.GoalStatus
+	Count       int
+}
+
+// GoalCompletionStats is the number of progress rows for one goal in each status.
+// Players without a row for the goal (never assigned, no event yet) are not counted.
+type GoalCompletionStats struct {
+	ChallengeID string
+	GoalID      string
+	NotStarted  int
+	InProgress  int
+	Completed   int
+	Claimed     int
+}
+
+// Total returns the number of progress rows for the goal.
+func (s *GoalCompletionStats) Total() int {
+	return s.NotStarted + s.InProgress + s.Completed + s.Claimed
+}
+
+// ChallengeMismatch is a progress row stored under a different challenge than
+// its goal in the config, e.g. left behind when goals moved between challenges.
+type ChallengeMismatch struct {
+	UserID            string
+	GoalID            string
+	StoredChallengeID string
+	// ChallengeID is the goal's challenge in the config.
+	ChallengeID string
+	Status      domain.GoalStatus
+	UpdatedAt   time.Time
+}
+
+// PostgresProgressQueryRepository implements ProgressQueryRepository on PostgreSQL.
+type PostgresProgressQueryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresProgressQueryRepository creates a new PostgreSQL progress query repository.
+func NewPostgresProgressQueryRepository(db *sql.DB) *PostgresProgressQueryRepository {
+	return &PostgresProgressQueryRepository{db: db}
+}
+
+// GetUserProgressPage retrieves one keyset page of a user's progress ordered by goal_id.
+//
+// The (user_id, goal_id) primary key serves the full scan; the partial index
+// idx_user_goal_progress_active_keyset (migration 003) serves activeOnly pages, so
+// each page is an index range scan regardless of how many goals the user has.
+func (r *PostgresProgressQueryRepository) GetUserProgressPage(
+	ctx context.Context,
+	userID string,
+	activeOnly bool,
+	afterGoalID string,
+	limit int,
+) ([]*domain.UserGoalProgress, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, progress, status,
+		       completed_at, claimed_at, created_at, updated_at,
+		       is_active, assigned_at, expires_at, baseline_value
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id > $2
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY goal_id ASC LIMIT $3"
+
+	rows, err := r.db.QueryContext(ctx, query, userID, afterGoalID, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get user progress page", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanProgressRows(rows)
+}
+
+// GetUserProgressSnapshot reads the rows in a read-only REPEATABLE READ
+// transaction. Its first statement takes the snapshot and reads the clock, so
+// the rows and TakenAt come from the same snapshot.
+func (r *PostgresProgressQueryRepository) GetUserProgressSnapshot(
+	ctx context.Context,
+	userID string,
+	challengeID string,
+	activeOnly bool,
+) (*ProgressSnapshot, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin progress snapshot", err)
+	}
+
+	// Read-only, so rolling back after the reads is as good as committing
+	defer func() { _ = tx.Rollback() }()
+
+	snapshot := &ProgressSnapshot{}
+	if err := tx.QueryRowContext(ctx, "SELECT clock_timestamp()").Scan(&snapshot.TakenAt); err != nil {
+		return nil, errors.ErrDatabaseError("get progress snapshot time", err)
+	}
+
+	query := `
+		SELECT user_id, goal_id, challenge_id, namespace, progress, status,
+		       completed_at, claimed_at, created_at, updated_at,
+		       is_active, assigned_at, expires_at, baseline_value
+		FROM user_goal_progress
+		WHERE user_id = $1 AND ($2 = '' OR challenge_id = $2)
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+
+	rows, err := tx.QueryContext(ctx, query, userID, challengeID)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get user progress snapshot", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	snapshot.Rows, err = scanProgressRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// GetUserProgressSummary aggregates a user's progress in the database so callers do
+// not have to load every row to count them. Rows are ordered by challenge_id, status.
+func (r *PostgresProgressQueryRepository) GetUserProgressSummary(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	activeOnly bool,
+) ([]*ProgressStatusCount, error) {
+	query := `
+		SELECT challenge_id, status, COUNT(*)
+		FROM user_goal_progress
+		WHERE user_id = $1 AND namespace = $2
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " GROUP BY challenge_id, status ORDER BY challenge_id, status"
+
+	rows, err := r.db.QueryContext(ctx, query, userID, namespace)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get user progress summary", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []*ProgressStatusCount
+	for rows.Next() {
+		var count ProgressStatusCount
+		if err := rows.Scan(&count.ChallengeID, &count.Status, &count.Count); err != nil {
+			return nil, errors.ErrDatabaseError("scan progress summary row", err)
+		}
+		results = append(results, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate progress summary rows", err)
+	}
+
+	return results, nil
+}
+
+// CountByStatus is an index range scan on the (user_id, goal_id) primary key
+// that returns one row per status, so it stays cheap however many goals the user
+// has. It serves the unclaimed rewards badge, which clients poll on every menu open.
+func (r *PostgresProgressQueryRepository) CountByStatus(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	statuses []domain.GoalStatus,
+) (map[domain.GoalStatus]int, error) {
+	counts := make(map[domain.GoalStatus]int)
+	if len(statuses) == 0 {
+		return counts, nil
+	}
+
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+
+	query := `
+		SELECT status, COUNT(*)
+		FROM user_goal_progress
+		WHERE user_id = $1 AND namespace = $2 AND is_active = true AND status = ANY($3)
+		GROUP BY status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, namespace, pq.Array(values))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("count progress by status", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var status domain.GoalStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, errors.ErrDatabaseError("scan progress status count", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate progress status counts", err)
+	}
+
+	return counts, nil
+}
+
+// CountUnclaimedCompleted counts completed-but-unclaimed rows for the given goals.
+//
+// No index leads with goal_id, so this scans user_goal_progress. It is meant for
+// rare admin operations (config reload), not request paths.
+func (r *PostgresProgressQueryRepository) CountUnclaimedCompleted(
+	ctx context.Context,
+	namespace string,
+	goalIDs []string,
+) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(goalIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT goal_id, COUNT(*)
+		FROM user_goal_progress
+		WHERE namespace = $1 AND goal_id = ANY($2) AND status = 'completed'
+		GROUP BY goal_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("count unclaimed completed progress", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var goalID string
+		var count int
+		if err := rows.Scan(&goalID, &count); err != nil {
+			return nil, errors.ErrDatabaseError("scan unclaimed completed count", err)
+		}
+		counts[goalID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate unclaimed completed counts", err)
+	}
+
+	return counts, nil
+}
+
+// GetActiveGoalStatuses looks up active rows for a batch of users and goals.
+//
+// The (user_id, goal_id) primary key serves the user_id = ANY lookup; callers bound
+// the result size by chunking userIDs and goalIDs.
+func (r *PostgresProgressQueryRepository) GetActiveGoalStatuses(
+	ctx context.Context,
+	namespace string,
+	userIDs []string,
+	goalIDs []string,
+) (map[UserGoalKey]domain.GoalStatus, error) {
+	statuses := make(map[UserGoalKey]domain.GoalStatus)
+	if len(userIDs) == 0 || len(goalIDs) == 0 {
+		return statuses, nil
+	}
+
+	query := `
+		SELECT user_id, goal_id, status
+		FROM user_goal_progress
+		WHERE namespace = $1 AND user_id = ANY($2) AND goal_id = ANY($3) AND is_active = true
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, pq.Array(userIDs), pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get active goal statuses", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var key UserGoalKey
+		var status domain.GoalStatus
+		if err := rows.Scan(&key.UserID, &key.GoalID, &status); err != nil {
+			return nil, errors.ErrDatabaseError("scan active goal status", err)
+		}
+		statuses[key] = status
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate active goal statuses", err)
+	}
+
+	return statuses, nil
+}
+
+// GetActiveGoalIDsForUser is an index range scan on the (user_id, goal_id) primary key.
+func (r *PostgresProgressQueryRepository) GetActiveGoalIDsForUser(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string]bool, error) {
+	active := make(map[string]bool)
+	if len(goalIDs) == 0 {
+		return active, nil
+	}
+
+	query := `
+		SELECT goal_id
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = ANY($2) AND is_active = true
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get active goal IDs", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var goalID string
+		if err := rows.Scan(&goalID); err != nil {
+			return nil, errors.ErrDatabaseError("scan active goal ID", err)
+		}
+		active[goalID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate active goal IDs", err)
+	}
+
+	return active, nil
+}
+
+// GetFirstCompletions is an index range scan on the (user_id, goal_id) primary key.
+func (r *PostgresProgressQueryRepository) GetFirstCompletions(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string]time.Time, error) {
+	completions := make(map[string]time.Time)
+	if len(goalIDs) == 0 {
+		return completions, nil
+	}
+
+	query := `
+		SELECT goal_id, COALESCE(first_completed_at, completed_at)
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = ANY($2)
+		  AND COALESCE(first_completed_at, completed_at) IS NOT NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get first completions", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var goalID string
+		var completedAt time.Time
+		if err := rows.Scan(&goalID, &completedAt); err != nil {
+			return nil, errors.ErrDatabaseError("scan first completion", err)
+		}
+		completions[goalID] = completedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate first completions", err)
+	}
+
+	return completions, nil
+}
+
+// GetGoalCompletionStats aggregates progress per goal in the database.
+//
+// This scans every row of the namespace in user_goal_progress. It is meant for the
+// cached admin stats, not request paths.
+func (r *PostgresProgressQueryRepository) GetGoalCompletionStats(
+	ctx context.Context,
+	namespace string,
+) ([]*GoalCompletionStats, error) {
+	query := `
+		SELECT challenge_id, goal_id,
+		       COUNT(*) FILTER (WHERE status = 'not_started'),
+		       COUNT(*) FILTER (WHERE status = 'in_progress'),
+		       COUNT(*) FILTER (WHERE status = 'completed'),
+		       COUNT(*) FILTER (WHERE status = 'claimed')
+		FROM user_goal_progress
+		WHERE namespace = $1
+		GROUP BY challenge_id, goal_id
+		ORDER BY challenge_id, goal_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get goal completion stats", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []*GoalCompletionStats
+	for rows.Next() {
+		var stats GoalCompletionStats
+		if err := rows.Scan(&stats.ChallengeID, &stats.GoalID,
+			&stats.NotStarted, &stats.InProgress, &stats.Completed, &stats.Claimed); err != nil {
+			return nil, errors.ErrDatabaseError("scan goal completion stats row", err)
+		}
+		results = append(results, &stats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate goal completion stats rows", err)
+	}
+
+	return results, nil
+}
+
+// GetChallengeMismatches compares each row against the config through an
+// unnest of the goal/challenge pairs.
+//
+// No index leads with goal_id, so this scans user_goal_progress. It is meant for
+// admin reconciliation, not request paths.
+func (r *PostgresProgressQueryRepository) GetChallengeMismatches(
+	ctx context.Context,
+	namespace string,
+	goalChallenges map[string]string,
+	limit int,
+) ([]*ChallengeMismatch, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+	if len(goalChallenges) == 0 {
+		return nil, nil
+	}
+
+	goalIDs, challengeIDs := goalChallengeArrays(goalChallenges)
+	query := `
+		SELECT p.user_id, p.goal_id, p.challenge_id, e.challenge_id, p.status, p.updated_at
+		FROM user_goal_progress p
+		JOIN unnest($2::text[], $3::text[]) AS e(goal_id, challenge_id) ON e.goal_id = p.goal_id
+		WHERE p.namespace = $1 AND p.challenge_id <> e.challenge_id
+		ORDER BY p.goal_id, p.user_id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, pq.Array(goalIDs), pq.Array(challengeIDs), limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get challenge mismatches", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []*ChallengeMismatch
+	for rows.Next() {
+		var mismatch ChallengeMismatch
+		if err := rows.Scan(&mismatch.UserID, &mismatch.GoalID, &mismatch.StoredChallengeID,
+			&mismatch.ChallengeID, &mismatch.Status, &mismatch.UpdatedAt); err != nil {
+			return nil, errors.ErrDatabaseError("scan challenge mismatch row", err)
+		}
+		results = append(results, &mismatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate challenge mismatch rows", err)
+	}
+
+	return results, nil
+}
+
+// GetUsersReachingTargets finds the users a lowered goal target completes, e.g.
+// to recompute their statuses after a config reload.
+//
+// Like CountUnclaimedCompleted this scans user_goal_progress and is meant for
+// rare admin operations.
+func (r *PostgresProgressQueryRepository) GetUsersReachingTargets(
+	ctx context.Context,
+	namespace string,
+	goalTargets map[string]int,
+	limit int,
+) ([]string, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got %d", limit)
+	}
+	if len(goalTargets) == 0 {
+		return nil, nil
+	}
+
+	goalIDs := make([]string, 0, len(goalTargets))
+	for goalID := range goalTargets {
+		goalIDs = append(goalIDs, goalID)
+	}
+	sort.Strings(goalIDs)
+	targets := make([]int64, len(goalIDs))
+	for i, goalID := range goalIDs {
+		targets[i] = int64(goalTargets[goalID])
+	}
+
+	query := `
+		SELECT DISTINCT p.user_id
+		FROM user_goal_progress p
+		JOIN unnest($2::text[], $3::int[]) AS t(goal_id, target) ON t.goal_id = p.goal_id
+		WHERE p.namespace = $1 AND p.status IN ('not_started', 'in_progress')
+		  AND p.progress - COALESCE(p.baseline_value, 0) >= t.target
+		ORDER BY p.user_id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace, pq.Array(goalIDs), pq.Array(targets), limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get users reaching targets", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.ErrDatabaseError("scan user reaching targets", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate users reaching targets", err)
+	}
+
+	return userIDs, nil
+}
+
+// goalChallengeArrays splits a goal ID to challenge ID map into parallel arrays
+// ordered by goal ID, for unnest.
+func goalChallengeArrays(goalChallenges map[string]string) (goalIDs, challengeIDs []string) {
+	goalIDs = make([]string, 0, len(goalChallenges))
+	for goalID := range goalChallenges {
+		goalIDs = append(goalIDs, goalID)
+	}
+	sort.Strings(goalIDs)
+
+	challengeIDs = make([]string, len(goalIDs))
+	for i, goalID := range goalIDs {
+		challengeIDs[i] = goalChallenges[goalID]
+	}
+	return goalIDs, challengeIDs
+}
+
+// scanProgressRows scans rows selected with the standard user_goal_progress column list.
+func scanProgressRows(rows *sql.Rows) ([]*domain.UserGoalProgress, error) {
+	var results []*domain.UserGoalProgress
+
+	for rows.Next() {
+		var progress domain.UserGoalProgress
+		err := rows.Scan(
+			&progress.UserID,
+			&progress.GoalID,
+			&progress.ChallengeID,
+			&progress.Namespace,
+			&progress.Progress,
+			&progress.Status,
+			&progress.CompletedAt,
+			&progress.ClaimedAt,
+			&progress.CreatedAt,
+			&progress.UpdatedAt,
+			&progress.IsActive,
+			&progress.AssignedAt,
+			&progress.ExpiresAt,
+			&progress.BaselineValue,
+		)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("scan progress row", err)
+		}
+		results = append(results, &progress)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate progress rows", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+var progressColumns = []string{
+	"user_id", "goal_id", "challenge_id", "namespace", "progress", "status",
+	"completed_at", "claimed_at", "created_at", "updated_at",
+	"is_active", "assigned_at", "expires_at", "baseline_value",
+}
+
+func TestGetUserProgressPage_ActiveOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	now := time.Now().UTC()
+	mock.ExpectQuery(`WHERE user_id = \$1 AND goal_id > \$2\s+AND is_active = true ORDER BY goal_id ASC LIMIT \$3`).
+		WithArgs("user-1", "goal-010", 2).
+		WillReturnRows(sqlmock.NewRows(progressColumns).
+			AddRow("user-1", "goal-011", "ch-1", "ns", 3, "in_progress", nil, nil, now, now, true, now, nil, nil).
+			AddRow("user-1", "goal-012", "ch-1", "ns", 5, "completed", now, nil, now, now, true, now, nil, nil))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	page, err := repo.GetUserProgressPage(context.Background(), "user-1", true, "goal-010", 2)
+
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "goal-011", page[0].GoalID)
+	assert.Equal(t, "goal-012", page[1].GoalID)
+	assert.NotNil(t, page[1].CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	takenAt := time.Date(2025, 6, 1, 12, 0, 0, 250_000_000, time.UTC)
+	now := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT clock_timestamp\(\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"clock_timestamp"}).AddRow(takenAt))
+	mock.ExpectQuery(`WHERE user_id = \$1 AND \(\$2 = '' OR challenge_id = \$2\)\s+AND is_active = true`).
+		WithArgs("user-1", "ch-1").
+		WillReturnRows(sqlmock.NewRows(progressColumns).
+			AddRow("user-1", "goal-1", "ch-1", "ns", 5, "completed", now, nil, now, now, true, now, nil, nil))
+	mock.ExpectRollback()
+
+	repo := NewPostgresProgressQueryRepository(db)
+	snapshot, err := repo.GetUserProgressSnapshot(context.Background(), "user-1", "ch-1", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, takenAt, snapshot.TakenAt)
+	require.Len(t, snapshot.Rows, 1)
+	assert.Equal(t, "goal-1", snapshot.Rows[0].GoalID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressSnapshot_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT clock_timestamp\(\)`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetUserProgressSnapshot(context.Background(), "user-1", "", false)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressPage_AllGoalsFirstPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`WHERE user_id = \$1 AND goal_id > \$2\s+ORDER BY goal_id ASC LIMIT \$3`).
+		WithArgs("user-1", "", 50).
+		WillReturnRows(sqlmock.NewRows(progressColumns))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	page, err := repo.GetUserProgressPage(context.Background(), "user-1", false, "", 50)
+
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressPage_InvalidLimit(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetUserProgressPage(context.Background(), "user-1", false, "", 0)
+
+	assert.Error(t, err)
+}
+
+func TestGetUserProgressPage_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM user_goal_progress`).WillReturnError(errors.New("connection refused"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetUserProgressPage(context.Background(), "user-1", false, "", 10)
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestGetUserProgressSummary_ActiveOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT challenge_id, status, COUNT\(\*\)\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND namespace = \$2\s+AND is_active = true GROUP BY challenge_id, status`).
+		WithArgs("user-1", "ns").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "status", "count"}).
+			AddRow("ch-1", "claimed", 2).
+			AddRow("ch-1", "in_progress", 1).
+			AddRow("ch-2", "completed", 4))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	counts, err := repo.GetUserProgressSummary(context.Background(), "user-1", "ns", true)
+
+	require.NoError(t, err)
+	require.Len(t, counts, 3)
+	assert.Equal(t, ProgressStatusCount{ChallengeID: "ch-1", Status: "claimed", Count: 2}, *counts[0])
+	assert.Equal(t, ProgressStatusCount{ChallengeID: "ch-2", Status: "completed", Count: 4}, *counts[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressSummary_AllGoals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`WHERE user_id = \$1 AND namespace = \$2\s+GROUP BY challenge_id, status`).
+		WithArgs("user-1", "ns").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "status", "count"}))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	counts, err := repo.GetUserProgressSummary(context.Background(), "user-1", "ns", false)
+
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserProgressSummary_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM user_goal_progress`).WillReturnError(errors.New("connection refused"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetUserProgressSummary(context.Background(), "user-1", "ns", false)
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestCountUnclaimedCompleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id, COUNT\(\*\)\s+FROM user_goal_progress\s+WHERE namespace = \$1 AND goal_id = ANY\(\$2\) AND status = 'completed'\s+GROUP BY goal_id`).
+		WithArgs("ns", `{"goal-1","goal-2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "count"}).AddRow("goal-1", 12))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	counts, err := repo.CountUnclaimedCompleted(context.Background(), "ns", []string{"goal-1", "goal-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"goal-1": 12}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountUnclaimedCompleted_NoGoals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewPostgresProgressQueryRepository(db)
+	counts, err := repo.CountUnclaimedCompleted(context.Background(), "ns", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActiveGoalStatuses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT user_id, goal_id, status\s+FROM user_goal_progress\s+WHERE namespace = \$1 AND user_id = ANY\(\$2\) AND goal_id = ANY\(\$3\) AND is_active = true`).
+		WithArgs("ns", `{"user-1","user-2"}`, `{"goal-1","goal-2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status"}).
+			AddRow("user-1", "goal-1", "in_progress").
+			AddRow("user-2", "goal-2", "claimed"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	statuses, err := repo.GetActiveGoalStatuses(context.Background(), "ns", []string{"user-1", "user-2"}, []string{"goal-1", "goal-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[UserGoalKey]domain.GoalStatus{
+		{UserID: "user-1", GoalID: "goal-1"}: domain.GoalStatusInProgress,
+		{UserID: "user-2", GoalID: "goal-2"}: domain.GoalStatusClaimed,
+	}, statuses)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActiveGoalStatuses_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`FROM user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetActiveGoalStatuses(context.Background(), "ns", []string{"user-1"}, []string{"goal-1"})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestGetActiveGoalIDsForUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\) AND is_active = true`).
+		WithArgs("user-1", `{"goal-1","goal-2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id"}).AddRow("goal-2"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	active, err := repo.GetActiveGoalIDsForUser(context.Background(), "user-1", []string{"goal-1", "goal-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"goal-2": true}, active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActiveGoalIDsForUser_NoGoals(t *testing.T) {
+	repo := NewPostgresProgressQueryRepository(nil)
+	active, err := repo.GetActiveGoalIDsForUser(context.Background(), "user-1", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestGetFirstCompletions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	first := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT goal_id, COALESCE\(first_completed_at, completed_at\)\s+FROM user_goal_progress\s+`+
+		`WHERE user_id = \$1 AND goal_id = ANY\(\$2\)\s+AND COALESCE\(first_completed_at, completed_at\) IS NOT NULL`).
+		WithArgs("user-1", `{"goal-1","goal-2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "coalesce"}).AddRow("goal-1", first))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	completions, err := repo.GetFirstCompletions(context.Background(), "user-1", []string{"goal-1", "goal-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"goal-1": first}, completions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFirstCompletions_NoGoals(t *testing.T) {
+	repo := NewPostgresProgressQueryRepository(nil)
+	completions, err := repo.GetFirstCompletions(context.Background(), "user-1", nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+}
+
+func TestGetGoalCompletionStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT challenge_id, goal_id,(.|\n)+FROM user_goal_progress\s+WHERE namespace = \$1\s+GROUP BY challenge_id, goal_id\s+ORDER BY challenge_id, goal_id`).
+		WithArgs("ns").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "goal_id", "not_started", "in_progress", "completed", "claimed"}).
+			AddRow("daily", "login", 1, 2, 3, 4).
+			AddRow("daily", "play-match", 10, 0, 0, 0))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	stats, err := repo.GetGoalCompletionStats(context.Background(), "ns")
+
+	require.NoError(t, err)
+	assert.Equal(t, []*GoalCompletionStats{
+		{ChallengeID: "daily", GoalID: "login", NotStarted: 1, InProgress: 2, Completed: 3, Claimed: 4},
+		{ChallengeID: "daily", GoalID: "play-match", NotStarted: 10},
+	}, stats)
+	assert.Equal(t, 10, stats[0].Total())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGoalCompletionStats_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT challenge_id, goal_id`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	_, err = repo.GetGoalCompletionStats(context.Background(), "ns")
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestGetChallengeMismatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	updatedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`JOIN unnest\(\$2::text\[\], \$3::text\[\]\) AS e\(goal_id, challenge_id\)(.|\n)+WHERE p.namespace = \$1 AND p.challenge_id <> e.challenge_id`).
+		WithArgs("ns", pq.Array([]string{"kills", "login"}), pq.Array([]string{"weekly", "daily"}), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "challenge_id", "challenge_id", "status", "updated_at"}).
+			AddRow("u1", "kills", "daily", "weekly", "completed", updatedAt))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	mismatches, err := repo.GetChallengeMismatches(context.Background(), "ns", map[string]string{"login": "daily", "kills": "weekly"}, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*ChallengeMismatch{{
+		UserID:            "u1",
+		GoalID:            "kills",
+		StoredChallengeID: "daily",
+		ChallengeID:       "weekly",
+		Status:            domain.GoalStatusCompleted,
+		UpdatedAt:         updatedAt,
+	}}, mismatches)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChallengeMismatches_NoGoals(t *testing.T) {
+	repo := NewPostgresProgressQueryRepository(nil)
+	mismatches, err := repo.GetChallengeMismatches(context.Background(), "ns", nil, 10)
+
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestCountByStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT status, COUNT\(\*\)\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND namespace = \$2 AND is_active = true AND status = ANY\(\$3\)\s+GROUP BY status`).
+		WithArgs("user-1", "ns", pq.Array([]string{"completed", "in_progress"})).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).AddRow("completed", 2))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	counts, err := repo.CountByStatus(context.Background(), "user-1", "ns",
+		[]domain.GoalStatus{domain.GoalStatusCompleted, domain.GoalStatusInProgress})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.GoalStatus]int{domain.GoalStatusCompleted: 2}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersReachingTargets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT DISTINCT p.user_id\s+FROM user_goal_progress p\s+JOIN unnest\(\$2::text\[\], \$3::int\[\]\) AS t\(goal_id, target\) ON t.goal_id = p.goal_id\s+WHERE p.namespace = \$1 AND p.status IN \('not_started', 'in_progress'\)`).
+		WithArgs("ns", `{"goal-1","goal-2"}`, "{5,3}", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1").AddRow("user-2"))
+
+	repo := NewPostgresProgressQueryRepository(db)
+	userIDs, err := repo.GetUsersReachingTargets(context.Background(), "ns", map[string]int{"goal-2": 3, "goal-1": 5}, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1", "user-2"}, userIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersReachingTargets_NoGoals(t *testing.T) {
+	repo := NewPostgresProgressQueryRepository(nil)
+
+	userIDs, err := repo.GetUsersReachingTargets(context.Background(), "ns", nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, userIDs)
+
+	_, err = repo.GetUsersReachingTargets(context.Background(), "ns", map[string]int{"goal-1": 5}, 0)
+	assert.Error(t, err)
+}
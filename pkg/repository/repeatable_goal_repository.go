@@ -0,0 +1,120 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// RepeatState is how often a user claimed a repeatable goal, and when last.
+type RepeatState struct {
+	TimesClaimed  int
+	LastClaimedAt time.Time
+	// FirstCompletedAt is when the user first completed the goal, which the
+	// reset of its claimed row keeps (first_completed_at, migration 025); nil
+	// for rows claimed without a completion time.
+	FirstCompletedAt *time.Time
+}
+
+// RepeatableGoalRepository resets the progress rows of claimed repeatable goals
+// and reads their claim history (user_goal_progress.times_claimed and
+// last_claimed_at, migration 021).
+type RepeatableGoalRepository interface {
+	// ResetClaimed resets the user's claimed row of goalID to an inactive
+	// not_started row without progress, counting the claim and keeping its
+	// time. It returns nil when the row is not claimed, e.g. already reset.
+	ResetClaimed(ctx context.Context, userID, goalID string) (*RepeatState, error)
+
+	// GetRepeatStates returns goal ID -> state for the user's rows among goalIDs
+	// that were claimed and reset at least once.
+	GetRepeatStates(ctx context.Context, userID string, goalIDs []string) (map[string]RepeatState, error)
+}
+
+// PostgresRepeatableGoalRepository implements RepeatableGoalRepository on PostgreSQL.
+type PostgresRepeatableGoalRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepeatableGoalRepository creates a new PostgreSQL repeatable goal repository.
+func NewPostgresRepeatableGoalRepository(db *sql.DB) *PostgresRepeatableGoalRepository {
+	return &PostgresRepeatableGoalRepository{db: db}
+}
+
+// ResetClaimed updates the row in one statement, moving claimed_at to
+// last_claimed_at. Only claimed rows match, so resetting twice counts the claim
+// once.
+func (r *PostgresRepeatableGoalRepository) ResetClaimed(ctx context.Context, userID, goalID string) (*RepeatState, error) {
+	query := `
+		UPDATE user_goal_progress
+		SET status = 'not_started',
+		    progress = 0,
+		    baseline_value = NULL,
+		    step_progress = NULL,
+		    first_completed_at = COALESCE(first_completed_at, completed_at),
+		    completed_at = NULL,
+		    claimed_at = NULL,
+		    is_active = false,
+		    last_claimed_at = claimed_at,
+		    times_claimed = times_claimed + 1,
+		    updated_at = NOW()
+		WHERE user_id = $1 AND goal_id = $2 AND status = 'claimed'
+		RETURNING times_claimed, last_claimed_at, first_completed_at
+	`
+
+	var state RepeatState
+	err := r.db.QueryRowContext(ctx, query, userID, goalID).Scan(&state.TimesClaimed, &state.LastClaimedAt, &state.FirstCompletedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.ErrDatabaseError("reset claimed repeatable goal", err)
+	}
+
+	return &state, nil
+}
+
+// GetRepeatStates uses the (user_id, goal_id) primary key.
+func (r *PostgresRepeatableGoalRepository) GetRepeatStates(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string]RepeatState, error) {
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT goal_id, times_claimed, last_claimed_at, first_completed_at
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = ANY($2) AND last_claimed_at IS NOT NULL
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get repeat states", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := make(map[string]RepeatState)
+	for rows.Next() {
+		var goalID string
+		var state RepeatState
+		if err := rows.Scan(&goalID, &state.TimesClaimed, &state.LastClaimedAt, &state.FirstCompletedAt); err != nil {
+			return nil, errors.ErrDatabaseError("scan repeat state", err)
+		}
+		states[goalID] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate repeat states", err)
+	}
+
+	return states, nil
+}
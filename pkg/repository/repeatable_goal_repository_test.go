@@ -0,0 +1,97 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetClaimed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	claimedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	firstCompletedAt := claimedAt.Add(-72 * time.Hour)
+	mock.ExpectQuery(`UPDATE user_goal_progress\s+SET status = 'not_started',(.|\n)+first_completed_at = COALESCE\(first_completed_at, completed_at\),\s+completed_at = NULL,(.|\n)+is_active = false,\s+last_claimed_at = claimed_at,\s+times_claimed = times_claimed \+ 1,(.|\n)+WHERE user_id = \$1 AND goal_id = \$2 AND status = 'claimed'\s+RETURNING times_claimed, last_claimed_at, first_completed_at`).
+		WithArgs("user-1", "daily-win").
+		WillReturnRows(sqlmock.NewRows([]string{"times_claimed", "last_claimed_at", "first_completed_at"}).AddRow(3, claimedAt, firstCompletedAt))
+
+	repo := NewPostgresRepeatableGoalRepository(db)
+	state, err := repo.ResetClaimed(context.Background(), "user-1", "daily-win")
+
+	require.NoError(t, err)
+	assert.Equal(t, &RepeatState{TimesClaimed: 3, LastClaimedAt: claimedAt, FirstCompletedAt: &firstCompletedAt}, state)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetClaimed_NotClaimed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).
+		WillReturnRows(sqlmock.NewRows([]string{"times_claimed", "last_claimed_at", "first_completed_at"}))
+
+	repo := NewPostgresRepeatableGoalRepository(db)
+	state, err := repo.ResetClaimed(context.Background(), "user-1", "daily-win")
+
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestResetClaimed_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresRepeatableGoalRepository(db)
+	_, err = repo.ResetClaimed(context.Background(), "user-1", "daily-win")
+
+	assert.ErrorContains(t, err, "reset claimed repeatable goal")
+}
+
+func TestGetRepeatStates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	claimedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT goal_id, times_claimed, last_claimed_at, first_completed_at\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\) AND last_claimed_at IS NOT NULL`).
+		WithArgs("user-1", pq.Array([]string{"daily-win", "weekly-win"})).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "times_claimed", "last_claimed_at", "first_completed_at"}).
+			AddRow("daily-win", 2, claimedAt, claimedAt.Add(-time.Hour)))
+
+	repo := NewPostgresRepeatableGoalRepository(db)
+	states, err := repo.GetRepeatStates(context.Background(), "user-1", []string{"daily-win", "weekly-win"})
+
+	require.NoError(t, err)
+	firstCompletedAt := claimedAt.Add(-time.Hour)
+	assert.Equal(t, map[string]RepeatState{"daily-win": {TimesClaimed: 2, LastClaimedAt: claimedAt, FirstCompletedAt: &firstCompletedAt}}, states)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRepeatStates_NoGoals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewPostgresRepeatableGoalRepository(db)
+	states, err := repo.GetRepeatStates(context.Background(), "user-1", nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, states)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// RewardCapRepository counts the claims holding one of a goal's capped rewards
+// (reward_cap_counters, migration 019). A slot is held by the claim's outbox
+// entry (ClaimOutboxEntry.RewardCapSlot) until the reward is granted, so a claim
+// released without granting gives it back.
+//
+// Like the outbox, slots are taken and released outside the claim's progress
+// transaction, whose *sql.Tx the goal repository of extend-challenge-common does
+// not expose; each call commits on its own. A claim that fails after taking a
+// slot, its transaction's commit included, releases it with ReleaseSlot. If that
+// fails too, the slot stays marked on the pending outbox entry, and claim
+// recovery gives it back when it deletes the stale entry (see
+// ClaimOutboxRepository.DeleteIfPending), so a slot is never lost.
+type RewardCapRepository interface {
+	// TakeSlot takes one of the limit slots of goalID for the user's pending
+	// claim outbox entry, which must exist. It reports whether a slot was taken
+	// and the number of slots taken afterwards; when every slot is taken it
+	// returns false and limit (or more, if the limit was lowered).
+	TakeSlot(ctx context.Context, userID, goalID string, limit int) (bool, int, error)
+
+	// ReleaseSlot gives back the slot held by the user's pending claim outbox
+	// entry for goalID. Releasing an entry without a slot is not an error.
+	ReleaseSlot(ctx context.Context, userID, goalID string) error
+}
+
+// PostgresRewardCapRepository implements RewardCapRepository on PostgreSQL.
+type PostgresRewardCapRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRewardCapRepository creates a new PostgreSQL reward cap repository.
+func NewPostgresRewardCapRepository(db *sql.DB) *PostgresRewardCapRepository {
+	return &PostgresRewardCapRepository{db: db}
+}
+
+// TakeSlot increments the goal's counter with a single UPDATE ... WHERE
+// claimed < limit RETURNING. Concurrent claims queue on the counter row and each
+// re-checks the limit against the committed count, so no more than limit slots
+// are ever taken. The outbox entry is marked in the same statement.
+func (r *PostgresRewardCapRepository) TakeSlot(ctx context.Context, userID, goalID string, limit int) (bool, int, error) {
+	// A separate statement: the UPDATE below cannot see a row inserted by the same statement
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO reward_cap_counters (goal_id, claimed, updated_at)
+		VALUES ($1, 0, NOW())
+		ON CONFLICT (goal_id) DO NOTHING
+	`, goalID); err != nil {
+		return false, 0, errors.ErrDatabaseError("create reward cap counter", err)
+	}
+
+	query := `
+		WITH slot AS (
+			UPDATE reward_cap_counters
+			SET claimed = claimed + 1, updated_at = NOW()
+			WHERE goal_id = $2 AND claimed < $3
+			  AND EXISTS (
+				SELECT 1 FROM claim_outbox
+				WHERE user_id = $1 AND goal_id = $2 AND state = 'pending' AND NOT reward_cap_slot
+			  )
+			RETURNING claimed
+		), marked AS (
+			UPDATE claim_outbox
+			SET reward_cap_slot = true, updated_at = NOW()
+			WHERE user_id = $1 AND goal_id = $2 AND state = 'pending' AND EXISTS (SELECT 1 FROM slot)
+		)
+		SELECT claimed, true FROM slot
+		UNION ALL
+		SELECT claimed, false FROM reward_cap_counters
+		WHERE goal_id = $2 AND NOT EXISTS (SELECT 1 FROM slot)
+	`
+
+	var (
+		claimed int
+		taken   bool
+	)
+	if err := r.db.QueryRowContext(ctx, query, userID, goalID, limit).Scan(&claimed, &taken); err != nil {
+		return false, 0, errors.ErrDatabaseError("take reward cap slot", err)
+	}
+
+	return taken, claimed, nil
+}
+
+// ReleaseSlot clears the entry's slot and decrements the counter in one
+// statement.
+func (r *PostgresRewardCapRepository) ReleaseSlot(ctx context.Context, userID, goalID string) error {
+	query := `
+		WITH released AS (
+			UPDATE claim_outbox
+			SET reward_cap_slot = false, updated_at = NOW()
+			WHERE user_id = $1 AND goal_id = $2 AND state = 'pending' AND reward_cap_slot
+			RETURNING goal_id
+		)
+		UPDATE reward_cap_counters
+		SET claimed = claimed - 1, updated_at = NOW()
+		WHERE goal_id IN (SELECT goal_id FROM released)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, goalID); err != nil {
+		return errors.ErrDatabaseError("release reward cap slot", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeRewardCapSlot(t *testing.T) {
+	tests := []struct {
+		name        string
+		claimed     int
+		taken       bool
+		wantTaken   bool
+		wantClaimed int
+	}{
+		{name: "taken", claimed: 3, taken: true, wantTaken: true, wantClaimed: 3},
+		{name: "cap reached", claimed: 10, taken: false, wantTaken: false, wantClaimed: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			mock.ExpectExec(`INSERT INTO reward_cap_counters .+ ON CONFLICT \(goal_id\) DO NOTHING`).
+				WithArgs("skin").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`UPDATE reward_cap_counters\s+SET claimed = claimed \+ 1, updated_at = NOW\(\)\s+WHERE goal_id = \$2 AND claimed < \$3`+
+				`(.|\n)+UPDATE claim_outbox\s+SET reward_cap_slot = true`).
+				WithArgs("user-1", "skin", 10).
+				WillReturnRows(sqlmock.NewRows([]string{"claimed", "taken"}).AddRow(tt.claimed, tt.taken))
+
+			repo := NewPostgresRewardCapRepository(db)
+			taken, claimed, err := repo.TakeSlot(context.Background(), "user-1", "skin", 10)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTaken, taken)
+			assert.Equal(t, tt.wantClaimed, claimed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTakeRewardCapSlot_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`INSERT INTO reward_cap_counters`).WillReturnError(errors.New("connection refused"))
+
+	repo := NewPostgresRewardCapRepository(db)
+	_, _, err = repo.TakeSlot(context.Background(), "user-1", "skin", 10)
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
+
+func TestReleaseRewardCapSlot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE claim_outbox\s+SET reward_cap_slot = false, updated_at = NOW\(\)\s+`+
+		`WHERE user_id = \$1 AND goal_id = \$2 AND state = 'pending' AND reward_cap_slot`+
+		`(.|\n)+UPDATE reward_cap_counters\s+SET claimed = claimed - 1`).
+		WithArgs("user-1", "skin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresRewardCapRepository(db)
+	require.NoError(t, repo.ReleaseSlot(context.Background(), "user-1", "skin"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
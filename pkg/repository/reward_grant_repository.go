@@ -0,0 +1,47 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// RewardGrantRepository records the AGS IDs of a claimed goal's reward grant on
+// its progress row (user_goal_progress, migration 007).
+type RewardGrantRepository interface {
+	// RecordGrant stores the entitlement ID (ITEM rewards) or wallet ID (WALLET
+	// rewards) of the grant; an empty ID is stored as NULL.
+	RecordGrant(ctx context.Context, userID, goalID, entitlementID, walletID string) error
+}
+
+// PostgresRewardGrantRepository implements RewardGrantRepository on PostgreSQL.
+type PostgresRewardGrantRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRewardGrantRepository creates a new PostgreSQL reward grant repository.
+func NewPostgresRewardGrantRepository(db *sql.DB) *PostgresRewardGrantRepository {
+	return &PostgresRewardGrantRepository{db: db}
+}
+
+// RecordGrant only updates claimed rows, so a row reset by rotation in the
+// meantime does not get the IDs of an earlier claim. Rows of repeatable goals
+// are reset by the claim itself (last_claimed_at is set), so they match too.
+func (r *PostgresRewardGrantRepository) RecordGrant(ctx context.Context, userID, goalID, entitlementID, walletID string) error {
+	query := `
+		UPDATE user_goal_progress
+		SET reward_entitlement_id = NULLIF($3, ''), reward_wallet_id = NULLIF($4, '')
+		WHERE user_id = $1 AND goal_id = $2 AND (status = 'claimed' OR last_claimed_at IS NOT NULL)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, goalID, entitlementID, walletID); err != nil {
+		return errors.ErrDatabaseError("record reward grant", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE user_goal_progress\s+SET reward_entitlement_id = NULLIF\(\$3, ''\), reward_wallet_id = NULLIF\(\$4, ''\)\s+WHERE user_id = \$1 AND goal_id = \$2 AND \(status = 'claimed' OR last_claimed_at IS NOT NULL\)`).
+		WithArgs("user-1", "goal-1", "ent-1", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewPostgresRewardGrantRepository(db)
+	err = repo.RecordGrant(context.Background(), "user-1", "goal-1", "ent-1", "")
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordGrant_DatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresRewardGrantRepository(db)
+	err = repo.RecordGrant(context.Background(), "user-1", "goal-1", "", "wallet-1")
+
+	assert.ErrorContains(t, err, "record reward grant")
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// StepProgressRepository stores the step progress of multi-step goals in
+// user_goal_progress.step_progress (migration 017).
+//
+// BatchUpsertProgressWithCOPY (extend-challenge-common) writes a single progress
+// value per row, so step goals are written through this repository instead.
+type StepProgressRepository interface {
+	// ApplyStepProgress locks the existing rows among keys and passes each to
+	// apply, which updates its Steps and Status in place and returns whether the
+	// row should be written. Written rows get progress set to the first step and
+	// completed_at set when they become completed (cleared when they no longer
+	// are, keeping it in first_completed_at), in the same transaction. Missing rows are skipped. It returns the
+	// keys written.
+	ApplyStepProgress(
+		ctx context.Context,
+		namespace string,
+		keys []UserGoalKey,
+		apply func(row *StepProgressRow) bool,
+	) ([]UserGoalKey, error)
+
+	// GetStepProgress returns the step values of the user's goals among goalIDs,
+	// by goal ID. Goals without a row or without step progress are absent.
+	GetStepProgress(ctx context.Context, userID string, goalIDs []string) (map[string][]int, error)
+}
+
+// StepProgressRow is a locked progress row of a step goal.
+type StepProgressRow struct {
+	UserGoalKey
+	Status   domain.GoalStatus
+	IsActive bool
+	// Steps holds the stored step values, in config order; empty before the
+	// first step update.
+	Steps []int
+}
+
+// PostgresStepProgressRepository implements StepProgressRepository on PostgreSQL.
+type PostgresStepProgressRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresStepProgressRepository creates a new PostgreSQL step progress repository.
+func NewPostgresStepProgressRepository(db *sql.DB) *PostgresStepProgressRepository {
+	return &PostgresStepProgressRepository{db: db}
+}
+
+// ApplyStepProgress locks the rows in (user_id, goal_id) order, so concurrent
+// batches touching the same rows cannot deadlock.
+func (r *PostgresStepProgressRepository) ApplyStepProgress(
+	ctx context.Context,
+	namespace string,
+	keys []UserGoalKey,
+	apply func(row *StepProgressRow) bool,
+) ([]UserGoalKey, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]string, len(keys))
+	goalIDs := make([]string, len(keys))
+	for i, key := range keys {
+		userIDs[i] = key.UserID
+		goalIDs[i] = key.GoalID
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("begin step progress", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := lockStepProgress(ctx, tx, namespace, userIDs, goalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	update := `
+		UPDATE user_goal_progress
+		SET progress = $3,
+		    step_progress = $4::jsonb,
+		    status = $5,
+		    completed_at = CASE
+		        WHEN $5 = 'completed' THEN COALESCE(completed_at, NOW())
+		        ELSE NULL
+		    END,
+		    first_completed_at = COALESCE(first_completed_at, completed_at),
+		    updated_at = NOW()
+		WHERE user_id = $1 AND goal_id = $2
+	`
+
+	var written []UserGoalKey
+	for _, row := range rows {
+		if !apply(row) {
+			continue
+		}
+
+		steps, err := json.Marshal(row.Steps)
+		if err != nil {
+			return nil, errors.ErrDatabaseError("encode step progress", err)
+		}
+		progress := 0
+		if len(row.Steps) > 0 {
+			progress = row.Steps[0]
+		}
+
+		if _, err := tx.ExecContext(ctx, update, row.UserID, row.GoalID, progress, string(steps), string(row.Status)); err != nil {
+			return nil, errors.ErrDatabaseError("write step progress", err)
+		}
+		written = append(written, row.UserGoalKey)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.ErrDatabaseError("commit step progress", err)
+	}
+
+	return written, nil
+}
+
+// lockStepProgress selects the existing rows of the user goals FOR UPDATE.
+func lockStepProgress(ctx context.Context, tx *sql.Tx, namespace string, userIDs, goalIDs []string) ([]*StepProgressRow, error) {
+	query := `
+		SELECT ugp.user_id, ugp.goal_id, ugp.status, ugp.is_active, ugp.step_progress
+		FROM user_goal_progress AS ugp
+		JOIN (
+			SELECT UNNEST($2::text[]) AS user_id, UNNEST($3::text[]) AS goal_id
+		) AS data ON ugp.user_id = data.user_id AND ugp.goal_id = data.goal_id
+		WHERE ugp.namespace = $1
+		ORDER BY ugp.user_id, ugp.goal_id
+		FOR UPDATE OF ugp
+	`
+
+	result, err := tx.QueryContext(ctx, query, namespace, pq.Array(userIDs), pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("lock step progress rows", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	var rows []*StepProgressRow
+	for result.Next() {
+		row := &StepProgressRow{}
+		var steps []byte
+		if err := result.Scan(&row.UserID, &row.GoalID, &row.Status, &row.IsActive, &steps); err != nil {
+			return nil, errors.ErrDatabaseError("scan step progress row", err)
+		}
+		if len(steps) > 0 {
+			if err := json.Unmarshal(steps, &row.Steps); err != nil {
+				return nil, errors.ErrDatabaseError("decode step progress", err)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate step progress rows", err)
+	}
+
+	return rows, nil
+}
+
+// GetStepProgress reads the step_progress column of the user's rows.
+func (r *PostgresStepProgressRepository) GetStepProgress(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string][]int, error) {
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT goal_id, step_progress
+		FROM user_goal_progress
+		WHERE user_id = $1 AND goal_id = ANY($2) AND step_progress IS NOT NULL
+	`
+
+	result, err := r.db.QueryContext(ctx, query, userID, pq.Array(goalIDs))
+	if err != nil {
+		return nil, errors.ErrDatabaseError("get step progress", err)
+	}
+	defer func() { _ = result.Close() }()
+
+	progress := make(map[string][]int)
+	for result.Next() {
+		var goalID string
+		var steps []byte
+		if err := result.Scan(&goalID, &steps); err != nil {
+			return nil, errors.ErrDatabaseError("scan step progress", err)
+		}
+		var values []int
+		if err := json.Unmarshal(steps, &values); err != nil {
+			return nil, errors.ErrDatabaseError("decode step progress", err)
+		}
+		progress[goalID] = values
+	}
+
+	if err := result.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("iterate step progress", err)
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyStepProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	keys := []UserGoalKey{
+		{UserID: "user-1", GoalID: "grind"},
+		{UserID: "user-2", GoalID: "grind"},
+		{UserID: "user-3", GoalID: "grind"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT ugp.user_id, ugp.goal_id, ugp.status, ugp.is_active, ugp.step_progress(.|\n)+ORDER BY ugp.user_id, ugp.goal_id\s+FOR UPDATE OF ugp`).
+		WithArgs("ns", `{"user-1","user-2","user-3"}`, `{"grind","grind","grind"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status", "is_active", "step_progress"}).
+			AddRow("user-1", "grind", "in_progress", true, []byte(`[2,500]`)).
+			AddRow("user-2", "grind", "claimed", true, nil))
+	mock.ExpectExec(`UPDATE user_goal_progress\s+SET progress = \$3,\s+step_progress = \$4::jsonb(.|\n)+`+
+		`WHEN \$5 = 'completed' THEN COALESCE\(completed_at, NOW\(\)\)(.|\n)+first_completed_at = COALESCE\(first_completed_at, completed_at\)`).
+		WithArgs("user-1", "grind", 3, "[3,10000]", "completed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var seen []StepProgressRow
+	repo := NewPostgresStepProgressRepository(db)
+	written, err := repo.ApplyStepProgress(context.Background(), "ns", keys, func(row *StepProgressRow) bool {
+		seen = append(seen, *row)
+		if row.Status == domain.GoalStatusClaimed {
+			return false
+		}
+		row.Steps = []int{3, 10000}
+		row.Status = domain.GoalStatusCompleted
+		return true
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []UserGoalKey{{UserID: "user-1", GoalID: "grind"}}, written)
+	require.Len(t, seen, 2)
+	assert.Equal(t, []int{2, 500}, seen[0].Steps)
+	assert.True(t, seen[0].IsActive)
+	assert.Nil(t, seen[1].Steps)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyStepProgress_NoKeys(t *testing.T) {
+	repo := NewPostgresStepProgressRepository(nil)
+	written, err := repo.ApplyStepProgress(context.Background(), "ns", nil, func(*StepProgressRow) bool { return true })
+
+	require.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+func TestApplyStepProgress_WriteErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT ugp.user_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status", "is_active", "step_progress"}).
+			AddRow("user-1", "grind", "in_progress", true, nil))
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresStepProgressRepository(db)
+	_, err = repo.ApplyStepProgress(context.Background(), "ns", []UserGoalKey{{UserID: "user-1", GoalID: "grind"}},
+		func(row *StepProgressRow) bool {
+			row.Steps = []int{1, 0}
+			return true
+		})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStepProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id, step_progress\s+FROM user_goal_progress\s+WHERE user_id = \$1 AND goal_id = ANY\(\$2\) AND step_progress IS NOT NULL`).
+		WithArgs("user-1", `{"grind","marathon"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "step_progress"}).AddRow("grind", []byte(`[3,250]`)))
+
+	repo := NewPostgresStepProgressRepository(db)
+	progress, err := repo.GetStepProgress(context.Background(), "user-1", []string{"grind", "marathon"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int{"grind": {3, 250}}, progress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStepProgress_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT goal_id, step_progress`).WillReturnError(errors.New("connection reset"))
+
+	repo := NewPostgresStepProgressRepository(db)
+	_, err = repo.GetStepProgress(context.Background(), "user-1", []string{"grind"})
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, commonErrors.ErrCodeDatabaseError, challengeErr.Code)
+}
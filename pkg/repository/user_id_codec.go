@@ -0,0 +1,377 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+)
+
+// User ID codec modes, the values of USER_ID_CODEC.
+const (
+	// UserIDCodecHMAC stores HMAC-SHA256(key, userID): not reversible.
+	UserIDCodecHMAC = "hmac"
+	// UserIDCodecAESGCM stores userID encrypted with AES-256-GCM under a nonce
+	// derived from it, so the same ID always encodes the same way: reversible.
+	UserIDCodecAESGCM = "aes-gcm"
+)
+
+// encodedUserIDPrefix starts every encoded user ID, followed by the key ID, a
+// colon and the base64url payload. AGS user IDs never start with it.
+const encodedUserIDPrefix = "enc:"
+
+// maxRememberedUserIDs bounds the users UserIDMapper remembers as moved under
+// the current key; past it the set is cleared.
+const maxRememberedUserIDs = 100000
+
+// UserIDCodec pseudonymizes the user IDs stored in user_goal_progress. Encoding
+// is deterministic, so lookups by the encoded ID still use the user_id indexes.
+// IDs are encoded with the current key; the previous keys are kept so rows
+// written before a key rotation can still be found (see UserIDMapper).
+type UserIDCodec struct {
+	mode     string
+	current  *userIDKey
+	previous []*userIDKey
+	// readPlain also finds rows still stored under the plain ID, while the
+	// -encode-user-ids backfill has not converted them yet.
+	readPlain bool
+}
+
+type userIDKey struct {
+	id   string
+	mac  []byte
+	aead cipher.AEAD
+}
+
+// NewUserIDCodec creates a codec in mode (UserIDCodecHMAC or UserIDCodecAESGCM).
+// Keys are "<key ID>:<base64 key of at least 32 bytes>"; key IDs are short
+// alphanumeric names stored with every encoded ID, e.g. "k1".
+func NewUserIDCodec(mode, currentKey string, previousKeys []string, readPlain bool) (*UserIDCodec, error) {
+	if mode != UserIDCodecHMAC && mode != UserIDCodecAESGCM {
+		return nil, fmt.Errorf("unknown user ID codec %q (must be %q or %q)", mode, UserIDCodecHMAC, UserIDCodecAESGCM)
+	}
+
+	current, err := parseUserIDKey(currentKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current key: %w", err)
+	}
+	codec := &UserIDCodec{mode: mode, current: current, readPlain: readPlain}
+
+	seen := map[string]bool{current.id: true}
+	for _, value := range previousKeys {
+		key, err := parseUserIDKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous key: %w", err)
+		}
+		if seen[key.id] {
+			return nil, fmt.Errorf("key ID %q is used twice", key.id)
+		}
+		seen[key.id] = true
+		codec.previous = append(codec.previous, key)
+	}
+
+	return codec, nil
+}
+
+// NewUserIDCodecFromEnv creates a codec configured by:
+//   - USER_ID_CODEC: "hmac" or "aes-gcm"; empty, the default, returns nil and
+//     user IDs are stored as they are
+//   - USER_ID_CODEC_KEY: the current key
+//   - USER_ID_CODEC_PREVIOUS_KEYS: comma-separated keys rotated out
+//   - USER_ID_CODEC_READ_PLAIN: "true" while rows stored before the codec was
+//     enabled have not been converted
+func NewUserIDCodecFromEnv() (*UserIDCodec, error) {
+	mode := strings.ToLower(common.GetEnv("USER_ID_CODEC", ""))
+	if mode == "" {
+		return nil, nil
+	}
+
+	var previous []string
+	for _, key := range strings.Split(common.GetEnv("USER_ID_CODEC_PREVIOUS_KEYS", ""), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			previous = append(previous, key)
+		}
+	}
+	readPlain := strings.ToLower(common.GetEnv("USER_ID_CODEC_READ_PLAIN", "false")) == "true"
+
+	return NewUserIDCodec(mode, common.GetEnv("USER_ID_CODEC_KEY", ""), previous, readPlain)
+}
+
+func parseUserIDKey(value string) (*userIDKey, error) {
+	id, encoded, ok := strings.Cut(value, ":")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("key must be <key ID>:<base64 key>")
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return nil, fmt.Errorf("key ID %q must be alphanumeric", id)
+		}
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key %q is not base64: %w", id, err)
+	}
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("key %q is shorter than 32 bytes", id)
+	}
+
+	// Separate subkeys for the MAC (and the nonces) and for the cipher
+	block, err := aes.NewCipher(deriveUserIDSubkey(secret, "encrypt"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userIDKey{id: id, mac: deriveUserIDSubkey(secret, "mac"), aead: aead}, nil
+}
+
+func deriveUserIDSubkey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("user-id-codec:" + purpose))
+	return mac.Sum(nil)
+}
+
+// Reversible reports whether encoded IDs can be decoded (AES-GCM).
+func (c *UserIDCodec) Reversible() bool {
+	return c.mode == UserIDCodecAESGCM
+}
+
+// Encode returns userID encoded with the current key. Values that are already
+// encoded, e.g. user IDs read back from the table, are returned unchanged.
+func (c *UserIDCodec) Encode(userID string) string {
+	if isEncodedUserID(userID) {
+		return userID
+	}
+	return c.encode(c.current, userID)
+}
+
+func (c *UserIDCodec) encode(key *userIDKey, userID string) string {
+	mac := hmac.New(sha256.New, key.mac)
+	mac.Write([]byte(userID))
+	sum := mac.Sum(nil)
+
+	payload := sum
+	if c.mode == UserIDCodecAESGCM {
+		nonce := sum[:key.aead.NonceSize()]
+		payload = key.aead.Seal(append([]byte{}, nonce...), nonce, []byte(userID), []byte(key.id))
+	}
+	return encodedUserIDPrefix + key.id + ":" + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// Decode returns the plain user ID of a stored one. Plain stored IDs are
+// returned as they are. It reports false for IDs that cannot be decoded: every
+// ID encoded with HMAC, and IDs encoded with an unknown key.
+func (c *UserIDCodec) Decode(stored string) (string, bool) {
+	if !isEncodedUserID(stored) {
+		return stored, true
+	}
+	if !c.Reversible() {
+		return "", false
+	}
+
+	keyID, encoded, _ := strings.Cut(strings.TrimPrefix(stored, encodedUserIDPrefix), ":")
+	key := c.key(keyID)
+	if key == nil {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) < key.aead.NonceSize() {
+		return "", false
+	}
+	nonce := payload[:key.aead.NonceSize()]
+	plain, err := key.aead.Open(nil, nonce, payload[len(nonce):], []byte(key.id))
+	if err != nil {
+		return "", false
+	}
+	return string(plain), true
+}
+
+// Reencode returns the ID a stored ID is converted to by the backfill, and
+// false when it is left as it is: IDs already under the current key, and IDs
+// of previous HMAC keys, which cannot be decoded. Those are moved under the
+// current key by UserIDMapper when the player is next seen.
+func (c *UserIDCodec) Reencode(stored string) (string, bool) {
+	if c.isCurrent(stored) {
+		return "", false
+	}
+	plain, ok := c.Decode(stored)
+	if !ok {
+		return "", false
+	}
+	return c.encode(c.current, plain), true
+}
+
+// legacyIDs returns the other IDs rows of userID may be stored under: its
+// encodings with the previous keys, and userID itself with readPlain.
+func (c *UserIDCodec) legacyIDs(userID string) []string {
+	if isEncodedUserID(userID) {
+		return nil
+	}
+
+	var ids []string
+	for _, key := range c.previous {
+		ids = append(ids, c.encode(key, userID))
+	}
+	if c.readPlain {
+		ids = append(ids, userID)
+	}
+	return ids
+}
+
+// isCurrent reports whether stored is encoded with the current key.
+func (c *UserIDCodec) isCurrent(stored string) bool {
+	return strings.HasPrefix(stored, encodedUserIDPrefix+c.current.id+":")
+}
+
+func (c *UserIDCodec) key(id string) *userIDKey {
+	if c.current.id == id {
+		return c.current
+	}
+	for _, key := range c.previous {
+		if key.id == id {
+			return key
+		}
+	}
+	return nil
+}
+
+func isEncodedUserID(userID string) bool {
+	return strings.HasPrefix(userID, encodedUserIDPrefix)
+}
+
+// UserIDMapper encodes the user IDs of repository calls with a UserIDCodec.
+// While the codec has previous keys (or reads plain IDs), the first call for a
+// user moves the rows stored under its legacy IDs under the current one, so
+// reads find them and writes never split a user across two IDs: dual-read,
+// single-write. A row that exists under both IDs keeps the current one.
+type UserIDMapper struct {
+	codec *UserIDCodec
+	rekey UserIDRekeyRepository
+
+	mu    sync.Mutex
+	moved map[string]struct{}
+}
+
+// NewUserIDMapper creates a mapper moving legacy rows with rekey.
+func NewUserIDMapper(codec *UserIDCodec, rekey UserIDRekeyRepository) *UserIDMapper {
+	return &UserIDMapper{codec: codec, rekey: rekey, moved: make(map[string]struct{})}
+}
+
+// Codec returns the mapper's codec.
+func (m *UserIDMapper) Codec() *UserIDCodec {
+	return m.codec
+}
+
+// Encode returns the stored ID of userID, moving its legacy rows first.
+func (m *UserIDMapper) Encode(ctx context.Context, userID string) (string, error) {
+	encoded := m.codec.Encode(userID)
+
+	legacy := m.codec.legacyIDs(userID)
+	if len(legacy) == 0 {
+		return encoded, nil
+	}
+
+	m.mu.Lock()
+	_, done := m.moved[encoded]
+	m.mu.Unlock()
+	if done {
+		return encoded, nil
+	}
+
+	if _, err := m.rekey.MoveUserRows(ctx, encoded, legacy); err != nil {
+		return "", errors.ErrDatabaseError("move user rows to the current user ID key", err)
+	}
+
+	m.mu.Lock()
+	if len(m.moved) >= maxRememberedUserIDs {
+		m.moved = make(map[string]struct{})
+	}
+	m.moved[encoded] = struct{}{}
+	m.mu.Unlock()
+
+	return encoded, nil
+}
+
+// EncodeAll encodes userIDs in order and returns the plain ID of each encoded one.
+func (m *UserIDMapper) EncodeAll(ctx context.Context, userIDs []string) ([]string, map[string]string, error) {
+	encoded := make([]string, len(userIDs))
+	plain := make(map[string]string, len(userIDs))
+	for i, userID := range userIDs {
+		id, err := m.Encode(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded[i] = id
+		plain[id] = userID
+	}
+	return encoded, plain, nil
+}
+
+// Decode returns the plain ID of a stored one that was not looked up by its
+// plain ID, or the stored ID when the codec cannot decode it.
+func (m *UserIDMapper) Decode(stored string) string {
+	if plain, ok := m.codec.Decode(stored); ok {
+		return plain
+	}
+	return stored
+}
+
+// UserIDBackfillResult counts the stored user IDs seen by EncodeStoredUserIDs.
+type UserIDBackfillResult struct {
+	Users     int `json:"users"`
+	Converted int `json:"converted"`
+	Rows      int `json:"rows"`
+	Skipped   int `json:"skipped"`
+}
+
+// EncodeStoredUserIDs converts the stored user IDs of user_goal_progress to the
+// codec's current key, batchSize users at a time: plain IDs and IDs encoded with
+// a previous reversible key. IDs it cannot convert are counted as skipped. It can
+// be re-run; converted users are not seen again.
+func EncodeStoredUserIDs(ctx context.Context, codec *UserIDCodec, rekey UserIDRekeyRepository, batchSize int) (UserIDBackfillResult, error) {
+	var result UserIDBackfillResult
+	after := ""
+	for {
+		stored, err := rekey.ListStoredUserIDs(ctx, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, userID := range stored {
+			result.Users++
+			encoded, ok := codec.Reencode(userID)
+			if !ok {
+				if !codec.isCurrent(userID) {
+					result.Skipped++
+				}
+				continue
+			}
+			moved, err := rekey.MoveUserRows(ctx, encoded, []string{userID})
+			if err != nil {
+				return result, err
+			}
+			result.Converted++
+			result.Rows += moved
+		}
+		if len(stored) < batchSize {
+			return result, nil
+		}
+		after = stored[len(stored)-1]
+	}
+}
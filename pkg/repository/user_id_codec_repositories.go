@@ -0,0 +1,746 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// The decorators below store user IDs encoded by a UserIDMapper in
+// user_goal_progress, and in the goal_selection_events, goal_admin_audit and
+// event_outbox rows written with it. Callers keep passing and getting plain
+// user IDs: rows read by user ID get it back, rows of namespace-wide reads
+// are decoded when the codec is reversible and keep the stored ID otherwise.
+// The other tables (claims, freezes, failed grants, jobs) store plain IDs,
+// which AGS grants and admin lookups need. Each constructor returns next
+// unchanged for a nil mapper.
+
+// NewUserIDCodecGoalRepository wraps next, encoding user IDs with ids. The
+// TxRepository returned by BeginTx is wrapped too.
+func NewUserIDCodecGoalRepository(next commonRepo.GoalRepository, ids *UserIDMapper) commonRepo.GoalRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecGoalRepository{next: next, ids: ids}
+}
+
+type userIDCodecGoalRepository struct {
+	next commonRepo.GoalRepository
+	ids  *UserIDMapper
+}
+
+// withUserID sets the user ID of rows read by it back to the plain one.
+func withUserID(progresses []*domain.UserGoalProgress, userID string) []*domain.UserGoalProgress {
+	for _, progress := range progresses {
+		if progress != nil {
+			progress.UserID = userID
+		}
+	}
+	return progresses
+}
+
+// encodeProgress returns copies of progresses with encoded user IDs; the
+// caller's rows are left plain.
+func encodeProgress(ctx context.Context, ids *UserIDMapper, progresses []*domain.UserGoalProgress) ([]*domain.UserGoalProgress, error) {
+	encoded := make([]*domain.UserGoalProgress, len(progresses))
+	for i, progress := range progresses {
+		if progress == nil {
+			continue
+		}
+		userID, err := ids.Encode(ctx, progress.UserID)
+		if err != nil {
+			return nil, err
+		}
+		row := *progress
+		row.UserID = userID
+		encoded[i] = &row
+	}
+	return encoded, nil
+}
+
+// encodeCopyRows returns copies of rows with encoded user IDs, and the plain
+// ID of each encoded one.
+func encodeCopyRows(ctx context.Context, ids *UserIDMapper, rows []commonRepo.CopyRow) ([]commonRepo.CopyRow, map[string]string, error) {
+	encoded := make([]commonRepo.CopyRow, len(rows))
+	plain := make(map[string]string)
+	for i, row := range rows {
+		userID, err := ids.Encode(ctx, row.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		plain[userID] = row.UserID
+		row.UserID = userID
+		encoded[i] = row
+	}
+	return encoded, plain, nil
+}
+
+// decodeKeys sets the user IDs of keys back to the plain IDs they were encoded from.
+func decodeKeys(keys []UserGoalKey, plain map[string]string) []UserGoalKey {
+	for i := range keys {
+		if userID, ok := plain[keys[i].UserID]; ok {
+			keys[i].UserID = userID
+		}
+	}
+	return keys
+}
+
+func (r *userIDCodecGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.GetProgress(ctx, encoded, goalID)
+	if progress != nil {
+		progress.UserID = userID
+	}
+	return progress, err
+}
+
+func (r *userIDCodecGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.GetUserProgress(ctx, encoded, activeOnly)
+	return withUserID(progress, userID), err
+}
+
+func (r *userIDCodecGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.GetChallengeProgress(ctx, encoded, challengeID, activeOnly)
+	return withUserID(progress, userID), err
+}
+
+func (r *userIDCodecGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, []*domain.UserGoalProgress{progress})
+	if err != nil {
+		return err
+	}
+	return r.next.UpsertProgress(ctx, encoded[0])
+}
+
+func (r *userIDCodecGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, updates)
+	if err != nil {
+		return err
+	}
+	return r.next.BatchUpsertProgress(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
+	encoded, _, err := encodeCopyRows(ctx, r.ids, rows)
+	if err != nil {
+		return err
+	}
+	return r.next.BatchUpsertProgressWithCOPY(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.next.MarkAsClaimed(ctx, encoded, goalID)
+}
+
+// BeginTx returns a transaction that encodes user IDs too.
+func (r *userIDCodecGoalRepository) BeginTx(ctx context.Context) (commonRepo.TxRepository, error) {
+	tx, err := r.next.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &userIDCodecTxRepository{
+		userIDCodecGoalRepository: userIDCodecGoalRepository{next: tx, ids: r.ids},
+		tx:                        tx,
+	}, nil
+}
+
+func (r *userIDCodecGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.GetGoalsByIDs(ctx, encoded, goalIDs)
+	return withUserID(progress, userID), err
+}
+
+func (r *userIDCodecGoalRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, progresses)
+	if err != nil {
+		return err
+	}
+	return r.next.BulkInsert(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, progresses)
+	if err != nil {
+		return err
+	}
+	return r.next.BulkInsertWithCOPY(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, []*domain.UserGoalProgress{progress})
+	if err != nil {
+		return err
+	}
+	return r.next.UpsertGoalActive(ctx, encoded[0])
+}
+
+func (r *userIDCodecGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
+	encoded, err := encodeProgress(ctx, r.ids, progresses)
+	if err != nil {
+		return err
+	}
+	return r.next.BatchUpsertGoalActive(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.next.GetUserGoalCount(ctx, encoded)
+}
+
+func (r *userIDCodecGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.GetActiveGoals(ctx, encoded)
+	return withUserID(progress, userID), err
+}
+
+// userIDCodecTxRepository decorates a TxRepository.
+type userIDCodecTxRepository struct {
+	userIDCodecGoalRepository
+	tx commonRepo.TxRepository
+}
+
+func (r *userIDCodecTxRepository) GetProgressForUpdate(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.tx.GetProgressForUpdate(ctx, encoded, goalID)
+	if progress != nil {
+		progress.UserID = userID
+	}
+	return progress, err
+}
+
+func (r *userIDCodecTxRepository) Commit() error {
+	return r.tx.Commit()
+}
+
+func (r *userIDCodecTxRepository) Rollback() error {
+	return r.tx.Rollback()
+}
+
+// NewUserIDCodecProgressQueryRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecProgressQueryRepository(next ProgressQueryRepository, ids *UserIDMapper) ProgressQueryRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecProgressQueries{ProgressQueryRepository: next, ids: ids}
+}
+
+// userIDCodecProgressQueries decorates the per-user reads; the namespace-wide
+// counts are passed through.
+type userIDCodecProgressQueries struct {
+	ProgressQueryRepository
+	ids *UserIDMapper
+}
+
+func (r *userIDCodecProgressQueries) GetUserProgressPage(ctx context.Context, userID string, activeOnly bool, afterGoalID string, limit int) ([]*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.ProgressQueryRepository.GetUserProgressPage(ctx, encoded, activeOnly, afterGoalID, limit)
+	return withUserID(progress, userID), err
+}
+
+func (r *userIDCodecProgressQueries) GetUserProgressSnapshot(ctx context.Context, userID, challengeID string, activeOnly bool) (*ProgressSnapshot, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := r.ProgressQueryRepository.GetUserProgressSnapshot(ctx, encoded, challengeID, activeOnly)
+	if snapshot != nil {
+		withUserID(snapshot.Rows, userID)
+	}
+	return snapshot, err
+}
+
+func (r *userIDCodecProgressQueries) GetUserProgressSummary(ctx context.Context, userID, namespace string, activeOnly bool) ([]*ProgressStatusCount, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ProgressQueryRepository.GetUserProgressSummary(ctx, encoded, namespace, activeOnly)
+}
+
+func (r *userIDCodecProgressQueries) CountByStatus(ctx context.Context, userID, namespace string, statuses []domain.GoalStatus) (map[domain.GoalStatus]int, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ProgressQueryRepository.CountByStatus(ctx, encoded, namespace, statuses)
+}
+
+func (r *userIDCodecProgressQueries) GetActiveGoalStatuses(ctx context.Context, namespace string, userIDs, goalIDs []string) (map[UserGoalKey]domain.GoalStatus, error) {
+	encoded, plain, err := r.ids.EncodeAll(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := r.ProgressQueryRepository.GetActiveGoalStatuses(ctx, namespace, encoded, goalIDs)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make(map[UserGoalKey]domain.GoalStatus, len(statuses))
+	for key, status := range statuses {
+		decoded[UserGoalKey{UserID: plain[key.UserID], GoalID: key.GoalID}] = status
+	}
+	return decoded, nil
+}
+
+func (r *userIDCodecProgressQueries) GetActiveGoalIDsForUser(ctx context.Context, userID string, goalIDs []string) (map[string]bool, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ProgressQueryRepository.GetActiveGoalIDsForUser(ctx, encoded, goalIDs)
+}
+
+func (r *userIDCodecProgressQueries) GetFirstCompletions(ctx context.Context, userID string, goalIDs []string) (map[string]time.Time, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ProgressQueryRepository.GetFirstCompletions(ctx, encoded, goalIDs)
+}
+
+func (r *userIDCodecProgressQueries) GetChallengeMismatches(ctx context.Context, namespace string, goalChallenges map[string]string, limit int) ([]*ChallengeMismatch, error) {
+	mismatches, err := r.ProgressQueryRepository.GetChallengeMismatches(ctx, namespace, goalChallenges, limit)
+	for _, mismatch := range mismatches {
+		mismatch.UserID = r.ids.Decode(mismatch.UserID)
+	}
+	return mismatches, err
+}
+
+// GetUsersReachingTargets returns stored IDs that cannot be decoded as they
+// are; the other decorators pass them through unchanged.
+func (r *userIDCodecProgressQueries) GetUsersReachingTargets(ctx context.Context, namespace string, goalTargets map[string]int, limit int) ([]string, error) {
+	userIDs, err := r.ProgressQueryRepository.GetUsersReachingTargets(ctx, namespace, goalTargets, limit)
+	for i, userID := range userIDs {
+		userIDs[i] = r.ids.Decode(userID)
+	}
+	return userIDs, err
+}
+
+// NewUserIDCodecActivationSourceRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecActivationSourceRepository(next ActivationSourceRepository, ids *UserIDMapper) ActivationSourceRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecActivationSources{next: next, ids: ids}
+}
+
+type userIDCodecActivationSources struct {
+	next ActivationSourceRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecActivationSources) SetActivationSource(ctx context.Context, userID string, goalIDs []string, source string) error {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.next.SetActivationSource(ctx, encoded, goalIDs, source)
+}
+
+func (r *userIDCodecActivationSources) GetActivationSources(ctx context.Context, userID string, goalIDs []string) (map[string]string, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.next.GetActivationSources(ctx, encoded, goalIDs)
+}
+
+// NewUserIDCodecGoalSelectionRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecGoalSelectionRepository(next GoalSelectionRepository, ids *UserIDMapper) GoalSelectionRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecGoalSelections{GoalSelectionRepository: next, ids: ids}
+}
+
+type userIDCodecGoalSelections struct {
+	GoalSelectionRepository
+	ids *UserIDMapper
+}
+
+func (r *userIDCodecGoalSelections) ApplySelection(ctx context.Context, selection *GoalSelection) (int, error) {
+	encoded, err := r.ids.Encode(ctx, selection.UserID)
+	if err != nil {
+		return 0, err
+	}
+	stored := *selection
+	stored.UserID = encoded
+	return r.GoalSelectionRepository.ApplySelection(ctx, &stored)
+}
+
+func (r *userIDCodecGoalSelections) GetSelectionHistory(ctx context.Context, userID, namespace string, limit int) ([]*GoalSelectionEvent, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := r.GoalSelectionRepository.GetSelectionHistory(ctx, encoded, namespace, limit)
+	for _, event := range events {
+		event.UserID = userID
+	}
+	return events, err
+}
+
+// NewUserIDCodecProgressInsertRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecProgressInsertRepository(next ProgressInsertRepository, ids *UserIDMapper) ProgressInsertRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecProgressInserts{next: next, ids: ids}
+}
+
+type userIDCodecProgressInserts struct {
+	next ProgressInsertRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecProgressInserts) BulkInsertReturningConflicts(ctx context.Context, progresses []*domain.UserGoalProgress) (*BulkInsertResult, error) {
+	encoded, err := encodeProgress(ctx, r.ids, progresses)
+	if err != nil {
+		return nil, err
+	}
+	plain := make(map[string]string)
+	for i, progress := range encoded {
+		if progress != nil {
+			plain[progress.UserID] = progresses[i].UserID
+		}
+	}
+
+	result, err := r.next.BulkInsertReturningConflicts(ctx, encoded)
+	if result != nil {
+		decodeKeys(result.Inserted, plain)
+		decodeKeys(result.Existing, plain)
+		for i := range result.Failed {
+			if userID, ok := plain[result.Failed[i].UserID]; ok {
+				result.Failed[i].UserID = userID
+			}
+		}
+	}
+	return result, err
+}
+
+// NewUserIDCodecStepProgressRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecStepProgressRepository(next StepProgressRepository, ids *UserIDMapper) StepProgressRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecStepProgress{next: next, ids: ids}
+}
+
+type userIDCodecStepProgress struct {
+	next StepProgressRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecStepProgress) ApplyStepProgress(
+	ctx context.Context,
+	namespace string,
+	keys []UserGoalKey,
+	apply func(row *StepProgressRow) bool,
+) ([]UserGoalKey, error) {
+	encoded := make([]UserGoalKey, len(keys))
+	plain := make(map[string]string)
+	for i, key := range keys {
+		userID, err := r.ids.Encode(ctx, key.UserID)
+		if err != nil {
+			return nil, err
+		}
+		plain[userID] = key.UserID
+		encoded[i] = UserGoalKey{UserID: userID, GoalID: key.GoalID}
+	}
+
+	written, err := r.next.ApplyStepProgress(ctx, namespace, encoded, func(row *StepProgressRow) bool {
+		stored := row.UserID
+		row.UserID = plain[stored]
+		defer func() { row.UserID = stored }()
+		return apply(row)
+	})
+	return decodeKeys(written, plain), err
+}
+
+func (r *userIDCodecStepProgress) GetStepProgress(ctx context.Context, userID string, goalIDs []string) (map[string][]int, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.next.GetStepProgress(ctx, encoded, goalIDs)
+}
+
+// NewUserIDCodecRepeatableGoalRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecRepeatableGoalRepository(next RepeatableGoalRepository, ids *UserIDMapper) RepeatableGoalRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecRepeatableGoals{next: next, ids: ids}
+}
+
+type userIDCodecRepeatableGoals struct {
+	next RepeatableGoalRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecRepeatableGoals) ResetClaimed(ctx context.Context, userID, goalID string) (*RepeatState, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.next.ResetClaimed(ctx, encoded, goalID)
+}
+
+func (r *userIDCodecRepeatableGoals) GetRepeatStates(ctx context.Context, userID string, goalIDs []string) (map[string]RepeatState, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.next.GetRepeatStates(ctx, encoded, goalIDs)
+}
+
+// NewUserIDCodecRewardGrantRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecRewardGrantRepository(next RewardGrantRepository, ids *UserIDMapper) RewardGrantRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecRewardGrants{next: next, ids: ids}
+}
+
+type userIDCodecRewardGrants struct {
+	next RewardGrantRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecRewardGrants) RecordGrant(ctx context.Context, userID, goalID, entitlementID, walletID string) error {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.next.RecordGrant(ctx, encoded, goalID, entitlementID, walletID)
+}
+
+// NewUserIDCodecGoalAdminRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecGoalAdminRepository(next GoalAdminRepository, ids *UserIDMapper) GoalAdminRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecGoalAdmin{GoalAdminRepository: next, ids: ids}
+}
+
+type userIDCodecGoalAdmin struct {
+	GoalAdminRepository
+	ids *UserIDMapper
+}
+
+func (r *userIDCodecGoalAdmin) ForceComplete(
+	ctx context.Context,
+	completion *ForcedCompletion,
+	check func(current *domain.UserGoalProgress) error,
+) (*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, completion.UserID)
+	if err != nil {
+		return nil, err
+	}
+	stored := *completion
+	stored.UserID = encoded
+
+	progress, err := r.GoalAdminRepository.ForceComplete(ctx, &stored, func(current *domain.UserGoalProgress) error {
+		if current != nil {
+			plain := *current
+			plain.UserID = completion.UserID
+			current = &plain
+		}
+		return check(current)
+	})
+	if progress != nil {
+		progress.UserID = completion.UserID
+	}
+	return progress, err
+}
+
+func (r *userIDCodecGoalAdmin) RecomputeStatuses(
+	ctx context.Context,
+	recompute *StatusRecompute,
+	decide func(row *domain.UserGoalProgress) domain.GoalStatus,
+) ([]StatusTransition, error) {
+	encoded, err := r.ids.Encode(ctx, recompute.UserID)
+	if err != nil {
+		return nil, err
+	}
+	stored := *recompute
+	stored.UserID = encoded
+
+	return r.GoalAdminRepository.RecomputeStatuses(ctx, &stored, func(row *domain.UserGoalProgress) domain.GoalStatus {
+		plain := *row
+		plain.UserID = recompute.UserID
+		return decide(&plain)
+	})
+}
+
+// NewUserIDCodecBulkActivationRepository wraps next, encoding the user IDs of
+// new jobs with ids. Batches activate the stored IDs as they are.
+func NewUserIDCodecBulkActivationRepository(next BulkActivationRepository, ids *UserIDMapper) BulkActivationRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecBulkActivations{BulkActivationRepository: next, ids: ids}
+}
+
+type userIDCodecBulkActivations struct {
+	BulkActivationRepository
+	ids *UserIDMapper
+}
+
+func (r *userIDCodecBulkActivations) CreateBulkActivationJob(ctx context.Context, job *BulkActivationJob, userIDs []string) error {
+	encoded, _, err := r.ids.EncodeAll(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	return r.BulkActivationRepository.CreateBulkActivationJob(ctx, job, encoded)
+}
+
+func (r *userIDCodecBulkActivations) CountBulkActivation(ctx context.Context, goalID string, userIDs []string) (*BulkActivationCounts, error) {
+	encoded, _, err := r.ids.EncodeAll(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return r.BulkActivationRepository.CountBulkActivation(ctx, goalID, encoded)
+}
+
+// NewUserIDCodecInactiveProgressRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecInactiveProgressRepository(next InactiveProgressRepository, ids *UserIDMapper) InactiveProgressRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecInactiveProgress{next: next, ids: ids}
+}
+
+type userIDCodecInactiveProgress struct {
+	next InactiveProgressRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecInactiveProgress) ApplyInactiveProgress(ctx context.Context, namespace string, rows []commonRepo.CopyRow) ([]UserGoalKey, error) {
+	encoded, plain, err := encodeCopyRows(ctx, r.ids, rows)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := r.next.ApplyInactiveProgress(ctx, namespace, encoded)
+	return decodeKeys(keys, plain), err
+}
+
+// NewUserIDCodecLateProgressRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecLateProgressRepository(next LateProgressRepository, ids *UserIDMapper) LateProgressRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecLateProgress{next: next, ids: ids}
+}
+
+type userIDCodecLateProgress struct {
+	next LateProgressRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecLateProgress) ApplyLateProgress(ctx context.Context, namespace string, rows []commonRepo.CopyRow) ([]UserGoalKey, error) {
+	encoded, plain, err := encodeCopyRows(ctx, r.ids, rows)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := r.next.ApplyLateProgress(ctx, namespace, encoded)
+	return decodeKeys(keys, plain), err
+}
+
+// NewUserIDCodecEventOutboxRepository wraps next, encoding the user IDs of
+// enqueued events with ids. Published events get the plain ID back when the
+// codec is reversible, and the stored one otherwise, as do the events the
+// user_goal_progress trigger enqueues.
+func NewUserIDCodecEventOutboxRepository(next EventOutboxRepository, ids *UserIDMapper) EventOutboxRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecEventOutbox{next: next, ids: ids}
+}
+
+type userIDCodecEventOutbox struct {
+	next EventOutboxRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecEventOutbox) Enqueue(ctx context.Context, event *OutboxEvent) error {
+	encoded, err := r.ids.Encode(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	stored := *event
+	stored.UserID = encoded
+	return r.next.Enqueue(ctx, &stored)
+}
+
+func (r *userIDCodecEventOutbox) PublishPending(ctx context.Context, limit int, publish func(events []*OutboxEvent) error) (int, error) {
+	return r.next.PublishPending(ctx, limit, func(events []*OutboxEvent) error {
+		decoded := make([]*OutboxEvent, len(events))
+		for i, event := range events {
+			plain := *event
+			plain.UserID = r.ids.Decode(event.UserID)
+			decoded[i] = &plain
+		}
+		return publish(decoded)
+	})
+}
+
+// NewUserIDCodecGoalResetRepository wraps next, encoding user IDs with ids.
+func NewUserIDCodecGoalResetRepository(next GoalResetRepository, ids *UserIDMapper) GoalResetRepository {
+	if ids == nil {
+		return next
+	}
+	return &userIDCodecGoalReset{next: next, ids: ids}
+}
+
+type userIDCodecGoalReset struct {
+	next GoalResetRepository
+	ids  *UserIDMapper
+}
+
+func (r *userIDCodecGoalReset) DeactivateAndReset(ctx context.Context, userID string, goalID string) (*domain.UserGoalProgress, error) {
+	encoded, err := r.ids.Encode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.next.DeactivateAndReset(ctx, encoded, goalID)
+	if progress != nil {
+		progress.UserID = userID
+	}
+	return progress, err
+}
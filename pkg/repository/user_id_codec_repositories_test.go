@@ -0,0 +1,131 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserIDCodecGoalRepository_LooksUpByEncodedID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	codec := newTestUserIDCodec(t, UserIDCodecAESGCM, nil, false)
+	encoded := codec.Encode("user-1")
+	now := time.Now().UTC()
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs(encoded, "goal-1").
+		WillReturnRows(sqlmock.NewRows(instrumentedProgressColumns).
+			AddRow(encoded, "goal-1", "challenge-1", "ns", 3, "in_progress", nil, nil, now, now, true, now, nil, nil))
+
+	repo := NewUserIDCodecGoalRepository(commonRepo.NewPostgresGoalRepository(db), NewUserIDMapper(codec, &fakeUserIDRekey{}))
+	progress, err := repo.GetProgress(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", progress.UserID, "responses keep the plain ID")
+	assert.Equal(t, 3, progress.Progress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIDCodecGoalRepository_WritesEncodedIDWithoutChangingTheCaller(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, false)
+	mock.ExpectExec(`INSERT INTO user_goal_progress`).
+		WithArgs(codec.Encode("user-1"), "goal-1", "challenge-1", "ns", 1, domain.GoalStatusInProgress,
+			sqlmock.AnyArg(), true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	progress := &domain.UserGoalProgress{
+		UserID: "user-1", GoalID: "goal-1", ChallengeID: "challenge-1", Namespace: "ns",
+		Progress: 1, Status: domain.GoalStatusInProgress, IsActive: true,
+	}
+	repo := NewUserIDCodecGoalRepository(commonRepo.NewPostgresGoalRepository(db), NewUserIDMapper(codec, &fakeUserIDRekey{}))
+
+	require.NoError(t, repo.UpsertProgress(context.Background(), progress))
+	assert.Equal(t, "user-1", progress.UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIDCodecGoalRepository_MovesLegacyRowsBeforeTheLookup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// Rows written before the codec was enabled are found under the new ID
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, true)
+	encoded := codec.Encode("user-1")
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE user_goal_progress p`).WithArgs(encoded, `{"user-1"}`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE goal_selection_events`).WithArgs(encoded, `{"user-1"}`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT .+ FROM user_goal_progress`).WithArgs(encoded, "goal-1").WillReturnRows(sqlmock.NewRows(instrumentedProgressColumns))
+
+	mapper := NewUserIDMapper(codec, NewPostgresUserIDRekeyRepository(db))
+	repo := NewUserIDCodecGoalRepository(commonRepo.NewPostgresGoalRepository(db), mapper)
+	_, err = repo.GetProgress(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIDCodecProgressQueries_GetActiveGoalStatusesKeepsPlainKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, false)
+	user1, user2 := codec.Encode("user-1"), codec.Encode("user-2")
+	mock.ExpectQuery(`SELECT user_id, goal_id, status\s+FROM user_goal_progress`).
+		WithArgs("ns", `{"`+user1+`","`+user2+`"}`, `{"goal-1"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status"}).AddRow(user2, "goal-1", "completed"))
+
+	repo := NewUserIDCodecProgressQueryRepository(NewPostgresProgressQueryRepository(db), NewUserIDMapper(codec, &fakeUserIDRekey{}))
+	statuses, err := repo.GetActiveGoalStatuses(context.Background(), "ns", []string{"user-1", "user-2"}, []string{"goal-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[UserGoalKey]domain.GoalStatus{
+		{UserID: "user-2", GoalID: "goal-1"}: domain.GoalStatusCompleted,
+	}, statuses)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIDCodecRepositories_NilMapperPassesThrough(t *testing.T) {
+	goals := commonRepo.NewPostgresGoalRepository(nil)
+	queries := NewPostgresProgressQueryRepository(nil)
+
+	assert.Same(t, goals, NewUserIDCodecGoalRepository(goals, nil))
+	assert.Same(t, queries, NewUserIDCodecProgressQueryRepository(queries, nil))
+}
+
+func TestUserIDCodecGoalReset_ResetsByEncodedID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, false)
+	encoded := codec.Encode("user-1")
+	now := time.Now().UTC()
+	mock.ExpectQuery(`UPDATE user_goal_progress`).WithArgs(encoded, "goal-1").
+		WillReturnRows(sqlmock.NewRows(progressRowColumns).
+			AddRow(encoded, "goal-1", "challenge-1", "ns", 0, "not_started", nil, nil, now, now, false, now, nil, nil))
+
+	repo := NewUserIDCodecGoalResetRepository(NewPostgresGoalResetRepository(db), NewUserIDMapper(codec, &fakeUserIDRekey{}))
+	progress, err := repo.DeactivateAndReset(context.Background(), "user-1", "goal-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", progress.UserID, "responses keep the plain ID")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
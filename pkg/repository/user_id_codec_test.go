@@ -0,0 +1,218 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUserIDKey(id string, fill byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(fill), 32)))
+}
+
+func newTestUserIDCodec(t *testing.T, mode string, previous []string, readPlain bool) *UserIDCodec {
+	t.Helper()
+	codec, err := NewUserIDCodec(mode, testUserIDKey("k2", 'b'), previous, readPlain)
+	require.NoError(t, err)
+	return codec
+}
+
+// fakeUserIDRekey records the moves of UserIDMapper and EncodeStoredUserIDs.
+type fakeUserIDRekey struct {
+	stored []string
+	moves  map[string][]string
+	err    error
+}
+
+func (f *fakeUserIDRekey) MoveUserRows(_ context.Context, to string, from []string) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	if f.moves == nil {
+		f.moves = make(map[string][]string)
+	}
+	f.moves[to] = append(f.moves[to], from...)
+	return len(from), nil
+}
+
+func (f *fakeUserIDRekey) ListStoredUserIDs(_ context.Context, afterUserID string, limit int) ([]string, error) {
+	stored := append([]string{}, f.stored...)
+	sort.Strings(stored)
+	var ids []string
+	for _, id := range stored {
+		if id > afterUserID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func TestUserIDCodec_EncodeIsDeterministic(t *testing.T) {
+	for _, mode := range []string{UserIDCodecHMAC, UserIDCodecAESGCM} {
+		t.Run(mode, func(t *testing.T) {
+			codec := newTestUserIDCodec(t, mode, nil, false)
+
+			encoded := codec.Encode("user-1")
+
+			assert.Equal(t, encoded, codec.Encode("user-1"))
+			assert.NotEqual(t, encoded, codec.Encode("user-2"))
+			assert.True(t, strings.HasPrefix(encoded, "enc:k2:"))
+			assert.NotContains(t, encoded, "user-1")
+			assert.Equal(t, encoded, codec.Encode(encoded), "already encoded IDs are not encoded twice")
+		})
+	}
+}
+
+func TestUserIDCodec_AESGCMRoundTrip(t *testing.T) {
+	codec := newTestUserIDCodec(t, UserIDCodecAESGCM, nil, false)
+
+	plain, ok := codec.Decode(codec.Encode("8a5f1c2e-user"))
+
+	require.True(t, ok)
+	assert.Equal(t, "8a5f1c2e-user", plain)
+	assert.True(t, codec.Reversible())
+}
+
+func TestUserIDCodec_Decode(t *testing.T) {
+	hmacCodec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, false)
+	aesCodec := newTestUserIDCodec(t, UserIDCodecAESGCM, nil, false)
+	otherKey, err := NewUserIDCodec(UserIDCodecAESGCM, testUserIDKey("k9", 'z'), nil, false)
+	require.NoError(t, err)
+
+	_, ok := hmacCodec.Decode(hmacCodec.Encode("user-1"))
+	assert.False(t, ok, "HMAC is not reversible")
+
+	_, ok = aesCodec.Decode(otherKey.Encode("user-1"))
+	assert.False(t, ok, "unknown key")
+
+	_, ok = aesCodec.Decode("enc:k2:not-base64!")
+	assert.False(t, ok)
+
+	plain, ok := aesCodec.Decode("user-1")
+	assert.True(t, ok, "plain IDs are returned as they are")
+	assert.Equal(t, "user-1", plain)
+}
+
+func TestNewUserIDCodec_InvalidSettings(t *testing.T) {
+	cases := map[string]struct {
+		mode     string
+		current  string
+		previous []string
+	}{
+		"unknown mode": {mode: "rot13", current: testUserIDKey("k1", 'a')},
+		"missing key":  {mode: UserIDCodecHMAC},
+		"short key":    {mode: UserIDCodecHMAC, current: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		"not base64":   {mode: UserIDCodecHMAC, current: "k1:%%%"},
+		"bad key ID":   {mode: UserIDCodecHMAC, current: testUserIDKey("k-1", 'a')},
+		"duplicate ID": {mode: UserIDCodecHMAC, current: testUserIDKey("k1", 'a'), previous: []string{testUserIDKey("k1", 'b')}},
+		"bad previous": {mode: UserIDCodecHMAC, current: testUserIDKey("k1", 'a'), previous: []string{"k0"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewUserIDCodec(tc.mode, tc.current, tc.previous, false)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewUserIDCodecFromEnv(t *testing.T) {
+	t.Setenv("USER_ID_CODEC", "")
+	codec, err := NewUserIDCodecFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, codec, "disabled by default")
+
+	t.Setenv("USER_ID_CODEC", "AES-GCM")
+	t.Setenv("USER_ID_CODEC_KEY", testUserIDKey("k2", 'b'))
+	t.Setenv("USER_ID_CODEC_PREVIOUS_KEYS", testUserIDKey("k1", 'a')+", ")
+	t.Setenv("USER_ID_CODEC_READ_PLAIN", "true")
+	codec, err = NewUserIDCodecFromEnv()
+	require.NoError(t, err)
+	require.NotNil(t, codec)
+	assert.True(t, codec.Reversible())
+	assert.Len(t, codec.legacyIDs("user-1"), 2)
+}
+
+func TestUserIDMapper_MovesLegacyRowsOnce(t *testing.T) {
+	previous, err := NewUserIDCodec(UserIDCodecHMAC, testUserIDKey("k1", 'a'), nil, false)
+	require.NoError(t, err)
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, []string{testUserIDKey("k1", 'a')}, true)
+	rekey := &fakeUserIDRekey{}
+	mapper := NewUserIDMapper(codec, rekey)
+
+	for range 2 {
+		encoded, err := mapper.Encode(context.Background(), "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, codec.Encode("user-1"), encoded)
+	}
+
+	assert.Equal(t, map[string][]string{
+		codec.Encode("user-1"): {previous.Encode("user-1"), "user-1"},
+	}, rekey.moves)
+}
+
+func TestUserIDMapper_NoLegacyKeys(t *testing.T) {
+	rekey := &fakeUserIDRekey{}
+	mapper := NewUserIDMapper(newTestUserIDCodec(t, UserIDCodecHMAC, nil, false), rekey)
+
+	_, err := mapper.Encode(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.Empty(t, rekey.moves)
+}
+
+func TestUserIDMapper_MoveError(t *testing.T) {
+	rekey := &fakeUserIDRekey{err: errors.New("connection reset")}
+	mapper := NewUserIDMapper(newTestUserIDCodec(t, UserIDCodecHMAC, nil, true), rekey)
+
+	_, err := mapper.Encode(context.Background(), "user-1")
+	require.Error(t, err)
+
+	// Retried on the next call
+	rekey.err = nil
+	_, err = mapper.Encode(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Len(t, rekey.moves, 1)
+}
+
+func TestUserIDCodec_Reencode(t *testing.T) {
+	previousAES, err := NewUserIDCodec(UserIDCodecAESGCM, testUserIDKey("k1", 'a'), nil, false)
+	require.NoError(t, err)
+	codec := newTestUserIDCodec(t, UserIDCodecAESGCM, []string{testUserIDKey("k1", 'a')}, false)
+
+	converted, ok := codec.Reencode("user-1")
+	assert.True(t, ok)
+	assert.Equal(t, codec.Encode("user-1"), converted)
+
+	converted, ok = codec.Reencode(previousAES.Encode("user-2"))
+	assert.True(t, ok, "previous AES-GCM keys are converted")
+	assert.Equal(t, codec.Encode("user-2"), converted)
+
+	_, ok = codec.Reencode(codec.Encode("user-3"))
+	assert.False(t, ok, "already under the current key")
+}
+
+func TestEncodeStoredUserIDs(t *testing.T) {
+	codec := newTestUserIDCodec(t, UserIDCodecHMAC, nil, false)
+	otherHMAC, err := NewUserIDCodec(UserIDCodecHMAC, testUserIDKey("k1", 'a'), nil, false)
+	require.NoError(t, err)
+	rekey := &fakeUserIDRekey{stored: []string{
+		codec.Encode("user-0"), otherHMAC.Encode("user-4"), "user-1", "user-2", "user-3",
+	}}
+
+	result, err := EncodeStoredUserIDs(context.Background(), codec, rekey, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, UserIDBackfillResult{Users: 5, Converted: 3, Rows: 3, Skipped: 1}, result)
+	assert.Equal(t, []string{"user-1"}, rekey.moves[codec.Encode("user-1")])
+	assert.Equal(t, []string{"user-3"}, rekey.moves[codec.Encode("user-3")])
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/lib/pq"
+)
+
+// UserIDRekeyRepository moves rows between the stored IDs of a user, for the
+// user ID codec (see UserIDCodec): user_goal_progress and the
+// goal_selection_events written with it.
+type UserIDRekeyRepository interface {
+	// MoveUserRows re-keys the rows stored under any of from to to, in one
+	// transaction, and returns the number of progress rows moved. Progress
+	// rows whose goal already has a row under to are left where they are.
+	MoveUserRows(ctx context.Context, to string, from []string) (int, error)
+
+	// ListStoredUserIDs returns up to limit distinct stored user IDs of
+	// user_goal_progress after afterUserID ("" for the first batch), in order.
+	ListStoredUserIDs(ctx context.Context, afterUserID string, limit int) ([]string, error)
+}
+
+// PostgresUserIDRekeyRepository implements UserIDRekeyRepository on PostgreSQL.
+type PostgresUserIDRekeyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserIDRekeyRepository creates a new PostgreSQL user ID rekey repository.
+func NewPostgresUserIDRekeyRepository(db *sql.DB) *PostgresUserIDRekeyRepository {
+	return &PostgresUserIDRekeyRepository{db: db}
+}
+
+// MoveUserRows uses the (user_id, goal_id) primary key and the selection
+// history index on user_id.
+func (r *PostgresUserIDRekeyRepository) MoveUserRows(ctx context.Context, to string, from []string) (int, error) {
+	if len(from) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.ErrDatabaseError("begin move user rows", err)
+	}
+
+	// No-op once committed
+	defer func() { _ = tx.Rollback() }()
+
+	progressQuery := `
+		UPDATE user_goal_progress p
+		SET user_id = $1
+		WHERE p.user_id = ANY($2)
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_goal_progress c
+			WHERE c.user_id = $1 AND c.goal_id = p.goal_id
+		  )
+	`
+	result, err := tx.ExecContext(ctx, progressQuery, to, pq.Array(from))
+	if err != nil {
+		return 0, errors.ErrDatabaseError("move user progress rows", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.ErrDatabaseError("move user progress rows", err)
+	}
+
+	eventsQuery := `UPDATE goal_selection_events SET user_id = $1 WHERE user_id = ANY($2)`
+	if _, err := tx.ExecContext(ctx, eventsQuery, to, pq.Array(from)); err != nil {
+		return 0, errors.ErrDatabaseError("move user selection events", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.ErrDatabaseError("commit move user rows", err)
+	}
+
+	return int(moved), nil
+}
+
+// ListStoredUserIDs reads the distinct users in primary key order, so a batch
+// costs one index range scan however many users come before it.
+func (r *PostgresUserIDRekeyRepository) ListStoredUserIDs(ctx context.Context, afterUserID string, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM user_goal_progress
+		WHERE user_id > $1
+		ORDER BY user_id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterUserID, limit)
+	if err != nil {
+		return nil, errors.ErrDatabaseError("list stored user IDs", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	userIDs := make([]string, 0, limit)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.ErrDatabaseError("scan stored user ID", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.ErrDatabaseError("list stored user IDs", err)
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveUserRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// Goals already stored under the new ID keep that row
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE user_goal_progress p\s+SET user_id = \$1\s+WHERE p.user_id = ANY\(\$2\)\s+AND NOT EXISTS`).
+		WithArgs("enc:k2:abc", `{"enc:k1:xyz","user-1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`UPDATE goal_selection_events SET user_id = \$1 WHERE user_id = ANY\(\$2\)`).
+		WithArgs("enc:k2:abc", `{"enc:k1:xyz","user-1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewPostgresUserIDRekeyRepository(db)
+	moved, err := repo.MoveUserRows(context.Background(), "enc:k2:abc", []string{"enc:k1:xyz", "user-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveUserRows_RollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE user_goal_progress`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE goal_selection_events`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	repo := NewPostgresUserIDRekeyRepository(db)
+	_, err = repo.MoveUserRows(context.Background(), "enc:k2:abc", []string{"user-1"})
+
+	require.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveUserRows_NothingToMove(t *testing.T) {
+	repo := NewPostgresUserIDRekeyRepository(nil)
+
+	moved, err := repo.MoveUserRows(context.Background(), "enc:k2:abc", nil)
+
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+}
+
+func TestListStoredUserIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery(`SELECT DISTINCT user_id\s+FROM user_goal_progress\s+WHERE user_id > \$1\s+ORDER BY user_id\s+LIMIT \$2`).
+		WithArgs("user-1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-2").AddRow("user-3"))
+
+	repo := NewPostgresUserIDRekeyRepository(db)
+	userIDs, err := repo.ListStoredUserIDs(context.Background(), "user-1", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-2", "user-3"}, userIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -7,8 +7,13 @@ package response
 import (
 	"bytes"
 	"fmt"
+	"maps"
+	"strconv"
+	"time"
 
 	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
 
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
 )
@@ -29,6 +34,23 @@ import (
 // Thread-safety: Safe for concurrent use (cache uses RWMutex, string ops are read-only)
 type ChallengeResponseBuilder struct {
 	cache *cache.SerializedChallengeCache
+	// segment selects the goal JSON with the segment's targets ("" for the configured targets)
+	segment string
+	// excludeClaimed leaves out goals the user progress shows claimed
+	excludeClaimed bool
+	// steps holds the steps of the user's multi-step goals, by goal ID
+	steps map[string][]*pb.GoalStep
+	// claimBlockers holds the claim blockers of the goals that cannot be claimed, by goal ID
+	claimBlockers map[string][]string
+	// repeats holds the claim history of the repeatable goals, by goal ID
+	repeats map[string]*pb.GoalRepeat
+	// firstCompletions holds the first completion of the goals whose rows were
+	// reset since, by goal ID
+	firstCompletions map[string]time.Time
+	// resets holds the next reset of the challenges that have one, by challenge ID
+	resets map[string]time.Time
+	// now is the time secondsRemaining counts from
+	now time.Time
 }
 
 // NewChallengeResponseBuilder creates a new response builder.
@@ -44,12 +66,160 @@ func NewChallengeResponseBuilder(cache *cache.SerializedChallengeCache) *Challen
 	}
 }
 
+// ForSegment returns a builder whose goals carry the targets of the given player
+// segment (see cache.SerializedChallengeCache.SetTargetOverrides).
+func (b *ChallengeResponseBuilder) ForSegment(segment string) *ChallengeResponseBuilder {
+	if segment == b.segment {
+		return b
+	}
+	builder := *b
+	builder.segment = segment
+	return &builder
+}
+
+// ExcludingClaimed returns a builder that leaves out the goals whose user
+// progress is claimed, for GetUserChallenges with exclude_claimed. A left-out
+// goal's fragment is never fetched; its challenge is still listed.
+func (b *ChallengeResponseBuilder) ExcludingClaimed() *ChallengeResponseBuilder {
+	if b.excludeClaimed {
+		return b
+	}
+	builder := *b
+	builder.excludeClaimed = true
+	return &builder
+}
+
+// WithSteps returns a builder that appends the given steps, by goal ID, to
+// multi-step goals (see AppendStepsToGoal). Other goals are written unchanged.
+func (b *ChallengeResponseBuilder) WithSteps(steps map[string][]*pb.GoalStep) *ChallengeResponseBuilder {
+	if len(steps) == 0 {
+		return b
+	}
+	builder := *b
+	builder.steps = steps
+	return &builder
+}
+
+// WithClaimBlockers returns a builder that appends the given claim blockers,
+// by goal ID, to the goals that have them (see AppendClaimBlockersToGoal).
+func (b *ChallengeResponseBuilder) WithClaimBlockers(blockers map[string][]string) *ChallengeResponseBuilder {
+	if len(blockers) == 0 {
+		return b
+	}
+	builder := *b
+	builder.claimBlockers = blockers
+	return &builder
+}
+
+// WithRepeats returns a builder that appends the given claim history, by goal
+// ID, to repeatable goals (see AppendRepeatToGoal).
+func (b *ChallengeResponseBuilder) WithRepeats(repeats map[string]*pb.GoalRepeat) *ChallengeResponseBuilder {
+	if len(repeats) == 0 {
+		return b
+	}
+	builder := *b
+	builder.repeats = repeats
+	return &builder
+}
+
+// WithFirstCompletions returns a builder that writes the given first
+// completions, by goal ID, as firstCompletedAt, on top of those it already
+// writes. Other goals were first completed at their completedAt.
+func (b *ChallengeResponseBuilder) WithFirstCompletions(firstCompletions map[string]time.Time) *ChallengeResponseBuilder {
+	if len(firstCompletions) == 0 {
+		return b
+	}
+	builder := *b
+	builder.firstCompletions = make(map[string]time.Time, len(b.firstCompletions)+len(firstCompletions))
+	maps.Copy(builder.firstCompletions, b.firstCompletions)
+	maps.Copy(builder.firstCompletions, firstCompletions)
+	return &builder
+}
+
+// WithNextResets returns a builder that writes each challenge's next reset
+// from resets, by challenge ID (see service.NextChallengeResets), with the
+// seconds remaining until it at now. Challenges without an entry, and those of
+// a builder without resets, have none.
+func (b *ChallengeResponseBuilder) WithNextResets(resets map[string]time.Time, now time.Time) *ChallengeResponseBuilder {
+	builder := *b
+	builder.resets = resets
+	builder.now = now
+	return &builder
+}
+
+// appendGoal appends a goal fragment to dst with the user's progress written
+// into its slot and, for a multi-step goal, its steps, followed by its claim
+// blockers and repeat if any. Nothing is re-encoded: the static JSON is copied
+// and the dynamic fields are appended pre-formatted.
+func (b *ChallengeResponseBuilder) appendGoal(
+	dst []byte,
+	fragment cache.GoalFragment,
+	goalID string,
+	progress *commonDomain.UserGoalProgress,
+	activatable bool,
+	activationSource string,
+	now time.Time,
+) []byte {
+	var firstCompletedAt *time.Time
+	if first, ok := b.firstCompletions[goalID]; ok {
+		firstCompletedAt = &first
+	}
+	dst = append(dst, fragment.JSON[:fragment.Slot]...)
+	dst = appendProgressFields(dst, progress, firstCompletedAt, fragment.HasPrerequisites, activatable, activationSource, now)
+	if steps := b.steps[goalID]; len(steps) > 0 {
+		dst = appendSteps(dst, steps)
+	}
+	if blockers := b.claimBlockers[goalID]; len(blockers) > 0 {
+		dst = appendClaimBlockers(dst, blockers)
+	}
+	if repeat := b.repeats[goalID]; repeat != nil {
+		dst = appendRepeat(dst, repeat)
+	}
+	return append(dst, fragment.JSON[fragment.Slot:]...)
+}
+
+// writeGoal writes a goal like appendGoal into buf.
+func (b *ChallengeResponseBuilder) writeGoal(
+	buf *bytes.Buffer,
+	fragment cache.GoalFragment,
+	goalID string,
+	progress *commonDomain.UserGoalProgress,
+	activatable bool,
+	activationSource string,
+	now time.Time,
+) {
+	buf.Grow(len(fragment.JSON) + progressFieldsSize)
+	buf.Write(b.appendGoal(buf.AvailableBuffer(), fragment, goalID, progress, activatable, activationSource, now))
+}
+
+// timeNow returns the time expiresInSeconds counts from: the builder's
+// WithNextResets time, or the current time.
+func (b *ChallengeResponseBuilder) timeNow() time.Time {
+	if !b.now.IsZero() {
+		return b.now
+	}
+	return time.Now().UTC()
+}
+
+// skipGoal reports whether the builder leaves the goal out of responses.
+func (b *ChallengeResponseBuilder) skipGoal(progress *commonDomain.UserGoalProgress) bool {
+	return b.excludeClaimed && progress != nil && progress.Status == commonDomain.GoalStatusClaimed
+}
+
+// goalFragment returns the pre-serialized goal for the builder's segment.
+func (b *ChallengeResponseBuilder) goalFragment(challengeID, goalID string) (cache.GoalFragment, bool) {
+	return b.cache.GetGoalFragment(challengeID, goalID, b.segment)
+}
+
 // BuildChallengesResponse builds the complete challenges response JSON by merging
 // pre-serialized challenge data with user progress using string injection.
 //
 // Args:
 //   - challengeIDs: List of challenge IDs to include in response
 //   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
+//   - locks: Map of challenge ID -> lock reason for locked challenges (may be nil)
 //
 // Returns:
 //   - []byte: Complete challenges response JSON
@@ -62,13 +232,45 @@ func NewChallengeResponseBuilder(cache *cache.SerializedChallengeCache) *Challen
 //  4. Zero GC pressure from temporary allocations
 //
 // Algorithm:
-//  1. Get pre-serialized JSON for each challenge
-//  2. Inject user progress into each challenge using InjectProgressIntoChallenge
+//  1. Get the challenge fragment for each challenge
+//  2. Write each listed goal's fragment with user progress appended in its slot
 //  3. Concatenate all challenges into {"challenges": [...]} structure
 //  4. Return final JSON bytes
 func (b *ChallengeResponseBuilder) BuildChallengesResponse(
 	challengeIDs []string,
 	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
+) ([]byte, error) {
+	return b.BuildChallengesResponseWithGoals(challengeIDs, nil, userProgress, activatable, activationSources, locks)
+}
+
+// BuildChallengesResponseWithGoals builds the challenges response like
+// BuildChallengesResponse, then appends extra goals to each challenge.
+//
+// Extra goals are goals left out of the challenge fragments (hidden goals the
+// user has unlocked). They are built from the per-goal JSON with user progress
+// injected and appended after the challenge's listed goals.
+//
+// Args:
+//   - challengeIDs: List of challenge IDs to include in response
+//   - extraGoals: Map of challenge ID -> goal IDs to append (may be nil)
+//   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
+//   - locks: Map of challenge ID -> lock reason for locked challenges (may be nil)
+//
+// Returns:
+//   - []byte: Complete challenges response JSON
+//   - error: If any challenge or goal is missing from cache or JSON operations fail
+func (b *ChallengeResponseBuilder) BuildChallengesResponseWithGoals(
+	challengeIDs []string,
+	extraGoals map[string][]string,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
 ) ([]byte, error) {
 	if b.cache == nil {
 		return nil, fmt.Errorf("cache is nil")
@@ -82,14 +284,7 @@ func (b *ChallengeResponseBuilder) BuildChallengesResponse(
 	// Calculate total size needed based on actual goal counts
 	totalSize := 100 // {"challenges":[...]}
 	for _, challengeID := range challengeIDs {
-		staticJSON, ok := b.cache.GetChallengeJSON(challengeID)
-		if !ok {
-			return nil, fmt.Errorf("challenge %s not found in serialization cache", challengeID)
-		}
-
-		goalCount := b.cache.GetGoalCount(challengeID)
-		totalSize += len(staticJSON)
-		totalSize += goalCount * 150 // 150 bytes per goal for progress fields
+		totalSize += b.estimateChallengeSize(challengeID, len(extraGoals[challengeID]))
 	}
 
 	// Allocate buffer with accurate size
@@ -98,28 +293,15 @@ func (b *ChallengeResponseBuilder) BuildChallengesResponse(
 	// Start response
 	result.WriteString(`{"challenges":[`)
 
-	// Process each challenge
+	// Assemble each challenge from its fragments
 	for i, challengeID := range challengeIDs {
 		if i > 0 {
 			result.WriteByte(',')
 		}
 
-		// Get pre-serialized challenge JSON from cache
-		staticJSON, ok := b.cache.GetChallengeJSON(challengeID)
-		if !ok {
-			return nil, fmt.Errorf("challenge %s not found in serialization cache", challengeID)
-		}
-
-		goalCount := b.cache.GetGoalCount(challengeID)
-
-		// Inject user progress into challenge with goal count for optimal buffer sizing
-		challengeWithProgress, err := InjectProgressIntoChallenge(staticJSON, userProgress, goalCount)
-		if err != nil {
-			return nil, fmt.Errorf("failed to inject progress into challenge %s: %w", challengeID, err)
+		if err := b.writeChallenge(result, challengeID, extraGoals[challengeID], userProgress, activatable, activationSources, locks); err != nil {
+			return nil, err
 		}
-
-		// Write injected challenge to result
-		result.Write(challengeWithProgress)
 	}
 
 	// End response
@@ -128,52 +310,161 @@ func (b *ChallengeResponseBuilder) BuildChallengesResponse(
 	return result.Bytes(), nil
 }
 
-// BuildSingleChallenge builds a single challenge response by injecting user progress
-// into pre-serialized challenge JSON using string injection.
+// AssembleChallenge builds a single challenge document from the cached challenge
+// and goal fragments, injecting user progress into each listed goal.
+//
+// It produces the same JSON as one element of the BuildChallengesResponse list,
+// so list and detail endpoints share the pre-serialized data.
 //
 // Args:
 //   - challengeID: The challenge ID
 //   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
+//   - locks: Map of challenge ID -> lock reason for locked challenges (may be nil)
 //
 // Returns:
 //   - []byte: Challenge JSON with user progress injected
-//   - error: If challenge not found in cache or JSON operations fail
-//
-// Performance: ~500-800μs vs ~15ms for unmarshal+marshal (20-30x faster)
+//   - error: If the challenge or one of its goals is not in the serialization cache
+func (b *ChallengeResponseBuilder) AssembleChallenge(
+	challengeID string,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
+) ([]byte, error) {
+	return b.AssembleChallengeWithGoals(challengeID, nil, userProgress, activatable, activationSources, locks)
+}
+
+// AssembleChallengeWithGoals builds a single challenge like AssembleChallenge,
+// then appends extra goals (see BuildChallengesResponseWithGoals).
 //
-// Algorithm:
-//  1. Get pre-serialized challenge JSON from cache
-//  2. Inject user progress using InjectProgressIntoChallenge (string manipulation)
-//  3. Return modified JSON bytes
+// Args:
+//   - challengeID: The challenge ID
+//   - extraGoalIDs: Goal IDs to append after the listed goals (may be nil)
+//   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
+//   - locks: Map of challenge ID -> lock reason for locked challenges (may be nil)
 //
-// Zero unmarshaling, zero marshaling - just string operations!
-func (b *ChallengeResponseBuilder) BuildSingleChallenge(
+// Returns:
+//   - []byte: Challenge JSON with user progress injected
+//   - error: If the challenge or a goal is not in the serialization cache
+func (b *ChallengeResponseBuilder) AssembleChallengeWithGoals(
 	challengeID string,
+	extraGoalIDs []string,
 	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
 ) ([]byte, error) {
-	// Get pre-serialized challenge JSON from cache
-	staticJSON, ok := b.cache.GetChallengeJSON(challengeID)
+	if b.cache == nil {
+		return nil, fmt.Errorf("cache is nil")
+	}
+
+	result := bytes.NewBuffer(make([]byte, 0, b.estimateChallengeSize(challengeID, len(extraGoalIDs))))
+	if err := b.writeChallenge(result, challengeID, extraGoalIDs, userProgress, activatable, activationSources, locks); err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+// StaticResponseSize returns the size of the challenges response for
+// challengeIDs without user progress, from the pre-serialized fragment sizes.
+// The response with progress is larger; challenges missing from the cache are
+// not counted.
+func (b *ChallengeResponseBuilder) StaticResponseSize(challengeIDs []string) int {
+	size := len(`{"challenges":[]}`)
+	found := 0
+	for _, challengeID := range challengeIDs {
+		if fragment, ok := b.cache.GetChallengeFragment(challengeID); ok {
+			size += fragment.Size
+			found++
+		}
+	}
+	if found > 1 {
+		size += found - 1 // commas
+	}
+	return size
+}
+
+// estimatedGoalSize is the approximate size of one goal with progress injected.
+const estimatedGoalSize = 400
+
+// estimateChallengeSize estimates the assembled size of a challenge for buffer sizing.
+func (b *ChallengeResponseBuilder) estimateChallengeSize(challengeID string, extraGoals int) int {
+	size := 100
+	if fragment, ok := b.cache.GetChallengeFragment(challengeID); ok {
+		size += len(fragment.Header) + (len(fragment.GoalIDs)+extraGoals)*estimatedGoalSize
+	}
+	return size
+}
+
+// writeChallenge assembles a challenge from its fragments into result: the
+// listed goals, then extraGoalIDs, each with user progress injected, followed by
+// the challenge's lock and reset fields. Goals left out by the builder are skipped before
+// their fragment is looked up.
+func (b *ChallengeResponseBuilder) writeChallenge(
+	result *bytes.Buffer,
+	challengeID string,
+	extraGoalIDs []string,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
+) error {
+	fragment, ok := b.cache.GetChallengeFragment(challengeID)
 	if !ok {
-		return nil, fmt.Errorf("challenge %s not found in serialization cache", challengeID)
+		return fmt.Errorf("challenge %s not found in serialization cache", challengeID)
 	}
 
-	goalCount := b.cache.GetGoalCount(challengeID)
+	// Written as ChallengeFragment.WriteJSON writes it, without its closing
+	// brace: the goals field is left out when no goal is written
+	now := b.timeNow()
+	result.Write(fragment.Header)
+	written := 0
+	for _, goalIDs := range [][]string{fragment.GoalIDs, extraGoalIDs} {
+		for _, goalID := range goalIDs {
+			progress := userProgress[goalID]
+			if b.skipGoal(progress) {
+				continue
+			}
+			goal, ok := b.goalFragment(challengeID, goalID)
+			if !ok {
+				return fmt.Errorf("goal %s not found in serialization cache", goalID)
+			}
 
-	// Inject user progress using string injection with goal count
-	// This is FAST - no unmarshal/marshal cycle!
-	challengeWithProgress, err := InjectProgressIntoChallenge(staticJSON, userProgress, goalCount)
-	if err != nil {
-		return nil, fmt.Errorf("failed to inject progress: %w", err)
+			switch {
+			case written > 0:
+				result.WriteByte(',')
+			case len(fragment.Header) > 1:
+				result.WriteString(`,"goals":[`)
+			default:
+				result.WriteString(`"goals":[`)
+			}
+			written++
+			b.writeGoal(result, goal, goalID, progress, activatable[goalID], activationSources[goalID], now)
+		}
+	}
+	if written > 0 {
+		result.WriteByte(']')
 	}
 
-	return challengeWithProgress, nil
+	writeChallengeLockFields(result, challengeID, locks)
+	b.writeChallengeResetFields(result, challengeID)
+	result.WriteByte('}')
+	return nil
 }
 
 // BuildGoalResponse builds a single goal response (useful for claim endpoints).
 //
 // Args:
+//   - challengeID: The challenge the goal belongs to
 //   - goalID: The goal ID
 //   - userProgress: User progress data for this goal
+//   - activatable: Whether the user can activate the goal
+//   - activationSource: How the goal was last activated ("" when not recorded)
 //
 // Returns:
 //   - []byte: Goal JSON with user progress injected
@@ -182,22 +473,135 @@ func (b *ChallengeResponseBuilder) BuildSingleChallenge(
 // Performance: ~100-200μs vs ~2-3ms for unmarshal+marshal (15-30x faster)
 //
 // Algorithm:
-//  1. Get pre-serialized goal JSON from cache
-//  2. Inject user progress using InjectProgressIntoGoal (string manipulation)
+//  1. Get the pre-serialized goal fragment from cache
+//  2. Append user progress in the fragment's slot (no encoding/json)
 //  3. Return modified JSON bytes
 func (b *ChallengeResponseBuilder) BuildGoalResponse(
+	challengeID string,
 	goalID string,
 	userProgress *commonDomain.UserGoalProgress,
+	activatable bool,
+	activationSource string,
 ) ([]byte, error) {
-	// Get pre-serialized goal JSON from cache
-	staticJSON, ok := b.cache.GetGoalJSON(goalID)
+	// Get pre-serialized goal from cache
+	fragment, ok := b.goalFragment(challengeID, goalID)
 	if !ok {
 		return nil, fmt.Errorf("goal %s not found in serialization cache", goalID)
 	}
 
-	// Inject user progress using string injection
-	// This is FAST - no unmarshal/marshal!
-	goalWithProgress := InjectProgressIntoGoal(staticJSON, userProgress)
+	// Fill the progress slot - no unmarshal/marshal!
+	dst := make([]byte, 0, len(fragment.JSON)+progressFieldsSize)
+	return b.appendGoal(dst, fragment, goalID, userProgress, activatable, activationSource, b.timeNow()), nil
+}
+
+// ChallengePage lists the goals of one challenge that fall on the current page.
+type ChallengePage struct {
+	ChallengeID string
+	Name        string
+	Description string
+	GoalIDs     []string
+}
+
+// BuildChallengesPageResponse builds a paginated challenges response.
+//
+// Unlike BuildChallengesResponse, each challenge only contains the goals on the page,
+// so challenges are assembled from per-goal pre-serialized JSON instead of the
+// per-challenge JSON. Field order and naming match the protojson output.
+//
+// Args:
+//   - pages: Challenges on this page with their goal IDs, in response order
+//   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
+//   - locks: Map of challenge ID -> lock reason for locked challenges (may be nil)
+//   - nextAfterGoalID: Cursor for the next page (omitted from the response if empty)
+//
+// Returns:
+//   - []byte: {"challenges":[...],"nextAfterGoalId":"..."}
+//   - error: If any goal is missing from the serialization cache
+func (b *ChallengeResponseBuilder) BuildChallengesPageResponse(
+	pages []ChallengePage,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
+	locks map[string]string,
+	nextAfterGoalID string,
+) ([]byte, error) {
+	if b.cache == nil {
+		return nil, fmt.Errorf("cache is nil")
+	}
+
+	now := b.timeNow()
+	result := bytes.NewBuffer(make([]byte, 0, 100+len(pages)*500))
+	result.WriteString(`{"challenges":[`)
+
+	for i, page := range pages {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+
+		result.WriteString(`{"challengeId":"`)
+		result.WriteString(escapeJSONString(page.ChallengeID))
+		result.WriteString(`","name":"`)
+		result.WriteString(escapeJSONString(page.Name))
+		result.WriteByte('"')
+		if page.Description != "" {
+			result.WriteString(`,"description":"`)
+			result.WriteString(escapeJSONString(page.Description))
+			result.WriteByte('"')
+		}
+		result.WriteString(`,"goals":[`)
+
+		written := 0
+		for _, goalID := range page.GoalIDs {
+			if b.skipGoal(userProgress[goalID]) {
+				continue
+			}
+			if written > 0 {
+				result.WriteByte(',')
+			}
+			written++
+
+			goal, ok := b.goalFragment(page.ChallengeID, goalID)
+			if !ok {
+				return nil, fmt.Errorf("goal %s not found in serialization cache", goalID)
+			}
+			b.writeGoal(result, goal, goalID, userProgress[goalID], activatable[goalID], activationSources[goalID], now)
+		}
+
+		result.WriteByte(']')
+		writeChallengeLockFields(result, page.ChallengeID, locks)
+		b.writeChallengeResetFields(result, page.ChallengeID)
+		result.WriteByte('}')
+	}
+
+	result.WriteByte(']')
+	if nextAfterGoalID != "" {
+		result.WriteString(`,"nextAfterGoalId":"`)
+		result.WriteString(escapeJSONString(nextAfterGoalID))
+		result.WriteByte('"')
+	}
+	result.WriteByte('}')
+
+	return result.Bytes(), nil
+}
+
+// noResetFields are the reset fields of a challenge without a next reset.
+var noResetFields = []byte(`,"nextResetAt":null,"secondsRemaining":0`)
+
+// writeChallengeResetFields writes the challenge's next reset and the seconds
+// remaining until it, like mapper.SetChallengeReset.
+//
+// Output format: ,"nextResetAt":"2025-01-02T00:00:00Z","secondsRemaining":3600
+func (b *ChallengeResponseBuilder) writeChallengeResetFields(buf *bytes.Buffer, challengeID string) {
+	resetAt, ok := b.resets[challengeID]
+	if !ok {
+		buf.Write(noResetFields)
+		return
+	}
 
-	return goalWithProgress, nil
+	buf.WriteString(`,"nextResetAt":"`)
+	buf.Write(mapper.AppendTimestamp(buf.AvailableBuffer(), resetAt))
+	buf.WriteString(`","secondsRemaining":`)
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(mapper.SecondsUntil(&resetAt, b.now)), 10))
 }
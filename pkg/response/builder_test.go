@@ -5,24 +5,37 @@
 package response
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/mapper"
 	pb "extend-challenge-service/pkg/pb"
 
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
 )
 
 // createTestCache creates a SerializedChallengeCache populated with test data
-func createTestCache(t *testing.T) *cache.SerializedChallengeCache {
+func createTestCache(t *testing.T, hiddenGoalIDs ...string) *cache.SerializedChallengeCache {
 	t.Helper()
 
 	c := cache.NewSerializedChallengeCache()
+	if len(hiddenGoalIDs) > 0 {
+		hidden := make(map[string]bool, len(hiddenGoalIDs))
+		for _, goalID := range hiddenGoalIDs {
+			hidden[goalID] = true
+		}
+		c.SetHiddenGoals(hidden)
+	}
 
 	// Create test challenges
 	challenges := []*pb.Challenge{
@@ -113,7 +126,7 @@ func TestBuildChallengesResponse_EmptyChallenges(t *testing.T) {
 	cache := createTestCache(t)
 	builder := NewChallengeResponseBuilder(cache)
 
-	result, err := builder.BuildChallengesResponse([]string{}, map[string]*commonDomain.UserGoalProgress{})
+	result, err := builder.BuildChallengesResponse([]string{}, map[string]*commonDomain.UserGoalProgress{}, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.Equal(t, `{"challenges":[]}`, string(result))
@@ -126,7 +139,7 @@ func TestBuildChallengesResponse_SingleChallenge_NoProgress(t *testing.T) {
 	challengeIDs := []string{"challenge1"}
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress)
+	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -171,7 +184,7 @@ func TestBuildChallengesResponse_SingleChallenge_WithProgress(t *testing.T) {
 		},
 	}
 
-	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress)
+	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -198,7 +211,7 @@ func TestBuildChallengesResponse_MultipleChallenges(t *testing.T) {
 	challengeIDs := []string{"challenge1", "challenge2"}
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress)
+	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -224,7 +237,7 @@ func TestBuildChallengesResponse_NilCache(t *testing.T) {
 	challengeIDs := []string{"challenge1"}
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress)
+	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cache is nil")
@@ -238,20 +251,20 @@ func TestBuildChallengesResponse_ChallengeNotFound(t *testing.T) {
 	challengeIDs := []string{"nonexistent-challenge"}
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress)
+	result, err := builder.BuildChallengesResponse(challengeIDs, userProgress, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found in serialization cache")
 	assert.Nil(t, result)
 }
 
-func TestBuildSingleChallenge_Success(t *testing.T) {
+func TestAssembleChallenge_Success(t *testing.T) {
 	cache := createTestCache(t)
 	builder := NewChallengeResponseBuilder(cache)
 
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildSingleChallenge("challenge1", userProgress)
+	result, err := builder.AssembleChallenge("challenge1", userProgress, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -268,7 +281,7 @@ func TestBuildSingleChallenge_Success(t *testing.T) {
 	assert.Len(t, goals, 2)
 }
 
-func TestBuildSingleChallenge_WithProgress(t *testing.T) {
+func TestAssembleChallenge_WithProgress(t *testing.T) {
 	cache := createTestCache(t)
 	builder := NewChallengeResponseBuilder(cache)
 
@@ -295,7 +308,7 @@ func TestBuildSingleChallenge_WithProgress(t *testing.T) {
 		},
 	}
 
-	result, err := builder.BuildSingleChallenge("challenge1", userProgress)
+	result, err := builder.AssembleChallenge("challenge1", userProgress, nil, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -320,13 +333,13 @@ func TestBuildSingleChallenge_WithProgress(t *testing.T) {
 	assert.Equal(t, "in_progress", goal2["status"])
 }
 
-func TestBuildSingleChallenge_NotFound(t *testing.T) {
+func TestAssembleChallenge_NotFound(t *testing.T) {
 	cache := createTestCache(t)
 	builder := NewChallengeResponseBuilder(cache)
 
 	userProgress := map[string]*commonDomain.UserGoalProgress{}
 
-	result, err := builder.BuildSingleChallenge("nonexistent", userProgress)
+	result, err := builder.AssembleChallenge("nonexistent", userProgress, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found in serialization cache")
@@ -347,7 +360,7 @@ func TestBuildGoalResponse_Success(t *testing.T) {
 		ClaimedAt:   nil,
 	}
 
-	result, err := builder.BuildGoalResponse("goal1", userProgress)
+	result, err := builder.BuildGoalResponse("challenge1", "goal1", userProgress, false, "")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -378,7 +391,7 @@ func TestBuildGoalResponse_WithProgress(t *testing.T) {
 		ClaimedAt:   nil,
 	}
 
-	result, err := builder.BuildGoalResponse("goal2", userProgress)
+	result, err := builder.BuildGoalResponse("challenge1", "goal2", userProgress, false, "")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -392,7 +405,7 @@ func TestBuildGoalResponse_WithProgress(t *testing.T) {
 	assert.Equal(t, float64(5), goal["progress"])
 	assert.Equal(t, "completed", goal["status"])
 	assert.Equal(t, "2025-01-15T10:30:00Z", goal["completedAt"])
-	assert.Equal(t, "", goal["claimedAt"])
+	assert.Nil(t, goal["claimedAt"])
 }
 
 func TestBuildGoalResponse_Claimed(t *testing.T) {
@@ -411,7 +424,7 @@ func TestBuildGoalResponse_Claimed(t *testing.T) {
 		ClaimedAt:   &claimedAt,
 	}
 
-	result, err := builder.BuildGoalResponse("goal3", userProgress)
+	result, err := builder.BuildGoalResponse("challenge2", "goal3", userProgress, false, "")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -432,7 +445,7 @@ func TestBuildGoalResponse_NilProgress(t *testing.T) {
 	cache := createTestCache(t)
 	builder := NewChallengeResponseBuilder(cache)
 
-	result, err := builder.BuildGoalResponse("goal1", nil)
+	result, err := builder.BuildGoalResponse("challenge1", "goal1", nil, false, "")
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, result)
@@ -445,8 +458,8 @@ func TestBuildGoalResponse_NilProgress(t *testing.T) {
 	// Should have default values
 	assert.Equal(t, float64(0), goal["progress"])
 	assert.Equal(t, "not_started", goal["status"])
-	assert.Equal(t, "", goal["completedAt"])
-	assert.Equal(t, "", goal["claimedAt"])
+	assert.Nil(t, goal["completedAt"])
+	assert.Nil(t, goal["claimedAt"])
 }
 
 func TestBuildGoalResponse_NotFound(t *testing.T) {
@@ -460,9 +473,549 @@ func TestBuildGoalResponse_NotFound(t *testing.T) {
 		Status:   commonDomain.GoalStatusInProgress,
 	}
 
-	result, err := builder.BuildGoalResponse("nonexistent", userProgress)
+	result, err := builder.BuildGoalResponse("challenge1", "nonexistent", userProgress, false, "")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in serialization cache")
+	assert.Nil(t, result)
+}
+
+func TestBuildChallengesPageResponse_PartialChallenges(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+
+	pages := []ChallengePage{
+		{ChallengeID: "challenge1", Name: "Test Challenge 1", Description: "First test challenge", GoalIDs: []string{"goal2"}},
+		{ChallengeID: "challenge2", Name: "Test \"Quoted\"", GoalIDs: []string{"goal3"}},
+	}
+	userProgress := map[string]*commonDomain.UserGoalProgress{
+		"goal3": {GoalID: "goal3", Progress: 2, Status: commonDomain.GoalStatusInProgress},
+	}
+
+	result, err := builder.BuildChallengesPageResponse(pages, userProgress, nil, nil, nil, "goal3")
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	assert.Equal(t, "goal3", response["nextAfterGoalId"])
+
+	challenges := response["challenges"].([]interface{})
+	require.Len(t, challenges, 2)
+
+	first := challenges[0].(map[string]interface{})
+	assert.Equal(t, "First test challenge", first["description"])
+	firstGoals := first["goals"].([]interface{})
+	require.Len(t, firstGoals, 1, "Only page goals should be included")
+	assert.Equal(t, "goal2", firstGoals[0].(map[string]interface{})["goalId"])
+
+	second := challenges[1].(map[string]interface{})
+	assert.Equal(t, "Test \"Quoted\"", second["name"])
+	assert.NotContains(t, second, "description", "Empty description is omitted like protojson")
+	goal3 := second["goals"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "in_progress", goal3["status"])
+}
+
+func TestBuildChallengesPageResponse_LastPage(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+
+	result, err := builder.BuildChallengesPageResponse(nil, nil, nil, nil, nil, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"challenges":[]}`, string(result))
+}
+
+func TestBuildChallengesPageResponse_GoalNotFound(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+
+	pages := []ChallengePage{{ChallengeID: "challenge1", Name: "Test Challenge 1", GoalIDs: []string{"missing"}}}
+	result, err := builder.BuildChallengesPageResponse(pages, nil, nil, nil, nil, "")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found in serialization cache")
 	assert.Nil(t, result)
 }
+
+func TestBuildChallengesResponseWithGoals_AppendsExtraGoals(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t, "goal2"))
+
+	// goal2 is hidden, so left out of challenge1's fragment, and appended as for a user who unlocked it
+	extraGoals := map[string][]string{"challenge1": {"goal2"}}
+	userProgress := map[string]*commonDomain.UserGoalProgress{
+		"goal2": {GoalID: "goal2", Progress: 1, Status: commonDomain.GoalStatusInProgress},
+	}
+
+	result, err := builder.BuildChallengesResponseWithGoals([]string{"challenge1"}, extraGoals, userProgress, nil, nil, nil)
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	goals := response["challenges"].([]interface{})[0].(map[string]interface{})["goals"].([]interface{})
+	require.Len(t, goals, 2)
+	appended := goals[1].(map[string]interface{})
+	assert.Equal(t, "goal2", appended["goalId"])
+	assert.Equal(t, "in_progress", appended["status"])
+}
+
+func TestBuildChallengesResponseWithGoals_GoalOfOtherChallenge(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+
+	// Goal fragments are keyed by challenge, so goal3 (challenge2) is not found under challenge1
+	result, err := builder.BuildChallengesResponseWithGoals([]string{"challenge1"}, map[string][]string{"challenge1": {"goal3"}}, nil, nil, nil, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "goal goal3 not found in serialization cache")
+	assert.Nil(t, result)
+}
+
+func TestBuildChallengesResponseWithGoals_GoalNotFound(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+
+	result, err := builder.BuildChallengesResponseWithGoals([]string{"challenge1"}, map[string][]string{"challenge1": {"missing"}}, nil, nil, nil, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in serialization cache")
+	assert.Nil(t, result)
+}
+
+func TestAssembleChallenge_MatchesListEntry(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+	userProgress := map[string]*commonDomain.UserGoalProgress{
+		"goal2": {GoalID: "goal2", Progress: 3, Status: commonDomain.GoalStatusInProgress},
+	}
+
+	list, err := builder.BuildChallengesResponse([]string{"challenge2", "challenge1"}, userProgress, nil, nil, nil)
+	require.NoError(t, err)
+	var response struct {
+		Challenges []json.RawMessage `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(list, &response))
+	require.Len(t, response.Challenges, 2)
+
+	detail, err := builder.AssembleChallenge("challenge1", userProgress, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(response.Challenges[1]), string(detail), "list and detail share the same fragments")
+}
+
+func TestAssembleChallengeWithGoals_OnlyExtraGoals(t *testing.T) {
+	c := cache.NewSerializedChallengeCache()
+	c.SetHiddenGoals(map[string]bool{"secret": true})
+	require.NoError(t, c.WarmUp([]*pb.Challenge{
+		{ChallengeId: "quest", Name: "Quest", Goals: []*pb.Goal{{GoalId: "secret", Name: "Secret"}}},
+	}))
+	builder := NewChallengeResponseBuilder(c)
+
+	locked, err := builder.AssembleChallenge("quest", nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"challengeId":"quest","name":"Quest","locked":false,"lockedReason":"","nextResetAt":null,"secondsRemaining":0}`, string(locked))
+
+	unlocked, err := builder.AssembleChallengeWithGoals("quest", []string{"secret"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	var challenge map[string]interface{}
+	require.NoError(t, json.Unmarshal(unlocked, &challenge), "Response should be valid JSON")
+	goals := challenge["goals"].([]interface{})
+	require.Len(t, goals, 1)
+	assert.Equal(t, "not_started", goals[0].(map[string]interface{})["status"])
+}
+
+func TestBuildChallengesResponse_ChallengeLocks(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+	locks := map[string]string{"challenge2": `requires "challenge1"`}
+
+	result, err := builder.BuildChallengesResponse([]string{"challenge1", "challenge2"}, nil, nil, nil, locks)
+	require.NoError(t, err)
+
+	var response struct {
+		Challenges []map[string]interface{} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	require.Len(t, response.Challenges, 2)
+	assert.Equal(t, false, response.Challenges[0]["locked"])
+	assert.Equal(t, "", response.Challenges[0]["lockedReason"])
+	assert.Equal(t, true, response.Challenges[1]["locked"])
+	assert.Equal(t, `requires "challenge1"`, response.Challenges[1]["lockedReason"])
+	assert.NotEmpty(t, response.Challenges[1]["goals"], "locked challenges still list their goals")
+}
+
+func TestBuildChallengesPageResponse_ChallengeLocks(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t))
+	pages := []ChallengePage{{ChallengeID: "challenge2", Name: "Test Challenge 2", GoalIDs: []string{"goal3"}}}
+
+	result, err := builder.BuildChallengesPageResponse(pages, nil, nil, nil, map[string]string{"challenge2": "locked"}, "")
+	require.NoError(t, err)
+
+	var response struct {
+		Challenges []map[string]interface{} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	require.Len(t, response.Challenges, 1)
+	assert.Equal(t, true, response.Challenges[0]["locked"])
+	assert.Equal(t, "locked", response.Challenges[0]["lockedReason"])
+}
+
+func TestStaticResponseSize(t *testing.T) {
+	c := createTestCache(t)
+	builder := NewChallengeResponseBuilder(c)
+
+	challenge1, ok := c.GetChallengeJSON("challenge1")
+	require.True(t, ok)
+	challenge2, ok := c.GetChallengeJSON("challenge2")
+	require.True(t, ok)
+
+	assert.Equal(t, len(`{"challenges":[]}`), builder.StaticResponseSize(nil))
+	assert.Equal(t, len(`{"challenges":[`+string(challenge1)+`]}`), builder.StaticResponseSize([]string{"challenge1"}))
+	assert.Equal(t, len(`{"challenges":[`+string(challenge1)+`,`+string(challenge2)+`]}`),
+		builder.StaticResponseSize([]string{"challenge1", "challenge2", "unknown"}), "unknown challenges are not counted")
+}
+
+func TestBuildChallengesResponse_ExcludingClaimed(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t)).ExcludingClaimed()
+	userProgress := map[string]*commonDomain.UserGoalProgress{
+		"goal1": {GoalID: "goal1", Progress: 10, Status: commonDomain.GoalStatusClaimed},
+		"goal2": {GoalID: "goal2", Progress: 2, Status: commonDomain.GoalStatusInProgress},
+		"goal3": {GoalID: "goal3", Progress: 3, Status: commonDomain.GoalStatusClaimed},
+	}
+
+	result, err := builder.ForSegment("veteran").BuildChallengesResponse([]string{"challenge1", "challenge2"}, userProgress, nil, nil, nil)
+	require.NoError(t, err)
+
+	var response struct {
+		Challenges []struct {
+			ChallengeID string `json:"challengeId"`
+			Goals       []struct {
+				GoalID string `json:"goalId"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	require.Len(t, response.Challenges, 2, "Challenges stay listed")
+	require.Len(t, response.Challenges[0].Goals, 1)
+	assert.Equal(t, "goal2", response.Challenges[0].Goals[0].GoalID)
+	assert.Empty(t, response.Challenges[1].Goals)
+}
+
+func TestBuildChallengesPageResponse_ExcludingClaimed(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t)).ExcludingClaimed()
+
+	pages := []ChallengePage{{ChallengeID: "challenge1", Name: "Test Challenge 1", GoalIDs: []string{"goal1", "goal2"}}}
+	userProgress := map[string]*commonDomain.UserGoalProgress{
+		"goal1": {GoalID: "goal1", Progress: 10, Status: commonDomain.GoalStatusClaimed},
+	}
+
+	result, err := builder.BuildChallengesPageResponse(pages, userProgress, nil, nil, nil, "")
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	goals := response["challenges"].([]interface{})[0].(map[string]interface{})["goals"].([]interface{})
+	require.Len(t, goals, 1)
+	assert.Equal(t, "goal2", goals[0].(map[string]interface{})["goalId"])
+}
+
+func TestBuildChallengesResponse_WithSteps(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t)).WithSteps(map[string][]*pb.GoalStep{
+		"goal2": {
+			{StatCode: "wins", Operator: ">=", TargetValue: 5, Progress: 5, Completed: true},
+			{StatCode: "damage", Operator: ">=", TargetValue: 100, Progress: 40},
+		},
+	})
+
+	result, err := builder.ExcludingClaimed().BuildChallengesResponse([]string{"challenge1"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	var response struct {
+		Challenges []struct {
+			Goals []struct {
+				GoalID string `json:"goalId"`
+				Steps  []struct {
+					StatCode string `json:"statCode"`
+					Progress int    `json:"progress"`
+				} `json:"steps"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	goals := response.Challenges[0].Goals
+	require.Len(t, goals, 2)
+	assert.Empty(t, goals[0].Steps, "Single-requirement goals have no steps")
+	require.Len(t, goals[1].Steps, 2)
+	assert.Equal(t, "damage", goals[1].Steps[1].StatCode)
+	assert.Equal(t, 40, goals[1].Steps[1].Progress)
+}
+
+func TestBuildChallengesResponse_WithClaimBlockers(t *testing.T) {
+	builder := NewChallengeResponseBuilder(createTestCache(t)).WithClaimBlockers(map[string][]string{
+		"goal2": {"not_completed", "prerequisites_not_met:goal1"},
+	})
+
+	result, err := builder.BuildChallengesResponse([]string{"challenge1"}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	var response struct {
+		Challenges []struct {
+			Goals []struct {
+				GoalID        string   `json:"goalId"`
+				ClaimBlockers []string `json:"claimBlockers"`
+			} `json:"goals"`
+		} `json:"challenges"`
+	}
+	require.NoError(t, json.Unmarshal(result, &response), "Response should be valid JSON")
+	goals := response.Challenges[0].Goals
+	require.Len(t, goals, 2)
+	assert.Empty(t, goals[0].ClaimBlockers, "Goals without blockers have no claimBlockers")
+	assert.Equal(t, []string{"not_completed", "prerequisites_not_met:goal1"}, goals[1].ClaimBlockers)
+}
+
+// dynamicGoalFields are the per-user fields of a goal, in the order the
+// builder writes them, for encoding with encoding/json.
+type dynamicGoalFields struct {
+	Progress         int32             `json:"progress"`
+	Status           string            `json:"status"`
+	Locked           bool              `json:"locked"`
+	CompletedAt      *string           `json:"completedAt"`
+	FirstCompletedAt *string           `json:"firstCompletedAt"`
+	ClaimedAt        *string           `json:"claimedAt"`
+	IsActive         bool              `json:"isActive"`
+	ExpiresAt        *string           `json:"expiresAt"`
+	ExpiresInSeconds int64             `json:"expiresInSeconds"`
+	AssignedAt       *string           `json:"assignedAt"`
+	Activatable      bool              `json:"activatable"`
+	ActivationSource string            `json:"activationSource"`
+	Steps            []dynamicGoalStep `json:"steps,omitempty"`
+	ClaimBlockers    []string          `json:"claimBlockers,omitempty"`
+	Repeat           *dynamicRepeat    `json:"repeat,omitempty"`
+}
+
+type dynamicGoalStep struct {
+	StatCode    string `json:"statCode"`
+	Operator    string `json:"operator"`
+	TargetValue int32  `json:"targetValue"`
+	Progress    int32  `json:"progress"`
+	Completed   bool   `json:"completed"`
+}
+
+type dynamicRepeat struct {
+	TimesClaimed int32   `json:"timesClaimed"`
+	AvailableAt  *string `json:"availableAt"`
+}
+
+// differentialStrings are string values to escape, avoiding the characters
+// encoding/json escapes differently but equivalently (<, >, &, \b, \f, U+2028).
+var differentialStrings = []string{"", "random", "manual", `quo"te`, `back\slash`, "new\nline", "tab\tx", "ctl\x01\x1f", "héllo ✓"}
+
+func randomTime(rng *rand.Rand, now time.Time) *time.Time {
+	if rng.Intn(3) == 0 {
+		return nil
+	}
+	t := now.Add(time.Duration(rng.Int63n(int64(96*time.Hour))) - 48*time.Hour)
+	return &t
+}
+
+func timestampString(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := string(mapper.AppendTimestamp(nil, *t))
+	return &formatted
+}
+
+// expectedGoalJSON encodes the dynamic fields of a goal with encoding/json and
+// writes them into the goal's slot.
+func expectedGoalJSON(t *testing.T, fragment cache.GoalFragment, fields dynamicGoalFields) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	require.NoError(t, encoder.Encode(fields))
+	object := bytes.TrimSpace(encoded.Bytes())
+
+	expected := append([]byte{}, fragment.JSON[:fragment.Slot]...)
+	expected = append(expected, ',')
+	expected = append(expected, object[1:len(object)-1]...)
+	return append(expected, fragment.JSON[fragment.Slot:]...)
+}
+
+// TestBuildGoalResponse_MatchesEncodingJSON checks the pre-formatted progress
+// fields against encoding/json for randomized progress states.
+func TestBuildGoalResponse_MatchesEncodingJSON(t *testing.T) {
+	c := cache.NewSerializedChallengeCache()
+	require.NoError(t, c.WarmUp([]*pb.Challenge{{
+		ChallengeId: "c1",
+		Name:        "Challenge",
+		Goals: []*pb.Goal{
+			{GoalId: "plain", Name: "Plain", Requirement: &pb.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 5}},
+			{GoalId: "gated", Name: "Gated", Prerequisites: []string{"plain"}, Requirement: &pb.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 9}},
+		},
+	}}))
+	statuses := []commonDomain.GoalStatus{
+		commonDomain.GoalStatusNotStarted, commonDomain.GoalStatusInProgress,
+		commonDomain.GoalStatusCompleted, commonDomain.GoalStatusClaimed, `custom"status`,
+	}
+
+	now := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(2437))
+	for i := 0; i < 2000; i++ {
+		goalID := []string{"plain", "gated"}[rng.Intn(2)]
+		fragment, ok := c.GetGoalFragment("c1", goalID, "")
+		require.True(t, ok)
+
+		activatable := rng.Intn(2) == 0
+		source := differentialStrings[rng.Intn(len(differentialStrings))]
+		fields := dynamicGoalFields{Activatable: activatable, Status: string(commonDomain.GoalStatusNotStarted)}
+
+		var progress *commonDomain.UserGoalProgress
+		switch rng.Intn(3) {
+		case 0:
+			// No row: locked by prerequisites
+			fields.Locked = fragment.HasPrerequisites
+		case 1:
+			// No row of a rotating goal: only the next expiry
+			expiresAt := now.Add(time.Duration(rng.Intn(100000)) * time.Second)
+			progress = NoProgress(goalID, &expiresAt)
+			fields.Locked = fragment.HasPrerequisites
+			fields.ExpiresAt = timestampString(&expiresAt)
+			fields.ExpiresInSeconds = int64(expiresAt.Sub(now).Seconds())
+		default:
+			progress = &commonDomain.UserGoalProgress{
+				GoalID:      goalID,
+				Progress:    rng.Intn(2000000) - 1000000,
+				Status:      statuses[rng.Intn(len(statuses))],
+				CompletedAt: randomTime(rng, now),
+				ClaimedAt:   randomTime(rng, now),
+				IsActive:    rng.Intn(2) == 0,
+				ExpiresAt:   randomTime(rng, now),
+				AssignedAt:  randomTime(rng, now),
+			}
+			fields = dynamicGoalFields{
+				Progress:         int32(progress.Progress), // #nosec G115 - test values fit
+				Status:           string(progress.Status),
+				CompletedAt:      timestampString(progress.CompletedAt),
+				FirstCompletedAt: timestampString(progress.CompletedAt),
+				ClaimedAt:        timestampString(progress.ClaimedAt),
+				IsActive:         progress.IsActive,
+				ExpiresAt:        timestampString(progress.ExpiresAt),
+				AssignedAt:       timestampString(progress.AssignedAt),
+				Activatable:      activatable,
+				ActivationSource: source,
+			}
+			if progress.ExpiresAt != nil {
+				fields.ExpiresInSeconds = max(int64(progress.ExpiresAt.Sub(now).Seconds()), 0)
+			}
+		}
+
+		builder := NewChallengeResponseBuilder(c).WithNextResets(nil, now)
+		if firstCompletedAt := randomTime(rng, now); firstCompletedAt != nil && progress != nil && progress.Status != "" {
+			builder = builder.WithFirstCompletions(map[string]time.Time{goalID: *firstCompletedAt})
+			fields.FirstCompletedAt = timestampString(firstCompletedAt)
+		}
+		if rng.Intn(3) == 0 {
+			step := &pb.GoalStep{StatCode: differentialStrings[rng.Intn(len(differentialStrings))], Operator: ">=", TargetValue: rng.Int31n(100), Progress: rng.Int31n(100), Completed: rng.Intn(2) == 0}
+			builder = builder.WithSteps(map[string][]*pb.GoalStep{goalID: {step, step}})
+			fields.Steps = []dynamicGoalStep{
+				{StatCode: step.StatCode, Operator: step.Operator, TargetValue: step.TargetValue, Progress: step.Progress, Completed: step.Completed},
+				{StatCode: step.StatCode, Operator: step.Operator, TargetValue: step.TargetValue, Progress: step.Progress, Completed: step.Completed},
+			}
+		}
+		if rng.Intn(3) == 0 {
+			blocker := differentialStrings[rng.Intn(len(differentialStrings))]
+			builder = builder.WithClaimBlockers(map[string][]string{goalID: {"not_completed", blocker}})
+			fields.ClaimBlockers = []string{"not_completed", blocker}
+		}
+		if rng.Intn(3) == 0 {
+			repeat := &pb.GoalRepeat{TimesClaimed: rng.Int31n(50)}
+			if availableAt := randomTime(rng, now); availableAt != nil {
+				repeat.AvailableAt = timestamppb.New(*availableAt)
+			}
+			builder = builder.WithRepeats(map[string]*pb.GoalRepeat{goalID: repeat})
+			fields.Repeat = &dynamicRepeat{TimesClaimed: repeat.TimesClaimed}
+			if repeat.AvailableAt != nil {
+				availableAt := repeat.AvailableAt.AsTime()
+				fields.Repeat.AvailableAt = timestampString(&availableAt)
+			}
+		}
+
+		got, err := builder.BuildGoalResponse("c1", goalID, progress, activatable, source)
+		require.NoError(t, err)
+		require.Equal(t, string(expectedGoalJSON(t, fragment, fields)), string(got), "case %d", i)
+	}
+}
+
+// benchmarkChallengeCache warms a cache with one challenge of goalCount goals.
+func benchmarkChallengeCache(b *testing.B, goalCount int) (*cache.SerializedChallengeCache, map[string]*commonDomain.UserGoalProgress) {
+	b.Helper()
+	now := time.Now().UTC()
+	challenge := &pb.Challenge{ChallengeId: "season", Name: "Season", Description: "Season goals"}
+	progress := make(map[string]*commonDomain.UserGoalProgress, goalCount)
+	for i := 0; i < goalCount; i++ {
+		goalID := fmt.Sprintf("goal-%d", i)
+		challenge.Goals = append(challenge.Goals, &pb.Goal{
+			GoalId:      goalID,
+			Name:        "Goal " + goalID,
+			Description: "Win some matches",
+			Requirement: &pb.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 10},
+			Reward:      &pb.Reward{Type: "WALLET", RewardId: "GOLD", Quantity: 100},
+		})
+		if i%4 != 0 {
+			progress[goalID] = &commonDomain.UserGoalProgress{
+				GoalID: goalID, Progress: i % 10, Status: commonDomain.GoalStatusInProgress,
+				IsActive: true, AssignedAt: &now, CompletedAt: &now,
+			}
+		}
+	}
+
+	c := cache.NewSerializedChallengeCache()
+	if err := c.WarmUp([]*pb.Challenge{challenge}); err != nil {
+		b.Fatal(err)
+	}
+	return c, progress
+}
+
+// BenchmarkBuildChallengesResponse measures assembly with the progress fields
+// appended into the goal fragments' slots.
+func BenchmarkBuildChallengesResponse(b *testing.B) {
+	c, progress := benchmarkChallengeCache(b, 100)
+	builder := NewChallengeResponseBuilder(c)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.BuildChallengesResponse([]string{"season"}, progress, nil, nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProgressFields_EncodingJSON is the baseline of encoding the same
+// progress fields with encoding/json.
+func BenchmarkProgressFields_EncodingJSON(b *testing.B) {
+	_, progress := benchmarkChallengeCache(b, 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range progress {
+			if _, err := json.Marshal(dynamicGoalFields{
+				Progress:         int32(p.Progress), // #nosec G115 - benchmark values fit
+				Status:           string(p.Status),
+				CompletedAt:      timestampString(p.CompletedAt),
+				FirstCompletedAt: timestampString(p.CompletedAt),
+				IsActive:         p.IsActive,
+				AssignedAt:       timestampString(p.AssignedAt),
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkAppendProgressFields measures the pre-formatted progress fields alone.
+func BenchmarkAppendProgressFields(b *testing.B) {
+	_, progress := benchmarkChallengeCache(b, 100)
+	now := time.Now().UTC()
+	scratch := make([]byte, 0, progressFieldsSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range progress {
+			scratch = appendProgressFields(scratch[:0], p, nil, false, false, "", now)
+		}
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"strconv"
 	"time"
 
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
+
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
 )
 
@@ -24,16 +27,21 @@ import (
 // Args:
 //   - staticJSON: Pre-serialized goal JSON from cache
 //   - progress: User progress data (nil for defaults)
+//   - activatable: Whether the user can activate the goal (see service.IsActivatable)
+//   - activationSource: How the goal was last activated ("" when not recorded)
 //
 // Returns:
 //   - []byte: Goal JSON with progress fields injected
 //
 // Algorithm:
 //  1. Find the last closing brace } in the JSON
-//  2. Build progress fields as JSON string: ,"progress":5,"status":"in_progress"
-//  3. Insert before the closing brace
+//  2. Append progress fields: ,"progress":5,"status":"in_progress" (see appendProgressFields)
+//  3. Close the object
 //  4. Return modified bytes
 //
+// Responses built from the serialization cache use the goal fragments' slots
+// instead, see ChallengeResponseBuilder.
+//
 // Safety:
 //   - Validates JSON has closing brace
 //   - Escapes string values to prevent injection
@@ -41,6 +49,8 @@ import (
 func InjectProgressIntoGoal(
 	staticJSON []byte,
 	progress *commonDomain.UserGoalProgress,
+	activatable bool,
+	activationSource string,
 ) []byte {
 	// Find the closing brace of the goal object
 	// We inject progress fields just before it
@@ -50,97 +60,279 @@ func InjectProgressIntoGoal(
 		return staticJSON
 	}
 
-	// Build progress fields
-	var progressFields []byte
-	if progress == nil {
-		// No progress - use defaults
-		progressFields = buildDefaultProgressFields()
-	} else {
-		progressFields = buildProgressFields(progress)
-	}
-
 	// Allocate buffer for result (original + progress fields)
-	// Typical: 200 bytes original + 100 bytes progress = 300 bytes
-	result := make([]byte, 0, len(staticJSON)+len(progressFields)+10)
+	// Typical: 200 bytes original + 200 bytes progress = 400 bytes
+	result := make([]byte, 0, len(staticJSON)+progressFieldsSize)
 
 	// Build result: original[0:closingBrace] + progressFields + }
 	result = append(result, staticJSON[:closingBraceIdx]...)
-	result = append(result, progressFields...)
+	result = appendProgressFields(result, progress, nil, hasPrerequisites(staticJSON), activatable, activationSource, time.Now().UTC())
 	result = append(result, '}')
 
 	return result
 }
 
-// buildDefaultProgressFields returns JSON fields for goals with no user progress.
-func buildDefaultProgressFields() []byte {
-	return []byte(`,"progress":0,"status":"not_started","completedAt":"","claimedAt":"","isActive":false,"expiresAt":"","expiresInSeconds":0`)
+// NoProgress returns a display row for a goal the user has no progress for. It
+// carries only the goal's next rotation expiry, and InjectProgressIntoGoal
+// writes it like a missing row. Rows read from storage always have a status.
+func NoProgress(goalID string, expiresAt *time.Time) *commonDomain.UserGoalProgress {
+	return &commonDomain.UserGoalProgress{GoalID: goalID, ExpiresAt: expiresAt}
 }
 
-// buildProgressFields builds JSON fields for a goal with user progress.
-//
-// Output format: ,"progress":5,"status":"in_progress","completedAt":"2025-01-15T10:30:00Z","claimedAt":""
-//
-// Args:
-//   - progress: User progress data
+// noProgressExpiresAt returns the expiry of a NoProgress row, nil for a missing row.
+func noProgressExpiresAt(progress *commonDomain.UserGoalProgress) *time.Time {
+	if progress == nil {
+		return nil
+	}
+	return progress.ExpiresAt
+}
+
+// prerequisitesField marks a pre-serialized goal with prerequisites: the cache
+// omits empty fields, and quotes inside string values are escaped, so the key
+// only appears for a non-empty prerequisites array.
+var prerequisitesField = []byte(`"prerequisites":[`)
+
+// hasPrerequisites reports whether the pre-serialized goal lists prerequisites.
+func hasPrerequisites(staticJSON []byte) bool {
+	return bytes.Contains(staticJSON, prerequisitesField)
+}
+
+// Default fields for goals with no user progress and no expiry, by locked and
+// activatable. A goal without progress is locked when it has prerequisites, as
+// in mapper.GoalToProto.
+var (
+	defaultProgressFields                  = []byte(`,"progress":0,"status":"not_started","locked":false,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":false,"expiresAt":null,"expiresInSeconds":0,"assignedAt":null,"activatable":false,"activationSource":""`)
+	defaultActivatableProgressFields       = []byte(`,"progress":0,"status":"not_started","locked":false,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":false,"expiresAt":null,"expiresInSeconds":0,"assignedAt":null,"activatable":true,"activationSource":""`)
+	defaultLockedProgressFields            = []byte(`,"progress":0,"status":"not_started","locked":true,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":false,"expiresAt":null,"expiresInSeconds":0,"assignedAt":null,"activatable":false,"activationSource":""`)
+	defaultLockedActivatableProgressFields = []byte(`,"progress":0,"status":"not_started","locked":true,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":false,"expiresAt":null,"expiresInSeconds":0,"assignedAt":null,"activatable":true,"activationSource":""`)
+)
+
+// statusFields are the pre-formatted status fields of the goal statuses; other
+// statuses are escaped when written.
+var statusFields = map[commonDomain.GoalStatus][]byte{
+	commonDomain.GoalStatusNotStarted: []byte(`,"status":"not_started"`),
+	commonDomain.GoalStatusInProgress: []byte(`,"status":"in_progress"`),
+	commonDomain.GoalStatusCompleted:  []byte(`,"status":"completed"`),
+	commonDomain.GoalStatusClaimed:    []byte(`,"status":"claimed"`),
+}
+
+// progressFieldsSize is the usual length of the progress fields of a goal.
+const progressFieldsSize = 256
+
+// appendProgressFields appends the progress fields of a goal to dst, for the
+// goal's slot in its pre-serialized JSON (see cache.GoalFragment). A goal
+// without progress is locked when hasPrerequisites. firstCompletedAt is the
+// goal's first completion when its row was reset since, nil to use completedAt.
+// expiresInSeconds counts from now.
 //
-// Returns:
-//   - []byte: JSON fields string
-func buildProgressFields(progress *commonDomain.UserGoalProgress) []byte {
-	// Use bytes.Buffer for efficient string building
-	// Average size: ~80-120 bytes
-	buf := bytes.NewBuffer(make([]byte, 0, 120))
-
-	// Inject progress (always present)
-	buf.WriteString(`,"progress":`)
-	buf.WriteString(strconv.FormatInt(int64(progress.Progress), 10))
-
-	// Inject status (always present)
-	buf.WriteString(`,"status":"`)
-	buf.WriteString(escapeJSONString(string(progress.Status)))
-	buf.WriteString(`"`)
-
-	// Inject completedAt (camelCase)
-	buf.WriteString(`,"completedAt":"`)
-	if progress.CompletedAt != nil {
-		buf.WriteString(progress.CompletedAt.Format(time.RFC3339))
-	}
-	buf.WriteString(`"`)
-
-	// Inject claimedAt (camelCase)
-	buf.WriteString(`,"claimedAt":"`)
-	if progress.ClaimedAt != nil {
-		buf.WriteString(progress.ClaimedAt.Format(time.RFC3339))
-	}
-	buf.WriteString(`"`)
-
-	// Inject isActive (camelCase)
-	buf.WriteString(`,"isActive":`)
-	if progress.IsActive {
-		buf.WriteString(`true`)
+// Output format: ,"progress":5,"status":"in_progress","locked":false,"completedAt":"2025-01-15T10:30:00Z","firstCompletedAt":"2025-01-15T10:30:00Z","claimedAt":null,...
+func appendProgressFields(
+	dst []byte,
+	progress *commonDomain.UserGoalProgress,
+	firstCompletedAt *time.Time,
+	hasPrerequisites bool,
+	activatable bool,
+	activationSource string,
+	now time.Time,
+) []byte {
+	if progress != nil && progress.Status != "" {
+		return appendProgressValues(dst, progress, firstCompletedAt, false, activatable, activationSource, now)
+	}
+
+	// No progress: defaults, with the next rotation expiry of a rotating goal
+	if expiresAt := noProgressExpiresAt(progress); expiresAt != nil {
+		return appendProgressValues(dst, &commonDomain.UserGoalProgress{
+			Status:    commonDomain.GoalStatusNotStarted,
+			ExpiresAt: expiresAt,
+		}, nil, hasPrerequisites, activatable, "", now)
+	}
+
+	switch {
+	case hasPrerequisites && activatable:
+		return append(dst, defaultLockedActivatableProgressFields...)
+	case hasPrerequisites:
+		return append(dst, defaultLockedProgressFields...)
+	case activatable:
+		return append(dst, defaultActivatableProgressFields...)
+	default:
+		return append(dst, defaultProgressFields...)
+	}
+}
+
+// appendProgressValues appends the progress fields of progress with the given
+// first completion (nil for completedAt) and goal lock state.
+func appendProgressValues(
+	dst []byte,
+	progress *commonDomain.UserGoalProgress,
+	firstCompletedAt *time.Time,
+	locked bool,
+	activatable bool,
+	activationSource string,
+	now time.Time,
+) []byte {
+	dst = append(dst, `,"progress":`...)
+	dst = strconv.AppendInt(dst, int64(progress.Progress), 10)
+
+	if field, ok := statusFields[progress.Status]; ok {
+		dst = append(dst, field...)
 	} else {
-		buf.WriteString(`false`)
+		dst = append(dst, `,"status":"`...)
+		dst = appendJSONString(dst, string(progress.Status))
+		dst = append(dst, '"')
 	}
 
-	// M5: Inject expiresAt (camelCase) - pre-computed on display copy
-	buf.WriteString(`,"expiresAt":"`)
+	dst = append(dst, `,"locked":`...)
+	dst = strconv.AppendBool(dst, locked)
+	dst = appendTimestampField(dst, `,"completedAt":`, progress.CompletedAt)
+	if firstCompletedAt == nil {
+		firstCompletedAt = progress.CompletedAt
+	}
+	dst = appendTimestampField(dst, `,"firstCompletedAt":`, firstCompletedAt)
+	dst = appendTimestampField(dst, `,"claimedAt":`, progress.ClaimedAt)
+	dst = append(dst, `,"isActive":`...)
+	dst = strconv.AppendBool(dst, progress.IsActive)
+
+	// M5: expiresAt and expiresInSeconds - pre-computed on display copy
+	dst = appendTimestampField(dst, `,"expiresAt":`, progress.ExpiresAt)
+	dst = append(dst, `,"expiresInSeconds":`...)
+	var seconds int64
 	if progress.ExpiresAt != nil {
-		buf.WriteString(progress.ExpiresAt.Format(time.RFC3339))
+		seconds = max(int64(progress.ExpiresAt.Sub(now).Seconds()), 0)
 	}
-	buf.WriteString(`"`)
+	dst = strconv.AppendInt(dst, seconds, 10)
+
+	dst = appendTimestampField(dst, `,"assignedAt":`, progress.AssignedAt)
+	dst = append(dst, `,"activatable":`...)
+	dst = strconv.AppendBool(dst, activatable)
+	dst = append(dst, `,"activationSource":"`...)
+	dst = appendJSONString(dst, activationSource)
+	return append(dst, '"')
+}
 
-	// M5: Inject expiresInSeconds - computed from ExpiresAt
-	buf.WriteString(`,"expiresInSeconds":`)
-	if progress.ExpiresAt != nil {
-		seconds := int64(progress.ExpiresAt.Sub(time.Now().UTC()).Seconds())
-		if seconds < 0 {
-			seconds = 0
+// appendTimestampField appends a timestamp field opened by field, null for nil.
+func appendTimestampField(dst []byte, field string, t *time.Time) []byte {
+	dst = append(dst, field...)
+	return mapper.AppendNullableTimestamp(dst, t)
+}
+
+// appendSteps appends the steps field of a multi-step goal.
+func appendSteps(dst []byte, steps []*pb.GoalStep) []byte {
+	dst = append(dst, `,"steps":[`...)
+	for i, step := range steps {
+		if i > 0 {
+			dst = append(dst, ',')
 		}
-		buf.WriteString(strconv.FormatInt(seconds, 10))
+		dst = append(dst, `{"statCode":"`...)
+		dst = appendJSONString(dst, step.StatCode)
+		dst = append(dst, `","operator":"`...)
+		dst = appendJSONString(dst, step.Operator)
+		dst = append(dst, `","targetValue":`...)
+		dst = strconv.AppendInt(dst, int64(step.TargetValue), 10)
+		dst = append(dst, `,"progress":`...)
+		dst = strconv.AppendInt(dst, int64(step.Progress), 10)
+		dst = append(dst, `,"completed":`...)
+		dst = strconv.AppendBool(dst, step.Completed)
+		dst = append(dst, '}')
+	}
+	return append(dst, ']')
+}
+
+// appendClaimBlockers appends the claimBlockers field of a goal.
+func appendClaimBlockers(dst []byte, blockers []string) []byte {
+	dst = append(dst, `,"claimBlockers":[`...)
+	for i, blocker := range blockers {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '"')
+		dst = appendJSONString(dst, blocker)
+		dst = append(dst, '"')
+	}
+	return append(dst, ']')
+}
+
+// appendRepeat appends the repeat field of a repeatable goal.
+func appendRepeat(dst []byte, repeat *pb.GoalRepeat) []byte {
+	dst = append(dst, `,"repeat":{"timesClaimed":`...)
+	dst = strconv.AppendInt(dst, int64(repeat.TimesClaimed), 10)
+	dst = append(dst, `,"availableAt":`...)
+	if repeat.AvailableAt != nil {
+		availableAt := repeat.AvailableAt.AsTime()
+		dst = mapper.AppendNullableTimestamp(dst, &availableAt)
 	} else {
-		buf.WriteString(`0`)
+		dst = append(dst, "null"...)
+	}
+	return append(dst, '}')
+}
+
+// AppendStepsToGoal appends the steps of a multi-step goal to a goal JSON
+// object, after its progress fields, as protojson writes Goal.steps. A goal
+// without steps is returned unchanged.
+//
+// Output format: {...,"steps":[{"statCode":"wins","operator":">=","targetValue":3,"progress":1,"completed":false}]}
+func AppendStepsToGoal(goalJSON []byte, steps []*pb.GoalStep) []byte {
+	closingBraceIdx := bytes.LastIndexByte(goalJSON, '}')
+	if len(steps) == 0 || closingBraceIdx == -1 {
+		return goalJSON
 	}
 
-	return buf.Bytes()
+	result := make([]byte, 0, len(goalJSON)+len(steps)*100+12)
+	result = append(result, goalJSON[:closingBraceIdx]...)
+	result = appendSteps(result, steps)
+	return append(result, '}')
+}
+
+// AppendClaimBlockersToGoal appends the claim blockers of a goal to a goal JSON
+// object, as protojson writes Goal.claim_blockers. A goal without blockers is
+// returned unchanged.
+//
+// Output format: {...,"claimBlockers":["not_completed","prerequisites_not_met:goal-1"]}
+func AppendClaimBlockersToGoal(goalJSON []byte, blockers []string) []byte {
+	closingBraceIdx := bytes.LastIndexByte(goalJSON, '}')
+	if len(blockers) == 0 || closingBraceIdx == -1 {
+		return goalJSON
+	}
+
+	result := make([]byte, 0, len(goalJSON)+len(blockers)*32+20)
+	result = append(result, goalJSON[:closingBraceIdx]...)
+	result = appendClaimBlockers(result, blockers)
+	return append(result, '}')
+}
+
+// AppendRepeatToGoal appends the claim history of a repeatable goal to a goal
+// JSON object, as Goal.repeat. availableAt is null when the goal was never
+// claimed.
+//
+// Output format: {...,"repeat":{"timesClaimed":2,"availableAt":"2025-01-01T12:00:00Z"}}
+func AppendRepeatToGoal(goalJSON []byte, repeat *pb.GoalRepeat) []byte {
+	closingBraceIdx := bytes.LastIndexByte(goalJSON, '}')
+	if repeat == nil || closingBraceIdx == -1 {
+		return goalJSON
+	}
+
+	result := make([]byte, 0, len(goalJSON)+64)
+	result = append(result, goalJSON[:closingBraceIdx]...)
+	result = appendRepeat(result, repeat)
+	return append(result, '}')
+}
+
+// unlockedChallengeFields are the lock fields of a challenge that is not locked.
+var unlockedChallengeFields = []byte(`,"locked":false,"lockedReason":""`)
+
+// writeChallengeLockFields writes the lock fields of a challenge, locked when it
+// has an entry in locks (challenge ID -> lock reason).
+//
+// Output format: ,"locked":true,"lockedReason":"prerequisite challenges not completed: season-1"
+func writeChallengeLockFields(buf *bytes.Buffer, challengeID string, locks map[string]string) {
+	reason, locked := locks[challengeID]
+	if !locked {
+		buf.Write(unlockedChallengeFields)
+		return
+	}
+
+	buf.WriteString(`,"locked":true,"lockedReason":"`)
+	buf.WriteString(escapeJSONString(reason))
+	buf.WriteByte('"')
 }
 
 // InjectProgressIntoChallenge injects user progress into multiple goals within a challenge JSON.
@@ -153,6 +345,8 @@ func buildProgressFields(progress *commonDomain.UserGoalProgress) []byte {
 // Args:
 //   - staticJSON: Pre-serialized challenge JSON from cache
 //   - userProgress: Map of goal ID -> user progress data
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
 //   - goalCount: Number of goals in challenge (for optimal buffer allocation)
 //
 // Returns:
@@ -173,6 +367,8 @@ func buildProgressFields(progress *commonDomain.UserGoalProgress) []byte {
 func InjectProgressIntoChallenge(
 	staticJSON []byte,
 	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
 	goalCount int,
 ) ([]byte, error) {
 	// Find "goals" field in JSON
@@ -206,7 +402,7 @@ func InjectProgressIntoChallenge(
 
 	// Process each goal in the array
 	goalsArrayJSON := staticJSON[arrayStartIdx+1 : arrayEndIdx]
-	if err := processGoalsArray(result, goalsArrayJSON, userProgress); err != nil {
+	if err := processGoalsArray(result, goalsArrayJSON, userProgress, activatable, activationSources); err != nil {
 		return nil, fmt.Errorf("failed to process goals array: %w", err)
 	}
 
@@ -216,12 +412,80 @@ func InjectProgressIntoChallenge(
 	return result.Bytes(), nil
 }
 
+// AppendGoalsToChallenge appends already-injected goal objects to the end of a
+// challenge's goals array.
+//
+// Used for hidden goals, which are not part of the pre-serialized challenge JSON
+// and are only added for users who unlocked them. If the challenge JSON has no
+// "goals" field (protojson omits empty arrays), one is added.
+//
+// Args:
+//   - challengeJSON: Challenge JSON (typically after InjectProgressIntoChallenge)
+//   - goals: Goal JSON objects to append, in order
+//
+// Returns:
+//   - []byte: Challenge JSON with the goals appended
+//   - error: If JSON structure is invalid
+func AppendGoalsToChallenge(challengeJSON []byte, goals [][]byte) ([]byte, error) {
+	if len(goals) == 0 {
+		return challengeJSON, nil
+	}
+
+	size := len(challengeJSON) + len(`,"goals":[]`)
+	for _, goal := range goals {
+		size += len(goal) + 1
+	}
+	result := bytes.NewBuffer(make([]byte, 0, size))
+
+	var insertIdx int
+	goalsIdx := bytes.Index(challengeJSON, []byte(`"goals":`))
+	if goalsIdx == -1 {
+		insertIdx = bytes.LastIndexByte(challengeJSON, '}')
+		if insertIdx == -1 {
+			return nil, fmt.Errorf("invalid challenge structure: missing closing brace")
+		}
+		result.Write(challengeJSON[:insertIdx])
+		result.WriteString(`,"goals":[`)
+	} else {
+		arrayStartIdx := bytes.IndexByte(challengeJSON[goalsIdx:], '[')
+		if arrayStartIdx == -1 {
+			return nil, fmt.Errorf("invalid goals structure: missing opening bracket")
+		}
+		arrayStartIdx += goalsIdx
+
+		insertIdx = findMatchingClosingBracket(challengeJSON, arrayStartIdx)
+		if insertIdx == -1 {
+			return nil, fmt.Errorf("invalid goals structure: missing closing bracket")
+		}
+		result.Write(challengeJSON[:insertIdx])
+		if len(bytes.TrimSpace(challengeJSON[arrayStartIdx+1:insertIdx])) > 0 {
+			result.WriteByte(',')
+		}
+	}
+
+	for i, goal := range goals {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+		result.Write(goal)
+	}
+
+	if goalsIdx == -1 {
+		result.WriteByte(']')
+	}
+	result.Write(challengeJSON[insertIdx:])
+
+	return result.Bytes(), nil
+}
+
 // processGoalsArray processes each goal in the goals array and injects progress.
 //
 // Args:
 //   - result: Buffer to write processed goals to
 //   - goalsArrayJSON: JSON content between [ and ] of goals array
 //   - userProgress: Map of goal ID -> user progress
+//   - activatable: IDs of the goals the user can activate (may be nil)
+//   - activationSources: Map of goal ID -> activation source (may be nil)
 //
 // Returns:
 //   - error: If goal structure is invalid
@@ -229,6 +493,8 @@ func processGoalsArray(
 	result *bytes.Buffer,
 	goalsArrayJSON []byte,
 	userProgress map[string]*commonDomain.UserGoalProgress,
+	activatable map[string]bool,
+	activationSources map[string]string,
 ) error {
 	// Parse goals by properly tracking brace nesting depth
 	// Goals can have nested objects (requirement, reward), so we need to match braces correctly
@@ -285,7 +551,7 @@ func processGoalsArray(
 				progress := userProgress[goalID]
 
 				// Inject progress into this goal
-				processedGoal := InjectProgressIntoGoal(goalJSON, progress)
+				processedGoal := InjectProgressIntoGoal(goalJSON, progress, activatable[goalID], activationSources[goalID])
 
 				// Write to result
 				if goalIndex > 0 {
@@ -378,52 +644,53 @@ func findMatchingClosingBracket(json []byte, openIdx int) int {
 // Returns:
 //   - string: Escaped string safe for JSON
 func escapeJSONString(s string) string {
-	// For safety, we escape:
-	// - Backslash: \ -> \\
-	// - Double quote: " -> \"
-	// - Control characters: \n, \r, \t
-	//
-	// Note: For production, consider using a proper JSON string escaper
-	// or encoding/json's string encoding logic
-
 	// Quick check: if string has no special chars, return as-is
-	needsEscape := false
+	if !needsJSONEscape(s) {
+		return s
+	}
+	return string(appendJSONString(make([]byte, 0, len(s)*2), s))
+}
+
+// needsJSONEscape reports whether s has characters appendJSONString escapes.
+func needsJSONEscape(s string) bool {
 	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c == '\\' || c == '"' || c == '\n' || c == '\r' || c == '\t' || c < 0x20 {
-			needsEscape = true
-			break
+		if c := s[i]; c == '\\' || c == '"' || c < 0x20 {
+			return true
 		}
 	}
+	return false
+}
 
-	if !needsEscape {
-		return s
+// appendJSONString appends s to dst escaped for a JSON string:
+//   - Backslash: \ -> \\
+//   - Double quote: " -> \"
+//   - Control characters: \n, \r, \t, and \u00XX for the others
+func appendJSONString(dst []byte, s string) []byte {
+	if !needsJSONEscape(s) {
+		return append(dst, s...)
 	}
 
-	// Escape special characters
-	result := make([]byte, 0, len(s)*2)
+	const hex = "0123456789abcdef"
 	for i := 0; i < len(s); i++ {
 		c := s[i]
 		switch c {
 		case '\\':
-			result = append(result, '\\', '\\')
+			dst = append(dst, '\\', '\\')
 		case '"':
-			result = append(result, '\\', '"')
+			dst = append(dst, '\\', '"')
 		case '\n':
-			result = append(result, '\\', 'n')
+			dst = append(dst, '\\', 'n')
 		case '\r':
-			result = append(result, '\\', 'r')
+			dst = append(dst, '\\', 'r')
 		case '\t':
-			result = append(result, '\\', 't')
+			dst = append(dst, '\\', 't')
 		default:
 			if c < 0x20 {
-				// Control character - escape as \uXXXX
-				result = append(result, []byte(fmt.Sprintf("\\u%04x", c))...)
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
 			} else {
-				result = append(result, c)
+				dst = append(dst, c)
 			}
 		}
 	}
-
-	return string(result)
+	return dst
 }
@@ -6,17 +6,22 @@ package response
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 
+	pb "extend-challenge-service/pkg/pb"
+
 	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TestInjectProgressIntoGoal_NoProgress tests injecting defaults when no progress exists
 func TestInjectProgressIntoGoal_NoProgress(t *testing.T) {
 	staticJSON := []byte(`{"goalId":"g1","name":"Test Goal","targetValue":10}`)
 
-	result := InjectProgressIntoGoal(staticJSON, nil)
+	result := InjectProgressIntoGoal(staticJSON, nil, false, "")
 
 	// Validate JSON is parseable
 	var goal map[string]interface{}
@@ -31,11 +36,11 @@ func TestInjectProgressIntoGoal_NoProgress(t *testing.T) {
 	if goal["status"].(string) != "not_started" {
 		t.Errorf("Expected status=not_started, got %v", goal["status"])
 	}
-	if goal["completedAt"].(string) != "" {
-		t.Errorf("Expected completed_at empty, got %v", goal["completedAt"])
+	if goal["completedAt"] != nil {
+		t.Errorf("Expected completed_at null, got %v", goal["completedAt"])
 	}
-	if goal["claimedAt"].(string) != "" {
-		t.Errorf("Expected claimed_at empty, got %v", goal["claimedAt"])
+	if goal["claimedAt"] != nil {
+		t.Errorf("Expected claimed_at null, got %v", goal["claimedAt"])
 	}
 
 	// Validate original fields are preserved
@@ -63,7 +68,7 @@ func TestInjectProgressIntoGoal_WithProgress(t *testing.T) {
 		ClaimedAt:   nil,
 	}
 
-	result := InjectProgressIntoGoal(staticJSON, progress)
+	result := InjectProgressIntoGoal(staticJSON, progress, false, "")
 
 	// Validate JSON is parseable
 	var goal map[string]interface{}
@@ -81,8 +86,61 @@ func TestInjectProgressIntoGoal_WithProgress(t *testing.T) {
 	if goal["completedAt"].(string) != "2025-01-15T10:30:00Z" {
 		t.Errorf("Expected completed_at=2025-01-15T10:30:00Z, got %v", goal["completedAt"])
 	}
-	if goal["claimedAt"].(string) != "" {
-		t.Errorf("Expected claimed_at empty, got %v", goal["claimedAt"])
+	if goal["claimedAt"] != nil {
+		t.Errorf("Expected claimed_at null, got %v", goal["claimedAt"])
+	}
+}
+
+// TestInjectProgressIntoGoal_NonUTCTimestamps tests that timestamps in other zones are written in UTC
+func TestInjectProgressIntoGoal_NonUTCTimestamps(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	original := time.Local
+	time.Local = newYork
+	defer func() { time.Local = original }()
+
+	staticJSON := []byte(`{"goalId":"g1","name":"Test Goal","targetValue":10}`)
+
+	// 2025-03-09 is the DST transition in New York (EST before, EDT after)
+	completedAt := time.Date(2025, 3, 9, 1, 30, 0, 0, newYork)
+	claimedAt := time.Date(2025, 3, 9, 3, 30, 0, 250, newYork)
+	expiresAt := time.Date(2025, 3, 10, 4, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	progress := &commonDomain.UserGoalProgress{
+		GoalID:      "g1",
+		Progress:    10,
+		Status:      "claimed",
+		CompletedAt: &completedAt,
+		ClaimedAt:   &claimedAt,
+		ExpiresAt:   &expiresAt,
+	}
+
+	result := InjectProgressIntoGoal(staticJSON, progress, false, "")
+
+	var goal map[string]interface{}
+	if err := json.Unmarshal(result, &goal); err != nil {
+		t.Fatalf("Result is not valid JSON: %v", err)
+	}
+
+	expected := map[string]time.Time{
+		"completedAt": completedAt,
+		"claimedAt":   claimedAt,
+		"expiresAt":   expiresAt,
+	}
+	for field, want := range expected {
+		got := goal[field].(string)
+		if got != want.UTC().Truncate(time.Second).Format(time.RFC3339) {
+			t.Errorf("Expected %s in UTC, got %v", field, got)
+		}
+
+		parsed, err := time.Parse(time.RFC3339, got)
+		if err != nil {
+			t.Fatalf("%s is not RFC3339: %v", field, err)
+		}
+		if !parsed.Equal(want.Truncate(time.Second)) {
+			t.Errorf("%s round trip mismatch: got %v, want %v", field, parsed, want)
+		}
 	}
 }
 
@@ -100,7 +158,7 @@ func TestInjectProgressIntoGoal_Completed(t *testing.T) {
 		ClaimedAt:   &claimedAt,
 	}
 
-	result := InjectProgressIntoGoal(staticJSON, progress)
+	result := InjectProgressIntoGoal(staticJSON, progress, false, "")
 
 	// Validate JSON
 	var goal map[string]interface{}
@@ -133,7 +191,7 @@ func TestInjectProgressIntoGoal_ComplexJSON(t *testing.T) {
 		Status:   "in_progress",
 	}
 
-	result := InjectProgressIntoGoal(staticJSON, progress)
+	result := InjectProgressIntoGoal(staticJSON, progress, false, "")
 
 	// Validate JSON
 	var goal map[string]interface{}
@@ -203,7 +261,7 @@ func TestInjectProgressIntoChallenge(t *testing.T) {
 
 	goalCount := 2
 
-	result, err := InjectProgressIntoChallenge(staticJSON, progress, goalCount)
+	result, err := InjectProgressIntoChallenge(staticJSON, progress, nil, nil, goalCount)
 	if err != nil {
 		t.Fatalf("InjectProgressIntoChallenge failed: %v", err)
 	}
@@ -259,7 +317,7 @@ func TestInjectProgressIntoChallenge_NoGoals(t *testing.T) {
 
 	goalCount := 0
 
-	result, err := InjectProgressIntoChallenge(staticJSON, nil, goalCount)
+	result, err := InjectProgressIntoChallenge(staticJSON, nil, nil, nil, goalCount)
 	if err != nil {
 		t.Fatalf("InjectProgressIntoChallenge failed: %v", err)
 	}
@@ -291,7 +349,7 @@ func TestInjectProgressIntoChallenge_MissingProgress(t *testing.T) {
 
 	goalCount := 2
 
-	result, err := InjectProgressIntoChallenge(staticJSON, progress, goalCount)
+	result, err := InjectProgressIntoChallenge(staticJSON, progress, nil, nil, goalCount)
 	if err != nil {
 		t.Fatalf("InjectProgressIntoChallenge failed: %v", err)
 	}
@@ -427,7 +485,7 @@ func TestInjectProgressIntoGoal_InvalidJSON(t *testing.T) {
 	// Missing closing brace
 	staticJSON := []byte(`{"goalId":"g1","name":"Test"`)
 
-	result := InjectProgressIntoGoal(staticJSON, nil)
+	result := InjectProgressIntoGoal(staticJSON, nil, false, "")
 
 	// Should return unchanged (invalid JSON protection)
 	if string(result) != string(staticJSON) {
@@ -436,6 +494,136 @@ func TestInjectProgressIntoGoal_InvalidJSON(t *testing.T) {
 }
 
 // BenchmarkInjectProgressIntoGoal benchmarks single goal injection
+// TestInjectProgressIntoGoal_GoalStates pins the injected fields for the states a
+// client renders differently: inactive (activate button), active with no
+// progress, in progress, and completed.
+func TestInjectProgressIntoGoal_GoalStates(t *testing.T) {
+	staticJSON := []byte(`{"goalId":"g1"}`)
+	assignedAt := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		progress    *commonDomain.UserGoalProgress
+		activatable bool
+		want        string
+	}{
+		{
+			name:        "inactive",
+			activatable: true,
+			want:        `{"goalId":"g1","progress":0,"status":"not_started","locked":false,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":false,"expiresAt":null,"expiresInSeconds":0,"assignedAt":null,"activatable":true,"activationSource":""}`,
+		},
+		{
+			name:     "active, no progress",
+			progress: &commonDomain.UserGoalProgress{Status: "not_started", IsActive: true, AssignedAt: &assignedAt},
+			want:     `{"goalId":"g1","progress":0,"status":"not_started","locked":false,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":true,"expiresAt":null,"expiresInSeconds":0,"assignedAt":"2025-01-15T09:00:00Z","activatable":false,"activationSource":""}`,
+		},
+		{
+			name:     "in progress",
+			progress: &commonDomain.UserGoalProgress{Progress: 4, Status: "in_progress", IsActive: true, AssignedAt: &assignedAt},
+			want:     `{"goalId":"g1","progress":4,"status":"in_progress","locked":false,"completedAt":null,"firstCompletedAt":null,"claimedAt":null,"isActive":true,"expiresAt":null,"expiresInSeconds":0,"assignedAt":"2025-01-15T09:00:00Z","activatable":false,"activationSource":""}`,
+		},
+		{
+			name:     "completed",
+			progress: &commonDomain.UserGoalProgress{Progress: 10, Status: "completed", IsActive: true, AssignedAt: &assignedAt, CompletedAt: &completedAt},
+			want:     `{"goalId":"g1","progress":10,"status":"completed","locked":false,"completedAt":"2025-01-15T10:30:00Z","firstCompletedAt":"2025-01-15T10:30:00Z","claimedAt":null,"isActive":true,"expiresAt":null,"expiresInSeconds":0,"assignedAt":"2025-01-15T09:00:00Z","activatable":false,"activationSource":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(InjectProgressIntoGoal(staticJSON, tt.progress, tt.activatable, ""))
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestInjectProgressIntoGoal_Locked verifies a goal with prerequisites is
+// locked until the user has progress on it, as in mapper.GoalToProto.
+func TestInjectProgressIntoGoal_Locked(t *testing.T) {
+	staticJSON := []byte(`{"goalId":"g2","prerequisites":["g1"]}`)
+
+	tests := []struct {
+		name       string
+		staticJSON []byte
+		progress   *commonDomain.UserGoalProgress
+		want       bool
+	}{
+		{name: "prerequisites, no progress", staticJSON: staticJSON, want: true},
+		{name: "prerequisites, progress", staticJSON: staticJSON, progress: &commonDomain.UserGoalProgress{Status: "in_progress"}, want: false},
+		{name: "no prerequisites", staticJSON: []byte(`{"goalId":"g1","description":"see \"prerequisites\":["}`), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var goal map[string]interface{}
+			result := InjectProgressIntoGoal(tt.staticJSON, tt.progress, true, "")
+			if err := json.Unmarshal(result, &goal); err != nil {
+				t.Fatalf("Result is not valid JSON: %v\nJSON: %s", err, result)
+			}
+			if goal["locked"] != tt.want {
+				t.Errorf("Expected locked=%v, got %v", tt.want, goal["locked"])
+			}
+			if goal["activatable"] != true {
+				t.Errorf("Expected activatable=true, got %v", goal["activatable"])
+			}
+		})
+	}
+}
+
+// TestInjectProgressIntoGoal_NoProgressExpiry verifies a NoProgress row is
+// written as defaults with its expiry.
+func TestInjectProgressIntoGoal_NoProgressExpiry(t *testing.T) {
+	staticJSON := []byte(`{"goalId":"g1","prerequisites":["g0"]}`)
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+
+	result := InjectProgressIntoGoal(staticJSON, NoProgress("g1", &expiresAt), false, "ignored")
+
+	var goal map[string]interface{}
+	if err := json.Unmarshal(result, &goal); err != nil {
+		t.Fatalf("Result is not valid JSON: %v\nJSON: %s", err, result)
+	}
+	if goal["status"] != "not_started" || goal["progress"] != float64(0) || goal["isActive"] != false {
+		t.Errorf("Expected default progress fields, got %s", result)
+	}
+	if goal["locked"] != true {
+		t.Errorf("Expected locked=true, got %v", goal["locked"])
+	}
+	if goal["expiresAt"] != expiresAt.Format(time.RFC3339) {
+		t.Errorf("Expected expiresAt %s, got %v", expiresAt.Format(time.RFC3339), goal["expiresAt"])
+	}
+	if seconds := goal["expiresInSeconds"].(float64); seconds <= 0 || seconds > 3600 {
+		t.Errorf("Expected expiresInSeconds in (0, 3600], got %v", seconds)
+	}
+	if goal["activationSource"] != "" {
+		t.Errorf("Expected no activationSource, got %v", goal["activationSource"])
+	}
+}
+
+// TestInjectProgressIntoGoal_ActivationSource verifies the activation source is
+// injected and escaped.
+func TestInjectProgressIntoGoal_ActivationSource(t *testing.T) {
+	staticJSON := []byte(`{"goalId":"g1"}`)
+	progress := &commonDomain.UserGoalProgress{Status: "in_progress", IsActive: true}
+
+	result := InjectProgressIntoGoal(staticJSON, progress, false, "random")
+
+	var goal map[string]interface{}
+	if err := json.Unmarshal(result, &goal); err != nil {
+		t.Fatalf("Result is not valid JSON: %v\nJSON: %s", err, result)
+	}
+	if goal["activationSource"] != "random" {
+		t.Errorf("Expected activationSource 'random', got %v", goal["activationSource"])
+	}
+
+	result = InjectProgressIntoGoal(staticJSON, progress, false, `bad"source`)
+	if err := json.Unmarshal(result, &goal); err != nil {
+		t.Fatalf("Escaped source is not valid JSON: %v\nJSON: %s", err, result)
+	}
+}
+
 func BenchmarkInjectProgressIntoGoal(b *testing.B) {
 	staticJSON := []byte(`{"goalId":"g1","name":"Test Goal","description":"A test goal","targetValue":100,"requirements":{"statCode":"DAILY_LOGIN","operator":"GTE","targetValue":7},"rewards":[{"type":"ITEM","rewardId":"GOLD","quantity":100}],"prerequisites":[],"locked":false}`)
 
@@ -449,7 +637,7 @@ func BenchmarkInjectProgressIntoGoal(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = InjectProgressIntoGoal(staticJSON, progress)
+		_ = InjectProgressIntoGoal(staticJSON, progress, false, "")
 	}
 }
 
@@ -469,6 +657,127 @@ func BenchmarkInjectProgressIntoChallenge(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = InjectProgressIntoChallenge(staticJSON, progress, goalCount)
+		_, _ = InjectProgressIntoChallenge(staticJSON, progress, nil, nil, goalCount)
+	}
+}
+
+func TestAppendGoalsToChallenge(t *testing.T) {
+	goals := [][]byte{[]byte(`{"goalId":"g2"}`), []byte(`{"goalId":"g3"}`)}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"existing goals", `{"challengeId":"c","goals":[{"goalId":"g1"}]}`, `{"challengeId":"c","goals":[{"goalId":"g1"},{"goalId":"g2"},{"goalId":"g3"}]}`},
+		{"empty goals array", `{"challengeId":"c","goals":[]}`, `{"challengeId":"c","goals":[{"goalId":"g2"},{"goalId":"g3"}]}`},
+		{"no goals field", `{"challengeId":"c","name":"C"}`, `{"challengeId":"c","name":"C","goals":[{"goalId":"g2"},{"goalId":"g3"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AppendGoalsToChallenge([]byte(tt.input), goals)
+			if err != nil {
+				t.Fatalf("AppendGoalsToChallenge failed: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("got %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAppendGoalsToChallenge_NoGoals(t *testing.T) {
+	input := []byte(`{"challengeId":"c","goals":[{"goalId":"g1"}]}`)
+
+	result, err := AppendGoalsToChallenge(input, nil)
+	if err != nil {
+		t.Fatalf("AppendGoalsToChallenge failed: %v", err)
+	}
+	if string(result) != string(input) {
+		t.Errorf("expected input unchanged, got %s", result)
+	}
+}
+
+func TestAppendGoalsToChallenge_InvalidJSON(t *testing.T) {
+	if _, err := AppendGoalsToChallenge([]byte(`{"goals":[{"goalId":"g1"}`), [][]byte{[]byte(`{}`)}); err == nil {
+		t.Error("expected error for unterminated goals array")
+	}
+}
+
+func TestAppendStepsToGoal(t *testing.T) {
+	input := []byte(`{"goalId":"g1","progress":3}`)
+	steps := []*pb.GoalStep{
+		{StatCode: "wins", Operator: ">=", TargetValue: 3, Progress: 3, Completed: true},
+		{StatCode: "damage", Operator: ">=", TargetValue: 10000, Progress: 250},
+	}
+
+	result := AppendStepsToGoal(input, steps)
+
+	expected := `{"goalId":"g1","progress":3,"steps":[` +
+		`{"statCode":"wins","operator":">=","targetValue":3,"progress":3,"completed":true},` +
+		`{"statCode":"damage","operator":">=","targetValue":10000,"progress":250,"completed":false}]}`
+	var got, want map[string]interface{}
+	if err := json.Unmarshal(result, &got); err != nil {
+		t.Fatalf("Result is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatalf("Expected is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+}
+
+func TestAppendStepsToGoal_NoSteps(t *testing.T) {
+	input := []byte(`{"goalId":"g1"}`)
+
+	if result := AppendStepsToGoal(input, nil); string(result) != string(input) {
+		t.Errorf("expected input unchanged, got %s", result)
+	}
+}
+
+func TestAppendClaimBlockersToGoal(t *testing.T) {
+	input := []byte(`{"goalId":"g1","status":"in_progress"}`)
+
+	result := AppendClaimBlockersToGoal(input, []string{"not_completed", "prerequisites_not_met:g0"})
+
+	expected := `{"goalId":"g1","status":"in_progress","claimBlockers":["not_completed","prerequisites_not_met:g0"]}`
+	if string(result) != expected {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+}
+
+func TestAppendClaimBlockersToGoal_NoBlockers(t *testing.T) {
+	input := []byte(`{"goalId":"g1"}`)
+
+	if result := AppendClaimBlockersToGoal(input, nil); string(result) != string(input) {
+		t.Errorf("expected input unchanged, got %s", result)
+	}
+}
+
+func TestAppendRepeatToGoal(t *testing.T) {
+	input := []byte(`{"goalId":"g1","status":"not_started"}`)
+	availableAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	result := AppendRepeatToGoal(input, &pb.GoalRepeat{TimesClaimed: 2, AvailableAt: timestamppb.New(availableAt)})
+
+	expected := `{"goalId":"g1","status":"not_started","repeat":{"timesClaimed":2,"availableAt":"2025-01-01T12:00:00Z"}}`
+	if string(result) != expected {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+}
+
+func TestAppendRepeatToGoal_NeverClaimed(t *testing.T) {
+	input := []byte(`{"goalId":"g1"}`)
+
+	result := AppendRepeatToGoal(input, &pb.GoalRepeat{})
+
+	expected := `{"goalId":"g1","repeat":{"timesClaimed":0,"availableAt":null}}`
+	if string(result) != expected {
+		t.Errorf("got %s, want %s", result, expected)
+	}
+	if result := AppendRepeatToGoal(input, nil); string(result) != string(input) {
+		t.Errorf("expected input unchanged, got %s", result)
 	}
 }
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package selftest runs the startup checks behind the --self-test flag: a
+// smoke test for a built image that exercises the critical startup paths
+// without binding ports or serving traffic.
+package selftest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"extend-challenge-service/pkg/common/version"
+)
+
+// Check statuses reported in a Result.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// ErrSkipped is returned by a Check that does not apply to the current
+// configuration, e.g. the IAM login in mock reward mode. It does not fail the
+// self-test.
+var ErrSkipped = errors.New("not applicable")
+
+// Check is one step of the self-test.
+type Check struct {
+	Name string
+	// Timeout bounds the check. A check that does not honour its context is
+	// abandoned when the timeout expires and reported as failed.
+	Timeout time.Duration
+	// Run performs the check and returns a short detail for the report.
+	Run func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one Check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the JSON document printed by the self-test.
+type Report struct {
+	Version version.Info `json:"version"`
+	Passed  bool         `json:"passed"`
+	Checks  []Result     `json:"checks"`
+}
+
+// Run executes checks in order. Later checks build on earlier ones (the
+// migrations need the database, the cache needs the config), so once a check
+// fails the remaining ones are reported as skipped.
+func Run(ctx context.Context, checks []Check) *Report {
+	report := &Report{
+		Version: version.Get(),
+		Passed:  true,
+		Checks:  make([]Result, 0, len(checks)),
+	}
+
+	for _, check := range checks {
+		if !report.Passed {
+			report.Checks = append(report.Checks, Result{
+				Name:   check.Name,
+				Status: StatusSkipped,
+				Detail: "an earlier check failed",
+			})
+			continue
+		}
+
+		start := time.Now()
+		detail, err := runWithTimeout(ctx, check)
+		result := Result{
+			Name:       check.Name,
+			Status:     StatusPassed,
+			Detail:     detail,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		switch {
+		case errors.Is(err, ErrSkipped):
+			result.Status = StatusSkipped
+		case err != nil:
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+// runWithTimeout runs the check in its own goroutine so that calls which take
+// no context (e.g. the SDK login) still cannot hang the self-test.
+func runWithTimeout(ctx context.Context, check Check) (string, error) {
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, check.Timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		detail, err := check.Run(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.detail, out.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("timed out after %s: %w", check.Timeout, ctx.Err())
+	}
+}
+
+// Write prints the report as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// ExitCode is 0 when every check passed or was skipped as not applicable, 1 otherwise.
+func (r *Report) ExitCode() int {
+	if r.Passed {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCheck returns a check that records its call in calls.
+func fakeCheck(name string, calls *[]string, detail string, err error) Check {
+	return Check{
+		Name:    name,
+		Timeout: time.Second,
+		Run: func(context.Context) (string, error) {
+			*calls = append(*calls, name)
+			return detail, err
+		},
+	}
+}
+
+func statuses(report *Report) map[string]string {
+	got := make(map[string]string, len(report.Checks))
+	for _, result := range report.Checks {
+		got[result.Name] = result.Status
+	}
+	return got
+}
+
+func TestRun_AllPassed(t *testing.T) {
+	var calls []string
+	report := Run(context.Background(), []Check{
+		fakeCheck("database", &calls, "connected", nil),
+		fakeCheck("migrations", &calls, "0 pending migrations", nil),
+	})
+
+	assert.True(t, report.Passed)
+	assert.Equal(t, 0, report.ExitCode())
+	assert.Equal(t, []string{"database", "migrations"}, calls, "checks run in order")
+	assert.Equal(t, map[string]string{"database": StatusPassed, "migrations": StatusPassed}, statuses(report))
+	assert.Equal(t, "connected", report.Checks[0].Detail)
+}
+
+func TestRun_FailureSkipsRemainingChecks(t *testing.T) {
+	var calls []string
+	report := Run(context.Background(), []Check{
+		fakeCheck("database", &calls, "", errors.New("connection refused")),
+		fakeCheck("migrations", &calls, "", nil),
+	})
+
+	assert.False(t, report.Passed)
+	assert.Equal(t, 1, report.ExitCode())
+	assert.Equal(t, []string{"database"}, calls)
+	assert.Equal(t, map[string]string{"database": StatusFailed, "migrations": StatusSkipped}, statuses(report))
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+}
+
+func TestRun_NotApplicableCheckDoesNotFail(t *testing.T) {
+	var calls []string
+	report := Run(context.Background(), []Check{
+		fakeCheck("iam_login", &calls, "mock reward mode with auth disabled", ErrSkipped),
+		fakeCheck("after", &calls, "", nil),
+	})
+
+	assert.True(t, report.Passed)
+	assert.Equal(t, map[string]string{"iam_login": StatusSkipped, "after": StatusPassed}, statuses(report))
+	assert.Empty(t, report.Checks[0].Error)
+	assert.Equal(t, []string{"iam_login", "after"}, calls)
+}
+
+func TestRun_TimeoutAbandonsCheckIgnoringContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	report := Run(context.Background(), []Check{{
+		Name:    "iam_login",
+		Timeout: 20 * time.Millisecond,
+		Run: func(context.Context) (string, error) {
+			<-release // Like an SDK call that takes no context
+			return "", nil
+		},
+	}})
+
+	assert.False(t, report.Passed)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, StatusFailed, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Error, "timed out after 20ms")
+}
+
+func TestReport_Write(t *testing.T) {
+	var calls []string
+	report := Run(context.Background(), []Check{fakeCheck("database", &calls, "connected", nil)})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, true, decoded["passed"])
+	assert.Contains(t, decoded, "version")
+	checks := decoded["checks"].([]any)
+	require.Len(t, checks, 1)
+	assert.Equal(t, "database", checks[0].(map[string]any)["name"])
+	assert.Equal(t, "passed", checks[0].(map[string]any)["status"])
+}
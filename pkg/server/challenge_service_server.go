@@ -8,12 +8,17 @@ import (
 	"context"
 	"database/sql"
 	stdErrors "errors"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	agsClient "extend-challenge-service/pkg/client"
 	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/common/version"
 	"extend-challenge-service/pkg/mapper"
 	pb "extend-challenge-service/pkg/pb"
+	serviceRepo "extend-challenge-service/pkg/repository"
 	"extend-challenge-service/pkg/service"
 
 	"github.com/AccelByte/extend-challenge-common/pkg/cache"
@@ -26,17 +31,391 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // ChallengeServiceServer implements the gRPC ServiceServer interface
 type ChallengeServiceServer struct {
 	pb.UnimplementedServiceServer
 
-	goalCache    cache.GoalCache
-	repo         repository.GoalRepository
-	rewardClient client.RewardClient
-	db           *sql.DB
-	namespace    string
+	goalCache        cache.GoalCache
+	repo             repository.GoalRepository
+	progressQueries  serviceRepo.ProgressQueryRepository
+	inactiveProgress serviceRepo.InactiveProgressRepository
+	lateProgress     serviceRepo.LateProgressRepository
+	stepProgress     serviceRepo.StepProgressRepository
+	progressInsert   serviceRepo.ProgressInsertRepository
+	claimOutbox      serviceRepo.ClaimOutboxRepository
+	claimStaleAfter  time.Duration
+	rewardGrants     serviceRepo.RewardGrantRepository
+	activationSrc    serviceRepo.ActivationSourceRepository
+	goalAdmin        serviceRepo.GoalAdminRepository
+	goalResets       serviceRepo.GoalResetRepository
+	eventOutbox      serviceRepo.EventOutboxRepository
+	goalSelections   serviceRepo.GoalSelectionRepository
+	rewardClient     client.RewardClient
+	db               *sql.DB
+	namespace        string
+	hiddenGoals      service.HiddenGoals
+	inactivePolicy   service.InactiveProgressPolicy
+	latePolicies     service.LateEventPolicies
+	matchGoals       service.MatchGoals
+	goalSteps        service.GoalSteps
+	rewardCaps       service.RewardCaps
+	rewardCapRepo    serviceRepo.RewardCapRepository
+	rewardDeliveries *service.RewardDeliveries
+	repeatableGoals  service.RepeatableGoals
+	repeatableRepo   serviceRepo.RepeatableGoalRepository
+	challengePrereqs service.ChallengePrerequisites
+	rollouts         service.ChallengeRollouts
+	autoRandomSelect *service.AutoRandomSelector
+	targetOverrides  service.TargetOverrides
+	configReloader   *service.ConfigReloader
+	claimCap         *service.ClaimCap
+	claimFreezes     *service.ClaimFreezes
+	failedGrants     *service.FailedGrants
+	grantNamespaces  *service.GrantNamespaces
+	goalStats        *service.GoalStats
+	serviceState     *service.ServiceStateReporter
+	backfills        *service.Backfills
+	bulkActivations  *service.BulkActivations
+	statusRecomputes *service.StatusRecomputes
+	unclaimedCounts  *service.UnclaimedCounts
+	challengeMetrics *service.ChallengeMetrics
+	sessionStart     bool
+	batchProgress    service.BatchProgressConfig
+	background       *common.WorkerPool
+	logger           logrus.FieldLogger
+	now              func() time.Time
+
+	healthComponents []HealthComponent
+}
+
+// HealthComponent is an extra dependency reported by HealthCheck, in addition
+// to the built-in database and goal cache checks.
+type HealthComponent struct {
+	Name string
+	// Critical components make HealthCheck return Unavailable when they fail.
+	Critical bool
+	// Check must be cheap: it runs on every /healthz and /readyz probe.
+	Check func(ctx context.Context) error
+}
+
+// AddHealthComponents registers additional components for HealthCheck.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) AddHealthComponents(components ...HealthComponent) {
+	s.healthComponents = append(s.healthComponents, components...)
+}
+
+// SetHiddenGoals sets the goals GetUserChallenges only lists once the user unlocks them.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetHiddenGoals(hiddenGoals service.HiddenGoals) {
+	s.hiddenGoals = hiddenGoals
+}
+
+// SetChallengePrerequisites sets the challenges that are locked until their
+// prerequisite challenges are completed. Goals of a locked challenge cannot be
+// claimed, selected or activated. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetChallengePrerequisites(prerequisites service.ChallengePrerequisites) {
+	s.challengePrereqs = prerequisites
+}
+
+// SetChallengeRollouts sets the challenges available to a percentage of users
+// only. Other users do not see them listed, the RPCs naming them answer as for a
+// challenge that does not exist, and InitializePlayer and StartSession do not
+// assign their default goals. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetChallengeRollouts(rollouts service.ChallengeRollouts) {
+	s.rollouts = rollouts
+}
+
+// SetTargetOverrides sets the per-segment goal targets. The segment of each call
+// is resolved by common.SegmentUnaryServerInterceptor. It must be called before
+// the server starts serving.
+func (s *ChallengeServiceServer) SetTargetOverrides(overrides service.TargetOverrides) {
+	s.targetOverrides = overrides
+}
+
+// segmentTargets returns the goal targets of the segment resolved for the call.
+func (s *ChallengeServiceServer) segmentTargets(ctx context.Context) service.SegmentTargets {
+	return s.targetOverrides.For(common.GetSegmentFromContext(ctx))
+}
+
+// SetInactiveProgressPolicy sets the challenges whose inactive goals BatchReportProgress
+// leaves alone. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetInactiveProgressPolicy(policy service.InactiveProgressPolicy) {
+	s.inactivePolicy = policy
+}
+
+// SetLateEventPolicies sets how BatchReportProgress handles events that occurred
+// before the current period of a rotating goal.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetLateEventPolicies(policies service.LateEventPolicies) {
+	s.latePolicies = policies
+}
+
+// SetMatchGoals sets the goals ReportMatchResult applies match results to.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetMatchGoals(goals service.MatchGoals) {
+	s.matchGoals = goals
+}
+
+// SetGoalSteps sets the multi-step goals: BatchReportProgress writes their
+// steps, claims require every step complete and challenge listings show each
+// step's progress. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetGoalSteps(steps service.GoalSteps) {
+	s.goalSteps = steps
+}
+
+// SetRewardCaps sets the goals whose reward only the first claims get, counting
+// the claims with repo. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetRewardCaps(caps service.RewardCaps, repo serviceRepo.RewardCapRepository) {
+	s.rewardCaps = caps
+	s.rewardCapRepo = repo
+}
+
+// SetRewardDeliveries sets where ITEM rewards are delivered: the deliveries a
+// claim may request and the goals' defaults. It must be called before the
+// server starts serving.
+func (s *ChallengeServiceServer) SetRewardDeliveries(deliveries *service.RewardDeliveries) {
+	s.rewardDeliveries = deliveries
+}
+
+// SetRepeatableGoals sets the goals a claim resets so they can be claimed again
+// after their cooldown, storing their claim history with repo. It must be
+// called before the server starts serving.
+func (s *ChallengeServiceServer) SetRepeatableGoals(goals service.RepeatableGoals, repo serviceRepo.RepeatableGoalRepository) {
+	s.repeatableGoals = goals
+	s.repeatableRepo = repo
+}
+
+// withRepeatableGoals returns ctx with the repeatable goals (see
+// service.WithRepeatableGoals).
+func (s *ChallengeServiceServer) withRepeatableGoals(ctx context.Context) context.Context {
+	return service.WithRepeatableGoals(ctx, s.repeatableGoals, s.repeatableRepo)
+}
+
+// SetStepProgress replaces the PostgreSQL repository that stores the step
+// progress of multi-step goals. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetStepProgress(stepProgress serviceRepo.StepProgressRepository) {
+	s.stepProgress = stepProgress
+}
+
+// SetProgressInserter replaces the repository InitializePlayer creates default
+// goal rows with. nil makes the insert all-or-nothing (BulkInsert).
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetProgressInserter(inserter serviceRepo.ProgressInsertRepository) {
+	s.progressInsert = inserter
+}
+
+// SetGoalSelections makes RandomSelectGoals and BatchSelectGoals record each
+// selection in the selection history, and enables the GetSelectionHistory and
+// GetSelectionStats admin RPCs. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetGoalSelections(selections serviceRepo.GoalSelectionRepository) {
+	s.goalSelections = selections
+}
+
+// SetAutoRandomSelector makes InitializePlayer and StartSession run the random
+// selections of challenges with autoRandomSelect for new players. nil leaves
+// those challenges to RandomSelectGoals. It must be called before the server
+// starts serving.
+func (s *ChallengeServiceServer) SetAutoRandomSelector(selector *service.AutoRandomSelector) {
+	s.autoRandomSelect = selector
+}
+
+// SetSessionStart enables the StartSession RPC (SESSION_START_ENABLED).
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetSessionStart(enabled bool) {
+	s.sessionStart = enabled
+}
+
+// SetConfigReloader enables the ReloadConfig admin RPC.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetConfigReloader(reloader *service.ConfigReloader) {
+	s.configReloader = reloader
+}
+
+// SetClaimCap enables the per-user claim cap checked by ClaimGoalReward and the
+// GetClaimCap/ResetClaimCap admin RPCs. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetClaimCap(claimCap *service.ClaimCap) {
+	s.claimCap = claimCap
+}
+
+// SetClaimFreezes enables the per-user claim freezes checked by ClaimGoalReward and
+// ClaimAllCompleted, and the claim freeze admin RPCs. It must be called before
+// the server starts serving.
+func (s *ChallengeServiceServer) SetClaimFreezes(freezes *service.ClaimFreezes) {
+	s.claimFreezes = freezes
+}
+
+// SetFailedGrants enables recording claims whose reward grant failed permanently,
+// and the ListFailedGrants/RetryFailedGrant admin RPCs. It must be called before
+// the server starts serving.
+func (s *ChallengeServiceServer) SetFailedGrants(failedGrants *service.FailedGrants) {
+	s.failedGrants = failedGrants
+}
+
+// SetGrantNamespaces lets players whose token namespace is grantable claim
+// rewards in that namespace instead of the service namespace. It must be called
+// before the server starts serving.
+func (s *ChallengeServiceServer) SetGrantNamespaces(grantNamespaces *service.GrantNamespaces) {
+	s.grantNamespaces = grantNamespaces
+}
+
+// SetWorkerPool runs the best-effort writes that follow a claim (reward grant
+// IDs, failed grants) on pool instead of in the request. Without a pool they run
+// inline. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetWorkerPool(pool *common.WorkerPool) {
+	s.background = pool
+}
+
+// SetGoalStats enables the GetGoalStats admin RPC.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetGoalStats(goalStats *service.GoalStats) {
+	s.goalStats = goalStats
+}
+
+// SetServiceState enables the GetServiceState admin RPC, if the reporter is
+// enabled. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetServiceState(reporter *service.ServiceStateReporter) {
+	s.serviceState = reporter
+}
+
+// SetBackfills enables the BackfillDefaultGoal and GetBackfillJob admin RPCs.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetBackfills(backfills *service.Backfills) {
+	s.backfills = backfills
+}
+
+// SetBulkActivations enables the BulkActivateGoal and GetBulkActivationJob admin
+// RPCs. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetBulkActivations(bulkActivations *service.BulkActivations) {
+	s.bulkActivations = bulkActivations
+}
+
+// SetStatusRecomputes enables the RecomputeUserStatuses admin RPC and the
+// recompute_statuses option of ReloadConfig. It must be called before the
+// server starts serving.
+func (s *ChallengeServiceServer) SetStatusRecomputes(statusRecomputes *service.StatusRecomputes) {
+	s.statusRecomputes = statusRecomputes
+}
+
+// SetUnclaimedCounts replaces the unclaimed counts cache used by GetUnclaimedCount,
+// e.g. to share it with the optimized initialize handler, which invalidates it too.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetUnclaimedCounts(unclaimedCounts *service.UnclaimedCounts) {
+	s.unclaimedCounts = unclaimedCounts
+}
+
+// SetChallengeMetrics sets the per-challenge counters of claims and of the
+// completions force-completes and progress reports make. It must be called
+// before the server starts serving.
+func (s *ChallengeServiceServer) SetChallengeMetrics(metrics *service.ChallengeMetrics) {
+	s.challengeMetrics = metrics
+}
+
+// SetBatchProgressConfig sets the limits for BatchReportProgress.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetBatchProgressConfig(config service.BatchProgressConfig) {
+	s.batchProgress = config
+}
+
+// SetProgressQueries replaces the PostgreSQL progress query repository, used by
+// paginated and consistency=strong reads, GetProgressSummary, BatchReportProgress
+// and GetChallengeMismatches. The unclaimed counts cache keeps the repository
+// it was created with. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetProgressQueries(progressQueries serviceRepo.ProgressQueryRepository) {
+	s.progressQueries = progressQueries
+}
+
+// SetClaimOutbox replaces the PostgreSQL claim outbox used by ClaimGoalReward.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetClaimOutbox(outbox serviceRepo.ClaimOutboxRepository) {
+	s.claimOutbox = outbox
+}
+
+// SetClaimStaleAfter sets how long a claim outbox entry must be idle before a
+// claim of its goal resumes it instead of waiting (ClaimRecovery.StaleAfter);
+// zero, the default, always waits. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetClaimStaleAfter(staleAfter time.Duration) {
+	s.claimStaleAfter = staleAfter
+}
+
+// SetRewardGrants replaces the PostgreSQL repository that records the AGS IDs of
+// claimed rewards. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetRewardGrants(rewardGrants serviceRepo.RewardGrantRepository) {
+	s.rewardGrants = rewardGrants
+}
+
+// SetActivationSources replaces the PostgreSQL repository that records how goals
+// were activated. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetActivationSources(sources serviceRepo.ActivationSourceRepository) {
+	s.activationSrc = sources
+}
+
+// SetGoalAdmin replaces the PostgreSQL repository used by ForceCompleteGoal.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetGoalAdmin(goalAdmin serviceRepo.GoalAdminRepository) {
+	s.goalAdmin = goalAdmin
+}
+
+// SetGoalResets replaces the PostgreSQL repository used by SetGoalActive with
+// reset_progress. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetGoalResets(resets serviceRepo.GoalResetRepository) {
+	s.goalResets = resets
+}
+
+// SetEventOutbox replaces the PostgreSQL outbox that InitializePlayer,
+// BatchSelectGoals and RandomSelectGoals write their domain events to.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetEventOutbox(outbox serviceRepo.EventOutboxRepository) {
+	s.eventOutbox = outbox
+}
+
+// SetUserIDs encodes the user IDs stored by the repositories
+// NewChallengeServiceServer creates, and by its unclaimed counts cache (see
+// serviceRepo.UserIDMapper); a nil mapper leaves them as they are. The goal
+// repository and the repositories passed to the other setters are wrapped by
+// the caller. It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetUserIDs(ids *serviceRepo.UserIDMapper) {
+	if ids == nil {
+		return
+	}
+	s.progressQueries = serviceRepo.NewUserIDCodecProgressQueryRepository(s.progressQueries, ids)
+	s.inactiveProgress = serviceRepo.NewUserIDCodecInactiveProgressRepository(s.inactiveProgress, ids)
+	s.lateProgress = serviceRepo.NewUserIDCodecLateProgressRepository(s.lateProgress, ids)
+	s.stepProgress = serviceRepo.NewUserIDCodecStepProgressRepository(s.stepProgress, ids)
+	s.progressInsert = serviceRepo.NewUserIDCodecProgressInsertRepository(s.progressInsert, ids)
+	s.rewardGrants = serviceRepo.NewUserIDCodecRewardGrantRepository(s.rewardGrants, ids)
+	s.activationSrc = serviceRepo.NewUserIDCodecActivationSourceRepository(s.activationSrc, ids)
+	s.goalAdmin = serviceRepo.NewUserIDCodecGoalAdminRepository(s.goalAdmin, ids)
+	s.goalResets = serviceRepo.NewUserIDCodecGoalResetRepository(s.goalResets, ids)
+	s.eventOutbox = serviceRepo.NewUserIDCodecEventOutboxRepository(s.eventOutbox, ids)
+	s.unclaimedCounts = service.NewUnclaimedCounts(s.progressQueries, s.namespace, service.DefaultUnclaimedCountTTL)
+}
+
+// SetQueryMetrics instruments the repositories NewChallengeServiceServer
+// creates (see serviceRepo.NewInstrumentedClaimOutboxRepository), so their
+// calls are recorded in metrics and counted with the request's queries. The goal
+// repository and the repositories passed to the other setters are instrumented
+// by the caller. It must be called after SetUserIDs, before the server starts
+// serving.
+func (s *ChallengeServiceServer) SetQueryMetrics(metrics *serviceRepo.QueryMetrics) {
+	s.progressQueries = serviceRepo.NewInstrumentedProgressQueryRepository(s.progressQueries, metrics)
+	s.stepProgress = serviceRepo.NewInstrumentedStepProgressRepository(s.stepProgress, metrics)
+	s.progressInsert = serviceRepo.NewInstrumentedProgressInsertRepository(s.progressInsert, metrics)
+	s.claimOutbox = serviceRepo.NewInstrumentedClaimOutboxRepository(s.claimOutbox, metrics)
+	s.rewardGrants = serviceRepo.NewInstrumentedRewardGrantRepository(s.rewardGrants, metrics)
+	s.activationSrc = serviceRepo.NewInstrumentedActivationSourceRepository(s.activationSrc, metrics)
+	s.eventOutbox = serviceRepo.NewInstrumentedEventOutboxRepository(s.eventOutbox, metrics)
+	s.unclaimedCounts = service.NewUnclaimedCounts(s.progressQueries, s.namespace, service.DefaultUnclaimedCountTTL)
+}
+
+// SetLogger replaces the logger passed to the claim, goal selection and
+// initialization services, which defaults to the standard logrus logger.
+// It must be called before the server starts serving.
+func (s *ChallengeServiceServer) SetLogger(logger logrus.FieldLogger) {
+	s.logger = logger
 }
 
 // NewChallengeServiceServer creates a new challenge service server
@@ -47,12 +426,31 @@ func NewChallengeServiceServer(
 	db *sql.DB,
 	namespace string,
 ) *ChallengeServiceServer {
+	progressQueries := serviceRepo.NewPostgresProgressQueryRepository(db)
 	return &ChallengeServiceServer{
-		goalCache:    goalCache,
-		repo:         repo,
-		rewardClient: rewardClient,
-		db:           db,
-		namespace:    namespace,
+		goalCache:        goalCache,
+		repo:             repo,
+		progressQueries:  progressQueries,
+		inactiveProgress: serviceRepo.NewPostgresInactiveProgressRepository(db),
+		lateProgress:     serviceRepo.NewPostgresLateProgressRepository(db),
+		stepProgress:     serviceRepo.NewPostgresStepProgressRepository(db),
+		progressInsert:   serviceRepo.NewPostgresProgressInsertRepository(db),
+		claimOutbox:      serviceRepo.NewPostgresClaimOutboxRepository(db),
+		rewardGrants:     serviceRepo.NewPostgresRewardGrantRepository(db),
+		activationSrc:    serviceRepo.NewPostgresActivationSourceRepository(db),
+		goalAdmin:        serviceRepo.NewPostgresGoalAdminRepository(db),
+		goalResets:       serviceRepo.NewPostgresGoalResetRepository(db),
+		eventOutbox:      serviceRepo.NewPostgresEventOutboxRepository(db),
+		unclaimedCounts:  service.NewUnclaimedCounts(progressQueries, namespace, service.DefaultUnclaimedCountTTL),
+		rewardClient:     rewardClient,
+		db:               db,
+		namespace:        namespace,
+		batchProgress: service.BatchProgressConfig{
+			MaxEvents: service.DefaultBatchProgressMaxEvents,
+			ChunkSize: service.DefaultBatchProgressChunkSize,
+		},
+		logger: logrus.StandardLogger(),
+		now:    func() time.Time { return time.Now().UTC() },
 	}
 }
 
@@ -79,22 +477,59 @@ func (s *ChallengeServiceServer) GetUserChallenges(
 		return nil, err
 	}
 
+	strong, err := service.ParseConsistency(req.Consistency)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// A limit of 0 is unset: the whole list, as before pagination existed
+	paged := req.Limit != 0
+	if paged {
+		if err := service.CheckProgressPage(int(req.Limit), req.ChallengeIds, strong); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
 	logrus.WithFields(logrus.Fields{
-		"user_id":     userID,
-		"namespace":   s.namespace,
-		"active_only": req.ActiveOnly, // M3 Phase 4
+		"user_id":         userID,
+		"namespace":       s.namespace,
+		"active_only":     req.ActiveOnly, // M3 Phase 4
+		"exclude_claimed": req.ExcludeClaimed,
+		"challenge_ids":   req.ChallengeIds,
+		"consistency":     req.Consistency,
+		"limit":           req.Limit,
+		"after_goal_id":   req.AfterGoalId,
 	}).Info("Getting user challenges")
 
 	// Get challenges with progress using service layer
 	// M3 Phase 4: Pass activeOnly parameter from request
-	challengesWithProgress, err := service.GetUserChallengesWithProgress(
-		ctx,
-		userID,
-		s.namespace,
-		s.goalCache,
-		s.repo,
-		req.ActiveOnly,
-	)
+	var challengesWithProgress []*service.ChallengeWithProgress
+	var snapshotAt time.Time
+	var nextAfterGoalID string
+	if paged {
+		// Each challenge only lists its goals on the page
+		var page *service.ProgressPage
+		page, err = service.LoadProgressPage(ctx, s.progressQueries, s.repo, s.goalCache, userID, req.ActiveOnly, req.AfterGoalId, int(req.Limit))
+		if err == nil {
+			challengesWithProgress = service.ChallengesWithProgress(service.PageChallenges(s.goalCache, page.GoalIDs), page.Rows)
+			nextAfterGoalID = page.NextAfterGoalID
+		}
+	} else if strong {
+		var rows []*commonDomain.UserGoalProgress
+		rows, snapshotAt, err = service.LoadProgressSnapshot(ctx, s.progressQueries, userID, "", req.ActiveOnly)
+		challengesWithProgress = service.ChallengesWithProgress(
+			service.FilterChallenges(s.goalCache.GetAllChallenges(), req.ChallengeIds), rows)
+	} else {
+		challengesWithProgress, err = service.GetUserChallengesWithProgress(
+			ctx,
+			userID,
+			s.namespace,
+			s.goalCache,
+			s.repo,
+			req.ActiveOnly,
+			req.ChallengeIds,
+		)
+	}
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":   userID,
@@ -104,12 +539,110 @@ func (s *ChallengeServiceServer) GetUserChallenges(
 		return nil, status.Error(codes.Internal, "failed to retrieve challenges")
 	}
 
+	// Challenges not rolled out to the user are not listed
+	if len(s.rollouts) > 0 {
+		challengesWithProgress = slices.DeleteFunc(challengesWithProgress, func(cwp *service.ChallengeWithProgress) bool {
+			return !s.rollouts.IsAvailable(userID, cwp.Challenge.ID)
+		})
+	}
+
+	// Every challenge shares the user's progress map; rows the segment has
+	// completed at a lower target are shown completed
+	targets := s.segmentTargets(ctx)
+	if len(challengesWithProgress) > 0 {
+		evaluated := targets.EvaluateAll(challengesWithProgress[0].UserProgress, s.goalCache)
+		for _, cwp := range challengesWithProgress {
+			cwp.UserProgress = evaluated
+		}
+	}
+
+	// With active_only the progress map lacks inactive rows, and with
+	// challenge_ids or limit it lacks rows of other goals; either way
+	// prerequisite rows may need to be loaded.
+	filtered := len(req.ChallengeIds) > 0 || paged
+	partial := req.ActiveOnly || filtered
+
+	// Hidden goals are only listed once unlocked
+	var visibility map[string]*commonDomain.UserGoalProgress
+	if len(s.hiddenGoals) > 0 && len(challengesWithProgress) > 0 {
+		visibility = challengesWithProgress[0].UserProgress
+		if partial {
+			var goals []*commonDomain.Goal
+			for _, cwp := range challengesWithProgress {
+				goals = append(goals, cwp.Challenge.Goals...)
+			}
+			visibility, err = s.hiddenGoals.WithPrerequisites(ctx, s.repo, userID, goals, visibility)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"user_id":   userID,
+					"namespace": s.namespace,
+					"error":     err,
+				}).Error("Failed to get user challenges")
+				return nil, status.Error(codes.Internal, "failed to retrieve challenges")
+			}
+		}
+	}
+
+	// Challenges locked by unfinished prerequisite challenges
+	var locks map[string]string
+	if len(s.challengePrereqs) > 0 && len(challengesWithProgress) > 0 {
+		challengeIDs := make([]string, 0, len(challengesWithProgress))
+		for _, cwp := range challengesWithProgress {
+			challengeIDs = append(challengeIDs, cwp.Challenge.ID)
+		}
+		lookup := challengesWithProgress[0].UserProgress
+		if partial {
+			lookup, err = s.challengePrereqs.WithPrerequisites(ctx, s.repo, userID, challengeIDs, s.goalCache, lookup)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"user_id":   userID,
+					"namespace": s.namespace,
+					"error":     err,
+				}).Error("Failed to get user challenges")
+				return nil, status.Error(codes.Internal, "failed to retrieve challenges")
+			}
+		}
+		locks = s.challengePrereqs.Locks(challengeIDs, s.goalCache, lookup)
+	}
+
+	// Activatable needs the rows of goal prerequisites, which may sit in
+	// challenges outside the filter
+	var activation map[string]*commonDomain.UserGoalProgress
+	if !req.ActiveOnly && len(challengesWithProgress) > 0 {
+		activation = challengesWithProgress[0].UserProgress
+		if filtered {
+			var goals []*commonDomain.Goal
+			for _, cwp := range challengesWithProgress {
+				goals = append(goals, cwp.Challenge.Goals...)
+			}
+			activation, err = service.WithActivationPrerequisites(ctx, s.repo, userID, goals, activation)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"user_id":   userID,
+					"namespace": s.namespace,
+					"error":     err,
+				}).Error("Failed to get user challenges")
+				return nil, status.Error(codes.Internal, "failed to retrieve challenges")
+			}
+		}
+	}
+
 	// Convert to protobuf response
 	// M5: Pass current time for rotation display calculations
-	now := time.Now().UTC()
+	now := s.now()
 	protoChallenges := make([]*pb.Challenge, 0, len(challengesWithProgress))
 	for _, cwp := range challengesWithProgress {
-		protoChallenge, err := mapper.ChallengeToProto(cwp.Challenge, cwp.UserProgress, now)
+		challenge := s.hiddenGoals.VisibleChallenge(cwp.Challenge, visibility)
+
+		// active_only leaves activatable unset. Goals of locked challenges are
+		// never activatable.
+		var activatable map[string]bool
+		if !req.ActiveOnly {
+			activatable = service.ActivatableGoals(challenge.Goals, activation)
+			service.ClearLockedActivatable(activatable, challenge.Goals, locks)
+		}
+
+		protoChallenge, err := mapper.ChallengeToProto(targets.Challenge(challenge), cwp.UserProgress, activatable, now)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"user_id":      userID,
@@ -118,169 +651,399 @@ func (s *ChallengeServiceServer) GetUserChallenges(
 			}).Error("Failed to convert challenge to proto")
 			return nil, status.Error(codes.Internal, "failed to convert challenge data")
 		}
+		protoChallenge.LockedReason, protoChallenge.Locked = locks[cwp.Challenge.ID]
+		// A page's challenges only hold its goals; the reset is the configured challenge's
+		resetChallenge := cwp.Challenge
+		if paged {
+			resetChallenge = s.goalCache.GetChallengeByChallengeID(cwp.Challenge.ID)
+		}
+		mapper.SetChallengeReset(protoChallenge, service.NextChallengeReset(resetChallenge, now), now)
+		if req.ExcludeClaimed {
+			protoChallenge.Goals = withoutClaimedGoals(protoChallenge.Goals)
+			if paged && len(protoChallenge.Goals) == 0 {
+				// Like the HTTP handler, a page only lists challenges with goals on it
+				continue
+			}
+		}
 		protoChallenges = append(protoChallenges, protoChallenge)
 	}
-
-	// NOTE: Cannot return objects to pool here - gRPC serializes AFTER handler returns
-	// This would cause a race condition. Pooling only helps if done at a different layer.
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":         userID,
-		"namespace":       s.namespace,
-		"challenge_count": len(protoChallenges),
-	}).Info("Successfully retrieved user challenges")
-
-	return &pb.GetChallengesResponse{
-		Challenges: protoChallenges,
-	}, nil
-}
-
-// InitializePlayer assigns default goals to a new player or syncs existing player with config changes
-func (s *ChallengeServiceServer) InitializePlayer(
-	ctx context.Context,
-	req *pb.InitializeRequest,
-) (*pb.InitializeResponse, error) {
-	// Extract user ID from JWT token (already validated by interceptor)
-	userID, err := extractUserIDFromContext(ctx)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to extract user ID from context")
-		return nil, err
+	if len(challengesWithProgress) > 0 {
+		s.applyActivationSources(ctx, userID, protoChallenges, challengesWithProgress[0].UserProgress)
+		s.applyFirstCompletions(ctx, userID, protoChallenges, challengesWithProgress[0].UserProgress)
 	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":   userID,
-		"namespace": s.namespace,
-	}).Info("Initializing player with default goals")
-
-	// Call business logic
-	result, err := service.InitializePlayer(
-		ctx,
-		userID,
-		s.namespace,
-		s.goalCache,
-		s.repo,
-	)
-	if err != nil {
+	s.applyGoalRepeats(ctx, userID, protoChallenges, now)
+	if err := s.applyGoalSteps(ctx, userID, protoChallenges); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": s.namespace,
 			"error":     err,
-		}).Error("Failed to initialize player")
-		return nil, status.Error(codes.Internal, "failed to initialize player")
+		}).Error("Failed to get user challenges")
+		return nil, status.Error(codes.Internal, "failed to retrieve challenges")
 	}
-
-	// Convert to protobuf response
-	protoAssignedGoals := make([]*pb.AssignedGoal, 0, len(result.AssignedGoals))
-	for _, assignedGoal := range result.AssignedGoals {
-		protoGoal, err := assignedGoalToProto(assignedGoal)
+	if req.IncludeBlockers && len(challengesWithProgress) > 0 {
+		var goals []*commonDomain.Goal
+		for _, cwp := range challengesWithProgress {
+			goals = append(goals, cwp.Challenge.Goals...)
+		}
+		err := s.applyClaimBlockers(ctx, userID, protoChallenges, goals, challengesWithProgress[0].UserProgress, targets, locks, partial)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"goal_id": assignedGoal.GoalID,
-				"error":   err,
-			}).Error("Failed to convert assigned goal to proto")
-			continue
+				"user_id":   userID,
+				"namespace": s.namespace,
+				"error":     err,
+			}).Error("Failed to get user challenges")
+			return nil, status.Error(codes.Internal, "failed to retrieve challenges")
 		}
-		protoAssignedGoals = append(protoAssignedGoals, protoGoal)
 	}
 
+	// NOTE: Cannot return objects to pool here - gRPC serializes AFTER handler returns
+	// This would cause a race condition. Pooling only helps if done at a different layer.
+
 	logrus.WithFields(logrus.Fields{
 		"user_id":         userID,
 		"namespace":       s.namespace,
-		"new_assignments": result.NewAssignments,
-		"total_active":    result.TotalActive,
-	}).Info("Successfully initialized player")
+		"challenge_count": len(protoChallenges),
+	}).Info("Successfully retrieved user challenges")
 
-	return &pb.InitializeResponse{
-		AssignedGoals: protoAssignedGoals,
-		// #nosec G115 - NewAssignments will never exceed int32 max (limited by goal count)
-		NewAssignments: int32(result.NewAssignments),
-		// #nosec G115 - TotalActive will never exceed int32 max (limited by goal count)
-		TotalActive: int32(result.TotalActive),
+	return &pb.GetChallengesResponse{
+		Challenges:      protoChallenges,
+		SnapshotAt:      mapper.ToProtoTimestamp(&snapshotAt),
+		NextAfterGoalId: nextAfterGoalID,
 	}, nil
 }
 
-// SetGoalActive allows players to manually control goal assignment (M3 Phase 3)
-func (s *ChallengeServiceServer) SetGoalActive(
+// GetChallenge returns one challenge with the authenticated user's progress.
+// The HTTP route is served by OptimizedChallengesHandler.ServeChallenge; this
+// implementation backs gRPC clients.
+func (s *ChallengeServiceServer) GetChallenge(
 	ctx context.Context,
-	req *pb.SetGoalActiveRequest,
-) (*pb.SetGoalActiveResponse, error) {
-	// Extract user ID from JWT token (already validated by interceptor)
+	req *pb.GetChallengeRequest,
+) (*pb.GetChallengeResponse, error) {
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
 	userID, err := extractUserIDFromContext(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to extract user ID from context")
 		return nil, err
 	}
 
-	// Validate request
-	if req.ChallengeId == "" {
-		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	strong, err := service.ParseConsistency(req.Consistency)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if req.GoalId == "" {
-		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	configured := s.goalCache.GetChallengeByChallengeID(req.ChallengeId)
+	if configured == nil {
+		return nil, status.Errorf(codes.NotFound, "challenge not found: %s", req.ChallengeId)
+	}
+	if err := s.rollouts.CheckAvailable(userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":      userID,
-		"challenge_id": req.ChallengeId,
-		"goal_id":      req.GoalId,
-		"is_active":    req.IsActive,
 		"namespace":    s.namespace,
-	}).Info("Setting goal active status")
-
-	// Call business logic
-	result, err := service.SetGoalActive(
-		ctx,
-		userID,
-		req.ChallengeId,
-		req.GoalId,
-		s.namespace,
-		req.IsActive,
-		s.goalCache,
-		s.repo,
-	)
+		"challenge_id": req.ChallengeId,
+		"active_only":  req.ActiveOnly,
+		"consistency":  req.Consistency,
+	}).Info("Getting user challenge")
+
+	var cwp *service.ChallengeWithProgress
+	var snapshotAt time.Time
+	if strong {
+		var rows []*commonDomain.UserGoalProgress
+		rows, snapshotAt, err = service.LoadProgressSnapshot(ctx, s.progressQueries, userID, req.ChallengeId, req.ActiveOnly)
+		cwp = service.ChallengesWithProgress([]*commonDomain.Challenge{configured}, rows)[0]
+	} else {
+		cwp, err = service.GetUserChallengeWithProgress(
+			ctx,
+			userID,
+			req.ChallengeId,
+			s.namespace,
+			s.goalCache,
+			s.repo,
+			req.ActiveOnly,
+		)
+	}
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": req.ChallengeId,
-			"goal_id":      req.GoalId,
-			"is_active":    req.IsActive,
-			"namespace":    s.namespace,
 			"error":        err,
-		}).Error("Failed to set goal active status")
-		return nil, status.Errorf(codes.Internal, "failed to set goal active status: %v", err)
+		}).Error("Failed to get user challenge")
+		return nil, status.Error(codes.Internal, "failed to retrieve challenge")
 	}
-
-	// Convert response
-	response := &pb.SetGoalActiveResponse{
-		ChallengeId: result.ChallengeID,
-		GoalId:      result.GoalID,
-		IsActive:    result.IsActive,
-		Message:     result.Message,
+	targets := s.segmentTargets(ctx)
+	cwp.UserProgress = targets.EvaluateAll(cwp.UserProgress, s.goalCache)
+
+	// Progress is limited to this challenge, so prerequisite rows from other
+	// challenges may be missing. Activatable needs them unless active_only,
+	// which leaves it unset; hidden goals always do.
+	visibility := cwp.UserProgress
+	var activatable map[string]bool
+	if !req.ActiveOnly {
+		visibility, err = service.WithActivationPrerequisites(ctx, s.repo, userID, cwp.Challenge.Goals, visibility)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": req.ChallengeId,
+				"error":        err,
+			}).Error("Failed to get user challenge")
+			return nil, status.Error(codes.Internal, "failed to retrieve challenge")
+		}
+		activatable = service.ActivatableGoals(cwp.Challenge.Goals, visibility)
 	}
-
-	// Convert assigned_at timestamp (nullable)
-	if result.AssignedAt != nil {
-		response.AssignedAt = result.AssignedAt.UTC().Format(time.RFC3339)
+	if len(s.hiddenGoals) > 0 {
+		visibility, err = s.hiddenGoals.WithPrerequisites(ctx, s.repo, userID, cwp.Challenge.Goals, visibility)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": req.ChallengeId,
+				"error":        err,
+			}).Error("Failed to get user challenge")
+			return nil, status.Error(codes.Internal, "failed to retrieve challenge")
+		}
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"challenge_id": req.ChallengeId,
-		"goal_id":      req.GoalId,
-		"is_active":    result.IsActive,
-		"namespace":    s.namespace,
-	}).Info("Successfully set goal active status")
+	// The goals of the prerequisite challenges are outside this challenge too
+	var locks map[string]string
+	if len(s.challengePrereqs) > 0 {
+		challengeIDs := []string{req.ChallengeId}
+		visibility, err = s.challengePrereqs.WithPrerequisites(ctx, s.repo, userID, challengeIDs, s.goalCache, visibility)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": req.ChallengeId,
+				"error":        err,
+			}).Error("Failed to get user challenge")
+			return nil, status.Error(codes.Internal, "failed to retrieve challenge")
+		}
+		locks = s.challengePrereqs.Locks(challengeIDs, s.goalCache, visibility)
+		service.ClearLockedActivatable(activatable, cwp.Challenge.Goals, locks)
+	}
 
-	return response, nil
+	now := s.now()
+	challenge := s.hiddenGoals.VisibleChallenge(cwp.Challenge, visibility)
+	protoChallenge, err := mapper.ChallengeToProto(targets.Challenge(challenge), cwp.UserProgress, activatable, now)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"error":        err,
+		}).Error("Failed to convert challenge to proto")
+		return nil, status.Error(codes.Internal, "failed to convert challenge data")
+	}
+	protoChallenge.LockedReason, protoChallenge.Locked = locks[req.ChallengeId]
+	mapper.SetChallengeReset(protoChallenge, service.NextChallengeReset(cwp.Challenge, now), now)
+	s.applyActivationSources(ctx, userID, []*pb.Challenge{protoChallenge}, cwp.UserProgress)
+	s.applyFirstCompletions(ctx, userID, []*pb.Challenge{protoChallenge}, cwp.UserProgress)
+	s.applyGoalRepeats(ctx, userID, []*pb.Challenge{protoChallenge}, now)
+	if err := s.applyGoalSteps(ctx, userID, []*pb.Challenge{protoChallenge}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"error":        err,
+		}).Error("Failed to get user challenge")
+		return nil, status.Error(codes.Internal, "failed to retrieve challenge")
+	}
+	if req.IncludeBlockers {
+		err := s.applyClaimBlockers(ctx, userID, []*pb.Challenge{protoChallenge}, cwp.Challenge.Goals, cwp.UserProgress, targets, locks, true)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": req.ChallengeId,
+				"error":        err,
+			}).Error("Failed to get user challenge")
+			return nil, status.Error(codes.Internal, "failed to retrieve challenge")
+		}
+	}
+
+	return &pb.GetChallengeResponse{Challenge: protoChallenge, SnapshotAt: mapper.ToProtoTimestamp(&snapshotAt)}, nil
 }
 
-// BatchSelectGoals activates multiple player-selected goals at once (M4)
-func (s *ChallengeServiceServer) BatchSelectGoals(
+// applyActivationSources sets the activation source of each goal with a progress
+// row. Pooled goals may carry a previous value, so every goal is set.
+func (s *ChallengeServiceServer) applyActivationSources(
 	ctx context.Context,
-	req *pb.BatchSelectRequest,
-) (*pb.GoalSelectionResponse, error) {
+	userID string,
+	challenges []*pb.Challenge,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+) {
+	var goalIDs []string
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			if userProgress[goal.GoalId] != nil {
+				goalIDs = append(goalIDs, goal.GoalId)
+			}
+		}
+	}
+
+	var sources map[string]string
+	if len(goalIDs) > 0 {
+		sources = service.ResolveActivationSources(ctx, s.activationSrc, userID, nil, "", goalIDs, s.logger)
+	}
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			goal.ActivationSource = sources[goal.GoalId]
+		}
+	}
+}
+
+// applyFirstCompletions sets the first completion of each goal whose row was
+// reset since (see service.ResolveFirstCompletions). Other goals keep the
+// completedAt the mapper set.
+func (s *ChallengeServiceServer) applyFirstCompletions(
+	ctx context.Context,
+	userID string,
+	challenges []*pb.Challenge,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+) {
+	var goalIDs []string
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			goalIDs = append(goalIDs, goal.GoalId)
+		}
+	}
+
+	completions := service.ResolveFirstCompletions(ctx, s.progressQueries, userID, goalIDs, userProgress, s.logger)
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			if first, ok := completions[goal.GoalId]; ok {
+				goal.FirstCompletedAt = mapper.ToProtoTimestamp(&first)
+			}
+		}
+	}
+}
+
+// applyGoalRepeats sets the claim history of each repeatable goal, and the first
+// completion of those whose claims reset them; a goal cooling down at now is not
+// activatable. Pooled goals may carry a previous value, so every goal is set. A
+// failure is logged and leaves the history unset.
+func (s *ChallengeServiceServer) applyGoalRepeats(ctx context.Context, userID string, challenges []*pb.Challenge, now time.Time) {
+	if len(s.repeatableGoals) == 0 {
+		return
+	}
+
+	var goalIDs []string
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			goalIDs = append(goalIDs, goal.GoalId)
+		}
+	}
+
+	repeats := service.ResolveGoalRepeats(s.withRepeatableGoals(ctx), userID, goalIDs, s.logger)
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			repeat, ok := repeats[goal.GoalId]
+			if !ok {
+				goal.Repeat = nil
+				continue
+			}
+			goal.Repeat = goalRepeatToProto(&repeat)
+			if repeat.FirstCompletedAt != nil {
+				goal.FirstCompletedAt = mapper.ToProtoTimestamp(repeat.FirstCompletedAt)
+			}
+			if repeat.CoolingDown(now) {
+				goal.Activatable = false
+			}
+		}
+	}
+}
+
+// applyGoalSteps sets the steps of each multi-step goal, with the user's
+// progress on each step. Nothing is loaded when no goal has steps.
+func (s *ChallengeServiceServer) applyGoalSteps(ctx context.Context, userID string, challenges []*pb.Challenge) error {
+	if len(s.goalSteps) == 0 {
+		return nil
+	}
+
+	var goalIDs []string
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			goalIDs = append(goalIDs, goal.GoalId)
+		}
+	}
+
+	progress, err := s.goalSteps.LoadProgress(ctx, s.stepProgress, userID, goalIDs)
+	if err != nil {
+		return err
+	}
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			for i, step := range s.goalSteps.Steps(goal.GoalId) {
+				goal.Steps = append(goal.Steps, mapper.GoalStepToProto(step.StatCode, step.Operator, step.TargetValue, progress[goal.GoalId][i]))
+			}
+		}
+	}
+	return nil
+}
+
+// applyClaimBlockers sets the claim blockers of each goal (see
+// service.ClaimBlockers). userProgress holds the user's rows evaluated against
+// targets; with partial, rows of prerequisites outside it are loaded.
+func (s *ChallengeServiceServer) applyClaimBlockers(
+	ctx context.Context,
+	userID string,
+	challenges []*pb.Challenge,
+	goals []*commonDomain.Goal,
+	userProgress map[string]*commonDomain.UserGoalProgress,
+	targets service.SegmentTargets,
+	locks map[string]string,
+	partial bool,
+) error {
+	progress := userProgress
+	if partial {
+		merged, err := service.WithClaimPrerequisites(ctx, s.repo, userID, goals, userProgress)
+		if err != nil {
+			return err
+		}
+		progress = targets.EvaluateAll(merged, s.goalCache)
+	}
+
+	goalIDs := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+	stepProgress, err := s.goalSteps.LoadProgress(ctx, s.stepProgress, userID, goalIDs)
+	if err != nil {
+		return err
+	}
+
+	blockers := service.ClaimBlockers(goals, service.ClaimBlockerState{
+		Progress:     progress,
+		Targets:      targets,
+		Locks:        locks,
+		Steps:        s.goalSteps,
+		StepProgress: stepProgress,
+		Now:          time.Now().UTC(),
+	})
+	for _, challenge := range challenges {
+		for _, goal := range challenge.Goals {
+			goal.ClaimBlockers = blockers[goal.GoalId]
+		}
+	}
+	return nil
+}
+
+// withoutClaimedGoals returns goals without those displayed claimed, for
+// GetUserChallenges with exclude_claimed.
+func withoutClaimedGoals(goals []*pb.Goal) []*pb.Goal {
+	kept := goals[:0]
+	for _, goal := range goals {
+		if goal.Status != string(commonDomain.GoalStatusClaimed) {
+			kept = append(kept, goal)
+		}
+	}
+	return kept
+}
+
+// GetProgressSummary returns goal counts by status across all challenges for the authenticated user
+func (s *ChallengeServiceServer) GetProgressSummary(
+	ctx context.Context,
+	req *pb.GetProgressSummaryRequest,
+) (*pb.GetProgressSummaryResponse, error) {
 	// Extract user ID from JWT token (already validated by interceptor)
 	userID, err := extractUserIDFromContext(ctx)
 	if err != nil {
@@ -288,343 +1051,2606 @@ func (s *ChallengeServiceServer) BatchSelectGoals(
 		return nil, err
 	}
 
-	// Validate request
-	if req.ChallengeId == "" {
-		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	logrus.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"namespace":   s.namespace,
+		"active_only": req.ActiveOnly,
+	}).Info("Getting progress summary")
+
+	now := s.now()
+	summary, err := service.GetUserProgressSummary(
+		ctx,
+		userID,
+		s.namespace,
+		s.goalCache,
+		s.progressQueries,
+		req.ActiveOnly,
+		now,
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to get progress summary")
+		return nil, status.Error(codes.Internal, "failed to retrieve progress summary")
 	}
+	s.rollouts.FilterSummary(userID, summary)
 
-	if len(req.GoalIds) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "goal_ids cannot be empty")
+	return progressSummaryToProto(summary, now), nil
+}
+
+// progressSummaryToProto converts a progress summary taken at now.
+func progressSummaryToProto(summary *service.ProgressSummary, now time.Time) *pb.GetProgressSummaryResponse {
+	protoChallenges := make([]*pb.ChallengeProgressSummary, 0, len(summary.Challenges))
+	for _, c := range summary.Challenges {
+		protoChallenges = append(protoChallenges, &pb.ChallengeProgressSummary{
+			ChallengeId:      c.ChallengeID,
+			Name:             c.Name,
+			TotalGoals:       int32(c.TotalGoals),
+			CompletedGoals:   int32(c.Completed),
+			ClaimedGoals:     int32(c.Claimed),
+			InProgressGoals:  int32(c.InProgress),
+			NextResetAt:      mapper.ToProtoTimestamp(c.NextResetAt),
+			SecondsRemaining: mapper.SecondsUntil(c.NextResetAt, now),
+		})
+	}
+
+	return &pb.GetProgressSummaryResponse{
+		TotalGoals:      int32(summary.Totals.TotalGoals),
+		CompletedGoals:  int32(summary.Totals.Completed),
+		ClaimedGoals:    int32(summary.Totals.Claimed),
+		InProgressGoals: int32(summary.Totals.InProgress),
+		Challenges:      protoChallenges,
+	}
+}
+
+// GetUnclaimedCount returns how many of the authenticated user's active goals
+// have a reward to claim and how many are in progress, for the unclaimed rewards
+// badge. Counts are cached per user for a few seconds.
+func (s *ChallengeServiceServer) GetUnclaimedCount(
+	ctx context.Context,
+	req *pb.GetUnclaimedCountRequest,
+) (*pb.GetUnclaimedCountResponse, error) {
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	count, err := s.unclaimedCounts.Get(ctx, userID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to get unclaimed count")
+		return nil, status.Error(codes.Internal, "failed to retrieve unclaimed count")
+	}
+
+	return unclaimedCountToProto(count), nil
+}
+
+func unclaimedCountToProto(count service.UnclaimedCount) *pb.GetUnclaimedCountResponse {
+	return &pb.GetUnclaimedCountResponse{
+		HasUnclaimed: count.Unclaimed > 0,
+		// #nosec G115 - goal counts fit in int32
+		UnclaimedCount: int32(count.Unclaimed),
+		// #nosec G115 - goal counts fit in int32
+		InProgressCount: int32(count.InProgress),
+	}
+}
+
+// GetActiveGoals returns the authenticated user's active goals with their
+// progress, oldest assignment first.
+func (s *ChallengeServiceServer) GetActiveGoals(
+	ctx context.Context,
+	req *pb.GetActiveGoalsRequest,
+) (*pb.GetActiveGoalsResponse, error) {
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	result, err := service.GetActiveGoals(ctx, userID, int(req.Limit), s.goalCache, s.repo, s.logger)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to get active goals")
+		return nil, status.Error(codes.Internal, "failed to retrieve active goals")
+	}
+	s.segmentTargets(ctx).ApplyToAssignedGoals(result.Goals)
+
+	protoGoals := make([]*pb.AssignedGoal, 0, len(result.Goals))
+	for _, goal := range result.Goals {
+		protoGoal, err := assignedGoalToProto(goal)
+		if err != nil {
+			continue
+		}
+		protoGoals = append(protoGoals, protoGoal)
+	}
+
+	return &pb.GetActiveGoalsResponse{
+		Goals:     protoGoals,
+		Truncated: result.Truncated,
+	}, nil
+}
+
+// InitializePlayer assigns default goals to a new player or syncs existing player with config changes
+func (s *ChallengeServiceServer) InitializePlayer(
+	ctx context.Context,
+	req *pb.InitializeRequest,
+) (*pb.InitializeResponse, error) {
+	// Extract user ID from JWT token (already validated by interceptor)
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":          userID,
-		"challenge_id":     req.ChallengeId,
-		"goal_count":       len(req.GoalIds),
-		"replace_existing": req.ReplaceExisting,
-		"namespace":        s.namespace,
-	}).Info("Batch selecting goals")
+		"user_id":   userID,
+		"namespace": s.namespace,
+	}).Info("Initializing player with default goals")
 
 	// Call business logic
-	result, err := service.BatchSelectGoals(
-		ctx,
+	result, err := service.InitializePlayer(
+		service.WithChallengeRollouts(service.WithAutoRandomSelector(ctx, s.autoRandomSelect), s.rollouts),
 		userID,
-		req.ChallengeId,
-		req.GoalIds,
-		req.ReplaceExisting,
 		s.namespace,
 		s.goalCache,
 		s.repo,
+		s.progressInsert,
+		s.logger,
 	)
+	s.unclaimedCounts.Invalidate(userID)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": req.ChallengeId,
-			"goal_count":   len(req.GoalIds),
-			"error":        err,
-		}).Error("Failed to batch select goals")
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to initialize player")
+		return nil, status.Error(codes.Internal, "failed to initialize player")
+	}
+	service.ResolveInitializeActivationSources(ctx, s.activationSrc, userID, result, s.logger)
+	service.EnqueuePlayerInitialized(ctx, s.eventOutbox, s.namespace, userID, result, s.logger)
+	s.segmentTargets(ctx).ApplyToAssignedGoals(result.AssignedGoals)
 
-		// Return 404 Not Found for resource not found errors
-		if strings.Contains(err.Error(), "not found") {
-			return nil, status.Errorf(codes.NotFound, "%v", err)
-		}
+	logrus.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"namespace":       s.namespace,
+		"new_assignments": result.NewAssignments,
+		"total_active":    result.TotalActive,
+	}).Info("Successfully initialized player")
 
-		return nil, status.Errorf(codes.Internal, "failed to batch select goals: %v", err)
-	}
+	return initializeResponseToProto(userID, result), nil
+}
 
-	// Convert to protobuf response
-	protoSelectedGoals := make([]*pb.SelectedGoal, 0, len(result.SelectedGoals))
-	for _, selectedGoal := range result.SelectedGoals {
-		protoGoal, err := selectedGoalToProto(selectedGoal)
+// initializeResponseToProto converts an initialization result, leaving out
+// goals that fail to convert.
+func initializeResponseToProto(userID string, result *service.InitializeResponse) *pb.InitializeResponse {
+	protoAssignedGoals := make([]*pb.AssignedGoal, 0, len(result.AssignedGoals))
+	for _, assignedGoal := range result.AssignedGoals {
+		protoGoal, err := assignedGoalToProto(assignedGoal)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"user_id": userID,
-				"goal_id": selectedGoal.GoalID,
+				"goal_id": assignedGoal.GoalID,
 				"error":   err,
-			}).Error("Failed to convert selected goal to proto")
+			}).Error("Failed to convert assigned goal to proto")
 			continue
 		}
-		protoSelectedGoals = append(protoSelectedGoals, protoGoal)
+		protoAssignedGoals = append(protoAssignedGoals, protoGoal)
+	}
+
+	return &pb.InitializeResponse{
+		AssignedGoals: protoAssignedGoals,
+		// #nosec G115 - NewAssignments will never exceed int32 max (limited by goal count)
+		NewAssignments: int32(result.NewAssignments),
+		// #nosec G115 - TotalActive will never exceed int32 max (limited by goal count)
+		TotalActive: int32(result.TotalActive),
+	}
+}
+
+// StartSession initializes the player and returns the player state a game
+// client loads at login in one response. It is disabled unless enabled with
+// SetSessionStart.
+func (s *ChallengeServiceServer) StartSession(
+	ctx context.Context,
+	req *pb.StartSessionRequest,
+) (*pb.StartSessionResponse, error) {
+	if !s.sessionStart {
+		return nil, status.Error(codes.Unimplemented, "session start is not enabled")
+	}
+
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	now := s.now()
+	session, err := service.StartSession(
+		service.WithChallengeRollouts(service.WithAutoRandomSelector(ctx, s.autoRandomSelect), s.rollouts),
+		userID,
+		s.namespace,
+		s.goalCache,
+		s.repo,
+		s.progressInsert,
+		s.logger,
+		now,
+	)
+	s.unclaimedCounts.Invalidate(userID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to start session")
+		return nil, status.Error(codes.Internal, "failed to start session")
+	}
+	service.ResolveInitializeActivationSources(ctx, s.activationSrc, userID, session.InitializeResponse, s.logger)
+	service.EnqueuePlayerInitialized(ctx, s.eventOutbox, s.namespace, userID, session.InitializeResponse, s.logger)
+	s.segmentTargets(ctx).ApplyToAssignedGoals(session.AssignedGoals)
+	s.rollouts.FilterSummary(userID, session.Summary)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"namespace":       s.namespace,
+		"new_assignments": session.NewAssignments,
+		"total_active":    session.TotalActive,
+		"unclaimed":       session.Unclaimed.Unclaimed,
+	}).Info("Started player session")
+
+	return &pb.StartSessionResponse{
+		Initialize: initializeResponseToProto(userID, session.InitializeResponse),
+		Summary:    progressSummaryToProto(session.Summary, now),
+		Unclaimed:  unclaimedCountToProto(session.Unclaimed),
+	}, nil
+}
+
+// SetGoalActive allows players to manually control goal assignment (M3 Phase 3).
+// With reset_progress, deactivating also discards the goal's progress (see
+// service.ResetGoalProgress).
+func (s *ChallengeServiceServer) SetGoalActive(
+	ctx context.Context,
+	req *pb.SetGoalActiveRequest,
+) (*pb.SetGoalActiveResponse, error) {
+	// Extract user ID from JWT token (already validated by interceptor)
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	// Validate request
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	if req.ResetProgress && req.IsActive {
+		return nil, status.Error(codes.InvalidArgument, "reset_progress only applies when deactivating (is_active false)")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":        userID,
+		"challenge_id":   req.ChallengeId,
+		"goal_id":        req.GoalId,
+		"is_active":      req.IsActive,
+		"reset_progress": req.ResetProgress,
+		"namespace":      s.namespace,
+	}).Info("Setting goal active status")
+
+	if err := s.rollouts.CheckGoalAvailable(s.goalCache, userID, req.ChallengeId, req.GoalId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Deactivating is always allowed; activating needs an unlocked challenge
+	if req.IsActive {
+		if err := s.challengePrereqs.CheckUnlocked(ctx, s.repo, s.goalCache, userID, req.ChallengeId); err != nil {
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+	}
+
+	// Call business logic
+	var result *service.SetGoalActiveResponse
+	if req.ResetProgress {
+		result, err = service.ResetGoalProgress(
+			ctx,
+			userID,
+			req.ChallengeId,
+			req.GoalId,
+			s.namespace,
+			s.goalCache,
+			s.repo,
+			s.goalResets,
+			s.logger,
+		)
+	} else {
+		result, err = service.SetGoalActive(
+			s.withRepeatableGoals(ctx),
+			userID,
+			req.ChallengeId,
+			req.GoalId,
+			s.namespace,
+			req.IsActive,
+			s.goalCache,
+			s.repo,
+			s.logger,
+		)
+	}
+	s.unclaimedCounts.Invalidate(userID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"goal_id":      req.GoalId,
+			"is_active":    req.IsActive,
+			"namespace":    s.namespace,
+			"error":        err,
+		}).Error("Failed to set goal active status")
+		var mismatch *mapper.ChallengeMismatchError
+		var otherChallenge *mapper.GoalInOtherChallengeError
+		var resetRefused *mapper.ProgressResetRefusedError
+		var coolingDown *mapper.GoalCoolingDownError
+		var alreadyClaimed *mapper.GoalAlreadyClaimedError
+		if stdErrors.As(err, &mismatch) || stdErrors.As(err, &otherChallenge) || stdErrors.As(err, &resetRefused) ||
+			stdErrors.As(err, &coolingDown) || stdErrors.As(err, &alreadyClaimed) {
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set goal active status: %v", err)
+	}
+
+	// Deactivation keeps the last source
+	var activated []string
+	if result.Changed && result.IsActive {
+		activated = []string{result.GoalID}
+	}
+	sources := service.ResolveActivationSources(ctx, s.activationSrc, userID, activated, service.ActivationSourceManual, []string{result.GoalID}, s.logger)
+
+	// Convert response
+	response := &pb.SetGoalActiveResponse{
+		ChallengeId:      result.ChallengeID,
+		GoalId:           result.GoalID,
+		IsActive:         result.IsActive,
+		Message:          result.Message,
+		Changed:          result.Changed,
+		ActivationSource: sources[result.GoalID],
+		Progress:         int32(result.Progress),
+		Status:           result.Status,
+	}
+
+	// Convert assigned_at timestamp (nullable)
+	response.AssignedAt = mapper.ToProtoTimestamp(result.AssignedAt)
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": req.ChallengeId,
+		"goal_id":      req.GoalId,
+		"is_active":    result.IsActive,
+		"namespace":    s.namespace,
+	}).Info("Successfully set goal active status")
+
+	return response, nil
+}
+
+// GetActivationPreflight reports whether SetGoalActive would activate the goal,
+// from the same checks: the rollout, the challenge lock, then the activation
+// blockers of service.ActivationBlockers.
+func (s *ChallengeServiceServer) GetActivationPreflight(
+	ctx context.Context,
+	req *pb.GetActivationPreflightRequest,
+) (*pb.GetActivationPreflightResponse, error) {
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	if err := s.rollouts.CheckGoalAvailable(s.goalCache, userID, req.ChallengeId, req.GoalId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	preflight, err := service.GetActivationPreflight(
+		s.withRepeatableGoals(ctx),
+		userID,
+		req.ChallengeId,
+		req.GoalId,
+		s.goalCache,
+		s.repo,
+		s.challengePrereqs,
+		s.logger,
+	)
+	if err != nil {
+		var notFound *mapper.GoalNotFoundError
+		var otherChallenge *mapper.GoalInOtherChallengeError
+		if stdErrors.As(err, &notFound) || stdErrors.As(err, &otherChallenge) {
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"goal_id":      req.GoalId,
+			"error":        err,
+		}).Error("Failed to get goal activation preflight")
+		return nil, status.Error(codes.Internal, "failed to get goal activation preflight")
+	}
+
+	return &pb.GetActivationPreflightResponse{
+		ChallengeId: req.ChallengeId,
+		GoalId:      req.GoalId,
+		Allowed:     preflight.Allowed,
+		Blockers:    preflight.Blockers,
+	}, nil
+}
+
+// BatchSelectGoals activates multiple player-selected goals at once (M4)
+func (s *ChallengeServiceServer) BatchSelectGoals(
+	ctx context.Context,
+	req *pb.BatchSelectRequest,
+) (*pb.GoalSelectionResponse, error) {
+	// Extract user ID from JWT token (already validated by interceptor)
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	// Validate request
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if len(req.GoalIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "goal_ids cannot be empty")
+	}
+
+	clientContext := service.ClientContext(req.ClientContext)
+	if err := clientContext.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx = service.WithClientContext(ctx, clientContext)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"challenge_id":     req.ChallengeId,
+		"goal_count":       len(req.GoalIds),
+		"replace_existing": req.ReplaceExisting,
+		"namespace":        s.namespace,
+	}).Info("Batch selecting goals")
+
+	if err := s.rollouts.CheckAvailable(userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	if err := s.challengePrereqs.CheckUnlocked(ctx, s.repo, s.goalCache, userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Call business logic
+	result, err := service.BatchSelectGoals(
+		s.withRepeatableGoals(ctx),
+		userID,
+		req.ChallengeId,
+		req.GoalIds,
+		req.ReplaceExisting,
+		s.namespace,
+		s.goalCache,
+		s.repo,
+		s.goalSelections,
+		s.logger,
+	)
+	s.unclaimedCounts.Invalidate(userID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"goal_count":   len(req.GoalIds),
+			"error":        err,
+		}).Error("Failed to batch select goals")
+
+		var configInvalid *mapper.ConfigInvalidError
+		var otherChallenge *mapper.GoalInOtherChallengeError
+		if stdErrors.As(err, &configInvalid) || stdErrors.As(err, &otherChallenge) {
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+
+		// Return 404 Not Found for resource not found errors
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to batch select goals: %v", err)
+	}
+	service.ResolveSelectionActivationSources(ctx, s.activationSrc, userID, result, service.ActivationSourceManual, s.logger)
+	service.EnqueueGoalsSelected(ctx, s.eventOutbox, s.namespace, userID, result, service.ActivationSourceManual, s.logger)
+	s.segmentTargets(ctx).ApplyToSelectedGoals(result.SelectedGoals)
+
+	// Convert to protobuf response
+	protoSelectedGoals := make([]*pb.SelectedGoal, 0, len(result.SelectedGoals))
+	for _, selectedGoal := range result.SelectedGoals {
+		protoGoal, err := selectedGoalToProto(selectedGoal)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id": userID,
+				"goal_id": selectedGoal.GoalID,
+				"error":   err,
+			}).Error("Failed to convert selected goal to proto")
+			continue
+		}
+		protoSelectedGoals = append(protoSelectedGoals, protoGoal)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": req.ChallengeId,
+		"selected":     len(protoSelectedGoals),
+		"total_active": result.TotalActiveGoals,
+	}).Info("Successfully batch selected goals")
+
+	return &pb.GoalSelectionResponse{
+		SelectedGoals: protoSelectedGoals,
+		ChallengeId:   result.ChallengeID,
+		// #nosec G115 - counts will never exceed int32 max (limited by goal count / request size)
+		TotalActiveGoals:  int32(result.TotalActiveGoals),
+		ReplacedGoals:     result.ReplacedGoals,
+		RequestedCount:    int32(result.RequestedCount),    // #nosec G115
+		ChangedCount:      int32(result.ChangedCount),      // #nosec G115
+		AvailablePoolSize: int32(result.AvailablePoolSize), // #nosec G115
+		Partial:           result.Partial,
+		ClientContext:     req.ClientContext,
+	}, nil
+}
+
+// RandomSelectGoals randomly selects N goals from a challenge and activates them (M4)
+func (s *ChallengeServiceServer) RandomSelectGoals(
+	ctx context.Context,
+	req *pb.RandomSelectRequest,
+) (*pb.GoalSelectionResponse, error) {
+	// Extract user ID from JWT token (already validated by interceptor)
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	// Validate request
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if req.Count <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "count must be greater than 0")
+	}
+
+	clientContext := service.ClientContext(req.ClientContext)
+	if err := clientContext.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx = service.WithClientContext(ctx, clientContext)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":          userID,
+		"challenge_id":     req.ChallengeId,
+		"count":            req.Count,
+		"replace_existing": req.ReplaceExisting,
+		"exclude_active":   req.ExcludeActive,
+		"strict":           req.Strict,
+		"namespace":        s.namespace,
+	}).Info("Random selecting goals")
+
+	if err := s.rollouts.CheckAvailable(userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	if err := s.challengePrereqs.CheckUnlocked(ctx, s.repo, s.goalCache, userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Call business logic
+	result, err := service.RandomSelectGoals(
+		s.withRepeatableGoals(ctx),
+		userID,
+		req.ChallengeId,
+		// #nosec G115 - Count is validated to be > 0, safe to convert
+		int(req.Count),
+		req.ReplaceExisting,
+		req.ExcludeActive,
+		req.Strict,
+		s.namespace,
+		s.goalCache,
+		s.repo,
+		s.goalSelections,
+		s.logger,
+	)
+	s.unclaimedCounts.Invalidate(userID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"count":        req.Count,
+			"error":        err,
+		}).Error("Failed to random select goals")
+
+		// Strict selection with fewer goals available than requested
+		var insufficientGoals *mapper.InsufficientGoalsError
+		if stdErrors.As(err, &insufficientGoals) {
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+
+		// Check for insufficient goals error (M4: zero goals available)
+		var challengeErr *errors.ChallengeError
+		if stdErrors.As(err, &challengeErr) && challengeErr.Code == errors.ErrCodeInsufficientGoals {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+
+		// Return 404 Not Found for resource not found errors
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to random select goals: %v", err)
+	}
+	service.ResolveSelectionActivationSources(ctx, s.activationSrc, userID, result, service.ActivationSourceRandom, s.logger)
+	service.EnqueueGoalsSelected(ctx, s.eventOutbox, s.namespace, userID, result, service.ActivationSourceRandom, s.logger)
+	s.segmentTargets(ctx).ApplyToSelectedGoals(result.SelectedGoals)
+
+	// Convert to protobuf response
+	protoSelectedGoals := make([]*pb.SelectedGoal, 0, len(result.SelectedGoals))
+	for _, selectedGoal := range result.SelectedGoals {
+		protoGoal, err := selectedGoalToProto(selectedGoal)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id": userID,
+				"goal_id": selectedGoal.GoalID,
+				"error":   err,
+			}).Error("Failed to convert selected goal to proto")
+			continue
+		}
+		protoSelectedGoals = append(protoSelectedGoals, protoGoal)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": req.ChallengeId,
+		"selected":     len(protoSelectedGoals),
+		"total_active": result.TotalActiveGoals,
+	}).Info("Successfully random selected goals")
+
+	return &pb.GoalSelectionResponse{
+		SelectedGoals: protoSelectedGoals,
+		ChallengeId:   result.ChallengeID,
+		// #nosec G115 - counts will never exceed int32 max (limited by goal count / request size)
+		TotalActiveGoals:  int32(result.TotalActiveGoals),
+		ReplacedGoals:     result.ReplacedGoals,
+		RequestedCount:    int32(result.RequestedCount),    // #nosec G115
+		ChangedCount:      int32(result.ChangedCount),      // #nosec G115
+		AvailablePoolSize: int32(result.AvailablePoolSize), // #nosec G115
+		Partial:           result.Partial,
+		ClientContext:     req.ClientContext,
+	}, nil
+}
+
+// ClaimGoalReward claims reward for a completed goal. With x-debug-timing it
+// marks the auth, db, grant and encode phases (see common.DebugTiming).
+func (s *ChallengeServiceServer) ClaimGoalReward(
+	ctx context.Context,
+	req *pb.ClaimRewardRequest,
+) (*pb.ClaimRewardResponse, error) {
+	// Extract user ID from JWT token (already validated by interceptor)
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+	timings := common.TimingsFromContext(ctx)
+	timings.Mark("auth")
+
+	// Validate request
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	precondition := service.ClaimPrecondition{ExpectedStatus: req.ExpectedStatus}
+	if req.IfSnapshotAfter != nil {
+		snapshotAt := req.IfSnapshotAfter.AsTime()
+		precondition.SnapshotAt = &snapshotAt
+	}
+	if err := precondition.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	clientContext := service.ClientContext(req.ClientContext)
+	if err := clientContext.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx = service.WithClientContext(ctx, clientContext)
+
+	delivery, err := s.rewardDeliveries.Resolve(req.GoalId, agsClient.RewardDelivery{
+		CollectionID: req.GetDelivery().GetCollectionId(),
+		StoreID:      req.GetDelivery().GetStoreId(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid delivery: %v", err)
+	}
+	ctx = agsClient.WithRewardDelivery(ctx, delivery)
+
+	grantNamespace, err := s.grantNamespaces.Resolve(ctx)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"goal_id":   req.GoalId,
+			"namespace": s.namespace,
+		}).WithError(err).Warn("Rejected claim from a namespace that is not grantable")
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+	ctx = service.WithGrantNamespace(ctx, grantNamespace)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"goal_id":         req.GoalId,
+		"challenge_id":    req.ChallengeId,
+		"namespace":       s.namespace,
+		"grant_namespace": grantNamespace,
+	}).Info("Claiming goal reward")
+
+	if err := s.rollouts.CheckGoalAvailable(s.goalCache, userID, req.ChallengeId, req.GoalId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Reject before touching progress rows, AGS or the claim cap when claims are frozen
+	if err := s.claimFreezes.Check(ctx, userID, s.namespace); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Reject before touching progress rows or AGS when the user hit the claim cap
+	if err := s.claimCap.Check(ctx, userID, s.namespace, req.GoalId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	if err := s.challengePrereqs.CheckUnlocked(ctx, s.repo, s.goalCache, userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+	timings.Mark("db")
+
+	result, err := s.claimGoal(ctx, userID, req.ChallengeId, req.GoalId, precondition)
+	timings.Mark("grant")
+	if err != nil {
+		// Map domain errors to gRPC status codes
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Convert reward to proto
+	protoReward, err := claimRewardToProto(result)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"goal_id":      req.GoalId,
+			"challenge_id": req.ChallengeId,
+			"error":        err,
+		}).Error("Failed to convert reward to proto")
+		return nil, status.Error(codes.Internal, "failed to convert reward data")
+	}
+
+	// NOTE: Cannot return objects to pool here - gRPC serializes AFTER handler returns
+	// This would cause a race condition. Pooling only helps if done at a different layer.
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"goal_id":      req.GoalId,
+		"challenge_id": req.ChallengeId,
+		"reward_type":  result.Reward.Type,
+		"reward_id":    result.Reward.RewardID,
+	}).Info("Successfully claimed goal reward")
+
+	response := &pb.ClaimRewardResponse{
+		GoalId:        result.GoalID,
+		Status:        result.Status,
+		Reward:        protoReward,
+		ClaimedAt:     mapper.ToProtoTimestamp(&result.ClaimedAt),
+		EntitlementId: result.Grant.EntitlementID,
+		WalletId:      result.Grant.WalletID,
+		RewardCap:     rewardCapOutcomeToProto(result.RewardCap),
+		Repeat:        goalRepeatToProto(result.Repeat),
+		ClientContext: req.ClientContext,
+	}
+	timings.Mark("encode")
+
+	// The reward is granted at this point, so a failure only omits the follow-ups
+	if req.IncludeFollowUps {
+		followUps, err := s.challengePrereqs.ClaimFollowUps(
+			ctx, s.repo, s.goalCache, s.segmentTargets(ctx), userID, req.ChallengeId, req.GoalId,
+		)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"goal_id":      req.GoalId,
+				"challenge_id": req.ChallengeId,
+			}).WithError(err).Warn("Failed to load claim follow-ups")
+		} else {
+			response.FollowUps = &pb.ClaimFollowUps{
+				ChallengeCompleted:   followUps.ChallengeCompleted,
+				UnlockedChallengeIds: followUps.UnlockedChallengeIDs,
+				ClaimableGoalIds:     followUps.ClaimableGoalIDs,
+			}
+		}
+		timings.Mark("db")
+	}
+
+	return response, nil
+}
+
+// claimGoal runs the claim flow for one goal whose claim cap and challenge lock
+// the caller has checked, and records a successful claim toward the cap. A claim
+// whose grant failed permanently is recorded as a failed grant, and a successful
+// one resolves the goal's failed grant, if any. A multi-step goal is only
+// claimed once every step is complete (see service.GoalSteps.CheckComplete).
+// Reward caps and repeatable goals apply (see service.WithRewardCaps and
+// service.WithRepeatableGoals).
+func (s *ChallengeServiceServer) claimGoal(
+	ctx context.Context,
+	userID, challengeID, goalID string,
+	precondition service.ClaimPrecondition,
+) (*service.ClaimResult, error) {
+	ctx = service.WithRewardCaps(ctx, s.rewardCaps, s.rewardCapRepo)
+	ctx = s.withRepeatableGoals(ctx)
+	ctx = s.withDefaultDelivery(ctx, goalID)
+	ctx = service.WithChallengeMetrics(ctx, s.challengeMetrics)
+	if err := s.goalSteps.CheckComplete(ctx, s.stepProgress, userID, goalID); err != nil {
+		return nil, err
+	}
+
+	result, err := service.ClaimGoalReward(
+		ctx,
+		userID,
+		goalID,
+		challengeID,
+		s.namespace,
+		s.goalCache,
+		s.repo,
+		s.claimOutbox,
+		s.claimStaleAfter,
+		s.rewardClient,
+		s.segmentTargets(ctx),
+		precondition,
+		s.logger,
+	)
+	// Also on failure: a claim that fails after the grant may still mark the row
+	s.unclaimedCounts.Invalidate(userID)
+	if err != nil {
+		s.recordFailedGrant(ctx, userID, err)
+		return nil, err
+	}
+
+	// Count the claim toward the cap once the reward is granted. This stays in
+	// the request: the next claim must see it.
+	s.claimCap.Record(ctx, userID, s.namespace, goalID)
+	s.recordRewardGrant(ctx, result)
+	s.resolveFailedGrant(ctx, userID, goalID)
+
+	return result, nil
+}
+
+// withDefaultDelivery returns a context delivering the reward of goalID as its
+// default delivery, unless ctx already carries the delivery the claim requested.
+func (s *ChallengeServiceServer) withDefaultDelivery(ctx context.Context, goalID string) context.Context {
+	if _, ok := agsClient.RewardDeliveryFrom(ctx); ok {
+		return ctx
+	}
+	return agsClient.WithRewardDelivery(ctx, s.rewardDeliveries.Default(goalID))
+}
+
+// ClaimAllCompleted claims every completed goal of the user across all
+// challenges, up to service.DefaultClaimAllLimit per call, through the same
+// claim flow as ClaimGoalReward.
+func (s *ChallengeServiceServer) ClaimAllCompleted(
+	ctx context.Context,
+	req *pb.ClaimAllCompletedRequest,
+) (*pb.ClaimAllCompletedResponse, error) {
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	grantNamespace, err := s.grantNamespaces.Resolve(ctx)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+		}).WithError(err).Warn("Rejected claim from a namespace that is not grantable")
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+	ctx = service.WithGrantNamespace(ctx, grantNamespace)
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"namespace":       s.namespace,
+		"grant_namespace": grantNamespace,
+	}).Info("Claiming all completed goals")
+
+	// A freeze rejects the whole call rather than failing every goal
+	if err := s.claimFreezes.Check(ctx, userID, s.namespace); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Challenge locks are judged by service.ClaimableGoals from the user's full progress
+	claim := func(ctx context.Context, goal *commonDomain.Goal) (*service.ClaimResult, error) {
+		if err := s.claimCap.Check(ctx, userID, s.namespace, goal.ID); err != nil {
+			return nil, err
+		}
+		return s.claimGoal(ctx, userID, goal.ChallengeID, goal.ID, service.ClaimPrecondition{})
+	}
+
+	result, err := service.ClaimAllCompleted(ctx, userID, service.DefaultClaimAllLimit, s.goalCache, s.repo, s.challengePrereqs, s.rollouts, s.segmentTargets(ctx), claim, s.logger)
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	return claimAllToProto(result), nil
+}
+
+// claimAllToProto converts a claim-all result, mapping each failed claim to the
+// gRPC status ClaimGoalReward would have returned.
+func claimAllToProto(result *service.ClaimAllResult) *pb.ClaimAllCompletedResponse {
+	resp := &pb.ClaimAllCompletedResponse{
+		Outcomes:     make([]*pb.ClaimOutcome, 0, len(result.Outcomes)),
+		Rewards:      make([]*pb.RewardSummary, 0, len(result.Rewards)),
+		ClaimedCount: int32(result.Claimed), // #nosec G115 - bounded by service.DefaultClaimAllLimit
+		FailedCount:  int32(result.Failed),  // #nosec G115 - bounded by service.DefaultClaimAllLimit
+		HasMore:      result.HasMore,
+	}
+
+	for _, outcome := range result.Outcomes {
+		pbOutcome := &pb.ClaimOutcome{
+			ChallengeId: outcome.ChallengeID,
+			GoalId:      outcome.GoalID,
+		}
+
+		if outcome.Err != nil || outcome.Result == nil {
+			st, _ := status.FromError(mapper.MapErrorToGRPCStatus(outcome.Err))
+			pbOutcome.Status = "failed"
+			pbOutcome.ErrorCode = st.Code().String()
+			pbOutcome.Error = st.Message()
+			resp.Outcomes = append(resp.Outcomes, pbOutcome)
+			continue
+		}
+
+		// The reward is granted either way; failing the response would hide the other claims
+		protoReward, err := claimRewardToProto(outcome.Result)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      outcome.Result.UserID,
+				"goal_id":      outcome.GoalID,
+				"challenge_id": outcome.ChallengeID,
+				"error":        err,
+			}).Error("Failed to convert reward to proto")
+		}
+
+		pbOutcome.Status = "claimed"
+		pbOutcome.Reward = protoReward
+		pbOutcome.ClaimedAt = mapper.ToProtoTimestamp(&outcome.Result.ClaimedAt)
+		pbOutcome.EntitlementId = outcome.Result.Grant.EntitlementID
+		pbOutcome.WalletId = outcome.Result.Grant.WalletID
+		pbOutcome.RewardCap = rewardCapOutcomeToProto(outcome.Result.RewardCap)
+		resp.Outcomes = append(resp.Outcomes, pbOutcome)
+	}
+
+	for _, total := range result.Rewards {
+		resp.Rewards = append(resp.Rewards, &pb.RewardSummary{
+			Type:      total.Type,
+			RewardId:  total.RewardID,
+			Quantity:  int32(total.Quantity), // #nosec G115 - sum of at most DefaultClaimAllLimit config quantities
+			GoalCount: int32(total.Goals),    // #nosec G115 - bounded by service.DefaultClaimAllLimit
+		})
+	}
+
+	return resp
+}
+
+// claimRewardToProto converts the reward a claim granted; nil when it granted
+// none.
+func claimRewardToProto(result *service.ClaimResult) (*pb.Reward, error) {
+	if !result.Granted() {
+		return nil, nil
+	}
+	return mapper.RewardToProto(&result.Reward)
+}
+
+// rewardCapOutcomeToProto converts a claim's reward cap outcome; nil for nil.
+func rewardCapOutcomeToProto(outcome *service.RewardCapOutcome) *pb.RewardCapOutcome {
+	if outcome == nil {
+		return nil
+	}
+	return &pb.RewardCapOutcome{
+		Granted:   outcome.Granted,
+		Limit:     int32(outcome.Limit),     // #nosec G115 - config limits fit in int32
+		Remaining: int32(outcome.Remaining), // #nosec G115 - at most Limit
+	}
+}
+
+// goalRepeatToProto converts a repeatable goal's claim history; nil for nil.
+func goalRepeatToProto(repeat *service.GoalRepeat) *pb.GoalRepeat {
+	if repeat == nil {
+		return nil
+	}
+	return mapper.GoalRepeatToProto(repeat.TimesClaimed, repeat.AvailableAt)
+}
+
+// recordRewardGrant stores the AGS IDs of a granted reward on the progress row.
+// The reward is already granted and the row claimed, so a failure only loses the
+// IDs for later support lookups and does not fail the claim.
+func (s *ChallengeServiceServer) recordRewardGrant(ctx context.Context, result *service.ClaimResult) {
+	if s.rewardGrants == nil || result.Grant.IsZero() {
+		return
+	}
+
+	s.background.Submit(ctx, "record_reward_grant", common.TaskBestEffort, func(ctx context.Context) {
+		err := s.rewardGrants.RecordGrant(ctx, result.UserID, result.GoalID, result.Grant.EntitlementID, result.Grant.WalletID)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":        result.UserID,
+				"goal_id":        result.GoalID,
+				"entitlement_id": result.Grant.EntitlementID,
+				"wallet_id":      result.Grant.WalletID,
+				"error":          err,
+			}).Warn("Failed to record reward grant IDs")
+		}
+	})
+}
+
+// recordFailedGrant dead-letters a claim that failed permanently. It is critical:
+// support finds stuck players only through the dead letter.
+func (s *ChallengeServiceServer) recordFailedGrant(ctx context.Context, userID string, err error) {
+	if s.failedGrants == nil {
+		return
+	}
+
+	s.background.Submit(ctx, "record_failed_grant", common.TaskCritical, func(ctx context.Context) {
+		s.failedGrants.Record(ctx, userID, s.namespace, err)
+	})
+}
+
+// resolveFailedGrant deletes the dead letter of a goal that is now claimed. It is
+// best effort: a stale dead letter is resolved when an admin retries it.
+func (s *ChallengeServiceServer) resolveFailedGrant(ctx context.Context, userID, goalID string) {
+	if s.failedGrants == nil {
+		return
+	}
+
+	s.background.Submit(ctx, "resolve_failed_grant", common.TaskBestEffort, func(ctx context.Context) {
+		s.failedGrants.Resolve(ctx, userID, goalID)
+	})
+}
+
+// GetAvailableGoals returns the goals of a challenge a random selection could
+// pick for the user, without selecting any.
+func (s *ChallengeServiceServer) GetAvailableGoals(
+	ctx context.Context,
+	req *pb.GetAvailableGoalsRequest,
+) (*pb.GetAvailableGoalsResponse, error) {
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	if err := s.rollouts.CheckAvailable(userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	// Same lock check as RandomSelectGoals, so a locked challenge has no pool
+	if err := s.challengePrereqs.CheckUnlocked(ctx, s.repo, s.goalCache, userID, req.ChallengeId); err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	goalIDs, err := service.AvailableGoals(s.withRepeatableGoals(ctx), userID, req.ChallengeId, req.ExcludeActive, s.goalCache, s.repo, s.logger)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": req.ChallengeId,
+			"error":        err,
+		}).Error("Failed to get available goals")
+		return nil, status.Error(codes.Internal, "failed to get available goals")
+	}
+
+	return &pb.GetAvailableGoalsResponse{
+		ChallengeId: req.ChallengeId,
+		GoalIds:     goalIDs,
+		// #nosec G115 - bounded by the goals of one challenge
+		AvailablePoolSize: int32(len(goalIDs)),
+	}, nil
+}
+
+// GetRotationStatus returns rotation schedule and current period info for a challenge (M5)
+func (s *ChallengeServiceServer) GetRotationStatus(
+	ctx context.Context,
+	req *pb.GetRotationStatusRequest,
+) (*pb.GetRotationStatusResponse, error) {
+	if req.ChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	}
+
+	// Look up challenge in cache
+	challenge := s.goalCache.GetChallengeByChallengeID(req.ChallengeId)
+	if challenge == nil {
+		return nil, status.Errorf(codes.NotFound, "challenge not found: %s", req.ChallengeId)
+	}
+
+	// Find the first goal with rotation enabled (all goals in a challenge share the same rotation config)
+	var rotationCfg *commonDomain.RotationConfig
+	for _, goal := range challenge.Goals {
+		if goal.Rotation != nil && goal.Rotation.Enabled {
+			rotationCfg = goal.Rotation
+			break
+		}
+	}
+
+	// Build rotation info
+	now := time.Now().UTC()
+	rotationInfo := buildRotationInfo(rotationCfg, now)
+
+	return &pb.GetRotationStatusResponse{
+		ChallengeId: req.ChallengeId,
+		Rotation:    rotationInfo,
+	}, nil
+}
+
+// buildRotationInfo constructs a RotationInfo proto from config and current time.
+func buildRotationInfo(cfg *commonDomain.RotationConfig, now time.Time) *pb.RotationInfo {
+	if cfg == nil {
+		return &pb.RotationInfo{Enabled: false}
+	}
+
+	info := &pb.RotationInfo{
+		Enabled:  true,
+		Type:     string(cfg.Type),
+		Schedule: string(cfg.Schedule),
+	}
+
+	currentStart := rotation.CalculateLastRotationBoundary(cfg.Schedule, now)
+	currentEnd := rotation.CalculateNextRotationBoundary(cfg.Schedule, now)
+	if currentStart.IsZero() || currentEnd.IsZero() {
+		return info
+	}
+
+	currentExpiresIn := int32(currentEnd.Sub(now).Seconds())
+	if currentExpiresIn < 0 {
+		currentExpiresIn = 0
+	}
+	info.CurrentPeriod = &pb.RotationPeriod{
+		StartTime:        mapper.ToProtoTimestamp(&currentStart),
+		EndTime:          mapper.ToProtoTimestamp(&currentEnd),
+		ExpiresInSeconds: currentExpiresIn,
+	}
+
+	// Next period (starts when current ends)
+	nextEnd := rotation.CalculateNextRotationBoundary(cfg.Schedule, currentEnd)
+	if !nextEnd.IsZero() {
+		info.NextPeriod = &pb.RotationPeriod{
+			StartTime: mapper.ToProtoTimestamp(&currentEnd),
+			EndTime:   mapper.ToProtoTimestamp(&nextEnd),
+		}
+	}
+
+	return info
+}
+
+// ReloadConfig reloads the challenge config file and returns what changed.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CONFIG permission.
+func (s *ChallengeServiceServer) ReloadConfig(
+	ctx context.Context,
+	req *pb.ReloadConfigRequest,
+) (*pb.ReloadConfigResponse, error) {
+	if s.configReloader == nil {
+		return nil, status.Error(codes.Unimplemented, "config reload is not enabled")
+	}
+
+	if req.RecomputeStatuses && s.statusRecomputes == nil {
+		return nil, status.Error(codes.Unimplemented, "status recomputes are not enabled")
+	}
+
+	userID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":   userID,
+		"namespace": s.namespace,
+	}).Info("Reloading challenge config")
+
+	diff, err := s.configReloader.Reload(ctx)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to reload challenge config")
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to reload config: %v", err)
+	}
+
+	response := &pb.ReloadConfigResponse{}
+	if req.RecomputeStatuses {
+		queued := s.statusRecomputes.QueueLoweredTargets(ctx, diff, userID, common.GetClientIPFromContext(ctx))
+		// #nosec G115 - bounded by service.StatusRecomputeQueueSize
+		response.RecomputeUsersQueued = int32(queued)
+	}
+	response.Diff = configDiffToProto(diff)
+
+	return response, nil
+}
+
+// GetConfigSchema returns the JSON Schema challenge config files are validated
+// against (see service.ConfigSchema).
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CONFIG permission.
+func (s *ChallengeServiceServer) GetConfigSchema(
+	_ context.Context,
+	_ *pb.GetConfigSchemaRequest,
+) (*pb.GetConfigSchemaResponse, error) {
+	schema := &structpb.Struct{}
+	if err := protojson.Unmarshal(service.ConfigSchema(), schema); err != nil {
+		logrus.WithError(err).Error("Failed to encode config schema")
+		return nil, status.Error(codes.Internal, "failed to encode config schema")
+	}
+
+	return &pb.GetConfigSchemaResponse{Schema: schema}, nil
+}
+
+// GetClaimCap returns a user's claim count against the rolling claim cap.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CLAIMCAP permission.
+func (s *ChallengeServiceServer) GetClaimCap(
+	ctx context.Context,
+	req *pb.GetClaimCapRequest,
+) (*pb.ClaimCapStatus, error) {
+	if s.claimCap == nil {
+		return nil, status.Error(codes.Unimplemented, "claim cap is not configured")
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	capStatus, err := s.claimCap.Status(ctx, req.UserId, s.namespace)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"target_user_id": req.UserId,
+			"namespace":      s.namespace,
+			"error":          err,
+		}).Error("Failed to get claim cap status")
+		return nil, status.Error(codes.Internal, "failed to get claim cap status")
+	}
+
+	return &pb.ClaimCapStatus{
+		UserId:  capStatus.UserID,
+		Enabled: capStatus.Enabled,
+		// #nosec G115 - CLAIM_CAP_PER_DAY and per-day claim counts fit in int32
+		Limit: int32(capStatus.Limit),
+		// #nosec G115 - see above
+		ClaimsInWindow:    int32(capStatus.ClaimsInWindow),
+		RetryAfterSeconds: int64(math.Ceil(capStatus.RetryAfter.Seconds())),
+	}, nil
+}
+
+// ResetClaimCap clears a user's claims in the rolling window.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CLAIMCAP permission.
+func (s *ChallengeServiceServer) ResetClaimCap(
+	ctx context.Context,
+	req *pb.ResetClaimCapRequest,
+) (*pb.ResetClaimCapResponse, error) {
+	if s.claimCap == nil {
+		return nil, status.Error(codes.Unimplemented, "claim cap is not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	cleared, err := s.claimCap.Reset(ctx, req.UserId, s.namespace)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":        adminID,
+			"target_user_id": req.UserId,
+			"namespace":      s.namespace,
+			"error":          err,
+		}).Error("Failed to reset claim cap")
+		return nil, status.Error(codes.Internal, "failed to reset claim cap")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         adminID,
+		"target_user_id":  req.UserId,
+		"namespace":       s.namespace,
+		"claims_cleared":  cleared,
+		"security_review": true,
+	}).Info("Admin reset claim cap")
+
+	return &pb.ResetClaimCapResponse{
+		UserId: req.UserId,
+		// #nosec G115 - claims per window fit in int32
+		ClaimsCleared: int32(cleared),
+	}, nil
+}
+
+// SetClaimFreeze stops a user from claiming rewards until req.Until.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CLAIMFREEZE permission.
+func (s *ChallengeServiceServer) SetClaimFreeze(
+	ctx context.Context,
+	req *pb.SetClaimFreezeRequest,
+) (*pb.ClaimFreeze, error) {
+	if s.claimFreezes == nil {
+		return nil, status.Error(codes.Unimplemented, "claim freezes are not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if req.Until == nil || !req.Until.AsTime().After(s.claimFreezes.Now()) {
+		return nil, status.Error(codes.InvalidArgument, "until must be in the future")
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	freeze := &serviceRepo.ClaimFreeze{
+		UserID:      req.UserId,
+		Namespace:   s.namespace,
+		FrozenUntil: req.Until.AsTime(),
+		Reason:      req.Reason,
+		ActorUserID: adminID,
+		CreatedAt:   s.claimFreezes.Now(),
+	}
+	if err := s.claimFreezes.Freeze(ctx, freeze, common.GetClientIPFromContext(ctx)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":        adminID,
+			"target_user_id": req.UserId,
+			"namespace":      s.namespace,
+			"error":          err,
+		}).Error("Failed to set claim freeze")
+		return nil, status.Error(codes.Internal, "failed to set claim freeze")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         adminID,
+		"target_user_id":  req.UserId,
+		"namespace":       s.namespace,
+		"frozen_until":    freeze.FrozenUntil,
+		"reason":          req.Reason,
+		"security_review": true,
+	}).Info("Admin froze user claims")
+
+	return claimFreezeToProto(freeze), nil
+}
+
+// ListClaimFreezes lists the claim freezes in effect.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CLAIMFREEZE permission.
+func (s *ChallengeServiceServer) ListClaimFreezes(
+	ctx context.Context,
+	req *pb.ListClaimFreezesRequest,
+) (*pb.ListClaimFreezesResponse, error) {
+	if s.claimFreezes == nil {
+		return nil, status.Error(codes.Unimplemented, "claim freezes are not configured")
+	}
+
+	freezes, truncated, err := s.claimFreezes.List(ctx, s.namespace, int(req.Limit))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to list claim freezes")
+		return nil, status.Error(codes.Internal, "failed to list claim freezes")
+	}
+
+	resp := &pb.ListClaimFreezesResponse{
+		Freezes:   make([]*pb.ClaimFreeze, 0, len(freezes)),
+		Truncated: truncated,
+	}
+	for _, freeze := range freezes {
+		resp.Freezes = append(resp.Freezes, claimFreezeToProto(freeze))
+	}
+
+	return resp, nil
+}
+
+// RemoveClaimFreeze lifts a user's claim freeze.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:CLAIMFREEZE permission.
+func (s *ChallengeServiceServer) RemoveClaimFreeze(
+	ctx context.Context,
+	req *pb.RemoveClaimFreezeRequest,
+) (*pb.RemoveClaimFreezeResponse, error) {
+	if s.claimFreezes == nil {
+		return nil, status.Error(codes.Unimplemented, "claim freezes are not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	removed, err := s.claimFreezes.Unfreeze(ctx, req.UserId, s.namespace, adminID, req.Reason, common.GetClientIPFromContext(ctx))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":        adminID,
+			"target_user_id": req.UserId,
+			"namespace":      s.namespace,
+			"error":          err,
+		}).Error("Failed to remove claim freeze")
+		return nil, status.Error(codes.Internal, "failed to remove claim freeze")
+	}
+
+	if removed != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":         adminID,
+			"target_user_id":  req.UserId,
+			"namespace":       s.namespace,
+			"frozen_until":    removed.FrozenUntil,
+			"reason":          req.Reason,
+			"security_review": true,
+		}).Info("Admin removed user claim freeze")
+	}
+
+	return &pb.RemoveClaimFreezeResponse{
+		UserId:  req.UserId,
+		Removed: removed != nil,
+	}, nil
+}
+
+// claimFreezeToProto converts a claim freeze to its API representation.
+func claimFreezeToProto(freeze *serviceRepo.ClaimFreeze) *pb.ClaimFreeze {
+	return &pb.ClaimFreeze{
+		UserId:      freeze.UserID,
+		FrozenUntil: mapper.ToProtoTimestamp(&freeze.FrozenUntil),
+		Reason:      freeze.Reason,
+		ActorUserId: freeze.ActorUserID,
+		CreatedAt:   mapper.ToProtoTimestamp(&freeze.CreatedAt),
+	}
+}
+
+// ListFailedGrants lists the claims whose reward grant failed permanently.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:FAILEDGRANT permission.
+func (s *ChallengeServiceServer) ListFailedGrants(
+	ctx context.Context,
+	req *pb.ListFailedGrantsRequest,
+) (*pb.ListFailedGrantsResponse, error) {
+	if s.failedGrants == nil {
+		return nil, status.Error(codes.Unimplemented, "failed grants are not configured")
+	}
+
+	grants, truncated, err := s.failedGrants.List(ctx, s.namespace, int(req.Limit))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to list failed grants")
+		return nil, status.Error(codes.Internal, "failed to list failed grants")
+	}
+
+	resp := &pb.ListFailedGrantsResponse{
+		FailedGrants: make([]*pb.FailedGrant, 0, len(grants)),
+		Truncated:    truncated,
+	}
+	for _, grant := range grants {
+		resp.FailedGrants = append(resp.FailedGrants, &pb.FailedGrant{
+			Id:          grant.ID,
+			UserId:      grant.UserID,
+			ChallengeId: grant.ChallengeID,
+			GoalId:      grant.GoalID,
+			Reward: &pb.Reward{
+				Type:     grant.RewardType,
+				RewardId: grant.RewardID,
+				// #nosec G115 - config reward quantities fit in int32
+				Quantity: int32(grant.RewardQuantity),
+			},
+			ErrorClass: grant.ErrorClass,
+			LastError:  grant.LastError,
+			// #nosec G115 - claim attempts fit in int32
+			Attempts:      int32(grant.Attempts),
+			FirstFailedAt: mapper.ToProtoTimestamp(&grant.FirstFailedAt),
+			LastFailedAt:  mapper.ToProtoTimestamp(&grant.LastFailedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// RetryFailedGrant claims the goal of a failed grant for its player through the
+// normal claim flow. The claim cap and claim freezes are not checked, as for
+// ForceCompleteGoal, but the claim still counts toward the cap.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:FAILEDGRANT permission.
+func (s *ChallengeServiceServer) RetryFailedGrant(
+	ctx context.Context,
+	req *pb.RetryFailedGrantRequest,
+) (*pb.RetryFailedGrantResponse, error) {
+	if s.failedGrants == nil {
+		return nil, status.Error(codes.Unimplemented, "failed grants are not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	grant, err := s.failedGrants.Get(ctx, s.namespace, req.Id)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":         adminID,
+			"failed_grant_id": req.Id,
+			"namespace":       s.namespace,
+			"error":           err,
+		}).Error("Failed to get failed grant")
+		return nil, status.Error(codes.Internal, "failed to get failed grant")
+	}
+	if grant == nil {
+		return nil, status.Errorf(codes.NotFound, "failed grant not found (id: %d)", req.Id)
+	}
+
+	// Grant where the player's claim failed, not in the admin's token namespace
+	result, err := s.claimGoal(
+		service.WithGrantNamespace(ctx, grant.GrantNamespace),
+		grant.UserID, grant.ChallengeID, grant.GoalID, service.ClaimPrecondition{},
+	)
+	if err != nil {
+		// Claimed some other way since, e.g. by ForceCompleteGoal with auto_claim
+		var alreadyClaimed *mapper.GoalAlreadyClaimedError
+		if stdErrors.As(err, &alreadyClaimed) {
+			s.resolveFailedGrant(ctx, grant.UserID, grant.GoalID)
+		}
+		logrus.WithFields(logrus.Fields{
+			"user_id":         adminID,
+			"target_user_id":  grant.UserID,
+			"goal_id":         grant.GoalID,
+			"failed_grant_id": grant.ID,
+			"error":           err,
+		}).Warn("Admin retry of failed reward grant failed")
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":         adminID,
+		"target_user_id":  grant.UserID,
+		"goal_id":         grant.GoalID,
+		"failed_grant_id": grant.ID,
+		"attempts":        grant.Attempts,
+		"reward_type":     result.Reward.Type,
+		"reward_id":       result.Reward.RewardID,
+	}).Info("Admin retried failed reward grant")
+
+	reward, err := mapper.RewardToProto(&result.Reward)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id":        adminID,
+			"target_user_id": grant.UserID,
+			"goal_id":        grant.GoalID,
+			"error":          err,
+		}).Error("Failed to convert reward to proto")
+		return nil, status.Error(codes.Internal, "failed to convert reward data")
+	}
+
+	return &pb.RetryFailedGrantResponse{
+		UserId:        grant.UserID,
+		ChallengeId:   grant.ChallengeID,
+		GoalId:        grant.GoalID,
+		Status:        result.Status,
+		Reward:        reward,
+		ClaimedAt:     mapper.ToProtoTimestamp(&result.ClaimedAt),
+		EntitlementId: result.Grant.EntitlementID,
+		WalletId:      result.Grant.WalletID,
+	}, nil
+}
+
+// ForceCompleteGoal completes a goal on a user's behalf and records the reason
+// in the audit log, optionally claiming the reward through the normal claim flow.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) ForceCompleteGoal(
+	ctx context.Context,
+	req *pb.ForceCompleteGoalRequest,
+) (*pb.ForceCompleteGoalResponse, error) {
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	result, err := service.ForceCompleteGoal(
+		service.WithChallengeMetrics(s.withDefaultDelivery(s.withRepeatableGoals(service.WithRewardCaps(ctx, s.rewardCaps, s.rewardCapRepo)), req.GoalId), s.challengeMetrics),
+		adminID,
+		req.UserId,
+		req.GoalId,
+		s.namespace,
+		service.ForceCompleteOptions{
+			Reason:    req.Reason,
+			Force:     req.Force,
+			AutoClaim: req.AutoClaim,
+			ClientIP:  common.GetClientIPFromContext(ctx),
+		},
+		s.goalCache,
+		s.goalSteps,
+		s.goalAdmin,
+		s.repo,
+		s.claimOutbox,
+		s.rewardClient,
+		s.logger,
+	)
+	s.unclaimedCounts.Invalidate(req.UserId)
+	if err != nil {
+		s.recordFailedGrant(ctx, req.UserId, err)
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	response := &pb.ForceCompleteGoalResponse{
+		UserId:      req.UserId,
+		ChallengeId: result.ChallengeID,
+		GoalId:      req.GoalId,
+		Status:      string(result.Progress.Status),
+		// #nosec G115 - goal targets fit in int32
+		Progress:    int32(result.Progress.Progress),
+		CompletedAt: mapper.ToProtoTimestamp(result.Progress.CompletedAt),
+	}
+
+	if result.Claim != nil {
+		// The admin claim bypasses the claim cap check but still counts toward it
+		s.claimCap.Record(ctx, req.UserId, s.namespace, req.GoalId)
+		s.recordRewardGrant(ctx, result.Claim)
+		s.resolveFailedGrant(ctx, req.UserId, req.GoalId)
+
+		reward, err := claimRewardToProto(result.Claim)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":        adminID,
+				"target_user_id": req.UserId,
+				"goal_id":        req.GoalId,
+				"error":          err,
+			}).Error("Failed to convert reward to proto")
+			return nil, status.Error(codes.Internal, "failed to convert reward data")
+		}
+		response.Status = result.Claim.Status
+		response.Reward = reward
+		response.ClaimedAt = mapper.ToProtoTimestamp(&result.Claim.ClaimedAt)
+		response.EntitlementId = result.Claim.Grant.EntitlementID
+		response.WalletId = result.Claim.Grant.WalletID
+		response.RewardCap = rewardCapOutcomeToProto(result.Claim.RewardCap)
+	}
+
+	return response, nil
+}
+
+// RecomputeUserStatuses re-evaluates a user's goal statuses against the targets
+// in the current config and returns the changes written.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) RecomputeUserStatuses(
+	ctx context.Context,
+	req *pb.RecomputeUserStatusesRequest,
+) (*pb.RecomputeUserStatusesResponse, error) {
+	if s.statusRecomputes == nil {
+		return nil, status.Error(codes.Unimplemented, "status recomputes are not enabled")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	result, err := s.statusRecomputes.Recompute(ctx, service.StatusRecomputeRequest{
+		UserID:      req.UserId,
+		ActorUserID: adminID,
+		Reason:      strings.TrimSpace(req.Reason),
+		Regress:     req.Regress,
+		ClientIP:    common.GetClientIPFromContext(ctx),
+	})
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	response := &pb.RecomputeUserStatusesResponse{
+		UserId:      req.UserId,
+		Transitions: make([]*pb.StatusTransition, 0, len(result.Transitions)),
+		// #nosec G115 - bounded by the user's progress rows
+		Completed: int32(result.Completed),
+		// #nosec G115 - see above
+		Regressed: int32(result.Regressed),
+	}
+	for _, transition := range result.Transitions {
+		response.Transitions = append(response.Transitions, &pb.StatusTransition{
+			GoalId:      transition.GoalID,
+			ChallengeId: transition.ChallengeID,
+			FromStatus:  string(transition.From),
+			ToStatus:    string(transition.To),
+			// #nosec G115 - goal progress fits in int32
+			Progress: int32(transition.Progress),
+		})
+	}
+
+	return response, nil
+}
+
+// GetGoalStats returns per-goal completion counts, served from the goal stats cache.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:STATS permission.
+func (s *ChallengeServiceServer) GetGoalStats(
+	ctx context.Context,
+	req *pb.GetGoalStatsRequest,
+) (*pb.GetGoalStatsResponse, error) {
+	if s.goalStats == nil {
+		return nil, status.Error(codes.Unimplemented, "goal stats are not enabled")
+	}
+
+	snapshot, err := s.goalStats.Get(ctx, req.Refresh)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"namespace": s.namespace,
+			"refresh":   req.Refresh,
+			"error":     err,
+		}).Error("Failed to get goal stats")
+		return nil, status.Error(codes.Internal, "failed to get goal stats")
+	}
+
+	response := &pb.GetGoalStatsResponse{
+		Goals:           make([]*pb.GoalStats, 0, len(snapshot.Goals)),
+		GeneratedAt:     mapper.ToProtoTimestamp(&snapshot.GeneratedAt),
+		CacheTtlSeconds: int64(s.goalStats.TTL().Seconds()),
+	}
+	for _, goal := range snapshot.Goals {
+		stats := &pb.GoalStats{
+			ChallengeId: goal.ChallengeID,
+			GoalId:      goal.GoalID,
+			NotStarted:  int64(goal.NotStarted),
+			InProgress:  int64(goal.InProgress),
+			Completed:   int64(goal.Completed),
+			Claimed:     int64(goal.Claimed),
+			Total:       int64(goal.Total()),
+		}
+		if total := goal.Total(); total > 0 {
+			stats.CompletionRate = float64(goal.Completed+goal.Claimed) / float64(total)
+		}
+		response.Goals = append(response.Goals, stats)
+	}
+
+	return response, nil
+}
+
+// GetServiceState returns the state of this instance for debugging: config,
+// caches, modes, database pool and uptime, but no user data.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:DEBUG permission.
+func (s *ChallengeServiceServer) GetServiceState(
+	ctx context.Context,
+	req *pb.GetServiceStateRequest,
+) (*pb.GetServiceStateResponse, error) {
+	if !s.serviceState.Enabled() {
+		return nil, status.Error(codes.Unimplemented, "service state is not enabled")
+	}
+
+	state := s.serviceState.State()
+	return &pb.GetServiceStateResponse{
+		ConfigVersion:  state.ConfigVersion,
+		ConfigPath:     state.ConfigPath,
+		ChallengeCount: int32(state.Challenges), // #nosec G115 - config sizes fit in int32
+		GoalCount:      int32(state.Goals),      // #nosec G115 - config sizes fit in int32
+		SerializedCache: &pb.SerializedCacheState{
+			ChallengeFragments: int32(state.Cache.ChallengeFragments), // #nosec G115 - config sizes fit in int32
+			GoalFragments:      int32(state.Cache.GoalFragments),      // #nosec G115 - config sizes fit in int32
+			ListedGoals:        int32(state.Cache.ListedGoals),        // #nosec G115 - config sizes fit in int32
+			TotalBytes:         int64(state.Cache.TotalBytes),
+		},
+		RewardClientMode: state.RewardClientMode,
+		AuthEnabled:      state.AuthEnabled,
+		DbPool: &pb.DatabasePoolState{
+			MaxOpenConnections: int32(state.DB.MaxOpenConnections), // #nosec G115 - pool sizes fit in int32
+			OpenConnections:    int32(state.DB.OpenConnections),    // #nosec G115 - pool sizes fit in int32
+			InUse:              int32(state.DB.InUse),              // #nosec G115 - pool sizes fit in int32
+			Idle:               int32(state.DB.Idle),               // #nosec G115 - pool sizes fit in int32
+			WaitCount:          state.DB.WaitCount,
+			WaitDurationMs:     state.DB.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      state.DB.MaxIdleClosed,
+			MaxIdleTimeClosed:  state.DB.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  state.DB.MaxLifetimeClosed,
+		},
+		StartedAt:     mapper.ToProtoTimestamp(&state.StartedAt),
+		UptimeSeconds: int64(state.Uptime.Seconds()),
+	}, nil
+}
+
+// GetSelectionHistory lists a user's goal selections, newest first.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) GetSelectionHistory(
+	ctx context.Context,
+	req *pb.GetSelectionHistoryRequest,
+) (*pb.GetSelectionHistoryResponse, error) {
+	if s.goalSelections == nil {
+		return nil, status.Error(codes.Unimplemented, "selection history is not configured")
+	}
+
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	history, err := service.GetSelectionHistory(ctx, req.UserId, s.namespace, int(req.Limit), s.goalSelections, s.logger)
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	response := &pb.GetSelectionHistoryResponse{
+		Selections: make([]*pb.GoalSelectionRecord, 0, len(history.Selections)),
+		Truncated:  history.Truncated,
+	}
+	for _, selection := range history.Selections {
+		response.Selections = append(response.Selections, &pb.GoalSelectionRecord{
+			ChallengeId:     selection.ChallengeID,
+			SelectedGoalIds: selection.SelectedGoalIDs,
+			ReplacedGoalIds: selection.ReplacedGoalIDs,
+			Source:          selection.Source,
+			SelectedAt:      mapper.ToProtoTimestamp(&selection.CreatedAt),
+			ClientContext:   selection.ClientContext,
+		})
+	}
+
+	return response, nil
+}
+
+// GetUserRollouts lists the challenges with a rollout percentage, with the
+// user's bucket for each and whether the user is eligible.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) GetUserRollouts(
+	ctx context.Context,
+	req *pb.GetUserRolloutsRequest,
+) (*pb.GetUserRolloutsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	statuses := s.rollouts.UserStatus(req.UserId, s.goalCache.GetAllChallenges())
+	response := &pb.GetUserRolloutsResponse{
+		UserId:     req.UserId,
+		Challenges: make([]*pb.ChallengeRollout, 0, len(statuses)),
+	}
+	for _, rollout := range statuses {
+		response.Challenges = append(response.Challenges, &pb.ChallengeRollout{
+			ChallengeId: rollout.ChallengeID,
+			// #nosec G115 - percentages are 0 to 100, buckets 0 to 99
+			RolloutPercentage: int32(rollout.Percentage),
+			// #nosec G115 - percentages are 0 to 100, buckets 0 to 99
+			Bucket:   int32(rollout.Bucket),
+			Eligible: rollout.Available,
+		})
+	}
+
+	return response, nil
+}
+
+// GetSelectionStats counts, per goal, how often it was selected and replaced.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:STATS permission.
+func (s *ChallengeServiceServer) GetSelectionStats(
+	ctx context.Context,
+	req *pb.GetSelectionStatsRequest,
+) (*pb.GetSelectionStatsResponse, error) {
+	if s.goalSelections == nil {
+		return nil, status.Error(codes.Unimplemented, "selection history is not configured")
+	}
+
+	since := time.Now().UTC().Add(-service.DefaultSelectionStatsWindow)
+	if req.Since != nil {
+		since = req.Since.AsTime()
+	}
+
+	stats, err := service.GetSelectionStats(ctx, s.namespace, req.ChallengeId, since, s.goalSelections, s.logger)
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	response := &pb.GetSelectionStatsResponse{
+		Goals: make([]*pb.GoalSelectionStats, 0, len(stats)),
+		Since: mapper.ToProtoTimestamp(&since),
+	}
+	for _, count := range stats {
+		response.Goals = append(response.Goals, &pb.GoalSelectionStats{
+			ChallengeId: count.ChallengeID,
+			GoalId:      count.GoalID,
+			Selected:    count.Selected,
+			Replaced:    count.Replaced,
+		})
+	}
+
+	return response, nil
+}
+
+// GetChallengeMismatches lists progress rows stored under a different challenge
+// than their goal in the config.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) GetChallengeMismatches(
+	ctx context.Context,
+	req *pb.GetChallengeMismatchesRequest,
+) (*pb.GetChallengeMismatchesResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	report, err := service.GetChallengeMismatches(ctx, s.namespace, int(req.Limit), s.goalCache, s.progressQueries, s.logger)
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	response := &pb.GetChallengeMismatchesResponse{
+		Mismatches: make([]*pb.ChallengeMismatch, 0, len(report.Mismatches)),
+		Truncated:  report.Truncated,
+	}
+	for _, mismatch := range report.Mismatches {
+		response.Mismatches = append(response.Mismatches, &pb.ChallengeMismatch{
+			UserId:            mismatch.UserID,
+			GoalId:            mismatch.GoalID,
+			StoredChallengeId: mismatch.StoredChallengeID,
+			ChallengeId:       mismatch.ChallengeID,
+			Status:            string(mismatch.Status),
+			UpdatedAt:         mapper.ToProtoTimestamp(&mismatch.UpdatedAt),
+		})
+	}
+
+	return response, nil
+}
+
+// FixChallengeMismatches rewrites one batch of mismatched progress rows to their
+// goal's challenge in the config and records each change in the audit log.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) FixChallengeMismatches(
+	ctx context.Context,
+	req *pb.FixChallengeMismatchesRequest,
+) (*pb.FixChallengeMismatchesResponse, error) {
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	result, err := service.FixChallengeMismatches(
+		ctx,
+		adminID,
+		s.namespace,
+		service.ChallengeMismatchFixOptions{
+			Reason:   req.Reason,
+			Limit:    int(req.Limit),
+			ClientIP: common.GetClientIPFromContext(ctx),
+		},
+		s.goalCache,
+		s.goalAdmin,
+		s.logger,
+	)
+	if err != nil {
+		return nil, mapper.MapErrorToGRPCStatus(err)
+	}
+
+	return &pb.FixChallengeMismatchesResponse{
+		// #nosec G115 - bounded by MaxChallengeMismatchFixLimit
+		Fixed: int32(result.Fixed),
+		More:  result.More,
+	}, nil
+}
+
+// BackfillDefaultGoal starts creating a default goal's progress rows for the
+// users who have progress but not the goal's row. The rows are created in the
+// background; GetBackfillJob reports how far the job got.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) BackfillDefaultGoal(
+	ctx context.Context,
+	req *pb.BackfillDefaultGoalRequest,
+) (*pb.BackfillJob, error) {
+	if s.backfills == nil {
+		return nil, status.Error(codes.Unimplemented, "default goal backfills are not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	job, err := s.backfills.Start(ctx, req.GoalId, adminID, req.Restart)
+	if err != nil {
+		var configInvalid *mapper.ConfigInvalidError
+		switch {
+		case stdErrors.Is(err, service.ErrBackfillGoalNotFound):
+			return nil, status.Errorf(codes.NotFound, "goal %s not found", req.GoalId)
+		case stdErrors.Is(err, service.ErrBackfillGoalNotDefault):
+			return nil, status.Errorf(codes.FailedPrecondition, "goal %s is not default-assigned", req.GoalId)
+		case stdErrors.As(err, &configInvalid):
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"user_id":   adminID,
+			"goal_id":   req.GoalId,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to start default goal backfill")
+		return nil, status.Error(codes.Internal, "failed to start backfill")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":   adminID,
+		"goal_id":   req.GoalId,
+		"namespace": s.namespace,
+		"restart":   req.Restart,
+		"status":    job.Status,
+	}).Info("Admin started default goal backfill")
+
+	return backfillJobToProto(job), nil
+}
+
+// GetBackfillJob returns the status of a goal's backfill job.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) GetBackfillJob(
+	ctx context.Context,
+	req *pb.GetBackfillJobRequest,
+) (*pb.BackfillJob, error) {
+	if s.backfills == nil {
+		return nil, status.Error(codes.Unimplemented, "default goal backfills are not configured")
+	}
+
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	job, err := s.backfills.Get(ctx, req.GoalId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"goal_id":   req.GoalId,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to get backfill job")
+		return nil, status.Error(codes.Internal, "failed to get backfill job")
+	}
+	if job == nil {
+		return nil, status.Errorf(codes.NotFound, "no backfill job for goal %s", req.GoalId)
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"challenge_id": req.ChallengeId,
-		"selected":     len(protoSelectedGoals),
-		"total_active": result.TotalActiveGoals,
-	}).Info("Successfully batch selected goals")
+	return backfillJobToProto(job), nil
+}
 
-	return &pb.GoalSelectionResponse{
-		SelectedGoals: protoSelectedGoals,
-		ChallengeId:   result.ChallengeID,
-		// #nosec G115 - TotalActiveGoals will never exceed int32 max (limited by goal count)
-		TotalActiveGoals: int32(result.TotalActiveGoals),
-		ReplacedGoals:    result.ReplacedGoals,
-	}, nil
+func backfillJobToProto(job *serviceRepo.BackfillJob) *pb.BackfillJob {
+	return &pb.BackfillJob{
+		GoalId:       job.GoalID,
+		Status:       job.Status,
+		LastUserId:   job.LastUserID,
+		UsersScanned: job.UsersScanned,
+		UsersSkipped: job.UsersSkipped,
+		RowsInserted: job.RowsInserted,
+		Error:        job.Error,
+		ActorUserId:  job.ActorUserID,
+		StartedAt:    mapper.ToProtoTimestamp(&job.StartedAt),
+		UpdatedAt:    mapper.ToProtoTimestamp(&job.UpdatedAt),
+		CompletedAt:  mapper.ToProtoTimestamp(job.CompletedAt),
+	}
 }
 
-// RandomSelectGoals randomly selects N goals from a challenge and activates them (M4)
-func (s *ChallengeServiceServer) RandomSelectGoals(
+// BulkActivateGoal starts activating a goal for a list of users, or resumes a
+// failed job. The goal is activated in the background; GetBulkActivationJob
+// reports how far the job got. A dry run returns the counts right away.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) BulkActivateGoal(
 	ctx context.Context,
-	req *pb.RandomSelectRequest,
-) (*pb.GoalSelectionResponse, error) {
-	// Extract user ID from JWT token (already validated by interceptor)
-	userID, err := extractUserIDFromContext(ctx)
+	req *pb.BulkActivateGoalRequest,
+) (*pb.BulkActivationJob, error) {
+	if s.bulkActivations == nil {
+		return nil, status.Error(codes.Unimplemented, "bulk goal activations are not configured")
+	}
+
+	adminID, err := extractUserIDFromContext(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to extract user ID from context")
 		return nil, err
 	}
 
-	// Validate request
-	if req.ChallengeId == "" {
-		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	// Segments are resolved from each player's own request (common.SegmentResolver);
+	// nothing lists the players of one
+	if req.Segment != "" {
+		return nil, status.Error(codes.InvalidArgument,
+			"segment is not supported: segments are resolved per player request, so their players cannot be listed; pass them as user_ids")
 	}
 
-	if req.Count <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "count must be greater than 0")
-	}
+	ctx = s.withRepeatableGoals(ctx)
+	clientIP := common.GetClientIPFromContext(ctx)
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":          userID,
-		"challenge_id":     req.ChallengeId,
-		"count":            req.Count,
-		"replace_existing": req.ReplaceExisting,
-		"exclude_active":   req.ExcludeActive,
-		"namespace":        s.namespace,
-	}).Info("Random selecting goals")
+	var job *serviceRepo.BulkActivationJob
+	if req.JobId != 0 {
+		if len(req.UserIds) > 0 || req.DryRun {
+			return nil, status.Error(codes.InvalidArgument, "user_ids and dry_run cannot be set when resuming a job")
+		}
+		job, err = s.bulkActivations.Resume(ctx, req.JobId, adminID, clientIP)
+	} else {
+		if req.GoalId == "" {
+			return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+		}
+		if req.Reason == "" {
+			return nil, status.Error(codes.InvalidArgument, "reason is required")
+		}
+		if len(req.UserIds) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+		}
+		if len(req.UserIds) > service.MaxBulkActivationUsers {
+			return nil, status.Errorf(codes.InvalidArgument, "at most %d user_ids are allowed", service.MaxBulkActivationUsers)
+		}
+		for _, userID := range req.UserIds {
+			if userID == "" {
+				return nil, status.Error(codes.InvalidArgument, "user_ids must not contain empty IDs")
+			}
+		}
 
-	// Call business logic
-	result, err := service.RandomSelectGoals(
-		ctx,
-		userID,
-		req.ChallengeId,
-		// #nosec G115 - Count is validated to be > 0, safe to convert
-		int(req.Count),
-		req.ReplaceExisting,
-		req.ExcludeActive,
-		s.namespace,
-		s.goalCache,
-		s.repo,
-	)
+		job, err = s.bulkActivations.Start(ctx, service.BulkActivationRequest{
+			GoalID:      req.GoalId,
+			UserIDs:     req.UserIds,
+			Reason:      req.Reason,
+			ActorUserID: adminID,
+			ClientIP:    clientIP,
+			DryRun:      req.DryRun,
+		})
+	}
 	if err != nil {
+		var configInvalid *mapper.ConfigInvalidError
+		switch {
+		case stdErrors.Is(err, service.ErrBulkActivationGoalNotFound):
+			return nil, status.Errorf(codes.NotFound, "goal %s not found", req.GoalId)
+		case stdErrors.Is(err, service.ErrBulkActivationJobNotFound):
+			return nil, status.Errorf(codes.NotFound, "no bulk activation job %d", req.JobId)
+		case stdErrors.Is(err, service.ErrBulkActivationGoalRepeatable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case stdErrors.As(err, &configInvalid):
+			return nil, mapper.MapErrorToGRPCStatus(err)
+		}
 		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": req.ChallengeId,
-			"count":        req.Count,
-			"error":        err,
-		}).Error("Failed to random select goals")
+			"user_id":   adminID,
+			"goal_id":   req.GoalId,
+			"job_id":    req.JobId,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to start bulk goal activation")
+		return nil, status.Error(codes.Internal, "failed to start bulk activation")
+	}
 
-		// Check for insufficient goals error (M4: zero goals available)
-		var challengeErr *errors.ChallengeError
-		if stdErrors.As(err, &challengeErr) && challengeErr.Code == errors.ErrCodeInsufficientGoals {
-			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
-		}
+	logrus.WithFields(logrus.Fields{
+		"user_id":     adminID,
+		"goal_id":     job.GoalID,
+		"job_id":      job.ID,
+		"namespace":   s.namespace,
+		"dry_run":     req.DryRun,
+		"users_total": job.UsersTotal,
+		"status":      job.Status,
+	}).Info("Admin started bulk goal activation")
+
+	resp := bulkActivationJobToProto(job)
+	resp.DryRun = req.DryRun
+	return resp, nil
+}
 
-		// Return 404 Not Found for resource not found errors
-		if strings.Contains(err.Error(), "not found") {
-			return nil, status.Errorf(codes.NotFound, "%v", err)
-		}
+// GetBulkActivationJob returns the status of a bulk goal activation.
+// Access is restricted by the ADMIN:NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission.
+func (s *ChallengeServiceServer) GetBulkActivationJob(
+	ctx context.Context,
+	req *pb.GetBulkActivationJobRequest,
+) (*pb.BulkActivationJob, error) {
+	if s.bulkActivations == nil {
+		return nil, status.Error(codes.Unimplemented, "bulk goal activations are not configured")
+	}
 
-		return nil, status.Errorf(codes.Internal, "failed to random select goals: %v", err)
+	if req.JobId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
 	}
 
-	// Convert to protobuf response
-	protoSelectedGoals := make([]*pb.SelectedGoal, 0, len(result.SelectedGoals))
-	for _, selectedGoal := range result.SelectedGoals {
-		protoGoal, err := selectedGoalToProto(selectedGoal)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"goal_id": selectedGoal.GoalID,
-				"error":   err,
-			}).Error("Failed to convert selected goal to proto")
-			continue
-		}
-		protoSelectedGoals = append(protoSelectedGoals, protoGoal)
+	job, err := s.bulkActivations.Get(ctx, req.JobId)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"job_id":    req.JobId,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to get bulk activation job")
+		return nil, status.Error(codes.Internal, "failed to get bulk activation job")
+	}
+	if job == nil {
+		return nil, status.Errorf(codes.NotFound, "no bulk activation job %d", req.JobId)
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"challenge_id": req.ChallengeId,
-		"selected":     len(protoSelectedGoals),
-		"total_active": result.TotalActiveGoals,
-	}).Info("Successfully random selected goals")
+	return bulkActivationJobToProto(job), nil
+}
 
-	return &pb.GoalSelectionResponse{
-		SelectedGoals: protoSelectedGoals,
-		ChallengeId:   result.ChallengeID,
-		// #nosec G115 - TotalActiveGoals will never exceed int32 max (limited by goal count)
-		TotalActiveGoals: int32(result.TotalActiveGoals),
-		ReplacedGoals:    result.ReplacedGoals,
-	}, nil
+func bulkActivationJobToProto(job *serviceRepo.BulkActivationJob) *pb.BulkActivationJob {
+	return &pb.BulkActivationJob{
+		JobId:              job.ID,
+		GoalId:             job.GoalID,
+		Status:             job.Status,
+		UsersTotal:         job.UsersTotal,
+		UsersProcessed:     job.UsersProcessed,
+		UsersActivated:     job.Activated,
+		UsersAlreadyActive: job.AlreadyActive,
+		UsersClaimed:       job.Claimed,
+		Reason:             job.Reason,
+		Error:              job.Error,
+		ActorUserId:        job.ActorUserID,
+		StartedAt:          mapper.ToProtoTimestamp(&job.StartedAt),
+		UpdatedAt:          mapper.ToProtoTimestamp(&job.UpdatedAt),
+		CompletedAt:        mapper.ToProtoTimestamp(job.CompletedAt),
+	}
 }
 
-// ClaimGoalReward claims reward for a completed goal
-func (s *ChallengeServiceServer) ClaimGoalReward(
+// BatchReportProgress applies stat updates for many players at once.
+// Access is restricted by the NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission,
+// which is meant for game server clients.
+func (s *ChallengeServiceServer) BatchReportProgress(
 	ctx context.Context,
-	req *pb.ClaimRewardRequest,
-) (*pb.ClaimRewardResponse, error) {
-	// Extract user ID from JWT token (already validated by interceptor)
-	userID, err := extractUserIDFromContext(ctx)
+	req *pb.BatchReportProgressRequest,
+) (*pb.BatchReportProgressResponse, error) {
+	clientID, err := extractUserIDFromContext(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to extract user ID from context")
 		return nil, err
 	}
 
-	// Validate request
-	if req.ChallengeId == "" {
-		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	if req.Namespace != s.namespace {
+		logrus.WithFields(logrus.Fields{
+			"user_id":           clientID,
+			"namespace":         s.namespace,
+			"request_namespace": req.Namespace,
+		}).Warn("Batch progress rejected: namespace mismatch")
+		return nil, status.Errorf(codes.PermissionDenied, "namespace %q is not served by this service", req.Namespace)
 	}
 
-	if req.GoalId == "" {
-		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	if len(req.Events) > s.batchProgress.MaxEvents {
+		return nil, status.Errorf(codes.InvalidArgument, "too many events: %d (max %d)", len(req.Events), s.batchProgress.MaxEvents)
+	}
+
+	events := make([]service.ProgressEvent, len(req.Events))
+	for i, event := range req.Events {
+		events[i] = service.ProgressEvent{UserID: event.UserId, StatCode: event.StatCode}
+		if event.OccurredAt != nil {
+			events[i].OccurredAt = event.OccurredAt.AsTime()
+		}
+		switch update := event.Update.(type) {
+		case *pb.ProgressEvent_Delta:
+			delta := int(update.Delta)
+			events[i].Delta = &delta
+		case *pb.ProgressEvent_Value:
+			value := int(update.Value)
+			events[i].Value = &value
+		}
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"goal_id":      req.GoalId,
-		"challenge_id": req.ChallengeId,
-		"namespace":    s.namespace,
-	}).Info("Claiming goal reward")
+		"user_id":   clientID,
+		"namespace": s.namespace,
+		"events":    len(events),
+	}).Info("Batch reporting progress")
 
-	// Call claim service
-	result, err := service.ClaimGoalReward(
-		ctx,
-		userID,
-		req.GoalId,
-		req.ChallengeId,
+	result, err := service.BatchReportProgress(
+		service.WithChallengeMetrics(ctx, s.challengeMetrics),
 		s.namespace,
+		events,
 		s.goalCache,
 		s.repo,
-		s.rewardClient,
+		s.progressQueries,
+		s.inactiveProgress,
+		s.lateProgress,
+		s.stepProgress,
+		s.inactivePolicy,
+		s.latePolicies,
+		s.goalSteps,
+		s.batchProgress,
+		s.logger,
 	)
-	if err != nil {
-		// Map domain errors to gRPC status codes
-		return nil, mapper.MapErrorToGRPCStatus(err)
+	for _, event := range events {
+		s.unclaimedCounts.Invalidate(event.UserID)
 	}
-
-	// Convert reward to proto
-	protoReward, err := mapper.RewardToProto(&result.Reward)
 	if err != nil {
+		if stdErrors.Is(err, service.ErrProgressBatchTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      req.GoalId,
-			"challenge_id": req.ChallengeId,
-			"error":        err,
-		}).Error("Failed to convert reward to proto")
-		return nil, status.Error(codes.Internal, "failed to convert reward data")
+			"user_id":   clientID,
+			"namespace": s.namespace,
+			"error":     err,
+		}).Error("Failed to batch report progress")
+		return nil, status.Error(codes.Internal, "failed to report progress")
 	}
 
-	// NOTE: Cannot return objects to pool here - gRPC serializes AFTER handler returns
-	// This would cause a race condition. Pooling only helps if done at a different layer.
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"goal_id":      req.GoalId,
-		"challenge_id": req.ChallengeId,
-		"reward_type":  result.Reward.Type,
-		"reward_id":    result.Reward.RewardID,
-	}).Info("Successfully claimed goal reward")
+	results := make([]*pb.ProgressEventResult, len(result.Results))
+	for i, r := range result.Results {
+		skipped := make([]*pb.SkippedGoal, 0, len(r.SkippedGoals))
+		for _, goal := range r.SkippedGoals {
+			skipped = append(skipped, &pb.SkippedGoal{GoalId: goal.GoalID, Reason: goal.Reason})
+		}
+		results[i] = &pb.ProgressEventResult{
+			// #nosec G115 - bounded by MaxEvents
+			Index:          int32(i),
+			UserId:         r.UserID,
+			StatCode:       r.StatCode,
+			Status:         r.Status,
+			AppliedGoalIds: r.AppliedGoalIDs,
+			SkippedGoals:   skipped,
+			FailedGoalIds:  r.FailedGoalIDs,
+			Error:          r.Error,
+		}
+	}
 
-	return &pb.ClaimRewardResponse{
-		GoalId:    result.GoalID,
-		Status:    result.Status,
-		Reward:    protoReward,
-		ClaimedAt: result.ClaimedAt.Format(time.RFC3339),
+	return &pb.BatchReportProgressResponse{
+		Results: results,
+		// #nosec G115 - bounded by MaxEvents times goals per stat code
+		RowsWritten: int32(result.RowsWritten),
 	}, nil
 }
 
-// GetRotationStatus returns rotation schedule and current period info for a challenge (M5)
-func (s *ChallengeServiceServer) GetRotationStatus(
+// ReportMatchResult applies one player's match result to the match goals whose
+// filter it matches.
+// Access is restricted by the NAMESPACE:{namespace}:CHALLENGE:PROGRESS permission,
+// which is meant for game server clients.
+func (s *ChallengeServiceServer) ReportMatchResult(
 	ctx context.Context,
-	req *pb.GetRotationStatusRequest,
-) (*pb.GetRotationStatusResponse, error) {
-	if req.ChallengeId == "" {
-		return nil, status.Error(codes.InvalidArgument, "challenge_id is required")
+	req *pb.ReportMatchResultRequest,
+) (*pb.ReportMatchResultResponse, error) {
+	clientID, err := extractUserIDFromContext(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to extract user ID from context")
+		return nil, err
 	}
 
-	// Look up challenge in cache
-	challenge := s.goalCache.GetChallengeByChallengeID(req.ChallengeId)
-	if challenge == nil {
-		return nil, status.Errorf(codes.NotFound, "challenge not found: %s", req.ChallengeId)
+	event := service.MatchEvent{
+		UserID:    req.UserId,
+		Mode:      req.Mode,
+		Result:    req.Result,
+		Placement: int(req.Placement),
+		Kills:     int(req.Kills),
+	}
+	if len(req.Stats) > 0 {
+		event.Stats = make(map[string]int, len(req.Stats))
+		for key, value := range req.Stats {
+			event.Stats[key] = int(value)
+		}
+	}
+	if req.OccurredAt != nil {
+		event.OccurredAt = req.OccurredAt.AsTime()
 	}
 
-	// Find the first goal with rotation enabled (all goals in a challenge share the same rotation config)
-	var rotationCfg *commonDomain.RotationConfig
-	for _, goal := range challenge.Goals {
-		if goal.Rotation != nil && goal.Rotation.Enabled {
-			rotationCfg = goal.Rotation
-			break
+	result, err := service.ReportMatchResult(
+		service.WithChallengeMetrics(ctx, s.challengeMetrics),
+		s.namespace,
+		event,
+		s.matchGoals,
+		s.goalCache,
+		s.repo,
+		s.progressQueries,
+		s.inactiveProgress,
+		s.lateProgress,
+		s.inactivePolicy,
+		s.latePolicies,
+		s.batchProgress,
+		s.logger,
+	)
+	if err != nil {
+		if stdErrors.Is(err, service.ErrInvalidMatchEvent) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
+		logrus.WithFields(logrus.Fields{
+			"user_id":        clientID,
+			"target_user_id": req.UserId,
+			"namespace":      s.namespace,
+			"error":          err,
+		}).Error("Failed to report match result")
+		return nil, status.Error(codes.Internal, "failed to report match result")
 	}
+	s.unclaimedCounts.Invalidate(req.UserId)
 
-	// Build rotation info
-	now := time.Now().UTC()
-	rotationInfo := buildRotationInfo(rotationCfg, now)
+	logrus.WithFields(logrus.Fields{
+		"user_id":        clientID,
+		"target_user_id": req.UserId,
+		"event_source":   service.EventSourceMatch,
+		"mode":           req.Mode,
+		"result":         req.Result,
+		"status":         result.Status,
+		"applied":        len(result.AppliedGoalIDs),
+	}).Info("Match result reported")
+
+	skipped := make([]*pb.SkippedGoal, 0, len(result.SkippedGoals))
+	for _, goal := range result.SkippedGoals {
+		skipped = append(skipped, &pb.SkippedGoal{GoalId: goal.GoalID, Reason: goal.Reason})
+	}
 
-	return &pb.GetRotationStatusResponse{
-		ChallengeId: req.ChallengeId,
-		Rotation:    rotationInfo,
+	return &pb.ReportMatchResultResponse{
+		UserId:         result.UserID,
+		Status:         result.Status,
+		AppliedGoalIds: result.AppliedGoalIDs,
+		SkippedGoals:   skipped,
+		FailedGoalIds:  result.FailedGoalIDs,
+		Error:          result.Error,
 	}, nil
 }
 
-// buildRotationInfo constructs a RotationInfo proto from config and current time.
-func buildRotationInfo(cfg *commonDomain.RotationConfig, now time.Time) *pb.RotationInfo {
-	if cfg == nil {
-		return &pb.RotationInfo{Enabled: false}
+// configDiffToProto converts a service.ConfigDiff to its protobuf form.
+func configDiffToProto(diff *service.ConfigDiff) *pb.ConfigDiff {
+	fieldsToProto := func(fields []service.FieldChange) []*pb.FieldChange {
+		result := make([]*pb.FieldChange, 0, len(fields))
+		for _, field := range fields {
+			result = append(result, &pb.FieldChange{Field: field.Field, OldValue: field.Old, NewValue: field.New})
+		}
+		return result
 	}
 
-	info := &pb.RotationInfo{
-		Enabled:  true,
-		Type:     string(cfg.Type),
-		Schedule: string(cfg.Schedule),
+	result := &pb.ConfigDiff{
+		ChallengesAdded:   diff.ChallengesAdded,
+		ChallengesRemoved: diff.ChallengesRemoved,
+		GoalsAdded:        diff.GoalsAdded,
+		GoalsRemoved:      diff.GoalsRemoved,
+		Warnings:          diff.Warnings,
 	}
+	for _, change := range diff.ChallengesModified {
+		result.ChallengesModified = append(result.ChallengesModified, &pb.ChallengeChange{
+			ChallengeId: change.ChallengeID,
+			Fields:      fieldsToProto(change.Fields),
+		})
+	}
+	for _, change := range diff.GoalsModified {
+		result.GoalsModified = append(result.GoalsModified, &pb.GoalChange{
+			GoalId:      change.GoalID,
+			ChallengeId: change.ChallengeID,
+			Fields:      fieldsToProto(change.Fields),
+			// #nosec G115 - player counts per goal fit in int32
+			UnclaimedCompleted: int32(change.UnclaimedCompleted),
+		})
+	}
+	return result
+}
 
-	currentStart := rotation.CalculateLastRotationBoundary(cfg.Schedule, now)
-	currentEnd := rotation.CalculateNextRotationBoundary(cfg.Schedule, now)
-	if currentStart.IsZero() || currentEnd.IsZero() {
-		return info
+// HealthCheck verifies the health of the database, the goal cache and any
+// registered HealthComponent. Every component is checked and reported; the call
+// fails with Unavailable (carrying the full response as a status detail) if any
+// critical component is unhealthy.
+func (s *ChallengeServiceServer) HealthCheck(
+	ctx context.Context,
+	req *pb.HealthCheckRequest,
+) (*pb.HealthCheckResponse, error) {
+	components := []HealthComponent{
+		{Name: "database", Critical: true, Check: s.checkDatabase},
+		{Name: "goal_cache", Critical: true, Check: s.checkGoalCache},
 	}
+	components = append(components, s.healthComponents...)
 
-	currentExpiresIn := int32(currentEnd.Sub(now).Seconds())
-	if currentExpiresIn < 0 {
-		currentExpiresIn = 0
+	resp := &pb.HealthCheckResponse{
+		Status:     "healthy",
+		Version:    version.Version,
+		Components: make([]*pb.ComponentHealth, 0, len(components)),
 	}
-	info.CurrentPeriod = &pb.RotationPeriod{
-		StartTime:        currentStart.Format(time.RFC3339),
-		EndTime:          currentEnd.Format(time.RFC3339),
-		ExpiresInSeconds: currentExpiresIn,
+
+	var criticalFailures []string
+	for _, component := range components {
+		result := &pb.ComponentHealth{
+			Name:     component.Name,
+			Status:   "healthy",
+			Critical: component.Critical,
+		}
+
+		if err := component.Check(ctx); err != nil {
+			logrus.WithError(err).WithField("component", component.Name).Warn("Health check component unhealthy")
+			result.Status = "unhealthy"
+			result.Message = err.Error()
+
+			if component.Critical {
+				criticalFailures = append(criticalFailures, err.Error())
+			} else {
+				resp.Status = "degraded"
+			}
+		}
+
+		resp.Components = append(resp.Components, result)
 	}
 
-	// Next period (starts when current ends)
-	nextEnd := rotation.CalculateNextRotationBoundary(cfg.Schedule, currentEnd)
-	if !nextEnd.IsZero() {
-		info.NextPeriod = &pb.RotationPeriod{
-			StartTime: currentEnd.Format(time.RFC3339),
-			EndTime:   nextEnd.Format(time.RFC3339),
+	if len(criticalFailures) > 0 {
+		resp.Status = "unhealthy"
+		st := status.New(codes.Unavailable, "health check failed: "+strings.Join(criticalFailures, "; "))
+		if detailed, err := st.WithDetails(resp); err == nil {
+			st = detailed
 		}
+		return nil, st.Err()
 	}
 
-	return info
+	return resp, nil
 }
 
-// HealthCheck verifies service and database health
-func (s *ChallengeServiceServer) HealthCheck(
-	ctx context.Context,
-	req *pb.HealthCheckRequest,
-) (*pb.HealthCheckResponse, error) {
-	// Check database connectivity with 2-second timeout
+// checkDatabase pings the database with a 2-second timeout.
+func (s *ChallengeServiceServer) checkDatabase(ctx context.Context) error {
 	healthCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	if err := s.db.PingContext(healthCtx); err != nil {
 		logrus.WithError(err).Error("Database health check failed")
-		return nil, status.Error(codes.Unavailable, "database connectivity check failed")
+		return stdErrors.New("database connectivity check failed")
 	}
 
-	return &pb.HealthCheckResponse{
-		Status: "healthy",
-	}, nil
+	return nil
+}
+
+// checkGoalCache fails when no challenges are loaded, e.g. after a bad config reload.
+func (s *ChallengeServiceServer) checkGoalCache(ctx context.Context) error {
+	if len(s.goalCache.GetAllChallenges()) == 0 {
+		return stdErrors.New("goal cache has no challenges loaded")
+	}
+
+	return nil
 }
 
 // assignedGoalToProto converts a service.AssignedGoal to a protobuf AssignedGoal message.
@@ -652,19 +3678,14 @@ func assignedGoalToProto(goal *service.AssignedGoal) (*pb.AssignedGoal, error) {
 		// #nosec G115 - Progress values are bounded by config target values (safe to convert)
 		Progress: int32(goal.Progress),
 		// #nosec G115 - Target values are validated at config load time (safe to convert)
-		Target: int32(goal.Target),
-		Status: goal.Status,
+		Target:           int32(goal.Target),
+		Status:           goal.Status,
+		ActivationSource: goal.ActivationSource,
 	}
 
-	// Convert assigned_at timestamp (nullable)
-	if goal.AssignedAt != nil {
-		protoGoal.AssignedAt = goal.AssignedAt.UTC().Format(time.RFC3339)
-	}
-
-	// Convert expires_at timestamp (nullable, NULL in M3)
-	if goal.ExpiresAt != nil {
-		protoGoal.ExpiresAt = goal.ExpiresAt.UTC().Format(time.RFC3339)
-	}
+	// Convert nullable timestamps
+	protoGoal.AssignedAt = mapper.ToProtoTimestamp(goal.AssignedAt)
+	protoGoal.ExpiresAt = mapper.ToProtoTimestamp(goal.ExpiresAt)
 
 	// Convert requirement
 	protoRequirement, err := mapper.RequirementToProto(&goal.Requirement)
@@ -705,19 +3726,15 @@ func selectedGoalToProto(goal *service.SelectedGoalInfo) (*pb.SelectedGoal, erro
 		// #nosec G115 - Progress values are bounded by config target values (safe to convert)
 		Progress: int32(goal.Progress),
 		// #nosec G115 - Target values are validated at config load time (safe to convert)
-		Target:   int32(goal.Target),
-		IsActive: goal.IsActive,
+		Target:           int32(goal.Target),
+		IsActive:         goal.IsActive,
+		ActivationSource: goal.ActivationSource,
+		Changed:          goal.Changed,
 	}
 
-	// Convert assigned_at timestamp (nullable)
-	if goal.AssignedAt != nil {
-		protoGoal.AssignedAt = goal.AssignedAt.UTC().Format(time.RFC3339)
-	}
-
-	// Convert expires_at timestamp (nullable, NULL in M4)
-	if goal.ExpiresAt != nil {
-		protoGoal.ExpiresAt = goal.ExpiresAt.UTC().Format(time.RFC3339)
-	}
+	// Convert nullable timestamps
+	protoGoal.AssignedAt = mapper.ToProtoTimestamp(goal.AssignedAt)
+	protoGoal.ExpiresAt = mapper.ToProtoTimestamp(goal.ExpiresAt)
 
 	// Convert requirement
 	protoRequirement, err := mapper.RequirementToProto(&goal.Requirement)
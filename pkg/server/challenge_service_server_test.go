@@ -7,310 +7,36 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	agsClient "extend-challenge-service/pkg/client"
 	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/common/version"
 	pb "extend-challenge-service/pkg/pb"
+	serviceRepo "extend-challenge-service/pkg/repository"
 	"extend-challenge-service/pkg/service"
+	"extend-challenge-service/pkg/testutil"
+	"extend-challenge-service/pkg/testutil/mocks"
 
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/AccelByte/extend-challenge-common/pkg/repository"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// Mock implementations
-type MockGoalCache struct {
-	mock.Mock
-}
-
-func (m *MockGoalCache) GetGoalByID(goalID string) *domain.Goal {
-	args := m.Called(goalID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*domain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsByStatCode(statCode string) []*domain.Goal {
-	args := m.Called(statCode)
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) GetAllChallenges() []*domain.Challenge {
-	args := m.Called()
-	return args.Get(0).([]*domain.Challenge)
-}
-
-func (m *MockGoalCache) GetAllGoals() []*domain.Goal {
-	args := m.Called()
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsWithDefaultAssigned() []*domain.Goal {
-	args := m.Called()
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) GetChallengeByChallengeID(challengeID string) *domain.Challenge {
-	args := m.Called(challengeID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*domain.Challenge)
-}
-
-func (m *MockGoalCache) Reload() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-type MockGoalRepository struct {
-	mock.Mock
-}
-
-func (m *MockGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, challengeID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
-	args := m.Called(ctx, userID, goalID)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgress(ctx context.Context, progressList []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progressList)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []repository.CopyRow) error {
-	args := m.Called(ctx, rows)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BeginTx(ctx context.Context) (repository.TxRepository, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(repository.TxRepository), args.Error(1)
-}
-
-// M3: Goal assignment control methods
-func (m *MockGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-// M4: Batch goal activation
-func (m *MockGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-// M3 Phase 9: Fast path optimization methods
-func (m *MockGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-type MockTxGoalRepository struct {
-	mock.Mock
-}
-
-func (m *MockTxGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockTxGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockTxGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, challengeID, activeOnly)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
-	args := m.Called(ctx, userID, goalID)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) BatchUpsertProgress(ctx context.Context, progressList []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progressList)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []repository.CopyRow) error {
-	args := m.Called(ctx, rows)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) GetProgressForUpdate(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxGoalRepository) Commit() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) Rollback() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) BeginTx(ctx context.Context) (repository.TxRepository, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(repository.TxRepository), args.Error(1)
-}
-
-// M3: Goal assignment control methods
-func (m *MockTxGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxGoalRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockTxGoalRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-// M4: Batch goal activation
-func (m *MockTxGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-// M3 Phase 9: Fast path optimization methods
-func (m *MockTxGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockTxGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-type MockRewardClient struct {
-	mock.Mock
-}
-
-func (m *MockRewardClient) GrantItemReward(ctx context.Context, namespace, userID, itemID string, quantity int) error {
-	args := m.Called(ctx, namespace, userID, itemID, quantity)
-	return args.Error(0)
-}
-
-func (m *MockRewardClient) GrantWalletReward(ctx context.Context, namespace, userID, currencyCode string, amount int) error {
-	args := m.Called(ctx, namespace, userID, currencyCode, amount)
-	return args.Error(0)
-}
-
-func (m *MockRewardClient) GrantReward(ctx context.Context, namespace, userID string, reward domain.Reward) error {
-	args := m.Called(ctx, namespace, userID, reward)
-	return args.Error(0)
-}
-
 // Helper function to create authenticated context with user ID
 // This simulates what the auth interceptor does after JWT validation
 func createAuthContext(userID, namespace string) context.Context {
@@ -362,9 +88,9 @@ func TestExtractUserIDFromContext_EmptyUserID(t *testing.T) {
 
 // Tests for GetUserChallenges
 func TestGetUserChallenges_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -433,10 +159,376 @@ func TestGetUserChallenges_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetUserChallenges_QueryBudget(t *testing.T) {
+	var challenges []*domain.Challenge
+	var userProgress []*domain.UserGoalProgress
+	for c := range 4 {
+		challenge := &domain.Challenge{ID: fmt.Sprintf("challenge%d", c), Name: "Test Challenge"}
+		for g := range 5 {
+			goal := &domain.Goal{
+				ID:          fmt.Sprintf("goal%d-%d", c, g),
+				ChallengeID: challenge.ID,
+				Name:        "Test Goal",
+				Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+				Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+				EventSource: domain.EventSourceStatistic,
+			}
+			challenge.Goals = append(challenge.Goals, goal)
+			userProgress = append(userProgress, &domain.UserGoalProgress{
+				UserID: "user123", GoalID: goal.ID, ChallengeID: challenge.ID, Progress: 5, Status: domain.GoalStatusInProgress,
+			})
+		}
+		challenges = append(challenges, challenge)
+	}
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return(challenges)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return(userProgress, nil)
+
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	dbMock.MatchExpectationsInOrder(false)
+	dbMock.ExpectQuery("SELECT goal_id, activation_source").
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "activation_source"}))
+	dbMock.ExpectQuery("SELECT goal_id, COALESCE\\(first_completed_at, completed_at\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id", "first_completed_at"}))
+	repo, ctx, queries := testutil.CountQueries(createAuthContext("user123", "test-namespace"), mockRepo)
+	server := NewChallengeServiceServer(mockCache, repo, new(mocks.RewardClient), db, "test-namespace")
+	server.SetQueryMetrics(serviceRepo.NewQueryMetrics(0))
+
+	resp, err := server.GetUserChallenges(ctx, &pb.GetChallengesRequest{})
+
+	require.NoError(t, err)
+	assert.Len(t, resp.Challenges, 4)
+	testutil.AssertMaxQueries(t, queries, testutil.MaxGetUserChallengesQueries, "GetUserChallenges")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetUserChallenges_StrongConsistency(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.progressQueries = queries
+
+	goal := &domain.Goal{
+		ID:          "goal1",
+		ChallengeID: "challenge1",
+		Name:        "Test Goal",
+		EventSource: domain.EventSourceStatistic,
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1", Name: "Test Challenge", Goals: []*domain.Goal{goal}}})
+	queries.On("GetUserProgressSnapshot", mock.Anything, "user123", "", false).Return(&serviceRepo.ProgressSnapshot{
+		Rows: []*domain.UserGoalProgress{
+			{UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Progress: 10, Status: domain.GoalStatusCompleted, IsActive: true},
+		},
+		TakenAt: time.Date(2025, 6, 1, 12, 0, 0, 250_000_000, time.UTC),
+	}, nil)
+	queries.On("GetFirstCompletions", mock.Anything, "user123", []string{"goal1"}).Return(map[string]time.Time(nil), nil)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{Consistency: "strong"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 1)
+	assert.Equal(t, "completed", resp.Challenges[0].Goals[0].Status)
+	// Rounded up, so passing it back never rejects a row the snapshot held
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 1, 0, time.UTC), resp.SnapshotAt.AsTime())
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+
+	// The default read has no snapshot
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return(nil, nil)
+	resp, err = server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, resp.SnapshotAt)
+
+	_, err = server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{Consistency: "eventually"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetUserChallenges_FirstCompletionOfResetRow(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.progressQueries = queries
+
+	goal := &domain.Goal{
+		ID: "goal1", ChallengeID: "challenge1",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1", Name: "Test Challenge", Goals: []*domain.Goal{goal}}})
+	// Reset by a rotation: completed_at is cleared, the first completion kept
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Progress: 2, Status: domain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+	firstCompleted := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	queries.On("GetFirstCompletions", mock.Anything, "user123", []string{"goal1"}).
+		Return(map[string]time.Time{"goal1": firstCompleted}, nil)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{})
+
+	require.NoError(t, err)
+	got := resp.Challenges[0].Goals[0]
+	assert.Nil(t, got.CompletedAt)
+	assert.Equal(t, firstCompleted, got.FirstCompletedAt.AsTime())
+}
+
+func TestGetUserChallenges_Paginated(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.progressQueries = queries
+
+	newGoal := func(id, challengeID string) *domain.Goal {
+		return &domain.Goal{
+			ID:          id,
+			ChallengeID: challengeID,
+			Name:        id,
+			EventSource: domain.EventSourceStatistic,
+			Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+		}
+	}
+	goalA, goalB, goalC := newGoal("goal-a", "challenge1"), newGoal("goal-b", "challenge2"), newGoal("goal-c", "challenge1")
+	challenge1 := &domain.Challenge{ID: "challenge1", Name: "Challenge 1", Goals: []*domain.Goal{goalA, goalC}}
+	challenge2 := &domain.Challenge{ID: "challenge2", Name: "Challenge 2", Goals: []*domain.Goal{goalB}}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1, challenge2})
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge1)
+	mockCache.On("GetChallengeByChallengeID", "challenge2").Return(challenge2)
+	mockCache.On("GetAllGoals").Return([]*domain.Goal{goalC, goalB, goalA})
+	for _, goal := range []*domain.Goal{goalA, goalB, goalC} {
+		mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	}
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"goal-a", "goal-b"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal-b", ChallengeID: "challenge2", Progress: 4, Status: domain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+	queries.On("GetFirstCompletions", mock.Anything, "user123", mock.Anything).Return(map[string]time.Time(nil), nil)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 2)
+	assert.Equal(t, "challenge1", resp.Challenges[0].ChallengeId)
+	require.Len(t, resp.Challenges[0].Goals, 1, "only the page's goals")
+	assert.Equal(t, "goal-a", resp.Challenges[0].Goals[0].GoalId)
+	assert.Equal(t, "goal-b", resp.Challenges[1].Goals[0].GoalId)
+	assert.Equal(t, int32(4), resp.Challenges[1].Goals[0].Progress)
+	assert.Equal(t, "goal-b", resp.NextAfterGoalId)
+
+	// active_only pages over the user's active rows
+	queries.On("GetUserProgressPage", mock.Anything, "user123", true, "goal-b", 3).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal-c", ChallengeID: "challenge1", Progress: 1, Status: domain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+
+	resp, err = server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{Limit: 2, AfterGoalId: "goal-b", ActiveOnly: true})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 1)
+	assert.Equal(t, "goal-c", resp.Challenges[0].Goals[0].GoalId)
+	assert.Empty(t, resp.NextAfterGoalId, "last page")
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserChallenges_ExcludeClaimed(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.progressQueries = new(mocks.ProgressQueryRepository)
+
+	newGoal := func(id, challengeID string) *domain.Goal {
+		return &domain.Goal{
+			ID:          id,
+			ChallengeID: challengeID,
+			Name:        id,
+			EventSource: domain.EventSourceStatistic,
+			Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+		}
+	}
+	goalA, goalB, goalC := newGoal("goal-a", "challenge1"), newGoal("goal-b", "challenge2"), newGoal("goal-c", "challenge1")
+	challenge1 := &domain.Challenge{ID: "challenge1", Name: "Challenge 1", Goals: []*domain.Goal{goalA, goalC}}
+	challenge2 := &domain.Challenge{ID: "challenge2", Name: "Challenge 2", Goals: []*domain.Goal{goalB}}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1, challenge2})
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge1)
+	mockCache.On("GetChallengeByChallengeID", "challenge2").Return(challenge2)
+	mockCache.On("GetAllGoals").Return([]*domain.Goal{goalC, goalB, goalA})
+	for _, goal := range []*domain.Goal{goalA, goalB, goalC} {
+		mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	}
+	claimedA := &domain.UserGoalProgress{UserID: "user123", GoalID: "goal-a", ChallengeID: "challenge1", Progress: 10, Status: domain.GoalStatusClaimed, IsActive: true}
+	claimedB := &domain.UserGoalProgress{UserID: "user123", GoalID: "goal-b", ChallengeID: "challenge2", Progress: 10, Status: domain.GoalStatusClaimed, IsActive: true}
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{claimedA, claimedB}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"goal-a", "goal-b"}).Return([]*domain.UserGoalProgress{claimedA, claimedB}, nil)
+	ctx := createAuthContext("user123", "test-namespace")
+
+	resp, err := server.GetUserChallenges(ctx, &pb.GetChallengesRequest{ExcludeClaimed: true})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 2, "challenges stay listed")
+	require.Len(t, resp.Challenges[0].Goals, 1)
+	assert.Equal(t, "goal-c", resp.Challenges[0].Goals[0].GoalId)
+	assert.Empty(t, resp.Challenges[1].Goals)
+
+	// A page holding only claimed goals lists no challenges but still moves the cursor
+	resp, err = server.GetUserChallenges(ctx, &pb.GetChallengesRequest{ExcludeClaimed: true, Limit: 2})
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.Challenges)
+	assert.Equal(t, "goal-b", resp.NextAfterGoalId)
+}
+
+func TestGetUserChallenges_InvalidPagination(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("user123", "test-namespace")
+
+	for name, req := range map[string]*pb.GetChallengesRequest{
+		"negative limit":     {Limit: -1},
+		"limit too large":    {Limit: service.MaxProgressPageLimit + 1},
+		"with challenge_ids": {Limit: 10, ChallengeIds: []string{"challenge1"}},
+		"with consistency":   {Limit: 10, Consistency: "strong"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := server.GetUserChallenges(ctx, req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestGetUserChallenges_HiddenGoal(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	newGoal := func(id string, prerequisites ...string) *domain.Goal {
+		return &domain.Goal{
+			ID:            id,
+			ChallengeID:   "challenge1",
+			Name:          id,
+			Requirement:   domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 1, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:        domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+			EventSource:   domain.EventSourceStatistic,
+			Prerequisites: prerequisites,
+		}
+	}
+	challenge := &domain.Challenge{
+		ID:    "challenge1",
+		Name:  "Test Challenge",
+		Goals: []*domain.Goal{newGoal("intro"), newGoal("secret", "intro")},
+	}
+	intro := &domain.UserGoalProgress{UserID: "user123", GoalID: "intro", ChallengeID: "challenge1", Progress: 1}
+
+	tests := []struct {
+		name    string
+		status  domain.GoalStatus
+		wantIDs []string
+	}{
+		{"locked until prerequisite claimed", domain.GoalStatusCompleted, []string{"intro"}},
+		{"listed once prerequisite claimed", domain.GoalStatusClaimed, []string{"intro", "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(mocks.GoalCache)
+			mockRepo := new(mocks.GoalRepository)
+			server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+			server.SetHiddenGoals(service.HiddenGoals{"secret": true})
+
+			progress := *intro
+			progress.Status = tt.status
+			mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge})
+			mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{&progress}, nil)
+
+			resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{})
+
+			require.NoError(t, err)
+			var goalIDs []string
+			for _, goal := range resp.Challenges[0].Goals {
+				goalIDs = append(goalIDs, goal.GoalId)
+			}
+			assert.Equal(t, tt.wantIDs, goalIDs)
+			assert.Len(t, challenge.Goals, 2, "config challenge must not be modified")
+		})
+	}
+}
+
+func TestGetUserChallenges_SingleChallengeFilterUsesChallengeQuery(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	newGoal := func(id, challengeID string, prerequisites ...string) *domain.Goal {
+		return &domain.Goal{
+			ID:            id,
+			ChallengeID:   challengeID,
+			Name:          id,
+			Requirement:   domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 1, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:        domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+			EventSource:   domain.EventSourceStatistic,
+			Prerequisites: prerequisites,
+		}
+	}
+	challenges := []*domain.Challenge{
+		{ID: "challenge1", Name: "First", Goals: []*domain.Goal{newGoal("goal1", "challenge1")}},
+		{ID: "challenge2", Name: "Second", Goals: []*domain.Goal{newGoal("goal2", "challenge2", "goal1"), newGoal("goal3", "challenge2")}},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetAllChallenges").Return(challenges)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge2", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal3", ChallengeID: "challenge2", Progress: 1, Status: domain.GoalStatusCompleted},
+	}, nil)
+	// The prerequisite of goal2 is outside the filter, so its row is loaded by ID
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"goal1"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Progress: 1, Status: domain.GoalStatusClaimed},
+	}, nil)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{ChallengeIds: []string{"challenge2"}})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 1)
+	challenge := resp.Challenges[0]
+	assert.Equal(t, "challenge2", challenge.ChallengeId)
+	require.Len(t, challenge.Goals, 2)
+	assert.Equal(t, "goal2", challenge.Goals[0].GoalId)
+	assert.True(t, challenge.Goals[0].Activatable, "prerequisite from another challenge is claimed")
+	assert.Equal(t, "completed", challenge.Goals[1].Status)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetUserChallenges_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -455,9 +547,9 @@ func TestGetUserChallenges_NoAuthContext(t *testing.T) {
 }
 
 func TestGetUserChallenges_ServiceError(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -489,11 +581,110 @@ func TestGetUserChallenges_ServiceError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// Tests for GetProgressSummary
+func TestGetProgressSummary_Success(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		{ID: "challenge1", Name: "Challenge 1", Goals: []*domain.Goal{{ID: "goal1"}, {ID: "goal2"}, {ID: "goal3"}}},
+		{ID: "challenge2", Name: "Challenge 2", Goals: []*domain.Goal{{ID: "goal4"}}},
+	})
+	sqlMock.ExpectQuery(`GROUP BY challenge_id, status`).
+		WithArgs("user123", "test-namespace").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "status", "count"}).
+			AddRow("challenge1", "claimed", 1).
+			AddRow("challenge1", "completed", 1).
+			AddRow("challenge1", "in_progress", 1))
+
+	ctx := createAuthContext("user123", "test-namespace")
+	resp, err := server.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), resp.TotalGoals)
+	assert.Equal(t, int32(2), resp.CompletedGoals)
+	assert.Equal(t, int32(1), resp.ClaimedGoals)
+	assert.Equal(t, int32(1), resp.InProgressGoals)
+	assert.Len(t, resp.Challenges, 2)
+	assert.Equal(t, "challenge1", resp.Challenges[0].ChallengeId)
+	assert.Equal(t, int32(3), resp.Challenges[0].TotalGoals)
+	assert.Equal(t, int32(2), resp.Challenges[0].CompletedGoals)
+	assert.Equal(t, int32(0), resp.Challenges[1].CompletedGoals)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestGetProgressSummary_NextReset(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		{ID: "daily", Goals: []*domain.Goal{{ID: "login", Rotation: &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}}}},
+		{ID: "season", Goals: []*domain.Goal{{ID: "win"}}},
+	})
+	sqlMock.ExpectQuery(`GROUP BY challenge_id, status`).
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "status", "count"}))
+
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+	server.now = func() time.Time { return time.Date(2025, 3, 4, 23, 59, 30, 0, time.UTC) }
+
+	resp, err := server.GetProgressSummary(createAuthContext("user123", "test-namespace"), &pb.GetProgressSummaryRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 2)
+	assert.Equal(t, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), resp.Challenges[0].NextResetAt.AsTime())
+	assert.Equal(t, int32(30), resp.Challenges[0].SecondsRemaining)
+	assert.Nil(t, resp.Challenges[1].NextResetAt, "no goal rotates")
+	assert.Zero(t, resp.Challenges[1].SecondsRemaining)
+}
+
+func TestGetProgressSummary_NoAuthContext(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	resp, err := server.GetProgressSummary(context.Background(), &pb.GetProgressSummaryRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetProgressSummary_DatabaseError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	sqlMock.ExpectQuery(`AND is_active = true GROUP BY challenge_id, status`).
+		WithArgs("user123", "test-namespace").
+		WillReturnError(errors.New("connection refused"))
+
+	ctx := createAuthContext("user123", "test-namespace")
+	resp, err := server.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{ActiveOnly: true})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
 // Tests for InitializePlayer
 func TestInitializePlayer_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -532,12 +723,18 @@ func TestInitializePlayer_Success(t *testing.T) {
 
 	// Phase 10: No GetGoalsByIDs() call when count == 0 (optimization)
 
-	mockRepo.On("BulkInsert", mock.Anything, mock.MatchedBy(func(progress []*domain.UserGoalProgress) bool {
+	mockInserter := new(mocks.ProgressInsertRepository)
+	mockInserter.On("BulkInsertReturningConflicts", mock.Anything, mock.MatchedBy(func(progress []*domain.UserGoalProgress) bool {
 		return len(progress) == 1 && progress[0].GoalID == "daily-login" && progress[0].IsActive
-	})).Return(nil)
+	})).Return(&serviceRepo.BulkInsertResult{Inserted: []serviceRepo.UserGoalKey{{UserID: "new-user", GoalID: "daily-login"}}}, nil)
+	server.SetProgressInserter(mockInserter)
 
 	// Phase 10: No GetGoalsByIDs() after insert (return created data directly)
 
+	mockSources := new(mocks.ActivationSourceRepository)
+	mockSources.On("SetActivationSource", mock.Anything, "new-user", []string{"daily-login"}, "default").Return(nil)
+	server.SetActivationSources(mockSources)
+
 	ctx := createAuthContext("new-user", "test-namespace")
 	req := &pb.InitializeRequest{}
 
@@ -550,15 +747,78 @@ func TestInitializePlayer_Success(t *testing.T) {
 	assert.Len(t, resp.AssignedGoals, 1)
 	assert.Equal(t, "daily-login", resp.AssignedGoals[0].GoalId)
 	assert.True(t, resp.AssignedGoals[0].IsActive)
+	assert.Equal(t, "default", resp.AssignedGoals[0].ActivationSource)
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
+	mockSources.AssertExpectations(t)
+	mockInserter.AssertExpectations(t)
+}
+
+func TestInitializePlayer_AutoRandomSelect(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	loginGoal := &domain.Goal{
+		ID: "daily-login", ChallengeID: "starter", DefaultAssigned: true,
+		Requirement: domain.Requirement{StatCode: "login_count", Operator: ">=", TargetValue: 1},
+	}
+	killGoal := &domain.Goal{
+		ID: "kill-5", ChallengeID: "daily", EventSource: domain.EventSourceStatistic,
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 5},
+	}
+	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*domain.Goal{loginGoal})
+	mockCache.On("GetGoalByID", "daily-login").Return(loginGoal)
+	mockCache.On("GetGoalByID", "kill-5").Return(killGoal)
+	mockCache.On("GetChallengeByChallengeID", "daily").Return(&domain.Challenge{ID: "daily", Goals: []*domain.Goal{killGoal}})
+	mockRepo.On("GetUserGoalCount", mock.Anything, "new-user").Return(0, nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "new-user", "daily", false).Return([]*domain.UserGoalProgress{}, nil)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockTx.On("BatchUpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
+	mockTx.On("CountActiveGoals", mock.Anything, "new-user", "daily").Return(1, nil)
+	mockTx.On("Commit").Return(nil)
+	mockTx.On("Rollback").Return(nil)
+
+	mockInserter := new(mocks.ProgressInsertRepository)
+	mockInserter.On("BulkInsertReturningConflicts", mock.Anything, mock.Anything).
+		Return(&serviceRepo.BulkInsertResult{Inserted: []serviceRepo.UserGoalKey{{UserID: "new-user", GoalID: "daily-login"}}}, nil)
+	server.SetProgressInserter(mockInserter)
+
+	mockSources := new(mocks.ActivationSourceRepository)
+	mockSources.On("SetActivationSource", mock.Anything, "new-user", []string{"kill-5"}, "random").Return(nil)
+	mockSources.On("SetActivationSource", mock.Anything, "new-user", []string{"daily-login"}, "default").Return(nil)
+	mockSources.On("GetActivationSources", mock.Anything, "new-user", []string{"kill-5"}).Return(map[string]string{"kill-5": "random"}, nil)
+	server.SetActivationSources(mockSources)
+
+	selector := service.NewAutoRandomSelector(service.AutoRandomSelections{"daily": 1}, nil)
+	selector.SetActivationSources(mockSources)
+	server.SetAutoRandomSelector(selector)
+
+	resp, err := server.InitializePlayer(createAuthContext("new-user", "test-namespace"), &pb.InitializeRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.NewAssignments)
+	assert.Equal(t, int32(2), resp.TotalActive)
+	require.Len(t, resp.AssignedGoals, 2)
+	assert.Equal(t, "default", resp.AssignedGoals[0].ActivationSource)
+	assert.Equal(t, "kill-5", resp.AssignedGoals[1].GoalId)
+	assert.Equal(t, "daily", resp.AssignedGoals[1].ChallengeId)
+	assert.Equal(t, "random", resp.AssignedGoals[1].ActivationSource)
+	mockRepo.AssertExpectations(t)
+	mockSources.AssertExpectations(t)
 }
 
 func TestInitializePlayer_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -577,9 +837,9 @@ func TestInitializePlayer_NoAuthContext(t *testing.T) {
 }
 
 func TestInitializePlayer_ServiceError(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -593,6 +853,7 @@ func TestInitializePlayer_ServiceError(t *testing.T) {
 			ID:              "test-goal",
 			ChallengeID:     "test-challenge",
 			DefaultAssigned: true,
+			Requirement:     domain.Requirement{TargetValue: 1},
 		},
 	})
 	// Phase 10: No GetGoalByID() mock needed - when BulkInsert fails, function returns early
@@ -601,8 +862,10 @@ func TestInitializePlayer_ServiceError(t *testing.T) {
 	// M3 Phase 9: Fast path check - user not initialized
 	mockRepo.On("GetUserGoalCount", mock.Anything, "user123").Return(0, nil)
 
-	// Phase 10: No GetGoalsByIDs() call, test BulkInsert error instead
-	mockRepo.On("BulkInsert", mock.Anything, mock.Anything).Return(errors.New("database error"))
+	// Phase 10: No GetGoalsByIDs() call, test insert error instead
+	mockInserter := new(mocks.ProgressInsertRepository)
+	mockInserter.On("BulkInsertReturningConflicts", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+	server.SetProgressInserter(mockInserter)
 
 	ctx := createAuthContext("user123", "test-namespace")
 	req := &pb.InitializeRequest{}
@@ -617,14 +880,82 @@ func TestInitializePlayer_ServiceError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// Tests for SetGoalActive
-func TestSetGoalActive_ActivateGoal(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+func TestStartSession_Disabled(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(new(mocks.GoalCache), mockRepo, new(mocks.RewardClient), nil, "test-namespace")
 
-	db, _, err := sqlmock.New()
-	assert.NoError(t, err)
+	_, err := server.StartSession(createAuthContext("user123", "test-namespace"), &pb.StartSessionRequest{})
+
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	mockRepo.AssertNotCalled(t, "GetUserGoalCount", mock.Anything, mock.Anything)
+}
+
+func TestStartSession_ReturningPlayer(t *testing.T) {
+	kills := &domain.Goal{ID: "kills", ChallengeID: "c1", Name: "Kill 10", DefaultAssigned: true, Requirement: domain.Requirement{StatCode: "kills", TargetValue: 10}}
+	wins := &domain.Goal{ID: "wins", ChallengeID: "c1", Name: "Win 3", DefaultAssigned: true, Requirement: domain.Requirement{StatCode: "wins", TargetValue: 3}}
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*domain.Goal{kills, wins})
+	mockCache.On("GetGoalByID", "kills").Return(kills)
+	mockCache.On("GetGoalByID", "wins").Return(wins)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "c1", Name: "Season", Goals: []*domain.Goal{kills, wins}}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user123").Return(2, nil)
+	mockRepo.On("GetActiveGoals", mock.Anything, "user123").Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "kills", ChallengeID: "c1", Progress: 10, Status: domain.GoalStatusCompleted, IsActive: true},
+		{UserID: "user123", GoalID: "wins", ChallengeID: "c1", Progress: 1, Status: domain.GoalStatusInProgress, IsActive: true},
+	}, nil)
+	mockSources := new(mocks.ActivationSourceRepository)
+	mockSources.On("GetActivationSources", mock.Anything, "user123", []string{"kills", "wins"}).Return(map[string]string{"kills": "default"}, nil)
+	queries := new(mocks.ProgressQueryRepository)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetActivationSources(mockSources)
+	server.SetUnclaimedCounts(service.NewUnclaimedCounts(queries, "test-namespace", time.Minute))
+	server.SetSessionStart(true)
+
+	resp, err := server.StartSession(createAuthContext("user123", "test-namespace"), &pb.StartSessionRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Initialize.AssignedGoals, 2)
+	assert.Equal(t, "default", resp.Initialize.AssignedGoals[0].ActivationSource)
+	assert.Equal(t, int32(2), resp.Initialize.TotalActive)
+	assert.Equal(t, int32(2), resp.Summary.TotalGoals)
+	assert.Equal(t, int32(1), resp.Summary.CompletedGoals)
+	assert.Equal(t, int32(1), resp.Summary.InProgressGoals)
+	require.Len(t, resp.Summary.Challenges, 1)
+	assert.Equal(t, "Season", resp.Summary.Challenges[0].Name)
+	assert.Equal(t, &pb.GetUnclaimedCountResponse{HasUnclaimed: true, UnclaimedCount: 1, InProgressCount: 1}, resp.Unclaimed)
+
+	// Summary and counts come from the active goals, not from their own queries
+	queries.AssertNotCalled(t, "GetUserProgressSummary", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	queries.AssertNotCalled(t, "CountByStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartSession_ServiceError(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*domain.Goal{
+		{ID: "test-goal", ChallengeID: "test-challenge", DefaultAssigned: true, Requirement: domain.Requirement{TargetValue: 1}},
+	})
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user123").Return(0, errors.New("database error"))
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetSessionStart(true)
+
+	_, err := server.StartSession(createAuthContext("user123", "test-namespace"), &pb.StartSessionRequest{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+// Tests for SetGoalActive
+func TestSetGoalActive_ActivateGoal(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
 	defer func() { _ = db.Close() }()
 
 	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
@@ -656,6 +987,10 @@ func TestSetGoalActive_ActivateGoal(t *testing.T) {
 	mockRepo.On("GetProgress", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil) // No existing progress
 	mockRepo.On("UpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
 
+	mockSources := new(mocks.ActivationSourceRepository)
+	mockSources.On("SetActivationSource", mock.Anything, "user123", []string{"optional-quest"}, "manual").Return(nil)
+	server.SetActivationSources(mockSources)
+
 	ctx := createAuthContext("user123", "test-namespace")
 	req := &pb.SetGoalActiveRequest{
 		ChallengeId: "side-quests",
@@ -672,17 +1007,17 @@ func TestSetGoalActive_ActivateGoal(t *testing.T) {
 	assert.True(t, resp.IsActive)
 	assert.NotEmpty(t, resp.AssignedAt)
 	assert.Contains(t, resp.Message, "activated")
+	assert.Equal(t, "manual", resp.ActivationSource)
 
 	// Verify assigned_at is recent (within last 5 seconds)
-	parsedTime, parseErr := time.Parse(time.RFC3339, resp.AssignedAt)
-	assert.NoError(t, parseErr)
-	assert.WithinDuration(t, assignedAt, parsedTime, 5*time.Second)
+	assert.WithinDuration(t, assignedAt, resp.AssignedAt.AsTime(), 5*time.Second)
+	mockSources.AssertExpectations(t)
 }
 
 func TestSetGoalActive_DeactivateGoal(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -711,6 +1046,11 @@ func TestSetGoalActive_DeactivateGoal(t *testing.T) {
 	mockRepo.On("GetProgress", mock.Anything, mock.Anything, mock.Anything).Return(existingProgress, nil)
 	mockRepo.On("UpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
 
+	// Deactivation keeps the source the goal was activated with
+	mockSources := new(mocks.ActivationSourceRepository)
+	mockSources.On("GetActivationSources", mock.Anything, "user123", []string{"active-quest"}).Return(map[string]string{"active-quest": "random"}, nil)
+	server.SetActivationSources(mockSources)
+
 	ctx := createAuthContext("user123", "test-namespace")
 	req := &pb.SetGoalActiveRequest{
 		ChallengeId: "daily-quests",
@@ -726,12 +1066,16 @@ func TestSetGoalActive_DeactivateGoal(t *testing.T) {
 	assert.Equal(t, "active-quest", resp.GoalId)
 	assert.False(t, resp.IsActive)
 	assert.Contains(t, resp.Message, "deactivated")
+	assert.Equal(t, "random", resp.ActivationSource)
+	assert.Equal(t, int32(3), resp.Progress, "deactivation keeps progress by default")
+	assert.Equal(t, "in_progress", resp.Status)
+	mockSources.AssertNotCalled(t, "SetActivationSource", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestSetGoalActive_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -754,9 +1098,9 @@ func TestSetGoalActive_NoAuthContext(t *testing.T) {
 }
 
 func TestSetGoalActive_MissingChallengeID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -780,9 +1124,9 @@ func TestSetGoalActive_MissingChallengeID(t *testing.T) {
 }
 
 func TestSetGoalActive_MissingGoalID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -806,9 +1150,9 @@ func TestSetGoalActive_MissingGoalID(t *testing.T) {
 }
 
 func TestSetGoalActive_ServiceError(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -835,12 +1179,295 @@ func TestSetGoalActive_ServiceError(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
+func TestSetGoalActive_ChallengeMismatch(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", "goal1").Return(&domain.Goal{ID: "goal1", ChallengeID: "challenge1"})
+	mockRepo.On("GetProgress", mock.Anything, "user123", "goal1").
+		Return(&domain.UserGoalProgress{UserID: "user123", GoalID: "goal1", ChallengeID: "old-challenge"}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.SetGoalActive(createAuthContext("user123", "test-namespace"), &pb.SetGoalActiveRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+		IsActive:    false,
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
+
+func TestSetGoalActive_GoalInOtherChallenge(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", "daily-login").Return(&domain.Goal{ID: "daily-login", ChallengeID: "daily"})
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.SetGoalActive(createAuthContext("user123", "test-namespace"), &pb.SetGoalActiveRequest{
+		ChallengeId: "weekly",
+		GoalId:      "daily-login",
+		IsActive:    true,
+	})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "it belongs to challenge daily")
+	mockRepo.AssertNotCalled(t, "GetProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetGoalActive_ResetProgress(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	resets := new(mocks.GoalResetRepository)
+	mockSources := new(mocks.ActivationSourceRepository)
+
+	mockCache.On("GetGoalByID", "goal1").Return(&domain.Goal{ID: "goal1", ChallengeID: "challenge1"})
+	mockRepo.On("GetProgress", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    7,
+		Status:      domain.GoalStatusInProgress,
+		IsActive:    true,
+	}, nil)
+	resets.On("DeactivateAndReset", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Status:      domain.GoalStatusNotStarted,
+	}, nil)
+	mockSources.On("GetActivationSources", mock.Anything, "user123", []string{"goal1"}).Return(map[string]string{}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalResets(resets)
+	server.SetActivationSources(mockSources)
+
+	resp, err := server.SetGoalActive(createAuthContext("user123", "test-namespace"), &pb.SetGoalActiveRequest{
+		ChallengeId:   "challenge1",
+		GoalId:        "goal1",
+		IsActive:      false,
+		ResetProgress: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Changed)
+	assert.False(t, resp.IsActive)
+	assert.Equal(t, int32(0), resp.Progress)
+	assert.Equal(t, "not_started", resp.Status)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+	resets.AssertExpectations(t)
+}
+
+func TestSetGoalActive_ResetProgressClaimed(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", "goal1").Return(&domain.Goal{ID: "goal1", ChallengeID: "challenge1"})
+	mockRepo.On("GetProgress", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Status:      domain.GoalStatusClaimed,
+	}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalResets(new(mocks.GoalResetRepository))
+
+	_, err := server.SetGoalActive(createAuthContext("user123", "test-namespace"), &pb.SetGoalActiveRequest{
+		ChallengeId:   "challenge1",
+		GoalId:        "goal1",
+		ResetProgress: true,
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "Claimed goals cannot have their progress reset")
+}
+
+func TestSetGoalActive_ResetProgressWhenActivating(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.SetGoalActive(createAuthContext("user123", "test-namespace"), &pb.SetGoalActiveRequest{
+		ChallengeId:   "challenge1",
+		GoalId:        "goal1",
+		IsActive:      true,
+		ResetProgress: true,
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "reset_progress only applies when deactivating")
+}
+
+// activationRepeats keeps the repeat state of one repeatable goal for
+// TestGetActivationPreflight_AgreesWithSetGoalActive: like the PostgreSQL
+// repository, ResetClaimed counts the claim of a claimed row.
+type activationRepeats struct {
+	row   *domain.UserGoalProgress
+	state *serviceRepo.RepeatState
+}
+
+func (r *activationRepeats) ResetClaimed(ctx context.Context, userID, goalID string) (*serviceRepo.RepeatState, error) {
+	if r.row == nil || r.row.Status != domain.GoalStatusClaimed {
+		return nil, nil
+	}
+	state := serviceRepo.RepeatState{TimesClaimed: 1, LastClaimedAt: *r.row.ClaimedAt}
+	if r.state != nil {
+		state.TimesClaimed += r.state.TimesClaimed
+	}
+	r.state, r.row = &state, nil
+	return &state, nil
+}
+
+func (r *activationRepeats) GetRepeatStates(ctx context.Context, userID string, goalIDs []string) (map[string]serviceRepo.RepeatState, error) {
+	if r.state == nil {
+		return nil, nil
+	}
+	return map[string]serviceRepo.RepeatState{"s1a": *r.state}, nil
+}
+
+// TestGetActivationPreflight_AgreesWithSetGoalActive runs the preflight and the
+// activation on the same state for every activation blocker: the preflight
+// allows exactly the activations that succeed, and its first blocker is the
+// error the activation fails with.
+func TestGetActivationPreflight_AgreesWithSetGoalActive(t *testing.T) {
+	now := time.Now().UTC()
+	hoursAgo := func(hours int) *time.Time {
+		at := now.Add(-time.Duration(hours) * time.Hour)
+		return &at
+	}
+	row := func(status domain.GoalStatus, active bool) *domain.UserGoalProgress {
+		return &domain.UserGoalProgress{UserID: "user123", GoalID: "s1a", ChallengeID: "season-1", Status: status, IsActive: active}
+	}
+	claimedRow := func(active bool, claimedAt *time.Time) *domain.UserGoalProgress {
+		claimed := row(domain.GoalStatusClaimed, active)
+		claimed.ClaimedAt = claimedAt
+		return claimed
+	}
+	mismatched := row(domain.GoalStatusInProgress, false)
+	mismatched.ChallengeID = "tutorial"
+
+	tests := []struct {
+		name         string
+		locked       bool
+		row          *domain.UserGoalProgress
+		repeatable   bool
+		lastClaimed  *time.Time
+		wantBlockers []string
+		wantCode     codes.Code
+	}{
+		{name: "no row"},
+		{name: "inactive", row: row(domain.GoalStatusInProgress, false)},
+		{name: "already active", row: row(domain.GoalStatusInProgress, true)},
+		{name: "completed", row: row(domain.GoalStatusCompleted, false)},
+		{name: "claimed", row: claimedRow(false, hoursAgo(1)), wantBlockers: []string{"already_claimed"}, wantCode: codes.AlreadyExists},
+		{name: "claimed and still active", row: claimedRow(true, hoursAgo(1))},
+		{name: "locked", locked: true, wantBlockers: []string{"challenge_locked"}, wantCode: codes.FailedPrecondition},
+		{
+			name: "locked and claimed", locked: true, row: claimedRow(false, hoursAgo(1)),
+			wantBlockers: []string{"challenge_locked", "already_claimed"}, wantCode: codes.FailedPrecondition,
+		},
+		{name: "stored under another challenge", row: mismatched, wantBlockers: []string{"challenge_mismatch"}, wantCode: codes.FailedPrecondition},
+		{name: "repeatable, never claimed", repeatable: true},
+		{
+			name: "repeatable, cooling down", repeatable: true, row: row(domain.GoalStatusNotStarted, false), lastClaimed: hoursAgo(1),
+			wantBlockers: []string{"goal_cooling_down"}, wantCode: codes.FailedPrecondition,
+		},
+		{name: "repeatable, cooldown over", repeatable: true, row: row(domain.GoalStatusNotStarted, false), lastClaimed: hoursAgo(30)},
+		{
+			name: "repeatable, claimed row cooling down", repeatable: true, row: claimedRow(true, hoursAgo(1)), lastClaimed: hoursAgo(48),
+			wantBlockers: []string{"goal_cooling_down"}, wantCode: codes.FailedPrecondition,
+		},
+		{name: "repeatable, claimed row cooled down", repeatable: true, row: claimedRow(true, hoursAgo(30))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newServer := func() *ChallengeServiceServer {
+				mockCache := new(mocks.GoalCache)
+				mockCache.On("GetGoalByID", "s1a").Return(&domain.Goal{ID: "s1a", ChallengeID: "season-1"})
+				mockCache.On("GetChallengeByChallengeID", "tutorial").Return(&domain.Challenge{
+					ID: "tutorial", Goals: []*domain.Goal{{ID: "t1", ChallengeID: "tutorial"}},
+				})
+				tutorial := []*domain.UserGoalProgress{{UserID: "user123", GoalID: "t1", ChallengeID: "tutorial", Status: domain.GoalStatusClaimed}}
+				if tt.locked {
+					tutorial = nil
+				}
+				mockRepo := new(mocks.GoalRepository)
+				mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"t1"}).Return(tutorial, nil)
+				mockRepo.On("GetProgress", mock.Anything, "user123", "s1a").Return(tt.row, nil)
+				mockRepo.On("UpsertGoalActive", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+				mockSources := new(mocks.ActivationSourceRepository)
+				mockSources.On("SetActivationSource", mock.Anything, "user123", []string{"s1a"}, "manual").Return(nil).Maybe()
+				mockSources.On("GetActivationSources", mock.Anything, "user123", []string{"s1a"}).Return(nil, nil).Maybe()
+
+				server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+				server.SetActivationSources(mockSources)
+				server.SetChallengePrerequisites(service.ChallengePrerequisites{"season-1": {"tutorial"}})
+				if tt.repeatable {
+					repeats := &activationRepeats{row: tt.row}
+					if tt.lastClaimed != nil {
+						repeats.state = &serviceRepo.RepeatState{TimesClaimed: 1, LastClaimedAt: *tt.lastClaimed}
+					}
+					server.SetRepeatableGoals(service.RepeatableGoals{"s1a": {Cooldown: 24 * time.Hour}}, repeats)
+				}
+				return server
+			}
+			ctx := createAuthContext("user123", "test-namespace")
+
+			preflight, err := newServer().GetActivationPreflight(ctx, &pb.GetActivationPreflightRequest{
+				ChallengeId: "season-1",
+				GoalId:      "s1a",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBlockers, preflight.Blockers)
+
+			_, err = newServer().SetGoalActive(ctx, &pb.SetGoalActiveRequest{
+				ChallengeId: "season-1",
+				GoalId:      "s1a",
+				IsActive:    true,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err), "activation error: %v", err)
+			assert.Equal(t, preflight.Allowed, err == nil, "preflight allowed %v, activation error %v", preflight.Allowed, err)
+		})
+	}
+}
+
+func TestGetActivationPreflight_Validation(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("user123", "test-namespace")
+
+	_, err := server.GetActivationPreflight(ctx, &pb.GetActivationPreflightRequest{GoalId: "goal1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.GetActivationPreflight(ctx, &pb.GetActivationPreflightRequest{ChallengeId: "challenge1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.GetActivationPreflight(context.Background(), &pb.GetActivationPreflightRequest{ChallengeId: "challenge1", GoalId: "goal1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetActivationPreflight_GoalNotFound(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "missing").Return(nil)
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.GetActivationPreflight(createAuthContext("user123", "test-namespace"), &pb.GetActivationPreflightRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "missing",
+	})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 // Tests for ClaimGoalReward
 func TestClaimGoalReward_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -884,12 +1511,19 @@ func TestClaimGoalReward_Success(t *testing.T) {
 	mockCache.On("GetGoalByID", "goal1").Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
 	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal1").Return(nil)
 	mockTxRepo.On("Commit").Return(nil)
 
-	ctx := createAuthContext("user123", "test-namespace")
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "user123", "goal1").Return(nil)
+	outbox.On("Delete", mock.Anything, "user123", "goal1").Return(nil)
+	server.SetClaimOutbox(outbox)
+
+	// Timed as a call that sent x-debug-timing
+	timings := common.NewDebugTiming("test-namespace").Start("1", "test-namespace", "", nil)
+	ctx := common.WithTimings(createAuthContext("user123", "test-namespace"), timings)
 	req := &pb.ClaimRewardRequest{
 		ChallengeId: "challenge1",
 		GoalId:      "goal1",
@@ -905,16 +1539,205 @@ func TestClaimGoalReward_Success(t *testing.T) {
 	assert.Equal(t, "sword", resp.Reward.RewardId)
 	assert.Equal(t, int32(1), resp.Reward.Quantity)
 
+	header := http.Header{}
+	timings.WriteHeader(header, pb.Service_ClaimGoalReward_FullMethodName, "user123")
+	assert.Regexp(t, `^auth;dur=[0-9.]+, db;dur=[0-9.]+, grant;dur=[0-9.]+, encode;dur=[0-9.]+, queries;desc="[0-9]+", total;dur=[0-9.]+$`,
+		header.Get(common.ServerTimingHeader))
+
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 	mockTxRepo.AssertExpectations(t)
+	outbox.AssertExpectations(t)
 	mockRewardClient.AssertExpectations(t)
 }
 
+func TestClaimGoalReward_InvalidExpectedStatus(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId:    "challenge1",
+		GoalId:         "goal1",
+		ExpectedStatus: "done",
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClientContext_Oversized(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("user123", "test-namespace")
+	oversized := map[string]string{"surface": strings.Repeat("x", service.MaxClientContextBytes+1)}
+
+	_, err := server.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "challenge1", GoalId: "goal1", ClientContext: oversized})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.BatchSelectGoals(ctx, &pb.BatchSelectRequest{ChallengeId: "challenge1", GoalIds: []string{"goal1"}, ClientContext: oversized})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.RandomSelectGoals(ctx, &pb.RandomSelectRequest{ChallengeId: "challenge1", Count: 1, ClientContext: map[string]string{
+		"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6",
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClaimGoalReward_QueryBudget(t *testing.T) {
+	goal := &domain.Goal{
+		ID:          "goal1",
+		ChallengeID: "challenge1",
+		Requirement: domain.Requirement{TargetValue: 1},
+		Reward:      domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 100},
+	}
+	grant := agsClient.MockGrantResult("test-namespace", "user123", goal.Reward)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	mockTxRepo := new(mocks.TxRepository)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusCompleted, IsActive: true,
+	}, nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockRewardClient := new(mocks.RewardClient)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+
+	// Every optional step of a claim is enabled, so each adds its queries
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "user123", "goal1").Return(nil)
+	outbox.On("Delete", mock.Anything, "user123", "goal1").Return(nil)
+	freezes := new(mocks.ClaimFreezeRepository)
+	freezes.On("GetClaimFreeze", mock.Anything, "user123", "test-namespace", mock.Anything).Return(nil, nil)
+	claims := new(mocks.ClaimCounterRepository)
+	claims.On("GetClaimWindow", mock.Anything, "user123", "test-namespace", mock.Anything).Return(&serviceRepo.ClaimWindow{}, nil)
+	claims.On("RecordClaim", mock.Anything, "user123", "test-namespace", "goal1", mock.Anything, mock.Anything).Return(nil)
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal1", 1000).Return(true, 1, nil)
+	failedGrants := new(mocks.FailedGrantRepository)
+	failedGrants.On("DeleteFailedGrant", mock.Anything, "user123", "goal1").Return(nil)
+
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	dbMock.ExpectExec(`UPDATE user_goal_progress`).WithArgs("user123", "goal1", "", grant.WalletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	metrics := serviceRepo.NewQueryMetrics(0)
+	repo, ctx, queries := testutil.CountQueries(createAuthContext("user123", "test-namespace"), mockRepo)
+	server := NewChallengeServiceServer(mockCache, repo, agsClient.WithMockGrantResults(mockRewardClient), db, "test-namespace")
+	server.SetClaimOutbox(outbox)
+	server.SetClaimFreezes(service.NewClaimFreezes(serviceRepo.NewInstrumentedClaimFreezeRepository(freezes, metrics), logrus.StandardLogger()))
+	server.SetClaimCap(service.NewClaimCap(serviceRepo.NewInstrumentedClaimCounterRepository(claims, metrics), 10, logrus.StandardLogger()))
+	server.SetRewardCaps(service.RewardCaps{"goal1": {Limit: 1000}},
+		serviceRepo.NewInstrumentedRewardCapRepository(caps, metrics))
+	server.SetFailedGrants(service.NewFailedGrants(
+		serviceRepo.NewInstrumentedFailedGrantRepository(failedGrants, metrics), logrus.StandardLogger()))
+	server.SetQueryMetrics(metrics)
+
+	_, err = server.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "challenge1", GoalId: "goal1"})
+
+	require.NoError(t, err)
+	testutil.AssertMaxQueries(t, queries, testutil.MaxClaimQueries, "ClaimGoalReward")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestClaimGoalReward_RecordsGrantIDs(t *testing.T) {
+	goal := &domain.Goal{
+		ID:          "goal1",
+		ChallengeID: "challenge1",
+		Requirement: domain.Requirement{TargetValue: 1},
+		Reward:      domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 100},
+	}
+	grant := agsClient.MockGrantResult("test-namespace", "user123", goal.Reward)
+
+	tests := []struct {
+		name      string
+		recordErr error
+	}{
+		{name: "recorded"},
+		{name: "record fails", recordErr: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(mocks.GoalCache)
+			mockRepo := new(mocks.GoalRepository)
+			mockTxRepo := new(mocks.TxRepository)
+			mockRewardClient := new(mocks.RewardClient)
+
+			db, dbMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			update := dbMock.ExpectExec(`UPDATE user_goal_progress`).WithArgs("user123", "goal1", "", grant.WalletID)
+			if tt.recordErr != nil {
+				update.WillReturnError(tt.recordErr)
+			} else {
+				update.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			server := NewChallengeServiceServer(mockCache, mockRepo, agsClient.WithMockGrantResults(mockRewardClient), db, "test-namespace")
+			outbox := new(mocks.ClaimOutboxRepository)
+			outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+			outbox.On("MarkGranted", mock.Anything, "user123", "goal1").Return(nil)
+			outbox.On("Delete", mock.Anything, "user123", "goal1").Return(nil)
+			server.SetClaimOutbox(outbox)
+
+			mockCache.On("GetGoalByID", "goal1").Return(goal)
+			mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+			mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+				UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusCompleted, IsActive: true,
+			}, nil)
+			mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+			mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal1").Return(nil)
+			mockTxRepo.On("Commit").Return(nil)
+
+			resp, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+				ChallengeId: "challenge1",
+				GoalId:      "goal1",
+			})
+
+			require.NoError(t, err, "failing to record the IDs does not fail the claim")
+			assert.Equal(t, grant.WalletID, resp.WalletId)
+			assert.Empty(t, resp.EntitlementId)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClaimGoalReward_ClaimCapExceeded(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectQuery(`FROM user_claim_events`).
+		WithArgs("user123", "test-namespace", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(2, time.Now().UTC().Add(-time.Hour)))
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+	server.SetClaimCap(service.NewClaimCap(serviceRepo.NewPostgresClaimCounterRepository(db), 2, logrus.StandardLogger()))
+
+	resp, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "retry_after")
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
 func TestClaimGoalReward_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -936,9 +1759,9 @@ func TestClaimGoalReward_NoAuthContext(t *testing.T) {
 }
 
 func TestClaimGoalReward_MissingChallengeID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -961,9 +1784,9 @@ func TestClaimGoalReward_MissingChallengeID(t *testing.T) {
 }
 
 func TestClaimGoalReward_MissingGoalID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -986,9 +1809,9 @@ func TestClaimGoalReward_MissingGoalID(t *testing.T) {
 }
 
 func TestClaimGoalReward_GoalNotFound(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1014,10 +1837,10 @@ func TestClaimGoalReward_GoalNotFound(t *testing.T) {
 }
 
 func TestClaimGoalReward_GoalNotCompleted(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1059,6 +1882,7 @@ func TestClaimGoalReward_GoalNotCompleted(t *testing.T) {
 	mockCache.On("GetGoalByID", "goal1").Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil)
 
 	ctx := createAuthContext("user123", "test-namespace")
 	req := &pb.ClaimRewardRequest{
@@ -1078,10 +1902,10 @@ func TestClaimGoalReward_GoalNotCompleted(t *testing.T) {
 }
 
 func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1125,6 +1949,7 @@ func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
 	mockCache.On("GetGoalByID", "goal1").Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil)
 
 	ctx := createAuthContext("user123", "test-namespace")
 	req := &pb.ClaimRewardRequest{
@@ -1145,9 +1970,10 @@ func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
 
 // Tests for HealthCheck
 func TestHealthCheck_Healthy(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1"}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 	assert.NoError(t, err)
@@ -1166,14 +1992,21 @@ func TestHealthCheck_Healthy(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "healthy", resp.Status)
+	assert.Equal(t, version.Version, resp.Version)
+	assert.Len(t, resp.Components, 2)
+	for _, component := range resp.Components {
+		assert.Equal(t, "healthy", component.Status, component.Name)
+		assert.True(t, component.Critical, component.Name)
+	}
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestHealthCheck_DatabaseDown(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1"}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 	assert.NoError(t, err)
@@ -1194,13 +2027,24 @@ func TestHealthCheck_DatabaseDown(t *testing.T) {
 	assert.Equal(t, codes.Unavailable, status.Code(err))
 	assert.Contains(t, err.Error(), "database connectivity check failed")
 
+	// The failing component is reported in the status details
+	details := status.Convert(err).Details()
+	assert.Len(t, details, 1)
+	healthResp, ok := details[0].(*pb.HealthCheckResponse)
+	assert.True(t, ok)
+	assert.Equal(t, "unhealthy", healthResp.Status)
+	assert.Equal(t, "database", healthResp.Components[0].Name)
+	assert.Equal(t, "unhealthy", healthResp.Components[0].Status)
+	assert.Equal(t, "healthy", healthResp.Components[1].Status)
+
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestHealthCheck_Timeout(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1"}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
 	assert.NoError(t, err)
@@ -1221,15 +2065,79 @@ func TestHealthCheck_Timeout(t *testing.T) {
 	assert.Equal(t, codes.Unavailable, status.Code(err))
 }
 
+func TestHealthCheck_EmptyGoalCache(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{})
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectPing()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+
+	resp, err := server.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "goal cache has no challenges loaded")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthCheck_AdditionalComponents(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "challenge1"}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+	server.AddHealthComponents(HealthComponent{
+		Name:  "optional",
+		Check: func(ctx context.Context) error { return errors.New("optional dependency down") },
+	})
+
+	// A failing non-critical component degrades the status but keeps the service available
+	mock.ExpectPing()
+	resp, err := server.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "degraded", resp.Status)
+	assert.Len(t, resp.Components, 3)
+	assert.Equal(t, "optional", resp.Components[2].Name)
+	assert.Equal(t, "unhealthy", resp.Components[2].Status)
+	assert.Equal(t, "optional dependency down", resp.Components[2].Message)
+
+	// A failing critical component makes the service unavailable
+	server.AddHealthComponents(HealthComponent{
+		Name:     "iam_token",
+		Critical: true,
+		Check:    func(ctx context.Context) error { return errors.New("no IAM token available") },
+	})
+	mock.ExpectPing()
+	resp, err = server.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "no IAM token available")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // ============================================================================
 // Tests for BatchSelectGoals
 // ============================================================================
 
 func TestBatchSelectGoals_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1277,7 +2185,8 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	// 5. tx.BatchUpsertGoalActive (activate selected goals)
 	mockTxRepo.On("BatchUpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
-	// 6. tx.Commit
+	// 6. tx.CountActiveGoals and tx.Commit
+	mockTxRepo.On("CountActiveGoals", mock.Anything, "user123", "challenge1").Return(1, nil)
 	mockTxRepo.On("Commit").Return(nil)
 	// 7. tx.Rollback (deferred, expected after commit)
 	mockTxRepo.On("Rollback").Return(nil)
@@ -1287,6 +2196,7 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 		ChallengeId:     "challenge1",
 		GoalIds:         []string{"goal1"},
 		ReplaceExisting: false,
+		ClientContext:   map[string]string{"surface": "challenges_menu"},
 	}
 
 	resp, err := server.BatchSelectGoals(ctx, req)
@@ -1294,6 +2204,7 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "challenge1", resp.ChallengeId)
+	assert.Equal(t, map[string]string{"surface": "challenges_menu"}, resp.ClientContext)
 	assert.Len(t, resp.SelectedGoals, 1)
 	assert.Equal(t, "goal1", resp.SelectedGoals[0].GoalId)
 	assert.Equal(t, "Test Goal", resp.SelectedGoals[0].Name)
@@ -1307,9 +2218,9 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 }
 
 func TestBatchSelectGoals_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1331,9 +2242,9 @@ func TestBatchSelectGoals_NoAuthContext(t *testing.T) {
 }
 
 func TestBatchSelectGoals_MissingChallengeID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1356,9 +2267,9 @@ func TestBatchSelectGoals_MissingChallengeID(t *testing.T) {
 }
 
 func TestBatchSelectGoals_EmptyGoalIds(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1381,9 +2292,9 @@ func TestBatchSelectGoals_EmptyGoalIds(t *testing.T) {
 }
 
 func TestBatchSelectGoals_ServiceError_NotFound(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1414,10 +2325,10 @@ func TestBatchSelectGoals_ServiceError_NotFound(t *testing.T) {
 // ============================================================================
 
 func TestRandomSelectGoals_Success(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1465,7 +2376,8 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 	mockCache.On("GetGoalByID", "goal1").Return(goal)
 	// 5. tx.BatchUpsertGoalActive
 	mockTxRepo.On("BatchUpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
-	// 6. tx.Commit
+	// 6. tx.CountActiveGoals and tx.Commit
+	mockTxRepo.On("CountActiveGoals", mock.Anything, "user123", "challenge1").Return(1, nil)
 	mockTxRepo.On("Commit").Return(nil)
 	// 7. tx.Rollback (deferred)
 	mockTxRepo.On("Rollback").Return(nil)
@@ -1488,6 +2400,8 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 	assert.Equal(t, "Random Goal", resp.SelectedGoals[0].Name)
 	assert.True(t, resp.SelectedGoals[0].IsActive)
 	assert.Equal(t, int32(1), resp.TotalActiveGoals)
+	assert.Equal(t, int32(1), resp.AvailablePoolSize)
+	assert.False(t, resp.Partial)
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -1495,9 +2409,9 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 }
 
 func TestRandomSelectGoals_NoAuthContext(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1519,9 +2433,9 @@ func TestRandomSelectGoals_NoAuthContext(t *testing.T) {
 }
 
 func TestRandomSelectGoals_MissingChallengeID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1544,9 +2458,9 @@ func TestRandomSelectGoals_MissingChallengeID(t *testing.T) {
 }
 
 func TestRandomSelectGoals_InvalidCount(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1569,9 +2483,9 @@ func TestRandomSelectGoals_InvalidCount(t *testing.T) {
 }
 
 func TestRandomSelectGoals_InsufficientGoals(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1625,14 +2539,10 @@ func TestRandomSelectGoals_InsufficientGoals(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// ============================================================================
-// Tests for GetRotationStatus
-// ============================================================================
-
-func TestGetRotationStatus_MissingChallengeID(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+func TestRandomSelectGoals_StrictInsufficientGoals(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1640,50 +2550,51 @@ func TestGetRotationStatus_MissingChallengeID(t *testing.T) {
 
 	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
 
-	ctx := createAuthContext("user123", "test-namespace")
-	req := &pb.GetRotationStatusRequest{
-		ChallengeId: "",
+	challenge := &domain.Challenge{
+		ID:   "challenge1",
+		Name: "Test Challenge",
+		Goals: []*domain.Goal{
+			{ID: "goal1", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 1}, Prerequisites: []string{}},
+			{ID: "goal2", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 1}, Prerequisites: []string{}},
+		},
 	}
 
-	resp, err := server.GetRotationStatus(ctx, req)
-
-	assert.Error(t, err)
-	assert.Nil(t, resp)
-	assert.Equal(t, codes.InvalidArgument, status.Code(err))
-	assert.Contains(t, err.Error(), "challenge_id")
-}
-
-func TestGetRotationStatus_ChallengeNotFound(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
-
-	db, _, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer func() { _ = db.Close() }()
-
-	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
-
-	mockCache.On("GetChallengeByChallengeID", "nonexistent").Return(nil)
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge1", false).
+		Return([]*domain.UserGoalProgress{}, nil)
 
 	ctx := createAuthContext("user123", "test-namespace")
-	req := &pb.GetRotationStatusRequest{
-		ChallengeId: "nonexistent",
+	req := &pb.RandomSelectRequest{
+		ChallengeId: "challenge1",
+		Count:       3,
+		Strict:      true,
 	}
 
-	resp, err := server.GetRotationStatus(ctx, req)
+	resp, err := server.RandomSelectGoals(ctx, req)
 
-	assert.Error(t, err)
 	assert.Nil(t, resp)
-	assert.Equal(t, codes.NotFound, status.Code(err))
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "INSUFFICIENT_GOALS", info.Reason)
+	assert.Equal(t, "3", info.Metadata["requested"])
+	assert.Equal(t, "2", info.Metadata["available_pool_size"])
 
 	mockCache.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
 }
 
-func TestGetRotationStatus_NoRotation(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+// ============================================================================
+// Tests for GetAvailableGoals
+// ============================================================================
+
+func TestGetAvailableGoals_Success(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1691,16 +2602,328 @@ func TestGetRotationStatus_NoRotation(t *testing.T) {
 
 	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
 
-	// Challenge with goals that have no rotation config
 	challenge := &domain.Challenge{
 		ID:   "challenge1",
-		Name: "No Rotation Challenge",
+		Name: "Test Challenge",
 		Goals: []*domain.Goal{
-			{
-				ID:          "goal1",
-				ChallengeID: "challenge1",
-				Name:        "Static Goal",
-				Rotation:    nil, // No rotation
+			{ID: "goal1", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 1}, Prerequisites: []string{}},
+			{ID: "goal2", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 1}, Prerequisites: []string{}},
+			{ID: "goal3", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 1}, Prerequisites: []string{}},
+		},
+	}
+
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge1", false).
+		Return([]*domain.UserGoalProgress{
+			{UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusClaimed},
+			{UserID: "user123", GoalID: "goal2", ChallengeID: "challenge1", Status: domain.GoalStatusInProgress, IsActive: true},
+		}, nil)
+
+	ctx := createAuthContext("user123", "test-namespace")
+	resp, err := server.GetAvailableGoals(ctx, &pb.GetAvailableGoalsRequest{
+		ChallengeId:   "challenge1",
+		ExcludeActive: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "challenge1", resp.ChallengeId)
+	assert.Equal(t, []string{"goal3"}, resp.GoalIds)
+	assert.Equal(t, int32(1), resp.AvailablePoolSize)
+
+	mockCache.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAvailableGoals_MissingChallengeID(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	ctx := createAuthContext("user123", "test-namespace")
+	resp, err := server.GetAvailableGoals(ctx, &pb.GetAvailableGoalsRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetAvailableGoals_ChallengeNotFound(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "missing").Return(nil)
+
+	ctx := createAuthContext("user123", "test-namespace")
+	resp, err := server.GetAvailableGoals(ctx, &pb.GetAvailableGoalsRequest{ChallengeId: "missing"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// ============================================================================
+// Tests for GetChallenge
+// ============================================================================
+
+func TestGetChallenge_Success(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	newGoal := func(id string, prerequisites ...string) *domain.Goal {
+		return &domain.Goal{
+			ID:            id,
+			ChallengeID:   "challenge1",
+			Name:          id,
+			Requirement:   domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:        domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+			EventSource:   domain.EventSourceStatistic,
+			Prerequisites: prerequisites,
+		}
+	}
+	challenge := &domain.Challenge{
+		ID:    "challenge1",
+		Name:  "Test Challenge",
+		Goals: []*domain.Goal{newGoal("intro"), newGoal("secret", "intro")},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.SetHiddenGoals(service.HiddenGoals{"secret": true})
+
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge1", true).Return([]*domain.UserGoalProgress{}, nil)
+	// The claimed prerequisite is inactive, so it is loaded separately
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"intro"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "intro", ChallengeID: "challenge1", Progress: 10, Status: domain.GoalStatusClaimed},
+	}, nil)
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{
+		ChallengeId: "challenge1",
+		ActiveOnly:  true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "challenge1", resp.Challenge.ChallengeId)
+	require.Len(t, resp.Challenge.Goals, 2)
+	assert.Equal(t, "secret", resp.Challenge.Goals[1].GoalId)
+	assert.False(t, resp.Challenge.Goals[1].Activatable, "active_only leaves activatable unset")
+	mockCache.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetChallenge_NextReset(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// The daily goal is hidden and locked; the reset is still the challenge's
+	challenge := &domain.Challenge{ID: "challenge1", Goals: []*domain.Goal{
+		{ID: "weekly", ChallengeID: "challenge1", Reward: domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}, Rotation: &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleWeekly}},
+		{ID: "daily", ChallengeID: "challenge1", Reward: domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}, Prerequisites: []string{"weekly"}, Rotation: &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}},
+	}}
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge1", true).Return([]*domain.UserGoalProgress{}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"weekly"}).Return([]*domain.UserGoalProgress{}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	server.SetHiddenGoals(service.HiddenGoals{"daily": true})
+	server.now = func() time.Time { return time.Date(2025, 3, 4, 22, 30, 0, 0, time.UTC) }
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{
+		ChallengeId: "challenge1",
+		ActiveOnly:  true,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenge.Goals, 1)
+	assert.Equal(t, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), resp.Challenge.NextResetAt.AsTime())
+	assert.Equal(t, int32(90*60), resp.Challenge.SecondsRemaining)
+}
+
+func TestGetChallenge_Activatable(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	newGoal := func(id string, prerequisites ...string) *domain.Goal {
+		return &domain.Goal{
+			ID:            id,
+			ChallengeID:   "challenge2",
+			Name:          id,
+			Requirement:   domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:        domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+			EventSource:   domain.EventSourceStatistic,
+			Prerequisites: prerequisites,
+		}
+	}
+	challenge := &domain.Challenge{
+		ID:    "challenge2",
+		Name:  "Follow-up Challenge",
+		Goals: []*domain.Goal{newGoal("tracked"), newGoal("next", "challenge1-final"), newGoal("after-next", "next")},
+	}
+	assignedAt := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "challenge2").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge2", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "tracked", ChallengeID: "challenge2", Status: domain.GoalStatusNotStarted, IsActive: true, AssignedAt: &assignedAt},
+	}, nil)
+	// The prerequisite from another challenge is loaded; "next" is known to have no row
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"challenge1-final"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "challenge1-final", ChallengeID: "challenge1", Status: domain.GoalStatusClaimed},
+	}, nil).Once()
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{ChallengeId: "challenge2"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenge.Goals, 3)
+	tracked, next, afterNext := resp.Challenge.Goals[0], resp.Challenge.Goals[1], resp.Challenge.Goals[2]
+	assert.True(t, tracked.IsActive)
+	assert.Equal(t, assignedAt, tracked.AssignedAt.AsTime())
+	assert.False(t, tracked.Activatable, "already active")
+	assert.True(t, next.Activatable)
+	assert.Nil(t, next.AssignedAt)
+	assert.False(t, afterNext.Activatable, "prerequisite not completed")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetChallenge_MissingChallengeID(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetChallenge_ChallengeNotFound(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "nonexistent").Return(nil)
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{ChallengeId: "nonexistent"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetChallenge_DatabaseError(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "challenge1").Return(&domain.Challenge{ID: "challenge1"})
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge1", false).Return(nil, errors.New("connection refused"))
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{ChallengeId: "challenge1"})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+// ============================================================================
+// Tests for GetRotationStatus
+// ============================================================================
+
+func TestGetRotationStatus_MissingChallengeID(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+
+	ctx := createAuthContext("user123", "test-namespace")
+	req := &pb.GetRotationStatusRequest{
+		ChallengeId: "",
+	}
+
+	resp, err := server.GetRotationStatus(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "challenge_id")
+}
+
+func TestGetRotationStatus_ChallengeNotFound(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "nonexistent").Return(nil)
+
+	ctx := createAuthContext("user123", "test-namespace")
+	req := &pb.GetRotationStatusRequest{
+		ChallengeId: "nonexistent",
+	}
+
+	resp, err := server.GetRotationStatus(ctx, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockCache.AssertExpectations(t)
+}
+
+func TestGetRotationStatus_NoRotation(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, db, "test-namespace")
+
+	// Challenge with goals that have no rotation config
+	challenge := &domain.Challenge{
+		ID:   "challenge1",
+		Name: "No Rotation Challenge",
+		Goals: []*domain.Goal{
+			{
+				ID:          "goal1",
+				ChallengeID: "challenge1",
+				Name:        "Static Goal",
+				Rotation:    nil, // No rotation
 			},
 		},
 	}
@@ -1724,9 +2947,9 @@ func TestGetRotationStatus_NoRotation(t *testing.T) {
 }
 
 func TestGetRotationStatus_WithRotation(t *testing.T) {
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -1822,8 +3045,8 @@ func TestBuildRotationInfo_DailySchedule(t *testing.T) {
 	assert.NotNil(t, info.CurrentPeriod)
 	expectedStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
 	expectedEnd := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
-	assert.Equal(t, expectedStart.Format(time.RFC3339), info.CurrentPeriod.StartTime)
-	assert.Equal(t, expectedEnd.Format(time.RFC3339), info.CurrentPeriod.EndTime)
+	assert.Equal(t, expectedStart, info.CurrentPeriod.StartTime.AsTime())
+	assert.Equal(t, expectedEnd, info.CurrentPeriod.EndTime.AsTime())
 
 	// ExpiresInSeconds should be 12 hours (43200 seconds)
 	assert.Equal(t, int32(43200), info.CurrentPeriod.ExpiresInSeconds)
@@ -1832,8 +3055,8 @@ func TestBuildRotationInfo_DailySchedule(t *testing.T) {
 	assert.NotNil(t, info.NextPeriod)
 	expectedNextStart := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
 	expectedNextEnd := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
-	assert.Equal(t, expectedNextStart.Format(time.RFC3339), info.NextPeriod.StartTime)
-	assert.Equal(t, expectedNextEnd.Format(time.RFC3339), info.NextPeriod.EndTime)
+	assert.Equal(t, expectedNextStart, info.NextPeriod.StartTime.AsTime())
+	assert.Equal(t, expectedNextEnd, info.NextPeriod.EndTime.AsTime())
 }
 
 // ============================================================================
@@ -1872,6 +3095,7 @@ func TestSelectedGoalToProto_FullConversion(t *testing.T) {
 		IsActive:   true,
 		AssignedAt: &assignedAt,
 		ExpiresAt:  &expiresAt,
+		Changed:    true,
 	}
 
 	result, err := selectedGoalToProto(goal)
@@ -1885,8 +3109,9 @@ func TestSelectedGoalToProto_FullConversion(t *testing.T) {
 	assert.Equal(t, int32(3), result.Progress)
 	assert.Equal(t, int32(10), result.Target)
 	assert.True(t, result.IsActive)
-	assert.Equal(t, assignedAt.Format(time.RFC3339), result.AssignedAt)
-	assert.Equal(t, expiresAt.Format(time.RFC3339), result.ExpiresAt)
+	assert.True(t, result.Changed)
+	assert.Equal(t, assignedAt, result.AssignedAt.AsTime())
+	assert.Equal(t, expiresAt, result.ExpiresAt.AsTime())
 
 	// Verify requirement was converted
 	assert.NotNil(t, result.Requirement)
@@ -1900,3 +3125,1789 @@ func TestSelectedGoalToProto_FullConversion(t *testing.T) {
 	assert.Equal(t, "sword", result.Reward.RewardId)
 	assert.Equal(t, int32(1), result.Reward.Quantity)
 }
+
+func TestReloadConfig_NotEnabled(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	resp, err := server.ReloadConfig(createAuthContext("admin-1", "test-namespace"), &pb.ReloadConfigRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGetConfigSchema(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	resp, err := server.GetConfigSchema(createAuthContext("admin-1", "test-namespace"), &pb.GetConfigSchemaRequest{})
+
+	require.NoError(t, err)
+	schema := resp.Schema.AsMap()
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	assert.Contains(t, schema["definitions"], "goal")
+}
+
+func TestConfigDiffToProto(t *testing.T) {
+	diff := &service.ConfigDiff{
+		ChallengesAdded:    []string{"c2"},
+		ChallengesModified: []service.ChallengeChange{{ChallengeID: "c1", Fields: []service.FieldChange{{Field: "name", Old: "A", New: "B"}}}},
+		GoalsRemoved:       []string{"g9"},
+		GoalsModified: []service.GoalChange{{
+			GoalID:             "g1",
+			ChallengeID:        "c1",
+			Fields:             []service.FieldChange{{Field: "reward.quantity", Old: "100", New: "500"}},
+			UnclaimedCompleted: 3,
+		}},
+		Warnings: []string{"reward changed"},
+	}
+
+	result := configDiffToProto(diff)
+
+	assert.Equal(t, []string{"c2"}, result.ChallengesAdded)
+	assert.Equal(t, []string{"g9"}, result.GoalsRemoved)
+	require.Len(t, result.ChallengesModified, 1)
+	assert.Equal(t, "B", result.ChallengesModified[0].Fields[0].NewValue)
+	require.Len(t, result.GoalsModified, 1)
+	assert.Equal(t, int32(3), result.GoalsModified[0].UnclaimedCompleted)
+	assert.Equal(t, "reward.quantity", result.GoalsModified[0].Fields[0].Field)
+	assert.Equal(t, "100", result.GoalsModified[0].Fields[0].OldValue)
+	assert.Equal(t, []string{"reward changed"}, result.Warnings)
+}
+
+func TestGetClaimCap(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectQuery(`FROM user_claim_events`).
+		WithArgs("player-1", "test-namespace", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(4, time.Now().UTC().Add(-23*time.Hour)))
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+	server.SetClaimCap(service.NewClaimCap(serviceRepo.NewPostgresClaimCounterRepository(db), 10, logrus.StandardLogger()))
+
+	resp, err := server.GetClaimCap(createAuthContext("admin-1", "test-namespace"), &pb.GetClaimCapRequest{UserId: "player-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "player-1", resp.UserId)
+	assert.True(t, resp.Enabled)
+	assert.Equal(t, int32(10), resp.Limit)
+	assert.Equal(t, int32(4), resp.ClaimsInWindow)
+	assert.InDelta(t, 3600, resp.RetryAfterSeconds, 5)
+}
+
+func TestResetClaimCap(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectExec(`DELETE FROM user_claim_events`).
+		WithArgs("player-1", "test-namespace").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+	server.SetClaimCap(service.NewClaimCap(serviceRepo.NewPostgresClaimCounterRepository(db), 10, logrus.StandardLogger()))
+
+	resp, err := server.ResetClaimCap(createAuthContext("admin-1", "test-namespace"), &pb.ResetClaimCapRequest{UserId: "player-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), resp.ClaimsCleared)
+
+	_, err = server.ResetClaimCap(createAuthContext("admin-1", "test-namespace"), &pb.ResetClaimCapRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClaimCapRPCs_NotConfigured(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.GetClaimCap(ctx, &pb.GetClaimCapRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.ResetClaimCap(ctx, &pb.ResetClaimCapRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestClaimGoalReward_ClaimFrozen(t *testing.T) {
+	until := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	freezeRepo := new(mocks.ClaimFreezeRepository)
+	freezeRepo.On("GetClaimFreeze", mock.Anything, "user123", "test-namespace", mock.Anything).
+		Return(&serviceRepo.ClaimFreeze{UserID: "user123", FrozenUntil: until}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), mockRepo, mockRewardClient, nil, "test-namespace")
+	server.SetClaimFreezes(service.NewClaimFreezes(freezeRepo, logrus.StandardLogger()))
+
+	resp, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), until.Format(time.RFC3339))
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	allResp, err := server.ClaimAllCompleted(createAuthContext("user123", "test-namespace"), &pb.ClaimAllCompletedRequest{})
+
+	assert.Nil(t, allResp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	mockRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetClaimFreeze(t *testing.T) {
+	until := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+	freezeRepo := new(mocks.ClaimFreezeRepository)
+	freezeRepo.On("SetClaimFreeze", mock.Anything, mock.MatchedBy(func(freeze *serviceRepo.ClaimFreeze) bool {
+		return freeze.UserID == "player-1" && freeze.Namespace == "test-namespace" &&
+			freeze.FrozenUntil.Equal(until) && freeze.Reason == "anti-cheat flag" && freeze.ActorUserID == "admin-1"
+	}), "").Return(nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetClaimFreezes(service.NewClaimFreezes(freezeRepo, logrus.StandardLogger()))
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.SetClaimFreeze(ctx, &pb.SetClaimFreezeRequest{
+		UserId: "player-1",
+		Until:  timestamppb.New(until),
+		Reason: "anti-cheat flag",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "player-1", resp.UserId)
+	assert.Equal(t, until, resp.FrozenUntil.AsTime())
+	assert.Equal(t, "admin-1", resp.ActorUserId)
+	freezeRepo.AssertExpectations(t)
+
+	invalid := []*pb.SetClaimFreezeRequest{
+		{Until: timestamppb.New(until), Reason: "r"},
+		{UserId: "player-1", Reason: "r"},
+		{UserId: "player-1", Until: timestamppb.New(time.Now().Add(-time.Minute)), Reason: "r"},
+		{UserId: "player-1", Until: timestamppb.New(until), Reason: " "},
+	}
+	for _, req := range invalid {
+		_, err := server.SetClaimFreeze(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestRemoveClaimFreeze(t *testing.T) {
+	freezeRepo := new(mocks.ClaimFreezeRepository)
+	freezeRepo.On("RemoveClaimFreeze", mock.Anything, "player-1", "test-namespace", "admin-1", "false positive", "", mock.Anything).
+		Return(&serviceRepo.ClaimFreeze{UserID: "player-1", FrozenUntil: time.Now().Add(time.Hour)}, nil)
+	freezeRepo.On("RemoveClaimFreeze", mock.Anything, "player-2", "test-namespace", "admin-1", "", "", mock.Anything).
+		Return(nil, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetClaimFreezes(service.NewClaimFreezes(freezeRepo, logrus.StandardLogger()))
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.RemoveClaimFreeze(ctx, &pb.RemoveClaimFreezeRequest{UserId: "player-1", Reason: "false positive"})
+	require.NoError(t, err)
+	assert.True(t, resp.Removed)
+
+	resp, err = server.RemoveClaimFreeze(ctx, &pb.RemoveClaimFreezeRequest{UserId: "player-2"})
+	require.NoError(t, err)
+	assert.False(t, resp.Removed)
+}
+
+func TestListClaimFreezes(t *testing.T) {
+	until := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	freezeRepo := new(mocks.ClaimFreezeRepository)
+	freezeRepo.On("ListClaimFreezes", mock.Anything, "test-namespace", mock.Anything, 2).
+		Return([]*serviceRepo.ClaimFreeze{
+			{UserID: "player-1", FrozenUntil: until},
+			{UserID: "player-2", FrozenUntil: until.Add(time.Hour)},
+		}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetClaimFreezes(service.NewClaimFreezes(freezeRepo, logrus.StandardLogger()))
+
+	resp, err := server.ListClaimFreezes(createAuthContext("admin-1", "test-namespace"), &pb.ListClaimFreezesRequest{Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Freezes, 1)
+	assert.Equal(t, "player-1", resp.Freezes[0].UserId)
+	assert.True(t, resp.Truncated)
+}
+
+func TestClaimFreezeRPCs_NotConfigured(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.SetClaimFreeze(ctx, &pb.SetClaimFreezeRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.ListClaimFreezes(ctx, &pb.ListClaimFreezesRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.RemoveClaimFreeze(ctx, &pb.RemoveClaimFreezeRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// newFailedGrantTestServer returns a server whose goal1 of challenge1 is completed
+// and whose AGS grant of its reward returns grantErr.
+func newFailedGrantTestServer(grantErr error, failedGrantRepo *mocks.FailedGrantRepository) *ChallengeServiceServer {
+	goal := &domain.Goal{
+		ID:          "goal1",
+		ChallengeID: "challenge1",
+		Requirement: domain.Requirement{TargetValue: 1},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "player-1", "goal1").Return(&domain.UserGoalProgress{
+		UserID: "player-1", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusCompleted, IsActive: true,
+	}, nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "player-1", "goal1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "player-1", goal.Reward).Return(grantErr)
+
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "player-1", "goal1").Return(nil)
+	outbox.On("Delete", mock.Anything, "player-1", "goal1").Return(nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, nil, "test-namespace")
+	server.SetClaimOutbox(outbox)
+	server.SetFailedGrants(service.NewFailedGrants(failedGrantRepo, logrus.StandardLogger()))
+	return server
+}
+
+func TestClaimGoalReward_PermanentGrantFailure(t *testing.T) {
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("RecordFailedGrant", mock.Anything, mock.MatchedBy(func(grant *serviceRepo.FailedGrant) bool {
+		return grant.UserID == "player-1" && grant.GoalID == "goal1" && grant.ChallengeID == "challenge1" &&
+			grant.Namespace == "test-namespace" && grant.RewardID == "sword" && grant.ErrorClass == service.GrantErrorClassNotFound
+	})).Return(nil)
+
+	server := newFailedGrantTestServer(&commonClient.NotFoundError{Resource: "item sword"}, failedGrantRepo)
+
+	resp, err := server.ClaimGoalReward(createAuthContext("player-1", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	assert.Nil(t, resp)
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "contact support")
+	if assert.Len(t, st.Details(), 1) {
+		assert.Equal(t, "REWARD_GRANT_FAILED_PERMANENTLY", st.Details()[0].(*errdetails.ErrorInfo).Reason)
+	}
+	failedGrantRepo.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_SaturatedWorkerPool(t *testing.T) {
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("RecordFailedGrant", mock.Anything, mock.Anything).Return(nil)
+
+	server := newFailedGrantTestServer(&commonClient.NotFoundError{Resource: "item sword"}, failedGrantRepo)
+	// No workers and no queue: every background task is rejected
+	server.SetWorkerPool(common.NewWorkerPool(common.WorkerPoolConfig{}))
+
+	_, err := server.ClaimGoalReward(createAuthContext("player-1", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	// The dead letter is critical, so it is written inline instead of dropped
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	failedGrantRepo.AssertNumberOfCalls(t, "RecordFailedGrant", 1)
+}
+
+func TestClaimGoalReward_RetryableGrantFailureNotRecorded(t *testing.T) {
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	server := newFailedGrantTestServer(&commonClient.ForbiddenError{Message: "namespace mismatch"}, failedGrantRepo)
+
+	_, err := server.ClaimGoalReward(createAuthContext("player-1", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	failedGrantRepo.AssertNotCalled(t, "RecordFailedGrant", mock.Anything, mock.Anything)
+}
+
+func TestRetryFailedGrant(t *testing.T) {
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("GetFailedGrant", mock.Anything, "test-namespace", int64(7)).Return(&serviceRepo.FailedGrant{
+		ID: 7, UserID: "player-1", GoalID: "goal1", ChallengeID: "challenge1", Attempts: 2,
+	}, nil)
+	failedGrantRepo.On("GetFailedGrant", mock.Anything, "test-namespace", int64(8)).Return(nil, nil)
+	failedGrantRepo.On("DeleteFailedGrant", mock.Anything, "player-1", "goal1").Return(nil).Once()
+
+	server := newFailedGrantTestServer(nil, failedGrantRepo)
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.RetryFailedGrant(ctx, &pb.RetryFailedGrantRequest{Id: 7})
+
+	require.NoError(t, err)
+	assert.Equal(t, "player-1", resp.UserId)
+	assert.Equal(t, "goal1", resp.GoalId)
+	assert.Equal(t, "claimed", resp.Status)
+	assert.Equal(t, "sword", resp.Reward.RewardId)
+
+	_, err = server.RetryFailedGrant(ctx, &pb.RetryFailedGrantRequest{Id: 8})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.RetryFailedGrant(ctx, &pb.RetryFailedGrantRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	failedGrantRepo.AssertExpectations(t)
+}
+
+func TestRetryFailedGrant_FailsAgain(t *testing.T) {
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("GetFailedGrant", mock.Anything, "test-namespace", int64(7)).Return(&serviceRepo.FailedGrant{
+		ID: 7, UserID: "player-1", GoalID: "goal1", ChallengeID: "challenge1",
+	}, nil)
+	failedGrantRepo.On("RecordFailedGrant", mock.Anything, mock.Anything).Return(nil).Once()
+
+	server := newFailedGrantTestServer(&commonClient.NotFoundError{Resource: "item sword"}, failedGrantRepo)
+
+	_, err := server.RetryFailedGrant(createAuthContext("admin-1", "test-namespace"), &pb.RetryFailedGrantRequest{Id: 7})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	failedGrantRepo.AssertExpectations(t)
+	failedGrantRepo.AssertNotCalled(t, "DeleteFailedGrant", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListFailedGrants(t *testing.T) {
+	failedAt := time.Now().UTC().Truncate(time.Second)
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("ListFailedGrants", mock.Anything, "test-namespace", 2).
+		Return([]*serviceRepo.FailedGrant{
+			{ID: 2, UserID: "player-2", GoalID: "goal1", RewardType: "ITEM", RewardID: "sword", RewardQuantity: 1,
+				ErrorClass: "not_found", Attempts: 3, FirstFailedAt: failedAt.Add(-time.Hour), LastFailedAt: failedAt},
+			{ID: 1, UserID: "player-1", GoalID: "goal1"},
+		}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetFailedGrants(service.NewFailedGrants(failedGrantRepo, logrus.StandardLogger()))
+
+	resp, err := server.ListFailedGrants(createAuthContext("admin-1", "test-namespace"), &pb.ListFailedGrantsRequest{Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, resp.FailedGrants, 1)
+	assert.True(t, resp.Truncated)
+	grant := resp.FailedGrants[0]
+	assert.Equal(t, int64(2), grant.Id)
+	assert.Equal(t, "player-2", grant.UserId)
+	assert.Equal(t, "sword", grant.Reward.RewardId)
+	assert.Equal(t, "not_found", grant.ErrorClass)
+	assert.Equal(t, int32(3), grant.Attempts)
+	assert.Equal(t, failedAt, grant.LastFailedAt.AsTime())
+}
+
+func TestFailedGrantRPCs_NotConfigured(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.ListFailedGrants(ctx, &pb.ListFailedGrantsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.RetryFailedGrant(ctx, &pb.RetryFailedGrantRequest{Id: 1})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+// newGrantNamespaceTestServer returns a server whose goal1 claim of player-1
+// succeeds, with grantable namespace "other-game".
+func newGrantNamespaceTestServer() (*ChallengeServiceServer, *mocks.RewardClient, *mocks.ClaimOutboxRepository) {
+	goal := &domain.Goal{
+		ID:          "goal1",
+		ChallengeID: "challenge1",
+		Requirement: domain.Requirement{TargetValue: 1},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "player-1", "goal1").Return(&domain.UserGoalProgress{
+		UserID: "player-1", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusCompleted, IsActive: true,
+	}, nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "player-1", "goal1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	rewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "player-1", "goal1").Return(nil)
+	outbox.On("Delete", mock.Anything, "player-1", "goal1").Return(nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, rewardClient, nil, "test-namespace")
+	server.SetClaimOutbox(outbox)
+	server.SetGrantNamespaces(service.NewGrantNamespaces("test-namespace", []string{"other-game"}))
+	return server, rewardClient, outbox
+}
+
+func TestClaimGoalReward_GrantNamespace(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokenNamespace string
+		grantNamespace string
+	}{
+		{name: "service namespace", tokenNamespace: "test-namespace", grantNamespace: "test-namespace"},
+		{name: "allow-listed namespace", tokenNamespace: "other-game", grantNamespace: "other-game"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, rewardClient, outbox := newGrantNamespaceTestServer()
+			rewardClient.On("GrantReward", mock.Anything, tt.grantNamespace, "player-1", mock.Anything).Return(nil).Once()
+
+			_, err := server.ClaimGoalReward(createAuthContext("player-1", tt.tokenNamespace), &pb.ClaimRewardRequest{
+				ChallengeId: "challenge1",
+				GoalId:      "goal1",
+			})
+
+			require.NoError(t, err)
+			rewardClient.AssertExpectations(t)
+			outbox.AssertCalled(t, "Reserve", mock.Anything, mock.MatchedBy(func(entry *serviceRepo.ClaimOutboxEntry) bool {
+				return entry.Namespace == tt.grantNamespace
+			}))
+		})
+	}
+}
+
+func TestClaimGoalReward_GrantNamespaceNotAllowed(t *testing.T) {
+	server, rewardClient, outbox := newGrantNamespaceTestServer()
+
+	_, err := server.ClaimGoalReward(createAuthContext("player-1", "unknown-game"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.ClaimAllCompleted(createAuthContext("player-1", "unknown-game"), &pb.ClaimAllCompletedRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	rewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_Delivery(t *testing.T) {
+	deliveries, err := service.ParseRewardDeliveries([]byte(`{
+		"rewardDelivery": {"allowedCollectionIds": ["season-stash", "main"]},
+		"challenges": [{"goals": [{"goalId": "goal1", "delivery": {"collectionId": "season-stash"}}]}]
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		delivery *pb.RewardDelivery
+		want     string
+	}{
+		{name: "goal default", want: "season-stash"},
+		{name: "requested", delivery: &pb.RewardDelivery{CollectionId: "main"}, want: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, rewardClient, _ := newGrantNamespaceTestServer()
+			server.SetRewardDeliveries(deliveries)
+			rewardClient.On("GrantReward", mock.MatchedBy(func(ctx context.Context) bool {
+				delivery, _ := agsClient.RewardDeliveryFrom(ctx)
+				return delivery.CollectionID == tt.want
+			}), "test-namespace", "player-1", mock.Anything).Return(nil).Once()
+
+			_, err := server.ClaimGoalReward(createAuthContext("player-1", "test-namespace"), &pb.ClaimRewardRequest{
+				ChallengeId: "challenge1",
+				GoalId:      "goal1",
+				Delivery:    tt.delivery,
+			})
+
+			require.NoError(t, err)
+			rewardClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClaimGoalReward_DeliveryNotAllowed(t *testing.T) {
+	server, rewardClient, outbox := newGrantNamespaceTestServer()
+
+	_, err := server.ClaimGoalReward(createAuthContext("player-1", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+		Delivery:    &pb.RewardDelivery{CollectionId: "season-stash"},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	rewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+}
+
+func TestRetryFailedGrant_GrantNamespace(t *testing.T) {
+	server, rewardClient, _ := newGrantNamespaceTestServer()
+	failedGrantRepo := new(mocks.FailedGrantRepository)
+	failedGrantRepo.On("GetFailedGrant", mock.Anything, "test-namespace", int64(7)).Return(&serviceRepo.FailedGrant{
+		ID: 7, UserID: "player-1", GoalID: "goal1", ChallengeID: "challenge1", Namespace: "test-namespace", GrantNamespace: "other-game",
+	}, nil)
+	failedGrantRepo.On("DeleteFailedGrant", mock.Anything, "player-1", "goal1").Return(nil)
+	server.SetFailedGrants(service.NewFailedGrants(failedGrantRepo, logrus.StandardLogger()))
+	rewardClient.On("GrantReward", mock.Anything, "other-game", "player-1", mock.Anything).Return(nil).Once()
+
+	// The admin's token namespace is not the player's
+	_, err := server.RetryFailedGrant(createAuthContext("admin-1", "test-namespace"), &pb.RetryFailedGrantRequest{Id: 7})
+
+	require.NoError(t, err)
+	rewardClient.AssertExpectations(t)
+}
+
+func TestBackfillDefaultGoal(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "new-goal").Return(&domain.Goal{ID: "new-goal", DefaultAssigned: true, Requirement: domain.Requirement{TargetValue: 1}})
+	goalCache.On("GetGoalByID", "optional-goal").Return(&domain.Goal{ID: "optional-goal"})
+	goalCache.On("GetGoalByID", "missing").Return(nil)
+	backfillRepo := new(mocks.BackfillRepository)
+	backfillRepo.On("GetBackfillJob", mock.Anything, "new-goal", "test-namespace").Return(nil, nil)
+	backfillRepo.On("SaveBackfillJob", mock.Anything, mock.MatchedBy(func(job *serviceRepo.BackfillJob) bool {
+		return job.GoalID == "new-goal" && job.ActorUserID == "admin-1"
+	})).Return(nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetBackfills(service.NewBackfills(backfillRepo, goalCache, "test-namespace", service.BackfillConfig{}, logrus.StandardLogger()))
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.BackfillDefaultGoal(ctx, &pb.BackfillDefaultGoalRequest{GoalId: "new-goal"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "running", resp.Status)
+	assert.Equal(t, "admin-1", resp.ActorUserId)
+	assert.NotNil(t, resp.StartedAt)
+	assert.Nil(t, resp.CompletedAt)
+	backfillRepo.AssertExpectations(t)
+
+	errorCases := map[string]codes.Code{
+		"":              codes.InvalidArgument,
+		"missing":       codes.NotFound,
+		"optional-goal": codes.FailedPrecondition,
+	}
+	for goalID, want := range errorCases {
+		_, err := server.BackfillDefaultGoal(ctx, &pb.BackfillDefaultGoalRequest{GoalId: goalID})
+		assert.Equal(t, want, status.Code(err), goalID)
+	}
+}
+
+func TestGetBackfillJob(t *testing.T) {
+	completedAt := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	backfillRepo := new(mocks.BackfillRepository)
+	backfillRepo.On("GetBackfillJob", mock.Anything, "new-goal", "test-namespace").Return(&serviceRepo.BackfillJob{
+		GoalID:       "new-goal",
+		Status:       serviceRepo.BackfillStatusCompleted,
+		LastUserID:   "user-9",
+		UsersScanned: 9,
+		UsersSkipped: 2,
+		RowsInserted: 7,
+		StartedAt:    completedAt.Add(-time.Hour),
+		UpdatedAt:    completedAt,
+		CompletedAt:  &completedAt,
+	}, nil)
+	backfillRepo.On("GetBackfillJob", mock.Anything, "other-goal", "test-namespace").Return(nil, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetBackfills(service.NewBackfills(backfillRepo, new(mocks.GoalCache), "test-namespace", service.BackfillConfig{}, logrus.StandardLogger()))
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.GetBackfillJob(ctx, &pb.GetBackfillJobRequest{GoalId: "new-goal"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", resp.Status)
+	assert.Equal(t, "user-9", resp.LastUserId)
+	assert.Equal(t, int64(7), resp.RowsInserted)
+	assert.Equal(t, completedAt, resp.CompletedAt.AsTime())
+
+	_, err = server.GetBackfillJob(ctx, &pb.GetBackfillJobRequest{GoalId: "other-goal"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestBulkActivateGoal(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "event-goal").Return(&domain.Goal{ID: "event-goal", ChallengeID: "c1", Requirement: domain.Requirement{TargetValue: 1}})
+	goalCache.On("GetGoalByID", "missing").Return(nil)
+	bulkRepo := new(mocks.BulkActivationRepository)
+	bulkRepo.On("CreateBulkActivationJob", mock.Anything, mock.MatchedBy(func(job *serviceRepo.BulkActivationJob) bool {
+		return job.GoalID == "event-goal" && job.ActorUserID == "admin-1" && job.Reason == "tournament"
+	}), []string{"user-1", "user-2"}).Run(func(args mock.Arguments) {
+		args.Get(1).(*serviceRepo.BulkActivationJob).ID = 7
+	}).Return(nil)
+	bulkRepo.On("CountBulkActivation", mock.Anything, "event-goal", []string{"user-1", "user-2"}).
+		Return(&serviceRepo.BulkActivationCounts{Activated: 1, Claimed: 1}, nil)
+	bulkRepo.On("GetBulkActivationJob", mock.Anything, int64(8), "test-namespace").Return(nil, nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetBulkActivations(service.NewBulkActivations(bulkRepo, goalCache, "test-namespace", service.BackfillConfig{}, logrus.StandardLogger()))
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.BulkActivateGoal(ctx, &pb.BulkActivateGoalRequest{
+		GoalId:  "event-goal",
+		UserIds: []string{"user-2", "user-1", "user-2"},
+		Reason:  "tournament",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp.JobId)
+	assert.Equal(t, "running", resp.Status)
+	assert.Equal(t, int64(2), resp.UsersTotal)
+	assert.False(t, resp.DryRun)
+
+	resp, err = server.BulkActivateGoal(ctx, &pb.BulkActivateGoalRequest{
+		GoalId:  "event-goal",
+		UserIds: []string{"user-1", "user-2"},
+		Reason:  "tournament",
+		DryRun:  true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), resp.JobId)
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, "completed", resp.Status)
+	assert.Equal(t, int64(1), resp.UsersActivated)
+	assert.Equal(t, int64(1), resp.UsersClaimed)
+	bulkRepo.AssertNumberOfCalls(t, "CreateBulkActivationJob", 1)
+
+	errorCases := map[string]struct {
+		req  *pb.BulkActivateGoalRequest
+		want codes.Code
+	}{
+		"no goal":        {&pb.BulkActivateGoalRequest{UserIds: []string{"user-1"}, Reason: "r"}, codes.InvalidArgument},
+		"no reason":      {&pb.BulkActivateGoalRequest{GoalId: "event-goal", UserIds: []string{"user-1"}}, codes.InvalidArgument},
+		"no users":       {&pb.BulkActivateGoalRequest{GoalId: "event-goal", Reason: "r"}, codes.InvalidArgument},
+		"empty user":     {&pb.BulkActivateGoalRequest{GoalId: "event-goal", UserIds: []string{""}, Reason: "r"}, codes.InvalidArgument},
+		"too many users": {&pb.BulkActivateGoalRequest{GoalId: "event-goal", UserIds: make([]string, service.MaxBulkActivationUsers+1), Reason: "r"}, codes.InvalidArgument},
+		"missing goal":   {&pb.BulkActivateGoalRequest{GoalId: "missing", UserIds: []string{"user-1"}, Reason: "r"}, codes.NotFound},
+		"resume users":   {&pb.BulkActivateGoalRequest{JobId: 8, UserIds: []string{"user-1"}}, codes.InvalidArgument},
+		"resume missing": {&pb.BulkActivateGoalRequest{JobId: 8}, codes.NotFound},
+	}
+	for name, tc := range errorCases {
+		_, err := server.BulkActivateGoal(ctx, tc.req)
+		assert.Equal(t, tc.want, status.Code(err), name)
+	}
+
+	_, err = server.BulkActivateGoal(ctx, &pb.BulkActivateGoalRequest{
+		GoalId:  "event-goal",
+		UserIds: []string{"user-1", "user-2"},
+		Segment: "new-players",
+		Reason:  "tournament",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "pass them as user_ids")
+	bulkRepo.AssertNumberOfCalls(t, "CreateBulkActivationJob", 1)
+}
+
+func TestGetBulkActivationJob(t *testing.T) {
+	completedAt := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	bulkRepo := new(mocks.BulkActivationRepository)
+	bulkRepo.On("GetBulkActivationJob", mock.Anything, int64(7), "test-namespace").Return(&serviceRepo.BulkActivationJob{
+		ID:             7,
+		GoalID:         "event-goal",
+		Status:         serviceRepo.BackfillStatusCompleted,
+		UsersTotal:     9,
+		UsersProcessed: 9,
+		BulkActivationCounts: serviceRepo.BulkActivationCounts{
+			Activated:     6,
+			AlreadyActive: 2,
+			Claimed:       1,
+		},
+		Reason:      "tournament",
+		StartedAt:   completedAt.Add(-time.Hour),
+		UpdatedAt:   completedAt,
+		CompletedAt: &completedAt,
+	}, nil)
+	bulkRepo.On("GetBulkActivationJob", mock.Anything, int64(8), "test-namespace").Return(nil, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.GetBulkActivationJob(ctx, &pb.GetBulkActivationJobRequest{JobId: 7})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "not configured")
+
+	server.SetBulkActivations(service.NewBulkActivations(bulkRepo, new(mocks.GoalCache), "test-namespace", service.BackfillConfig{}, logrus.StandardLogger()))
+
+	resp, err := server.GetBulkActivationJob(ctx, &pb.GetBulkActivationJobRequest{JobId: 7})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", resp.Status)
+	assert.Equal(t, int64(6), resp.UsersActivated)
+	assert.Equal(t, int64(2), resp.UsersAlreadyActive)
+	assert.Equal(t, int64(1), resp.UsersClaimed)
+	assert.Equal(t, completedAt, resp.CompletedAt.AsTime())
+
+	_, err = server.GetBulkActivationJob(ctx, &pb.GetBulkActivationJobRequest{JobId: 8})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestBackfillRPCs_NotConfigured(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.BackfillDefaultGoal(ctx, &pb.BackfillDefaultGoalRequest{GoalId: "new-goal"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.GetBackfillJob(ctx, &pb.GetBackfillJobRequest{GoalId: "new-goal"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGetSelectionHistory(t *testing.T) {
+	selectedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("GetSelectionHistory", mock.Anything, "player-1", "test-namespace", 2).
+		Return([]*serviceRepo.GoalSelectionEvent{
+			{ChallengeID: "daily", SelectedGoalIDs: []string{"goal-a"}, ReplacedGoalIDs: []string{"goal-b"}, Source: "random", CreatedAt: selectedAt},
+			{ChallengeID: "daily", SelectedGoalIDs: []string{"goal-b"}, Source: "manual", CreatedAt: selectedAt.Add(-time.Hour)},
+		}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalSelections(selections)
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.GetSelectionHistory(ctx, &pb.GetSelectionHistoryRequest{UserId: "player-1", Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Selections, 1)
+	assert.Equal(t, []string{"goal-a"}, resp.Selections[0].SelectedGoalIds)
+	assert.Equal(t, []string{"goal-b"}, resp.Selections[0].ReplacedGoalIds)
+	assert.Equal(t, "random", resp.Selections[0].Source)
+	assert.Equal(t, selectedAt, resp.Selections[0].SelectedAt.AsTime())
+	assert.True(t, resp.Truncated)
+
+	_, err = server.GetSelectionHistory(ctx, &pb.GetSelectionHistoryRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestChallengeRollouts(t *testing.T) {
+	newChallenge := func(id string) *domain.Challenge {
+		return &domain.Challenge{ID: id, Name: id, Goals: []*domain.Goal{{
+			ID:          id + "-goal",
+			ChallengeID: id,
+			Name:        id + " goal",
+			EventSource: domain.EventSourceStatistic,
+			Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		}}}
+	}
+	daily, canary := newChallenge("daily"), newChallenge("canary")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetChallengeRollouts(service.ChallengeRollouts{"canary": 0})
+	ctx := createAuthContext("user123", "test-namespace")
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{daily, canary})
+	mockCache.On("GetChallengeByChallengeID", "canary").Return(canary)
+	mockCache.On("GetGoalByID", "canary-goal").Return(canary.Goals[0])
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{}, nil)
+
+	list, err := server.GetUserChallenges(ctx, &pb.GetChallengesRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Challenges, 1)
+	assert.Equal(t, "daily", list.Challenges[0].ChallengeId)
+
+	// The challenge is reported as missing, not as unavailable
+	_, err = server.GetChallenge(ctx, &pb.GetChallengeRequest{ChallengeId: "canary"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "challenge not found: canary", status.Convert(err).Message())
+
+	_, err = server.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "canary", GoalId: "canary-goal"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.RandomSelectGoals(ctx, &pb.RandomSelectRequest{ChallengeId: "canary", Count: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserRollouts(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "daily"}, {ID: "canary"}, {ID: "everyone"}})
+
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetChallengeRollouts(service.ChallengeRollouts{"canary": 0, "everyone": 100})
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.GetUserRollouts(ctx, &pb.GetUserRolloutsRequest{UserId: "player-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "player-1", resp.UserId)
+	require.Len(t, resp.Challenges, 2)
+	assert.Equal(t, "canary", resp.Challenges[0].ChallengeId)
+	assert.Equal(t, int32(0), resp.Challenges[0].RolloutPercentage)
+	assert.Equal(t, int32(service.RolloutBucket("player-1", "canary")), resp.Challenges[0].Bucket)
+	assert.False(t, resp.Challenges[0].Eligible)
+	assert.Equal(t, "everyone", resp.Challenges[1].ChallengeId)
+	assert.True(t, resp.Challenges[1].Eligible)
+
+	_, err = server.GetUserRollouts(ctx, &pb.GetUserRolloutsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetSelectionStats(t *testing.T) {
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("GetSelectionStats", mock.Anything, "test-namespace", "daily", since).
+		Return([]*serviceRepo.GoalSelectionCount{
+			{ChallengeID: "daily", GoalID: "goal-a", Selected: 12, Replaced: 3},
+		}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalSelections(selections)
+
+	resp, err := server.GetSelectionStats(createAuthContext("admin-1", "test-namespace"), &pb.GetSelectionStatsRequest{
+		ChallengeId: "daily",
+		Since:       timestamppb.New(since),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Goals, 1)
+	assert.Equal(t, int64(12), resp.Goals[0].Selected)
+	assert.Equal(t, int64(3), resp.Goals[0].Replaced)
+	assert.Equal(t, since, resp.Since.AsTime())
+}
+
+func TestSelectionHistoryRPCs_NotConfigured(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	_, err := server.GetSelectionHistory(ctx, &pb.GetSelectionHistoryRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = server.GetSelectionStats(ctx, &pb.GetSelectionStatsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestForceCompleteGoal(t *testing.T) {
+	completedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	goal := &domain.Goal{
+		ID:          "kill-10",
+		ChallengeID: "combat",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+	written := &domain.UserGoalProgress{
+		UserID:      "player-1",
+		GoalID:      "kill-10",
+		ChallengeID: "combat",
+		Progress:    10,
+		Status:      domain.GoalStatusCompleted,
+		CompletedAt: &completedAt,
+		IsActive:    true,
+		UpdatedAt:   completedAt,
+	}
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "kill-10").Return(goal)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("ForceComplete", mock.Anything, mock.MatchedBy(func(c *serviceRepo.ForcedCompletion) bool {
+		return c.UserID == "player-1" && c.ActorUserID == "admin-1" && c.Reason == "ticket 123" && c.Forced
+	}), mock.Anything).Return(written, nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalAdmin(goalAdmin)
+
+	resp, err := server.ForceCompleteGoal(createAuthContext("admin-1", "test-namespace"), &pb.ForceCompleteGoalRequest{
+		UserId: "player-1",
+		GoalId: "kill-10",
+		Reason: "ticket 123",
+		Force:  true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "combat", resp.ChallengeId)
+	assert.Equal(t, "completed", resp.Status)
+	assert.Equal(t, int32(10), resp.Progress)
+	assert.Equal(t, completedAt, resp.CompletedAt.AsTime())
+	assert.Nil(t, resp.Reward)
+	goalAdmin.AssertExpectations(t)
+}
+
+func TestForceCompleteGoal_AutoClaim(t *testing.T) {
+	goal := &domain.Goal{
+		ID:          "kill-10",
+		ChallengeID: "combat",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+	}
+	now := time.Now().UTC()
+	written := &domain.UserGoalProgress{
+		UserID:      "player-1",
+		GoalID:      "kill-10",
+		ChallengeID: "combat",
+		Progress:    10,
+		Status:      domain.GoalStatusCompleted,
+		CompletedAt: &now,
+		IsActive:    true,
+		UpdatedAt:   now,
+	}
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "kill-10").Return(goal)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("ForceComplete", mock.Anything, mock.Anything, mock.Anything).Return(written, nil)
+
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "player-1", "kill-10").Return(written, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "player-1", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "player-1", "kill-10").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "player-1", "kill-10").Return(nil)
+	outbox.On("Delete", mock.Anything, "player-1", "kill-10").Return(nil)
+
+	server := NewChallengeServiceServer(goalCache, mockRepo, mockRewardClient, nil, "test-namespace")
+	server.SetGoalAdmin(goalAdmin)
+	server.SetClaimOutbox(outbox)
+
+	resp, err := server.ForceCompleteGoal(createAuthContext("admin-1", "test-namespace"), &pb.ForceCompleteGoalRequest{
+		UserId:    "player-1",
+		GoalId:    "kill-10",
+		Reason:    "ticket 123",
+		AutoClaim: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "claimed", resp.Status)
+	assert.Equal(t, "sword", resp.Reward.RewardId)
+	assert.NotNil(t, resp.ClaimedAt)
+	mockRewardClient.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestForceCompleteGoal_Errors(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "kill-10").Return(&domain.Goal{ID: "kill-10", ChallengeID: "combat"})
+	goalCache.On("GetGoalByID", "removed").Return(nil)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("ForceComplete", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection refused")).Maybe()
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalAdmin(goalAdmin)
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	tests := []struct {
+		name string
+		req  *pb.ForceCompleteGoalRequest
+		want codes.Code
+	}{
+		{name: "missing user", req: &pb.ForceCompleteGoalRequest{GoalId: "kill-10", Reason: "ticket"}, want: codes.InvalidArgument},
+		{name: "missing goal", req: &pb.ForceCompleteGoalRequest{UserId: "player-1", Reason: "ticket"}, want: codes.InvalidArgument},
+		{name: "blank reason", req: &pb.ForceCompleteGoalRequest{UserId: "player-1", GoalId: "kill-10", Reason: "  "}, want: codes.InvalidArgument},
+		{name: "goal not in config", req: &pb.ForceCompleteGoalRequest{UserId: "player-1", GoalId: "removed", Reason: "ticket"}, want: codes.NotFound},
+		{name: "database error", req: &pb.ForceCompleteGoalRequest{UserId: "player-1", GoalId: "kill-10", Reason: "ticket"}, want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.ForceCompleteGoal(ctx, tt.req)
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestForceCompleteGoal_InactiveGoalRefused(t *testing.T) {
+	inactive := &domain.UserGoalProgress{UserID: "player-1", GoalID: "kill-10", Status: domain.GoalStatusInProgress}
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "kill-10").Return(&domain.Goal{ID: "kill-10", ChallengeID: "combat"})
+	goalAdmin := new(mocks.GoalAdminRepository)
+	call := goalAdmin.On("ForceComplete", mock.Anything, mock.Anything, mock.Anything)
+	call.Run(func(args mock.Arguments) {
+		check := args.Get(2).(func(*domain.UserGoalProgress) error)
+		call.ReturnArguments = mock.Arguments{nil, check(inactive)}
+	})
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalAdmin(goalAdmin)
+
+	_, err := server.ForceCompleteGoal(createAuthContext("admin-1", "test-namespace"), &pb.ForceCompleteGoalRequest{
+		UserId: "player-1",
+		GoalId: "kill-10",
+		Reason: "ticket",
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "set force to override")
+}
+
+func TestBatchReportProgress(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectQuery(`SELECT user_id, goal_id, status\s+FROM user_goal_progress`).
+		WithArgs("test-namespace", `{"player-1"}`, `{"kill-10"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status"}).AddRow("player-1", "kill-10", "in_progress"))
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalsByStatCode", "kills").Return([]*domain.Goal{
+		{ID: "kill-10", ChallengeID: "combat", Requirement: domain.Requirement{StatCode: "kills", TargetValue: 10}},
+	})
+	goalCache.On("GetGoalsByStatCode", "deaths").Return([]*domain.Goal(nil))
+
+	repo := new(mocks.GoalRepository)
+	repo.On("BatchUpsertProgressWithCOPY", mock.Anything, mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 1 && rows[0].UserID == "player-1" && *rows[0].Progress == 12
+	})).Return(nil)
+
+	server := NewChallengeServiceServer(goalCache, repo, new(mocks.RewardClient), db, "test-namespace")
+
+	resp, err := server.BatchReportProgress(createAuthContext("game-server", "test-namespace"), &pb.BatchReportProgressRequest{
+		Namespace: "test-namespace",
+		Events: []*pb.ProgressEvent{
+			{UserId: "player-1", StatCode: "kills", Update: &pb.ProgressEvent_Value{Value: 12}},
+			{UserId: "player-1", StatCode: "deaths", Update: &pb.ProgressEvent_Value{Value: 3}},
+			{UserId: "player-1", StatCode: "kills"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.RowsWritten)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, service.ProgressEventApplied, resp.Results[0].Status)
+	assert.Equal(t, []string{"kill-10"}, resp.Results[0].AppliedGoalIds)
+	assert.Equal(t, service.ProgressEventUnknownStat, resp.Results[1].Status)
+	assert.Equal(t, int32(1), resp.Results[1].Index)
+	assert.Equal(t, service.ProgressEventInvalid, resp.Results[2].Status)
+	repo.AssertExpectations(t)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestBatchReportProgress_InactiveProgressPolicy(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalsByStatCode", "kills").Return([]*domain.Goal{
+		{ID: "kill-10", ChallengeID: "combat", Requirement: domain.Requirement{StatCode: "kills", TargetValue: 10}},
+	})
+	request := &pb.BatchReportProgressRequest{
+		Namespace: "test-namespace",
+		Events:    []*pb.ProgressEvent{{UserId: "player-1", StatCode: "kills", Update: &pb.ProgressEvent_Value{Value: 12}}},
+	}
+
+	tests := []struct {
+		name        string
+		policy      service.InactiveProgressPolicy
+		wantStatus  string
+		wantWritten int32
+	}{
+		{name: "tracked by default", wantStatus: service.ProgressEventApplied, wantWritten: 1},
+		{name: "not tracked", policy: service.InactiveProgressPolicy{"combat": true}, wantStatus: service.ProgressEventSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			// kill-10 is not active for player-1
+			dbMock.ExpectQuery(`SELECT user_id, goal_id, status\s+FROM user_goal_progress`).
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status"}))
+			if tt.policy == nil {
+				dbMock.ExpectQuery(`UPDATE user_goal_progress AS ugp`).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id"}).AddRow("player-1", "kill-10"))
+			}
+
+			server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+			server.SetInactiveProgressPolicy(tt.policy)
+
+			resp, err := server.BatchReportProgress(createAuthContext("game-server", "test-namespace"), request)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWritten, resp.RowsWritten)
+			assert.Equal(t, tt.wantStatus, resp.Results[0].Status)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBatchReportProgress_RejectsBadRequests(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetBatchProgressConfig(service.BatchProgressConfig{MaxEvents: 2, ChunkSize: 1})
+	ctx := createAuthContext("game-server", "test-namespace")
+
+	_, err := server.BatchReportProgress(ctx, &pb.BatchReportProgressRequest{Namespace: "other-namespace"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.BatchReportProgress(ctx, &pb.BatchReportProgressRequest{
+		Namespace: "test-namespace",
+		Events:    make([]*pb.ProgressEvent, 3),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestReportMatchResult(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectQuery(`SELECT user_id, goal_id, status\s+FROM user_goal_progress`).
+		WithArgs("test-namespace", `{"player-1"}`, `{"win-ranked"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "goal_id", "status"}).AddRow("player-1", "win-ranked", "in_progress"))
+
+	winRanked := &domain.Goal{
+		ID:          "win-ranked",
+		ChallengeID: "season",
+		Requirement: domain.Requirement{StatCode: service.MatchStatMatches, TargetValue: 3, ProgressMode: domain.ProgressModeRelative},
+	}
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "win-ranked").Return(winRanked)
+	goalCache.On("GetGoalsByStatCode", service.MatchStatMatches).Return([]*domain.Goal{winRanked})
+
+	repo := new(mocks.GoalRepository)
+	repo.On("BatchUpsertProgressWithCOPY", mock.Anything, mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 1 && rows[0].GoalID == "win-ranked" && rows[0].IncValue == 1
+	})).Return(nil)
+
+	server := NewChallengeServiceServer(goalCache, repo, new(mocks.RewardClient), db, "test-namespace")
+	server.SetMatchGoals(service.MatchGoals{{
+		GoalID:   "win-ranked",
+		StatCode: service.MatchStatMatches,
+		Filter:   service.MatchFilter{Mode: "ranked", Result: service.MatchResultWin},
+	}})
+	ctx := createAuthContext("game-server", "test-namespace")
+
+	resp, err := server.ReportMatchResult(ctx, &pb.ReportMatchResultRequest{
+		UserId: "player-1",
+		Mode:   "ranked",
+		Result: service.MatchResultWin,
+		Kills:  4,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, service.ProgressEventApplied, resp.Status)
+	assert.Equal(t, []string{"win-ranked"}, resp.AppliedGoalIds)
+
+	// A casual match matches no goal and writes nothing
+	resp, err = server.ReportMatchResult(ctx, &pb.ReportMatchResultRequest{UserId: "player-1", Mode: "casual", Result: service.MatchResultWin})
+
+	require.NoError(t, err)
+	assert.Equal(t, service.MatchEventNoMatch, resp.Status)
+	repo.AssertNumberOfCalls(t, "BatchUpsertProgressWithCOPY", 1)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReportMatchResult_InvalidEvent(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	ctx := createAuthContext("game-server", "test-namespace")
+
+	_, err := server.ReportMatchResult(ctx, &pb.ReportMatchResultRequest{Mode: "ranked"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ReportMatchResult(ctx, &pb.ReportMatchResultRequest{UserId: "player-1", Result: "victory"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClaimGoalReward_IncompleteGoalSteps(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	stepProgress := new(mocks.StepProgressRepository)
+	stepProgress.On("GetStepProgress", mock.Anything, "user123", []string{"goal1"}).Return(map[string][]int{"goal1": {10, 40}}, nil)
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), mockRepo, mockRewardClient, nil, "test-namespace")
+	server.SetGoalSteps(service.GoalSteps{"goal1": {
+		{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		{StatCode: "wins", Operator: ">=", TargetValue: 50},
+	}})
+	server.SetStepProgress(stepProgress)
+
+	resp, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_RewardCap(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	goal := &domain.Goal{
+		ID: "goal1", ChallengeID: "challenge1",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		Reward:      domain.Reward{Type: "ITEM", RewardID: "skin", Quantity: 1},
+	}
+	completedAt := time.Now()
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Progress: 10,
+		Status: domain.GoalStatusCompleted, IsActive: true, CompletedAt: &completedAt,
+	}, nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "user123", "goal1").Return(nil)
+	outbox.On("Delete", mock.Anything, "user123", "goal1").Return(nil)
+	rewardCaps := new(mocks.RewardCapRepository)
+	rewardCaps.On("TakeSlot", mock.Anything, "user123", "goal1", 1000).Return(false, 1000, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, nil, "test-namespace")
+	server.SetClaimOutbox(outbox)
+	server.SetRewardCaps(service.RewardCaps{"goal1": {Limit: 1000}}, rewardCaps)
+
+	resp, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	// Past the cap without a fallback reward: claimed, nothing granted
+	require.NoError(t, err)
+	assert.Equal(t, "claimed", resp.Status)
+	assert.Nil(t, resp.Reward)
+	require.NotNil(t, resp.RewardCap)
+	assert.Equal(t, service.RewardCapGrantedNone, resp.RewardCap.Granted)
+	assert.Equal(t, int32(1000), resp.RewardCap.Limit)
+	assert.Zero(t, resp.RewardCap.Remaining)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_RepeatableCoolingDown(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	goal := &domain.Goal{
+		ID: "goal1", ChallengeID: "challenge1",
+		Requirement: domain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 1},
+		Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+	}
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	lastClaimed := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal1").Return(nil, nil)
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal1"}).
+		Return(map[string]serviceRepo.RepeatState{"goal1": {TimesClaimed: 1, LastClaimedAt: lastClaimed}}, nil)
+
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetClaimOutbox(new(mocks.ClaimOutboxRepository))
+	server.SetRepeatableGoals(service.RepeatableGoals{"goal1": {Cooldown: 24 * time.Hour}}, repeats)
+
+	_, err := server.ClaimGoalReward(createAuthContext("user123", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "challenge1",
+		GoalId:      "goal1",
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), lastClaimed.Add(24*time.Hour).Format(time.RFC3339))
+}
+
+func TestGetUserChallenges_GoalRepeats(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	challenge := &domain.Challenge{
+		ID:   "challenge1",
+		Name: "Test Challenge",
+		Goals: []*domain.Goal{
+			{
+				ID: "goal1", ChallengeID: "challenge1",
+				Requirement: domain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 1},
+				Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			},
+			{
+				ID: "goal2", ChallengeID: "challenge1",
+				Requirement: domain.Requirement{StatCode: "logins", Operator: ">=", TargetValue: 3},
+				Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			},
+		},
+	}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge})
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress(nil), nil)
+	lastClaimed := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	repeats := new(mocks.RepeatableGoalRepository)
+	firstCompleted := lastClaimed.Add(-72 * time.Hour)
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal1"}).
+		Return(map[string]serviceRepo.RepeatState{"goal1": {TimesClaimed: 3, LastClaimedAt: lastClaimed, FirstCompletedAt: &firstCompleted}}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetRepeatableGoals(service.RepeatableGoals{"goal1": {Cooldown: 24 * time.Hour}}, repeats)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{})
+
+	require.NoError(t, err)
+	goals := resp.Challenges[0].Goals
+	require.Len(t, goals, 2)
+	require.NotNil(t, goals[0].Repeat)
+	assert.Equal(t, int32(3), goals[0].Repeat.TimesClaimed)
+	assert.Equal(t, lastClaimed.Add(24*time.Hour), goals[0].Repeat.AvailableAt.AsTime())
+	// The reset row has no completed_at; the first completion is kept
+	assert.Nil(t, goals[0].CompletedAt)
+	assert.Equal(t, firstCompleted, goals[0].FirstCompletedAt.AsTime())
+	assert.Nil(t, goals[1].FirstCompletedAt)
+	// Cooling down: cannot be activated yet
+	assert.False(t, goals[0].Activatable)
+	assert.Nil(t, goals[1].Repeat)
+	assert.True(t, goals[1].Activatable)
+	repeats.AssertNotCalled(t, "ResetClaimed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetUserChallenges_GoalSteps(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	stepProgress := new(mocks.StepProgressRepository)
+
+	challenge := &domain.Challenge{
+		ID:   "challenge1",
+		Name: "Test Challenge",
+		Goals: []*domain.Goal{
+			{
+				ID: "goal1", ChallengeID: "challenge1",
+				Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+				Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			},
+			{
+				ID: "goal2", ChallengeID: "challenge1",
+				Requirement: domain.Requirement{StatCode: "logins", Operator: ">=", TargetValue: 3},
+				Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+			},
+		},
+	}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge})
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress(nil), nil)
+	stepProgress.On("GetStepProgress", mock.Anything, "user123", []string{"goal1"}).Return(map[string][]int{"goal1": {10, 40}}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalSteps(service.GoalSteps{"goal1": {
+		{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		{StatCode: "wins", Operator: ">=", TargetValue: 50},
+	}})
+	server.SetStepProgress(stepProgress)
+
+	resp, err := server.GetUserChallenges(createAuthContext("user123", "test-namespace"), &pb.GetChallengesRequest{})
+
+	require.NoError(t, err)
+	goals := resp.Challenges[0].Goals
+	require.Len(t, goals, 2)
+	require.Len(t, goals[0].Steps, 2)
+	assert.True(t, goals[0].Steps[0].Completed)
+	assert.Equal(t, "wins", goals[0].Steps[1].StatCode)
+	assert.Equal(t, int32(40), goals[0].Steps[1].Progress)
+	assert.False(t, goals[0].Steps[1].Completed)
+	assert.Empty(t, goals[1].Steps)
+}
+
+func TestGetGoalStats(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dbMock.ExpectQuery(`FROM user_goal_progress\s+WHERE namespace = \$1\s+GROUP BY challenge_id, goal_id`).
+		WithArgs("test-namespace").
+		WillReturnRows(sqlmock.NewRows([]string{"challenge_id", "goal_id", "not_started", "in_progress", "completed", "claimed"}).
+			AddRow("daily", "login", 2, 3, 1, 4).
+			AddRow("daily", "orphan", 0, 0, 0, 0))
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+	server.SetGoalStats(service.NewGoalStats(serviceRepo.NewPostgresProgressQueryRepository(db), "test-namespace", time.Minute, false, logrus.StandardLogger()))
+
+	resp, err := server.GetGoalStats(createAuthContext("admin-1", "test-namespace"), &pb.GetGoalStatsRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Goals, 2)
+	assert.Equal(t, "login", resp.Goals[0].GoalId)
+	assert.Equal(t, int64(10), resp.Goals[0].Total)
+	assert.InDelta(t, 0.5, resp.Goals[0].CompletionRate, 1e-9)
+	assert.Equal(t, int64(4), resp.Goals[0].Claimed)
+	assert.Zero(t, resp.Goals[1].CompletionRate)
+	assert.Equal(t, int64(60), resp.CacheTtlSeconds)
+	assert.NotNil(t, resp.GeneratedAt)
+
+	// Served from the cache: no second query
+	_, err = server.GetGoalStats(createAuthContext("admin-1", "test-namespace"), &pb.GetGoalStatsRequest{})
+	require.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetGoalStats_Errors(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	_, err = server.GetGoalStats(createAuthContext("admin-1", "test-namespace"), &pb.GetGoalStatsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	dbMock.ExpectQuery(`FROM user_goal_progress`).WillReturnError(errors.New("connection reset"))
+	server.SetGoalStats(service.NewGoalStats(serviceRepo.NewPostgresProgressQueryRepository(db), "test-namespace", time.Minute, false, logrus.StandardLogger()))
+
+	_, err = server.GetGoalStats(createAuthContext("admin-1", "test-namespace"), &pb.GetGoalStatsRequest{Refresh: true})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetServiceState(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(5)
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		{ID: "daily", Goals: []*domain.Goal{{ID: "login"}, {ID: "kills"}}},
+	})
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), db, "test-namespace")
+
+	_, err = server.GetServiceState(createAuthContext("admin-1", "test-namespace"), &pb.GetServiceStateRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	startedAt := time.Now().UTC().Add(-time.Hour)
+	server.SetServiceState(service.NewServiceStateReporter(true, nil, goalCache, nil, db, "mock", true, startedAt))
+
+	resp, err := server.GetServiceState(createAuthContext("admin-1", "test-namespace"), &pb.GetServiceStateRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ChallengeCount)
+	assert.Equal(t, int32(2), resp.GoalCount)
+	assert.Equal(t, "mock", resp.RewardClientMode)
+	assert.True(t, resp.AuthEnabled)
+	assert.Equal(t, int32(5), resp.DbPool.MaxOpenConnections)
+	assert.Zero(t, resp.SerializedCache.TotalBytes)
+	assert.Equal(t, startedAt.Unix(), resp.StartedAt.AsTime().Unix())
+	assert.GreaterOrEqual(t, resp.UptimeSeconds, int64(3600))
+}
+
+func TestGetChallengeMismatches(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetAllGoals").Return([]*domain.Goal{{ID: "kills", ChallengeID: "weekly"}})
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetChallengeMismatches", mock.Anything, "test-namespace", map[string]string{"kills": "weekly"}, 3).
+		Return([]*serviceRepo.ChallengeMismatch{{
+			UserID:            "player-1",
+			GoalID:            "kills",
+			StoredChallengeID: "daily",
+			ChallengeID:       "weekly",
+			Status:            domain.GoalStatusCompleted,
+			UpdatedAt:         updatedAt,
+		}}, nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.progressQueries = queries
+
+	resp, err := server.GetChallengeMismatches(createAuthContext("admin-1", "test-namespace"), &pb.GetChallengeMismatchesRequest{Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Mismatches, 1)
+	assert.False(t, resp.Truncated)
+	assert.Equal(t, "daily", resp.Mismatches[0].StoredChallengeId)
+	assert.Equal(t, "weekly", resp.Mismatches[0].ChallengeId)
+	assert.Equal(t, "completed", resp.Mismatches[0].Status)
+	assert.Equal(t, updatedAt, resp.Mismatches[0].UpdatedAt.AsTime())
+
+	_, err = server.GetChallengeMismatches(createAuthContext("admin-1", "test-namespace"), &pb.GetChallengeMismatchesRequest{Limit: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestFixChallengeMismatches(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetAllGoals").Return([]*domain.Goal{{ID: "kills", ChallengeID: "weekly"}})
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("FixChallengeMismatches", mock.Anything, mock.MatchedBy(func(fix *serviceRepo.ChallengeMismatchFix) bool {
+		return fix.ActorUserID == "admin-1" && fix.Reason == "moved kills" && fix.Limit == service.DefaultChallengeMismatchFixLimit
+	})).Return(12, nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetGoalAdmin(goalAdmin)
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	resp, err := server.FixChallengeMismatches(ctx, &pb.FixChallengeMismatchesRequest{Reason: "moved kills"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(12), resp.Fixed)
+	assert.False(t, resp.More)
+
+	_, err = server.FixChallengeMismatches(ctx, &pb.FixChallengeMismatchesRequest{Reason: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetUnclaimedCount(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "removed").Return(nil)
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("CountByStatus", mock.Anything, "user123", "test-namespace", mock.Anything).
+		Return(map[domain.GoalStatus]int{domain.GoalStatusCompleted: 2, domain.GoalStatusInProgress: 1}, nil)
+
+	server := NewChallengeServiceServer(mockCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetUnclaimedCounts(service.NewUnclaimedCounts(queries, "test-namespace", time.Minute))
+	ctx := createAuthContext("user123", "test-namespace")
+
+	resp, err := server.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+
+	require.NoError(t, err)
+	assert.True(t, resp.HasUnclaimed)
+	assert.Equal(t, int32(2), resp.UnclaimedCount)
+	assert.Equal(t, int32(1), resp.InProgressCount)
+
+	// Served from the cache until the user's next claim attempt
+	_, err = server.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+	require.NoError(t, err)
+	queries.AssertNumberOfCalls(t, "CountByStatus", 1)
+
+	_, err = server.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "c1", GoalId: "removed"})
+	require.Error(t, err)
+	_, err = server.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+	require.NoError(t, err)
+	queries.AssertNumberOfCalls(t, "CountByStatus", 2)
+}
+
+func TestGetUnclaimedCount_Error(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("CountByStatus", mock.Anything, "user123", "test-namespace", mock.Anything).Return(nil, errors.New("db down"))
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetUnclaimedCounts(service.NewUnclaimedCounts(queries, "test-namespace", time.Minute))
+
+	_, err := server.GetUnclaimedCount(createAuthContext("user123", "test-namespace"), &pb.GetUnclaimedCountRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetActiveGoals(t *testing.T) {
+	earlier := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "g1").Return(&domain.Goal{ID: "g1", ChallengeID: "c1", Name: "Kill 10", Requirement: domain.Requirement{StatCode: "kills", TargetValue: 10}})
+	mockCache.On("GetGoalByID", "g2").Return(&domain.Goal{ID: "g2", ChallengeID: "c1", Name: "Win 3", Requirement: domain.Requirement{StatCode: "wins", TargetValue: 3}})
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetActiveGoals", mock.Anything, "user123").Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "g1", ChallengeID: "c1", Progress: 4, Status: domain.GoalStatusInProgress, IsActive: true, AssignedAt: &later},
+		{UserID: "user123", GoalID: "g2", ChallengeID: "c1", Progress: 3, Status: domain.GoalStatusCompleted, IsActive: true, AssignedAt: &earlier},
+	}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	resp, err := server.GetActiveGoals(createAuthContext("user123", "test-namespace"), &pb.GetActiveGoalsRequest{Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Goals, 1)
+	assert.True(t, resp.Truncated)
+	assert.Equal(t, "g2", resp.Goals[0].GoalId, "oldest assignment first")
+	assert.Equal(t, "Win 3", resp.Goals[0].Name)
+	assert.Equal(t, int32(3), resp.Goals[0].Progress)
+	assert.Equal(t, int32(3), resp.Goals[0].Target)
+	assert.Equal(t, string(domain.GoalStatusCompleted), resp.Goals[0].Status)
+	assert.Equal(t, earlier, resp.Goals[0].AssignedAt.AsTime())
+	assert.True(t, resp.Goals[0].IsActive)
+}
+
+func TestGetActiveGoals_Error(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetActiveGoals", mock.Anything, "user123").Return(nil, errors.New("db down"))
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), mockRepo, new(mocks.RewardClient), nil, "test-namespace")
+	_, err := server.GetActiveGoals(createAuthContext("user123", "test-namespace"), &pb.GetActiveGoalsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = server.GetActiveGoals(context.Background(), &pb.GetActiveGoalsRequest{})
+	assert.Error(t, err)
+}
+
+func TestClaimAllCompleted(t *testing.T) {
+	now := time.Now()
+	target := domain.Requirement{TargetValue: 1}
+	sword := &domain.Goal{ID: "g1", ChallengeID: "daily", Requirement: target, Reward: domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1}}
+	gold := &domain.Goal{ID: "g2", ChallengeID: "daily", Requirement: target, Reward: domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 100}}
+	completed := func(goalID string) *domain.UserGoalProgress {
+		return &domain.UserGoalProgress{
+			UserID: "user123", GoalID: goalID, ChallengeID: "daily", Namespace: "test-namespace",
+			Status: domain.GoalStatusCompleted, IsActive: true, CompletedAt: &now,
+		}
+	}
+	// g2 was claimed by a concurrent request after the progress was listed
+	claimedG2 := completed("g2")
+	claimedG2.Status = domain.GoalStatusClaimed
+	claimedG2.ClaimedAt = &now
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{{ID: "daily", Goals: []*domain.Goal{sword, gold}}})
+	mockCache.On("GetGoalByID", "g1").Return(sword)
+	mockCache.On("GetGoalByID", "g2").Return(gold)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{completed("g1"), completed("g2")}, nil)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "g1").Return(completed("g1"), nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "g2").Return(claimedG2, nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "g1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+	mockTxRepo.On("Rollback").Return(nil)
+	mockRewardClient := new(mocks.RewardClient)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", sword.Reward).Return(nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, mockRewardClient, nil, "test-namespace")
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "user123", "g1").Return(nil)
+	outbox.On("Delete", mock.Anything, "user123", "g1").Return(nil)
+	server.SetClaimOutbox(outbox)
+
+	resp, err := server.ClaimAllCompleted(createAuthContext("user123", "test-namespace"), &pb.ClaimAllCompletedRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ClaimedCount)
+	assert.Equal(t, int32(1), resp.FailedCount)
+	assert.False(t, resp.HasMore)
+	require.Len(t, resp.Outcomes, 2)
+	assert.Equal(t, "claimed", resp.Outcomes[0].Status)
+	assert.Equal(t, "sword", resp.Outcomes[0].Reward.RewardId)
+	assert.Equal(t, "failed", resp.Outcomes[1].Status)
+	assert.Equal(t, codes.AlreadyExists.String(), resp.Outcomes[1].ErrorCode)
+	require.Len(t, resp.Rewards, 1)
+	assert.Equal(t, &pb.RewardSummary{Type: "ITEM", RewardId: "sword", Quantity: 1, GoalCount: 1}, resp.Rewards[0])
+	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
+}
+
+func TestClaimAllCompleted_NoAuthContext(t *testing.T) {
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+
+	_, err := server.ClaimAllCompleted(context.Background(), &pb.ClaimAllCompletedRequest{})
+
+	assert.Error(t, err)
+}
+
+func TestGetChallenge_IncludeBlockers(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	newGoal := func(id string, prerequisites ...string) *domain.Goal {
+		return &domain.Goal{
+			ID:            id,
+			ChallengeID:   "challenge2",
+			Name:          id,
+			Requirement:   domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10, ProgressMode: domain.ProgressModeAbsolute},
+			Reward:        domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+			EventSource:   domain.EventSourceStatistic,
+			Prerequisites: prerequisites,
+		}
+	}
+	challenge := &domain.Challenge{
+		ID:    "challenge2",
+		Name:  "Follow-up Challenge",
+		Goals: []*domain.Goal{newGoal("done"), newGoal("next", "challenge1-final"), newGoal("after-next", "next")},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+
+	mockCache.On("GetChallengeByChallengeID", "challenge2").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "challenge2", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "done", ChallengeID: "challenge2", Status: domain.GoalStatusCompleted, Progress: 10, IsActive: true, UpdatedAt: time.Now()},
+		{UserID: "user123", GoalID: "next", ChallengeID: "challenge2", Status: domain.GoalStatusInProgress, Progress: 4, IsActive: true, UpdatedAt: time.Now()},
+	}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"challenge1-final"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "challenge1-final", ChallengeID: "challenge1", Status: domain.GoalStatusInProgress},
+	}, nil)
+
+	resp, err := server.GetChallenge(createAuthContext("user123", "test-namespace"), &pb.GetChallengeRequest{
+		ChallengeId:     "challenge2",
+		IncludeBlockers: true,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Challenge.Goals, 3)
+	done, next, afterNext := resp.Challenge.Goals[0], resp.Challenge.Goals[1], resp.Challenge.Goals[2]
+	assert.Empty(t, done.ClaimBlockers, "completed goals are claimable")
+	assert.Equal(t, []string{"not_completed", "prerequisites_not_met:challenge1-final"}, next.ClaimBlockers)
+	assert.Equal(t, []string{"not_completed", "prerequisites_not_met:next"}, afterNext.ClaimBlockers)
+}
+
+func TestGetUserChallenges_ClaimBlockersOnlyWhenRequested(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	challenge := &domain.Challenge{
+		ID:   "challenge1",
+		Name: "Test Challenge",
+		Goals: []*domain.Goal{{
+			ID: "goal1", ChallengeID: "challenge1",
+			Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+			Reward:      domain.Reward{Type: "WALLET", RewardID: "gold", Quantity: 10},
+		}},
+	}
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge})
+	mockRepo.On("GetUserProgress", mock.Anything, "user123", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal1", ChallengeID: "challenge1", Status: domain.GoalStatusClaimed, Progress: 10, IsActive: true, UpdatedAt: time.Now()},
+	}, nil)
+
+	server := NewChallengeServiceServer(mockCache, mockRepo, new(mocks.RewardClient), db, "test-namespace")
+	ctx := createAuthContext("user123", "test-namespace")
+
+	resp, err := server.GetUserChallenges(ctx, &pb.GetChallengesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Challenges[0].Goals[0].ClaimBlockers)
+
+	resp, err = server.GetUserChallenges(ctx, &pb.GetChallengesRequest{IncludeBlockers: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"already_claimed"}, resp.Challenges[0].Goals[0].ClaimBlockers)
+}
+
+func TestRecomputeUserStatuses(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.MatchedBy(func(r *serviceRepo.StatusRecompute) bool {
+		return r.UserID == "player-1" && r.ActorUserID == "admin-1" && r.Reason == "ticket 123"
+	}), mock.Anything).Return([]serviceRepo.StatusTransition{
+		{GoalID: "kill-10", ChallengeID: "combat", From: domain.GoalStatusInProgress, To: domain.GoalStatusCompleted, Progress: 7},
+	}, nil)
+
+	server := NewChallengeServiceServer(goalCache, new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	server.SetStatusRecomputes(service.NewStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository), goalCache,
+		service.GoalSteps{}, service.InactiveProgressPolicy{}, "test-namespace", logrus.New()))
+
+	resp, err := server.RecomputeUserStatuses(createAuthContext("admin-1", "test-namespace"), &pb.RecomputeUserStatusesRequest{
+		UserId: "player-1",
+		Reason: " ticket 123 ",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "player-1", resp.UserId)
+	assert.Equal(t, int32(1), resp.Completed)
+	assert.Equal(t, int32(0), resp.Regressed)
+	require.Len(t, resp.Transitions, 1)
+	assert.Equal(t, "in_progress", resp.Transitions[0].FromStatus)
+	assert.Equal(t, "completed", resp.Transitions[0].ToStatus)
+	assert.Equal(t, int32(7), resp.Transitions[0].Progress)
+}
+
+func TestRecomputeUserStatuses_Errors(t *testing.T) {
+	ctx := createAuthContext("admin-1", "test-namespace")
+
+	server := NewChallengeServiceServer(new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.RewardClient), nil, "test-namespace")
+	_, err := server.RecomputeUserStatuses(ctx, &pb.RecomputeUserStatusesRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+	server.SetStatusRecomputes(service.NewStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository), new(mocks.GoalCache),
+		service.GoalSteps{}, service.InactiveProgressPolicy{}, "test-namespace", logrus.New()))
+
+	_, err = server.RecomputeUserStatuses(ctx, &pb.RecomputeUserStatusesRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.RecomputeUserStatuses(ctx, &pb.RecomputeUserStatusesRequest{UserId: "player-1"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
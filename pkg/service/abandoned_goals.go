@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultAbandonedGoalSweepInterval is how often abandoned goals are deactivated.
+	DefaultAbandonedGoalSweepInterval = time.Hour
+
+	// abandonedGoalBatchSize bounds the rows one update deactivates.
+	abandonedGoalBatchSize = 500
+)
+
+// AutoDeactivation maps challenge IDs to their "autoDeactivateAfterDays" from the
+// challenge config: how many days a goal of the challenge may stay active
+// without progress before the AbandonedGoalSweeper deactivates it. Challenges
+// not listed, and a nil AutoDeactivation, keep goals active.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so it is read
+// from the config file separately by LoadAutoDeactivation.
+type AutoDeactivation map[string]int
+
+// autoDeactivationConfig is the subset of challenges.json needed to read the setting.
+type autoDeactivationConfig struct {
+	Challenges []struct {
+		ID                      string `json:"challengeId"`
+		AutoDeactivateAfterDays int    `json:"autoDeactivateAfterDays"`
+	} `json:"challenges"`
+}
+
+// LoadAutoDeactivation reads the challenges' autoDeactivateAfterDays from the
+// challenge config file.
+func LoadAutoDeactivation(configPath string) (AutoDeactivation, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseAutoDeactivation(data)
+}
+
+// ParseAutoDeactivation extracts the challenges' autoDeactivateAfterDays from
+// challenge config JSON. 0 or unset keeps goals active; a negative value is an
+// error.
+func ParseAutoDeactivation(data []byte) (AutoDeactivation, error) {
+	var cfg autoDeactivationConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	policy := make(AutoDeactivation)
+	for _, challenge := range cfg.Challenges {
+		switch {
+		case challenge.AutoDeactivateAfterDays < 0:
+			return nil, fmt.Errorf("challenge %q: autoDeactivateAfterDays must not be negative, got %d",
+				challenge.ID, challenge.AutoDeactivateAfterDays)
+		case challenge.AutoDeactivateAfterDays > 0:
+			policy[challenge.ID] = challenge.AutoDeactivateAfterDays
+		}
+	}
+
+	return policy, nil
+}
+
+// After returns how long the challenge's goals may stay active without
+// progress; 0 if they are never deactivated.
+func (a AutoDeactivation) After(challengeID string) time.Duration {
+	return time.Duration(a[challengeID]) * 24 * time.Hour
+}
+
+// AbandonedGoalSweeper deactivates goals players activated and never made
+// progress on, per the challenges' AutoDeactivation, so they stop cluttering the
+// player's active goals. Deactivated goals get the ActivationSourceAutoExpired
+// source; players can activate them again like any other goal. It deactivates
+// in batches, so a large backlog after enabling it does not hold long locks.
+type AbandonedGoalSweeper struct {
+	repo      serviceRepo.AbandonedGoalRepository
+	namespace string
+	policy    AutoDeactivation
+	interval  time.Duration
+	now       func() time.Time
+	logger    logrus.FieldLogger
+
+	deactivated *prometheus.CounterVec
+	sweptPerRun prometheus.Histogram
+}
+
+// NewAbandonedGoalSweeper creates a sweeper for the challenges of policy in
+// namespace, running every interval and logging through logger.
+func NewAbandonedGoalSweeper(
+	repo serviceRepo.AbandonedGoalRepository,
+	namespace string,
+	policy AutoDeactivation,
+	interval time.Duration,
+	logger logrus.FieldLogger,
+) *AbandonedGoalSweeper {
+	return &AbandonedGoalSweeper{
+		repo:      repo,
+		namespace: namespace,
+		policy:    policy,
+		interval:  interval,
+		now:       func() time.Time { return time.Now().UTC() },
+		logger:    logger,
+		deactivated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "abandoned_goals_deactivated_total",
+			Help: "Goals deactivated because they stayed active without progress past autoDeactivateAfterDays, by challenge.",
+		}, []string{"challenge_id"}),
+		sweptPerRun: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "abandoned_goal_sweep_rows",
+			Help:    "Goals deactivated per abandoned goal sweep.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		}),
+	}
+}
+
+// NewAbandonedGoalSweeperFromEnv creates a sweeper running every
+// ABANDONED_GOAL_SWEEP_INTERVAL (default "1h").
+func NewAbandonedGoalSweeperFromEnv(
+	repo serviceRepo.AbandonedGoalRepository,
+	namespace string,
+	policy AutoDeactivation,
+	logger logrus.FieldLogger,
+) *AbandonedGoalSweeper {
+	return NewAbandonedGoalSweeper(
+		repo,
+		namespace,
+		policy,
+		parseClaimRecoveryDuration("ABANDONED_GOAL_SWEEP_INTERVAL", DefaultAbandonedGoalSweepInterval, 0, logger),
+		logger,
+	)
+}
+
+// Collectors returns the sweeper metrics for registration.
+func (s *AbandonedGoalSweeper) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{s.deactivated, s.sweptPerRun}
+}
+
+// Interval returns how often Run sweeps.
+func (s *AbandonedGoalSweeper) Interval() time.Duration {
+	return s.interval
+}
+
+// Sweep deactivates the abandoned goals of each configured challenge, batch by
+// batch until none are left or ctx is done, and returns the number deactivated.
+// A failing challenge does not stop the others; the first error is returned.
+func (s *AbandonedGoalSweeper) Sweep(ctx context.Context) (int, error) {
+	now := s.now()
+
+	total := 0
+	var firstErr error
+	for _, challengeID := range slices.Sorted(maps.Keys(s.policy)) {
+		deactivated, err := s.sweepChallenge(ctx, challengeID, now.Add(-s.policy.After(challengeID)))
+		total += deactivated
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("challenge %s: %w", challengeID, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(s.policy) > 0 {
+		s.sweptPerRun.Observe(float64(total))
+	}
+
+	return total, firstErr
+}
+
+// sweepChallenge deactivates the challenge's goals assigned before cutoff.
+func (s *AbandonedGoalSweeper) sweepChallenge(ctx context.Context, challengeID string, cutoff time.Time) (int, error) {
+	total := 0
+	for {
+		deactivated, err := s.repo.DeactivateAbandonedGoals(ctx, s.namespace, challengeID, cutoff,
+			ActivationSourceAutoExpired, abandonedGoalBatchSize)
+		total += deactivated
+		s.deactivated.WithLabelValues(challengeID).Add(float64(deactivated))
+		if err != nil {
+			return total, err
+		}
+		if deactivated < abandonedGoalBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled. It returns right away when
+// no challenge sets autoDeactivateAfterDays.
+func (s *AbandonedGoalSweeper) Run(ctx context.Context) {
+	if len(s.policy) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		deactivated, err := s.Sweep(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.WithError(err).Warn("Failed to deactivate abandoned goals")
+		}
+		if deactivated > 0 {
+			s.logger.WithField("deactivated", deactivated).Info("Deactivated abandoned goals")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
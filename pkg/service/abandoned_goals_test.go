@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var sweepNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestSweeper(repo *mocks.AbandonedGoalRepository, policy AutoDeactivation) *AbandonedGoalSweeper {
+	sweeper := NewAbandonedGoalSweeper(repo, "ns", policy, time.Hour, logrus.StandardLogger())
+	sweeper.now = func() time.Time { return sweepNow }
+	return sweeper
+}
+
+func TestParseAutoDeactivation(t *testing.T) {
+	policy, err := ParseAutoDeactivation([]byte(`{
+		"challenges": [
+			{"challengeId": "season"},
+			{"challengeId": "daily", "autoDeactivateAfterDays": 0},
+			{"challengeId": "weekly", "autoDeactivateAfterDays": 14}
+		]
+	}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, AutoDeactivation{"weekly": 14}, policy)
+	assert.Equal(t, 14*24*time.Hour, policy.After("weekly"))
+	assert.Zero(t, policy.After("season"))
+}
+
+func TestParseAutoDeactivation_Invalid(t *testing.T) {
+	_, err := ParseAutoDeactivation([]byte(`{"challenges": [{"challengeId": "weekly", "autoDeactivateAfterDays": -1}]}`))
+	assert.ErrorContains(t, err, `challenge "weekly": autoDeactivateAfterDays must not be negative`)
+
+	_, err = ParseAutoDeactivation([]byte(`{"challenges": [{"challengeId": "weekly", "autoDeactivateAfterDays": "7"}]}`))
+	assert.Error(t, err)
+}
+
+func TestAbandonedGoalSweeper_SweepDeactivatesInBatches(t *testing.T) {
+	repo := new(mocks.AbandonedGoalRepository)
+	daily := sweepNow.Add(-3 * 24 * time.Hour)
+	weekly := sweepNow.Add(-14 * 24 * time.Hour)
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "daily", daily, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(abandonedGoalBatchSize, nil).Once()
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "daily", daily, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(7, nil).Once()
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "weekly", weekly, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(0, nil).Once()
+
+	sweeper := newTestSweeper(repo, AutoDeactivation{"daily": 3, "weekly": 14})
+	deactivated, err := sweeper.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, abandonedGoalBatchSize+7, deactivated)
+	assert.Equal(t, float64(abandonedGoalBatchSize+7), testutil.ToFloat64(sweeper.deactivated.WithLabelValues("daily")))
+	assert.Zero(t, testutil.ToFloat64(sweeper.deactivated.WithLabelValues("weekly")))
+	assert.Equal(t, 1, testutil.CollectAndCount(sweeper.sweptPerRun))
+	repo.AssertExpectations(t)
+}
+
+func TestAbandonedGoalSweeper_SweepContinuesAfterError(t *testing.T) {
+	repo := new(mocks.AbandonedGoalRepository)
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "daily", mock.Anything, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(0, errors.New("db down")).Once()
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "weekly", mock.Anything, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(4, nil).Once()
+
+	sweeper := newTestSweeper(repo, AutoDeactivation{"daily": 3, "weekly": 14})
+	deactivated, err := sweeper.Sweep(context.Background())
+
+	assert.ErrorContains(t, err, "challenge daily: db down")
+	assert.Equal(t, 4, deactivated)
+	repo.AssertExpectations(t)
+}
+
+// Run logs a failed sweep and keeps sweeping; the next sweep logs what it
+// deactivated.
+func TestAbandonedGoalSweeper_RunLogsSweeps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := new(mocks.AbandonedGoalRepository)
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "daily", mock.Anything, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Return(0, errors.New("db down")).Once()
+	repo.On("DeactivateAbandonedGoals", mock.Anything, "ns", "daily", mock.Anything, ActivationSourceAutoExpired, abandonedGoalBatchSize).
+		Run(func(mock.Arguments) { cancel() }).Return(3, nil).Once()
+	logger, hook := logtest.NewNullLogger()
+
+	NewAbandonedGoalSweeper(repo, "ns", AutoDeactivation{"daily": 3}, time.Millisecond, logger).Run(ctx)
+
+	entries := hook.AllEntries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "Failed to deactivate abandoned goals", entries[0].Message)
+	assert.ErrorContains(t, entries[0].Data[logrus.ErrorKey].(error), "challenge daily: db down")
+	assert.Equal(t, logrus.InfoLevel, entries[1].Level)
+	assert.Equal(t, 3, entries[1].Data["deactivated"])
+	repo.AssertExpectations(t)
+}
+
+func TestAbandonedGoalSweeper_RunWithoutPolicyReturns(t *testing.T) {
+	repo := new(mocks.AbandonedGoalRepository)
+	sweeper := newTestSweeper(repo, nil)
+
+	done := make(chan struct{})
+	go func() {
+		sweeper.Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return without autoDeactivateAfterDays")
+	}
+	repo.AssertNotCalled(t, "DeactivateAbandonedGoals", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Activation blockers, the reasons SetGoalActive refuses to activate a goal,
+// reported by GetActivationPreflight.
+const (
+	ActivationBlockerChallengeLocked   = "challenge_locked"
+	ActivationBlockerChallengeMismatch = "challenge_mismatch"
+	ActivationBlockerCoolingDown       = "goal_cooling_down"
+	ActivationBlockerAlreadyClaimed    = "already_claimed"
+)
+
+// The activation predicates are pure, like the claim predicates:
+// SetGoalActive applies them in order and stops at the first failure,
+// ActivationBlockers applies all of them to report every reason at once. The
+// challenge lock is checked by the caller of SetGoalActive (see
+// ChallengePrerequisites.CheckUnlocked), the others by SetGoalActive.
+//
+// Goal prerequisites do not block activation, only claims; there is no cap on
+// active goals and no challenge schedule.
+
+// checkActivationClaimed fails when the goal is claimed as listed to the
+// player. A rotating goal whose period has ended can be activated again, and
+// the claimed row of a repeatable goal is reset by the activation instead
+// (leaving its cooldown to block it).
+func checkActivationClaimed(goal *domain.Goal, progress *domain.UserGoalProgress, repeatable bool, now time.Time) error {
+	if progress == nil || repeatable || !progress.IsClaimed() || rotation.HasRotationOccurred(progress, goal, now) {
+		return nil
+	}
+	return &mapper.GoalAlreadyClaimedError{
+		GoalID:    goal.ID,
+		ClaimedAt: mapper.FormatOptionalTimestamp(progress.ClaimedAt),
+	}
+}
+
+// ActivationBlockerState is the user's state the activation blockers of a goal
+// are computed from.
+type ActivationBlockerState struct {
+	// Progress is the user's row of the goal, nil without one. The claimed row of
+	// a repeatable goal is as the activation's reset leaves it (see
+	// resetRepeatableRow).
+	Progress *domain.UserGoalProgress
+	// LockedBy holds the prerequisite challenges of the goal's challenge the user
+	// has not completed (see ChallengePrerequisites.UserLockedBy).
+	LockedBy []string
+	// Repeats holds the claim history of the goal if it is repeatable, counting
+	// the claim of a row still claimed (see repeatableGoalRepeats).
+	Repeats map[string]GoalRepeat
+	Now     time.Time
+}
+
+// ActivationBlockers returns the activation blockers of the goal requested
+// under challengeID, in the order SetGoalActive checks them; nil when the goal
+// can be activated. An active goal has none beyond the lock, the challenge
+// mismatch and the cooldown: activating it again changes nothing.
+func ActivationBlockers(goal *domain.Goal, challengeID string, state ActivationBlockerState) []string {
+	var blockers []string
+	add := func(err error) {
+		if blocker := activationBlocker(err); blocker != "" {
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	add(checkChallengeUnlocked(challengeID, state.LockedBy))
+	if state.Progress != nil {
+		add(checkProgressChallenge(goal.ID, challengeID, state.Progress))
+	}
+	add(coolingDownError(state.Repeats, []string{goal.ID}, state.Now))
+	if state.Progress == nil || !state.Progress.IsActive {
+		_, repeatable := state.Repeats[goal.ID]
+		add(checkActivationClaimed(goal, state.Progress, repeatable, state.Now))
+	}
+
+	return blockers
+}
+
+// activationBlocker returns the activation blocker for an error of an
+// activation predicate; "" for nil.
+func activationBlocker(err error) string {
+	var (
+		locked         *mapper.ChallengeLockedError
+		mismatch       *mapper.ChallengeMismatchError
+		coolingDown    *mapper.GoalCoolingDownError
+		alreadyClaimed *mapper.GoalAlreadyClaimedError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &locked):
+		return ActivationBlockerChallengeLocked
+	case errors.As(err, &mismatch):
+		return ActivationBlockerChallengeMismatch
+	case errors.As(err, &coolingDown):
+		return ActivationBlockerCoolingDown
+	case errors.As(err, &alreadyClaimed):
+		return ActivationBlockerAlreadyClaimed
+	default:
+		return ""
+	}
+}
+
+// ActivationPreflight tells whether SetGoalActive would activate a goal now.
+type ActivationPreflight struct {
+	Allowed bool
+	// Blockers are the reasons it would not (see ActivationBlockers).
+	Blockers []string
+}
+
+// GetActivationPreflight computes the activation blockers of a goal for the
+// user, so a client can tell whether an activate toggle will work before
+// showing it. Nothing is written: a claimed repeatable row is not reset, its
+// reset is only accounted for. The repeatable goals in ctx (see
+// WithRepeatableGoals) and prerequisites are those SetGoalActive and its
+// caller use.
+//
+// A goal not configured under challengeID returns the error SetGoalActive
+// returns for it. The computed blockers are logged to logger.
+func GetActivationPreflight(
+	ctx context.Context,
+	userID string,
+	challengeID string,
+	goalID string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	prerequisites ChallengePrerequisites,
+	logger logrus.FieldLogger,
+) (*ActivationPreflight, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	goal, err := GetGoal(goalCache, challengeID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedBy, err := prerequisites.UserLockedBy(ctx, repo, goalCache, userID, challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := repo.GetProgress(ctx, userID, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal progress: %w", err)
+	}
+
+	// Without progress rows nothing is reset
+	repeats, err := repeatableGoalRepeats(ctx, userID, []string{goalID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if repeatable, _, ok := repeatableGoalFrom(ctx, goalID); ok && progress != nil && progress.Status == domain.GoalStatusClaimed {
+		repeats[goalID] = repeatable.pendingRepeat(repeats[goalID], progress.ClaimedAt)
+		progress = resetRepeatableRow(progress)
+	}
+
+	blockers := ActivationBlockers(goal, challengeID, ActivationBlockerState{
+		Progress: progress,
+		LockedBy: lockedBy,
+		Repeats:  repeats,
+		Now:      time.Now().UTC(),
+	})
+
+	logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": challengeID,
+		"goal_id":      goalID,
+		"blockers":     blockers,
+	}).Debug("Computed goal activation preflight")
+
+	return &ActivationPreflight{
+		Allowed:  len(blockers) == 0,
+		Blockers: blockers,
+	}, nil
+}
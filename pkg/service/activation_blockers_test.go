@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivationBlockers(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	newProgress := func() *domain.UserGoalProgress {
+		return &domain.UserGoalProgress{
+			GoalID:      "g",
+			ChallengeID: "c",
+			Status:      domain.GoalStatusClaimed,
+			Progress:    10,
+			UpdatedAt:   now,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		goal     func(*domain.Goal)
+		progress func(*domain.UserGoalProgress) *domain.UserGoalProgress
+		state    func(*ActivationBlockerState)
+		want     []string
+	}{
+		{
+			name:     "no row",
+			progress: func(*domain.UserGoalProgress) *domain.UserGoalProgress { return nil },
+		},
+		{
+			name: "completed",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.Status = domain.GoalStatusCompleted
+				return p
+			},
+		},
+		{
+			name: "claimed",
+			want: []string{ActivationBlockerAlreadyClaimed},
+		},
+		{
+			name: "claimed and active",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.IsActive = true
+				return p
+			},
+		},
+		{
+			name: "claimed, rotated",
+			goal: func(g *domain.Goal) {
+				g.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+			},
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.UpdatedAt = now.Add(-48 * time.Hour)
+				return p
+			},
+		},
+		{
+			name: "claimed, rotation enabled, this period",
+			goal: func(g *domain.Goal) {
+				g.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+			},
+			want: []string{ActivationBlockerAlreadyClaimed},
+		},
+		{
+			name: "repeatable, cooling down",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				return resetRepeatableRow(p)
+			},
+			state: func(s *ActivationBlockerState) {
+				s.Repeats = map[string]GoalRepeat{"g": {TimesClaimed: 1, AvailableAt: now.Add(time.Hour)}}
+			},
+			want: []string{ActivationBlockerCoolingDown},
+		},
+		{
+			name: "repeatable, cooldown over",
+			state: func(s *ActivationBlockerState) {
+				s.Repeats = map[string]GoalRepeat{"g": {TimesClaimed: 1, AvailableAt: now}}
+			},
+		},
+		{
+			name: "stored under another challenge",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.ChallengeID = "other"
+				p.Status = domain.GoalStatusInProgress
+				return p
+			},
+			want: []string{ActivationBlockerChallengeMismatch},
+		},
+		{
+			name:  "challenge locked",
+			state: func(s *ActivationBlockerState) { s.LockedBy = []string{"tutorial"} },
+			want:  []string{ActivationBlockerChallengeLocked, ActivationBlockerAlreadyClaimed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := &domain.Goal{ID: "g", ChallengeID: "c"}
+			if tt.goal != nil {
+				tt.goal(goal)
+			}
+			progress := newProgress()
+			if tt.progress != nil {
+				progress = tt.progress(progress)
+			}
+			state := ActivationBlockerState{Progress: progress, Now: now}
+			if tt.state != nil {
+				tt.state(&state)
+			}
+
+			assert.Equal(t, tt.want, ActivationBlockers(goal, "c", state))
+		})
+	}
+}
+
+func TestGetActivationPreflight(t *testing.T) {
+	goalCache := newChainGoalCache()
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"t1"}).Return([]*domain.UserGoalProgress{claimed("t1")}, nil)
+	mockRepo.On("GetProgress", mock.Anything, "user1", "s1a").
+		Return(&domain.UserGoalProgress{GoalID: "s1a", ChallengeID: "season-1", Status: domain.GoalStatusCompleted}, nil)
+
+	preflight, err := GetActivationPreflight(context.Background(), "user1", "season-1", "s1a", goalCache, mockRepo, chainPrerequisites, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, &ActivationPreflight{Allowed: true}, preflight)
+}
+
+func TestGetActivationPreflight_Locked(t *testing.T) {
+	goalCache := newChainGoalCache()
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"t1"}).Return(nil, nil)
+	mockRepo.On("GetProgress", mock.Anything, "user1", "s1a").Return(nil, nil)
+
+	preflight, err := GetActivationPreflight(context.Background(), "user1", "season-1", "s1a", goalCache, mockRepo, chainPrerequisites, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, &ActivationPreflight{Allowed: false, Blockers: []string{ActivationBlockerChallengeLocked}}, preflight)
+}
+
+func TestGetActivationPreflight_GoalNotFound(t *testing.T) {
+	_, err := GetActivationPreflight(context.Background(), "user1", "season-1", "missing", newChainGoalCache(), new(mocks.GoalRepository), nil, logrus.StandardLogger())
+
+	var notFound *mapper.GoalNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestGetActivationPreflight_NilLogger(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+
+	_, err := GetActivationPreflight(context.Background(), "user1", "season-1", "s1a", newChainGoalCache(), mockRepo, chainPrerequisites, nil)
+
+	assert.EqualError(t, err, "logger cannot be nil")
+	mockRepo.AssertNotCalled(t, "GetProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// A claimed repeatable row is left for the activation to reset; the claim it
+// counts starts the cooldown.
+func TestGetActivationPreflight_RepeatableClaimedRow(t *testing.T) {
+	claimedAt := time.Now().UTC().Add(-time.Hour)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetProgress", mock.Anything, "user1", "t1").Return(&domain.UserGoalProgress{
+		GoalID: "t1", ChallengeID: "tutorial", Status: domain.GoalStatusClaimed, ClaimedAt: &claimedAt, IsActive: true,
+	}, nil)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("GetRepeatStates", mock.Anything, "user1", []string{"t1"}).
+		Return(map[string]repository.RepeatState{"t1": {TimesClaimed: 1, LastClaimedAt: claimedAt.Add(-48 * time.Hour)}}, nil)
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"t1": {Cooldown: 24 * time.Hour}}, repeats)
+	preflight, err := GetActivationPreflight(ctx, "user1", "tutorial", "t1", newChainGoalCache(), mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{ActivationBlockerCoolingDown}, preflight.Blockers)
+	repeats.AssertNotCalled(t, "ResetClaimed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetGoalActive_ClaimedGoalRefused(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetProgress", mock.Anything, "user1", "t1").
+		Return(&domain.UserGoalProgress{GoalID: "t1", ChallengeID: "tutorial", Status: domain.GoalStatusClaimed}, nil)
+
+	_, err := SetGoalActive(context.Background(), "user1", "tutorial", "t1", "test-namespace", true, newChainGoalCache(), mockRepo, logrus.StandardLogger())
+
+	var alreadyClaimed *mapper.GoalAlreadyClaimedError
+	require.ErrorAs(t, err, &alreadyClaimed)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Activation sources stored in user_goal_progress.activation_source: how a goal
+// was last activated.
+const (
+	// ActivationSourceDefault marks default-assigned goals activated by InitializePlayer.
+	ActivationSourceDefault = "default"
+	// ActivationSourceRandom marks goals activated by RandomSelectGoals.
+	ActivationSourceRandom = "random"
+	// ActivationSourceManual marks goals activated by BatchSelectGoals or SetGoalActive.
+	ActivationSourceManual = "manual"
+	// ActivationSourceAdmin marks goals activated by an admin (ForceCompleteGoal with force).
+	ActivationSourceAdmin = "admin"
+	// ActivationSourceAutoExpired marks goals deactivated by the abandoned goal
+	// sweep (see AbandonedGoalSweeper); the only deactivation that sets a source.
+	ActivationSourceAutoExpired = "auto_expired"
+)
+
+// ResolveActivationSources records source for the activated goals and returns
+// the source of each of goalIDs: source for the activated ones, the stored one
+// for the rest (absent when none is stored).
+//
+// The source is analytics data written after the activation itself, so
+// failures are logged and do not fail the request; the affected goals are left
+// out of the result. A nil repository records and returns nothing.
+func ResolveActivationSources(
+	ctx context.Context,
+	sources serviceRepo.ActivationSourceRepository,
+	userID string,
+	activated []string,
+	source string,
+	goalIDs []string,
+	logger logrus.FieldLogger,
+) map[string]string {
+	if sources == nil {
+		return nil
+	}
+
+	resolved := make(map[string]string, len(goalIDs))
+	if len(activated) > 0 {
+		if err := sources.SetActivationSource(ctx, userID, activated, source); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":    userID,
+				"goal_count": len(activated),
+				"source":     source,
+				"error":      err,
+			}).Warn("Failed to record goal activation source")
+		} else {
+			for _, goalID := range activated {
+				resolved[goalID] = source
+			}
+		}
+	}
+
+	var stored []string
+	for _, goalID := range goalIDs {
+		if !slices.Contains(activated, goalID) {
+			stored = append(stored, goalID)
+		}
+	}
+	if len(stored) == 0 {
+		return resolved
+	}
+
+	loaded, err := sources.GetActivationSources(ctx, userID, stored)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"goal_count": len(stored),
+			"error":      err,
+		}).Warn("Failed to load goal activation sources")
+		return resolved
+	}
+	for goalID, goalSource := range loaded {
+		resolved[goalID] = goalSource
+	}
+
+	return resolved
+}
+
+// ResolveInitializeActivationSources records the default source of the goals
+// InitializePlayer just assigned and fills in the stored source of the others.
+func ResolveInitializeActivationSources(
+	ctx context.Context,
+	sources serviceRepo.ActivationSourceRepository,
+	userID string,
+	result *InitializeResponse,
+	logger logrus.FieldLogger,
+) {
+	goalIDs := make([]string, len(result.AssignedGoals))
+	var assigned []string
+	for i, goal := range result.AssignedGoals {
+		goalIDs[i] = goal.GoalID
+		if goal.ActivationSource == ActivationSourceDefault {
+			assigned = append(assigned, goal.GoalID)
+		}
+	}
+
+	resolved := ResolveActivationSources(ctx, sources, userID, assigned, ActivationSourceDefault, goalIDs, logger)
+	for _, goal := range result.AssignedGoals {
+		goal.ActivationSource = resolved[goal.GoalID]
+	}
+}
+
+// ResolveSelectionActivationSources records source for the goals a selection
+// activated and fills in the source of every selected goal.
+func ResolveSelectionActivationSources(
+	ctx context.Context,
+	sources serviceRepo.ActivationSourceRepository,
+	userID string,
+	result *GoalSelectionResult,
+	source string,
+	logger logrus.FieldLogger,
+) {
+	goalIDs := make([]string, len(result.SelectedGoals))
+	for i, goal := range result.SelectedGoals {
+		goalIDs[i] = goal.GoalID
+	}
+
+	resolved := ResolveActivationSources(ctx, sources, userID, result.ActivatedGoalIDs, source, goalIDs, logger)
+	for _, goal := range result.SelectedGoals {
+		goal.ActivationSource = resolved[goal.GoalID]
+	}
+}
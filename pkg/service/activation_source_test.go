@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolveActivationSources(t *testing.T) {
+	sources := new(mocks.ActivationSourceRepository)
+	sources.On("SetActivationSource", mock.Anything, "user1", []string{"g1"}, ActivationSourceRandom).Return(nil)
+	sources.On("GetActivationSources", mock.Anything, "user1", []string{"g2", "g3"}).
+		Return(map[string]string{"g2": ActivationSourceDefault}, nil)
+
+	resolved := ResolveActivationSources(context.Background(), sources, "user1", []string{"g1"}, ActivationSourceRandom, []string{"g1", "g2", "g3"}, logrus.StandardLogger())
+
+	assert.Equal(t, map[string]string{"g1": ActivationSourceRandom, "g2": ActivationSourceDefault}, resolved)
+	sources.AssertExpectations(t)
+}
+
+func TestResolveActivationSources_FailuresAreNotFatal(t *testing.T) {
+	sources := new(mocks.ActivationSourceRepository)
+	sources.On("SetActivationSource", mock.Anything, "user1", []string{"g1"}, ActivationSourceManual).Return(errors.New("db down"))
+	sources.On("GetActivationSources", mock.Anything, "user1", []string{"g2"}).Return(nil, errors.New("db down"))
+
+	resolved := ResolveActivationSources(context.Background(), sources, "user1", []string{"g1"}, ActivationSourceManual, []string{"g1", "g2"}, logrus.StandardLogger())
+
+	assert.Empty(t, resolved)
+	sources.AssertExpectations(t)
+}
+
+func TestResolveActivationSources_NilRepository(t *testing.T) {
+	assert.Nil(t, ResolveActivationSources(context.Background(), nil, "user1", []string{"g1"}, ActivationSourceManual, []string{"g1"}, logrus.StandardLogger()))
+}
+
+func TestResolveInitializeActivationSources(t *testing.T) {
+	sources := new(mocks.ActivationSourceRepository)
+	sources.On("SetActivationSource", mock.Anything, "user1", []string{"new"}, ActivationSourceDefault).Return(nil)
+	sources.On("GetActivationSources", mock.Anything, "user1", []string{"existing"}).
+		Return(map[string]string{"existing": ActivationSourceManual}, nil)
+
+	result := &InitializeResponse{AssignedGoals: []*AssignedGoal{
+		{GoalID: "new", ActivationSource: ActivationSourceDefault},
+		{GoalID: "existing"},
+	}}
+	ResolveInitializeActivationSources(context.Background(), sources, "user1", result, logrus.StandardLogger())
+
+	assert.Equal(t, ActivationSourceDefault, result.AssignedGoals[0].ActivationSource)
+	assert.Equal(t, ActivationSourceManual, result.AssignedGoals[1].ActivationSource, "a returning player's goals keep their source")
+	sources.AssertExpectations(t)
+}
+
+func TestResolveSelectionActivationSources(t *testing.T) {
+	sources := new(mocks.ActivationSourceRepository)
+	sources.On("SetActivationSource", mock.Anything, "user1", []string{"g1"}, ActivationSourceRandom).Return(nil)
+	sources.On("GetActivationSources", mock.Anything, "user1", []string{"g2"}).
+		Return(map[string]string{"g2": ActivationSourceAdmin}, nil)
+
+	result := &GoalSelectionResult{
+		SelectedGoals:    []*SelectedGoalInfo{{GoalID: "g1"}, {GoalID: "g2"}},
+		ActivatedGoalIDs: []string{"g1"},
+	}
+	ResolveSelectionActivationSources(context.Background(), sources, "user1", result, ActivationSourceRandom, logrus.StandardLogger())
+
+	assert.Equal(t, ActivationSourceRandom, result.SelectedGoals[0].ActivationSource)
+	assert.Equal(t, ActivationSourceAdmin, result.SelectedGoals[1].ActivationSource, "already active goals keep their source")
+	sources.AssertExpectations(t)
+}
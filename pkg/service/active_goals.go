@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultActiveGoalsLimit is the number of goals GetActiveGoals returns when
+	// the caller does not set a limit.
+	DefaultActiveGoalsLimit = 50
+	// MaxActiveGoalsLimit caps the goals returned per call.
+	MaxActiveGoalsLimit = 500
+)
+
+// ActiveGoals is the result of GetActiveGoals.
+type ActiveGoals struct {
+	// Goals are ordered by AssignedAt, then GoalID; goals without an
+	// assignment time come last.
+	Goals []*AssignedGoal
+	// Truncated is true when more active goals exist than were returned.
+	Truncated bool
+}
+
+// GetActiveGoals returns a user's active goals with their progress, oldest
+// assignment first. This helper function is used by the GetActiveGoals RPC handler.
+//
+// Only the user's active progress rows are read (GetActiveGoals); static goal
+// data comes from the cache, and rows of goals removed from the config are left
+// out. A limit of 0 uses DefaultActiveGoalsLimit; larger limits are capped at
+// MaxActiveGoalsLimit.
+func GetActiveGoals(
+	ctx context.Context,
+	userID string,
+	limit int,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
+) (*ActiveGoals, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if repo == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	limit = clampLimit(limit, DefaultActiveGoalsLimit, MaxActiveGoalsLimit)
+
+	progresses, err := repo.GetActiveGoals(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active goals: %w", err)
+	}
+
+	goals := mapToAssignedGoals(progresses, nil, goalCache, logger)
+	sort.Slice(goals, func(i, j int) bool {
+		return assignedBefore(goals[i], goals[j])
+	})
+
+	result := &ActiveGoals{Goals: goals}
+	if len(goals) > limit {
+		result.Goals = goals[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// assignedBefore orders goals by AssignedAt, then GoalID. Goals without an
+// AssignedAt sort after the assigned ones.
+func assignedBefore(a, b *AssignedGoal) bool {
+	switch {
+	case a.AssignedAt == nil && b.AssignedAt == nil:
+	case a.AssignedAt == nil:
+		return false
+	case b.AssignedAt == nil:
+		return true
+	case !a.AssignedAt.Equal(*b.AssignedAt):
+		return a.AssignedAt.Before(*b.AssignedAt)
+	}
+	return a.GoalID < b.GoalID
+}
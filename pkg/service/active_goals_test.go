@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func activeProgress(goalID string, assignedAt *time.Time) *domain.UserGoalProgress {
+	return &domain.UserGoalProgress{
+		UserID:      "user1",
+		GoalID:      goalID,
+		ChallengeID: "c1",
+		Status:      domain.GoalStatusInProgress,
+		Progress:    2,
+		IsActive:    true,
+		AssignedAt:  assignedAt,
+	}
+}
+
+func TestGetActiveGoals_OrderedByAssignment(t *testing.T) {
+	first := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	mockCache := new(mocks.GoalCache)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		mockCache.On("GetGoalByID", id).Return(&domain.Goal{ID: id, ChallengeID: "c1", Name: "Goal " + id, Requirement: domain.Requirement{TargetValue: 5}})
+	}
+	mockCache.On("GetGoalByID", "removed").Return(nil)
+	mockRepo := new(mocks.GoalRepository)
+	// The repository orders by challenge and goal ID
+	mockRepo.On("GetActiveGoals", mock.Anything, "user1").Return([]*domain.UserGoalProgress{
+		activeProgress("a", nil),
+		activeProgress("b", &second),
+		activeProgress("c", &first),
+		activeProgress("d", &first),
+		activeProgress("removed", &first),
+	}, nil)
+
+	result, err := GetActiveGoals(context.Background(), "user1", 0, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	var goalIDs []string
+	for _, goal := range result.Goals {
+		goalIDs = append(goalIDs, goal.GoalID)
+	}
+	assert.Equal(t, []string{"c", "d", "b", "a"}, goalIDs, "by assigned_at, then goal ID, unassigned last")
+	assert.False(t, result.Truncated)
+	assert.Equal(t, "Goal c", result.Goals[0].Name)
+	assert.Equal(t, 2, result.Goals[0].Progress)
+	assert.Equal(t, 5, result.Goals[0].Target)
+}
+
+func TestGetActiveGoals_Limit(t *testing.T) {
+	assignedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	progresses := make([]*domain.UserGoalProgress, 0, MaxActiveGoalsLimit+1)
+	for i := 0; i <= MaxActiveGoalsLimit; i++ {
+		id := fmt.Sprintf("goal-%03d", i)
+		mockCache.On("GetGoalByID", id).Return(&domain.Goal{ID: id, ChallengeID: "c1", Requirement: domain.Requirement{TargetValue: 1}})
+		progresses = append(progresses, activeProgress(id, &assignedAt))
+	}
+	mockRepo.On("GetActiveGoals", mock.Anything, "user1").Return(progresses, nil)
+
+	tests := []struct {
+		name          string
+		limit         int
+		wantLen       int
+		wantTruncated bool
+	}{
+		{"default", 0, DefaultActiveGoalsLimit, true},
+		{"explicit", 3, 3, true},
+		{"capped", MaxActiveGoalsLimit + 100, MaxActiveGoalsLimit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetActiveGoals(context.Background(), "user1", tt.limit, mockCache, mockRepo, logrus.StandardLogger())
+
+			require.NoError(t, err)
+			assert.Len(t, result.Goals, tt.wantLen)
+			assert.Equal(t, tt.wantTruncated, result.Truncated)
+			assert.Equal(t, "goal-000", result.Goals[0].GoalID)
+		})
+	}
+}
+
+func TestGetActiveGoals_Errors(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetActiveGoals", mock.Anything, "user1").Return(nil, errors.New("db down"))
+
+	_, err := GetActiveGoals(context.Background(), "user1", 0, new(mocks.GoalCache), mockRepo, logrus.StandardLogger())
+	assert.ErrorContains(t, err, "db down")
+
+	_, err = GetActiveGoals(context.Background(), "", 0, new(mocks.GoalCache), mockRepo, logrus.StandardLogger())
+	assert.Error(t, err)
+}
+
+// benchActiveGoalRepository serves GetActiveGoals from memory; testify mocks
+// would dominate the benchmark by formatting every argument.
+type benchActiveGoalRepository struct {
+	repository.GoalRepository
+	progresses []*domain.UserGoalProgress
+}
+
+func (r benchActiveGoalRepository) GetActiveGoals(context.Context, string) ([]*domain.UserGoalProgress, error) {
+	return r.progresses, nil
+}
+
+// BenchmarkGetActiveGoals measures the in-process cost of a current quests HUD
+// request for a player with 100 active goals, with the database call stubbed out.
+func BenchmarkGetActiveGoals(b *testing.B) {
+	const goals = 100
+
+	challenge := &domain.Challenge{ID: "bench"}
+	repo := benchActiveGoalRepository{}
+	assignedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < goals; i++ {
+		id := fmt.Sprintf("goal-%03d", i)
+		challenge.Goals = append(challenge.Goals, &domain.Goal{ID: id, ChallengeID: "bench", Requirement: domain.Requirement{StatCode: "kills", TargetValue: 100}})
+		// Reverse assignment order, so every call sorts
+		at := assignedAt.Add(time.Duration(goals-i) * time.Minute)
+		repo.progresses = append(repo.progresses, &domain.UserGoalProgress{
+			UserID:      "player",
+			GoalID:      id,
+			ChallengeID: "bench",
+			Status:      domain.GoalStatusInProgress,
+			Progress:    i,
+			IsActive:    true,
+			AssignedAt:  &at,
+		})
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{challenge}}, "", slog.Default())
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetActiveGoals(ctx, "player", DefaultActiveGoalsLimit, goalCache, repo, logrus.StandardLogger()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AutoRandomSelections maps challenge IDs to the goal count of their
+// "autoRandomSelect" from the challenge config: how many of the challenge's
+// goals InitializePlayer randomly activates for a new player, as if the player
+// had called RandomSelectGoals. Challenges not listed, and a nil
+// AutoRandomSelections, are left to the selection endpoints.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so it is read
+// from the config file separately by LoadAutoRandomSelections.
+type AutoRandomSelections map[string]int
+
+// autoRandomSelectConfig is the subset of challenges.json needed to read the setting.
+type autoRandomSelectConfig struct {
+	Challenges []struct {
+		ID               string `json:"challengeId"`
+		AutoRandomSelect *struct {
+			Count int `json:"count"`
+		} `json:"autoRandomSelect"`
+	} `json:"challenges"`
+}
+
+// LoadAutoRandomSelections reads the challenges' autoRandomSelect from the
+// challenge config file.
+func LoadAutoRandomSelections(configPath string) (AutoRandomSelections, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseAutoRandomSelections(data)
+}
+
+// ParseAutoRandomSelections extracts the challenges' autoRandomSelect from
+// challenge config JSON. A count must be positive.
+func ParseAutoRandomSelections(data []byte) (AutoRandomSelections, error) {
+	var cfg autoRandomSelectConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	selections := make(AutoRandomSelections)
+	for _, challenge := range cfg.Challenges {
+		if challenge.AutoRandomSelect == nil {
+			continue
+		}
+		if challenge.AutoRandomSelect.Count <= 0 {
+			return nil, fmt.Errorf("challenge %q: autoRandomSelect count must be positive, got %d",
+				challenge.ID, challenge.AutoRandomSelect.Count)
+		}
+		selections[challenge.ID] = challenge.AutoRandomSelect.Count
+	}
+
+	return selections, nil
+}
+
+// AutoRandomSelector runs the AutoRandomSelections of new players. It is
+// passed to InitializePlayer with WithAutoRandomSelector and only runs when
+// the player has no goal rows yet, so returning players never get goals
+// reselected and their fast path is unchanged.
+//
+// Each challenge is selected like RandomSelectGoals with exclude_active set:
+// locked challenges (see ChallengePrerequisites) and challenges not rolled out
+// to the player (see ChallengeRollouts) are skipped, the selection is
+// recorded in the selection history, the goals get ActivationSourceRandom and a
+// goals.selected event is written. A failed selection is logged and skipped
+// rather than failing the login; it is not retried at later logins, so the
+// player picks those goals with the selection endpoints instead.
+type AutoRandomSelector struct {
+	selections     AutoRandomSelections
+	prerequisites  ChallengePrerequisites
+	rollouts       ChallengeRollouts
+	history        serviceRepo.GoalSelectionRepository
+	repeatable     RepeatableGoals
+	repeatableRepo serviceRepo.RepeatableGoalRepository
+	sources        serviceRepo.ActivationSourceRepository
+	outbox         serviceRepo.EventOutboxRepository
+}
+
+// NewAutoRandomSelector creates a selector for selections, skipping challenges
+// locked by prerequisites.
+func NewAutoRandomSelector(selections AutoRandomSelections, prerequisites ChallengePrerequisites) *AutoRandomSelector {
+	return &AutoRandomSelector{
+		selections:    selections,
+		prerequisites: prerequisites,
+	}
+}
+
+// SetChallengeRollouts sets the challenges available to a percentage of
+// players; the others are skipped. It must be called before the server starts serving.
+func (s *AutoRandomSelector) SetChallengeRollouts(rollouts ChallengeRollouts) {
+	s.rollouts = rollouts
+}
+
+// SetGoalSelections sets the repository the selections are recorded in.
+// It must be called before the server starts serving.
+func (s *AutoRandomSelector) SetGoalSelections(history serviceRepo.GoalSelectionRepository) {
+	s.history = history
+}
+
+// SetRepeatableGoals sets the repeatable goals whose cooldown the selections
+// respect. It must be called before the server starts serving.
+func (s *AutoRandomSelector) SetRepeatableGoals(goals RepeatableGoals, repo serviceRepo.RepeatableGoalRepository) {
+	s.repeatable = goals
+	s.repeatableRepo = repo
+}
+
+// SetActivationSources sets the repository the selected goals' source is
+// recorded in. It must be called before the server starts serving.
+func (s *AutoRandomSelector) SetActivationSources(sources serviceRepo.ActivationSourceRepository) {
+	s.sources = sources
+}
+
+// SetEventOutbox sets the outbox the goals.selected events are written to.
+// It must be called before the server starts serving.
+func (s *AutoRandomSelector) SetEventOutbox(outbox serviceRepo.EventOutboxRepository) {
+	s.outbox = outbox
+}
+
+// Enabled reports whether any challenge has autoRandomSelect.
+func (s *AutoRandomSelector) Enabled() bool {
+	return s != nil && len(s.selections) > 0
+}
+
+type autoRandomSelectorKey struct{}
+
+// WithAutoRandomSelector returns a context whose InitializePlayer runs the
+// selections of selector for new players. A nil or disabled selector leaves
+// ctx unchanged.
+func WithAutoRandomSelector(ctx context.Context, selector *AutoRandomSelector) context.Context {
+	if !selector.Enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, autoRandomSelectorKey{}, selector)
+}
+
+// autoRandomSelectorFrom returns the selector set by WithAutoRandomSelector, or nil.
+func autoRandomSelectorFrom(ctx context.Context) *AutoRandomSelector {
+	selector, _ := ctx.Value(autoRandomSelectorKey{}).(*AutoRandomSelector)
+	return selector
+}
+
+// apply runs the selections for a new player, in challenge ID order, and adds
+// the goals they activated to result.
+func (s *AutoRandomSelector) apply(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
+	result *InitializeResponse,
+) {
+	selectCtx := WithRepeatableGoals(ctx, s.repeatable, s.repeatableRepo)
+
+	for _, challengeID := range slices.Sorted(maps.Keys(s.selections)) {
+		count := s.selections[challengeID]
+		fields := logrus.Fields{
+			"user_id":      userID,
+			"namespace":    namespace,
+			"challenge_id": challengeID,
+			"count":        count,
+		}
+
+		if !s.rollouts.IsAvailable(userID, challengeID) {
+			logger.WithFields(fields).Debug("Challenge not rolled out to the player, skipping auto random selection")
+			continue
+		}
+
+		if err := s.prerequisites.CheckUnlocked(ctx, repo, goalCache, userID, challengeID); err != nil {
+			logger.WithFields(fields).WithError(err).Info("Challenge not unlocked, skipping auto random selection")
+			continue
+		}
+
+		selection, err := RandomSelectGoals(
+			selectCtx,
+			userID,
+			challengeID,
+			count,
+			false, // replaceExisting
+			true,  // excludeActive
+			false, // strict
+			namespace,
+			goalCache,
+			repo,
+			s.history,
+			logger,
+		)
+		if err != nil {
+			logger.WithFields(fields).WithError(err).Warn("Auto random selection failed, skipping it")
+			continue
+		}
+		ResolveSelectionActivationSources(ctx, s.sources, userID, selection, ActivationSourceRandom, logger)
+		EnqueueGoalsSelected(ctx, s.outbox, namespace, userID, selection, ActivationSourceRandom, logger)
+
+		for _, goal := range selection.SelectedGoals {
+			if goal.Changed {
+				result.AssignedGoals = append(result.AssignedGoals, selectedToAssignedGoal(challengeID, goal, goalCache))
+			}
+		}
+		result.NewAssignments += selection.ChangedCount
+		result.TotalActive += selection.ChangedCount
+
+		logger.WithFields(fields).WithField("selected", selection.ChangedCount).Info("Auto random selected goals for new player")
+	}
+}
+
+// selectedToAssignedGoal converts a goal activated by a selection.
+func selectedToAssignedGoal(challengeID string, goal *SelectedGoalInfo, goalCache cache.GoalCache) *AssignedGoal {
+	assigned := &AssignedGoal{
+		ChallengeID:      challengeID,
+		GoalID:           goal.GoalID,
+		Name:             goal.Name,
+		Description:      goal.Description,
+		IsActive:         goal.IsActive,
+		AssignedAt:       goal.AssignedAt,
+		ExpiresAt:        goal.ExpiresAt,
+		Progress:         goal.Progress,
+		Target:           goal.Target,
+		Status:           goal.Status,
+		Requirement:      goal.Requirement,
+		Reward:           goal.Reward,
+		ActivationSource: goal.ActivationSource,
+	}
+	if configured := goalCache.GetGoalByID(goal.GoalID); configured != nil {
+		assigned.ProgressMode = configured.Requirement.ProgressMode
+		assigned.EventSource = configured.EventSource
+	}
+	return assigned
+}
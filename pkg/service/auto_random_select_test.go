@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAutoRandomSelections(t *testing.T) {
+	selections, err := ParseAutoRandomSelections([]byte(`{"challenges":[
+		{"challengeId":"daily","autoRandomSelect":{"count":3}},
+		{"challengeId":"season"}
+	]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, AutoRandomSelections{"daily": 3}, selections)
+}
+
+func TestParseAutoRandomSelections_InvalidCount(t *testing.T) {
+	for _, config := range []string{
+		`{"challenges":[{"challengeId":"daily","autoRandomSelect":{"count":0}}]}`,
+		`{"challenges":[{"challengeId":"daily","autoRandomSelect":{}}]}`,
+		`{"challenges":[{"challengeId":"daily","autoRandomSelect":{"count":-1}}]}`,
+	} {
+		_, err := ParseAutoRandomSelections([]byte(config))
+		assert.ErrorContains(t, err, `challenge "daily": autoRandomSelect count must be positive`, config)
+	}
+}
+
+func TestLoadAutoRandomSelections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"daily","autoRandomSelect":{"count":2}}]}`), 0o600))
+
+	selections, err := LoadAutoRandomSelections(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, AutoRandomSelections{"daily": 2}, selections)
+}
+
+func TestAutoRandomSelector_Enabled(t *testing.T) {
+	var nilSelector *AutoRandomSelector
+	assert.False(t, nilSelector.Enabled())
+	assert.False(t, NewAutoRandomSelector(nil, nil).Enabled())
+	assert.True(t, NewAutoRandomSelector(AutoRandomSelections{"daily": 1}, nil).Enabled())
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithAutoRandomSelector(ctx, NewAutoRandomSelector(nil, nil)), "a disabled selector leaves ctx unchanged")
+}
+
+// newAutoSelectGoalCache returns a cache with a default-assigned "starter" goal
+// and a "daily" challenge of 5 goals to select from.
+func newAutoSelectGoalCache(withDefault bool) commonCache.GoalCache {
+	daily := createTestChallengeWithGoals("daily", 5)
+	challenges := []*domain.Challenge{daily}
+	if withDefault {
+		challenges = append(challenges, &domain.Challenge{ID: "starter", Goals: []*domain.Goal{{
+			ID:              "starter-goal",
+			ChallengeID:     "starter",
+			DefaultAssigned: true,
+			EventSource:     domain.EventSourceLogin,
+			Requirement:     domain.Requirement{StatCode: "login", Operator: ">=", TargetValue: 1},
+		}}})
+	}
+	return commonCache.NewInMemoryGoalCache(&config.Config{Challenges: challenges}, "", slog.Default())
+}
+
+func TestInitializePlayer_AutoRandomSelect_FirstLogin(t *testing.T) {
+	goalCache := newAutoSelectGoalCache(true)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
+	sources := new(mocks.ActivationSourceRepository)
+
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(0, nil)
+	mockRepo.On("BulkInsert", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user1", "daily", false).Return([]*domain.UserGoalProgress{}, nil)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockTx.On("BatchUpsertGoalActive", mock.Anything, mock.AnythingOfType("[]*domain.UserGoalProgress")).Return(nil)
+	mockTx.On("CountActiveGoals", mock.Anything, "user1", "daily").Return(3, nil)
+	mockTx.On("Commit").Return(nil)
+	mockTx.On("Rollback").Return(nil)
+	sources.On("SetActivationSource", mock.Anything, "user1", mock.Anything, ActivationSourceRandom).Return(nil)
+
+	selector := NewAutoRandomSelector(AutoRandomSelections{"daily": 3}, nil)
+	selector.SetActivationSources(sources)
+	ctx := WithAutoRandomSelector(context.Background(), selector)
+
+	result, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.NewAssignments)
+	assert.Equal(t, 4, result.TotalActive)
+	require.Len(t, result.AssignedGoals, 4)
+	assert.Equal(t, "starter-goal", result.AssignedGoals[0].GoalID)
+	assert.Equal(t, ActivationSourceDefault, result.AssignedGoals[0].ActivationSource)
+	for _, goal := range result.AssignedGoals[1:] {
+		assert.Equal(t, "daily", goal.ChallengeID)
+		assert.True(t, goal.IsActive)
+		assert.Equal(t, domain.EventSourceStatistic, goal.EventSource)
+		assert.Equal(t, ActivationSourceRandom, goal.ActivationSource)
+	}
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+	sources.AssertExpectations(t)
+}
+
+func TestInitializePlayer_AutoRandomSelect_ReturningPlayerIsNotReselected(t *testing.T) {
+	goalCache := newAutoSelectGoalCache(true)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(4, nil)
+	mockRepo.On("GetActiveGoals", mock.Anything, "user1").Return([]*domain.UserGoalProgress{}, nil)
+
+	ctx := WithAutoRandomSelector(context.Background(), NewAutoRandomSelector(AutoRandomSelections{"daily": 3}, nil))
+	result, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.NewAssignments)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+}
+
+func TestInitializePlayer_AutoRandomSelect_NoDefaultGoals(t *testing.T) {
+	goalCache := newAutoSelectGoalCache(false)
+
+	t.Run("new player", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockTx := new(mocks.TxRepository)
+		mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(0, nil)
+		mockRepo.On("GetChallengeProgress", mock.Anything, "user1", "daily", false).Return([]*domain.UserGoalProgress{}, nil)
+		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockTx.On("BatchUpsertGoalActive", mock.Anything, mock.Anything).Return(nil)
+		mockTx.On("CountActiveGoals", mock.Anything, "user1", "daily").Return(2, nil)
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+
+		ctx := WithAutoRandomSelector(context.Background(), NewAutoRandomSelector(AutoRandomSelections{"daily": 2}, nil))
+		result, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.NewAssignments)
+		assert.Len(t, result.AssignedGoals, 2)
+	})
+
+	t.Run("returning player", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(2, nil)
+
+		ctx := WithAutoRandomSelector(context.Background(), NewAutoRandomSelector(AutoRandomSelections{"daily": 2}, nil))
+		result, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+		require.NoError(t, err)
+		assert.Empty(t, result.AssignedGoals)
+		mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("count error", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(0, errors.New("db down"))
+
+		ctx := WithAutoRandomSelector(context.Background(), NewAutoRandomSelector(AutoRandomSelections{"daily": 2}, nil))
+		_, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+		assert.ErrorContains(t, err, "failed to get user goal count")
+	})
+}
+
+func TestInitializePlayer_AutoRandomSelect_FailureDoesNotFailLogin(t *testing.T) {
+	goalCache := newAutoSelectGoalCache(true)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user1").Return(0, nil)
+	mockRepo.On("BulkInsert", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user1", "daily", false).Return(nil, errors.New("db down"))
+
+	// "removed" is no longer in the config
+	ctx := WithAutoRandomSelector(context.Background(), NewAutoRandomSelector(AutoRandomSelections{"daily": 3, "removed": 1}, nil))
+	result, err := InitializePlayer(ctx, "user1", "test-namespace", goalCache, mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.NewAssignments)
+	require.Len(t, result.AssignedGoals, 1)
+	assert.Equal(t, "starter-goal", result.AssignedGoals[0].GoalID)
+}
+
+func TestAutoRandomSelector_SkipsLockedChallenges(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"t1"}).Return([]*domain.UserGoalProgress{}, nil)
+
+	selector := NewAutoRandomSelector(AutoRandomSelections{"season-1": 1}, chainPrerequisites)
+	result := &InitializeResponse{}
+	selector.apply(context.Background(), "user1", "test-namespace", newChainGoalCache(), mockRepo, logrus.StandardLogger(), result)
+
+	assert.Empty(t, result.AssignedGoals)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAutoRandomSelector_SkipsChallengesNotRolledOut(t *testing.T) {
+	mockRepo := new(mocks.GoalRepository)
+
+	selector := NewAutoRandomSelector(AutoRandomSelections{"daily": 3}, nil)
+	selector.SetChallengeRollouts(ChallengeRollouts{"daily": 0})
+	result := &InitializeResponse{}
+	selector.apply(context.Background(), "user1", "test-namespace", newAutoSelectGoalCache(false), mockRepo, logrus.StandardLogger(), result)
+
+	assert.Empty(t, result.AssignedGoals)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
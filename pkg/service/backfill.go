@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultBackfillBatchSize is the number of users one backfill batch scans.
+	DefaultBackfillBatchSize = 1000
+
+	// DefaultBackfillUsersPerSecond caps the users a backfill scans per second.
+	DefaultBackfillUsersPerSecond = 5000
+
+	// DefaultBackfillPollInterval is how often Run looks for running jobs it was
+	// not woken for, e.g. jobs left running by a stopped replica.
+	DefaultBackfillPollInterval = time.Minute
+)
+
+var (
+	// ErrBackfillGoalNotFound is returned when the backfilled goal is not in the config.
+	ErrBackfillGoalNotFound = errors.New("goal not found")
+
+	// ErrBackfillGoalNotDefault is returned when the backfilled goal is not default-assigned.
+	ErrBackfillGoalNotDefault = errors.New("goal is not default-assigned")
+)
+
+// BackfillConfig limits the database load of default goal backfills.
+type BackfillConfig struct {
+	// BatchSize is the number of users scanned, and at most the rows inserted
+	// with one COPY, per transaction.
+	BatchSize int
+	// UsersPerSecond caps the users scanned per second by this replica.
+	UsersPerSecond int
+	// PollInterval is how often Run looks for running jobs.
+	PollInterval time.Duration
+}
+
+// NewBackfillConfigFromEnv reads the backfill limits from:
+//   - BACKFILL_BATCH_SIZE: users scanned per transaction (default 1000)
+//   - BACKFILL_USERS_PER_SECOND: users scanned per second (default 5000)
+//   - BACKFILL_POLL_INTERVAL: how often running jobs are looked for (default "1m")
+//
+// Non-positive values fall back to the defaults; invalid ones are logged to logger.
+func NewBackfillConfigFromEnv(logger logrus.FieldLogger) BackfillConfig {
+	config := BackfillConfig{
+		BatchSize:      common.GetEnvInt("BACKFILL_BATCH_SIZE", DefaultBackfillBatchSize),
+		UsersPerSecond: common.GetEnvInt("BACKFILL_USERS_PER_SECOND", DefaultBackfillUsersPerSecond),
+		PollInterval:   parseClaimRecoveryDuration("BACKFILL_POLL_INTERVAL", DefaultBackfillPollInterval, 0, logger),
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBackfillBatchSize
+	}
+	if config.UsersPerSecond <= 0 {
+		config.UsersPerSecond = DefaultBackfillUsersPerSecond
+	}
+	return config
+}
+
+// Backfills creates a default goal's progress rows for the users who had
+// progress before the goal was added to the config, instead of waiting for
+// their next InitializePlayer.
+//
+// Start records a running job; Run works through running jobs one batch (one
+// transaction) at a time, pacing batches to BackfillConfig.UsersPerSecond. The
+// job's cursor advances with each batch, so a job interrupted by a restart is
+// picked up where it stopped, and a failed job resumes there when started
+// again. Several replicas can run the same job: a batch locks the job row, and
+// rows that already exist are skipped.
+type Backfills struct {
+	repo      serviceRepo.BackfillRepository
+	goalCache cache.GoalCache
+	namespace string
+	config    BackfillConfig
+	rollouts  ChallengeRollouts
+	logger    logrus.FieldLogger
+	now       func() time.Time
+	wake      chan struct{}
+
+	rowsInserted *prometheus.CounterVec
+}
+
+// NewBackfills creates default goal backfills stored in repo that log through
+// logger.
+func NewBackfills(
+	repo serviceRepo.BackfillRepository,
+	goalCache cache.GoalCache,
+	namespace string,
+	config BackfillConfig,
+	logger logrus.FieldLogger,
+) *Backfills {
+	return &Backfills{
+		repo:      repo,
+		goalCache: goalCache,
+		namespace: namespace,
+		config:    config,
+		logger:    logger,
+		now:       func() time.Time { return time.Now().UTC() },
+		wake:      make(chan struct{}, 1),
+		rowsInserted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "backfill_rows_inserted_total",
+			Help: "Progress rows created by default goal backfills, by goal.",
+		}, []string{"goal_id"}),
+	}
+}
+
+// SetChallengeRollouts sets the challenges available to a percentage of users
+// only. Their goals are not backfilled for the other users. It must be called
+// before Run.
+func (b *Backfills) SetChallengeRollouts(rollouts ChallengeRollouts) {
+	b.rollouts = rollouts
+}
+
+// Collectors returns the backfill metrics for registration.
+func (b *Backfills) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{b.rowsInserted}
+}
+
+// Start starts backfilling goalID, which must be a default-assigned goal with a
+// positive target (mapper.ConfigInvalidError otherwise). A running job is
+// returned unchanged and a completed one too, unless restart is set; a failed
+// job resumes after the last user it processed. restart starts over from the
+// first user.
+func (b *Backfills) Start(ctx context.Context, goalID, actorUserID string, restart bool) (*serviceRepo.BackfillJob, error) {
+	goal := b.goalCache.GetGoalByID(goalID)
+	if goal == nil {
+		return nil, ErrBackfillGoalNotFound
+	}
+	if !goal.DefaultAssigned {
+		return nil, ErrBackfillGoalNotDefault
+	}
+	if err := checkGoalAssignable(goal, goalGuardDefaultAssignment, b.logger); err != nil {
+		return nil, err
+	}
+
+	job, err := b.repo.GetBackfillJob(ctx, goalID, b.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill job: %w", err)
+	}
+
+	now := b.now()
+	switch {
+	case job == nil || restart:
+		job = &serviceRepo.BackfillJob{
+			GoalID:    goalID,
+			Namespace: b.namespace,
+			StartedAt: now,
+		}
+	case job.Status == serviceRepo.BackfillStatusRunning, job.Status == serviceRepo.BackfillStatusCompleted:
+		return job, nil
+	}
+
+	// New, restarted or failed: (re)run from the stored cursor
+	job.Status = serviceRepo.BackfillStatusRunning
+	job.Error = ""
+	job.ActorUserID = actorUserID
+	job.UpdatedAt = now
+	job.CompletedAt = nil
+	if err := b.repo.SaveBackfillJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to save backfill job: %w", err)
+	}
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// StartFromEnv starts backfilling the goals listed in BACKFILL_DEFAULT_GOALS
+// (comma-separated goal IDs). Goals already backfilled are not backfilled again,
+// so the variable can stay set across restarts.
+func (b *Backfills) StartFromEnv(ctx context.Context) {
+	for _, goalID := range strings.Split(common.GetEnv("BACKFILL_DEFAULT_GOALS", ""), ",") {
+		goalID = strings.TrimSpace(goalID)
+		if goalID == "" {
+			continue
+		}
+
+		job, err := b.Start(ctx, goalID, "", false)
+		if err != nil {
+			b.logger.WithError(err).WithField("goal_id", goalID).Error("Failed to start default goal backfill")
+			continue
+		}
+		b.logger.WithFields(logrus.Fields{
+			"goal_id":       goalID,
+			"status":        job.Status,
+			"rows_inserted": job.RowsInserted,
+		}).Info("Default goal backfill requested by BACKFILL_DEFAULT_GOALS")
+	}
+}
+
+// Get returns the goal's job, or nil if it was never started.
+func (b *Backfills) Get(ctx context.Context, goalID string) (*serviceRepo.BackfillJob, error) {
+	job, err := b.repo.GetBackfillJob(ctx, goalID, b.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill job: %w", err)
+	}
+	return job, nil
+}
+
+// Run works through the running jobs when Start is called and every
+// PollInterval, until ctx is cancelled.
+func (b *Backfills) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		b.RunPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunPending runs the running jobs to completion, oldest first.
+func (b *Backfills) RunPending(ctx context.Context) {
+	jobs, err := b.repo.ListBackfillJobs(ctx, b.namespace, serviceRepo.BackfillStatusRunning)
+	if err != nil {
+		if ctx.Err() == nil {
+			b.logger.WithError(err).Warn("Failed to list running backfill jobs")
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		b.runJob(ctx, job.GoalID, job.RowsInserted)
+	}
+}
+
+// runJob processes the job's batches until it completes, fails, or another
+// replica holds it. rowsInserted is the job's count before the first batch.
+func (b *Backfills) runJob(ctx context.Context, goalID string, rowsInserted int64) {
+	var scanned int64
+	started := time.Now()
+
+	for ctx.Err() == nil {
+		goal := b.goalCache.GetGoalByID(goalID)
+		if goal == nil || !goal.DefaultAssigned {
+			b.fail(ctx, goalID, "goal is no longer a default-assigned goal in the config")
+			return
+		}
+		if err := checkGoalAssignable(goal, goalGuardDefaultAssignment, b.logger); err != nil {
+			b.fail(ctx, goalID, err.Error())
+			return
+		}
+
+		job, err := b.repo.ProcessBackfillBatch(ctx, goalID, b.namespace, b.config.BatchSize, func(userIDs []string) []*domain.UserGoalProgress {
+			return b.defaultRows(goal, userIDs)
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				b.fail(ctx, goalID, err.Error())
+			}
+			return
+		}
+		if job == nil {
+			// Not running any more, or another replica is running a batch
+			return
+		}
+
+		if job.RowsInserted > rowsInserted {
+			b.rowsInserted.WithLabelValues(goalID).Add(float64(job.RowsInserted - rowsInserted))
+		}
+		rowsInserted = job.RowsInserted
+		if job.Status == serviceRepo.BackfillStatusCompleted {
+			b.logger.WithFields(logrus.Fields{
+				"goal_id":       goalID,
+				"users_scanned": job.UsersScanned,
+				"users_skipped": job.UsersSkipped,
+				"rows_inserted": job.RowsInserted,
+			}).Info("Default goal backfill completed")
+			return
+		}
+
+		scanned += int64(b.config.BatchSize)
+		if !b.config.pace(ctx, started, scanned) {
+			return
+		}
+	}
+}
+
+// pace waits until scanned users since started are within UsersPerSecond. It
+// returns false if ctx is cancelled first.
+func (c BackfillConfig) pace(ctx context.Context, started time.Time, scanned int64) bool {
+	wait := time.Until(started.Add(time.Duration(scanned) * time.Second / time.Duration(c.UsersPerSecond)))
+	if wait <= 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
+
+// defaultRows returns the goal's progress rows for users, as InitializePlayer creates them.
+// Users the goal's challenge is not rolled out to get none.
+func (b *Backfills) defaultRows(goal *domain.Goal, userIDs []string) []*domain.UserGoalProgress {
+	now := b.now()
+	expiresAt := rotation.CalculateNextExpiresAt(goal, now)
+
+	rows := make([]*domain.UserGoalProgress, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !b.rollouts.IsAvailable(userID, goal.ChallengeID) {
+			continue
+		}
+		rows = append(rows, &domain.UserGoalProgress{
+			UserID:      userID,
+			GoalID:      goal.ID,
+			ChallengeID: goal.ChallengeID,
+			Namespace:   b.namespace,
+			Status:      domain.GoalStatusNotStarted,
+			IsActive:    true,
+			AssignedAt:  &now,
+			ExpiresAt:   expiresAt,
+		})
+	}
+	return rows
+}
+
+// fail marks the job failed with reason; starting it again resumes it.
+func (b *Backfills) fail(ctx context.Context, goalID, reason string) {
+	b.logger.WithFields(logrus.Fields{
+		"goal_id": goalID,
+		"error":   reason,
+	}).Error("Default goal backfill failed")
+
+	job, err := b.repo.GetBackfillJob(ctx, goalID, b.namespace)
+	if err != nil || job == nil {
+		return
+	}
+	job.Status = serviceRepo.BackfillStatusFailed
+	job.Error = reason
+	job.UpdatedAt = b.now()
+	if err := b.repo.SaveBackfillJob(ctx, job); err != nil {
+		b.logger.WithError(err).WithField("goal_id", goalID).Warn("Failed to mark backfill job failed")
+	}
+}
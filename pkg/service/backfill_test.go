@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var backfillNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newBackfillGoalCache() *mocks.GoalCache {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "new-goal").Return(&domain.Goal{ID: "new-goal", ChallengeID: "c1", DefaultAssigned: true, Requirement: domain.Requirement{TargetValue: 1}})
+	goalCache.On("GetGoalByID", "optional-goal").Return(&domain.Goal{ID: "optional-goal", ChallengeID: "c1"})
+	goalCache.On("GetGoalByID", "missing").Return(nil)
+	return goalCache
+}
+
+func newTestBackfills(repo repository.BackfillRepository, goalCache *mocks.GoalCache) *Backfills {
+	backfills := NewBackfills(repo, goalCache, "ns", BackfillConfig{BatchSize: 2, UsersPerSecond: 1 << 30, PollInterval: time.Minute}, logrus.StandardLogger())
+	backfills.now = func() time.Time { return backfillNow }
+	return backfills
+}
+
+func TestBackfills_Start_NewJob(t *testing.T) {
+	repo := new(mocks.BackfillRepository)
+	repo.On("GetBackfillJob", mock.Anything, "new-goal", "ns").Return(nil, nil)
+	repo.On("SaveBackfillJob", mock.Anything, mock.MatchedBy(func(job *repository.BackfillJob) bool {
+		return job.Status == repository.BackfillStatusRunning && job.LastUserID == "" &&
+			job.ActorUserID == "admin-1" && job.StartedAt.Equal(backfillNow)
+	})).Return(nil)
+
+	backfills := newTestBackfills(repo, newBackfillGoalCache())
+	job, err := backfills.Start(context.Background(), "new-goal", "admin-1", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, repository.BackfillStatusRunning, job.Status)
+	assert.Len(t, backfills.wake, 1, "Run is woken up")
+	repo.AssertExpectations(t)
+}
+
+func TestBackfills_Start_ExistingJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		restart        bool
+		wantSave       bool
+		wantLastUserID string
+	}{
+		{"running is returned", repository.BackfillStatusRunning, false, false, "user-5"},
+		{"completed is returned", repository.BackfillStatusCompleted, false, false, "user-5"},
+		{"failed resumes", repository.BackfillStatusFailed, false, true, "user-5"},
+		{"restart starts over", repository.BackfillStatusCompleted, true, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &repository.BackfillJob{
+				GoalID:     "new-goal",
+				Namespace:  "ns",
+				Status:     tt.status,
+				LastUserID: "user-5",
+				Error:      "connection reset",
+				StartedAt:  backfillNow.Add(-time.Hour),
+			}
+			repo := new(mocks.BackfillRepository)
+			repo.On("GetBackfillJob", mock.Anything, "new-goal", "ns").Return(existing, nil)
+			if tt.wantSave {
+				repo.On("SaveBackfillJob", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			job, err := newTestBackfills(repo, newBackfillGoalCache()).Start(context.Background(), "new-goal", "admin-1", tt.restart)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLastUserID, job.LastUserID)
+			if tt.wantSave {
+				assert.Equal(t, repository.BackfillStatusRunning, job.Status)
+				assert.Empty(t, job.Error)
+			} else {
+				assert.Equal(t, tt.status, job.Status)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBackfills_Start_RejectsGoal(t *testing.T) {
+	backfills := newTestBackfills(new(mocks.BackfillRepository), newBackfillGoalCache())
+
+	_, err := backfills.Start(context.Background(), "missing", "admin-1", false)
+	assert.ErrorIs(t, err, ErrBackfillGoalNotFound)
+
+	_, err = backfills.Start(context.Background(), "optional-goal", "admin-1", false)
+	assert.ErrorIs(t, err, ErrBackfillGoalNotDefault)
+}
+
+func TestBackfills_RunPending(t *testing.T) {
+	repo := new(mocks.BackfillRepository)
+	repo.On("ListBackfillJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BackfillJob{{GoalID: "new-goal", RowsInserted: 10}}, nil)
+
+	var built []*domain.UserGoalProgress
+	build := func(args mock.Arguments) {
+		built = append(built, args.Get(4).(func([]string) []*domain.UserGoalProgress)([]string{"user-1"})...)
+	}
+	repo.On("ProcessBackfillBatch", mock.Anything, "new-goal", "ns", 2, mock.Anything).Run(build).
+		Return(&repository.BackfillJob{Status: repository.BackfillStatusRunning, RowsInserted: 12}, nil).Once()
+	repo.On("ProcessBackfillBatch", mock.Anything, "new-goal", "ns", 2, mock.Anything).Run(build).
+		Return(&repository.BackfillJob{Status: repository.BackfillStatusCompleted, RowsInserted: 13}, nil).Once()
+
+	backfills := newTestBackfills(repo, newBackfillGoalCache())
+	backfills.RunPending(context.Background())
+
+	repo.AssertExpectations(t)
+	assert.Equal(t, 3.0, testutil.ToFloat64(backfills.rowsInserted.WithLabelValues("new-goal")),
+		"only rows inserted by this replica are counted")
+	require.Len(t, built, 2)
+	assert.Equal(t, &domain.UserGoalProgress{
+		UserID:      "user-1",
+		GoalID:      "new-goal",
+		ChallengeID: "c1",
+		Namespace:   "ns",
+		Status:      domain.GoalStatusNotStarted,
+		IsActive:    true,
+		AssignedAt:  &backfillNow,
+	}, built[0])
+}
+
+func TestBackfills_RunPending_LockedByAnotherReplica(t *testing.T) {
+	repo := new(mocks.BackfillRepository)
+	repo.On("ListBackfillJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BackfillJob{{GoalID: "new-goal"}}, nil)
+	repo.On("ProcessBackfillBatch", mock.Anything, "new-goal", "ns", 2, mock.Anything).Return(nil, nil).Once()
+
+	newTestBackfills(repo, newBackfillGoalCache()).RunPending(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestBackfills_RunPending_SkipsUsersOutsideRollout(t *testing.T) {
+	var eligible, ineligible string
+	for i := 0; eligible == "" || ineligible == ""; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		if RolloutBucket(userID, "c1") < 50 {
+			eligible = userID
+		} else {
+			ineligible = userID
+		}
+	}
+
+	repo := new(mocks.BackfillRepository)
+	repo.On("ListBackfillJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BackfillJob{{GoalID: "new-goal"}}, nil)
+	var built []*domain.UserGoalProgress
+	repo.On("ProcessBackfillBatch", mock.Anything, "new-goal", "ns", 2, mock.Anything).Run(func(args mock.Arguments) {
+		built = args.Get(4).(func([]string) []*domain.UserGoalProgress)([]string{eligible, ineligible})
+	}).Return(&repository.BackfillJob{Status: repository.BackfillStatusCompleted, RowsInserted: 1}, nil).Once()
+
+	backfills := newTestBackfills(repo, newBackfillGoalCache())
+	backfills.SetChallengeRollouts(ChallengeRollouts{"c1": 50})
+	backfills.RunPending(context.Background())
+
+	repo.AssertExpectations(t)
+	require.Len(t, built, 1)
+	assert.Equal(t, eligible, built[0].UserID)
+}
+
+func TestBackfills_RunPending_FailsJob(t *testing.T) {
+	tests := []struct {
+		name      string
+		goalID    string
+		batchErr  error
+		wantError string
+	}{
+		{"batch error", "new-goal", errors.New("connection reset"), "connection reset"},
+		{"goal no longer default", "optional-goal", nil, "goal is no longer a default-assigned goal in the config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.BackfillRepository)
+			repo.On("ListBackfillJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+				Return([]*repository.BackfillJob{{GoalID: tt.goalID}}, nil)
+			if tt.batchErr != nil {
+				repo.On("ProcessBackfillBatch", mock.Anything, tt.goalID, "ns", 2, mock.Anything).Return(nil, tt.batchErr)
+			}
+			repo.On("GetBackfillJob", mock.Anything, tt.goalID, "ns").
+				Return(&repository.BackfillJob{GoalID: tt.goalID, Status: repository.BackfillStatusRunning, LastUserID: "user-5"}, nil)
+			repo.On("SaveBackfillJob", mock.Anything, mock.MatchedBy(func(job *repository.BackfillJob) bool {
+				return job.Status == repository.BackfillStatusFailed && job.Error == tt.wantError && job.LastUserID == "user-5"
+			})).Return(nil)
+
+			newTestBackfills(repo, newBackfillGoalCache()).RunPending(context.Background())
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewBackfillConfigFromEnv(t *testing.T) {
+	t.Setenv("BACKFILL_BATCH_SIZE", "250")
+	t.Setenv("BACKFILL_USERS_PER_SECOND", "-1")
+
+	config := NewBackfillConfigFromEnv(logrus.StandardLogger())
+
+	assert.Equal(t, 250, config.BatchSize)
+	assert.Equal(t, DefaultBackfillUsersPerSecond, config.UsersPerSecond)
+	assert.Equal(t, DefaultBackfillPollInterval, config.PollInterval)
+}
@@ -0,0 +1,624 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultBatchProgressMaxEvents is the default maximum number of events per batch.
+	DefaultBatchProgressMaxEvents = 10000
+
+	// DefaultBatchProgressChunkSize is the default number of progress rows written per COPY.
+	DefaultBatchProgressChunkSize = 1000
+)
+
+// Progress event result statuses.
+const (
+	// ProgressEventApplied means at least one goal was updated and none failed.
+	ProgressEventApplied = "applied"
+	// ProgressEventUnknownStat means no goal tracks the event's stat code.
+	ProgressEventUnknownStat = "unknown_stat"
+	// ProgressEventSkipped means every goal tracking the stat code was skipped.
+	ProgressEventSkipped = "skipped"
+	// ProgressEventInvalid means the event was malformed and not processed.
+	ProgressEventInvalid = "invalid"
+	// ProgressEventFailed means writing at least one goal failed. Only failed events should
+	// be resent, since deltas of applied goals would otherwise be counted twice.
+	ProgressEventFailed = "failed"
+)
+
+// Reasons a goal tracking an event's stat code was not updated.
+const (
+	// SkipReasonInactive means the goal is not active for the user (including never assigned)
+	// and its progress is not tracked while inactive.
+	SkipReasonInactive = "inactive"
+	// SkipReasonClaimed means the goal is already claimed and cannot be reselected.
+	SkipReasonClaimed = "claimed"
+	// SkipReasonValueRequired means an absolute goal received a delta instead of a value.
+	SkipReasonValueRequired = "value_required"
+	// SkipReasonDeltaRequired means a relative goal received a value instead of a delta.
+	SkipReasonDeltaRequired = "delta_required"
+	// SkipReasonLate means the event occurred in an earlier period of a rotating goal
+	// and the challenge's LateEventPolicy did not apply it (see LateEventPolicies).
+	SkipReasonLate = "late"
+	// SkipReasonIllegalTransition means the update would have moved the goal to a
+	// status its stored status cannot reach (see progressstate.Transition).
+	SkipReasonIllegalTransition = "illegal_transition"
+)
+
+// maxEventClockSkew is how far in the future an event's OccurredAt may be, to
+// allow for game server clocks running ahead.
+const maxEventClockSkew = 5 * time.Minute
+
+// ErrProgressBatchTooLarge is returned when a batch has more events than BatchProgressConfig.MaxEvents.
+var ErrProgressBatchTooLarge = errors.New("progress batch too large")
+
+// BatchProgressConfig limits BatchReportProgress.
+type BatchProgressConfig struct {
+	// MaxEvents is the maximum number of events accepted in one batch.
+	MaxEvents int
+	// ChunkSize is the number of progress rows looked up and written per database round trip.
+	ChunkSize int
+}
+
+// NewBatchProgressConfigFromEnv reads the batch limits from:
+//   - BATCH_PROGRESS_MAX_EVENTS: maximum events per batch (default 10000)
+//   - BATCH_PROGRESS_CHUNK_SIZE: progress rows written per COPY (default 1000)
+//
+// Non-positive values fall back to the defaults.
+func NewBatchProgressConfigFromEnv() BatchProgressConfig {
+	config := BatchProgressConfig{
+		MaxEvents: common.GetEnvInt("BATCH_PROGRESS_MAX_EVENTS", DefaultBatchProgressMaxEvents),
+		ChunkSize: common.GetEnvInt("BATCH_PROGRESS_CHUNK_SIZE", DefaultBatchProgressChunkSize),
+	}
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = DefaultBatchProgressMaxEvents
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = DefaultBatchProgressChunkSize
+	}
+	return config
+}
+
+// ProgressEvent is one stat update reported by a game server.
+// Exactly one of Delta and Value must be set.
+type ProgressEvent struct {
+	UserID   string
+	StatCode string
+	// Delta increments relative goals (progressMode "relative").
+	Delta *int
+	// Value sets absolute goals (progressMode "absolute", the default).
+	Value *int
+	// OccurredAt is when the stat changed, used to bucket the event into a period of
+	// rotating goals. Zero means now.
+	OccurredAt time.Time
+	// GoalID restricts the event to one goal tracking StatCode. Match goals
+	// sharing a match stat code have different filters, so their events
+	// (see ReportMatchResult) name the goal; match stat codes require it.
+	GoalID string
+}
+
+// SkippedGoal is a goal tracking an event's stat code that was not updated.
+type SkippedGoal struct {
+	GoalID string
+	Reason string
+}
+
+// ProgressEventResult is the outcome of one ProgressEvent, in request order.
+type ProgressEventResult struct {
+	UserID         string
+	StatCode       string
+	Status         string
+	AppliedGoalIDs []string
+	SkippedGoals   []SkippedGoal
+	FailedGoalIDs  []string
+	// Error explains an invalid or failed event.
+	Error string
+}
+
+// BatchProgressResult is the outcome of BatchReportProgress.
+type BatchProgressResult struct {
+	Results []*ProgressEventResult
+	// RowsWritten is the number of progress rows sent to the database after
+	// merging events for the same user and goal.
+	RowsWritten int
+}
+
+// pendingRow is the merged update for one user goal and the events that contributed to it.
+type pendingRow struct {
+	row    repository.CopyRow
+	events []int
+}
+
+// BatchReportProgress applies stat updates for many users at once, e.g. end-of-match
+// results from a dedicated game server.
+//
+// Flow:
+// 1. Resolve each event's stat code to goals via the goal cache
+// 2. Route values to absolute goals and deltas to relative goals, merging events for
+// the same user and goal (last value wins, deltas add up)
+// 3. Per chunk of ChunkSize rows: look up which goals are active, then write the
+// active ones with a single BatchUpsertProgressWithCOPY
+// 4. Write inactive rows of challenges that track inactive progress (see
+// InactiveProgressPolicy) with a single ApplyInactiveProgress
+// 5. Write late events routed to the previous period (see LateEventPolicies) with
+// ApplyLateProgress, per chunk, after the current period's rows
+// 6. Write the steps of multi-step goals (see GoalSteps) with ApplyStepProgress,
+// per chunk; these goals never go through the COPY upsert, which only knows one
+// requirement
+//
+// Only existing rows are updated; goals without a progress row are reported as skipped
+// (inactive), as are inactive goals of challenges with "trackInactiveProgress": false
+// and inactive rotating goals, whose periods are only tracked while active. A failed
+// chunk marks its events failed and processing continues with the next chunk.
+//
+// Failed writes and skipped multi-step updates are logged through logger.
+// Returns ErrProgressBatchTooLarge when len(events) exceeds config.MaxEvents.
+func BatchReportProgress(
+	ctx context.Context,
+	namespace string,
+	events []ProgressEvent,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	queries serviceRepo.ProgressQueryRepository,
+	inactive serviceRepo.InactiveProgressRepository,
+	late serviceRepo.LateProgressRepository,
+	stepProgress serviceRepo.StepProgressRepository,
+	policy InactiveProgressPolicy,
+	latePolicies LateEventPolicies,
+	steps GoalSteps,
+	config BatchProgressConfig,
+	logger logrus.FieldLogger,
+) (*BatchProgressResult, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if repo == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if queries == nil {
+		return nil, fmt.Errorf("progress query repository cannot be nil")
+	}
+
+	if inactive == nil {
+		return nil, fmt.Errorf("inactive progress repository cannot be nil")
+	}
+
+	if late == nil {
+		return nil, fmt.Errorf("late progress repository cannot be nil")
+	}
+
+	if len(steps) > 0 && stepProgress == nil {
+		return nil, fmt.Errorf("step progress repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	if len(events) > config.MaxEvents {
+		return nil, fmt.Errorf("%w: %d events (max %d)", ErrProgressBatchTooLarge, len(events), config.MaxEvents)
+	}
+
+	now := time.Now().UTC()
+	results := make([]*ProgressEventResult, len(events))
+	pending := make(map[serviceRepo.UserGoalKey]*pendingRow)
+	var order []serviceRepo.UserGoalKey
+	// Late events routed to the previous period, merged separately
+	latePending := make(map[serviceRepo.UserGoalKey]*pendingRow)
+	var lateOrder []serviceRepo.UserGoalKey
+	// Multi-step goals, merged per step
+	stepRefs := steps.stepsByStatCode()
+	stepPending := make(map[serviceRepo.UserGoalKey]*pendingSteps)
+	var stepOrder []serviceRepo.UserGoalKey
+
+	for i, event := range events {
+		result := &ProgressEventResult{UserID: event.UserID, StatCode: event.StatCode}
+		results[i] = result
+
+		if msg := validateProgressEvent(event); msg != "" {
+			result.Status = ProgressEventInvalid
+			result.Error = msg
+			continue
+		}
+
+		goals := goalCache.GetGoalsByStatCode(event.StatCode)
+		refs := stepRefs[event.StatCode]
+		if event.GoalID != "" {
+			goals = goalsWithID(goals, event.GoalID)
+			refs = nil
+		}
+		if len(goals) == 0 && len(refs) == 0 {
+			result.Status = ProgressEventUnknownStat
+			continue
+		}
+
+		for _, ref := range refs {
+			goal := goalCache.GetGoalByID(ref.goalID)
+			if goal == nil {
+				continue
+			}
+			relative := ref.step.relative()
+			if relative && event.Delta == nil {
+				result.SkippedGoals = append(result.SkippedGoals, SkippedGoal{GoalID: goal.ID, Reason: SkipReasonDeltaRequired})
+				continue
+			}
+			if !relative && event.Value == nil {
+				result.SkippedGoals = append(result.SkippedGoals, SkippedGoal{GoalID: goal.ID, Reason: SkipReasonValueRequired})
+				continue
+			}
+
+			key := serviceRepo.UserGoalKey{UserID: event.UserID, GoalID: goal.ID}
+			p, ok := stepPending[key]
+			if !ok {
+				n := len(steps.Steps(goal.ID))
+				p = &pendingSteps{challengeID: goal.ChallengeID, values: make([]*int, n), deltas: make([]int, n)}
+				stepPending[key] = p
+				stepOrder = append(stepOrder, key)
+			}
+
+			if relative {
+				p.deltas[ref.index] += *event.Delta
+			} else {
+				value := *event.Value
+				p.values[ref.index] = &value
+			}
+			p.events = append(p.events, i)
+		}
+
+		for _, goal := range goals {
+			if len(steps.Steps(goal.ID)) > 0 {
+				// Written through its steps above
+				continue
+			}
+			relative := goal.Requirement.ProgressMode == domain.ProgressModeRelative
+			if relative && event.Delta == nil {
+				result.SkippedGoals = append(result.SkippedGoals, SkippedGoal{GoalID: goal.ID, Reason: SkipReasonDeltaRequired})
+				continue
+			}
+			if !relative && event.Value == nil {
+				result.SkippedGoals = append(result.SkippedGoals, SkippedGoal{GoalID: goal.ID, Reason: SkipReasonValueRequired})
+				continue
+			}
+
+			key := serviceRepo.UserGoalKey{UserID: event.UserID, GoalID: goal.ID}
+			rows, rowOrder := pending, &order
+			row := newCopyRow(event.UserID, namespace, goal, now)
+			if row.RotationBoundary != nil {
+				route, previousStart := latePolicies.route(goal, event.OccurredAt, *row.RotationBoundary)
+				switch route {
+				case routeSkip:
+					result.SkippedGoals = append(result.SkippedGoals, SkippedGoal{GoalID: goal.ID, Reason: SkipReasonLate})
+					continue
+				case routePrevious:
+					// The previous period ends where the current one starts
+					periodEnd := *row.RotationBoundary
+					row.RotationBoundary = &previousStart
+					row.NewExpiresAt = &periodEnd
+					rows, rowOrder = latePending, &lateOrder
+				}
+			}
+
+			p, ok := rows[key]
+			if !ok {
+				p = &pendingRow{row: row}
+				rows[key] = p
+				*rowOrder = append(*rowOrder, key)
+			}
+
+			if relative {
+				p.row.IncValue += *event.Delta
+			} else {
+				value := *event.Value
+				p.row.Progress = &value
+			}
+			p.events = append(p.events, i)
+		}
+	}
+
+	rowsWritten := 0
+	for start := 0; start < len(order); start += config.ChunkSize {
+		end := min(start+config.ChunkSize, len(order))
+		rowsWritten += applyProgressChunk(ctx, namespace, order[start:end], pending, repo, queries, inactive, policy, results, logger)
+	}
+	for start := 0; start < len(lateOrder); start += config.ChunkSize {
+		end := min(start+config.ChunkSize, len(lateOrder))
+		rowsWritten += applyLateChunk(ctx, namespace, lateOrder[start:end], latePending, late, results, logger)
+	}
+	for start := 0; start < len(stepOrder); start += config.ChunkSize {
+		end := min(start+config.ChunkSize, len(stepOrder))
+		rowsWritten += applyStepChunk(ctx, namespace, stepOrder[start:end], stepPending, steps, stepProgress, policy, results, logger)
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		if result.Status == "" {
+			result.Status = progressEventStatus(result)
+		}
+		counts[result.Status]++
+	}
+
+	logger.WithFields(logrus.Fields{
+		"namespace":    namespace,
+		"events":       len(events),
+		"rows_written": rowsWritten,
+		"applied":      counts[ProgressEventApplied],
+		"skipped":      counts[ProgressEventSkipped],
+		"unknown_stat": counts[ProgressEventUnknownStat],
+		"invalid":      counts[ProgressEventInvalid],
+		"failed":       counts[ProgressEventFailed],
+	}).Info("Batch progress reported")
+
+	return &BatchProgressResult{Results: results, RowsWritten: rowsWritten}, nil
+}
+
+// applyProgressChunk writes one chunk of merged rows and records the outcome on
+// the contributing events. It returns the number of rows written.
+func applyProgressChunk(
+	ctx context.Context,
+	namespace string,
+	keys []serviceRepo.UserGoalKey,
+	pending map[serviceRepo.UserGoalKey]*pendingRow,
+	repo repository.GoalRepository,
+	queries serviceRepo.ProgressQueryRepository,
+	inactive serviceRepo.InactiveProgressRepository,
+	policy InactiveProgressPolicy,
+	results []*ProgressEventResult,
+	logger logrus.FieldLogger,
+) int {
+	fail := func(keys []serviceRepo.UserGoalKey, err error) {
+		for _, key := range keys {
+			for _, i := range pending[key].events {
+				results[i].FailedGoalIDs = append(results[i].FailedGoalIDs, key.GoalID)
+				results[i].Error = err.Error()
+			}
+		}
+	}
+
+	userIDs := make([]string, 0, len(keys))
+	goalIDs := make([]string, 0, len(keys))
+	seenUsers := make(map[string]bool)
+	seenGoals := make(map[string]bool)
+	for _, key := range keys {
+		if !seenUsers[key.UserID] {
+			seenUsers[key.UserID] = true
+			userIDs = append(userIDs, key.UserID)
+		}
+		if !seenGoals[key.GoalID] {
+			seenGoals[key.GoalID] = true
+			goalIDs = append(goalIDs, key.GoalID)
+		}
+	}
+
+	statuses, err := queries.GetActiveGoalStatuses(ctx, namespace, userIDs, goalIDs)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"rows":      len(keys),
+			"error":     err,
+		}).Error("Failed to look up active goals for batch progress")
+		fail(keys, fmt.Errorf("failed to look up active goals: %w", err))
+		return 0
+	}
+
+	skip := func(key serviceRepo.UserGoalKey, reason string) {
+		for _, i := range pending[key].events {
+			results[i].SkippedGoals = append(results[i].SkippedGoals, SkippedGoal{GoalID: key.GoalID, Reason: reason})
+		}
+	}
+	apply := func(key serviceRepo.UserGoalKey) {
+		for _, i := range pending[key].events {
+			results[i].AppliedGoalIDs = append(results[i].AppliedGoalIDs, key.GoalID)
+		}
+	}
+
+	rows := make([]repository.CopyRow, 0, len(keys))
+	writeKeys := make([]serviceRepo.UserGoalKey, 0, len(keys))
+	var inactiveRows []repository.CopyRow
+	var inactiveKeys []serviceRepo.UserGoalKey
+	for _, key := range keys {
+		p := pending[key]
+
+		goalStatus, active := statuses[key]
+		switch {
+		case !active && (!policy.TracksInactive(p.row.ChallengeID) || p.row.RotationBoundary != nil):
+			skip(key, SkipReasonInactive)
+		case !active:
+			inactiveRows = append(inactiveRows, p.row)
+			inactiveKeys = append(inactiveKeys, key)
+		case goalStatus == domain.GoalStatusClaimed && !p.row.AllowReselection:
+			skip(key, SkipReasonClaimed)
+		default:
+			rows = append(rows, p.row)
+			writeKeys = append(writeKeys, key)
+		}
+	}
+
+	written := 0
+	if len(rows) > 0 {
+		if err := repo.BatchUpsertProgressWithCOPY(ctx, rows); err != nil {
+			logger.WithFields(logrus.Fields{
+				"namespace": namespace,
+				"rows":      len(rows),
+				"error":     err,
+			}).Error("Failed to write batch progress chunk")
+			fail(writeKeys, fmt.Errorf("failed to write progress: %w", err))
+		} else {
+			for _, key := range writeKeys {
+				apply(key)
+			}
+			written += len(rows)
+		}
+	}
+
+	if len(inactiveRows) > 0 {
+		inactiveWritten, err := inactive.ApplyInactiveProgress(ctx, namespace, inactiveRows)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"namespace": namespace,
+				"rows":      len(inactiveRows),
+				"error":     err,
+			}).Error("Failed to write inactive batch progress")
+			fail(inactiveKeys, fmt.Errorf("failed to write progress: %w", err))
+			return written
+		}
+
+		// Rows not written are missing or claimed (or were activated meanwhile)
+		wasWritten := make(map[serviceRepo.UserGoalKey]bool, len(inactiveWritten))
+		for _, key := range inactiveWritten {
+			wasWritten[key] = true
+		}
+		for _, key := range inactiveKeys {
+			if wasWritten[key] {
+				apply(key)
+				written++
+			} else {
+				skip(key, SkipReasonInactive)
+			}
+		}
+	}
+
+	return written
+}
+
+// applyLateChunk writes one chunk of late rows to their previous period and
+// records the outcome on the contributing events. Rows that are missing,
+// inactive, claimed or already in the current period are skipped as late. It
+// returns the number of rows written.
+func applyLateChunk(
+	ctx context.Context,
+	namespace string,
+	keys []serviceRepo.UserGoalKey,
+	pending map[serviceRepo.UserGoalKey]*pendingRow,
+	late serviceRepo.LateProgressRepository,
+	results []*ProgressEventResult,
+	logger logrus.FieldLogger,
+) int {
+	rows := make([]repository.CopyRow, len(keys))
+	for i, key := range keys {
+		rows[i] = pending[key].row
+	}
+
+	written, err := late.ApplyLateProgress(ctx, namespace, rows)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"rows":      len(rows),
+			"error":     err,
+		}).Error("Failed to write late batch progress")
+		for _, key := range keys {
+			for _, i := range pending[key].events {
+				results[i].FailedGoalIDs = append(results[i].FailedGoalIDs, key.GoalID)
+				results[i].Error = fmt.Sprintf("failed to write progress: %v", err)
+			}
+		}
+		return 0
+	}
+
+	wasWritten := make(map[serviceRepo.UserGoalKey]bool, len(written))
+	for _, key := range written {
+		wasWritten[key] = true
+	}
+	for _, key := range keys {
+		for _, i := range pending[key].events {
+			if wasWritten[key] {
+				results[i].AppliedGoalIDs = append(results[i].AppliedGoalIDs, key.GoalID)
+			} else {
+				results[i].SkippedGoals = append(results[i].SkippedGoals, SkippedGoal{GoalID: key.GoalID, Reason: SkipReasonLate})
+			}
+		}
+	}
+
+	return len(written)
+}
+
+// newCopyRow builds the progress row for a user goal, including the rotation
+// metadata BatchUpsertProgressWithCOPY uses to reset stale periods.
+func newCopyRow(userID, namespace string, goal *domain.Goal, now time.Time) repository.CopyRow {
+	mode := goal.Requirement.ProgressMode
+	if mode == "" {
+		mode = domain.ProgressModeAbsolute
+	}
+
+	row := repository.CopyRow{
+		UserID:        userID,
+		GoalID:        goal.ID,
+		ChallengeID:   goal.ChallengeID,
+		Namespace:     namespace,
+		ProgressMode:  string(mode),
+		TargetValue:   goal.Requirement.TargetValue,
+		ResetProgress: true,
+	}
+
+	if goal.Rotation != nil && goal.Rotation.Enabled {
+		boundary := rotation.CalculateLastRotationBoundary(goal.Rotation.Schedule, now)
+		row.RotationBoundary = &boundary
+		row.NewExpiresAt = rotation.CalculateNextExpiresAt(goal, now)
+		row.AllowReselection = goal.Rotation.OnExpiry.AllowReselection
+		row.ResetProgress = goal.Rotation.OnExpiry.ResetProgress
+	}
+
+	return row
+}
+
+// validateProgressEvent returns why an event is invalid, or "" if it is valid.
+func validateProgressEvent(event ProgressEvent) string {
+	switch {
+	case event.UserID == "":
+		return "user_id is required"
+	case event.StatCode == "":
+		return "stat_code is required"
+	case (event.Delta == nil) == (event.Value == nil):
+		return "exactly one of delta and value is required"
+	case event.Delta != nil && *event.Delta < 1:
+		return "delta must be positive"
+	case event.Value != nil && *event.Value < 0:
+		return "value cannot be negative"
+	case event.OccurredAt.After(time.Now().Add(maxEventClockSkew)):
+		return "occurred_at is in the future"
+	case IsMatchStatCode(event.StatCode) && event.GoalID == "":
+		return "match stat codes are reported with match results, not stat updates"
+	}
+	return ""
+}
+
+// goalsWithID returns the goal of goals with the given ID, if any.
+func goalsWithID(goals []*domain.Goal, goalID string) []*domain.Goal {
+	for _, goal := range goals {
+		if goal.ID == goalID {
+			return []*domain.Goal{goal}
+		}
+	}
+	return nil
+}
+
+// progressEventStatus derives the status of an event whose goals were all processed.
+func progressEventStatus(result *ProgressEventResult) string {
+	switch {
+	case len(result.FailedGoalIDs) > 0:
+		return ProgressEventFailed
+	case len(result.AppliedGoalIDs) > 0:
+		return ProgressEventApplied
+	default:
+		return ProgressEventSkipped
+	}
+}
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testBatchProgressConfig = BatchProgressConfig{MaxEvents: 100, ChunkSize: 10}
+
+func intPtr(v int) *int { return &v }
+
+// batchProgressGoals returns an absolute "kills" goal, a relative "kills" goal and
+// a relative "wins" goal.
+func batchProgressGoals() (kills, killsWeekly, wins *domain.Goal) {
+	kills = &domain.Goal{
+		ID:          "kill-100",
+		ChallengeID: "combat",
+		Requirement: domain.Requirement{StatCode: "kills", TargetValue: 100, ProgressMode: domain.ProgressModeAbsolute},
+	}
+	killsWeekly = &domain.Goal{
+		ID:          "kill-10-weekly",
+		ChallengeID: "weekly",
+		Requirement: domain.Requirement{StatCode: "kills", TargetValue: 10, ProgressMode: domain.ProgressModeRelative},
+		Rotation: &domain.RotationConfig{
+			Enabled:  true,
+			Type:     domain.RotationTypeGlobal,
+			Schedule: domain.RotationScheduleWeekly,
+			OnExpiry: domain.OnExpiryConfig{ResetProgress: true, AllowReselection: true},
+		},
+	}
+	wins = &domain.Goal{
+		ID:          "win-5",
+		ChallengeID: "combat",
+		Requirement: domain.Requirement{StatCode: "wins", TargetValue: 5, ProgressMode: domain.ProgressModeRelative},
+	}
+	return kills, killsWeekly, wins
+}
+
+func newBatchProgressCache() *mocks.GoalCache {
+	kills, killsWeekly, wins := batchProgressGoals()
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalsByStatCode", "kills").Return([]*domain.Goal{kills, killsWeekly})
+	goalCache.On("GetGoalsByStatCode", "wins").Return([]*domain.Goal{wins})
+	goalCache.On("GetGoalsByStatCode", mock.Anything).Return([]*domain.Goal(nil))
+	return goalCache
+}
+
+func TestBatchReportProgress_AppliesAndReportsPerEvent(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{
+		{UserID: "user-1", GoalID: "kill-100"}:       domain.GoalStatusInProgress,
+		{UserID: "user-1", GoalID: "kill-10-weekly"}: domain.GoalStatusClaimed,
+		{UserID: "user-1", GoalID: "win-5"}:          domain.GoalStatusInProgress,
+		{UserID: "user-2", GoalID: "kill-100"}:       domain.GoalStatusClaimed,
+	}, nil)
+
+	var written []repository.CopyRow
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(1).([]repository.CopyRow)...)
+	}).Return(nil)
+
+	// user-3 has no progress row for win-5, so the inactive write skips it
+	inactive.On("ApplyInactiveProgress", ctx, "test-namespace", mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 1 && rows[0].UserID == "user-3" && rows[0].GoalID == "win-5"
+	})).Return([]serviceRepo.UserGoalKey(nil), nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(40)},
+		{UserID: "user-1", StatCode: "kills", Delta: intPtr(2)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+		{UserID: "user-2", StatCode: "kills", Value: intPtr(7)},
+		{UserID: "user-3", StatCode: "wins", Delta: intPtr(1)},
+		{UserID: "user-1", StatCode: "deaths", Value: intPtr(3)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), Value: intPtr(1)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	require.Len(t, result.Results, 8)
+
+	// Absolute goal gets the value; the relative goal skips it
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, []string{"kill-100"}, result.Results[0].AppliedGoalIDs)
+	assert.Equal(t, []SkippedGoal{{GoalID: "kill-10-weekly", Reason: SkipReasonDeltaRequired}}, result.Results[0].SkippedGoals)
+
+	// Claimed rotating goal with reselection is written; the absolute goal skips the delta
+	assert.Equal(t, ProgressEventApplied, result.Results[1].Status)
+	assert.Equal(t, []string{"kill-10-weekly"}, result.Results[1].AppliedGoalIDs)
+	assert.Equal(t, []SkippedGoal{{GoalID: "kill-100", Reason: SkipReasonValueRequired}}, result.Results[1].SkippedGoals)
+
+	assert.Equal(t, ProgressEventApplied, result.Results[2].Status)
+	assert.Equal(t, ProgressEventApplied, result.Results[3].Status)
+
+	assert.Equal(t, ProgressEventSkipped, result.Results[4].Status)
+	assert.Contains(t, result.Results[4].SkippedGoals, SkippedGoal{GoalID: "kill-100", Reason: SkipReasonClaimed})
+
+	assert.Equal(t, ProgressEventSkipped, result.Results[5].Status)
+	assert.Equal(t, []SkippedGoal{{GoalID: "win-5", Reason: SkipReasonInactive}}, result.Results[5].SkippedGoals)
+
+	assert.Equal(t, ProgressEventUnknownStat, result.Results[6].Status)
+	inactive.AssertExpectations(t)
+
+	assert.Equal(t, ProgressEventInvalid, result.Results[7].Status)
+	assert.NotEmpty(t, result.Results[7].Error)
+
+	// Deltas for the same user goal are merged into one row
+	assert.Equal(t, 3, result.RowsWritten)
+	require.Len(t, written, 3)
+	for _, row := range written {
+		switch row.GoalID {
+		case "kill-100":
+			require.NotNil(t, row.Progress)
+			assert.Equal(t, 40, *row.Progress)
+			assert.Equal(t, string(domain.ProgressModeAbsolute), row.ProgressMode)
+			assert.Nil(t, row.RotationBoundary)
+		case "win-5":
+			assert.Nil(t, row.Progress)
+			assert.Equal(t, 2, row.IncValue)
+			assert.Equal(t, 5, row.TargetValue)
+		case "kill-10-weekly":
+			assert.Equal(t, 2, row.IncValue)
+			assert.NotNil(t, row.RotationBoundary)
+			assert.NotNil(t, row.NewExpiresAt)
+			assert.True(t, row.AllowReselection)
+		default:
+			t.Fatalf("unexpected row for goal %s", row.GoalID)
+		}
+	}
+}
+
+func TestBatchReportProgress_LastValueWins(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", []string{"user-1"}, []string{"kill-100"}).
+		Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{{UserID: "user-1", GoalID: "kill-100"}: domain.GoalStatusInProgress}, nil)
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 1 && *rows[0].Progress == 55
+	})).Return(nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(50)},
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(55)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.RowsWritten)
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, ProgressEventApplied, result.Results[1].Status)
+	repo.AssertExpectations(t)
+}
+
+func TestBatchReportProgress_ChunksAndContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	events := make([]ProgressEvent, 25)
+	for i := range events {
+		events[i] = ProgressEvent{UserID: fmt.Sprintf("user-%02d", i), StatCode: "wins", Delta: intPtr(1)}
+	}
+
+	statuses := make(map[serviceRepo.UserGoalKey]domain.GoalStatus)
+	for _, event := range events {
+		statuses[serviceRepo.UserGoalKey{UserID: event.UserID, GoalID: "win-5"}] = domain.GoalStatusInProgress
+	}
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, []string{"win-5"}).Return(statuses, nil)
+	writeErr := errors.New("connection reset")
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Return(nil).Once()
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Return(writeErr).Once()
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Return(nil).Once()
+
+	result, err := BatchReportProgress(ctx, "test-namespace", events, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 15, result.RowsWritten)
+	for i, r := range result.Results {
+		if i >= 10 && i < 20 {
+			assert.Equal(t, ProgressEventFailed, r.Status, "event %d", i)
+			assert.Equal(t, []string{"win-5"}, r.FailedGoalIDs)
+			assert.Contains(t, r.Error, "connection reset")
+		} else {
+			assert.Equal(t, ProgressEventApplied, r.Status, "event %d", i)
+		}
+	}
+	repo.AssertNumberOfCalls(t, "BatchUpsertProgressWithCOPY", 3)
+	queries.AssertNumberOfCalls(t, "GetActiveGoalStatuses", 3)
+}
+
+func TestBatchReportProgress_TooLarge(t *testing.T) {
+	events := make([]ProgressEvent, testBatchProgressConfig.MaxEvents+1)
+
+	_, err := BatchReportProgress(context.Background(), "test-namespace", events,
+		new(mocks.GoalCache), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, ErrProgressBatchTooLarge)
+}
+
+func TestBatchReportProgress_ActiveLookupError(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(nil, errors.New("timeout"))
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventFailed, result.Results[0].Status)
+	repo.AssertNotCalled(t, "BatchUpsertProgressWithCOPY", mock.Anything, mock.Anything)
+}
+
+func TestBatchReportProgress_TracksInactiveProgressByDefault(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	// No goal is active for user-1
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).
+		Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{}, nil)
+	inactive.On("ApplyInactiveProgress", ctx, "test-namespace", mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 2 && rows[0].GoalID == "kill-100" && rows[1].GoalID == "win-5"
+	})).Return([]serviceRepo.UserGoalKey{
+		{UserID: "user-1", GoalID: "kill-100"},
+		{UserID: "user-1", GoalID: "win-5"},
+	}, nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(40)},
+		{UserID: "user-1", StatCode: "kills", Delta: intPtr(2)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.RowsWritten)
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, []string{"kill-100"}, result.Results[0].AppliedGoalIDs)
+	assert.Equal(t, ProgressEventApplied, result.Results[2].Status)
+
+	// Rotating goals only track progress while active
+	assert.Equal(t, ProgressEventSkipped, result.Results[1].Status)
+	assert.Contains(t, result.Results[1].SkippedGoals, SkippedGoal{GoalID: "kill-10-weekly", Reason: SkipReasonInactive})
+
+	repo.AssertNotCalled(t, "BatchUpsertProgressWithCOPY", mock.Anything, mock.Anything)
+	inactive.AssertExpectations(t)
+}
+
+func TestBatchReportProgress_SkipsInactiveWhenNotTracked(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	// kill-100 is active for user-1; win-5 is not
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).
+		Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{{UserID: "user-1", GoalID: "kill-100"}: domain.GoalStatusInProgress}, nil)
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 1 && rows[0].GoalID == "kill-100"
+	})).Return(nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(40)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, InactiveProgressPolicy{"combat": true}, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.RowsWritten)
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, ProgressEventSkipped, result.Results[1].Status)
+	assert.Equal(t, []SkippedGoal{{GoalID: "win-5", Reason: SkipReasonInactive}}, result.Results[1].SkippedGoals)
+	inactive.AssertNotCalled(t, "ApplyInactiveProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBatchReportProgress_InactiveWriteError(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newBatchProgressCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	inactive := new(mocks.InactiveProgressRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).
+		Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{}, nil)
+	inactive.On("ApplyInactiveProgress", ctx, "test-namespace", mock.Anything).Return(nil, errors.New("connection reset"))
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+	}, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.RowsWritten)
+	assert.Equal(t, ProgressEventFailed, result.Results[0].Status)
+	assert.Equal(t, []string{"win-5"}, result.Results[0].FailedGoalIDs)
+}
+
+func TestNewBatchProgressConfigFromEnv(t *testing.T) {
+	for _, key := range []string{"BATCH_PROGRESS_MAX_EVENTS", "BATCH_PROGRESS_CHUNK_SIZE"} {
+		t.Setenv(key, "") // restores the variable after the test
+		require.NoError(t, os.Unsetenv(key))
+	}
+	assert.Equal(t, BatchProgressConfig{MaxEvents: 10000, ChunkSize: 1000}, NewBatchProgressConfigFromEnv())
+
+	t.Setenv("BATCH_PROGRESS_MAX_EVENTS", "500")
+	t.Setenv("BATCH_PROGRESS_CHUNK_SIZE", "0")
+	assert.Equal(t, BatchProgressConfig{MaxEvents: 500, ChunkSize: 1000}, NewBatchProgressConfigFromEnv())
+}
+
+// benchGoalRepository and benchProgressQueries stub the two calls BatchReportProgress
+// makes; testify mocks would dominate the benchmark by formatting every argument.
+type benchGoalRepository struct {
+	repository.GoalRepository
+}
+
+func (benchGoalRepository) BatchUpsertProgressWithCOPY(context.Context, []repository.CopyRow) error {
+	return nil
+}
+
+type benchProgressQueries struct {
+	serviceRepo.ProgressQueryRepository
+	statuses map[serviceRepo.UserGoalKey]domain.GoalStatus
+}
+
+func (q benchProgressQueries) GetActiveGoalStatuses(context.Context, string, []string, []string) (map[serviceRepo.UserGoalKey]domain.GoalStatus, error) {
+	return q.statuses, nil
+}
+
+// BenchmarkBatchReportProgress_10kEvents measures the in-process cost of a 10k event
+// batch (100 players x 100 stat updates) with the database calls stubbed out.
+// See tests/integration for the same batch against PostgreSQL.
+func BenchmarkBatchReportProgress_10kEvents(b *testing.B) {
+	const players, statsPerPlayer = 100, 100
+
+	challenge := &domain.Challenge{ID: "bench"}
+	for s := 0; s < statsPerPlayer; s++ {
+		statCode := fmt.Sprintf("stat-%03d", s)
+		challenge.Goals = append(challenge.Goals,
+			&domain.Goal{ID: "abs-" + statCode, ChallengeID: "bench", Requirement: domain.Requirement{StatCode: statCode, TargetValue: 100}},
+			&domain.Goal{ID: "rel-" + statCode, ChallengeID: "bench", Requirement: domain.Requirement{StatCode: statCode, TargetValue: 100, ProgressMode: domain.ProgressModeRelative}},
+		)
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{challenge}}, "", slog.Default())
+
+	events := make([]ProgressEvent, 0, players*statsPerPlayer)
+	for p := 0; p < players; p++ {
+		for s := 0; s < statsPerPlayer; s++ {
+			event := ProgressEvent{UserID: fmt.Sprintf("player-%03d", p), StatCode: fmt.Sprintf("stat-%03d", s)}
+			if s%2 == 0 {
+				event.Value = intPtr(s)
+			} else {
+				event.Delta = intPtr(1)
+			}
+			events = append(events, event)
+		}
+	}
+
+	// Every goal is active for every player
+	statuses := make(map[serviceRepo.UserGoalKey]domain.GoalStatus, players*statsPerPlayer*2)
+	for _, event := range events {
+		for _, goal := range goalCache.GetGoalsByStatCode(event.StatCode) {
+			statuses[serviceRepo.UserGoalKey{UserID: event.UserID, GoalID: goal.ID}] = domain.GoalStatusInProgress
+		}
+	}
+	queries := benchProgressQueries{statuses: statuses}
+	repo := benchGoalRepository{}
+	inactive := new(mocks.InactiveProgressRepository) // every goal is active, so it is never called
+
+	batchConfig := BatchProgressConfig{MaxEvents: DefaultBatchProgressMaxEvents, ChunkSize: DefaultBatchProgressChunkSize}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := BatchReportProgress(ctx, "test-namespace", events, goalCache, repo, queries, inactive, new(mocks.LateProgressRepository), nil, nil, nil, nil, batchConfig, logrus.StandardLogger())
+		if err != nil || result.RowsWritten != players*statsPerPlayer {
+			b.Fatalf("BatchReportProgress: %+v, err=%v", result, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxBulkActivationUsers is the most users one bulk activation can list.
+const MaxBulkActivationUsers = 100000
+
+var (
+	// ErrBulkActivationGoalNotFound is returned when the activated goal is not in the config.
+	ErrBulkActivationGoalNotFound = errors.New("goal not found")
+
+	// ErrBulkActivationGoalRepeatable is returned for a repeatable goal, whose
+	// cooldown a bulk activation would bypass.
+	ErrBulkActivationGoalRepeatable = errors.New("repeatable goals cannot be bulk activated")
+
+	// ErrBulkActivationJobNotFound is returned when resuming a job that does not exist.
+	ErrBulkActivationJobNotFound = errors.New("bulk activation job not found")
+)
+
+// BulkActivationRequest is an admin request to activate a goal for a list of users.
+type BulkActivationRequest struct {
+	GoalID string
+	// UserIDs may be in any order and contain duplicates.
+	UserIDs []string
+	// Reason is stored in the audit record of every activated row.
+	Reason      string
+	ActorUserID string
+	// ClientIP is the address the admin's request came from ("" when unknown).
+	ClientIP string
+	// DryRun counts what the job would do without starting it.
+	DryRun bool
+}
+
+// BulkActivations activates a goal for a list of users, e.g. the players of a
+// tournament, without each of them opting in.
+//
+// They run like Backfills and share its BackfillConfig: Start records a running
+// job; Run works through running jobs one batch (one transaction) at a time,
+// paced to BackfillConfig.UsersPerSecond. The job's cursor advances with each
+// batch, so a job interrupted by a restart is picked up where it stopped, and a
+// failed job resumes there when resumed. Users who claimed the goal are
+// skipped, and every activated row is audited with the job's reason.
+type BulkActivations struct {
+	repo      serviceRepo.BulkActivationRepository
+	goalCache cache.GoalCache
+	namespace string
+	config    BackfillConfig
+	now       func() time.Time
+	wake      chan struct{}
+	logger    logrus.FieldLogger
+
+	goalsActivated *prometheus.CounterVec
+}
+
+// NewBulkActivations creates bulk goal activations stored in repo that log
+// through logger. Non-positive limits in config fall back to the defaults of
+// NewBackfillConfigFromEnv.
+func NewBulkActivations(
+	repo serviceRepo.BulkActivationRepository,
+	goalCache cache.GoalCache,
+	namespace string,
+	config BackfillConfig,
+	logger logrus.FieldLogger,
+) *BulkActivations {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBackfillBatchSize
+	}
+	if config.UsersPerSecond <= 0 {
+		config.UsersPerSecond = DefaultBackfillUsersPerSecond
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultBackfillPollInterval
+	}
+	return &BulkActivations{
+		repo:      repo,
+		goalCache: goalCache,
+		namespace: namespace,
+		config:    config,
+		now:       func() time.Time { return time.Now().UTC() },
+		wake:      make(chan struct{}, 1),
+		logger:    logger,
+		goalsActivated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bulk_activated_goals_total",
+			Help: "Goals activated by admin bulk activations, by goal.",
+		}, []string{"goal_id"}),
+	}
+}
+
+// Collectors returns the bulk activation metrics for registration.
+func (b *BulkActivations) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{b.goalsActivated}
+}
+
+// Start starts activating req.GoalID for req.UserIDs. The goal must be in the
+// config with a positive target (mapper.ConfigInvalidError otherwise) and must
+// not be repeatable (see WithRepeatableGoals).
+//
+// With req.DryRun, nothing is written: the returned job is completed, has no
+// ID, and its counters are what the job would do now.
+func (b *BulkActivations) Start(ctx context.Context, req BulkActivationRequest) (*serviceRepo.BulkActivationJob, error) {
+	if _, err := b.activatableGoal(ctx, req.GoalID); err != nil {
+		return nil, err
+	}
+
+	userIDs := distinctUserIDs(req.UserIDs)
+	now := b.now()
+	job := &serviceRepo.BulkActivationJob{
+		GoalID:      req.GoalID,
+		Namespace:   b.namespace,
+		Status:      serviceRepo.BackfillStatusRunning,
+		UsersTotal:  int64(len(userIDs)),
+		Reason:      req.Reason,
+		ActorUserID: req.ActorUserID,
+		ClientIP:    req.ClientIP,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if req.DryRun {
+		for start := 0; start < len(userIDs); start += b.config.BatchSize {
+			end := min(start+b.config.BatchSize, len(userIDs))
+			counts, err := b.repo.CountBulkActivation(ctx, req.GoalID, userIDs[start:end])
+			if err != nil {
+				return nil, fmt.Errorf("failed to count bulk activation: %w", err)
+			}
+			job.Activated += counts.Activated
+			job.AlreadyActive += counts.AlreadyActive
+			job.Claimed += counts.Claimed
+		}
+		job.Status = serviceRepo.BackfillStatusCompleted
+		job.UsersProcessed = job.UsersTotal
+		job.CompletedAt = &now
+		return job, nil
+	}
+
+	if err := b.repo.CreateBulkActivationJob(ctx, job, userIDs); err != nil {
+		return nil, fmt.Errorf("failed to create bulk activation job: %w", err)
+	}
+
+	b.signal()
+	return job, nil
+}
+
+// Resume resumes a failed job after the last user it processed, recording
+// actorUserID and clientIP as its actor. A running or completed job is
+// returned unchanged.
+func (b *BulkActivations) Resume(ctx context.Context, id int64, actorUserID, clientIP string) (*serviceRepo.BulkActivationJob, error) {
+	job, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrBulkActivationJobNotFound
+	}
+	if job.Status != serviceRepo.BackfillStatusFailed {
+		return job, nil
+	}
+	if _, err := b.activatableGoal(ctx, job.GoalID); err != nil {
+		return nil, err
+	}
+
+	job.Status = serviceRepo.BackfillStatusRunning
+	job.Error = ""
+	job.ActorUserID = actorUserID
+	job.ClientIP = clientIP
+	job.UpdatedAt = b.now()
+	if err := b.repo.UpdateBulkActivationJobStatus(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update bulk activation job: %w", err)
+	}
+
+	b.signal()
+	return job, nil
+}
+
+// Get returns the job, or nil if there is none with that ID.
+func (b *BulkActivations) Get(ctx context.Context, id int64) (*serviceRepo.BulkActivationJob, error) {
+	job, err := b.repo.GetBulkActivationJob(ctx, id, b.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk activation job: %w", err)
+	}
+	return job, nil
+}
+
+// Run works through the running jobs when Start or Resume is called and every
+// PollInterval, until ctx is cancelled.
+func (b *BulkActivations) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		b.RunPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunPending runs the running jobs to completion, oldest first.
+func (b *BulkActivations) RunPending(ctx context.Context) {
+	jobs, err := b.repo.ListBulkActivationJobs(ctx, b.namespace, serviceRepo.BackfillStatusRunning)
+	if err != nil {
+		if ctx.Err() == nil {
+			b.logger.WithError(err).Warn("Failed to list running bulk activation jobs")
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		b.runJob(ctx, job.ID, job.GoalID, job.Activated)
+	}
+}
+
+// runJob processes the job's batches until it completes, fails, or another
+// replica holds it. activated is the job's count before the first batch.
+func (b *BulkActivations) runJob(ctx context.Context, id int64, goalID string, activated int64) {
+	var processed int64
+	started := time.Now()
+
+	for ctx.Err() == nil {
+		goal := b.goalCache.GetGoalByID(goalID)
+		if goal == nil {
+			b.fail(ctx, id, goalID, "goal is no longer in the config")
+			return
+		}
+		if err := checkGoalAssignable(goal, goalGuardBulkActivation, b.logger); err != nil {
+			b.fail(ctx, id, goalID, err.Error())
+			return
+		}
+
+		job, err := b.repo.ProcessBulkActivationBatch(ctx, id, b.namespace, b.config.BatchSize, func(userIDs []string) []*domain.UserGoalProgress {
+			return b.activationRows(goal, userIDs)
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				b.fail(ctx, id, goalID, err.Error())
+			}
+			return
+		}
+		if job == nil {
+			// Not running any more, or another replica is running a batch
+			return
+		}
+
+		if job.Activated > activated {
+			b.goalsActivated.WithLabelValues(goalID).Add(float64(job.Activated - activated))
+		}
+		activated = job.Activated
+		if job.Status == serviceRepo.BackfillStatusCompleted {
+			b.logger.WithFields(logrus.Fields{
+				"job_id":               id,
+				"goal_id":              goalID,
+				"users_total":          job.UsersTotal,
+				"users_activated":      job.Activated,
+				"users_already_active": job.AlreadyActive,
+				"users_claimed":        job.Claimed,
+			}).Info("Bulk goal activation completed")
+			return
+		}
+
+		processed += int64(b.config.BatchSize)
+		if !b.config.pace(ctx, started, processed) {
+			return
+		}
+	}
+}
+
+// activatableGoal returns goalID's config if a bulk activation may activate it.
+func (b *BulkActivations) activatableGoal(ctx context.Context, goalID string) (*domain.Goal, error) {
+	goal := b.goalCache.GetGoalByID(goalID)
+	if goal == nil {
+		return nil, ErrBulkActivationGoalNotFound
+	}
+	if _, _, ok := repeatableGoalFrom(ctx, goalID); ok {
+		return nil, ErrBulkActivationGoalRepeatable
+	}
+	if err := checkGoalAssignable(goal, goalGuardBulkActivation, b.logger); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// activationRows returns the goal's active rows for users, as SetGoalActive
+// creates them, expiring with the goal's rotation period.
+func (b *BulkActivations) activationRows(goal *domain.Goal, userIDs []string) []*domain.UserGoalProgress {
+	now := b.now()
+	expiresAt := rotation.CalculateNextExpiresAt(goal, now)
+
+	rows := make([]*domain.UserGoalProgress, len(userIDs))
+	for i, userID := range userIDs {
+		rows[i] = &domain.UserGoalProgress{
+			UserID:      userID,
+			GoalID:      goal.ID,
+			ChallengeID: goal.ChallengeID,
+			Namespace:   b.namespace,
+			Status:      domain.GoalStatusNotStarted,
+			IsActive:    true,
+			AssignedAt:  &now,
+			ExpiresAt:   expiresAt,
+		}
+	}
+	return rows
+}
+
+// signal wakes Run up, if it is not already due to run.
+func (b *BulkActivations) signal() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// fail marks the job failed with reason; resuming it continues where it stopped.
+func (b *BulkActivations) fail(ctx context.Context, id int64, goalID, reason string) {
+	b.logger.WithFields(logrus.Fields{
+		"job_id":  id,
+		"goal_id": goalID,
+		"error":   reason,
+	}).Error("Bulk goal activation failed")
+
+	job, err := b.repo.GetBulkActivationJob(ctx, id, b.namespace)
+	if err != nil || job == nil {
+		return
+	}
+	job.Status = serviceRepo.BackfillStatusFailed
+	job.Error = reason
+	job.UpdatedAt = b.now()
+	if err := b.repo.UpdateBulkActivationJobStatus(ctx, job); err != nil {
+		b.logger.WithError(err).WithField("job_id", id).Warn("Failed to mark bulk activation job failed")
+	}
+}
+
+// distinctUserIDs returns userIDs sorted and without duplicates, the order a
+// job's cursor walks them in.
+func distinctUserIDs(userIDs []string) []string {
+	return slices.Compact(slices.Sorted(slices.Values(userIDs)))
+}
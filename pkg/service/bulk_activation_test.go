@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBulkActivationGoalCache() *mocks.GoalCache {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "event-goal").Return(&domain.Goal{ID: "event-goal", ChallengeID: "c1", Requirement: domain.Requirement{TargetValue: 3}})
+	goalCache.On("GetGoalByID", "broken-goal").Return(&domain.Goal{ID: "broken-goal", ChallengeID: "c1"})
+	goalCache.On("GetGoalByID", "missing").Return(nil)
+	return goalCache
+}
+
+func newTestBulkActivations(repo repository.BulkActivationRepository) *BulkActivations {
+	bulkActivations := NewBulkActivations(repo, newBulkActivationGoalCache(), "ns", BackfillConfig{BatchSize: 2, UsersPerSecond: 1 << 30, PollInterval: time.Minute},
+		logrus.StandardLogger())
+	bulkActivations.now = func() time.Time { return backfillNow }
+	return bulkActivations
+}
+
+func TestBulkActivations_Start(t *testing.T) {
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("CreateBulkActivationJob", mock.Anything, mock.MatchedBy(func(job *repository.BulkActivationJob) bool {
+		return job.GoalID == "event-goal" && job.Status == repository.BackfillStatusRunning && job.UsersTotal == 3 &&
+			job.Reason == "tournament" && job.ActorUserID == "admin-1" && job.ClientIP == "10.0.0.1"
+	}), []string{"user-1", "user-2", "user-3"}).Run(func(args mock.Arguments) {
+		args.Get(1).(*repository.BulkActivationJob).ID = 7
+	}).Return(nil)
+
+	bulkActivations := newTestBulkActivations(repo)
+	job, err := bulkActivations.Start(context.Background(), BulkActivationRequest{
+		GoalID:      "event-goal",
+		UserIDs:     []string{"user-3", "user-1", "user-2", "user-1"},
+		Reason:      "tournament",
+		ActorUserID: "admin-1",
+		ClientIP:    "10.0.0.1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), job.ID)
+	assert.Len(t, bulkActivations.wake, 1, "Run is woken up")
+	repo.AssertExpectations(t)
+}
+
+func TestBulkActivations_Start_DryRun(t *testing.T) {
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("CountBulkActivation", mock.Anything, "event-goal", []string{"user-1", "user-2"}).
+		Return(&repository.BulkActivationCounts{Activated: 1, Claimed: 1}, nil).Once()
+	repo.On("CountBulkActivation", mock.Anything, "event-goal", []string{"user-3"}).
+		Return(&repository.BulkActivationCounts{AlreadyActive: 1}, nil).Once()
+
+	bulkActivations := newTestBulkActivations(repo)
+	job, err := bulkActivations.Start(context.Background(), BulkActivationRequest{
+		GoalID:  "event-goal",
+		UserIDs: []string{"user-1", "user-2", "user-3"},
+		Reason:  "tournament",
+		DryRun:  true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), job.ID, "a dry run is not stored")
+	assert.Equal(t, repository.BackfillStatusCompleted, job.Status)
+	assert.Equal(t, repository.BulkActivationCounts{Activated: 1, AlreadyActive: 1, Claimed: 1}, job.BulkActivationCounts)
+	assert.Empty(t, bulkActivations.wake)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CreateBulkActivationJob", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBulkActivations_Start_RejectsGoal(t *testing.T) {
+	bulkActivations := newTestBulkActivations(new(mocks.BulkActivationRepository))
+	request := func(goalID string) BulkActivationRequest {
+		return BulkActivationRequest{GoalID: goalID, UserIDs: []string{"user-1"}, Reason: "tournament"}
+	}
+
+	_, err := bulkActivations.Start(context.Background(), request("missing"))
+	assert.ErrorIs(t, err, ErrBulkActivationGoalNotFound)
+
+	_, err = bulkActivations.Start(context.Background(), request("broken-goal"))
+	assert.Error(t, err, "a goal without a positive target is refused")
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"event-goal": {Cooldown: time.Hour}}, new(mocks.RepeatableGoalRepository))
+	_, err = bulkActivations.Start(ctx, request("event-goal"))
+	assert.ErrorIs(t, err, ErrBulkActivationGoalRepeatable)
+}
+
+func TestBulkActivations_Resume(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		wantUpdate bool
+	}{
+		{"failed resumes", repository.BackfillStatusFailed, true},
+		{"running is returned", repository.BackfillStatusRunning, false},
+		{"completed is returned", repository.BackfillStatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.BulkActivationRepository)
+			repo.On("GetBulkActivationJob", mock.Anything, int64(7), "ns").Return(&repository.BulkActivationJob{
+				ID:             7,
+				GoalID:         "event-goal",
+				Status:         tt.status,
+				UsersProcessed: 4,
+				Error:          "connection reset",
+			}, nil)
+			if tt.wantUpdate {
+				repo.On("UpdateBulkActivationJobStatus", mock.Anything, mock.MatchedBy(func(job *repository.BulkActivationJob) bool {
+					return job.Status == repository.BackfillStatusRunning && job.Error == "" &&
+						job.UsersProcessed == 4 && job.ActorUserID == "admin-2"
+				})).Return(nil)
+			}
+
+			job, err := newTestBulkActivations(repo).Resume(context.Background(), 7, "admin-2", "")
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), job.ID)
+			repo.AssertExpectations(t)
+		})
+	}
+
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("GetBulkActivationJob", mock.Anything, int64(8), "ns").Return(nil, nil)
+	_, err := newTestBulkActivations(repo).Resume(context.Background(), 8, "admin-2", "")
+	assert.ErrorIs(t, err, ErrBulkActivationJobNotFound)
+}
+
+func TestBulkActivations_RunPending(t *testing.T) {
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("ListBulkActivationJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BulkActivationJob{{ID: 7, GoalID: "event-goal", BulkActivationCounts: repository.BulkActivationCounts{Activated: 10}}}, nil)
+
+	var built []*domain.UserGoalProgress
+	build := func(args mock.Arguments) {
+		built = append(built, args.Get(4).(func([]string) []*domain.UserGoalProgress)([]string{"user-1"})...)
+	}
+	repo.On("ProcessBulkActivationBatch", mock.Anything, int64(7), "ns", 2, mock.Anything).Run(build).
+		Return(&repository.BulkActivationJob{Status: repository.BackfillStatusRunning, BulkActivationCounts: repository.BulkActivationCounts{Activated: 12}}, nil).Once()
+	repo.On("ProcessBulkActivationBatch", mock.Anything, int64(7), "ns", 2, mock.Anything).Run(build).
+		Return(&repository.BulkActivationJob{Status: repository.BackfillStatusCompleted, BulkActivationCounts: repository.BulkActivationCounts{Activated: 13}}, nil).Once()
+
+	bulkActivations := newTestBulkActivations(repo)
+	bulkActivations.RunPending(context.Background())
+
+	repo.AssertExpectations(t)
+	assert.Equal(t, 3.0, testutil.ToFloat64(bulkActivations.goalsActivated.WithLabelValues("event-goal")),
+		"only goals activated by this replica are counted")
+	require.Len(t, built, 2)
+	assert.Equal(t, &domain.UserGoalProgress{
+		UserID:      "user-1",
+		GoalID:      "event-goal",
+		ChallengeID: "c1",
+		Namespace:   "ns",
+		Status:      domain.GoalStatusNotStarted,
+		IsActive:    true,
+		AssignedAt:  &backfillNow,
+	}, built[0])
+}
+
+func TestBulkActivations_RunPending_FailsJob(t *testing.T) {
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("ListBulkActivationJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BulkActivationJob{{ID: 7, GoalID: "event-goal"}}, nil)
+	repo.On("ProcessBulkActivationBatch", mock.Anything, int64(7), "ns", 2, mock.Anything).Return(nil, errors.New("connection reset"))
+	repo.On("GetBulkActivationJob", mock.Anything, int64(7), "ns").
+		Return(&repository.BulkActivationJob{ID: 7, GoalID: "event-goal", Status: repository.BackfillStatusRunning, UsersProcessed: 4}, nil)
+	repo.On("UpdateBulkActivationJobStatus", mock.Anything, mock.MatchedBy(func(job *repository.BulkActivationJob) bool {
+		return job.Status == repository.BackfillStatusFailed && job.Error == "connection reset" && job.UsersProcessed == 4
+	})).Return(nil)
+
+	bulkActivations := newTestBulkActivations(repo)
+	logger, hook := logtest.NewNullLogger()
+	bulkActivations.logger = logger
+	bulkActivations.RunPending(context.Background())
+
+	repo.AssertExpectations(t)
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Bulk goal activation failed", hook.LastEntry().Message)
+	assert.Equal(t, int64(7), hook.LastEntry().Data["job_id"])
+}
+
+// Failures the jobs cannot record are logged: listing the jobs, and marking
+// one failed.
+func TestBulkActivations_RunPending_LogsUnrecordedFailures(t *testing.T) {
+	repo := new(mocks.BulkActivationRepository)
+	repo.On("ListBulkActivationJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return(nil, errors.New("connection reset")).Once()
+	bulkActivations := newTestBulkActivations(repo)
+	logger, hook := logtest.NewNullLogger()
+	bulkActivations.logger = logger
+
+	bulkActivations.RunPending(context.Background())
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to list running bulk activation jobs", hook.LastEntry().Message)
+
+	repo.On("ListBulkActivationJobs", mock.Anything, "ns", repository.BackfillStatusRunning).
+		Return([]*repository.BulkActivationJob{{ID: 7, GoalID: "missing"}}, nil).Once()
+	repo.On("GetBulkActivationJob", mock.Anything, int64(7), "ns").
+		Return(&repository.BulkActivationJob{ID: 7, GoalID: "missing", Status: repository.BackfillStatusRunning}, nil)
+	repo.On("UpdateBulkActivationJobStatus", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+	bulkActivations.RunPending(context.Background())
+	require.Len(t, hook.AllEntries(), 3)
+	assert.Equal(t, "goal is no longer in the config", hook.AllEntries()[1].Data["error"])
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to mark bulk activation job failed", hook.LastEntry().Message)
+	assert.Equal(t, int64(7), hook.LastEntry().Data["job_id"])
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultChallengeMetricsMaxChallenges is how many challenges get their own
+	// challenge_id label by default.
+	DefaultChallengeMetricsMaxChallenges = 100
+
+	// ChallengeMetricsOtherLabel is the label value of challenges and reward
+	// types that don't get their own.
+	ChallengeMetricsOtherLabel = "other"
+
+	// rewardTypeNone is the reward_type of claims that granted nothing, past a
+	// reward cap without a fallback reward.
+	rewardTypeNone = "none"
+)
+
+// ChallengeMetrics counts goal completions and reward claims per challenge for
+// live dashboards.
+//
+// Labels are guarded against unbounded cardinality: a challenge gets its own
+// challenge_id label only while it is in the config and fewer than
+// maxChallenges challenges have one; every other challenge is counted under
+// "other". reward_type is ITEM, WALLET, "none" for a claim that granted
+// nothing, or "other". Both counters carry the namespace as a constant label.
+//
+// All methods are safe on a nil *ChallengeMetrics, which counts nothing.
+type ChallengeMetrics struct {
+	goalCache     cache.GoalCache
+	maxChallenges int
+
+	mu       sync.Mutex
+	labelled map[string]bool
+
+	goalsCompleted *prometheus.CounterVec
+	rewardsClaimed *prometheus.CounterVec
+}
+
+// NewChallengeMetrics creates the counters for namespace. Challenges are
+// checked against goalCache, and at most maxChallenges get their own label.
+func NewChallengeMetrics(goalCache cache.GoalCache, namespace string, maxChallenges int) *ChallengeMetrics {
+	constLabels := prometheus.Labels{"namespace": namespace}
+	return &ChallengeMetrics{
+		goalCache:     goalCache,
+		maxChallenges: maxChallenges,
+		labelled:      make(map[string]bool),
+		goalsCompleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "goals_completed_total",
+			Help:        "Goals completed by this service (force-completes, status recomputes, multi-step progress), by challenge.",
+			ConstLabels: constLabels,
+		}, []string{"challenge_id"}),
+		rewardsClaimed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "rewards_claimed_total",
+			Help:        "Goal rewards claimed, by challenge and reward type.",
+			ConstLabels: constLabels,
+		}, []string{"challenge_id", "reward_type"}),
+	}
+}
+
+// NewChallengeMetricsFromEnv reads the label cap from
+// CHALLENGE_METRICS_MAX_CHALLENGES (default 100). Negative values fall back to
+// the default; 0 counts every challenge under "other".
+func NewChallengeMetricsFromEnv(goalCache cache.GoalCache, namespace string) *ChallengeMetrics {
+	maxChallenges := common.GetEnvInt("CHALLENGE_METRICS_MAX_CHALLENGES", DefaultChallengeMetricsMaxChallenges)
+	if maxChallenges < 0 {
+		maxChallenges = DefaultChallengeMetricsMaxChallenges
+	}
+	return NewChallengeMetrics(goalCache, namespace, maxChallenges)
+}
+
+// Collectors returns the challenge metrics for registration.
+func (m *ChallengeMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.goalsCompleted, m.rewardsClaimed}
+}
+
+// GoalCompleted counts a goal of challengeID moving to completed.
+func (m *ChallengeMetrics) GoalCompleted(challengeID string) {
+	if m == nil {
+		return
+	}
+	m.goalsCompleted.WithLabelValues(m.challengeLabel(challengeID)).Inc()
+}
+
+// RewardClaimed counts a successful claim of a goal of challengeID. A claim
+// that granted nothing is counted with reward_type "none".
+func (m *ChallengeMetrics) RewardClaimed(challengeID string, reward domain.Reward, granted bool) {
+	if m == nil {
+		return
+	}
+	rewardType := rewardTypeNone
+	if granted {
+		rewardType = rewardTypeLabel(reward.Type)
+	}
+	m.rewardsClaimed.WithLabelValues(m.challengeLabel(challengeID), rewardType).Inc()
+}
+
+// challengeLabel returns the challenge_id label of challengeID.
+func (m *ChallengeMetrics) challengeLabel(challengeID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.labelled[challengeID] {
+		return challengeID
+	}
+	if len(m.labelled) >= m.maxChallenges || m.goalCache.GetChallengeByChallengeID(challengeID) == nil {
+		return ChallengeMetricsOtherLabel
+	}
+	m.labelled[challengeID] = true
+	return challengeID
+}
+
+// rewardTypeLabel returns the reward_type label of a reward type.
+func rewardTypeLabel(rewardType string) string {
+	switch domain.RewardType(rewardType) {
+	case domain.RewardTypeItem, domain.RewardTypeWallet:
+		return rewardType
+	}
+	return ChallengeMetricsOtherLabel
+}
+
+type challengeMetricsKey struct{}
+
+// WithChallengeMetrics returns a context whose claims and progress updates are
+// counted by metrics. A nil metrics leaves ctx unchanged.
+func WithChallengeMetrics(ctx context.Context, metrics *ChallengeMetrics) context.Context {
+	if metrics == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, challengeMetricsKey{}, metrics)
+}
+
+// challengeMetricsFrom returns the metrics set by WithChallengeMetrics, or nil.
+func challengeMetricsFrom(ctx context.Context) *ChallengeMetrics {
+	metrics, _ := ctx.Value(challengeMetricsKey{}).(*ChallengeMetrics)
+	return metrics
+}
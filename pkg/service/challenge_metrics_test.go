@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChallengeMetrics returns metrics over the challenges c1 and c2,
+// registered on a private registry.
+func newTestChallengeMetrics(t *testing.T, maxChallenges int) (*ChallengeMetrics, *prometheus.Registry) {
+	t.Helper()
+
+	goalCache := commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c1", Goals: []*domain.Goal{createClaimableGoal("goal-1", "c1")}},
+		{ID: "c2", Goals: []*domain.Goal{createClaimableGoal("goal-2", "c2")}},
+	}}, "", slog.Default())
+
+	metrics := NewChallengeMetrics(goalCache, "test-namespace", maxChallenges)
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics.goalsCompleted))
+	require.NoError(t, registry.Register(metrics.rewardsClaimed))
+	return metrics, registry
+}
+
+// claimWithMetrics claims goal-1 of c1 for user123 with metrics in ctx; the
+// claim's final MarkAsClaimed returns markErr.
+func claimWithMetrics(metrics *ChallengeMetrics, markErr error) error {
+	goal := createClaimableGoal("goal-1", "c1")
+	progress := createCompletedProgress("user123", "goal-1", "c1")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(markErr)
+	mockTxRepo.On("Commit").Return(nil)
+	mockTxRepo.On("Rollback").Return(nil).Maybe()
+
+	ctx := WithChallengeMetrics(context.Background(), metrics)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "c1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+	return err
+}
+
+func TestChallengeMetrics_ClaimCounted(t *testing.T) {
+	metrics, registry := newTestChallengeMetrics(t, 10)
+
+	require.NoError(t, claimWithMetrics(metrics, nil))
+
+	expected := `
+# HELP rewards_claimed_total Goal rewards claimed, by challenge and reward type.
+# TYPE rewards_claimed_total counter
+rewards_claimed_total{challenge_id="c1",namespace="test-namespace",reward_type="ITEM"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "rewards_claimed_total"))
+}
+
+func TestChallengeMetrics_RolledBackClaimNotCounted(t *testing.T) {
+	metrics, registry := newTestChallengeMetrics(t, 10)
+
+	err := claimWithMetrics(metrics, errors.New("database error"))
+
+	require.Error(t, err)
+	count, err := testutil.GatherAndCount(registry, "rewards_claimed_total")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestChallengeMetrics_RecomputeCountsCompletions(t *testing.T) {
+	metrics, _ := newTestChallengeMetrics(t, 10)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	recomputes, _ := newTestStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository))
+	recomputes.SetChallengeMetrics(metrics)
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.Anything, mock.Anything).Return([]repository.StatusTransition{
+		{GoalID: "g1", ChallengeID: "c1", From: domain.GoalStatusInProgress, To: domain.GoalStatusCompleted},
+		{GoalID: "g2", ChallengeID: "c1", From: domain.GoalStatusCompleted, To: domain.GoalStatusInProgress},
+	}, nil)
+
+	_, err := recomputes.Recompute(context.Background(), StatusRecomputeRequest{UserID: "user-1", ActorUserID: "admin-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.goalsCompleted.WithLabelValues("c1")))
+}
+
+func TestChallengeMetrics_CardinalityGuard(t *testing.T) {
+	metrics, _ := newTestChallengeMetrics(t, 1)
+
+	metrics.GoalCompleted("c1")
+	metrics.GoalCompleted("c2")      // over the cap
+	metrics.GoalCompleted("removed") // not in the config
+	metrics.GoalCompleted("c1")      // keeps its label
+	metrics.RewardClaimed("c1", domain.Reward{Type: "BUNDLE"}, true)
+	metrics.RewardClaimed("c1", domain.Reward{Type: string(domain.RewardTypeWallet)}, false)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.goalsCompleted.WithLabelValues("c1")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.goalsCompleted.WithLabelValues(ChallengeMetricsOtherLabel)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.rewardsClaimed.WithLabelValues("c1", ChallengeMetricsOtherLabel)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.rewardsClaimed.WithLabelValues("c1", "none")))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.goalsCompleted))
+}
+
+func TestChallengeMetrics_Nil(t *testing.T) {
+	var metrics *ChallengeMetrics
+
+	assert.NotPanics(t, func() {
+		metrics.GoalCompleted("c1")
+		metrics.RewardClaimed("c1", domain.Reward{}, true)
+	})
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithChallengeMetrics(ctx, nil))
+	assert.Nil(t, challengeMetricsFrom(ctx))
+}
+
+func TestNewChallengeMetricsFromEnv(t *testing.T) {
+	goalCache := new(mocks.GoalCache)
+
+	assert.Equal(t, DefaultChallengeMetricsMaxChallenges, NewChallengeMetricsFromEnv(goalCache, "ns").maxChallenges)
+
+	t.Setenv("CHALLENGE_METRICS_MAX_CHALLENGES", "5")
+	assert.Equal(t, 5, NewChallengeMetricsFromEnv(goalCache, "ns").maxChallenges)
+
+	t.Setenv("CHALLENGE_METRICS_MAX_CHALLENGES", "-1")
+	assert.Equal(t, DefaultChallengeMetricsMaxChallenges, NewChallengeMetricsFromEnv(goalCache, "ns").maxChallenges)
+}
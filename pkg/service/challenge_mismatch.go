@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultChallengeMismatchReportLimit is the number of rows GetChallengeMismatches
+	// returns when the caller does not set a limit.
+	DefaultChallengeMismatchReportLimit = 100
+	// MaxChallengeMismatchReportLimit caps the rows returned per report.
+	MaxChallengeMismatchReportLimit = 1000
+	// DefaultChallengeMismatchFixLimit is the number of rows FixChallengeMismatches
+	// rewrites per call when the caller does not set a limit.
+	DefaultChallengeMismatchFixLimit = 500
+	// MaxChallengeMismatchFixLimit caps the rows rewritten per call, bounding how
+	// many row locks one batch holds.
+	MaxChallengeMismatchFixLimit = 5000
+)
+
+// ChallengeMismatchReport is the result of GetChallengeMismatches.
+type ChallengeMismatchReport struct {
+	Mismatches []*serviceRepo.ChallengeMismatch
+	// Truncated is true when more mismatched rows exist than were returned.
+	Truncated bool
+}
+
+// ChallengeMismatchFixOptions are the admin's choices for FixChallengeMismatches.
+type ChallengeMismatchFixOptions struct {
+	// Reason is stored in the audit record of every fixed row. It is required.
+	Reason string
+	// Limit is the maximum number of rows to fix (0 means the default).
+	Limit int
+	// ClientIP is the address the admin's request came from. Empty when unknown.
+	ClientIP string
+}
+
+// ChallengeMismatchFixResult is the outcome of FixChallengeMismatches.
+type ChallengeMismatchFixResult struct {
+	Fixed int
+	// More is true when the batch was full, so mismatched rows may remain.
+	More bool
+}
+
+// GetChallengeMismatches lists progress rows stored under a different challenge
+// than their goal in the current config, e.g. after goals were moved between
+// challenges. Such rows cannot be claimed or toggled until they are fixed.
+// Rows of goals removed from the config are not reported.
+//
+// A limit of 0 uses DefaultChallengeMismatchReportLimit; larger limits are
+// capped at MaxChallengeMismatchReportLimit.
+func GetChallengeMismatches(
+	ctx context.Context,
+	namespace string,
+	limit int,
+	goalCache cache.GoalCache,
+	queries serviceRepo.ProgressQueryRepository,
+	logger logrus.FieldLogger,
+) (*ChallengeMismatchReport, error) {
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if queries == nil {
+		return nil, fmt.Errorf("progress query repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	limit = clampLimit(limit, DefaultChallengeMismatchReportLimit, MaxChallengeMismatchReportLimit)
+
+	// One extra row tells whether the report is truncated
+	mismatches, err := queries.GetChallengeMismatches(ctx, namespace, goalChallengeIDs(goalCache), limit+1)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"error":     err,
+		}).Error("Failed to get challenge mismatches")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	report := &ChallengeMismatchReport{Mismatches: mismatches}
+	if len(mismatches) > limit {
+		report.Mismatches = mismatches[:limit]
+		report.Truncated = true
+	}
+
+	return report, nil
+}
+
+// FixChallengeMismatches rewrites the challenge_id of mismatched progress rows
+// to their goal's challenge in the current config, one audited batch of up to
+// opts.Limit rows per call. Progress, status and timestamps are kept. It is safe
+// to run while players are claiming: rows locked by a claim are left for a
+// later call. Callers repeat until More is false.
+//
+// A limit of 0 uses DefaultChallengeMismatchFixLimit; larger limits are capped
+// at MaxChallengeMismatchFixLimit.
+func FixChallengeMismatches(
+	ctx context.Context,
+	adminID string,
+	namespace string,
+	opts ChallengeMismatchFixOptions,
+	goalCache cache.GoalCache,
+	goalAdmin serviceRepo.GoalAdminRepository,
+	logger logrus.FieldLogger,
+) (*ChallengeMismatchFixResult, error) {
+	if adminID == "" {
+		return nil, fmt.Errorf("admin ID cannot be empty")
+	}
+
+	if opts.Reason == "" {
+		return nil, fmt.Errorf("reason cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if goalAdmin == nil {
+		return nil, fmt.Errorf("goal admin repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	limit := clampLimit(opts.Limit, DefaultChallengeMismatchFixLimit, MaxChallengeMismatchFixLimit)
+	log := logger.WithFields(logrus.Fields{
+		"user_id":   adminID,
+		"namespace": namespace,
+		"limit":     limit,
+		"client_ip": opts.ClientIP,
+	})
+
+	fixed, err := goalAdmin.FixChallengeMismatches(ctx, &serviceRepo.ChallengeMismatchFix{
+		Namespace:      namespace,
+		GoalChallenges: goalChallengeIDs(goalCache),
+		Limit:          limit,
+		ActorUserID:    adminID,
+		ClientIP:       opts.ClientIP,
+		Reason:         opts.Reason,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to fix challenge mismatches")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	log.WithFields(logrus.Fields{
+		"fixed":           fixed,
+		"security_review": true,
+	}).Info("Admin fixed challenge mismatches")
+
+	return &ChallengeMismatchFixResult{Fixed: fixed, More: fixed == limit}, nil
+}
+
+// goalChallengeIDs maps every goal in the config to its challenge ID.
+func goalChallengeIDs(goalCache cache.GoalCache) map[string]string {
+	goals := goalCache.GetAllGoals()
+	goalChallenges := make(map[string]string, len(goals))
+	for _, goal := range goals {
+		goalChallenges[goal.ID] = goal.ChallengeID
+	}
+	return goalChallenges
+}
+
+// clampLimit returns fallback for a non-positive limit and upper for one above upper.
+func clampLimit(limit, fallback, upper int) int {
+	switch {
+	case limit <= 0:
+		return fallback
+	case limit > upper:
+		return upper
+	default:
+		return limit
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMismatchGoalCache() *mocks.GoalCache {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetAllGoals").Return([]*domain.Goal{
+		{ID: "login", ChallengeID: "daily"},
+		{ID: "kills", ChallengeID: "weekly"},
+	})
+	return goalCache
+}
+
+var mismatchGoalChallenges = map[string]string{"login": "daily", "kills": "weekly"}
+
+func TestGetChallengeMismatches(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetChallengeMismatches", mock.Anything, "ns", mismatchGoalChallenges, 3).
+		Return([]*repository.ChallengeMismatch{
+			{UserID: "u1", GoalID: "kills", StoredChallengeID: "daily", ChallengeID: "weekly"},
+			{UserID: "u2", GoalID: "kills", StoredChallengeID: "daily", ChallengeID: "weekly"},
+			{UserID: "u3", GoalID: "kills", StoredChallengeID: "daily", ChallengeID: "weekly"},
+		}, nil)
+
+	report, err := GetChallengeMismatches(context.Background(), "ns", 2, newMismatchGoalCache(), queries, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Len(t, report.Mismatches, 2)
+	assert.True(t, report.Truncated)
+}
+
+func TestGetChallengeMismatches_DefaultLimitAndError(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetChallengeMismatches", mock.Anything, "ns", mismatchGoalChallenges, DefaultChallengeMismatchReportLimit+1).
+		Return(nil, errors.New("db down"))
+
+	_, err := GetChallengeMismatches(context.Background(), "ns", 0, newMismatchGoalCache(), queries, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+}
+
+func TestFixChallengeMismatches(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	goalAdmin.On("FixChallengeMismatches", mock.Anything, &repository.ChallengeMismatchFix{
+		Namespace:      "ns",
+		GoalChallenges: mismatchGoalChallenges,
+		Limit:          MaxChallengeMismatchFixLimit,
+		ActorUserID:    "admin-1",
+		ClientIP:       "198.51.100.1",
+		Reason:         "moved kills to weekly",
+	}).Return(MaxChallengeMismatchFixLimit, nil)
+
+	result, err := FixChallengeMismatches(context.Background(), "admin-1", "ns", ChallengeMismatchFixOptions{
+		Reason:   "moved kills to weekly",
+		Limit:    MaxChallengeMismatchFixLimit + 1,
+		ClientIP: "198.51.100.1",
+	}, newMismatchGoalCache(), goalAdmin, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, MaxChallengeMismatchFixLimit, result.Fixed)
+	assert.True(t, result.More, "a full batch may leave rows behind")
+}
+
+func TestFixChallengeMismatches_MissingReason(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+
+	_, err := FixChallengeMismatches(context.Background(), "admin-1", "ns", ChallengeMismatchFixOptions{}, newMismatchGoalCache(), goalAdmin, logrus.StandardLogger())
+
+	assert.Error(t, err)
+	goalAdmin.AssertNotCalled(t, "FixChallengeMismatches", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+)
+
+// NextChallengeReset returns when the challenge next resets: the earliest next
+// rotation boundary of its goals with rotation enabled, or nil when none of
+// them rotates.
+//
+// Boundaries come from rotation.CalculateNextExpiresAt, the schedule progress
+// rows are reset by, so the reset reported to clients is the one applied.
+// They fall at 00:00 UTC (weekly: Monday, monthly: the 1st); the schedule has
+// no reset hour or time zone.
+func NextChallengeReset(challenge *domain.Challenge, now time.Time) *time.Time {
+	if challenge == nil {
+		return nil
+	}
+
+	var next *time.Time
+	for _, goal := range challenge.Goals {
+		expiresAt := rotation.CalculateNextExpiresAt(goal, now)
+		if expiresAt != nil && (next == nil || expiresAt.Before(*next)) {
+			next = expiresAt
+		}
+	}
+	return next
+}
+
+// NextChallengeResets returns the NextChallengeReset of each of challenges
+// that has one, by challenge ID.
+func NextChallengeResets(challenges []*domain.Challenge, now time.Time) map[string]time.Time {
+	resets := make(map[string]time.Time)
+	for _, challenge := range challenges {
+		if next := NextChallengeReset(challenge, now); next != nil {
+			resets[challenge.ID] = *next
+		}
+	}
+	return resets
+}
+
+// PagedChallengeResets returns NextChallengeResets of the configured challenges
+// of a progress page: challenges from PageChallenges only hold the goals on the
+// page, and a challenge's reset does not depend on paging.
+func PagedChallengeResets(goalCache cache.GoalCache, challengeIDs []string, now time.Time) map[string]time.Time {
+	challenges := make([]*domain.Challenge, 0, len(challengeIDs))
+	for _, challengeID := range challengeIDs {
+		if challenge := goalCache.GetChallengeByChallengeID(challengeID); challenge != nil {
+			challenges = append(challenges, challenge)
+		}
+	}
+	return NextChallengeResets(challenges, now)
+}
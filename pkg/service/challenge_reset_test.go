@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rotatingGoal(id string, schedule domain.RotationSchedule) *domain.Goal {
+	return &domain.Goal{ID: id, Rotation: &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: schedule}}
+}
+
+func TestNextChallengeReset(t *testing.T) {
+	// A zone with daylight saving time: the reset stays at 00:00 UTC across its changes
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	daily := &domain.Challenge{ID: "daily", Goals: []*domain.Goal{{ID: "plain"}, rotatingGoal("d", domain.RotationScheduleDaily)}}
+	weekly := &domain.Challenge{ID: "weekly", Goals: []*domain.Goal{rotatingGoal("w", domain.RotationScheduleWeekly)}}
+	monthly := &domain.Challenge{ID: "monthly", Goals: []*domain.Goal{rotatingGoal("m", domain.RotationScheduleMonthly)}}
+	mixed := &domain.Challenge{ID: "mixed", Goals: []*domain.Goal{
+		rotatingGoal("m", domain.RotationScheduleMonthly),
+		rotatingGoal("w", domain.RotationScheduleWeekly),
+	}}
+
+	tests := []struct {
+		name      string
+		challenge *domain.Challenge
+		now       time.Time
+		want      time.Time
+	}{
+		{"daily mid-day", daily, time.Date(2025, 3, 4, 15, 30, 0, 0, time.UTC), time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"daily one second before midnight", daily, time.Date(2025, 3, 4, 23, 59, 59, 0, time.UTC), time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"daily at midnight is the next midnight", daily, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"daily across the year", daily, time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"daily with a local clock on a DST change", daily, time.Date(2025, 3, 9, 3, 30, 0, 0, newYork), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"weekly on Sunday", weekly, time.Date(2025, 3, 9, 23, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"weekly at Monday midnight", weekly, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"monthly in a leap February", monthly, time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"earliest goal", mixed, time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"earliest goal at month end", mixed, time.Date(2025, 3, 28, 12, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextChallengeReset(tt.challenge, tt.now)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+}
+
+func TestNextChallengeReset_NoRotation(t *testing.T) {
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	disabled := rotatingGoal("d", domain.RotationScheduleDaily)
+	disabled.Rotation.Enabled = false
+
+	assert.Nil(t, NextChallengeReset(nil, now))
+	assert.Nil(t, NextChallengeReset(&domain.Challenge{ID: "c", Goals: []*domain.Goal{{ID: "plain"}, disabled}}, now))
+}
+
+func TestPagedChallengeResets(t *testing.T) {
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	goalCache := new(mocks.GoalCache)
+	// The page only holds the monthly goal; the configured challenge also rotates daily
+	goalCache.On("GetChallengeByChallengeID", "mixed").Return(&domain.Challenge{ID: "mixed", Goals: []*domain.Goal{
+		rotatingGoal("m", domain.RotationScheduleMonthly),
+		rotatingGoal("d", domain.RotationScheduleDaily),
+	}})
+	goalCache.On("GetChallengeByChallengeID", "plain").Return(&domain.Challenge{ID: "plain", Goals: []*domain.Goal{{ID: "p"}}})
+	goalCache.On("GetChallengeByChallengeID", "removed").Return(nil)
+
+	resets := PagedChallengeResets(goalCache, []string{"mixed", "plain", "removed"}, now)
+
+	assert.Equal(t, map[string]time.Time{"mixed": time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)}, resets)
+}
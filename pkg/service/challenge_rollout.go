@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// ChallengeRollouts maps challenge IDs to the "rolloutPercentage" configured
+// for them in the challenge config: the share of players, 0 to 100, the
+// challenge is available to.
+//
+// A player sees the challenge when their RolloutBucket for it is below the
+// percentage. Buckets come from a hash of the user and challenge IDs, so a
+// player keeps or lacks the challenge across requests and instances, and
+// raising the percentage only adds players: everyone below the old threshold
+// is below the new one. Lowering it would take the challenge away from players
+// who already had it, so CheckRolloutsNotLowered rejects such a config at load
+// and reload.
+//
+// Challenges not listed, and a nil ChallengeRollouts, are available to
+// everyone. An unavailable challenge is left out of challenge lists and
+// progress summaries, is skipped by claim-all and automatic selections, and is
+// reported as not found by the endpoints that name it, so its existence is not
+// revealed. Initialization (see WithChallengeRollouts) and default goal
+// backfills do not assign its defaultAssigned goals.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so it is read
+// from the config file separately by LoadChallengeRollouts.
+type ChallengeRollouts map[string]int
+
+// challengeRolloutsConfig is the subset of challenges.json needed to read the
+// rollout percentages.
+type challengeRolloutsConfig struct {
+	Challenges []struct {
+		ID                string `json:"challengeId"`
+		RolloutPercentage *int   `json:"rolloutPercentage"`
+	} `json:"challenges"`
+}
+
+// LoadChallengeRollouts reads the challenges' rolloutPercentage from the
+// challenge config file.
+func LoadChallengeRollouts(configPath string) (ChallengeRollouts, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseChallengeRollouts(data)
+}
+
+// ParseChallengeRollouts extracts the challenges' rolloutPercentage from
+// challenge config JSON. A percentage must be between 0 and 100.
+func ParseChallengeRollouts(data []byte) (ChallengeRollouts, error) {
+	rollouts, _, err := parseChallengeRollouts(data)
+	return rollouts, err
+}
+
+// parseChallengeRollouts is ParseChallengeRollouts, also returning the IDs of
+// every challenge in the config.
+func parseChallengeRollouts(data []byte) (ChallengeRollouts, []string, error) {
+	var cfg challengeRolloutsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	rollouts := make(ChallengeRollouts)
+	challengeIDs := make([]string, 0, len(cfg.Challenges))
+	for _, challenge := range cfg.Challenges {
+		challengeIDs = append(challengeIDs, challenge.ID)
+		if challenge.RolloutPercentage == nil {
+			continue
+		}
+		if percentage := *challenge.RolloutPercentage; percentage < 0 || percentage > 100 {
+			return nil, nil, fmt.Errorf("challenge %q: rolloutPercentage must be between 0 and 100, got %d",
+				challenge.ID, percentage)
+		}
+		rollouts[challenge.ID] = *challenge.RolloutPercentage
+	}
+
+	return rollouts, challengeIDs, nil
+}
+
+// CheckRolloutsNotLowered returns an error naming the challenges whose
+// percentage in rollouts is lower than in previous, the rollouts of a config
+// that was already served with the challenges previousIDs. A challenge without
+// a percentage is at 100. Challenges not in previousIDs are new and may start
+// at any percentage.
+func CheckRolloutsNotLowered(previous ChallengeRollouts, previousIDs []string, rollouts ChallengeRollouts) error {
+	var lowered []string
+	for _, challengeID := range slices.Sorted(slices.Values(previousIDs)) {
+		before, ok := previous[challengeID]
+		if !ok {
+			before = 100
+		}
+		after, ok := rollouts[challengeID]
+		if !ok {
+			after = 100
+		}
+		if after < before {
+			lowered = append(lowered, fmt.Sprintf("challenge %q from %d to %d", challengeID, before, after))
+		}
+	}
+	if len(lowered) > 0 {
+		return fmt.Errorf("rolloutPercentage cannot be lowered, players who had the challenge would lose it: %s",
+			strings.Join(lowered, ", "))
+	}
+	return nil
+}
+
+// CheckConfigRolloutsNotLowered is CheckRolloutsNotLowered for the challenge
+// config files at previousPath, the config served before, and configPath.
+func CheckConfigRolloutsNotLowered(previousPath, configPath string) error {
+	previousData, err := os.ReadFile(previousPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return fmt.Errorf("failed to read previous challenge config: %w", err)
+	}
+	previous, previousIDs, err := parseChallengeRollouts(previousData)
+	if err != nil {
+		return fmt.Errorf("previous challenge config: %w", err)
+	}
+
+	rollouts, err := LoadChallengeRollouts(configPath)
+	if err != nil {
+		return err
+	}
+	return CheckRolloutsNotLowered(previous, previousIDs, rollouts)
+}
+
+// RolloutBucket returns the user's bucket for the challenge, 0 to 99: the
+// first 8 bytes of SHA-256(userID, challengeID) modulo 100. Hashing the
+// challenge ID too gives each challenge a different share of players.
+func RolloutBucket(userID, challengeID string) int {
+	sum := sha256.Sum256([]byte(userID + "\x00" + challengeID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+// IsAvailable reports whether challengeID is rolled out to userID.
+func (r ChallengeRollouts) IsAvailable(userID, challengeID string) bool {
+	percentage, ok := r[challengeID]
+	if !ok {
+		return true
+	}
+	return RolloutBucket(userID, challengeID) < percentage
+}
+
+// AvailableChallenges returns the challenges rolled out to userID, in order.
+// challenges is returned as-is when there are no rollouts.
+func (r ChallengeRollouts) AvailableChallenges(userID string, challenges []*domain.Challenge) []*domain.Challenge {
+	if len(r) == 0 {
+		return challenges
+	}
+
+	available := make([]*domain.Challenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		if r.IsAvailable(userID, challenge.ID) {
+			available = append(available, challenge)
+		}
+	}
+	return available
+}
+
+// AvailableGoals returns the goals whose challenge is rolled out to userID, in
+// order. goals is returned as-is when there are no rollouts.
+func (r ChallengeRollouts) AvailableGoals(userID string, goals []*domain.Goal) []*domain.Goal {
+	if len(r) == 0 {
+		return goals
+	}
+
+	available := make([]*domain.Goal, 0, len(goals))
+	for _, goal := range goals {
+		if r.IsAvailable(userID, goal.ChallengeID) {
+			available = append(available, goal)
+		}
+	}
+	return available
+}
+
+// AvailableGoalIDs returns the goal IDs whose challenge in goalCache is rolled
+// out to userID, in order. goalIDs is returned as-is when there are no rollouts.
+func (r ChallengeRollouts) AvailableGoalIDs(goalCache cache.GoalCache, userID string, goalIDs []string) []string {
+	if len(r) == 0 {
+		return goalIDs
+	}
+
+	available := make([]string, 0, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if goal := goalCache.GetGoalByID(goalID); goal == nil || r.IsAvailable(userID, goal.ChallengeID) {
+			available = append(available, goalID)
+		}
+	}
+	return available
+}
+
+type challengeRolloutsKey struct{}
+
+// WithChallengeRollouts returns a context whose InitializePlayer only assigns
+// the defaultAssigned goals of challenges rolled out to the player. Empty
+// rollouts leave ctx unchanged.
+func WithChallengeRollouts(ctx context.Context, rollouts ChallengeRollouts) context.Context {
+	if len(rollouts) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, challengeRolloutsKey{}, rollouts)
+}
+
+// challengeRolloutsFrom returns the rollouts set by WithChallengeRollouts, or nil.
+func challengeRolloutsFrom(ctx context.Context) ChallengeRollouts {
+	rollouts, _ := ctx.Value(challengeRolloutsKey{}).(ChallengeRollouts)
+	return rollouts
+}
+
+// CheckAvailable returns a *mapper.ChallengeNotFoundError, the error of a
+// challenge that does not exist, when challengeID is not rolled out to userID.
+func (r ChallengeRollouts) CheckAvailable(userID, challengeID string) error {
+	if !r.IsAvailable(userID, challengeID) {
+		return &mapper.ChallengeNotFoundError{ChallengeID: challengeID}
+	}
+	return nil
+}
+
+// CheckGoalAvailable is CheckAvailable for requests naming a goal: it returns
+// the *mapper.GoalNotFoundError of a goal that does not exist. The goal's own
+// challenge in goalCache is checked too, so naming the goal under another
+// challenge does not reveal where it belongs.
+func (r ChallengeRollouts) CheckGoalAvailable(goalCache cache.GoalCache, userID, challengeID, goalID string) error {
+	if len(r) == 0 {
+		return nil
+	}
+
+	available := r.IsAvailable(userID, challengeID)
+	if goal := goalCache.GetGoalByID(goalID); goal != nil && goal.ChallengeID != challengeID {
+		available = available && r.IsAvailable(userID, goal.ChallengeID)
+	}
+	if !available {
+		return &mapper.GoalNotFoundError{GoalID: goalID, ChallengeID: challengeID}
+	}
+	return nil
+}
+
+// ChallengeRolloutStatus is a challenge's rollout as seen for one user.
+type ChallengeRolloutStatus struct {
+	ChallengeID string
+	Percentage  int
+	Bucket      int
+	Available   bool
+}
+
+// UserStatus returns the user's rollout status of every challenge with a
+// rollout percentage among challenges, in order, for support.
+func (r ChallengeRollouts) UserStatus(userID string, challenges []*domain.Challenge) []ChallengeRolloutStatus {
+	var statuses []ChallengeRolloutStatus
+	for _, challenge := range challenges {
+		percentage, ok := r[challenge.ID]
+		if !ok {
+			continue
+		}
+		bucket := RolloutBucket(userID, challenge.ID)
+		statuses = append(statuses, ChallengeRolloutStatus{
+			ChallengeID: challenge.ID,
+			Percentage:  percentage,
+			Bucket:      bucket,
+			Available:   bucket < percentage,
+		})
+	}
+	return statuses
+}
+
+// FilterSummary removes the challenges not rolled out to userID from summary,
+// and their counts from its totals.
+func (r ChallengeRollouts) FilterSummary(userID string, summary *ProgressSummary) {
+	if len(r) == 0 || summary == nil {
+		return
+	}
+
+	available := summary.Challenges[:0]
+	for _, challenge := range summary.Challenges {
+		if r.IsAvailable(userID, challenge.ChallengeID) {
+			available = append(available, challenge)
+			continue
+		}
+		summary.Totals.TotalGoals -= challenge.TotalGoals
+		summary.Totals.Completed -= challenge.Completed
+		summary.Totals.Claimed -= challenge.Claimed
+		summary.Totals.InProgress -= challenge.InProgress
+	}
+	summary.Challenges = available
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChallengeRollouts(t *testing.T) {
+	rollouts, err := ParseChallengeRollouts([]byte(`{"challenges": [
+		{"challengeId": "daily"},
+		{"challengeId": "canary", "rolloutPercentage": 5},
+		{"challengeId": "dark", "rolloutPercentage": 0},
+		{"challengeId": "everyone", "rolloutPercentage": 100}
+	]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, ChallengeRollouts{"canary": 5, "dark": 0, "everyone": 100}, rollouts)
+}
+
+func TestParseChallengeRollouts_OutOfRange(t *testing.T) {
+	for _, percentage := range []int{-1, 101} {
+		_, err := ParseChallengeRollouts(fmt.Appendf(nil,
+			`{"challenges": [{"challengeId": "canary", "rolloutPercentage": %d}]}`, percentage))
+
+		assert.EqualError(t, err, fmt.Sprintf(
+			`challenge "canary": rolloutPercentage must be between 0 and 100, got %d`, percentage))
+	}
+
+	_, err := ParseChallengeRollouts([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestLoadChallengeRollouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [{"challengeId": "canary", "rolloutPercentage": 5}]}`), 0o600))
+
+	rollouts, err := LoadChallengeRollouts(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, ChallengeRollouts{"canary": 5}, rollouts)
+
+	_, err = LoadChallengeRollouts(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestCheckRolloutsNotLowered(t *testing.T) {
+	previous := ChallengeRollouts{"canary": 10, "dark": 0}
+	previousIDs := []string{"daily", "canary", "dark"}
+
+	assert.NoError(t, CheckRolloutsNotLowered(previous, previousIDs, ChallengeRollouts{"canary": 20, "dark": 0}))
+	assert.NoError(t, CheckRolloutsNotLowered(previous, previousIDs, ChallengeRollouts{"dark": 5}),
+		"removing a percentage raises it to 100")
+	assert.NoError(t, CheckRolloutsNotLowered(previous, previousIDs, ChallengeRollouts{"canary": 10, "new": 1}),
+		"new challenges may start at any percentage")
+
+	err := CheckRolloutsNotLowered(previous, previousIDs, ChallengeRollouts{"canary": 5, "daily": 50})
+	assert.EqualError(t, err, `rolloutPercentage cannot be lowered, players who had the challenge would lose it: `+
+		`challenge "canary" from 10 to 5, challenge "daily" from 100 to 50`)
+}
+
+func TestCheckConfigRolloutsNotLowered(t *testing.T) {
+	dir := t.TempDir()
+	previousPath := filepath.Join(dir, "last-good.json")
+	path := filepath.Join(dir, "challenges.json")
+	require.NoError(t, os.WriteFile(previousPath, []byte(`{"challenges": [
+		{"challengeId": "daily"},
+		{"challengeId": "canary", "rolloutPercentage": 10}
+	]}`), 0o600))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [
+		{"challengeId": "daily"},
+		{"challengeId": "canary", "rolloutPercentage": 20}
+	]}`), 0o600))
+	assert.NoError(t, CheckConfigRolloutsNotLowered(previousPath, path))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [
+		{"challengeId": "daily", "rolloutPercentage": 50},
+		{"challengeId": "canary", "rolloutPercentage": 10}
+	]}`), 0o600))
+	assert.ErrorContains(t, CheckConfigRolloutsNotLowered(previousPath, path), `challenge "daily" from 100 to 50`)
+
+	assert.Error(t, CheckConfigRolloutsNotLowered(filepath.Join(dir, "missing.json"), path))
+}
+
+func TestRolloutBucket_Deterministic(t *testing.T) {
+	assert.Equal(t, RolloutBucket("user1", "canary"), RolloutBucket("user1", "canary"))
+
+	// The challenge ID is part of the hash, so users land in different buckets per challenge
+	differs := false
+	for i := range 100 {
+		userID := fmt.Sprintf("user-%d", i)
+		if RolloutBucket(userID, "canary") != RolloutBucket(userID, "other") {
+			differs = true
+			break
+		}
+	}
+	assert.True(t, differs)
+}
+
+// TestRolloutBucket_Distribution checks that buckets are spread evenly, so a
+// percentage exposes about that share of users.
+func TestRolloutBucket_Distribution(t *testing.T) {
+	const users = 100_000
+	var counts [100]int
+	for i := range users {
+		bucket := RolloutBucket(fmt.Sprintf("user-%d", i), "canary")
+		require.True(t, bucket >= 0 && bucket < 100, "bucket %d out of range", bucket)
+		counts[bucket]++
+	}
+
+	// 1000 users expected per bucket; the standard deviation is about 31
+	for bucket, count := range counts {
+		assert.InDelta(t, users/100, count, 200, "bucket %d", bucket)
+	}
+
+	for _, percentage := range []int{1, 5, 50} {
+		rollouts := ChallengeRollouts{"canary": percentage}
+		exposed := 0
+		for i := range users {
+			if rollouts.IsAvailable(fmt.Sprintf("user-%d", i), "canary") {
+				exposed++
+			}
+		}
+		assert.InDelta(t, float64(percentage)/100, float64(exposed)/users, 0.005, "%d%%", percentage)
+	}
+}
+
+func TestChallengeRollouts_RaisingOnlyAddsUsers(t *testing.T) {
+	for i := range 10_000 {
+		userID := fmt.Sprintf("user-%d", i)
+		previous := false
+		for percentage := 0; percentage <= 100; percentage++ {
+			available := ChallengeRollouts{"canary": percentage}.IsAvailable(userID, "canary")
+			require.False(t, previous && !available, "%s lost the challenge at %d%%", userID, percentage)
+			previous = available
+		}
+		assert.True(t, previous, "%s does not have the challenge at 100%%", userID)
+	}
+}
+
+func TestChallengeRollouts_IsAvailable(t *testing.T) {
+	rollouts := ChallengeRollouts{"dark": 0, "everyone": 100}
+
+	assert.True(t, rollouts.IsAvailable("user1", "daily"), "challenges without a percentage are available")
+	assert.False(t, rollouts.IsAvailable("user1", "dark"))
+	assert.True(t, rollouts.IsAvailable("user1", "everyone"))
+	assert.True(t, ChallengeRollouts(nil).IsAvailable("user1", "dark"))
+}
+
+func TestChallengeRollouts_AvailableChallenges(t *testing.T) {
+	challenges := []*domain.Challenge{{ID: "daily"}, {ID: "dark"}, {ID: "weekly"}}
+
+	available := ChallengeRollouts{"dark": 0}.AvailableChallenges("user1", challenges)
+
+	assert.Equal(t, []*domain.Challenge{{ID: "daily"}, {ID: "weekly"}}, available)
+	assert.Len(t, challenges, 3, "the input is not modified")
+}
+
+func TestChallengeRollouts_AvailableGoals(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g1", ChallengeID: "daily"}, {ID: "g2", ChallengeID: "dark"}}
+
+	assert.Equal(t, goals[:1], ChallengeRollouts{"dark": 0}.AvailableGoals("user1", goals))
+	assert.Equal(t, goals, ChallengeRollouts(nil).AvailableGoals("user1", goals))
+}
+
+func TestChallengeRollouts_AvailableGoalIDs(t *testing.T) {
+	goalCache := newChainGoalCache()
+	goalIDs := []string{"t1", "s1a", "s1b", "s2"}
+
+	assert.Equal(t, []string{"t1", "s2"}, ChallengeRollouts{"season-1": 0}.AvailableGoalIDs(goalCache, "user1", goalIDs))
+	assert.Equal(t, goalIDs, ChallengeRollouts(nil).AvailableGoalIDs(goalCache, "user1", goalIDs))
+}
+
+func TestChallengeRollouts_CheckAvailable(t *testing.T) {
+	rollouts := ChallengeRollouts{"season-1": 0}
+
+	assert.NoError(t, rollouts.CheckAvailable("user1", "tutorial"))
+
+	var notFound *mapper.ChallengeNotFoundError
+	require.ErrorAs(t, rollouts.CheckAvailable("user1", "season-1"), &notFound)
+	assert.Equal(t, "season-1", notFound.ChallengeID)
+}
+
+func TestChallengeRollouts_CheckGoalAvailable(t *testing.T) {
+	goalCache := newChainGoalCache()
+	rollouts := ChallengeRollouts{"season-1": 0}
+
+	assert.NoError(t, rollouts.CheckGoalAvailable(goalCache, "user1", "tutorial", "t1"))
+	assert.NoError(t, ChallengeRollouts(nil).CheckGoalAvailable(goalCache, "user1", "season-1", "s1a"))
+
+	var notFound *mapper.GoalNotFoundError
+	require.ErrorAs(t, rollouts.CheckGoalAvailable(goalCache, "user1", "season-1", "s1a"), &notFound)
+	assert.Equal(t, &mapper.GoalNotFoundError{GoalID: "s1a", ChallengeID: "season-1"}, notFound)
+
+	// Naming the goal under another challenge does not reveal season-1
+	require.ErrorAs(t, rollouts.CheckGoalAvailable(goalCache, "user1", "tutorial", "s1a"), &notFound)
+	assert.Equal(t, &mapper.GoalNotFoundError{GoalID: "s1a", ChallengeID: "tutorial"}, notFound)
+}
+
+func TestChallengeRollouts_UserStatus(t *testing.T) {
+	challenges := []*domain.Challenge{{ID: "daily"}, {ID: "dark"}, {ID: "everyone"}}
+
+	statuses := ChallengeRollouts{"dark": 0, "everyone": 100}.UserStatus("user1", challenges)
+
+	assert.Equal(t, []ChallengeRolloutStatus{
+		{ChallengeID: "dark", Percentage: 0, Bucket: RolloutBucket("user1", "dark"), Available: false},
+		{ChallengeID: "everyone", Percentage: 100, Bucket: RolloutBucket("user1", "everyone"), Available: true},
+	}, statuses)
+}
+
+func TestChallengeRollouts_FilterSummary(t *testing.T) {
+	summary := &ProgressSummary{
+		Totals: ProgressCounts{TotalGoals: 5, Completed: 3, Claimed: 1, InProgress: 2},
+		Challenges: []*ChallengeProgressSummary{
+			{ChallengeID: "daily", ProgressCounts: ProgressCounts{TotalGoals: 3, Completed: 2, Claimed: 1, InProgress: 1}},
+			{ChallengeID: "dark", ProgressCounts: ProgressCounts{TotalGoals: 2, Completed: 1, InProgress: 1}},
+		},
+	}
+
+	ChallengeRollouts{"dark": 0}.FilterSummary("user1", summary)
+
+	assert.Equal(t, ProgressCounts{TotalGoals: 3, Completed: 2, Claimed: 1, InProgress: 1}, summary.Totals)
+	require.Len(t, summary.Challenges, 1)
+	assert.Equal(t, "daily", summary.Challenges[0].ChallengeID)
+
+	ChallengeRollouts(nil).FilterSummary("user1", nil)
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// ChallengePrerequisites maps challenge IDs to the "prerequisiteChallengeIds"
+// configured for them in the challenge config.
+//
+// A challenge is locked for a user until every prerequisite challenge is
+// completed, i.e. all of its goals are claimed. Goals of a locked challenge
+// cannot be claimed, selected or activated. Only direct prerequisites are
+// checked; a chain unlocks one level at a time because the goals of a locked
+// challenge cannot be claimed. A nil ChallengePrerequisites locks nothing.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so it is read
+// from the config file separately by LoadChallengePrerequisites.
+type ChallengePrerequisites map[string][]string
+
+// challengePrerequisitesConfig is the subset of challenges.json needed to read
+// the challenge prerequisites.
+type challengePrerequisitesConfig struct {
+	Challenges []struct {
+		ID                       string   `json:"challengeId"`
+		PrerequisiteChallengeIDs []string `json:"prerequisiteChallengeIds"`
+	} `json:"challenges"`
+}
+
+// LoadChallengePrerequisites reads and validates the challenge prerequisites
+// from the challenge config file.
+func LoadChallengePrerequisites(configPath string) (ChallengePrerequisites, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseChallengePrerequisites(data)
+}
+
+// ParseChallengePrerequisites extracts the challenge prerequisites from challenge
+// config JSON. Every prerequisite must be a challenge in the same config, and
+// prerequisites must not form a cycle (a challenge requiring itself included).
+func ParseChallengePrerequisites(data []byte) (ChallengePrerequisites, error) {
+	var cfg challengePrerequisitesConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	known := make(map[string]bool, len(cfg.Challenges))
+	for _, challenge := range cfg.Challenges {
+		known[challenge.ID] = true
+	}
+
+	prerequisites := make(ChallengePrerequisites)
+	order := make([]string, 0, len(cfg.Challenges))
+	for _, challenge := range cfg.Challenges {
+		if len(challenge.PrerequisiteChallengeIDs) == 0 {
+			continue
+		}
+		for _, prereqID := range challenge.PrerequisiteChallengeIDs {
+			if !known[prereqID] {
+				return nil, fmt.Errorf("challenge '%s': unknown prerequisite challenge '%s'", challenge.ID, prereqID)
+			}
+		}
+		prerequisites[challenge.ID] = challenge.PrerequisiteChallengeIDs
+		order = append(order, challenge.ID)
+	}
+
+	if cycle := prerequisites.findCycle(order); cycle != nil {
+		return nil, fmt.Errorf("prerequisite challenge cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	return prerequisites, nil
+}
+
+// findCycle returns a prerequisite cycle as a path that starts and ends with the
+// same challenge, or nil if there is none. Challenges are visited in order so the
+// reported cycle is deterministic.
+func (p ChallengePrerequisites) findCycle(order []string) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(p))
+	var path []string
+
+	var visit func(challengeID string) []string
+	visit = func(challengeID string) []string {
+		switch state[challengeID] {
+		case done:
+			return nil
+		case visiting:
+			for i, id := range path {
+				if id == challengeID {
+					return append(append([]string(nil), path[i:]...), challengeID)
+				}
+			}
+		}
+
+		state[challengeID] = visiting
+		path = append(path, challengeID)
+		for _, prereqID := range p[challengeID] {
+			if cycle := visit(prereqID); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[challengeID] = done
+		return nil
+	}
+
+	for _, challengeID := range order {
+		if cycle := visit(challengeID); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// IsChallengeCompleted reports whether every goal of the challenge is claimed in
+// progressMap. A goal without a row is not claimed.
+func IsChallengeCompleted(challenge *domain.Challenge, progressMap map[string]*domain.UserGoalProgress) bool {
+	if challenge == nil {
+		return false
+	}
+
+	for _, goal := range challenge.Goals {
+		progress := progressMap[goal.ID]
+		if progress == nil || !progress.IsClaimed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LockedBy returns the prerequisite challenges of challengeID that are not
+// completed in progressMap, in config order. The challenge is unlocked when the
+// result is empty. Prerequisites no longer in goalCache (removed by a reload)
+// are ignored.
+func (p ChallengePrerequisites) LockedBy(
+	challengeID string,
+	goalCache cache.GoalCache,
+	progressMap map[string]*domain.UserGoalProgress,
+) []string {
+	var lockedBy []string
+	for _, prereqID := range p[challengeID] {
+		prereq := goalCache.GetChallengeByChallengeID(prereqID)
+		if prereq != nil && !IsChallengeCompleted(prereq, progressMap) {
+			lockedBy = append(lockedBy, prereqID)
+		}
+	}
+
+	return lockedBy
+}
+
+// Locks returns the lock reason of each locked challenge among challengeIDs for
+// the response mappers. Unlocked challenges have no entry.
+//
+// progressMap must hold the user's rows for the goals of the prerequisite
+// challenges; use WithPrerequisites first when it only holds part of them.
+func (p ChallengePrerequisites) Locks(
+	challengeIDs []string,
+	goalCache cache.GoalCache,
+	progressMap map[string]*domain.UserGoalProgress,
+) map[string]string {
+	if len(p) == 0 {
+		return nil
+	}
+
+	locks := make(map[string]string)
+	for _, challengeID := range challengeIDs {
+		if lockedBy := p.LockedBy(challengeID, goalCache, progressMap); len(lockedBy) > 0 {
+			locks[challengeID] = ChallengeLockReason(lockedBy)
+		}
+	}
+
+	return locks
+}
+
+// ChallengeLockReason describes why a challenge is locked, given the prerequisite
+// challenges that are not completed.
+func ChallengeLockReason(lockedBy []string) string {
+	return "prerequisite challenges not completed: " + strings.Join(lockedBy, ", ")
+}
+
+// ClearLockedActivatable removes the goals of locked challenges from activatable,
+// since they cannot be activated. activatable is modified in place.
+func ClearLockedActivatable(activatable map[string]bool, goals []*domain.Goal, locks map[string]string) {
+	if len(activatable) == 0 || len(locks) == 0 {
+		return
+	}
+
+	for _, goal := range goals {
+		if _, locked := locks[goal.ChallengeID]; locked {
+			delete(activatable, goal.ID)
+		}
+	}
+}
+
+// MissingPrerequisiteGoals returns the goal IDs of the prerequisite challenges of
+// challengeIDs that have no entry in progressMap.
+//
+// Callers holding only part of the user's progress (active_only, a single
+// challenge or page) load these rows before calling Locks or LockedBy, so
+// completed prerequisites are not missed.
+func (p ChallengePrerequisites) MissingPrerequisiteGoals(
+	challengeIDs []string,
+	goalCache cache.GoalCache,
+	progressMap map[string]*domain.UserGoalProgress,
+) []string {
+	if len(p) == 0 {
+		return nil
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, challengeID := range challengeIDs {
+		for _, prereqID := range p[challengeID] {
+			if seen[prereqID] {
+				continue
+			}
+			seen[prereqID] = true
+
+			prereq := goalCache.GetChallengeByChallengeID(prereqID)
+			if prereq == nil {
+				continue
+			}
+			for _, goal := range prereq.Goals {
+				if progressMap[goal.ID] == nil {
+					missing = append(missing, goal.ID)
+				}
+			}
+		}
+	}
+
+	return missing
+}
+
+// WithPrerequisites returns progressMap extended with the rows returned by
+// MissingPrerequisiteGoals, loaded in one query. progressMap itself is not
+// modified and is returned as-is when nothing is missing.
+func (p ChallengePrerequisites) WithPrerequisites(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	userID string,
+	challengeIDs []string,
+	goalCache cache.GoalCache,
+	progressMap map[string]*domain.UserGoalProgress,
+) (map[string]*domain.UserGoalProgress, error) {
+	missing := p.MissingPrerequisiteGoals(challengeIDs, goalCache, progressMap)
+	if len(missing) == 0 {
+		return progressMap, nil
+	}
+
+	rows, err := repo.GetGoalsByIDs(ctx, userID, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prerequisite challenge progress: %w", err)
+	}
+
+	merged := make(map[string]*domain.UserGoalProgress, len(progressMap)+len(rows))
+	for goalID, progress := range progressMap {
+		merged[goalID] = progress
+	}
+	for _, row := range rows {
+		merged[row.GoalID] = row
+	}
+	return merged, nil
+}
+
+// CheckUnlocked returns a *mapper.ChallengeLockedError when the challenge is
+// locked for the user. The rows of the prerequisite challenges' goals are loaded
+// in one query; challenges without prerequisites need no query.
+func (p ChallengePrerequisites) CheckUnlocked(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	goalCache cache.GoalCache,
+	userID string,
+	challengeID string,
+) error {
+	lockedBy, err := p.UserLockedBy(ctx, repo, goalCache, userID, challengeID)
+	if err != nil {
+		return err
+	}
+
+	return checkChallengeUnlocked(challengeID, lockedBy)
+}
+
+// UserLockedBy is LockedBy for the user's stored progress, loading the rows of
+// the prerequisite challenges' goals in one query; challenges without
+// prerequisites need no query.
+func (p ChallengePrerequisites) UserLockedBy(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	goalCache cache.GoalCache,
+	userID string,
+	challengeID string,
+) ([]string, error) {
+	if len(p[challengeID]) == 0 {
+		return nil, nil
+	}
+
+	lookup, err := p.WithPrerequisites(ctx, repo, userID, []string{challengeID}, goalCache, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.LockedBy(challengeID, goalCache, lookup), nil
+}
+
+// checkChallengeUnlocked fails when lockedBy, the prerequisite challenges of
+// challengeID the user has not completed (see LockedBy), is not empty.
+func checkChallengeUnlocked(challengeID string, lockedBy []string) error {
+	if len(lockedBy) > 0 {
+		return &mapper.ChallengeLockedError{
+			ChallengeID: challengeID,
+			LockedBy:    lockedBy,
+		}
+	}
+	return nil
+}
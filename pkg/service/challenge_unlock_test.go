@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newChainGoalCache builds tutorial -> season-1 -> season-2, where season-1 has
+// two goals.
+func newChainGoalCache() commonCache.GoalCache {
+	challenges := []*domain.Challenge{
+		{ID: "tutorial", Goals: []*domain.Goal{{ID: "t1", ChallengeID: "tutorial"}}},
+		{ID: "season-1", Goals: []*domain.Goal{{ID: "s1a", ChallengeID: "season-1"}, {ID: "s1b", ChallengeID: "season-1"}}},
+		{ID: "season-2", Goals: []*domain.Goal{{ID: "s2", ChallengeID: "season-2"}}},
+	}
+	return commonCache.NewInMemoryGoalCache(&config.Config{Challenges: challenges}, "", slog.Default())
+}
+
+var chainPrerequisites = ChallengePrerequisites{
+	"season-1": {"tutorial"},
+	"season-2": {"season-1"},
+}
+
+func claimed(goalID string) *domain.UserGoalProgress {
+	return &domain.UserGoalProgress{GoalID: goalID, Status: domain.GoalStatusClaimed}
+}
+
+func TestParseChallengePrerequisites(t *testing.T) {
+	prereqs, err := ParseChallengePrerequisites([]byte(`{"challenges":[
+		{"challengeId":"tutorial"},
+		{"challengeId":"season-1","prerequisiteChallengeIds":["tutorial"]},
+		{"challengeId":"season-2","prerequisiteChallengeIds":["season-1","tutorial"]}
+	]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, ChallengePrerequisites{
+		"season-1": {"tutorial"},
+		"season-2": {"season-1", "tutorial"},
+	}, prereqs)
+}
+
+func TestParseChallengePrerequisites_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "unknown challenge",
+			config:  `{"challenges":[{"challengeId":"a","prerequisiteChallengeIds":["missing"]}]}`,
+			wantErr: "challenge 'a': unknown prerequisite challenge 'missing'",
+		},
+		{
+			name:    "self reference",
+			config:  `{"challenges":[{"challengeId":"a","prerequisiteChallengeIds":["a"]}]}`,
+			wantErr: "prerequisite challenge cycle: a -> a",
+		},
+		{
+			name: "cycle through three challenges",
+			config: `{"challenges":[
+				{"challengeId":"root"},
+				{"challengeId":"a","prerequisiteChallengeIds":["root","b"]},
+				{"challengeId":"b","prerequisiteChallengeIds":["c"]},
+				{"challengeId":"c","prerequisiteChallengeIds":["a"]}
+			]}`,
+			wantErr: "prerequisite challenge cycle: a -> b -> c -> a",
+		},
+		{
+			name:    "malformed JSON",
+			config:  `{"challenges":`,
+			wantErr: "failed to parse challenge config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseChallengePrerequisites([]byte(tt.config))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseChallengePrerequisites_SharedPrerequisiteIsNotACycle(t *testing.T) {
+	_, err := ParseChallengePrerequisites([]byte(`{"challenges":[
+		{"challengeId":"root"},
+		{"challengeId":"a","prerequisiteChallengeIds":["root"]},
+		{"challengeId":"b","prerequisiteChallengeIds":["root","a"]}
+	]}`))
+
+	assert.NoError(t, err)
+}
+
+func TestIsChallengeCompleted(t *testing.T) {
+	challenge := &domain.Challenge{ID: "c", Goals: []*domain.Goal{{ID: "g1"}, {ID: "g2"}}}
+
+	assert.False(t, IsChallengeCompleted(challenge, nil))
+	assert.False(t, IsChallengeCompleted(challenge, map[string]*domain.UserGoalProgress{
+		"g1": claimed("g1"),
+		"g2": {GoalID: "g2", Status: domain.GoalStatusCompleted},
+	}), "completed but unclaimed goals do not count")
+	assert.True(t, IsChallengeCompleted(challenge, map[string]*domain.UserGoalProgress{
+		"g1": claimed("g1"),
+		"g2": claimed("g2"),
+	}))
+	assert.False(t, IsChallengeCompleted(nil, nil))
+}
+
+// TestChallengePrerequisites_TwoLevelChain unlocks the chain one level at a time
+// as the user claims goals during a session.
+func TestChallengePrerequisites_TwoLevelChain(t *testing.T) {
+	goalCache := newChainGoalCache()
+	ids := []string{"tutorial", "season-1", "season-2"}
+	progress := map[string]*domain.UserGoalProgress{}
+
+	assert.Equal(t, map[string]string{
+		"season-1": "prerequisite challenges not completed: tutorial",
+		"season-2": "prerequisite challenges not completed: season-1",
+	}, chainPrerequisites.Locks(ids, goalCache, progress))
+
+	progress["t1"] = claimed("t1")
+	assert.Equal(t, map[string]string{
+		"season-2": "prerequisite challenges not completed: season-1",
+	}, chainPrerequisites.Locks(ids, goalCache, progress))
+
+	progress["s1a"] = claimed("s1a")
+	assert.Equal(t, []string{"season-1"}, chainPrerequisites.LockedBy("season-2", goalCache, progress),
+		"season-1 is only completed once every goal is claimed")
+
+	progress["s1b"] = claimed("s1b")
+	assert.Empty(t, chainPrerequisites.Locks(ids, goalCache, progress))
+}
+
+func TestChallengePrerequisites_NilLocksNothing(t *testing.T) {
+	var prereqs ChallengePrerequisites
+
+	assert.Nil(t, prereqs.Locks([]string{"season-1"}, newChainGoalCache(), nil))
+	assert.NoError(t, prereqs.CheckUnlocked(context.Background(), new(mocks.GoalRepository), newChainGoalCache(), "user1", "season-1"))
+}
+
+func TestChallengePrerequisites_LockedByIgnoresRemovedChallenges(t *testing.T) {
+	prereqs := ChallengePrerequisites{"season-1": {"tutorial", "removed"}}
+
+	assert.Empty(t, prereqs.LockedBy("season-1", newChainGoalCache(), map[string]*domain.UserGoalProgress{"t1": claimed("t1")}))
+}
+
+func TestClearLockedActivatable(t *testing.T) {
+	goals := []*domain.Goal{{ID: "t1", ChallengeID: "tutorial"}, {ID: "s2", ChallengeID: "season-2"}}
+	activatable := map[string]bool{"t1": true, "s2": true}
+
+	ClearLockedActivatable(activatable, goals, map[string]string{"season-2": "locked"})
+
+	assert.Equal(t, map[string]bool{"t1": true}, activatable)
+}
+
+func TestChallengePrerequisites_WithPrerequisites(t *testing.T) {
+	progressMap := map[string]*domain.UserGoalProgress{"s1a": claimed("s1a")}
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"s1b"}).Return([]*domain.UserGoalProgress{claimed("s1b")}, nil)
+
+	merged, err := chainPrerequisites.WithPrerequisites(context.Background(), mockRepo, "user1", []string{"season-2", "tutorial"}, newChainGoalCache(), progressMap)
+
+	require.NoError(t, err)
+	assert.Contains(t, merged, "s1b")
+	assert.Len(t, progressMap, 1, "input map must not be modified")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestChallengePrerequisites_CheckUnlocked(t *testing.T) {
+	goalCache := newChainGoalCache()
+
+	t.Run("locked", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"s1a", "s1b"}).Return([]*domain.UserGoalProgress{claimed("s1a")}, nil)
+
+		err := chainPrerequisites.CheckUnlocked(context.Background(), mockRepo, goalCache, "user1", "season-2")
+
+		var locked *mapper.ChallengeLockedError
+		require.ErrorAs(t, err, &locked)
+		assert.Equal(t, "season-2", locked.ChallengeID)
+		assert.Equal(t, []string{"season-1"}, locked.LockedBy)
+	})
+
+	t.Run("unlocked", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"t1"}).Return([]*domain.UserGoalProgress{claimed("t1")}, nil)
+
+		assert.NoError(t, chainPrerequisites.CheckUnlocked(context.Background(), mockRepo, goalCache, "user1", "season-1"))
+	})
+
+	t.Run("no prerequisites needs no query", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+
+		assert.NoError(t, chainPrerequisites.CheckUnlocked(context.Background(), mockRepo, goalCache, "user1", "tutorial"))
+		mockRepo.AssertNotCalled(t, "GetGoalsByIDs", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mockRepo := new(mocks.GoalRepository)
+		mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"t1"}).Return(nil, errors.New("db down"))
+
+		err := chainPrerequisites.CheckUnlocked(context.Background(), mockRepo, goalCache, "user1", "season-1")
+
+		assert.ErrorContains(t, err, "failed to load prerequisite challenge progress")
+	})
+}
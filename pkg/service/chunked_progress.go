@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DroppedProgressRow is a row ChunkedProgressWriter could not write because the
+// row itself is bad, e.g. a goal ID longer than its column.
+type DroppedProgressRow struct {
+	Row repository.CopyRow
+	Err error
+}
+
+// ChunkedWriteResult is the outcome of ChunkedProgressWriter.Write.
+type ChunkedWriteResult struct {
+	// Written is the number of rows written.
+	Written int
+	// Dropped are the bad rows, isolated and left out; resending them fails again.
+	Dropped []DroppedProgressRow
+	// Unwritten are the rows not attempted after an error that is not caused by
+	// a row (e.g. a lost connection), in input order. They can be resent; rows
+	// before them are written or dropped.
+	Unwritten []repository.CopyRow
+}
+
+// ChunkedProgressWriter writes progress rows with BatchUpsertProgressWithCOPY in
+// chunks, so that one bad row does not fail a whole batch of the event consumer.
+//
+// BatchUpsertProgressWithCOPY writes its rows in one transaction. When a chunk
+// fails because of its rows (see serviceRepo.IsRowError), it is split in half
+// and each half is written again, down to single rows; a single row that still
+// fails is logged, counted and dropped, and writing continues with the rest.
+// A chunk with one bad row costs about 2*log2(chunk size) extra writes.
+//
+// Any other error stops the write: retrying chunks against a database that is
+// down would only add load. Rows are written in order, so the rows not attempted
+// are reported as ChunkedWriteResult.Unwritten for the caller to resend.
+type ChunkedProgressWriter struct {
+	repo      repository.GoalRepository
+	chunkSize int
+
+	rowsWritten   prometheus.Counter
+	chunksRetried prometheus.Counter
+	rowsDropped   prometheus.Counter
+}
+
+// NewChunkedProgressWriter creates a writer of chunkSize rows per COPY;
+// a non-positive chunkSize uses DefaultBatchProgressChunkSize.
+func NewChunkedProgressWriter(repo repository.GoalRepository, chunkSize int) *ChunkedProgressWriter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultBatchProgressChunkSize
+	}
+
+	return &ChunkedProgressWriter{
+		repo:      repo,
+		chunkSize: chunkSize,
+		rowsWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "progress_copy_rows_written_total",
+			Help: "Progress rows written by chunked COPY upserts.",
+		}),
+		chunksRetried: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "progress_copy_chunks_retried_total",
+			Help: "Progress COPY chunks that failed because of a bad row and were retried as two halves.",
+		}),
+		rowsDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "progress_copy_rows_dropped_total",
+			Help: "Progress rows dropped because writing them on their own failed.",
+		}),
+	}
+}
+
+// NewChunkedProgressWriterFromEnv creates a writer of PROGRESS_COPY_CHUNK_SIZE
+// rows per COPY (default 1000).
+func NewChunkedProgressWriterFromEnv(repo repository.GoalRepository) *ChunkedProgressWriter {
+	return NewChunkedProgressWriter(repo, common.GetEnvInt("PROGRESS_COPY_CHUNK_SIZE", DefaultBatchProgressChunkSize))
+}
+
+// Collectors returns the writer metrics for registration.
+func (w *ChunkedProgressWriter) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{w.rowsWritten, w.chunksRetried, w.rowsDropped}
+}
+
+// Write writes rows chunk by chunk, dropping the bad ones, which are logged to
+// logger. It returns an error, along with the result so far, when a write fails
+// for another reason than its rows.
+func (w *ChunkedProgressWriter) Write(
+	ctx context.Context,
+	rows []repository.CopyRow,
+	logger logrus.FieldLogger,
+) (*ChunkedWriteResult, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	result := &ChunkedWriteResult{}
+	for start := 0; start < len(rows); start += w.chunkSize {
+		end := min(start+w.chunkSize, len(rows))
+		if err := w.writeChunk(ctx, rows[start:end], result, logger); err != nil {
+			result.Unwritten = rows[result.Written+len(result.Dropped):]
+			logger.WithFields(logrus.Fields{
+				"rows":      len(rows),
+				"written":   result.Written,
+				"dropped":   len(result.Dropped),
+				"unwritten": len(result.Unwritten),
+				"error":     err,
+			}).Error("Failed to write progress chunk")
+			return result, fmt.Errorf("failed to write progress: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// writeChunk writes one chunk, bisecting it while it fails because of its rows.
+func (w *ChunkedProgressWriter) writeChunk(
+	ctx context.Context,
+	chunk []repository.CopyRow,
+	result *ChunkedWriteResult,
+	logger logrus.FieldLogger,
+) error {
+	err := w.repo.BatchUpsertProgressWithCOPY(ctx, chunk)
+	switch {
+	case err == nil:
+		result.Written += len(chunk)
+		w.rowsWritten.Add(float64(len(chunk)))
+		return nil
+	case !serviceRepo.IsRowError(err):
+		return err
+	case len(chunk) == 1:
+		row := chunk[0]
+		logger.WithFields(logrus.Fields{
+			"user_id":      row.UserID,
+			"goal_id":      row.GoalID,
+			"challenge_id": row.ChallengeID,
+			"namespace":    row.Namespace,
+			"error":        err,
+		}).Error("Dropped progress row that cannot be written")
+		result.Dropped = append(result.Dropped, DroppedProgressRow{Row: row, Err: err})
+		w.rowsDropped.Inc()
+		return nil
+	}
+
+	w.chunksRetried.Inc()
+	mid := len(chunk) / 2
+	if err := w.writeChunk(ctx, chunk[:mid], result, logger); err != nil {
+		return err
+	}
+	return w.writeChunk(ctx, chunk[mid:], result, logger)
+}
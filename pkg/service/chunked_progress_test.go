@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyGoalRepository fails COPY chunks holding a goal ID longer than the
+// goal_id column, like PostgreSQL does, and records what was written.
+type copyGoalRepository struct {
+	repository.GoalRepository
+	calls   int
+	written []repository.CopyRow
+	// failAfter fails every call after that many with a connection error; 0 never.
+	failAfter int
+}
+
+func (r *copyGoalRepository) BatchUpsertProgressWithCOPY(_ context.Context, rows []repository.CopyRow) error {
+	r.calls++
+	if r.failAfter > 0 && r.calls > r.failAfter {
+		return commonErrors.ErrDatabaseError("begin transaction for COPY", errors.New("connection refused"))
+	}
+	for _, row := range rows {
+		if len(row.GoalID) > 100 {
+			return commonErrors.ErrDatabaseError("commit COPY transaction",
+				&pq.Error{Code: "22001", Message: "value too long for type character varying(100)"})
+		}
+	}
+	r.written = append(r.written, rows...)
+	return nil
+}
+
+func newCopyRows(n int) []repository.CopyRow {
+	rows := make([]repository.CopyRow, n)
+	for i := range rows {
+		rows[i] = repository.CopyRow{UserID: fmt.Sprintf("user-%04d", i), GoalID: "wins", ChallengeID: "season", Namespace: "ns"}
+	}
+	return rows
+}
+
+func TestChunkedProgressWriter_DropsPoisonedRow(t *testing.T) {
+	rows := newCopyRows(1000)
+	rows[500].GoalID = strings.Repeat("x", 101)
+
+	repo := &copyGoalRepository{}
+	writer := NewChunkedProgressWriter(repo, 100)
+	logger, hook := logtest.NewNullLogger()
+	result, err := writer.Write(context.Background(), rows, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, 999, result.Written)
+	require.Len(t, result.Dropped, 1)
+	assert.Equal(t, "user-0500", result.Dropped[0].Row.UserID)
+	assert.True(t, serviceRepo.IsRowError(result.Dropped[0].Err))
+	assert.Empty(t, result.Unwritten)
+
+	assert.Len(t, repo.written, 999)
+	assert.Equal(t, "user-0499", repo.written[499].UserID)
+	assert.Equal(t, "user-0501", repo.written[500].UserID, "rows after the bad one are written in order")
+
+	// 10 chunks; the bad row heads its chunk, bisected 100 -> 50 -> 25 -> 12 -> 6 -> 3 -> 1
+	assert.Equal(t, 999.0, testutil.ToFloat64(writer.rowsWritten))
+	assert.Equal(t, 6.0, testutil.ToFloat64(writer.chunksRetried))
+	assert.Equal(t, 1.0, testutil.ToFloat64(writer.rowsDropped))
+	assert.Equal(t, 10+2*6, repo.calls)
+
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, "Dropped progress row that cannot be written", hook.LastEntry().Message)
+	assert.Equal(t, "user-0500", hook.LastEntry().Data["user_id"])
+}
+
+func TestChunkedProgressWriter_NilLogger(t *testing.T) {
+	repo := &copyGoalRepository{}
+	result, err := NewChunkedProgressWriter(repo, 100).Write(context.Background(), newCopyRows(10), nil)
+
+	assert.EqualError(t, err, "logger cannot be nil")
+	assert.Nil(t, result)
+	assert.Zero(t, repo.calls)
+}
+
+func TestChunkedProgressWriter_StopsOnOtherErrors(t *testing.T) {
+	rows := newCopyRows(250)
+
+	repo := &copyGoalRepository{failAfter: 2}
+	writer := NewChunkedProgressWriter(repo, 100)
+	result, err := writer.Write(context.Background(), rows, logrus.StandardLogger())
+
+	var challengeErr *commonErrors.ChallengeError
+	require.ErrorAs(t, err, &challengeErr)
+	assert.Equal(t, 200, result.Written)
+	assert.Empty(t, result.Dropped)
+	assert.Equal(t, rows[200:], result.Unwritten)
+	assert.Equal(t, 3, repo.calls, "the failed chunk is not bisected")
+	assert.Equal(t, 0.0, testutil.ToFloat64(writer.chunksRetried))
+}
+
+func TestChunkedProgressWriter_UnwrittenAfterDroppedRows(t *testing.T) {
+	rows := newCopyRows(8)
+	rows[1].GoalID = strings.Repeat("x", 101)
+
+	// Chunk [0,4) is bisected into [0,2) -> {0}, {1 dropped}, then [2,4) fails
+	repo := &copyGoalRepository{failAfter: 4}
+	writer := NewChunkedProgressWriter(repo, 4)
+	result, err := writer.Write(context.Background(), rows, logrus.StandardLogger())
+
+	require.Error(t, err)
+	assert.Equal(t, 1, result.Written)
+	require.Len(t, result.Dropped, 1)
+	assert.Equal(t, rows[2:], result.Unwritten)
+}
+
+func TestChunkedProgressWriter_FromEnv(t *testing.T) {
+	t.Setenv("PROGRESS_COPY_CHUNK_SIZE", "")
+	assert.Equal(t, DefaultBatchProgressChunkSize, NewChunkedProgressWriterFromEnv(nil).chunkSize)
+
+	t.Setenv("PROGRESS_COPY_CHUNK_SIZE", "250")
+	assert.Equal(t, 250, NewChunkedProgressWriterFromEnv(nil).chunkSize)
+
+	t.Setenv("PROGRESS_COPY_CHUNK_SIZE", "-1")
+	assert.Equal(t, DefaultBatchProgressChunkSize, NewChunkedProgressWriterFromEnv(nil).chunkSize)
+}
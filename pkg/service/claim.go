@@ -2,16 +2,21 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
+	agsClient "extend-challenge-service/pkg/client"
 	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
 
 	"github.com/AccelByte/extend-challenge-common/pkg/cache"
 	"github.com/AccelByte/extend-challenge-common/pkg/client"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/AccelByte/extend-challenge-common/pkg/repository"
-	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
 
 	"github.com/sirupsen/logrus"
 )
@@ -24,26 +29,97 @@ type ClaimResult struct {
 	ClaimedAt   time.Time
 	UserID      string
 	ChallengeID string
+	// Grant holds the IDs AGS assigned to the grant; zero when the reward
+	// client does not report them.
+	Grant agsClient.GrantResult
+	// RewardCap tells which reward a goal with a reward cap granted; nil for
+	// other goals. Reward is the reward granted, zero when it granted none.
+	RewardCap *RewardCapOutcome
+	// Repeat is the claim history of a repeatable goal after the claim reset
+	// it; nil for other goals, or when the reset failed and is left for later.
+	Repeat *GoalRepeat
 }
 
-// ClaimGoalReward handles the reward claim flow with transaction and row-level locking.
+// Granted reports whether the claim granted a reward; only a claim past the
+// reward cap of a goal without a fallback reward grants none.
+func (r *ClaimResult) Granted() bool {
+	return r.RewardCap == nil || r.RewardCap.Granted != RewardCapGrantedNone
+}
+
+const (
+	// claimTimeout bounds a whole claim, including the AGS grant retries.
+	claimTimeout = 10 * time.Second
+
+	// claimWaitInterval is how often a claim polls while another claim of the
+	// same goal is granting its reward.
+	claimWaitInterval = 50 * time.Millisecond
+)
+
+// errClaimInFlight means another claim of the same goal has reserved it and is
+// granting the reward; the caller waits for it to finish and validates again.
+var errClaimInFlight = errors.New("claim already in flight")
+
+// ClaimGoalReward handles the reward claim flow.
 // This is the main entry point for the claim RPC handler.
 //
-// Flow (Decision Q3):
-// 1. Start transaction with 10s timeout
-// 2. Lock user progress row (SELECT ... FOR UPDATE)
-// 3. Validate goal is completed and not claimed
-// 4. Call AGS Platform Service (inside transaction with retry)
-// 5. Mark as claimed in database
-// 6. Commit transaction
+// The progress row lock is only held for the short transactions around the AGS
+// grant, never across it, so progress events for the user are not blocked
+// while AGS retries. The claim_outbox entry is the guard in between:
+//
+// 1. Transaction 1: lock the progress row (SELECT ... FOR UPDATE), validate the
+// goal is completed, not claimed, and its prerequisites (if any) are met,
+// reserve the outbox entry (state 'pending'), commit
+// 2. Grant the reward via AGS Platform Service with retry, outside any transaction
+// 3. Mark the outbox entry 'granted'
+// 4. Transaction 2: mark the goal claimed, commit
+// 5. Delete the outbox entry
+//
+// A claim that finds another claim's pending entry waits for it to finish, so
+// concurrent claims still see AlreadyExists once the first one has succeeded.
+// If the grant fails the entry is deleted and the goal stays claimable. Entries
+// left behind by a crash are resolved by ClaimRecovery.
+//
+// staleAfter is how long another claim's entry must be idle before this claim
+// resumes it instead of waiting (ClaimRecovery.StaleAfter; zero always waits).
+// A stale granted entry only needs the goal marked claimed: the claim succeeds
+// without granting again. A stale pending entry is released, as ClaimRecovery
+// would, and the claim grants the reward itself.
 //
 // Error Handling:
 // - Returns mapper.ErrGoalNotFound if goal doesn't exist in config
 // - Returns mapper.ErrGoalNotCompleted if goal not completed
-// - Returns mapper.ErrGoalAlreadyClaimed if already claimed
+// - Returns mapper.ErrGoalAlreadyClaimed if already claimed (or granted and not yet marked)
 // - Returns mapper.ErrPrerequisitesNotMet if prerequisites not met
+// - Returns mapper.ChallengeMismatchError if the progress row is stored under another challenge
+// - Returns mapper.ConfigInvalidError if the goal's target or reward quantity is not positive
 // - Returns mapper.ErrRewardGrantFailed if AGS call fails after retries
+// - Returns mapper.RewardGrantPermanentError if AGS rejects the grant in a way
+// retrying cannot fix (see PermanentGrantErrorClass)
 // - Returns mapper.ErrDatabaseError for database failures
+//
+// targets are the goal targets of the user's segment: a row in progress that
+// reaches the segment's target is marked completed in transaction 1 and claimed.
+//
+// precondition is what the player saw when claiming; a goal changed since then
+// fails with mapper.StaleClaimError (see ClaimPrecondition).
+//
+// The reward is granted in namespace unless ctx carries another grant namespace
+// (see WithGrantNamespace); progress is stored under namespace either way.
+//
+// A goal with a reward cap in ctx (see WithRewardCaps) takes a slot of the cap
+// in transaction 1, after reserving the outbox entry. Claims past the cap
+// succeed with the fallback reward, or without granting anything. A claim that
+// grants nothing of its own reward gives its slot back when it is released.
+//
+// A repeatable goal in ctx (see WithRepeatableGoals) is reset after the claim.
+// Claiming it during the cooldown of its last claim returns
+// mapper.GoalCoolingDownError.
+//
+// The client context in ctx (see WithClientContext) is stored on the claim's
+// outbox entry, from which the goal.claimed event picks it up.
+//
+// logger receives the claim's entries, with user_id, goal_id, challenge_id,
+// namespace and grant_namespace set.
 func ClaimGoalReward(
 	ctx context.Context,
 	userID string,
@@ -52,7 +128,12 @@ func ClaimGoalReward(
 	namespace string,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	outbox serviceRepo.ClaimOutboxRepository,
+	staleAfter time.Duration,
 	rewardClient client.RewardClient,
+	targets SegmentTargets,
+	precondition ClaimPrecondition,
+	logger logrus.FieldLogger,
 ) (*ClaimResult, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
@@ -78,195 +159,606 @@ func ClaimGoalReward(
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
+	if outbox == nil {
+		return nil, fmt.Errorf("claim outbox cannot be nil")
+	}
+
 	if rewardClient == nil {
 		return nil, fmt.Errorf("reward client cannot be nil")
 	}
 
-	// Get goal from cache
-	goal := goalCache.GetGoalByID(goalID)
-	if goal == nil {
-		return nil, &mapper.GoalNotFoundError{
-			GoalID:      goalID,
-			ChallengeID: challengeID,
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	if err := precondition.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Get goal from cache, verifying it belongs to the specified challenge
+	goal, err := GetGoal(goalCache, challengeID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A zero target or quantity would mark the goal claimed and grant nothing
+	if err := checkGoalClaimable(goal, targets.Target(goal), logger); err != nil {
+		return nil, err
+	}
+
+	// 10s budget for the whole claim (Decision Q3, FQ1).
+	//
+	// Every step up to and including the AGS grant runs on reqCtx, which also
+	// honours the request deadline, so a deadline that fires before the grant
+	// completes releases the reservation with nothing granted. Once AGS has
+	// granted the reward, the remaining writes run on txCtx, which is detached
+	// from the caller's cancellation, so a request deadline cannot leave a
+	// granted-but-unclaimed goal behind.
+	txCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), claimTimeout)
+	defer cancel()
+	reqCtx, reqCancel := context.WithTimeout(ctx, claimTimeout)
+	defer reqCancel()
+
+	grantNamespace := grantNamespaceFrom(ctx, namespace)
+	claim := &claimAttempt{
+		userID:         userID,
+		goalID:         goalID,
+		challengeID:    challengeID,
+		namespace:      namespace,
+		grantNamespace: grantNamespace,
+		goal:           goal,
+		goalCache:      goalCache,
+		targets:        targets,
+		precondition:   precondition,
+		repo:           repo,
+		outbox:         outbox,
+		staleAfter:     staleAfter,
+		clientContext:  clientContextFrom(ctx),
+		metrics:        challengeMetricsFrom(ctx),
+		reward:         goal.Reward,
+		grants:         true,
+		log: logger.WithFields(logrus.Fields{
+			"user_id":         userID,
+			"goal_id":         goalID,
+			"challenge_id":    challengeID,
+			"namespace":       namespace,
+			"grant_namespace": grantNamespace,
+		}),
+	}
+	claim.rewardCap, claim.rewardCapRepo, claim.capped = rewardCapFrom(ctx, goalID)
+	claim.repeatableGoal, claim.repeatableRepo, claim.repeatable = repeatableGoalFrom(ctx, goalID)
+
+	if claim.repeatable {
+		if err := claim.checkCooldown(reqCtx); err != nil {
+			return nil, requestContextErrOr(ctx, err)
 		}
 	}
 
-	// Verify goal belongs to the specified challenge
-	if goal.ChallengeID != challengeID {
-		return nil, &mapper.GoalNotFoundError{
-			GoalID:      goalID,
-			ChallengeID: challengeID,
+	for {
+		err := claim.reserve(ctx, reqCtx, txCtx)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errClaimInFlight) {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(claimWaitInterval):
+		case <-reqCtx.Done():
+			claim.log.Warn("Gave up waiting for a concurrent claim of the same goal")
+			return nil, reqCtx.Err()
 		}
 	}
 
-	// Start transaction with 10s timeout (Decision Q3, FQ1)
-	txCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+	// A crashed claim already granted the reward; only the claim is left to record
+	if claim.resumedGrant {
+		return claim.completeResumed(txCtx)
+	}
 
-	txRepo, err := repo.BeginTx(txCtx)
+	// Don't start a grant the caller has already given up on
+	if err := reqCtx.Err(); err != nil {
+		claim.log.WithError(err).Warn("Claim aborted before reward grant, releasing reservation")
+		claim.release(txCtx)
+		return nil, err
+	}
+
+	// Grant reward via AGS Platform Service with retry (Decision Q3, FQ1).
+	// The challenge/goal IDs are attached to the AGS request headers for tracing.
+	// A claim past a reward cap without a fallback reward grants nothing.
+	var grant agsClient.GrantResult
+	if claim.grants {
+		grantCtx := agsClient.WithGrantMetadata(reqCtx, challengeID, goalID)
+		grant, err = grantRewardWithRetry(grantCtx, grantNamespace, userID, claim.reward, rewardClient, claim.idempotencyKey, claim.log)
+	}
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"error":        err,
-		}).Error("Failed to start transaction")
+		claim.log.WithFields(logrus.Fields{
+			"reward_type": claim.reward.Type,
+			"reward_id":   claim.reward.RewardID,
+		}).WithError(err).Error("Failed to grant reward after retries")
+		claim.release(txCtx)
+		if class := PermanentGrantErrorClass(err); class != "" {
+			return nil, requestContextErrOr(ctx, &mapper.RewardGrantPermanentError{
+				GoalID:         goalID,
+				ChallengeID:    challengeID,
+				Reward:         claim.reward,
+				GrantNamespace: grantNamespace,
+				ErrorClass:     class,
+				Err:            err,
+			})
+		}
+		return nil, requestContextErrOr(ctx, &mapper.RewardGrantError{
+			GoalID: goalID,
+			Err:    err,
+		})
+	}
+
+	// From here on the reward is granted. A failure leaves the outbox entry for
+	// ClaimRecovery, and re-claims get AlreadyExists until it is resolved.
+	if err := outbox.MarkGranted(txCtx, userID, goalID); err != nil {
+		claim.log.WithError(err).Error("Failed to mark claim as granted in outbox")
+	}
+
+	if err := claim.markClaimed(txCtx); err != nil {
+		claim.log.WithError(err).
+			Error("Reward granted but failed to mark goal as claimed, left for claim recovery")
 		return nil, mapper.ErrDatabaseError
 	}
 
-	// Ensure transaction is rolled back on error
+	if err := outbox.Delete(txCtx, userID, goalID); err != nil {
+		claim.log.WithError(err).Warn("Failed to delete claim outbox entry, left for claim recovery")
+	}
+
+	repeat := claim.resetRepeatable(txCtx)
+	claim.metrics.RewardClaimed(challengeID, claim.reward, claim.grants)
+
+	claim.log.WithFields(logrus.Fields{
+		"reward_type": claim.reward.Type,
+		"reward_id":   claim.reward.RewardID,
+	}).Info("Successfully claimed goal reward")
+
+	// Return result
+	return &ClaimResult{
+		GoalID:      goalID,
+		Status:      string(domain.GoalStatusClaimed),
+		Reward:      claim.reward,
+		ClaimedAt:   time.Now().UTC(),
+		UserID:      userID,
+		ChallengeID: challengeID,
+		Grant:       grant,
+		RewardCap:   claim.rewardCapOutcome,
+		Repeat:      repeat,
+	}, nil
+}
+
+// claimAttempt holds the inputs shared by the steps of one claim.
+type claimAttempt struct {
+	userID      string
+	goalID      string
+	challengeID string
+	namespace   string
+	// grantNamespace is the namespace the reward is granted in, recorded on the
+	// claim's outbox entry.
+	grantNamespace string
+	goal           *domain.Goal
+	goalCache      cache.GoalCache
+	targets        SegmentTargets
+	precondition   ClaimPrecondition
+	repo           repository.GoalRepository
+	outbox         serviceRepo.ClaimOutboxRepository
+	staleAfter     time.Duration
+	// clientContext is recorded on the claim's outbox entry (see WithClientContext)
+	clientContext ClientContext
+	metrics       *ChallengeMetrics
+	// resumedGrant is set by reserve when it took over a stale granted entry
+	resumedGrant bool
+	// idempotencyKey is sent with the grant (see grantIdempotencyKey)
+	idempotencyKey string
+	// reward is the reward the claim grants, if grants is set: the goal's,
+	// unless the goal's reward cap is reached (see takeRewardCapSlot).
+	reward domain.Reward
+	grants bool
+	// capped is set when the goal has a reward cap; rewardCapSlot when the
+	// claim's outbox entry holds one of its slots.
+	capped           bool
+	rewardCap        RewardCap
+	rewardCapRepo    serviceRepo.RewardCapRepository
+	rewardCapSlot    bool
+	rewardCapOutcome *RewardCapOutcome
+	// repeatable is set when the goal is repeatable
+	repeatable     bool
+	repeatableGoal RepeatableGoal
+	repeatableRepo serviceRepo.RepeatableGoalRepository
+	// log carries the claim's user_id, goal_id, challenge_id and namespace
+	log logrus.FieldLogger
+}
+
+// reserve runs transaction 1: it validates the goal under the row lock and
+// reserves the outbox entry. It returns errClaimInFlight when another claim
+// holds a pending reservation, and takes over the reservation when it is
+// stale (see resumeStale).
+func (c *claimAttempt) reserve(ctx, reqCtx, txCtx context.Context) error {
+	txRepo, err := c.repo.BeginTx(txCtx)
+	if err != nil {
+		c.log.WithError(err).Error("Failed to start transaction")
+		return mapper.ErrDatabaseError
+	}
+
+	// Roll back on every path that does not reach Commit (including early
+	// validation returns and request deadline/cancellation)
+	finished := false
 	defer func() {
-		if err != nil {
+		if !finished {
 			if rbErr := txRepo.Rollback(); rbErr != nil {
-				logrus.WithFields(logrus.Fields{
-					"user_id":      userID,
-					"goal_id":      goalID,
-					"challenge_id": challengeID,
-					"error":        rbErr,
-				}).Error("Failed to rollback transaction")
+				c.log.WithError(rbErr).Error("Failed to rollback transaction")
 			}
 		}
 	}()
 
 	// Lock user progress row (SELECT ... FOR UPDATE)
-	progress, err := txRepo.GetProgressForUpdate(txCtx, userID, goalID)
+	progress, err := txRepo.GetProgressForUpdate(reqCtx, c.userID, c.goalID)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"error":        err,
-		}).Error("Failed to lock progress row")
-		return nil, mapper.ErrDatabaseError
+		c.log.WithError(err).Error("Failed to lock progress row")
+		return requestContextErrOr(ctx, mapper.ErrDatabaseError)
+	}
+
+	// The player acted on what they saw; a goal changed since then fails clearly
+	if err := c.precondition.check(c.goalID, c.targets.Evaluate(progress, c.goal)); err != nil {
+		c.log.WithError(err).Info("Claim rejected: goal changed since it was listed")
+		return err
 	}
 
 	// Validate progress exists
-	if progress == nil {
-		return nil, &mapper.GoalNotCompletedError{
-			GoalID: goalID,
-			Status: string(domain.GoalStatusNotStarted),
-		}
+	if err := checkClaimStarted(c.goalID, progress); err != nil {
+		return err
 	}
 
-	// M3 Phase 6: Validate goal is active
-	if !progress.IsActive {
-		return nil, &mapper.GoalNotActiveError{
-			GoalID:      goalID,
-			ChallengeID: challengeID,
-		}
+	// The request and config challenge already agree (checked in
+	// ClaimGoalReward); the stored row must too
+	if err := checkProgressChallenge(c.goalID, c.challengeID, progress); err != nil {
+		c.log.
+			WithField("stored_challenge_id", progress.ChallengeID).
+			Warn("Claim rejected: progress row stored under another challenge")
+		return err
+	}
+
+	if err := checkClaimActive(c.goalID, c.challengeID, progress); err != nil {
+		return err
+	}
+
+	if err := checkClaimRotation(c.goal, c.challengeID, progress, time.Now().UTC()); err != nil {
+		c.log.Warn("Claim rejected: goal has rotated")
+		return err
 	}
 
-	// M5 Phase 6: Check if goal has rotated since completion
-	if rotation.HasRotationOccurred(progress, goal, time.Now().UTC()) {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-		}).Warn("Claim rejected: goal has rotated")
-		return nil, &mapper.GoalRotatedError{
-			GoalID:      goalID,
-			ChallengeID: challengeID,
+	// Rows are stored against the configured target. One the user's segment has
+	// completed at a lower target is marked completed, which MarkAsClaimed requires.
+	if evaluated := c.targets.Evaluate(progress, c.goal); evaluated != progress {
+		if err := progressstate.Transition(progress.Status, evaluated.Status, progressstate.Flags{}); err != nil {
+			c.log.WithError(err).Error("Refused to mark goal completed for segment target")
+			return err
 		}
+		if err := txRepo.UpsertProgress(reqCtx, evaluated); err != nil {
+			c.log.WithError(err).Error("Failed to mark goal completed for segment target")
+			return requestContextErrOr(ctx, mapper.ErrDatabaseError)
+		}
+		progress = evaluated
 	}
 
-	// Validate goal is completed
-	if !progress.CanClaim() {
-		if progress.IsClaimed() {
-			return nil, &mapper.GoalAlreadyClaimedError{
-				GoalID:    goalID,
-				ClaimedAt: progress.ClaimedAt.UTC().Format(time.RFC3339),
-			}
+	if err := checkClaimStatus(c.goalID, progress); err != nil {
+		return err
+	}
+	c.idempotencyKey = grantIdempotencyKey(c.outboxEntry(), progress.CompletedAt)
+
+	// Check prerequisites. Only the prerequisite rows are loaded, and goals
+	// without prerequisites skip the query entirely, so the row lock is not held
+	// while scanning the user's full progress.
+	if len(c.goal.Prerequisites) > 0 {
+		prereqProgress, err := txRepo.GetGoalsByIDs(reqCtx, c.userID, c.goal.Prerequisites)
+		if err != nil {
+			c.log.WithError(err).Error("Failed to load prerequisite progress")
+			return requestContextErrOr(ctx, mapper.ErrDatabaseError)
 		}
 
-		return nil, &mapper.GoalNotCompletedError{
-			GoalID: goalID,
-			Status: string(progress.Status),
+		prereqChecker := NewPrerequisiteChecker(c.targets.EvaluateAll(buildProgressMap(prereqProgress), c.goalCache))
+		if err := checkClaimPrerequisites(c.goal, prereqChecker); err != nil {
+			return err
 		}
 	}
 
-	// Check prerequisites (Decision Q7)
-	// Load all user progress for prerequisite checking
-	// M3 Phase 4: Get all goals (activeOnly = false) for prerequisite checking
-	allProgress, err := txRepo.GetUserProgress(txCtx, userID, false)
+	// Don't reserve a grant the caller has already given up on
+	if err := reqCtx.Err(); err != nil {
+		c.log.WithError(err).Warn("Claim aborted before reward grant, rolling back")
+		return err
+	}
+
+	reserved, err := c.outbox.Reserve(reqCtx, c.outboxEntry())
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"error":        err,
-		}).Error("Failed to load user progress for prerequisite check")
-		return nil, mapper.ErrDatabaseError
+		c.log.WithError(err).Error("Failed to reserve claim")
+		return requestContextErrOr(ctx, mapper.ErrDatabaseError)
+	}
+
+	if !reserved {
+		entry, err := c.outbox.Get(reqCtx, c.userID, c.goalID)
+		if err != nil {
+			c.log.WithError(err).Error("Failed to load claim reservation")
+			return requestContextErrOr(ctx, mapper.ErrDatabaseError)
+		}
+
+		if entry == nil || !c.isStale(entry) {
+			// Granted but not yet marked claimed: the reward is already out
+			if entry != nil && entry.State == serviceRepo.ClaimOutboxGranted {
+				return &mapper.GoalAlreadyClaimedError{
+					GoalID:    c.goalID,
+					ClaimedAt: mapper.FormatTimestamp(entry.UpdatedAt),
+				}
+			}
+
+			return errClaimInFlight
+		}
+
+		if err := c.resumeStale(reqCtx, entry); err != nil {
+			return requestContextErrOr(ctx, err)
+		}
 	}
 
-	// Build progress map and check prerequisites
-	progressMap := buildProgressMap(allProgress)
-	prereqChecker := NewPrerequisiteChecker(progressMap)
+	// The slot is taken while the row lock is held, so the goal cannot be
+	// claimed concurrently by the same user. It commits on its own, so a failed
+	// commit below gives it back with the reservation (see RewardCapRepository)
+	if c.capped && !c.resumedGrant {
+		if err := c.takeRewardCapSlot(reqCtx); err != nil {
+			c.log.WithError(err).Error("Failed to take reward cap slot")
+			c.release(txCtx)
+			return requestContextErrOr(ctx, mapper.ErrDatabaseError)
+		}
+	}
 
-	if !prereqChecker.CheckAllPrerequisitesMet(goal) {
-		missingPrereqs := prereqChecker.GetMissingPrerequisites(goal)
-		return nil, &mapper.PrerequisitesNotMetError{
-			GoalID:         goalID,
-			MissingGoalIDs: missingPrereqs,
+	// Commit releases the row lock before the grant. A failed commit already
+	// ends the transaction, so the deferred rollback is skipped either way.
+	finished = true
+	if err := txRepo.Commit(); err != nil {
+		c.log.WithError(err).Error("Failed to commit transaction")
+		// A resumed granted entry records a grant; only recovery may resolve it
+		if !c.resumedGrant {
+			c.release(txCtx)
 		}
+		return mapper.ErrDatabaseError
 	}
 
-	// Grant reward via AGS Platform Service with retry (Decision Q3, FQ1)
-	if err := grantRewardWithRetry(txCtx, namespace, userID, goal.Reward, rewardClient); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"reward_type":  goal.Reward.Type,
-			"reward_id":    goal.Reward.RewardID,
-			"error":        err,
-		}).Error("Failed to grant reward after retries")
-		return nil, &mapper.RewardGrantError{
-			GoalID: goalID,
-			Err:    err,
+	return nil
+}
+
+func (c *claimAttempt) outboxEntry() *serviceRepo.ClaimOutboxEntry {
+	return &serviceRepo.ClaimOutboxEntry{
+		UserID:        c.userID,
+		GoalID:        c.goalID,
+		ChallengeID:   c.challengeID,
+		Namespace:     c.grantNamespace,
+		ClientContext: c.clientContext,
+	}
+}
+
+// grantIdempotencyKey derives the AGS idempotency key of a claim's grant from
+// its outbox entry's key (grant namespace, user_id, goal_id) and the completion
+// it claims. A claim that resumes a stale pending entry, or claims again after
+// ClaimRecovery released it, sends the key of the crashed claim, so AGS does
+// not grant twice; a repeatable goal completed again gets a new key.
+func grantIdempotencyKey(entry *serviceRepo.ClaimOutboxEntry, completedAt *time.Time) string {
+	var completed int64
+	if completedAt != nil {
+		completed = completedAt.UnixMicro()
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", entry.Namespace, entry.UserID, entry.GoalID, completed)))
+	return hex.EncodeToString(sum[:])
+}
+
+// isStale reports whether another claim's entry has been idle for staleAfter,
+// i.e. its claim crashed.
+func (c *claimAttempt) isStale(entry *serviceRepo.ClaimOutboxEntry) bool {
+	return c.staleAfter > 0 && entry.UpdatedAt.Before(time.Now().UTC().Add(-c.staleAfter))
+}
+
+// resumeStale takes over the stale entry of a crashed claim, under the progress
+// row lock of transaction 1, which still shows the goal completed:
+//   - granted: the crashed claim granted the reward but did not mark the goal
+//     claimed. The entry is kept and resumedGrant set, so this claim marks the
+//     goal claimed without granting again.
+//   - pending: whether the crashed claim granted is unknown. The entry is
+//     released and reserved again for this claim, which grants the reward.
+//
+// It returns errClaimInFlight when the entry changed in the meantime.
+func (c *claimAttempt) resumeStale(ctx context.Context, entry *serviceRepo.ClaimOutboxEntry) error {
+	log := c.log.WithFields(logrus.Fields{
+		"outbox_state":      entry.State,
+		"outbox_updated_at": entry.UpdatedAt,
+	})
+
+	if entry.State == serviceRepo.ClaimOutboxGranted {
+		c.resumedGrant = true
+		if c.capped {
+			// The crashed claim's count is unknown, so no slot is reported left
+			c.setRewardCapOutcome(entry.RewardCapSlot, c.rewardCap.Limit)
 		}
+		log.Warn("Resuming stale granted claim; marking the goal claimed without granting again")
+		return nil
 	}
 
-	// Mark as claimed in database
-	if err := txRepo.MarkAsClaimed(txCtx, userID, goalID); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"error":        err,
-		}).Error("Failed to mark goal as claimed")
-		return nil, mapper.ErrDatabaseError
+	deleted, err := c.outbox.DeleteIfPending(ctx, c.userID, c.goalID, time.Now().UTC().Add(-c.staleAfter))
+	if err != nil {
+		log.WithError(err).Error("Failed to release stale pending claim")
+		return mapper.ErrDatabaseError
+	}
+	if !deleted {
+		return errClaimInFlight
 	}
 
-	// Commit transaction
-	if err := txRepo.Commit(); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"goal_id":      goalID,
-			"challenge_id": challengeID,
-			"error":        err,
-		}).Error("Failed to commit transaction")
+	reserved, err := c.outbox.Reserve(ctx, c.outboxEntry())
+	if err != nil {
+		log.WithError(err).Error("Failed to reserve claim")
+		return mapper.ErrDatabaseError
+	}
+	if !reserved {
+		return errClaimInFlight
+	}
+
+	log.Warn("Resuming stale pending claim; the earlier reward grant outcome is unknown, reconcile with AGS")
+	return nil
+}
+
+// completeResumed finishes a claim that took over a stale granted entry: the
+// goal is marked claimed and the entry deleted. The reward was granted by the
+// crashed claim, so the result has no grant IDs.
+func (c *claimAttempt) completeResumed(txCtx context.Context) (*ClaimResult, error) {
+	if err := c.markClaimed(txCtx); err != nil {
+		// A concurrent claim or ClaimRecovery resumed it first
+		if isGoalNotCompleted(err) {
+			return nil, &mapper.GoalAlreadyClaimedError{GoalID: c.goalID}
+		}
+		c.log.WithError(err).Error("Failed to mark resumed claim's goal as claimed, left for claim recovery")
 		return nil, mapper.ErrDatabaseError
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"goal_id":      goalID,
-		"challenge_id": challengeID,
-		"reward_type":  goal.Reward.Type,
-		"reward_id":    goal.Reward.RewardID,
-	}).Info("Successfully claimed goal reward")
+	if err := c.outbox.Delete(txCtx, c.userID, c.goalID); err != nil {
+		c.log.WithError(err).Warn("Failed to delete claim outbox entry, left for claim recovery")
+	}
+
+	repeat := c.resetRepeatable(txCtx)
+	c.metrics.RewardClaimed(c.challengeID, c.reward, c.grants)
+
+	c.log.WithFields(logrus.Fields{
+		"reward_type": c.reward.Type,
+		"reward_id":   c.reward.RewardID,
+	}).Info("Successfully claimed goal reward granted by an earlier claim")
 
-	// Return result
 	return &ClaimResult{
-		GoalID:      goalID,
+		GoalID:      c.goalID,
 		Status:      string(domain.GoalStatusClaimed),
-		Reward:      goal.Reward,
+		Reward:      c.reward,
 		ClaimedAt:   time.Now().UTC(),
-		UserID:      userID,
-		ChallengeID: challengeID,
+		UserID:      c.userID,
+		ChallengeID: c.challengeID,
+		RewardCap:   c.rewardCapOutcome,
+		Repeat:      repeat,
 	}, nil
 }
 
+// takeRewardCapSlot takes a slot of the goal's reward cap for the claim's
+// outbox entry and picks the reward to grant: the goal's within the cap, the
+// fallback reward or none past it.
+func (c *claimAttempt) takeRewardCapSlot(ctx context.Context) error {
+	taken, claimed, err := c.rewardCapRepo.TakeSlot(ctx, c.userID, c.goalID, c.rewardCap.Limit)
+	if err != nil {
+		return err
+	}
+
+	c.rewardCapSlot = taken
+	c.setRewardCapOutcome(taken, claimed)
+	if !taken {
+		c.log.WithFields(logrus.Fields{
+			"reward_cap_limit": c.rewardCap.Limit,
+			"reward_granted":   c.rewardCapOutcome.Granted,
+		}).Info("Goal reward cap reached")
+	}
+	return nil
+}
+
+// setRewardCapOutcome records the outcome of the claim against the goal's
+// reward cap and the reward it grants.
+func (c *claimAttempt) setRewardCapOutcome(taken bool, claimed int) {
+	c.rewardCapOutcome = c.rewardCap.outcome(taken, claimed)
+	c.reward, c.grants = c.rewardCap.reward(c.goal.Reward, c.rewardCapOutcome)
+}
+
+// markClaimed runs transaction 2, marking the goal claimed after the grant.
+func (c *claimAttempt) markClaimed(txCtx context.Context) error {
+	txRepo, err := c.repo.BeginTx(txCtx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err := txRepo.MarkAsClaimed(txCtx, c.userID, c.goalID); err != nil {
+		if rbErr := txRepo.Rollback(); rbErr != nil {
+			c.log.WithError(rbErr).Error("Failed to rollback transaction")
+		}
+		return fmt.Errorf("failed to mark goal as claimed: %w", err)
+	}
+
+	if err := txRepo.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// checkCooldown returns mapper.GoalCoolingDownError when the repeatable goal
+// was last claimed less than its cooldown ago. A row still claimed because
+// resetting it failed, or because ClaimRecovery marked it claimed, is reset
+// first.
+func (c *claimAttempt) checkCooldown(ctx context.Context) error {
+	if _, err := resetClaimedRepeatable(ctx, c.repeatableRepo, c.repeatableGoal, c.userID, c.goalID); err != nil {
+		c.log.WithError(err).Error("Failed to reset claimed repeatable goal")
+		return mapper.ErrDatabaseError
+	}
+
+	states, err := c.repeatableRepo.GetRepeatStates(ctx, c.userID, []string{c.goalID})
+	if err != nil {
+		c.log.WithError(err).Error("Failed to load repeatable goal state")
+		return mapper.ErrDatabaseError
+	}
+
+	state, ok := states[c.goalID]
+	if !ok {
+		return nil
+	}
+	repeats := map[string]GoalRepeat{c.goalID: c.repeatableGoal.repeat(state)}
+	return coolingDownError(repeats, []string{c.goalID}, time.Now().UTC())
+}
+
+// resetRepeatable resets the claimed row of a repeatable goal and returns its
+// claim history; nil for other goals. A failed reset is logged and left for
+// the next claim, activation or selection of the goal to repair.
+func (c *claimAttempt) resetRepeatable(txCtx context.Context) *GoalRepeat {
+	if !c.repeatable {
+		return nil
+	}
+
+	repeat, err := resetClaimedRepeatable(txCtx, c.repeatableRepo, c.repeatableGoal, c.userID, c.goalID)
+	if err != nil {
+		c.log.WithError(err).Error("Failed to reset claimed repeatable goal, left for its next claim or activation")
+		return nil
+	}
+	return repeat
+}
+
+// release deletes the reservation of a claim that granted nothing, so the goal
+// can be claimed again. A reward cap slot held by the reservation is given back
+// first; if that fails, the reservation is kept for claim recovery, which
+// releases both.
+func (c *claimAttempt) release(txCtx context.Context) {
+	if c.rewardCapSlot {
+		if err := c.rewardCapRepo.ReleaseSlot(txCtx, c.userID, c.goalID); err != nil {
+			c.log.WithError(err).Error("Failed to release reward cap slot, left for claim recovery")
+			return
+		}
+		c.rewardCapSlot = false
+	}
+	if err := c.outbox.Delete(txCtx, c.userID, c.goalID); err != nil {
+		c.log.WithError(err).Error("Failed to release claim reservation, left for claim recovery")
+	}
+}
+
+// requestContextErrOr returns the request's context error when it was cancelled
+// or ran past its deadline, so callers see DeadlineExceeded/Canceled instead of a
+// generic failure; otherwise it returns fallback.
+func requestContextErrOr(ctx context.Context, fallback error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return fallback
+}
+
 // grantRewardWithRetry calls AGS Platform Service with exponential backoff retry.
 // Decision FQ1: 3 retries with 500ms base delay
 //
@@ -281,13 +773,20 @@ func ClaimGoalReward(
 // - Attempt 4: 2000ms delay
 //
 // Total delays: ~3.5s + AGS call times (4-8s) = 7.5-11.5s (fits in 10s timeout)
+//
+// The returned GrantResult is zero unless rewardClient reports grant IDs (see
+// agsClient.GrantResultRewardClient). Every attempt carries idempotencyKey, so a
+// retry of a grant AGS applied but did not acknowledge is not applied again.
+// Attempts are logged to log.
 func grantRewardWithRetry(
 	ctx context.Context,
 	namespace string,
 	userID string,
 	reward domain.Reward,
 	rewardClient client.RewardClient,
-) error {
+	idempotencyKey string,
+	log logrus.FieldLogger,
+) (agsClient.GrantResult, error) {
 	const (
 		maxRetries  = 3
 		baseDelay   = 500 * time.Millisecond
@@ -295,6 +794,8 @@ func grantRewardWithRetry(
 		backoffRate = 2.0
 	)
 
+	ctx = agsClient.WithIdempotencyKey(ctx, idempotencyKey)
+
 	var lastErr error
 	delay := baseDelay
 
@@ -305,7 +806,7 @@ func grantRewardWithRetry(
 			case <-time.After(delay):
 				// Continue with retry
 			case <-ctx.Done():
-				return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+				return agsClient.GrantResult{}, fmt.Errorf("context cancelled during retry: %w", ctx.Err())
 			}
 
 			// Exponential backoff with max cap
@@ -316,55 +817,48 @@ func grantRewardWithRetry(
 		}
 
 		// Attempt to grant reward
-		err := rewardClient.GrantReward(ctx, namespace, userID, reward)
+		grant, err := agsClient.GrantRewardWithResult(ctx, rewardClient, namespace, userID, reward)
 		if err == nil {
 			// Success
 			if attempt > 0 {
-				logrus.WithFields(logrus.Fields{
-					"user_id":     userID,
+				log.WithFields(logrus.Fields{
 					"reward_type": reward.Type,
 					"reward_id":   reward.RewardID,
 					"attempt":     attempt + 1,
 				}).Info("Reward granted successfully after retry")
 			}
-			return nil
+			return grant, nil
 		}
 
 		lastErr = err
 
 		// Check if error is retryable (Decision FQ1 enhancement)
 		if !client.IsRetryableError(err) {
-			logrus.WithFields(logrus.Fields{
-				"user_id":     userID,
+			log.WithFields(logrus.Fields{
 				"reward_type": reward.Type,
 				"reward_id":   reward.RewardID,
 				"attempt":     attempt + 1,
-				"error":       err,
-			}).Error("Reward grant failed with non-retryable error")
-			return fmt.Errorf("reward grant failed (non-retryable): %w", err)
+			}).WithError(err).Error("Reward grant failed with non-retryable error")
+			return agsClient.GrantResult{}, fmt.Errorf("reward grant failed (non-retryable): %w", err)
 		}
 
 		// Log retry attempt for retryable errors
 		if attempt < maxRetries {
-			logrus.WithFields(logrus.Fields{
-				"user_id":     userID,
+			log.WithFields(logrus.Fields{
 				"reward_type": reward.Type,
 				"reward_id":   reward.RewardID,
 				"attempt":     attempt + 1,
 				"next_delay":  delay,
-				"error":       err,
-			}).Warn("Reward grant failed (retryable), retrying")
+			}).WithError(err).Warn("Reward grant failed (retryable), retrying")
 		}
 	}
 
 	// All retries exhausted
-	logrus.WithFields(logrus.Fields{
-		"user_id":     userID,
+	log.WithFields(logrus.Fields{
 		"reward_type": reward.Type,
 		"reward_id":   reward.RewardID,
 		"attempts":    maxRetries + 1,
-		"error":       lastErr,
-	}).Error("Reward grant failed after all retries")
+	}).WithError(lastErr).Error("Reward grant failed after all retries")
 
-	return fmt.Errorf("reward grant failed after %d retries: %w", maxRetries, lastErr)
+	return agsClient.GrantResult{}, fmt.Errorf("reward grant failed after %d retries: %w", maxRetries, lastErr)
 }
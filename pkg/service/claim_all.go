@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultClaimAllLimit caps the goals ClaimAllCompleted claims per call. Claims
+// run one after another, each with its own AGS grant, so the cap bounds how long
+// a call takes.
+const DefaultClaimAllLimit = 50
+
+// GoalClaimer claims one goal through the single-goal claim flow, including any
+// checks the caller adds around it (e.g. the claim cap).
+type GoalClaimer func(ctx context.Context, goal *domain.Goal) (*ClaimResult, error)
+
+// ClaimAllOutcome is the result of claiming one goal.
+type ClaimAllOutcome struct {
+	GoalID      string
+	ChallengeID string
+	// Result is set when the goal was claimed.
+	Result *ClaimResult
+	// Err is set when the claim failed; the goal stays claimable unless it was
+	// claimed concurrently.
+	Err error
+}
+
+// RewardTotal is the sum of the claimed rewards of one type and ID.
+type RewardTotal struct {
+	Type     string
+	RewardID string
+	Quantity int
+	// Goals is the number of claimed goals that granted this reward.
+	Goals int
+}
+
+// ClaimAllResult is the outcome of ClaimAllCompleted.
+type ClaimAllResult struct {
+	// Outcomes has one entry per goal claimed or attempted, in config order.
+	Outcomes []ClaimAllOutcome
+	// Rewards sums the granted rewards by type and ID, in the order first granted.
+	Rewards []RewardTotal
+	Claimed int
+	Failed  int
+	// HasMore is true when claimable goals were left for another call: there
+	// were more than the limit, or the claim cap or the request deadline stopped
+	// the batch.
+	HasMore bool
+}
+
+// ClaimAllCompleted claims every goal of the user, across all challenges, that
+// has a reward to claim: completed, active, not rotated, with its goal
+// prerequisites met and its challenge unlocked and rolled out to the user. The candidates come from one
+// progress query and the config; each is then claimed in its own claim flow by
+// claim, so a failed goal does not undo the others. Goals of challenges
+// unlocked by the claims made here are claimed too. Completion is judged
+// against targets, the goal targets of the user's segment.
+//
+// At most limit goals are attempted per call (DefaultClaimAllLimit when 0). The
+// batch stops early when the claim cap is reached or ctx is done.
+func ClaimAllCompleted(
+	ctx context.Context,
+	userID string,
+	limit int,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	challengePrereqs ChallengePrerequisites,
+	rollouts ChallengeRollouts,
+	targets SegmentTargets,
+	claim GoalClaimer,
+	logger logrus.FieldLogger,
+) (*ClaimAllResult, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if repo == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if claim == nil {
+		return nil, fmt.Errorf("claimer cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	if limit <= 0 {
+		limit = DefaultClaimAllLimit
+	}
+
+	// All rows, not only active ones: prerequisites may be completed goals the
+	// user has since deactivated
+	userProgress, err := repo.GetUserProgress(ctx, userID, false)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"error":   err,
+		}).Error("Failed to get user progress for claim all")
+		return nil, requestContextErrOr(ctx, mapper.ErrDatabaseError)
+	}
+
+	progressMap := targets.EvaluateAll(buildProgressMap(userProgress), goalCache)
+	attempted := make(map[string]bool)
+	result := &ClaimAllResult{}
+
+	// Claims can complete a challenge and so unlock the challenges that require
+	// it; those are picked up by the next round
+claimRounds:
+	for {
+		var candidates []*domain.Goal
+		for _, goal := range ClaimableGoals(goalCache, challengePrereqs, progressMap, time.Now().UTC()) {
+			if !attempted[goal.ID] && rollouts.IsAvailable(userID, goal.ChallengeID) {
+				candidates = append(candidates, goal)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		for _, goal := range candidates {
+			if len(result.Outcomes) >= limit || ctx.Err() != nil {
+				result.HasMore = true
+				break claimRounds
+			}
+
+			attempted[goal.ID] = true
+			claimed, err := claim(ctx, goal)
+			result.add(ClaimAllOutcome{
+				GoalID:      goal.ID,
+				ChallengeID: goal.ChallengeID,
+				Result:      claimed,
+				Err:         err,
+			})
+
+			if err == nil {
+				progressMap[goal.ID] = claimedProgress(progressMap[goal.ID])
+				continue
+			}
+
+			// Every remaining claim would fail the same way; the failed goal is still claimable
+			var capErr *mapper.ClaimCapExceededError
+			if errors.As(err, &capErr) || ctx.Err() != nil {
+				result.HasMore = true
+				break claimRounds
+			}
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"user_id":  userID,
+		"claimed":  result.Claimed,
+		"failed":   result.Failed,
+		"has_more": result.HasMore,
+	}).Info("Claimed all completed goals")
+
+	return result, nil
+}
+
+// ClaimableGoals returns the goals in goalCache, in config order, whose rewards
+// the user can claim at now according to progressMap. progressMap must hold all
+// of the user's rows, so prerequisite goals and challenges are judged correctly.
+//
+// Rows stored under another challenge than their goal are left out; they cannot
+// be claimed until an admin fixes them.
+func ClaimableGoals(
+	goalCache cache.GoalCache,
+	challengePrereqs ChallengePrerequisites,
+	progressMap map[string]*domain.UserGoalProgress,
+	now time.Time,
+) []*domain.Goal {
+	prereqChecker := NewPrerequisiteChecker(progressMap)
+
+	var claimable []*domain.Goal
+	for _, challenge := range goalCache.GetAllChallenges() {
+		var unlockChecked, locked bool
+		for _, goal := range challenge.Goals {
+			progress := progressMap[goal.ID]
+			if progress == nil || !progress.IsActive || !progress.CanClaim() || progress.ChallengeID != goal.ChallengeID {
+				continue
+			}
+			if rotation.HasRotationOccurred(progress, goal, now) || !prereqChecker.CheckAllPrerequisitesMet(goal) {
+				continue
+			}
+
+			if !unlockChecked {
+				locked = len(challengePrereqs.LockedBy(challenge.ID, goalCache, progressMap)) > 0
+				unlockChecked = true
+			}
+			if locked {
+				break
+			}
+
+			claimable = append(claimable, goal)
+		}
+	}
+
+	return claimable
+}
+
+// claimedProgress returns a copy of progress marked claimed.
+func claimedProgress(progress *domain.UserGoalProgress) *domain.UserGoalProgress {
+	claimed := *progress
+	claimed.Status = domain.GoalStatusClaimed
+	return &claimed
+}
+
+// add records one claim outcome and adds a granted reward to the totals.
+func (r *ClaimAllResult) add(outcome ClaimAllOutcome) {
+	r.Outcomes = append(r.Outcomes, outcome)
+	if outcome.Err != nil || outcome.Result == nil {
+		r.Failed++
+		return
+	}
+
+	r.Claimed++
+	if !outcome.Result.Granted() {
+		return
+	}
+	reward := outcome.Result.Reward
+	for i := range r.Rewards {
+		if r.Rewards[i].Type == reward.Type && r.Rewards[i].RewardID == reward.RewardID {
+			r.Rewards[i].Quantity += reward.Quantity
+			r.Rewards[i].Goals++
+			return
+		}
+	}
+	r.Rewards = append(r.Rewards, RewardTotal{
+		Type:     reward.Type,
+		RewardID: reward.RewardID,
+		Quantity: reward.Quantity,
+		Goals:    1,
+	})
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newClaimAllGoalCache builds two challenges: daily (d1, d2 requiring d1, d3)
+// and weekly (w1, w2), where weekly is locked behind daily.
+func newClaimAllGoalCache() commonCache.GoalCache {
+	d1 := createClaimableGoal("d1", "daily")
+	d2 := createClaimableGoal("d2", "daily")
+	d2.Prerequisites = []string{"d1"}
+	d3 := createClaimableGoal("d3", "daily")
+	d3.Reward = domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 100}
+	w1 := createClaimableGoal("w1", "weekly")
+	w2 := createClaimableGoal("w2", "weekly")
+	w2.Reward = domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 50}
+
+	return commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "daily", Name: "Daily", Goals: []*domain.Goal{d1, d2, d3}},
+		{ID: "weekly", Name: "Weekly", Goals: []*domain.Goal{w1, w2}},
+	}}, "", slog.Default())
+}
+
+// fakeClaimer claims every goal unless it has an error in errs, recording the order.
+type fakeClaimer struct {
+	claimed []string
+	errs    map[string]error
+}
+
+func (f *fakeClaimer) claim(_ context.Context, goal *domain.Goal) (*ClaimResult, error) {
+	f.claimed = append(f.claimed, goal.ID)
+	if err := f.errs[goal.ID]; err != nil {
+		return nil, err
+	}
+	return &ClaimResult{GoalID: goal.ID, ChallengeID: goal.ChallengeID, Reward: goal.Reward, Status: string(domain.GoalStatusClaimed)}, nil
+}
+
+func TestClaimableGoals(t *testing.T) {
+	goalCache := newClaimAllGoalCache()
+	inactive := createCompletedProgress("user", "d3", "daily")
+	inactive.IsActive = false
+	progressMap := buildProgressMap([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d2", "daily"),
+		inactive,
+		createCompletedProgress("user", "w1", "weekly"),
+	})
+
+	var ids []string
+	for _, goal := range ClaimableGoals(goalCache, ChallengePrerequisites{"weekly": {"daily"}}, progressMap, time.Now().UTC()) {
+		ids = append(ids, goal.ID)
+	}
+
+	// d2's prerequisite d1 is completed; d3 is inactive; weekly is locked until daily is completed
+	assert.Equal(t, []string{"d1", "d2"}, ids)
+}
+
+func TestClaimableGoals_SkipsUnmetPrerequisitesAndMismatchedRows(t *testing.T) {
+	goalCache := newClaimAllGoalCache()
+	d1 := createCompletedProgress("user", "d1", "daily")
+	d1.Status = domain.GoalStatusInProgress
+	d1.CompletedAt = nil
+	progressMap := buildProgressMap([]*domain.UserGoalProgress{
+		d1,
+		createCompletedProgress("user", "d2", "daily"),
+		createCompletedProgress("user", "d3", "old-daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+	})
+
+	goals := ClaimableGoals(goalCache, nil, progressMap, time.Now().UTC())
+
+	require.Len(t, goals, 1)
+	assert.Equal(t, "w1", goals[0].ID)
+}
+
+func TestClaimAllCompleted_ClaimsAcrossChallengesAndSumsRewards(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+		createCompletedProgress("user", "w2", "weekly"),
+	}, nil)
+	claimer := &fakeClaimer{}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3", "w1", "w2"}, claimer.claimed)
+	assert.Equal(t, 4, result.Claimed)
+	assert.Zero(t, result.Failed)
+	assert.False(t, result.HasMore)
+	assert.Equal(t, []RewardTotal{
+		{Type: string(domain.RewardTypeItem), RewardID: "sword", Quantity: 2, Goals: 2},
+		{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 150, Goals: 2},
+	}, result.Rewards)
+}
+
+func TestClaimAllCompleted_FailedGoalDoesNotStopOthers(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+	}, nil)
+	claimer := &fakeClaimer{errs: map[string]error{"d1": &mapper.RewardGrantError{GoalID: "d1", Err: errors.New("AGS down")}}}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	require.Len(t, result.Outcomes, 2)
+	assert.Error(t, result.Outcomes[0].Err)
+	assert.Equal(t, "d3", result.Outcomes[1].Result.GoalID)
+	assert.Equal(t, 1, result.Claimed)
+	assert.Equal(t, 1, result.Failed)
+	assert.False(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_RewardCapGrantedNone(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+	}, nil)
+	claim := func(ctx context.Context, goal *domain.Goal) (*ClaimResult, error) {
+		result := &ClaimResult{GoalID: goal.ID, ChallengeID: goal.ChallengeID, Reward: goal.Reward, Status: string(domain.GoalStatusClaimed)}
+		if goal.ID == "d1" {
+			result.Reward = domain.Reward{}
+			result.RewardCap = &RewardCapOutcome{Granted: RewardCapGrantedNone, Limit: 10}
+		}
+		return result, nil
+	}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claim, logrus.StandardLogger())
+
+	// Claimed past its reward cap, d1 counts as claimed but adds no reward
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Claimed)
+	assert.Equal(t, []RewardTotal{
+		{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 100, Goals: 1},
+	}, result.Rewards)
+}
+
+func TestClaimAllCompleted_Limit(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+	}, nil)
+	claimer := &fakeClaimer{}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 2, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3"}, claimer.claimed)
+	assert.True(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_StopsAtClaimCap(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+	}, nil)
+	claimer := &fakeClaimer{errs: map[string]error{"d3": &mapper.ClaimCapExceededError{UserID: "user", Limit: 1}}}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3"}, claimer.claimed, "w1 is not attempted")
+	assert.Equal(t, 1, result.Claimed)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_StopsWhenContextDone(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+	}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	claimer := &fakeClaimer{}
+	claim := func(ctx context.Context, goal *domain.Goal) (*ClaimResult, error) {
+		defer cancel()
+		return claimer.claim(ctx, goal)
+	}
+
+	result, err := ClaimAllCompleted(ctx, "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1"}, claimer.claimed)
+	assert.True(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_NothingToClaim(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return(nil, nil)
+	claimer := &fakeClaimer{}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Outcomes)
+	assert.Empty(t, claimer.claimed)
+	assert.False(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_ProgressQueryFails(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return(nil, errors.New("connection refused"))
+
+	_, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, SegmentTargets{}, (&fakeClaimer{}).claim, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+}
+
+func TestClaimAllCompleted_ClaimsChallengesUnlockedByTheBatch(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "d2", "daily"),
+		createCompletedProgress("user", "d3", "daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+	}, nil)
+	claimer := &fakeClaimer{}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo,
+		ChallengePrerequisites{"weekly": {"daily"}}, nil, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d2", "d3", "w1"}, claimer.claimed)
+	assert.False(t, result.HasMore)
+}
+
+func TestClaimAllCompleted_SkipsChallengesNotRolledOut(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+	repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+		createCompletedProgress("user", "d1", "daily"),
+		createCompletedProgress("user", "w1", "weekly"),
+	}, nil)
+	claimer := &fakeClaimer{}
+
+	result, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo,
+		nil, ChallengeRollouts{"weekly": 0}, SegmentTargets{}, claimer.claim, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1"}, claimer.claimed)
+	assert.Equal(t, 1, result.Claimed)
+}
+
+func TestClaimAllCompleted_SegmentTargets(t *testing.T) {
+	d3 := createCompletedProgress("user", "d3", "daily")
+	d3.Status = domain.GoalStatusInProgress
+	d3.Progress = 4
+	d3.CompletedAt = nil
+	targets := TargetOverrides{"d3": {"new_player": 4, "veteran": 20}}
+
+	for segment, want := range map[string][]string{
+		"new_player": {"d1", "d3"},
+		"veteran":    {"d1"},
+		"":           {"d1"},
+	} {
+		repo := new(mocks.GoalRepository)
+		repo.On("GetUserProgress", mock.Anything, "user", false).Return([]*domain.UserGoalProgress{
+			createCompletedProgress("user", "d1", "daily"),
+			d3,
+		}, nil)
+		claimer := &fakeClaimer{}
+
+		_, err := ClaimAllCompleted(context.Background(), "user", 0, newClaimAllGoalCache(), repo, nil, nil, targets.For(segment), claimer.claim, logrus.StandardLogger())
+
+		require.NoError(t, err)
+		assert.Equal(t, want, claimer.claimed, "segment %q", segment)
+	}
+}
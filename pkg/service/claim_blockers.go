@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+)
+
+// Claim blockers, the reasons a goal cannot be claimed right now, reported per
+// goal with include_blockers. Prerequisite blockers name the missing goal, e.g.
+// "prerequisites_not_met:goal-1".
+const (
+	ClaimBlockerNotCompleted        = "not_completed"
+	ClaimBlockerAlreadyClaimed      = "already_claimed"
+	ClaimBlockerGoalInactive        = "goal_inactive"
+	ClaimBlockerGoalRotated         = "goal_rotated"
+	ClaimBlockerPrerequisitesNotMet = "prerequisites_not_met"
+	ClaimBlockerChallengeLocked     = "challenge_locked"
+	ClaimBlockerChallengeMismatch   = "challenge_mismatch"
+	ClaimBlockerConfigInvalid       = "config_invalid"
+)
+
+// The claim predicates below are pure: ClaimGoalReward applies them in order
+// and stops at the first failure, ClaimBlockers applies all of them to report
+// every reason at once.
+
+// goalClaimProblem returns why the goal's reward cannot be claimed with target
+// (the segment's), or "" if it can (see checkGoalClaimable).
+func goalClaimProblem(goal *domain.Goal, target int) string {
+	if reason := goalTargetProblem(target); reason != "" {
+		return reason
+	}
+	if goal.Reward.Quantity <= 0 {
+		return GoalConfigNonPositiveQuantity
+	}
+	return ""
+}
+
+// checkClaimStarted fails when the user has no progress row for the goal.
+func checkClaimStarted(goalID string, progress *domain.UserGoalProgress) error {
+	if progress == nil {
+		return &mapper.GoalNotCompletedError{
+			GoalID: goalID,
+			Status: string(domain.GoalStatusNotStarted),
+		}
+	}
+	return nil
+}
+
+// checkProgressChallenge fails when the progress row is stored under another
+// challenge than the one requested, e.g. claimed or activated.
+func checkProgressChallenge(goalID, challengeID string, progress *domain.UserGoalProgress) error {
+	if progress.ChallengeID != challengeID {
+		return &mapper.ChallengeMismatchError{
+			GoalID:            goalID,
+			ChallengeID:       challengeID,
+			StoredChallengeID: progress.ChallengeID,
+		}
+	}
+	return nil
+}
+
+// checkClaimActive fails when the goal is not active (M3 Phase 6).
+func checkClaimActive(goalID, challengeID string, progress *domain.UserGoalProgress) error {
+	if !progress.IsActive {
+		return &mapper.GoalNotActiveError{
+			GoalID:      goalID,
+			ChallengeID: challengeID,
+		}
+	}
+	return nil
+}
+
+// checkClaimRotation fails when the goal has rotated since it was completed
+// (M5 Phase 6).
+func checkClaimRotation(goal *domain.Goal, challengeID string, progress *domain.UserGoalProgress, now time.Time) error {
+	if rotation.HasRotationOccurred(progress, goal, now) {
+		return &mapper.GoalRotatedError{
+			GoalID:      goal.ID,
+			ChallengeID: challengeID,
+		}
+	}
+	return nil
+}
+
+// checkClaimStatus fails when the goal is not completed or already claimed.
+// progress must be evaluated against the segment's target; activeness is left
+// to checkClaimActive.
+func checkClaimStatus(goalID string, progress *domain.UserGoalProgress) error {
+	if progress.Status == domain.GoalStatusCompleted {
+		return nil
+	}
+	if progress.IsClaimed() {
+		return &mapper.GoalAlreadyClaimedError{
+			GoalID:    goalID,
+			ClaimedAt: mapper.FormatOptionalTimestamp(progress.ClaimedAt),
+		}
+	}
+	return &mapper.GoalNotCompletedError{
+		GoalID: goalID,
+		Status: string(progress.Status),
+	}
+}
+
+// checkClaimPrerequisites fails when a prerequisite goal of goal is not
+// completed (Decision Q7).
+func checkClaimPrerequisites(goal *domain.Goal, checker *PrerequisiteChecker) error {
+	if !checker.CheckAllPrerequisitesMet(goal) {
+		return &mapper.PrerequisitesNotMetError{
+			GoalID:         goal.ID,
+			MissingGoalIDs: checker.GetMissingPrerequisites(goal),
+		}
+	}
+	return nil
+}
+
+// ClaimBlockerState is the user's state the claim blockers are computed from,
+// loaded once per response.
+type ClaimBlockerState struct {
+	// Progress holds the user's rows for the goals and their prerequisites,
+	// evaluated against Targets (see SegmentTargets.EvaluateAll); use
+	// WithClaimPrerequisites when it only holds part of them.
+	Progress map[string]*domain.UserGoalProgress
+	Targets  SegmentTargets
+	// Locks holds the lock reasons of the locked challenges (see
+	// ChallengePrerequisites.Locks).
+	Locks map[string]string
+	// Steps and StepProgress hold the multi-step goals and the user's progress
+	// on their steps (see GoalSteps.LoadProgress).
+	Steps        GoalSteps
+	StepProgress map[string][]int
+	Now          time.Time
+}
+
+// ClaimBlockers returns the claim blockers of each goal that cannot be claimed,
+// by goal ID, in the order the claim path checks them. Claimable goals have no
+// entry.
+func ClaimBlockers(goals []*domain.Goal, state ClaimBlockerState) map[string][]string {
+	blockers := make(map[string][]string)
+	checker := NewPrerequisiteChecker(state.Progress)
+	for _, goal := range goals {
+		if goal == nil {
+			continue
+		}
+		if goalBlockers := goalClaimBlockers(goal, checker, state); len(goalBlockers) > 0 {
+			blockers[goal.ID] = goalBlockers
+		}
+	}
+	return blockers
+}
+
+// goalClaimBlockers applies every claim predicate to one goal.
+func goalClaimBlockers(goal *domain.Goal, checker *PrerequisiteChecker, state ClaimBlockerState) []string {
+	var blockers []string
+	add := func(err error) {
+		for _, blocker := range claimBlockers(err) {
+			if !slices.Contains(blockers, blocker) {
+				blockers = append(blockers, blocker)
+			}
+		}
+	}
+
+	if _, locked := state.Locks[goal.ChallengeID]; locked {
+		blockers = append(blockers, ClaimBlockerChallengeLocked)
+	}
+	if goalClaimProblem(goal, state.Targets.Target(goal)) != "" {
+		blockers = append(blockers, ClaimBlockerConfigInvalid)
+	}
+	add(state.Steps.checkStepsComplete(goal.ID, state.StepProgress[goal.ID]))
+
+	progress := state.Progress[goal.ID]
+	if err := checkClaimStarted(goal.ID, progress); err != nil {
+		add(err)
+	} else {
+		add(checkProgressChallenge(goal.ID, goal.ChallengeID, progress))
+		add(checkClaimActive(goal.ID, goal.ChallengeID, progress))
+		add(checkClaimRotation(goal, goal.ChallengeID, progress, state.Now))
+		add(checkClaimStatus(goal.ID, progress))
+	}
+	add(checkClaimPrerequisites(goal, checker))
+
+	return blockers
+}
+
+// claimBlockers returns the claim blockers for an error of a claim predicate;
+// nil for nil.
+func claimBlockers(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		notCompleted   *mapper.GoalNotCompletedError
+		alreadyClaimed *mapper.GoalAlreadyClaimedError
+		notActive      *mapper.GoalNotActiveError
+		rotated        *mapper.GoalRotatedError
+		mismatch       *mapper.ChallengeMismatchError
+		prerequisites  *mapper.PrerequisitesNotMetError
+	)
+	switch {
+	case errors.As(err, &notCompleted):
+		return []string{ClaimBlockerNotCompleted}
+	case errors.As(err, &alreadyClaimed):
+		return []string{ClaimBlockerAlreadyClaimed}
+	case errors.As(err, &notActive):
+		return []string{ClaimBlockerGoalInactive}
+	case errors.As(err, &rotated):
+		return []string{ClaimBlockerGoalRotated}
+	case errors.As(err, &mismatch):
+		return []string{ClaimBlockerChallengeMismatch}
+	case errors.As(err, &prerequisites):
+		blockers := make([]string, 0, len(prerequisites.MissingGoalIDs))
+		for _, goalID := range prerequisites.MissingGoalIDs {
+			blockers = append(blockers, ClaimBlockerPrerequisitesNotMet+":"+goalID)
+		}
+		return blockers
+	default:
+		return nil
+	}
+}
+
+// MissingClaimPrerequisites returns the prerequisite IDs of goals that have no
+// entry in progressMap and are not among goals.
+func MissingClaimPrerequisites(goals []*domain.Goal, progressMap map[string]*domain.UserGoalProgress) []string {
+	var missing []string
+	seen := make(map[string]bool, len(goals))
+	for _, goal := range goals {
+		if goal != nil {
+			seen[goal.ID] = true
+		}
+	}
+	for _, goal := range goals {
+		if goal == nil {
+			continue
+		}
+		for _, prereqID := range goal.Prerequisites {
+			if progressMap[prereqID] == nil && !seen[prereqID] {
+				seen[prereqID] = true
+				missing = append(missing, prereqID)
+			}
+		}
+	}
+
+	return missing
+}
+
+// WithClaimPrerequisites returns progressMap extended with the rows returned by
+// MissingClaimPrerequisites, loaded in one query, like WithActivationPrerequisites
+// does for activatable goals. progressMap itself is not modified and is returned
+// as-is when nothing is missing.
+func WithClaimPrerequisites(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	userID string,
+	goals []*domain.Goal,
+	progressMap map[string]*domain.UserGoalProgress,
+) (map[string]*domain.UserGoalProgress, error) {
+	missing := MissingClaimPrerequisites(goals, progressMap)
+	if len(missing) == 0 {
+		return progressMap, nil
+	}
+
+	rows, err := repo.GetGoalsByIDs(ctx, userID, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goal prerequisites: %w", err)
+	}
+
+	merged := make(map[string]*domain.UserGoalProgress, len(progressMap)+len(rows))
+	for goalID, progress := range progressMap {
+		merged[goalID] = progress
+	}
+	for _, row := range rows {
+		merged[row.GoalID] = row
+	}
+	return merged, nil
+}
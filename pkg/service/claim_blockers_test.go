@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimBlockers(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	newGoal := func() *domain.Goal {
+		return &domain.Goal{
+			ID:          "g",
+			ChallengeID: "c",
+			Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+			Reward:      domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 100},
+		}
+	}
+	newProgress := func() *domain.UserGoalProgress {
+		return &domain.UserGoalProgress{
+			GoalID:      "g",
+			ChallengeID: "c",
+			Status:      domain.GoalStatusCompleted,
+			Progress:    10,
+			IsActive:    true,
+			UpdatedAt:   now,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		goal     func(*domain.Goal)
+		progress func(*domain.UserGoalProgress) *domain.UserGoalProgress
+		state    func(*ClaimBlockerState)
+		want     []string
+	}{
+		{
+			name: "claimable",
+		},
+		{
+			name:     "not started",
+			progress: func(*domain.UserGoalProgress) *domain.UserGoalProgress { return nil },
+			want:     []string{ClaimBlockerNotCompleted},
+		},
+		{
+			name: "in progress",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.Status = domain.GoalStatusInProgress
+				return p
+			},
+			want: []string{ClaimBlockerNotCompleted},
+		},
+		{
+			name: "already claimed",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.Status = domain.GoalStatusClaimed
+				return p
+			},
+			want: []string{ClaimBlockerAlreadyClaimed},
+		},
+		{
+			name: "inactive",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.IsActive = false
+				return p
+			},
+			want: []string{ClaimBlockerGoalInactive},
+		},
+		{
+			name: "rotated",
+			goal: func(g *domain.Goal) {
+				g.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+			},
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.UpdatedAt = now.Add(-48 * time.Hour)
+				return p
+			},
+			want: []string{ClaimBlockerGoalRotated},
+		},
+		{
+			name: "rotation enabled, completed this period",
+			goal: func(g *domain.Goal) {
+				g.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+			},
+		},
+		{
+			name: "stored under another challenge",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.ChallengeID = "other"
+				return p
+			},
+			want: []string{ClaimBlockerChallengeMismatch},
+		},
+		{
+			name: "prerequisites not met",
+			goal: func(g *domain.Goal) { g.Prerequisites = []string{"done", "pending", "missing"} },
+			state: func(s *ClaimBlockerState) {
+				s.Progress["done"] = &domain.UserGoalProgress{GoalID: "done", Status: domain.GoalStatusClaimed}
+				s.Progress["pending"] = &domain.UserGoalProgress{GoalID: "pending", Status: domain.GoalStatusInProgress}
+			},
+			want: []string{
+				ClaimBlockerPrerequisitesNotMet + ":pending",
+				ClaimBlockerPrerequisitesNotMet + ":missing",
+			},
+		},
+		{
+			name: "prerequisites met",
+			goal: func(g *domain.Goal) { g.Prerequisites = []string{"done"} },
+			state: func(s *ClaimBlockerState) {
+				s.Progress["done"] = &domain.UserGoalProgress{GoalID: "done", Status: domain.GoalStatusCompleted}
+			},
+		},
+		{
+			name:  "challenge locked",
+			state: func(s *ClaimBlockerState) { s.Locks = map[string]string{"c": "prerequisite_challenges"} },
+			want:  []string{ClaimBlockerChallengeLocked},
+		},
+		{
+			name:  "other challenge locked",
+			state: func(s *ClaimBlockerState) { s.Locks = map[string]string{"other": "prerequisite_challenges"} },
+		},
+		{
+			name: "non-positive reward quantity",
+			goal: func(g *domain.Goal) { g.Reward.Quantity = 0 },
+			want: []string{ClaimBlockerConfigInvalid},
+		},
+		{
+			name: "non-positive target",
+			goal: func(g *domain.Goal) { g.Requirement.TargetValue = 0 },
+			want: []string{ClaimBlockerConfigInvalid},
+		},
+		{
+			name: "steps incomplete",
+			state: func(s *ClaimBlockerState) {
+				s.Steps = testGoalSteps()
+				s.Steps["g"] = s.Steps["grind"]
+				s.StepProgress = map[string][]int{"g": {3, 500}}
+			},
+			want: []string{ClaimBlockerNotCompleted},
+		},
+		{
+			name: "steps complete",
+			state: func(s *ClaimBlockerState) {
+				s.Steps = GoalSteps{"g": testGoalSteps()["grind"]}
+				s.StepProgress = map[string][]int{"g": {3, 10000}}
+			},
+		},
+		{
+			name: "steps and status incomplete report once",
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.Status = domain.GoalStatusInProgress
+				return p
+			},
+			state: func(s *ClaimBlockerState) {
+				s.Steps = GoalSteps{"g": testGoalSteps()["grind"]}
+			},
+			want: []string{ClaimBlockerNotCompleted},
+		},
+		{
+			name: "every blocker at once, in claim order",
+			goal: func(g *domain.Goal) {
+				g.Reward.Quantity = 0
+				g.Prerequisites = []string{"missing"}
+				g.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+			},
+			progress: func(p *domain.UserGoalProgress) *domain.UserGoalProgress {
+				p.ChallengeID = "other"
+				p.IsActive = false
+				p.Status = domain.GoalStatusClaimed
+				p.UpdatedAt = now.Add(-48 * time.Hour)
+				return p
+			},
+			state: func(s *ClaimBlockerState) { s.Locks = map[string]string{"c": "prerequisite_challenges"} },
+			want: []string{
+				ClaimBlockerChallengeLocked,
+				ClaimBlockerConfigInvalid,
+				ClaimBlockerChallengeMismatch,
+				ClaimBlockerGoalInactive,
+				ClaimBlockerGoalRotated,
+				ClaimBlockerAlreadyClaimed,
+				ClaimBlockerPrerequisitesNotMet + ":missing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := newGoal()
+			if tt.goal != nil {
+				tt.goal(goal)
+			}
+			progress := newProgress()
+			if tt.progress != nil {
+				progress = tt.progress(progress)
+			}
+			state := ClaimBlockerState{Progress: map[string]*domain.UserGoalProgress{}, Now: now}
+			if progress != nil {
+				state.Progress["g"] = progress
+			}
+			if tt.state != nil {
+				tt.state(&state)
+			}
+
+			blockers := ClaimBlockers([]*domain.Goal{goal}, state)
+
+			if tt.want == nil {
+				assert.Empty(t, blockers)
+				return
+			}
+			assert.Equal(t, map[string][]string{"g": tt.want}, blockers)
+		})
+	}
+}
+
+func TestClaimBlockers_SegmentTarget(t *testing.T) {
+	goal := &domain.Goal{
+		ID:          "g",
+		ChallengeID: "c",
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		Reward:      domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 100},
+	}
+	targets := TargetOverrides{"g": {"casual": 5}}.For("casual")
+	row := &domain.UserGoalProgress{GoalID: "g", ChallengeID: "c", Status: domain.GoalStatusInProgress, Progress: 5, IsActive: true}
+	progress := targets.EvaluateAll(map[string]*domain.UserGoalProgress{"g": row}, commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c", Goals: []*domain.Goal{goal}},
+	}}, "", slog.Default()))
+
+	blockers := ClaimBlockers([]*domain.Goal{goal}, ClaimBlockerState{Progress: progress, Targets: targets, Now: time.Now()})
+
+	assert.Empty(t, blockers, "the segment's target of 5 is reached")
+}
+
+func TestClaimBlockers_SkipsNilGoals(t *testing.T) {
+	assert.Empty(t, ClaimBlockers([]*domain.Goal{nil}, ClaimBlockerState{}))
+}
+
+func TestMissingClaimPrerequisites(t *testing.T) {
+	goals := []*domain.Goal{
+		{ID: "a", Prerequisites: []string{"known", "elsewhere", "b"}},
+		{ID: "b", Prerequisites: []string{"elsewhere", "other"}},
+		nil,
+	}
+	progressMap := map[string]*domain.UserGoalProgress{"known": {GoalID: "known"}}
+
+	assert.Equal(t, []string{"elsewhere", "other"}, MissingClaimPrerequisites(goals, progressMap))
+}
+
+func TestWithClaimPrerequisites(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g2", Prerequisites: []string{"g1"}}}
+	progressMap := map[string]*domain.UserGoalProgress{"g2": {GoalID: "g2"}}
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"g1"}).Return([]*domain.UserGoalProgress{
+		{GoalID: "g1", Status: domain.GoalStatusCompleted},
+	}, nil)
+
+	merged, err := WithClaimPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.Contains(t, merged, "g1")
+	assert.Len(t, progressMap, 1, "input map must not be modified")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWithClaimPrerequisites_NothingMissing(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g2", Prerequisites: []string{"g1"}}}
+	progressMap := map[string]*domain.UserGoalProgress{"g1": {GoalID: "g1"}}
+	mockRepo := new(mocks.GoalRepository)
+
+	merged, err := WithClaimPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.Equal(t, progressMap, merged)
+	mockRepo.AssertNotCalled(t, "GetGoalsByIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWithClaimPrerequisites_QueryFails(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g2", Prerequisites: []string{"g1"}}}
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"g1"}).Return(nil, errors.New("db down"))
+
+	_, err := WithClaimPrerequisites(context.Background(), mockRepo, "user1", goals, nil)
+
+	assert.ErrorContains(t, err, "failed to load goal prerequisites")
+}
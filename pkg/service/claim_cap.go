@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ClaimCapWindow is the rolling window the claim cap counts over.
+	ClaimCapWindow = 24 * time.Hour
+
+	// claimRecordTimeout bounds recording a claim, which runs after the request
+	// context may already be done.
+	claimRecordTimeout = 2 * time.Second
+)
+
+// ClaimCap limits how many rewards one user can claim per rolling 24h, to slow
+// down botting on compromised accounts.
+//
+// The cap is checked before a claim starts and a claim is recorded after it
+// succeeds, so concurrent claims from the same user can overshoot the limit by
+// the number of claims in flight. If the claim log cannot be read, claims are
+// allowed (and the failure logged) rather than blocking every player.
+type ClaimCap struct {
+	repo   serviceRepo.ClaimCounterRepository
+	limit  int
+	logger logrus.FieldLogger
+	now    func() time.Time
+
+	hits prometheus.Counter
+}
+
+// ClaimCapStatus is a user's position against the cap.
+type ClaimCapStatus struct {
+	UserID         string
+	Enabled        bool
+	Limit          int
+	ClaimsInWindow int
+	// RetryAfter is the time until the oldest claim in the window expires; zero
+	// when the window is empty.
+	RetryAfter time.Duration
+}
+
+// NewClaimCap creates a claim cap allowing limit claims per user per rolling
+// 24h, logging through logger. A limit of 0 or less disables the cap.
+func NewClaimCap(repo serviceRepo.ClaimCounterRepository, limit int, logger logrus.FieldLogger) *ClaimCap {
+	return &ClaimCap{
+		repo:   repo,
+		limit:  limit,
+		logger: logger,
+		now:    func() time.Time { return time.Now().UTC() },
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "claim_cap_hits_total",
+			Help: "Claims rejected because the user reached the rolling claim cap.",
+		}),
+	}
+}
+
+// NewClaimCapFromEnv creates a claim cap limited by CLAIM_CAP_PER_DAY
+// (default 0 = disabled).
+func NewClaimCapFromEnv(repo serviceRepo.ClaimCounterRepository, logger logrus.FieldLogger) *ClaimCap {
+	return NewClaimCap(repo, common.GetEnvInt("CLAIM_CAP_PER_DAY", 0), logger)
+}
+
+// Enabled reports whether claims are capped.
+func (c *ClaimCap) Enabled() bool {
+	return c != nil && c.limit > 0
+}
+
+// Limit returns the number of claims allowed per window (0 or less when disabled).
+func (c *ClaimCap) Limit() int {
+	return c.limit
+}
+
+// Collectors returns the claim cap metrics for registration.
+func (c *ClaimCap) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.hits}
+}
+
+// Check returns a *mapper.ClaimCapExceededError if the user has reached the cap.
+// It always returns nil when the cap is disabled.
+func (c *ClaimCap) Check(ctx context.Context, userID, namespace, goalID string) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	now := c.now()
+	window, err := c.repo.GetClaimWindow(ctx, userID, namespace, now.Add(-ClaimCapWindow))
+	if err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": namespace,
+			"goal_id":   goalID,
+			"error":     err,
+		}).Error("Failed to read claim window; allowing claim")
+		return nil
+	}
+
+	if window.Count < c.limit {
+		return nil
+	}
+
+	c.hits.Inc()
+	capErr := &mapper.ClaimCapExceededError{
+		UserID:     userID,
+		Limit:      c.limit,
+		Window:     ClaimCapWindow,
+		RetryAfter: retryAfter(window, now),
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"user_id":             userID,
+		"namespace":           namespace,
+		"goal_id":             goalID,
+		"claims_in_window":    window.Count,
+		"claim_cap":           c.limit,
+		"retry_after_seconds": capErr.RetryAfterSeconds(),
+		"security_review":     true,
+	}).Warn("Claim cap exceeded")
+
+	return capErr
+}
+
+// Record logs a successful claim. Failures are logged, not returned: the reward
+// has already been granted at this point. Recording is detached from ctx
+// cancellation so a claim that just made its deadline is still counted.
+func (c *ClaimCap) Record(ctx context.Context, userID, namespace, goalID string) {
+	if !c.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), claimRecordTimeout)
+	defer cancel()
+
+	now := c.now()
+	if err := c.repo.RecordClaim(ctx, userID, namespace, goalID, now, now.Add(-ClaimCapWindow)); err != nil {
+		c.logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": namespace,
+			"goal_id":   goalID,
+			"error":     err,
+		}).Error("Failed to record claim for claim cap")
+	}
+}
+
+// Status returns the user's claim count in the current window.
+// The count is read even when the cap is disabled.
+func (c *ClaimCap) Status(ctx context.Context, userID, namespace string) (*ClaimCapStatus, error) {
+	now := c.now()
+	window, err := c.repo.GetClaimWindow(ctx, userID, namespace, now.Add(-ClaimCapWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim window: %w", err)
+	}
+
+	return &ClaimCapStatus{
+		UserID:         userID,
+		Enabled:        c.Enabled(),
+		Limit:          c.limit,
+		ClaimsInWindow: window.Count,
+		RetryAfter:     retryAfter(window, now),
+	}, nil
+}
+
+// Reset clears the user's claim log and returns the number of claims removed.
+func (c *ClaimCap) Reset(ctx context.Context, userID, namespace string) (int, error) {
+	cleared, err := c.repo.ResetClaims(ctx, userID, namespace)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset claims: %w", err)
+	}
+
+	return cleared, nil
+}
+
+// retryAfter is the time until the oldest claim in the window drops out of it.
+func retryAfter(window *serviceRepo.ClaimWindow, now time.Time) time.Duration {
+	if window.OldestClaimAt == nil {
+		return 0
+	}
+	return max(window.OldestClaimAt.Add(ClaimCapWindow).Sub(now), 0)
+}
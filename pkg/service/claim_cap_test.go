@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var claimCapNow = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestClaimCap(repo repository.ClaimCounterRepository, limit int) *ClaimCap {
+	claimCap := NewClaimCap(repo, limit, logrus.StandardLogger())
+	claimCap.now = func() time.Time { return claimCapNow }
+	return claimCap
+}
+
+func TestClaimCap_DisabledByDefault(t *testing.T) {
+	t.Setenv("CLAIM_CAP_PER_DAY", "")
+	repo := new(mocks.ClaimCounterRepository)
+	claimCap := NewClaimCapFromEnv(repo, logrus.StandardLogger())
+
+	assert.False(t, claimCap.Enabled())
+	assert.NoError(t, claimCap.Check(context.Background(), "user-1", "ns", "goal-1"))
+	claimCap.Record(context.Background(), "user-1", "ns", "goal-1")
+	repo.AssertNotCalled(t, "GetClaimWindow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "RecordClaim", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	var nilCap *ClaimCap
+	assert.NoError(t, nilCap.Check(context.Background(), "user-1", "ns", "goal-1"))
+}
+
+func TestClaimCap_Check_UnderLimit(t *testing.T) {
+	repo := new(mocks.ClaimCounterRepository)
+	repo.On("GetClaimWindow", mock.Anything, "user-1", "ns", claimCapNow.Add(-ClaimCapWindow)).
+		Return(&repository.ClaimWindow{Count: 2}, nil)
+
+	claimCap := newTestClaimCap(repo, 3)
+
+	assert.NoError(t, claimCap.Check(context.Background(), "user-1", "ns", "goal-1"))
+	assert.Equal(t, 0.0, testutil.ToFloat64(claimCap.hits))
+}
+
+func TestClaimCap_Check_LimitReached(t *testing.T) {
+	oldest := claimCapNow.Add(-20 * time.Hour)
+	repo := new(mocks.ClaimCounterRepository)
+	repo.On("GetClaimWindow", mock.Anything, "user-1", "ns", claimCapNow.Add(-ClaimCapWindow)).
+		Return(&repository.ClaimWindow{Count: 3, OldestClaimAt: &oldest}, nil)
+
+	claimCap := newTestClaimCap(repo, 3)
+	err := claimCap.Check(context.Background(), "user-1", "ns", "goal-1")
+
+	var capErr *mapper.ClaimCapExceededError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, 3, capErr.Limit)
+	assert.Equal(t, 4*time.Hour, capErr.RetryAfter)
+	assert.Equal(t, 1.0, testutil.ToFloat64(claimCap.hits))
+}
+
+func TestClaimCap_Check_RepositoryErrorAllowsClaim(t *testing.T) {
+	repo := new(mocks.ClaimCounterRepository)
+	repo.On("GetClaimWindow", mock.Anything, "user-1", "ns", mock.Anything).Return(nil, errors.New("db down"))
+
+	claimCap := newTestClaimCap(repo, 3)
+	logger, hook := logtest.NewNullLogger()
+	claimCap.logger = logger
+
+	assert.NoError(t, claimCap.Check(context.Background(), "user-1", "ns", "goal-1"))
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to read claim window; allowing claim", hook.LastEntry().Message)
+	assert.Equal(t, "goal-1", hook.LastEntry().Data["goal_id"])
+}
+
+func TestClaimCap_Record(t *testing.T) {
+	repo := new(mocks.ClaimCounterRepository)
+	var recordErr error
+	repo.On("RecordClaim", mock.Anything, "user-1", "ns", "goal-1", claimCapNow, claimCapNow.Add(-ClaimCapWindow)).
+		Run(func(args mock.Arguments) { recordErr = args.Get(0).(context.Context).Err() }).
+		Return(nil)
+
+	// A cancelled request still records the claim
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	newTestClaimCap(repo, 3).Record(ctx, "user-1", "ns", "goal-1")
+
+	repo.AssertExpectations(t)
+	assert.NoError(t, recordErr)
+}
+
+func TestClaimCap_StatusAndReset(t *testing.T) {
+	oldest := claimCapNow.Add(-23 * time.Hour)
+	repo := new(mocks.ClaimCounterRepository)
+	repo.On("GetClaimWindow", mock.Anything, "user-1", "ns", claimCapNow.Add(-ClaimCapWindow)).
+		Return(&repository.ClaimWindow{Count: 5, OldestClaimAt: &oldest}, nil)
+	repo.On("ResetClaims", mock.Anything, "user-1", "ns").Return(5, nil)
+
+	claimCap := newTestClaimCap(repo, 0)
+
+	status, err := claimCap.Status(context.Background(), "user-1", "ns")
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.Equal(t, 5, status.ClaimsInWindow)
+	assert.Equal(t, time.Hour, status.RetryAfter)
+
+	cleared, err := claimCap.Reset(context.Background(), "user-1", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, 5, cleared)
+}
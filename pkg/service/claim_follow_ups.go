@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+)
+
+// ClaimFollowUps describes what a successful claim changed beyond the claimed
+// goal, so clients can update in one step instead of refetching.
+type ClaimFollowUps struct {
+	// ChallengeCompleted is true when the claim completed the goal's challenge:
+	// every goal of it is now claimed.
+	ChallengeCompleted bool
+	// UnlockedChallengeIDs are the challenges whose prerequisite challenges are
+	// all completed now, in config order.
+	UnlockedChallengeIDs []string
+	// ClaimableGoalIDs are the goals of the unlocked challenges that are already
+	// completed, active and have their goal prerequisites met, so they can be
+	// claimed right away.
+	ClaimableGoalIDs []string
+}
+
+// ClaimFollowUps returns the follow-ups of claiming goalID in challengeID. It
+// runs after the claim is committed and loads the rows it needs (the
+// challenge's other goals and, for the challenges requiring it, their goals and
+// other prerequisite challenges) in one query by goal ID. A challenge with no
+// other goals needs no query.
+func (p ChallengePrerequisites) ClaimFollowUps(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	goalCache cache.GoalCache,
+	targets SegmentTargets,
+	userID string,
+	challengeID string,
+	goalID string,
+) (*ClaimFollowUps, error) {
+	followUps := &ClaimFollowUps{
+		UnlockedChallengeIDs: []string{},
+		ClaimableGoalIDs:     []string{},
+	}
+
+	challenge := goalCache.GetChallengeByChallengeID(challengeID)
+	if challenge == nil {
+		return followUps, nil
+	}
+
+	// Challenges that require this one; only they can be unlocked by it
+	var dependents []*domain.Challenge
+	for _, candidate := range goalCache.GetAllChallenges() {
+		for _, prereqID := range p[candidate.ID] {
+			if prereqID == challengeID {
+				dependents = append(dependents, candidate)
+				break
+			}
+		}
+	}
+
+	// Rows of this challenge, of the dependents' other prerequisite challenges
+	// and of the dependents' goals with their goal prerequisites
+	var goalIDs []string
+	seen := map[string]bool{goalID: true}
+	addGoal := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			goalIDs = append(goalIDs, id)
+		}
+	}
+	for _, goal := range challenge.Goals {
+		addGoal(goal.ID)
+	}
+	for _, dependent := range dependents {
+		for _, prereqID := range p[dependent.ID] {
+			if prereq := goalCache.GetChallengeByChallengeID(prereqID); prereq != nil {
+				for _, goal := range prereq.Goals {
+					addGoal(goal.ID)
+				}
+			}
+		}
+		for _, goal := range dependent.Goals {
+			addGoal(goal.ID)
+			for _, prereqGoalID := range goal.Prerequisites {
+				addGoal(prereqGoalID)
+			}
+		}
+	}
+
+	progressMap := map[string]*domain.UserGoalProgress{
+		// The claim was just committed; no need to read it back
+		goalID: {UserID: userID, GoalID: goalID, ChallengeID: challengeID, Status: domain.GoalStatusClaimed},
+	}
+	if len(goalIDs) > 0 {
+		rows, err := repo.GetGoalsByIDs(ctx, userID, goalIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load claim follow-up progress: %w", err)
+		}
+		for _, row := range rows {
+			if row.GoalID != goalID {
+				progressMap[row.GoalID] = row
+			}
+		}
+	}
+
+	followUps.ChallengeCompleted = IsChallengeCompleted(challenge, progressMap)
+	if !followUps.ChallengeCompleted {
+		// The challenge was not completed before the claim either, so nothing
+		// that requires it is unlocked
+		return followUps, nil
+	}
+
+	// The challenge was incomplete until this claim, so every dependent that is
+	// unlocked now was locked before
+	evaluated := targets.EvaluateAll(progressMap, goalCache)
+	checker := NewPrerequisiteChecker(evaluated)
+	now := time.Now().UTC()
+	for _, dependent := range dependents {
+		if len(p.LockedBy(dependent.ID, goalCache, progressMap)) > 0 {
+			continue
+		}
+		followUps.UnlockedChallengeIDs = append(followUps.UnlockedChallengeIDs, dependent.ID)
+
+		for _, goal := range dependent.Goals {
+			progress := evaluated[goal.ID]
+			if progress == nil || !progress.CanClaim() || !progress.IsActive {
+				continue
+			}
+			if checker.CheckGoalLocked(goal) || rotation.HasRotationOccurred(progress, goal, now) {
+				continue
+			}
+			followUps.ClaimableGoalIDs = append(followUps.ClaimableGoalIDs, goal.ID)
+		}
+	}
+
+	return followUps, nil
+}
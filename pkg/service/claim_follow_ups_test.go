@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func completedRow(goalID string, isActive bool) *domain.UserGoalProgress {
+	return &domain.UserGoalProgress{GoalID: goalID, Status: domain.GoalStatusCompleted, IsActive: isActive}
+}
+
+func TestClaimFollowUps_CompletesChallengeAndUnlocksDependent(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	repo.On("GetGoalsByIDs", ctx, "user-1", []string{"s1a", "s2"}).
+		Return([]*domain.UserGoalProgress{claimed("s1a"), completedRow("s2", true)}, nil)
+
+	followUps, err := chainPrerequisites.ClaimFollowUps(ctx, repo, newChainGoalCache(), SegmentTargets{}, "user-1", "season-1", "s1b")
+
+	require.NoError(t, err)
+	assert.Equal(t, &ClaimFollowUps{
+		ChallengeCompleted:   true,
+		UnlockedChallengeIDs: []string{"season-2"},
+		ClaimableGoalIDs:     []string{"s2"},
+	}, followUps)
+	repo.AssertExpectations(t)
+}
+
+func TestClaimFollowUps_ChallengeNotCompleted(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	repo.On("GetGoalsByIDs", ctx, "user-1", []string{"s1a", "s2"}).
+		Return([]*domain.UserGoalProgress{completedRow("s1a", true), completedRow("s2", true)}, nil)
+
+	followUps, err := chainPrerequisites.ClaimFollowUps(ctx, repo, newChainGoalCache(), SegmentTargets{}, "user-1", "season-1", "s1b")
+
+	require.NoError(t, err)
+	assert.False(t, followUps.ChallengeCompleted)
+	assert.Empty(t, followUps.UnlockedChallengeIDs)
+	assert.Empty(t, followUps.ClaimableGoalIDs)
+}
+
+func TestClaimFollowUps_OnlyActiveCompletedGoalsAreClaimable(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	// season-1's goals: s1a is completed but inactive, s1b can be claimed
+	repo.On("GetGoalsByIDs", ctx, "user-1", []string{"s1a", "s1b"}).
+		Return([]*domain.UserGoalProgress{completedRow("s1a", false), completedRow("s1b", true)}, nil)
+
+	followUps, err := chainPrerequisites.ClaimFollowUps(ctx, repo, newChainGoalCache(), SegmentTargets{}, "user-1", "tutorial", "t1")
+
+	require.NoError(t, err)
+	assert.True(t, followUps.ChallengeCompleted)
+	assert.Equal(t, []string{"season-1"}, followUps.UnlockedChallengeIDs)
+	assert.Equal(t, []string{"s1b"}, followUps.ClaimableGoalIDs)
+}
+
+func TestClaimFollowUps_SingleGoalChallengeWithoutDependentsNeedsNoQuery(t *testing.T) {
+	repo := new(mocks.GoalRepository)
+
+	followUps, err := chainPrerequisites.ClaimFollowUps(context.Background(), repo, newChainGoalCache(), SegmentTargets{}, "user-1", "season-2", "s2")
+
+	require.NoError(t, err)
+	assert.True(t, followUps.ChallengeCompleted)
+	assert.Empty(t, followUps.UnlockedChallengeIDs)
+	repo.AssertNotCalled(t, "GetGoalsByIDs")
+}
+
+func TestClaimFollowUps_NilPrerequisites(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	repo.On("GetGoalsByIDs", ctx, "user-1", []string{"s1a"}).
+		Return([]*domain.UserGoalProgress{claimed("s1a")}, nil)
+
+	var prereqs ChallengePrerequisites
+	followUps, err := prereqs.ClaimFollowUps(ctx, repo, newChainGoalCache(), SegmentTargets{}, "user-1", "season-1", "s1b")
+
+	require.NoError(t, err)
+	assert.True(t, followUps.ChallengeCompleted)
+	assert.Empty(t, followUps.UnlockedChallengeIDs)
+}
+
+func TestClaimFollowUps_QueryError(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	repo.On("GetGoalsByIDs", ctx, "user-1", []string{"s1a", "s2"}).Return(nil, errors.New("db down"))
+
+	_, err := chainPrerequisites.ClaimFollowUps(ctx, repo, newChainGoalCache(), SegmentTargets{}, "user-1", "season-1", "s1b")
+
+	assert.ErrorContains(t, err, "db down")
+}
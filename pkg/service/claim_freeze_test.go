@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var claimFreezeNow = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestClaimFreezes(repo repository.ClaimFreezeRepository) *ClaimFreezes {
+	freezes := NewClaimFreezes(repo, logrus.StandardLogger())
+	freezes.now = func() time.Time { return claimFreezeNow }
+	return freezes
+}
+
+func TestClaimFreezes_Check(t *testing.T) {
+	until := claimFreezeNow.Add(48 * time.Hour)
+	repo := new(mocks.ClaimFreezeRepository)
+	repo.On("GetClaimFreeze", mock.Anything, "user-1", "ns", claimFreezeNow).
+		Return(&repository.ClaimFreeze{UserID: "user-1", FrozenUntil: until}, nil)
+	repo.On("GetClaimFreeze", mock.Anything, "user-2", "ns", claimFreezeNow).Return(nil, nil)
+
+	freezes := newTestClaimFreezes(repo)
+
+	var frozenErr *mapper.ClaimFrozenError
+	require.ErrorAs(t, freezes.Check(context.Background(), "user-1", "ns"), &frozenErr)
+	assert.Equal(t, until, frozenErr.FrozenUntil)
+	assert.NoError(t, freezes.Check(context.Background(), "user-2", "ns"))
+}
+
+func TestClaimFreezes_Check_RepositoryErrorRejectsClaim(t *testing.T) {
+	repo := new(mocks.ClaimFreezeRepository)
+	repo.On("GetClaimFreeze", mock.Anything, "user-1", "ns", claimFreezeNow).Return(nil, errors.New("connection reset"))
+
+	err := newTestClaimFreezes(repo).Check(context.Background(), "user-1", "ns")
+
+	assert.ErrorContains(t, err, "connection reset")
+}
+
+func TestClaimFreezes_Check_Nil(t *testing.T) {
+	var freezes *ClaimFreezes
+	assert.NoError(t, freezes.Check(context.Background(), "user-1", "ns"))
+}
+
+func TestClaimFreezes_Unfreeze(t *testing.T) {
+	removed := &repository.ClaimFreeze{UserID: "user-1", FrozenUntil: claimFreezeNow.Add(time.Hour)}
+	repo := new(mocks.ClaimFreezeRepository)
+	repo.On("RemoveClaimFreeze", mock.Anything, "user-1", "ns", "admin-1", "false positive", "10.0.0.1", claimFreezeNow).
+		Return(removed, nil)
+
+	got, err := newTestClaimFreezes(repo).Unfreeze(context.Background(), "user-1", "ns", "admin-1", "false positive", "10.0.0.1")
+
+	require.NoError(t, err)
+	assert.Equal(t, removed, got)
+}
+
+func TestClaimFreezes_List(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		wantLimit     int
+		rows          int
+		wantLen       int
+		wantTruncated bool
+	}{
+		{"default limit", 0, DefaultClaimFreezeListLimit, 3, 3, false},
+		{"capped limit", 5000, MaxClaimFreezeListLimit, 0, 0, false},
+		{"truncated", 2, 2, 3, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([]*repository.ClaimFreeze, tt.rows)
+			for i := range rows {
+				rows[i] = &repository.ClaimFreeze{FrozenUntil: claimFreezeNow.Add(time.Duration(i+1) * time.Hour)}
+			}
+			repo := new(mocks.ClaimFreezeRepository)
+			repo.On("ListClaimFreezes", mock.Anything, "ns", claimFreezeNow, tt.wantLimit+1).Return(rows, nil)
+
+			freezes, truncated, err := newTestClaimFreezes(repo).List(context.Background(), "ns", tt.limit)
+
+			require.NoError(t, err)
+			assert.Len(t, freezes, tt.wantLen)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
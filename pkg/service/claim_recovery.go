@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultClaimRecoveryInterval is how often the claim outbox is scanned.
+	DefaultClaimRecoveryInterval = time.Minute
+	// DefaultClaimRecoveryStaleAfter is how long an outbox entry must be idle
+	// before recovery touches it. It must exceed the claim timeout so entries of
+	// claims still running are left alone.
+	DefaultClaimRecoveryStaleAfter = time.Minute
+
+	claimRecoveryBatchSize = 100
+)
+
+// Claim recovery outcomes (claim_recovery_resolutions_total outcome label).
+const (
+	ClaimRecoveryClaimed  = "claimed"
+	ClaimRecoveryReleased = "released"
+	ClaimRecoveryFailed   = "failed"
+)
+
+// ClaimRecovery resolves claim outbox entries left behind when a claim stopped
+// between reserving a goal and deleting its entry (process crash, database
+// outage after the grant):
+//   - granted entries: the reward is out, so the goal is marked claimed
+//   - pending entries of goals already claimed: the claim only failed to mark
+//     the entry granted, so the entry is deleted
+//   - other pending entries: whether AGS granted is unknown. The entry is
+//     deleted so the goal is completed and claimable again, as it was when a
+//     crash rolled back the old single-transaction claim. Each case is logged
+//     for reconciliation.
+//
+// Every resolution is counted in claim_recovery_resolutions_total by outcome.
+// ClaimGoalReward resumes stale entries the same way when the player claims
+// before the next pass.
+type ClaimRecovery struct {
+	repo       repository.GoalRepository
+	outbox     serviceRepo.ClaimOutboxRepository
+	interval   time.Duration
+	staleAfter time.Duration
+	now        func() time.Time
+	logger     logrus.FieldLogger
+
+	resolutions *prometheus.CounterVec
+}
+
+// ClaimRecoveryResult counts the entries resolved by one recovery pass.
+type ClaimRecoveryResult struct {
+	MarkedClaimed   int
+	ReleasedPending int
+	Failed          int
+}
+
+// NewClaimRecovery creates a claim recovery worker that logs through logger.
+func NewClaimRecovery(
+	repo repository.GoalRepository,
+	outbox serviceRepo.ClaimOutboxRepository,
+	interval time.Duration,
+	staleAfter time.Duration,
+	logger logrus.FieldLogger,
+) *ClaimRecovery {
+	return &ClaimRecovery{
+		repo:       repo,
+		outbox:     outbox,
+		interval:   interval,
+		staleAfter: staleAfter,
+		now:        func() time.Time { return time.Now().UTC() },
+		logger:     logger,
+		resolutions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "claim_recovery_resolutions_total",
+			Help: "Stale claim outbox entries resolved by claim recovery, by entry state and outcome (claimed, released, failed).",
+		}, []string{"state", "outcome"}),
+	}
+}
+
+// Collectors returns the claim recovery metrics for registration.
+func (r *ClaimRecovery) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{r.resolutions}
+}
+
+// NewClaimRecoveryFromEnv creates a claim recovery worker configured by:
+//   - CLAIM_RECOVERY_INTERVAL: how often the outbox is scanned (default "1m")
+//   - CLAIM_RECOVERY_STALE_AFTER: how long an entry must be idle before it is
+//     recovered (default "1m", must be longer than the 10s claim timeout)
+func NewClaimRecoveryFromEnv(
+	repo repository.GoalRepository,
+	outbox serviceRepo.ClaimOutboxRepository,
+	logger logrus.FieldLogger,
+) *ClaimRecovery {
+	interval := parseClaimRecoveryDuration("CLAIM_RECOVERY_INTERVAL", DefaultClaimRecoveryInterval, 0, logger)
+	staleAfter := parseClaimRecoveryDuration("CLAIM_RECOVERY_STALE_AFTER", DefaultClaimRecoveryStaleAfter, claimTimeout, logger)
+
+	return NewClaimRecovery(repo, outbox, interval, staleAfter, logger)
+}
+
+func parseClaimRecoveryDuration(key string, fallback, minimum time.Duration, logger logrus.FieldLogger) time.Duration {
+	value := common.GetEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= minimum {
+		logger.Warnf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// Interval returns how often Run scans the outbox.
+func (r *ClaimRecovery) Interval() time.Duration {
+	return r.interval
+}
+
+// StaleAfter returns how long an entry must be idle before it is recovered.
+func (r *ClaimRecovery) StaleAfter() time.Duration {
+	return r.staleAfter
+}
+
+// RecoverStale resolves one batch of stale outbox entries. Entries that fail
+// are kept for the next pass.
+func (r *ClaimRecovery) RecoverStale(ctx context.Context) (*ClaimRecoveryResult, error) {
+	before := r.now().Add(-r.staleAfter)
+
+	entries, err := r.outbox.ListStale(ctx, before, claimRecoveryBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale claims: %w", err)
+	}
+
+	result := &ClaimRecoveryResult{}
+	for _, entry := range entries {
+		log := r.logger.WithFields(logrus.Fields{
+			"user_id":         entry.UserID,
+			"goal_id":         entry.GoalID,
+			"challenge_id":    entry.ChallengeID,
+			"grant_namespace": entry.Namespace,
+			"state":           entry.State,
+			"updated_at":      entry.UpdatedAt,
+		})
+
+		if entry.State == serviceRepo.ClaimOutboxGranted {
+			if err := r.completeGranted(ctx, entry); err != nil {
+				r.count(result, entry, ClaimRecoveryFailed)
+				log.WithError(err).Error("Failed to recover granted claim")
+				continue
+			}
+			r.count(result, entry, ClaimRecoveryClaimed)
+			log.Info("Recovered granted claim")
+			continue
+		}
+
+		// A claimed goal means the claim granted and only failed to mark the entry
+		progress, err := r.repo.GetProgress(ctx, entry.UserID, entry.GoalID)
+		if err != nil {
+			r.count(result, entry, ClaimRecoveryFailed)
+			log.WithError(err).Error("Failed to load progress of stale pending claim")
+			continue
+		}
+		claimed := progress != nil && progress.IsClaimed()
+
+		deleted, err := r.outbox.DeleteIfPending(ctx, entry.UserID, entry.GoalID, before)
+		if err != nil {
+			r.count(result, entry, ClaimRecoveryFailed)
+			log.WithError(err).Error("Failed to release stale pending claim")
+			continue
+		}
+		switch {
+		case !deleted:
+			// Marked granted or resumed by a claim in the meantime
+		case claimed:
+			r.count(result, entry, ClaimRecoveryClaimed)
+			log.Info("Recovered stale pending claim of a claimed goal")
+		default:
+			r.count(result, entry, ClaimRecoveryReleased)
+			log.Warn("Released stale pending claim; the reward grant outcome is unknown, reconcile with AGS")
+		}
+	}
+
+	return result, nil
+}
+
+// count records the outcome of resolving entry.
+func (r *ClaimRecovery) count(result *ClaimRecoveryResult, entry *serviceRepo.ClaimOutboxEntry, outcome string) {
+	switch outcome {
+	case ClaimRecoveryClaimed:
+		result.MarkedClaimed++
+	case ClaimRecoveryReleased:
+		result.ReleasedPending++
+	case ClaimRecoveryFailed:
+		result.Failed++
+	}
+	r.resolutions.WithLabelValues(entry.State, outcome).Inc()
+}
+
+// completeGranted marks a granted entry's goal claimed and deletes the entry.
+// A goal that is no longer claimable (already claimed, or reset by rotation)
+// only needs its entry deleted.
+func (r *ClaimRecovery) completeGranted(ctx context.Context, entry *serviceRepo.ClaimOutboxEntry) error {
+	if err := r.repo.MarkAsClaimed(ctx, entry.UserID, entry.GoalID); err != nil {
+		if !isGoalNotCompleted(err) {
+			return fmt.Errorf("failed to mark goal as claimed: %w", err)
+		}
+		r.logger.WithFields(logrus.Fields{
+			"user_id": entry.UserID,
+			"goal_id": entry.GoalID,
+		}).Warn("Granted claim's goal is no longer claimable, deleting outbox entry")
+	}
+
+	if err := r.outbox.Delete(ctx, entry.UserID, entry.GoalID); err != nil {
+		return fmt.Errorf("failed to delete claim outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// isGoalNotCompleted reports whether err is MarkAsClaimed's error for a goal
+// that is not completed, e.g. already claimed or reset by rotation.
+func isGoalNotCompleted(err error) bool {
+	var challengeErr *commonErrors.ChallengeError
+	return stdErrors.As(err, &challengeErr) && challengeErr.Code == commonErrors.ErrCodeGoalNotCompleted
+}
+
+// Run recovers stale claims every interval until ctx is cancelled.
+func (r *ClaimRecovery) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RecoverStale(ctx); err != nil && ctx.Err() == nil {
+			r.logger.WithError(err).Warn("Failed to recover stale claims")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClaimRecovery(repo *mocks.GoalRepository, outbox *mocks.ClaimOutboxRepository, now time.Time) *ClaimRecovery {
+	recovery := NewClaimRecovery(repo, outbox, time.Minute, time.Minute, logrus.StandardLogger())
+	recovery.now = func() time.Time { return now }
+	return recovery
+}
+
+func TestClaimRecovery_RecoverStale(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Minute)
+
+	repo := new(mocks.GoalRepository)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	outbox.On("ListStale", mock.Anything, before, claimRecoveryBatchSize).Return([]*repository.ClaimOutboxEntry{
+		{UserID: "user-1", GoalID: "granted", State: repository.ClaimOutboxGranted},
+		{UserID: "user-2", GoalID: "already-claimed", State: repository.ClaimOutboxGranted},
+		{UserID: "user-3", GoalID: "pending", State: repository.ClaimOutboxPending},
+		{UserID: "user-4", GoalID: "db-down", State: repository.ClaimOutboxGranted},
+		{UserID: "user-5", GoalID: "pending-claimed", State: repository.ClaimOutboxPending},
+	}, nil)
+
+	repo.On("MarkAsClaimed", mock.Anything, "user-1", "granted").Return(nil)
+	repo.On("MarkAsClaimed", mock.Anything, "user-2", "already-claimed").
+		Return(commonErrors.ErrGoalNotCompleted("already-claimed"))
+	repo.On("MarkAsClaimed", mock.Anything, "user-4", "db-down").Return(errors.New("connection refused"))
+
+	outbox.On("Delete", mock.Anything, "user-1", "granted").Return(nil)
+	outbox.On("Delete", mock.Anything, "user-2", "already-claimed").Return(nil)
+	outbox.On("DeleteIfPending", mock.Anything, "user-3", "pending", before).Return(true, nil)
+	// Claimed, but the claim failed to mark its entry granted
+	repo.On("GetProgress", mock.Anything, "user-3", "pending").
+		Return(&domain.UserGoalProgress{Status: domain.GoalStatusCompleted}, nil)
+	repo.On("GetProgress", mock.Anything, "user-5", "pending-claimed").
+		Return(&domain.UserGoalProgress{Status: domain.GoalStatusClaimed}, nil)
+	outbox.On("DeleteIfPending", mock.Anything, "user-5", "pending-claimed", before).Return(true, nil)
+
+	recovery := newTestClaimRecovery(repo, outbox, now)
+	result, err := recovery.RecoverStale(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &ClaimRecoveryResult{MarkedClaimed: 3, ReleasedPending: 1, Failed: 1}, result)
+	assert.Equal(t, 2.0, testutil.ToFloat64(recovery.resolutions.WithLabelValues(repository.ClaimOutboxGranted, ClaimRecoveryClaimed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recovery.resolutions.WithLabelValues(repository.ClaimOutboxGranted, ClaimRecoveryFailed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recovery.resolutions.WithLabelValues(repository.ClaimOutboxPending, ClaimRecoveryClaimed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recovery.resolutions.WithLabelValues(repository.ClaimOutboxPending, ClaimRecoveryReleased)))
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, "user-4", "db-down")
+	repo.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestClaimRecovery_PendingGrantedMeanwhileIsKept(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("ListStale", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.ClaimOutboxEntry{
+		{UserID: "user-1", GoalID: "goal-1", State: repository.ClaimOutboxPending},
+	}, nil)
+	outbox.On("DeleteIfPending", mock.Anything, "user-1", "goal-1", mock.Anything).Return(false, nil)
+	repo := new(mocks.GoalRepository)
+	repo.On("GetProgress", mock.Anything, "user-1", "goal-1").
+		Return(&domain.UserGoalProgress{Status: domain.GoalStatusCompleted}, nil)
+
+	result, err := newTestClaimRecovery(repo, outbox, now).RecoverStale(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &ClaimRecoveryResult{}, result)
+}
+
+func TestClaimRecovery_PendingProgressError(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("ListStale", mock.Anything, mock.Anything, mock.Anything).Return([]*repository.ClaimOutboxEntry{
+		{UserID: "user-1", GoalID: "goal-1", State: repository.ClaimOutboxPending},
+	}, nil)
+	repo := new(mocks.GoalRepository)
+	repo.On("GetProgress", mock.Anything, "user-1", "goal-1").Return(nil, errors.New("db down"))
+	logger, hook := logtest.NewNullLogger()
+
+	recovery := newTestClaimRecovery(repo, outbox, now)
+	recovery.logger = logger
+	result, err := recovery.RecoverStale(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &ClaimRecoveryResult{Failed: 1}, result)
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Failed to load progress of stale pending claim", hook.LastEntry().Message)
+	assert.Equal(t, "goal-1", hook.LastEntry().Data["goal_id"])
+	outbox.AssertNotCalled(t, "DeleteIfPending", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimRecovery_ListError(t *testing.T) {
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("ListStale", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+	_, err := newTestClaimRecovery(new(mocks.GoalRepository), outbox, time.Now()).RecoverStale(context.Background())
+
+	assert.ErrorContains(t, err, "failed to list stale claims")
+}
+
+func TestClaimRecovery_FromEnv(t *testing.T) {
+	t.Setenv("CLAIM_RECOVERY_INTERVAL", "30s")
+	t.Setenv("CLAIM_RECOVERY_STALE_AFTER", "5s")
+
+	recovery := NewClaimRecoveryFromEnv(new(mocks.GoalRepository), new(mocks.ClaimOutboxRepository), logrus.StandardLogger())
+
+	assert.Equal(t, 30*time.Second, recovery.Interval())
+	assert.Equal(t, DefaultClaimRecoveryStaleAfter, recovery.StaleAfter(), "must not be shorter than the claim timeout")
+}
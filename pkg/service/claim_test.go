@@ -6,155 +6,22 @@ import (
 	"testing"
 	"time"
 
+	agsClient "extend-challenge-service/pkg/client"
 	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
 
 	"github.com/AccelByte/extend-challenge-common/pkg/client"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
-	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockRewardClient is a mock implementation of client.RewardClient
-type MockRewardClient struct {
-	mock.Mock
-}
-
-func (m *MockRewardClient) GrantItemReward(ctx context.Context, namespace, userID, itemID string, quantity int) error {
-	args := m.Called(ctx, namespace, userID, itemID, quantity)
-	return args.Error(0)
-}
-
-func (m *MockRewardClient) GrantWalletReward(ctx context.Context, namespace, userID, currencyCode string, amount int) error {
-	args := m.Called(ctx, namespace, userID, currencyCode, amount)
-	return args.Error(0)
-}
-
-func (m *MockRewardClient) GrantReward(ctx context.Context, namespace, userID string, reward domain.Reward) error {
-	args := m.Called(ctx, namespace, userID, reward)
-	return args.Error(0)
-}
-
-// MockTxRepository is a mock implementation of repository.TxRepository
-type MockTxRepository struct {
-	mock.Mock
-}
-
-// Compile-time interface check
-var _ repository.TxRepository = (*MockTxRepository)(nil)
-
-func (m *MockTxRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockTxRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, activeOnly)
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockTxRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, challengeID, activeOnly)
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) BatchUpsertProgress(ctx context.Context, updates []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, updates)
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []repository.CopyRow) error {
-	args := m.Called(ctx, rows)
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
-	args := m.Called(ctx, userID, goalID)
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) BeginTx(ctx context.Context) (repository.TxRepository, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(repository.TxRepository), args.Error(1)
-}
-
-func (m *MockTxRepository) GetProgressForUpdate(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxRepository) Commit() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) Rollback() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-// M3: Goal assignment control methods
-func (m *MockTxRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockTxRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-// M3 Phase 9: Fast path optimization methods
-func (m *MockTxRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockTxRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockTxRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-// M4: Batch goal activation
-func (m *MockTxRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
 // Test Fixtures
 
 func createClaimableGoal(goalID, challengeID string) *domain.Goal {
@@ -195,6 +62,15 @@ func createCompletedProgress(userID, goalID, challengeID string) *domain.UserGoa
 	}
 }
 
+// newClaimOutbox returns an outbox that reserves every claim.
+func newClaimOutbox() *mocks.ClaimOutboxRepository {
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	outbox.On("MarkGranted", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	outbox.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return outbox
+}
+
 // Test ClaimGoalReward - Success
 
 func TestClaimGoalReward_Success(t *testing.T) {
@@ -207,20 +83,19 @@ func TestClaimGoalReward_Success(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
 	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
 	mockTxRepo.On("Commit").Return(nil)
 
-	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	require.NoError(t, err)
 	assert.Equal(t, goalID, result.GoalID)
@@ -228,6 +103,7 @@ func TestClaimGoalReward_Success(t *testing.T) {
 	assert.Equal(t, goal.Reward, result.Reward)
 	assert.Equal(t, userID, result.UserID)
 	assert.Equal(t, challengeID, result.ChallengeID)
+	assert.True(t, result.Grant.IsZero(), "plain reward clients report no grant IDs")
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -235,15 +111,43 @@ func TestClaimGoalReward_Success(t *testing.T) {
 	mockRewardClient.AssertExpectations(t)
 }
 
+func TestClaimGoalReward_ReturnsGrantIDs(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(createCompletedProgress("user123", "goal-1", "challenge-1"), nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(),
+		0,
+		agsClient.WithMockGrantResults(mockRewardClient),
+		SegmentTargets{},
+		ClaimPrecondition{},
+		logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, agsClient.MockGrantResult("test-namespace", "user123", goal.Reward), result.Grant)
+	assert.NotEmpty(t, result.Grant.EntitlementID)
+}
+
 // Test ClaimGoalReward - Validation Errors
 
 func TestClaimGoalReward_EmptyUserID(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "", "goal-1", "challenge-1", "namespace", mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "", "goal-1", "challenge-1", "namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "user ID cannot be empty")
@@ -251,11 +155,11 @@ func TestClaimGoalReward_EmptyUserID(t *testing.T) {
 
 func TestClaimGoalReward_EmptyGoalID(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "user123", "", "challenge-1", "namespace", mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "user123", "", "challenge-1", "namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "goal ID cannot be empty")
@@ -263,11 +167,11 @@ func TestClaimGoalReward_EmptyGoalID(t *testing.T) {
 
 func TestClaimGoalReward_EmptyChallengeID(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "", "namespace", mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "", "namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "challenge ID cannot be empty")
@@ -275,11 +179,11 @@ func TestClaimGoalReward_EmptyChallengeID(t *testing.T) {
 
 func TestClaimGoalReward_EmptyNamespace(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "", mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "namespace cannot be empty")
@@ -287,10 +191,10 @@ func TestClaimGoalReward_EmptyNamespace(t *testing.T) {
 
 func TestClaimGoalReward_NilCache(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", nil, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", nil, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "goal cache cannot be nil")
@@ -298,10 +202,10 @@ func TestClaimGoalReward_NilCache(t *testing.T) {
 
 func TestClaimGoalReward_NilRepository(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRewardClient := new(mocks.RewardClient)
 
-	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", mockCache, nil, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", mockCache, nil, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "repository cannot be nil")
@@ -309,15 +213,34 @@ func TestClaimGoalReward_NilRepository(t *testing.T) {
 
 func TestClaimGoalReward_NilRewardClient(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", mockCache, mockRepo, nil)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", mockCache, mockRepo, newClaimOutbox(), 0, nil, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "reward client cannot be nil")
 }
 
+func TestClaimGoalReward_NilOutbox(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "namespace", mockCache, mockRepo, nil, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "claim outbox cannot be nil")
+}
+
+func TestClaimGoalReward_NilLogger(t *testing.T) {
+	_, err := ClaimGoalReward(context.Background(), "user123", "goal-1", "challenge-1", "test-namespace", new(mocks.GoalCache), new(mocks.GoalRepository), newClaimOutbox(), 0, new(mocks.RewardClient), SegmentTargets{}, ClaimPrecondition{}, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "logger cannot be nil")
+}
+
 // Test ClaimGoalReward - Goal Not Found
 
 func TestClaimGoalReward_GoalNotFound(t *testing.T) {
@@ -327,13 +250,13 @@ func TestClaimGoalReward_GoalNotFound(t *testing.T) {
 	challengeID := "challenge-1"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(nil)
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var goalNotFoundErr *mapper.GoalNotFoundError
@@ -353,17 +276,19 @@ func TestClaimGoalReward_GoalWrongChallenge(t *testing.T) {
 
 	goal := createClaimableGoal(goalID, "challenge-1")
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
-	var goalNotFoundErr *mapper.GoalNotFoundError
-	assert.True(t, errors.As(err, &goalNotFoundErr))
+	var otherChallengeErr *mapper.GoalInOtherChallengeError
+	assert.True(t, errors.As(err, &otherChallengeErr))
+	assert.Equal(t, challengeID, otherChallengeErr.ChallengeID)
+	assert.Equal(t, "challenge-1", otherChallengeErr.GoalChallengeID)
 
 	mockCache.AssertExpectations(t)
 }
@@ -379,18 +304,28 @@ func TestClaimGoalReward_TransactionStartFailed(t *testing.T) {
 
 	goal := createClaimableGoal(goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(nil, errors.New("database error"))
+	logger, hook := logtest.NewNullLogger()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logger)
 
 	assert.Error(t, err)
 	assert.Equal(t, mapper.ErrDatabaseError, err)
 
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "Failed to start transaction", entry.Message)
+	assert.Equal(t, userID, entry.Data["user_id"])
+	assert.Equal(t, goalID, entry.Data["goal_id"])
+	assert.Equal(t, challengeID, entry.Data["challenge_id"])
+	assert.Equal(t, namespace, entry.Data["namespace"])
+
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
@@ -406,17 +341,17 @@ func TestClaimGoalReward_ProgressNotFound(t *testing.T) {
 
 	goal := createClaimableGoal(goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(nil, nil)
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var goalNotCompletedErr *mapper.GoalNotCompletedError
@@ -436,17 +371,17 @@ func TestClaimGoalReward_GoalNotCompleted(t *testing.T) {
 	progress := createCompletedProgress(userID, goalID, challengeID)
 	progress.Status = domain.GoalStatusInProgress
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var goalNotCompletedErr *mapper.GoalNotCompletedError
@@ -455,6 +390,155 @@ func TestClaimGoalReward_GoalNotCompleted(t *testing.T) {
 	assert.Equal(t, string(domain.GoalStatusInProgress), goalNotCompletedErr.Status)
 }
 
+func TestClaimGoalReward_SegmentTargetCompletesGoal(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	progress := createCompletedProgress("user123", "goal-1", "challenge-1")
+	progress.Status = domain.GoalStatusInProgress
+	progress.Progress = 3
+	progress.CompletedAt = nil
+	targets := TargetOverrides{"goal-1": {"new_player": 3}}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockTxRepo.On("UpsertProgress", mock.Anything, mock.MatchedBy(func(p *domain.UserGoalProgress) bool {
+		return p.Status == domain.GoalStatusCompleted && p.CompletedAt != nil && p.Progress == 3
+	})).Return(nil).Once()
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, targets.For("new_player"), ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+	assert.Equal(t, domain.GoalStatusInProgress, progress.Status, "the loaded row is not modified")
+	mockTxRepo.AssertExpectations(t)
+	mockRewardClient.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_SegmentTargetDoesNotApplyToOtherSegments(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	progress := createCompletedProgress("user123", "goal-1", "challenge-1")
+	progress.Status = domain.GoalStatusInProgress
+	progress.Progress = 3
+	progress.CompletedAt = nil
+	targets := TargetOverrides{"goal-1": {"new_player": 3}}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil).Maybe()
+
+	for _, segment := range []string{"", "veteran"} {
+		_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, new(mocks.RewardClient), targets.For(segment), ClaimPrecondition{}, logrus.StandardLogger())
+
+		var notCompleted *mapper.GoalNotCompletedError
+		assert.ErrorAs(t, err, &notCompleted, "segment %q", segment)
+	}
+	mockTxRepo.AssertNotCalled(t, "UpsertProgress", mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_StalePrecondition(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	snapshotAt := time.Now().UTC().Add(-time.Minute)
+	progress := createCompletedProgress("user123", "goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil)
+
+	// Completed after the list was read, e.g. a reset goal completed again
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient,
+		SegmentTargets{}, ClaimPrecondition{ExpectedStatus: "completed", SnapshotAt: &snapshotAt}, logrus.StandardLogger())
+
+	var stale *mapper.StaleClaimError
+	require.ErrorAs(t, err, &stale)
+	assert.Equal(t, "completed", stale.Status)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "MarkAsClaimed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_PreconditionMet(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	progress := createCompletedProgress("user123", "goal-1", "challenge-1")
+	snapshotAt := progress.UpdatedAt.Add(time.Second)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient,
+		SegmentTargets{}, ClaimPrecondition{ExpectedStatus: "completed", SnapshotAt: &snapshotAt}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+}
+
+func TestClaimGoalReward_PreconditionExpectsSegmentStatus(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	progress := createCompletedProgress("user123", "goal-1", "challenge-1")
+	progress.Status = domain.GoalStatusInProgress
+	progress.Progress = 3
+	progress.CompletedAt = nil
+	targets := TargetOverrides{"goal-1": {"new_player": 3}}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockTxRepo.On("UpsertProgress", mock.Anything, mock.Anything).Return(nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	// The list showed the goal completed at the segment's target
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient,
+		targets.For("new_player"), ClaimPrecondition{ExpectedStatus: "completed"}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+}
+
+func TestClaimGoalReward_InvalidPrecondition(t *testing.T) {
+	_, err := ClaimGoalReward(context.Background(), "user123", "goal-1", "challenge-1", "test-namespace",
+		new(mocks.GoalCache), new(mocks.GoalRepository), newClaimOutbox(), 0, new(mocks.RewardClient),
+		SegmentTargets{}, ClaimPrecondition{ExpectedStatus: "done"}, logrus.StandardLogger())
+
+	assert.ErrorContains(t, err, "invalid expected_status")
+}
+
 func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
@@ -468,17 +552,17 @@ func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
 	claimedAt := time.Now().UTC()
 	progress.ClaimedAt = &claimedAt
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var alreadyClaimedErr *mapper.GoalAlreadyClaimedError
@@ -486,6 +570,290 @@ func TestClaimGoalReward_AlreadyClaimed(t *testing.T) {
 	assert.Equal(t, goalID, alreadyClaimedErr.GoalID)
 }
 
+func TestClaimGoalReward_AlreadyGranted(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil).Once()
+	// Another claim granted the reward but has not marked the goal claimed yet
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil)
+	outbox.On("Get", mock.Anything, userID, goalID).
+		Return(&repository.ClaimOutboxEntry{UserID: userID, GoalID: goalID, State: repository.ClaimOutboxGranted}, nil)
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var alreadyClaimedErr *mapper.GoalAlreadyClaimedError
+	require.True(t, errors.As(err, &alreadyClaimedErr))
+	assert.Equal(t, goalID, alreadyClaimedErr.GoalID)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "Commit")
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_WaitsForInFlightClaim(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	completed := createCompletedProgress(userID, goalID, challengeID)
+	claimed := createCompletedProgress(userID, goalID, challengeID)
+	claimed.Status = domain.GoalStatusClaimed
+	claimed.ClaimedAt = &claimed.UpdatedAt
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	// The first attempt finds another claim's pending reservation; by the
+	// second attempt that claim has finished
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(completed, nil).Once()
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(claimed, nil).Once()
+	mockTxRepo.On("Rollback").Return(nil).Twice()
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+	outbox.On("Get", mock.Anything, userID, goalID).
+		Return(&repository.ClaimOutboxEntry{UserID: userID, GoalID: goalID, State: repository.ClaimOutboxPending}, nil).Once()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var alreadyClaimedErr *mapper.GoalAlreadyClaimedError
+	require.True(t, errors.As(err, &alreadyClaimedErr))
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_WaitForInFlightClaimHonoursDeadline(t *testing.T) {
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil)
+	outbox.On("Get", mock.Anything, userID, goalID).
+		Return(&repository.ClaimOutboxEntry{UserID: userID, GoalID: goalID, State: repository.ClaimOutboxPending}, nil)
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// The crash points of a claim leave its outbox entry behind; a later claim
+// resumes a stale entry instead of waiting for it. A crash after the goal is
+// marked claimed is covered by TestClaimGoalReward_AlreadyClaimed.
+
+// Crashed after marking the entry granted, before marking the goal claimed
+func TestClaimGoalReward_ResumesStaleGrantedClaim(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Commit").Return(nil).Twice()
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+	outbox.On("Get", mock.Anything, userID, goalID).Return(&repository.ClaimOutboxEntry{
+		UserID: userID, GoalID: goalID, State: repository.ClaimOutboxGranted, UpdatedAt: time.Now().UTC().Add(-time.Hour),
+	}, nil)
+	outbox.On("Delete", mock.Anything, userID, goalID).Return(nil).Once()
+
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, time.Minute, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+	assert.True(t, result.Grant.IsZero())
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_StaleGrantedClaimResumedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Commit").Return(nil).Once()
+	// Claim recovery or another claim marked the goal claimed first
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(commonErrors.ErrGoalNotCompleted(goalID))
+	mockTxRepo.On("Rollback").Return(nil).Once()
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+	outbox.On("Get", mock.Anything, userID, goalID).Return(&repository.ClaimOutboxEntry{
+		UserID: userID, GoalID: goalID, State: repository.ClaimOutboxGranted, UpdatedAt: time.Now().UTC().Add(-time.Hour),
+	}, nil)
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, time.Minute, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var alreadyClaimedErr *mapper.GoalAlreadyClaimedError
+	require.True(t, errors.As(err, &alreadyClaimedErr))
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Crashed after reserving, before or during the grant (or after the grant,
+// before marking the entry granted)
+func TestClaimGoalReward_ResumesStalePendingClaim(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Commit").Return(nil).Twice()
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
+	// The crashed claim's grant may have been applied; AGS deduplicates the
+	// retry by the key of the completion both claims claim
+	crashedKey := grantIdempotencyKey(&repository.ClaimOutboxEntry{Namespace: namespace, UserID: userID, GoalID: goalID}, progress.CompletedAt)
+	withCrashedKey := mock.MatchedBy(func(ctx context.Context) bool {
+		return agsClient.IdempotencyKeyFromContext(ctx) == crashedKey
+	})
+	mockRewardClient.On("GrantReward", withCrashedKey, namespace, userID, goal.Reward).Return(nil).Once()
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+	outbox.On("Get", mock.Anything, userID, goalID).Return(&repository.ClaimOutboxEntry{
+		UserID: userID, GoalID: goalID, State: repository.ClaimOutboxPending, UpdatedAt: time.Now().UTC().Add(-time.Hour),
+	}, nil)
+	outbox.On("DeleteIfPending", mock.Anything, userID, goalID, mock.Anything).Return(true, nil).Once()
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+	outbox.On("MarkGranted", mock.Anything, userID, goalID).Return(nil).Once()
+	outbox.On("Delete", mock.Anything, userID, goalID).Return(nil).Once()
+
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, time.Minute, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+	mockRewardClient.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestGrantIdempotencyKey(t *testing.T) {
+	completedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	completedAgain := completedAt.Add(time.Hour)
+	entry := &repository.ClaimOutboxEntry{Namespace: "test-namespace", UserID: "user123", GoalID: "goal-1"}
+
+	key := grantIdempotencyKey(entry, &completedAt)
+
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, grantIdempotencyKey(&repository.ClaimOutboxEntry{
+		Namespace: "test-namespace", UserID: "user123", GoalID: "goal-1", State: repository.ClaimOutboxPending,
+	}, &completedAt), "a resumed entry keeps the key")
+	assert.NotEqual(t, key, grantIdempotencyKey(entry, &completedAgain), "a new completion gets a new key")
+	assert.NotEqual(t, key, grantIdempotencyKey(&repository.ClaimOutboxEntry{
+		Namespace: "test-namespace", UserID: "user123", GoalID: "goal-2",
+	}, &completedAt))
+}
+
+func TestClaimGoalReward_RecentEntryIsNotResumed(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil).Once()
+	// A granted entry of a claim still running
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil)
+	outbox.On("Get", mock.Anything, userID, goalID).Return(&repository.ClaimOutboxEntry{
+		UserID: userID, GoalID: goalID, State: repository.ClaimOutboxGranted, UpdatedAt: time.Now().UTC(),
+	}, nil)
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, time.Minute, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var alreadyClaimedErr *mapper.GoalAlreadyClaimedError
+	require.True(t, errors.As(err, &alreadyClaimedErr))
+	mockTxRepo.AssertNotCalled(t, "MarkAsClaimed", mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertNotCalled(t, "DeleteIfPending", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // M3 Phase 6: Test claim validation with inactive goal
 func TestClaimGoalReward_GoalNotActive(t *testing.T) {
 	ctx := context.Background()
@@ -498,17 +866,17 @@ func TestClaimGoalReward_GoalNotActive(t *testing.T) {
 	progress := createCompletedProgress(userID, goalID, challengeID)
 	progress.IsActive = false // Goal is completed but inactive
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var goalNotActiveErr *mapper.GoalNotActiveError
@@ -517,6 +885,33 @@ func TestClaimGoalReward_GoalNotActive(t *testing.T) {
 	assert.Equal(t, challengeID, goalNotActiveErr.ChallengeID)
 }
 
+func TestClaimGoalReward_StoredChallengeMismatch(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	// Left under the goal's old challenge by a config reorganization
+	progress := createCompletedProgress("user123", "goal-1", "old-challenge")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := newClaimOutbox()
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(progress, nil)
+	mockTxRepo.On("Rollback").Return(nil).Maybe()
+
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var mismatch *mapper.ChallengeMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, "challenge-1", mismatch.ChallengeID)
+	assert.Equal(t, "old-challenge", mismatch.StoredChallengeID)
+	outbox.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // Test ClaimGoalReward - Prerequisites
 
 func TestClaimGoalReward_PrerequisitesNotMet(t *testing.T) {
@@ -527,28 +922,163 @@ func TestClaimGoalReward_PrerequisitesNotMet(t *testing.T) {
 	namespace := "test-namespace"
 
 	goal := createClaimableGoal(goalID, challengeID)
-	goal.Prerequisites = []string{"goal-1"}
+	goal.Prerequisites = []string{"goal-1", "goal-0"}
 
 	progress := createCompletedProgress(userID, goalID, challengeID)
+	// goal-0 is done, goal-1 is still in progress
+	prereqDone := createCompletedProgress(userID, "goal-0", challengeID)
+	prereqPending := createCompletedProgress(userID, "goal-1", challengeID)
+	prereqPending.Status = domain.GoalStatusInProgress
+	prereqPending.CompletedAt = nil
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, userID, []string{"goal-1", "goal-0"}).
+		Return([]*domain.UserGoalProgress{prereqDone, prereqPending}, nil)
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var prereqsNotMetErr *mapper.PrerequisitesNotMetError
 	assert.True(t, errors.As(err, &prereqsNotMetErr))
 	assert.Equal(t, goalID, prereqsNotMetErr.GoalID)
-	assert.Contains(t, prereqsNotMetErr.MissingGoalIDs, "goal-1")
+	assert.Equal(t, []string{"goal-1"}, prereqsNotMetErr.MissingGoalIDs)
+	mockTxRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_PrerequisiteWithoutProgress(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-2"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	goal.Prerequisites = []string{"goal-1"}
+
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, userID, []string{"goal-1"}).
+		Return([]*domain.UserGoalProgress{}, nil)
+	mockTxRepo.On("Rollback").Return(nil).Once()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var prereqsNotMetErr *mapper.PrerequisitesNotMetError
+	require.True(t, errors.As(err, &prereqsNotMetErr))
+	assert.Equal(t, []string{"goal-1"}, prereqsNotMetErr.MissingGoalIDs)
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_PrerequisitesMet(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-2"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	goal.Prerequisites = []string{"goal-1"}
+
+	progress := createCompletedProgress(userID, goalID, challengeID)
+	prereq := createCompletedProgress(userID, "goal-1", challengeID)
+	prereq.Status = domain.GoalStatusClaimed
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, userID, []string{"goal-1"}).
+		Return([]*domain.UserGoalProgress{prereq}, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, goalID, result.GoalID)
+	mockTxRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertExpectations(t)
+	mockRewardClient.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_NoPrerequisites_SkipsQuery(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	mockTxRepo.AssertNotCalled(t, "GetUserProgress", mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "GetGoalsByIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_PrerequisiteQueryFailed(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-2"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	goal.Prerequisites = []string{"goal-1"}
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, userID, []string{"goal-1"}).
+		Return(nil, errors.New("connection reset"))
+	mockTxRepo.On("Rollback").Return(nil).Once()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+	mockTxRepo.AssertExpectations(t)
 }
 
 // Test ClaimGoalReward - Reward Grant Errors
@@ -563,25 +1093,30 @@ func TestClaimGoalReward_RewardGrantFailed(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(errors.New("AGS error"))
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
+	outbox := newClaimOutbox()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	var rewardGrantErr *mapper.RewardGrantError
 	assert.True(t, errors.As(err, &rewardGrantErr))
 	assert.Equal(t, goalID, rewardGrantErr.GoalID)
 
+	// Nothing was granted, so the reservation is released and the goal stays claimable
+	outbox.AssertCalled(t, "Delete", mock.Anything, userID, goalID)
+	outbox.AssertNotCalled(t, "MarkGranted", mock.Anything, mock.Anything, mock.Anything)
+
 	// Should have attempted 4 times (1 initial + 3 retries)
 	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 4)
 }
@@ -596,15 +1131,14 @@ func TestClaimGoalReward_RewardGrantSuccessAfterRetry(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 
 	// Fail first 2 attempts, succeed on 3rd
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).
@@ -617,7 +1151,7 @@ func TestClaimGoalReward_RewardGrantSuccessAfterRetry(t *testing.T) {
 	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
 	mockTxRepo.On("Commit").Return(nil)
 
-	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	require.NoError(t, err)
 	assert.Equal(t, goalID, result.GoalID)
@@ -640,23 +1174,28 @@ func TestClaimGoalReward_MarkAsClaimedFailed(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
 	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(errors.New("database error"))
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
+	outbox := newClaimOutbox()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Equal(t, mapper.ErrDatabaseError, err)
+
+	// The reward is out: the granted entry is kept for claim recovery
+	outbox.AssertCalled(t, "MarkGranted", mock.Anything, userID, goalID)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestClaimGoalReward_CommitFailed(t *testing.T) {
@@ -669,28 +1208,97 @@ func TestClaimGoalReward_CommitFailed(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := newClaimOutbox()
 
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
-	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
-	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
 	mockTxRepo.On("Commit").Return(errors.New("commit failed"))
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 	assert.Equal(t, mapper.ErrDatabaseError, err)
 
+	// The reservation is released and nothing is granted
+	outbox.AssertCalled(t, "Delete", mock.Anything, userID, goalID)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "Rollback")
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 	mockTxRepo.AssertExpectations(t)
-	mockRewardClient.AssertExpectations(t)
+}
+
+// The row lock must be released before AGS is called, so progress writes for
+// the user are not blocked by a slow grant.
+func TestClaimGoalReward_CommitsBeforeGrant(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	var steps []string
+	record := func(step string) func(mock.Arguments) {
+		return func(mock.Arguments) { steps = append(steps, step) }
+	}
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil).Run(record("begin"))
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil).Run(record("lock"))
+	mockTxRepo.On("Commit").Return(nil).Run(record("commit"))
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil).Run(record("mark claimed"))
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil).Run(record("grant"))
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"begin", "lock", "commit", "grant", "begin", "mark claimed", "commit"}, steps)
+}
+
+func TestClaimGoalReward_CommitAfterGrantFailed(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := newClaimOutbox()
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, userID, goalID).Return(nil)
+	mockTxRepo.On("Commit").Return(nil).Once()
+	mockTxRepo.On("Commit").Return(errors.New("commit failed")).Once()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.Equal(t, mapper.ErrDatabaseError, err)
+	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
+	outbox.AssertCalled(t, "MarkGranted", mock.Anything, userID, goalID)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertExpectations(t)
 }
 
 // Test Error Classification - Non-Retryable Errors
@@ -705,10 +1313,10 @@ func TestClaimGoalReward_NonRetryableError_BadRequest(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return non-retryable error (bad request)
 	badRequestErr := &client.BadRequestError{Message: "invalid item ID"}
@@ -716,20 +1324,59 @@ func TestClaimGoalReward_NonRetryableError_BadRequest(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(badRequestErr).Once()
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
-	var rewardGrantErr *mapper.RewardGrantError
-	assert.True(t, errors.As(err, &rewardGrantErr))
+	var permanentErr *mapper.RewardGrantPermanentError
+	require.ErrorAs(t, err, &permanentErr)
+	assert.Equal(t, GrantErrorClassBadRequest, permanentErr.ErrorClass)
 
 	// Should only attempt once (no retries for non-retryable errors)
 	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
 }
 
+func TestClaimGoalReward_GrantNamespace(t *testing.T) {
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	mockTxRepo.On("Commit").Return(nil).Once()
+	mockTxRepo.On("Rollback").Return(nil).Maybe()
+	outbox.On("Reserve", mock.Anything, mock.MatchedBy(func(entry *repository.ClaimOutboxEntry) bool {
+		return entry.Namespace == "other-game"
+	})).Return(true, nil)
+	outbox.On("Delete", mock.Anything, userID, goalID).Return(nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "other-game", userID, goal.Reward).
+		Return(&client.NotFoundError{Resource: "item"}).Once()
+
+	ctx := WithGrantNamespace(context.Background(), "other-game")
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	// Progress stays under the service namespace; only the grant moves
+	var permanentErr *mapper.RewardGrantPermanentError
+	require.ErrorAs(t, err, &permanentErr)
+	assert.Equal(t, "other-game", permanentErr.GrantNamespace)
+	mockRewardClient.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
 func TestClaimGoalReward_NonRetryableError_NotFound(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
@@ -740,10 +1387,10 @@ func TestClaimGoalReward_NonRetryableError_NotFound(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return non-retryable error (not found)
 	notFoundErr := &client.NotFoundError{Resource: "item_sword"}
@@ -751,13 +1398,19 @@ func TestClaimGoalReward_NonRetryableError_NotFound(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(notFoundErr).Once()
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
-	assert.Error(t, err)
+	// The deleted store item fails every retry identically, so the claim is dead-lettered
+	var permanentErr *mapper.RewardGrantPermanentError
+	require.ErrorAs(t, err, &permanentErr)
+	assert.Equal(t, GrantErrorClassNotFound, permanentErr.ErrorClass)
+	assert.Equal(t, challengeID, permanentErr.ChallengeID)
+	assert.Equal(t, goal.Reward, permanentErr.Reward)
+	assert.ErrorIs(t, err, notFoundErr)
 
 	// Should only attempt once (no retries for non-retryable errors)
 	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
@@ -773,10 +1426,10 @@ func TestClaimGoalReward_NonRetryableError_Forbidden(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return non-retryable error (forbidden)
 	forbiddenErr := &client.ForbiddenError{Message: "namespace mismatch"}
@@ -784,13 +1437,15 @@ func TestClaimGoalReward_NonRetryableError_Forbidden(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(forbiddenErr).Once()
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
-	assert.Error(t, err)
+	// Service misconfiguration, not a problem with this reward
+	var rewardGrantErr *mapper.RewardGrantError
+	assert.ErrorAs(t, err, &rewardGrantErr)
 
 	// Should only attempt once (no retries for non-retryable errors)
 	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
@@ -806,10 +1461,10 @@ func TestClaimGoalReward_NonRetryableError_Authentication(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return non-retryable error (authentication)
 	authErr := &client.AuthenticationError{Message: "invalid token"}
@@ -817,11 +1472,11 @@ func TestClaimGoalReward_NonRetryableError_Authentication(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(authErr).Once()
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 
@@ -839,10 +1494,10 @@ func TestClaimGoalReward_NonRetryableError_MessagePattern(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return non-retryable error with pattern match
 	patternErr := errors.New("invalid currency code: XYZ not found")
@@ -850,11 +1505,11 @@ func TestClaimGoalReward_NonRetryableError_MessagePattern(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(patternErr).Once()
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 
@@ -874,10 +1529,10 @@ func TestClaimGoalReward_RetryableError_BadGateway(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return retryable error (502 Bad Gateway)
 	badGatewayErr := &client.AGSError{StatusCode: 502, Message: "bad gateway"}
@@ -885,11 +1540,11 @@ func TestClaimGoalReward_RetryableError_BadGateway(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(badGatewayErr)
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 
@@ -907,10 +1562,10 @@ func TestClaimGoalReward_RetryableError_ServiceUnavailable(t *testing.T) {
 	goal := createClaimableGoal(goalID, challengeID)
 	progress := createCompletedProgress(userID, goalID, challengeID)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTxRepo := new(MockTxRepository)
-	mockRewardClient := new(MockRewardClient)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
 
 	// Return retryable error (503 Service Unavailable)
 	serviceUnavailableErr := &client.AGSError{StatusCode: 503, Message: "service unavailable"}
@@ -918,14 +1573,128 @@ func TestClaimGoalReward_RetryableError_ServiceUnavailable(t *testing.T) {
 	mockCache.On("GetGoalByID", goalID).Return(goal)
 	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
 	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
-	mockTxRepo.On("GetUserProgress", mock.Anything, userID, false).Return([]*domain.UserGoalProgress{progress}, nil)
 	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).Return(serviceUnavailableErr)
+	mockTxRepo.On("Commit").Return(nil).Once()
 	mockTxRepo.On("Rollback").Return(nil).Maybe()
 
-	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, mockRewardClient)
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
 
 	assert.Error(t, err)
 
 	// Should attempt 4 times (1 initial + 3 retries for retryable errors)
 	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 4)
 }
+
+// Request deadline handling: a deadline that fires before the grant completes
+// must release the reservation with nothing granted or marked claimed.
+
+func TestClaimGoalReward_DeadlineExceededBeforeGrant(t *testing.T) {
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	goal.Prerequisites = []string{"goal-0"}
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	// The deadline fires while the prerequisite query runs
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, userID, []string{"goal-0"}).
+		Run(func(mock.Arguments) { cancel() }).
+		Return([]*domain.UserGoalProgress{createCompletedProgress(userID, "goal-0", challengeID)}, nil)
+	mockTxRepo.On("Rollback").Return(nil).Once()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "MarkAsClaimed", mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "Commit")
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_DeadlineExceededDuringGrant(t *testing.T) {
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	// AGS call blocks until the request deadline fires
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return(&client.AGSError{StatusCode: 503, Message: "timeout"})
+	mockTxRepo.On("Commit").Return(nil).Once()
+	outbox := newClaimOutbox()
+
+	_, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	mockRewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
+	mockTxRepo.AssertNotCalled(t, "MarkAsClaimed", mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertCalled(t, "Delete", mock.Anything, userID, goalID)
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_DeadlineAfterGrantStillCommits(t *testing.T) {
+	userID := "user123"
+	goalID := "goal-1"
+	challengeID := "challenge-1"
+	namespace := "test-namespace"
+
+	goal := createClaimableGoal(goalID, challengeID)
+	progress := createCompletedProgress(userID, goalID, challengeID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goalID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, userID, goalID).Return(progress, nil)
+	// The request is cancelled right after AGS granted the reward
+	mockRewardClient.On("GrantReward", mock.Anything, namespace, userID, goal.Reward).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil)
+	// Marking as claimed must not see the request cancellation
+	mockTxRepo.On("MarkAsClaimed", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }), userID, goalID).
+		Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ClaimGoalReward(ctx, userID, goalID, challengeID, namespace, mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+	mockTxRepo.AssertNotCalled(t, "Rollback")
+	mockTxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// IsClaimed reports whether progress shows its goal claimed. Pass the displayed
+// progress, so a rotating goal whose period has ended counts as unclaimed.
+func IsClaimed(progress *domain.UserGoalProgress) bool {
+	return progress != nil && progress.Status == domain.GoalStatusClaimed
+}
+
+// WithoutClaimedGoals returns goalIDs without the goals progress shows claimed,
+// for GetUserChallenges with exclude_claimed. goalIDs is not modified.
+func WithoutClaimedGoals(goalIDs []string, progress map[string]*domain.UserGoalProgress) []string {
+	kept := make([]string, 0, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if !IsClaimed(progress[goalID]) {
+			kept = append(kept, goalID)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithoutClaimedGoals(t *testing.T) {
+	goalIDs := []string{"claimed", "completed", "no-progress", "in-progress"}
+	progress := map[string]*domain.UserGoalProgress{
+		"claimed":     {GoalID: "claimed", Status: domain.GoalStatusClaimed},
+		"completed":   {GoalID: "completed", Status: domain.GoalStatusCompleted},
+		"in-progress": {GoalID: "in-progress", Status: domain.GoalStatusInProgress},
+	}
+
+	assert.Equal(t, []string{"completed", "no-progress", "in-progress"}, WithoutClaimedGoals(goalIDs, progress))
+	assert.Equal(t, []string{"claimed", "completed", "no-progress", "in-progress"}, goalIDs, "input is not modified")
+	assert.Empty(t, WithoutClaimedGoals(nil, progress))
+}
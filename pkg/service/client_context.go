@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+const (
+	// MaxClientContextKeys is the most keys a request's client context may have.
+	MaxClientContextKeys = 5
+
+	// MaxClientContextBytes is the longest key or value of a client context, in bytes.
+	MaxClientContextBytes = 64
+)
+
+// ClientContext is analytics metadata a client sends with claims and
+// selections, e.g. the UI surface the player used. It is stored with the
+// selection event and the claim's outbox entry and published on the domain
+// events, but never changes what the request does.
+type ClientContext map[string]string
+
+// Validate checks the limits of a client context; an empty one is valid.
+func (c ClientContext) Validate() error {
+	if len(c) > MaxClientContextKeys {
+		return fmt.Errorf("client_context has %d keys, at most %d are allowed", len(c), MaxClientContextKeys)
+	}
+
+	// Sorted, so the same context always reports the same key
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case key == "":
+			return fmt.Errorf("client_context keys cannot be empty")
+		case len(key) > MaxClientContextBytes:
+			return fmt.Errorf("client_context key %.16q... is longer than %d bytes", key, MaxClientContextBytes)
+		case len(c[key]) > MaxClientContextBytes:
+			return fmt.Errorf("client_context value of %q is longer than %d bytes", key, MaxClientContextBytes)
+		}
+	}
+	return nil
+}
+
+type clientContextKey struct{}
+
+// WithClientContext returns a context whose claims and selections record
+// clientContext. An empty client context leaves ctx unchanged.
+func WithClientContext(ctx context.Context, clientContext ClientContext) context.Context {
+	if len(clientContext) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, clientContextKey{}, clientContext)
+}
+
+// clientContextFrom returns the client context set by WithClientContext, or nil.
+func clientContextFrom(ctx context.Context) ClientContext {
+	clientContext, _ := ctx.Value(clientContextKey{}).(ClientContext)
+	return clientContext
+}
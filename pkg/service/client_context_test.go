@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientContext_Validate(t *testing.T) {
+	full := ClientContext{}
+	for i := 0; i < MaxClientContextKeys; i++ {
+		full[fmt.Sprintf("key-%d", i)] = strings.Repeat("v", MaxClientContextBytes)
+	}
+	assert.NoError(t, full.Validate())
+	assert.NoError(t, ClientContext(nil).Validate())
+	assert.NoError(t, ClientContext{strings.Repeat("k", MaxClientContextBytes): ""}.Validate())
+
+	tooMany := ClientContext{"extra": "v"}
+	for key, value := range full {
+		tooMany[key] = value
+	}
+
+	tests := map[string]ClientContext{
+		"too many keys":  tooMany,
+		"empty key":      {"": "v"},
+		"long key":       {strings.Repeat("k", MaxClientContextBytes+1): "v"},
+		"long value":     {"surface": strings.Repeat("v", MaxClientContextBytes+1)},
+		"multibyte long": {"surface": strings.Repeat("é", MaxClientContextBytes/2+1)},
+	}
+	for name, clientContext := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, clientContext.Validate())
+		})
+	}
+}
+
+func TestWithClientContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithClientContext(ctx, nil))
+	assert.Nil(t, clientContextFrom(ctx))
+
+	clientContext := ClientContext{"surface": "menu"}
+	assert.Equal(t, clientContext, clientContextFrom(WithClientContext(ctx, clientContext)))
+}
+
+func TestClaimGoalReward_RecordsClientContext(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	outbox := new(mocks.ClaimOutboxRepository)
+
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(createCompletedProgress("user123", "goal-1", "challenge-1"), nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+	outbox.On("Reserve", mock.Anything, mock.MatchedBy(func(entry *serviceRepo.ClaimOutboxEntry) bool {
+		return entry.ClientContext["surface"] == "end_of_match"
+	})).Return(true, nil)
+	outbox.On("MarkGranted", mock.Anything, "user123", "goal-1").Return(nil)
+	outbox.On("Delete", mock.Anything, "user123", "goal-1").Return(nil)
+
+	ctx := WithClientContext(context.Background(), ClientContext{"surface": "end_of_match"})
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox,
+		0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, goal.Reward, result.Reward, "the client context does not change the claim")
+	outbox.AssertExpectations(t)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// ConfigDiff describes what changed between two challenge configurations.
+// Field names follow the challenges.json keys.
+type ConfigDiff struct {
+	ChallengesAdded    []string          `json:"challengesAdded,omitempty"`
+	ChallengesRemoved  []string          `json:"challengesRemoved,omitempty"`
+	ChallengesModified []ChallengeChange `json:"challengesModified,omitempty"`
+	GoalsAdded         []string          `json:"goalsAdded,omitempty"`
+	GoalsRemoved       []string          `json:"goalsRemoved,omitempty"`
+	GoalsModified      []GoalChange      `json:"goalsModified,omitempty"`
+	// Warnings flag changes that affect players or need a restart to apply.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ChallengeChange lists the modified fields of a challenge present in both configs.
+type ChallengeChange struct {
+	ChallengeID string        `json:"challengeId"`
+	Fields      []FieldChange `json:"fields"`
+}
+
+// GoalChange lists the modified fields of a goal present in both configs.
+type GoalChange struct {
+	GoalID      string        `json:"goalId"`
+	ChallengeID string        `json:"challengeId"`
+	Fields      []FieldChange `json:"fields"`
+	// UnclaimedCompleted is the number of players who completed the goal but have
+	// not claimed it yet. Only counted when the reward changed.
+	UnclaimedCompleted int `json:"unclaimedCompleted,omitempty"`
+}
+
+// FieldChange is one modified field, with values rendered as strings.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// RewardChanged reports whether any reward field of the goal changed.
+func (c *GoalChange) RewardChanged() bool {
+	for _, field := range c.Fields {
+		if strings.HasPrefix(field.Field, "reward.") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty reports whether the two configs were identical.
+func (d *ConfigDiff) IsEmpty() bool {
+	return len(d.ChallengesAdded) == 0 && len(d.ChallengesRemoved) == 0 && len(d.ChallengesModified) == 0 &&
+		len(d.GoalsAdded) == 0 && len(d.GoalsRemoved) == 0 && len(d.GoalsModified) == 0
+}
+
+// DiffConfig compares two challenge configurations.
+//
+// Added and modified entries follow the new config order, removed entries the
+// old config order. Goals are matched by goal ID, so a goal moved to another
+// challenge is reported as modified (challengeId), not removed and added.
+func DiffConfig(oldChallenges, newChallenges []*domain.Challenge) *ConfigDiff {
+	diff := &ConfigDiff{}
+
+	oldByID := make(map[string]*domain.Challenge, len(oldChallenges))
+	oldGoals := make(map[string]*domain.Goal)
+	for _, challenge := range oldChallenges {
+		oldByID[challenge.ID] = challenge
+		for _, goal := range challenge.Goals {
+			oldGoals[goal.ID] = goal
+		}
+	}
+
+	newByID := make(map[string]bool, len(newChallenges))
+	newGoals := make(map[string]bool)
+	for _, challenge := range newChallenges {
+		newByID[challenge.ID] = true
+
+		if old, ok := oldByID[challenge.ID]; !ok {
+			diff.ChallengesAdded = append(diff.ChallengesAdded, challenge.ID)
+		} else if fields := diffChallengeFields(old, challenge); len(fields) > 0 {
+			diff.ChallengesModified = append(diff.ChallengesModified, ChallengeChange{ChallengeID: challenge.ID, Fields: fields})
+		}
+
+		for _, goal := range challenge.Goals {
+			newGoals[goal.ID] = true
+
+			if old, ok := oldGoals[goal.ID]; !ok {
+				diff.GoalsAdded = append(diff.GoalsAdded, goal.ID)
+			} else if fields := diffGoalFields(old, goal); len(fields) > 0 {
+				diff.GoalsModified = append(diff.GoalsModified, GoalChange{GoalID: goal.ID, ChallengeID: goal.ChallengeID, Fields: fields})
+			}
+		}
+	}
+
+	for _, challenge := range oldChallenges {
+		if !newByID[challenge.ID] {
+			diff.ChallengesRemoved = append(diff.ChallengesRemoved, challenge.ID)
+		}
+		for _, goal := range challenge.Goals {
+			if !newGoals[goal.ID] {
+				diff.GoalsRemoved = append(diff.GoalsRemoved, goal.ID)
+			}
+		}
+	}
+
+	return diff
+}
+
+func diffChallengeFields(old, updated *domain.Challenge) []FieldChange {
+	var fields []FieldChange
+	fields = appendFieldChange(fields, "name", old.Name, updated.Name)
+	fields = appendFieldChange(fields, "description", old.Description, updated.Description)
+	return fields
+}
+
+func diffGoalFields(old, updated *domain.Goal) []FieldChange {
+	var fields []FieldChange
+	fields = appendFieldChange(fields, "name", old.Name, updated.Name)
+	fields = appendFieldChange(fields, "description", old.Description, updated.Description)
+	fields = appendFieldChange(fields, "challengeId", old.ChallengeID, updated.ChallengeID)
+	fields = appendFieldChange(fields, "eventSource", string(old.EventSource), string(updated.EventSource))
+	fields = appendFieldChange(fields, "defaultAssigned", strconv.FormatBool(old.DefaultAssigned), strconv.FormatBool(updated.DefaultAssigned))
+	fields = appendFieldChange(fields, "requirement.statCode", old.Requirement.StatCode, updated.Requirement.StatCode)
+	fields = appendFieldChange(fields, "requirement.operator", old.Requirement.Operator, updated.Requirement.Operator)
+	fields = appendFieldChange(fields, "requirement.targetValue", strconv.Itoa(old.Requirement.TargetValue), strconv.Itoa(updated.Requirement.TargetValue))
+	fields = appendFieldChange(fields, "requirement.progressMode", string(old.Requirement.ProgressMode), string(updated.Requirement.ProgressMode))
+	fields = appendFieldChange(fields, "reward.type", old.Reward.Type, updated.Reward.Type)
+	fields = appendFieldChange(fields, "reward.rewardId", old.Reward.RewardID, updated.Reward.RewardID)
+	fields = appendFieldChange(fields, "reward.quantity", strconv.Itoa(old.Reward.Quantity), strconv.Itoa(updated.Reward.Quantity))
+	fields = appendFieldChange(fields, "prerequisites", strings.Join(old.Prerequisites, ","), strings.Join(updated.Prerequisites, ","))
+	fields = appendFieldChange(fields, "rotation", rotationString(old.Rotation), rotationString(updated.Rotation))
+	return fields
+}
+
+func appendFieldChange(fields []FieldChange, name, old, updated string) []FieldChange {
+	if old == updated {
+		return fields
+	}
+	return append(fields, FieldChange{Field: name, Old: old, New: updated})
+}
+
+// rotationString renders a rotation config as compact JSON ("" when unset).
+func rotationString(rotation *domain.RotationConfig) string {
+	if rotation == nil {
+		return ""
+	}
+	data, err := json.Marshal(rotation)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
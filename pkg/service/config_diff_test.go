@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffGoal(id, challengeID string, quantity int) *domain.Goal {
+	return &domain.Goal{
+		ID:          id,
+		Name:        "Goal " + id,
+		ChallengeID: challengeID,
+		EventSource: domain.EventSourceStatistic,
+		Requirement: domain.Requirement{StatCode: "kills", Operator: ">=", TargetValue: 10},
+		Reward:      domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: quantity},
+	}
+}
+
+func TestDiffConfig_NoChanges(t *testing.T) {
+	challenges := []*domain.Challenge{{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}}}
+
+	diff := DiffConfig(challenges, challenges)
+
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestDiffConfig_AddedRemovedModified(t *testing.T) {
+	oldConfig := []*domain.Challenge{
+		{ID: "c1", Name: "Old name", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100), newDiffGoal("g2", "c1", 50)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g3", "c2", 10)}},
+	}
+
+	changed := newDiffGoal("g1", "c1", 200)
+	changed.Requirement.TargetValue = 20
+	changed.Prerequisites = []string{"g4"}
+	newConfig := []*domain.Challenge{
+		{ID: "c1", Name: "New name", Goals: []*domain.Goal{changed, newDiffGoal("g4", "c1", 5)}},
+		{ID: "c3", Name: "C3", Goals: []*domain.Goal{newDiffGoal("g5", "c3", 1)}},
+	}
+
+	diff := DiffConfig(oldConfig, newConfig)
+
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{"c3"}, diff.ChallengesAdded)
+	assert.Equal(t, []string{"c2"}, diff.ChallengesRemoved)
+	assert.Equal(t, []ChallengeChange{{ChallengeID: "c1", Fields: []FieldChange{{Field: "name", Old: "Old name", New: "New name"}}}}, diff.ChallengesModified)
+	assert.Equal(t, []string{"g4", "g5"}, diff.GoalsAdded)
+	assert.Equal(t, []string{"g2", "g3"}, diff.GoalsRemoved)
+
+	require.Len(t, diff.GoalsModified, 1)
+	change := diff.GoalsModified[0]
+	assert.Equal(t, "g1", change.GoalID)
+	assert.Equal(t, []FieldChange{
+		{Field: "requirement.targetValue", Old: "10", New: "20"},
+		{Field: "reward.quantity", Old: "100", New: "200"},
+		{Field: "prerequisites", Old: "", New: "g4"},
+	}, change.Fields)
+	assert.True(t, change.RewardChanged())
+}
+
+func TestDiffConfig_GoalMovedBetweenChallenges(t *testing.T) {
+	oldConfig := []*domain.Challenge{
+		{ID: "c1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+		{ID: "c2"},
+	}
+	newConfig := []*domain.Challenge{
+		{ID: "c1"},
+		{ID: "c2", Goals: []*domain.Goal{newDiffGoal("g1", "c2", 100)}},
+	}
+
+	diff := DiffConfig(oldConfig, newConfig)
+
+	assert.Empty(t, diff.GoalsAdded)
+	assert.Empty(t, diff.GoalsRemoved)
+	require.Len(t, diff.GoalsModified, 1)
+	assert.Equal(t, []FieldChange{{Field: "challengeId", Old: "c1", New: "c2"}}, diff.GoalsModified[0].Fields)
+	assert.False(t, diff.GoalsModified[0].RewardChanged())
+}
+
+func TestDiffConfig_RotationChange(t *testing.T) {
+	oldGoal := newDiffGoal("g1", "c1", 100)
+	newGoal := newDiffGoal("g1", "c1", 100)
+	newGoal.Rotation = &domain.RotationConfig{Enabled: true, Type: domain.RotationTypeGlobal, Schedule: domain.RotationScheduleDaily}
+
+	diff := DiffConfig(
+		[]*domain.Challenge{{ID: "c1", Goals: []*domain.Goal{oldGoal}}},
+		[]*domain.Challenge{{ID: "c1", Goals: []*domain.Goal{newGoal}}},
+	)
+
+	require.Len(t, diff.GoalsModified, 1)
+	field := diff.GoalsModified[0].Fields[0]
+	assert.Equal(t, "rotation", field.Field)
+	assert.Empty(t, field.Old)
+	assert.Contains(t, field.New, `"schedule":"daily"`)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+
+	"extend-challenge-service/pkg/common"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultConfigFallbackCachePath is where the last-known-good challenge config is kept.
+const DefaultConfigFallbackCachePath = "/tmp/challenge-config/last-good.json"
+
+// configFallbackHashSuffix names the file holding the SHA-256 of the cached config.
+const configFallbackHashSuffix = ".sha256"
+
+// ConfigFallback keeps a copy of the last challenge config that loaded
+// successfully, so a pod can still boot when the config file it is given is
+// corrupt (e.g. a bad ConfigMap rollout).
+//
+// The copy is refreshed after every successful load whether or not the fallback
+// is enabled. Booting from it is opt-in: the copy may predate config changes
+// the operator intended to ship, so it is only used when Enabled.
+type ConfigFallback struct {
+	enabled   bool
+	cachePath string
+	logger    logrus.FieldLogger
+
+	active   atomic.Bool
+	fallback prometheus.Counter
+	gauge    prometheus.Gauge
+}
+
+// NewConfigFallback creates a config fallback that caches to cachePath and logs
+// through logger.
+func NewConfigFallback(enabled bool, cachePath string, logger logrus.FieldLogger) *ConfigFallback {
+	return &ConfigFallback{
+		enabled:   enabled,
+		cachePath: cachePath,
+		logger:    logger,
+		fallback: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "config_fallback_boots_total",
+			Help: "Startups that loaded the last-known-good challenge config because the configured file failed to load.",
+		}),
+		gauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "config_fallback_active",
+			Help: "1 while the service is running on the last-known-good challenge config.",
+		}),
+	}
+}
+
+// NewConfigFallbackFromEnv creates a config fallback configured by
+// CONFIG_FALLBACK: whether to boot from the cached config when the configured
+// file fails to load (default false), and CONFIG_FALLBACK_CACHE_PATH: where the
+// cached config is kept (default DefaultConfigFallbackCachePath).
+func NewConfigFallbackFromEnv(logger logrus.FieldLogger) *ConfigFallback {
+	enabled := false
+	if value := common.GetEnv("CONFIG_FALLBACK", ""); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			logger.Warnf("Invalid CONFIG_FALLBACK %q, fallback disabled", value)
+		} else {
+			enabled = parsed
+		}
+	}
+
+	return NewConfigFallback(enabled, common.GetEnv("CONFIG_FALLBACK_CACHE_PATH", DefaultConfigFallbackCachePath), logger)
+}
+
+// Enabled reports whether startup may fall back to the cached config.
+func (f *ConfigFallback) Enabled() bool {
+	return f.enabled
+}
+
+// CachePath returns where the cached config is kept.
+func (f *ConfigFallback) CachePath() string {
+	return f.cachePath
+}
+
+// LastKnownGood returns the path of the cached config, the last one that
+// loaded successfully, and whether it is present and matches its hash.
+func (f *ConfigFallback) LastKnownGood() (string, bool) {
+	if f.verify() != nil {
+		return "", false
+	}
+	return f.cachePath, true
+}
+
+// Active reports whether the service booted from the cached config and the
+// configured file has not loaded successfully since.
+func (f *ConfigFallback) Active() bool {
+	return f.active.Load()
+}
+
+// Collectors returns the fallback metrics for registration.
+func (f *ConfigFallback) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{f.fallback, f.gauge}
+}
+
+// Load runs load against configPath and caches the file when it succeeds. When
+// it fails and the fallback is enabled, load is run against the cached config
+// instead and the fallback becomes active. The returned error is the original
+// failure, joined with the cache's when the fallback cannot be used either.
+//
+// load must read everything it needs from the path it is given.
+func (f *ConfigFallback) Load(configPath string, load func(path string) error) error {
+	loadErr := load(configPath)
+	if loadErr == nil {
+		if err := f.Save(configPath); err != nil {
+			f.logger.WithError(err).Warn("Failed to cache challenge config as last-known-good")
+		}
+		return nil
+	}
+
+	if !f.enabled {
+		return loadErr
+	}
+
+	if err := f.verify(); err != nil {
+		return errors.Join(loadErr, fmt.Errorf("no usable last-known-good config: %w", err))
+	}
+	if err := load(f.cachePath); err != nil {
+		return errors.Join(loadErr, fmt.Errorf("failed to load last-known-good config: %w", err))
+	}
+
+	f.fallback.Inc()
+	f.setActive(true)
+	f.logger.WithFields(logrus.Fields{
+		"config_path": configPath,
+		"cache_path":  f.cachePath,
+		"error":       loadErr,
+	}).Error("CHALLENGE CONFIG FAILED TO LOAD: running on the last-known-good config. Fix the config file and reload or restart")
+
+	return nil
+}
+
+// Save copies the config file at configPath to the cache along with its hash.
+// The files are replaced atomically so a crash never leaves a torn copy.
+func (f *ConfigFallback) Save(configPath string) error {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.cachePath), 0o750); err != nil {
+		return fmt.Errorf("failed to create config cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	// The hash goes first: a config without a matching hash is rejected, never used
+	if err := writeFileAtomic(f.cachePath+configFallbackHashSuffix, []byte(hex.EncodeToString(sum[:]))); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(f.cachePath, data)
+}
+
+// Recovered clears the fallback state after the configured file loaded
+// successfully, e.g. through an admin reload.
+func (f *ConfigFallback) Recovered() {
+	if f.active.Load() {
+		f.logger.Info("Challenge config loaded successfully; no longer running on the last-known-good config")
+	}
+	f.setActive(false)
+}
+
+// Check is a health check that fails while the fallback is active. Register it
+// as a non-critical component so the service reports degraded, not down.
+func (f *ConfigFallback) Check(_ context.Context) error {
+	if f.Active() {
+		return fmt.Errorf("running on fallback config %s", f.cachePath)
+	}
+	return nil
+}
+
+// verify checks the cached config against its hash.
+func (f *ConfigFallback) verify() error {
+	data, err := os.ReadFile(f.cachePath)
+	if err != nil {
+		return err
+	}
+
+	want, err := os.ReadFile(f.cachePath + configFallbackHashSuffix)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if !bytes.Equal(bytes.TrimSpace(want), []byte(hex.EncodeToString(sum[:]))) {
+		return fmt.Errorf("cached config %s does not match its hash", f.cachePath)
+	}
+
+	return nil
+}
+
+func (f *ConfigFallback) setActive(active bool) {
+	f.active.Store(active)
+	if active {
+		f.gauge.Set(1)
+	} else {
+		f.gauge.Set(0)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFallbackTestConfig loads a challenge config like main does at startup and
+// records the path it was read from.
+func loadFallbackTestConfig(loaded *string) func(path string) error {
+	return func(path string) error {
+		if _, err := config.NewConfigLoader(path, slog.Default()).LoadConfig(); err != nil {
+			return err
+		}
+		if _, err := LoadHiddenGoals(path); err != nil {
+			return err
+		}
+		*loaded = path
+		return nil
+	}
+}
+
+func newTestConfigFallback(t *testing.T) (*ConfigFallback, string) {
+	t.Helper()
+	dir := t.TempDir()
+	return NewConfigFallback(true, filepath.Join(dir, "cache", "last-good.json"), logrus.StandardLogger()), filepath.Join(dir, "challenges.json")
+}
+
+func TestConfigFallback_Load_ValidFileRefreshesCache(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	})
+
+	var loaded string
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+	assert.Equal(t, path, loaded)
+
+	// A newer valid file replaces the cached copy
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g2", "c1", 100)}},
+	})
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	cached, err := os.ReadFile(fallback.CachePath())
+	require.NoError(t, err)
+	assert.Equal(t, want, cached)
+	assert.NoError(t, fallback.verify())
+	assert.False(t, fallback.Active())
+	assert.NoError(t, fallback.Check(context.Background()))
+	assert.Equal(t, 0.0, testutil.ToFloat64(fallback.fallback))
+}
+
+func TestConfigFallback_Load_CorruptFileWithCache(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	})
+	var loaded string
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [`), 0o600))
+
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+	assert.Equal(t, fallback.CachePath(), loaded)
+	assert.True(t, fallback.Active())
+	assert.ErrorContains(t, fallback.Check(context.Background()), "running on fallback config")
+	assert.Equal(t, 1.0, testutil.ToFloat64(fallback.fallback))
+	assert.Equal(t, 1.0, testutil.ToFloat64(fallback.gauge))
+
+	// The corrupt file never overwrites the cache
+	assert.NoError(t, fallback.verify())
+}
+
+func TestConfigFallback_Load_CorruptFileWithoutCache(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [`), 0o600))
+
+	var loaded string
+	err := fallback.Load(path, loadFallbackTestConfig(&loaded))
+
+	assert.ErrorContains(t, err, "no usable last-known-good config")
+	assert.Empty(t, loaded)
+	assert.False(t, fallback.Active())
+	assert.Equal(t, 0.0, testutil.ToFloat64(fallback.fallback))
+}
+
+func TestConfigFallback_Load_CacheHashMismatch(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	})
+	var loaded string
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+
+	writeReloadConfig(t, fallback.CachePath(), []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("tampered", "c1", 100)}},
+	})
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+
+	err := fallback.Load(path, loadFallbackTestConfig(&loaded))
+
+	assert.ErrorContains(t, err, "does not match its hash")
+	assert.False(t, fallback.Active())
+}
+
+func TestConfigFallback_Load_Disabled(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+	fallback.enabled = false
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	})
+	var loaded string
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+	assert.NoError(t, fallback.verify(), "the cache is kept even when the fallback is disabled")
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+
+	assert.Error(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+	assert.False(t, fallback.Active())
+}
+
+func TestConfigFallback_LastKnownGood(t *testing.T) {
+	fallback, path := newTestConfigFallback(t)
+
+	_, ok := fallback.LastKnownGood()
+	assert.False(t, ok, "nothing cached yet")
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	})
+	var loaded string
+	require.NoError(t, fallback.Load(path, loadFallbackTestConfig(&loaded)))
+
+	previous, ok := fallback.LastKnownGood()
+	assert.True(t, ok)
+	assert.Equal(t, fallback.CachePath(), previous)
+
+	require.NoError(t, os.WriteFile(fallback.CachePath(), []byte(`{"challenges": []}`), 0o600))
+	_, ok = fallback.LastKnownGood()
+	assert.False(t, ok, "a cache not matching its hash is not used")
+}
+
+func TestNewConfigFallbackFromEnv(t *testing.T) {
+	t.Setenv("CONFIG_FALLBACK", "true")
+	t.Setenv("CONFIG_FALLBACK_CACHE_PATH", "/var/cache/challenges.json")
+
+	fallback := NewConfigFallbackFromEnv(logrus.StandardLogger())
+
+	assert.True(t, fallback.Enabled())
+	assert.Equal(t, "/var/cache/challenges.json", fallback.CachePath())
+
+	t.Setenv("CONFIG_FALLBACK", "maybe")
+	assert.False(t, NewConfigFallbackFromEnv(logrus.StandardLogger()).Enabled())
+}
+
+func TestConfigReloader_Reload_EndsFallback(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, new(mocks.ProgressQueryRepository))
+	fallback := NewConfigFallback(true, filepath.Join(t.TempDir(), "last-good.json"), logrus.StandardLogger())
+	fallback.setActive(true)
+	reloader.SetFallback(fallback)
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g2", "c1", 100)}},
+	})
+
+	_, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, fallback.Active())
+	assert.NoError(t, fallback.Check(context.Background()))
+	assert.NoError(t, fallback.verify(), "the reloaded file is cached")
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": []}`), 0o600))
+
+	version, err := ConfigVersion(path)
+	require.NoError(t, err)
+	assert.Len(t, version, configVersionLength)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [] }`), 0o600))
+	changed, err := ConfigVersion(path)
+	require.NoError(t, err)
+	assert.NotEqual(t, version, changed)
+
+	_, err = ConfigVersion(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestConfigInfo_UpdatedByReload(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	// Startup, as in main
+	info := NewConfigInfo(logrus.StandardLogger())
+	info.now = func() time.Time { return time.Unix(1000, 0) }
+	info.Update(path, reloader.goalCache.GetAllChallenges(), 1234)
+	reloader.SetConfigInfo(info)
+
+	startupVersion, err := ConfigVersion(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.info.WithLabelValues(startupVersion, path)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.challenges))
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.goals))
+	assert.Equal(t, 1234.0, testutil.ToFloat64(info.cacheBytes))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(info.lastReload))
+	assert.Equal(t, startupVersion, info.Version())
+	assert.Equal(t, path, info.Path())
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100), newDiffGoal("g2", "c1", 10)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g3", "c2", 5)}},
+	})
+	info.now = func() time.Time { return time.Unix(2000, 0) }
+
+	_, err = reloader.Reload(context.Background())
+	require.NoError(t, err)
+
+	reloadedVersion, err := ConfigVersion(path)
+	require.NoError(t, err)
+	require.NotEqual(t, startupVersion, reloadedVersion)
+	assert.Equal(t, 1, testutil.CollectAndCount(info.info), "only the loaded version is reported")
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.info.WithLabelValues(reloadedVersion, path)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(info.challenges))
+	assert.Equal(t, 3.0, testutil.ToFloat64(info.goals))
+	assert.Equal(t, float64(reloader.serCache.GetStats().TotalBytes), testutil.ToFloat64(info.cacheBytes))
+	assert.Equal(t, 2000.0, testutil.ToFloat64(info.lastReload))
+	assert.Equal(t, reloadedVersion, info.Version())
+}
+
+func TestConfigInfo_FailedReloadKeepsValues(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	info := NewConfigInfo(logrus.StandardLogger())
+	info.Update(path, reloader.goalCache.GetAllChallenges(), 1234)
+	reloader.SetConfigInfo(info)
+	version, err := ConfigVersion(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": []}`), 0o600))
+	_, err = reloader.Reload(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.info.WithLabelValues(version, path)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(info.goals))
+	assert.Equal(t, version, info.Version())
+}
+
+func TestConfigInfo_Nil(t *testing.T) {
+	var info *ConfigInfo
+	assert.NotPanics(t, func() { info.Update("missing.json", nil, 0) })
+	assert.Empty(t, info.Version())
+	assert.Empty(t, info.Path())
+	assert.Empty(t, NewConfigInfo(logrus.StandardLogger()).Version())
+}
+
+func TestConfigInfo_UpdateUnreadableConfig(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	info := NewConfigInfo(logger)
+
+	info.Update(filepath.Join(t.TempDir(), "missing.json"), nil, 0)
+
+	assert.Equal(t, ConfigVersionUnknown, info.Version())
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Failed to compute challenge config version", hook.LastEntry().Message)
+}
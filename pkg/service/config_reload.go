@@ -0,0 +1,569 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	serviceCache "extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// unclaimedCountTimeout bounds the unclaimed-progress count run for reward
+// changes; the query scans user_goal_progress.
+const unclaimedCountTimeout = 10 * time.Second
+
+// ConfigReloader reloads the challenge config at runtime and reports what changed.
+//
+// Reload swaps the GoalCache (extend-challenge-common), refreshes the goal
+// snapshots and the serialization cache and diffs the configs before and after the swap.
+// Reloads are serialized, so each diff covers exactly one swap.
+type ConfigReloader struct {
+	goalCache   cache.GoalCache
+	serCache    *serviceCache.SerializedChallengeCache
+	queries     serviceRepo.ProgressQueryRepository
+	namespace   string
+	configPath  string
+	hiddenGoals HiddenGoals
+	inactive    InactiveProgressPolicy
+	prereqs     ChallengePrerequisites
+	targets     TargetOverrides
+	matchGoals  MatchGoals
+	goalSteps   GoalSteps
+	rewardCaps  RewardCaps
+	deliveries  *RewardDeliveries
+	autoExpire  AutoDeactivation
+	repeatable  RepeatableGoals
+	autoSelect  AutoRandomSelections
+	rollouts    ChallengeRollouts
+	fallback    *ConfigFallback
+	info        *ConfigInfo
+	snapshots   *serviceCache.GoalSnapshots
+	logger      logrus.FieldLogger
+
+	// lastRollouts is the rolloutPercentage of the config last loaded, which
+	// other instances may serve after a restart
+	lastRollouts ChallengeRollouts
+
+	reloads            *prometheus.CounterVec
+	challengesAdded    prometheus.Counter
+	challengesRemoved  prometheus.Counter
+	challengesModified prometheus.Counter
+	goalsAdded         prometheus.Counter
+	goalsRemoved       prometheus.Counter
+	goalsModified      prometheus.Counter
+	rewardWarnings     prometheus.Counter
+
+	mu sync.Mutex
+}
+
+// NewConfigReloader creates a config reloader.
+//
+// hiddenGoals, inactive and prereqs are the sets loaded at startup; they are compared with the
+// reloaded file only to warn, since handlers keep using the startup sets until restart.
+// Reloads are logged through logger.
+func NewConfigReloader(
+	goalCache cache.GoalCache,
+	serCache *serviceCache.SerializedChallengeCache,
+	queries serviceRepo.ProgressQueryRepository,
+	namespace string,
+	configPath string,
+	hiddenGoals HiddenGoals,
+	inactive InactiveProgressPolicy,
+	prereqs ChallengePrerequisites,
+	logger logrus.FieldLogger,
+) *ConfigReloader {
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: help})
+	}
+
+	return &ConfigReloader{
+		goalCache:   goalCache,
+		serCache:    serCache,
+		queries:     queries,
+		namespace:   namespace,
+		configPath:  configPath,
+		hiddenGoals: hiddenGoals,
+		inactive:    inactive,
+		prereqs:     prereqs,
+		logger:      logger,
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "config_reloads_total",
+			Help: "Challenge config reloads by result (success, error).",
+		}, []string{"result"}),
+		challengesAdded:    counter("config_challenges_added_total", "Challenges added by config reloads."),
+		challengesRemoved:  counter("config_challenges_removed_total", "Challenges removed by config reloads."),
+		challengesModified: counter("config_challenges_modified_total", "Challenges modified by config reloads."),
+		goalsAdded:         counter("config_goals_added_total", "Goals added by config reloads."),
+		goalsRemoved:       counter("config_goals_removed_total", "Goals removed by config reloads."),
+		goalsModified:      counter("config_goals_modified_total", "Goals modified by config reloads."),
+		rewardWarnings: counter("config_reward_changes_unclaimed_total",
+			"Goal reward changes made while players had completed but unclaimed progress."),
+	}
+}
+
+// SetFallback sets the config fallback refreshed by successful reloads. A
+// successful reload also ends a startup fallback, since the configured file
+// loads again. It must be called before the server starts serving.
+func (r *ConfigReloader) SetFallback(fallback *ConfigFallback) {
+	r.fallback = fallback
+}
+
+// SetConfigInfo sets the config info metrics updated by successful reloads.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetConfigInfo(info *ConfigInfo) {
+	r.info = info
+}
+
+// SetGoalSnapshots sets the snapshot views of the goal cache, refreshed by every
+// successful reload. It must be called before the server starts serving.
+func (r *ConfigReloader) SetGoalSnapshots(snapshots *serviceCache.GoalSnapshots) {
+	r.snapshots = snapshots
+}
+
+// SetTargetOverrides sets the per-segment goal targets loaded at startup. Like
+// the other startup sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetTargetOverrides(targets TargetOverrides) {
+	r.targets = targets
+}
+
+// SetMatchGoals sets the match goals loaded at startup. Like the other startup
+// sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetMatchGoals(goals MatchGoals) {
+	r.matchGoals = goals
+}
+
+// SetGoalSteps sets the multi-step goals loaded at startup. Like the other
+// startup sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetGoalSteps(steps GoalSteps) {
+	r.goalSteps = steps
+}
+
+// SetRewardCaps sets the reward caps loaded at startup. Like the other startup
+// sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetRewardCaps(caps RewardCaps) {
+	r.rewardCaps = caps
+}
+
+// SetRewardDeliveries sets the reward deliveries loaded at startup. Like the
+// other startup sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetRewardDeliveries(deliveries *RewardDeliveries) {
+	r.deliveries = deliveries
+}
+
+// SetAutoDeactivation sets the challenges' autoDeactivateAfterDays loaded at
+// startup. Like the other startup sets they are only compared with the reloaded
+// file to warn. It must be called before the server starts serving.
+func (r *ConfigReloader) SetAutoDeactivation(policy AutoDeactivation) {
+	r.autoExpire = policy
+}
+
+// SetRepeatableGoals sets the repeatable goals loaded at startup. Like the
+// other startup sets they are only compared with the reloaded file to warn.
+// It must be called before the server starts serving.
+func (r *ConfigReloader) SetRepeatableGoals(goals RepeatableGoals) {
+	r.repeatable = goals
+}
+
+// SetAutoRandomSelections sets the challenges' autoRandomSelect loaded at
+// startup. Like the other startup sets they are only compared with the reloaded
+// file to warn. It must be called before the server starts serving.
+func (r *ConfigReloader) SetAutoRandomSelections(selections AutoRandomSelections) {
+	r.autoSelect = selections
+}
+
+// SetChallengeRollouts sets the challenges' rolloutPercentage loaded at
+// startup. Like the other startup sets they are compared with the reloaded
+// file to warn; a reloaded file that lowers a percentage is also rejected (see
+// CheckRolloutsNotLowered). It must be called before the server starts serving.
+func (r *ConfigReloader) SetChallengeRollouts(rollouts ChallengeRollouts) {
+	r.rollouts = rollouts
+	r.lastRollouts = rollouts
+}
+
+// Collectors returns the reload metrics for registration.
+func (r *ConfigReloader) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		r.reloads,
+		r.challengesAdded, r.challengesRemoved, r.challengesModified,
+		r.goalsAdded, r.goalsRemoved, r.goalsModified,
+		r.rewardWarnings,
+	}
+}
+
+// Reload re-reads the challenge config file and returns the diff against the
+// previous config. If the file fails to load or validate, the current config
+// stays in place and an error is returned.
+func (r *ConfigReloader) Reload(ctx context.Context) (*ConfigDiff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oldChallenges := r.goalCache.GetAllChallenges()
+	if err := CheckConfigSchema(r.configPath); err != nil {
+		r.reloads.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("failed to reload challenge config: %w", err)
+	}
+	if err := CheckGoalIDCollisions(r.configPath); err != nil {
+		r.reloads.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("failed to reload challenge config: %w", err)
+	}
+	rollouts, err := r.loadRollouts(oldChallenges)
+	if err != nil {
+		r.reloads.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("failed to reload challenge config: %w", err)
+	}
+	if err := r.goalCache.Reload(); err != nil {
+		r.reloads.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("failed to reload challenge config: %w", err)
+	}
+	r.lastRollouts = rollouts
+	newChallenges := r.goalCache.GetAllChallenges()
+	// Handlers reading snapshot views see the new config from here on
+	if r.snapshots != nil {
+		r.snapshots.Refresh()
+	}
+
+	pbChallenges, err := mapper.ChallengesToProto(newChallenges, nil, nil, time.Now().UTC())
+	if err == nil {
+		err = r.serCache.Refresh(pbChallenges)
+	}
+	if err != nil {
+		r.reloads.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("config reloaded but serialization cache refresh failed: %w", err)
+	}
+
+	diff := DiffConfig(oldChallenges, newChallenges)
+	r.checkHiddenGoals(diff)
+	r.checkInactiveProgressPolicy(diff)
+	r.checkChallengePrerequisites(diff)
+	r.checkTargetOverrides(diff)
+	r.checkMatchGoals(diff)
+	r.checkGoalSteps(diff)
+	r.checkRewardCaps(diff)
+	r.checkRewardDeliveries(diff)
+	r.checkAutoDeactivation(diff)
+	r.checkRepeatableGoals(diff)
+	r.checkAutoRandomSelections(diff)
+	r.checkChallengeRollouts(diff)
+	r.checkRewardChanges(ctx, diff)
+	r.record(diff)
+	r.info.Update(r.configPath, newChallenges, r.serCache.GetStats().TotalBytes)
+
+	if r.fallback != nil {
+		if err := r.fallback.Save(r.configPath); err != nil {
+			r.logger.WithError(err).Warn("Failed to cache reloaded challenge config as last-known-good")
+		}
+		r.fallback.Recovered()
+	}
+
+	return diff, nil
+}
+
+// loadRollouts reads the reloaded file's rolloutPercentage and rejects it when
+// it lowers the percentage of a challenge of current, the config being
+// replaced.
+func (r *ConfigReloader) loadRollouts(current []*domain.Challenge) (ChallengeRollouts, error) {
+	rollouts, err := LoadChallengeRollouts(r.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeIDs := make([]string, len(current))
+	for i, challenge := range current {
+		challengeIDs[i] = challenge.ID
+	}
+	if err := CheckRolloutsNotLowered(r.lastRollouts, challengeIDs, rollouts); err != nil {
+		return nil, err
+	}
+	return rollouts, nil
+}
+
+// checkHiddenGoals warns when hidden flags in the reloaded file differ from the
+// set loaded at startup.
+func (r *ConfigReloader) checkHiddenGoals(diff *ConfigDiff) {
+	hidden, err := LoadHiddenGoals(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare hidden goals: %v", err))
+		return
+	}
+
+	if len(hidden) == 0 && len(r.hiddenGoals) == 0 {
+		return
+	}
+	if !maps.Equal(hidden, r.hiddenGoals) {
+		diff.Warnings = append(diff.Warnings, "hidden goal flags changed; they take effect after a restart")
+	}
+}
+
+// checkInactiveProgressPolicy warns when trackInactiveProgress flags in the reloaded
+// file differ from the set loaded at startup.
+func (r *ConfigReloader) checkInactiveProgressPolicy(diff *ConfigDiff) {
+	policy, err := LoadInactiveProgressPolicy(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare trackInactiveProgress flags: %v", err))
+		return
+	}
+
+	if len(policy) == 0 && len(r.inactive) == 0 {
+		return
+	}
+	if !maps.Equal(policy, r.inactive) {
+		diff.Warnings = append(diff.Warnings, "trackInactiveProgress flags changed; they take effect after a restart")
+	}
+}
+
+// checkChallengePrerequisites warns when prerequisiteChallengeIds in the reloaded
+// file differ from the set loaded at startup, or are no longer valid.
+func (r *ConfigReloader) checkChallengePrerequisites(diff *ConfigDiff) {
+	prereqs, err := LoadChallengePrerequisites(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare prerequisiteChallengeIds: %v", err))
+		return
+	}
+
+	if len(prereqs) == 0 && len(r.prereqs) == 0 {
+		return
+	}
+	if !maps.EqualFunc(prereqs, r.prereqs, slices.Equal[[]string]) {
+		diff.Warnings = append(diff.Warnings, "prerequisiteChallengeIds changed; they take effect after a restart")
+	}
+}
+
+// checkTargetOverrides warns when targetOverrides in the reloaded file differ
+// from the set loaded at startup.
+func (r *ConfigReloader) checkTargetOverrides(diff *ConfigDiff) {
+	targets, err := LoadTargetOverrides(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare targetOverrides: %v", err))
+		return
+	}
+
+	if len(targets) == 0 && len(r.targets) == 0 {
+		return
+	}
+	if !maps.EqualFunc(targets, r.targets, maps.Equal[map[string]int]) {
+		diff.Warnings = append(diff.Warnings, "targetOverrides changed; they take effect after a restart")
+	}
+}
+
+// checkMatchGoals warns when match goals or their matchFilter in the reloaded
+// file differ from the set loaded at startup.
+func (r *ConfigReloader) checkMatchGoals(diff *ConfigDiff) {
+	goals, err := LoadMatchGoals(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare match goals: %v", err))
+		return
+	}
+
+	if !slices.Equal(goals, r.matchGoals) {
+		diff.Warnings = append(diff.Warnings, "match goals or matchFilter changed; they take effect after a restart")
+	}
+}
+
+// checkGoalSteps warns when the requirements of multi-step goals in the
+// reloaded file differ from the set loaded at startup.
+func (r *ConfigReloader) checkGoalSteps(diff *ConfigDiff) {
+	steps, err := LoadGoalSteps(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare goal requirements: %v", err))
+		return
+	}
+
+	if len(steps) == 0 && len(r.goalSteps) == 0 {
+		return
+	}
+	if !maps.EqualFunc(steps, r.goalSteps, slices.Equal[[]GoalStep]) {
+		diff.Warnings = append(diff.Warnings, "multi-step goal requirements changed; they take effect after a restart")
+	}
+}
+
+// checkRewardCaps warns when the reward caps in the reloaded file differ from
+// the set loaded at startup.
+func (r *ConfigReloader) checkRewardCaps(diff *ConfigDiff) {
+	caps, err := LoadRewardCaps(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare reward caps: %v", err))
+		return
+	}
+
+	if len(caps) == 0 && len(r.rewardCaps) == 0 {
+		return
+	}
+	if !maps.EqualFunc(caps, r.rewardCaps, RewardCap.equal) {
+		diff.Warnings = append(diff.Warnings, "rewardCap changed; it takes effect after a restart")
+	}
+}
+
+// checkRewardDeliveries warns when the reward delivery allow-list or the goals'
+// default deliveries in the reloaded file differ from the set loaded at startup.
+func (r *ConfigReloader) checkRewardDeliveries(diff *ConfigDiff) {
+	deliveries, err := LoadRewardDeliveries(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare reward deliveries: %v", err))
+		return
+	}
+
+	if !deliveries.equal(r.deliveries) {
+		diff.Warnings = append(diff.Warnings, "rewardDelivery or goal delivery changed; it takes effect after a restart")
+	}
+}
+
+// checkAutoDeactivation warns when autoDeactivateAfterDays in the reloaded file
+// differs from the set loaded at startup.
+func (r *ConfigReloader) checkAutoDeactivation(diff *ConfigDiff) {
+	policy, err := LoadAutoDeactivation(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare autoDeactivateAfterDays: %v", err))
+		return
+	}
+
+	if len(policy) == 0 && len(r.autoExpire) == 0 {
+		return
+	}
+	if !maps.Equal(policy, r.autoExpire) {
+		diff.Warnings = append(diff.Warnings, "autoDeactivateAfterDays changed; it takes effect after a restart")
+	}
+}
+
+// checkRepeatableGoals warns when the repeatable goals or their cooldowns in the
+// reloaded file differ from the set loaded at startup.
+func (r *ConfigReloader) checkRepeatableGoals(diff *ConfigDiff) {
+	goals, err := LoadRepeatableGoals(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare repeatable goals: %v", err))
+		return
+	}
+
+	if len(goals) == 0 && len(r.repeatable) == 0 {
+		return
+	}
+	if !maps.Equal(goals, r.repeatable) {
+		diff.Warnings = append(diff.Warnings, "repeatable or cooldownHours changed; it takes effect after a restart")
+	}
+}
+
+// checkAutoRandomSelections warns when autoRandomSelect in the reloaded file
+// differs from the set loaded at startup.
+func (r *ConfigReloader) checkAutoRandomSelections(diff *ConfigDiff) {
+	selections, err := LoadAutoRandomSelections(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare autoRandomSelect: %v", err))
+		return
+	}
+
+	if len(selections) == 0 && len(r.autoSelect) == 0 {
+		return
+	}
+	if !maps.Equal(selections, r.autoSelect) {
+		diff.Warnings = append(diff.Warnings, "autoRandomSelect changed; it takes effect after a restart")
+	}
+}
+
+// checkChallengeRollouts warns when rolloutPercentage in the reloaded file
+// differs from the set loaded at startup. A lowered percentage never gets here:
+// Reload rejects it.
+func (r *ConfigReloader) checkChallengeRollouts(diff *ConfigDiff) {
+	rollouts, err := LoadChallengeRollouts(r.configPath)
+	if err != nil {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf("could not compare rolloutPercentage: %v", err))
+		return
+	}
+
+	if len(rollouts) == 0 && len(r.rollouts) == 0 {
+		return
+	}
+	if !maps.Equal(rollouts, r.rollouts) {
+		diff.Warnings = append(diff.Warnings, "rolloutPercentage changed; it takes effect after a restart")
+	}
+}
+
+// checkRewardChanges counts completed-but-unclaimed progress for goals whose
+// reward changed and adds a warning for each one that has any, since those
+// players will receive the new reward when they claim.
+func (r *ConfigReloader) checkRewardChanges(ctx context.Context, diff *ConfigDiff) {
+	var goalIDs []string
+	for i := range diff.GoalsModified {
+		if diff.GoalsModified[i].RewardChanged() {
+			goalIDs = append(goalIDs, diff.GoalsModified[i].GoalID)
+		}
+	}
+	if len(goalIDs) == 0 {
+		return
+	}
+
+	countCtx, cancel := context.WithTimeout(ctx, unclaimedCountTimeout)
+	defer cancel()
+
+	counts, err := r.queries.CountUnclaimedCompleted(countCtx, r.namespace, goalIDs)
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"namespace": r.namespace,
+			"goal_ids":  goalIDs,
+			"error":     err,
+		}).Warn("Failed to count unclaimed progress for goals with changed rewards")
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf(
+			"reward changed for goals %v; unclaimed progress could not be counted", goalIDs))
+		return
+	}
+
+	for i := range diff.GoalsModified {
+		change := &diff.GoalsModified[i]
+		if count := counts[change.GoalID]; count > 0 {
+			change.UnclaimedCompleted = count
+			diff.Warnings = append(diff.Warnings, fmt.Sprintf(
+				"reward changed for goal %s with %d completed but unclaimed players", change.GoalID, count))
+			r.rewardWarnings.Inc()
+		}
+	}
+}
+
+// record updates the metrics and logs the diff.
+func (r *ConfigReloader) record(diff *ConfigDiff) {
+	r.reloads.WithLabelValues("success").Inc()
+	r.challengesAdded.Add(float64(len(diff.ChallengesAdded)))
+	r.challengesRemoved.Add(float64(len(diff.ChallengesRemoved)))
+	r.challengesModified.Add(float64(len(diff.ChallengesModified)))
+	r.goalsAdded.Add(float64(len(diff.GoalsAdded)))
+	r.goalsRemoved.Add(float64(len(diff.GoalsRemoved)))
+	r.goalsModified.Add(float64(len(diff.GoalsModified)))
+
+	// The full diff is logged as one JSON document; counts are separate fields for filtering
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		diffJSON = []byte("{}")
+	}
+	log := r.logger.WithFields(logrus.Fields{
+		"namespace":           r.namespace,
+		"challenges_added":    len(diff.ChallengesAdded),
+		"challenges_removed":  len(diff.ChallengesRemoved),
+		"challenges_modified": len(diff.ChallengesModified),
+		"goals_added":         len(diff.GoalsAdded),
+		"goals_removed":       len(diff.GoalsRemoved),
+		"goals_modified":      len(diff.GoalsModified),
+		"diff":                string(diffJSON),
+	})
+	if diff.IsEmpty() {
+		log.Info("Challenge config reloaded with no changes")
+	} else {
+		log.Info("Challenge config reloaded")
+	}
+
+	for _, warning := range diff.Warnings {
+		r.logger.WithField("namespace", r.namespace).Warn("Challenge config reload: " + warning)
+	}
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	serviceCache "extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func writeReloadConfig(t *testing.T, path string, challenges []*domain.Challenge) {
+	t.Helper()
+	data, err := json.Marshal(&config.Config{Challenges: challenges})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// newTestConfigReloader loads the initial config from a temp file into a real
+// InMemoryGoalCache, like main does at startup.
+func newTestConfigReloader(t *testing.T, challenges []*domain.Challenge, queries *mocks.ProgressQueryRepository) (*ConfigReloader, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	writeReloadConfig(t, path, challenges)
+
+	cfg, err := config.NewConfigLoader(path, slog.Default()).LoadConfig()
+	require.NoError(t, err)
+	goalCache := commonCache.NewInMemoryGoalCache(cfg, path, slog.Default())
+
+	return NewConfigReloader(goalCache, serviceCache.NewSerializedChallengeCache(), queries, "test-namespace", path, nil, nil, nil, logrus.StandardLogger()), path
+}
+
+func TestConfigReloader_Reload_RewardChangeWithUnclaimedProgress(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100), newDiffGoal("g2", "c1", 10)}},
+	}, queries)
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 500), newDiffGoal("g3", "c1", 10)}},
+	})
+	queries.On("CountUnclaimedCompleted", mock.Anything, "test-namespace", []string{"g1"}).Return(map[string]int{"g1": 7}, nil)
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g3"}, diff.GoalsAdded)
+	assert.Equal(t, []string{"g2"}, diff.GoalsRemoved)
+	require.Len(t, diff.GoalsModified, 1)
+	assert.Equal(t, 7, diff.GoalsModified[0].UnclaimedCompleted)
+	require.Len(t, diff.Warnings, 1)
+	assert.Contains(t, diff.Warnings[0], "g1")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.goalsAdded))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.goalsRemoved))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.goalsModified))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.rewardWarnings))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.reloads.WithLabelValues("success")))
+
+	// The serialization cache serves the reloaded config
+	_, ok := reloader.serCache.GetGoalJSON("c1", "g3")
+	assert.True(t, ok)
+	queries.AssertExpectations(t)
+}
+
+func TestConfigReloader_Reload_CountFailureStillWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 1)}},
+	})
+	queries.On("CountUnclaimedCompleted", mock.Anything, "test-namespace", []string{"g1"}).Return(nil, errors.New("timeout"))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, diff.Warnings, 1)
+	assert.Contains(t, diff.Warnings[0], "could not be counted")
+}
+
+func TestConfigReloader_Reload_InvalidConfigKeepsCurrent(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": []}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/challenges: must have at least 1 items")
+	assert.Nil(t, diff)
+	assert.NotNil(t, reloader.goalCache.GetGoalByID("g1"), "current config stays in place")
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.reloads.WithLabelValues("error")))
+	queries.AssertNotCalled(t, "CountUnclaimedCompleted", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfigReloader_Reload_GoalIDCollisionKeepsCurrent(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "daily", Name: "Daily", Goals: []*domain.Goal{newDiffGoal("daily-login", "daily", 1)}},
+	}, queries)
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "daily", Name: "Daily", Goals: []*domain.Goal{newDiffGoal("daily-login", "daily", 1)}},
+		{ID: "weekly", Name: "Weekly", Goals: []*domain.Goal{newDiffGoal("daily-login", "weekly", 7)}},
+	})
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"daily-login" in challenges daily, weekly`)
+	assert.Nil(t, diff)
+	assert.Equal(t, "daily", reloader.goalCache.GetGoalByID("daily-login").ChallengeID, "current config stays in place")
+	assert.Equal(t, 1.0, testutil.ToFloat64(reloader.reloads.WithLabelValues("error")))
+}
+
+func TestConfigReloader_Reload_RefreshesGoalSnapshots(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+	snapshots := serviceCache.NewGoalSnapshots(reloader.goalCache)
+	reloader.SetGoalSnapshots(snapshots)
+
+	// A failed reload keeps the current view
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": []}`), 0o600))
+	_, err := reloader.Reload(context.Background())
+	require.Error(t, err)
+	assert.NotNil(t, snapshots.View().GetGoalByID("g1"))
+
+	writeReloadConfig(t, path, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g2", "c1", 100)}},
+	})
+	_, err = reloader.Reload(context.Background())
+	require.NoError(t, err)
+
+	assert.Nil(t, snapshots.View().GetGoalByID("g1"))
+	assert.NotNil(t, snapshots.View().GetGoalByID("g2"))
+}
+
+func TestConfigReloader_Reload_HiddenFlagChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","goals":[
+		{"goalId":"g1","name":"Goal g1","hidden":true,"eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "hidden goal flags changed; they take effect after a restart")
+}
+
+func TestConfigReloader_Reload_TrackInactiveProgressChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","trackInactiveProgress":false,"goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "trackInactiveProgress flags changed; they take effect after a restart")
+}
+
+func TestConfigReloader_Reload_TargetOverridesChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic","targetOverrides":{"new_player":3},
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "targetOverrides changed; they take effect after a restart")
+}
+
+func TestConfigReloader_Reload_GoalStepsChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "requirements":[
+			{"statCode":"kills","operator":">=","targetValue":10},
+			{"statCode":"wins","operator":">=","targetValue":2}],
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "multi-step goal requirements changed; they take effect after a restart")
+}
+
+func TestConfigReloader_Reload_RewardCapChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+	reloader.SetRewardCaps(RewardCaps{"g1": {Limit: 100}})
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100},
+		 "rewardCap":{"limit":200}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "rewardCap changed; it takes effect after a restart")
+}
+
+func TestConfigReloader_Reload_RewardDeliveryChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rewardDelivery":{"allowedCollectionIds":["season-stash"]},
+		"challenges":[{"challengeId":"c1","name":"C1","goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100},
+		 "delivery":{"collectionId":"season-stash"}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "rewardDelivery or goal delivery changed; it takes effect after a restart")
+}
+
+func TestConfigReloader_Reload_AutoDeactivationChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","autoDeactivateAfterDays":7,"goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "autoDeactivateAfterDays changed; it takes effect after a restart")
+}
+
+func TestConfigReloader_Reload_AutoRandomSelectChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[{"challengeId":"c1","name":"C1","autoRandomSelect":{"count":1},"goals":[
+		{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+		 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+		 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "autoRandomSelect changed; it takes effect after a restart")
+}
+
+func TestConfigReloader_Reload_ChallengeRolloutsChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g2", "c2", 100)}},
+	}, queries)
+	reloader.SetChallengeRollouts(ChallengeRollouts{"c1": 5, "c2": 50})
+
+	// Raising c1 and c2, and adding c3 at any percentage, are accepted
+	require.NoError(t, os.WriteFile(path, []byte(rolloutReloadConfig(10, 60, 5)), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "rolloutPercentage changed; it takes effect after a restart")
+}
+
+func TestConfigReloader_Reload_ChallengeRolloutsLoweredRejected(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g2", "c2", 100)}},
+	}, queries)
+	reloader.SetChallengeRollouts(ChallengeRollouts{"c1": 5, "c2": 50})
+
+	require.NoError(t, os.WriteFile(path, []byte(rolloutReloadConfig(10, 20, 5)), 0o600))
+
+	_, err := reloader.Reload(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `challenge "c2" from 50 to 20`)
+	assert.Nil(t, reloader.goalCache.GetChallengeByChallengeID("c3"), "the current config stays in place")
+
+	// The accepted reload is the new floor: going back to 5 would drop players it added
+	require.NoError(t, os.WriteFile(path, []byte(rolloutReloadConfig(10, 50, 5)), 0o600))
+	_, err = reloader.Reload(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(rolloutReloadConfig(5, 50, 5)), 0o600))
+	_, err = reloader.Reload(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `challenge "c1" from 10 to 5`)
+}
+
+// rolloutReloadConfig returns a config of challenges c1, c2 and c3 with the
+// given rolloutPercentage.
+func rolloutReloadConfig(c1, c2, c3 int) string {
+	config := `{"challenges":[`
+	for i, percentage := range []int{c1, c2, c3} {
+		if i > 0 {
+			config += ","
+		}
+		config += fmt.Sprintf(`
+		{"challengeId":"c%[1]d","name":"C%[1]d","rolloutPercentage":%[2]d,"goals":[
+			{"goalId":"g%[1]d","name":"Goal g%[1]d","eventSource":"statistic",
+			 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+			 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}`, i+1, percentage)
+	}
+	return config + "]}"
+}
+
+func TestConfigReloader_Reload_ChallengePrerequisitesChangeWarns(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g2", "c2", 100)}},
+	}, queries)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges":[
+		{"challengeId":"c1","name":"C1","goals":[
+			{"goalId":"g1","name":"Goal g1","eventSource":"statistic",
+			 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+			 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]},
+		{"challengeId":"c2","name":"C2","prerequisiteChallengeIds":["c1"],"goals":[
+			{"goalId":"g2","name":"Goal g2","eventSource":"statistic",
+			 "requirement":{"statCode":"kills","operator":">=","targetValue":10},
+			 "reward":{"type":"WALLET","rewardId":"GOLD","quantity":100}}]}]}`), 0o600))
+
+	diff, err := reloader.Reload(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, diff.Warnings, "prerequisiteChallengeIds changed; they take effect after a restart")
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// configSchemaJSON is the JSON Schema of challenges.json. Every json field of
+// the structs the config is parsed into must have an entry (see
+// TestConfigSchema_CoversConfigStructs); fields it does not list are allowed.
+//
+//go:embed config_schema.json
+var configSchemaJSON []byte
+
+// configSchema is configSchemaJSON parsed for validation.
+var configSchema = mustParseConfigSchema(configSchemaJSON)
+
+// ConfigSchema returns the JSON Schema of the challenge config, for editors and
+// external tools.
+func ConfigSchema() []byte {
+	return slices.Clone(configSchemaJSON)
+}
+
+// ConfigSchemaViolation is a part of the challenge config that does not match
+// its schema.
+type ConfigSchemaViolation struct {
+	// Pointer is the JSON pointer (RFC 6901) of the value, e.g.
+	// "/challenges/0/goals/2/reward/type"; empty for the whole document.
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func (v ConfigSchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer + ": " + v.Message
+}
+
+// LoadConfigSchemaViolations validates the challenge config file against the
+// schema.
+func LoadConfigSchemaViolations(configPath string) ([]ConfigSchemaViolation, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseConfigSchemaViolations(data)
+}
+
+// ParseConfigSchemaViolations validates challenge config JSON against the
+// schema and lists what does not match, in document order with object fields
+// sorted by name.
+//
+// The common config validator stops at the first error and names goals by ID;
+// the violations point at each wrong value, so a hand-edited config can be
+// fixed in one pass.
+func ParseConfigSchemaViolations(data []byte) ([]ConfigSchemaViolation, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	v := &schemaValidator{definitions: configSchema.Definitions}
+	v.validate(configSchema, document, "")
+	return v.violations, nil
+}
+
+// CheckConfigSchema fails if the challenge config file does not match the
+// schema, listing every violation.
+func CheckConfigSchema(configPath string) error {
+	violations, err := LoadConfigSchemaViolations(configPath)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		descriptions = append(descriptions, violation.String())
+	}
+	return fmt.Errorf("challenge config does not match its schema; %d violations: %s",
+		len(violations), strings.Join(descriptions, "; "))
+}
+
+// schemaNode is the subset of JSON Schema that config_schema.json uses.
+// Enums hold strings only.
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Enum                 []string               `json:"enum"`
+	Minimum              *json.Number           `json:"minimum"`
+	Maximum              *json.Number           `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	Definitions          map[string]*schemaNode `json:"definitions"`
+}
+
+// additionalProperties is either false, rejecting fields not in properties,
+// or the schema of those fields.
+type additionalProperties struct {
+	forbidden bool
+	schema    *schemaNode
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if string(data) == "false" {
+		a.forbidden = true
+		return nil
+	}
+	if string(data) == "true" {
+		return nil
+	}
+	return json.Unmarshal(data, &a.schema)
+}
+
+func mustParseConfigSchema(data []byte) *schemaNode {
+	var schema schemaNode
+	if err := json.Unmarshal(data, &schema); err != nil {
+		panic(fmt.Sprintf("invalid config_schema.json: %v", err))
+	}
+	return &schema
+}
+
+// schemaValidator collects the violations of a document.
+type schemaValidator struct {
+	definitions map[string]*schemaNode
+	violations  []ConfigSchemaViolation
+}
+
+func (v *schemaValidator) fail(pointer, format string, args ...any) {
+	v.violations = append(v.violations, ConfigSchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) validate(schema *schemaNode, value any, pointer string) {
+	if name, ok := strings.CutPrefix(schema.Ref, "#/definitions/"); ok {
+		schema = v.definitions[name]
+	}
+
+	if schema.Type != "" && jsonType(value, schema.Type) != schema.Type {
+		v.fail(pointer, "must be %s, got %s", withArticle(schema.Type), withArticle(jsonType(value, schema.Type)))
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		v.validateObject(schema, value, pointer)
+	case []any:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			v.fail(pointer, "must have at least %d items", *schema.MinItems)
+		}
+		if schema.Items != nil {
+			for i, item := range value {
+				v.validate(schema.Items, item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case string:
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+			v.fail(pointer, "must be one of %s, got %q", quoteAll(schema.Enum), value)
+		}
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				v.fail(pointer, "must not be empty")
+			} else {
+				v.fail(pointer, "must be at least %d characters", *schema.MinLength)
+			}
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			v.fail(pointer, "must be at most %d characters, got %d", *schema.MaxLength, length)
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil {
+			if minimum, _ := schema.Minimum.Float64(); number < minimum {
+				v.fail(pointer, "must be at least %s, got %s", schema.Minimum, value)
+			}
+		}
+		if schema.Maximum != nil {
+			if maximum, _ := schema.Maximum.Float64(); number > maximum {
+				v.fail(pointer, "must be at most %s, got %s", schema.Maximum, value)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema *schemaNode, object map[string]any, pointer string) {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			v.fail(pointer+"/"+escapePointerToken(name), "is required")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(object)) {
+		fieldPointer := pointer + "/" + escapePointerToken(name)
+		// encoding/json reads null as a missing field
+		if object[name] == nil && !slices.Contains(schema.Required, name) {
+			continue
+		}
+		if property, ok := schema.Properties[name]; ok {
+			v.validate(property, object[name], fieldPointer)
+			continue
+		}
+		switch {
+		case schema.AdditionalProperties == nil:
+		case schema.AdditionalProperties.forbidden:
+			v.fail(fieldPointer, "unknown field")
+		case schema.AdditionalProperties.schema != nil:
+			v.validate(schema.AdditionalProperties.schema, object[name], fieldPointer)
+		}
+	}
+}
+
+// jsonType returns the JSON Schema type of value. A whole number is "integer"
+// when want is, and "number" otherwise.
+func jsonType(value any, want string) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		if _, err := value.Int64(); err == nil && want == "integer" {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func withArticle(jsonType string) string {
+	switch jsonType {
+	case "object", "array", "integer":
+		return "an " + jsonType
+	case "null":
+		return jsonType
+	}
+	return "a " + jsonType
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// escapePointerToken escapes a JSON pointer reference token (RFC 6901).
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
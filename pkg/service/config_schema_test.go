@@ -0,0 +1,176 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigSchemaViolations(t *testing.T) {
+	violations, err := ParseConfigSchemaViolations([]byte(`{"challenges": [
+		{"challengeId": "daily", "name": "Daily", "goals": [
+			{"goalId": "login", "name": "Login", "eventSource": "login",
+			 "requirement": {"statCode": "login", "operator": ">=", "targetValue": 1},
+			 "reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 10}},
+			{"goalId": "win", "name": "", "eventSource": "match",
+			 "requirement": {"statCode": "wins", "operator": ">=", "targetValue": 2.5},
+			 "reward": {"type": "COIN", "rewardId": "GOLD"},
+			 "targetOverrides": {"vip/gold": 0},
+			 "matchFilter": {"mode": "ranked", "reslt": "win"}}
+		]},
+		{"challengeId": "weekly", "goals": "none", "autoDeactivateAfterDays": -1, "rolloutPercentage": 101}
+	]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigSchemaViolation{
+		{Pointer: "/challenges/0/goals/1/eventSource", Message: `must be one of "login", "statistic", got "match"`},
+		{Pointer: "/challenges/0/goals/1/matchFilter/reslt", Message: "unknown field"},
+		{Pointer: "/challenges/0/goals/1/name", Message: "must not be empty"},
+		{Pointer: "/challenges/0/goals/1/requirement/targetValue", Message: "must be an integer, got a number"},
+		{Pointer: "/challenges/0/goals/1/reward/quantity", Message: "is required"},
+		{Pointer: "/challenges/0/goals/1/reward/type", Message: `must be one of "ITEM", "WALLET", got "COIN"`},
+		{Pointer: "/challenges/0/goals/1/targetOverrides/vip~1gold", Message: "must be at least 1, got 0"},
+		{Pointer: "/challenges/1/name", Message: "is required"},
+		{Pointer: "/challenges/1/autoDeactivateAfterDays", Message: "must be at least 0, got -1"},
+		{Pointer: "/challenges/1/goals", Message: "must be an array, got a string"},
+		{Pointer: "/challenges/1/rolloutPercentage", Message: "must be at most 100, got 101"},
+	}, violations)
+}
+
+func TestParseConfigSchemaViolations_Document(t *testing.T) {
+	violations, err := ParseConfigSchemaViolations([]byte(`[]`))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigSchemaViolation{{Pointer: "", Message: "must be an object, got an array"}}, violations)
+	assert.Equal(t, "/: must be an object, got an array", violations[0].String())
+
+	violations, err = ParseConfigSchemaViolations([]byte(`{"challenges": []}`))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigSchemaViolation{{Pointer: "/challenges", Message: "must have at least 1 items"}}, violations)
+
+	// Optional fields may be null, as written by encoding/json for nil slices
+	violations, err = ParseConfigSchemaViolations([]byte(`{"challenges": null}`))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigSchemaViolation{{Pointer: "/challenges", Message: "must be an array, got null"}}, violations)
+
+	_, err = ParseConfigSchemaViolations([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestCheckConfigSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [{"challengeId": "", "name": "Daily", "goals": []}]}`), 0o600))
+
+	err := CheckConfigSchema(path)
+
+	require.Error(t, err)
+	assert.Equal(t, `challenge config does not match its schema; 2 violations: `+
+		`/challenges/0/challengeId: must not be empty; /challenges/0/goals: must have at least 1 items`, err.Error())
+	assert.Error(t, CheckConfigSchema(filepath.Join(t.TempDir(), "missing.json")))
+}
+
+// TestConfigSchema_RepoConfigs keeps the shipped configs valid.
+func TestConfigSchema_RepoConfigs(t *testing.T) {
+	for _, path := range []string{
+		"../../config/challenges.json",
+		"../../config/challenges.test.json",
+		"../../tests/load/testdata/challenges.json",
+	} {
+		assert.NoError(t, CheckConfigSchema(path), path)
+	}
+}
+
+func TestConfigSchema_IsValidJSON(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(ConfigSchema(), &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+}
+
+// TestConfigSchema_CoversConfigStructs fails when a field of a struct the
+// challenge config is parsed into has no schema entry, so the schema cannot
+// fall behind a new config field.
+func TestConfigSchema_CoversConfigStructs(t *testing.T) {
+	for _, root := range []any{
+		config.Config{},
+		autoDeactivationConfig{},
+		autoRandomSelectConfig{},
+		challengePrerequisitesConfig{},
+		challengeRolloutsConfig{},
+		goalIDsConfig{},
+		goalStepsConfig{},
+		hiddenGoalsConfig{},
+		inactiveProgressConfig{},
+		lateEventConfig{},
+		matchGoalsConfig{},
+		repeatableGoalsConfig{},
+		rewardCapsConfig{},
+		rewardDeliveriesConfig{},
+		targetOverridesConfig{},
+	} {
+		assertSchemaCovers(t, configSchema, reflect.TypeOf(root), reflect.TypeOf(root).Name())
+	}
+
+	// Kept as json.RawMessage in the config structs
+	goal := configSchema.Definitions["goal"]
+	assertSchemaCovers(t, goal.Properties["matchFilter"], reflect.TypeOf(MatchFilter{}), "matchFilter")
+	assertSchemaCovers(t, goal.Properties["rewardCap"], reflect.TypeOf(RewardCap{}), "rewardCap")
+}
+
+func assertSchemaCovers(t *testing.T, schema *schemaNode, typ reflect.Type, path string) {
+	t.Helper()
+	if name, ok := strings.CutPrefix(schema.Ref, "#/definitions/"); ok {
+		schema = configSchema.Definitions[name]
+		require.NotNil(t, schema, "%s: unknown definition %s", path, name)
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(json.RawMessage{}) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			property, ok := schema.Properties[name]
+			if !assert.True(t, ok, "%s.%s has no schema entry", path, name) {
+				continue
+			}
+			assertSchemaCovers(t, property, field.Type, path+"."+name)
+		}
+	case reflect.Slice:
+		require.NotNil(t, schema.Items, "%s: schema has no items", path)
+		assertSchemaCovers(t, schema.Items, typ.Elem(), path+"[]")
+	case reflect.Map:
+		require.NotNil(t, schema.AdditionalProperties, "%s: schema has no additionalProperties", path)
+		require.NotNil(t, schema.AdditionalProperties.schema, "%s: schema has no additionalProperties", path)
+		assertSchemaCovers(t, schema.AdditionalProperties.schema, typ.Elem(), path+"{}")
+	}
+}
+
+func TestParseConfigSchemaViolations_NullOptionalField(t *testing.T) {
+	data, err := json.Marshal(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100)}},
+	}})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"prerequisites":null`)
+
+	violations, err := ParseConfigSchemaViolations(data)
+
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
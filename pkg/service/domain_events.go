@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"extend-challenge-service/pkg/events"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnqueuePlayerInitialized records a player.initialized event when
+// InitializePlayer assigned a new player's default goals. Returning players
+// get no event.
+//
+// Like activation sources, the event is written after the goals are, so a
+// failure is logged and does not fail the request. A nil outbox records nothing.
+func EnqueuePlayerInitialized(
+	ctx context.Context,
+	outbox serviceRepo.EventOutboxRepository,
+	namespace string,
+	userID string,
+	result *InitializeResponse,
+	logger logrus.FieldLogger,
+) {
+	if outbox == nil || result == nil || result.NewAssignments == 0 {
+		return
+	}
+
+	assigned := make([]string, len(result.AssignedGoals))
+	for i, goal := range result.AssignedGoals {
+		assigned[i] = goal.GoalID
+	}
+
+	enqueueEvent(ctx, outbox, namespace, userID, events.TypePlayerInitialized, events.PlayerInitialized{
+		AssignedGoalIDs: assigned,
+	}, logger)
+}
+
+// EnqueueGoalsSelected records a goals.selected event when a selection
+// activated or replaced goals; source is ActivationSourceManual or
+// ActivationSourceRandom. The client context of ctx (see WithClientContext) is
+// published with it. Failures are logged as in EnqueuePlayerInitialized.
+func EnqueueGoalsSelected(
+	ctx context.Context,
+	outbox serviceRepo.EventOutboxRepository,
+	namespace string,
+	userID string,
+	result *GoalSelectionResult,
+	source string,
+	logger logrus.FieldLogger,
+) {
+	if outbox == nil || result == nil || (len(result.ActivatedGoalIDs) == 0 && len(result.ReplacedGoals) == 0) {
+		return
+	}
+
+	selected := make([]string, len(result.SelectedGoals))
+	for i, goal := range result.SelectedGoals {
+		selected[i] = goal.GoalID
+	}
+
+	enqueueEvent(ctx, outbox, namespace, userID, events.TypeGoalsSelected, events.GoalsSelected{
+		ChallengeID:      result.ChallengeID,
+		Source:           source,
+		SelectedGoalIDs:  selected,
+		ActivatedGoalIDs: nonNilStrings(result.ActivatedGoalIDs),
+		ReplacedGoalIDs:  nonNilStrings(result.ReplacedGoals),
+		ClientContext:    clientContextFrom(ctx),
+	}, logger)
+}
+
+// enqueueEvent writes one event occurring now, logging failures.
+func enqueueEvent(
+	ctx context.Context,
+	outbox serviceRepo.EventOutboxRepository,
+	namespace string,
+	userID string,
+	eventType string,
+	data any,
+	logger logrus.FieldLogger,
+) {
+	log := logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"namespace":  namespace,
+		"event_type": eventType,
+	})
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode domain event")
+		return
+	}
+
+	if err := outbox.Enqueue(ctx, &serviceRepo.OutboxEvent{
+		Type:       eventType,
+		Namespace:  namespace,
+		UserID:     userID,
+		Payload:    payload,
+		OccurredAt: time.Now().UTC(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to enqueue domain event")
+	}
+}
+
+// nonNilStrings returns ids, or an empty slice when it is nil, so it encodes as [].
+func nonNilStrings(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
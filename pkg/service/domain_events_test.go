@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/events"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueuePlayerInitialized(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+	var enqueued *serviceRepo.OutboxEvent
+	outbox.On("Enqueue", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { enqueued = args.Get(1).(*serviceRepo.OutboxEvent) }).
+		Return(nil)
+
+	EnqueuePlayerInitialized(context.Background(), outbox, "ns", "user-1", &InitializeResponse{
+		AssignedGoals:  []*AssignedGoal{{GoalID: "g1"}, {GoalID: "g2"}},
+		NewAssignments: 2,
+	}, logrus.StandardLogger())
+
+	require.NotNil(t, enqueued)
+	assert.Equal(t, events.TypePlayerInitialized, enqueued.Type)
+	assert.Equal(t, "ns", enqueued.Namespace)
+	assert.Equal(t, "user-1", enqueued.UserID)
+	assert.False(t, enqueued.OccurredAt.IsZero())
+	assert.JSONEq(t, `{"assignedGoalIds":["g1","g2"]}`, string(enqueued.Payload))
+}
+
+func TestEnqueuePlayerInitialized_ReturningPlayer(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+
+	EnqueuePlayerInitialized(context.Background(), outbox, "ns", "user-1", &InitializeResponse{
+		AssignedGoals: []*AssignedGoal{{GoalID: "g1"}},
+	}, logrus.StandardLogger())
+	EnqueuePlayerInitialized(context.Background(), nil, "ns", "user-1", &InitializeResponse{NewAssignments: 1}, logrus.StandardLogger())
+
+	outbox.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+}
+
+func TestEnqueueGoalsSelected(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+	var enqueued *serviceRepo.OutboxEvent
+	outbox.On("Enqueue", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { enqueued = args.Get(1).(*serviceRepo.OutboxEvent) }).
+		Return(nil)
+
+	EnqueueGoalsSelected(context.Background(), outbox, "ns", "user-1", &GoalSelectionResult{
+		ChallengeID:      "daily",
+		SelectedGoals:    []*SelectedGoalInfo{{GoalID: "g1"}, {GoalID: "g2"}},
+		ActivatedGoalIDs: []string{"g2"},
+	}, ActivationSourceRandom, logrus.StandardLogger())
+
+	require.NotNil(t, enqueued)
+	assert.Equal(t, events.TypeGoalsSelected, enqueued.Type)
+	assert.JSONEq(t, `{
+		"challengeId": "daily",
+		"source": "random",
+		"selectedGoalIds": ["g1", "g2"],
+		"activatedGoalIds": ["g2"],
+		"replacedGoalIds": []
+	}`, string(enqueued.Payload))
+}
+
+func TestEnqueueGoalsSelected_ClientContext(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+	var enqueued *serviceRepo.OutboxEvent
+	outbox.On("Enqueue", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { enqueued = args.Get(1).(*serviceRepo.OutboxEvent) }).
+		Return(nil)
+
+	ctx := WithClientContext(context.Background(), ClientContext{"surface": "challenges_menu"})
+	EnqueueGoalsSelected(ctx, outbox, "ns", "user-1", &GoalSelectionResult{
+		ChallengeID:      "daily",
+		SelectedGoals:    []*SelectedGoalInfo{{GoalID: "g1"}},
+		ActivatedGoalIDs: []string{"g1"},
+	}, ActivationSourceManual, logrus.StandardLogger())
+
+	require.NotNil(t, enqueued)
+	assert.JSONEq(t, `{
+		"challengeId": "daily",
+		"source": "manual",
+		"selectedGoalIds": ["g1"],
+		"activatedGoalIds": ["g1"],
+		"replacedGoalIds": [],
+		"clientContext": {"surface": "challenges_menu"}
+	}`, string(enqueued.Payload))
+}
+
+func TestEnqueueGoalsSelected_NothingChanged(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+
+	EnqueueGoalsSelected(context.Background(), outbox, "ns", "user-1", &GoalSelectionResult{
+		ChallengeID:   "daily",
+		SelectedGoals: []*SelectedGoalInfo{{GoalID: "g1"}},
+	}, ActivationSourceManual, logrus.StandardLogger())
+
+	outbox.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+}
+
+func TestEnqueueGoalsSelected_FailureIsNotFatal(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+	outbox.On("Enqueue", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	assert.NotPanics(t, func() {
+		EnqueueGoalsSelected(context.Background(), outbox, "ns", "user-1", &GoalSelectionResult{
+			ReplacedGoals: []string{"g1"},
+		}, ActivationSourceManual, logrus.StandardLogger())
+	})
+	outbox.AssertExpectations(t)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	agsClient "extend-challenge-service/pkg/client"
+	"extend-challenge-service/pkg/events"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultEventRelayInterval is how often the event outbox is polled while it
+	// has no full batch waiting.
+	DefaultEventRelayInterval = time.Second
+
+	eventRelayBatchSize = 100
+
+	// eventPublishTimeout bounds one Publish call; the batch's rows stay locked
+	// until it returns.
+	eventPublishTimeout = 15 * time.Second
+)
+
+// EventRelay publishes the event outbox (see serviceRepo.EventOutboxRepository)
+// to an agsClient.EventPublisher in batches, oldest first, deleting each batch
+// once it is published. A batch that fails is kept and published again, so
+// events are delivered at least once.
+//
+// Without a publisher the outbox is drained without publishing, so it does not
+// grow while events are disabled.
+type EventRelay struct {
+	outbox    serviceRepo.EventOutboxRepository
+	publisher agsClient.EventPublisher
+	interval  time.Duration
+	logger    logrus.FieldLogger
+	now       func() time.Time
+
+	published *prometheus.CounterVec
+	discarded prometheus.Counter
+	failures  prometheus.Counter
+	oldestAge prometheus.Gauge
+}
+
+// NewEventRelay creates an event relay that logs through logger. A nil
+// publisher discards the events.
+func NewEventRelay(
+	outbox serviceRepo.EventOutboxRepository,
+	publisher agsClient.EventPublisher,
+	interval time.Duration,
+	logger logrus.FieldLogger,
+) *EventRelay {
+	return &EventRelay{
+		outbox:    outbox,
+		publisher: publisher,
+		interval:  interval,
+		logger:    logger,
+		now:       func() time.Time { return time.Now().UTC() },
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "events_published_total",
+			Help: "Domain events accepted by the event publisher, by event type.",
+		}, []string{"type"}),
+		discarded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "events_discarded_total",
+			Help: "Domain events removed from the outbox unpublished because no event publisher is configured.",
+		}),
+		failures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "event_publish_failures_total",
+			Help: "Event batches the event publisher failed to publish; they are retried.",
+		}),
+		oldestAge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "event_outbox_oldest_age_seconds",
+			Help: "Age of the oldest event in the last batch read from the outbox, 0 when it was empty.",
+		}),
+	}
+}
+
+// NewEventRelayFromEnv creates an event relay polling every EVENT_RELAY_INTERVAL
+// (default "1s").
+func NewEventRelayFromEnv(outbox serviceRepo.EventOutboxRepository, publisher agsClient.EventPublisher, logger logrus.FieldLogger) *EventRelay {
+	return NewEventRelay(outbox, publisher, parseClaimRecoveryDuration("EVENT_RELAY_INTERVAL", DefaultEventRelayInterval, 0, logger), logger)
+}
+
+// Collectors returns the event relay metrics for registration.
+func (r *EventRelay) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{r.published, r.discarded, r.failures, r.oldestAge}
+}
+
+// RelayPending publishes one batch of events and returns how many were
+// published (or discarded).
+func (r *EventRelay) RelayPending(ctx context.Context) (int, error) {
+	byType := make(map[string]int)
+	n, err := r.outbox.PublishPending(ctx, eventRelayBatchSize, func(pending []*serviceRepo.OutboxEvent) error {
+		r.oldestAge.Set(r.now().Sub(pending[0].CreatedAt.UTC()).Seconds())
+		if r.publisher == nil {
+			return nil
+		}
+
+		envelopes := make([]events.Envelope, len(pending))
+		for i, event := range pending {
+			envelopes[i] = toEnvelope(event)
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, eventPublishTimeout)
+		defer cancel()
+		if err := r.publisher.Publish(publishCtx, envelopes); err != nil {
+			r.failures.Inc()
+			return fmt.Errorf("failed to publish %d events: %w", len(envelopes), err)
+		}
+
+		for _, event := range pending {
+			byType[event.Type]++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if n == 0 {
+		r.oldestAge.Set(0)
+	}
+	if r.publisher == nil {
+		r.discarded.Add(float64(n))
+	}
+	for eventType, count := range byType {
+		r.published.WithLabelValues(eventType).Add(float64(count))
+	}
+
+	return n, nil
+}
+
+// Run relays events until ctx is cancelled. Full batches are followed by the
+// next one at once; otherwise it waits for the interval.
+func (r *EventRelay) Run(ctx context.Context) {
+	if r.publisher == nil {
+		r.logger.Info("No event publisher configured, domain events are discarded")
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.RelayPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.WithError(err).Warn("Failed to relay domain events")
+		}
+		if err == nil && n == eventRelayBatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// toEnvelope wraps an outbox event in the published envelope.
+func toEnvelope(event *serviceRepo.OutboxEvent) events.Envelope {
+	return events.Envelope{
+		ID:            event.EventID,
+		Type:          event.Type,
+		SchemaVersion: events.SchemaVersion,
+		Source:        events.Source,
+		Namespace:     event.Namespace,
+		UserID:        event.UserID,
+		OccurredAt:    event.OccurredAt.UTC().Truncate(time.Second),
+		Data:          event.Payload,
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/events"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records the published envelopes, failing with err when set.
+type recordingPublisher struct {
+	published []events.Envelope
+	err       error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, envelopes []events.Envelope) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, envelopes...)
+	return nil
+}
+
+// fakeEventOutbox hands its events to publish like PublishPending, dropping
+// them when publish succeeds.
+type fakeEventOutbox struct {
+	serviceRepo.EventOutboxRepository
+	pending []*serviceRepo.OutboxEvent
+}
+
+func (o *fakeEventOutbox) PublishPending(_ context.Context, limit int, publish func([]*serviceRepo.OutboxEvent) error) (int, error) {
+	batch := o.pending[:min(limit, len(o.pending))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(batch); err != nil {
+		return 0, err
+	}
+	o.pending = o.pending[len(batch):]
+	return len(batch), nil
+}
+
+func pendingEvents(now time.Time) []*serviceRepo.OutboxEvent {
+	return []*serviceRepo.OutboxEvent{
+		{Seq: 1, EventID: "e1", Type: events.TypeGoalCompleted, Namespace: "ns", UserID: "user-1",
+			Payload: json.RawMessage(`{"goalId":"g1"}`), OccurredAt: now.Add(-30*time.Second + 300*time.Millisecond), CreatedAt: now.Add(-30 * time.Second)},
+		{Seq: 2, EventID: "e2", Type: events.TypeGoalClaimed, Namespace: "ns", UserID: "user-1",
+			Payload: json.RawMessage(`{"goalId":"g1"}`), OccurredAt: now, CreatedAt: now},
+	}
+}
+
+func TestEventRelay_RelayPending(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outbox := &fakeEventOutbox{pending: pendingEvents(now)}
+	publisher := &recordingPublisher{}
+	relay := NewEventRelay(outbox, publisher, time.Second, logrus.StandardLogger())
+	relay.now = func() time.Time { return now }
+
+	n, err := relay.RelayPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.Len(t, publisher.published, 2)
+	first := publisher.published[0]
+	assert.Equal(t, events.Envelope{
+		ID:            "e1",
+		Type:          events.TypeGoalCompleted,
+		SchemaVersion: events.SchemaVersion,
+		Source:        events.Source,
+		Namespace:     "ns",
+		UserID:        "user-1",
+		OccurredAt:    now.Add(-30 * time.Second),
+		Data:          json.RawMessage(`{"goalId":"g1"}`),
+	}, first)
+	assert.Equal(t, 1.0, testutil.ToFloat64(relay.published.WithLabelValues(events.TypeGoalCompleted)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(relay.published.WithLabelValues(events.TypeGoalClaimed)))
+	assert.Equal(t, 30.0, testutil.ToFloat64(relay.oldestAge))
+}
+
+func TestEventRelay_PublishFailureKeepsEvents(t *testing.T) {
+	outbox := &fakeEventOutbox{pending: pendingEvents(time.Now().UTC())}
+	relay := NewEventRelay(outbox, &recordingPublisher{err: errors.New("broker down")}, time.Second, logrus.StandardLogger())
+
+	n, err := relay.RelayPending(context.Background())
+
+	assert.ErrorContains(t, err, "failed to publish 2 events: broker down")
+	assert.Zero(t, n)
+	assert.Equal(t, 1.0, testutil.ToFloat64(relay.failures))
+	assert.Len(t, outbox.pending, 2, "the events are kept")
+	assert.Zero(t, testutil.CollectAndCount(relay.published))
+}
+
+func TestEventRelay_WithoutPublisherDiscards(t *testing.T) {
+	outbox := &fakeEventOutbox{pending: pendingEvents(time.Now().UTC())}
+	relay := NewEventRelay(outbox, nil, time.Second, logrus.StandardLogger())
+
+	n, err := relay.RelayPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2.0, testutil.ToFloat64(relay.discarded))
+	assert.Zero(t, testutil.CollectAndCount(relay.published))
+}
+
+func TestEventRelay_EmptyOutboxResetsAge(t *testing.T) {
+	outbox := &fakeEventOutbox{}
+	relay := NewEventRelay(outbox, &recordingPublisher{}, time.Second, logrus.StandardLogger())
+	relay.oldestAge.Set(42)
+
+	n, err := relay.RelayPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, testutil.ToFloat64(relay.oldestAge))
+}
+
+func TestEventRelay_RunStopsOnCancel(t *testing.T) {
+	outbox := new(mocks.EventOutboxRepository)
+	ctx, cancel := context.WithCancel(context.Background())
+	outbox.On("PublishPending", mock.Anything, eventRelayBatchSize, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(0, nil)
+	relay := NewEventRelay(outbox, &recordingPublisher{}, time.Hour, logrus.StandardLogger())
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
+
+func TestEventRelay_RunDrainsFullBatches(t *testing.T) {
+	outbox := &fakeEventOutbox{}
+	for i := 0; i < eventRelayBatchSize+50; i++ {
+		outbox.pending = append(outbox.pending, &serviceRepo.OutboxEvent{Seq: int64(i), Type: events.TypeGoalCompleted})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	publisher := &cancellingPublisher{cancelAfter: eventRelayBatchSize + 50, cancel: cancel}
+	relay := NewEventRelay(outbox, publisher, time.Hour, logrus.StandardLogger())
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the second batch waited for the interval")
+	}
+	assert.Empty(t, outbox.pending)
+}
+
+// cancellingPublisher cancels once it has published cancelAfter events.
+type cancellingPublisher struct {
+	published   int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (p *cancellingPublisher) Publish(_ context.Context, envelopes []events.Envelope) error {
+	p.published += len(envelopes)
+	if p.published >= p.cancelAfter {
+		p.cancel()
+	}
+	return nil
+}
+
+func TestNewEventRelayFromEnv(t *testing.T) {
+	t.Setenv("EVENT_RELAY_INTERVAL", "250ms")
+	assert.Equal(t, 250*time.Millisecond, NewEventRelayFromEnv(nil, nil, logrus.StandardLogger()).interval)
+
+	t.Setenv("EVENT_RELAY_INTERVAL", "soon")
+	assert.Equal(t, DefaultEventRelayInterval, NewEventRelayFromEnv(nil, nil, logrus.StandardLogger()).interval)
+}
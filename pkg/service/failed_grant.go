@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/client"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultFailedGrantListLimit is the number of failed grants ListFailedGrants returns by default.
+	DefaultFailedGrantListLimit = 100
+
+	// MaxFailedGrantListLimit caps the limit of ListFailedGrants.
+	MaxFailedGrantListLimit = 1000
+)
+
+// Failed grant error classes (failed_grants.error_class).
+const (
+	GrantErrorClassBadRequest    = "bad_request"
+	GrantErrorClassNotFound      = "not_found"
+	GrantErrorClassConflict      = "conflict"
+	GrantErrorClassUnprocessable = "unprocessable"
+)
+
+// PermanentGrantErrorClass returns the error class of a reward grant error that
+// retrying the same grant cannot fix, or "" for any other error.
+//
+// Only AGS rejections of the grant itself are permanent: the reward's item or
+// currency is missing or invalid. Authentication and permission errors are
+// service misconfiguration that affects every claim, so they are not dead-lettered.
+func PermanentGrantErrorClass(err error) string {
+	var httpErr client.HTTPStatusCodeError
+	if !errors.As(err, &httpErr) {
+		return ""
+	}
+
+	switch httpErr.HTTPStatusCode() {
+	case 400:
+		return GrantErrorClassBadRequest
+	case 404:
+		return GrantErrorClassNotFound
+	case 409:
+		return GrantErrorClassConflict
+	case 422:
+		return GrantErrorClassUnprocessable
+	}
+	return ""
+}
+
+// FailedGrants dead-letters claims whose reward grant failed permanently, so
+// support can find the stuck players and retry the grant once the store is fixed.
+//
+// Recording is best effort: the claim has already failed, and a failure to
+// record it only loses the dead letter.
+type FailedGrants struct {
+	repo   serviceRepo.FailedGrantRepository
+	logger logrus.FieldLogger
+}
+
+// NewFailedGrants creates failed grants stored in repo that log through logger.
+func NewFailedGrants(repo serviceRepo.FailedGrantRepository, logger logrus.FieldLogger) *FailedGrants {
+	return &FailedGrants{repo: repo, logger: logger}
+}
+
+// Record stores the failed claim of the user if err is a
+// *mapper.RewardGrantPermanentError, and ignores any other error.
+// It does nothing on a nil *FailedGrants.
+func (g *FailedGrants) Record(ctx context.Context, userID, namespace string, err error) {
+	var permanent *mapper.RewardGrantPermanentError
+	if g == nil || !errors.As(err, &permanent) {
+		return
+	}
+
+	grant := &serviceRepo.FailedGrant{
+		UserID:         userID,
+		GoalID:         permanent.GoalID,
+		ChallengeID:    permanent.ChallengeID,
+		Namespace:      namespace,
+		GrantNamespace: permanent.GrantNamespace,
+		RewardType:     permanent.Reward.Type,
+		RewardID:       permanent.Reward.RewardID,
+		RewardQuantity: permanent.Reward.Quantity,
+		ErrorClass:     permanent.ErrorClass,
+		LastError:      permanent.Err.Error(),
+	}
+	if err := g.repo.RecordFailedGrant(ctx, grant); err != nil {
+		g.logger.WithFields(logrus.Fields{
+			"user_id":     userID,
+			"goal_id":     permanent.GoalID,
+			"namespace":   namespace,
+			"error_class": permanent.ErrorClass,
+			"error":       err,
+		}).Error("Failed to record failed reward grant")
+	}
+}
+
+// Resolve deletes the failed grant of a goal the user has now claimed.
+// It does nothing on a nil *FailedGrants.
+func (g *FailedGrants) Resolve(ctx context.Context, userID, goalID string) {
+	if g == nil {
+		return
+	}
+
+	if err := g.repo.DeleteFailedGrant(ctx, userID, goalID); err != nil {
+		g.logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"goal_id": goalID,
+			"error":   err,
+		}).Warn("Failed to delete resolved failed reward grant")
+	}
+}
+
+// Get returns the failed grant with the given ID, or nil.
+func (g *FailedGrants) Get(ctx context.Context, namespace string, id int64) (*serviceRepo.FailedGrant, error) {
+	grant, err := g.repo.GetFailedGrant(ctx, namespace, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed grant: %w", err)
+	}
+	return grant, nil
+}
+
+// List returns up to limit failed grants, most recently failed first, and
+// whether more exist. limit defaults to DefaultFailedGrantListLimit and is
+// capped at MaxFailedGrantListLimit.
+func (g *FailedGrants) List(ctx context.Context, namespace string, limit int) ([]*serviceRepo.FailedGrant, bool, error) {
+	if limit <= 0 {
+		limit = DefaultFailedGrantListLimit
+	}
+	limit = min(limit, MaxFailedGrantListLimit)
+
+	// One extra row tells whether the list was truncated
+	grants, err := g.repo.ListFailedGrants(ctx, namespace, limit+1)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list failed grants: %w", err)
+	}
+	if len(grants) > limit {
+		return grants[:limit], true, nil
+	}
+	return grants, false, nil
+}
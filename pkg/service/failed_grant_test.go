@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/client"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermanentGrantErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"not found", &client.NotFoundError{Resource: "item sword"}, GrantErrorClassNotFound},
+		{"bad request", &client.BadRequestError{Message: "invalid quantity"}, GrantErrorClassBadRequest},
+		{"wrapped", fmt.Errorf("reward grant failed (non-retryable): %w", &client.NotFoundError{}), GrantErrorClassNotFound},
+		{"conflict", &client.AGSError{StatusCode: 409, Message: "conflict"}, GrantErrorClassConflict},
+		{"unprocessable", &client.AGSError{StatusCode: 422, Message: "invalid"}, GrantErrorClassUnprocessable},
+		{"forbidden", &client.ForbiddenError{Message: "namespace mismatch"}, ""},
+		{"authentication", &client.AuthenticationError{Message: "expired token"}, ""},
+		{"server error", &client.AGSError{StatusCode: 503, Message: "unavailable"}, ""},
+		{"untyped", errors.New("connection refused"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PermanentGrantErrorClass(tt.err))
+		})
+	}
+}
+
+func TestFailedGrants_Record(t *testing.T) {
+	repo := new(mocks.FailedGrantRepository)
+	repo.On("RecordFailedGrant", mock.Anything, &repository.FailedGrant{
+		UserID:         "user-1",
+		GoalID:         "goal-1",
+		ChallengeID:    "daily",
+		Namespace:      "ns",
+		GrantNamespace: "other-ns",
+		RewardType:     "ITEM",
+		RewardID:       "sword",
+		RewardQuantity: 1,
+		ErrorClass:     GrantErrorClassNotFound,
+		LastError:      "resource not found: item sword",
+	}).Return(nil)
+
+	NewFailedGrants(repo, logrus.StandardLogger()).Record(context.Background(), "user-1", "ns", fmt.Errorf("claim failed: %w", &mapper.RewardGrantPermanentError{
+		GoalID:         "goal-1",
+		ChallengeID:    "daily",
+		Reward:         domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: 1},
+		GrantNamespace: "other-ns",
+		ErrorClass:     GrantErrorClassNotFound,
+		Err:            &client.NotFoundError{Resource: "item sword"},
+	}))
+
+	repo.AssertExpectations(t)
+}
+
+func TestFailedGrants_Record_IgnoresOtherErrors(t *testing.T) {
+	repo := new(mocks.FailedGrantRepository)
+
+	NewFailedGrants(repo, logrus.StandardLogger()).Record(context.Background(), "user-1", "ns", &mapper.RewardGrantError{GoalID: "goal-1", Err: errors.New("timeout")})
+
+	repo.AssertNotCalled(t, "RecordFailedGrant", mock.Anything, mock.Anything)
+}
+
+// Failing to store or delete a dead letter is logged and otherwise ignored,
+// since the claim's outcome does not depend on it.
+func TestFailedGrants_RepositoryErrorsAreLogged(t *testing.T) {
+	repo := new(mocks.FailedGrantRepository)
+	repo.On("RecordFailedGrant", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+	repo.On("DeleteFailedGrant", mock.Anything, "user-1", "goal-1").Return(errors.New("connection reset"))
+	logger, hook := logtest.NewNullLogger()
+	grants := NewFailedGrants(repo, logger)
+
+	grants.Record(context.Background(), "user-1", "ns", &mapper.RewardGrantPermanentError{
+		GoalID:     "goal-1",
+		ErrorClass: GrantErrorClassNotFound,
+		Err:        &client.NotFoundError{Resource: "item sword"},
+	})
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to record failed reward grant", hook.LastEntry().Message)
+	assert.Equal(t, GrantErrorClassNotFound, hook.LastEntry().Data["error_class"])
+
+	grants.Resolve(context.Background(), "user-1", "goal-1")
+	require.Len(t, hook.AllEntries(), 2)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to delete resolved failed reward grant", hook.LastEntry().Message)
+	assert.Equal(t, "goal-1", hook.LastEntry().Data["goal_id"])
+}
+
+func TestFailedGrants_Nil(t *testing.T) {
+	var grants *FailedGrants
+	grants.Record(context.Background(), "user-1", "ns", &mapper.RewardGrantPermanentError{Err: errors.New("gone")})
+	grants.Resolve(context.Background(), "user-1", "goal-1")
+}
+
+func TestFailedGrants_List(t *testing.T) {
+	repo := new(mocks.FailedGrantRepository)
+	rows := []*repository.FailedGrant{{ID: 3}, {ID: 2}, {ID: 1}}
+	repo.On("ListFailedGrants", mock.Anything, "ns", 3).Return(rows, nil)
+	repo.On("ListFailedGrants", mock.Anything, "ns", DefaultFailedGrantListLimit+1).Return(rows, nil)
+	repo.On("ListFailedGrants", mock.Anything, "ns", MaxFailedGrantListLimit+1).Return(rows, nil)
+
+	grants := NewFailedGrants(repo, logrus.StandardLogger())
+
+	listed, truncated, err := grants.List(context.Background(), "ns", 2)
+	require.NoError(t, err)
+	assert.Equal(t, rows[:2], listed)
+	assert.True(t, truncated)
+
+	listed, truncated, err = grants.List(context.Background(), "ns", 0)
+	require.NoError(t, err)
+	assert.Equal(t, rows, listed)
+	assert.False(t, truncated)
+
+	_, _, err = grants.List(context.Background(), "ns", MaxFailedGrantListLimit+50)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ResolveFirstCompletions returns when the user first completed each goal of
+// goalIDs with a row in progress. A reset by rotation, abandonment, an admin,
+// a step regression or a deactivation with reset_progress clears completed_at
+// but keeps the first completion (first_completed_at, migration 025), which
+// the progress rows of GoalRepository do not carry.
+//
+// Nothing is read when no goal has a row. A failure is logged and returns nil,
+// leaving the callers with completed_at.
+func ResolveFirstCompletions(
+	ctx context.Context,
+	queries serviceRepo.ProgressQueryRepository,
+	userID string,
+	goalIDs []string,
+	progress map[string]*domain.UserGoalProgress,
+	logger logrus.FieldLogger,
+) map[string]time.Time {
+	if queries == nil {
+		return nil
+	}
+
+	var stored []string
+	for _, goalID := range goalIDs {
+		if progress[goalID] != nil {
+			stored = append(stored, goalID)
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	completions, err := queries.GetFirstCompletions(ctx, userID, stored)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":    userID,
+			"goal_count": len(stored),
+			"error":      err,
+		}).Warn("Failed to load first goal completions")
+		return nil
+	}
+	return completions
+}
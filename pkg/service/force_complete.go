@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/client"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ForceCompleteOptions are the admin's choices for ForceCompleteGoal.
+type ForceCompleteOptions struct {
+	// Reason is stored in the audit record. It is required.
+	Reason string
+	// Force allows completing a goal that is not live for the user: never
+	// assigned, inactive, or from a rotation period that has ended. The goal is
+	// activated so it can be claimed.
+	Force bool
+	// AutoClaim claims the reward through ClaimGoalReward once the goal is completed.
+	AutoClaim bool
+	// ClientIP is the address the admin's request came from, stored in the audit
+	// record. Empty when unknown.
+	ClientIP string
+}
+
+// ForceCompleteResult is the outcome of ForceCompleteGoal.
+type ForceCompleteResult struct {
+	ChallengeID string
+	Progress    *domain.UserGoalProgress
+	// Claim is set when the goal was auto-claimed.
+	Claim *ClaimResult
+}
+
+// ForceCompleteGoal lets support complete a goal on a user's behalf, e.g. when
+// a stat event was lost.
+//
+// Flow:
+// 1. Validate the goal exists in config
+// 2. In one transaction: lock the progress row, refuse claimed goals (always)
+// and goals not live for the user (unless opts.Force), set progress to the
+// target (every step's target for a multi-step goal), status completed,
+// completed_at now unless already set, and write the audit record
+// 3. If opts.AutoClaim, claim through ClaimGoalReward, so the outbox guard,
+// prerequisite checks and reward grant retries apply as for a player claim
+//
+// A failed auto-claim leaves the goal completed; the error is returned and the
+// player (or a repeated force-complete) can claim it later.
+//
+// Error Cases:
+//   - Goal not found in config: returns *mapper.GoalNotFoundError
+//   - Goal already claimed: returns *mapper.GoalAlreadyClaimedError
+//   - Goal not live for the user without opts.Force: returns *mapper.GoalOverrideRefusedError
+//   - Database error: returns mapper.ErrDatabaseError
+//   - Auto-claim failure: returns the ClaimGoalReward error, wrapped
+func ForceCompleteGoal(
+	ctx context.Context,
+	adminID string,
+	userID string,
+	goalID string,
+	namespace string,
+	opts ForceCompleteOptions,
+	goalCache cache.GoalCache,
+	steps GoalSteps,
+	goalAdmin serviceRepo.GoalAdminRepository,
+	repo repository.GoalRepository,
+	outbox serviceRepo.ClaimOutboxRepository,
+	rewardClient client.RewardClient,
+	logger logrus.FieldLogger,
+) (*ForceCompleteResult, error) {
+	if adminID == "" {
+		return nil, fmt.Errorf("admin ID cannot be empty")
+	}
+
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if goalID == "" {
+		return nil, fmt.Errorf("goal ID cannot be empty")
+	}
+
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	if opts.Reason == "" {
+		return nil, fmt.Errorf("reason cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if goalAdmin == nil {
+		return nil, fmt.Errorf("goal admin repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	// Removed goals can't be completed: their target is no longer known
+	goal := goalCache.GetGoalByID(goalID)
+	if goal == nil {
+		return nil, &mapper.GoalNotFoundError{GoalID: goalID}
+	}
+
+	fields := logrus.Fields{
+		"user_id":        adminID,
+		"target_user_id": userID,
+		"goal_id":        goalID,
+		"challenge_id":   goal.ChallengeID,
+		"namespace":      namespace,
+		"forced":         opts.Force,
+		"auto_claim":     opts.AutoClaim,
+		"client_ip":      opts.ClientIP,
+	}
+
+	now := time.Now().UTC()
+	// from is the status check saw, the one the forced completion replaces
+	var from domain.GoalStatus
+	check := func(current *domain.UserGoalProgress) error {
+		if current != nil && current.IsClaimed() {
+			return &mapper.GoalAlreadyClaimedError{
+				GoalID:    goalID,
+				ClaimedAt: mapper.FormatOptionalTimestamp(current.ClaimedAt),
+			}
+		}
+
+		from = ""
+		if current != nil {
+			from = current.Status
+		}
+		if err := progressstate.Transition(from, domain.GoalStatusCompleted, progressstate.Flags{}); err != nil {
+			return err
+		}
+
+		if opts.Force {
+			return nil
+		}
+
+		if reason := notLiveReason(current, goal, now); reason != "" {
+			return &mapper.GoalOverrideRefusedError{
+				GoalID:      goalID,
+				ChallengeID: goal.ChallengeID,
+				Reason:      reason,
+			}
+		}
+
+		return nil
+	}
+
+	progress, err := goalAdmin.ForceComplete(ctx, &serviceRepo.ForcedCompletion{
+		UserID:       userID,
+		GoalID:       goalID,
+		ChallengeID:  goal.ChallengeID,
+		Namespace:    namespace,
+		ProgressMode: goal.Requirement.ProgressMode,
+		TargetValue:  goal.Requirement.TargetValue,
+		StepTargets:  steps.Targets(goalID),
+		ActorUserID:  adminID,
+		ClientIP:     opts.ClientIP,
+		Reason:       opts.Reason,
+		Forced:       opts.Force,
+		AutoClaim:    opts.AutoClaim,
+	}, check)
+	if err != nil {
+		var refused *mapper.GoalOverrideRefusedError
+		var claimed *mapper.GoalAlreadyClaimedError
+		var illegal *progressstate.IllegalTransitionError
+		if stdErrors.As(err, &refused) || stdErrors.As(err, &claimed) || stdErrors.As(err, &illegal) {
+			return nil, err
+		}
+
+		// A row claimed after the check found none
+		var challengeErr *commonErrors.ChallengeError
+		if stdErrors.As(err, &challengeErr) && challengeErr.Code == commonErrors.ErrCodeGoalAlreadyClaimed {
+			return nil, &mapper.GoalAlreadyClaimedError{GoalID: goalID}
+		}
+
+		logger.WithFields(fields).WithError(err).Error("Failed to force-complete goal")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	logger.WithFields(fields).WithField("security_review", true).Info("Admin force-completed goal")
+	if from != domain.GoalStatusCompleted {
+		challengeMetricsFrom(ctx).GoalCompleted(goal.ChallengeID)
+	}
+
+	result := &ForceCompleteResult{
+		ChallengeID: goal.ChallengeID,
+		Progress:    progress,
+	}
+
+	if !opts.AutoClaim {
+		return result, nil
+	}
+
+	claim, err := ClaimGoalReward(ctx, userID, goalID, goal.ChallengeID, namespace, goalCache, repo, outbox, 0, rewardClient, SegmentTargets{}, ClaimPrecondition{}, logger)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Warn("Force-completed goal but auto-claim failed, goal left completed")
+		return nil, fmt.Errorf("goal completed but auto-claim failed: %w", err)
+	}
+	result.Claim = claim
+
+	return result, nil
+}
+
+// notLiveReason says why a goal is not live for the user, or returns "" if it is.
+// A row from an ended rotation period is reset on the user's next read, so
+// completing it would complete the old period.
+func notLiveReason(current *domain.UserGoalProgress, goal *domain.Goal, now time.Time) string {
+	switch {
+	case current == nil:
+		return "not assigned"
+	case !current.IsActive:
+		return "inactive"
+	case rotation.HasRotationOccurred(current, goal, now):
+		return "from an ended rotation period"
+	default:
+		return ""
+	}
+}
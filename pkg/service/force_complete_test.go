@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectForceComplete makes goalAdmin run the service's check against current
+// and return the check's error, or written when the check passes.
+func expectForceComplete(goalAdmin *mocks.GoalAdminRepository, current, written *domain.UserGoalProgress) *mock.Call {
+	call := goalAdmin.On("ForceComplete", mock.Anything, mock.Anything, mock.Anything)
+	call.Run(func(args mock.Arguments) {
+		check := args.Get(2).(func(*domain.UserGoalProgress) error)
+		if err := check(current); err != nil {
+			call.ReturnArguments = mock.Arguments{nil, err}
+			return
+		}
+		call.ReturnArguments = mock.Arguments{written, nil}
+	})
+	return call
+}
+
+func forceComplete(
+	goalCache *mocks.GoalCache,
+	goalAdmin *mocks.GoalAdminRepository,
+	opts ForceCompleteOptions,
+) (*ForceCompleteResult, error) {
+	return ForceCompleteGoal(context.Background(), "admin-1", "user123", "goal-1", "test-namespace", opts,
+		goalCache, nil, goalAdmin, new(mocks.GoalRepository), newClaimOutbox(), new(mocks.RewardClient), logrus.StandardLogger())
+}
+
+func TestForceCompleteGoal_Success(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	current := createCompletedProgress("user123", "goal-1", "challenge-1")
+	current.Status = domain.GoalStatusInProgress
+	current.Progress = 7
+	written := createCompletedProgress("user123", "goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	expectForceComplete(goalAdmin, current, written)
+
+	result, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket 123: kill not counted", ClientIP: "198.51.100.1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "challenge-1", result.ChallengeID)
+	assert.Equal(t, written, result.Progress)
+	assert.Nil(t, result.Claim)
+
+	completion := goalAdmin.Calls[0].Arguments.Get(1).(*repository.ForcedCompletion)
+	assert.Equal(t, &repository.ForcedCompletion{
+		UserID:       "user123",
+		GoalID:       "goal-1",
+		ChallengeID:  "challenge-1",
+		Namespace:    "test-namespace",
+		ProgressMode: domain.ProgressModeAbsolute,
+		TargetValue:  10,
+		ActorUserID:  "admin-1",
+		ClientIP:     "198.51.100.1",
+		Reason:       "ticket 123: kill not counted",
+	}, completion)
+}
+
+func TestForceCompleteGoal_RefusesClaimedGoal(t *testing.T) {
+	claimedAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	current := createCompletedProgress("user123", "goal-1", "challenge-1")
+	current.Status = domain.GoalStatusClaimed
+	current.ClaimedAt = &claimedAt
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(createClaimableGoal("goal-1", "challenge-1"))
+	goalAdmin := new(mocks.GoalAdminRepository)
+	expectForceComplete(goalAdmin, current, nil)
+
+	_, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket", Force: true})
+
+	var claimedErr *mapper.GoalAlreadyClaimedError
+	require.ErrorAs(t, err, &claimedErr, "force does not override claimed goals")
+	assert.Equal(t, "2025-03-10T12:00:00Z", claimedErr.ClaimedAt)
+}
+
+func TestForceCompleteGoal_IllegalTransition(t *testing.T) {
+	current := createCompletedProgress("user123", "goal-1", "challenge-1")
+	current.Status = domain.GoalStatus("archived")
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(createClaimableGoal("goal-1", "challenge-1"))
+	goalAdmin := new(mocks.GoalAdminRepository)
+	expectForceComplete(goalAdmin, current, nil)
+
+	_, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket", Force: true})
+
+	var illegal *progressstate.IllegalTransitionError
+	require.ErrorAs(t, err, &illegal)
+	assert.Equal(t, domain.GoalStatusCompleted, illegal.To)
+}
+
+func TestForceCompleteGoal_GoalNotLive(t *testing.T) {
+	inactive := createCompletedProgress("user123", "goal-1", "challenge-1")
+	inactive.IsActive = false
+
+	rotated := createCompletedProgress("user123", "goal-1", "challenge-1")
+	rotated.UpdatedAt = time.Now().UTC().AddDate(0, 0, -2)
+
+	tests := []struct {
+		name    string
+		current *domain.UserGoalProgress
+		reason  string
+	}{
+		{name: "not assigned", current: nil, reason: "not assigned"},
+		{name: "inactive", current: inactive, reason: "inactive"},
+		{name: "ended rotation period", current: rotated, reason: "from an ended rotation period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := createClaimableGoal("goal-1", "challenge-1")
+			goal.Rotation = &domain.RotationConfig{Enabled: true, Schedule: domain.RotationScheduleDaily}
+			written := createCompletedProgress("user123", "goal-1", "challenge-1")
+
+			mockCache := new(mocks.GoalCache)
+			mockCache.On("GetGoalByID", "goal-1").Return(goal)
+
+			goalAdmin := new(mocks.GoalAdminRepository)
+			expectForceComplete(goalAdmin, tt.current, written)
+			_, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket"})
+
+			var refused *mapper.GoalOverrideRefusedError
+			require.ErrorAs(t, err, &refused)
+			assert.Equal(t, tt.reason, refused.Reason)
+
+			forcedAdmin := new(mocks.GoalAdminRepository)
+			expectForceComplete(forcedAdmin, tt.current, written)
+			result, err := forceComplete(mockCache, forcedAdmin, ForceCompleteOptions{Reason: "ticket", Force: true})
+
+			require.NoError(t, err)
+			assert.Equal(t, written, result.Progress)
+			assert.True(t, forcedAdmin.Calls[0].Arguments.Get(1).(*repository.ForcedCompletion).Forced)
+		})
+	}
+}
+
+func TestForceCompleteGoal_AutoClaim(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	written := createCompletedProgress("user123", "goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	expectForceComplete(goalAdmin, createCompletedProgress("user123", "goal-1", "challenge-1"), written)
+
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(written, nil)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+
+	result, err := ForceCompleteGoal(context.Background(), "admin-1", "user123", "goal-1", "test-namespace",
+		ForceCompleteOptions{Reason: "ticket", AutoClaim: true},
+		mockCache, nil, goalAdmin, mockRepo, newClaimOutbox(), mockRewardClient, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Claim)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Claim.Status)
+	assert.True(t, goalAdmin.Calls[0].Arguments.Get(1).(*repository.ForcedCompletion).AutoClaim)
+	mockRewardClient.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestForceCompleteGoal_AutoClaimFailure(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	goal.Prerequisites = []string{"goal-0"}
+	written := createCompletedProgress("user123", "goal-1", "challenge-1")
+
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	goalAdmin := new(mocks.GoalAdminRepository)
+	expectForceComplete(goalAdmin, written, written)
+
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").Return(written, nil)
+	mockTxRepo.On("GetGoalsByIDs", mock.Anything, "user123", []string{"goal-0"}).Return([]*domain.UserGoalProgress{}, nil)
+	mockTxRepo.On("Rollback").Return(nil)
+
+	_, err := ForceCompleteGoal(context.Background(), "admin-1", "user123", "goal-1", "test-namespace",
+		ForceCompleteOptions{Reason: "ticket", AutoClaim: true},
+		mockCache, nil, goalAdmin, mockRepo, newClaimOutbox(), new(mocks.RewardClient), logrus.StandardLogger())
+
+	var prereqErr *mapper.PrerequisitesNotMetError
+	require.ErrorAs(t, err, &prereqErr, "the claim path's rules still apply")
+	assert.ErrorContains(t, err, "goal completed but auto-claim failed")
+}
+
+func TestForceCompleteGoal_GoalNotFound(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(nil)
+	goalAdmin := new(mocks.GoalAdminRepository)
+
+	_, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket"})
+
+	var notFound *mapper.GoalNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+	goalAdmin.AssertNotCalled(t, "ForceComplete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForceCompleteGoal_MissingReason(t *testing.T) {
+	_, err := forceComplete(new(mocks.GoalCache), new(mocks.GoalAdminRepository), ForceCompleteOptions{})
+
+	assert.EqualError(t, err, "reason cannot be empty")
+}
+
+func TestForceCompleteGoal_RepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want func(t *testing.T, err error)
+	}{
+		{
+			name: "claimed concurrently",
+			err:  commonErrors.ErrGoalAlreadyClaimed("goal-1"),
+			want: func(t *testing.T, err error) {
+				var claimedErr *mapper.GoalAlreadyClaimedError
+				assert.ErrorAs(t, err, &claimedErr)
+			},
+		},
+		{
+			name: "database error",
+			err:  commonErrors.ErrDatabaseError("force complete goal", errors.New("connection refused")),
+			want: func(t *testing.T, err error) {
+				assert.Equal(t, mapper.ErrDatabaseError, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(mocks.GoalCache)
+			mockCache.On("GetGoalByID", "goal-1").Return(createClaimableGoal("goal-1", "challenge-1"))
+			goalAdmin := new(mocks.GoalAdminRepository)
+			goalAdmin.On("ForceComplete", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			_, err := forceComplete(mockCache, goalAdmin, ForceCompleteOptions{Reason: "ticket"})
+
+			tt.want(t, err)
+		})
+	}
+}
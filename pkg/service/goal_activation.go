@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// HasUnmetPrerequisites reports whether any of the goal's prerequisites is not
+// completed or claimed in userProgress. A prerequisite without a row is unmet.
+func HasUnmetPrerequisites(goal *domain.Goal, userProgress map[string]*domain.UserGoalProgress) bool {
+	for _, prereqID := range goal.Prerequisites {
+		progress := userProgress[prereqID]
+		// Prerequisite not met if not completed or claimed
+		if progress == nil || (progress.Status != domain.GoalStatusCompleted && progress.Status != domain.GoalStatusClaimed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActivatable reports whether the user can usefully activate the goal: it is
+// not already active, not completed or claimed, and its prerequisites are met.
+//
+// There is no cap on the number of active goals, so nothing else limits activation.
+func IsActivatable(goal *domain.Goal, userProgress map[string]*domain.UserGoalProgress) bool {
+	if goal == nil || !awaitsActivation(userProgress[goal.ID]) {
+		return false
+	}
+
+	return !HasUnmetPrerequisites(goal, userProgress)
+}
+
+// ActivatableGoals returns the IDs of the activatable goals (see IsActivatable)
+// for the response mappers. Goals that are not activatable have no entry.
+//
+// userProgress must hold the user's rows for the goals and their prerequisites;
+// use WithActivationPrerequisites first when it only holds part of them.
+func ActivatableGoals(goals []*domain.Goal, userProgress map[string]*domain.UserGoalProgress) map[string]bool {
+	activatable := make(map[string]bool)
+	for _, goal := range goals {
+		if IsActivatable(goal, userProgress) {
+			activatable[goal.ID] = true
+		}
+	}
+
+	return activatable
+}
+
+// MissingActivationPrerequisites returns the prerequisite IDs of goals awaiting
+// activation that have no entry in progressMap.
+//
+// progressMap is expected to hold the rows of goals themselves, so a
+// prerequisite that is one of goals is not reported.
+func MissingActivationPrerequisites(goals []*domain.Goal, progressMap map[string]*domain.UserGoalProgress) []string {
+	var missing []string
+	seen := make(map[string]bool, len(goals))
+	for _, goal := range goals {
+		if goal != nil {
+			seen[goal.ID] = true
+		}
+	}
+	for _, goal := range goals {
+		if goal == nil || !awaitsActivation(progressMap[goal.ID]) {
+			continue
+		}
+		for _, prereqID := range goal.Prerequisites {
+			if progressMap[prereqID] == nil && !seen[prereqID] {
+				seen[prereqID] = true
+				missing = append(missing, prereqID)
+			}
+		}
+	}
+
+	return missing
+}
+
+// WithActivationPrerequisites returns progressMap extended with the rows returned
+// by MissingActivationPrerequisites, loaded in one query. It is for progress maps
+// limited to one challenge or page, whose goals can depend on goals outside it.
+// progressMap itself is not modified and is returned as-is when nothing is missing.
+func WithActivationPrerequisites(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	userID string,
+	goals []*domain.Goal,
+	progressMap map[string]*domain.UserGoalProgress,
+) (map[string]*domain.UserGoalProgress, error) {
+	missing := MissingActivationPrerequisites(goals, progressMap)
+	if len(missing) == 0 {
+		return progressMap, nil
+	}
+
+	rows, err := repo.GetGoalsByIDs(ctx, userID, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goal prerequisites: %w", err)
+	}
+
+	merged := make(map[string]*domain.UserGoalProgress, len(progressMap)+len(rows))
+	for goalID, progress := range progressMap {
+		merged[goalID] = progress
+	}
+	for _, row := range rows {
+		merged[row.GoalID] = row
+	}
+	return merged, nil
+}
+
+// awaitsActivation reports whether a goal with this progress row (nil when the
+// user has none) is neither active nor finished.
+func awaitsActivation(progress *domain.UserGoalProgress) bool {
+	if progress == nil {
+		return true
+	}
+
+	return !progress.IsActive && progress.Status != domain.GoalStatusCompleted && progress.Status != domain.GoalStatusClaimed
+}
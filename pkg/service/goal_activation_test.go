@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasUnmetPrerequisites(t *testing.T) {
+	goal := &domain.Goal{ID: "g3", Prerequisites: []string{"g1", "g2"}}
+
+	tests := []struct {
+		name     string
+		goal     *domain.Goal
+		progress map[string]*domain.UserGoalProgress
+		want     bool
+	}{
+		{"no prerequisites", &domain.Goal{ID: "g1"}, nil, false},
+		{"prerequisites without rows", goal, nil, true},
+		{"one prerequisite in progress", goal, map[string]*domain.UserGoalProgress{
+			"g1": {Status: domain.GoalStatusClaimed},
+			"g2": {Status: domain.GoalStatusInProgress},
+		}, true},
+		{"prerequisites completed or claimed", goal, map[string]*domain.UserGoalProgress{
+			"g1": {Status: domain.GoalStatusClaimed},
+			"g2": {Status: domain.GoalStatusCompleted},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasUnmetPrerequisites(tt.goal, tt.progress))
+		})
+	}
+}
+
+func TestIsActivatable(t *testing.T) {
+	goal := &domain.Goal{ID: "g2", Prerequisites: []string{"g1"}}
+	prereqMet := &domain.UserGoalProgress{Status: domain.GoalStatusCompleted}
+
+	tests := []struct {
+		name string
+		own  *domain.UserGoalProgress
+		pre  *domain.UserGoalProgress
+		want bool
+	}{
+		{"no row, prerequisite met", nil, prereqMet, true},
+		{"no row, prerequisite unmet", nil, nil, false},
+		{"inactive in progress", &domain.UserGoalProgress{Status: domain.GoalStatusInProgress}, prereqMet, true},
+		{"already active", &domain.UserGoalProgress{Status: domain.GoalStatusNotStarted, IsActive: true}, prereqMet, false},
+		{"inactive completed", &domain.UserGoalProgress{Status: domain.GoalStatusCompleted}, prereqMet, false},
+		{"inactive claimed", &domain.UserGoalProgress{Status: domain.GoalStatusClaimed}, prereqMet, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := map[string]*domain.UserGoalProgress{}
+			if tt.own != nil {
+				progress["g2"] = tt.own
+			}
+			if tt.pre != nil {
+				progress["g1"] = tt.pre
+			}
+
+			assert.Equal(t, tt.want, IsActivatable(goal, progress))
+		})
+	}
+}
+
+func TestActivatableGoals(t *testing.T) {
+	goals := []*domain.Goal{
+		{ID: "free"},
+		{ID: "active"},
+		{ID: "locked", Prerequisites: []string{"active"}},
+	}
+	progress := map[string]*domain.UserGoalProgress{
+		"active": {GoalID: "active", Status: domain.GoalStatusInProgress, IsActive: true},
+	}
+
+	assert.Equal(t, map[string]bool{"free": true}, ActivatableGoals(goals, progress))
+}
+
+func TestWithActivationPrerequisites(t *testing.T) {
+	goals := []*domain.Goal{
+		{ID: "waiting", Prerequisites: []string{"known", "elsewhere", "sibling"}},
+		{ID: "active", Prerequisites: []string{"skipped"}},
+		{ID: "sibling"},
+	}
+	progressMap := map[string]*domain.UserGoalProgress{
+		"known":  {GoalID: "known", Status: domain.GoalStatusClaimed},
+		"active": {GoalID: "active", IsActive: true},
+	}
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"elsewhere"}).Return([]*domain.UserGoalProgress{
+		{GoalID: "elsewhere", Status: domain.GoalStatusCompleted},
+	}, nil)
+
+	merged, err := WithActivationPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.False(t, IsActivatable(goals[0], merged), "sibling has no row")
+	assert.Contains(t, merged, "elsewhere")
+	assert.Len(t, progressMap, 2, "input map must not be modified")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWithActivationPrerequisites_NothingMissing(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g2", Prerequisites: []string{"g1"}}}
+	progressMap := map[string]*domain.UserGoalProgress{"g1": {GoalID: "g1"}}
+	mockRepo := new(mocks.GoalRepository)
+
+	merged, err := WithActivationPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.Equal(t, progressMap, merged)
+	mockRepo.AssertNotCalled(t, "GetGoalsByIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWithActivationPrerequisites_QueryFails(t *testing.T) {
+	goals := []*domain.Goal{{ID: "g2", Prerequisites: []string{"g1"}}}
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"g1"}).Return(nil, errors.New("db down"))
+
+	_, err := WithActivationPrerequisites(context.Background(), mockRepo, "user1", goals, nil)
+
+	assert.ErrorContains(t, err, "failed to load goal prerequisites")
+}
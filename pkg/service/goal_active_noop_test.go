@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// These tests run the selection flows against the real PostgreSQL repository
+// on top of sqlmock, so any UPDATE/INSERT or transaction that is not expected
+// fails the test.
+
+var progressColumns = []string{
+	"user_id", "goal_id", "challenge_id", "namespace", "progress", "status",
+	"completed_at", "claimed_at", "created_at", "updated_at",
+	"is_active", "assigned_at", "expires_at", "baseline_value",
+}
+
+func addProgressRow(rows *sqlmock.Rows, userID, goalID, challengeID string, isActive bool) *sqlmock.Rows {
+	now := time.Now().UTC()
+	var assignedAt interface{}
+	if isActive {
+		assignedAt = now
+	}
+	return rows.AddRow(userID, goalID, challengeID, "test-namespace", 3, "in_progress",
+		nil, nil, now, now, isActive, assignedAt, nil, nil)
+}
+
+// pgArrayArg matches a pq.Array argument by its PostgreSQL text form, e.g. {"goal-2"}.
+type pgArrayArg string
+
+func (a pgArrayArg) Match(v driver.Value) bool {
+	switch s := v.(type) {
+	case string:
+		return s == string(a)
+	case []byte:
+		return string(s) == string(a)
+	}
+	return false
+}
+
+func newSelectionChallenge(challengeID string, goalIDs ...string) *domain.Challenge {
+	challenge := &domain.Challenge{ID: challengeID, Name: "Test Challenge"}
+	for _, id := range goalIDs {
+		challenge.Goals = append(challenge.Goals, &domain.Goal{
+			ID:          id,
+			Name:        "Goal " + id,
+			ChallengeID: challengeID,
+			Requirement: domain.Requirement{TargetValue: 10},
+		})
+	}
+	return challenge
+}
+
+func newSelectionCache(challenge *domain.Challenge) *mocks.GoalCache {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetChallengeByChallengeID", challenge.ID).Return(challenge)
+	for _, goal := range challenge.Goals {
+		mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	}
+	return mockCache
+}
+
+func TestBatchSelectGoals_AlreadyActive_NoWrite(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1", "goal-2")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	addProgressRow(rows, "user123", "goal-2", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(rows)
+	// No Begin/UPDATE expected
+
+	result, err := BatchSelectGoals(context.Background(), "user123", "challenge1",
+		[]string{"goal-1", "goal-2"}, false, "test-namespace",
+		newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.RequestedCount)
+	assert.Equal(t, 0, result.ChangedCount)
+	assert.Equal(t, 2, result.TotalActiveGoals)
+	assert.Empty(t, result.ReplacedGoals)
+	assert.Equal(t, "in_progress", result.SelectedGoals[0].Status, "unchanged goals keep their stored state")
+	for _, goal := range result.SelectedGoals {
+		assert.False(t, goal.Changed)
+	}
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestBatchSelectGoals_OnlyTransitionsWritten(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1", "goal-2")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	addProgressRow(rows, "user123", "goal-2", "challenge1", false)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(rows)
+	sqlMock.ExpectBegin()
+	// Only goal-2 flips; goal-1 is already active
+	sqlMock.ExpectExec("UPDATE user_goal_progress SET").
+		WithArgs("user123", pgArrayArg(`{"goal-2"}`), pgArrayArg("{t}")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The total is read back in the transaction
+	active := sqlmock.NewRows(progressColumns)
+	addProgressRow(active, "user123", "goal-1", "challenge1", true)
+	addProgressRow(active, "user123", "goal-2", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress .+ is_active = true").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(active)
+	sqlMock.ExpectCommit()
+
+	result, err := BatchSelectGoals(context.Background(), "user123", "challenge1",
+		[]string{"goal-1", "goal-2"}, false, "test-namespace",
+		newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.RequestedCount)
+	assert.Equal(t, 1, result.ChangedCount)
+	assert.Equal(t, 2, result.TotalActiveGoals)
+	require.Len(t, result.SelectedGoals, 2)
+	assert.False(t, result.SelectedGoals[0].Changed)
+	assert.True(t, result.SelectedGoals[1].Changed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestBatchSelectGoals_TotalActiveReadInTransaction(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1", "goal-2")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	addProgressRow(rows, "user123", "goal-2", "challenge1", false)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(rows)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("UPDATE user_goal_progress SET").
+		WithArgs("user123", pgArrayArg(`{"goal-2"}`), pgArrayArg("{t}")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// goal-1 was deactivated after the read; the total is what was committed,
+	// not the earlier read plus the activations
+	active := sqlmock.NewRows(progressColumns)
+	addProgressRow(active, "user123", "goal-2", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress .+ is_active = true").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(active)
+	sqlMock.ExpectCommit()
+
+	result, err := BatchSelectGoals(context.Background(), "user123", "challenge1",
+		[]string{"goal-2"}, false, "test-namespace",
+		newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ChangedCount)
+	assert.Equal(t, 1, result.TotalActiveGoals)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestBatchSelectGoals_ReplaceKeepsReselectedGoals(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1", "goal-2", "goal-3")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	addProgressRow(rows, "user123", "goal-2", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(rows)
+	sqlMock.ExpectBegin()
+	// goal-2 is deactivated; goal-1 stays active without a write; goal-3 is activated
+	sqlMock.ExpectExec("UPDATE user_goal_progress SET").
+		WithArgs("user123", pgArrayArg(`{"goal-2"}`), pgArrayArg("{f}")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec("UPDATE user_goal_progress SET").
+		WithArgs("user123", pgArrayArg(`{"goal-3"}`), pgArrayArg("{t}")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	active := sqlmock.NewRows(progressColumns)
+	addProgressRow(active, "user123", "goal-1", "challenge1", true)
+	addProgressRow(active, "user123", "goal-3", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress .+ is_active = true").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(active)
+	sqlMock.ExpectCommit()
+
+	result, err := BatchSelectGoals(context.Background(), "user123", "challenge1",
+		[]string{"goal-1", "goal-3"}, true, "test-namespace",
+		newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"goal-2"}, result.ReplacedGoals)
+	assert.Equal(t, 1, result.ChangedCount)
+	assert.Equal(t, 2, result.TotalActiveGoals)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestRandomSelectGoals_AllSelectedAlreadyActive_NoWrite(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "challenge1").
+		WillReturnRows(rows)
+
+	result, err := RandomSelectGoals(context.Background(), "user123", "challenge1",
+		1, false, false, false, "test-namespace",
+		newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.RequestedCount)
+	assert.Equal(t, 0, result.ChangedCount)
+	assert.Equal(t, 1, result.TotalActiveGoals)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestSetGoalActive_Unchanged_NoUpdate(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "goal-1").
+		WillReturnRows(rows)
+
+	result, err := SetGoalActive(context.Background(), "user123", "challenge1", "goal-1",
+		"test-namespace", true, newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.NotNil(t, result.AssignedAt)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestSetGoalActive_Transition_IssuesUpdate(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", true)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "goal-1").
+		WillReturnRows(rows)
+	sqlMock.ExpectExec("UPDATE user_goal_progress SET").
+		WithArgs(false, "user123", "goal-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := SetGoalActive(context.Background(), "user123", "challenge1", "goal-1",
+		"test-namespace", false, newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+// Activating a goal only flips is_active/assigned_at. Progress reported while the
+// goal was inactive (and not tracked, see InactiveProgressPolicy) is not applied.
+func TestSetGoalActive_Activation_DoesNotApplyMissedProgress(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	challenge := newSelectionChallenge("challenge1", "goal-1")
+	rows := sqlmock.NewRows(progressColumns)
+	addProgressRow(rows, "user123", "goal-1", "challenge1", false)
+	sqlMock.ExpectQuery("SELECT .+ FROM user_goal_progress").
+		WithArgs("user123", "goal-1").
+		WillReturnRows(rows)
+	sqlMock.ExpectExec(`UPDATE user_goal_progress SET\s+is_active = \$1,\s+assigned_at = CASE\s+WHEN \$1 = true THEN NOW\(\)\s+ELSE assigned_at\s+END,\s+updated_at = NOW\(\)\s+WHERE`).
+		WithArgs(true, "user123", "goal-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := SetGoalActive(context.Background(), "user123", "challenge1", "goal-1",
+		"test-namespace", true, newSelectionCache(challenge), commonRepo.NewPostgresGoalRepository(db), logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Reasons a goal's config is refused at runtime (ConfigInvalidError.Reason).
+const (
+	GoalConfigNonPositiveTarget   = "non_positive_target"
+	GoalConfigNonPositiveQuantity = "non_positive_reward_quantity"
+)
+
+// Where a misconfigured goal was refused (the site label of invalid_goal_config_total).
+const (
+	goalGuardClaim             = "claim"
+	goalGuardSelection         = "selection"
+	goalGuardDefaultAssignment = "default_assignment"
+	goalGuardBulkActivation    = "bulk_activation"
+)
+
+// invalidGoalConfig counts the goals refused for a config that config validation
+// rejects at load time. It is package-level because the guarded paths are
+// functions, not types; main registers it through GoalConfigGuardCollectors.
+var invalidGoalConfig = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "invalid_goal_config_total",
+	Help: "Goals refused at runtime for a non-positive target or reward quantity, by goal, reason and site.",
+}, []string{"goal_id", "reason", "site"})
+
+// GoalConfigGuardCollectors returns the misconfigured goal metrics for registration.
+func GoalConfigGuardCollectors() []prometheus.Collector {
+	return []prometheus.Collector{invalidGoalConfig}
+}
+
+// goalTargetProblem returns why a goal with target cannot be assigned, selected
+// or claimed, or "" if it can. A goal with a non-positive target would be
+// completed as soon as it is assigned.
+func goalTargetProblem(target int) string {
+	if target <= 0 {
+		return GoalConfigNonPositiveTarget
+	}
+	return ""
+}
+
+// refuseGoal logs and counts a goal refused at site and returns the error for it.
+func refuseGoal(goal *domain.Goal, reason, site string, logger logrus.FieldLogger) *mapper.ConfigInvalidError {
+	invalidGoalConfig.WithLabelValues(goal.ID, reason, site).Inc()
+	logger.WithFields(logrus.Fields{
+		"goal_id":         goal.ID,
+		"challenge_id":    goal.ChallengeID,
+		"target_value":    goal.Requirement.TargetValue,
+		"reward_quantity": goal.Reward.Quantity,
+		"reason":          reason,
+		"site":            site,
+	}).Error("Refused misconfigured goal; fix the challenge config")
+
+	return &mapper.ConfigInvalidError{
+		GoalID:      goal.ID,
+		ChallengeID: goal.ChallengeID,
+		Reason:      reason,
+	}
+}
+
+// checkGoalAssignable returns a ConfigInvalidError if goal's configured target
+// is not positive, so the goal must not be selected or assigned at site.
+func checkGoalAssignable(goal *domain.Goal, site string, logger logrus.FieldLogger) error {
+	if reason := goalTargetProblem(goal.Requirement.TargetValue); reason != "" {
+		return refuseGoal(goal, reason, site, logger)
+	}
+	return nil
+}
+
+// checkGoalClaimable returns a ConfigInvalidError if the goal's reward cannot be
+// claimed: its target (the segment's) or its reward quantity is not positive.
+// It runs before the claim reserves the goal, so a refused claim leaves the
+// goal claimable once the config is fixed.
+func checkGoalClaimable(goal *domain.Goal, target int, logger logrus.FieldLogger) error {
+	if reason := goalClaimProblem(goal, target); reason != "" {
+		return refuseGoal(goal, reason, goalGuardClaim, logger)
+	}
+	return nil
+}
+
+// assignableGoals returns goals without the misconfigured ones. goals is
+// returned as is when none are, which is the normal case.
+func assignableGoals(goals []*domain.Goal, site string, logger logrus.FieldLogger) []*domain.Goal {
+	for i, goal := range goals {
+		if goalTargetProblem(goal.Requirement.TargetValue) == "" {
+			continue
+		}
+
+		valid := append(make([]*domain.Goal, 0, len(goals)-1), goals[:i]...)
+		for _, goal := range goals[i:] {
+			if checkGoalAssignable(goal, site, logger) == nil {
+				valid = append(valid, goal)
+			}
+		}
+		return valid
+	}
+	return goals
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func guardGoal(id string, target, quantity int) *domain.Goal {
+	return &domain.Goal{
+		ID:              id,
+		ChallengeID:     "c1",
+		DefaultAssigned: true,
+		Requirement:     domain.Requirement{TargetValue: target},
+		Reward:          domain.Reward{Type: "ITEM", RewardID: "sword", Quantity: quantity},
+	}
+}
+
+func TestAssignableGoals(t *testing.T) {
+	valid := []*domain.Goal{guardGoal("a", 1, 1), guardGoal("b", 5, 1)}
+	assert.Same(t, &valid[0], &assignableGoals(valid, goalGuardSelection, logrus.StandardLogger())[0], "valid goals are not copied")
+
+	before := testutil.ToFloat64(invalidGoalConfig.WithLabelValues("zero", GoalConfigNonPositiveTarget, goalGuardDefaultAssignment))
+	goals := []*domain.Goal{guardGoal("a", 1, 1), guardGoal("zero", 0, 1), guardGoal("negative", -1, 1), guardGoal("b", 5, 1)}
+
+	got := assignableGoals(goals, goalGuardDefaultAssignment, logrus.StandardLogger())
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].ID)
+	assert.Equal(t, "b", got[1].ID)
+	assert.Equal(t, "zero", goals[1].ID, "the input is left unchanged")
+	assert.Equal(t, before+1, testutil.ToFloat64(invalidGoalConfig.WithLabelValues("zero", GoalConfigNonPositiveTarget, goalGuardDefaultAssignment)))
+}
+
+func TestClaimGoalReward_ConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		goal       *domain.Goal
+		wantReason string
+	}{
+		{"zero target", guardGoal("g1", 0, 1), GoalConfigNonPositiveTarget},
+		{"zero quantity", guardGoal("g1", 3, 0), GoalConfigNonPositiveQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache := new(mocks.GoalCache)
+			mockCache.On("GetGoalByID", "g1").Return(tt.goal)
+			mockRepo := new(mocks.GoalRepository)
+			mockRewardClient := new(mocks.RewardClient)
+
+			_, err := ClaimGoalReward(context.Background(), "user123", "g1", "c1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+			var configErr *mapper.ConfigInvalidError
+			require.ErrorAs(t, err, &configErr)
+			assert.Equal(t, tt.wantReason, configErr.Reason)
+			mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+			mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestClaimGoalReward_ConfigInvalid_SegmentTargetWins(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "g1").Return(guardGoal("g1", 0, 1))
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("BeginTx", mock.Anything).Return(nil, mapper.ErrDatabaseError)
+	targets := TargetOverrides{"g1": {"vip": 5}}.For("vip")
+
+	_, err := ClaimGoalReward(context.Background(), "user123", "g1", "c1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, new(mocks.RewardClient), targets, ClaimPrecondition{}, logrus.StandardLogger())
+
+	// A positive segment target makes the goal claimable: the claim gets as far as the database
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+}
+
+func TestFilterAvailableGoals_ExcludesMisconfiguredGoals(t *testing.T) {
+	goals := []*domain.Goal{guardGoal("a", 1, 1), guardGoal("zero", 0, 1)}
+
+	assert.Equal(t, []string{"a"}, filterAvailableGoals(goals, map[string]*domain.UserGoalProgress{}, false, logrus.StandardLogger()))
+}
+
+func TestBatchSelectGoals_RejectsMisconfiguredGoal(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetChallengeByChallengeID", "c1").Return(&domain.Challenge{ID: "c1"})
+	mockCache.On("GetGoalByID", "zero").Return(guardGoal("zero", 0, 1))
+	mockRepo := new(mocks.GoalRepository)
+
+	_, err := BatchSelectGoals(context.Background(), "user123", "c1", []string{"zero"}, false, "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+
+	var configErr *mapper.ConfigInvalidError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "zero", configErr.GoalID)
+	mockRepo.AssertNotCalled(t, "GetChallengeProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInitializePlayer_SkipsMisconfiguredDefaultGoals(t *testing.T) {
+	valid := guardGoal("valid", 1, 1)
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*domain.Goal{valid, guardGoal("zero", 0, 1)})
+	mockCache.On("GetGoalByID", "valid").Return(valid)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user123").Return(0, nil)
+	mockRepo.On("BulkInsert", mock.Anything, mock.MatchedBy(func(rows []*domain.UserGoalProgress) bool {
+		return len(rows) == 1 && rows[0].GoalID == "valid"
+	})).Return(nil)
+
+	resp, err := InitializePlayer(context.Background(), "user123", "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.NewAssignments)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GoalIDCollision is a goal ID used by more than one goal of the challenge config.
+type GoalIDCollision struct {
+	GoalID string `json:"goalId"`
+	// ChallengeIDs lists the challenge of every goal using the ID, in config
+	// order; a challenge appears twice when it repeats the ID itself.
+	ChallengeIDs []string `json:"challengeIds"`
+}
+
+// goalIDsConfig is the subset of challenges.json needed to find goal ID collisions.
+type goalIDsConfig struct {
+	Challenges []struct {
+		ID    string `json:"challengeId"`
+		Goals []struct {
+			ID string `json:"goalId"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadGoalIDCollisions reads the goal ID collisions of the challenge config file.
+func LoadGoalIDCollisions(configPath string) ([]GoalIDCollision, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseGoalIDCollisions(data)
+}
+
+// ParseGoalIDCollisions lists the goal IDs that challenge config JSON uses for
+// more than one goal, sorted by goal ID.
+//
+// Goals are looked up, stored and claimed by goal ID alone, so a goal reusing
+// the ID of a goal in another challenge would shadow it. The common config
+// loader rejects such configs with only the duplicate ID; this report names
+// every challenge involved, so the config can be fixed in one pass.
+func ParseGoalIDCollisions(data []byte) ([]GoalIDCollision, error) {
+	var cfg goalIDsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	challengesByGoal := make(map[string][]string)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			challengesByGoal[goal.ID] = append(challengesByGoal[goal.ID], challenge.ID)
+		}
+	}
+
+	var collisions []GoalIDCollision
+	for goalID, challengeIDs := range challengesByGoal {
+		if len(challengeIDs) > 1 {
+			collisions = append(collisions, GoalIDCollision{GoalID: goalID, ChallengeIDs: challengeIDs})
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i].GoalID < collisions[j].GoalID })
+
+	return collisions, nil
+}
+
+// CheckGoalIDCollisions fails if the challenge config file uses a goal ID for
+// more than one goal, listing every collision.
+func CheckGoalIDCollisions(configPath string) error {
+	collisions, err := LoadGoalIDCollisions(configPath)
+	if err != nil {
+		return err
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(collisions))
+	for _, collision := range collisions {
+		descriptions = append(descriptions, fmt.Sprintf("%q in challenges %s",
+			collision.GoalID, strings.Join(collision.ChallengeIDs, ", ")))
+	}
+	return fmt.Errorf("goal IDs must be unique across challenges; %d reused: %s",
+		len(collisions), strings.Join(descriptions, "; "))
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collidingGoalsConfig = `{"challenges": [
+	{"challengeId": "daily", "goals": [{"goalId": "daily-login"}, {"goalId": "win-3"}]},
+	{"challengeId": "weekly", "goals": [{"goalId": "daily-login"}, {"goalId": "win-10"}]},
+	{"challengeId": "events", "goals": [{"goalId": "win-10"}, {"goalId": "win-10"}]}
+]}`
+
+func TestParseGoalIDCollisions(t *testing.T) {
+	collisions, err := ParseGoalIDCollisions([]byte(collidingGoalsConfig))
+
+	require.NoError(t, err)
+	assert.Equal(t, []GoalIDCollision{
+		{GoalID: "daily-login", ChallengeIDs: []string{"daily", "weekly"}},
+		{GoalID: "win-10", ChallengeIDs: []string{"weekly", "events", "events"}},
+	}, collisions)
+}
+
+func TestParseGoalIDCollisions_None(t *testing.T) {
+	collisions, err := ParseGoalIDCollisions([]byte(`{"challenges": [{"challengeId": "daily", "goals": [{"goalId": "a"}, {"goalId": "b"}]}]}`))
+
+	require.NoError(t, err)
+	assert.Empty(t, collisions)
+
+	_, err = ParseGoalIDCollisions([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestCheckGoalIDCollisions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(collidingGoalsConfig), 0o600))
+
+	err := CheckGoalIDCollisions(path)
+
+	require.Error(t, err)
+	assert.Equal(t, `goal IDs must be unique across challenges; 2 reused: "daily-login" in challenges daily, weekly; `+
+		`"win-10" in challenges weekly, events, events`, err.Error())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"challenges": [{"challengeId": "daily", "goals": [{"goalId": "a"}]}]}`), 0o600))
+	assert.NoError(t, CheckGoalIDCollisions(path))
+	assert.Error(t, CheckGoalIDCollisions(filepath.Join(t.TempDir(), "missing.json")))
+}
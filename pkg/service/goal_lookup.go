@@ -0,0 +1,36 @@
+package service
+
+import (
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// GetGoal returns the goal goalID of challenge challengeID from the config.
+//
+// Requests name a goal by its challenge and goal IDs, and are validated
+// against the pair: a goal that is not in the config fails with
+// mapper.GoalNotFoundError, and one configured under another challenge fails
+// with mapper.GoalInOtherChallengeError naming that challenge. Goal IDs are
+// unique across challenges (see CheckGoalIDCollisions), so the goal cache's
+// lookup by goal ID finds the only candidate.
+func GetGoal(goalCache cache.GoalCache, challengeID, goalID string) (*domain.Goal, error) {
+	goal := goalCache.GetGoalByID(goalID)
+	if goal == nil {
+		return nil, &mapper.GoalNotFoundError{
+			GoalID:      goalID,
+			ChallengeID: challengeID,
+		}
+	}
+
+	if goal.ChallengeID != challengeID {
+		return nil, &mapper.GoalInOtherChallengeError{
+			GoalID:          goalID,
+			ChallengeID:     challengeID,
+			GoalChallengeID: goal.ChallengeID,
+		}
+	}
+
+	return goal, nil
+}
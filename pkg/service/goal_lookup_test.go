@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGoal(t *testing.T) {
+	goal := &domain.Goal{ID: "daily-login", ChallengeID: "daily"}
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "daily-login").Return(goal)
+	goalCache.On("GetGoalByID", "missing").Return(nil)
+
+	got, err := GetGoal(goalCache, "daily", "daily-login")
+	require.NoError(t, err)
+	assert.Same(t, goal, got)
+
+	_, err = GetGoal(goalCache, "weekly", "daily-login")
+	var otherChallenge *mapper.GoalInOtherChallengeError
+	require.ErrorAs(t, err, &otherChallenge)
+	assert.Equal(t, &mapper.GoalInOtherChallengeError{GoalID: "daily-login", ChallengeID: "weekly", GoalChallengeID: "daily"}, otherChallenge)
+
+	_, err = GetGoal(goalCache, "daily", "missing")
+	var notFound *mapper.GoalNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "daily", notFound.ChallengeID)
+}
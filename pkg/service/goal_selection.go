@@ -11,6 +11,9 @@ import (
 	"math/big"
 	"time"
 
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/cache"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/AccelByte/extend-challenge-common/pkg/errors"
@@ -26,6 +29,20 @@ type GoalSelectionResult struct {
 	ChallengeID      string
 	TotalActiveGoals int
 	ReplacedGoals    []string
+	// RequestedCount is the number of goals requested for activation.
+	RequestedCount int
+	// AvailablePoolSize is the number of goals a random selection could draw
+	// from (see filterAvailableGoals), before this selection was applied.
+	AvailablePoolSize int
+	// Partial is set when a random selection activated fewer goals than
+	// requested because the pool was smaller.
+	Partial bool
+	// ChangedCount is the number of selected goals that were not already active
+	// (activations actually written).
+	ChangedCount int
+	// ActivatedGoalIDs are the selected goals that were not already active, in
+	// selection order.
+	ActivatedGoalIDs []string
 }
 
 // SelectedGoalInfo contains detailed information about a selected goal.
@@ -41,6 +58,12 @@ type SelectedGoalInfo struct {
 	IsActive    bool
 	AssignedAt  *time.Time
 	ExpiresAt   *time.Time
+	// ActivationSource is how the goal was last activated (see
+	// ResolveSelectionActivationSources); empty when unknown.
+	ActivationSource string
+	// Changed is false when the goal was already active, so its row (and
+	// AssignedAt) was left as it was.
+	Changed bool
 }
 
 // RandomSelectGoals randomly selects N goals from a challenge and activates them.
@@ -50,12 +73,13 @@ type SelectedGoalInfo struct {
 // Algorithm:
 //  1. Validate challenge exists
 //  2. Get user's current progress
-//  3. Filter available goals (exclude completed/claimed/prerequisites)
-//  4. Handle insufficient goals (return partial results)
+//  3. Filter available goals (exclude completed/claimed/prerequisites and
+//     repeatable goals cooling down)
+//  4. Handle insufficient goals (return partial results, or fail if strict)
 //  5. Random sample using crypto/rand (Fisher-Yates shuffle)
-//  6. Database transaction:
-//     a. Deactivate existing (if replace mode)
-//     b. Activate selected goals (BATCH operation)
+//  6. Database transaction (skipped when nothing changes):
+//     a. Deactivate existing goals that were not selected (if replace mode)
+//     b. Activate selected goals that are not already active (BATCH operation)
 //  7. Build response with goal details
 //
 // Parameters:
@@ -65,9 +89,13 @@ type SelectedGoalInfo struct {
 //   - count: Number of goals to select
 //   - replaceExisting: Whether to deactivate existing active goals first
 //   - excludeActive: Whether to exclude already-active goals from selection pool
+//   - strict: Whether to fail instead of returning partial results
 //   - namespace: Namespace (extracted from JWT)
 //   - goalCache: In-memory config cache for goal validation
 //   - repo: Database repository for persisting changes
+//   - selections: Writes the selection and records it in the selection history;
+//     nil writes through repo without history
+//   - logger: Logger for the selection's log entries
 //
 // Returns:
 //   - GoalSelectionResult with selected goals and metadata
@@ -77,10 +105,12 @@ type SelectedGoalInfo struct {
 //   - Challenge not found: returns error
 //   - Invalid count (<= 0): returns error
 //   - No goals available (after filtering): returns error
+//   - Fewer goals available than requested with strict: returns *mapper.InsufficientGoalsError
 //   - Database error: returns wrapped error
 //
 // Notes:
 //   - If fewer goals available than requested (but > 0), returns partial results
+//     with Partial set, unless strict
 //   - Uses BatchUpsertGoalActive for performance (~10ms for 10 goals)
 //   - Deactivated goals keep their progress (not reset)
 func RandomSelectGoals(
@@ -90,9 +120,12 @@ func RandomSelectGoals(
 	count int,
 	replaceExisting bool,
 	excludeActive bool,
+	strict bool,
 	namespace string,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	selections serviceRepo.GoalSelectionRepository,
+	logger logrus.FieldLogger,
 ) (*GoalSelectionResult, error) {
 	// Early return validation
 	if userID == "" {
@@ -119,10 +152,14 @@ func RandomSelectGoals(
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
 	// 1. Validate challenge exists
 	challenge := goalCache.GetChallengeByChallengeID(challengeID)
 	if challenge == nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -133,7 +170,7 @@ func RandomSelectGoals(
 	// 2. Get user's current progress
 	userProgress, err := repo.GetChallengeProgress(ctx, userID, challengeID, false)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -148,15 +185,43 @@ func RandomSelectGoals(
 		progressMap[p.GoalID] = p
 	}
 
+	// Reset claimed repeatable goals first, so those past their cooldown are available
+	repeats, err := challengeGoalRepeats(ctx, userID, challenge.Goals, progressMap)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"namespace":    namespace,
+			"error":        err,
+		}).Error("Failed to get repeatable goal states")
+		return nil, err
+	}
+
 	// 3. Filter available goals
 	// When replace_existing=true, exclude currently active goals from selection
 	// to ensure we select completely new goals (not reselecting ones we'll deactivate)
 	shouldExcludeActive := excludeActive || replaceExisting
-	availableGoalIDs := filterAvailableGoals(challenge.Goals, progressMap, shouldExcludeActive, goalCache)
+	availableGoalIDs := withoutCoolingDown(
+		filterAvailableGoals(challenge.Goals, progressMap, shouldExcludeActive, logger), repeats, time.Now().UTC())
 
 	// 4. Handle insufficient goals
+	if strict && len(availableGoalIDs) < count {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"namespace":    namespace,
+			"requested":    count,
+			"available":    len(availableGoalIDs),
+		}).Info("Fewer goals available than requested, rejecting strict selection")
+		return nil, &mapper.InsufficientGoalsError{
+			ChallengeID: challengeID,
+			Requested:   count,
+			Available:   len(availableGoalIDs),
+		}
+	}
+
 	if len(availableGoalIDs) == 0 {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -169,7 +234,7 @@ func RandomSelectGoals(
 	// Return partial results if fewer available than requested
 	actualCount := count
 	if len(availableGoalIDs) < count {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -182,7 +247,7 @@ func RandomSelectGoals(
 	// 5. Random sample using crypto/rand
 	selectedGoalIDs, err := randomSample(availableGoalIDs, actualCount)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -192,130 +257,49 @@ func RandomSelectGoals(
 		return nil, fmt.Errorf("failed to random sample goals: %w", err)
 	}
 
-	// 6. Database transaction
-	tx, err := repo.BeginTx(ctx)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": challengeID,
-			"namespace":    namespace,
-			"error":        err,
-		}).Error("Failed to begin transaction")
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
-		if err := tx.Rollback(); err != nil {
-			// Rollback can fail if transaction already committed, which is fine
-			logrus.WithError(err).Debug("Transaction rollback (expected if already committed)")
-		}
-	}()
-
-	// 7. Deactivate existing (if replace mode)
-	replacedGoals := []string{}
-	if replaceExisting {
-		activeGoals := getActiveGoalIDs(progressMap)
-		if len(activeGoals) > 0 {
-			// Deactivate ALL active goals when replacing
-			// (selectedGoalIDs will only contain inactive goals since we excluded active ones above)
-			deactivateBatch := make([]*domain.UserGoalProgress, len(activeGoals))
-			now := time.Now().UTC()
-			for i, goalID := range activeGoals {
-				deactivateBatch[i] = &domain.UserGoalProgress{
-					UserID:      userID,
-					GoalID:      goalID,
-					ChallengeID: challengeID,
-					Namespace:   namespace,
-					IsActive:    false,
-					AssignedAt:  &now, // Keep timestamp but mark inactive
-				}
-			}
-
-			// Use batch operation for deactivation
-			err = tx.BatchUpsertGoalActive(ctx, deactivateBatch)
-			if err != nil {
-				logrus.WithFields(logrus.Fields{
-					"user_id":      userID,
-					"challenge_id": challengeID,
-					"namespace":    namespace,
-					"goal_count":   len(activeGoals),
-					"error":        err,
-				}).Error("Failed to deactivate goals")
-				return nil, fmt.Errorf("failed to deactivate goals: %w", err)
-			}
-
-			replacedGoals = activeGoals
-		}
-	}
-
-	// 8. Activate selected goals (BATCH operation for performance)
+	// 6. Only write actual transitions: selected goals that are not yet active,
+	//    and (replace mode) active goals that were not selected
 	now := time.Now().UTC()
-	goalBatch := make([]*domain.UserGoalProgress, len(selectedGoalIDs))
-	for i, goalID := range selectedGoalIDs {
-		goalBatch[i] = &domain.UserGoalProgress{
-			UserID:      userID,
-			GoalID:      goalID,
-			ChallengeID: challengeID,
-			Namespace:   namespace,
-			IsActive:    true,
-			AssignedAt:  &now,
-			ExpiresAt:   rotation.CalculateNextExpiresAt(goalCache.GetGoalByID(goalID), now),
-			Progress:    0,
-			Status:      domain.GoalStatusNotStarted,
-		}
-	}
-
-	// Single batch operation instead of N queries
-	err = tx.BatchUpsertGoalActive(ctx, goalBatch)
+	toActivate, toDeactivate := activeTransitions(selectedGoalIDs, progressMap, replaceExisting)
+	selection := &serviceRepo.GoalSelection{
+		UserID:          userID,
+		Namespace:       namespace,
+		ChallengeID:     challengeID,
+		SelectedGoalIDs: selectedGoalIDs,
+		Activate:        toActivate,
+		Deactivate:      toDeactivate,
+		Source:          ActivationSourceRandom,
+		ClientContext:   clientContextFrom(ctx),
+	}
+	totalActive, err := applyActiveTransitions(ctx, repo, selections, selection, len(getActiveGoalIDs(progressMap)), goalCache, now, logger)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": challengeID,
-			"namespace":    namespace,
-			"goal_count":   len(selectedGoalIDs),
-			"error":        err,
-		}).Error("Failed to batch activate goals")
-		return nil, fmt.Errorf("failed to batch activate goals: %w", err)
-	}
-
-	// 9. Commit transaction
-	if err = tx.Commit(); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": challengeID,
-			"namespace":    namespace,
-			"error":        err,
-		}).Error("Failed to commit transaction")
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
 	}
 
-	// 10. Build response with goal details
+	// 7. Build response with goal details
 	selectedGoalDetails := buildGoalDetails(challenge, selectedGoalIDs, &now)
-	totalActive := len(selectedGoalIDs)
-	if !replaceExisting {
-		// Add existing active count
-		totalActive += len(getActiveGoalIDs(progressMap))
-		// Subtract any that were already active and got re-activated
-		for _, goalID := range selectedGoalIDs {
-			if progressMap[goalID] != nil && progressMap[goalID].IsActive {
-				totalActive--
-			}
-		}
-	}
+	applyUnchangedProgress(selectedGoalDetails, progressMap)
 
-	logrus.WithFields(logrus.Fields{
+	logger.WithFields(logrus.Fields{
 		"user_id":      userID,
 		"challenge_id": challengeID,
 		"namespace":    namespace,
 		"selected":     len(selectedGoalIDs),
+		"changed":      len(toActivate),
 		"total_active": totalActive,
-		"replaced":     len(replacedGoals),
+		"replaced":     len(toDeactivate),
 	}).Info("Successfully completed random goal selection")
 
 	return &GoalSelectionResult{
-		SelectedGoals:    selectedGoalDetails,
-		ChallengeID:      challengeID,
-		TotalActiveGoals: totalActive,
-		ReplacedGoals:    replacedGoals,
+		SelectedGoals:     selectedGoalDetails,
+		ChallengeID:       challengeID,
+		TotalActiveGoals:  totalActive,
+		ReplacedGoals:     toDeactivate,
+		RequestedCount:    count,
+		AvailablePoolSize: len(availableGoalIDs),
+		Partial:           actualCount < count,
+		ChangedCount:      len(toActivate),
+		ActivatedGoalIDs:  toActivate,
 	}, nil
 }
 
@@ -325,9 +309,9 @@ func RandomSelectGoals(
 //
 // Flow:
 //  1. Validate all goal IDs exist in the challenge
-//  2. Database transaction:
-//     a. Deactivate existing (if replace mode)
-//     b. Activate selected goals (BATCH operation)
+//  2. Database transaction (skipped when nothing changes):
+//     a. Deactivate existing goals that were not selected (if replace mode)
+//     b. Activate selected goals that are not already active (BATCH operation)
 //  3. Build response with goal details
 //
 // Parameters:
@@ -339,6 +323,9 @@ func RandomSelectGoals(
 //   - namespace: Namespace (extracted from JWT)
 //   - goalCache: In-memory config cache for goal validation
 //   - repo: Database repository for persisting changes
+//   - selections: Writes the selection and records it in the selection history;
+//     nil writes through repo without history
+//   - logger: Logger for the selection's log entries
 //
 // Returns:
 //   - GoalSelectionResult with selected goals and metadata
@@ -348,6 +335,7 @@ func RandomSelectGoals(
 //   - Empty goal list: returns error
 //   - Goal not found in config: returns error
 //   - Goal not in specified challenge: returns error
+//   - Repeatable goal cooling down: returns *mapper.GoalCoolingDownError
 //   - Database error: returns wrapped error
 func BatchSelectGoals(
 	ctx context.Context,
@@ -358,6 +346,8 @@ func BatchSelectGoals(
 	namespace string,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	selections serviceRepo.GoalSelectionRepository,
+	logger logrus.FieldLogger,
 ) (*GoalSelectionResult, error) {
 	// Early return validation
 	if userID == "" {
@@ -384,10 +374,14 @@ func BatchSelectGoals(
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
 	// 1. Validate challenge exists
 	challenge := goalCache.GetChallengeByChallengeID(challengeID)
 	if challenge == nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -397,33 +391,27 @@ func BatchSelectGoals(
 
 	// 2. Validate all goals exist and belong to this challenge
 	for _, goalID := range goalIDs {
-		goal := goalCache.GetGoalByID(goalID)
-		if goal == nil {
-			logrus.WithFields(logrus.Fields{
+		goal, err := GetGoal(goalCache, challengeID, goalID)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
 				"user_id":      userID,
 				"challenge_id": challengeID,
 				"goal_id":      goalID,
 				"namespace":    namespace,
-			}).Warn("Goal not found in config")
-			return nil, fmt.Errorf("goal '%s' not found", goalID)
+				"error":        err,
+			}).Warn("Goal not found in challenge")
+			return nil, err
 		}
 
-		if goal.ChallengeID != challengeID {
-			logrus.WithFields(logrus.Fields{
-				"user_id":             userID,
-				"requested_challenge": challengeID,
-				"actual_challenge":    goal.ChallengeID,
-				"goal_id":             goalID,
-				"namespace":           namespace,
-			}).Warn("Goal does not belong to specified challenge")
-			return nil, fmt.Errorf("goal '%s' does not belong to challenge '%s'", goalID, challengeID)
+		if err := checkGoalAssignable(goal, goalGuardSelection, logger); err != nil {
+			return nil, err
 		}
 	}
 
 	// 3. Get user's current progress (for replace mode and total count)
 	userProgress, err := repo.GetChallengeProgress(ctx, userID, challengeID, false)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
@@ -438,128 +426,309 @@ func BatchSelectGoals(
 		progressMap[p.GoalID] = p
 	}
 
-	// 4. Database transaction
+	// Reset claimed repeatable goals first; those cooling down cannot be selected
+	now := time.Now().UTC()
+	repeats, err := challengeGoalRepeats(ctx, userID, challenge.Goals, progressMap)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"namespace":    namespace,
+			"error":        err,
+		}).Error("Failed to get repeatable goal states")
+		return nil, err
+	}
+	if err := coolingDownError(repeats, goalIDs, now); err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"namespace":    namespace,
+		}).WithError(err).Info("Batch selection rejected: goal cooling down")
+		return nil, err
+	}
+
+	// 4. Only write actual transitions: requested goals that are not yet active,
+	//    and (replace mode) active goals that were not requested
+	toActivate, toDeactivate := activeTransitions(goalIDs, progressMap, replaceExisting)
+	selection := &serviceRepo.GoalSelection{
+		UserID:          userID,
+		Namespace:       namespace,
+		ChallengeID:     challengeID,
+		SelectedGoalIDs: goalIDs,
+		Activate:        toActivate,
+		Deactivate:      toDeactivate,
+		Source:          ActivationSourceManual,
+		ClientContext:   clientContextFrom(ctx),
+	}
+	totalActive, err := applyActiveTransitions(ctx, repo, selections, selection, len(getActiveGoalIDs(progressMap)), goalCache, now, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Build response with goal details
+	selectedGoalDetails := buildGoalDetails(challenge, goalIDs, &now)
+	applyUnchangedProgress(selectedGoalDetails, progressMap)
+
+	logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": challengeID,
+		"namespace":    namespace,
+		"selected":     len(goalIDs),
+		"changed":      len(toActivate),
+		"total_active": totalActive,
+		"replaced":     len(toDeactivate),
+	}).Info("Successfully completed batch goal selection")
+
+	return &GoalSelectionResult{
+		SelectedGoals:    selectedGoalDetails,
+		ChallengeID:      challengeID,
+		TotalActiveGoals: totalActive,
+		ReplacedGoals:    toDeactivate,
+		RequestedCount:   len(goalIDs),
+		// The pool a random selection with the same replace mode would draw from
+		AvailablePoolSize: len(withoutCoolingDown(filterAvailableGoals(challenge.Goals, progressMap, replaceExisting, logger), repeats, now)),
+		ChangedCount:      len(toActivate),
+		ActivatedGoalIDs:  toActivate,
+	}, nil
+}
+
+// AvailableGoals returns the IDs of the challenge's goals a random selection
+// could draw from for the user, in config order (see filterAvailableGoals),
+// without repeatable goals cooling down. excludeActive also leaves out active goals, as random selections with
+// exclude_active or replace_existing do.
+func AvailableGoals(
+	ctx context.Context,
+	userID string,
+	challengeID string,
+	excludeActive bool,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
+) ([]string, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	challenge := goalCache.GetChallengeByChallengeID(challengeID)
+	if challenge == nil {
+		return nil, fmt.Errorf("challenge '%s' not found", challengeID)
+	}
+
+	userProgress, err := repo.GetChallengeProgress(ctx, userID, challengeID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user progress: %w", err)
+	}
+
+	progressMap := make(map[string]*domain.UserGoalProgress, len(userProgress))
+	for _, p := range userProgress {
+		progressMap[p.GoalID] = p
+	}
+
+	repeats, err := challengeGoalRepeats(ctx, userID, challenge.Goals, progressMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutCoolingDown(filterAvailableGoals(challenge.Goals, progressMap, excludeActive, logger), repeats, time.Now().UTC()), nil
+}
+
+// activeTransitions diffs a selection against the user's current progress.
+//
+// Returns:
+//   - toActivate: selected goals that are not currently active (deduplicated, in selection order)
+//   - toDeactivate: when replaceExisting, currently active goals that were not selected
+//
+// Goals already in the target state are left out so no write is issued for them.
+func activeTransitions(
+	selectedGoalIDs []string,
+	progressMap map[string]*domain.UserGoalProgress,
+	replaceExisting bool,
+) (toActivate []string, toDeactivate []string) {
+	selected := make(map[string]bool, len(selectedGoalIDs))
+	toActivate = []string{}
+	for _, goalID := range selectedGoalIDs {
+		if selected[goalID] {
+			continue
+		}
+		selected[goalID] = true
+
+		if progress := progressMap[goalID]; progress == nil || !progress.IsActive {
+			toActivate = append(toActivate, goalID)
+		}
+	}
+
+	toDeactivate = []string{}
+	if replaceExisting {
+		for _, goalID := range getActiveGoalIDs(progressMap) {
+			if !selected[goalID] {
+				toDeactivate = append(toDeactivate, goalID)
+			}
+		}
+	}
+
+	return toActivate, toDeactivate
+}
+
+// applyActiveTransitions writes the selection's activations and deactivations in
+// one transaction, and returns the user's active goals in the challenge counted
+// in that transaction after the writes.
+//
+// With selections, the selection is also recorded in the selection history in
+// that transaction, even when nothing changed. Without it, a selection that
+// changes nothing starts no transaction at all and returns activeBefore, the
+// active goals of the progress just read.
+func applyActiveTransitions(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	selections serviceRepo.GoalSelectionRepository,
+	selection *serviceRepo.GoalSelection,
+	activeBefore int,
+	goalCache cache.GoalCache,
+	now time.Time,
+	logger logrus.FieldLogger,
+) (int, error) {
+	userID := selection.UserID
+	challengeID := selection.ChallengeID
+	namespace := selection.Namespace
+	toActivate := selection.Activate
+	toDeactivate := selection.Deactivate
+
+	if selections != nil {
+		totalActive, err := selections.ApplySelection(ctx, selection)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": challengeID,
+				"namespace":    namespace,
+				"activate":     len(toActivate),
+				"deactivate":   len(toDeactivate),
+				"error":        err,
+			}).Error("Failed to apply goal selection")
+			return 0, fmt.Errorf("failed to apply goal selection: %w", err)
+		}
+		return totalActive, nil
+	}
+
+	if len(toActivate) == 0 && len(toDeactivate) == 0 {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"namespace":    namespace,
+		}).Debug("Goal selection is a no-op, skipping write")
+		return activeBefore, nil
+	}
+
 	tx, err := repo.BeginTx(ctx)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
 			"error":        err,
 		}).Error("Failed to begin transaction")
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil {
-			logrus.WithError(err).Debug("Transaction rollback (expected if already committed)")
+			// Rollback can fail if transaction already committed, which is fine
+			logger.WithError(err).Debug("Transaction rollback (expected if already committed)")
 		}
 	}()
 
-	// 5. Deactivate existing (if replace mode)
-	replacedGoals := []string{}
-	if replaceExisting {
-		activeGoals := getActiveGoalIDs(progressMap)
-		if len(activeGoals) > 0 {
-			// Deactivate by setting is_active = false for all active goals
-			deactivateBatch := make([]*domain.UserGoalProgress, len(activeGoals))
-			now := time.Now().UTC()
-			for i, goalID := range activeGoals {
-				deactivateBatch[i] = &domain.UserGoalProgress{
-					UserID:      userID,
-					GoalID:      goalID,
-					ChallengeID: challengeID,
-					Namespace:   namespace,
-					IsActive:    false,
-					AssignedAt:  &now,
-				}
-			}
-
-			err = tx.BatchUpsertGoalActive(ctx, deactivateBatch)
-			if err != nil {
-				logrus.WithFields(logrus.Fields{
-					"user_id":      userID,
-					"challenge_id": challengeID,
-					"namespace":    namespace,
-					"goal_count":   len(activeGoals),
-					"error":        err,
-				}).Error("Failed to deactivate goals")
-				return nil, fmt.Errorf("failed to deactivate goals: %w", err)
+	// Deactivate replaced goals (progress is kept, not reset)
+	if len(toDeactivate) > 0 {
+		deactivateBatch := make([]*domain.UserGoalProgress, len(toDeactivate))
+		for i, goalID := range toDeactivate {
+			deactivateBatch[i] = &domain.UserGoalProgress{
+				UserID:      userID,
+				GoalID:      goalID,
+				ChallengeID: challengeID,
+				Namespace:   namespace,
+				IsActive:    false,
 			}
+		}
 
-			replacedGoals = activeGoals
+		if err := tx.BatchUpsertGoalActive(ctx, deactivateBatch); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": challengeID,
+				"namespace":    namespace,
+				"goal_count":   len(toDeactivate),
+				"error":        err,
+			}).Error("Failed to deactivate goals")
+			return 0, fmt.Errorf("failed to deactivate goals: %w", err)
 		}
 	}
 
-	// 6. Activate selected goals (BATCH operation for performance)
-	now := time.Now().UTC()
-	goalBatch := make([]*domain.UserGoalProgress, len(goalIDs))
-	for i, goalID := range goalIDs {
-		goalBatch[i] = &domain.UserGoalProgress{
-			UserID:      userID,
-			GoalID:      goalID,
-			ChallengeID: challengeID,
-			Namespace:   namespace,
-			IsActive:    true,
-			AssignedAt:  &now,
-			ExpiresAt:   rotation.CalculateNextExpiresAt(goalCache.GetGoalByID(goalID), now),
-			Progress:    0,
-			Status:      domain.GoalStatusNotStarted,
+	// Activate newly selected goals (BATCH operation for performance)
+	if len(toActivate) > 0 {
+		goalBatch := make([]*domain.UserGoalProgress, len(toActivate))
+		for i, goalID := range toActivate {
+			goalBatch[i] = &domain.UserGoalProgress{
+				UserID:      userID,
+				GoalID:      goalID,
+				ChallengeID: challengeID,
+				Namespace:   namespace,
+				IsActive:    true,
+				AssignedAt:  &now,
+				ExpiresAt:   rotation.CalculateNextExpiresAt(goalCache.GetGoalByID(goalID), now),
+				Progress:    0,
+				Status:      domain.GoalStatusNotStarted,
+			}
+		}
+
+		if err := tx.BatchUpsertGoalActive(ctx, goalBatch); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"challenge_id": challengeID,
+				"namespace":    namespace,
+				"goal_count":   len(toActivate),
+				"error":        err,
+			}).Error("Failed to batch activate goals")
+			return 0, fmt.Errorf("failed to batch activate goals: %w", err)
 		}
 	}
 
-	// Single batch operation instead of N queries
-	err = tx.BatchUpsertGoalActive(ctx, goalBatch)
+	totalActive, err := serviceRepo.CountActiveGoals(ctx, tx, userID, challengeID)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
-			"goal_count":   len(goalIDs),
 			"error":        err,
-		}).Error("Failed to batch activate goals")
-		return nil, fmt.Errorf("failed to batch activate goals: %w", err)
+		}).Error("Failed to count active goals")
+		return 0, fmt.Errorf("failed to count active goals: %w", err)
 	}
 
-	// 7. Commit transaction
-	if err = tx.Commit(); err != nil {
-		logrus.WithFields(logrus.Fields{
+	if err := tx.Commit(); err != nil {
+		logger.WithFields(logrus.Fields{
 			"user_id":      userID,
 			"challenge_id": challengeID,
 			"namespace":    namespace,
 			"error":        err,
 		}).Error("Failed to commit transaction")
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 8. Build response with goal details
-	selectedGoalDetails := buildGoalDetails(challenge, goalIDs, &now)
-	totalActive := len(goalIDs)
-	if !replaceExisting {
-		// Add existing active count
-		totalActive += len(getActiveGoalIDs(progressMap))
-		// Subtract any that were already active and got re-activated
-		for _, goalID := range goalIDs {
-			if progressMap[goalID] != nil && progressMap[goalID].IsActive {
-				totalActive--
-			}
-		}
-	}
+	return totalActive, nil
+}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":      userID,
-		"challenge_id": challengeID,
-		"namespace":    namespace,
-		"selected":     len(goalIDs),
-		"total_active": totalActive,
-		"replaced":     len(replacedGoals),
-	}).Info("Successfully completed batch goal selection")
+// applyUnchangedProgress replaces the "newly activated" defaults in goal details
+// with the stored state for goals that were already active, since their rows
+// were not rewritten.
+func applyUnchangedProgress(details []*SelectedGoalInfo, progressMap map[string]*domain.UserGoalProgress) {
+	for _, detail := range details {
+		progress := progressMap[detail.GoalID]
+		if progress == nil || !progress.IsActive {
+			continue
+		}
 
-	return &GoalSelectionResult{
-		SelectedGoals:    selectedGoalDetails,
-		ChallengeID:      challengeID,
-		TotalActiveGoals: totalActive,
-		ReplacedGoals:    replacedGoals,
-	}, nil
+		detail.Changed = false
+		detail.Status = string(progress.Status)
+		detail.Progress = progress.Progress
+		detail.AssignedAt = progress.AssignedAt
+		detail.ExpiresAt = progress.ExpiresAt
+	}
 }
 
 // filterAvailableGoals filters goals based on user progress and exclusion criteria.
@@ -568,6 +737,7 @@ func BatchSelectGoals(
 // - Exclude goals with status = 'claimed'
 // - Exclude goals with status = 'completed' (should claim first)
 // - Exclude goals with unmet prerequisites
+// - Exclude misconfigured goals (non-positive target)
 //
 // Optional filters:
 // - excludeActive: Exclude goals with is_active = true
@@ -576,7 +746,6 @@ func BatchSelectGoals(
 //   - allGoals: All goals in the challenge
 //   - userProgress: Map of goal_id -> UserGoalProgress
 //   - excludeActive: Whether to exclude already-active goals
-//   - goalCache: For prerequisite checking
 //
 // Returns:
 //   - Slice of available goal IDs
@@ -584,7 +753,7 @@ func filterAvailableGoals(
 	allGoals []*domain.Goal,
 	userProgress map[string]*domain.UserGoalProgress,
 	excludeActive bool,
-	goalCache cache.GoalCache,
+	logger logrus.FieldLogger,
 ) []string {
 	available := []string{}
 
@@ -602,7 +771,11 @@ func filterAvailableGoals(
 		}
 
 		// Skip goals with unmet prerequisites
-		if hasUnmetPrerequisites(goal, userProgress, goalCache) {
+		if HasUnmetPrerequisites(goal, userProgress) {
+			continue
+		}
+
+		if checkGoalAssignable(goal, goalGuardSelection, logger) != nil {
 			continue
 		}
 
@@ -612,35 +785,6 @@ func filterAvailableGoals(
 	return available
 }
 
-// hasUnmetPrerequisites checks if a goal has any unmet prerequisites.
-//
-// Parameters:
-//   - goal: The goal to check
-//   - userProgress: Map of goal_id -> UserGoalProgress
-//   - goalCache: For retrieving prerequisite goal definitions
-//
-// Returns:
-//   - true if goal has unmet prerequisites, false otherwise
-func hasUnmetPrerequisites(
-	goal *domain.Goal,
-	userProgress map[string]*domain.UserGoalProgress,
-	goalCache cache.GoalCache,
-) bool {
-	if len(goal.Prerequisites) == 0 {
-		return false
-	}
-
-	for _, prereqID := range goal.Prerequisites {
-		progress := userProgress[prereqID]
-		// Prerequisite not met if not completed or claimed
-		if progress == nil || (progress.Status != domain.GoalStatusCompleted && progress.Status != domain.GoalStatusClaimed) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // randomSample selects N random elements from a slice using Fisher-Yates shuffle.
 //
 // Uses crypto/rand for quality randomness (industry standard for non-cryptographic random selection).
@@ -740,6 +884,7 @@ func buildGoalDetails(
 			IsActive:    true,
 			AssignedAt:  assignedAt,
 			ExpiresAt:   rotation.CalculateNextExpiresAt(goal, time.Now().UTC()),
+			Changed:     true,
 		})
 	}
 
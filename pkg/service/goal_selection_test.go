@@ -10,15 +10,19 @@ import (
 	"testing"
 	"time"
 
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// NOTE: MockGoalCache and MockGoalRepository are defined in progress_query_test.go
-
 // Test RandomSelectGoals - Happy Path: Select 3 Random Goals
 func TestRandomSelectGoals_Success(t *testing.T) {
 	ctx := context.Background()
@@ -34,9 +38,9 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 	userProgress := []*domain.UserGoalProgress{}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, g := range challenge.Goals {
@@ -45,11 +49,12 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return(userProgress, nil)
 	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
 	mockTx.On("BatchUpsertGoalActive", ctx, mock.AnythingOfType("[]*domain.UserGoalProgress")).Return(nil)
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(count, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -58,6 +63,8 @@ func TestRandomSelectGoals_Success(t *testing.T) {
 	assert.Equal(t, challengeID, result.ChallengeID)
 	assert.Equal(t, count, result.TotalActiveGoals)
 	assert.Empty(t, result.ReplacedGoals)
+	assert.Equal(t, 10, result.AvailablePoolSize)
+	assert.False(t, result.Partial)
 
 	// Verify all selected goals are from the challenge
 	goalIDs := make(map[string]bool)
@@ -111,9 +118,9 @@ func TestRandomSelectGoals_ReplaceExisting(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, g := range challenge.Goals {
@@ -147,11 +154,12 @@ func TestRandomSelectGoals_ReplaceExisting(t *testing.T) {
 		}
 		return false
 	})).Return(nil).Once()
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(count, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute with replace_existing = true
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, true, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, true, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -202,9 +210,9 @@ func TestRandomSelectGoals_ExcludeActive(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, g := range challenge.Goals {
@@ -213,11 +221,12 @@ func TestRandomSelectGoals_ExcludeActive(t *testing.T) {
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return(userProgress, nil)
 	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
 	mockTx.On("BatchUpsertGoalActive", ctx, mock.AnythingOfType("[]*domain.UserGoalProgress")).Return(nil)
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(5, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute with exclude_active = true
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, true, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, true, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -249,9 +258,9 @@ func TestRandomSelectGoals_PartialResults(t *testing.T) {
 	userProgress := []*domain.UserGoalProgress{}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, g := range challenge.Goals {
@@ -260,22 +269,73 @@ func TestRandomSelectGoals_PartialResults(t *testing.T) {
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return(userProgress, nil)
 	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
 	mockTx.On("BatchUpsertGoalActive", ctx, mock.AnythingOfType("[]*domain.UserGoalProgress")).Return(nil)
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(3, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert - should return all 3 available goals (partial result)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, 3, len(result.SelectedGoals)) // Only 3 available
+	assert.Equal(t, count, result.RequestedCount)
+	assert.Equal(t, 3, result.AvailablePoolSize)
+	assert.True(t, result.Partial)
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 	mockTx.AssertExpectations(t)
 }
 
+// Test RandomSelectGoals - Strict Selection Fails Instead of Returning Partial Results
+func TestRandomSelectGoals_StrictInsufficientGoals(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 3)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	mockRepo.On("GetChallengeProgress", ctx, "user123", "daily-challenge", false).Return([]*domain.UserGoalProgress{}, nil)
+
+	result, err := RandomSelectGoals(ctx, "user123", "daily-challenge", 5, false, false, true, "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+
+	assert.Nil(t, result)
+	var insufficient *mapper.InsufficientGoalsError
+	require.ErrorAs(t, err, &insufficient)
+	assert.Equal(t, 5, insufficient.Requested)
+	assert.Equal(t, 3, insufficient.Available)
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+}
+
+func TestAvailableGoals(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 4)
+	now := time.Now()
+	userProgress := []*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: challenge.Goals[0].ID, Status: domain.GoalStatusClaimed, ClaimedAt: &now},
+		{UserID: "user123", GoalID: challenge.Goals[1].ID, Status: domain.GoalStatusInProgress, IsActive: true},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	mockCache.On("GetChallengeByChallengeID", "missing").Return(nil)
+	mockRepo.On("GetChallengeProgress", ctx, "user123", "daily-challenge", false).Return(userProgress, nil)
+
+	goalIDs, err := AvailableGoals(ctx, "user123", "daily-challenge", false, mockCache, mockRepo, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{challenge.Goals[1].ID, challenge.Goals[2].ID, challenge.Goals[3].ID}, goalIDs)
+
+	goalIDs, err = AvailableGoals(ctx, "user123", "daily-challenge", true, mockCache, mockRepo, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{challenge.Goals[2].ID, challenge.Goals[3].ID}, goalIDs)
+
+	_, err = AvailableGoals(ctx, "user123", "missing", false, mockCache, mockRepo, logrus.StandardLogger())
+	assert.ErrorContains(t, err, "not found")
+}
+
 // Test RandomSelectGoals - No Goals Available
 func TestRandomSelectGoals_NoGoalsAvailable(t *testing.T) {
 	ctx := context.Background()
@@ -315,14 +375,14 @@ func TestRandomSelectGoals_NoGoalsAvailable(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return(userProgress, nil)
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert - should return error (no goals available)
 	require.Error(t, err)
@@ -342,19 +402,26 @@ func TestRandomSelectGoals_ChallengeNotFound(t *testing.T) {
 	count := 3
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(nil)
+	logger, hook := logtest.NewNullLogger()
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logger)
 
 	// Assert
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "not found")
 
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Challenge not found in config", entry.Message)
+	assert.Equal(t, logrus.Fields{"user_id": userID, "challenge_id": challengeID, "namespace": namespace}, entry.Data)
+
 	mockCache.AssertExpectations(t)
 }
 
@@ -366,11 +433,11 @@ func TestRandomSelectGoals_InvalidCount(t *testing.T) {
 	namespace := "test-namespace"
 	count := 0 // Invalid
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -389,14 +456,14 @@ func TestRandomSelectGoals_DatabaseError(t *testing.T) {
 	challenge := createTestChallengeWithGoals(challengeID, 10)
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return([]*domain.UserGoalProgress(nil), errors.New("database error"))
 
 	// Execute
-	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, namespace, mockCache, mockRepo)
+	result, err := RandomSelectGoals(ctx, userID, challengeID, count, false, false, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -427,9 +494,9 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 	userProgress := []*domain.UserGoalProgress{}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, goalID := range goalIDs {
@@ -438,11 +505,12 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return(userProgress, nil)
 	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
 	mockTx.On("BatchUpsertGoalActive", ctx, mock.AnythingOfType("[]*domain.UserGoalProgress")).Return(nil)
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(3, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -450,6 +518,8 @@ func TestBatchSelectGoals_Success(t *testing.T) {
 	assert.Equal(t, 3, len(result.SelectedGoals))
 	assert.Equal(t, challengeID, result.ChallengeID)
 	assert.Equal(t, 3, result.TotalActiveGoals)
+	assert.Equal(t, 10, result.AvailablePoolSize)
+	assert.False(t, result.Partial)
 
 	// Verify correct goals were selected
 	selectedGoalIDs := make(map[string]bool)
@@ -481,15 +551,15 @@ func TestBatchSelectGoals_GoalNotFound(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockCache.On("GetGoalByID", challenge.Goals[0].ID).Return(challenge.Goals[0])
 	mockCache.On("GetGoalByID", "nonexistent-goal").Return(nil)
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -516,15 +586,15 @@ func TestBatchSelectGoals_GoalFromDifferentChallenge(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockCache.On("GetGoalByID", challenge.Goals[0].ID).Return(challenge.Goals[0])
 	mockCache.On("GetGoalByID", otherChallenge.Goals[0].ID).Return(otherChallenge.Goals[0])
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -541,11 +611,11 @@ func TestBatchSelectGoals_EmptyGoalList(t *testing.T) {
 	challengeID := "daily-challenge"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Execute with empty goal list
-	result, err := BatchSelectGoals(ctx, userID, challengeID, []string{}, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, []string{}, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -563,9 +633,9 @@ func TestBatchSelectGoals_BeginTransactionError(t *testing.T) {
 
 	challenge := createTestChallengeWithGoals(challengeID, 3)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, goalID := range goalIDs {
@@ -575,7 +645,7 @@ func TestBatchSelectGoals_BeginTransactionError(t *testing.T) {
 	mockRepo.On("BeginTx", ctx).Return(mockTx, errors.New("transaction error"))
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -596,9 +666,9 @@ func TestBatchSelectGoals_ActivationError(t *testing.T) {
 
 	challenge := createTestChallengeWithGoals(challengeID, 3)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, goalID := range goalIDs {
@@ -610,7 +680,7 @@ func TestBatchSelectGoals_ActivationError(t *testing.T) {
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -632,9 +702,9 @@ func TestBatchSelectGoals_CommitError(t *testing.T) {
 
 	challenge := createTestChallengeWithGoals(challengeID, 3)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	for _, goalID := range goalIDs {
@@ -643,11 +713,12 @@ func TestBatchSelectGoals_CommitError(t *testing.T) {
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return([]*domain.UserGoalProgress{}, nil)
 	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
 	mockTx.On("BatchUpsertGoalActive", ctx, mock.Anything).Return(nil)
+	mockTx.On("CountActiveGoals", ctx, userID, challengeID).Return(2, nil)
 	mockTx.On("Commit").Return(errors.New("commit error"))
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, false, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -688,9 +759,9 @@ func TestBatchSelectGoals_DeactivationError(t *testing.T) {
 		},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
-	mockTx := new(MockTxRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTx := new(mocks.TxRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockCache.On("GetGoalByID", "goal-C").Return(challenge.Goals[2])
@@ -701,7 +772,7 @@ func TestBatchSelectGoals_DeactivationError(t *testing.T) {
 	mockTx.On("Rollback").Return(nil)
 
 	// Execute with replace mode
-	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, true, namespace, mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, userID, challengeID, goalIDs, true, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -716,9 +787,9 @@ func TestBatchSelectGoals_DeactivationError(t *testing.T) {
 // Test BatchSelectGoals - Nil Cache
 func TestBatchSelectGoals_NilCache(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
-	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "namespace", nil, mockRepo)
+	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "namespace", nil, mockRepo, nil, logrus.StandardLogger())
 
 	require.Error(t, err)
 	assert.Nil(t, result)
@@ -728,22 +799,33 @@ func TestBatchSelectGoals_NilCache(t *testing.T) {
 // Test BatchSelectGoals - Nil Repository
 func TestBatchSelectGoals_NilRepository(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 
-	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "namespace", mockCache, nil)
+	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "namespace", mockCache, nil, nil, logrus.StandardLogger())
 
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "repository cannot be nil")
 }
 
+// Test BatchSelectGoals - Nil Logger
+func TestBatchSelectGoals_NilLogger(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "namespace", new(mocks.GoalCache), new(mocks.GoalRepository), nil, nil)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "logger cannot be nil")
+}
+
 // Test BatchSelectGoals - Empty UserID
 func TestBatchSelectGoals_EmptyUserID(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	result, err := BatchSelectGoals(ctx, "", "challenge", []string{"goal-A"}, false, "namespace", mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, "", "challenge", []string{"goal-A"}, false, "namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	require.Error(t, err)
 	assert.Nil(t, result)
@@ -753,10 +835,10 @@ func TestBatchSelectGoals_EmptyUserID(t *testing.T) {
 // Test BatchSelectGoals - Empty ChallengeID
 func TestBatchSelectGoals_EmptyChallengeID(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	result, err := BatchSelectGoals(ctx, "user123", "", []string{"goal-A"}, false, "namespace", mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, "user123", "", []string{"goal-A"}, false, "namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	require.Error(t, err)
 	assert.Nil(t, result)
@@ -766,10 +848,10 @@ func TestBatchSelectGoals_EmptyChallengeID(t *testing.T) {
 // Test BatchSelectGoals - Empty Namespace
 func TestBatchSelectGoals_EmptyNamespace(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "", mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, "user123", "challenge", []string{"goal-A"}, false, "", mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	require.Error(t, err)
 	assert.Nil(t, result)
@@ -779,27 +861,113 @@ func TestBatchSelectGoals_EmptyNamespace(t *testing.T) {
 // Test BatchSelectGoals - Challenge Not Found
 func TestBatchSelectGoals_ChallengeNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", "nonexistent").Return(nil)
+	logger, hook := logtest.NewNullLogger()
 
-	result, err := BatchSelectGoals(ctx, "user123", "nonexistent", []string{"goal-A"}, false, "namespace", mockCache, mockRepo)
+	result, err := BatchSelectGoals(ctx, "user123", "nonexistent", []string{"goal-A"}, false, "namespace", mockCache, mockRepo, nil, logger)
 
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "not found")
 
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, logrus.WarnLevel, hook.Entries[0].Level)
+	assert.Equal(t, "Challenge not found in config", hook.Entries[0].Message)
+	assert.Equal(t, "nonexistent", hook.Entries[0].Data["challenge_id"])
+
 	mockCache.AssertExpectations(t)
 }
 
+func TestBatchSelectGoals_RecordsSelection(t *testing.T) {
+	ctx := WithClientContext(context.Background(), ClientContext{"surface": "challenges_menu"})
+	challenge := createTestChallengeWithGoals("daily-challenge", 3)
+	goalIDs := []string{challenge.Goals[0].ID, challenge.Goals[2].ID}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	selections := new(mocks.GoalSelectionRepository)
+
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	for _, g := range challenge.Goals {
+		mockCache.On("GetGoalByID", g.ID).Return(g).Maybe()
+	}
+	mockRepo.On("GetChallengeProgress", ctx, "user123", "daily-challenge", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: challenge.Goals[0].ID, IsActive: true, Status: domain.GoalStatusInProgress},
+		{UserID: "user123", GoalID: challenge.Goals[1].ID, IsActive: true, Status: domain.GoalStatusInProgress},
+	}, nil)
+	selections.On("ApplySelection", ctx, &serviceRepo.GoalSelection{
+		UserID:          "user123",
+		Namespace:       "test-namespace",
+		ChallengeID:     "daily-challenge",
+		SelectedGoalIDs: goalIDs,
+		Activate:        []string{challenge.Goals[2].ID},
+		Deactivate:      []string{challenge.Goals[1].ID},
+		Source:          ActivationSourceManual,
+		ClientContext:   map[string]string{"surface": "challenges_menu"},
+	}).Return(2, nil)
+
+	result, err := BatchSelectGoals(ctx, "user123", "daily-challenge", goalIDs, true, "test-namespace", mockCache, mockRepo, selections, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{challenge.Goals[1].ID}, result.ReplacedGoals)
+	selections.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+}
+
+func TestRandomSelectGoals_RecordsNoOpSelection(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 1)
+	goalID := challenge.Goals[0].ID
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	selections := new(mocks.GoalSelectionRepository)
+
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	mockCache.On("GetGoalByID", goalID).Return(challenge.Goals[0]).Maybe()
+	mockRepo.On("GetChallengeProgress", ctx, "user123", "daily-challenge", false).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: goalID, IsActive: true, Status: domain.GoalStatusInProgress},
+	}, nil)
+	selections.On("ApplySelection", ctx, mock.MatchedBy(func(selection *serviceRepo.GoalSelection) bool {
+		return selection.Source == ActivationSourceRandom && len(selection.Activate) == 0 &&
+			len(selection.SelectedGoalIDs) == 1 && selection.SelectedGoalIDs[0] == goalID
+	})).Return(1, nil)
+
+	result, err := RandomSelectGoals(ctx, "user123", "daily-challenge", 1, false, false, false, "test-namespace", mockCache, mockRepo, selections, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ChangedCount)
+	selections.AssertExpectations(t)
+}
+
+func TestBatchSelectGoals_ApplySelectionError(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 2)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	selections := new(mocks.GoalSelectionRepository)
+
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	mockCache.On("GetGoalByID", challenge.Goals[0].ID).Return(challenge.Goals[0])
+	mockRepo.On("GetChallengeProgress", ctx, "user123", "daily-challenge", false).Return([]*domain.UserGoalProgress{}, nil)
+	selections.On("ApplySelection", ctx, mock.Anything).Return(0, errors.New("connection reset"))
+
+	result, err := BatchSelectGoals(ctx, "user123", "daily-challenge", []string{challenge.Goals[0].ID}, false, "test-namespace", mockCache, mockRepo, selections, logrus.StandardLogger())
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to apply goal selection")
+}
+
 // Test filterAvailableGoals - All Goals Available
 func TestFilterAvailableGoals_AllAvailable(t *testing.T) {
 	challenge := createTestChallengeWithGoals("test-challenge", 5)
 	userProgress := make(map[string]*domain.UserGoalProgress)
-	mockCache := new(MockGoalCache)
 
-	available := filterAvailableGoals(challenge.Goals, userProgress, false, mockCache)
+	available := filterAvailableGoals(challenge.Goals, userProgress, false, logrus.StandardLogger())
 
 	assert.Equal(t, 5, len(available))
 }
@@ -823,9 +991,8 @@ func TestFilterAvailableGoals_ExcludeCompleted(t *testing.T) {
 			ClaimedAt:   &now,
 		},
 	}
-	mockCache := new(MockGoalCache)
 
-	available := filterAvailableGoals(challenge.Goals, userProgress, false, mockCache)
+	available := filterAvailableGoals(challenge.Goals, userProgress, false, logrus.StandardLogger())
 
 	// Should exclude 2 completed/claimed goals
 	assert.Equal(t, 3, len(available))
@@ -853,9 +1020,8 @@ func TestFilterAvailableGoals_ExcludeActive(t *testing.T) {
 			AssignedAt: &now,
 		},
 	}
-	mockCache := new(MockGoalCache)
 
-	available := filterAvailableGoals(challenge.Goals, userProgress, true, mockCache)
+	available := filterAvailableGoals(challenge.Goals, userProgress, true, logrus.StandardLogger())
 
 	// Should exclude 2 active goals
 	assert.Equal(t, 3, len(available))
@@ -994,5 +1160,3 @@ func findGoalByID(challenge *domain.Challenge, goalID string) *domain.Goal {
 	}
 	return nil
 }
-
-// NOTE: MockTxRepository is defined in claim_test.go and reused here
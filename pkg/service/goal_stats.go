@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultGoalStatsTTL is how long computed goal completion stats are served from memory.
+const DefaultGoalStatsTTL = 5 * time.Minute
+
+// GoalStats serves per-goal completion counts (how many players have each goal
+// not started, in progress, completed or claimed) for tuning goal difficulty.
+//
+// Counting scans user_goal_progress, so results are cached for the TTL and
+// concurrent callers share one query. When metrics are enabled, every refresh
+// also sets the goal_progress_players gauge; it has one series per goal and
+// status, which is why it is off by default.
+type GoalStats struct {
+	repo      serviceRepo.ProgressQueryRepository
+	namespace string
+	ttl       time.Duration
+	logger    logrus.FieldLogger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	snapshot *GoalStatsSnapshot
+
+	players *prometheus.GaugeVec // nil unless metrics are enabled
+}
+
+// GoalStatsSnapshot is the result of one stats query.
+type GoalStatsSnapshot struct {
+	Goals       []*serviceRepo.GoalCompletionStats
+	GeneratedAt time.Time
+}
+
+// NewGoalStats creates goal stats for a namespace cached for ttl, logging
+// through logger. exportMetrics enables the per-goal Prometheus gauge.
+func NewGoalStats(
+	repo serviceRepo.ProgressQueryRepository,
+	namespace string,
+	ttl time.Duration,
+	exportMetrics bool,
+	logger logrus.FieldLogger,
+) *GoalStats {
+	g := &GoalStats{
+		repo:      repo,
+		namespace: namespace,
+		ttl:       ttl,
+		logger:    logger,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+
+	if exportMetrics {
+		g.players = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "goal_progress_players",
+			Help: "Players with a progress row for the goal, by status. Updated every GOAL_STATS_CACHE_TTL.",
+		}, []string{"challenge_id", "goal_id", "status"})
+	}
+
+	return g
+}
+
+// NewGoalStatsFromEnv creates goal stats configured by:
+//   - GOAL_STATS_CACHE_TTL: how long results are cached (default "5m")
+//   - GOAL_STATS_METRICS_ENABLED: "true" to export the per-goal gauge (default "false")
+func NewGoalStatsFromEnv(repo serviceRepo.ProgressQueryRepository, namespace string, logger logrus.FieldLogger) *GoalStats {
+	ttl := DefaultGoalStatsTTL
+	if value := common.GetEnv("GOAL_STATS_CACHE_TTL", ""); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			logger.Warnf("Invalid GOAL_STATS_CACHE_TTL %q, using %s", value, DefaultGoalStatsTTL)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	exportMetrics := strings.ToLower(common.GetEnv("GOAL_STATS_METRICS_ENABLED", "false")) == "true"
+
+	return NewGoalStats(repo, namespace, ttl, exportMetrics, logger)
+}
+
+// TTL returns how long results are cached.
+func (g *GoalStats) TTL() time.Duration {
+	return g.ttl
+}
+
+// MetricsEnabled reports whether the per-goal gauge is exported.
+func (g *GoalStats) MetricsEnabled() bool {
+	return g.players != nil
+}
+
+// Collectors returns the goal stats metrics for registration (none when disabled).
+func (g *GoalStats) Collectors() []prometheus.Collector {
+	if g.players == nil {
+		return nil
+	}
+	return []prometheus.Collector{g.players}
+}
+
+// Get returns the cached stats, querying the database when they are older than
+// the TTL or refresh is set.
+func (g *GoalStats) Get(ctx context.Context, refresh bool) (*GoalStatsSnapshot, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !refresh && g.snapshot != nil && g.now().Sub(g.snapshot.GeneratedAt) < g.ttl {
+		return g.snapshot, nil
+	}
+
+	goals, err := g.repo.GetGoalCompletionStats(ctx, g.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal completion stats: %w", err)
+	}
+
+	g.snapshot = &GoalStatsSnapshot{Goals: goals, GeneratedAt: g.now()}
+	g.updateMetrics(goals)
+
+	return g.snapshot, nil
+}
+
+// Run refreshes the stats every TTL until ctx is cancelled, so the gauge stays
+// current without admin calls. Only needed when metrics are enabled.
+func (g *GoalStats) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.ttl)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.Get(ctx, true); err != nil && ctx.Err() == nil {
+			g.logger.WithFields(logrus.Fields{
+				"namespace": g.namespace,
+				"error":     err,
+			}).Warn("Failed to refresh goal completion stats")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *GoalStats) updateMetrics(goals []*serviceRepo.GoalCompletionStats) {
+	if g.players == nil {
+		return
+	}
+
+	// Reset so goals removed from the config (or whose rows were deleted) disappear
+	g.players.Reset()
+	for _, goal := range goals {
+		g.players.WithLabelValues(goal.ChallengeID, goal.GoalID, "not_started").Set(float64(goal.NotStarted))
+		g.players.WithLabelValues(goal.ChallengeID, goal.GoalID, "in_progress").Set(float64(goal.InProgress))
+		g.players.WithLabelValues(goal.ChallengeID, goal.GoalID, "completed").Set(float64(goal.Completed))
+		g.players.WithLabelValues(goal.ChallengeID, goal.GoalID, "claimed").Set(float64(goal.Claimed))
+	}
+}
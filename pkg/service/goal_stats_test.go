@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGoalStats(repo repository.ProgressQueryRepository, exportMetrics bool, now *time.Time) *GoalStats {
+	goalStats := NewGoalStats(repo, "ns", time.Minute, exportMetrics, logrus.StandardLogger())
+	goalStats.now = func() time.Time { return *now }
+	return goalStats
+}
+
+func TestGoalStats_CachesForTTL(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := new(mocks.ProgressQueryRepository)
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return([]*repository.GoalCompletionStats{{ChallengeID: "daily", GoalID: "login", Completed: 3}}, nil).Twice()
+
+	goalStats := newTestGoalStats(repo, false, &now)
+
+	first, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, now, first.GeneratedAt)
+
+	now = now.Add(59 * time.Second)
+	cached, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+
+	now = now.Add(time.Second)
+	expired, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, now, expired.GeneratedAt)
+
+	repo.AssertNumberOfCalls(t, "GetGoalCompletionStats", 2)
+}
+
+func TestGoalStats_RefreshBypassesCache(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := new(mocks.ProgressQueryRepository)
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").Return([]*repository.GoalCompletionStats{}, nil)
+
+	goalStats := newTestGoalStats(repo, false, &now)
+
+	_, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	_, err = goalStats.Get(context.Background(), true)
+	require.NoError(t, err)
+
+	repo.AssertNumberOfCalls(t, "GetGoalCompletionStats", 2)
+}
+
+func TestGoalStats_ErrorKeepsPreviousSnapshot(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := new(mocks.ProgressQueryRepository)
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return([]*repository.GoalCompletionStats{{GoalID: "login"}}, nil).Once()
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return(nil, errors.New("db down")).Once()
+
+	goalStats := newTestGoalStats(repo, false, &now)
+
+	first, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+
+	_, err = goalStats.Get(context.Background(), true)
+	assert.ErrorContains(t, err, "failed to get goal completion stats")
+
+	cached, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+}
+
+func TestGoalStats_MetricsDisabledByDefault(t *testing.T) {
+	t.Setenv("GOAL_STATS_CACHE_TTL", "")
+	t.Setenv("GOAL_STATS_METRICS_ENABLED", "")
+
+	goalStats := NewGoalStatsFromEnv(new(mocks.ProgressQueryRepository), "ns", logrus.StandardLogger())
+
+	assert.False(t, goalStats.MetricsEnabled())
+	assert.Empty(t, goalStats.Collectors())
+	assert.Equal(t, DefaultGoalStatsTTL, goalStats.TTL())
+}
+
+func TestGoalStats_FromEnv(t *testing.T) {
+	t.Setenv("GOAL_STATS_CACHE_TTL", "30s")
+	t.Setenv("GOAL_STATS_METRICS_ENABLED", "true")
+
+	goalStats := NewGoalStatsFromEnv(new(mocks.ProgressQueryRepository), "ns", logrus.StandardLogger())
+
+	assert.True(t, goalStats.MetricsEnabled())
+	assert.Len(t, goalStats.Collectors(), 1)
+	assert.Equal(t, 30*time.Second, goalStats.TTL())
+}
+
+func TestGoalStats_FromEnv_InvalidTTL(t *testing.T) {
+	t.Setenv("GOAL_STATS_CACHE_TTL", "-1s")
+
+	goalStats := NewGoalStatsFromEnv(new(mocks.ProgressQueryRepository), "ns", logrus.StandardLogger())
+
+	assert.Equal(t, DefaultGoalStatsTTL, goalStats.TTL())
+}
+
+func TestGoalStats_ExportsGauge(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := new(mocks.ProgressQueryRepository)
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return([]*repository.GoalCompletionStats{
+			{ChallengeID: "daily", GoalID: "login", NotStarted: 1, InProgress: 2, Completed: 3, Claimed: 4},
+			{ChallengeID: "daily", GoalID: "play-match", InProgress: 5},
+		}, nil).Once()
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return([]*repository.GoalCompletionStats{
+			{ChallengeID: "daily", GoalID: "login", Claimed: 7},
+		}, nil).Once()
+
+	goalStats := newTestGoalStats(repo, true, &now)
+
+	_, err := goalStats.Get(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 8, testutil.CollectAndCount(goalStats.players))
+	assert.Equal(t, 4.0, testutil.ToFloat64(goalStats.players.WithLabelValues("daily", "login", "claimed")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(goalStats.players.WithLabelValues("daily", "play-match", "in_progress")))
+
+	_, err = goalStats.Get(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, testutil.CollectAndCount(goalStats.players), "goals without rows are removed")
+	assert.Equal(t, 7.0, testutil.ToFloat64(goalStats.players.WithLabelValues("daily", "login", "claimed")))
+}
+
+func TestGoalStats_RunRefreshesUntilCancelled(t *testing.T) {
+	repo := new(mocks.ProgressQueryRepository)
+	refreshed := make(chan struct{}, 1)
+	repo.On("GetGoalCompletionStats", mock.Anything, "ns").
+		Return([]*repository.GoalCompletionStats{}, nil).
+		Run(func(mock.Arguments) {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+		})
+
+	goalStats := NewGoalStats(repo, "ns", time.Hour, true, logrus.StandardLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		goalStats.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not refresh the stats on start")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
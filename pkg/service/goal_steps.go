@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GoalStep is one requirement of a multi-step goal.
+type GoalStep struct {
+	StatCode     string              `json:"statCode"`
+	Operator     string              `json:"operator"`
+	TargetValue  int                 `json:"targetValue"`
+	ProgressMode domain.ProgressMode `json:"progressMode,omitempty"`
+}
+
+// relative reports whether the step adds up deltas instead of taking values.
+func (s GoalStep) relative() bool {
+	return s.ProgressMode == domain.ProgressModeRelative
+}
+
+// GoalSteps maps the IDs of multi-step goals to their steps, in config order.
+// A multi-step goal lists its steps as "requirements" in the challenge config,
+// e.g. "win 3 matches" and "deal 10000 damage", and is completed once every
+// step reaches its target. A nil GoalSteps has no multi-step goals.
+//
+// domain.Goal (extend-challenge-common) has a single requirement, so the steps
+// are read from the config file separately by LoadGoalSteps. The goal's
+// "requirement" must repeat the first step: the common config loader still
+// requires it, and readers unaware of steps see the first step's progress.
+type GoalSteps map[string][]GoalStep
+
+// goalStepsConfig is the subset of challenges.json needed to read the steps.
+type goalStepsConfig struct {
+	Challenges []struct {
+		Goals []struct {
+			ID           string     `json:"goalId"`
+			Requirement  GoalStep   `json:"requirement"`
+			Requirements []GoalStep `json:"requirements"`
+			Rotation     *struct {
+				Enabled bool `json:"enabled"`
+			} `json:"rotation"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadGoalSteps reads the multi-step goals and their steps from the challenge
+// config file.
+func LoadGoalSteps(configPath string) (GoalSteps, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseGoalSteps(data)
+}
+
+// ParseGoalSteps extracts the multi-step goals from challenge config JSON. Each
+// step is validated like a requirement, a goal needs at least two steps with
+// distinct stat codes, and its requirement must equal the first step. Steps
+// cannot use match stat codes, and multi-step goals cannot rotate: their
+// periods would need resetting every step.
+func ParseGoalSteps(data []byte) (GoalSteps, error) {
+	var cfg goalStepsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	steps := make(GoalSteps)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			if len(goal.Requirements) == 0 {
+				continue
+			}
+			if len(goal.Requirements) < 2 {
+				return nil, fmt.Errorf("goal %s: requirements needs at least 2 steps, use requirement for one", goal.ID)
+			}
+			if goal.Rotation != nil && goal.Rotation.Enabled {
+				return nil, fmt.Errorf("goal %s: requirements cannot be used with rotation", goal.ID)
+			}
+
+			seen := make(map[string]bool, len(goal.Requirements))
+			for i, step := range goal.Requirements {
+				if err := validateGoalStep(step); err != nil {
+					return nil, fmt.Errorf("goal %s: requirements[%d]: %w", goal.ID, i, err)
+				}
+				if seen[step.StatCode] {
+					return nil, fmt.Errorf("goal %s: requirements[%d]: stat code %q is used by another step", goal.ID, i, step.StatCode)
+				}
+				seen[step.StatCode] = true
+			}
+
+			if normalizeGoalStep(goal.Requirement) != normalizeGoalStep(goal.Requirements[0]) {
+				return nil, fmt.Errorf("goal %s: requirement must equal requirements[0]", goal.ID)
+			}
+
+			goalSteps := make([]GoalStep, len(goal.Requirements))
+			for i, step := range goal.Requirements {
+				goalSteps[i] = normalizeGoalStep(step)
+			}
+			steps[goal.ID] = goalSteps
+		}
+	}
+
+	return steps, nil
+}
+
+// validateGoalStep applies the requirement rules of the common config validator
+// to one step.
+func validateGoalStep(step GoalStep) error {
+	switch {
+	case step.StatCode == "":
+		return fmt.Errorf("statCode is required")
+	case IsMatchStatCode(step.StatCode):
+		return fmt.Errorf("match stat code %q cannot be a step", step.StatCode)
+	case step.Operator != ">=":
+		return fmt.Errorf("invalid operator %q (only \">=\" supported)", step.Operator)
+	case step.TargetValue <= 0:
+		return fmt.Errorf("targetValue must be positive, got %d", step.TargetValue)
+	}
+
+	switch step.ProgressMode {
+	case "", domain.ProgressModeAbsolute, domain.ProgressModeRelative:
+		return nil
+	default:
+		return fmt.Errorf("invalid progressMode %q (must be %q or %q)",
+			step.ProgressMode, domain.ProgressModeAbsolute, domain.ProgressModeRelative)
+	}
+}
+
+// normalizeGoalStep fills in the default progress mode.
+func normalizeGoalStep(step GoalStep) GoalStep {
+	if step.ProgressMode == "" {
+		step.ProgressMode = domain.ProgressModeAbsolute
+	}
+	return step
+}
+
+// Steps returns the steps of a goal, nil for a goal with a single requirement.
+func (g GoalSteps) Steps(goalID string) []GoalStep {
+	return g[goalID]
+}
+
+// GoalIDs returns the IDs of the multi-step goals, sorted.
+func (g GoalSteps) GoalIDs() []string {
+	goalIDs := make([]string, 0, len(g))
+	for goalID := range g {
+		goalIDs = append(goalIDs, goalID)
+	}
+	sort.Strings(goalIDs)
+	return goalIDs
+}
+
+// Progress returns one value per step of the goal from its stored step
+// progress: missing values are 0 and values of removed steps are dropped.
+func (g GoalSteps) Progress(goalID string, stored []int) []int {
+	steps := g[goalID]
+	progress := make([]int, len(steps))
+	copy(progress, stored)
+	return progress
+}
+
+// Targets returns the target of each step of the goal, nil for a goal with a
+// single requirement.
+func (g GoalSteps) Targets(goalID string) []int {
+	steps := g[goalID]
+	if len(steps) == 0 {
+		return nil
+	}
+
+	targets := make([]int, len(steps))
+	for i, step := range steps {
+		targets[i] = step.TargetValue
+	}
+	return targets
+}
+
+// Complete reports whether the stored step progress of a multi-step goal
+// reaches the target of every step.
+func (g GoalSteps) Complete(goalID string, stored []int) bool {
+	for i, step := range g[goalID] {
+		if i >= len(stored) || stored[i] < step.TargetValue {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadProgress returns the progress of the user's multi-step goals among
+// goalIDs, one value per step (see Progress). Goals with a single requirement
+// are left out, and nothing is queried when goalIDs has no multi-step goal.
+func (g GoalSteps) LoadProgress(
+	ctx context.Context,
+	stepProgress serviceRepo.StepProgressRepository,
+	userID string,
+	goalIDs []string,
+) (map[string][]int, error) {
+	var stepGoalIDs []string
+	for _, goalID := range goalIDs {
+		if len(g[goalID]) > 0 {
+			stepGoalIDs = append(stepGoalIDs, goalID)
+		}
+	}
+	if len(stepGoalIDs) == 0 {
+		return nil, nil
+	}
+
+	stored, err := stepProgress.GetStepProgress(ctx, userID, stepGoalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load step progress: %w", err)
+	}
+
+	progress := make(map[string][]int, len(stepGoalIDs))
+	for _, goalID := range stepGoalIDs {
+		progress[goalID] = g.Progress(goalID, stored[goalID])
+	}
+	return progress, nil
+}
+
+// CheckComplete returns *mapper.GoalNotCompletedError if goalID is a
+// multi-step goal with a step below its target. Completion is recorded on the
+// progress row from every step, but a writer unaware of steps (such as the
+// event handler's COPY upsert on the first step's stat code) may mark the goal
+// completed on its first step alone, so claims check the steps again.
+func (g GoalSteps) CheckComplete(
+	ctx context.Context,
+	stepProgress serviceRepo.StepProgressRepository,
+	userID string,
+	goalID string,
+) error {
+	if len(g[goalID]) == 0 {
+		return nil
+	}
+
+	stored, err := stepProgress.GetStepProgress(ctx, userID, []string{goalID})
+	if err != nil {
+		return fmt.Errorf("failed to load step progress: %w", err)
+	}
+
+	return g.checkStepsComplete(goalID, stored[goalID])
+}
+
+// checkStepsComplete returns *mapper.GoalNotCompletedError if goalID is a
+// multi-step goal whose stored step progress has a step below its target.
+func (g GoalSteps) checkStepsComplete(goalID string, stored []int) error {
+	if len(g[goalID]) > 0 && !g.Complete(goalID, stored) {
+		return &mapper.GoalNotCompletedError{
+			GoalID: goalID,
+			Status: string(domain.GoalStatusInProgress),
+		}
+	}
+	return nil
+}
+
+// stepRef is one step of a multi-step goal.
+type stepRef struct {
+	goalID string
+	index  int
+	step   GoalStep
+}
+
+// stepsByStatCode returns the steps tracking each stat code, ordered by goal
+// ID and step.
+func (g GoalSteps) stepsByStatCode() map[string][]stepRef {
+	if len(g) == 0 {
+		return nil
+	}
+
+	refs := make(map[string][]stepRef)
+	for _, goalID := range g.GoalIDs() {
+		for i, step := range g[goalID] {
+			refs[step.StatCode] = append(refs[step.StatCode], stepRef{goalID: goalID, index: i, step: step})
+		}
+	}
+	return refs
+}
+
+// pendingSteps is the merged update of one user's multi-step goal and the
+// events that contributed to it.
+type pendingSteps struct {
+	challengeID string
+	// values holds the last value reported for each absolute step, nil if none
+	values []*int
+	// deltas holds the sum of the deltas reported for each relative step
+	deltas []int
+	events []int
+}
+
+// merge applies the pending update to the stored step progress, like the COPY
+// upsert does for single-requirement goals: absolute steps take the last value
+// and relative steps add the deltas.
+func (p *pendingSteps) merge(stored []int) []int {
+	progress := make([]int, len(p.values))
+	copy(progress, stored)
+	for i := range progress {
+		if p.values[i] != nil {
+			progress[i] = *p.values[i]
+		}
+		progress[i] += p.deltas[i]
+	}
+	return progress
+}
+
+// applyStepChunk writes one chunk of merged multi-step goal updates and records
+// the outcome on the contributing events. Rows that are missing, claimed, or
+// inactive in a challenge that does not track inactive progress are skipped.
+// A row is completed once every step reaches its target; a completed row keeps
+// its status only if its steps were complete. It returns the number of rows
+// written.
+func applyStepChunk(
+	ctx context.Context,
+	namespace string,
+	keys []serviceRepo.UserGoalKey,
+	pending map[serviceRepo.UserGoalKey]*pendingSteps,
+	steps GoalSteps,
+	stepProgress serviceRepo.StepProgressRepository,
+	policy InactiveProgressPolicy,
+	results []*ProgressEventResult,
+	logger logrus.FieldLogger,
+) int {
+	skipped := make(map[serviceRepo.UserGoalKey]string, len(keys))
+	for _, key := range keys {
+		skipped[key] = SkipReasonInactive
+	}
+	// completed are the rows this chunk moves to completed, counted once written
+	completed := make(map[serviceRepo.UserGoalKey]bool)
+
+	written, err := stepProgress.ApplyStepProgress(ctx, namespace, keys, func(row *serviceRepo.StepProgressRow) bool {
+		p := pending[row.UserGoalKey]
+		switch {
+		case row.Status == domain.GoalStatusClaimed:
+			skipped[row.UserGoalKey] = SkipReasonClaimed
+			return false
+		case !row.IsActive && !policy.TracksInactive(p.challengeID):
+			return false
+		}
+
+		wasComplete := row.Status == domain.GoalStatusCompleted && steps.Complete(row.GoalID, row.Steps)
+		merged := p.merge(row.Steps)
+		status := domain.GoalStatusInProgress
+		if wasComplete || steps.Complete(row.GoalID, merged) {
+			status = domain.GoalStatusCompleted
+		}
+		// Raised steps may take a completed goal back to in progress
+		if err := progressstate.Transition(row.Status, status, progressstate.Flags{Regress: true}); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id": row.UserID,
+				"goal_id": row.GoalID,
+				"error":   err,
+			}).Warn("Skipped multi-step progress update")
+			skipped[row.UserGoalKey] = SkipReasonIllegalTransition
+			return false
+		}
+		completed[row.UserGoalKey] = status == domain.GoalStatusCompleted && row.Status != domain.GoalStatusCompleted
+		row.Steps = merged
+		row.Status = status
+		return true
+	})
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"rows":      len(keys),
+			"error":     err,
+		}).Error("Failed to write multi-step batch progress")
+		for _, key := range keys {
+			for _, i := range pending[key].events {
+				results[i].FailedGoalIDs = append(results[i].FailedGoalIDs, key.GoalID)
+				results[i].Error = fmt.Sprintf("failed to write progress: %v", err)
+			}
+		}
+		return 0
+	}
+
+	metrics := challengeMetricsFrom(ctx)
+	wasWritten := make(map[serviceRepo.UserGoalKey]bool, len(written))
+	for _, key := range written {
+		wasWritten[key] = true
+		if completed[key] {
+			metrics.GoalCompleted(pending[key].challengeID)
+		}
+	}
+	for _, key := range keys {
+		for _, i := range pending[key].events {
+			if wasWritten[key] {
+				results[i].AppliedGoalIDs = append(results[i].AppliedGoalIDs, key.GoalID)
+			} else {
+				results[i].SkippedGoals = append(results[i].SkippedGoals, SkippedGoal{GoalID: key.GoalID, Reason: skipped[key]})
+			}
+		}
+	}
+
+	return len(written)
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const goalStepsConfigJSON = `{"challenges": [{"challengeId": "season", "name": "Season", "description": "Season goals", "goals": [
+	{
+		"goalId": "grind", "name": "Grind", "description": "Win 3 matches and deal 10000 damage", "eventSource": "statistic",
+		"requirement": {"statCode": "wins", "operator": ">=", "targetValue": 3, "progressMode": "relative"},
+		"requirements": [
+			{"statCode": "wins", "operator": ">=", "targetValue": 3, "progressMode": "relative"},
+			{"statCode": "damage", "operator": ">=", "targetValue": 10000}
+		],
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100}
+	},
+	{
+		"goalId": "kill-100", "name": "Kills", "description": "Kill 100", "eventSource": "statistic",
+		"requirement": {"statCode": "kills", "operator": ">=", "targetValue": 100},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100}
+	}
+]}]}`
+
+func testGoalSteps() GoalSteps {
+	return GoalSteps{
+		"grind": {
+			{StatCode: "wins", Operator: ">=", TargetValue: 3, ProgressMode: domain.ProgressModeRelative},
+			{StatCode: "damage", Operator: ">=", TargetValue: 10000, ProgressMode: domain.ProgressModeAbsolute},
+		},
+	}
+}
+
+// newGoalStepsCache returns a goal cache with the goals of goalStepsConfigJSON.
+func newGoalStepsCache() *mocks.GoalCache {
+	grind := &domain.Goal{
+		ID:          "grind",
+		ChallengeID: "season",
+		Requirement: domain.Requirement{StatCode: "wins", TargetValue: 3, ProgressMode: domain.ProgressModeRelative},
+	}
+	kill := &domain.Goal{
+		ID:          "kill-100",
+		ChallengeID: "season",
+		Requirement: domain.Requirement{StatCode: "kills", TargetValue: 100, ProgressMode: domain.ProgressModeAbsolute},
+	}
+
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalByID", "grind").Return(grind)
+	goalCache.On("GetGoalByID", "kill-100").Return(kill)
+	goalCache.On("GetGoalsByStatCode", "wins").Return([]*domain.Goal{grind})
+	goalCache.On("GetGoalsByStatCode", "kills").Return([]*domain.Goal{kill})
+	goalCache.On("GetGoalsByStatCode", mock.Anything).Return([]*domain.Goal(nil))
+	return goalCache
+}
+
+func TestParseGoalSteps(t *testing.T) {
+	steps, err := ParseGoalSteps([]byte(goalStepsConfigJSON))
+
+	require.NoError(t, err)
+	assert.Equal(t, testGoalSteps(), steps)
+	assert.Equal(t, []string{"grind"}, steps.GoalIDs())
+	assert.Equal(t, []int{3, 10000}, steps.Targets("grind"))
+	assert.Nil(t, steps.Targets("kill-100"))
+}
+
+func TestParseGoalSteps_AcceptedByCommonConfigLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(goalStepsConfigJSON), 0o600))
+
+	cfg, err := config.NewConfigLoader(path, slog.New(slog.DiscardHandler)).LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.Challenges, 1)
+
+	steps, err := LoadGoalSteps(path)
+	require.NoError(t, err)
+	assert.Len(t, steps, 1)
+}
+
+func TestParseGoalSteps_Invalid(t *testing.T) {
+	goal := func(requirement, requirements, extra string) string {
+		return `{"challenges": [{"goals": [{"goalId": "g1", "requirement": ` + requirement +
+			`, "requirements": ` + requirements + extra + `}]}]}`
+	}
+	wins := `{"statCode": "wins", "operator": ">=", "targetValue": 3}`
+	damage := `{"statCode": "damage", "operator": ">=", "targetValue": 100}`
+	tests := map[string]string{
+		"single step":          goal(wins, `[`+wins+`]`, ``),
+		"rotation":             goal(wins, `[`+wins+`,`+damage+`]`, `, "rotation": {"enabled": true}`),
+		"duplicate stat code":  goal(wins, `[`+wins+`,`+wins+`]`, ``),
+		"requirement mismatch": goal(damage, `[`+wins+`,`+damage+`]`, ``),
+		"missing stat code":    goal(wins, `[`+wins+`, {"operator": ">=", "targetValue": 1}]`, ``),
+		"match stat code":      goal(wins, `[`+wins+`, {"statCode": "match.kills", "operator": ">=", "targetValue": 1}]`, ``),
+		"invalid operator":     goal(wins, `[`+wins+`, {"statCode": "damage", "operator": ">", "targetValue": 1}]`, ``),
+		"zero target":          goal(wins, `[`+wins+`, {"statCode": "damage", "operator": ">=", "targetValue": 0}]`, ``),
+		"invalid mode":         goal(wins, `[`+wins+`, {"statCode": "damage", "operator": ">=", "targetValue": 1, "progressMode": "sum"}]`, ``),
+		"malformed":            `{"challenges": [`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseGoalSteps([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseGoalSteps_DefaultModeMatchesAbsolute(t *testing.T) {
+	steps, err := ParseGoalSteps([]byte(`{"challenges": [{"goals": [{"goalId": "g1",
+		"requirement": {"statCode": "wins", "operator": ">=", "targetValue": 3},
+		"requirements": [
+			{"statCode": "wins", "operator": ">=", "targetValue": 3, "progressMode": "absolute"},
+			{"statCode": "damage", "operator": ">=", "targetValue": 100}
+		]
+	}]}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProgressModeAbsolute, steps["g1"][1].ProgressMode)
+}
+
+func TestGoalSteps_ProgressAndComplete(t *testing.T) {
+	steps := testGoalSteps()
+
+	assert.Equal(t, []int{0, 0}, steps.Progress("grind", nil))
+	assert.Equal(t, []int{2, 0}, steps.Progress("grind", []int{2}))
+	assert.Equal(t, []int{3, 9000}, steps.Progress("grind", []int{3, 9000, 7}))
+
+	assert.False(t, steps.Complete("grind", nil))
+	assert.False(t, steps.Complete("grind", []int{3}))
+	assert.False(t, steps.Complete("grind", []int{3, 9999}))
+	assert.True(t, steps.Complete("grind", []int{4, 10000}))
+}
+
+func TestGoalSteps_LoadProgress(t *testing.T) {
+	ctx := context.Background()
+	steps := testGoalSteps()
+
+	t.Run("no step goals skips the query", func(t *testing.T) {
+		progress, err := steps.LoadProgress(ctx, new(mocks.StepProgressRepository), "user-1", []string{"kill-100"})
+
+		require.NoError(t, err)
+		assert.Nil(t, progress)
+	})
+
+	t.Run("pads missing goals", func(t *testing.T) {
+		stepProgress := new(mocks.StepProgressRepository)
+		stepProgress.On("GetStepProgress", ctx, "user-1", []string{"grind"}).Return(map[string][]int(nil), nil)
+
+		progress, err := steps.LoadProgress(ctx, stepProgress, "user-1", []string{"grind", "kill-100"})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]int{"grind": {0, 0}}, progress)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		stepProgress := new(mocks.StepProgressRepository)
+		stepProgress.On("GetStepProgress", ctx, "user-1", []string{"grind"}).Return(nil, errors.New("db down"))
+
+		_, err := steps.LoadProgress(ctx, stepProgress, "user-1", []string{"grind"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestGoalSteps_CheckComplete(t *testing.T) {
+	ctx := context.Background()
+	steps := testGoalSteps()
+
+	t.Run("single requirement goal", func(t *testing.T) {
+		assert.NoError(t, steps.CheckComplete(ctx, new(mocks.StepProgressRepository), "user-1", "kill-100"))
+	})
+
+	t.Run("incomplete step", func(t *testing.T) {
+		stepProgress := new(mocks.StepProgressRepository)
+		stepProgress.On("GetStepProgress", ctx, "user-1", []string{"grind"}).Return(map[string][]int{"grind": {3, 500}}, nil)
+
+		err := steps.CheckComplete(ctx, stepProgress, "user-1", "grind")
+
+		var notCompleted *mapper.GoalNotCompletedError
+		require.ErrorAs(t, err, &notCompleted)
+		assert.Equal(t, string(domain.GoalStatusInProgress), notCompleted.Status)
+	})
+
+	t.Run("every step complete", func(t *testing.T) {
+		stepProgress := new(mocks.StepProgressRepository)
+		stepProgress.On("GetStepProgress", ctx, "user-1", []string{"grind"}).Return(map[string][]int{"grind": {3, 10000}}, nil)
+
+		assert.NoError(t, steps.CheckComplete(ctx, stepProgress, "user-1", "grind"))
+	})
+}
+
+func TestBatchReportProgress_GoalSteps(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	stepProgress := new(mocks.StepProgressRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{
+		{UserID: "user-1", GoalID: "kill-100"}: domain.GoalStatusInProgress,
+	}, nil)
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Return(nil)
+
+	// user-1 finishes the damage step, user-2 is still short of wins, user-3
+	// already claimed the goal and user-4 has no progress row
+	stored := []*serviceRepo.StepProgressRow{
+		{UserGoalKey: serviceRepo.UserGoalKey{UserID: "user-1", GoalID: "grind"}, Status: domain.GoalStatusInProgress, IsActive: true, Steps: []int{3, 2000}},
+		{UserGoalKey: serviceRepo.UserGoalKey{UserID: "user-2", GoalID: "grind"}, Status: domain.GoalStatusCompleted, IsActive: true, Steps: []int{1}},
+		{UserGoalKey: serviceRepo.UserGoalKey{UserID: "user-3", GoalID: "grind"}, Status: domain.GoalStatusClaimed, IsActive: true, Steps: []int{3, 10000}},
+	}
+	var written []serviceRepo.UserGoalKey
+	stepProgress.On("ApplyStepProgress", ctx, "test-namespace", []serviceRepo.UserGoalKey{
+		{UserID: "user-1", GoalID: "grind"},
+		{UserID: "user-2", GoalID: "grind"},
+		{UserID: "user-3", GoalID: "grind"},
+		{UserID: "user-4", GoalID: "grind"},
+	}, mock.Anything).Run(func(args mock.Arguments) {
+		apply := args.Get(3).(func(row *serviceRepo.StepProgressRow) bool)
+		for _, row := range stored {
+			if apply(row) {
+				written = append(written, row.UserGoalKey)
+			}
+		}
+	}).Return([]serviceRepo.UserGoalKey{
+		{UserID: "user-1", GoalID: "grind"},
+		{UserID: "user-2", GoalID: "grind"},
+	}, nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "damage", Value: intPtr(10000)},
+		{UserID: "user-1", StatCode: "kills", Value: intPtr(5)},
+		{UserID: "user-2", StatCode: "wins", Delta: intPtr(1)},
+		{UserID: "user-3", StatCode: "damage", Value: intPtr(20000)},
+		{UserID: "user-4", StatCode: "wins", Delta: intPtr(1)},
+		{UserID: "user-1", StatCode: "damage", Delta: intPtr(1)},
+	}, newGoalStepsCache(), repo, queries, new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository),
+		stepProgress, nil, nil, testGoalSteps(), testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	require.Len(t, result.Results, 6)
+
+	assert.Equal(t, []serviceRepo.UserGoalKey{
+		{UserID: "user-1", GoalID: "grind"},
+		{UserID: "user-2", GoalID: "grind"},
+	}, written)
+	assert.Equal(t, domain.GoalStatusCompleted, stored[0].Status)
+	assert.Equal(t, []int{3, 10000}, stored[0].Steps)
+	// Completed on the first step alone by a writer unaware of steps
+	assert.Equal(t, domain.GoalStatusInProgress, stored[1].Status)
+	assert.Equal(t, []int{2, 0}, stored[1].Steps)
+	assert.Equal(t, []int{3, 10000}, stored[2].Steps)
+
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, []string{"grind"}, result.Results[0].AppliedGoalIDs)
+	assert.Equal(t, []string{"kill-100"}, result.Results[1].AppliedGoalIDs)
+	assert.Equal(t, []string{"grind"}, result.Results[2].AppliedGoalIDs)
+	assert.Equal(t, []SkippedGoal{{GoalID: "grind", Reason: SkipReasonClaimed}}, result.Results[3].SkippedGoals)
+	assert.Equal(t, []SkippedGoal{{GoalID: "grind", Reason: SkipReasonInactive}}, result.Results[4].SkippedGoals)
+	assert.Equal(t, []SkippedGoal{{GoalID: "grind", Reason: SkipReasonValueRequired}}, result.Results[5].SkippedGoals)
+	repo.AssertExpectations(t)
+	stepProgress.AssertExpectations(t)
+}
+
+func TestBatchReportProgress_GoalStepsIllegalTransition(t *testing.T) {
+	ctx := context.Background()
+	stepProgress := new(mocks.StepProgressRepository)
+	row := &serviceRepo.StepProgressRow{
+		UserGoalKey: serviceRepo.UserGoalKey{UserID: "user-1", GoalID: "grind"},
+		Status:      domain.GoalStatus("archived"),
+		IsActive:    true,
+		Steps:       []int{1, 0},
+	}
+	stepProgress.On("ApplyStepProgress", ctx, "test-namespace", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		apply := args.Get(3).(func(row *serviceRepo.StepProgressRow) bool)
+		assert.False(t, apply(row))
+	}).Return([]serviceRepo.UserGoalKey{}, nil)
+	logger, hook := logtest.NewNullLogger()
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "damage", Value: intPtr(10000)},
+	}, newGoalStepsCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository),
+		new(mocks.LateProgressRepository), stepProgress, nil, nil, testGoalSteps(), testBatchProgressConfig, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0}, row.Steps, "the row is left as stored")
+	assert.Equal(t, []SkippedGoal{{GoalID: "grind", Reason: SkipReasonIllegalTransition}}, result.Results[0].SkippedGoals)
+
+	require.NotEmpty(t, hook.AllEntries())
+	entry := hook.AllEntries()[0]
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Skipped multi-step progress update", entry.Message)
+	assert.Equal(t, "user-1", entry.Data["user_id"])
+	assert.Equal(t, "grind", entry.Data["goal_id"])
+}
+
+func TestBatchReportProgress_GoalStepsWriteFails(t *testing.T) {
+	ctx := context.Background()
+	stepProgress := new(mocks.StepProgressRepository)
+	stepProgress.On("ApplyStepProgress", ctx, "test-namespace", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+	logger, hook := logtest.NewNullLogger()
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "damage", Value: intPtr(10000)},
+	}, newGoalStepsCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository),
+		new(mocks.LateProgressRepository), stepProgress, nil, nil, testGoalSteps(), testBatchProgressConfig, logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventFailed, result.Results[0].Status)
+	assert.Equal(t, []string{"grind"}, result.Results[0].FailedGoalIDs)
+
+	require.NotEmpty(t, hook.AllEntries())
+	entry := hook.AllEntries()[0]
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "Failed to write multi-step batch progress", entry.Message)
+	assert.Equal(t, 1, entry.Data["rows"])
+}
+
+func TestBatchReportProgress_GoalStepsRequireRepository(t *testing.T) {
+	_, err := BatchReportProgress(context.Background(), "test-namespace", nil,
+		newGoalStepsCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository),
+		new(mocks.LateProgressRepository), nil, nil, nil, testGoalSteps(), testBatchProgressConfig, logrus.StandardLogger())
+	assert.Error(t, err)
+
+	_, err = BatchReportProgress(context.Background(), "test-namespace", nil,
+		newGoalStepsCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository),
+		new(mocks.LateProgressRepository), new(mocks.StepProgressRepository), nil, nil, testGoalSteps(), testBatchProgressConfig, nil)
+	assert.ErrorContains(t, err, "logger cannot be nil")
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// HiddenGoals is the set of goal IDs marked "hidden": true in the challenge config.
+//
+// Hidden goals are left out of challenge listings until the user unlocks them:
+// every prerequisite is claimed, or the goal itself already has progress.
+// A nil HiddenGoals hides nothing.
+//
+// domain.Goal (extend-challenge-common) has no hidden field, so the flag is read
+// from the config file separately by LoadHiddenGoals.
+type HiddenGoals map[string]bool
+
+// hiddenGoalsConfig is the subset of challenges.json needed to read the hidden flag.
+type hiddenGoalsConfig struct {
+	Challenges []struct {
+		Goals []struct {
+			ID     string `json:"goalId"`
+			Hidden bool   `json:"hidden"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadHiddenGoals reads the hidden goal IDs from the challenge config file.
+func LoadHiddenGoals(configPath string) (HiddenGoals, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseHiddenGoals(data)
+}
+
+// ParseHiddenGoals extracts the hidden goal IDs from challenge config JSON.
+func ParseHiddenGoals(data []byte) (HiddenGoals, error) {
+	var cfg hiddenGoalsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	hidden := make(HiddenGoals)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			if goal.Hidden {
+				hidden[goal.ID] = true
+			}
+		}
+	}
+
+	return hidden, nil
+}
+
+// IsHidden reports whether the goal is configured as hidden.
+func (h HiddenGoals) IsHidden(goalID string) bool {
+	return h[goalID]
+}
+
+// IsVisible reports whether the goal should be listed for a user with the given progress.
+//
+// A hidden goal becomes visible once it has its own progress row, or once it has
+// prerequisites and all of them are claimed. A hidden goal without prerequisites
+// stays hidden until progress is recorded for it.
+func (h HiddenGoals) IsVisible(goal *domain.Goal, progressMap map[string]*domain.UserGoalProgress) bool {
+	if goal == nil || !h.IsHidden(goal.ID) {
+		return true
+	}
+
+	if progressMap[goal.ID] != nil {
+		return true
+	}
+
+	if len(goal.Prerequisites) == 0 {
+		return false
+	}
+
+	for _, prereqID := range goal.Prerequisites {
+		prereq := progressMap[prereqID]
+		if prereq == nil || !prereq.IsClaimed() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MissingPrerequisites returns the prerequisite IDs of locked hidden goals that
+// have no entry in progressMap.
+//
+// Callers holding only part of the user's progress (active_only, a single page)
+// load these rows before calling IsVisible, so unlocks are not missed.
+func (h HiddenGoals) MissingPrerequisites(goals []*domain.Goal, progressMap map[string]*domain.UserGoalProgress) []string {
+	if len(h) == 0 {
+		return nil
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, goal := range goals {
+		if goal == nil || !h.IsHidden(goal.ID) || progressMap[goal.ID] != nil {
+			continue
+		}
+		for _, prereqID := range goal.Prerequisites {
+			if progressMap[prereqID] == nil && !seen[prereqID] {
+				seen[prereqID] = true
+				missing = append(missing, prereqID)
+			}
+		}
+	}
+
+	return missing
+}
+
+// WithPrerequisites returns progressMap extended with the rows returned by
+// MissingPrerequisites, loaded in one query. progressMap itself is not modified
+// and is returned as-is when nothing is missing.
+func (h HiddenGoals) WithPrerequisites(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	userID string,
+	goals []*domain.Goal,
+	progressMap map[string]*domain.UserGoalProgress,
+) (map[string]*domain.UserGoalProgress, error) {
+	missing := h.MissingPrerequisites(goals, progressMap)
+	if len(missing) == 0 {
+		return progressMap, nil
+	}
+
+	rows, err := repo.GetGoalsByIDs(ctx, userID, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hidden goal prerequisites: %w", err)
+	}
+
+	merged := make(map[string]*domain.UserGoalProgress, len(progressMap)+len(rows))
+	for goalID, progress := range progressMap {
+		merged[goalID] = progress
+	}
+	for _, row := range rows {
+		merged[row.GoalID] = row
+	}
+	return merged, nil
+}
+
+// VisibleChallenge returns the challenge without the hidden goals the user has
+// not unlocked. The original challenge is returned when nothing is filtered out,
+// otherwise a shallow copy with its own goal slice.
+func (h HiddenGoals) VisibleChallenge(challenge *domain.Challenge, progressMap map[string]*domain.UserGoalProgress) *domain.Challenge {
+	if challenge == nil || len(h) == 0 {
+		return challenge
+	}
+
+	var goals []*domain.Goal
+	for i, goal := range challenge.Goals {
+		if h.IsVisible(goal, progressMap) {
+			if goals != nil {
+				goals = append(goals, goal)
+			}
+			continue
+		}
+		if goals == nil {
+			goals = make([]*domain.Goal, i, len(challenge.Goals)-1)
+			copy(goals, challenge.Goals[:i])
+		}
+	}
+
+	if goals == nil {
+		return challenge
+	}
+
+	visible := *challenge
+	visible.Goals = goals
+	return &visible
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHiddenGoals(t *testing.T) {
+	data := []byte(`{
+		"challenges": [
+			{"challengeId": "c1", "goals": [
+				{"goalId": "visible"},
+				{"goalId": "secret", "hidden": true},
+				{"goalId": "explicit", "hidden": false}
+			]},
+			{"challengeId": "c2", "goals": [{"goalId": "secret-2", "hidden": true}]}
+		]
+	}`)
+
+	hidden, err := ParseHiddenGoals(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, HiddenGoals{"secret": true, "secret-2": true}, hidden)
+}
+
+func TestParseHiddenGoals_InvalidJSON(t *testing.T) {
+	_, err := ParseHiddenGoals([]byte(`{"challenges":`))
+
+	assert.Error(t, err)
+}
+
+func TestLoadHiddenGoals_TestConfig(t *testing.T) {
+	hidden, err := LoadHiddenGoals("../../config/challenges.test.json")
+
+	require.NoError(t, err)
+	assert.NotNil(t, hidden)
+}
+
+func TestHiddenGoals_IsVisible(t *testing.T) {
+	hidden := HiddenGoals{"secret": true, "orphan": true}
+	secret := &domain.Goal{ID: "secret", Prerequisites: []string{"p1", "p2"}}
+	orphan := &domain.Goal{ID: "orphan"}
+	regular := &domain.Goal{ID: "regular", Prerequisites: []string{"p1"}}
+
+	claimed := &domain.UserGoalProgress{Status: domain.GoalStatusClaimed}
+	completed := &domain.UserGoalProgress{Status: domain.GoalStatusCompleted}
+
+	tests := []struct {
+		name     string
+		goal     *domain.Goal
+		progress map[string]*domain.UserGoalProgress
+		want     bool
+	}{
+		{"regular goal always visible", regular, nil, true},
+		{"hidden without progress", secret, nil, false},
+		{"hidden with one prerequisite claimed", secret, map[string]*domain.UserGoalProgress{"p1": claimed}, false},
+		{"hidden with prerequisite only completed", secret, map[string]*domain.UserGoalProgress{"p1": claimed, "p2": completed}, false},
+		{"hidden with all prerequisites claimed", secret, map[string]*domain.UserGoalProgress{"p1": claimed, "p2": claimed}, true},
+		{"hidden with own progress", secret, map[string]*domain.UserGoalProgress{"secret": {Status: domain.GoalStatusInProgress}}, true},
+		{"hidden without prerequisites", orphan, nil, false},
+		{"hidden without prerequisites but with progress", orphan, map[string]*domain.UserGoalProgress{"orphan": completed}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hidden.IsVisible(tt.goal, tt.progress))
+		})
+	}
+
+	var none HiddenGoals
+	assert.True(t, none.IsVisible(secret, nil), "nil HiddenGoals hides nothing")
+}
+
+func TestHiddenGoals_VisibleChallenge(t *testing.T) {
+	hidden := HiddenGoals{"secret": true}
+	challenge := &domain.Challenge{
+		ID: "c1",
+		Goals: []*domain.Goal{
+			{ID: "first"},
+			{ID: "secret", Prerequisites: []string{"first"}},
+			{ID: "last"},
+		},
+	}
+
+	locked := hidden.VisibleChallenge(challenge, nil)
+	require.Len(t, locked.Goals, 2)
+	assert.Equal(t, "first", locked.Goals[0].ID)
+	assert.Equal(t, "last", locked.Goals[1].ID)
+	assert.Len(t, challenge.Goals, 3, "config challenge must not be modified")
+
+	unlocked := hidden.VisibleChallenge(challenge, map[string]*domain.UserGoalProgress{
+		"first": {Status: domain.GoalStatusClaimed},
+	})
+	assert.Same(t, challenge, unlocked)
+}
+
+func TestHiddenGoals_WithPrerequisites(t *testing.T) {
+	hidden := HiddenGoals{"secret": true}
+	goals := []*domain.Goal{
+		{ID: "secret", Prerequisites: []string{"known", "inactive"}},
+		{ID: "regular", Prerequisites: []string{"other"}},
+	}
+	progressMap := map[string]*domain.UserGoalProgress{
+		"known": {GoalID: "known", Status: domain.GoalStatusClaimed},
+	}
+
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"inactive"}).Return([]*domain.UserGoalProgress{
+		{GoalID: "inactive", Status: domain.GoalStatusClaimed},
+	}, nil)
+
+	merged, err := hidden.WithPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.True(t, hidden.IsVisible(goals[0], merged))
+	assert.Len(t, progressMap, 1, "input map must not be modified")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestHiddenGoals_WithPrerequisites_NothingMissing(t *testing.T) {
+	hidden := HiddenGoals{"secret": true}
+	goals := []*domain.Goal{{ID: "secret", Prerequisites: []string{"known"}}}
+	progressMap := map[string]*domain.UserGoalProgress{"known": {GoalID: "known"}}
+	mockRepo := new(mocks.GoalRepository)
+
+	merged, err := hidden.WithPrerequisites(context.Background(), mockRepo, "user1", goals, progressMap)
+
+	require.NoError(t, err)
+	assert.Equal(t, progressMap, merged)
+	mockRepo.AssertNotCalled(t, "GetGoalsByIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHiddenGoals_WithPrerequisites_QueryFails(t *testing.T) {
+	hidden := HiddenGoals{"secret": true}
+	goals := []*domain.Goal{{ID: "secret", Prerequisites: []string{"missing"}}}
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"missing"}).Return(nil, errors.New("db down"))
+
+	_, err := hidden.WithPrerequisites(context.Background(), mockRepo, "user1", goals, nil)
+
+	assert.ErrorContains(t, err, "failed to load hidden goal prerequisites")
+}
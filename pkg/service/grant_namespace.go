@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+)
+
+// GrantNamespaces decides which AGS namespace a player's reward is granted in.
+//
+// By default every reward is granted in the service namespace. A deployment
+// that serves players of several namespaces lists the others as grantable; a
+// claim whose token namespace is one of them is granted there instead, and any
+// other token namespace is refused. Progress is always stored under the service
+// namespace.
+//
+// A nil *GrantNamespaces, or one without grantable namespaces, always grants in
+// the service namespace.
+type GrantNamespaces struct {
+	defaultNamespace string
+	allowed          map[string]bool
+}
+
+// NewGrantNamespaces creates grant namespaces that fall back to defaultNamespace
+// and also allow the given namespaces.
+func NewGrantNamespaces(defaultNamespace string, allowed []string) *GrantNamespaces {
+	g := &GrantNamespaces{defaultNamespace: defaultNamespace, allowed: make(map[string]bool, len(allowed))}
+	for _, namespace := range allowed {
+		if namespace = strings.TrimSpace(namespace); namespace != "" && namespace != defaultNamespace {
+			g.allowed[namespace] = true
+		}
+	}
+	return g
+}
+
+// NewGrantNamespacesFromEnv reads the grantable namespaces from
+// REWARD_GRANT_NAMESPACES (comma-separated, default unset = service namespace only).
+func NewGrantNamespacesFromEnv(defaultNamespace string) *GrantNamespaces {
+	return NewGrantNamespaces(defaultNamespace, strings.Split(common.GetEnv("REWARD_GRANT_NAMESPACES", ""), ","))
+}
+
+// Enabled reports whether any namespace besides the service namespace is grantable.
+func (g *GrantNamespaces) Enabled() bool {
+	return g != nil && len(g.allowed) > 0
+}
+
+// Namespaces returns the grantable namespaces besides the service namespace, sorted.
+func (g *GrantNamespaces) Namespaces() []string {
+	if g == nil {
+		return nil
+	}
+
+	namespaces := make([]string, 0, len(g.allowed))
+	for namespace := range g.allowed {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Resolve returns the namespace to grant the rewards of the authenticated
+// player in: the token namespace if it is grantable, the service namespace if
+// the token has none or grantable namespaces are not configured. Any other
+// token namespace fails with mapper.GrantNamespaceNotAllowedError.
+// A nil *GrantNamespaces returns "", which WithGrantNamespace ignores.
+func (g *GrantNamespaces) Resolve(ctx context.Context) (string, error) {
+	if g == nil {
+		return "", nil
+	}
+	if !g.Enabled() {
+		return g.defaultNamespace, nil
+	}
+
+	namespace := common.GetNamespaceFromContext(ctx)
+	if namespace == "" || namespace == g.defaultNamespace {
+		return g.defaultNamespace, nil
+	}
+	if !g.allowed[namespace] {
+		return "", &mapper.GrantNamespaceNotAllowedError{Namespace: namespace}
+	}
+	return namespace, nil
+}
+
+type grantNamespaceKey struct{}
+
+// WithGrantNamespace returns a context whose claims grant rewards in namespace
+// instead of the service namespace. An empty namespace leaves ctx unchanged.
+func WithGrantNamespace(ctx context.Context, namespace string) context.Context {
+	if namespace == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, grantNamespaceKey{}, namespace)
+}
+
+// grantNamespaceFrom returns the namespace set by WithGrantNamespace, or fallback.
+func grantNamespaceFrom(ctx context.Context, fallback string) string {
+	if namespace, ok := ctx.Value(grantNamespaceKey{}).(string); ok {
+		return namespace
+	}
+	return fallback
+}
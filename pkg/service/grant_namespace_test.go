@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/mapper"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantNamespaces_Resolve(t *testing.T) {
+	grantNamespaces := NewGrantNamespaces("game", []string{" other-game ", "", "game", "third-game"})
+
+	tests := []struct {
+		name           string
+		tokenNamespace string
+		want           string
+	}{
+		{name: "no token namespace", tokenNamespace: "", want: "game"},
+		{name: "service namespace", tokenNamespace: "game", want: "game"},
+		{name: "allow-listed namespace", tokenNamespace: "other-game", want: "other-game"},
+		{name: "second allow-listed namespace", tokenNamespace: "third-game", want: "third-game"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), common.ContextKeyNamespace, tt.tokenNamespace)
+			got, err := grantNamespaces.Resolve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, []string{"other-game", "third-game"}, grantNamespaces.Namespaces())
+}
+
+func TestGrantNamespaces_ResolveMismatch(t *testing.T) {
+	grantNamespaces := NewGrantNamespaces("game", []string{"other-game"})
+	ctx := context.WithValue(context.Background(), common.ContextKeyNamespace, "unknown-game")
+
+	_, err := grantNamespaces.Resolve(ctx)
+
+	var notAllowed *mapper.GrantNamespaceNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	assert.Equal(t, "unknown-game", notAllowed.Namespace)
+}
+
+func TestGrantNamespaces_NotConfigured(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextKeyNamespace, "other-game")
+
+	// Without grantable namespaces the token namespace is ignored, as before
+	grantNamespaces := NewGrantNamespaces("game", nil)
+	assert.False(t, grantNamespaces.Enabled())
+	got, err := grantNamespaces.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "game", got)
+
+	var nilNamespaces *GrantNamespaces
+	got, err = nilNamespaces.Resolve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+	assert.Equal(t, "fallback", grantNamespaceFrom(WithGrantNamespace(ctx, got), "fallback"))
+}
+
+func TestNewGrantNamespacesFromEnv(t *testing.T) {
+	t.Setenv("REWARD_GRANT_NAMESPACES", "other-game, third-game")
+
+	grantNamespaces := NewGrantNamespacesFromEnv("game")
+
+	assert.True(t, grantNamespaces.Enabled())
+	assert.Equal(t, []string{"other-game", "third-game"}, grantNamespaces.Namespaces())
+}
+
+func TestWithGrantNamespace(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "game", grantNamespaceFrom(ctx, "game"))
+	assert.Equal(t, "other-game", grantNamespaceFrom(WithGrantNamespace(ctx, "other-game"), "game"))
+}
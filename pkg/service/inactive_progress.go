@@ -0,0 +1,63 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// InactiveProgressPolicy is the set of challenge IDs configured with
+// "trackInactiveProgress": false in the challenge config.
+//
+// Progress for those challenges is only applied to goals the user has activated,
+// so players cannot advance goals before selecting them. Activating a goal later
+// does not apply progress reported while it was inactive. Every other challenge
+// tracks inactive progress, which is the default. A nil InactiveProgressPolicy
+// tracks every challenge.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so the flag is
+// read from the config file separately by LoadInactiveProgressPolicy.
+type InactiveProgressPolicy map[string]bool
+
+// inactiveProgressConfig is the subset of challenges.json needed to read the flag.
+type inactiveProgressConfig struct {
+	Challenges []struct {
+		ID                    string `json:"challengeId"`
+		TrackInactiveProgress *bool  `json:"trackInactiveProgress"`
+	} `json:"challenges"`
+}
+
+// LoadInactiveProgressPolicy reads the challenges that do not track inactive
+// progress from the challenge config file.
+func LoadInactiveProgressPolicy(configPath string) (InactiveProgressPolicy, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseInactiveProgressPolicy(data)
+}
+
+// ParseInactiveProgressPolicy extracts the challenges that do not track inactive
+// progress from challenge config JSON.
+func ParseInactiveProgressPolicy(data []byte) (InactiveProgressPolicy, error) {
+	var cfg inactiveProgressConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	policy := make(InactiveProgressPolicy)
+	for _, challenge := range cfg.Challenges {
+		if challenge.TrackInactiveProgress != nil && !*challenge.TrackInactiveProgress {
+			policy[challenge.ID] = true
+		}
+	}
+
+	return policy, nil
+}
+
+// TracksInactive reports whether progress is applied to the challenge's goals
+// while they are inactive for the user.
+func (p InactiveProgressPolicy) TracksInactive(challengeID string) bool {
+	return !p[challengeID]
+}
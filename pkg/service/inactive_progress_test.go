@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInactiveProgressPolicy(t *testing.T) {
+	data := []byte(`{
+		"challenges": [
+			{"challengeId": "default"},
+			{"challengeId": "strict", "trackInactiveProgress": false},
+			{"challengeId": "explicit", "trackInactiveProgress": true}
+		]
+	}`)
+
+	policy, err := ParseInactiveProgressPolicy(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, InactiveProgressPolicy{"strict": true}, policy)
+	assert.True(t, policy.TracksInactive("default"))
+	assert.True(t, policy.TracksInactive("explicit"))
+	assert.False(t, policy.TracksInactive("strict"))
+}
+
+func TestParseInactiveProgressPolicy_InvalidJSON(t *testing.T) {
+	_, err := ParseInactiveProgressPolicy([]byte(`{"challenges":`))
+
+	assert.Error(t, err)
+}
+
+func TestInactiveProgressPolicy_NilTracksEverything(t *testing.T) {
+	var policy InactiveProgressPolicy
+
+	assert.True(t, policy.TracksInactive("any"))
+}
+
+func TestLoadInactiveProgressPolicy_TestConfig(t *testing.T) {
+	policy, err := LoadInactiveProgressPolicy("../../config/challenges.test.json")
+
+	require.NoError(t, err)
+	assert.NotNil(t, policy)
+}
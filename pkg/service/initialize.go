@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/cache"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/AccelByte/extend-challenge-common/pkg/repository"
@@ -36,6 +39,11 @@ type AssignedGoal struct {
 	EventSource  domain.EventSource
 	Requirement  domain.Requirement
 	Reward       domain.Reward
+	// ActivationSource is how the goal was last activated: ActivationSourceDefault
+	// for goals assigned by this call, ActivationSourceRandom for goals an
+	// AutoRandomSelector activated, otherwise empty until filled in by
+	// ResolveInitializeActivationSources.
+	ActivationSource string
 }
 
 // InitializePlayer creates database rows for DEFAULT-ASSIGNED goals on first login or config sync on subsequent logins.
@@ -55,6 +63,14 @@ type AssignedGoal struct {
 // 3. If count == 0: First login, insert default-assigned goals (~20ms)
 // 4. Return all assigned goals (existing + new)
 //
+// With an AutoRandomSelector in ctx (see WithAutoRandomSelector), a first login
+// then also runs the random selections of the challenges with autoRandomSelect
+// and returns their goals with the default ones. Returning players are not
+// affected.
+//
+// With ChallengeRollouts in ctx (see WithChallengeRollouts), the default goals
+// of challenges not rolled out to the player are neither assigned nor returned.
+//
 // Performance:
 // - First login (10 default goals): 1 COUNT + 1 INSERT, ~20ms (254x faster than Phase 8)
 // - Subsequent login (fast path): 1 COUNT + 1 SELECT, ~5ms (170x faster than Phase 8)
@@ -66,6 +82,14 @@ type AssignedGoal struct {
 // - namespace: Namespace from JWT claims
 // - goalCache: In-memory goal cache for config lookup
 // - repo: Database repository for goal progress
+// - inserter: Creates the default goal rows row by row (see below); nil uses repo.BulkInsert
+// - logger: Logger for the initialization's log entries
+//
+// With an inserter, a default goal row that already exists (e.g. from a
+// concurrent first login) is returned as stored, and a row that violates a
+// constraint is logged and left out, so one bad row never fails the login.
+// NewAssignments counts only the rows this call created. With nil, the insert is
+// all-or-nothing and any failure fails initialization.
 //
 // Returns:
 // - *InitializeResponse: Contains assigned goals, new assignments count, total active count
@@ -76,6 +100,8 @@ func InitializePlayer(
 	namespace string,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	inserter serviceRepo.ProgressInsertRepository,
+	logger logrus.FieldLogger,
 ) (*InitializeResponse, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
@@ -93,29 +119,40 @@ func InitializePlayer(
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
 	// M3 Phase 9: Get ONLY default-assigned goals (lazy materialization)
-	// Non-default goals will be created later when user activates them via SetGoalActive
-	defaultGoals := goalCache.GetGoalsWithDefaultAssigned()
+	// Non-default goals will be created later when user activates them via SetGoalActive.
+	// Misconfigured goals (non-positive target) are never assigned.
+	defaultGoals := assignableGoals(goalCache.GetGoalsWithDefaultAssigned(), goalGuardDefaultAssignment, logger)
+	// Nor are the goals of challenges not rolled out to the player
+	defaultGoals = challengeRolloutsFrom(ctx).AvailableGoals(userID, defaultGoals)
 
 	// Early return if no default goals configured
 	if len(defaultGoals) == 0 {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": namespace,
 		}).Info("No default goals configured, initialization skipped")
 
-		return &InitializeResponse{
+		result := &InitializeResponse{
 			AssignedGoals:  []*AssignedGoal{},
 			NewAssignments: 0,
 			TotalActive:    0,
-		}, nil
+		}
+		if err := selectForNewPlayer(ctx, userID, namespace, goalCache, repo, logger, result); err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 
 	// 2. Fast path check: Use COUNT(*) to see if user already initialized
 	// This avoids expensive GetGoalsByIDs query with 500 IDs (Phase 8 bottleneck)
 	userGoalCount, err := repo.GetUserGoalCount(ctx, userID)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": namespace,
 			"error":     err,
@@ -125,7 +162,7 @@ func InitializePlayer(
 
 	// 3. Fast path: User already initialized, return active goals only
 	if userGoalCount > 0 {
-		return handleReturningPlayer(ctx, userID, namespace, goalCache, repo, defaultGoals, userGoalCount)
+		return handleReturningPlayer(ctx, userID, namespace, goalCache, repo, defaultGoals, userGoalCount, logger)
 	}
 
 	// 4. Slow path: First login - insert ALL default goals
@@ -155,9 +192,20 @@ func InitializePlayer(
 		}
 	}
 
+	if inserter != nil {
+		result, err := insertDefaultGoals(ctx, userID, namespace, goalCache, repo, inserter, defaultGoals, newAssignments, logger)
+		if err != nil {
+			return nil, err
+		}
+		if selector := autoRandomSelectorFrom(ctx); selector != nil {
+			selector.apply(ctx, userID, namespace, goalCache, repo, logger, result)
+		}
+		return result, nil
+	}
+
 	err = repo.BulkInsert(ctx, newAssignments)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": namespace,
 			"count":     len(defaultGoals),
@@ -166,7 +214,7 @@ func InitializePlayer(
 		return nil, fmt.Errorf("failed to bulk insert goals: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logger.WithFields(logrus.Fields{
 		"user_id":         userID,
 		"namespace":       namespace,
 		"new_assignments": len(defaultGoals),
@@ -175,10 +223,145 @@ func InitializePlayer(
 
 	// 6. Return the newly created assignments (no need to re-fetch from DB)
 	// We already have all the data we need from the insert operation
-	return &InitializeResponse{
-		AssignedGoals:  mapToAssignedGoals(newAssignments, defaultGoals, goalCache),
+	assignedGoals := mapToAssignedGoals(newAssignments, defaultGoals, goalCache, logger)
+	for _, assigned := range assignedGoals {
+		assigned.ActivationSource = ActivationSourceDefault
+	}
+
+	result := &InitializeResponse{
+		AssignedGoals:  assignedGoals,
 		NewAssignments: len(defaultGoals),
 		TotalActive:    len(defaultGoals), // All default goals are active
+	}
+
+	// 7. Random selections of challenges with autoRandomSelect, after the default goals
+	if selector := autoRandomSelectorFrom(ctx); selector != nil {
+		selector.apply(ctx, userID, namespace, goalCache, repo, logger, result)
+	}
+
+	return result, nil
+}
+
+// selectForNewPlayer runs the auto random selections (see
+// WithAutoRandomSelector) when no default goals are configured, where
+// InitializePlayer otherwise reads nothing: only a player without goal rows is
+// new.
+func selectForNewPlayer(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
+	result *InitializeResponse,
+) error {
+	selector := autoRandomSelectorFrom(ctx)
+	if selector == nil {
+		return nil
+	}
+
+	userGoalCount, err := repo.GetUserGoalCount(ctx, userID)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": namespace,
+			"error":     err,
+		}).Error("Failed to get user goal count")
+		return fmt.Errorf("failed to get user goal count: %w", err)
+	}
+	if userGoalCount == 0 {
+		selector.apply(ctx, userID, namespace, goalCache, repo, logger, result)
+	}
+
+	return nil
+}
+
+// insertDefaultGoals creates a first-time player's default goal rows with
+// inserter, tolerating rows that already exist or fail to insert.
+func insertDefaultGoals(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	inserter serviceRepo.ProgressInsertRepository,
+	defaultGoals []*domain.Goal,
+	newAssignments []*domain.UserGoalProgress,
+	logger logrus.FieldLogger,
+) (*InitializeResponse, error) {
+	result, err := inserter.BulkInsertReturningConflicts(ctx, newAssignments)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": namespace,
+			"count":     len(newAssignments),
+			"error":     err,
+		}).Error("Failed to bulk insert default goals")
+		return nil, fmt.Errorf("failed to bulk insert goals: %w", err)
+	}
+
+	for _, failed := range result.Failed {
+		logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"namespace": namespace,
+			"goal_id":   failed.GoalID,
+			"error":     failed.Err,
+		}).Warn("Default goal could not be inserted, skipping it")
+	}
+
+	inserted := make(map[string]bool, len(result.Inserted))
+	for _, key := range result.Inserted {
+		inserted[key.GoalID] = true
+	}
+	assignments := make([]*domain.UserGoalProgress, 0, len(newAssignments))
+	for _, progress := range newAssignments {
+		if inserted[progress.GoalID] {
+			assignments = append(assignments, progress)
+		}
+	}
+
+	// Rows that already existed are returned as stored; a failed read leaves
+	// them out rather than failing the login
+	if len(result.Existing) > 0 {
+		goalIDs := make([]string, len(result.Existing))
+		for i, key := range result.Existing {
+			goalIDs[i] = key.GoalID
+		}
+		existing, err := repo.GetGoalsByIDs(ctx, userID, goalIDs)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":   userID,
+				"namespace": namespace,
+				"goal_ids":  goalIDs,
+				"error":     err,
+			}).Warn("Failed to load existing default goals")
+		}
+		for _, progress := range existing {
+			if progress.IsActive {
+				assignments = append(assignments, progress)
+			}
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"user_id":         userID,
+		"namespace":       namespace,
+		"new_assignments": len(result.Inserted),
+		"existing":        len(result.Existing),
+		"failed":          len(result.Failed),
+	}).Info("Successfully initialized new player with default goals")
+
+	assignedGoals := mapToAssignedGoals(assignments, defaultGoals, goalCache, logger)
+	for _, assigned := range assignedGoals {
+		if inserted[assigned.GoalID] {
+			assigned.ActivationSource = ActivationSourceDefault
+		}
+	}
+
+	return &InitializeResponse{
+		AssignedGoals:  assignedGoals,
+		NewAssignments: len(result.Inserted),
+		TotalActive:    len(assignedGoals),
 	}, nil
 }
 
@@ -192,10 +375,11 @@ func handleReturningPlayer(
 	repo repository.GoalRepository,
 	defaultGoals []*domain.Goal,
 	userGoalCount int,
+	logger logrus.FieldLogger,
 ) (*InitializeResponse, error) {
 	activeGoals, err := repo.GetActiveGoals(ctx, userID)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":   userID,
 			"namespace": namespace,
 			"error":     err,
@@ -204,9 +388,9 @@ func handleReturningPlayer(
 	}
 
 	// M5: Detect and apply rotation resets for returning players
-	applyRotationResets(ctx, userID, namespace, activeGoals, goalCache, repo)
+	applyRotationResets(ctx, userID, namespace, activeGoals, goalCache, repo, logger)
 
-	logrus.WithFields(logrus.Fields{
+	logger.WithFields(logrus.Fields{
 		"user_id":      userID,
 		"namespace":    namespace,
 		"total_goals":  userGoalCount,
@@ -214,7 +398,7 @@ func handleReturningPlayer(
 	}).Info("Player already initialized (fast path)")
 
 	return &InitializeResponse{
-		AssignedGoals:  mapToAssignedGoals(activeGoals, defaultGoals, goalCache),
+		AssignedGoals:  mapToAssignedGoals(activeGoals, defaultGoals, goalCache, logger),
 		NewAssignments: 0,
 		TotalActive:    len(activeGoals),
 	}, nil
@@ -229,6 +413,7 @@ func applyRotationResets(
 	activeGoals []*domain.UserGoalProgress,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
 ) {
 	now := time.Now().UTC()
 	var rowsToUpdate []*domain.UserGoalProgress
@@ -237,9 +422,19 @@ func applyRotationResets(
 		if goal == nil {
 			continue
 		}
-		if rotation.ApplyRotationReset(row, goal, now) {
-			rowsToUpdate = append(rowsToUpdate, row)
+		from := row.Status
+		if !rotation.ApplyRotationReset(row, goal, now) {
+			continue
+		}
+		if err := progressstate.Transition(from, row.Status, progressstate.Flags{Reset: true}); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id": userID,
+				"goal_id": row.GoalID,
+				"error":   err,
+			}).Warn("Skipped rotation reset")
+			continue
 		}
+		rowsToUpdate = append(rowsToUpdate, row)
 	}
 
 	if len(rowsToUpdate) == 0 {
@@ -247,7 +442,7 @@ func applyRotationResets(
 	}
 
 	if err := repo.BatchUpsertProgress(ctx, rowsToUpdate); err != nil {
-		logrus.WithFields(logrus.Fields{
+		logger.WithFields(logrus.Fields{
 			"user_id":       userID,
 			"namespace":     namespace,
 			"rotated_count": len(rowsToUpdate),
@@ -256,7 +451,7 @@ func applyRotationResets(
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	logger.WithFields(logrus.Fields{
 		"user_id":       userID,
 		"namespace":     namespace,
 		"rotated_count": len(rowsToUpdate),
@@ -271,6 +466,7 @@ func applyRotationResets(
 // - progresses: User goal progress from database
 // - goals: Goal configurations from cache (for enrichment)
 // - goalCache: Goal cache for looking up goal details
+// - logger: Logger for goals missing from the cache
 //
 // Returns:
 // - []*AssignedGoal: Array of assigned goals with complete information
@@ -278,6 +474,7 @@ func mapToAssignedGoals(
 	progresses []*domain.UserGoalProgress,
 	goals []*domain.Goal,
 	goalCache cache.GoalCache,
+	logger logrus.FieldLogger,
 ) []*AssignedGoal {
 	result := make([]*AssignedGoal, 0, len(progresses))
 	now := time.Now().UTC()
@@ -286,7 +483,12 @@ func mapToAssignedGoals(
 		goal := goalCache.GetGoalByID(progress.GoalID)
 		if goal == nil {
 			// Skip goals that are no longer in config (defensive)
-			logrus.WithField("goal_id", progress.GoalID).Warn("Goal not found in cache during mapping")
+			logger.WithFields(logrus.Fields{
+				"user_id":      progress.UserID,
+				"goal_id":      progress.GoalID,
+				"challenge_id": progress.ChallengeID,
+				"namespace":    progress.Namespace,
+			}).Warn("Goal not found in cache during mapping")
 			continue
 		}
 
@@ -6,16 +6,17 @@ import (
 	"testing"
 	"time"
 
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// NOTE: MockGoalCache and MockGoalRepository are defined in progress_query_test.go
-// to avoid duplication across test files.
-
 // Test InitializePlayer - Happy Path: First Login (M3 Phase 9: Lazy Materialization)
 // Phase 9: Only creates rows for DEFAULT-ASSIGNED goals (not all goals)
 // Non-default goals will be created later via SetGoalActive when user activates them
@@ -68,8 +69,8 @@ func TestInitializePlayer_FirstLogin(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// M3 Phase 9: Use GetGoalsWithDefaultAssigned() instead of GetAllGoals()
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
@@ -114,7 +115,7 @@ func TestInitializePlayer_FirstLogin(t *testing.T) {
 	mockCache.On("GetGoalByID", "goal2").Return(defaultGoals[1])
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -182,8 +183,8 @@ func TestInitializePlayer_SubsequentLogin_FastPath(t *testing.T) {
 		},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
 	mockCache.On("GetGoalByID", "goal1").Return(defaultGoals[0])
@@ -195,7 +196,7 @@ func TestInitializePlayer_SubsequentLogin_FastPath(t *testing.T) {
 	mockRepo.On("GetActiveGoals", ctx, userID).Return(existingProgress, nil)
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -241,8 +242,8 @@ func TestInitializePlayer_ReturningUser_ActiveGoals(t *testing.T) {
 		{UserID: userID, GoalID: "goal2", ChallengeID: "challenge1", Namespace: namespace, Progress: 3, Status: domain.GoalStatusInProgress, IsActive: true, AssignedAt: &now, ExpiresAt: nil},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
 	mockCache.On("GetGoalByID", "goal1").Return(defaultGoals[0])
@@ -255,7 +256,7 @@ func TestInitializePlayer_ReturningUser_ActiveGoals(t *testing.T) {
 	mockRepo.On("GetActiveGoals", ctx, userID).Return(activeProgress, nil)
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -278,14 +279,14 @@ func TestInitializePlayer_NoDefaultGoals(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// No default-assigned goals configured
 	mockCache.On("GetGoalsWithDefaultAssigned").Return([]*domain.Goal{})
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.NoError(t, err)
@@ -302,16 +303,41 @@ func TestInitializePlayer_NoDefaultGoals(t *testing.T) {
 	mockRepo.AssertNotCalled(t, "BulkInsert")
 }
 
+func TestInitializePlayer_SkipsGoalsOfUnavailableRollouts(t *testing.T) {
+	ctx := WithChallengeRollouts(context.Background(), ChallengeRollouts{"dark": 0})
+	defaultGoals := []*domain.Goal{
+		{ID: "goal1", ChallengeID: "challenge1", DefaultAssigned: true, Requirement: domain.Requirement{TargetValue: 1}},
+		{ID: "goal2", ChallengeID: "dark", DefaultAssigned: true, Requirement: domain.Requirement{TargetValue: 1}},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
+	mockCache.On("GetGoalByID", "goal1").Return(defaultGoals[0])
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, nil)
+	mockRepo.On("BulkInsert", ctx, mock.MatchedBy(func(progresses []*domain.UserGoalProgress) bool {
+		return len(progresses) == 1 && progresses[0].GoalID == "goal1"
+	})).Return(nil)
+
+	result, err := InitializePlayer(ctx, "user123", "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.NewAssignments)
+	require.Len(t, result.AssignedGoals, 1)
+	assert.Equal(t, "goal1", result.AssignedGoals[0].GoalID)
+	mockRepo.AssertExpectations(t)
+}
+
 // Test InitializePlayer - Validation: Empty User ID
 func TestInitializePlayer_EmptyUserID(t *testing.T) {
 	ctx := context.Background()
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Execute
-	result, err := InitializePlayer(ctx, "", namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, "", namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -324,11 +350,11 @@ func TestInitializePlayer_EmptyNamespace(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, "", mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, "", mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -342,10 +368,10 @@ func TestInitializePlayer_NilGoalCache(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, nil, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, nil, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -359,10 +385,10 @@ func TestInitializePlayer_NilRepository(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, nil)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, nil, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -380,8 +406,8 @@ func TestInitializePlayer_GetUserGoalCountError(t *testing.T) {
 		{ID: "goal1", ChallengeID: "challenge1", Name: "Goal 1", DefaultAssigned: true, EventSource: domain.EventSourceLogin, Requirement: domain.Requirement{StatCode: "login_count", Operator: ">=", TargetValue: 1, ProgressMode: domain.ProgressModeAbsolute}, Reward: domain.Reward{Type: string(domain.RewardTypeItem), RewardID: "item1", Quantity: 1}},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
 
@@ -389,7 +415,7 @@ func TestInitializePlayer_GetUserGoalCountError(t *testing.T) {
 	mockRepo.On("GetUserGoalCount", ctx, userID).Return(0, errors.New("database connection failed"))
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -410,8 +436,8 @@ func TestInitializePlayer_GetActiveGoalsError(t *testing.T) {
 		{ID: "goal1", ChallengeID: "challenge1", Name: "Goal 1", DefaultAssigned: true, EventSource: domain.EventSourceLogin, Requirement: domain.Requirement{StatCode: "login_count", Operator: ">=", TargetValue: 1, ProgressMode: domain.ProgressModeAbsolute}, Reward: domain.Reward{Type: string(domain.RewardTypeItem), RewardID: "item1", Quantity: 1}},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
 
@@ -422,7 +448,7 @@ func TestInitializePlayer_GetActiveGoalsError(t *testing.T) {
 	mockRepo.On("GetActiveGoals", ctx, userID).Return(nil, errors.New("connection lost"))
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -447,8 +473,8 @@ func TestInitializePlayer_BulkInsertError(t *testing.T) {
 		{ID: "goal1", ChallengeID: "challenge1", Name: "Goal 1", DefaultAssigned: true, EventSource: domain.EventSourceLogin, Requirement: domain.Requirement{StatCode: "login_count", Operator: ">=", TargetValue: 1, ProgressMode: domain.ProgressModeAbsolute}, Reward: domain.Reward{Type: string(domain.RewardTypeItem), RewardID: "item1", Quantity: 1}},
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
 
@@ -461,7 +487,7 @@ func TestInitializePlayer_BulkInsertError(t *testing.T) {
 	mockRepo.On("BulkInsert", ctx, mock.Anything).Return(errors.New("unique constraint violation"))
 
 	// Execute
-	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo)
+	result, err := InitializePlayer(ctx, userID, namespace, mockCache, mockRepo, nil, logrus.StandardLogger())
 
 	// Assert
 	require.Error(t, err)
@@ -494,12 +520,12 @@ func TestMapToAssignedGoals_GoalNotFoundInCache(t *testing.T) {
 
 	goals := []*domain.Goal{} // No goals in list
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 	// Goal not found in cache
 	mockCache.On("GetGoalByID", "missing_goal").Return(nil)
 
 	// Execute
-	result := mapToAssignedGoals(progresses, goals, mockCache)
+	result := mapToAssignedGoals(progresses, goals, mockCache, logrus.StandardLogger())
 
 	// Assert - should skip goals not found in cache
 	assert.Len(t, result, 0)
@@ -543,11 +569,11 @@ func TestMapToAssignedGoals_CompleteMapping(t *testing.T) {
 		},
 	}
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 	mockCache.On("GetGoalByID", "goal1").Return(goal)
 
 	// Execute
-	result := mapToAssignedGoals(progresses, []*domain.Goal{goal}, mockCache)
+	result := mapToAssignedGoals(progresses, []*domain.Goal{goal}, mockCache, logrus.StandardLogger())
 
 	// Assert
 	require.Len(t, result, 1)
@@ -578,3 +604,97 @@ func TestMapToAssignedGoals_CompleteMapping(t *testing.T) {
 
 	mockCache.AssertExpectations(t)
 }
+
+func threeDefaultGoals() []*domain.Goal {
+	goals := make([]*domain.Goal, 3)
+	for i, id := range []string{"goal1", "goal2", "goal3"} {
+		goals[i] = &domain.Goal{
+			ID:              id,
+			ChallengeID:     "challenge1",
+			DefaultAssigned: true,
+			Requirement:     domain.Requirement{StatCode: id, TargetValue: 1},
+		}
+	}
+	return goals
+}
+
+func TestInitializePlayer_MixedInsertResults(t *testing.T) {
+	ctx := context.Background()
+	defaultGoals := threeDefaultGoals()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockInserter := new(mocks.ProgressInsertRepository)
+
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
+	for _, goal := range defaultGoals {
+		mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	}
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, nil)
+
+	// goal1 is created, goal2 was created by a concurrent login, goal3 violates a constraint
+	mockInserter.On("BulkInsertReturningConflicts", ctx, mock.MatchedBy(func(progresses []*domain.UserGoalProgress) bool {
+		return len(progresses) == 3
+	})).Return(&serviceRepo.BulkInsertResult{
+		Inserted: []serviceRepo.UserGoalKey{{UserID: "user123", GoalID: "goal1"}},
+		Existing: []serviceRepo.UserGoalKey{{UserID: "user123", GoalID: "goal2"}},
+		Failed:   []serviceRepo.FailedInsert{{UserID: "user123", GoalID: "goal3", Err: errors.New("check constraint")}},
+	}, nil)
+	mockRepo.On("GetGoalsByIDs", ctx, "user123", []string{"goal2"}).Return([]*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: "goal2", ChallengeID: "challenge1", Progress: 1, Status: domain.GoalStatusCompleted, IsActive: true},
+	}, nil)
+
+	result, err := InitializePlayer(ctx, "user123", "test-namespace", mockCache, mockRepo, mockInserter, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.NewAssignments)
+	assert.Equal(t, 2, result.TotalActive)
+	require.Len(t, result.AssignedGoals, 2)
+	assert.Equal(t, "goal1", result.AssignedGoals[0].GoalID)
+	assert.Equal(t, ActivationSourceDefault, result.AssignedGoals[0].ActivationSource)
+	// The existing row is returned as stored, with its source left to be resolved
+	assert.Equal(t, "goal2", result.AssignedGoals[1].GoalID)
+	assert.Equal(t, string(domain.GoalStatusCompleted), result.AssignedGoals[1].Status)
+	assert.Empty(t, result.AssignedGoals[1].ActivationSource)
+	mockRepo.AssertNotCalled(t, "BulkInsert", mock.Anything, mock.Anything)
+	mockInserter.AssertExpectations(t)
+}
+
+func TestInitializePlayer_ExistingRowsReadFailureDoesNotFailLogin(t *testing.T) {
+	ctx := context.Background()
+	defaultGoals := threeDefaultGoals()[:2]
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockInserter := new(mocks.ProgressInsertRepository)
+
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
+	mockCache.On("GetGoalByID", "goal1").Return(defaultGoals[0])
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, nil)
+	mockInserter.On("BulkInsertReturningConflicts", ctx, mock.Anything).Return(&serviceRepo.BulkInsertResult{
+		Inserted: []serviceRepo.UserGoalKey{{UserID: "user123", GoalID: "goal1"}},
+		Existing: []serviceRepo.UserGoalKey{{UserID: "user123", GoalID: "goal2"}},
+	}, nil)
+	mockRepo.On("GetGoalsByIDs", ctx, "user123", []string{"goal2"}).Return(nil, errors.New("connection reset"))
+
+	result, err := InitializePlayer(ctx, "user123", "test-namespace", mockCache, mockRepo, mockInserter, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.NewAssignments)
+	require.Len(t, result.AssignedGoals, 1)
+	assert.Equal(t, "goal1", result.AssignedGoals[0].GoalID)
+}
+
+func TestInitializePlayer_InserterError(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockInserter := new(mocks.ProgressInsertRepository)
+
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(threeDefaultGoals())
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, nil)
+	mockInserter.On("BulkInsertReturningConflicts", ctx, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	result, err := InitializePlayer(ctx, "user123", "test-namespace", mockCache, mockRepo, mockInserter, logrus.StandardLogger())
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to bulk insert goals")
+}
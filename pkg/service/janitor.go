@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSelectionHistoryRetention is how long goal selection events are kept.
+	DefaultSelectionHistoryRetention = 90 * 24 * time.Hour
+
+	// DefaultJanitorInterval is how often the janitor deletes expired rows.
+	DefaultJanitorInterval = time.Hour
+
+	// janitorBatchSize bounds the rows one delete statement removes.
+	janitorBatchSize = 1000
+)
+
+// Janitor deletes history rows past their retention period, currently the goal
+// selection events (goal_selection_events). It deletes in batches, so a large
+// backlog after enabling it does not hold long locks.
+type Janitor struct {
+	selections         serviceRepo.GoalSelectionRepository
+	selectionRetention time.Duration
+	interval           time.Duration
+	logger             logrus.FieldLogger
+	now                func() time.Time
+
+	deleted *prometheus.CounterVec
+}
+
+// NewJanitor creates a janitor keeping goal selection events for
+// selectionRetention, logging through logger.
+func NewJanitor(
+	selections serviceRepo.GoalSelectionRepository,
+	selectionRetention time.Duration,
+	interval time.Duration,
+	logger logrus.FieldLogger,
+) *Janitor {
+	return &Janitor{
+		selections:         selections,
+		selectionRetention: selectionRetention,
+		interval:           interval,
+		logger:             logger,
+		now:                func() time.Time { return time.Now().UTC() },
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "janitor_rows_deleted_total",
+			Help: "Rows deleted by the janitor because they are past their retention period, by table.",
+		}, []string{"table"}),
+	}
+}
+
+// NewJanitorFromEnv creates a janitor keeping goal selection events for
+// SELECTION_HISTORY_RETENTION (default "2160h", 90 days) and running every
+// JANITOR_INTERVAL (default "1h").
+func NewJanitorFromEnv(selections serviceRepo.GoalSelectionRepository, logger logrus.FieldLogger) *Janitor {
+	return NewJanitor(
+		selections,
+		parseClaimRecoveryDuration("SELECTION_HISTORY_RETENTION", DefaultSelectionHistoryRetention, 0, logger),
+		parseClaimRecoveryDuration("JANITOR_INTERVAL", DefaultJanitorInterval, 0, logger),
+		logger,
+	)
+}
+
+// Collectors returns the janitor metrics for registration.
+func (j *Janitor) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{j.deleted}
+}
+
+// SelectionRetention returns how long goal selection events are kept.
+func (j *Janitor) SelectionRetention() time.Duration {
+	return j.selectionRetention
+}
+
+// PurgeSelectionHistory deletes the goal selection events older than the
+// retention period, batch by batch until none are left or ctx is done, and
+// returns the number deleted.
+func (j *Janitor) PurgeSelectionHistory(ctx context.Context) (int, error) {
+	before := j.now().Add(-j.selectionRetention)
+
+	total := 0
+	for {
+		deleted, err := j.selections.DeleteSelectionEventsBefore(ctx, before, janitorBatchSize)
+		total += deleted
+		j.deleted.WithLabelValues("goal_selection_events").Add(float64(deleted))
+		if err != nil {
+			return total, err
+		}
+		if deleted < janitorBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// Run purges expired rows every interval until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := j.PurgeSelectionHistory(ctx)
+		if err != nil && ctx.Err() == nil {
+			j.logger.WithError(err).Warn("Failed to purge goal selection history")
+		}
+		if deleted > 0 {
+			j.logger.WithFields(logrus.Fields{
+				"table":   "goal_selection_events",
+				"deleted": deleted,
+			}).Info("Purged expired rows")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
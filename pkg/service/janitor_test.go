@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var janitorNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestJanitor(selections *mocks.GoalSelectionRepository) *Janitor {
+	janitor := NewJanitor(selections, 24*time.Hour, time.Hour, logrus.StandardLogger())
+	janitor.now = func() time.Time { return janitorNow }
+	return janitor
+}
+
+func TestJanitor_PurgeSelectionHistoryDeletesInBatches(t *testing.T) {
+	cutoff := janitorNow.Add(-24 * time.Hour)
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("DeleteSelectionEventsBefore", mock.Anything, cutoff, janitorBatchSize).Return(janitorBatchSize, nil).Twice()
+	selections.On("DeleteSelectionEventsBefore", mock.Anything, cutoff, janitorBatchSize).Return(12, nil).Once()
+
+	janitor := newTestJanitor(selections)
+	deleted, err := janitor.PurgeSelectionHistory(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2*janitorBatchSize+12, deleted)
+	assert.Equal(t, float64(deleted), testutil.ToFloat64(janitor.deleted.WithLabelValues("goal_selection_events")))
+	selections.AssertExpectations(t)
+}
+
+func TestJanitor_PurgeSelectionHistoryError(t *testing.T) {
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("DeleteSelectionEventsBefore", mock.Anything, mock.Anything, janitorBatchSize).Return(0, errors.New("connection reset"))
+
+	_, err := newTestJanitor(selections).PurgeSelectionHistory(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestNewJanitorFromEnv(t *testing.T) {
+	t.Setenv("SELECTION_HISTORY_RETENTION", "720h")
+	t.Setenv("JANITOR_INTERVAL", "invalid")
+
+	janitor := NewJanitorFromEnv(new(mocks.GoalSelectionRepository), logrus.StandardLogger())
+
+	assert.Equal(t, 720*time.Hour, janitor.SelectionRetention())
+	assert.Equal(t, DefaultJanitorInterval, janitor.interval)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+)
+
+// LateEventPolicy decides what happens to a progress event that occurred in an
+// earlier period of a rotating goal than the current one, e.g. a match that
+// ended just before the daily reset and was reported just after it.
+type LateEventPolicy string
+
+const (
+	// LateEventCount applies late events to the current period. This is the
+	// default and how events without a timestamp are always handled.
+	LateEventCount LateEventPolicy = "count"
+	// LateEventDrop skips late events for the goal.
+	LateEventDrop LateEventPolicy = "drop"
+	// LateEventPreviousDay applies late events to the period they occurred in,
+	// as long as that is the previous period and the user's progress row has not
+	// moved on to the current one yet. Other late events are skipped.
+	LateEventPreviousDay LateEventPolicy = "previous_day"
+)
+
+// LateEventPolicies maps challenge IDs to their "lateEventPolicy" from the
+// challenge config. Challenges not listed, and a nil LateEventPolicies, use
+// LateEventCount.
+//
+// domain.Challenge (extend-challenge-common) has no such field, so the policy is
+// read from the config file separately by LoadLateEventPolicies.
+type LateEventPolicies map[string]LateEventPolicy
+
+// lateEventConfig is the subset of challenges.json needed to read the policy.
+type lateEventConfig struct {
+	Challenges []struct {
+		ID              string          `json:"challengeId"`
+		LateEventPolicy LateEventPolicy `json:"lateEventPolicy"`
+	} `json:"challenges"`
+}
+
+// LoadLateEventPolicies reads the challenges' late event policies from the
+// challenge config file.
+func LoadLateEventPolicies(configPath string) (LateEventPolicies, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseLateEventPolicies(data)
+}
+
+// ParseLateEventPolicies extracts the challenges' late event policies from
+// challenge config JSON. An unknown policy is an error.
+func ParseLateEventPolicies(data []byte) (LateEventPolicies, error) {
+	var cfg lateEventConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	policies := make(LateEventPolicies)
+	for _, challenge := range cfg.Challenges {
+		switch challenge.LateEventPolicy {
+		case "", LateEventCount:
+		case LateEventDrop, LateEventPreviousDay:
+			policies[challenge.ID] = challenge.LateEventPolicy
+		default:
+			return nil, fmt.Errorf("challenge %q: invalid lateEventPolicy %q (must be %q, %q or %q)",
+				challenge.ID, challenge.LateEventPolicy, LateEventCount, LateEventDrop, LateEventPreviousDay)
+		}
+	}
+
+	return policies, nil
+}
+
+// For returns the late event policy of a challenge.
+func (p LateEventPolicies) For(challengeID string) LateEventPolicy {
+	if policy, ok := p[challengeID]; ok {
+		return policy
+	}
+	return LateEventCount
+}
+
+// lateEventRoute is where one event's update for one goal is written.
+type lateEventRoute int
+
+const (
+	// routeCurrent writes the update to the current period.
+	routeCurrent lateEventRoute = iota
+	// routePrevious writes the update to the previous period.
+	routePrevious
+	// routeSkip skips the goal as SkipReasonLate.
+	routeSkip
+)
+
+// route buckets an event for goal by the goal's reset boundary (midnight UTC for
+// daily goals) and decides where it goes. Goals that do not rotate have a single
+// bucket, and events without a timestamp belong to the current period.
+//
+// For routePrevious, it also returns the start of the previous period; the
+// current period starts at currentStart.
+func (p LateEventPolicies) route(goal *domain.Goal, occurredAt time.Time, currentStart time.Time) (lateEventRoute, time.Time) {
+	if occurredAt.IsZero() || goal.Rotation == nil || !goal.Rotation.Enabled || !occurredAt.Before(currentStart) {
+		return routeCurrent, time.Time{}
+	}
+
+	switch p.For(goal.ChallengeID) {
+	case LateEventDrop:
+		return routeSkip, time.Time{}
+	case LateEventPreviousDay:
+		previousStart := rotation.CalculateLastRotationBoundary(goal.Rotation.Schedule, currentStart.Add(-time.Nanosecond))
+		if occurredAt.Before(previousStart) {
+			return routeSkip, time.Time{}
+		}
+		return routePrevious, previousStart
+	default:
+		return routeCurrent, time.Time{}
+	}
+}
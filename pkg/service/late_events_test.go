@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLateEventPolicies(t *testing.T) {
+	data := []byte(`{
+		"challenges": [
+			{"challengeId": "default"},
+			{"challengeId": "counted", "lateEventPolicy": "count"},
+			{"challengeId": "strict", "lateEventPolicy": "drop"},
+			{"challengeId": "backdated", "lateEventPolicy": "previous_day"}
+		]
+	}`)
+
+	policies, err := ParseLateEventPolicies(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, LateEventPolicies{"strict": LateEventDrop, "backdated": LateEventPreviousDay}, policies)
+	assert.Equal(t, LateEventCount, policies.For("default"))
+	assert.Equal(t, LateEventCount, policies.For("counted"))
+	assert.Equal(t, LateEventDrop, policies.For("strict"))
+}
+
+func TestParseLateEventPolicies_Invalid(t *testing.T) {
+	_, err := ParseLateEventPolicies([]byte(`{"challenges": [{"challengeId": "daily", "lateEventPolicy": "yesterday"}]}`))
+	assert.ErrorContains(t, err, `invalid lateEventPolicy "yesterday"`)
+
+	_, err = ParseLateEventPolicies([]byte(`{"challenges":`))
+	assert.Error(t, err)
+}
+
+func dailyGoal(challengeID string) *domain.Goal {
+	return &domain.Goal{
+		ID:          "daily-wins",
+		ChallengeID: challengeID,
+		Requirement: domain.Requirement{StatCode: "wins", TargetValue: 3, ProgressMode: domain.ProgressModeRelative},
+		Rotation: &domain.RotationConfig{
+			Enabled:  true,
+			Type:     domain.RotationTypeGlobal,
+			Schedule: domain.RotationScheduleDaily,
+			OnExpiry: domain.OnExpiryConfig{ResetProgress: true},
+		},
+	}
+}
+
+func TestLateEventPolicies_RouteAtMidnight(t *testing.T) {
+	midnight := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	yesterday := midnight.Add(-24 * time.Hour)
+	policies := LateEventPolicies{"drop": LateEventDrop, "backdate": LateEventPreviousDay}
+
+	tests := []struct {
+		name       string
+		challenge  string
+		occurredAt time.Time
+		want       lateEventRoute
+		wantStart  time.Time
+	}{
+		{"no timestamp", "drop", time.Time{}, routeCurrent, time.Time{}},
+		{"at midnight", "drop", midnight, routeCurrent, time.Time{}},
+		{"count", "count", midnight.Add(-time.Second), routeCurrent, time.Time{}},
+		{"drop", "drop", midnight.Add(-time.Second), routeSkip, time.Time{}},
+		{"previous day", "backdate", midnight.Add(-time.Second), routePrevious, yesterday},
+		{"start of previous day", "backdate", yesterday, routePrevious, yesterday},
+		{"two days ago", "backdate", yesterday.Add(-time.Second), routeSkip, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, start := policies.route(dailyGoal(tt.challenge), tt.occurredAt, midnight)
+
+			assert.Equal(t, tt.want, route)
+			assert.Equal(t, tt.wantStart, start)
+		})
+	}
+}
+
+func TestLateEventPolicies_NonRotatingGoalsHaveOneBucket(t *testing.T) {
+	goal := dailyGoal("drop")
+	goal.Rotation = nil
+	midnight := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	route, _ := LateEventPolicies{"drop": LateEventDrop}.route(goal, midnight.Add(-48*time.Hour), midnight)
+
+	assert.Equal(t, routeCurrent, route)
+}
+
+func newDailyGoalCache(challengeID string) *mocks.GoalCache {
+	goalCache := new(mocks.GoalCache)
+	goalCache.On("GetGoalsByStatCode", "wins").Return([]*domain.Goal{dailyGoal(challengeID)})
+	return goalCache
+}
+
+func TestBatchReportProgress_LateEventPolicies(t *testing.T) {
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	lastNight := midnight.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		policy      LateEventPolicy
+		wantCurrent int
+		wantStatus  string
+	}{
+		{"count", LateEventCount, 2, ProgressEventApplied},
+		{"drop", LateEventDrop, 1, ProgressEventSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := new(mocks.GoalRepository)
+			queries := new(mocks.ProgressQueryRepository)
+			late := new(mocks.LateProgressRepository)
+
+			queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{
+				{UserID: "user-1", GoalID: "daily-wins"}: domain.GoalStatusInProgress,
+			}, nil)
+			var written []repository.CopyRow
+			repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				written = append(written, args.Get(1).([]repository.CopyRow)...)
+			}).Return(nil)
+
+			result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+				{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), OccurredAt: lastNight},
+				{UserID: "user-1", StatCode: "wins", Delta: intPtr(1)},
+			}, newDailyGoalCache("daily"), repo, queries, new(mocks.InactiveProgressRepository), late,
+				nil,
+				nil, LateEventPolicies{"daily": tt.policy}, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, result.Results[0].Status)
+			assert.Equal(t, ProgressEventApplied, result.Results[1].Status)
+			require.Len(t, written, 1)
+			assert.Equal(t, tt.wantCurrent, written[0].IncValue)
+			assert.Equal(t, midnight, *written[0].RotationBoundary)
+			late.AssertNotCalled(t, "ApplyLateProgress", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBatchReportProgress_LateEventsToPreviousDay(t *testing.T) {
+	ctx := context.Background()
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := midnight.Add(-24 * time.Hour)
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	late := new(mocks.LateProgressRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{
+		{UserID: "user-1", GoalID: "daily-wins"}: domain.GoalStatusInProgress,
+	}, nil)
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Return(nil)
+
+	// Yesterday's events of one user are merged into one row bounded by yesterday;
+	// user-2's row has already moved on to today, so it is not written
+	late.On("ApplyLateProgress", ctx, "test-namespace", mock.MatchedBy(func(rows []repository.CopyRow) bool {
+		return len(rows) == 2 &&
+			rows[0].UserID == "user-1" && rows[0].IncValue == 3 &&
+			rows[0].RotationBoundary.Equal(yesterday) && rows[0].NewExpiresAt.Equal(midnight) &&
+			rows[1].UserID == "user-2"
+	})).Return([]serviceRepo.UserGoalKey{{UserID: "user-1", GoalID: "daily-wins"}}, nil)
+
+	result, err := BatchReportProgress(ctx, "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), OccurredAt: midnight.Add(-time.Second)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(2), OccurredAt: yesterday},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), OccurredAt: midnight},
+		{UserID: "user-2", StatCode: "wins", Delta: intPtr(1), OccurredAt: midnight.Add(-time.Hour)},
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), OccurredAt: yesterday.Add(-time.Second)},
+	}, newDailyGoalCache("daily"), repo, queries, new(mocks.InactiveProgressRepository), late,
+		nil,
+		nil, LateEventPolicies{"daily": LateEventPreviousDay}, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventApplied, result.Results[0].Status)
+	assert.Equal(t, ProgressEventApplied, result.Results[1].Status)
+	assert.Equal(t, ProgressEventApplied, result.Results[2].Status)
+	assert.Equal(t, ProgressEventSkipped, result.Results[3].Status)
+	assert.Equal(t, []SkippedGoal{{GoalID: "daily-wins", Reason: SkipReasonLate}}, result.Results[3].SkippedGoals)
+	// Older than the previous day
+	assert.Equal(t, []SkippedGoal{{GoalID: "daily-wins", Reason: SkipReasonLate}}, result.Results[4].SkippedGoals)
+	assert.Equal(t, 2, result.RowsWritten)
+	late.AssertExpectations(t)
+}
+
+func TestBatchReportProgress_FutureEventIsInvalid(t *testing.T) {
+	result, err := BatchReportProgress(context.Background(), "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: "wins", Delta: intPtr(1), OccurredAt: time.Now().Add(time.Hour)},
+	}, newDailyGoalCache("daily"), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository),
+		new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventInvalid, result.Results[0].Status)
+	assert.Equal(t, "occurred_at is in the future", result.Results[0].Error)
+}
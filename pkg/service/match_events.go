@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// EventSourceMatch is the event source of goals driven by match results
+// (ReportMatchResult) instead of statistics.
+//
+// The config validator of extend-challenge-common only accepts "login" and
+// "statistic" as eventSource, so match goals are declared with eventSource
+// "statistic" and a match stat code; IsMatchStatCode tells them apart.
+const EventSourceMatch domain.EventSource = "match"
+
+// Match stat codes. A match goal's requirement.statCode says what a matching
+// match counts for: MatchStatMatches counts the match itself, MatchStatKills
+// its kills, and "match.<key>" the event's Stats[key].
+//
+// AGS statistic codes cannot contain ".", so no statistic update can reach a
+// match goal.
+const (
+	MatchStatCodePrefix = "match."
+	MatchStatMatches    = MatchStatCodePrefix + "matches"
+	MatchStatKills      = MatchStatCodePrefix + "kills"
+)
+
+// Match results.
+const (
+	MatchResultWin  = "win"
+	MatchResultLoss = "loss"
+	MatchResultDraw = "draw"
+)
+
+// MatchEventNoMatch is the status of a match event no goal's filter matched.
+const MatchEventNoMatch = "no_match"
+
+// ErrInvalidMatchEvent is returned by ReportMatchResult for a malformed match event.
+var ErrInvalidMatchEvent = errors.New("invalid match event")
+
+// IsMatchStatCode reports whether statCode is a match stat code.
+func IsMatchStatCode(statCode string) bool {
+	return strings.HasPrefix(statCode, MatchStatCodePrefix) && len(statCode) > len(MatchStatCodePrefix)
+}
+
+// MatchFilter selects the matches a goal counts, configured with "matchFilter"
+// on the goal. Empty fields match any match; set fields match only events that
+// have the field.
+type MatchFilter struct {
+	// Mode is the game mode, e.g. "ranked".
+	Mode string `json:"mode,omitempty"`
+	// Result is MatchResultWin, MatchResultLoss or MatchResultDraw.
+	Result string `json:"result,omitempty"`
+	// MaxPlacement matches placements from 1 to MaxPlacement, e.g. 3 for a top 3 finish.
+	MaxPlacement int `json:"maxPlacement,omitempty"`
+}
+
+// Matches reports whether the match passes the filter.
+func (f MatchFilter) Matches(event MatchEvent) bool {
+	switch {
+	case f.Mode != "" && event.Mode != f.Mode:
+		return false
+	case f.Result != "" && event.Result != f.Result:
+		return false
+	case f.MaxPlacement > 0 && (event.Placement < 1 || event.Placement > f.MaxPlacement):
+		return false
+	}
+	return true
+}
+
+// MatchGoal is a goal with a match stat code and its filter.
+type MatchGoal struct {
+	GoalID   string
+	StatCode string
+	Filter   MatchFilter
+}
+
+// MatchGoals are the match goals of the challenge config, in config order.
+//
+// domain.Goal (extend-challenge-common) has no matchFilter field, so the
+// filters are read from the config file separately by LoadMatchGoals.
+type MatchGoals []MatchGoal
+
+// matchGoalsConfig is the subset of challenges.json needed to read the match goals.
+type matchGoalsConfig struct {
+	Challenges []struct {
+		Goals []struct {
+			ID          string          `json:"goalId"`
+			MatchFilter json.RawMessage `json:"matchFilter"`
+			Requirement struct {
+				StatCode     string              `json:"statCode"`
+				ProgressMode domain.ProgressMode `json:"progressMode"`
+			} `json:"requirement"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadMatchGoals reads the match goals and their filters from the challenge config file.
+func LoadMatchGoals(configPath string) (MatchGoals, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseMatchGoals(data)
+}
+
+// ParseMatchGoals extracts the match goals from challenge config JSON. Match goals
+// must be relative, since each match adds to them, and matchFilter is only
+// allowed on them. Unknown matchFilter fields are an error, so a typo cannot
+// silently widen a filter.
+func ParseMatchGoals(data []byte) (MatchGoals, error) {
+	var cfg matchGoalsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	var goals MatchGoals
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			hasFilter := len(goal.MatchFilter) > 0 && string(goal.MatchFilter) != "null"
+			if !IsMatchStatCode(goal.Requirement.StatCode) {
+				if hasFilter {
+					return nil, fmt.Errorf("goal %s: matchFilter requires a match stat code (%q, %q or %q), got %q",
+						goal.ID, MatchStatMatches, MatchStatKills, MatchStatCodePrefix+"<stat>", goal.Requirement.StatCode)
+				}
+				continue
+			}
+
+			if goal.Requirement.ProgressMode != domain.ProgressModeRelative {
+				return nil, fmt.Errorf("goal %s: match goals must use progressMode %q, got %q",
+					goal.ID, domain.ProgressModeRelative, goal.Requirement.ProgressMode)
+			}
+
+			matchGoal := MatchGoal{GoalID: goal.ID, StatCode: goal.Requirement.StatCode}
+			if hasFilter {
+				decoder := json.NewDecoder(bytes.NewReader(goal.MatchFilter))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(&matchGoal.Filter); err != nil {
+					return nil, fmt.Errorf("goal %s: invalid matchFilter: %w", goal.ID, err)
+				}
+				if err := validateMatchFilter(matchGoal.Filter); err != nil {
+					return nil, fmt.Errorf("goal %s: %w", goal.ID, err)
+				}
+			}
+			goals = append(goals, matchGoal)
+		}
+	}
+
+	return goals, nil
+}
+
+func validateMatchFilter(filter MatchFilter) error {
+	switch filter.Result {
+	case "", MatchResultWin, MatchResultLoss, MatchResultDraw:
+	default:
+		return fmt.Errorf("invalid matchFilter result %q (must be %q, %q or %q)",
+			filter.Result, MatchResultWin, MatchResultLoss, MatchResultDraw)
+	}
+	if filter.MaxPlacement < 0 {
+		return fmt.Errorf("matchFilter maxPlacement cannot be negative, got %d", filter.MaxPlacement)
+	}
+	return nil
+}
+
+// MatchEvent is one player's result of one match, reported by a game server.
+type MatchEvent struct {
+	UserID string
+	Mode   string
+	// Result is MatchResultWin, MatchResultLoss, MatchResultDraw or empty.
+	Result string
+	// Placement is the final placement, 1 for first. Zero means unranked.
+	Placement int
+	Kills     int
+	// Stats are other per-player counters, counted by goals with stat code "match.<key>".
+	Stats map[string]int
+	// OccurredAt is when the match ended. Zero means now.
+	OccurredAt time.Time
+}
+
+// validateMatchEvent returns why the event is invalid, or "".
+func validateMatchEvent(event MatchEvent) string {
+	switch {
+	case event.UserID == "":
+		return "user_id is required"
+	case event.Result != "" && event.Result != MatchResultWin && event.Result != MatchResultLoss && event.Result != MatchResultDraw:
+		return fmt.Sprintf("result must be %q, %q or %q", MatchResultWin, MatchResultLoss, MatchResultDraw)
+	case event.Placement < 0:
+		return "placement cannot be negative"
+	case event.Kills < 0:
+		return "kills cannot be negative"
+	case event.OccurredAt.After(time.Now().Add(maxEventClockSkew)):
+		return "occurred_at is in the future"
+	}
+	for key, value := range event.Stats {
+		if value < 0 {
+			return fmt.Sprintf("stats[%q] cannot be negative", key)
+		}
+	}
+	return ""
+}
+
+// delta returns what the event adds to goals with statCode. Counters the event
+// does not have add nothing.
+func (e MatchEvent) delta(statCode string) int {
+	switch statCode {
+	case MatchStatMatches:
+		return 1
+	case MatchStatKills:
+		return e.Kills
+	default:
+		return e.Stats[strings.TrimPrefix(statCode, MatchStatCodePrefix)]
+	}
+}
+
+// ProgressEvents maps a match to one progress delta per goal whose filter it
+// matches and that it adds to, in config order. Goals no longer in goalCache
+// are left out.
+func (g MatchGoals) ProgressEvents(event MatchEvent, goalCache cache.GoalCache) []ProgressEvent {
+	var events []ProgressEvent
+	for _, goal := range g {
+		if !goal.Filter.Matches(event) || goalCache.GetGoalByID(goal.GoalID) == nil {
+			continue
+		}
+
+		delta := event.delta(goal.StatCode)
+		if delta <= 0 {
+			continue
+		}
+		events = append(events, ProgressEvent{
+			UserID:     event.UserID,
+			StatCode:   goal.StatCode,
+			GoalID:     goal.GoalID,
+			Delta:      &delta,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	return events
+}
+
+// MatchEventResult is the outcome of ReportMatchResult.
+type MatchEventResult struct {
+	UserID string
+	// Status is one of the ProgressEvent statuses, or MatchEventNoMatch.
+	Status         string
+	AppliedGoalIDs []string
+	SkippedGoals   []SkippedGoal
+	FailedGoalIDs  []string
+	// Error explains a failed event.
+	Error string
+}
+
+// ReportMatchResult applies a match result to the user's match goals whose
+// filter it matches, as BatchReportProgress deltas: only active goals are
+// updated (or inactive ones of challenges that track inactive progress), and
+// late events follow the challenge's LateEventPolicy.
+//
+// An invalid event returns ErrInvalidMatchEvent. Write failures are logged
+// through logger.
+func ReportMatchResult(
+	ctx context.Context,
+	namespace string,
+	event MatchEvent,
+	matchGoals MatchGoals,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	queries serviceRepo.ProgressQueryRepository,
+	inactive serviceRepo.InactiveProgressRepository,
+	late serviceRepo.LateProgressRepository,
+	policy InactiveProgressPolicy,
+	latePolicies LateEventPolicies,
+	config BatchProgressConfig,
+	logger logrus.FieldLogger,
+) (*MatchEventResult, error) {
+	if msg := validateMatchEvent(event); msg != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMatchEvent, msg)
+	}
+
+	result := &MatchEventResult{UserID: event.UserID}
+	events := matchGoals.ProgressEvents(event, goalCache)
+	if len(events) == 0 {
+		result.Status = MatchEventNoMatch
+		return result, nil
+	}
+
+	// One event per goal, so the batch limit only needs room for this match
+	config.MaxEvents = max(config.MaxEvents, len(events))
+	// Match stat codes cannot be steps of multi-step goals (see ParseGoalSteps)
+	batch, err := BatchReportProgress(ctx, namespace, events, goalCache, repo, queries, inactive, late, nil, policy, latePolicies, nil, config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range batch.Results {
+		result.AppliedGoalIDs = append(result.AppliedGoalIDs, r.AppliedGoalIDs...)
+		result.SkippedGoals = append(result.SkippedGoals, r.SkippedGoals...)
+		result.FailedGoalIDs = append(result.FailedGoalIDs, r.FailedGoalIDs...)
+		if r.Error != "" {
+			result.Error = r.Error
+		}
+	}
+	result.Status = progressEventStatus(&ProgressEventResult{
+		AppliedGoalIDs: result.AppliedGoalIDs,
+		FailedGoalIDs:  result.FailedGoalIDs,
+	})
+
+	return result, nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const matchGoalsConfigJSON = `{"challenges": [{"challengeId": "season", "name": "Season", "description": "Season goals", "goals": [
+	{
+		"goalId": "win-3-ranked", "name": "Win 3 ranked", "description": "Win 3 ranked matches", "eventSource": "statistic",
+		"requirement": {"statCode": "match.matches", "operator": ">=", "targetValue": 3, "progressMode": "relative"},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100},
+		"matchFilter": {"mode": "ranked", "result": "win"}
+	},
+	{
+		"goalId": "ranked-kills", "name": "Ranked kills", "description": "50 kills in ranked", "eventSource": "statistic",
+		"requirement": {"statCode": "match.kills", "operator": ">=", "targetValue": 50, "progressMode": "relative"},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100},
+		"matchFilter": {"mode": "ranked"}
+	},
+	{
+		"goalId": "top-3", "name": "Top 3", "description": "Finish top 3", "eventSource": "statistic",
+		"requirement": {"statCode": "match.matches", "operator": ">=", "targetValue": 1, "progressMode": "relative"},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100},
+		"matchFilter": {"maxPlacement": 3}
+	},
+	{
+		"goalId": "any-assists", "name": "Assists", "description": "Assist in any match", "eventSource": "statistic",
+		"requirement": {"statCode": "match.assists", "operator": ">=", "targetValue": 20, "progressMode": "relative"},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100}
+	},
+	{
+		"goalId": "kill-100", "name": "Kills", "description": "Kill 100", "eventSource": "statistic",
+		"requirement": {"statCode": "kills", "operator": ">=", "targetValue": 100},
+		"reward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 100}
+	}
+]}]}`
+
+func testMatchGoals() MatchGoals {
+	return MatchGoals{
+		{GoalID: "win-3-ranked", StatCode: MatchStatMatches, Filter: MatchFilter{Mode: "ranked", Result: MatchResultWin}},
+		{GoalID: "ranked-kills", StatCode: MatchStatKills, Filter: MatchFilter{Mode: "ranked"}},
+		{GoalID: "top-3", StatCode: MatchStatMatches, Filter: MatchFilter{MaxPlacement: 3}},
+		{GoalID: "any-assists", StatCode: "match.assists"},
+	}
+}
+
+// newMatchGoalCache returns a goal cache with the relative goals of testMatchGoals.
+func newMatchGoalCache() *mocks.GoalCache {
+	goalCache := new(mocks.GoalCache)
+	byStatCode := make(map[string][]*domain.Goal)
+	for _, matchGoal := range testMatchGoals() {
+		goal := &domain.Goal{
+			ID:          matchGoal.GoalID,
+			ChallengeID: "season",
+			Requirement: domain.Requirement{StatCode: matchGoal.StatCode, TargetValue: 10, ProgressMode: domain.ProgressModeRelative},
+		}
+		goalCache.On("GetGoalByID", goal.ID).Return(goal)
+		byStatCode[matchGoal.StatCode] = append(byStatCode[matchGoal.StatCode], goal)
+	}
+	for statCode, goals := range byStatCode {
+		goalCache.On("GetGoalsByStatCode", statCode).Return(goals)
+	}
+	return goalCache
+}
+
+func TestParseMatchGoals(t *testing.T) {
+	goals, err := ParseMatchGoals([]byte(matchGoalsConfigJSON))
+
+	require.NoError(t, err)
+	assert.Equal(t, testMatchGoals(), goals)
+}
+
+func TestParseMatchGoals_AcceptedByCommonConfigLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "challenges.json")
+	require.NoError(t, os.WriteFile(path, []byte(matchGoalsConfigJSON), 0o600))
+
+	cfg, err := config.NewConfigLoader(path, slog.New(slog.DiscardHandler)).LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.Challenges, 1)
+
+	goals, err := LoadMatchGoals(path)
+	require.NoError(t, err)
+	assert.Len(t, goals, 4)
+}
+
+func TestParseMatchGoals_Invalid(t *testing.T) {
+	goal := func(statCode, progressMode, filter string) string {
+		return `{"challenges": [{"goals": [{"goalId": "g1", "requirement": {"statCode": "` + statCode +
+			`", "progressMode": "` + progressMode + `"}, "matchFilter": ` + filter + `}]}]}`
+	}
+	tests := map[string]string{
+		"filter on stat goal":    goal("kills", "relative", `{"mode": "ranked"}`),
+		"absolute match goal":    goal("match.matches", "absolute", `null`),
+		"default mode":           goal("match.matches", "", `{}`),
+		"unknown result":         goal("match.matches", "relative", `{"result": "victory"}`),
+		"negative placement":     goal("match.matches", "relative", `{"maxPlacement": -1}`),
+		"unknown filter field":   goal("match.matches", "relative", `{"mdoe": "ranked"}`),
+		"filter of wrong type":   goal("match.matches", "relative", `"ranked"`),
+		"malformed":              `{"challenges": [`,
+		"empty match stat":       goal("match.", "relative", `{"mode": "ranked"}`),
+		"placement not a number": goal("match.matches", "relative", `{"maxPlacement": "3"}`),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMatchGoals([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseMatchGoals_NullFilterOnStatGoal(t *testing.T) {
+	goals, err := ParseMatchGoals([]byte(`{"challenges": [{"goals": [
+		{"goalId": "g1", "requirement": {"statCode": "kills"}, "matchFilter": null}
+	]}]}`))
+
+	require.NoError(t, err)
+	assert.Empty(t, goals)
+}
+
+func TestMatchFilter_Matches(t *testing.T) {
+	ranked := MatchEvent{UserID: "user-1", Mode: "ranked", Result: MatchResultWin, Placement: 2}
+
+	tests := []struct {
+		name   string
+		filter MatchFilter
+		event  MatchEvent
+		want   bool
+	}{
+		{"empty filter matches anything", MatchFilter{}, MatchEvent{UserID: "user-1"}, true},
+		{"all fields match", MatchFilter{Mode: "ranked", Result: MatchResultWin, MaxPlacement: 3}, ranked, true},
+		{"other mode", MatchFilter{Mode: "casual"}, ranked, false},
+		{"other result", MatchFilter{Result: MatchResultLoss}, ranked, false},
+		{"placement too low", MatchFilter{MaxPlacement: 1}, ranked, false},
+		{"placement at the limit", MatchFilter{MaxPlacement: 2}, ranked, true},
+		{"missing mode", MatchFilter{Mode: "ranked"}, MatchEvent{UserID: "user-1", Result: MatchResultWin}, false},
+		{"missing result", MatchFilter{Result: MatchResultWin}, MatchEvent{UserID: "user-1", Mode: "ranked"}, false},
+		{"missing placement", MatchFilter{MaxPlacement: 3}, MatchEvent{UserID: "user-1", Mode: "ranked"}, false},
+		{"mode is case sensitive", MatchFilter{Mode: "ranked"}, MatchEvent{UserID: "user-1", Mode: "Ranked"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.event))
+		})
+	}
+}
+
+func TestMatchGoals_ProgressEvents(t *testing.T) {
+	goalCache := newMatchGoalCache()
+	occurredAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("multiple matching goals", func(t *testing.T) {
+		events := testMatchGoals().ProgressEvents(MatchEvent{
+			UserID:     "user-1",
+			Mode:       "ranked",
+			Result:     MatchResultWin,
+			Placement:  1,
+			Kills:      7,
+			Stats:      map[string]int{"assists": 4},
+			OccurredAt: occurredAt,
+		}, goalCache)
+
+		assert.Equal(t, []ProgressEvent{
+			{UserID: "user-1", StatCode: MatchStatMatches, GoalID: "win-3-ranked", Delta: intPtr(1), OccurredAt: occurredAt},
+			{UserID: "user-1", StatCode: MatchStatKills, GoalID: "ranked-kills", Delta: intPtr(7), OccurredAt: occurredAt},
+			{UserID: "user-1", StatCode: MatchStatMatches, GoalID: "top-3", Delta: intPtr(1), OccurredAt: occurredAt},
+			{UserID: "user-1", StatCode: "match.assists", GoalID: "any-assists", Delta: intPtr(4), OccurredAt: occurredAt},
+		}, events)
+	})
+
+	t.Run("loss counts only kills", func(t *testing.T) {
+		events := testMatchGoals().ProgressEvents(MatchEvent{UserID: "user-1", Mode: "ranked", Result: MatchResultLoss, Kills: 3}, goalCache)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, "ranked-kills", events[0].GoalID)
+		assert.Equal(t, 3, *events[0].Delta)
+	})
+
+	t.Run("missing counters add nothing", func(t *testing.T) {
+		events := testMatchGoals().ProgressEvents(MatchEvent{UserID: "user-1", Mode: "casual"}, goalCache)
+
+		assert.Empty(t, events)
+	})
+
+	t.Run("goals removed from the cache are left out", func(t *testing.T) {
+		goals := append(testMatchGoals(), MatchGoal{GoalID: "removed", StatCode: MatchStatMatches})
+		emptyCache := new(mocks.GoalCache)
+		emptyCache.On("GetGoalByID", mock.Anything).Return(nil)
+
+		assert.Empty(t, goals.ProgressEvents(MatchEvent{UserID: "user-1", Mode: "ranked"}, emptyCache))
+	})
+}
+
+func TestReportMatchResult_AppliesMatchingGoals(t *testing.T) {
+	ctx := context.Background()
+	goalCache := newMatchGoalCache()
+	repo := new(mocks.GoalRepository)
+	queries := new(mocks.ProgressQueryRepository)
+
+	queries.On("GetActiveGoalStatuses", ctx, "test-namespace", mock.Anything, mock.Anything).Return(map[serviceRepo.UserGoalKey]domain.GoalStatus{
+		{UserID: "user-1", GoalID: "win-3-ranked"}: domain.GoalStatusInProgress,
+		{UserID: "user-1", GoalID: "ranked-kills"}: domain.GoalStatusInProgress,
+		{UserID: "user-1", GoalID: "top-3"}:        domain.GoalStatusClaimed,
+	}, nil)
+	var written []repository.CopyRow
+	repo.On("BatchUpsertProgressWithCOPY", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(1).([]repository.CopyRow)...)
+	}).Return(nil)
+
+	result, err := ReportMatchResult(ctx, "test-namespace", MatchEvent{
+		UserID:    "user-1",
+		Mode:      "ranked",
+		Result:    MatchResultWin,
+		Placement: 3,
+		Kills:     5,
+	}, testMatchGoals(), goalCache, repo, queries, new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository),
+		nil, nil, BatchProgressConfig{MaxEvents: 1, ChunkSize: 10}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventApplied, result.Status)
+	assert.Equal(t, []string{"win-3-ranked", "ranked-kills"}, result.AppliedGoalIDs)
+	assert.Equal(t, []SkippedGoal{{GoalID: "top-3", Reason: SkipReasonClaimed}}, result.SkippedGoals)
+
+	require.Len(t, written, 2)
+	increments := map[string]int{}
+	for _, row := range written {
+		increments[row.GoalID] = row.IncValue
+	}
+	assert.Equal(t, map[string]int{"win-3-ranked": 1, "ranked-kills": 5}, increments)
+}
+
+func TestReportMatchResult_NoMatch(t *testing.T) {
+	result, err := ReportMatchResult(context.Background(), "test-namespace", MatchEvent{UserID: "user-1", Mode: "casual"},
+		testMatchGoals(), newMatchGoalCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository),
+		new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository), nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, MatchEventNoMatch, result.Status)
+	assert.Empty(t, result.AppliedGoalIDs)
+}
+
+func TestReportMatchResult_Invalid(t *testing.T) {
+	tests := map[string]MatchEvent{
+		"missing user":      {Mode: "ranked"},
+		"unknown result":    {UserID: "user-1", Result: "victory"},
+		"negative kills":    {UserID: "user-1", Kills: -1},
+		"negative stat":     {UserID: "user-1", Stats: map[string]int{"assists": -2}},
+		"negative place":    {UserID: "user-1", Placement: -1},
+		"future occurrence": {UserID: "user-1", OccurredAt: time.Now().Add(time.Hour)},
+	}
+
+	for name, event := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReportMatchResult(context.Background(), "test-namespace", event, testMatchGoals(), newMatchGoalCache(),
+				new(mocks.GoalRepository), new(mocks.ProgressQueryRepository), new(mocks.InactiveProgressRepository),
+				new(mocks.LateProgressRepository), nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+			assert.True(t, errors.Is(err, ErrInvalidMatchEvent))
+		})
+	}
+}
+
+func TestBatchReportProgress_MatchStatCodeRequiresGoal(t *testing.T) {
+	result, err := BatchReportProgress(context.Background(), "test-namespace", []ProgressEvent{
+		{UserID: "user-1", StatCode: MatchStatKills, Delta: intPtr(5)},
+	}, newMatchGoalCache(), new(mocks.GoalRepository), new(mocks.ProgressQueryRepository),
+		new(mocks.InactiveProgressRepository), new(mocks.LateProgressRepository), nil, nil, nil, nil, testBatchProgressConfig, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressEventInvalid, result.Results[0].Status)
+}
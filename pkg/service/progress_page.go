@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// MaxProgressPageLimit caps the page size of paginated GetUserChallenges reads.
+const MaxProgressPageLimit = 500
+
+// ProgressPage is one page of a paginated GetUserChallenges read.
+type ProgressPage struct {
+	// GoalIDs are the goals on the page, in goal_id order.
+	GoalIDs []string
+	// Rows are the user's progress rows for GoalIDs.
+	Rows []*domain.UserGoalProgress
+	// NextAfterGoalID is the after_goal_id of the next page, empty on the last page.
+	NextAfterGoalID string
+}
+
+// CheckProgressPage validates the limit of a paginated GetUserChallenges read.
+// Pages cannot be combined with challengeIDs, nor with strong consistency since
+// pages are separate requests that cannot share one snapshot.
+func CheckProgressPage(limit int, challengeIDs []string, strong bool) error {
+	if len(challengeIDs) > 0 {
+		return fmt.Errorf("challenge_ids cannot be combined with limit")
+	}
+
+	if strong {
+		return fmt.Errorf("consistency cannot be combined with limit")
+	}
+
+	if limit <= 0 || limit > MaxProgressPageLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxProgressPageLimit)
+	}
+
+	return nil
+}
+
+// LoadProgressPage loads up to limit goals after afterGoalID in goal_id order,
+// with the user's progress for them. Each page only loads progress for its own goals:
+//   - activeOnly: keyset query over the user's active rows (GetUserProgressPage)
+//   - otherwise: page over configured goal IDs, then load progress by ID
+//
+// An empty afterGoalID starts from the first goal.
+func LoadProgressPage(
+	ctx context.Context,
+	progressQueries serviceRepo.ProgressQueryRepository,
+	repo repository.GoalRepository,
+	goalCache cache.GoalCache,
+	userID string,
+	activeOnly bool,
+	afterGoalID string,
+	limit int,
+) (*ProgressPage, error) {
+	page := &ProgressPage{}
+	hasMore := false
+
+	if activeOnly {
+		// Fetch one extra row to know whether another page exists
+		rows, err := progressQueries.GetUserProgressPage(ctx, userID, true, afterGoalID, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user progress page: %w", err)
+		}
+		hasMore = len(rows) > limit
+		if hasMore {
+			rows = rows[:limit]
+		}
+		page.Rows = rows
+		page.GoalIDs = make([]string, 0, len(rows))
+		for _, row := range rows {
+			page.GoalIDs = append(page.GoalIDs, row.GoalID)
+		}
+	} else {
+		page.GoalIDs, hasMore = configGoalPage(goalCache, afterGoalID, limit)
+		if len(page.GoalIDs) > 0 {
+			rows, err := repo.GetGoalsByIDs(ctx, userID, page.GoalIDs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load user progress page: %w", err)
+			}
+			page.Rows = rows
+		}
+	}
+
+	// The cursor is taken before hidden and excluded claimed goals are dropped,
+	// so a page may hold fewer than limit goals while more follow.
+	if hasMore && len(page.GoalIDs) > 0 {
+		page.NextAfterGoalID = page.GoalIDs[len(page.GoalIDs)-1]
+	}
+	return page, nil
+}
+
+// configGoalPage returns up to limit configured goal IDs after afterGoalID in
+// goal_id order, and whether more goals follow.
+func configGoalPage(goalCache cache.GoalCache, afterGoalID string, limit int) ([]string, bool) {
+	allGoals := goalCache.GetAllGoals()
+	goalIDs := make([]string, 0, len(allGoals))
+	for _, goal := range allGoals {
+		if goal.ID > afterGoalID {
+			goalIDs = append(goalIDs, goal.ID)
+		}
+	}
+	sort.Strings(goalIDs)
+
+	if len(goalIDs) > limit {
+		return goalIDs[:limit], true
+	}
+	return goalIDs, false
+}
+
+// PageChallenges returns the challenges of the page's goals in config order,
+// each a copy limited to its goals on the page, in page order. Goals no longer
+// in the config are left out.
+func PageChallenges(goalCache cache.GoalCache, goalIDs []string) []*domain.Challenge {
+	byChallenge := make(map[string][]*domain.Goal)
+	for _, goalID := range goalIDs {
+		goal := goalCache.GetGoalByID(goalID)
+		if goal == nil {
+			// Progress row for a goal no longer in config
+			continue
+		}
+		byChallenge[goal.ChallengeID] = append(byChallenge[goal.ChallengeID], goal)
+	}
+
+	challenges := make([]*domain.Challenge, 0, len(byChallenge))
+	for _, challenge := range goalCache.GetAllChallenges() {
+		goals, ok := byChallenge[challenge.ID]
+		if !ok {
+			continue
+		}
+		paged := *challenge
+		paged.Goals = goals
+		challenges = append(challenges, &paged)
+	}
+	return challenges
+}
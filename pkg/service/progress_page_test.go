@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newPageGoalCache configures challenge "b" before "a", so config order and
+// goal_id order differ.
+func newPageGoalCache() commonCache.GoalCache {
+	return commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "b", Name: "B", Goals: []*domain.Goal{
+			createTestGoal("b-2", "B2", "b"),
+			createTestGoal("b-1", "B1", "b"),
+		}},
+		{ID: "a", Name: "A", Goals: []*domain.Goal{
+			createTestGoal("a-1", "A1", "a"),
+		}},
+	}}, "", slog.Default())
+}
+
+func TestCheckProgressPage(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        int
+		challengeIDs []string
+		strong       bool
+		wantErr      string
+	}{
+		{"valid", 10, nil, false, ""},
+		{"max", MaxProgressPageLimit, nil, false, ""},
+		{"zero", 0, nil, false, "limit must be between 1 and 500"},
+		{"negative", -1, nil, false, "limit must be between 1 and 500"},
+		{"too large", MaxProgressPageLimit + 1, nil, false, "limit must be between 1 and 500"},
+		{"with challenge_ids", 10, []string{"a"}, false, "challenge_ids cannot be combined with limit"},
+		{"with strong consistency", 10, nil, true, "consistency cannot be combined with limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckProgressPage(tt.limit, tt.challengeIDs, tt.strong)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadProgressPage_ConfigGoals(t *testing.T) {
+	goalCache := newPageGoalCache()
+	mockRepo := new(mocks.GoalRepository)
+	row := createTestProgress("user1", "b-1", "b", 3, domain.GoalStatusInProgress)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"a-1", "b-1"}).Return([]*domain.UserGoalProgress{row}, nil)
+	mockRepo.On("GetGoalsByIDs", mock.Anything, "user1", []string{"b-2"}).Return(nil, nil)
+
+	page, err := LoadProgressPage(context.Background(), nil, mockRepo, goalCache, "user1", false, "", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-1", "b-1"}, page.GoalIDs)
+	assert.Equal(t, []*domain.UserGoalProgress{row}, page.Rows)
+	assert.Equal(t, "b-1", page.NextAfterGoalID)
+
+	page, err = LoadProgressPage(context.Background(), nil, mockRepo, goalCache, "user1", false, page.NextAfterGoalID, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b-2"}, page.GoalIDs)
+	assert.Empty(t, page.NextAfterGoalID, "last page")
+}
+
+func TestLoadProgressPage_ActiveOnly(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	rows := []*domain.UserGoalProgress{
+		createTestProgress("user1", "a-1", "a", 1, domain.GoalStatusInProgress),
+		createTestProgress("user1", "b-2", "b", 1, domain.GoalStatusInProgress),
+	}
+	// One row more than the limit is fetched to detect the next page
+	queries.On("GetUserProgressPage", mock.Anything, "user1", true, "", 2).Return(rows, nil)
+
+	page, err := LoadProgressPage(context.Background(), queries, nil, newPageGoalCache(), "user1", true, "", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a-1"}, page.GoalIDs)
+	assert.Equal(t, rows[:1], page.Rows)
+	assert.Equal(t, "a-1", page.NextAfterGoalID)
+}
+
+func TestLoadProgressPage_Error(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetUserProgressPage", mock.Anything, "user1", true, "", 11).Return(nil, errors.New("db down"))
+
+	_, err := LoadProgressPage(context.Background(), queries, nil, newPageGoalCache(), "user1", true, "", 10)
+
+	assert.ErrorContains(t, err, "db down")
+}
+
+func TestPageChallenges(t *testing.T) {
+	challenges := PageChallenges(newPageGoalCache(), []string{"a-1", "b-1", "b-2", "removed"})
+
+	require.Len(t, challenges, 2)
+	assert.Equal(t, "b", challenges[0].ID, "config order")
+	assert.Equal(t, "B", challenges[0].Name)
+	require.Len(t, challenges[0].Goals, 2)
+	assert.Equal(t, "b-1", challenges[0].Goals[0].ID, "page order")
+	assert.Equal(t, "b-2", challenges[0].Goals[1].ID)
+	assert.Equal(t, "a", challenges[1].ID)
+}
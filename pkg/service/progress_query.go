@@ -16,18 +16,26 @@ type ChallengeWithProgress struct {
 	UserProgress map[string]*domain.UserGoalProgress // Key: goal_id
 }
 
+// narrowProgressQueryMaxGoals is the largest number of goals in a filtered set
+// that is loaded by goal ID. Larger sets load the whole account instead, since
+// a long goal ID list costs more than the indexed per-user scan.
+const narrowProgressQueryMaxGoals = 100
+
 // GetUserChallengesWithProgress retrieves all challenges with user progress.
 // This helper function is used by the GetUserChallenges RPC handler.
 //
 // Steps:
-// 1. Load all challenges from cache (O(1))
-// 2. Load all user progress from repository (single DB query)
+// 1. Load all challenges from cache (O(1)), limited to challengeIDs if any
+// 2. Load user progress from repository (single DB query, see LoadChallengesProgress)
 // 3. Build map of progress for efficient lookup
 // 4. Return combined data
 //
 // Performance: ~10-20ms for 50 challenges with 200 goals
 //
 // M3 Phase 4: activeOnly parameter filters to only is_active = true goals.
+//
+// With challengeIDs the progress map only holds rows for the goals of those
+// challenges, so callers load prerequisite rows from other challenges themselves.
 func GetUserChallengesWithProgress(
 	ctx context.Context,
 	userID string,
@@ -35,6 +43,7 @@ func GetUserChallengesWithProgress(
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
 	activeOnly bool,
+	challengeIDs []string,
 ) ([]*ChallengeWithProgress, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
@@ -53,32 +62,115 @@ func GetUserChallengesWithProgress(
 	}
 
 	// Get all challenges from cache (O(1))
-	challenges := goalCache.GetAllChallenges()
+	challenges := FilterChallenges(goalCache.GetAllChallenges(), challengeIDs)
 	if len(challenges) == 0 {
-		// No challenges configured, return empty result
+		// No challenges configured (or matching the filter), return empty result
 		return []*ChallengeWithProgress{}, nil
 	}
 
-	// Load all user progress from DB (single query)
+	// Load user progress from DB (single query)
 	// M3 Phase 4: Pass activeOnly parameter to filter goals
-	allProgress, err := repo.GetUserProgress(ctx, userID, activeOnly)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load user progress: %w", err)
+	var allProgress []*domain.UserGoalProgress
+	var err error
+	if len(challengeIDs) == 0 {
+		allProgress, err = repo.GetUserProgress(ctx, userID, activeOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user progress: %w", err)
+		}
+	} else {
+		allProgress, err = LoadChallengesProgress(ctx, repo, userID, challenges, activeOnly)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Build map for O(1) progress lookups
-	progressMap := buildProgressMap(allProgress)
+	// Combine challenges with progress (one map for O(1) progress lookups)
+	return ChallengesWithProgress(challenges, allProgress), nil
+}
+
+// FilterChallenges returns the challenges whose IDs are in challengeIDs, keeping
+// config order. Unknown IDs are ignored; an empty challengeIDs returns challenges
+// unchanged.
+func FilterChallenges(challenges []*domain.Challenge, challengeIDs []string) []*domain.Challenge {
+	if len(challengeIDs) == 0 {
+		return challenges
+	}
+
+	wanted := make(map[string]bool, len(challengeIDs))
+	for _, challengeID := range challengeIDs {
+		wanted[challengeID] = true
+	}
+
+	filtered := make([]*domain.Challenge, 0, len(challengeIDs))
+	for _, challenge := range challenges {
+		if wanted[challenge.ID] {
+			filtered = append(filtered, challenge)
+		}
+	}
+	return filtered
+}
+
+// LoadChallengesProgress loads the user's progress rows for the goals of a
+// filtered set of challenges, using the narrowest query for its size:
+//   - a single challenge: GetChallengeProgress (indexed by challenge)
+//   - up to narrowProgressQueryMaxGoals goals: GetGoalsByIDs, dropping inactive
+//     rows when activeOnly
+//   - otherwise: GetUserProgress for the whole account
+//
+// The last case also returns rows of goals outside the set; they are harmless
+// to the response mappers, which look rows up by goal ID.
+func LoadChallengesProgress(
+	ctx context.Context,
+	repo repository.GoalRepository,
+	userID string,
+	challenges []*domain.Challenge,
+	activeOnly bool,
+) ([]*domain.UserGoalProgress, error) {
+	if len(challenges) == 1 {
+		rows, err := repo.GetChallengeProgress(ctx, userID, challenges[0].ID, activeOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load challenge progress: %w", err)
+		}
+		return rows, nil
+	}
+
+	goalCount := 0
+	for _, challenge := range challenges {
+		goalCount += len(challenge.Goals)
+	}
+	if goalCount > narrowProgressQueryMaxGoals {
+		rows, err := repo.GetUserProgress(ctx, userID, activeOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user progress: %w", err)
+		}
+		return rows, nil
+	}
 
-	// Combine challenges with progress
-	result := make([]*ChallengeWithProgress, 0, len(challenges))
+	goalIDs := make([]string, 0, goalCount)
 	for _, challenge := range challenges {
-		result = append(result, &ChallengeWithProgress{
-			Challenge:    challenge,
-			UserProgress: progressMap,
-		})
+		for _, goal := range challenge.Goals {
+			goalIDs = append(goalIDs, goal.ID)
+		}
+	}
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := repo.GetGoalsByIDs(ctx, userID, goalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user progress: %w", err)
+	}
+	if !activeOnly {
+		return rows, nil
 	}
 
-	return result, nil
+	active := make([]*domain.UserGoalProgress, 0, len(rows))
+	for _, row := range rows {
+		if row.IsActive {
+			active = append(active, row)
+		}
+	}
+	return active, nil
 }
 
 // GetUserChallengeWithProgress retrieves a single challenge with user progress.
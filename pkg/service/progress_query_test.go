@@ -3,167 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
-	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"extend-challenge-service/pkg/testutil/mocks"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
-	"github.com/AccelByte/extend-challenge-common/pkg/repository"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockGoalCache is a mock implementation of cache.GoalCache
-type MockGoalCache struct {
-	mock.Mock
-}
-
-// Compile-time interface check
-var _ cache.GoalCache = (*MockGoalCache)(nil)
-
-func (m *MockGoalCache) GetGoalByID(goalID string) *domain.Goal {
-	args := m.Called(goalID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*domain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsByStatCode(statCode string) []*domain.Goal {
-	args := m.Called(statCode)
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) GetChallengeByChallengeID(challengeID string) *domain.Challenge {
-	args := m.Called(challengeID)
-	if args.Get(0) == nil {
-		return nil
-	}
-	return args.Get(0).(*domain.Challenge)
-}
-
-func (m *MockGoalCache) GetAllChallenges() []*domain.Challenge {
-	args := m.Called()
-	return args.Get(0).([]*domain.Challenge)
-}
-
-func (m *MockGoalCache) GetAllGoals() []*domain.Goal {
-	args := m.Called()
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) GetGoalsWithDefaultAssigned() []*domain.Goal {
-	args := m.Called()
-	return args.Get(0).([]*domain.Goal)
-}
-
-func (m *MockGoalCache) Reload() error {
-	args := m.Called()
-	return args.Error(0)
-}
-
-// MockGoalRepository is a mock implementation of repository.GoalRepository
-type MockGoalRepository struct {
-	mock.Mock
-}
-
-// Compile-time interface check
-var _ repository.GoalRepository = (*MockGoalRepository)(nil)
-
-func (m *MockGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, activeOnly)
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-// M3 Phase 4: Added activeOnly parameter
-func (m *MockGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, challengeID, activeOnly)
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) UpsertProgress(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, updates)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []repository.CopyRow) error {
-	args := m.Called(ctx, rows)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
-	args := m.Called(ctx, userID, goalID)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BeginTx(ctx context.Context) (repository.TxRepository, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(repository.TxRepository), args.Error(1)
-}
-
-// M3: Goal assignment control methods
-func (m *MockGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID, goalIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
-func (m *MockGoalRepository) BulkInsert(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-func (m *MockGoalRepository) UpsertGoalActive(ctx context.Context, progress *domain.UserGoalProgress) error {
-	args := m.Called(ctx, progress)
-	return args.Error(0)
-}
-
-// M4: Batch goal activation
-func (m *MockGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*domain.UserGoalProgress) error {
-	args := m.Called(ctx, progresses)
-	return args.Error(0)
-}
-
-// M3 Phase 9: Fast path optimization methods
-func (m *MockGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*domain.UserGoalProgress, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.UserGoalProgress), args.Error(1)
-}
-
 // Test Fixtures
 func createTestChallenge(id, name string, goalCount int) *domain.Challenge {
 	goals := make([]*domain.Goal, 0, goalCount)
@@ -229,8 +80,8 @@ func TestGetUserChallengesWithProgress_Success(t *testing.T) {
 	challenge1 := createTestChallenge("challenge-1", "Challenge 1", 2)
 	challenge2 := createTestChallenge("challenge-2", "Challenge 2", 3)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1, challenge2})
 
@@ -240,7 +91,7 @@ func TestGetUserChallengesWithProgress_Success(t *testing.T) {
 	}
 	mockRepo.On("GetUserProgress", ctx, userID, false).Return(userProgress, nil)
 
-	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false)
+	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false, nil)
 
 	require.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -258,12 +109,12 @@ func TestGetUserChallengesWithProgress_NoChallenges(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{})
 
-	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false)
+	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false, nil)
 
 	require.NoError(t, err)
 	assert.Empty(t, result)
@@ -279,13 +130,13 @@ func TestGetUserChallengesWithProgress_NoUserProgress(t *testing.T) {
 
 	challenge1 := createTestChallenge("challenge-1", "Challenge 1", 2)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1})
 	mockRepo.On("GetUserProgress", ctx, userID, false).Return([]*domain.UserGoalProgress{}, nil)
 
-	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false)
+	result, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false, nil)
 
 	require.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -299,10 +150,10 @@ func TestGetUserChallengesWithProgress_EmptyUserID(t *testing.T) {
 	ctx := context.Background()
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	_, err := GetUserChallengesWithProgress(ctx, "", namespace, mockCache, mockRepo, false)
+	_, err := GetUserChallengesWithProgress(ctx, "", namespace, mockCache, mockRepo, false, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "user ID cannot be empty")
@@ -312,10 +163,10 @@ func TestGetUserChallengesWithProgress_EmptyNamespace(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
-	_, err := GetUserChallengesWithProgress(ctx, userID, "", mockCache, mockRepo, false)
+	_, err := GetUserChallengesWithProgress(ctx, userID, "", mockCache, mockRepo, false, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "namespace cannot be empty")
@@ -326,9 +177,9 @@ func TestGetUserChallengesWithProgress_NilCache(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
-	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, nil, mockRepo, false)
+	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, nil, mockRepo, false, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "goal cache cannot be nil")
@@ -339,9 +190,9 @@ func TestGetUserChallengesWithProgress_NilRepository(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 
-	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, nil, false)
+	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, nil, false, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "repository cannot be nil")
@@ -354,13 +205,13 @@ func TestGetUserChallengesWithProgress_RepositoryError(t *testing.T) {
 
 	challenge1 := createTestChallenge("challenge-1", "Challenge 1", 2)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1})
 	mockRepo.On("GetUserProgress", ctx, userID, false).Return([]*domain.UserGoalProgress{}, errors.New("database error"))
 
-	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false)
+	_, err := GetUserChallengesWithProgress(ctx, userID, namespace, mockCache, mockRepo, false, nil)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to load user progress")
@@ -369,6 +220,102 @@ func TestGetUserChallengesWithProgress_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetUserChallengesWithProgress_SingleChallengeFilter(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+
+	challenge1 := createTestChallenge("challenge-1", "Challenge 1", 2)
+	challenge2 := createTestChallenge("challenge-2", "Challenge 2", 3)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1, challenge2})
+	mockRepo.On("GetChallengeProgress", ctx, userID, "challenge-2", true).Return([]*domain.UserGoalProgress{
+		createTestProgress(userID, "challenge-2-goal-1", "challenge-2", 3, domain.GoalStatusInProgress),
+	}, nil)
+
+	result, err := GetUserChallengesWithProgress(ctx, userID, "test-namespace", mockCache, mockRepo, true, []string{"challenge-2"})
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "challenge-2", result[0].Challenge.ID)
+	assert.Equal(t, 3, result[0].UserProgress["challenge-2-goal-1"].Progress)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetUserProgress", ctx, userID, true)
+}
+
+func TestGetUserChallengesWithProgress_SmallFilterLoadsByGoalID(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+
+	challenge1 := createTestChallenge("challenge-1", "Challenge 1", 1)
+	challenge2 := createTestChallenge("challenge-2", "Challenge 2", 2)
+	challenge3 := createTestChallenge("challenge-3", "Challenge 3", 1)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	active := createTestProgress(userID, "challenge-1-goal-0", "challenge-1", 5, domain.GoalStatusInProgress)
+	active.IsActive = true
+	inactive := createTestProgress(userID, "challenge-3-goal-0", "challenge-3", 1, domain.GoalStatusInProgress)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{challenge1, challenge2, challenge3})
+	mockRepo.On("GetGoalsByIDs", ctx, userID, []string{"challenge-1-goal-0", "challenge-3-goal-0"}).
+		Return([]*domain.UserGoalProgress{active, inactive}, nil)
+
+	result, err := GetUserChallengesWithProgress(ctx, userID, "test-namespace", mockCache, mockRepo, true, []string{"challenge-3", "unknown", "challenge-1"})
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "challenge-1", result[0].Challenge.ID, "config order is kept")
+	assert.Equal(t, "challenge-3", result[1].Challenge.ID)
+	assert.Len(t, result[0].UserProgress, 1, "inactive rows are dropped with active_only")
+	assert.Contains(t, result[0].UserProgress, "challenge-1-goal-0")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetUserChallengesWithProgress_LargeFilterLoadsWholeAccount(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+
+	bigGoals := make([]*domain.Goal, 0, narrowProgressQueryMaxGoals)
+	for i := 0; i < narrowProgressQueryMaxGoals; i++ {
+		bigGoals = append(bigGoals, createTestGoal(fmt.Sprintf("big-goal-%d", i), "Goal", "big"))
+	}
+	big := &domain.Challenge{ID: "big", Name: "Big", Goals: bigGoals}
+	small := createTestChallenge("small", "Small", 1)
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{big, small})
+	mockRepo.On("GetUserProgress", ctx, userID, false).Return([]*domain.UserGoalProgress{}, nil)
+
+	result, err := GetUserChallengesWithProgress(ctx, userID, "test-namespace", mockCache, mockRepo, false, []string{"big", "small"})
+
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetUserChallengesWithProgress_FilterMatchesNothing(t *testing.T) {
+	ctx := context.Background()
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{createTestChallenge("challenge-1", "Challenge 1", 1)})
+
+	result, err := GetUserChallengesWithProgress(ctx, "user123", "test-namespace", mockCache, mockRepo, false, []string{"unknown"})
+
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	mockRepo.AssertNotCalled(t, "GetUserProgress", ctx, "user123", false)
+}
+
 // Test GetUserChallengeWithProgress
 
 func TestGetUserChallengeWithProgress_Success(t *testing.T) {
@@ -379,8 +326,8 @@ func TestGetUserChallengeWithProgress_Success(t *testing.T) {
 
 	challenge := createTestChallenge(challengeID, "Challenge 1", 3)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 
@@ -408,8 +355,8 @@ func TestGetUserChallengeWithProgress_ChallengeNotFound(t *testing.T) {
 	challengeID := "nonexistent"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(nil)
 
@@ -430,8 +377,8 @@ func TestGetUserChallengeWithProgress_NoProgress(t *testing.T) {
 
 	challenge := createTestChallenge(challengeID, "Challenge 1", 2)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return([]*domain.UserGoalProgress{}, nil)
@@ -451,8 +398,8 @@ func TestGetUserChallengeWithProgress_EmptyUserID(t *testing.T) {
 	challengeID := "challenge-1"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	_, err := GetUserChallengeWithProgress(ctx, "", challengeID, namespace, mockCache, mockRepo, false)
 
@@ -465,8 +412,8 @@ func TestGetUserChallengeWithProgress_EmptyChallengeID(t *testing.T) {
 	userID := "user123"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	_, err := GetUserChallengeWithProgress(ctx, userID, "", namespace, mockCache, mockRepo, false)
 
@@ -479,8 +426,8 @@ func TestGetUserChallengeWithProgress_EmptyNamespace(t *testing.T) {
 	userID := "user123"
 	challengeID := "challenge-1"
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	_, err := GetUserChallengeWithProgress(ctx, userID, challengeID, "", mockCache, mockRepo, false)
 
@@ -494,7 +441,7 @@ func TestGetUserChallengeWithProgress_NilCache(t *testing.T) {
 	challengeID := "challenge-1"
 	namespace := "test-namespace"
 
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
 	_, err := GetUserChallengeWithProgress(ctx, userID, challengeID, namespace, nil, mockRepo, false)
 
@@ -508,7 +455,7 @@ func TestGetUserChallengeWithProgress_NilRepository(t *testing.T) {
 	challengeID := "challenge-1"
 	namespace := "test-namespace"
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 
 	_, err := GetUserChallengeWithProgress(ctx, userID, challengeID, namespace, mockCache, nil, false)
 
@@ -524,8 +471,8 @@ func TestGetUserChallengeWithProgress_RepositoryError(t *testing.T) {
 
 	challenge := createTestChallenge(challengeID, "Challenge 1", 2)
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetChallengeByChallengeID", challengeID).Return(challenge)
 	mockRepo.On("GetChallengeProgress", ctx, userID, challengeID, false).Return([]*domain.UserGoalProgress{}, errors.New("database error"))
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// ConsistencyStrong is the consistency option of the challenge list endpoints
+// that reads the user's progress from one database snapshot and reports when it
+// was taken. Without it progress is read as before, one query at a time.
+const ConsistencyStrong = "strong"
+
+// ParseConsistency reports whether a consistency option asks for a snapshot
+// read. "" keeps the default reads; other values are rejected.
+func ParseConsistency(consistency string) (bool, error) {
+	switch consistency {
+	case "":
+		return false, nil
+	case ConsistencyStrong:
+		return true, nil
+	default:
+		return false, fmt.Errorf("consistency must be empty or %q, got %q", ConsistencyStrong, consistency)
+	}
+}
+
+// LoadProgressSnapshot reads the user's progress rows, or one challenge's rows
+// when challengeID is set, from one REPEATABLE READ snapshot.
+//
+// The returned time is when the snapshot was taken, rounded up to the second
+// like every API timestamp, so a client passing it back as
+// ClaimPrecondition.SnapshotAt never has a row the snapshot held rejected as
+// stale.
+func LoadProgressSnapshot(
+	ctx context.Context,
+	queries serviceRepo.ProgressQueryRepository,
+	userID string,
+	challengeID string,
+	activeOnly bool,
+) ([]*domain.UserGoalProgress, time.Time, error) {
+	if queries == nil {
+		return nil, time.Time{}, fmt.Errorf("progress query repository cannot be nil")
+	}
+
+	snapshot, err := queries.GetUserProgressSnapshot(ctx, userID, challengeID, activeOnly)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to load user progress snapshot: %w", err)
+	}
+
+	takenAt := snapshot.TakenAt.UTC()
+	if truncated := takenAt.Truncate(time.Second); !truncated.Equal(takenAt) {
+		takenAt = truncated.Add(time.Second)
+	}
+	return snapshot.Rows, takenAt, nil
+}
+
+// ChallengesWithProgress pairs each challenge with the user's progress rows.
+// Every entry shares one progress map, as in GetUserChallengesWithProgress.
+func ChallengesWithProgress(challenges []*domain.Challenge, rows []*domain.UserGoalProgress) []*ChallengeWithProgress {
+	progressMap := buildProgressMap(rows)
+
+	result := make([]*ChallengeWithProgress, 0, len(challenges))
+	for _, challenge := range challenges {
+		result = append(result, &ChallengeWithProgress{
+			Challenge:    challenge,
+			UserProgress: progressMap,
+		})
+	}
+	return result
+}
+
+// ClaimPrecondition is what the player saw when they claimed. A claim whose
+// goal has changed since then fails with mapper.StaleClaimError instead of
+// whatever the changed row would cause. The zero value checks nothing.
+//
+// Goals already claimed keep failing with GoalAlreadyClaimedError, so a repeated
+// claim still reads as one.
+type ClaimPrecondition struct {
+	// ExpectedStatus is the goal status the player saw, e.g. "completed".
+	ExpectedStatus string
+	// SnapshotAt is the snapshot the player saw (see LoadProgressSnapshot). The
+	// claim fails if the goal's progress was written after it.
+	SnapshotAt *time.Time
+}
+
+// Validate rejects an unknown expected status.
+func (p ClaimPrecondition) Validate() error {
+	if p.ExpectedStatus != "" && !domain.GoalStatus(p.ExpectedStatus).IsValid() {
+		return fmt.Errorf("invalid expected_status: %q", p.ExpectedStatus)
+	}
+	return nil
+}
+
+// check compares the precondition with the goal's row as the player's segment
+// sees it; a nil row is a goal not started.
+func (p ClaimPrecondition) check(goalID string, progress *domain.UserGoalProgress) error {
+	if progress != nil && progress.IsClaimed() {
+		return nil
+	}
+
+	status := string(domain.GoalStatusNotStarted)
+	if progress != nil {
+		status = string(progress.Status)
+	}
+	changed := progress != nil && p.SnapshotAt != nil && progress.UpdatedAt.After(*p.SnapshotAt)
+	if (p.ExpectedStatus == "" || p.ExpectedStatus == status) && !changed {
+		return nil
+	}
+
+	stale := &mapper.StaleClaimError{
+		GoalID:         goalID,
+		ExpectedStatus: p.ExpectedStatus,
+		Status:         status,
+	}
+	if progress != nil {
+		stale.UpdatedAt = mapper.FormatTimestamp(progress.UpdatedAt)
+	}
+	return stale
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConsistency(t *testing.T) {
+	strong, err := ParseConsistency("")
+	require.NoError(t, err)
+	assert.False(t, strong)
+
+	strong, err = ParseConsistency("strong")
+	require.NoError(t, err)
+	assert.True(t, strong)
+
+	_, err = ParseConsistency("STRONG")
+	assert.Error(t, err)
+}
+
+func TestLoadProgressSnapshot_RoundsUpToTheSecond(t *testing.T) {
+	rows := []*domain.UserGoalProgress{createCompletedProgress("user", "g1", "c1")}
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetUserProgressSnapshot", mock.Anything, "user", "c1", true).Return(&serviceRepo.ProgressSnapshot{
+		Rows:    rows,
+		TakenAt: time.Date(2025, 6, 1, 12, 0, 0, 1, time.UTC),
+	}, nil).Once()
+	queries.On("GetUserProgressSnapshot", mock.Anything, "user", "", false).Return(&serviceRepo.ProgressSnapshot{
+		TakenAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}, nil).Once()
+
+	got, takenAt, err := LoadProgressSnapshot(context.Background(), queries, "user", "c1", true)
+	require.NoError(t, err)
+	assert.Equal(t, rows, got)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 1, 0, time.UTC), takenAt)
+
+	_, takenAt, err = LoadProgressSnapshot(context.Background(), queries, "user", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), takenAt, "whole seconds are kept")
+}
+
+func TestLoadProgressSnapshot_Error(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("GetUserProgressSnapshot", mock.Anything, "user", "", false).Return(nil, errors.New("connection refused"))
+
+	_, _, err := LoadProgressSnapshot(context.Background(), queries, "user", "", false)
+
+	assert.Error(t, err)
+}
+
+func TestClaimPrecondition_Validate(t *testing.T) {
+	assert.NoError(t, ClaimPrecondition{}.Validate())
+	assert.NoError(t, ClaimPrecondition{ExpectedStatus: "completed"}.Validate())
+	assert.Error(t, ClaimPrecondition{ExpectedStatus: "done"}.Validate())
+}
+
+func TestClaimPrecondition_Check(t *testing.T) {
+	snapshotAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	row := createCompletedProgress("user", "g1", "c1")
+	row.UpdatedAt = snapshotAt.Add(-time.Minute)
+	changed := createCompletedProgress("user", "g1", "c1")
+	changed.UpdatedAt = snapshotAt.Add(time.Millisecond)
+	inProgress := createCompletedProgress("user", "g1", "c1")
+	inProgress.Status = domain.GoalStatusInProgress
+	claimed := createCompletedProgress("user", "g1", "c1")
+	claimed.Status = domain.GoalStatusClaimed
+	claimed.UpdatedAt = snapshotAt.Add(time.Minute)
+
+	tests := []struct {
+		name         string
+		precondition ClaimPrecondition
+		row          *domain.UserGoalProgress
+		stale        bool
+	}{
+		{name: "no precondition", row: inProgress},
+		{name: "status as seen", precondition: ClaimPrecondition{ExpectedStatus: "completed"}, row: row},
+		{name: "status changed", precondition: ClaimPrecondition{ExpectedStatus: "completed"}, row: inProgress, stale: true},
+		{name: "missing row", precondition: ClaimPrecondition{ExpectedStatus: "completed"}, stale: true},
+		{name: "unchanged since snapshot", precondition: ClaimPrecondition{SnapshotAt: &snapshotAt}, row: row},
+		{name: "written after snapshot", precondition: ClaimPrecondition{SnapshotAt: &snapshotAt}, row: changed, stale: true},
+		{name: "claimed rows are left to the claim", precondition: ClaimPrecondition{ExpectedStatus: "completed", SnapshotAt: &snapshotAt}, row: claimed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.precondition.check("g1", tt.row)
+
+			if !tt.stale {
+				assert.NoError(t, err)
+				return
+			}
+			var stale *mapper.StaleClaimError
+			require.ErrorAs(t, err, &stale)
+			assert.Equal(t, "g1", stale.GoalID)
+		})
+	}
+}
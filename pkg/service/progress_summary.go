@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// ProgressCounts holds goal counts for a progress summary.
+type ProgressCounts struct {
+	TotalGoals int // Configured goals (or active goals when activeOnly)
+	Completed  int // Completed goals, including claimed ones
+	Claimed    int
+	InProgress int
+}
+
+// ChallengeProgressSummary is the progress rollup for one challenge.
+type ChallengeProgressSummary struct {
+	ChallengeID string
+	Name        string
+	// NextResetAt is the challenge's NextChallengeReset, nil when none of its goals rotates
+	NextResetAt *time.Time
+	ProgressCounts
+}
+
+// ProgressSummary is the account-wide progress rollup for a user.
+type ProgressSummary struct {
+	Totals     ProgressCounts
+	Challenges []*ChallengeProgressSummary // Config order
+}
+
+// GetUserProgressSummary counts a user's goals by status across all challenges.
+// This helper function is used by the GetProgressSummary RPC handler.
+//
+// Steps:
+// 1. Load per-challenge status counts from repository (single aggregated query)
+// 2. Roll counts up per configured challenge and in total
+//
+// Rows for challenges no longer in the config are ignored.
+// When activeOnly is true, only is_active = true goals are counted and TotalGoals is
+// the number of active goals; otherwise TotalGoals is the configured goal count.
+// Each challenge's NextResetAt is taken at now.
+func GetUserProgressSummary(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	queries repository.ProgressQueryRepository,
+	activeOnly bool,
+	now time.Time,
+) (*ProgressSummary, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if queries == nil {
+		return nil, fmt.Errorf("progress query repository cannot be nil")
+	}
+
+	counts, err := queries.GetUserProgressSummary(ctx, userID, namespace, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user progress summary: %w", err)
+	}
+
+	byChallenge := make(map[string]*ProgressCounts)
+	for _, c := range counts {
+		challengeCounts, ok := byChallenge[c.ChallengeID]
+		if !ok {
+			challengeCounts = &ProgressCounts{}
+			byChallenge[c.ChallengeID] = challengeCounts
+		}
+		challengeCounts.add(c.Status, c.Count, activeOnly)
+	}
+
+	return rollUpProgressSummary(goalCache, byChallenge, activeOnly, now), nil
+}
+
+// rollUpProgressSummary builds the summary of the configured challenges from
+// the counts of each challenge.
+func rollUpProgressSummary(
+	goalCache cache.GoalCache,
+	byChallenge map[string]*ProgressCounts,
+	activeOnly bool,
+	now time.Time,
+) *ProgressSummary {
+	challenges := goalCache.GetAllChallenges()
+	summary := &ProgressSummary{
+		Challenges: make([]*ChallengeProgressSummary, 0, len(challenges)),
+	}
+	for _, challenge := range challenges {
+		challengeSummary := &ChallengeProgressSummary{
+			ChallengeID: challenge.ID,
+			Name:        challenge.Name,
+			NextResetAt: NextChallengeReset(challenge, now),
+		}
+		if challengeCounts, ok := byChallenge[challenge.ID]; ok {
+			challengeSummary.ProgressCounts = *challengeCounts
+		}
+		if !activeOnly {
+			challengeSummary.TotalGoals = len(challenge.Goals)
+		}
+
+		summary.Totals.TotalGoals += challengeSummary.TotalGoals
+		summary.Totals.Completed += challengeSummary.Completed
+		summary.Totals.Claimed += challengeSummary.Claimed
+		summary.Totals.InProgress += challengeSummary.InProgress
+		summary.Challenges = append(summary.Challenges, challengeSummary)
+	}
+
+	return summary
+}
+
+// add accumulates count goals with the given status.
+func (c *ProgressCounts) add(status domain.GoalStatus, count int, countTotal bool) {
+	if countTotal {
+		c.TotalGoals += count
+	}
+
+	switch status {
+	case domain.GoalStatusClaimed:
+		c.Claimed += count
+		c.Completed += count
+	case domain.GoalStatusCompleted:
+		c.Completed += count
+	case domain.GoalStatusInProgress:
+		c.InProgress += count
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserProgressSummary_AllGoals(t *testing.T) {
+	ctx := context.Background()
+
+	mockCache := new(mocks.GoalCache)
+	mockQueries := new(mocks.ProgressQueryRepository)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		createTestChallenge("challenge-1", "Challenge 1", 4),
+		createTestChallenge("challenge-2", "Challenge 2", 3),
+	})
+	mockQueries.On("GetUserProgressSummary", ctx, "user123", "test-namespace", false).Return([]*repository.ProgressStatusCount{
+		{ChallengeID: "challenge-1", Status: domain.GoalStatusClaimed, Count: 1},
+		{ChallengeID: "challenge-1", Status: domain.GoalStatusCompleted, Count: 2},
+		{ChallengeID: "challenge-1", Status: domain.GoalStatusNotStarted, Count: 1},
+		{ChallengeID: "challenge-2", Status: domain.GoalStatusInProgress, Count: 1},
+		{ChallengeID: "removed-challenge", Status: domain.GoalStatusCompleted, Count: 9},
+	}, nil)
+
+	summary, err := GetUserProgressSummary(ctx, "user123", "test-namespace", mockCache, mockQueries, false, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressCounts{TotalGoals: 7, Completed: 3, Claimed: 1, InProgress: 1}, summary.Totals)
+	require.Len(t, summary.Challenges, 2)
+	assert.Equal(t, "challenge-1", summary.Challenges[0].ChallengeID)
+	assert.Equal(t, ProgressCounts{TotalGoals: 4, Completed: 3, Claimed: 1}, summary.Challenges[0].ProgressCounts)
+	assert.Equal(t, ProgressCounts{TotalGoals: 3, InProgress: 1}, summary.Challenges[1].ProgressCounts)
+
+	mockCache.AssertExpectations(t)
+	mockQueries.AssertExpectations(t)
+}
+
+func TestGetUserProgressSummary_ActiveOnlyCountsActiveGoals(t *testing.T) {
+	ctx := context.Background()
+
+	mockCache := new(mocks.GoalCache)
+	mockQueries := new(mocks.ProgressQueryRepository)
+
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		createTestChallenge("challenge-1", "Challenge 1", 5),
+		createTestChallenge("challenge-2", "Challenge 2", 3),
+	})
+	mockQueries.On("GetUserProgressSummary", ctx, "user123", "test-namespace", true).Return([]*repository.ProgressStatusCount{
+		{ChallengeID: "challenge-1", Status: domain.GoalStatusCompleted, Count: 1},
+		{ChallengeID: "challenge-1", Status: domain.GoalStatusNotStarted, Count: 2},
+	}, nil)
+
+	summary, err := GetUserProgressSummary(ctx, "user123", "test-namespace", mockCache, mockQueries, true, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, ProgressCounts{TotalGoals: 3, Completed: 1}, summary.Totals)
+	assert.Equal(t, 0, summary.Challenges[1].TotalGoals)
+}
+
+func TestGetUserProgressSummary_NextReset(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	mockCache := new(mocks.GoalCache)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	mockCache.On("GetAllChallenges").Return([]*domain.Challenge{
+		{ID: "daily", Goals: []*domain.Goal{rotatingGoal("d", domain.RotationScheduleDaily)}},
+		createTestChallenge("season", "Season", 1),
+	})
+	mockQueries.On("GetUserProgressSummary", ctx, "user123", "test-namespace", false).Return(nil, nil)
+
+	summary, err := GetUserProgressSummary(ctx, "user123", "test-namespace", mockCache, mockQueries, false, now)
+
+	require.NoError(t, err)
+	require.Len(t, summary.Challenges, 2)
+	assert.Equal(t, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), *summary.Challenges[0].NextResetAt)
+	assert.Nil(t, summary.Challenges[1].NextResetAt, "no goal rotates")
+}
+
+func TestGetUserProgressSummary_RepositoryError(t *testing.T) {
+	ctx := context.Background()
+
+	mockCache := new(mocks.GoalCache)
+	mockQueries := new(mocks.ProgressQueryRepository)
+	mockQueries.On("GetUserProgressSummary", ctx, "user123", "test-namespace", false).
+		Return(nil, errors.New("database error"))
+
+	summary, err := GetUserProgressSummary(ctx, "user123", "test-namespace", mockCache, mockQueries, false, time.Now())
+
+	assert.Error(t, err)
+	assert.Nil(t, summary)
+	assert.Contains(t, err.Error(), "failed to load user progress summary")
+	mockCache.AssertNotCalled(t, "GetAllChallenges")
+}
+
+func TestGetUserProgressSummary_InvalidInput(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockQueries := new(mocks.ProgressQueryRepository)
+
+	_, err := GetUserProgressSummary(ctx, "", "test-namespace", mockCache, mockQueries, false, time.Now())
+	assert.EqualError(t, err, "user ID cannot be empty")
+
+	_, err = GetUserProgressSummary(ctx, "user123", "", mockCache, mockQueries, false, time.Now())
+	assert.EqualError(t, err, "namespace cannot be empty")
+
+	_, err = GetUserProgressSummary(ctx, "user123", "test-namespace", nil, mockQueries, false, time.Now())
+	assert.EqualError(t, err, "goal cache cannot be nil")
+
+	_, err = GetUserProgressSummary(ctx, "user123", "test-namespace", mockCache, nil, false, time.Now())
+	assert.EqualError(t, err, "progress query repository cannot be nil")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// The query budgets hold whatever the number of goals; the tests use enough
+// goals for a query per goal to exceed them.
+const budgetGoals = 20
+
+func TestInitializePlayer_FirstLoginQueryBudget(t *testing.T) {
+	var defaultGoals []*domain.Goal
+	mockCache := new(mocks.GoalCache)
+	for i := range budgetGoals {
+		goal := createClaimableGoal(fmt.Sprintf("goal-%d", i), "challenge-1")
+		goal.DefaultAssigned = true
+		defaultGoals = append(defaultGoals, goal)
+		mockCache.On("GetGoalByID", goal.ID).Return(goal).Maybe()
+	}
+	mockCache.On("GetGoalsWithDefaultAssigned").Return(defaultGoals)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", mock.Anything, "user123").Return(0, nil)
+	mockRepo.On("BulkInsert", mock.Anything, mock.Anything).Return(nil)
+
+	repo, ctx, queries := testutil.CountQueries(context.Background(), mockRepo)
+	_, err := InitializePlayer(ctx, "user123", "test-namespace", mockCache, repo, nil, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	testutil.AssertMaxQueries(t, queries, testutil.MaxFirstLoginQueries, "InitializePlayer (first login)")
+}
+
+func TestClaimGoalReward_QueryBudget(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", "goal-1").Return(goal)
+	mockTxRepo := new(mocks.TxRepository)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", "goal-1").
+		Return(createCompletedProgress("user123", "goal-1", "challenge-1"), nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", "goal-1").Return(nil)
+	mockTxRepo.On("Commit").Return(nil)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockRewardClient := new(mocks.RewardClient)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil)
+
+	outbox := serviceRepo.NewInstrumentedClaimOutboxRepository(newClaimOutbox(), serviceRepo.NewQueryMetrics(0))
+
+	repo, ctx, queries := testutil.CountQueries(context.Background(), mockRepo)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, repo, outbox,
+		0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	testutil.AssertMaxQueries(t, queries, testutil.MaxClaimQueries, "ClaimGoalReward")
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RepeatableGoal is a goal that can be claimed again and again, e.g. "win a
+// match" once a day, without resetting its challenge.
+//
+// A claim resets the goal's row to not_started and inactive, counting the claim
+// (see serviceRepo.RepeatableGoalRepository). For Cooldown after the claim the
+// goal cannot be activated or selected, so it makes no progress and cannot be
+// claimed; then the player activates it again.
+type RepeatableGoal struct {
+	Cooldown time.Duration
+}
+
+// RepeatableGoals maps goal IDs to their repeat settings. A nil RepeatableGoals
+// has no repeatable goal.
+//
+// domain.Goal (extend-challenge-common) has no such fields, so they are read
+// from the config file separately by LoadRepeatableGoals.
+type RepeatableGoals map[string]RepeatableGoal
+
+// GoalRepeat is a user's claim history of a repeatable goal.
+type GoalRepeat struct {
+	TimesClaimed int
+	// AvailableAt is when the cooldown of the last claim ends; zero if the goal
+	// was never claimed.
+	AvailableAt time.Time
+	// FirstCompletedAt is when the user first completed the goal, before the
+	// claims that reset its row; nil if the goal was never claimed.
+	FirstCompletedAt *time.Time
+}
+
+// CoolingDown reports whether the goal cannot be activated, selected or
+// claimed at now. The cooldown is over at AvailableAt exactly.
+func (r GoalRepeat) CoolingDown(now time.Time) bool {
+	return now.Before(r.AvailableAt)
+}
+
+// repeatableGoalsConfig is the subset of challenges.json needed to read the
+// repeatable goals.
+type repeatableGoalsConfig struct {
+	Challenges []struct {
+		ID                    string `json:"challengeId"`
+		TrackInactiveProgress *bool  `json:"trackInactiveProgress"`
+		Goals                 []struct {
+			ID            string          `json:"goalId"`
+			Repeatable    bool            `json:"repeatable"`
+			CooldownHours *int            `json:"cooldownHours"`
+			RewardCap     json.RawMessage `json:"rewardCap"`
+			Rotation      *struct {
+				Enabled bool `json:"enabled"`
+			} `json:"rotation"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadRepeatableGoals reads the repeatable goals from the challenge config file.
+func LoadRepeatableGoals(configPath string) (RepeatableGoals, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseRepeatableGoals(data)
+}
+
+// ParseRepeatableGoals extracts the goals with "repeatable": true and their
+// "cooldownHours" (default 0) from challenge config JSON.
+//
+// A repeatable goal cannot rotate or have a reward cap, which would both count
+// its repeated claims as the claims of other players. Its challenge must not
+// track inactive progress, since the goal is inactive while it cools down.
+func ParseRepeatableGoals(data []byte) (RepeatableGoals, error) {
+	var cfg repeatableGoalsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	goals := make(RepeatableGoals)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			if !goal.Repeatable {
+				if goal.CooldownHours != nil {
+					return nil, fmt.Errorf("goal %s: cooldownHours requires repeatable", goal.ID)
+				}
+				continue
+			}
+
+			switch {
+			case goal.CooldownHours != nil && *goal.CooldownHours < 0:
+				return nil, fmt.Errorf("goal %s: cooldownHours must not be negative, got %d", goal.ID, *goal.CooldownHours)
+			case goal.Rotation != nil && goal.Rotation.Enabled:
+				return nil, fmt.Errorf("goal %s: repeatable cannot be used with rotation", goal.ID)
+			case len(goal.RewardCap) > 0 && string(goal.RewardCap) != "null":
+				return nil, fmt.Errorf("goal %s: repeatable cannot be used with rewardCap", goal.ID)
+			case challenge.TrackInactiveProgress == nil || *challenge.TrackInactiveProgress:
+				return nil, fmt.Errorf("goal %s: repeatable requires \"trackInactiveProgress\": false on challenge %s", goal.ID, challenge.ID)
+			}
+
+			var repeatable RepeatableGoal
+			if goal.CooldownHours != nil {
+				repeatable.Cooldown = time.Duration(*goal.CooldownHours) * time.Hour
+			}
+			goals[goal.ID] = repeatable
+		}
+	}
+
+	return goals, nil
+}
+
+// Goal returns the repeat settings of goalID, if it is repeatable.
+func (g RepeatableGoals) Goal(goalID string) (RepeatableGoal, bool) {
+	goal, ok := g[goalID]
+	return goal, ok
+}
+
+// repeat returns the claim history of the goal from its stored state.
+func (g RepeatableGoal) repeat(state serviceRepo.RepeatState) GoalRepeat {
+	return GoalRepeat{
+		TimesClaimed:     state.TimesClaimed,
+		AvailableAt:      state.LastClaimedAt.Add(g.Cooldown),
+		FirstCompletedAt: state.FirstCompletedAt,
+	}
+}
+
+type repeatableGoalsKey struct{}
+
+// repeatableGoalsValue is what WithRepeatableGoals stores in a context.
+type repeatableGoalsValue struct {
+	goals RepeatableGoals
+	repo  serviceRepo.RepeatableGoalRepository
+}
+
+// WithRepeatableGoals returns a context whose claims reset repeatable goals and
+// whose claims, activations and selections respect their cooldown, reading and
+// writing their rows with repo. Empty goals or a nil repo leave ctx unchanged.
+func WithRepeatableGoals(ctx context.Context, goals RepeatableGoals, repo serviceRepo.RepeatableGoalRepository) context.Context {
+	if len(goals) == 0 || repo == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, repeatableGoalsKey{}, repeatableGoalsValue{goals: goals, repo: repo})
+}
+
+// repeatableGoalsFrom returns the repeatable goals set by WithRepeatableGoals.
+func repeatableGoalsFrom(ctx context.Context) (repeatableGoalsValue, bool) {
+	value, ok := ctx.Value(repeatableGoalsKey{}).(repeatableGoalsValue)
+	return value, ok
+}
+
+// repeatableGoalFrom returns the repeat settings of goalID set by
+// WithRepeatableGoals and the repository of its rows; false when the goal is
+// not repeatable.
+func repeatableGoalFrom(ctx context.Context, goalID string) (RepeatableGoal, serviceRepo.RepeatableGoalRepository, bool) {
+	value, ok := repeatableGoalsFrom(ctx)
+	if !ok {
+		return RepeatableGoal{}, nil, false
+	}
+	goal, ok := value.goals.Goal(goalID)
+	if !ok {
+		return RepeatableGoal{}, nil, false
+	}
+	return goal, value.repo, true
+}
+
+// resetClaimedRepeatable resets the user's claimed row of a repeatable goal.
+// It returns nil when the row is not claimed.
+func resetClaimedRepeatable(
+	ctx context.Context,
+	repo serviceRepo.RepeatableGoalRepository,
+	goal RepeatableGoal,
+	userID, goalID string,
+) (*GoalRepeat, error) {
+	if err := progressstate.Transition(domain.GoalStatusClaimed, domain.GoalStatusNotStarted, progressstate.Flags{Reset: true}); err != nil {
+		return nil, err
+	}
+
+	state, err := repo.ResetClaimed(ctx, userID, goalID)
+	if err != nil || state == nil {
+		return nil, err
+	}
+
+	repeat := goal.repeat(*state)
+	return &repeat, nil
+}
+
+// repeatableGoalRepeats returns the claim history of the repeatable goals
+// among goalIDs, by goal ID; goals never claimed have a zero GoalRepeat.
+//
+// Rows in progress that are still claimed, because resetting them after the
+// claim failed or ClaimRecovery marked them claimed, are reset first and
+// replaced in progress. progress may be nil.
+func repeatableGoalRepeats(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+	progress map[string]*domain.UserGoalProgress,
+) (map[string]GoalRepeat, error) {
+	value, ok := repeatableGoalsFrom(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	var repeatableIDs []string
+	for _, goalID := range goalIDs {
+		goal, ok := value.goals.Goal(goalID)
+		if !ok {
+			continue
+		}
+		repeatableIDs = append(repeatableIDs, goalID)
+
+		if row := progress[goalID]; row != nil && row.Status == domain.GoalStatusClaimed {
+			if _, err := resetClaimedRepeatable(ctx, value.repo, goal, userID, goalID); err != nil {
+				return nil, fmt.Errorf("failed to reset claimed repeatable goal %s: %w", goalID, err)
+			}
+			progress[goalID] = resetRepeatableRow(row)
+		}
+	}
+	if len(repeatableIDs) == 0 {
+		return nil, nil
+	}
+
+	states, err := value.repo.GetRepeatStates(ctx, userID, repeatableIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repeatable goal states: %w", err)
+	}
+
+	repeats := make(map[string]GoalRepeat, len(repeatableIDs))
+	for _, goalID := range repeatableIDs {
+		var repeat GoalRepeat
+		if state, ok := states[goalID]; ok {
+			repeat = value.goals[goalID].repeat(state)
+		}
+		repeats[goalID] = repeat
+	}
+	return repeats, nil
+}
+
+// resetRepeatableRow returns a copy of a claimed row as resetClaimedRepeatable
+// leaves it.
+func resetRepeatableRow(row *domain.UserGoalProgress) *domain.UserGoalProgress {
+	reset := *row
+	reset.Status = domain.GoalStatusNotStarted
+	reset.Progress = 0
+	reset.BaselineValue = nil
+	reset.CompletedAt = nil
+	reset.ClaimedAt = nil
+	reset.IsActive = false
+	return &reset
+}
+
+// pendingRepeat returns the claim history of the goal once the user's claimed
+// row, claimed at claimedAt, is reset: the reset counts the claim. It is for
+// read-only callers, which leave the row claimed.
+func (g RepeatableGoal) pendingRepeat(repeat GoalRepeat, claimedAt *time.Time) GoalRepeat {
+	if claimedAt == nil {
+		return repeat
+	}
+	return g.repeat(serviceRepo.RepeatState{
+		TimesClaimed:     repeat.TimesClaimed + 1,
+		LastClaimedAt:    *claimedAt,
+		FirstCompletedAt: repeat.FirstCompletedAt,
+	})
+}
+
+// challengeGoalRepeats is repeatableGoalRepeats for the goals of a challenge.
+func challengeGoalRepeats(
+	ctx context.Context,
+	userID string,
+	goals []*domain.Goal,
+	progress map[string]*domain.UserGoalProgress,
+) (map[string]GoalRepeat, error) {
+	goalIDs := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+	return repeatableGoalRepeats(ctx, userID, goalIDs, progress)
+}
+
+// coolingDownError returns a GoalCoolingDownError for the first of goalIDs
+// that is cooling down at now, or nil.
+func coolingDownError(repeats map[string]GoalRepeat, goalIDs []string, now time.Time) error {
+	for _, goalID := range goalIDs {
+		if repeat, ok := repeats[goalID]; ok && repeat.CoolingDown(now) {
+			return &mapper.GoalCoolingDownError{GoalID: goalID, AvailableAt: repeat.AvailableAt}
+		}
+	}
+	return nil
+}
+
+// withoutCoolingDown returns goalIDs without the goals cooling down at now.
+// goalIDs is returned as is when none are.
+func withoutCoolingDown(goalIDs []string, repeats map[string]GoalRepeat, now time.Time) []string {
+	if len(repeats) == 0 {
+		return goalIDs
+	}
+	available := make([]string, 0, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if repeat, ok := repeats[goalID]; !ok || !repeat.CoolingDown(now) {
+			available = append(available, goalID)
+		}
+	}
+	return available
+}
+
+// ResolveGoalRepeats returns the claim history of the repeatable goals among
+// goalIDs for listings, by goal ID. Rows are only read, never reset. A failure
+// is logged through logger and returns nil, so listings show no history rather
+// than fail.
+func ResolveGoalRepeats(ctx context.Context, userID string, goalIDs []string, logger logrus.FieldLogger) map[string]GoalRepeat {
+	repeats, err := repeatableGoalRepeats(ctx, userID, goalIDs, nil)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"error":   err,
+		}).Warn("Failed to load repeatable goal states")
+		return nil
+	}
+	return repeats
+}
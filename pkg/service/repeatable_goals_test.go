@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepeatableGoals(t *testing.T) {
+	data := []byte(`{"challenges": [
+		{"challengeId": "daily", "trackInactiveProgress": false, "goals": [
+			{"goalId": "win", "repeatable": true, "cooldownHours": 24},
+			{"goalId": "play", "repeatable": true},
+			{"goalId": "plain"}
+		]},
+		{"challengeId": "season", "goals": [{"goalId": "other", "repeatable": false}]}
+	]}`)
+
+	goals, err := ParseRepeatableGoals(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, RepeatableGoals{
+		"win":  {Cooldown: 24 * time.Hour},
+		"play": {},
+	}, goals)
+	_, ok := goals.Goal("plain")
+	assert.False(t, ok)
+}
+
+func TestParseRepeatableGoals_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "cooldown without repeatable",
+			config:  `{"challengeId": "c", "trackInactiveProgress": false, "goals": [{"goalId": "g", "cooldownHours": 1}]}`,
+			wantErr: "goal g: cooldownHours requires repeatable",
+		},
+		{
+			name:    "negative cooldown",
+			config:  `{"challengeId": "c", "trackInactiveProgress": false, "goals": [{"goalId": "g", "repeatable": true, "cooldownHours": -1}]}`,
+			wantErr: "goal g: cooldownHours must not be negative, got -1",
+		},
+		{
+			name:    "rotation",
+			config:  `{"challengeId": "c", "trackInactiveProgress": false, "goals": [{"goalId": "g", "repeatable": true, "rotation": {"enabled": true}}]}`,
+			wantErr: "goal g: repeatable cannot be used with rotation",
+		},
+		{
+			name:    "reward cap",
+			config:  `{"challengeId": "c", "trackInactiveProgress": false, "goals": [{"goalId": "g", "repeatable": true, "rewardCap": {"limit": 1}}]}`,
+			wantErr: "goal g: repeatable cannot be used with rewardCap",
+		},
+		{
+			name:    "tracks inactive progress by default",
+			config:  `{"challengeId": "c", "goals": [{"goalId": "g", "repeatable": true}]}`,
+			wantErr: `goal g: repeatable requires "trackInactiveProgress": false on challenge c`,
+		},
+		{
+			name:    "tracks inactive progress",
+			config:  `{"challengeId": "c", "trackInactiveProgress": true, "goals": [{"goalId": "g", "repeatable": true}]}`,
+			wantErr: `goal g: repeatable requires "trackInactiveProgress": false on challenge c`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRepeatableGoals([]byte(`{"challenges": [` + tt.config + `]}`))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseRepeatableGoals_DisabledRotation(t *testing.T) {
+	goals, err := ParseRepeatableGoals([]byte(`{"challenges": [{"challengeId": "c", "trackInactiveProgress": false, "goals": [
+		{"goalId": "g", "repeatable": true, "rotation": {"enabled": false}, "rewardCap": null}
+	]}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, RepeatableGoals{"g": {}}, goals)
+}
+
+// TestGoalRepeat_CoolingDown checks the boundary: the cooldown is over at
+// AvailableAt exactly.
+func TestGoalRepeat_CoolingDown(t *testing.T) {
+	lastClaimed := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repeat := RepeatableGoal{Cooldown: 24 * time.Hour}.repeat(repository.RepeatState{TimesClaimed: 2, LastClaimedAt: lastClaimed})
+	availableAt := lastClaimed.Add(24 * time.Hour)
+
+	assert.Equal(t, GoalRepeat{TimesClaimed: 2, AvailableAt: availableAt}, repeat)
+	assert.True(t, repeat.CoolingDown(lastClaimed))
+	assert.True(t, repeat.CoolingDown(availableAt.Add(-time.Nanosecond)))
+	assert.False(t, repeat.CoolingDown(availableAt))
+	assert.False(t, repeat.CoolingDown(availableAt.Add(time.Nanosecond)))
+
+	// Never claimed, and without a cooldown: available right away
+	assert.False(t, GoalRepeat{}.CoolingDown(lastClaimed))
+	noCooldown := RepeatableGoal{}.repeat(repository.RepeatState{TimesClaimed: 1, LastClaimedAt: lastClaimed})
+	assert.False(t, noCooldown.CoolingDown(lastClaimed))
+}
+
+func TestCoolingDownError(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repeats := map[string]GoalRepeat{
+		"ready":   {TimesClaimed: 1, AvailableAt: now},
+		"cooling": {TimesClaimed: 3, AvailableAt: now.Add(time.Second)},
+	}
+
+	assert.NoError(t, coolingDownError(repeats, []string{"ready", "other"}, now))
+
+	err := coolingDownError(repeats, []string{"ready", "cooling"}, now)
+	var coolingErr *mapper.GoalCoolingDownError
+	require.ErrorAs(t, err, &coolingErr)
+	assert.Equal(t, "cooling", coolingErr.GoalID)
+	assert.Equal(t, now.Add(time.Second), coolingErr.AvailableAt)
+
+	assert.Equal(t, []string{"ready", "other"}, withoutCoolingDown([]string{"ready", "cooling", "other"}, repeats, now))
+}
+
+func TestWithRepeatableGoals_Empty(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithRepeatableGoals(ctx, nil, new(mocks.RepeatableGoalRepository)))
+	assert.Equal(t, ctx, WithRepeatableGoals(ctx, RepeatableGoals{"g": {}}, nil))
+}
+
+func TestResolveGoalRepeats(t *testing.T) {
+	lastClaimed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("GetRepeatStates", mock.Anything, "user-1", []string{"goal-1"}).
+		Return(map[string]repository.RepeatState{"goal-1": {TimesClaimed: 2, LastClaimedAt: lastClaimed}}, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user-1", []string{"goal-1"}).
+		Return(nil, errors.New("connection refused")).Once()
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"goal-1": {Cooldown: time.Hour}}, repeats)
+	logger, hook := logtest.NewNullLogger()
+
+	resolved := ResolveGoalRepeats(ctx, "user-1", []string{"goal-1", "other"}, logger)
+	assert.Equal(t, map[string]GoalRepeat{"goal-1": {TimesClaimed: 2, AvailableAt: lastClaimed.Add(time.Hour)}}, resolved)
+	assert.Empty(t, hook.AllEntries())
+
+	// Listings show no history rather than fail
+	assert.Nil(t, ResolveGoalRepeats(ctx, "user-1", []string{"goal-1"}, logger))
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Failed to load repeatable goal states", hook.LastEntry().Message)
+	assert.Equal(t, "user-1", hook.LastEntry().Data["user_id"])
+	repeats.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_RepeatableResets(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil).Once()
+	lastClaimed := time.Now().UTC()
+	repeats := new(mocks.RepeatableGoalRepository)
+	// The check before the claim finds nothing to repair; the reset after it counts the claim
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").Return(nil, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal-1"}).
+		Return(map[string]repository.RepeatState{"goal-1": {TimesClaimed: 1, LastClaimedAt: lastClaimed.Add(-25 * time.Hour)}}, nil).Once()
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").
+		Return(&repository.RepeatState{TimesClaimed: 2, LastClaimedAt: lastClaimed}, nil).Once()
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"goal-1": {Cooldown: 24 * time.Hour}}, repeats)
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+	assert.Equal(t, &GoalRepeat{TimesClaimed: 2, AvailableAt: lastClaimed.Add(24 * time.Hour)}, result.Repeat)
+	repeats.AssertExpectations(t)
+	mockRewardClient.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_RepeatableResetFails(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil).Once()
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").Return(nil, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal-1"}).Return(nil, nil).Once()
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").Return(nil, errors.New("connection refused")).Once()
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"goal-1": {}}, repeats)
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	// The reward is granted; the row is reset by the goal's next claim or activation
+	require.NoError(t, err)
+	assert.Nil(t, result.Repeat)
+	repeats.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_RepeatableCoolingDown(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	mockRepo := new(mocks.GoalRepository)
+	mockRewardClient := new(mocks.RewardClient)
+	lastClaimed := time.Now().UTC().Add(-time.Hour)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").Return(nil, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal-1"}).
+		Return(map[string]repository.RepeatState{"goal-1": {TimesClaimed: 1, LastClaimedAt: lastClaimed}}, nil).Once()
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"goal-1": {Cooldown: 24 * time.Hour}}, repeats)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var coolingErr *mapper.GoalCoolingDownError
+	require.ErrorAs(t, err, &coolingErr)
+	assert.Equal(t, "goal-1", coolingErr.GoalID)
+	assert.Equal(t, lastClaimed.Add(24*time.Hour), coolingErr.AvailableAt)
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_RepeatableStateFails(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").Return(nil, errors.New("connection refused")).Once()
+
+	ctx := WithRepeatableGoals(context.Background(), RepeatableGoals{"goal-1": {}}, repeats)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, new(mocks.GoalRepository), newClaimOutbox(), 0, new(mocks.RewardClient), SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+}
+
+func TestSetGoalActive_RepeatableCoolingDown(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	claimedAt := time.Now().UTC().Add(-time.Hour)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetProgress", mock.Anything, "user123", "goal-1").Return(&domain.UserGoalProgress{
+		UserID: "user123", GoalID: "goal-1", ChallengeID: "challenge-1",
+		Status: domain.GoalStatusClaimed, ClaimedAt: &claimedAt, IsActive: true,
+	}, nil)
+	repeats := new(mocks.RepeatableGoalRepository)
+	// The claimed row whose reset failed is reset first
+	repeats.On("ResetClaimed", mock.Anything, "user123", "goal-1").
+		Return(&repository.RepeatState{TimesClaimed: 1, LastClaimedAt: claimedAt}, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal-1"}).
+		Return(map[string]repository.RepeatState{"goal-1": {TimesClaimed: 1, LastClaimedAt: claimedAt}}, nil).Once()
+
+	ctx = WithRepeatableGoals(ctx, RepeatableGoals{"goal-1": {Cooldown: 24 * time.Hour}}, repeats)
+	_, err := SetGoalActive(ctx, "user123", "challenge-1", "goal-1", "test-namespace", true, mockCache, mockRepo, logrus.StandardLogger())
+
+	var coolingErr *mapper.GoalCoolingDownError
+	require.ErrorAs(t, err, &coolingErr)
+	assert.Equal(t, claimedAt.Add(24*time.Hour), coolingErr.AvailableAt)
+	repeats.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
+
+func TestSetGoalActive_RepeatableAfterCooldown(t *testing.T) {
+	ctx := context.Background()
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache := new(mocks.GoalCache)
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetProgress", mock.Anything, "user123", "goal-1").Return(&domain.UserGoalProgress{
+		UserID: "user123", GoalID: "goal-1", ChallengeID: "challenge-1", Status: domain.GoalStatusNotStarted,
+	}, nil)
+	mockRepo.On("UpsertGoalActive", mock.Anything, mock.MatchedBy(func(p *domain.UserGoalProgress) bool {
+		return p.IsActive && p.Status == domain.GoalStatusNotStarted
+	})).Return(nil).Once()
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{"goal-1"}).
+		Return(map[string]repository.RepeatState{"goal-1": {TimesClaimed: 4, LastClaimedAt: time.Now().UTC().Add(-25 * time.Hour)}}, nil).Once()
+
+	ctx = WithRepeatableGoals(ctx, RepeatableGoals{"goal-1": {Cooldown: 24 * time.Hour}}, repeats)
+	result, err := SetGoalActive(ctx, "user123", "challenge-1", "goal-1", "test-namespace", true, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	repeats.AssertNotCalled(t, "ResetClaimed", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAvailableGoals_RepeatableCoolingDown(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 3)
+	cooling, ready, plain := challenge.Goals[0].ID, challenge.Goals[1].ID, challenge.Goals[2].ID
+	now := time.Now().UTC()
+	userProgress := []*domain.UserGoalProgress{
+		{UserID: "user123", GoalID: cooling, Status: domain.GoalStatusNotStarted},
+		// Still claimed: reset before the pool is built
+		{UserID: "user123", GoalID: ready, Status: domain.GoalStatusClaimed, ClaimedAt: &now},
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "daily-challenge", false).Return(userProgress, nil)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("ResetClaimed", mock.Anything, "user123", ready).
+		Return(&repository.RepeatState{TimesClaimed: 1, LastClaimedAt: now}, nil).Once()
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{cooling, ready}).Return(map[string]repository.RepeatState{
+		cooling: {TimesClaimed: 2, LastClaimedAt: now.Add(-time.Hour)},
+		ready:   {TimesClaimed: 1, LastClaimedAt: now},
+	}, nil).Once()
+
+	ctx = WithRepeatableGoals(ctx, RepeatableGoals{cooling: {Cooldown: 24 * time.Hour}, ready: {}}, repeats)
+	goalIDs, err := AvailableGoals(ctx, "user123", "daily-challenge", false, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{ready, plain}, goalIDs)
+	repeats.AssertExpectations(t)
+}
+
+func TestBatchSelectGoals_RepeatableCoolingDown(t *testing.T) {
+	ctx := context.Background()
+	challenge := createTestChallengeWithGoals("daily-challenge", 2)
+	cooling := challenge.Goals[0].ID
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetChallengeByChallengeID", "daily-challenge").Return(challenge)
+	for _, goal := range challenge.Goals {
+		mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	}
+	mockRepo.On("GetChallengeProgress", mock.Anything, "user123", "daily-challenge", false).Return([]*domain.UserGoalProgress{}, nil)
+	lastClaimed := time.Now().UTC().Add(-time.Hour)
+	repeats := new(mocks.RepeatableGoalRepository)
+	repeats.On("GetRepeatStates", mock.Anything, "user123", []string{cooling}).
+		Return(map[string]repository.RepeatState{cooling: {TimesClaimed: 1, LastClaimedAt: lastClaimed}}, nil).Once()
+
+	ctx = WithRepeatableGoals(ctx, RepeatableGoals{cooling: {Cooldown: 2 * time.Hour}}, repeats)
+	_, err := BatchSelectGoals(ctx, "user123", "daily-challenge", []string{challenge.Goals[1].ID, cooling}, false, "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+
+	var coolingErr *mapper.GoalCoolingDownError
+	require.ErrorAs(t, err, &coolingErr)
+	assert.Equal(t, cooling, coolingErr.GoalID)
+	mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+}
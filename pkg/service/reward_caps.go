@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+)
+
+// Which reward a claim of a goal with a reward cap granted.
+const (
+	// RewardCapGrantedCapped is the goal's own reward, within the cap.
+	RewardCapGrantedCapped = "capped"
+	// RewardCapGrantedFallback is the fallback reward, past the cap.
+	RewardCapGrantedFallback = "fallback"
+	// RewardCapGrantedNone means nothing was granted, past the cap of a goal
+	// without a fallback reward.
+	RewardCapGrantedNone = "none"
+)
+
+// RewardCap limits a goal's reward to the first Limit claims. Later claims
+// still succeed but grant FallbackReward, or nothing when it is nil.
+type RewardCap struct {
+	Limit          int            `json:"limit"`
+	FallbackReward *domain.Reward `json:"fallbackReward,omitempty"`
+}
+
+// RewardCaps maps goal IDs to their reward caps, e.g. a skin for the first 1000
+// players to finish. A nil RewardCaps caps no goal.
+//
+// domain.Goal (extend-challenge-common) has no reward cap, so the caps are read
+// from the config file separately by LoadRewardCaps. Claims are counted per
+// goal ID by serviceRepo.RewardCapRepository across all users and namespaces.
+type RewardCaps map[string]RewardCap
+
+// RewardCapOutcome tells a claimant which reward a capped goal granted.
+type RewardCapOutcome struct {
+	// Granted is one of the RewardCapGranted* values.
+	Granted string
+	Limit   int
+	// Remaining is how many claims are left within the cap, as of this claim;
+	// concurrent claims may already have taken them.
+	Remaining int
+}
+
+// rewardCapsConfig is the subset of challenges.json needed to read the caps.
+type rewardCapsConfig struct {
+	Challenges []struct {
+		Goals []struct {
+			ID        string     `json:"goalId"`
+			RewardCap *RewardCap `json:"rewardCap"`
+			Rotation  *struct {
+				Enabled bool `json:"enabled"`
+			} `json:"rotation"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadRewardCaps reads the reward caps from the challenge config file.
+func LoadRewardCaps(configPath string) (RewardCaps, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseRewardCaps(data)
+}
+
+// ParseRewardCaps extracts the goals' "rewardCap" from challenge config JSON.
+// The limit must be positive and a fallback reward must be valid. Rotating
+// goals cannot be capped: a rotated goal is claimed again by the same players,
+// so the cap would run out in the first period.
+func ParseRewardCaps(data []byte) (RewardCaps, error) {
+	var cfg rewardCapsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	caps := make(RewardCaps)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			if goal.RewardCap == nil {
+				continue
+			}
+			if goal.Rotation != nil && goal.Rotation.Enabled {
+				return nil, fmt.Errorf("goal %s: rewardCap cannot be used with rotation", goal.ID)
+			}
+			if goal.RewardCap.Limit <= 0 {
+				return nil, fmt.Errorf("goal %s: rewardCap.limit must be positive, got %d", goal.ID, goal.RewardCap.Limit)
+			}
+			if fallback := goal.RewardCap.FallbackReward; fallback != nil {
+				if err := validateFallbackReward(*fallback); err != nil {
+					return nil, fmt.Errorf("goal %s: rewardCap.fallbackReward: %w", goal.ID, err)
+				}
+			}
+			caps[goal.ID] = *goal.RewardCap
+		}
+	}
+
+	return caps, nil
+}
+
+// validateFallbackReward applies the reward rules of the common config
+// validator to a fallback reward.
+func validateFallbackReward(reward domain.Reward) error {
+	switch {
+	case reward.Type != string(domain.RewardTypeItem) && reward.Type != string(domain.RewardTypeWallet):
+		return fmt.Errorf("invalid type %q (must be %q or %q)", reward.Type, domain.RewardTypeItem, domain.RewardTypeWallet)
+	case reward.RewardID == "":
+		return fmt.Errorf("rewardId is required")
+	case reward.Quantity <= 0:
+		return fmt.Errorf("quantity must be positive, got %d", reward.Quantity)
+	}
+	return nil
+}
+
+// Cap returns the reward cap of goalID, if it has one.
+func (r RewardCaps) Cap(goalID string) (RewardCap, bool) {
+	rewardCap, ok := r[goalID]
+	return rewardCap, ok
+}
+
+// equal reports whether c and other cap the same number of claims with the
+// same fallback reward.
+func (c RewardCap) equal(other RewardCap) bool {
+	if c.Limit != other.Limit || (c.FallbackReward == nil) != (other.FallbackReward == nil) {
+		return false
+	}
+	return c.FallbackReward == nil || *c.FallbackReward == *other.FallbackReward
+}
+
+// outcome returns the outcome of a claim that took a slot or not, with claimed
+// slots taken afterwards.
+func (c RewardCap) outcome(taken bool, claimed int) *RewardCapOutcome {
+	outcome := &RewardCapOutcome{Limit: c.Limit, Remaining: max(0, c.Limit-claimed)}
+	switch {
+	case taken:
+		outcome.Granted = RewardCapGrantedCapped
+	case c.FallbackReward != nil:
+		outcome.Granted = RewardCapGrantedFallback
+		outcome.Remaining = 0
+	default:
+		outcome.Granted = RewardCapGrantedNone
+		outcome.Remaining = 0
+	}
+	return outcome
+}
+
+// reward returns the reward a claim with outcome grants instead of goalReward;
+// false when it grants nothing.
+func (c RewardCap) reward(goalReward domain.Reward, outcome *RewardCapOutcome) (domain.Reward, bool) {
+	switch outcome.Granted {
+	case RewardCapGrantedCapped:
+		return goalReward, true
+	case RewardCapGrantedFallback:
+		return *c.FallbackReward, true
+	default:
+		return domain.Reward{}, false
+	}
+}
+
+type rewardCapsKey struct{}
+
+// rewardCapsValue is what WithRewardCaps stores in a context.
+type rewardCapsValue struct {
+	caps RewardCaps
+	repo serviceRepo.RewardCapRepository
+}
+
+// WithRewardCaps returns a context whose claims apply caps, counting claims
+// with repo. Empty caps or a nil repo leave ctx unchanged.
+func WithRewardCaps(ctx context.Context, caps RewardCaps, repo serviceRepo.RewardCapRepository) context.Context {
+	if len(caps) == 0 || repo == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, rewardCapsKey{}, rewardCapsValue{caps: caps, repo: repo})
+}
+
+// rewardCapFrom returns the reward cap of goalID set by WithRewardCaps and the
+// repository counting its claims; false when the goal is not capped.
+func rewardCapFrom(ctx context.Context, goalID string) (RewardCap, serviceRepo.RewardCapRepository, bool) {
+	value, ok := ctx.Value(rewardCapsKey{}).(rewardCapsValue)
+	if !ok {
+		return RewardCap{}, nil, false
+	}
+	rewardCap, ok := value.caps.Cap(goalID)
+	if !ok {
+		return RewardCap{}, nil, false
+	}
+	return rewardCap, value.repo, true
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/client"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRewardCaps(t *testing.T) {
+	data := []byte(`{"challenges": [{"id": "c1", "goals": [
+		{"goalId": "skin", "rewardCap": {"limit": 1000, "fallbackReward": {"type": "WALLET", "rewardId": "GOLD", "quantity": 50}}},
+		{"goalId": "badge", "rewardCap": {"limit": 10}},
+		{"goalId": "plain"}
+	]}]}`)
+
+	caps, err := ParseRewardCaps(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, RewardCaps{
+		"skin":  {Limit: 1000, FallbackReward: &domain.Reward{Type: "WALLET", RewardID: "GOLD", Quantity: 50}},
+		"badge": {Limit: 10},
+	}, caps)
+	_, ok := caps.Cap("plain")
+	assert.False(t, ok)
+}
+
+func TestParseRewardCaps_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		goal    string
+		wantErr string
+	}{
+		{
+			name:    "zero limit",
+			goal:    `{"goalId": "g", "rewardCap": {"limit": 0}}`,
+			wantErr: "goal g: rewardCap.limit must be positive, got 0",
+		},
+		{
+			name:    "fallback type",
+			goal:    `{"goalId": "g", "rewardCap": {"limit": 1, "fallbackReward": {"type": "BADGE", "rewardId": "x", "quantity": 1}}}`,
+			wantErr: `goal g: rewardCap.fallbackReward: invalid type "BADGE"`,
+		},
+		{
+			name:    "fallback reward ID",
+			goal:    `{"goalId": "g", "rewardCap": {"limit": 1, "fallbackReward": {"type": "ITEM", "quantity": 1}}}`,
+			wantErr: "goal g: rewardCap.fallbackReward: rewardId is required",
+		},
+		{
+			name:    "fallback quantity",
+			goal:    `{"goalId": "g", "rewardCap": {"limit": 1, "fallbackReward": {"type": "ITEM", "rewardId": "x"}}}`,
+			wantErr: "goal g: rewardCap.fallbackReward: quantity must be positive, got 0",
+		},
+		{
+			name:    "rotation",
+			goal:    `{"goalId": "g", "rotation": {"enabled": true}, "rewardCap": {"limit": 1}}`,
+			wantErr: "goal g: rewardCap cannot be used with rotation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRewardCaps([]byte(`{"challenges": [{"id": "c1", "goals": [` + tt.goal + `]}]}`))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// cappedClaimMocks returns the mocks of a claim of goal-1 that reaches the
+// grant; the reward client expectations are left to the test.
+func cappedClaimMocks(goal *domain.Goal) (*mocks.GoalCache, *mocks.GoalRepository, *mocks.TxRepository, *mocks.RewardClient) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockRewardClient := new(mocks.RewardClient)
+
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil)
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", goal.ID).
+		Return(createCompletedProgress("user123", goal.ID, goal.ChallengeID), nil)
+	mockTxRepo.On("MarkAsClaimed", mock.Anything, "user123", goal.ID).Return(nil).Maybe()
+	mockTxRepo.On("Commit").Return(nil)
+	mockTxRepo.On("Rollback").Return(nil).Maybe()
+	return mockCache, mockRepo, mockTxRepo, mockRewardClient
+}
+
+func TestClaimGoalReward_RewardCap(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	fallback := &domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 50}
+
+	tests := []struct {
+		name        string
+		fallback    *domain.Reward
+		taken       bool
+		claimed     int
+		wantReward  domain.Reward
+		wantOutcome RewardCapOutcome
+	}{
+		{
+			name:        "within cap",
+			fallback:    fallback,
+			taken:       true,
+			claimed:     3,
+			wantReward:  goal.Reward,
+			wantOutcome: RewardCapOutcome{Granted: RewardCapGrantedCapped, Limit: 10, Remaining: 7},
+		},
+		{
+			name:        "past cap with fallback",
+			fallback:    fallback,
+			claimed:     10,
+			wantReward:  *fallback,
+			wantOutcome: RewardCapOutcome{Granted: RewardCapGrantedFallback, Limit: 10},
+		},
+		{
+			name:        "past cap without fallback",
+			claimed:     10,
+			wantOutcome: RewardCapOutcome{Granted: RewardCapGrantedNone, Limit: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+			if tt.wantReward != (domain.Reward{}) {
+				mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", tt.wantReward).Return(nil).Once()
+			}
+			caps := new(mocks.RewardCapRepository)
+			caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(tt.taken, tt.claimed, nil).Once()
+
+			ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10, FallbackReward: tt.fallback}}, caps)
+			result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+			require.NoError(t, err)
+			assert.Equal(t, string(domain.GoalStatusClaimed), result.Status)
+			assert.Equal(t, tt.wantReward, result.Reward)
+			assert.Equal(t, &tt.wantOutcome, result.RewardCap)
+			assert.Equal(t, tt.wantOutcome.Granted != RewardCapGrantedNone, result.Granted())
+			mockRewardClient.AssertExpectations(t)
+			caps.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClaimGoalReward_RewardCapOtherGoal(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).Return(nil).Once()
+	caps := new(mocks.RewardCapRepository)
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-2": {Limit: 10}}, caps)
+	result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, newClaimOutbox(), 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Nil(t, result.RewardCap)
+	assert.True(t, result.Granted())
+	caps.AssertNotCalled(t, "TakeSlot", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClaimGoalReward_RewardCapReleasedOnGrantFailure(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).
+		Return(&client.NotFoundError{Resource: "item"}).Once()
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(true, 1, nil).Once()
+	caps.On("ReleaseSlot", mock.Anything, "user123", "goal-1").Return(nil).Once()
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+	outbox.On("Delete", mock.Anything, "user123", "goal-1").Return(nil).Once()
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10}}, caps)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	var permanentErr *mapper.RewardGrantPermanentError
+	require.ErrorAs(t, err, &permanentErr)
+	caps.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_RewardCapSlotKeptWhenReleaseFails(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "user123", goal.Reward).
+		Return(&client.NotFoundError{Resource: "item"}).Once()
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(true, 1, nil).Once()
+	caps.On("ReleaseSlot", mock.Anything, "user123", "goal-1").Return(errors.New("connection refused")).Once()
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10}}, caps)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	// The reservation is left for claim recovery, which releases the slot with it
+	require.Error(t, err)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// The slot is taken outside the claim's transaction, so a failed commit gives
+// it back with the reservation.
+func TestClaimGoalReward_RewardCapReleasedOnCommitFailure(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, mockTxRepo, mockRewardClient := commitFailingClaimMocks(goal)
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(true, 1, nil).Once()
+	caps.On("ReleaseSlot", mock.Anything, "user123", "goal-1").Return(nil).Once()
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+	outbox.On("Delete", mock.Anything, "user123", "goal-1").Return(nil).Once()
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10}}, caps)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+	mockTxRepo.AssertExpectations(t)
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	caps.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+}
+
+// A slot whose release fails after a failed commit stays on the reservation,
+// which claim recovery deletes with it.
+func TestClaimGoalReward_RewardCapLeftForRecoveryOnCommitFailure(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, _, mockRewardClient := commitFailingClaimMocks(goal)
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(true, 1, nil).Once()
+	caps.On("ReleaseSlot", mock.Anything, "user123", "goal-1").Return(errors.New("connection refused")).Once()
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+	logger, hook := logtest.NewNullLogger()
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10}}, caps)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logger)
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+	outbox.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, "Failed to release reward cap slot, left for claim recovery", hook.LastEntry().Message)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+	caps.AssertExpectations(t)
+}
+
+// commitFailingClaimMocks returns the mocks of a claim of goal whose first
+// transaction fails to commit.
+func commitFailingClaimMocks(goal *domain.Goal) (*mocks.GoalCache, *mocks.GoalRepository, *mocks.TxRepository, *mocks.RewardClient) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockTxRepo := new(mocks.TxRepository)
+	mockCache.On("GetGoalByID", goal.ID).Return(goal)
+	mockRepo.On("BeginTx", mock.Anything).Return(mockTxRepo, nil).Once()
+	mockTxRepo.On("GetProgressForUpdate", mock.Anything, "user123", goal.ID).
+		Return(createCompletedProgress("user123", goal.ID, goal.ChallengeID), nil)
+	mockTxRepo.On("Commit").Return(errors.New("commit failed")).Once()
+	return mockCache, mockRepo, mockTxRepo, new(mocks.RewardClient)
+}
+
+func TestClaimGoalReward_RewardCapTakeSlotFails(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	mockCache, mockRepo, mockTxRepo, mockRewardClient := cappedClaimMocks(goal)
+	caps := new(mocks.RewardCapRepository)
+	caps.On("TakeSlot", mock.Anything, "user123", "goal-1", 10).Return(false, 0, errors.New("connection refused")).Once()
+	outbox := new(mocks.ClaimOutboxRepository)
+	outbox.On("Reserve", mock.Anything, mock.Anything).Return(true, nil).Once()
+	outbox.On("Delete", mock.Anything, "user123", "goal-1").Return(nil).Once()
+
+	ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10}}, caps)
+	_, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, 0, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+	mockTxRepo.AssertNotCalled(t, "Commit")
+	mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	caps.AssertNotCalled(t, "ReleaseSlot", mock.Anything, mock.Anything, mock.Anything)
+	outbox.AssertExpectations(t)
+}
+
+func TestClaimGoalReward_RewardCapResumedGrant(t *testing.T) {
+	goal := createClaimableGoal("goal-1", "challenge-1")
+	fallback := &domain.Reward{Type: string(domain.RewardTypeWallet), RewardID: "GOLD", Quantity: 50}
+
+	for _, slot := range []bool{true, false} {
+		mockCache, mockRepo, _, mockRewardClient := cappedClaimMocks(goal)
+		caps := new(mocks.RewardCapRepository)
+		outbox := new(mocks.ClaimOutboxRepository)
+		outbox.On("Reserve", mock.Anything, mock.Anything).Return(false, nil).Once()
+		outbox.On("Get", mock.Anything, "user123", "goal-1").Return(&repository.ClaimOutboxEntry{
+			UserID: "user123", GoalID: "goal-1", State: repository.ClaimOutboxGranted,
+			RewardCapSlot: slot, UpdatedAt: time.Now().UTC().Add(-time.Hour),
+		}, nil)
+		outbox.On("Delete", mock.Anything, "user123", "goal-1").Return(nil).Once()
+
+		ctx := WithRewardCaps(context.Background(), RewardCaps{"goal-1": {Limit: 10, FallbackReward: fallback}}, caps)
+		result, err := ClaimGoalReward(ctx, "user123", "goal-1", "challenge-1", "test-namespace", mockCache, mockRepo, outbox, time.Minute, mockRewardClient, SegmentTargets{}, ClaimPrecondition{}, logrus.StandardLogger())
+
+		// The crashed claim granted according to its slot; nothing is taken or granted again
+		require.NoError(t, err)
+		if slot {
+			assert.Equal(t, goal.Reward, result.Reward)
+			assert.Equal(t, RewardCapGrantedCapped, result.RewardCap.Granted)
+		} else {
+			assert.Equal(t, *fallback, result.Reward)
+			assert.Equal(t, RewardCapGrantedFallback, result.RewardCap.Granted)
+		}
+		caps.AssertNotCalled(t, "TakeSlot", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRewardClient.AssertNotCalled(t, "GrantReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestWithRewardCaps_Empty(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, WithRewardCaps(ctx, nil, new(mocks.RewardCapRepository)))
+	assert.Equal(t, ctx, WithRewardCaps(ctx, RewardCaps{"g": {Limit: 1}}, nil))
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	agsClient "extend-challenge-service/pkg/client"
+)
+
+// maxDeliveryIDLength is the longest collection or store ID AGS accepts.
+const maxDeliveryIDLength = 128
+
+// RewardDeliveries decides where the entitlements of ITEM rewards are
+// delivered (see agsClient.RewardDelivery). A claim may name a delivery, which
+// must be on the allow-list; otherwise its goal's default delivery applies, if
+// any.
+//
+// The allow-list and defaults are read from the config file separately by
+// LoadRewardDeliveries, like the other goal settings domain.Goal
+// (extend-challenge-common) does not have. A nil *RewardDeliveries allows no
+// delivery and has no defaults.
+type RewardDeliveries struct {
+	allowedCollections map[string]bool
+	allowedStores      map[string]bool
+	defaults           map[string]agsClient.RewardDelivery
+}
+
+// rewardDeliveriesConfig is the subset of challenges.json needed to read the
+// reward deliveries.
+type rewardDeliveriesConfig struct {
+	RewardDelivery struct {
+		AllowedCollectionIDs []string `json:"allowedCollectionIds"`
+		AllowedStoreIDs      []string `json:"allowedStoreIds"`
+	} `json:"rewardDelivery"`
+	Challenges []struct {
+		Goals []struct {
+			ID       string                    `json:"goalId"`
+			Delivery *agsClient.RewardDelivery `json:"delivery"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadRewardDeliveries reads the reward deliveries from the challenge config file.
+func LoadRewardDeliveries(configPath string) (*RewardDeliveries, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseRewardDeliveries(data)
+}
+
+// ParseRewardDeliveries extracts the top-level "rewardDelivery" allow-list and
+// the goals' default "delivery" from challenge config JSON. A goal's default
+// must be on the allow-list too.
+func ParseRewardDeliveries(data []byte) (*RewardDeliveries, error) {
+	var cfg rewardDeliveriesConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	d := &RewardDeliveries{
+		allowedCollections: make(map[string]bool),
+		allowedStores:      make(map[string]bool),
+		defaults:           make(map[string]agsClient.RewardDelivery),
+	}
+	for _, id := range cfg.RewardDelivery.AllowedCollectionIDs {
+		if id == "" || len(id) > maxDeliveryIDLength {
+			return nil, fmt.Errorf("rewardDelivery.allowedCollectionIds: %q must be 1-%d characters", id, maxDeliveryIDLength)
+		}
+		d.allowedCollections[id] = true
+	}
+	for _, id := range cfg.RewardDelivery.AllowedStoreIDs {
+		if id == "" || len(id) > maxDeliveryIDLength {
+			return nil, fmt.Errorf("rewardDelivery.allowedStoreIds: %q must be 1-%d characters", id, maxDeliveryIDLength)
+		}
+		d.allowedStores[id] = true
+	}
+
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			if goal.Delivery == nil || goal.Delivery.IsZero() {
+				continue
+			}
+			if err := d.validate(*goal.Delivery); err != nil {
+				return nil, fmt.Errorf("goal %s: delivery: %w", goal.ID, err)
+			}
+			d.defaults[goal.ID] = *goal.Delivery
+		}
+	}
+
+	return d, nil
+}
+
+// validate checks that every ID of delivery is on the allow-list.
+func (d *RewardDeliveries) validate(delivery agsClient.RewardDelivery) error {
+	if delivery.CollectionID != "" && (d == nil || !d.allowedCollections[delivery.CollectionID]) {
+		return fmt.Errorf("collection %q is not in rewardDelivery.allowedCollectionIds", delivery.CollectionID)
+	}
+	if delivery.StoreID != "" && (d == nil || !d.allowedStores[delivery.StoreID]) {
+		return fmt.Errorf("store %q is not in rewardDelivery.allowedStoreIds", delivery.StoreID)
+	}
+	return nil
+}
+
+// Len returns the number of goals with a default delivery.
+func (d *RewardDeliveries) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.defaults)
+}
+
+// Default returns the default delivery of goalID, which is zero without one.
+func (d *RewardDeliveries) Default(goalID string) agsClient.RewardDelivery {
+	if d == nil {
+		return agsClient.RewardDelivery{}
+	}
+	return d.defaults[goalID]
+}
+
+// Resolve returns the delivery of a claim of goalID: requested if it is set,
+// replacing the goal's default as a whole, or else the default. A requested
+// delivery off the allow-list is an error.
+func (d *RewardDeliveries) Resolve(goalID string, requested agsClient.RewardDelivery) (agsClient.RewardDelivery, error) {
+	if requested.IsZero() {
+		return d.Default(goalID), nil
+	}
+	if err := d.validate(requested); err != nil {
+		return agsClient.RewardDelivery{}, err
+	}
+	return requested, nil
+}
+
+// equal reports whether d and other allow and default to the same deliveries.
+// nil equals a set without any.
+func (d *RewardDeliveries) equal(other *RewardDeliveries) bool {
+	if d == nil {
+		d = &RewardDeliveries{}
+	}
+	if other == nil {
+		other = &RewardDeliveries{}
+	}
+	return maps.Equal(d.allowedCollections, other.allowedCollections) &&
+		maps.Equal(d.allowedStores, other.allowedStores) &&
+		maps.Equal(d.defaults, other.defaults)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	agsClient "extend-challenge-service/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRewardDeliveries(t *testing.T) {
+	data := []byte(`{
+		"rewardDelivery": {"allowedCollectionIds": ["season-stash", "main"], "allowedStoreIds": ["store-1"]},
+		"challenges": [{"id": "c1", "goals": [
+			{"goalId": "skin", "delivery": {"collectionId": "season-stash", "storeId": "store-1"}},
+			{"goalId": "empty", "delivery": {}},
+			{"goalId": "plain"}
+		]}]
+	}`)
+
+	deliveries, err := ParseRewardDeliveries(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, deliveries.Len())
+	assert.Equal(t, agsClient.RewardDelivery{CollectionID: "season-stash", StoreID: "store-1"}, deliveries.Default("skin"))
+	assert.True(t, deliveries.Default("plain").IsZero())
+}
+
+func TestParseRewardDeliveries_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "collection not allowed",
+			config:  `{"challenges": [{"goals": [{"goalId": "g", "delivery": {"collectionId": "stash"}}]}]}`,
+			wantErr: `goal g: delivery: collection "stash" is not in rewardDelivery.allowedCollectionIds`,
+		},
+		{
+			name: "store not allowed",
+			config: `{"rewardDelivery": {"allowedCollectionIds": ["stash"]},
+				"challenges": [{"goals": [{"goalId": "g", "delivery": {"collectionId": "stash", "storeId": "s"}}]}]}`,
+			wantErr: `goal g: delivery: store "s" is not in rewardDelivery.allowedStoreIds`,
+		},
+		{
+			name:    "empty allowed ID",
+			config:  `{"rewardDelivery": {"allowedCollectionIds": [""]}}`,
+			wantErr: `rewardDelivery.allowedCollectionIds: "" must be 1-128 characters`,
+		},
+		{
+			name:    "long allowed ID",
+			config:  `{"rewardDelivery": {"allowedStoreIds": ["` + strings.Repeat("s", 129) + `"]}}`,
+			wantErr: "rewardDelivery.allowedStoreIds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRewardDeliveries([]byte(tt.config))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRewardDeliveries_Resolve(t *testing.T) {
+	deliveries, err := ParseRewardDeliveries([]byte(`{
+		"rewardDelivery": {"allowedCollectionIds": ["season-stash", "main"]},
+		"challenges": [{"goals": [{"goalId": "skin", "delivery": {"collectionId": "season-stash"}}]}]
+	}`))
+	require.NoError(t, err)
+
+	got, err := deliveries.Resolve("skin", agsClient.RewardDelivery{})
+	require.NoError(t, err)
+	assert.Equal(t, agsClient.RewardDelivery{CollectionID: "season-stash"}, got, "the goal's default")
+
+	got, err = deliveries.Resolve("skin", agsClient.RewardDelivery{CollectionID: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, agsClient.RewardDelivery{CollectionID: "main"}, got, "requested replaces the default")
+
+	_, err = deliveries.Resolve("skin", agsClient.RewardDelivery{CollectionID: "other"})
+	assert.EqualError(t, err, `collection "other" is not in rewardDelivery.allowedCollectionIds`)
+
+	_, err = deliveries.Resolve("skin", agsClient.RewardDelivery{StoreID: "store-1"})
+	assert.Error(t, err, "no store is allowed")
+}
+
+func TestRewardDeliveries_Nil(t *testing.T) {
+	var deliveries *RewardDeliveries
+
+	assert.Zero(t, deliveries.Len())
+	got, err := deliveries.Resolve("skin", agsClient.RewardDelivery{})
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+	_, err = deliveries.Resolve("skin", agsClient.RewardDelivery{CollectionID: "season-stash"})
+	assert.Error(t, err)
+
+	empty, err := ParseRewardDeliveries([]byte(`{}`))
+	require.NoError(t, err)
+	assert.True(t, deliveries.equal(empty))
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSelectionHistoryLimit is the number of selections GetSelectionHistory
+	// returns when the caller does not set a limit.
+	DefaultSelectionHistoryLimit = 50
+	// MaxSelectionHistoryLimit caps the selections returned per call.
+	MaxSelectionHistoryLimit = 500
+	// DefaultSelectionStatsWindow is how far back GetSelectionStats counts when
+	// the caller does not say.
+	DefaultSelectionStatsWindow = 30 * 24 * time.Hour
+)
+
+// SelectionHistory is the result of GetSelectionHistory.
+type SelectionHistory struct {
+	Selections []*serviceRepo.GoalSelectionEvent
+	// Truncated is true when the user has more selections than were returned.
+	Truncated bool
+}
+
+// GetSelectionHistory returns the user's goal selections, newest first.
+//
+// A limit of 0 uses DefaultSelectionHistoryLimit; larger limits are capped at
+// MaxSelectionHistoryLimit.
+func GetSelectionHistory(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	limit int,
+	selections serviceRepo.GoalSelectionRepository,
+	logger logrus.FieldLogger,
+) (*SelectionHistory, error) {
+	if selections == nil {
+		return nil, fmt.Errorf("goal selection repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	limit = clampLimit(limit, DefaultSelectionHistoryLimit, MaxSelectionHistoryLimit)
+
+	// One extra row tells whether the history is truncated
+	events, err := selections.GetSelectionHistory(ctx, userID, namespace, limit+1)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"target_user_id": userID,
+			"namespace":      namespace,
+			"error":          err,
+		}).Error("Failed to get goal selection history")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	history := &SelectionHistory{Selections: events}
+	if len(events) > limit {
+		history.Selections = events[:limit]
+		history.Truncated = true
+	}
+
+	return history, nil
+}
+
+// GetSelectionStats counts how often each goal was selected and replaced since
+// the given time (zero means DefaultSelectionStatsWindow before now), for one
+// challenge or, with an empty challengeID, all of them. Goals never selected or
+// replaced in that time are not listed.
+func GetSelectionStats(
+	ctx context.Context,
+	namespace string,
+	challengeID string,
+	since time.Time,
+	selections serviceRepo.GoalSelectionRepository,
+	logger logrus.FieldLogger,
+) ([]*serviceRepo.GoalSelectionCount, error) {
+	if selections == nil {
+		return nil, fmt.Errorf("goal selection repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	if since.IsZero() {
+		since = time.Now().UTC().Add(-DefaultSelectionStatsWindow)
+	}
+
+	stats, err := selections.GetSelectionStats(ctx, namespace, challengeID, since)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"namespace":    namespace,
+			"challenge_id": challengeID,
+			"error":        err,
+		}).Error("Failed to get goal selection stats")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	return stats, nil
+}
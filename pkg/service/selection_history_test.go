@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSelectionHistory(t *testing.T) {
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("GetSelectionHistory", mock.Anything, "user-1", "ns", 3).Return([]*repository.GoalSelectionEvent{
+		{ID: 3}, {ID: 2}, {ID: 1},
+	}, nil)
+	selections.On("GetSelectionHistory", mock.Anything, "user-2", "ns", DefaultSelectionHistoryLimit+1).
+		Return([]*repository.GoalSelectionEvent{{ID: 4}}, nil)
+
+	history, err := GetSelectionHistory(context.Background(), "user-1", "ns", 2, selections, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.Len(t, history.Selections, 2)
+	assert.True(t, history.Truncated)
+
+	history, err = GetSelectionHistory(context.Background(), "user-2", "ns", 0, selections, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.Len(t, history.Selections, 1)
+	assert.False(t, history.Truncated)
+}
+
+func TestGetSelectionHistory_RepositoryError(t *testing.T) {
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("GetSelectionHistory", mock.Anything, "user-1", "ns", MaxSelectionHistoryLimit+1).Return(nil, errors.New("connection reset"))
+
+	_, err := GetSelectionHistory(context.Background(), "user-1", "ns", 100000, selections, logrus.StandardLogger())
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+}
+
+func TestGetSelectionStats_DefaultWindow(t *testing.T) {
+	selections := new(mocks.GoalSelectionRepository)
+	selections.On("GetSelectionStats", mock.Anything, "ns", "", mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) > DefaultSelectionStatsWindow-time.Minute && time.Since(since) < DefaultSelectionStatsWindow+time.Minute
+	})).Return([]*repository.GoalSelectionCount{{ChallengeID: "c", GoalID: "g", Selected: 2}}, nil)
+
+	stats, err := GetSelectionStats(context.Background(), "ns", "", time.Time{}, selections, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.Len(t, stats, 1)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	serviceCache "extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceState is what GetServiceState reports about a running instance, for
+// debugging an environment without log access. It holds no user data.
+type ServiceState struct {
+	// ConfigVersion and ConfigPath describe the loaded challenge config (see
+	// ConfigInfo); ConfigPath is the last-known-good copy after a fallback boot.
+	ConfigVersion string
+	ConfigPath    string
+	Challenges    int
+	Goals         int
+	Cache         serviceCache.SerializedCacheStats
+	// RewardClientMode is REWARD_CLIENT_MODE: "real" or "mock".
+	RewardClientMode string
+	AuthEnabled      bool
+	DB               sql.DBStats
+	StartedAt        time.Time
+	Uptime           time.Duration
+}
+
+// ServiceStateReporter assembles the ServiceState. It is off unless
+// SERVICE_STATE_RPC_ENABLED is set: the state names the config path and the
+// deployment's modes, which an admin token alone should not reveal everywhere.
+// A nil *ServiceStateReporter is disabled.
+type ServiceStateReporter struct {
+	enabled          bool
+	configInfo       *ConfigInfo
+	goalCache        cache.GoalCache
+	serCache         *serviceCache.SerializedChallengeCache
+	db               *sql.DB
+	rewardClientMode string
+	authEnabled      bool
+	startedAt        time.Time
+	now              func() time.Time
+}
+
+// NewServiceStateReporterFromEnv creates a reporter enabled by
+// SERVICE_STATE_RPC_ENABLED (default false); an invalid value is logged to
+// logger. See NewServiceStateReporter for the other arguments.
+func NewServiceStateReporterFromEnv(
+	configInfo *ConfigInfo,
+	goalCache cache.GoalCache,
+	serCache *serviceCache.SerializedChallengeCache,
+	db *sql.DB,
+	rewardClientMode string,
+	authEnabled bool,
+	startedAt time.Time,
+	logger logrus.FieldLogger,
+) *ServiceStateReporter {
+	enabled, err := strconv.ParseBool(common.GetEnv("SERVICE_STATE_RPC_ENABLED", "false"))
+	if err != nil {
+		logger.Warnf("Invalid SERVICE_STATE_RPC_ENABLED %q, leaving it off", common.GetEnv("SERVICE_STATE_RPC_ENABLED", ""))
+		enabled = false
+	}
+
+	return NewServiceStateReporter(enabled, configInfo, goalCache, serCache, db, rewardClientMode, authEnabled, startedAt)
+}
+
+// NewServiceStateReporter creates a reporter of the live config (configInfo and
+// goalCache), the serialization cache and the database pool of db, which may
+// be nil. startedAt is when the process started.
+func NewServiceStateReporter(
+	enabled bool,
+	configInfo *ConfigInfo,
+	goalCache cache.GoalCache,
+	serCache *serviceCache.SerializedChallengeCache,
+	db *sql.DB,
+	rewardClientMode string,
+	authEnabled bool,
+	startedAt time.Time,
+) *ServiceStateReporter {
+	return &ServiceStateReporter{
+		enabled:          enabled,
+		configInfo:       configInfo,
+		goalCache:        goalCache,
+		serCache:         serCache,
+		db:               db,
+		rewardClientMode: rewardClientMode,
+		authEnabled:      authEnabled,
+		startedAt:        startedAt,
+		now:              time.Now,
+	}
+}
+
+// Enabled reports whether GetServiceState is served.
+func (r *ServiceStateReporter) Enabled() bool {
+	return r != nil && r.enabled
+}
+
+// State returns the current state. The counts are read from the goal cache
+// rather than ConfigInfo, so they match what requests are served from.
+func (r *ServiceStateReporter) State() ServiceState {
+	state := ServiceState{
+		ConfigVersion:    r.configInfo.Version(),
+		ConfigPath:       r.configInfo.Path(),
+		RewardClientMode: r.rewardClientMode,
+		AuthEnabled:      r.authEnabled,
+		StartedAt:        r.startedAt,
+		Uptime:           r.now().Sub(r.startedAt),
+	}
+
+	if r.goalCache != nil {
+		challenges := r.goalCache.GetAllChallenges()
+		state.Challenges = len(challenges)
+		for _, challenge := range challenges {
+			state.Goals += len(challenge.Goals)
+		}
+	}
+	if r.serCache != nil {
+		state.Cache = r.serCache.GetStats()
+	}
+	if r.db != nil {
+		state.DB = r.db.Stats()
+	}
+
+	return state
+}
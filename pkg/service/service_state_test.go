@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	serviceCache "extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceStateReporter_State(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, []*domain.Challenge{
+		{ID: "c1", Name: "C1", Goals: []*domain.Goal{newDiffGoal("g1", "c1", 100), newDiffGoal("g2", "c1", 10)}},
+		{ID: "c2", Name: "C2", Goals: []*domain.Goal{newDiffGoal("g3", "c2", 5)}},
+	}, new(mocks.ProgressQueryRepository))
+
+	serCache := serviceCache.NewSerializedChallengeCache()
+	pbChallenges, err := mapper.ChallengesToProto(reloader.goalCache.GetAllChallenges(), nil, nil, time.Now().UTC())
+	require.NoError(t, err)
+	require.NoError(t, serCache.WarmUp(pbChallenges))
+
+	info := NewConfigInfo(logrus.StandardLogger())
+	info.Update(path, reloader.goalCache.GetAllChallenges(), serCache.GetStats().TotalBytes)
+	version, err := ConfigVersion(path)
+	require.NoError(t, err)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(7)
+
+	startedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reporter := NewServiceStateReporter(true, info, reloader.goalCache, serCache, db, "mock", false, startedAt)
+	reporter.now = func() time.Time { return startedAt.Add(90 * time.Minute) }
+
+	state := reporter.State()
+
+	assert.Equal(t, version, state.ConfigVersion)
+	assert.Equal(t, path, state.ConfigPath)
+	assert.Equal(t, 2, state.Challenges)
+	assert.Equal(t, 3, state.Goals)
+	assert.Equal(t, serCache.GetStats(), state.Cache)
+	assert.Equal(t, 2, state.Cache.ChallengeFragments)
+	assert.Equal(t, "mock", state.RewardClientMode)
+	assert.False(t, state.AuthEnabled)
+	assert.Equal(t, 7, state.DB.MaxOpenConnections)
+	assert.Equal(t, startedAt, state.StartedAt)
+	assert.Equal(t, 90*time.Minute, state.Uptime)
+}
+
+func TestServiceStateReporter_WithoutSources(t *testing.T) {
+	startedAt := time.Now().UTC()
+	state := NewServiceStateReporter(true, nil, nil, nil, nil, "real", true, startedAt).State()
+
+	assert.Empty(t, state.ConfigVersion)
+	assert.Zero(t, state.Challenges)
+	assert.Zero(t, state.Cache)
+	assert.Zero(t, state.DB)
+	assert.True(t, state.AuthEnabled)
+}
+
+func TestServiceStateReporter_Enabled(t *testing.T) {
+	var reporter *ServiceStateReporter
+	assert.False(t, reporter.Enabled())
+	assert.False(t, NewServiceStateReporter(false, nil, nil, nil, nil, "real", true, time.Now()).Enabled())
+
+	logger, hook := logtest.NewNullLogger()
+
+	t.Setenv("SERVICE_STATE_RPC_ENABLED", "true")
+	assert.True(t, NewServiceStateReporterFromEnv(nil, nil, nil, nil, "real", true, time.Now(), logger).Enabled())
+	assert.Nil(t, hook.LastEntry())
+
+	t.Setenv("SERVICE_STATE_RPC_ENABLED", "yes please")
+	assert.False(t, NewServiceStateReporterFromEnv(nil, nil, nil, nil, "real", true, time.Now(), logger).Enabled())
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SessionStart is the player state a game client loads at login: what
+// InitializePlayer returns, the active-only progress summary and the
+// unclaimed counts.
+type SessionStart struct {
+	*InitializeResponse
+	Summary   *ProgressSummary
+	Unclaimed UnclaimedCount
+}
+
+// StartSession initializes the player as InitializePlayer does and derives the
+// progress summary (active goals only) and the unclaimed counts from the active
+// goals it returns, so a session start costs no more queries than the
+// initialization itself: a goal count and the active goals for a returning
+// player, a goal count and the insert for a new one.
+//
+// The counts use the statuses the client is shown for the active goals; active
+// goals no longer in the config are left out of them, as they are left out of
+// the assigned goals.
+func StartSession(
+	ctx context.Context,
+	userID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	inserter serviceRepo.ProgressInsertRepository,
+	logger logrus.FieldLogger,
+	now time.Time,
+) (*SessionStart, error) {
+	result, err := InitializePlayer(ctx, userID, namespace, goalCache, repo, inserter, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	byChallenge := make(map[string]*ProgressCounts)
+	var unclaimed UnclaimedCount
+	for _, goal := range result.AssignedGoals {
+		challengeCounts, ok := byChallenge[goal.ChallengeID]
+		if !ok {
+			challengeCounts = &ProgressCounts{}
+			byChallenge[goal.ChallengeID] = challengeCounts
+		}
+		status := domain.GoalStatus(goal.Status)
+		challengeCounts.add(status, 1, true)
+
+		switch status {
+		case domain.GoalStatusCompleted:
+			unclaimed.Unclaimed++
+		case domain.GoalStatusInProgress:
+			unclaimed.InProgress++
+		}
+	}
+
+	return &SessionStart{
+		InitializeResponse: result,
+		Summary:            rollUpProgressSummary(goalCache, byChallenge, true, now),
+		Unclaimed:          unclaimed,
+	}, nil
+}
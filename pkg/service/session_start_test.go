@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionStartCache returns a config of two challenges: c1 with the default
+// goals goal-1 and goal-2 and the optional goal-3, and c2 with the default goal goal-4.
+func newSessionStartCache() commonCache.GoalCache {
+	defaultGoal := func(goalID, challengeID string) *domain.Goal {
+		goal := createClaimableGoal(goalID, challengeID)
+		goal.DefaultAssigned = true
+		return goal
+	}
+
+	return commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c1", Name: "Challenge 1", Goals: []*domain.Goal{
+			defaultGoal("goal-1", "c1"), defaultGoal("goal-2", "c1"), createClaimableGoal("goal-3", "c1"),
+		}},
+		{ID: "c2", Name: "Challenge 2", Goals: []*domain.Goal{defaultGoal("goal-4", "c2")}},
+	}}, "", slog.Default())
+}
+
+func sessionProgress(goalID, challengeID string, status domain.GoalStatus, progress int) *domain.UserGoalProgress {
+	return &domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      goalID,
+		ChallengeID: challengeID,
+		Namespace:   "test-namespace",
+		Progress:    progress,
+		Status:      status,
+		IsActive:    true,
+	}
+}
+
+func TestStartSession_ReturningPlayer(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(4, nil)
+	mockRepo.On("GetActiveGoals", ctx, "user123").Return([]*domain.UserGoalProgress{
+		sessionProgress("goal-1", "c1", domain.GoalStatusCompleted, 10),
+		sessionProgress("goal-3", "c1", domain.GoalStatusClaimed, 10),
+		sessionProgress("goal-4", "c2", domain.GoalStatusInProgress, 4),
+		sessionProgress("removed-goal", "c2", domain.GoalStatusCompleted, 10),
+	}, nil)
+
+	session, err := StartSession(ctx, "user123", "test-namespace", newSessionStartCache(), mockRepo, nil, logrus.StandardLogger(), time.Now())
+
+	require.NoError(t, err)
+	assert.Len(t, session.AssignedGoals, 3, "goals removed from the config are left out")
+	assert.Equal(t, UnclaimedCount{Unclaimed: 1, InProgress: 1}, session.Unclaimed)
+	assert.Equal(t, ProgressCounts{TotalGoals: 3, Completed: 2, Claimed: 1, InProgress: 1}, session.Summary.Totals)
+	require.Len(t, session.Summary.Challenges, 2)
+	assert.Equal(t, "Challenge 1", session.Summary.Challenges[0].Name)
+	assert.Equal(t, ProgressCounts{TotalGoals: 2, Completed: 2, Claimed: 1}, session.Summary.Challenges[0].ProgressCounts)
+	assert.Equal(t, ProgressCounts{TotalGoals: 1, InProgress: 1}, session.Summary.Challenges[1].ProgressCounts)
+
+	// The goal count and the active goals are the only reads
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "GetUserGoalCount", 1)
+	mockRepo.AssertNumberOfCalls(t, "GetActiveGoals", 1)
+}
+
+func TestStartSession_FirstLogin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, nil)
+	mockRepo.On("BulkInsert", ctx, mock.Anything).Return(nil)
+
+	session, err := StartSession(ctx, "user123", "test-namespace", newSessionStartCache(), mockRepo, nil, logrus.StandardLogger(), time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, session.NewAssignments)
+	assert.Equal(t, UnclaimedCount{}, session.Unclaimed)
+	assert.Equal(t, ProgressCounts{TotalGoals: 3}, session.Summary.Totals)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartSession_InitializeError(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.GoalRepository)
+	mockRepo.On("GetUserGoalCount", ctx, "user123").Return(0, errors.New("database error"))
+
+	session, err := StartSession(ctx, "user123", "test-namespace", newSessionStartCache(), mockRepo, nil, logrus.StandardLogger(), time.Now())
+
+	require.Error(t, err)
+	assert.Nil(t, session)
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"time"
 
+	"extend-challenge-service/pkg/mapper"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/cache"
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 	"github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,6 +23,15 @@ type SetGoalActiveResponse struct {
 	IsActive    bool
 	AssignedAt  *time.Time
 	Message     string
+	// Changed is false when the goal was already in the requested state and no write was made.
+	Changed bool
+	// ActivationSource is how the goal was last activated; deactivation keeps it.
+	// Filled in by the caller (see ResolveActivationSources).
+	ActivationSource string
+	// Progress and Status are the goal's progress after the call, as listed to
+	// the player (rotation applied); 0 and not_started without a row.
+	Progress int
+	Status   string
 }
 
 // SetGoalActive allows players to manually control goal assignment.
@@ -27,8 +40,9 @@ type SetGoalActiveResponse struct {
 //
 // Flow:
 // 1. Validate goal exists in config
-// 2. UPSERT goal progress with is_active status
-// 3. Update assigned_at only when activating (not when deactivating)
+// 2. Skip the write if the goal is already in the requested state (after the activation blockers, see ActivationBlockers)
+// 3. UPSERT goal progress with is_active status
+// 4. Update assigned_at only when activating (deactivating keeps it)
 //
 // Parameters:
 //   - ctx: Request context for cancellation and timeout
@@ -39,6 +53,7 @@ type SetGoalActiveResponse struct {
 //   - isActive: Whether to activate (true) or deactivate (false) the goal
 //   - goalCache: In-memory config cache for goal validation
 //   - repo: Database repository for persisting changes
+//   - logger: Receives the activation's log entries
 //
 // Returns:
 //   - SetGoalActiveResponse with updated status
@@ -46,6 +61,11 @@ type SetGoalActiveResponse struct {
 //
 // Error Cases:
 //   - Goal not found in config: returns ErrGoalNotFound
+//   - Progress row stored under another challenge: returns *mapper.ChallengeMismatchError
+//   - Activating a repeatable goal cooling down (see WithRepeatableGoals): returns
+//     *mapper.GoalCoolingDownError
+//   - Activating an inactive goal already claimed (see checkActivationClaimed):
+//     returns *mapper.GoalAlreadyClaimedError
 //   - Database error: returns wrapped error
 func SetGoalActive(
 	ctx context.Context,
@@ -56,6 +76,7 @@ func SetGoalActive(
 	isActive bool,
 	goalCache cache.GoalCache,
 	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
 ) (*SetGoalActiveResponse, error) {
 	// Early return validation
 	if userID == "" {
@@ -82,33 +103,91 @@ func SetGoalActive(
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
-	// 1. Validate goal exists in config
-	goal := goalCache.GetGoalByID(goalID)
-	if goal == nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": challengeID,
-			"goal_id":      goalID,
-			"namespace":    namespace,
-		}).Warn("Goal not found in config")
-		return nil, fmt.Errorf("goal '%s' not found in challenge '%s'", goalID, challengeID)
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	// 1-2. Validate the goal and load its row
+	goal, existing, err := loadGoalRow(ctx, userID, challengeID, goalID, namespace, goalCache, repo, logger)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify goal belongs to the specified challenge
-	if goal.ChallengeID != challengeID {
-		logrus.WithFields(logrus.Fields{
-			"user_id":             userID,
-			"requested_challenge": challengeID,
-			"actual_challenge":    goal.ChallengeID,
-			"goal_id":             goalID,
-			"namespace":           namespace,
-		}).Warn("Goal does not belong to specified challenge")
-		return nil, fmt.Errorf("goal '%s' does not belong to challenge '%s'", goalID, challengeID)
+	log := logger.WithFields(logrus.Fields{
+		"user_id":      userID,
+		"challenge_id": challengeID,
+		"goal_id":      goalID,
+		"namespace":    namespace,
+	})
+
+	// A repeatable goal cannot be activated until the cooldown of its last
+	// claim is over. A row still claimed is reset first.
+	if isActive {
+		progressMap := make(map[string]*domain.UserGoalProgress, 1)
+		if existing != nil {
+			progressMap[goalID] = existing
+		}
+		repeats, err := repeatableGoalRepeats(ctx, userID, []string{goalID}, progressMap)
+		if err != nil {
+			log.WithError(err).Error("Failed to get repeatable goal state")
+			return nil, err
+		}
+		existing = progressMap[goalID]
+
+		if err := coolingDownError(repeats, []string{goalID}, time.Now().UTC()); err != nil {
+			log.Info("Goal activation rejected: goal cooling down")
+			return nil, err
+		}
 	}
 
-	// 2. UPSERT goal progress
-	now := time.Now().UTC() // Always use UTC for consistency across timezones
-	progress := &domain.UserGoalProgress{
+	currentlyActive := existing != nil && existing.IsActive
+	if currentlyActive == isActive {
+		var assignedAt *time.Time
+		if existing != nil {
+			assignedAt = existing.AssignedAt
+		}
+
+		message := "Goal already inactive"
+		if isActive {
+			message = "Goal already active"
+		}
+
+		log.WithField("is_active", isActive).Debug("Goal active status unchanged, skipping write")
+
+		progress, status := displayedProgress(existing, goal)
+		return &SetGoalActiveResponse{
+			ChallengeID: challengeID,
+			GoalID:      goalID,
+			IsActive:    isActive,
+			AssignedAt:  assignedAt,
+			Message:     message,
+			Changed:     false,
+			Progress:    progress,
+			Status:      status,
+		}, nil
+	}
+
+	// A claimed goal stays claimed; activating it again would not let the
+	// player earn its reward twice
+	if isActive {
+		_, _, repeatable := repeatableGoalFrom(ctx, goalID)
+		if err := checkActivationClaimed(goal, existing, repeatable, time.Now().UTC()); err != nil {
+			log.Info("Goal activation rejected: goal claimed")
+			return nil, err
+		}
+	}
+
+	// 3. UPSERT goal progress. Only activation sets assigned_at; deactivation
+	// keeps the time the goal was last assigned.
+	var assignedAt *time.Time
+	if isActive {
+		now := time.Now().UTC() // Always use UTC for consistency across timezones
+		assignedAt = &now
+	} else {
+		// Deactivation is a transition, so the goal was active and has a row
+		assignedAt = existing.AssignedAt
+	}
+	row := &domain.UserGoalProgress{
 		UserID:      userID,
 		GoalID:      goalID,
 		ChallengeID: challengeID,
@@ -116,19 +195,12 @@ func SetGoalActive(
 		Progress:    0,
 		Status:      domain.GoalStatusNotStarted,
 		IsActive:    isActive,
-		AssignedAt:  &now,
+		AssignedAt:  assignedAt,
 	}
 
-	err := repo.UpsertGoalActive(ctx, progress)
+	err = repo.UpsertGoalActive(ctx, row)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"challenge_id": challengeID,
-			"goal_id":      goalID,
-			"namespace":    namespace,
-			"is_active":    isActive,
-			"error":        err,
-		}).Error("Failed to update goal active status")
+		log.WithField("is_active", isActive).WithError(err).Error("Failed to update goal active status")
 		return nil, fmt.Errorf("failed to update goal active status: %w", err)
 	}
 
@@ -139,19 +211,196 @@ func SetGoalActive(
 		message = "Goal deactivated successfully"
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.WithField("is_active", isActive).Info("Successfully updated goal active status")
+
+	// The upsert only toggles is_active and assigned_at, so progress is as read
+	progress, status := displayedProgress(existing, goal)
+	return &SetGoalActiveResponse{
+		ChallengeID: challengeID,
+		GoalID:      goalID,
+		IsActive:    isActive,
+		AssignedAt:  assignedAt,
+		Message:     message,
+		Changed:     true,
+		Progress:    progress,
+		Status:      status,
+	}, nil
+}
+
+// ResetGoalProgress deactivates a goal and discards its progress ("abandon
+// quest"), for SetGoalActive with reset_progress: progress 0, status
+// not_started and completed_at cleared (see GoalResetRepository). Activating
+// the goal again starts it over.
+//
+// Claimed goals are refused with *mapper.ProgressResetRefusedError: their
+// reward was granted. A goal without a row, or an inactive one with nothing to
+// reset, is left alone (Changed false). The other error cases are those of
+// SetGoalActive.
+//
+// logger receives the reset's entries.
+func ResetGoalProgress(
+	ctx context.Context,
+	userID string,
+	challengeID string,
+	goalID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	resets serviceRepo.GoalResetRepository,
+	logger logrus.FieldLogger,
+) (*SetGoalActiveResponse, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if challengeID == "" {
+		return nil, fmt.Errorf("challenge ID cannot be empty")
+	}
+
+	if goalID == "" {
+		return nil, fmt.Errorf("goal ID cannot be empty")
+	}
+
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	if goalCache == nil {
+		return nil, fmt.Errorf("goal cache cannot be nil")
+	}
+
+	if repo == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if resets == nil {
+		return nil, fmt.Errorf("goal reset repository cannot be nil")
+	}
+
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	_, existing, err := loadGoalRow(ctx, userID, challengeID, goalID, namespace, goalCache, repo, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.WithFields(logrus.Fields{
 		"user_id":      userID,
 		"challenge_id": challengeID,
 		"goal_id":      goalID,
 		"namespace":    namespace,
-		"is_active":    isActive,
-	}).Info("Successfully updated goal active status")
+	})
+
+	if existing != nil && existing.Status == domain.GoalStatusClaimed {
+		log.Info("Progress reset rejected: goal claimed")
+		return nil, &mapper.ProgressResetRefusedError{GoalID: goalID, ChallengeID: challengeID}
+	}
+
+	if existing == nil || (!existing.IsActive && existing.Progress == 0 && existing.Status == domain.GoalStatusNotStarted) {
+		var assignedAt *time.Time
+		if existing != nil {
+			assignedAt = existing.AssignedAt
+		}
+		log.Debug("Goal already inactive without progress, skipping reset")
+		return &SetGoalActiveResponse{
+			ChallengeID: challengeID,
+			GoalID:      goalID,
+			IsActive:    false,
+			AssignedAt:  assignedAt,
+			Message:     "Goal already inactive",
+			Changed:     false,
+			Progress:    0,
+			Status:      string(domain.GoalStatusNotStarted),
+		}, nil
+	}
+
+	written, err := resets.DeactivateAndReset(ctx, userID, goalID)
+	if err != nil {
+		log.WithError(err).Error("Failed to reset goal progress")
+		return nil, fmt.Errorf("failed to reset goal progress: %w", err)
+	}
+	// Only a claim committed since the read leaves no unclaimed row
+	if written == nil {
+		log.Info("Progress reset rejected: goal claimed concurrently")
+		return nil, &mapper.ProgressResetRefusedError{GoalID: goalID, ChallengeID: challengeID}
+	}
+
+	log.WithField("previous_progress", existing.Progress).Info("Deactivated goal and reset its progress")
 
 	return &SetGoalActiveResponse{
 		ChallengeID: challengeID,
 		GoalID:      goalID,
-		IsActive:    isActive,
-		AssignedAt:  &now,
-		Message:     message,
+		IsActive:    false,
+		AssignedAt:  written.AssignedAt,
+		Message:     "Goal deactivated and progress reset",
+		Changed:     true,
+		Progress:    written.Progress,
+		Status:      string(written.Status),
 	}, nil
 }
+
+// loadGoalRow validates that goalID is configured under challengeID and
+// returns it with the user's row (nil without one), refusing rows stored under
+// another challenge.
+func loadGoalRow(
+	ctx context.Context,
+	userID string,
+	challengeID string,
+	goalID string,
+	namespace string,
+	goalCache cache.GoalCache,
+	repo repository.GoalRepository,
+	logger logrus.FieldLogger,
+) (*domain.Goal, *domain.UserGoalProgress, error) {
+	goal, err := GetGoal(goalCache, challengeID, goalID)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"goal_id":      goalID,
+			"namespace":    namespace,
+			"error":        err,
+		}).Warn("Goal not found in challenge")
+		return nil, nil, err
+	}
+
+	existing, err := repo.GetProgress(ctx, userID, goalID)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":      userID,
+			"challenge_id": challengeID,
+			"goal_id":      goalID,
+			"namespace":    namespace,
+			"error":        err,
+		}).Error("Failed to get goal progress")
+		return nil, nil, fmt.Errorf("failed to get goal progress: %w", err)
+	}
+
+	// Refuse rows left under another challenge by a config reorganization
+	if existing != nil {
+		if err := checkProgressChallenge(goalID, challengeID, existing); err != nil {
+			logger.WithFields(logrus.Fields{
+				"user_id":             userID,
+				"challenge_id":        challengeID,
+				"stored_challenge_id": existing.ChallengeID,
+				"goal_id":             goalID,
+				"namespace":           namespace,
+			}).Warn("Progress row stored under another challenge")
+			return nil, nil, err
+		}
+	}
+
+	return goal, existing, nil
+}
+
+// displayedProgress returns the progress and status of a goal's row as listed
+// to the player; 0 and not_started without a row.
+func displayedProgress(existing *domain.UserGoalProgress, goal *domain.Goal) (int, string) {
+	if existing == nil {
+		return 0, string(domain.GoalStatusNotStarted)
+	}
+	progress, status, _ := rotation.ApplyDisplayRotation(existing, goal, time.Now().UTC())
+	return progress, string(status)
+}
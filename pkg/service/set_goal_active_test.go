@@ -6,16 +6,18 @@ import (
 	"testing"
 	"time"
 
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/testutil/mocks"
+
 	"github.com/AccelByte/extend-challenge-common/pkg/domain"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// NOTE: MockGoalCache and MockGoalRepository are defined in progress_query_test.go
-// to avoid duplication across test files.
-
 // Test SetGoalActive - Happy Path: Activate Goal (Creates Row)
 func TestSetGoalActive_ActivateGoal_Success(t *testing.T) {
 	ctx := context.Background()
@@ -46,10 +48,11 @@ func TestSetGoalActive_ActivateGoal_Success(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(nil, nil) // No existing progress
 
 	// Expect UpsertGoalActive call
 	mockRepo.On("UpsertGoalActive", ctx, mock.MatchedBy(func(progress *domain.UserGoalProgress) bool {
@@ -65,7 +68,7 @@ func TestSetGoalActive_ActivateGoal_Success(t *testing.T) {
 	})).Return(nil)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.NoError(t, err)
@@ -73,6 +76,7 @@ func TestSetGoalActive_ActivateGoal_Success(t *testing.T) {
 	assert.Equal(t, challengeID, result.ChallengeID)
 	assert.Equal(t, goalID, result.GoalID)
 	assert.True(t, result.IsActive)
+	assert.True(t, result.Changed)
 	assert.NotNil(t, result.AssignedAt)
 	assert.Equal(t, "Goal activated successfully", result.Message)
 
@@ -98,21 +102,30 @@ func TestSetGoalActive_DeactivateGoal_Success(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
+	assignedAt := time.Date(2025, 11, 1, 8, 0, 0, 0, time.UTC)
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(&domain.UserGoalProgress{
+		UserID:      userID,
+		GoalID:      goalID,
+		ChallengeID: challengeID,
+		IsActive:    true,
+		AssignedAt:  &assignedAt,
+	}, nil)
 
-	// Expect UpsertGoalActive call with is_active=false
+	// Expect UpsertGoalActive call with is_active=false, keeping assigned_at
 	mockRepo.On("UpsertGoalActive", ctx, mock.MatchedBy(func(progress *domain.UserGoalProgress) bool {
 		assert.Equal(t, userID, progress.UserID)
 		assert.Equal(t, goalID, progress.GoalID)
 		assert.False(t, progress.IsActive)
+		assert.Equal(t, &assignedAt, progress.AssignedAt)
 		return true
 	})).Return(nil)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.NoError(t, err)
@@ -121,6 +134,8 @@ func TestSetGoalActive_DeactivateGoal_Success(t *testing.T) {
 	assert.Equal(t, goalID, result.GoalID)
 	assert.False(t, result.IsActive)
 	assert.Equal(t, "Goal deactivated successfully", result.Message)
+	assert.True(t, result.Changed)
+	assert.Equal(t, &assignedAt, result.AssignedAt, "deactivation keeps the original assignment time")
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -141,26 +156,120 @@ func TestSetGoalActive_ActivateAlreadyActive_Idempotent(t *testing.T) {
 		Name:        "Test Goal",
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
+	assignedAt := time.Now().UTC().Add(-time.Hour)
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
-	mockRepo.On("UpsertGoalActive", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(&domain.UserGoalProgress{
+		UserID:      userID,
+		GoalID:      goalID,
+		ChallengeID: challengeID,
+		IsActive:    true,
+		AssignedAt:  &assignedAt,
+	}, nil)
 
 	// Call function twice
-	result1, err1 := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
-	result2, err2 := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result1, err1 := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
+	result2, err2 := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
-	// Both calls should succeed
+	// Both calls should succeed without writing (goal already active)
 	require.NoError(t, err1)
 	require.NoError(t, err2)
 	assert.True(t, result1.IsActive)
 	assert.True(t, result2.IsActive)
+	assert.False(t, result1.Changed)
+	assert.False(t, result2.Changed)
+	assert.Equal(t, "Goal already active", result1.Message)
+	assert.Equal(t, &assignedAt, result1.AssignedAt)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
 
+// Test SetGoalActive - Deactivate goal without progress row (no-op)
+func TestSetGoalActive_DeactivateWithoutProgress_NoWrite(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	challengeID := "challenge1"
+	goalID := "goal1"
+	namespace := "test-namespace"
+
+	mockGoal := &domain.Goal{
+		ID:          goalID,
+		ChallengeID: challengeID,
+		Name:        "Test Goal",
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(nil, nil)
+
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, false, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsActive)
+	assert.False(t, result.Changed)
+	assert.Nil(t, result.AssignedAt)
+	assert.Equal(t, "Goal already inactive", result.Message)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
+
+// Test SetGoalActive - Progress row stored under another challenge
+func TestSetGoalActive_StoredChallengeMismatch(t *testing.T) {
+	ctx := context.Background()
+	mockGoal := &domain.Goal{ID: "goal1", ChallengeID: "challenge1", Name: "Test Goal"}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", "goal1").Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "old-challenge",
+	}, nil)
+
+	result, err := SetGoalActive(ctx, "user123", "challenge1", "goal1", "test-namespace", true, mockCache, mockRepo, logrus.StandardLogger())
+
+	assert.Nil(t, result)
+	var mismatch *mapper.ChallengeMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, "old-challenge", mismatch.StoredChallengeID)
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
+
+// Test SetGoalActive - Progress lookup fails
+func TestSetGoalActive_GetProgressError(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	challengeID := "challenge1"
+	goalID := "goal1"
+	namespace := "test-namespace"
+
+	mockGoal := &domain.Goal{
+		ID:          goalID,
+		ChallengeID: challengeID,
+		Name:        "Test Goal",
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(nil, errors.New("connection refused"))
+
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, true, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "failed to get goal progress")
+	mockRepo.AssertNotCalled(t, "UpsertGoalActive", mock.Anything, mock.Anything)
+}
+
 // Test SetGoalActive - Invalid Goal ID (404 Error)
 func TestSetGoalActive_InvalidGoalID_Error(t *testing.T) {
 	ctx := context.Background()
@@ -170,14 +279,14 @@ func TestSetGoalActive_InvalidGoalID_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Goal not found in cache
 	mockCache.On("GetGoalByID", goalID).Return((*domain.Goal)(nil))
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -204,13 +313,13 @@ func TestSetGoalActive_WrongChallenge_Error(t *testing.T) {
 		Name:        "Test Goal",
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -236,19 +345,24 @@ func TestSetGoalActive_DatabaseError(t *testing.T) {
 		Name:        "Test Goal",
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(nil, nil) // No existing progress
 	mockRepo.On("UpsertGoalActive", ctx, mock.Anything).Return(errors.New("database error"))
+	logger, hook := logtest.NewNullLogger()
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logger)
 
 	// Assertions
 	require.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to update goal active status")
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Failed to update goal active status", hook.LastEntry().Message)
+	assert.Equal(t, goalID, hook.LastEntry().Data["goal_id"])
 
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -263,11 +377,11 @@ func TestSetGoalActive_EmptyUserID_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -287,11 +401,11 @@ func TestSetGoalActive_EmptyChallengeID_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -308,11 +422,11 @@ func TestSetGoalActive_EmptyGoalID_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -329,11 +443,11 @@ func TestSetGoalActive_EmptyNamespace_Error(t *testing.T) {
 	namespace := ""
 	isActive := true
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Call function
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -350,10 +464,10 @@ func TestSetGoalActive_NilGoalCache_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Call function with nil cache
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, nil, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, nil, mockRepo, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -370,10 +484,10 @@ func TestSetGoalActive_NilRepository_Error(t *testing.T) {
 	namespace := "test-namespace"
 	isActive := true
 
-	mockCache := new(MockGoalCache)
+	mockCache := new(mocks.GoalCache)
 
 	// Call function with nil repo
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, nil)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, nil, logrus.StandardLogger())
 
 	// Assertions
 	require.Error(t, err)
@@ -381,6 +495,19 @@ func TestSetGoalActive_NilRepository_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "repository cannot be nil")
 }
 
+// Test SetGoalActive - Nil Logger Validation
+func TestSetGoalActive_NilLogger_Error(t *testing.T) {
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	result, err := SetGoalActive(context.Background(), "user123", "challenge1", "goal1", "test-namespace", true, mockCache, mockRepo, nil)
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "logger cannot be nil")
+	mockRepo.AssertNotCalled(t, "GetProgress", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // Test SetGoalActive - AssignedAt Timestamp is Set
 func TestSetGoalActive_AssignedAtTimestamp(t *testing.T) {
 	ctx := context.Background()
@@ -396,10 +523,11 @@ func TestSetGoalActive_AssignedAtTimestamp(t *testing.T) {
 		Name:        "Test Goal",
 	}
 
-	mockCache := new(MockGoalCache)
-	mockRepo := new(MockGoalRepository)
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
 
 	mockCache.On("GetGoalByID", goalID).Return(mockGoal)
+	mockRepo.On("GetProgress", ctx, userID, goalID).Return(nil, nil) // No existing progress
 
 	var capturedAssignedAt *time.Time
 	mockRepo.On("UpsertGoalActive", ctx, mock.MatchedBy(func(progress *domain.UserGoalProgress) bool {
@@ -408,7 +536,7 @@ func TestSetGoalActive_AssignedAtTimestamp(t *testing.T) {
 	})).Return(nil)
 
 	before := time.Now()
-	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo)
+	result, err := SetGoalActive(ctx, userID, challengeID, goalID, namespace, isActive, mockCache, mockRepo, logrus.StandardLogger())
 	after := time.Now()
 
 	// Assertions
@@ -423,3 +551,182 @@ func TestSetGoalActive_AssignedAtTimestamp(t *testing.T) {
 	mockCache.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestSetGoalActive_DeactivateKeepsProgressInResponse(t *testing.T) {
+	ctx := context.Background()
+	goal := &domain.Goal{ID: "goal1", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 10}}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(goal)
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    4,
+		Status:      domain.GoalStatusInProgress,
+		IsActive:    true,
+	}, nil)
+	mockRepo.On("UpsertGoalActive", ctx, mock.Anything).Return(nil)
+
+	result, err := SetGoalActive(ctx, "user123", "challenge1", "goal1", "test-namespace", false, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.Equal(t, 4, result.Progress, "progress is preserved by default")
+	assert.Equal(t, string(domain.GoalStatusInProgress), result.Status)
+}
+
+// resetTestGoal is the goal of the ResetGoalProgress tests.
+func resetTestGoal() *domain.Goal {
+	return &domain.Goal{ID: "goal1", ChallengeID: "challenge1", Requirement: domain.Requirement{TargetValue: 10}}
+}
+
+func TestResetGoalProgress_AbandonThenReactivate(t *testing.T) {
+	ctx := context.Background()
+	assignedAt := time.Date(2025, 11, 1, 8, 0, 0, 0, time.UTC)
+	completedAt := assignedAt.Add(time.Hour)
+	completed := &domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    10,
+		Status:      domain.GoalStatusCompleted,
+		CompletedAt: &completedAt,
+		IsActive:    true,
+		AssignedAt:  &assignedAt,
+	}
+	reset := &domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    0,
+		Status:      domain.GoalStatusNotStarted,
+		IsActive:    false,
+		AssignedAt:  &assignedAt,
+	}
+
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	resets := new(mocks.GoalResetRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(resetTestGoal())
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(completed, nil).Once()
+	resets.On("DeactivateAndReset", ctx, "user123", "goal1").Return(reset, nil).Once()
+
+	abandoned, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, abandoned.Changed)
+	assert.False(t, abandoned.IsActive)
+	assert.Equal(t, 0, abandoned.Progress)
+	assert.Equal(t, string(domain.GoalStatusNotStarted), abandoned.Status)
+	assert.Equal(t, "Goal deactivated and progress reset", abandoned.Message)
+
+	// Abandoning again has nothing left to reset
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(reset, nil).Once()
+	again, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+	require.NoError(t, err)
+	assert.False(t, again.Changed)
+
+	// Reactivating starts the goal over
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(reset, nil).Once()
+	mockRepo.On("UpsertGoalActive", ctx, mock.MatchedBy(func(progress *domain.UserGoalProgress) bool {
+		return progress.IsActive
+	})).Return(nil)
+	reactivated, err := SetGoalActive(ctx, "user123", "challenge1", "goal1", "test-namespace", true, mockCache, mockRepo, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.True(t, reactivated.Changed)
+	assert.True(t, reactivated.IsActive)
+	assert.Equal(t, 0, reactivated.Progress)
+	assert.Equal(t, string(domain.GoalStatusNotStarted), reactivated.Status)
+
+	mockRepo.AssertExpectations(t)
+	resets.AssertExpectations(t)
+}
+
+func TestResetGoalProgress_ClaimedGoalRefused(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	resets := new(mocks.GoalResetRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(resetTestGoal())
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    10,
+		Status:      domain.GoalStatusClaimed,
+		IsActive:    true,
+	}, nil)
+
+	_, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+
+	var refused *mapper.ProgressResetRefusedError
+	require.ErrorAs(t, err, &refused)
+	assert.Equal(t, "goal1", refused.GoalID)
+	resets.AssertNotCalled(t, "DeactivateAndReset", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResetGoalProgress_ClaimedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	resets := new(mocks.GoalResetRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(resetTestGoal())
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(&domain.UserGoalProgress{
+		UserID:      "user123",
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    10,
+		Status:      domain.GoalStatusCompleted,
+		IsActive:    true,
+	}, nil)
+	resets.On("DeactivateAndReset", ctx, "user123", "goal1").Return(nil, nil)
+
+	_, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+
+	var refused *mapper.ProgressResetRefusedError
+	assert.ErrorAs(t, err, &refused)
+}
+
+func TestResetGoalProgress_NoRow(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+	resets := new(mocks.GoalResetRepository)
+	mockCache.On("GetGoalByID", "goal1").Return(resetTestGoal())
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(nil, nil)
+
+	result, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.Equal(t, string(domain.GoalStatusNotStarted), result.Status)
+	resets.AssertNotCalled(t, "DeactivateAndReset", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResetGoalProgress_Errors(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(mocks.GoalCache)
+	mockRepo := new(mocks.GoalRepository)
+
+	_, err := ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, nil, logrus.StandardLogger())
+	assert.EqualError(t, err, "goal reset repository cannot be nil")
+
+	resets := new(mocks.GoalResetRepository)
+	_, err = ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, nil)
+	assert.EqualError(t, err, "logger cannot be nil")
+
+	mockCache.On("GetGoalByID", "goal1").Return(resetTestGoal())
+	mockRepo.On("GetProgress", ctx, "user123", "goal1").Return(&domain.UserGoalProgress{
+		GoalID:      "goal1",
+		ChallengeID: "challenge1",
+		Progress:    3,
+		Status:      domain.GoalStatusInProgress,
+	}, nil)
+	resets.On("DeactivateAndReset", ctx, "user123", "goal1").Return(nil, errors.New("db down"))
+
+	_, err = ResetGoalProgress(ctx, "user123", "challenge1", "goal1", "test-namespace", mockCache, mockRepo, resets, logrus.StandardLogger())
+	assert.ErrorContains(t, err, "failed to reset goal progress")
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/progressstate"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StatusRecomputeQueueSize is the most users a config reload queues for
+	// status recomputes.
+	StatusRecomputeQueueSize = 10000
+
+	// defaultStatusRecomputeReason is the audit reason of recomputes requested
+	// without one.
+	defaultStatusRecomputeReason = "recompute statuses"
+
+	// configReloadRecomputeReason is the audit reason of recomputes queued by a
+	// config reload.
+	configReloadRecomputeReason = "config reload lowered goal targets"
+)
+
+// StatusRecomputeRequest is a request to re-evaluate a user's goal statuses.
+type StatusRecomputeRequest struct {
+	UserID      string
+	ActorUserID string
+	// Reason is stored in the audit record of every changed row; empty uses a
+	// default reason.
+	Reason string
+	// Regress takes completed goals whose progress is below the target back to
+	// in_progress (not_started without progress). Without it statuses only move
+	// forward.
+	Regress bool
+	// ClientIP is the address the admin's request came from ("" when unknown).
+	ClientIP string
+}
+
+// StatusRecomputeResult is the outcome of StatusRecomputes.Recompute.
+type StatusRecomputeResult struct {
+	Transitions []serviceRepo.StatusTransition
+	// Completed counts the transitions to completed, Regressed the ones from it.
+	Completed int
+	Regressed int
+}
+
+// StatusRecomputes re-evaluates stored goal statuses against the goal targets
+// in the current config, e.g. after a target was lowered: progress rows are
+// only completed by progress events, so players past the new target stay
+// in_progress until their next event otherwise.
+//
+// A row is completed once its progress reaches the configured target, as
+// checked by reachesTarget. Rows stay as they are when their goal is no
+// longer in the config, has steps (multi-step goals complete with their steps),
+// has a non-positive target, is claimed, is from an ended rotation period, or
+// is inactive in a challenge that does not track inactive progress.
+//
+// Config reloads can queue the users a lowered target completes
+// (QueueLoweredTargets); Run recomputes them in the background. The queue is
+// in memory, so users still queued when the replica stops are dropped.
+type StatusRecomputes struct {
+	goalAdmin        serviceRepo.GoalAdminRepository
+	queries          serviceRepo.ProgressQueryRepository
+	goalCache        cache.GoalCache
+	steps            GoalSteps
+	policy           InactiveProgressPolicy
+	unclaimedCounts  *UnclaimedCounts
+	challengeMetrics *ChallengeMetrics
+	namespace        string
+	now              func() time.Time
+	queue            chan queuedStatusRecompute
+	logger           logrus.FieldLogger
+
+	transitions *prometheus.CounterVec
+}
+
+// queuedStatusRecompute is a user queued by a config reload.
+type queuedStatusRecompute struct {
+	userID      string
+	actorUserID string
+	clientIP    string
+}
+
+// NewStatusRecomputes creates status recomputes that read the current config
+// from goalCache and log through logger.
+func NewStatusRecomputes(
+	goalAdmin serviceRepo.GoalAdminRepository,
+	queries serviceRepo.ProgressQueryRepository,
+	goalCache cache.GoalCache,
+	steps GoalSteps,
+	policy InactiveProgressPolicy,
+	namespace string,
+	logger logrus.FieldLogger,
+) *StatusRecomputes {
+	return &StatusRecomputes{
+		goalAdmin: goalAdmin,
+		queries:   queries,
+		goalCache: goalCache,
+		steps:     steps,
+		policy:    policy,
+		namespace: namespace,
+		now:       func() time.Time { return time.Now().UTC() },
+		queue:     make(chan queuedStatusRecompute, StatusRecomputeQueueSize),
+		logger:    logger,
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "status_recompute_transitions_total",
+			Help: "Goal statuses changed by status recomputes, by new status.",
+		}, []string{"status"}),
+	}
+}
+
+// SetUnclaimedCounts sets the unclaimed count cache invalidated for users whose
+// statuses change. It must be called before the server starts serving.
+func (r *StatusRecomputes) SetUnclaimedCounts(unclaimedCounts *UnclaimedCounts) {
+	r.unclaimedCounts = unclaimedCounts
+}
+
+// SetChallengeMetrics sets the metrics that count the goals recomputes
+// complete. It must be called before the server starts serving.
+func (r *StatusRecomputes) SetChallengeMetrics(metrics *ChallengeMetrics) {
+	r.challengeMetrics = metrics
+}
+
+// Collectors returns the status recompute metrics for registration.
+func (r *StatusRecomputes) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{r.transitions}
+}
+
+// Recompute re-evaluates req.UserID's goal statuses and writes the ones that
+// change, each with an audit record, in one transaction.
+//
+// Error Cases:
+//   - Database error: returns mapper.ErrDatabaseError
+func (r *StatusRecomputes) Recompute(ctx context.Context, req StatusRecomputeRequest) (*StatusRecomputeResult, error) {
+	if req.UserID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if req.ActorUserID == "" {
+		return nil, fmt.Errorf("admin ID cannot be empty")
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = defaultStatusRecomputeReason
+	}
+
+	fields := logrus.Fields{
+		"user_id":        req.ActorUserID,
+		"target_user_id": req.UserID,
+		"namespace":      r.namespace,
+		"regress":        req.Regress,
+		"client_ip":      req.ClientIP,
+	}
+
+	now := r.now()
+	transitions, err := r.goalAdmin.RecomputeStatuses(ctx, &serviceRepo.StatusRecompute{
+		UserID:      req.UserID,
+		Namespace:   r.namespace,
+		ActorUserID: req.ActorUserID,
+		ClientIP:    req.ClientIP,
+		Reason:      reason,
+	}, func(row *domain.UserGoalProgress) domain.GoalStatus {
+		return r.recomputedStatus(row, req.Regress, now)
+	})
+	if err != nil {
+		r.logger.WithFields(fields).WithError(err).Error("Failed to recompute goal statuses")
+		return nil, mapper.ErrDatabaseError
+	}
+
+	result := &StatusRecomputeResult{Transitions: transitions}
+	for _, transition := range transitions {
+		if transition.To == domain.GoalStatusCompleted {
+			result.Completed++
+			r.challengeMetrics.GoalCompleted(transition.ChallengeID)
+		} else {
+			result.Regressed++
+		}
+		r.transitions.WithLabelValues(string(transition.To)).Inc()
+	}
+
+	if len(transitions) > 0 {
+		r.unclaimedCounts.Invalidate(req.UserID)
+		r.logger.WithFields(fields).WithFields(logrus.Fields{
+			"completed":       result.Completed,
+			"regressed":       result.Regressed,
+			"security_review": true,
+		}).Info("Admin recomputed goal statuses")
+	}
+
+	return result, nil
+}
+
+// recomputedStatus returns the status row should have under the current
+// config, or its current status when it is left as it is.
+func (r *StatusRecomputes) recomputedStatus(row *domain.UserGoalProgress, regress bool, now time.Time) domain.GoalStatus {
+	goal := r.goalCache.GetGoalByID(row.GoalID)
+	switch {
+	case goal == nil,
+		row.Status == domain.GoalStatusClaimed,
+		len(r.steps.Steps(goal.ID)) > 0,
+		goalTargetProblem(goal.Requirement.TargetValue) != "",
+		rotation.HasRotationOccurred(row, goal, now),
+		!row.IsActive && !r.policy.TracksInactive(goal.ChallengeID):
+		return row.Status
+	}
+
+	status := row.Status
+	reached := reachesTarget(row, goal, goal.Requirement.TargetValue)
+	switch {
+	case reached && row.Status != domain.GoalStatusCompleted:
+		status = domain.GoalStatusCompleted
+	case !reached && row.Status == domain.GoalStatusCompleted && regress:
+		status = domain.GoalStatusNotStarted
+		if rotation.CalculateDisplayedProgress(row, goal) > 0 {
+			status = domain.GoalStatusInProgress
+		}
+	}
+	if status == row.Status {
+		return status
+	}
+
+	if err := progressstate.Transition(row.Status, status, progressstate.Flags{Regress: regress}); err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"user_id": row.UserID,
+			"goal_id": row.GoalID,
+			"error":   err,
+		}).Warn("Skipped recomputed goal status")
+		return row.Status
+	}
+	return status
+}
+
+// QueueLoweredTargets queues the users whose unfinished progress reaches a goal
+// target the reload diff lowered, for Run to recompute on behalf of
+// actorUserID. It returns the number of users queued. Users that could not be
+// found or queued are reported as diff warnings; RecomputeUserStatuses can be
+// called for them.
+func (r *StatusRecomputes) QueueLoweredTargets(ctx context.Context, diff *ConfigDiff, actorUserID, clientIP string) int {
+	goalTargets := r.loweredTargets(diff)
+	if len(goalTargets) == 0 {
+		return 0
+	}
+
+	countCtx, cancel := context.WithTimeout(ctx, unclaimedCountTimeout)
+	defer cancel()
+
+	// One extra user tells whether more users are affected than can be queued
+	userIDs, err := r.queries.GetUsersReachingTargets(countCtx, r.namespace, goalTargets, StatusRecomputeQueueSize+1)
+	if err != nil {
+		r.logger.WithFields(logrus.Fields{
+			"namespace": r.namespace,
+			"error":     err,
+		}).Warn("Failed to find users for goals with lowered targets")
+		diff.Warnings = append(diff.Warnings, "target lowered; users to recompute could not be found")
+		return 0
+	}
+
+	queued := 0
+	for _, userID := range userIDs {
+		select {
+		case r.queue <- queuedStatusRecompute{userID: userID, actorUserID: actorUserID, clientIP: clientIP}:
+			queued++
+		default:
+		}
+	}
+	if queued < len(userIDs) {
+		diff.Warnings = append(diff.Warnings, fmt.Sprintf(
+			"target lowered; %d users queued for a status recompute, more are affected", queued))
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"namespace": r.namespace,
+		"user_id":   actorUserID,
+		"goal_ids":  len(goalTargets),
+		"queued":    queued,
+	}).Info("Queued status recomputes for lowered goal targets")
+
+	return queued
+}
+
+// loweredTargets returns the current target of each goal, not multi-step,
+// whose requirement.targetValue the diff lowered.
+func (r *StatusRecomputes) loweredTargets(diff *ConfigDiff) map[string]int {
+	goalTargets := make(map[string]int)
+	for _, change := range diff.GoalsModified {
+		for _, field := range change.Fields {
+			if field.Field != "requirement.targetValue" {
+				continue
+			}
+			oldTarget, oldErr := strconv.Atoi(field.Old)
+			newTarget, newErr := strconv.Atoi(field.New)
+			goal := r.goalCache.GetGoalByID(change.GoalID)
+			if oldErr != nil || newErr != nil || newTarget >= oldTarget || goal == nil || len(r.steps.Steps(goal.ID)) > 0 {
+				continue
+			}
+			goalTargets[goal.ID] = goal.Requirement.TargetValue
+		}
+	}
+	return goalTargets
+}
+
+// Run recomputes queued users until ctx is cancelled.
+func (r *StatusRecomputes) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-r.queue:
+			// Errors are logged by Recompute
+			_, _ = r.Recompute(ctx, StatusRecomputeRequest{
+				UserID:      queued.userID,
+				ActorUserID: queued.actorUserID,
+				Reason:      configReloadRecomputeReason,
+				ClientIP:    queued.clientIP,
+			})
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/mapper"
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var statusRecomputeNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestStatusRecomputes returns status recomputes over challenge c1 with the
+// goals g1 (target 5) and g2 (target 10).
+func newTestStatusRecomputes(goalAdmin *mocks.GoalAdminRepository, queries *mocks.ProgressQueryRepository) (*StatusRecomputes, *logtest.Hook) {
+	lowered := createClaimableGoal("g1", "c1")
+	lowered.Requirement.TargetValue = 5
+	raised := createClaimableGoal("g2", "c1")
+	goalCache := commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c1", Goals: []*domain.Goal{lowered, raised}},
+	}}, "", slog.Default())
+
+	logger, hook := logtest.NewNullLogger()
+	recomputes := NewStatusRecomputes(goalAdmin, queries, goalCache, GoalSteps{}, InactiveProgressPolicy{}, "test-namespace", logger)
+	recomputes.now = func() time.Time { return statusRecomputeNow }
+	return recomputes, hook
+}
+
+func statusRecomputeRow(goalID string, status domain.GoalStatus, progress int) *domain.UserGoalProgress {
+	row := createCompletedProgress("user-1", goalID, "c1")
+	row.Status = status
+	row.Progress = progress
+	return row
+}
+
+func TestStatusRecomputes_LoweredTargetCompletes(t *testing.T) {
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), new(mocks.ProgressQueryRepository))
+
+	assert.Equal(t, domain.GoalStatusCompleted,
+		recomputes.recomputedStatus(statusRecomputeRow("g1", domain.GoalStatusInProgress, 6), false, statusRecomputeNow))
+	assert.Equal(t, domain.GoalStatusCompleted,
+		recomputes.recomputedStatus(statusRecomputeRow("g1", domain.GoalStatusInProgress, 5), false, statusRecomputeNow))
+	assert.Equal(t, domain.GoalStatusInProgress,
+		recomputes.recomputedStatus(statusRecomputeRow("g1", domain.GoalStatusInProgress, 4), false, statusRecomputeNow))
+}
+
+func TestStatusRecomputes_RaisedTarget(t *testing.T) {
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), new(mocks.ProgressQueryRepository))
+
+	tests := map[string]struct {
+		progress int
+		regress  bool
+		want     domain.GoalStatus
+	}{
+		"kept without regress":             {progress: 6, regress: false, want: domain.GoalStatusCompleted},
+		"regressed with progress":          {progress: 6, regress: true, want: domain.GoalStatusInProgress},
+		"regressed without progress":       {progress: 0, regress: true, want: domain.GoalStatusNotStarted},
+		"kept at the new target":           {progress: 10, regress: true, want: domain.GoalStatusCompleted},
+		"kept past the new target":         {progress: 12, regress: true, want: domain.GoalStatusCompleted},
+		"kept without regress or progress": {progress: 0, regress: false, want: domain.GoalStatusCompleted},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			row := statusRecomputeRow("g2", domain.GoalStatusCompleted, tt.progress)
+			assert.Equal(t, tt.want, recomputes.recomputedStatus(row, tt.regress, statusRecomputeNow))
+		})
+	}
+}
+
+func TestStatusRecomputes_RowsLeftAsTheyAre(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	recomputes, _ := newTestStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository))
+	recomputes.steps = GoalSteps{"g1": {{TargetValue: 1}, {TargetValue: 2}}}
+	recomputes.policy = InactiveProgressPolicy{"c1": true}
+
+	claimed := statusRecomputeRow("g2", domain.GoalStatusClaimed, 2)
+	unknown := statusRecomputeRow("removed", domain.GoalStatusInProgress, 50)
+	multiStep := statusRecomputeRow("g1", domain.GoalStatusInProgress, 6)
+	inactive := statusRecomputeRow("g2", domain.GoalStatusInProgress, 12)
+	inactive.IsActive = false
+
+	for _, row := range []*domain.UserGoalProgress{claimed, unknown, multiStep, inactive} {
+		assert.Equal(t, row.Status, recomputes.recomputedStatus(row, true, statusRecomputeNow), row.GoalID)
+	}
+}
+
+func TestStatusRecomputes_Recompute(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	recomputes, hook := newTestStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository))
+
+	rows := []*domain.UserGoalProgress{
+		statusRecomputeRow("g1", domain.GoalStatusInProgress, 6),
+		statusRecomputeRow("g2", domain.GoalStatusCompleted, 6),
+		statusRecomputeRow("g2", domain.GoalStatusClaimed, 1),
+	}
+	transitions := []repository.StatusTransition{
+		{GoalID: "g1", ChallengeID: "c1", From: domain.GoalStatusInProgress, To: domain.GoalStatusCompleted, Progress: 6},
+		{GoalID: "g2", ChallengeID: "c1", From: domain.GoalStatusCompleted, To: domain.GoalStatusInProgress, Progress: 6},
+	}
+
+	var decided []domain.GoalStatus
+	goalAdmin.On("RecomputeStatuses", mock.Anything, &repository.StatusRecompute{
+		UserID:      "user-1",
+		Namespace:   "test-namespace",
+		ActorUserID: "admin-1",
+		ClientIP:    "10.0.0.1",
+		Reason:      defaultStatusRecomputeReason,
+	}, mock.Anything).Run(func(args mock.Arguments) {
+		decide := args.Get(2).(func(*domain.UserGoalProgress) domain.GoalStatus)
+		for _, row := range rows {
+			decided = append(decided, decide(row))
+		}
+	}).Return(transitions, nil)
+
+	result, err := recomputes.Recompute(context.Background(), StatusRecomputeRequest{
+		UserID:      "user-1",
+		ActorUserID: "admin-1",
+		Regress:     true,
+		ClientIP:    "10.0.0.1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.GoalStatus{domain.GoalStatusCompleted, domain.GoalStatusInProgress, domain.GoalStatusClaimed}, decided)
+	assert.Equal(t, transitions, result.Transitions)
+	assert.Equal(t, 1, result.Completed)
+	assert.Equal(t, 1, result.Regressed)
+	assert.Equal(t, 1.0, testutil.ToFloat64(recomputes.transitions.WithLabelValues("completed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recomputes.transitions.WithLabelValues("in_progress")))
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "Admin recomputed goal statuses", entry.Message)
+	assert.Equal(t, true, entry.Data["security_review"])
+	assert.Equal(t, "user-1", entry.Data["target_user_id"])
+}
+
+func TestStatusRecomputes_RecomputeWithoutChanges(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	recomputes, hook := newTestStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository))
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	result, err := recomputes.Recompute(context.Background(), StatusRecomputeRequest{UserID: "user-1", ActorUserID: "admin-1", Reason: "support ticket"})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Transitions)
+	assert.Empty(t, hook.AllEntries())
+	goalAdmin.AssertCalled(t, "RecomputeStatuses", mock.Anything,
+		mock.MatchedBy(func(recompute *repository.StatusRecompute) bool { return recompute.Reason == "support ticket" }), mock.Anything)
+}
+
+func TestStatusRecomputes_RecomputeDatabaseError(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	recomputes, hook := newTestStatusRecomputes(goalAdmin, new(mocks.ProgressQueryRepository))
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	_, err := recomputes.Recompute(context.Background(), StatusRecomputeRequest{UserID: "user-1", ActorUserID: "admin-1"})
+
+	assert.ErrorIs(t, err, mapper.ErrDatabaseError)
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+}
+
+func TestStatusRecomputes_RecomputeValidation(t *testing.T) {
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), new(mocks.ProgressQueryRepository))
+
+	_, err := recomputes.Recompute(context.Background(), StatusRecomputeRequest{ActorUserID: "admin-1"})
+	assert.Error(t, err)
+
+	_, err = recomputes.Recompute(context.Background(), StatusRecomputeRequest{UserID: "user-1"})
+	assert.Error(t, err)
+}
+
+// loweredTargetDiff is a reload diff that lowered g1's target from 8 to 5 and
+// raised g2's from 6 to 10.
+func loweredTargetDiff() *ConfigDiff {
+	return &ConfigDiff{GoalsModified: []GoalChange{
+		{GoalID: "g1", ChallengeID: "c1", Fields: []FieldChange{{Field: "requirement.targetValue", Old: "8", New: "5"}}},
+		{GoalID: "g2", ChallengeID: "c1", Fields: []FieldChange{{Field: "requirement.targetValue", Old: "6", New: "10"}}},
+	}}
+}
+
+func TestStatusRecomputes_QueueLoweredTargets(t *testing.T) {
+	goalAdmin := new(mocks.GoalAdminRepository)
+	queries := new(mocks.ProgressQueryRepository)
+	recomputes, _ := newTestStatusRecomputes(goalAdmin, queries)
+	queries.On("GetUsersReachingTargets", mock.Anything, "test-namespace", map[string]int{"g1": 5}, StatusRecomputeQueueSize+1).
+		Return([]string{"user-1", "user-2"}, nil)
+
+	diff := loweredTargetDiff()
+	queued := recomputes.QueueLoweredTargets(context.Background(), diff, "admin-1", "10.0.0.1")
+
+	assert.Equal(t, 2, queued)
+	assert.Empty(t, diff.Warnings)
+
+	recomputed := make(chan string, 2)
+	goalAdmin.On("RecomputeStatuses", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recompute := args.Get(1).(*repository.StatusRecompute)
+		assert.Equal(t, "admin-1", recompute.ActorUserID)
+		assert.Equal(t, "10.0.0.1", recompute.ClientIP)
+		assert.Equal(t, configReloadRecomputeReason, recompute.Reason)
+		recomputed <- recompute.UserID
+	}).Return(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go recomputes.Run(ctx)
+
+	assert.Equal(t, "user-1", <-recomputed)
+	assert.Equal(t, "user-2", <-recomputed)
+}
+
+func TestStatusRecomputes_QueueLoweredTargetsWithoutLoweredTargets(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), queries)
+
+	diff := &ConfigDiff{GoalsModified: []GoalChange{
+		{GoalID: "g2", ChallengeID: "c1", Fields: []FieldChange{{Field: "requirement.targetValue", Old: "6", New: "10"}}},
+		{GoalID: "g1", ChallengeID: "c1", Fields: []FieldChange{{Field: "name", Old: "Old", New: "New"}}},
+	}}
+
+	assert.Equal(t, 0, recomputes.QueueLoweredTargets(context.Background(), diff, "admin-1", ""))
+	queries.AssertNotCalled(t, "GetUsersReachingTargets", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatusRecomputes_QueueLoweredTargetsSkipsMultiStepGoals(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), queries)
+	recomputes.steps = GoalSteps{"g1": {{TargetValue: 1}, {TargetValue: 2}}}
+
+	assert.Equal(t, 0, recomputes.QueueLoweredTargets(context.Background(), loweredTargetDiff(), "admin-1", ""))
+	queries.AssertNotCalled(t, "GetUsersReachingTargets", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatusRecomputes_QueueLoweredTargetsQueryError(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	recomputes, hook := newTestStatusRecomputes(new(mocks.GoalAdminRepository), queries)
+	queries.On("GetUsersReachingTargets", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("timeout"))
+
+	diff := loweredTargetDiff()
+	assert.Equal(t, 0, recomputes.QueueLoweredTargets(context.Background(), diff, "admin-1", ""))
+	assert.Equal(t, []string{"target lowered; users to recompute could not be found"}, diff.Warnings)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+}
+
+func TestStatusRecomputes_QueueLoweredTargetsFullQueue(t *testing.T) {
+	queries := new(mocks.ProgressQueryRepository)
+	recomputes, _ := newTestStatusRecomputes(new(mocks.GoalAdminRepository), queries)
+	recomputes.queue = make(chan queuedStatusRecompute, 1)
+	queries.On("GetUsersReachingTargets", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"user-1", "user-2"}, nil)
+
+	diff := loweredTargetDiff()
+	assert.Equal(t, 1, recomputes.QueueLoweredTargets(context.Background(), diff, "admin-1", ""))
+	assert.Equal(t, []string{"target lowered; 1 users queued for a status recompute, more are affected"}, diff.Warnings)
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+	"github.com/AccelByte/extend-challenge-common/pkg/rotation"
+)
+
+// TargetOverrides holds the per-segment targets configured with "targetOverrides"
+// on goals in the challenge config, e.g. {"new_player": 1} on a goal whose
+// requirement targets 5. It maps goal ID -> segment -> target.
+//
+// The player's segment comes from a common.SegmentResolver. Players without a
+// segment, or in a segment the goal does not override, get the configured
+// requirement target. A nil TargetOverrides overrides nothing.
+//
+// domain.Goal (extend-challenge-common) has no such field, so the overrides are
+// read from the config file separately by LoadTargetOverrides.
+type TargetOverrides map[string]map[string]int
+
+// targetOverridesConfig is the subset of challenges.json needed to read the overrides.
+type targetOverridesConfig struct {
+	Challenges []struct {
+		Goals []struct {
+			ID              string         `json:"goalId"`
+			TargetOverrides map[string]int `json:"targetOverrides"`
+		} `json:"goals"`
+	} `json:"challenges"`
+}
+
+// LoadTargetOverrides reads the per-segment goal targets from the challenge config file.
+func LoadTargetOverrides(configPath string) (TargetOverrides, error) {
+	data, err := os.ReadFile(configPath) // #nosec G304 - path comes from service configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge config: %w", err)
+	}
+
+	return ParseTargetOverrides(data)
+}
+
+// ParseTargetOverrides extracts the per-segment goal targets from challenge config
+// JSON. Segment names must be non-empty and targets positive.
+func ParseTargetOverrides(data []byte) (TargetOverrides, error) {
+	var cfg targetOverridesConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse challenge config: %w", err)
+	}
+
+	overrides := make(TargetOverrides)
+	for _, challenge := range cfg.Challenges {
+		for _, goal := range challenge.Goals {
+			for segment, target := range goal.TargetOverrides {
+				if segment == "" {
+					return nil, fmt.Errorf("goal %s: target override with an empty segment name", goal.ID)
+				}
+				if target <= 0 {
+					return nil, fmt.Errorf("goal %s: target override for segment %q must be positive, got %d", goal.ID, segment, target)
+				}
+			}
+			if len(goal.TargetOverrides) > 0 {
+				overrides[goal.ID] = goal.TargetOverrides
+			}
+		}
+	}
+
+	return overrides, nil
+}
+
+// For returns the targets seen by players of segment. An empty segment gets
+// the configured targets.
+func (t TargetOverrides) For(segment string) SegmentTargets {
+	return SegmentTargets{overrides: t, segment: segment}
+}
+
+// SegmentTargets resolves goal targets for one player segment. The zero value
+// uses the configured targets.
+type SegmentTargets struct {
+	overrides TargetOverrides
+	segment   string
+}
+
+// Segment returns the segment the targets are resolved for.
+func (s SegmentTargets) Segment() string {
+	return s.segment
+}
+
+// override returns the segment's target for the goal, if it has one.
+func (s SegmentTargets) override(goalID string) (int, bool) {
+	if s.segment == "" {
+		return 0, false
+	}
+	target, ok := s.overrides[goalID][s.segment]
+	return target, ok
+}
+
+// Target returns the goal's target for the segment.
+func (s SegmentTargets) Target(goal *domain.Goal) int {
+	if target, ok := s.override(goal.ID); ok {
+		return target
+	}
+	return goal.Requirement.TargetValue
+}
+
+// Goal returns the goal with the segment's target. The original goal is
+// returned when it has no override for the segment, otherwise a shallow copy.
+func (s SegmentTargets) Goal(goal *domain.Goal) *domain.Goal {
+	if goal == nil {
+		return nil
+	}
+	target, ok := s.override(goal.ID)
+	if !ok || target == goal.Requirement.TargetValue {
+		return goal
+	}
+
+	overridden := *goal
+	overridden.Requirement.TargetValue = target
+	return &overridden
+}
+
+// Challenge returns the challenge with the segment's goal targets. The original
+// challenge is returned when none of its goals is overridden, otherwise a
+// shallow copy with its own goal slice.
+func (s SegmentTargets) Challenge(challenge *domain.Challenge) *domain.Challenge {
+	if challenge == nil || s.segment == "" || len(s.overrides) == 0 {
+		return challenge
+	}
+
+	var goals []*domain.Goal
+	for i, goal := range challenge.Goals {
+		overridden := s.Goal(goal)
+		if overridden == goal && goals == nil {
+			continue
+		}
+		if goals == nil {
+			goals = make([]*domain.Goal, len(challenge.Goals))
+			copy(goals, challenge.Goals[:i])
+		}
+		goals[i] = overridden
+	}
+
+	if goals == nil {
+		return challenge
+	}
+
+	withTargets := *challenge
+	withTargets.Goals = goals
+	return &withTargets
+}
+
+// Evaluate returns the progress row as the segment sees it. Rows are stored
+// against the configured target, so a row still in progress whose progress
+// reaches the segment's lower target is returned as a completed copy.
+//
+// Completed and claimed rows are returned unchanged: a segment target above the
+// configured one never uncompletes a row.
+func (s SegmentTargets) Evaluate(row *domain.UserGoalProgress, goal *domain.Goal) *domain.UserGoalProgress {
+	if row == nil || goal == nil || row.IsCompleted() {
+		return row
+	}
+
+	target, ok := s.override(goal.ID)
+	if !ok || !reachesTarget(row, goal, target) {
+		return row
+	}
+
+	completed := *row
+	completed.Status = domain.GoalStatusCompleted
+	if completed.CompletedAt == nil {
+		// The progress reached the target at the latest when the row was last updated
+		completedAt := row.UpdatedAt
+		completed.CompletedAt = &completedAt
+	}
+	return &completed
+}
+
+// reachesTarget reports whether the row's progress completes the goal at
+// target: its displayed progress, less the baseline of a relative goal, is at
+// least target. It is the completion check applied to stored progress when a
+// goal's target differs from the one the row was written against.
+func reachesTarget(row *domain.UserGoalProgress, goal *domain.Goal, target int) bool {
+	return rotation.CalculateDisplayedProgress(row, goal) >= target
+}
+
+// EvaluateAll returns progressMap with every row passed through Evaluate.
+// progressMap itself is not modified and is returned as-is when no row changes.
+func (s SegmentTargets) EvaluateAll(
+	progressMap map[string]*domain.UserGoalProgress,
+	goalCache cache.GoalCache,
+) map[string]*domain.UserGoalProgress {
+	if s.segment == "" || len(s.overrides) == 0 || goalCache == nil {
+		return progressMap
+	}
+
+	var evaluated map[string]*domain.UserGoalProgress
+	for goalID, row := range progressMap {
+		if _, ok := s.override(goalID); !ok {
+			continue
+		}
+		updated := s.Evaluate(row, goalCache.GetGoalByID(goalID))
+		if updated == row {
+			continue
+		}
+		if evaluated == nil {
+			evaluated = make(map[string]*domain.UserGoalProgress, len(progressMap))
+			for id, existing := range progressMap {
+				evaluated[id] = existing
+			}
+		}
+		evaluated[goalID] = updated
+	}
+
+	if evaluated == nil {
+		return progressMap
+	}
+	return evaluated
+}
+
+// ApplyToAssignedGoals sets the segment's targets on goals returned by
+// InitializePlayer, completing the status of those whose progress reaches them.
+func (s SegmentTargets) ApplyToAssignedGoals(goals []*AssignedGoal) {
+	for _, goal := range goals {
+		if goal != nil {
+			s.applyTarget(goal.GoalID, goal.Progress, &goal.Target, &goal.Requirement, &goal.Status)
+		}
+	}
+}
+
+// ApplyToSelectedGoals sets the segment's targets on goals returned by goal
+// selection, completing the status of those whose progress reaches them.
+func (s SegmentTargets) ApplyToSelectedGoals(goals []*SelectedGoalInfo) {
+	for _, goal := range goals {
+		if goal != nil {
+			s.applyTarget(goal.GoalID, goal.Progress, &goal.Target, &goal.Requirement, &goal.Status)
+		}
+	}
+}
+
+// applyTarget overrides the target fields of one response goal. progress is the
+// displayed progress; completed and claimed statuses are kept as they are.
+func (s SegmentTargets) applyTarget(goalID string, progress int, target *int, requirement *domain.Requirement, status *string) {
+	override, ok := s.override(goalID)
+	if !ok {
+		return
+	}
+
+	*target = override
+	requirement.TargetValue = override
+	notCompleted := *status == string(domain.GoalStatusNotStarted) || *status == string(domain.GoalStatusInProgress)
+	if notCompleted && progress >= override {
+		*status = string(domain.GoalStatusCompleted)
+	}
+}
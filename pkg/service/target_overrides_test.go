@@ -0,0 +1,129 @@
+package service
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	"github.com/AccelByte/extend-challenge-common/pkg/config"
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetOverrides(t *testing.T) {
+	overrides, err := ParseTargetOverrides([]byte(`{"challenges": [{"id": "c1", "goals": [
+		{"goalId": "g1", "targetOverrides": {"new_player": 1, "veteran": 8}},
+		{"goalId": "g2"}
+	]}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, TargetOverrides{"g1": {"new_player": 1, "veteran": 8}}, overrides)
+}
+
+func TestParseTargetOverrides_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty segment":   `{"challenges": [{"goals": [{"goalId": "g1", "targetOverrides": {"": 1}}]}]}`,
+		"zero target":     `{"challenges": [{"goals": [{"goalId": "g1", "targetOverrides": {"new_player": 0}}]}]}`,
+		"negative target": `{"challenges": [{"goals": [{"goalId": "g1", "targetOverrides": {"new_player": -2}}]}]}`,
+		"malformed":       `{"challenges": [`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTargetOverrides([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSegmentTargets_TwoSegmentsSeeDifferentTargets(t *testing.T) {
+	goal := createClaimableGoal("g1", "c1")
+	other := createClaimableGoal("g2", "c1")
+	challenge := &domain.Challenge{ID: "c1", Goals: []*domain.Goal{goal, other}}
+	overrides := TargetOverrides{"g1": {"new_player": 3, "veteran": 20}}
+
+	newPlayer := overrides.For("new_player")
+	veteran := overrides.For("veteran")
+
+	assert.Equal(t, 3, newPlayer.Target(goal))
+	assert.Equal(t, 20, veteran.Target(goal))
+	assert.Equal(t, 10, overrides.For("").Target(goal))
+	assert.Equal(t, 10, newPlayer.Target(other))
+
+	assert.Equal(t, 3, newPlayer.Challenge(challenge).Goals[0].Requirement.TargetValue)
+	assert.Equal(t, 20, veteran.Challenge(challenge).Goals[0].Requirement.TargetValue)
+	assert.Same(t, other, newPlayer.Challenge(challenge).Goals[1])
+	assert.Same(t, challenge, overrides.For("").Challenge(challenge))
+	assert.Equal(t, 10, goal.Requirement.TargetValue, "the cached goal is not modified")
+}
+
+func TestSegmentTargets_Evaluate(t *testing.T) {
+	goal := createClaimableGoal("g1", "c1")
+	overrides := TargetOverrides{"g1": {"new_player": 3, "veteran": 20}}
+	row := createCompletedProgress("user", "g1", "c1")
+	row.Status = domain.GoalStatusInProgress
+	row.Progress = 4
+	row.CompletedAt = nil
+	row.UpdatedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	completed := overrides.For("new_player").Evaluate(row, goal)
+	assert.Equal(t, domain.GoalStatusCompleted, completed.Status)
+	require.NotNil(t, completed.CompletedAt)
+	assert.Equal(t, row.UpdatedAt, *completed.CompletedAt)
+	assert.Equal(t, domain.GoalStatusInProgress, row.Status, "the row is not modified")
+
+	assert.Same(t, row, overrides.For("veteran").Evaluate(row, goal))
+	assert.Same(t, row, overrides.For("").Evaluate(row, goal))
+
+	// A higher segment target never uncompletes a row
+	for _, status := range []domain.GoalStatus{domain.GoalStatusCompleted, domain.GoalStatusClaimed} {
+		done := createCompletedProgress("user", "g1", "c1")
+		done.Status = status
+		assert.Same(t, done, overrides.For("veteran").Evaluate(done, goal))
+	}
+}
+
+func TestSegmentTargets_EvaluateAll(t *testing.T) {
+	goalCache := commonCache.NewInMemoryGoalCache(&config.Config{Challenges: []*domain.Challenge{
+		{ID: "c1", Goals: []*domain.Goal{createClaimableGoal("g1", "c1"), createClaimableGoal("g2", "c1")}},
+	}}, "", slog.Default())
+	overrides := TargetOverrides{"g1": {"new_player": 3}}
+	g1 := createCompletedProgress("user", "g1", "c1")
+	g1.Status = domain.GoalStatusInProgress
+	g1.Progress = 3
+	g2 := createCompletedProgress("user", "g2", "c1")
+	g2.Status = domain.GoalStatusInProgress
+	g2.Progress = 3
+	progressMap := buildProgressMap([]*domain.UserGoalProgress{g1, g2})
+
+	evaluated := overrides.For("new_player").EvaluateAll(progressMap, goalCache)
+
+	assert.Equal(t, domain.GoalStatusCompleted, evaluated["g1"].Status)
+	assert.Same(t, g2, evaluated["g2"])
+	assert.Same(t, g1, progressMap["g1"], "the input map is not modified")
+
+	unchanged := overrides.For("veteran").EvaluateAll(progressMap, goalCache)
+	assert.Equal(t, progressMap, unchanged)
+}
+
+func TestSegmentTargets_ApplyToAssignedGoals(t *testing.T) {
+	overrides := TargetOverrides{"g1": {"new_player": 3}, "g2": {"new_player": 20}}
+	goals := []*AssignedGoal{
+		{GoalID: "g1", Progress: 4, Target: 10, Status: string(domain.GoalStatusInProgress), Requirement: domain.Requirement{TargetValue: 10}},
+		{GoalID: "g2", Progress: 10, Target: 10, Status: string(domain.GoalStatusCompleted), Requirement: domain.Requirement{TargetValue: 10}},
+		{GoalID: "g3", Progress: 4, Target: 10, Status: string(domain.GoalStatusInProgress), Requirement: domain.Requirement{TargetValue: 10}},
+	}
+
+	overrides.For("new_player").ApplyToAssignedGoals(goals)
+
+	assert.Equal(t, 3, goals[0].Target)
+	assert.Equal(t, 3, goals[0].Requirement.TargetValue)
+	assert.Equal(t, string(domain.GoalStatusCompleted), goals[0].Status)
+	assert.Equal(t, 20, goals[1].Target)
+	assert.Equal(t, string(domain.GoalStatusCompleted), goals[1].Status, "completed goals stay completed")
+	assert.Equal(t, 10, goals[2].Target)
+	assert.Equal(t, string(domain.GoalStatusInProgress), goals[2].Status)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"extend-challenge-service/pkg/common"
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultUnclaimedCountTTL is how long a user's unclaimed counts are served from memory.
+const DefaultUnclaimedCountTTL = 5 * time.Second
+
+// maxUnclaimedCountEntries bounds the cache. When it is full, expired entries
+// are dropped, and if none are, the whole cache is.
+const maxUnclaimedCountEntries = 100000
+
+// unclaimedCountStatuses are the statuses UnclaimedCounts counts.
+var unclaimedCountStatuses = []domain.GoalStatus{domain.GoalStatusCompleted, domain.GoalStatusInProgress}
+
+// UnclaimedCount is the number of a user's active goals with a reward to claim
+// and still in progress.
+type UnclaimedCount struct {
+	Unclaimed  int
+	InProgress int
+}
+
+// UnclaimedCounts serves the counts behind the unclaimed rewards badge, which
+// clients fetch on every menu open.
+//
+// Counts come from one aggregate query and are cached per user for the TTL.
+// Writes made by this pod (claims, activation, progress) call Invalidate, so a
+// player sees their own change at once; writes made by other pods or the event
+// handler show up within the TTL.
+type UnclaimedCounts struct {
+	queries   serviceRepo.ProgressQueryRepository
+	namespace string
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*unclaimedCountEntry
+}
+
+// unclaimedCountEntry is one user's cached counts. An entry that is not loaded
+// marks a query in flight; Invalidate removes it so the query's (possibly
+// stale) result is not stored.
+type unclaimedCountEntry struct {
+	count     UnclaimedCount
+	expiresAt time.Time
+	loaded    bool
+}
+
+// NewUnclaimedCounts creates unclaimed counts for a namespace cached for ttl.
+// A ttl of 0 disables the cache.
+func NewUnclaimedCounts(queries serviceRepo.ProgressQueryRepository, namespace string, ttl time.Duration) *UnclaimedCounts {
+	return &UnclaimedCounts{
+		queries:   queries,
+		namespace: namespace,
+		ttl:       ttl,
+		now:       time.Now,
+		entries:   make(map[string]*unclaimedCountEntry),
+	}
+}
+
+// NewUnclaimedCountsFromEnv creates unclaimed counts configured by
+// UNCLAIMED_COUNT_CACHE_TTL: how long counts are cached per user (default "5s",
+// "0" disables the cache). An invalid value is logged through logger.
+func NewUnclaimedCountsFromEnv(queries serviceRepo.ProgressQueryRepository, namespace string, logger logrus.FieldLogger) *UnclaimedCounts {
+	ttl := DefaultUnclaimedCountTTL
+	if value := common.GetEnv("UNCLAIMED_COUNT_CACHE_TTL", ""); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			logger.Warnf("Invalid UNCLAIMED_COUNT_CACHE_TTL %q, using %s", value, DefaultUnclaimedCountTTL)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	return NewUnclaimedCounts(queries, namespace, ttl)
+}
+
+// TTL returns how long counts are cached.
+func (u *UnclaimedCounts) TTL() time.Duration {
+	return u.ttl
+}
+
+// Get returns the user's counts, querying the database when they are not cached.
+func (u *UnclaimedCounts) Get(ctx context.Context, userID string) (UnclaimedCount, error) {
+	if u.ttl <= 0 {
+		return u.load(ctx, userID)
+	}
+
+	u.mu.Lock()
+	if entry, ok := u.entries[userID]; ok && entry.loaded && u.now().Before(entry.expiresAt) {
+		u.mu.Unlock()
+		return entry.count, nil
+	}
+	pending := &unclaimedCountEntry{}
+	u.store(userID, pending)
+	u.mu.Unlock()
+
+	count, err := u.load(ctx, userID)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.entries[userID] != pending {
+		// Invalidated (or reloaded) while the query ran
+		return count, err
+	}
+	if err != nil {
+		delete(u.entries, userID)
+		return count, err
+	}
+	pending.count = count
+	pending.expiresAt = u.now().Add(u.ttl)
+	pending.loaded = true
+
+	return count, nil
+}
+
+// Invalidate drops the cached counts of the given users. Call it after a write
+// that may change them. Safe on a nil receiver.
+func (u *UnclaimedCounts) Invalidate(userIDs ...string) {
+	if u == nil || u.ttl <= 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, userID := range userIDs {
+		delete(u.entries, userID)
+	}
+}
+
+func (u *UnclaimedCounts) load(ctx context.Context, userID string) (UnclaimedCount, error) {
+	counts, err := u.queries.CountByStatus(ctx, userID, u.namespace, unclaimedCountStatuses)
+	if err != nil {
+		return UnclaimedCount{}, fmt.Errorf("failed to count progress by status: %w", err)
+	}
+
+	return UnclaimedCount{
+		Unclaimed:  counts[domain.GoalStatusCompleted],
+		InProgress: counts[domain.GoalStatusInProgress],
+	}, nil
+}
+
+// store adds an entry, evicting when the cache is full. u.mu must be held.
+func (u *UnclaimedCounts) store(userID string, entry *unclaimedCountEntry) {
+	if _, ok := u.entries[userID]; !ok && len(u.entries) >= maxUnclaimedCountEntries {
+		now := u.now()
+		for key, existing := range u.entries {
+			if existing.loaded && !now.Before(existing.expiresAt) {
+				delete(u.entries, key)
+			}
+		}
+		if len(u.entries) >= maxUnclaimedCountEntries {
+			clear(u.entries)
+		}
+	}
+	u.entries[userID] = entry
+}
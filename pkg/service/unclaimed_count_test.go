@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/testutil/mocks"
+
+	"github.com/AccelByte/extend-challenge-common/pkg/domain"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUnclaimedCounts(queries *mocks.ProgressQueryRepository, ttl time.Duration, now *time.Time) *UnclaimedCounts {
+	counts := NewUnclaimedCounts(queries, "ns", ttl)
+	counts.now = func() time.Time { return *now }
+	return counts
+}
+
+func expectCountByStatus(queries *mocks.ProgressQueryRepository, userID string, unclaimed, inProgress int) *mock.Call {
+	return queries.On("CountByStatus", mock.Anything, userID, "ns", unclaimedCountStatuses).
+		Return(map[domain.GoalStatus]int{domain.GoalStatusCompleted: unclaimed, domain.GoalStatusInProgress: inProgress}, nil)
+}
+
+func TestUnclaimedCounts_CachesForTTL(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	queries := new(mocks.ProgressQueryRepository)
+	expectCountByStatus(queries, "user1", 2, 3).Twice()
+
+	counts := newTestUnclaimedCounts(queries, 5*time.Second, &now)
+
+	first, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, UnclaimedCount{Unclaimed: 2, InProgress: 3}, first)
+
+	now = now.Add(4 * time.Second)
+	_, err = counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	queries.AssertNumberOfCalls(t, "CountByStatus", 1)
+
+	now = now.Add(time.Second)
+	_, err = counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	queries.AssertNumberOfCalls(t, "CountByStatus", 2)
+}
+
+func TestUnclaimedCounts_Invalidate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	queries := new(mocks.ProgressQueryRepository)
+	expectCountByStatus(queries, "user1", 1, 0).Once()
+	expectCountByStatus(queries, "user2", 4, 0).Once()
+	expectCountByStatus(queries, "user1", 0, 0).Once()
+
+	counts := newTestUnclaimedCounts(queries, time.Minute, &now)
+	_, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	_, err = counts.Get(context.Background(), "user2")
+	require.NoError(t, err)
+
+	counts.Invalidate("user1")
+
+	afterClaim, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Zero(t, afterClaim.Unclaimed)
+
+	other, err := counts.Get(context.Background(), "user2")
+	require.NoError(t, err)
+	assert.Equal(t, 4, other.Unclaimed, "other users stay cached")
+	queries.AssertExpectations(t)
+}
+
+func TestUnclaimedCounts_InvalidateDuringQueryIsNotCached(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	queries := new(mocks.ProgressQueryRepository)
+	counts := newTestUnclaimedCounts(queries, time.Minute, &now)
+
+	// A claim lands while the first query is running
+	expectCountByStatus(queries, "user1", 1, 0).Once().Run(func(mock.Arguments) {
+		counts.Invalidate("user1")
+	})
+	expectCountByStatus(queries, "user1", 0, 0).Once()
+
+	stale, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stale.Unclaimed)
+
+	fresh, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Zero(t, fresh.Unclaimed, "the result read before the claim was not cached")
+	queries.AssertExpectations(t)
+}
+
+func TestUnclaimedCounts_ErrorsAreNotCached(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("CountByStatus", mock.Anything, "user1", "ns", unclaimedCountStatuses).Return(nil, errors.New("db down")).Once()
+	expectCountByStatus(queries, "user1", 1, 0).Once()
+
+	counts := newTestUnclaimedCounts(queries, time.Minute, &now)
+
+	_, err := counts.Get(context.Background(), "user1")
+	require.Error(t, err)
+
+	count, err := counts.Get(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count.Unclaimed)
+	queries.AssertExpectations(t)
+}
+
+func TestUnclaimedCounts_ZeroTTLDisablesCache(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	queries := new(mocks.ProgressQueryRepository)
+	expectCountByStatus(queries, "user1", 1, 0).Twice()
+
+	counts := newTestUnclaimedCounts(queries, 0, &now)
+	for range 2 {
+		_, err := counts.Get(context.Background(), "user1")
+		require.NoError(t, err)
+	}
+	queries.AssertExpectations(t)
+
+	var nilCounts *UnclaimedCounts
+	assert.NotPanics(t, func() { nilCounts.Invalidate("user1") })
+}
+
+func TestNewUnclaimedCountsFromEnv(t *testing.T) {
+	t.Setenv("UNCLAIMED_COUNT_CACHE_TTL", "2s")
+	assert.Equal(t, 2*time.Second, NewUnclaimedCountsFromEnv(nil, "ns", logrus.StandardLogger()).TTL())
+
+	t.Setenv("UNCLAIMED_COUNT_CACHE_TTL", "soon")
+	assert.Equal(t, DefaultUnclaimedCountTTL, NewUnclaimedCountsFromEnv(nil, "ns", logrus.StandardLogger()).TTL())
+}
+
+// BenchmarkUnclaimedCounts_CachedGet is the cost of a menu open served from the cache.
+func BenchmarkUnclaimedCounts_CachedGet(b *testing.B) {
+	queries := new(mocks.ProgressQueryRepository)
+	queries.On("CountByStatus", mock.Anything, "user1", "ns", unclaimedCountStatuses).
+		Return(map[domain.GoalStatus]int{domain.GoalStatusCompleted: 1}, nil)
+	counts := NewUnclaimedCounts(queries, "ns", time.Minute)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := counts.Get(ctx, "user1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package mocks holds the generated testify mocks shared by the unit and
+// integration tests. Do not edit the generated files; change tools/mockgen
+// and run "make mocks" instead.
+package mocks
+
+//go:generate go run ../../../tools/mockgen -out .
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 AccelByte Inc. All Rights Reserved.
+// This is licensed software from AccelByte Inc, for limitations
+// and restrictions contact your company contract manager.
+
+// Package testutil holds helpers shared by the service's tests.
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/repository"
+
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// Query budgets of the key flows: the most repository queries each may make,
+// whatever the number of goals. A flow over its budget most likely loads rows
+// one goal at a time (N+1) instead of in one batch.
+const (
+	// GetUserChallenges, and GET /v1/challenges: progress, activation sources
+	// and first completions
+	MaxGetUserChallengesQueries = 3
+	// InitializePlayer for a player without progress
+	MaxFirstLoginQueries = 3
+	// ClaimGoalReward with claim freezes, the claim cap, reward caps and failed
+	// grants enabled: the freeze, the claim window, the outbox entry (reserve,
+	// mark granted, delete), the progress row and its claim, the reward cap
+	// slot, the grant IDs, the claim log and the failed grant
+	MaxClaimQueries = 11
+)
+
+// CountQueries returns repo decorated like in production (see
+// repository.InstrumentedGoalRepository) and ctx with a counter of the calls
+// made with it. Pass both to the flow under test, then AssertMaxQueries. The
+// flow's other repositories are counted too once decorated with the same
+// metrics, e.g. repository.NewInstrumentedClaimOutboxRepository.
+func CountQueries(ctx context.Context, repo commonRepo.GoalRepository) (commonRepo.GoalRepository, context.Context, *common.QueryCounter) {
+	counter := common.NewQueryCounter()
+	instrumented := repository.NewInstrumentedGoalRepository(repo, repository.NewQueryMetrics(0))
+	return instrumented, common.WithQueryCounter(ctx, counter), counter
+}
+
+// AssertMaxQueries fails the test when flow made more than max repository
+// queries, listing them by method so the query repeated per goal stands out.
+func AssertMaxQueries(t testing.TB, counter *common.QueryCounter, max int, flow string) bool {
+	t.Helper()
+	if total := counter.Total(); total > max {
+		t.Errorf("%s made %d repository queries, at most %d allowed: %s. "+
+			"A query per goal (N+1)? Load the rows in one batch, e.g. with GetGoalsByIDs.",
+			flow, total, max, counter)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/service"
+)
+
+// TestAbandonedGoalSweep_KeepsGoalsWithProgress runs the sweep query against
+// PostgreSQL: only active goals without any progress, assigned before the
+// cutoff, are deactivated.
+func TestAbandonedGoalSweep_KeepsGoalsWithProgress(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+
+	now := time.Now().UTC()
+	old := now.Add(-30 * 24 * time.Hour)
+	seed := func(goalID, challengeID string, progress int, status string, isActive bool, assignedAt time.Time, steps any) {
+		t.Helper()
+		_, err := db.Exec(`
+			INSERT INTO user_goal_progress
+			(user_id, goal_id, challenge_id, namespace, progress, status, is_active, assigned_at, step_progress, activation_source)
+			VALUES ('sweep-user', $1, $2, 'test-namespace', $3, $4, $5, $6, $7, 'manual')
+		`, goalID, challengeID, progress, status, isActive, assignedAt, steps)
+		require.NoError(t, err)
+	}
+	seed("abandoned", "weekly", 0, "not_started", true, old, nil)
+	seed("abandoned-steps", "weekly", 0, "in_progress", true, old, `[0,0]`)
+	seed("some-progress", "weekly", 3, "in_progress", true, old, nil)
+	seed("later-step-progress", "weekly", 0, "in_progress", true, old, `[0,250]`)
+	seed("recent", "weekly", 0, "not_started", true, now, nil)
+	seed("inactive", "weekly", 0, "not_started", false, old, nil)
+	seed("other-challenge", "daily", 0, "not_started", true, old, nil)
+
+	sweeper := service.NewAbandonedGoalSweeper(serviceRepo.NewPostgresAbandonedGoalRepository(db), "test-namespace",
+		service.AutoDeactivation{"weekly": 7}, time.Hour, logrus.StandardLogger())
+	deactivated, err := sweeper.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, deactivated)
+
+	state := func(goalID string) (bool, string) {
+		t.Helper()
+		var isActive bool
+		var source sql.NullString
+		require.NoError(t, db.QueryRow(
+			`SELECT is_active, activation_source FROM user_goal_progress WHERE user_id = 'sweep-user' AND goal_id = $1`, goalID,
+		).Scan(&isActive, &source))
+		return isActive, source.String
+	}
+	for _, goalID := range []string{"abandoned", "abandoned-steps"} {
+		isActive, source := state(goalID)
+		assert.False(t, isActive, goalID)
+		assert.Equal(t, service.ActivationSourceAutoExpired, source, goalID)
+	}
+	for _, goalID := range []string{"some-progress", "later-step-progress", "recent", "other-challenge"} {
+		isActive, source := state(goalID)
+		assert.True(t, isActive, goalID)
+		assert.Equal(t, service.ActivationSourceManual, source, goalID)
+	}
+
+	// A second sweep finds nothing left
+	deactivated, err = sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, deactivated)
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// TestActivationSource_Lifecycle follows how a goal's activation source is
+// recorded by first login and manual activation, and kept on deactivation.
+func TestActivationSource_Lifecycle(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "single_challenge"))
+
+	goalSource := func(userID, goalID string) string {
+		t.Helper()
+		resp, err := env.Client.GetChallenge(createAuthContext(userID, "test-namespace"), &pb.GetChallengeRequest{ChallengeId: "fixture-challenge"})
+		require.NoError(t, err)
+		for _, goal := range resp.Challenge.Goals {
+			if goal.GoalId == goalID {
+				return goal.ActivationSource
+			}
+		}
+		t.Fatalf("goal %s not found", goalID)
+		return ""
+	}
+
+	// First login assigns the default goal
+	initResp, err := env.Client.InitializePlayer(createAuthContext("source-user", "test-namespace"), &pb.InitializeRequest{})
+	require.NoError(t, err)
+	require.Len(t, initResp.AssignedGoals, 1)
+	assert.Equal(t, "default", initResp.AssignedGoals[0].ActivationSource)
+	assert.Equal(t, "default", goalSource("source-user", "fixture-wins"))
+
+	// Manual activation, then deactivation keeps the source
+	ctx := createAuthContext("fixture-user", "test-namespace")
+	assert.Empty(t, goalSource("fixture-user", "fixture-kills"), "seeded rows have no recorded source")
+
+	activated, err := env.Client.SetGoalActive(ctx, &pb.SetGoalActiveRequest{ChallengeId: "fixture-challenge", GoalId: "fixture-kills", IsActive: true})
+	require.NoError(t, err)
+	assert.Equal(t, "manual", activated.ActivationSource)
+
+	deactivated, err := env.Client.SetGoalActive(ctx, &pb.SetGoalActiveRequest{ChallengeId: "fixture-challenge", GoalId: "fixture-kills", IsActive: false})
+	require.NoError(t, err)
+	assert.Equal(t, "manual", deactivated.ActivationSource)
+	assert.Equal(t, "manual", goalSource("fixture-user", "fixture-kills"))
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/service"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	batchBenchPlayers = 100
+	batchBenchStats   = 100
+)
+
+// BenchmarkBatchReportProgress_10kEvents sends end-of-match results for 100
+// players x 100 stats (10k events, 20k goal rows: one absolute and one relative
+// goal per stat) through BatchReportProgress against PostgreSQL.
+func BenchmarkBatchReportProgress_10kEvents(b *testing.B) {
+	db := createTestSchema(b)
+	repo := commonRepo.NewPostgresGoalRepository(db)
+	queries := repository.NewPostgresProgressQueryRepository(db)
+	inactive := repository.NewPostgresInactiveProgressRepository(db)
+	late := repository.NewPostgresLateProgressRepository(db)
+
+	challenge := &commonDomain.Challenge{ID: "batch-bench"}
+	for s := 0; s < batchBenchStats; s++ {
+		statCode := fmt.Sprintf("stat-%03d", s)
+		challenge.Goals = append(challenge.Goals,
+			&commonDomain.Goal{ID: "abs-" + statCode, ChallengeID: challenge.ID, Requirement: commonDomain.Requirement{StatCode: statCode, TargetValue: 1000000}},
+			&commonDomain.Goal{ID: "rel-" + statCode, ChallengeID: challenge.ID, Requirement: commonDomain.Requirement{StatCode: statCode, TargetValue: 1000000, ProgressMode: commonDomain.ProgressModeRelative}},
+		)
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: []*commonDomain.Challenge{challenge}}, "", logger)
+
+	// Assign every goal to every player so all rows are written
+	now := time.Now().UTC()
+	rows := make([]*commonDomain.UserGoalProgress, 0, batchBenchPlayers*len(challenge.Goals))
+	events := make([]service.ProgressEvent, 0, batchBenchPlayers*batchBenchStats)
+	for p := 0; p < batchBenchPlayers; p++ {
+		userID := fmt.Sprintf("batch-bench-player-%03d", p)
+		for _, goal := range challenge.Goals {
+			rows = append(rows, &commonDomain.UserGoalProgress{
+				UserID:      userID,
+				GoalID:      goal.ID,
+				ChallengeID: challenge.ID,
+				Namespace:   "test-namespace",
+				Status:      commonDomain.GoalStatusNotStarted,
+				IsActive:    true,
+				AssignedAt:  &now,
+			})
+		}
+		for s := 0; s < batchBenchStats; s++ {
+			event := service.ProgressEvent{UserID: userID, StatCode: fmt.Sprintf("stat-%03d", s)}
+			value, delta := s, 1
+			if s%2 == 0 {
+				event.Value = &value
+			} else {
+				event.Delta = &delta
+			}
+			events = append(events, event)
+		}
+	}
+	if err := repo.BulkInsertWithCOPY(context.Background(), rows); err != nil {
+		b.Fatalf("Failed to seed benchmark progress: %v", err)
+	}
+
+	config := service.BatchProgressConfig{MaxEvents: service.DefaultBatchProgressMaxEvents, ChunkSize: service.DefaultBatchProgressChunkSize}
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := service.BatchReportProgress(ctx, "test-namespace", events, goalCache, repo, queries, inactive, late, nil, nil, nil, nil, config, logrus.StandardLogger())
+		if err != nil {
+			b.Fatalf("BatchReportProgress: %v", err)
+		}
+		if result.RowsWritten != len(events) {
+			b.Fatalf("BatchReportProgress: %d rows written, want %d", result.RowsWritten, len(events))
+		}
+	}
+}
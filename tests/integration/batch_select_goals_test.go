@@ -6,7 +6,6 @@ package integration
 
 import (
 	"testing"
-	"time"
 
 	pb "extend-challenge-service/pkg/pb"
 
@@ -324,7 +323,6 @@ func TestBatchSelectGoals_VerifyTimestamps(t *testing.T) {
 	goal := resp.SelectedGoals[0]
 	assert.NotEmpty(t, goal.AssignedAt, "AssignedAt should be set")
 
-	// Validate timestamp format (RFC3339)
-	_, err = time.Parse(time.RFC3339, goal.AssignedAt)
-	assert.NoError(t, err, "AssignedAt should be valid RFC3339 timestamp")
+	// Validate timestamp
+	assert.NoError(t, goal.AssignedAt.CheckValid(), "AssignedAt should be a valid timestamp")
 }
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// TestChallengeChain_UnlocksMidSession walks a two-level chain
+// (chain-tutorial -> chain-season-1 -> chain-season-2) within one session.
+// Both the tutorial and season 1 goals start completed, but season 1 only
+// unlocks once the tutorial goal is claimed, and season 2 once season 1 is.
+func TestChallengeChain_UnlocksMidSession(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "challenge_chain"))
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "chain-user", mock.Anything).Return(nil)
+	ctx := createAuthContext("chain-user", "test-namespace")
+
+	assertLocks := func(activeOnly bool, want map[string]bool) {
+		t.Helper()
+		resp, err := env.Client.GetUserChallenges(ctx, &pb.GetChallengesRequest{ActiveOnly: activeOnly})
+		require.NoError(t, err)
+		for challengeID, locked := range want {
+			challenge := findChallenge(resp.Challenges, challengeID)
+			require.NotNil(t, challenge, challengeID)
+			assert.Equal(t, locked, challenge.Locked, "%s (active_only=%v)", challengeID, activeOnly)
+			if locked {
+				assert.Contains(t, challenge.LockedReason, "prerequisite challenges not completed")
+			} else {
+				assert.Empty(t, challenge.LockedReason)
+			}
+		}
+	}
+
+	// Nothing claimed yet: both seasons are locked
+	assertLocks(false, map[string]bool{"chain-tutorial": false, "chain-season-1": true, "chain-season-2": true})
+
+	_, err := env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "chain-season-1", GoalId: "chain-season-1-goal"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "claim in a locked challenge")
+	assert.Contains(t, status.Convert(err).Message(), "prerequisite_challenge_ids: chain-tutorial")
+
+	_, err = env.Client.SetGoalActive(ctx, &pb.SetGoalActiveRequest{ChallengeId: "chain-season-2", GoalId: "chain-season-2-goal", IsActive: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "activation in a locked challenge")
+
+	_, err = env.Client.RandomSelectGoals(ctx, &pb.RandomSelectRequest{ChallengeId: "chain-season-2", Count: 1})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "selection in a locked challenge")
+
+	// Claiming the tutorial unlocks season 1 only
+	_, err = env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "chain-tutorial", GoalId: "chain-tutorial-goal"})
+	require.NoError(t, err)
+	assertLocks(false, map[string]bool{"chain-season-1": false, "chain-season-2": true})
+
+	_, err = env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "chain-season-1", GoalId: "chain-season-1-goal"})
+	require.NoError(t, err)
+
+	// Season 2 is now unlocked, also when only active rows are loaded
+	assertLocks(false, map[string]bool{"chain-season-2": false})
+	assertLocks(true, map[string]bool{"chain-season-2": false})
+
+	detail, err := env.Client.GetChallenge(ctx, &pb.GetChallengeRequest{ChallengeId: "chain-season-2"})
+	require.NoError(t, err)
+	assert.False(t, detail.Challenge.Locked)
+	assert.True(t, detail.Challenge.Goals[0].Activatable)
+
+	_, err = env.Client.SetGoalActive(ctx, &pb.SetGoalActiveRequest{ChallengeId: "chain-season-2", GoalId: "chain-season-2-goal", IsActive: true})
+	require.NoError(t, err)
+}
+
+// TestChallengeChain_ClaimFollowUps checks the follow-ups returned by claims
+// that complete a challenge and unlock the next one.
+func TestChallengeChain_ClaimFollowUps(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "challenge_chain"))
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "chain-user", mock.Anything).Return(nil)
+	ctx := createAuthContext("chain-user", "test-namespace")
+
+	// Season 1's goal is already completed, so it is claimable once the tutorial is claimed
+	resp, err := env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{
+		ChallengeId:      "chain-tutorial",
+		GoalId:           "chain-tutorial-goal",
+		IncludeFollowUps: true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.FollowUps)
+	assert.True(t, resp.FollowUps.ChallengeCompleted)
+	assert.Equal(t, []string{"chain-season-1"}, resp.FollowUps.UnlockedChallengeIds)
+	assert.Equal(t, []string{"chain-season-1-goal"}, resp.FollowUps.ClaimableGoalIds)
+
+	// Season 2 unlocks but has no completed goal; without the flag nothing is returned
+	resp, err = env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{
+		ChallengeId: "chain-season-1",
+		GoalId:      "chain-season-1-goal",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, resp.FollowUps)
+}
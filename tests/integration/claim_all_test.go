@@ -0,0 +1,38 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// TestClaimAllCompleted_ClaimsThroughChallengeChain claims the tutorial and the
+// season 1 goal it unlocks in one call, and has nothing left to claim after.
+func TestClaimAllCompleted_ClaimsThroughChallengeChain(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "challenge_chain"))
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "chain-user", mock.Anything).Return(nil)
+	ctx := createAuthContext("chain-user", "test-namespace")
+
+	resp, err := env.Client.ClaimAllCompleted(ctx, &pb.ClaimAllCompletedRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Outcomes, 2)
+	assert.Equal(t, "chain-tutorial-goal", resp.Outcomes[0].GoalId)
+	assert.Equal(t, "chain-season-1-goal", resp.Outcomes[1].GoalId)
+	assert.Equal(t, int32(2), resp.ClaimedCount)
+	assert.False(t, resp.HasMore)
+	require.Len(t, resp.Rewards, 1)
+	assert.Equal(t, "GOLD", resp.Rewards[0].RewardId)
+	assert.Equal(t, int32(200), resp.Rewards[0].Quantity)
+	assert.Equal(t, int32(2), resp.Rewards[0].GoalCount)
+
+	again, err := env.Client.ClaimAllCompleted(ctx, &pb.ClaimAllCompletedRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Outcomes)
+	env.RewardClient.AssertNumberOfCalls(t, "GrantReward", 2)
+}
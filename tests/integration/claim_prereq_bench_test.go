@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+const (
+	claimBenchUserID    = "claim-bench-user"
+	claimBenchGoalCount = 5000
+	claimBenchGoalID    = "goal-04999"
+)
+
+// claimBenchPrerequisites are the prerequisite IDs of the goal being claimed.
+var claimBenchPrerequisites = []string{"goal-00000", "goal-00001", "goal-00002"}
+
+// seedClaimBench inserts claimBenchGoalCount completed progress rows for
+// claimBenchUserID and returns a repository over a fresh schema.
+func seedClaimBench(b *testing.B) *commonRepo.PostgresGoalRepository {
+	b.Helper()
+
+	db := createTestSchema(b)
+	repo := commonRepo.NewPostgresGoalRepository(db)
+
+	now := time.Now().UTC()
+	rows := make([]*commonDomain.UserGoalProgress, claimBenchGoalCount)
+	for i := range rows {
+		rows[i] = &commonDomain.UserGoalProgress{
+			UserID:      claimBenchUserID,
+			GoalID:      fmt.Sprintf("goal-%05d", i),
+			ChallengeID: fmt.Sprintf("challenge-%03d", i/100),
+			Namespace:   "test-namespace",
+			Progress:    10,
+			Status:      commonDomain.GoalStatusCompleted,
+			CompletedAt: &now,
+			IsActive:    true,
+			AssignedAt:  &now,
+		}
+	}
+	if err := repo.BulkInsertWithCOPY(context.Background(), rows); err != nil {
+		b.Fatalf("Failed to seed claim benchmark progress: %v", err)
+	}
+	if _, err := db.Exec("ANALYZE user_goal_progress"); err != nil {
+		b.Fatalf("Failed to analyze user_goal_progress: %v", err)
+	}
+
+	return repo
+}
+
+// BenchmarkClaimPrerequisiteCheck measures how long the claim transaction holds
+// the row lock while checking prerequisites for a user with 5k progress rows:
+// the old full-progress load versus loading only the prerequisite rows.
+func BenchmarkClaimPrerequisiteCheck(b *testing.B) {
+	repo := seedClaimBench(b)
+	ctx := context.Background()
+
+	loaders := []struct {
+		name string
+		load func(tx commonRepo.TxRepository) (int, error)
+	}{
+		{"AllProgress", func(tx commonRepo.TxRepository) (int, error) {
+			rows, err := tx.GetUserProgress(ctx, claimBenchUserID, false)
+			return len(rows), err
+		}},
+		{"PrerequisitesOnly", func(tx commonRepo.TxRepository) (int, error) {
+			rows, err := tx.GetGoalsByIDs(ctx, claimBenchUserID, claimBenchPrerequisites)
+			return len(rows), err
+		}},
+	}
+
+	for _, loader := range loaders {
+		load := loader.load
+		b.Run(loader.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tx, err := repo.BeginTx(ctx)
+				if err != nil {
+					b.Fatalf("BeginTx: %v", err)
+				}
+				if _, err := tx.GetProgressForUpdate(ctx, claimBenchUserID, claimBenchGoalID); err != nil {
+					b.Fatalf("GetProgressForUpdate: %v", err)
+				}
+				if n, err := load(tx); err != nil || n == 0 {
+					b.Fatalf("prerequisite load: %d rows, err=%v", n, err)
+				}
+				if err := tx.Rollback(); err != nil {
+					b.Fatalf("Rollback: %v", err)
+				}
+			}
+		})
+	}
+}
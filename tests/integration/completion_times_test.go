@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+)
+
+// completionTimes reads the completion columns of a user's row.
+func completionTimes(t *testing.T, db *sql.DB, userID, goalID string) (completedAt, firstCompletedAt *time.Time) {
+	t.Helper()
+	err := db.QueryRow(`SELECT completed_at, first_completed_at FROM user_goal_progress WHERE user_id = $1 AND goal_id = $2`,
+		userID, goalID).Scan(&completedAt, &firstCompletedAt)
+	require.NoError(t, err)
+	return completedAt, firstCompletedAt
+}
+
+// TestCompletionTimes_ReupsertKeepsCompletedAt writes a completed row again,
+// as the claim of a goal completed at a segment target or a replayed event
+// would, and checks the first completion time survives it and a reset.
+func TestCompletionTimes_ReupsertKeepsCompletedAt(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+	ctx := context.Background()
+	repo := serviceRepo.NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := &commonDomain.UserGoalProgress{
+		UserID: "user-1", GoalID: "daily-win", ChallengeID: "daily", Namespace: "test",
+		Progress: 1, Status: commonDomain.GoalStatusCompleted, CompletedAt: &first, IsActive: true,
+	}
+	require.NoError(t, repo.UpsertProgress(ctx, row))
+
+	later := first.Add(6 * time.Hour)
+	again := *row
+	again.Progress = 2
+	again.CompletedAt = &later
+	require.NoError(t, repo.UpsertProgress(ctx, &again))
+	require.NoError(t, repo.BatchUpsertProgress(ctx, []*commonDomain.UserGoalProgress{&again}))
+
+	completedAt, firstCompletedAt := completionTimes(t, db, "user-1", "daily-win")
+	require.NotNil(t, completedAt)
+	assert.True(t, first.Equal(*completedAt), "completed_at is kept, got %v", completedAt)
+	assert.Nil(t, firstCompletedAt, "rows never reset were first completed at completed_at")
+
+	// A regression, such as a rotation reset, keeps completed_at too
+	reset := *row
+	reset.Progress = 0
+	reset.Status = commonDomain.GoalStatusNotStarted
+	reset.CompletedAt = nil
+	require.NoError(t, repo.BatchUpsertProgress(ctx, []*commonDomain.UserGoalProgress{&reset}))
+	require.NoError(t, repo.UpsertProgress(ctx, &reset))
+
+	completedAt, _ = completionTimes(t, db, "user-1", "daily-win")
+	require.NotNil(t, completedAt)
+	assert.True(t, first.Equal(*completedAt), "the regression keeps completed_at, got %v", completedAt)
+
+	require.NoError(t, repo.UpsertProgress(ctx, &again))
+	completedAt, firstCompletedAt = completionTimes(t, db, "user-1", "daily-win")
+	require.NotNil(t, completedAt)
+	assert.True(t, first.Equal(*completedAt), "the next completion keeps the first, got %v", completedAt)
+	assert.Nil(t, firstCompletedAt)
+	completions, err := serviceRepo.NewPostgresProgressQueryRepository(db).GetFirstCompletions(ctx, "user-1", []string{"daily-win"})
+	require.NoError(t, err)
+	assert.True(t, first.Equal(completions["daily-win"]))
+}
+
+// TestCompletionTimes_AdminResetKeepsFirstCompletion has an admin recompute
+// move a completed row back, which clears completed_at, and checks the first
+// completion is still reported once the row is completed again.
+func TestCompletionTimes_AdminResetKeepsFirstCompletion(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+	ctx := context.Background()
+	repo := serviceRepo.NewCompletionGuardGoalRepository(commonRepo.NewPostgresGoalRepository(db), db)
+
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := &commonDomain.UserGoalProgress{
+		UserID: "user-1", GoalID: "daily-win", ChallengeID: "daily", Namespace: "test",
+		Progress: 1, Status: commonDomain.GoalStatusCompleted, CompletedAt: &first, IsActive: true,
+	}
+	require.NoError(t, repo.UpsertProgress(ctx, row))
+
+	admin := serviceRepo.NewPostgresGoalAdminRepository(db)
+	_, err := admin.RecomputeStatuses(ctx, &serviceRepo.StatusRecompute{UserID: "user-1", Namespace: "test", Reason: "target raised"},
+		func(*commonDomain.UserGoalProgress) commonDomain.GoalStatus { return commonDomain.GoalStatusInProgress })
+	require.NoError(t, err)
+	completedAt, _ := completionTimes(t, db, "user-1", "daily-win")
+	assert.Nil(t, completedAt, "the admin reset clears completed_at")
+
+	later := first.Add(6 * time.Hour)
+	again := *row
+	again.CompletedAt = &later
+	require.NoError(t, repo.UpsertProgress(ctx, &again))
+
+	completedAt, firstCompletedAt := completionTimes(t, db, "user-1", "daily-win")
+	require.NotNil(t, completedAt)
+	assert.True(t, later.Equal(*completedAt), "the next completion gets its own time")
+	require.NotNil(t, firstCompletedAt)
+	assert.True(t, first.Equal(*firstCompletedAt))
+	completions, err := serviceRepo.NewPostgresProgressQueryRepository(db).GetFirstCompletions(ctx, "user-1", []string{"daily-win"})
+	require.NoError(t, err)
+	assert.True(t, first.Equal(completions["daily-win"]), "the reset row reports its first completion")
+}
+
+// TestCompletionTimes_RepeatableResetKeepsFirstCompletion claims a repeatable
+// goal twice and checks its repeat state reports the first completion.
+func TestCompletionTimes_RepeatableResetKeepsFirstCompletion(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+	ctx := context.Background()
+	repeats := serviceRepo.NewPostgresRepeatableGoalRepository(db)
+
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, err := db.Exec(`
+		INSERT INTO user_goal_progress (user_id, goal_id, challenge_id, namespace, progress, status,
+		                                completed_at, claimed_at, is_active, updated_at)
+		VALUES ('user-1', 'daily-win', 'daily', 'test', 1, 'claimed', $1, $2, true, NOW())`,
+		first, first.Add(time.Minute))
+	require.NoError(t, err)
+
+	state, err := repeats.ResetClaimed(ctx, "user-1", "daily-win")
+	require.NoError(t, err)
+	require.NotNil(t, state.FirstCompletedAt)
+	assert.True(t, first.Equal(*state.FirstCompletedAt))
+
+	second := first.Add(48 * time.Hour)
+	_, err = db.Exec(`UPDATE user_goal_progress SET status = 'claimed', completed_at = $1, claimed_at = $1
+		WHERE user_id = 'user-1' AND goal_id = 'daily-win'`, second)
+	require.NoError(t, err)
+	_, err = repeats.ResetClaimed(ctx, "user-1", "daily-win")
+	require.NoError(t, err)
+
+	states, err := repeats.GetRepeatStates(ctx, "user-1", []string{"daily-win"})
+	require.NoError(t, err)
+	require.Contains(t, states, "daily-win")
+	assert.Equal(t, 2, states["daily-win"].TimesClaimed)
+	assert.True(t, first.Equal(*states["daily-win"].FirstCompletedAt), "the second claim keeps the first completion")
+}
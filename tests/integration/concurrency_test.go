@@ -0,0 +1,241 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+)
+
+// Concurrency tests run on isolated environments (per-test schema or in-memory
+// repository), so they are safe to run in parallel with each other.
+
+// TestInitializePlayer_ConcurrentFirstLogin fires several InitializePlayer calls
+// for the same new user at once; every call must succeed and the user must end
+// up with exactly one row per default goal.
+func TestInitializePlayer_ConcurrentFirstLogin(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, nil)
+
+	const calls = 10
+	ctx := createAuthContext("concurrent-init-user", "test-namespace")
+
+	results := runConcurrently(calls, func(int) (*pb.InitializeResponse, error) {
+		return env.Client.InitializePlayer(ctx, &pb.InitializeRequest{})
+	})
+
+	defaultGoals := env.GoalCache.GetGoalsWithDefaultAssigned()
+	for i, result := range results {
+		require.NoError(t, result.Err, "call %d", i)
+		assert.Equal(t, int32(len(defaultGoals)), result.Resp.TotalActive, "call %d", i)
+	}
+
+	count, err := env.Repo.GetUserGoalCount(context.Background(), "concurrent-init-user")
+	require.NoError(t, err)
+	assert.Equal(t, len(defaultGoals), count)
+}
+
+// TestClaimGoalReward_ConcurrentClaims fires several claims for the same
+// completed goal at once; exactly one may grant the reward.
+func TestClaimGoalReward_ConcurrentClaims(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "claim_race"))
+
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "race-user", mock.Anything).
+		Return(nil)
+
+	const calls = 5
+	ctx := createAuthContext("race-user", "test-namespace")
+
+	results := runConcurrently(calls, func(int) (*pb.ClaimRewardResponse, error) {
+		return env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{
+			ChallengeId: "winter-challenge-2025",
+			GoalId:      "complete-tutorial",
+		})
+	})
+
+	succeeded := 0
+	for i, result := range results {
+		if result.Err == nil {
+			succeeded++
+			assert.Equal(t, "claimed", result.Resp.Status)
+			continue
+		}
+		assert.Equal(t, codes.AlreadyExists, status.Code(result.Err), "call %d: %v", i, result.Err)
+	}
+
+	assert.Equal(t, 1, succeeded)
+	env.RewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
+}
+
+// TestClaimGoalReward_ConcurrentClaimsDuringSlowGrant makes the other claims
+// arrive while the first one is still granting, so they find its outbox
+// reservation instead of a locked row; they must still end with AlreadyExists.
+func TestClaimGoalReward_ConcurrentClaimsDuringSlowGrant(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "claim_race"))
+
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "race-user", mock.Anything).
+		Run(func(mock.Arguments) { time.Sleep(200 * time.Millisecond) }).
+		Return(nil)
+
+	const calls = 5
+	ctx := createAuthContext("race-user", "test-namespace")
+
+	results := runConcurrently(calls, func(int) (*pb.ClaimRewardResponse, error) {
+		return env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{
+			ChallengeId: "winter-challenge-2025",
+			GoalId:      "complete-tutorial",
+		})
+	})
+
+	succeeded := 0
+	for i, result := range results {
+		if result.Err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, codes.AlreadyExists, status.Code(result.Err), "call %d: %v", i, result.Err)
+	}
+
+	assert.Equal(t, 1, succeeded)
+	env.RewardClient.AssertNumberOfCalls(t, "GrantReward", 1)
+
+	entry, err := env.Outbox.Get(context.Background(), "race-user", "complete-tutorial")
+	require.NoError(t, err)
+	assert.Nil(t, entry, "the outbox entry is deleted once the goal is claimed")
+}
+
+// TestClaimGoalReward_FailedGrantReleasesClaim fails the first grant while other
+// claims wait on its reservation; one of them must then claim the goal.
+func TestClaimGoalReward_FailedGrantReleasesClaim(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "claim_race"))
+
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "race-user", mock.Anything).
+		Run(func(mock.Arguments) { time.Sleep(100 * time.Millisecond) }).
+		Return(&commonClient.BadRequestError{Message: "item disabled"}).Once()
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "race-user", mock.Anything).
+		Return(nil)
+
+	const calls = 5
+	ctx := createAuthContext("race-user", "test-namespace")
+
+	results := runConcurrently(calls, func(int) (*pb.ClaimRewardResponse, error) {
+		return env.Client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{
+			ChallengeId: "winter-challenge-2025",
+			GoalId:      "complete-tutorial",
+		})
+	})
+
+	succeeded, alreadyClaimed, failed := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			succeeded++
+		case status.Code(result.Err) == codes.AlreadyExists:
+			alreadyClaimed++
+		default:
+			failed++
+		}
+	}
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, calls-2, alreadyClaimed)
+	env.RewardClient.AssertNumberOfCalls(t, "GrantReward", 2)
+}
+
+// TestClaimGoalReward_GrantDoesNotHoldRowLock locks the claimed row from another
+// transaction while AGS is granting; it must not wait for the grant.
+func TestClaimGoalReward_GrantDoesNotHoldRowLock(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "claim_race"))
+
+	granting := make(chan struct{})
+	release := make(chan struct{})
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "race-user", mock.Anything).
+		Run(func(mock.Arguments) {
+			close(granting)
+			<-release
+		}).
+		Return(nil)
+
+	claimDone := make(chan error, 1)
+	go func() {
+		_, err := env.Client.ClaimGoalReward(createAuthContext("race-user", "test-namespace"), &pb.ClaimRewardRequest{
+			ChallengeId: "winter-challenge-2025",
+			GoalId:      "complete-tutorial",
+		})
+		claimDone <- err
+	}()
+
+	select {
+	case <-granting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("claim never reached the reward grant")
+	}
+
+	// Stands in for a progress event updating the same row
+	locked := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		tx, err := env.Repo.BeginTx(ctx)
+		if err != nil {
+			locked <- err
+			return
+		}
+		_, err = tx.GetProgressForUpdate(ctx, "race-user", "complete-tutorial")
+		_ = tx.Rollback()
+		locked <- err
+	}()
+
+	select {
+	case err := <-locked:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatal("progress row stayed locked during the reward grant")
+	}
+
+	close(release)
+	require.NoError(t, <-claimDone)
+}
+
+// TestGetUserChallenges_FromFixture checks that fixture challenges and progress
+// are what the service serves.
+func TestGetUserChallenges_FromFixture(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "single_challenge"))
+
+	ctx := createAuthContext("fixture-user", "test-namespace")
+	resp, err := env.Client.GetUserChallenges(ctx, &pb.GetChallengesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Challenges, 1)
+
+	challenge := findChallenge(resp.Challenges, "fixture-challenge")
+	require.NotNil(t, challenge)
+
+	wins := findGoal(challenge.Goals, "fixture-wins")
+	require.NotNil(t, wins)
+	assert.Equal(t, "completed", wins.Status)
+	assert.Equal(t, int32(3), wins.Progress)
+	assert.True(t, wins.IsActive)
+
+	kills := findGoal(challenge.Goals, "fixture-kills")
+	require.NotNil(t, kills)
+	assert.Equal(t, "in_progress", kills.Status)
+	assert.Equal(t, int32(4), kills.Progress)
+	assert.False(t, kills.IsActive)
+}
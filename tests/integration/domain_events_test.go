@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"extend-challenge-service/pkg/events"
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/service"
+)
+
+// fakeEventPublisher is an in-memory client.EventPublisher standing in for Kafka.
+type fakeEventPublisher struct {
+	mu        sync.Mutex
+	published []events.Envelope
+}
+
+func (p *fakeEventPublisher) Publish(_ context.Context, envelopes []events.Envelope) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, envelopes...)
+	return nil
+}
+
+// relayEvents publishes the test's event outbox to a fake publisher and returns
+// the events of userID, in publish order.
+func relayEvents(t *testing.T, env *testEnv, userID string) []events.Envelope {
+	t.Helper()
+
+	publisher := &fakeEventPublisher{}
+	relay := service.NewEventRelay(env.Events, publisher, time.Second, logrus.StandardLogger())
+	for {
+		n, err := relay.RelayPending(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+
+	var userEvents []events.Envelope
+	for _, envelope := range publisher.published {
+		if envelope.UserID == userID {
+			userEvents = append(userEvents, envelope)
+		}
+	}
+	return userEvents
+}
+
+func eventTypes(envelopes []events.Envelope) []string {
+	types := make([]string, len(envelopes))
+	for i, envelope := range envelopes {
+		types[i] = envelope.Type
+	}
+	return types
+}
+
+// TestDomainEvents_InitializeAndSelect publishes the events the service writes
+// to the outbox itself.
+func TestDomainEvents_InitializeAndSelect(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "single_challenge"))
+
+	_, err := env.Client.InitializePlayer(createAuthContext("events-user", "test-namespace"), &pb.InitializeRequest{})
+	require.NoError(t, err)
+	// A returning player is not initialized again
+	_, err = env.Client.InitializePlayer(createAuthContext("events-user", "test-namespace"), &pb.InitializeRequest{})
+	require.NoError(t, err)
+
+	_, err = env.Client.BatchSelectGoals(createAuthContext("fixture-user", "test-namespace"), &pb.BatchSelectRequest{
+		ChallengeId: "fixture-challenge",
+		GoalIds:     []string{"fixture-kills"},
+	})
+	require.NoError(t, err)
+
+	initialized := relayEvents(t, env, "events-user")
+	require.Equal(t, []string{events.TypePlayerInitialized}, eventTypes(initialized))
+	assert.Equal(t, events.SchemaVersion, initialized[0].SchemaVersion)
+	assert.Equal(t, "test-namespace", initialized[0].Namespace)
+	var initPayload events.PlayerInitialized
+	require.NoError(t, json.Unmarshal(initialized[0].Data, &initPayload))
+	assert.Equal(t, []string{"fixture-wins"}, initPayload.AssignedGoalIDs)
+
+	// The outbox was drained by the first relay
+	assert.Empty(t, relayEvents(t, env, "events-user"))
+}
+
+// TestDomainEvents_SelectionPayload checks the goals.selected payload.
+func TestDomainEvents_SelectionPayload(t *testing.T) {
+	t.Parallel()
+	env := setupIsolatedTestServer(t, loadFixture(t, "single_challenge"))
+
+	_, err := env.Client.BatchSelectGoals(createAuthContext("fixture-user", "test-namespace"), &pb.BatchSelectRequest{
+		ChallengeId: "fixture-challenge",
+		GoalIds:     []string{"fixture-kills"},
+	})
+	require.NoError(t, err)
+
+	var selected []events.Envelope
+	for _, envelope := range relayEvents(t, env, "fixture-user") {
+		if envelope.Type == events.TypeGoalsSelected {
+			selected = append(selected, envelope)
+		}
+	}
+	require.Len(t, selected, 1)
+	var payload events.GoalsSelected
+	require.NoError(t, json.Unmarshal(selected[0].Data, &payload))
+	assert.Equal(t, events.GoalsSelected{
+		ChallengeID:      "fixture-challenge",
+		Source:           "manual",
+		SelectedGoalIDs:  []string{"fixture-kills"},
+		ActivatedGoalIDs: []string{"fixture-kills"},
+		ReplacedGoalIDs:  []string{},
+	}, payload)
+}
+
+// TestDomainEvents_CompletionAndClaimFromTriggers checks that goal status
+// changes reach the outbox through the database triggers.
+func TestDomainEvents_CompletionAndClaimFromTriggers(t *testing.T) {
+	requireDB(t)
+	t.Parallel()
+	// Seeding fixture-user's completed fixture-wins row emits goal.completed
+	env := setupIsolatedTestServer(t, loadFixture(t, "single_challenge"))
+	env.RewardClient.On("GrantReward", mock.Anything, "test-namespace", "fixture-user", mock.Anything).Return(nil)
+
+	_, err := env.Client.ClaimGoalReward(createAuthContext("fixture-user", "test-namespace"), &pb.ClaimRewardRequest{
+		ChallengeId: "fixture-challenge",
+		GoalId:      "fixture-wins",
+	})
+	require.NoError(t, err)
+
+	published := relayEvents(t, env, "fixture-user")
+	require.Equal(t, []string{events.TypeGoalCompleted, events.TypeGoalClaimed}, eventTypes(published))
+	assert.NotEqual(t, published[0].ID, published[1].ID)
+
+	var completed events.GoalCompleted
+	require.NoError(t, json.Unmarshal(published[0].Data, &completed))
+	assert.Equal(t, "fixture-wins", completed.GoalID)
+	assert.Equal(t, "fixture-challenge", completed.ChallengeID)
+	assert.Equal(t, 3, completed.Progress)
+
+	var claimed events.GoalClaimed
+	require.NoError(t, json.Unmarshal(published[1].Data, &claimed))
+	assert.Equal(t, "fixture-wins", claimed.GoalID)
+	claimedAt, err := time.Parse(time.RFC3339, claimed.ClaimedAt)
+	require.NoError(t, err)
+	assert.Equal(t, claimedAt, published[1].OccurredAt)
+
+	// Progress that does not change the status publishes nothing
+	_, err = env.DB.Exec(`UPDATE user_goal_progress SET progress = 4 WHERE user_id = 'fixture-user' AND goal_id = 'fixture-kills'`)
+	require.NoError(t, err)
+	assert.Empty(t, relayEvents(t, env, "fixture-user"))
+}
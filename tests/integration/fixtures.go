@@ -1,13 +1,109 @@
 package integration
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	pb "extend-challenge-service/pkg/pb"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
 )
 
+const fixturesDir = "testdata/fixtures"
+
+// testFixture is a small JSON document describing the challenges and user
+// progress a test starts from. Files live in testdata/fixtures.
+//
+// Example:
+//
+//	{
+//	  "challenges": [ ... same shape as challenges.json ... ],
+//	  "progress": [
+//	    {"userId": "user-1", "goalId": "complete-tutorial", "challengeId": "winter-challenge-2025", "progress": 1, "status": "completed"}
+//	  ]
+//	}
+//
+// "challenges" is optional; when omitted the default test config is used.
+type testFixture struct {
+	Challenges json.RawMessage   `json:"challenges,omitempty"`
+	Progress   []fixtureProgress `json:"progress"`
+}
+
+// fixtureProgress is one user_goal_progress row in a fixture file.
+// IsActive defaults to true (matching the column default).
+type fixtureProgress struct {
+	UserID      string `json:"userId"`
+	GoalID      string `json:"goalId"`
+	ChallengeID string `json:"challengeId"`
+	Progress    int    `json:"progress"`
+	Status      string `json:"status"`
+	IsActive    *bool  `json:"isActive,omitempty"`
+}
+
+// loadFixture reads testdata/fixtures/<name>.json.
+func loadFixture(t *testing.T, name string) *testFixture {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(fixturesDir, name+".json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", name, err)
+	}
+
+	var fixture testFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture %s: %v", name, err)
+	}
+
+	return &fixture
+}
+
+// seedFixture inserts the fixture's progress rows through the repository, so it
+// works the same for PostgreSQL and the in-memory repository.
+func seedFixture(t *testing.T, repo commonRepo.GoalRepository, fixture *testFixture) {
+	t.Helper()
+
+	if fixture == nil || len(fixture.Progress) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	rows := make([]*commonDomain.UserGoalProgress, len(fixture.Progress))
+	for i, p := range fixture.Progress {
+		row := &commonDomain.UserGoalProgress{
+			UserID:      p.UserID,
+			GoalID:      p.GoalID,
+			ChallengeID: p.ChallengeID,
+			Namespace:   "test-namespace",
+			Progress:    p.Progress,
+			Status:      commonDomain.GoalStatus(p.Status),
+			IsActive:    p.IsActive == nil || *p.IsActive,
+		}
+		if row.Status == "" {
+			row.Status = commonDomain.GoalStatusNotStarted
+		}
+		if row.IsActive {
+			row.AssignedAt = &now
+		}
+		if row.Status == commonDomain.GoalStatusCompleted || row.Status == commonDomain.GoalStatusClaimed {
+			row.CompletedAt = &now
+		}
+		if row.Status == commonDomain.GoalStatusClaimed {
+			row.ClaimedAt = &now
+		}
+		rows[i] = row
+	}
+
+	if err := repo.BulkInsert(context.Background(), rows); err != nil {
+		t.Fatalf("Failed to seed fixture: %v", err)
+	}
+}
+
 // seedCompletedGoal inserts a completed goal into the database
 func seedCompletedGoal(t *testing.T, db *sql.DB, userID, goalID, challengeID string) {
 	t.Helper()
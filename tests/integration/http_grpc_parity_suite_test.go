@@ -0,0 +1,835 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/service/iam"
+	"github.com/AccelByte/accelbyte-go-sdk/services-api/pkg/utils/auth/validator"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"extend-challenge-service/pkg/cache"
+	"extend-challenge-service/pkg/common"
+	"extend-challenge-service/pkg/handler"
+	"extend-challenge-service/pkg/mapper"
+	pb "extend-challenge-service/pkg/pb"
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/service"
+)
+
+// The parity suite runs every read endpoint over gRPC and over HTTP, as
+// production routes it (optimized handlers first, the gRPC-Gateway for the
+// rest), against the same seeded state. Successful reads must return the same
+// JSON, and the standard error cases the same status.
+//
+// parityCases needs an entry for every GET endpoint in service.proto;
+// TestHTTPGRPCParity_Registry fails when one is added without a case.
+
+const (
+	// parityUserID owns the progress seeded from testdata/fixtures/parity.json.
+	parityUserID = "parity-user"
+
+	// parityToken is the only bearer token parityValidator accepts.
+	parityToken = "parity-token"
+
+	// parityBulkActivationJobID is the ID of the seeded bulk activation job.
+	parityBulkActivationJobID = 4242
+)
+
+// paritySince is the fixed GetSelectionStats window start, so both calls count the same events.
+var paritySince = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// parityRequest is one request made over both transports.
+type parityRequest struct {
+	// path is the HTTP path and query.
+	path string
+	// call makes the same request over gRPC.
+	call func(ctx context.Context, client pb.ServiceClient) (proto.Message, error)
+}
+
+// parityCase covers one read endpoint.
+type parityCase struct {
+	// read must succeed and return the same response over both transports.
+	read parityRequest
+	// errors must fail with the given code over gRPC and the matching HTTP
+	// status (runtime.HTTPStatusFromCode) over HTTP.
+	errors map[codes.Code]parityRequest
+	// normalize clears the fields that depend on the time of the call.
+	normalize func(proto.Message)
+	// needsDB skips the case without PostgreSQL, for endpoints reading through
+	// repositories the in-memory test service does not have.
+	needsDB bool
+	// public endpoints are allowed without authentication (see common.NewAuthAllowList).
+	public bool
+}
+
+// parityCases has one case per GET endpoint, keyed by RPC name.
+var parityCases = map[string]parityCase{
+	"GetUserChallenges": {
+		read: parityRequest{
+			path: "/v1/challenges",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetUserChallenges(ctx, &pb.GetChallengesRequest{})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.InvalidArgument: {
+				path: "/v1/challenges?consistency=eventual-ish",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetUserChallenges(ctx, &pb.GetChallengesRequest{Consistency: "eventual-ish"})
+				},
+			},
+		},
+		normalize: clearCountdowns,
+	},
+	"GetChallenge": {
+		read: parityRequest{
+			path: "/v1/challenges/winter-challenge-2025?active_only=true",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetChallenge(ctx, &pb.GetChallengeRequest{ChallengeId: "winter-challenge-2025", ActiveOnly: true})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/challenges/no-such-challenge",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetChallenge(ctx, &pb.GetChallengeRequest{ChallengeId: "no-such-challenge"})
+				},
+			},
+			codes.InvalidArgument: {
+				path: "/v1/challenges/winter-challenge-2025?consistency=eventual-ish",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetChallenge(ctx, &pb.GetChallengeRequest{ChallengeId: "winter-challenge-2025", Consistency: "eventual-ish"})
+				},
+			},
+		},
+		normalize: clearCountdowns,
+	},
+	"GetProgressSummary": {
+		read: parityRequest{
+			path: "/v1/challenges/summary?active_only=true",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{ActiveOnly: true})
+			},
+		},
+		normalize: clearCountdowns,
+		needsDB:   true,
+	},
+	"GetUnclaimedCount": {
+		read: parityRequest{
+			path: "/v1/challenges/unclaimed-count",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+			},
+		},
+		needsDB: true,
+	},
+	"GetActiveGoals": {
+		read: parityRequest{
+			path: "/v1/challenges/goals/active?limit=2",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetActiveGoals(ctx, &pb.GetActiveGoalsRequest{Limit: 2})
+			},
+		},
+		needsDB: true,
+	},
+	"GetAvailableGoals": {
+		read: parityRequest{
+			path: "/v1/challenges/winter-challenge-2025/goals/available?exclude_active=true",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetAvailableGoals(ctx, &pb.GetAvailableGoalsRequest{
+					ChallengeId:   "winter-challenge-2025",
+					ExcludeActive: true,
+				})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/challenges/no-such-challenge/goals/available",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetAvailableGoals(ctx, &pb.GetAvailableGoalsRequest{ChallengeId: "no-such-challenge"})
+				},
+			},
+		},
+		needsDB: true,
+	},
+	"GetActivationPreflight": {
+		read: parityRequest{
+			path: "/v1/challenges/winter-challenge-2025/goals/kill-10-snowmen/activation-preflight",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetActivationPreflight(ctx, &pb.GetActivationPreflightRequest{
+					ChallengeId: "winter-challenge-2025",
+					GoalId:      "kill-10-snowmen",
+				})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/challenges/winter-challenge-2025/goals/no-such-goal/activation-preflight",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetActivationPreflight(ctx, &pb.GetActivationPreflightRequest{
+						ChallengeId: "winter-challenge-2025",
+						GoalId:      "no-such-goal",
+					})
+				},
+			},
+		},
+		needsDB: true,
+	},
+	"GetRotationStatus": {
+		read: parityRequest{
+			path: "/v1/challenges/rotation-challenge/rotation",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetRotationStatus(ctx, &pb.GetRotationStatusRequest{ChallengeId: "rotation-challenge"})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/challenges/no-such-challenge/rotation",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetRotationStatus(ctx, &pb.GetRotationStatusRequest{ChallengeId: "no-such-challenge"})
+				},
+			},
+		},
+		normalize: func(m proto.Message) {
+			rotation := m.(*pb.GetRotationStatusResponse).GetRotation()
+			for _, period := range []*pb.RotationPeriod{rotation.GetCurrentPeriod(), rotation.GetNextPeriod()} {
+				if period != nil {
+					period.ExpiresInSeconds = 0
+				}
+			}
+		},
+	},
+	"GetClaimCap": {
+		read: parityRequest{
+			path: "/v1/admin/users/" + parityUserID + "/claim-cap",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetClaimCap(ctx, &pb.GetClaimCapRequest{UserId: parityUserID})
+			},
+		},
+		needsDB: true,
+	},
+	"ListClaimFreezes": {
+		read: parityRequest{
+			path: "/v1/admin/claim-freezes?limit=10",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.ListClaimFreezes(ctx, &pb.ListClaimFreezesRequest{Limit: 10})
+			},
+		},
+		needsDB: true,
+	},
+	"ListFailedGrants": {
+		read: parityRequest{
+			path: "/v1/admin/failed-grants?limit=10",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.ListFailedGrants(ctx, &pb.ListFailedGrantsRequest{Limit: 10})
+			},
+		},
+		needsDB: true,
+	},
+	"GetGoalStats": {
+		read: parityRequest{
+			path: "/v1/admin/stats/goals",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetGoalStats(ctx, &pb.GetGoalStatsRequest{})
+			},
+		},
+		needsDB: true,
+	},
+	"GetSelectionHistory": {
+		read: parityRequest{
+			path: "/v1/admin/users/" + parityUserID + "/selection-history",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetSelectionHistory(ctx, &pb.GetSelectionHistoryRequest{UserId: parityUserID})
+			},
+		},
+		needsDB: true,
+	},
+	"GetUserRollouts": {
+		read: parityRequest{
+			path: "/v1/admin/users/" + parityUserID + "/rollouts",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetUserRollouts(ctx, &pb.GetUserRolloutsRequest{UserId: parityUserID})
+			},
+		},
+	},
+	"GetSelectionStats": {
+		read: parityRequest{
+			path: "/v1/admin/stats/selection?since=" + paritySince.Format(time.RFC3339),
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetSelectionStats(ctx, &pb.GetSelectionStatsRequest{Since: timestamppb.New(paritySince)})
+			},
+		},
+		needsDB: true,
+	},
+	"GetChallengeMismatches": {
+		read: parityRequest{
+			path: "/v1/admin/progress/challenge-mismatches",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetChallengeMismatches(ctx, &pb.GetChallengeMismatchesRequest{})
+			},
+		},
+		needsDB: true,
+	},
+	"GetBackfillJob": {
+		read: parityRequest{
+			path: "/v1/admin/goals/complete-tutorial/backfill",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetBackfillJob(ctx, &pb.GetBackfillJobRequest{GoalId: "complete-tutorial"})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/admin/goals/kill-10-snowmen/backfill",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetBackfillJob(ctx, &pb.GetBackfillJobRequest{GoalId: "kill-10-snowmen"})
+				},
+			},
+		},
+		needsDB: true,
+	},
+	"GetBulkActivationJob": {
+		read: parityRequest{
+			path: "/v1/admin/bulk-activations/" + strconv.Itoa(parityBulkActivationJobID),
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.GetBulkActivationJob(ctx, &pb.GetBulkActivationJobRequest{JobId: parityBulkActivationJobID})
+			},
+		},
+		errors: map[codes.Code]parityRequest{
+			codes.NotFound: {
+				path: "/v1/admin/bulk-activations/999999",
+				call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+					return client.GetBulkActivationJob(ctx, &pb.GetBulkActivationJobRequest{JobId: 999999})
+				},
+			},
+		},
+		needsDB: true,
+	},
+	"HealthCheck": {
+		read: parityRequest{
+			path: "/healthz",
+			call: func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+				return client.HealthCheck(ctx, &pb.HealthCheckRequest{})
+			},
+		},
+		needsDB: true,
+		public:  true,
+	},
+}
+
+// parityExemptions lists GET endpoints without a parity case, with the reason.
+// Keep it short: an exemption is an endpoint whose transports may drift.
+var parityExemptions = map[string]string{
+	"GetServiceState": "off in the test service; uptime and pool stats change between calls anyway",
+	"GetConfigSchema": "HTTP serves the schema document (response_body), not the response message; static either way",
+}
+
+// clearCountdowns clears Goal.expires_in_seconds and the challenges' seconds_remaining,
+// which count down between the two calls.
+func clearCountdowns(m proto.Message) {
+	var challenges []*pb.Challenge
+	switch resp := m.(type) {
+	case *pb.GetChallengesResponse:
+		challenges = resp.GetChallenges()
+	case *pb.GetChallengeResponse:
+		challenges = []*pb.Challenge{resp.GetChallenge()}
+	}
+
+	for _, challenge := range challenges {
+		challenge.SecondsRemaining = 0
+		for _, goal := range challenge.GetGoals() {
+			goal.ExpiresInSeconds = 0
+		}
+	}
+	if summary, ok := m.(*pb.GetProgressSummaryResponse); ok {
+		for _, challenge := range summary.GetChallenges() {
+			challenge.SecondsRemaining = 0
+		}
+	}
+}
+
+// parityValidator accepts parityToken only.
+type parityValidator struct{}
+
+var _ validator.AuthTokenValidator = parityValidator{}
+
+func (parityValidator) Initialize(ctx ...context.Context) error { return nil }
+
+func (parityValidator) Validate(token string, permission *iam.Permission, namespace *string, userID *string) error {
+	if token != parityToken {
+		return status.Error(codes.PermissionDenied, "invalid token")
+	}
+	return nil
+}
+
+// parityEnv is one isolated service served over both transports.
+type parityEnv struct {
+	*testEnv
+	// http routes like newGRPCGatewayHTTPServer in main.go.
+	http http.Handler
+}
+
+// setupParityEnv serves the parity fixture over gRPC, through the production
+// auth interceptor, and over HTTP. With authEnabled both transports require
+// parityToken; without, both take the user from the x-mock-user-id header.
+func setupParityEnv(t *testing.T, authEnabled bool) *parityEnv {
+	t.Helper()
+
+	// The parity reads are all GETs; initialize is not one of them
+	return setupParityEnvWith(t, authEnabled, handler.OptimizedHandlers{Challenges: true})
+}
+
+// setupParityEnvWith is setupParityEnv with the optimized read routes enabled
+// by optimized, like OPTIMIZED_HANDLERS.
+func setupParityEnvWith(t *testing.T, authEnabled bool, optimized handler.OptimizedHandlers) *parityEnv {
+	t.Helper()
+
+	env, challengeServer := newIsolatedTestServer(t, loadFixture(t, "parity"))
+
+	hiddenGoals, err := service.LoadHiddenGoals(env.ConfigPath)
+	require.NoError(t, err)
+	targetOverrides, err := service.LoadTargetOverrides(env.ConfigPath)
+	require.NoError(t, err)
+	challengePrereqs, err := service.LoadChallengePrerequisites(env.ConfigPath)
+	require.NoError(t, err)
+
+	challengeServer.SetHiddenGoals(hiddenGoals)
+	challengeServer.SetTargetOverrides(targetOverrides)
+
+	var progressQueries serviceRepo.ProgressQueryRepository
+	if env.DB != nil {
+		progressQueries = serviceRepo.NewPostgresProgressQueryRepository(env.DB)
+		seedParityAdminState(t, env, challengeServer, progressQueries)
+	} else {
+		// Pages and snapshots only; the admin reads need PostgreSQL
+		progressQueries = newMemoryProgressQueries(env.Repo)
+		challengeServer.SetProgressQueries(progressQueries)
+	}
+
+	var tokenValidator validator.AuthTokenValidator
+	if authEnabled {
+		tokenValidator = parityValidator{}
+		previous := common.Validator
+		common.Validator = tokenValidator
+		t.Cleanup(func() { common.Validator = previous })
+	}
+
+	client, cleanup := startBufconnServer(t, challengeServer,
+		common.NewUnaryAuthServerIntercept(common.NewProtoPermissionExtractor(), common.NewAuthAllowList()))
+	t.Cleanup(cleanup)
+	env.Client = client
+
+	gateway := common.NewGatewayServeMux()
+	require.NoError(t, pb.RegisterServiceHandlerClient(context.Background(), gateway, client))
+
+	serializedCache := cache.NewSerializedChallengeCache()
+	serializedCache.SetHiddenGoals(hiddenGoals)
+	serializedCache.SetTargetOverrides(targetOverrides)
+	pbChallenges, err := mapper.ChallengesToProto(env.GoalCache.GetAllChallenges(), nil, nil, time.Now().UTC())
+	require.NoError(t, err)
+	require.NoError(t, serializedCache.WarmUp(pbChallenges))
+
+	challengesHandler := handler.NewOptimizedChallengesHandler(
+		env.GoalCache,
+		env.Repo,
+		progressQueries,
+		serializedCache,
+		"test-namespace",
+		authEnabled,
+		tokenValidator,
+	)
+	challengesHandler.SetHiddenGoals(hiddenGoals)
+	challengesHandler.SetChallengePrerequisites(challengePrereqs)
+	challengesHandler.SetTargetOverrides(targetOverrides)
+	challengesHandler.SetActivationSources(env.Sources)
+
+	// The read routes of newGRPCGatewayHTTPServer
+	mux := http.NewServeMux()
+	handler.RegisterChallengeRoutes(mux, "", optimized, handler.ChallengeRoutes{
+		Gateway:         gateway,
+		Challenges:      challengesHandler,
+		ChallengeDetail: http.HandlerFunc(challengesHandler.ServeChallenge),
+	}, common.NewRequestMetrics())
+
+	return &parityEnv{testEnv: env, http: mux}
+}
+
+// seedParityAdminState enables the admin reads backed by PostgreSQL and gives
+// them something to return.
+func seedParityAdminState(
+	t *testing.T,
+	env *testEnv,
+	challengeServer interface {
+		SetClaimCap(*service.ClaimCap)
+		SetClaimFreezes(*service.ClaimFreezes)
+		SetFailedGrants(*service.FailedGrants)
+		SetGoalStats(*service.GoalStats)
+		SetGoalSelections(serviceRepo.GoalSelectionRepository)
+		SetBackfills(*service.Backfills)
+		SetBulkActivations(*service.BulkActivations)
+	},
+	progressQueries serviceRepo.ProgressQueryRepository,
+) {
+	t.Helper()
+	ctx := context.Background()
+
+	challengeServer.SetClaimCap(service.NewClaimCap(serviceRepo.NewPostgresClaimCounterRepository(env.DB), 5, logrus.StandardLogger()))
+	challengeServer.SetGoalStats(service.NewGoalStats(progressQueries, "test-namespace", service.DefaultGoalStatsTTL, false, logrus.StandardLogger()))
+
+	freezes := service.NewClaimFreezes(serviceRepo.NewPostgresClaimFreezeRepository(env.DB), logrus.StandardLogger())
+	require.NoError(t, freezes.Freeze(ctx, &serviceRepo.ClaimFreeze{
+		UserID:      parityUserID,
+		Namespace:   "test-namespace",
+		FrozenUntil: time.Now().UTC().Add(time.Hour).Truncate(time.Second),
+		Reason:      "parity",
+		ActorUserID: "parity-admin",
+	}, ""))
+	challengeServer.SetClaimFreezes(freezes)
+
+	failedGrantRepo := serviceRepo.NewPostgresFailedGrantRepository(env.DB)
+	require.NoError(t, failedGrantRepo.RecordFailedGrant(ctx, &serviceRepo.FailedGrant{
+		UserID:         parityUserID,
+		GoalID:         "complete-tutorial",
+		ChallengeID:    "winter-challenge-2025",
+		Namespace:      "test-namespace",
+		RewardType:     "ITEM",
+		RewardID:       "deleted-item",
+		RewardQuantity: 1,
+		ErrorClass:     service.GrantErrorClassNotFound,
+		LastError:      "resource not found: deleted-item",
+	}))
+	challengeServer.SetFailedGrants(service.NewFailedGrants(failedGrantRepo, logrus.StandardLogger()))
+
+	selections := serviceRepo.NewPostgresGoalSelectionRepository(env.DB)
+	_, err := selections.ApplySelection(ctx, &serviceRepo.GoalSelection{
+		UserID:          parityUserID,
+		Namespace:       "test-namespace",
+		ChallengeID:     "winter-challenge-2025",
+		SelectedGoalIDs: []string{"kill-10-snowmen"},
+		Activate:        []string{"kill-10-snowmen"},
+		Deactivate:      []string{"reach-level-5"},
+		Source:          service.ActivationSourceManual,
+	})
+	require.NoError(t, err)
+	challengeServer.SetGoalSelections(selections)
+
+	backfillRepo := serviceRepo.NewPostgresBackfillRepository(env.DB)
+	completedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, backfillRepo.SaveBackfillJob(ctx, &serviceRepo.BackfillJob{
+		GoalID:       "complete-tutorial",
+		Namespace:    "test-namespace",
+		Status:       serviceRepo.BackfillStatusCompleted,
+		LastUserID:   parityUserID,
+		UsersScanned: 2,
+		UsersSkipped: 1,
+		RowsInserted: 1,
+		ActorUserID:  "parity-admin",
+		StartedAt:    completedAt.Add(-time.Minute),
+		UpdatedAt:    completedAt,
+		CompletedAt:  &completedAt,
+	}))
+	challengeServer.SetBackfills(service.NewBackfills(backfillRepo, env.GoalCache, "test-namespace", service.BackfillConfig{}, logrus.StandardLogger()))
+
+	// A fixed ID, so the parity case knows the path
+	_, err = env.DB.ExecContext(ctx, `DELETE FROM bulk_activation_jobs WHERE id = $1`, parityBulkActivationJobID)
+	require.NoError(t, err)
+	_, err = env.DB.ExecContext(ctx, `
+		INSERT INTO bulk_activation_jobs (
+			id, goal_id, namespace, status, user_ids, users_total, users_processed,
+			users_activated, users_claimed, reason, actor_user_id, started_at, updated_at, completed_at
+		)
+		VALUES ($1, 'kill-10-snowmen', 'test-namespace', 'completed', ARRAY[$2, 'other-user'], 2, 2, 1, 1,
+			'tournament', 'parity-admin', $3, $4, $4)
+	`, parityBulkActivationJobID, parityUserID, completedAt.Add(-time.Minute), completedAt)
+	require.NoError(t, err)
+	challengeServer.SetBulkActivations(service.NewBulkActivations(
+		serviceRepo.NewPostgresBulkActivationRepository(env.DB), env.GoalCache, "test-namespace", service.BackfillConfig{},
+		logrus.StandardLogger(),
+	))
+}
+
+// grpcContext returns the context for a gRPC call as the parity user.
+func (e *parityEnv) grpcContext(authenticated bool) context.Context {
+	if !authenticated {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(),
+		"x-mock-user-id", parityUserID,
+		"authorization", "Bearer "+parityToken,
+	)
+}
+
+// get makes an HTTP GET as the parity user and returns the status and body.
+func (e *parityEnv) get(t *testing.T, path string, authenticated bool) (int, []byte) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authenticated {
+		req.Header.Set("x-mock-user-id", parityUserID)
+		req.Header.Set("Authorization", "Bearer "+parityToken)
+	}
+	w := httptest.NewRecorder()
+	e.http.ServeHTTP(w, req)
+
+	return w.Code, w.Body.Bytes()
+}
+
+// assertSameResponse checks that the read returns the same JSON over both
+// transports. The HTTP body is decoded into the gRPC response type first, so
+// the comparison ignores how a transport spells the JSON (omitted defaults,
+// int64 as string) but not field names or values: an unset timestamp written
+// as anything but null fails to decode.
+func (e *parityEnv) assertSameResponse(t *testing.T, tc parityCase) {
+	t.Helper()
+
+	grpcResp, err := tc.read.call(e.grpcContext(true), e.Client)
+	require.NoError(t, err, "gRPC %s", tc.read.path)
+
+	code, body := e.get(t, tc.read.path, true)
+	require.Equal(t, http.StatusOK, code, "HTTP %s: %s", tc.read.path, body)
+
+	httpResp := grpcResp.ProtoReflect().New().Interface()
+	require.NoError(t, protojson.Unmarshal(body, httpResp), "HTTP %s does not decode as %T: %s", tc.read.path, httpResp, body)
+
+	if tc.normalize != nil {
+		tc.normalize(grpcResp)
+		tc.normalize(httpResp)
+	}
+
+	marshaler := common.NewSonicMarshaler()
+	grpcJSON, err := marshaler.Marshal(grpcResp)
+	require.NoError(t, err)
+	httpJSON, err := marshaler.Marshal(httpResp)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(grpcJSON), string(httpJSON), "HTTP %s", tc.read.path)
+}
+
+// assertSameError checks that the request fails with code over gRPC and the
+// matching status over HTTP.
+func (e *parityEnv) assertSameError(t *testing.T, code codes.Code, req parityRequest, authenticated bool) {
+	t.Helper()
+
+	_, err := req.call(e.grpcContext(authenticated), e.Client)
+	assert.Equal(t, code, status.Code(err), "gRPC %s: %v", req.path, err)
+
+	httpCode, body := e.get(t, req.path, authenticated)
+	assert.Equal(t, runtime.HTTPStatusFromCode(code), httpCode, "HTTP %s: %s", req.path, body)
+}
+
+// getEndpoints returns the RPCs with a GET binding in service.proto.
+func getEndpoints(t *testing.T) []string {
+	t.Helper()
+
+	methods := pb.File_service_proto.Services().ByName("Service").Methods()
+	var names []string
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if ok && rule.GetGet() != "" {
+			names = append(names, string(method.Name()))
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// TestHTTPGRPCParity_Registry fails when a GET endpoint has no parity case, so
+// a new read endpoint cannot skip the suite.
+func TestHTTPGRPCParity_Registry(t *testing.T) {
+	endpoints := getEndpoints(t)
+	require.NotEmpty(t, endpoints)
+
+	known := make(map[string]bool, len(endpoints))
+	for _, name := range endpoints {
+		known[name] = true
+		_, hasCase := parityCases[name]
+		_, exempt := parityExemptions[name]
+		assert.True(t, hasCase || exempt, "GET endpoint %s has no parity case; add one to parityCases", name)
+		assert.False(t, hasCase && exempt, "GET endpoint %s has both a parity case and an exemption", name)
+	}
+
+	for name := range parityCases {
+		assert.True(t, known[name], "parityCases has %s, which is not a GET endpoint", name)
+	}
+	for name := range parityExemptions {
+		assert.True(t, known[name], "parityExemptions has %s, which is not a GET endpoint", name)
+	}
+
+	// Every case decodes the HTTP body into the RPC's response type
+	for name := range parityCases {
+		method := pb.File_service_proto.Services().ByName("Service").Methods().ByName(protoreflect.Name(name))
+		_, err := protoregistry.GlobalTypes.FindMessageByName(method.Output().FullName())
+		assert.NoError(t, err, "response type of %s", name)
+	}
+}
+
+func TestHTTPGRPCParity(t *testing.T) {
+	env := setupParityEnv(t, false)
+
+	for _, name := range getEndpoints(t) {
+		tc, ok := parityCases[name]
+		if !ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			if tc.needsDB {
+				requireDB(t)
+			}
+
+			env.assertSameResponse(t, tc)
+			for code, req := range tc.errors {
+				env.assertSameError(t, code, req, true)
+			}
+		})
+	}
+}
+
+func TestHTTPGRPCParity_Unauthenticated(t *testing.T) {
+	env := setupParityEnv(t, true)
+
+	for _, name := range getEndpoints(t) {
+		tc, ok := parityCases[name]
+		if !ok || tc.public {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			env.assertSameError(t, codes.Unauthenticated, tc.read, false)
+		})
+	}
+}
+
+// TestHTTPGRPCParity_GatewayFallback turns the optimized handlers off
+// (OPTIMIZED_HANDLERS=off): the gRPC-Gateway catch-all then serves the
+// challenge reads, with the same responses.
+func TestHTTPGRPCParity_GatewayFallback(t *testing.T) {
+	env := setupParityEnvWith(t, false, handler.OptimizedHandlers{})
+
+	for _, name := range []string{"GetUserChallenges", "GetChallenge"} {
+		t.Run(name, func(t *testing.T) {
+			tc, ok := parityCases[name]
+			require.True(t, ok)
+
+			env.assertSameResponse(t, tc)
+
+			req := httptest.NewRequest(http.MethodGet, tc.read.path, nil)
+			req.Header.Set("x-mock-user-id", parityUserID)
+			w := httptest.NewRecorder()
+			env.http.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, handler.HandlerGateway, w.Header().Get(handler.HandlerHeader))
+		})
+	}
+}
+
+// clearSnapshotAt clears GetChallengesResponse.snapshot_at, taken separately
+// by each call, and the goal countdowns.
+func clearSnapshotAt(m proto.Message) {
+	clearCountdowns(m)
+	if resp, ok := m.(*pb.GetChallengesResponse); ok {
+		resp.SnapshotAt = nil
+	}
+}
+
+// TestHTTPGRPCParity_GetUserChallengesFilters runs every combination of the
+// GetUserChallenges filters over both transports. Paginated reads walk every
+// page.
+func TestHTTPGRPCParity_GetUserChallengesFilters(t *testing.T) {
+	env := setupParityEnv(t, false)
+	challengeIDs := []string{"winter-challenge-2025", "daily-quests"}
+
+	getChallenges := func(req *pb.GetChallengesRequest) func(context.Context, pb.ServiceClient) (proto.Message, error) {
+		return func(ctx context.Context, client pb.ServiceClient) (proto.Message, error) {
+			return client.GetUserChallenges(ctx, req)
+		}
+	}
+	query := func(req *pb.GetChallengesRequest) string {
+		values := url.Values{}
+		if req.ActiveOnly {
+			values.Set("active_only", "true")
+		}
+		for _, challengeID := range req.ChallengeIds {
+			values.Add("challenge_ids", challengeID)
+		}
+		if req.Consistency != "" {
+			values.Set("consistency", req.Consistency)
+		}
+		if req.Limit != 0 {
+			values.Set("limit", strconv.Itoa(int(req.Limit)))
+		}
+		if req.AfterGoalId != "" {
+			values.Set("after_goal_id", req.AfterGoalId)
+		}
+		if len(values) == 0 {
+			return "/v1/challenges"
+		}
+		return "/v1/challenges?" + values.Encode()
+	}
+
+	for _, activeOnly := range []bool{false, true} {
+		reads := []*pb.GetChallengesRequest{
+			{ActiveOnly: activeOnly},
+			{ActiveOnly: activeOnly, ChallengeIds: challengeIDs},
+			{ActiveOnly: activeOnly, Consistency: service.ConsistencyStrong},
+			{ActiveOnly: activeOnly, ChallengeIds: challengeIDs, Consistency: service.ConsistencyStrong},
+		}
+		for _, req := range reads {
+			t.Run(query(req), func(t *testing.T) {
+				env.assertSameResponse(t, parityCase{
+					read:      parityRequest{path: query(req), call: getChallenges(req)},
+					normalize: clearSnapshotAt,
+				})
+			})
+		}
+
+		t.Run(fmt.Sprintf("pages active_only=%t", activeOnly), func(t *testing.T) {
+			req := &pb.GetChallengesRequest{ActiveOnly: activeOnly, Limit: 2}
+			for pages := 1; ; pages++ {
+				env.assertSameResponse(t, parityCase{
+					read:      parityRequest{path: query(req), call: getChallenges(req)},
+					normalize: clearCountdowns,
+				})
+
+				resp, err := env.Client.GetUserChallenges(env.grpcContext(true), req)
+				require.NoError(t, err)
+				if resp.NextAfterGoalId == "" {
+					break
+				}
+				require.Less(t, pages, 100, "pagination does not end")
+				req = &pb.GetChallengesRequest{ActiveOnly: activeOnly, Limit: 2, AfterGoalId: resp.NextAfterGoalId}
+			}
+		})
+
+		invalid := []*pb.GetChallengesRequest{
+			{ActiveOnly: activeOnly, Limit: -1},
+			{ActiveOnly: activeOnly, Limit: service.MaxProgressPageLimit + 1},
+			{ActiveOnly: activeOnly, Limit: 2, ChallengeIds: challengeIDs},
+			{ActiveOnly: activeOnly, Limit: 2, Consistency: service.ConsistencyStrong},
+		}
+		for _, req := range invalid {
+			t.Run(query(req), func(t *testing.T) {
+				env.assertSameError(t, codes.InvalidArgument, parityRequest{path: query(req), call: getChallenges(req)}, true)
+			})
+		}
+	}
+}
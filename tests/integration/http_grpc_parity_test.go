@@ -16,6 +16,7 @@ import (
 	"extend-challenge-service/pkg/cache"
 	"extend-challenge-service/pkg/handler"
 	pb "extend-challenge-service/pkg/pb"
+	serviceRepo "extend-challenge-service/pkg/repository"
 
 	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
 	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
@@ -182,14 +183,17 @@ func setupHTTPHandler(t *testing.T) *handler.OptimizedChallengesHandler {
 	}
 
 	// Create HTTP handler with auth disabled (using x-mock-user-id header for testing)
-	return handler.NewOptimizedChallengesHandler(
+	h := handler.NewOptimizedChallengesHandler(
 		goalCache,
 		goalRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // authEnabled = false (use mock header)
 		nil,   // tokenValidator = nil (not needed when auth disabled)
 	)
+	h.SetActivationSources(serviceRepo.NewPostgresActivationSourceRepository(testDB))
+	return h
 }
 
 // convertToPbChallenge converts a domain challenge to protobuf format
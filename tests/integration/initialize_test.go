@@ -13,22 +13,19 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// assertTimestampsEqual compares two RFC3339 timestamp strings as time values
-// This ensures timezone-agnostic comparison (e.g., "2025-11-11T13:11:39Z" == "2025-11-11T20:11:39+07:00")
-func assertTimestampsEqual(t *testing.T, expected, actual string, msgAndArgs ...interface{}) {
+// assertTimestampsEqual compares two protobuf timestamps as time values
+func assertTimestampsEqual(t *testing.T, expected, actual *timestamppb.Timestamp, msgAndArgs ...interface{}) {
 	t.Helper()
 
-	expectedTime, err := time.Parse(time.RFC3339, expected)
-	require.NoError(t, err, "Failed to parse expected timestamp: %s", expected)
+	require.NoError(t, expected.CheckValid(), "Invalid expected timestamp")
+	require.NoError(t, actual.CheckValid(), "Invalid actual timestamp")
 
-	actualTime, err := time.Parse(time.RFC3339, actual)
-	require.NoError(t, err, "Failed to parse actual timestamp: %s", actual)
-
-	if !expectedTime.Equal(actualTime) {
-		msg := fmt.Sprintf("Timestamps not equal:\n  Expected: %s (%s)\n  Actual:   %s (%s)",
-			expected, expectedTime.UTC(), actual, actualTime.UTC())
+	if !expected.AsTime().Equal(actual.AsTime()) {
+		msg := fmt.Sprintf("Timestamps not equal:\n  Expected: %s\n  Actual:   %s",
+			expected.AsTime(), actual.AsTime())
 		if len(msgAndArgs) > 0 {
 			msg = fmt.Sprintf("%v\n%s", msgAndArgs[0], msg)
 		}
@@ -88,9 +85,8 @@ func TestInitializePlayer_FirstLogin(t *testing.T) {
 	assert.Equal(t, "GOLD", goal.Reward.RewardId)
 	assert.Equal(t, int32(100), goal.Reward.Quantity)
 
-	// Validate timestamp format
-	_, err = time.Parse(time.RFC3339, goal.AssignedAt)
-	assert.NoError(t, err, "AssignedAt should be valid RFC3339 timestamp")
+	// Validate timestamp
+	assert.NoError(t, goal.AssignedAt.CheckValid(), "AssignedAt should be a valid timestamp")
 }
 
 // TestInitializePlayer_SubsequentLogin_FastPath verifies idempotency and fast path
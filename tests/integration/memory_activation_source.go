@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"sync"
+
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// memoryActivationSources is an in-memory implementation of
+// repository.ActivationSourceRepository used alongside memoryGoalRepository.
+//
+// Like the PostgreSQL repository, SetActivationSource only records the source of
+// goals with an active progress row; rows are looked up through goals. With a
+// nil goals repository every goal is treated as active.
+type memoryActivationSources struct {
+	mu      sync.Mutex
+	goals   commonRepo.GoalRepository
+	sources map[progressKey]string
+}
+
+// newMemoryActivationSources creates an empty in-memory activation source store.
+func newMemoryActivationSources(goals commonRepo.GoalRepository) *memoryActivationSources {
+	return &memoryActivationSources{goals: goals, sources: make(map[progressKey]string)}
+}
+
+func (s *memoryActivationSources) SetActivationSource(ctx context.Context, userID string, goalIDs []string, source string) error {
+	if err := checkContext(ctx, "set activation source"); err != nil {
+		return err
+	}
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	active := goalIDs
+	if s.goals != nil {
+		rows, err := s.goals.GetGoalsByIDs(ctx, userID, goalIDs)
+		if err != nil {
+			return err
+		}
+		active = make([]string, 0, len(rows))
+		for _, row := range rows {
+			if row.IsActive {
+				active = append(active, row.GoalID)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, goalID := range active {
+		s.sources[progressKey{userID: userID, goalID: goalID}] = source
+	}
+	return nil
+}
+
+func (s *memoryActivationSources) GetActivationSources(ctx context.Context, userID string, goalIDs []string) (map[string]string, error) {
+	if err := checkContext(ctx, "get activation sources"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources := make(map[string]string, len(goalIDs))
+	for _, goalID := range goalIDs {
+		if source, ok := s.sources[progressKey{userID: userID, goalID: goalID}]; ok {
+			sources[goalID] = source
+		}
+	}
+	return sources, nil
+}
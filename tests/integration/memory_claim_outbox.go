@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+)
+
+// memoryClaimOutbox is an in-memory implementation of
+// repository.ClaimOutboxRepository used alongside memoryGoalRepository.
+type memoryClaimOutbox struct {
+	mu      sync.Mutex
+	entries map[progressKey]serviceRepo.ClaimOutboxEntry
+}
+
+// newMemoryClaimOutbox creates an empty in-memory claim outbox.
+func newMemoryClaimOutbox() *memoryClaimOutbox {
+	return &memoryClaimOutbox{entries: make(map[progressKey]serviceRepo.ClaimOutboxEntry)}
+}
+
+func (o *memoryClaimOutbox) Reserve(ctx context.Context, entry *serviceRepo.ClaimOutboxEntry) (bool, error) {
+	if err := checkContext(ctx, "reserve claim"); err != nil {
+		return false, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := progressKey{userID: entry.UserID, goalID: entry.GoalID}
+	if _, exists := o.entries[key]; exists {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	reserved := *entry
+	reserved.State = serviceRepo.ClaimOutboxPending
+	reserved.CreatedAt = now
+	reserved.UpdatedAt = now
+	o.entries[key] = reserved
+	return true, nil
+}
+
+func (o *memoryClaimOutbox) Get(ctx context.Context, userID, goalID string) (*serviceRepo.ClaimOutboxEntry, error) {
+	if err := checkContext(ctx, "get claim outbox entry"); err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry, ok := o.entries[progressKey{userID: userID, goalID: goalID}]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (o *memoryClaimOutbox) MarkGranted(ctx context.Context, userID, goalID string) error {
+	if err := checkContext(ctx, "mark claim granted"); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := progressKey{userID: userID, goalID: goalID}
+	if entry, ok := o.entries[key]; ok {
+		entry.State = serviceRepo.ClaimOutboxGranted
+		entry.UpdatedAt = time.Now().UTC()
+		o.entries[key] = entry
+	}
+	return nil
+}
+
+func (o *memoryClaimOutbox) Delete(ctx context.Context, userID, goalID string) error {
+	if err := checkContext(ctx, "delete claim outbox entry"); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.entries, progressKey{userID: userID, goalID: goalID})
+	return nil
+}
+
+func (o *memoryClaimOutbox) ListStale(ctx context.Context, before time.Time, limit int) ([]*serviceRepo.ClaimOutboxEntry, error) {
+	if err := checkContext(ctx, "list stale claims"); err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	stale := make([]*serviceRepo.ClaimOutboxEntry, 0)
+	for _, entry := range o.entries {
+		if entry.UpdatedAt.Before(before) {
+			e := entry
+			stale = append(stale, &e)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].UpdatedAt.Before(stale[j].UpdatedAt) })
+	if len(stale) > limit {
+		stale = stale[:limit]
+	}
+	return stale, nil
+}
+
+func (o *memoryClaimOutbox) DeleteIfPending(ctx context.Context, userID, goalID string, before time.Time) (bool, error) {
+	if err := checkContext(ctx, "delete pending claim"); err != nil {
+		return false, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := progressKey{userID: userID, goalID: goalID}
+	entry, ok := o.entries[key]
+	if !ok || entry.State != serviceRepo.ClaimOutboxPending || !entry.UpdatedAt.Before(before) {
+		return false, nil
+	}
+	delete(o.entries, key)
+	return true, nil
+}
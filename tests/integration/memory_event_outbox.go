@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+)
+
+// memoryEventOutbox is an in-memory implementation of
+// repository.EventOutboxRepository. Unlike PostgreSQL it has no triggers, so it
+// only holds the events the service enqueues (goals.selected, player.initialized).
+type memoryEventOutbox struct {
+	mu     sync.Mutex
+	seq    int64
+	events []*serviceRepo.OutboxEvent
+}
+
+// newMemoryEventOutbox creates an empty in-memory event outbox.
+func newMemoryEventOutbox() *memoryEventOutbox {
+	return &memoryEventOutbox{}
+}
+
+func (o *memoryEventOutbox) Enqueue(ctx context.Context, event *serviceRepo.OutboxEvent) error {
+	if err := checkContext(ctx, "enqueue event"); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	stored := *event
+	stored.Seq = o.seq
+	stored.EventID = fmt.Sprintf("event-%d", o.seq)
+	stored.CreatedAt = time.Now().UTC()
+	o.events = append(o.events, &stored)
+	return nil
+}
+
+func (o *memoryEventOutbox) PublishPending(
+	ctx context.Context,
+	limit int,
+	publish func(events []*serviceRepo.OutboxEvent) error,
+) (int, error) {
+	if err := checkContext(ctx, "publish events"); err != nil {
+		return 0, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	batch := o.events[:min(limit, len(o.events))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(batch); err != nil {
+		return 0, err
+	}
+	o.events = o.events[len(batch):]
+	return len(batch), nil
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+)
+
+// memoryProgressQueries serves the user-facing reads of
+// repository.ProgressQueryRepository (pages, snapshots and first completions) from a goal
+// repository, so paginated and consistency=strong reads run without PostgreSQL.
+// The admin reports are not implemented and panic.
+type memoryProgressQueries struct {
+	serviceRepo.ProgressQueryRepository
+	goals commonRepo.GoalRepository
+}
+
+// newMemoryProgressQueries creates progress queries reading from goals.
+func newMemoryProgressQueries(goals commonRepo.GoalRepository) *memoryProgressQueries {
+	return &memoryProgressQueries{goals: goals}
+}
+
+func (q *memoryProgressQueries) GetUserProgressPage(
+	ctx context.Context,
+	userID string,
+	activeOnly bool,
+	afterGoalID string,
+	limit int,
+) ([]*commonDomain.UserGoalProgress, error) {
+	rows, err := q.goals.GetUserProgress(ctx, userID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].GoalID < rows[j].GoalID })
+
+	page := make([]*commonDomain.UserGoalProgress, 0, limit)
+	for _, row := range rows {
+		if row.GoalID > afterGoalID && len(page) < limit {
+			page = append(page, row)
+		}
+	}
+	return page, nil
+}
+
+func (q *memoryProgressQueries) GetUserProgressSnapshot(
+	ctx context.Context,
+	userID, challengeID string,
+	activeOnly bool,
+) (*serviceRepo.ProgressSnapshot, error) {
+	var (
+		rows []*commonDomain.UserGoalProgress
+		err  error
+	)
+	if challengeID == "" {
+		rows, err = q.goals.GetUserProgress(ctx, userID, activeOnly)
+	} else {
+		rows, err = q.goals.GetChallengeProgress(ctx, userID, challengeID, activeOnly)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &serviceRepo.ProgressSnapshot{Rows: rows, TakenAt: time.Now()}, nil
+}
+
+// GetFirstCompletions returns completed_at: the memory repository keeps no
+// completion of reset rows.
+func (q *memoryProgressQueries) GetFirstCompletions(
+	ctx context.Context,
+	userID string,
+	goalIDs []string,
+) (map[string]time.Time, error) {
+	completions := make(map[string]time.Time)
+	for _, goalID := range goalIDs {
+		row, err := q.goals.GetProgress(ctx, userID, goalID)
+		if err != nil {
+			return nil, err
+		}
+		if row != nil && row.CompletedAt != nil {
+			completions[goalID] = *row.CompletedAt
+		}
+	}
+	return completions, nil
+}
@@ -0,0 +1,534 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonErrors "github.com/AccelByte/extend-challenge-common/pkg/errors"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// memoryGoalRepository is an in-memory implementation of repository.GoalRepository,
+// repository.TxRepository and the service's ProgressInsertRepository used when
+// INTEGRATION_DB is not set.
+//
+// It mirrors the SQL semantics of PostgresGoalRepository closely enough for the
+// service flows (claimed rows are never overwritten, BulkInsert is
+// ON CONFLICT DO NOTHING, MarkAsClaimed requires status = 'completed', ...).
+// Transactions are serialized through txMu, which stands in for the row lock
+// taken by GetProgressForUpdate, and work on a private copy of the table that
+// is merged back on Commit. Like database/sql, a transaction is rolled back
+// automatically when the context passed to BeginTx is done.
+//
+// BatchUpsertProgressWithCOPY is only used by the event handler; rotation
+// columns on CopyRow are not simulated.
+type memoryGoalRepository struct {
+	mu    *sync.Mutex
+	txMu  *sync.Mutex
+	table *memoryTable
+
+	// Set only on transaction repositories.
+	parent *memoryGoalRepository
+	done   bool
+}
+
+// finish marks the transaction as done and releases txMu. It reports false if
+// the transaction had already finished.
+func (r *memoryGoalRepository) finish(apply func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.parent == nil || r.done {
+		return false
+	}
+	r.done = true
+	if apply != nil {
+		apply()
+	}
+	r.txMu.Unlock()
+	return true
+}
+
+type progressKey struct {
+	userID string
+	goalID string
+}
+
+type memoryRow struct {
+	progress commonDomain.UserGoalProgress
+	seq      uint64 // insertion order, stands in for ORDER BY created_at
+}
+
+type memoryTable struct {
+	rows  map[progressKey]*memoryRow
+	seq   uint64
+	dirty map[progressKey]struct{} // keys written inside a transaction
+}
+
+// newMemoryGoalRepository creates an empty in-memory repository.
+func newMemoryGoalRepository() *memoryGoalRepository {
+	return &memoryGoalRepository{
+		mu:    &sync.Mutex{},
+		txMu:  &sync.Mutex{},
+		table: &memoryTable{rows: make(map[progressKey]*memoryRow)},
+	}
+}
+
+func (r *memoryGoalRepository) lock() func() {
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+func (t *memoryTable) get(userID, goalID string) *memoryRow {
+	return t.rows[progressKey{userID: userID, goalID: goalID}]
+}
+
+func (t *memoryTable) insert(p commonDomain.UserGoalProgress) {
+	t.seq++
+	key := progressKey{userID: p.UserID, goalID: p.GoalID}
+	t.rows[key] = &memoryRow{progress: p, seq: t.seq}
+	t.touch(key)
+}
+
+func (t *memoryTable) touch(key progressKey) {
+	if t.dirty != nil {
+		t.dirty[key] = struct{}{}
+	}
+}
+
+func (t *memoryTable) clone() *memoryTable {
+	c := &memoryTable{
+		rows:  make(map[progressKey]*memoryRow, len(t.rows)),
+		seq:   t.seq,
+		dirty: make(map[progressKey]struct{}),
+	}
+	for k, row := range t.rows {
+		copied := *row
+		c.rows[k] = &copied
+	}
+	return c
+}
+
+// selectRows returns copies of the rows matching filter ordered by insertion.
+func (t *memoryTable) selectRows(filter func(*commonDomain.UserGoalProgress) bool) []*commonDomain.UserGoalProgress {
+	matched := make([]*memoryRow, 0)
+	for _, row := range t.rows {
+		if filter(&row.progress) {
+			matched = append(matched, row)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].seq < matched[j].seq })
+
+	result := make([]*commonDomain.UserGoalProgress, len(matched))
+	for i, row := range matched {
+		p := row.progress
+		result[i] = &p
+	}
+	return result
+}
+
+func checkContext(ctx context.Context, operation string) error {
+	if err := ctx.Err(); err != nil {
+		return commonErrors.ErrDatabaseError(operation, err)
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) GetProgress(ctx context.Context, userID, goalID string) (*commonDomain.UserGoalProgress, error) {
+	if err := checkContext(ctx, "get progress"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	row := r.table.get(userID, goalID)
+	if row == nil {
+		return nil, nil
+	}
+	p := row.progress
+	return &p, nil
+}
+
+func (r *memoryGoalRepository) GetProgressForUpdate(ctx context.Context, userID, goalID string) (*commonDomain.UserGoalProgress, error) {
+	if r.parent == nil {
+		return nil, fmt.Errorf("GetProgressForUpdate requires a transaction")
+	}
+	return r.GetProgress(ctx, userID, goalID)
+}
+
+func (r *memoryGoalRepository) GetUserProgress(ctx context.Context, userID string, activeOnly bool) ([]*commonDomain.UserGoalProgress, error) {
+	if err := checkContext(ctx, "get user progress"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	return r.table.selectRows(func(p *commonDomain.UserGoalProgress) bool {
+		return p.UserID == userID && (!activeOnly || p.IsActive)
+	}), nil
+}
+
+func (r *memoryGoalRepository) GetChallengeProgress(ctx context.Context, userID, challengeID string, activeOnly bool) ([]*commonDomain.UserGoalProgress, error) {
+	if err := checkContext(ctx, "get challenge progress"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	return r.table.selectRows(func(p *commonDomain.UserGoalProgress) bool {
+		return p.UserID == userID && p.ChallengeID == challengeID && (!activeOnly || p.IsActive)
+	}), nil
+}
+
+func (r *memoryGoalRepository) UpsertProgress(ctx context.Context, progress *commonDomain.UserGoalProgress) error {
+	if err := checkContext(ctx, "upsert progress"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	row := r.table.get(progress.UserID, progress.GoalID)
+	if row == nil {
+		p := *progress
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		r.table.insert(p)
+		return nil
+	}
+	if row.progress.Status == commonDomain.GoalStatusClaimed {
+		return nil
+	}
+
+	row.progress.Progress = progress.Progress
+	row.progress.Status = progress.Status
+	row.progress.CompletedAt = progress.CompletedAt
+	row.progress.IsActive = progress.IsActive
+	row.progress.AssignedAt = progress.AssignedAt
+	row.progress.ExpiresAt = progress.ExpiresAt
+	row.progress.UpdatedAt = now
+	r.table.touch(progressKey{userID: progress.UserID, goalID: progress.GoalID})
+	return nil
+}
+
+func (r *memoryGoalRepository) BatchUpsertProgress(ctx context.Context, updates []*commonDomain.UserGoalProgress) error {
+	if err := checkContext(ctx, "batch upsert progress"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	for _, update := range updates {
+		row := r.table.get(update.UserID, update.GoalID)
+		if row == nil {
+			r.table.insert(commonDomain.UserGoalProgress{
+				UserID:      update.UserID,
+				GoalID:      update.GoalID,
+				ChallengeID: update.ChallengeID,
+				Namespace:   update.Namespace,
+				Progress:    update.Progress,
+				Status:      update.Status,
+				CompletedAt: update.CompletedAt,
+				IsActive:    true, // column default
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+			continue
+		}
+		if row.progress.Status == commonDomain.GoalStatusClaimed || !row.progress.IsActive {
+			continue
+		}
+
+		row.progress.Progress = update.Progress
+		row.progress.Status = update.Status
+		row.progress.CompletedAt = update.CompletedAt
+		row.progress.UpdatedAt = now
+		r.table.touch(progressKey{userID: update.UserID, goalID: update.GoalID})
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) BatchUpsertProgressWithCOPY(ctx context.Context, rows []commonRepo.CopyRow) error {
+	if err := checkContext(ctx, "batch upsert progress with COPY"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	for _, copyRow := range rows {
+		row := r.table.get(copyRow.UserID, copyRow.GoalID)
+		if row == nil || !row.progress.IsActive || row.progress.Status == commonDomain.GoalStatusClaimed {
+			continue
+		}
+
+		progress := row.progress.Progress + copyRow.IncValue
+		if copyRow.Progress != nil {
+			progress = *copyRow.Progress
+		}
+
+		row.progress.Progress = progress
+		if row.progress.Status != commonDomain.GoalStatusCompleted {
+			switch {
+			case progress >= copyRow.TargetValue:
+				row.progress.Status = commonDomain.GoalStatusCompleted
+				row.progress.CompletedAt = &now
+			case progress > 0:
+				row.progress.Status = commonDomain.GoalStatusInProgress
+			}
+		}
+		row.progress.UpdatedAt = now
+		r.table.touch(progressKey{userID: copyRow.UserID, goalID: copyRow.GoalID})
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) MarkAsClaimed(ctx context.Context, userID, goalID string) error {
+	if err := checkContext(ctx, "mark as claimed"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	row := r.table.get(userID, goalID)
+	if row == nil || row.progress.Status != commonDomain.GoalStatusCompleted || row.progress.ClaimedAt != nil {
+		return commonErrors.ErrGoalNotCompleted(goalID)
+	}
+
+	now := time.Now().UTC()
+	row.progress.Status = commonDomain.GoalStatusClaimed
+	row.progress.ClaimedAt = &now
+	row.progress.UpdatedAt = now
+	r.table.touch(progressKey{userID: userID, goalID: goalID})
+	return nil
+}
+
+func (r *memoryGoalRepository) BeginTx(ctx context.Context) (commonRepo.TxRepository, error) {
+	if r.parent != nil {
+		return nil, fmt.Errorf("cannot begin nested transaction")
+	}
+	if err := checkContext(ctx, "begin transaction"); err != nil {
+		return nil, err
+	}
+
+	r.txMu.Lock()
+	r.mu.Lock()
+	snapshot := r.table.clone()
+	r.mu.Unlock()
+
+	tx := &memoryGoalRepository{
+		mu:     &sync.Mutex{},
+		txMu:   r.txMu,
+		table:  snapshot,
+		parent: r,
+	}
+
+	go func() {
+		<-ctx.Done()
+		tx.finish(nil)
+	}()
+
+	return tx, nil
+}
+
+func (r *memoryGoalRepository) Commit() error {
+	committed := r.finish(func() {
+		r.parent.mu.Lock()
+		defer r.parent.mu.Unlock()
+
+		for key := range r.table.dirty {
+			row := *r.table.rows[key]
+			if existing := r.parent.table.rows[key]; existing != nil {
+				row.seq = existing.seq
+			} else {
+				r.parent.table.seq++
+				row.seq = r.parent.table.seq
+			}
+			r.parent.table.rows[key] = &row
+		}
+	})
+	if !committed {
+		return commonErrors.ErrDatabaseError("commit transaction", sql.ErrTxDone)
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) Rollback() error {
+	if !r.finish(nil) {
+		return commonErrors.ErrDatabaseError("rollback transaction", sql.ErrTxDone)
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) GetGoalsByIDs(ctx context.Context, userID string, goalIDs []string) ([]*commonDomain.UserGoalProgress, error) {
+	if err := checkContext(ctx, "get goals by IDs"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	wanted := make(map[string]struct{}, len(goalIDs))
+	for _, id := range goalIDs {
+		wanted[id] = struct{}{}
+	}
+	return r.table.selectRows(func(p *commonDomain.UserGoalProgress) bool {
+		_, ok := wanted[p.GoalID]
+		return p.UserID == userID && ok
+	}), nil
+}
+
+func (r *memoryGoalRepository) BulkInsert(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
+	if err := checkContext(ctx, "bulk insert goals"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	for _, progress := range progresses {
+		if r.table.get(progress.UserID, progress.GoalID) != nil {
+			continue // ON CONFLICT DO NOTHING
+		}
+		p := *progress
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		r.table.insert(p)
+	}
+	return nil
+}
+
+// BulkInsertReturningConflicts implements repository.ProgressInsertRepository.
+// No constraint other than the primary key is simulated, so no row fails.
+func (r *memoryGoalRepository) BulkInsertReturningConflicts(
+	ctx context.Context,
+	progresses []*commonDomain.UserGoalProgress,
+) (*serviceRepo.BulkInsertResult, error) {
+	if err := checkContext(ctx, "bulk insert goals"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	result := &serviceRepo.BulkInsertResult{}
+	now := time.Now().UTC()
+	for _, progress := range progresses {
+		key := serviceRepo.UserGoalKey{UserID: progress.UserID, GoalID: progress.GoalID}
+		if r.table.get(progress.UserID, progress.GoalID) != nil {
+			result.Existing = append(result.Existing, key)
+			continue
+		}
+		p := *progress
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		r.table.insert(p)
+		result.Inserted = append(result.Inserted, key)
+	}
+	return result, nil
+}
+
+func (r *memoryGoalRepository) BulkInsertWithCOPY(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
+	return r.BulkInsert(ctx, progresses)
+}
+
+func (r *memoryGoalRepository) UpsertGoalActive(ctx context.Context, progress *commonDomain.UserGoalProgress) error {
+	if err := checkContext(ctx, "update goal active"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	row := r.table.get(progress.UserID, progress.GoalID)
+	if row == nil {
+		p := commonDomain.UserGoalProgress{
+			UserID:      progress.UserID,
+			GoalID:      progress.GoalID,
+			ChallengeID: progress.ChallengeID,
+			Namespace:   progress.Namespace,
+			Status:      commonDomain.GoalStatusNotStarted,
+			IsActive:    progress.IsActive,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if progress.IsActive {
+			p.AssignedAt = &now
+		}
+		r.table.insert(p)
+		return nil
+	}
+
+	row.progress.IsActive = progress.IsActive
+	if progress.IsActive {
+		row.progress.AssignedAt = &now
+	}
+	row.progress.UpdatedAt = now
+	r.table.touch(progressKey{userID: progress.UserID, goalID: progress.GoalID})
+	return nil
+}
+
+func (r *memoryGoalRepository) BatchUpsertGoalActive(ctx context.Context, progresses []*commonDomain.UserGoalProgress) error {
+	if err := checkContext(ctx, "batch update goal active"); err != nil {
+		return err
+	}
+	defer r.lock()()
+
+	now := time.Now().UTC()
+	for _, progress := range progresses {
+		var assignedAt *time.Time
+		if progress.IsActive {
+			assignedAt = &now
+		}
+
+		row := r.table.get(progress.UserID, progress.GoalID)
+		if row == nil {
+			r.table.insert(commonDomain.UserGoalProgress{
+				UserID:      progress.UserID,
+				GoalID:      progress.GoalID,
+				ChallengeID: progress.ChallengeID,
+				Namespace:   progress.Namespace,
+				Status:      commonDomain.GoalStatusNotStarted,
+				IsActive:    progress.IsActive,
+				AssignedAt:  assignedAt,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+			continue
+		}
+
+		row.progress.IsActive = progress.IsActive
+		row.progress.AssignedAt = assignedAt
+		row.progress.UpdatedAt = now
+		r.table.touch(progressKey{userID: progress.UserID, goalID: progress.GoalID})
+	}
+	return nil
+}
+
+func (r *memoryGoalRepository) GetUserGoalCount(ctx context.Context, userID string) (int, error) {
+	if err := checkContext(ctx, "get user goal count"); err != nil {
+		return 0, err
+	}
+	defer r.lock()()
+
+	count := 0
+	for key := range r.table.rows {
+		if key.userID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryGoalRepository) GetActiveGoals(ctx context.Context, userID string) ([]*commonDomain.UserGoalProgress, error) {
+	if err := checkContext(ctx, "get active goals"); err != nil {
+		return nil, err
+	}
+	defer r.lock()()
+
+	goals := r.table.selectRows(func(p *commonDomain.UserGoalProgress) bool {
+		return p.UserID == userID && p.IsActive
+	})
+	sort.SliceStable(goals, func(i, j int) bool {
+		if goals[i].ChallengeID != goals[j].ChallengeID {
+			return goals[i].ChallengeID < goals[j].ChallengeID
+		}
+		return goals[i].GoalID < goals[j].GoalID
+	})
+	return goals, nil
+}
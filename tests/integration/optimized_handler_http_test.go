@@ -16,6 +16,7 @@ import (
 	"extend-challenge-service/pkg/handler"
 	"extend-challenge-service/pkg/mapper"
 	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/testutil/mocks"
 
 	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
 	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
@@ -24,7 +25,7 @@ import (
 )
 
 // setupOptimizedHandler creates an optimized challenges handler for testing
-func setupOptimizedHandler(t *testing.T) (*handler.OptimizedChallengesHandler, *MockGoalRepository, func()) {
+func setupOptimizedHandler(t *testing.T) (*handler.OptimizedChallengesHandler, *mocks.GoalRepository, func()) {
 	// Truncate tables
 	truncateTables(t, testDB)
 
@@ -43,7 +44,7 @@ func setupOptimizedHandler(t *testing.T) (*handler.OptimizedChallengesHandler, *
 	// Convert domain challenges to protobuf format for cache warm-up
 	pbChallenges := make([]*pb.Challenge, 0, len(challengeConfig.Challenges))
 	for _, domainChallenge := range goalCache.GetAllChallenges() {
-		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, time.Now().UTC())
+		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, nil, time.Now().UTC())
 		if err != nil {
 			t.Fatalf("Failed to convert challenge %s: %v", domainChallenge.ID, err)
 		}
@@ -56,12 +57,13 @@ func setupOptimizedHandler(t *testing.T) (*handler.OptimizedChallengesHandler, *
 	}
 
 	// Use mock repository for controlled testing
-	mockRepo := new(MockGoalRepository)
+	mockRepo := new(mocks.GoalRepository)
 
 	// Create optimized handler (auth disabled for testing)
 	h := handler.NewOptimizedChallengesHandler(
 		goalCache,
 		mockRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -95,7 +97,7 @@ func setupOptimizedHandlerWithRealDB(t *testing.T) (*handler.OptimizedChallenges
 	// Convert domain challenges to protobuf format for cache warm-up
 	pbChallenges := make([]*pb.Challenge, 0, len(challengeConfig.Challenges))
 	for _, domainChallenge := range goalCache.GetAllChallenges() {
-		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, time.Now().UTC())
+		pbChallenge, err := mapper.ChallengeToProto(domainChallenge, nil, nil, time.Now().UTC())
 		if err != nil {
 			t.Fatalf("Failed to convert challenge %s: %v", domainChallenge.ID, err)
 		}
@@ -114,6 +116,7 @@ func setupOptimizedHandlerWithRealDB(t *testing.T) (*handler.OptimizedChallenges
 	h := handler.NewOptimizedChallengesHandler(
 		goalCache,
 		realRepo,
+		nil,
 		serCache,
 		"test-namespace",
 		false, // auth disabled
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"extend-challenge-service/pkg/repository"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+const (
+	benchUserID    = "bench-user"
+	benchGoalCount = 10000
+	benchPageSize  = 50
+)
+
+// seedBenchProgress inserts benchGoalCount active progress rows for benchUserID
+// into a fresh schema and returns the connection pool.
+func seedBenchProgress(b *testing.B) *sql.DB {
+	b.Helper()
+
+	db := createTestSchema(b)
+	repo := commonRepo.NewPostgresGoalRepository(db)
+
+	now := time.Now().UTC()
+	rows := make([]*commonDomain.UserGoalProgress, benchGoalCount)
+	for i := range rows {
+		rows[i] = &commonDomain.UserGoalProgress{
+			UserID:      benchUserID,
+			GoalID:      fmt.Sprintf("goal-%05d", i),
+			ChallengeID: fmt.Sprintf("challenge-%03d", i/100),
+			Namespace:   "test-namespace",
+			Progress:    i % 10,
+			Status:      commonDomain.GoalStatusInProgress,
+			IsActive:    true,
+			AssignedAt:  &now,
+		}
+	}
+	if err := repo.BulkInsertWithCOPY(context.Background(), rows); err != nil {
+		b.Fatalf("Failed to seed benchmark progress: %v", err)
+	}
+	if _, err := db.Exec("ANALYZE user_goal_progress"); err != nil {
+		b.Fatalf("Failed to analyze user_goal_progress: %v", err)
+	}
+
+	return db
+}
+
+// BenchmarkGetUserProgress_Full loads all 10k rows, which is what every
+// unpaginated challenges request does.
+func BenchmarkGetUserProgress_Full(b *testing.B) {
+	repo := commonRepo.NewPostgresGoalRepository(seedBenchProgress(b))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := repo.GetUserProgress(ctx, benchUserID, true)
+		if err != nil || len(rows) != benchGoalCount {
+			b.Fatalf("GetUserProgress: %d rows, err=%v", len(rows), err)
+		}
+	}
+}
+
+// BenchmarkGetUserProgressPage loads one page from the middle of the keyset,
+// which is what a paginated challenges request does.
+func BenchmarkGetUserProgressPage(b *testing.B) {
+	pages := repository.NewPostgresProgressQueryRepository(seedBenchProgress(b))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := pages.GetUserProgressPage(ctx, benchUserID, true, "goal-05000", benchPageSize+1)
+		if err != nil || len(rows) != benchPageSize+1 {
+			b.Fatalf("GetUserProgressPage: %d rows, err=%v", len(rows), err)
+		}
+	}
+}
+
+// BenchmarkCountByStatus is the unclaimed rewards badge query over the same 10k
+// rows, uncached: it should stay far below a full progress load.
+func BenchmarkCountByStatus(b *testing.B) {
+	queries := repository.NewPostgresProgressQueryRepository(seedBenchProgress(b))
+	ctx := context.Background()
+	statuses := []commonDomain.GoalStatus{commonDomain.GoalStatusCompleted, commonDomain.GoalStatusInProgress}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		counts, err := queries.CountByStatus(ctx, benchUserID, "test-namespace", statuses)
+		if err != nil || counts[commonDomain.GoalStatusInProgress] != benchGoalCount {
+			b.Fatalf("CountByStatus: %v, err=%v", counts, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// TestGetProgressSummary_CountsByStatus tests the aggregated summary against real rows
+func TestGetProgressSummary_CountsByStatus(t *testing.T) {
+	client, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	seedClaimedGoal(t, testDB, "summary-user", "complete-tutorial", "winter-challenge-2025")
+	seedCompletedGoal(t, testDB, "summary-user", "kill-10-snowmen", "winter-challenge-2025")
+	seedInProgressActiveGoal(t, testDB, "summary-user", "login-today", "daily-quests", 0, 1)
+
+	// Same user in another namespace must not be counted
+	_, err := testDB.Exec(`
+		INSERT INTO user_goal_progress
+		(user_id, goal_id, challenge_id, namespace, progress, status, is_active, completed_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 10, 'completed', true, $5, $5, $5)
+	`, "summary-user", "play-3-matches", "daily-quests", "other-namespace", time.Now())
+	require.NoError(t, err)
+
+	ctx := createAuthContext("summary-user", "test-namespace")
+	resp, err := client.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{})
+	require.NoError(t, err)
+
+	// Test config: winter-challenge-2025 (3), daily-quests (2), rotation-challenge (2)
+	assert.Equal(t, int32(7), resp.TotalGoals)
+	assert.Equal(t, int32(2), resp.CompletedGoals)
+	assert.Equal(t, int32(1), resp.ClaimedGoals)
+	assert.Equal(t, int32(1), resp.InProgressGoals)
+	require.Len(t, resp.Challenges, 3)
+
+	// active_only counts only the claimed (active) and in-progress active rows
+	resp, err = client.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TotalGoals)
+	assert.Equal(t, int32(1), resp.CompletedGoals)
+	assert.Equal(t, int32(1), resp.ClaimedGoals)
+	assert.Equal(t, int32(1), resp.InProgressGoals)
+}
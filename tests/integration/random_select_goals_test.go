@@ -6,7 +6,6 @@ package integration
 
 import (
 	"testing"
-	"time"
 
 	pb "extend-challenge-service/pkg/pb"
 
@@ -380,8 +379,7 @@ func TestRandomSelectGoals_VerifyTimestamps(t *testing.T) {
 	for _, goal := range resp.SelectedGoals {
 		assert.NotEmpty(t, goal.AssignedAt, "AssignedAt should be set for goal %s", goal.GoalId)
 
-		// Validate timestamp format (RFC3339)
-		_, err = time.Parse(time.RFC3339, goal.AssignedAt)
-		assert.NoError(t, err, "AssignedAt should be valid RFC3339 timestamp for goal %s", goal.GoalId)
+		// Validate timestamp
+		assert.NoError(t, goal.AssignedAt.CheckValid(), "AssignedAt should be a valid timestamp for goal %s", goal.GoalId)
 	}
 }
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	serviceRepo "extend-challenge-service/pkg/repository"
+	"extend-challenge-service/pkg/service"
+)
+
+// TestRewardCap_ExactlyNUnderConcurrentClaims claims a capped goal for 20
+// users at once against PostgreSQL: exactly the cap's limit get the goal's
+// reward and the rest the fallback, decided by the counter row alone.
+func TestRewardCap_ExactlyNUnderConcurrentClaims(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+	ctx := context.Background()
+
+	const (
+		users = 20
+		limit = 5
+	)
+	goal := &commonDomain.Goal{
+		ID: "first-finishers", ChallengeID: "launch", Name: "First finishers",
+		EventSource: commonDomain.EventSourceStatistic,
+		Requirement: commonDomain.Requirement{StatCode: "wins", Operator: ">=", TargetValue: 1},
+		Reward:      commonDomain.Reward{Type: string(commonDomain.RewardTypeItem), RewardID: "launch-skin", Quantity: 1},
+	}
+	fallback := commonDomain.Reward{Type: string(commonDomain.RewardTypeWallet), RewardID: "GOLD", Quantity: 50}
+	goalCache := commonCache.NewInMemoryGoalCache(&commonConfig.Config{Challenges: []*commonDomain.Challenge{
+		{ID: "launch", Name: "Launch", Goals: []*commonDomain.Goal{goal}},
+	}}, "", slog.Default())
+
+	repo := commonRepo.NewPostgresGoalRepository(db)
+	now := time.Now().UTC()
+	rows := make([]*commonDomain.UserGoalProgress, users)
+	for i := range rows {
+		rows[i] = &commonDomain.UserGoalProgress{
+			UserID: fmt.Sprintf("cap-user-%02d", i), GoalID: goal.ID, ChallengeID: goal.ChallengeID,
+			Namespace: "test-namespace", Progress: 1, Status: commonDomain.GoalStatusCompleted,
+			CompletedAt: &now, IsActive: true, AssignedAt: &now,
+		}
+	}
+	require.NoError(t, repo.BulkInsertWithCOPY(ctx, rows))
+
+	rewardClient := commonClient.NewMockRewardClient()
+	rewardClient.On("GrantReward", mock.Anything, "test-namespace", mock.Anything, mock.Anything).Return(nil)
+	outbox := serviceRepo.NewPostgresClaimOutboxRepository(db)
+	capCtx := service.WithRewardCaps(ctx,
+		service.RewardCaps{goal.ID: {Limit: limit, FallbackReward: &fallback}},
+		serviceRepo.NewPostgresRewardCapRepository(db))
+
+	results := runConcurrently(users, func(i int) (*service.ClaimResult, error) {
+		return service.ClaimGoalReward(capCtx, rows[i].UserID, goal.ID, goal.ChallengeID, "test-namespace",
+			goalCache, repo, outbox, 0, rewardClient, service.SegmentTargets{}, service.ClaimPrecondition{}, logrus.StandardLogger())
+	})
+
+	granted := map[string]int{}
+	for i, result := range results {
+		require.NoError(t, result.Err, rows[i].UserID)
+		require.NotNil(t, result.Resp.RewardCap)
+		granted[result.Resp.RewardCap.Granted]++
+		switch result.Resp.RewardCap.Granted {
+		case service.RewardCapGrantedCapped:
+			assert.Equal(t, goal.Reward, result.Resp.Reward)
+		case service.RewardCapGrantedFallback:
+			assert.Equal(t, fallback, result.Resp.Reward)
+			assert.Zero(t, result.Resp.RewardCap.Remaining)
+		}
+	}
+	assert.Equal(t, map[string]int{
+		service.RewardCapGrantedCapped:   limit,
+		service.RewardCapGrantedFallback: users - limit,
+	}, granted)
+	rewardClient.AssertNumberOfCalls(t, "GrantReward", users)
+
+	var claimed, slotsHeld int
+	require.NoError(t, db.QueryRow(`SELECT claimed FROM reward_cap_counters WHERE goal_id = $1`, goal.ID).Scan(&claimed))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM claim_outbox WHERE reward_cap_slot`).Scan(&slotsHeld))
+	assert.Equal(t, limit, claimed)
+	assert.Zero(t, slotsHeld, "finished claims delete their outbox entries")
+}
+
+// TestRewardCap_ReleasedSlotIsTakenAgain checks that a claim whose grant fails
+// gives its slot back to the next claimant.
+func TestRewardCap_ReleasedSlotIsTakenAgain(t *testing.T) {
+	t.Parallel()
+	db := createTestSchema(t)
+	ctx := context.Background()
+
+	outbox := serviceRepo.NewPostgresClaimOutboxRepository(db)
+	caps := serviceRepo.NewPostgresRewardCapRepository(db)
+	reserve := func(userID string) {
+		t.Helper()
+		reserved, err := outbox.Reserve(ctx, &serviceRepo.ClaimOutboxEntry{
+			UserID: userID, GoalID: "capped", ChallengeID: "launch", Namespace: "test-namespace",
+		})
+		require.NoError(t, err)
+		require.True(t, reserved)
+	}
+
+	reserve("user-a")
+	taken, claimed, err := caps.TakeSlot(ctx, "user-a", "capped", 1)
+	require.NoError(t, err)
+	assert.True(t, taken)
+	assert.Equal(t, 1, claimed)
+
+	reserve("user-b")
+	taken, _, err = caps.TakeSlot(ctx, "user-b", "capped", 1)
+	require.NoError(t, err)
+	assert.False(t, taken, "the only slot is held by user-a")
+
+	// user-a's grant failed: the slot goes back before the reservation is deleted
+	require.NoError(t, caps.ReleaseSlot(ctx, "user-a", "capped"))
+	require.NoError(t, outbox.Delete(ctx, "user-a", "capped"))
+
+	taken, claimed, err = caps.TakeSlot(ctx, "user-b", "capped", 1)
+	require.NoError(t, err)
+	assert.True(t, taken)
+	assert.Equal(t, 1, claimed)
+
+	// A stale pending entry released by recovery gives its slot back too
+	deleted, err := outbox.DeleteIfPending(ctx, "user-b", "capped", time.Now().UTC().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	require.NoError(t, db.QueryRow(`SELECT claimed FROM reward_cap_counters WHERE goal_id = 'capped'`).Scan(&claimed))
+	assert.Zero(t, claimed)
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/server"
+)
+
+// TestStartSession_MatchesIndividualCalls checks the combined session start
+// response against InitializePlayer, GetProgressSummary (active only) and
+// GetUnclaimedCount called one by one on the same rows.
+func TestStartSession_MatchesIndividualCalls(t *testing.T) {
+	client, _, cleanup := setupConfiguredTestServer(t, func(s *server.ChallengeServiceServer) {
+		s.SetSessionStart(true)
+	})
+	defer cleanup()
+
+	seedClaimedGoal(t, testDB, "session-user", "complete-tutorial", "winter-challenge-2025")
+	seedCompletedActiveGoal(t, testDB, "session-user", "kill-10-snowmen", "winter-challenge-2025")
+	seedInProgressActiveGoal(t, testDB, "session-user", "login-today", "daily-quests", 0, 1)
+	// Inactive goals count in neither the active summary nor the badge
+	seedCompletedGoal(t, testDB, "session-user", "play-3-matches", "daily-quests")
+
+	ctx := createAuthContext("session-user", "test-namespace")
+	session, err := client.StartSession(ctx, &pb.StartSessionRequest{})
+	require.NoError(t, err)
+
+	initialized, err := client.InitializePlayer(ctx, &pb.InitializeRequest{})
+	require.NoError(t, err)
+	summary, err := client.GetProgressSummary(ctx, &pb.GetProgressSummaryRequest{ActiveOnly: true})
+	require.NoError(t, err)
+	unclaimed, err := client.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, initialized.TotalActive, session.Initialize.TotalActive)
+	assert.Equal(t, initialized.NewAssignments, session.Initialize.NewAssignments)
+	require.Len(t, session.Initialize.AssignedGoals, len(initialized.AssignedGoals))
+	for i, goal := range initialized.AssignedGoals {
+		got := session.Initialize.AssignedGoals[i]
+		assert.Equal(t, goal.GoalId, got.GoalId)
+		assert.Equal(t, goal.Status, got.Status, goal.GoalId)
+		assert.Equal(t, goal.Progress, got.Progress, goal.GoalId)
+		assert.Equal(t, goal.ActivationSource, got.ActivationSource, goal.GoalId)
+	}
+
+	assert.Equal(t, summary.TotalGoals, session.Summary.TotalGoals)
+	assert.Equal(t, summary.CompletedGoals, session.Summary.CompletedGoals)
+	assert.Equal(t, summary.ClaimedGoals, session.Summary.ClaimedGoals)
+	assert.Equal(t, summary.InProgressGoals, session.Summary.InProgressGoals)
+	require.Len(t, session.Summary.Challenges, len(summary.Challenges))
+	for i, challenge := range summary.Challenges {
+		got := session.Summary.Challenges[i]
+		assert.Equal(t, challenge.ChallengeId, got.ChallengeId)
+		assert.Equal(t, challenge.TotalGoals, got.TotalGoals, challenge.ChallengeId)
+		assert.Equal(t, challenge.CompletedGoals, got.CompletedGoals, challenge.ChallengeId)
+		assert.Equal(t, challenge.ClaimedGoals, got.ClaimedGoals, challenge.ChallengeId)
+		assert.Equal(t, challenge.InProgressGoals, got.InProgressGoals, challenge.ChallengeId)
+		assert.Equal(t, challenge.NextResetAt.AsTime(), got.NextResetAt.AsTime(), challenge.ChallengeId)
+	}
+
+	assert.Equal(t, unclaimed.HasUnclaimed, session.Unclaimed.HasUnclaimed)
+	assert.Equal(t, unclaimed.UnclaimedCount, session.Unclaimed.UnclaimedCount)
+	assert.Equal(t, unclaimed.InProgressCount, session.Unclaimed.InProgressCount)
+	assert.Equal(t, int32(1), session.Unclaimed.UnclaimedCount)
+}
+
+// TestStartSession_DisabledByDefault checks that the endpoint is off unless enabled.
+func TestStartSession_DisabledByDefault(t *testing.T) {
+	client, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	_, err := client.StartSession(createAuthContext("session-user", "test-namespace"), &pb.StartSessionRequest{})
+
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pb "extend-challenge-service/pkg/pb"
+)
+
+// TestGetUnclaimedCount_InvalidatedByClaim checks the badge counts against real
+// rows and that a claim on this pod refreshes them at once.
+func TestGetUnclaimedCount_InvalidatedByClaim(t *testing.T) {
+	client, mockRewardClient, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	seedClaimedGoal(t, testDB, "badge-user", "complete-tutorial", "winter-challenge-2025")
+	seedCompletedActiveGoal(t, testDB, "badge-user", "kill-10-snowmen", "winter-challenge-2025")
+	seedInProgressActiveGoal(t, testDB, "badge-user", "login-today", "daily-quests", 0, 1)
+	// Inactive goals cannot be claimed, so they do not light the badge
+	seedCompletedGoal(t, testDB, "badge-user", "play-3-matches", "daily-quests")
+
+	ctx := createAuthContext("badge-user", "test-namespace")
+	resp, err := client.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+	require.NoError(t, err)
+	assert.True(t, resp.HasUnclaimed)
+	assert.Equal(t, int32(1), resp.UnclaimedCount)
+	assert.Equal(t, int32(1), resp.InProgressCount)
+
+	mockRewardClient.On("GrantReward", mock.Anything, "test-namespace", "badge-user", mock.Anything).Return(nil)
+	_, err = client.ClaimGoalReward(ctx, &pb.ClaimRewardRequest{ChallengeId: "winter-challenge-2025", GoalId: "kill-10-snowmen"})
+	require.NoError(t, err)
+
+	resp, err = client.GetUnclaimedCount(ctx, &pb.GetUnclaimedCountRequest{})
+	require.NoError(t, err)
+	assert.False(t, resp.HasUnclaimed, "the claim invalidates the cached counts")
+	assert.Zero(t, resp.UnclaimedCount)
+}
@@ -0,0 +1,204 @@
+//go:build load
+
+package load
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "extend-challenge-service/pkg/pb"
+	"extend-challenge-service/pkg/server"
+	"extend-challenge-service/pkg/service"
+
+	commonCache "github.com/AccelByte/extend-challenge-common/pkg/cache"
+	commonClient "github.com/AccelByte/extend-challenge-common/pkg/client"
+	commonConfig "github.com/AccelByte/extend-challenge-common/pkg/config"
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+// maxErrorSamples caps the unexpected errors kept in the report.
+const maxErrorSamples = 10
+
+// claimJob is one ClaimGoalReward call of the run.
+type claimJob struct {
+	userID string
+	goal   *commonDomain.Goal
+}
+
+// claimResult is the outcome of a claimJob.
+type claimResult struct {
+	latency time.Duration
+	code    codes.Code
+	err     error
+}
+
+// TestClaimLoad seeds LOAD_USERS users with every goal of testdata/challenges.json
+// completed, then claims them all through the in-process gRPC server with
+// LOAD_CONCURRENCY claims in flight, each goal LOAD_CLAIMS_PER_GOAL times. It
+// fails if a claim errors, if the p50 or p99 latency of successful claims is
+// over budget, or if the mock reward client's ledger shows a reward granted
+// twice or not at all.
+func TestClaimLoad(t *testing.T) {
+	cfg := loadConfigFromEnv(t)
+	db := openLoadSchema(t)
+
+	// Request logs would dominate the timings; the service logs through logrus
+	previousOutput := logrus.StandardLogger().Out
+	logrus.SetOutput(io.Discard)
+	t.Cleanup(func() { logrus.SetOutput(previousOutput) })
+
+	challengeConfig, err := commonConfig.NewConfigLoader(configPath, discardLogger).LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load challenge config: %v", err)
+	}
+	goalCache := commonCache.NewInMemoryGoalCache(challengeConfig, configPath, discardLogger)
+	goals := goalCache.GetAllGoals()
+
+	repo := commonRepo.NewPostgresGoalRepository(db)
+	userIDs := make([]string, cfg.Users)
+	for i := range userIDs {
+		userIDs[i] = fmt.Sprintf("load-user-%06d", i)
+	}
+	seedCompletedGoals(t, repo, userIDs, goals)
+
+	rewardClient := commonClient.NewMockRewardClient()
+	rewardClient.On("GrantReward", mock.Anything, loadNamespace, mock.Anything, mock.Anything).Return(nil)
+
+	challengeServer := server.NewChallengeServiceServer(goalCache, repo, rewardClient, db, loadNamespace)
+	challengePrereqs, err := service.LoadChallengePrerequisites(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load challenge prerequisites: %v", err)
+	}
+	challengeServer.SetChallengePrerequisites(challengePrereqs)
+	client := serveBufconn(t, challengeServer)
+
+	// Goals are claimed in random order; the repeats of a goal follow it, so
+	// they reach the server while the first claim is in flight
+	jobs := make([]claimJob, 0, cfg.Users*len(goals)*cfg.ClaimsPerGoal)
+	pairs := make([]claimJob, 0, cfg.Users*len(goals))
+	for _, userID := range userIDs {
+		for _, goal := range goals {
+			pairs = append(pairs, claimJob{userID: userID, goal: goal})
+		}
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+	for _, pair := range pairs {
+		for range cfg.ClaimsPerGoal {
+			jobs = append(jobs, pair)
+		}
+	}
+
+	report := &claimLoadReport{
+		StartedAt:     time.Now().UTC(),
+		Users:         cfg.Users,
+		GoalsPerUser:  len(goals),
+		ClaimsPerGoal: cfg.ClaimsPerGoal,
+		Concurrency:   cfg.Concurrency,
+		Claims:        len(jobs),
+		Budget:        budgetReport{P50: milliseconds(cfg.P50Budget), P99: milliseconds(cfg.P99Budget)},
+	}
+
+	results := runClaims(context.Background(), client, jobs, cfg.Concurrency)
+	report.DurationMs = milliseconds(time.Since(report.StartedAt))
+	report.ClaimsPerSec = float64(len(jobs)) / (report.DurationMs / 1000)
+
+	succeeded := make(map[string]int, len(pairs))
+	var latencies []time.Duration
+	for i, result := range results {
+		switch result.code {
+		case codes.OK:
+			report.Succeeded++
+			succeeded[jobs[i].userID+"/"+jobs[i].goal.ID]++
+			latencies = append(latencies, result.latency)
+		case codes.AlreadyExists:
+			report.AlreadyClaimed++
+		default:
+			report.Errors++
+			if len(report.ErrorSamples) < maxErrorSamples {
+				report.ErrorSamples = append(report.ErrorSamples,
+					fmt.Sprintf("%s %s: %v", jobs[i].userID, jobs[i].goal.ID, result.err))
+			}
+		}
+	}
+	report.Latency = newLatencyReport(latencies)
+
+	// Every goal has its own reward, so the ledger needs one grant per user and reward
+	grants := make(map[string]int, len(pairs))
+	for _, call := range rewardClient.Calls {
+		if call.Method != "GrantReward" {
+			continue
+		}
+		report.Grants++
+		grants[call.Arguments.String(2)+"/"+call.Arguments.Get(3).(commonDomain.Reward).RewardID]++
+	}
+	for _, count := range grants {
+		report.DoubleGrants += count - 1
+	}
+	for _, pair := range pairs {
+		if grants[pair.userID+"/"+pair.goal.Reward.RewardID] == 0 && succeeded[pair.userID+"/"+pair.goal.ID] > 0 {
+			report.MissingGrants++
+		}
+	}
+
+	claimedOnce := report.Succeeded == len(pairs) && len(succeeded) == len(pairs)
+	report.Passed = report.Errors == 0 && claimedOnce &&
+		report.DoubleGrants == 0 && report.MissingGrants == 0 &&
+		report.Latency.P50 <= report.Budget.P50 && report.Latency.P99 <= report.Budget.P99
+
+	if err := report.write(cfg.ReportPath); err != nil {
+		t.Errorf("Failed to write load report: %v", err)
+	}
+	t.Logf("%d claims in %.0fms (%.0f/s): p50 %.1fms, p90 %.1fms, p99 %.1fms, max %.1fms; report: %s",
+		report.Claims, report.DurationMs, report.ClaimsPerSec,
+		report.Latency.P50, report.Latency.P90, report.Latency.P99, report.Latency.Max, cfg.ReportPath)
+
+	assert.Zero(t, report.Errors, "unexpected claim errors, e.g. %v", report.ErrorSamples)
+	assert.True(t, claimedOnce, "each goal must be claimed exactly once (%d succeeded for %d goals)", report.Succeeded, len(pairs))
+	assert.Equal(t, len(jobs)-len(pairs), report.AlreadyClaimed, "repeated claims must be rejected as already claimed")
+	assert.Zero(t, report.DoubleGrants, "rewards granted more than once")
+	assert.Zero(t, report.MissingGrants, "claimed goals without a grant")
+	assert.LessOrEqual(t, report.Latency.P50, report.Budget.P50, "p50 claim latency (ms) over budget")
+	assert.LessOrEqual(t, report.Latency.P99, report.Budget.P99, "p99 claim latency (ms) over budget")
+}
+
+// runClaims issues jobs through client with concurrency calls in flight and
+// returns their results indexed like jobs.
+func runClaims(ctx context.Context, client pb.ServiceClient, jobs []claimJob, concurrency int) []claimResult {
+	results := make([]claimResult, len(jobs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Go(func() {
+			for i := range next {
+				job := jobs[i]
+				start := time.Now()
+				_, err := client.ClaimGoalReward(userContext(ctx, job.userID), &pb.ClaimRewardRequest{
+					ChallengeId: job.goal.ChallengeID,
+					GoalId:      job.goal.ID,
+				})
+				results[i] = claimResult{latency: time.Since(start), code: status.Code(err), err: err}
+			}
+		})
+	}
+
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,256 @@
+//go:build load
+
+package load
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	serviceCommon "extend-challenge-service/pkg/common"
+	pb "extend-challenge-service/pkg/pb"
+
+	commonDomain "github.com/AccelByte/extend-challenge-common/pkg/domain"
+	commonRepo "github.com/AccelByte/extend-challenge-common/pkg/repository"
+)
+
+const (
+	// integrationDBEnv holds the PostgreSQL URL of the integration test
+	// database; load tests are skipped when it is unset.
+	integrationDBEnv = "INTEGRATION_DB"
+	migrationsPath   = "file://../../migrations"
+	configPath       = "testdata/challenges.json"
+	loadNamespace    = "load-namespace"
+
+	// seedBatchSize is the number of progress rows inserted per query.
+	seedBatchSize = 1000
+)
+
+// loadConfig is the size and latency budget of a load run, read from the
+// environment so CI can scale it without code changes.
+type loadConfig struct {
+	// Users is the number of users seeded with every goal completed (LOAD_USERS).
+	Users int
+	// Concurrency is the number of claims in flight at once (LOAD_CONCURRENCY).
+	Concurrency int
+	// ClaimsPerGoal is how many times each goal is claimed, back to back so the
+	// repeats race the first claim (LOAD_CLAIMS_PER_GOAL).
+	ClaimsPerGoal int
+	// P50Budget and P99Budget bound the latency of successful claims
+	// (LOAD_P50_BUDGET, LOAD_P99_BUDGET).
+	P50Budget time.Duration
+	P99Budget time.Duration
+	// ReportPath is where the JSON report is written (LOAD_REPORT).
+	ReportPath string
+}
+
+// loadConfigFromEnv reads the run settings, failing the test on invalid values.
+func loadConfigFromEnv(t *testing.T) loadConfig {
+	t.Helper()
+
+	return loadConfig{
+		Users:         envInt(t, "LOAD_USERS", 500),
+		Concurrency:   envInt(t, "LOAD_CONCURRENCY", 50),
+		ClaimsPerGoal: envInt(t, "LOAD_CLAIMS_PER_GOAL", 2),
+		P50Budget:     envDuration(t, "LOAD_P50_BUDGET", 50*time.Millisecond),
+		P99Budget:     envDuration(t, "LOAD_P99_BUDGET", 150*time.Millisecond),
+		ReportPath:    envString("LOAD_REPORT", "load-report.json"),
+	}
+}
+
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(t *testing.T, name string, fallback int) int {
+	t.Helper()
+
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		t.Fatalf("%s must be a positive integer, got %q", name, value)
+	}
+	return n
+}
+
+func envDuration(t *testing.T, name string, fallback time.Duration) time.Duration {
+	t.Helper()
+
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		t.Fatalf("%s must be a positive duration such as 150ms, got %q", name, value)
+	}
+	return d
+}
+
+// openLoadSchema creates a fresh schema in the integration database, applies
+// the migrations to it and returns a connection using it. The schema is
+// dropped when the test ends. The test is skipped without INTEGRATION_DB.
+func openLoadSchema(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dbURL := os.Getenv(integrationDBEnv)
+	if dbURL == "" {
+		t.Skipf("%s not set; load tests require PostgreSQL", integrationDBEnv)
+	}
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", integrationDBEnv, err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := fmt.Sprintf("load_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("Failed to create load schema: %v", err)
+	}
+
+	dsn, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", integrationDBEnv, err)
+	}
+	query := dsn.Query()
+	query.Set("search_path", schema)
+	dsn.RawQuery = query.Encode()
+
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		t.Fatalf("Failed to open load schema connection: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Logf("Warning: failed to drop load schema %s: %v", schema, err)
+		}
+	})
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create migrate driver: %v", err)
+	}
+	m, err := migrate.NewWithDatabaseInstance(migrationsPath, "testdb", driver)
+	if err != nil {
+		t.Fatalf("Failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("Failed to migrate load schema: %v", err)
+	}
+
+	return db
+}
+
+// seedCompletedGoals inserts a completed progress row for every goal of every user.
+func seedCompletedGoals(t *testing.T, repo commonRepo.GoalRepository, userIDs []string, goals []*commonDomain.Goal) {
+	t.Helper()
+
+	now := time.Now().UTC()
+	batch := make([]*commonDomain.UserGoalProgress, 0, seedBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := repo.BulkInsert(context.Background(), batch); err != nil {
+			t.Fatalf("Failed to seed completed goals: %v", err)
+		}
+		batch = batch[:0]
+	}
+
+	for _, userID := range userIDs {
+		for _, goal := range goals {
+			batch = append(batch, &commonDomain.UserGoalProgress{
+				UserID:      userID,
+				GoalID:      goal.ID,
+				ChallengeID: goal.ChallengeID,
+				Namespace:   loadNamespace,
+				Progress:    goal.Requirement.TargetValue,
+				Status:      commonDomain.GoalStatusCompleted,
+				IsActive:    true,
+				AssignedAt:  &now,
+				CompletedAt: &now,
+			})
+			if len(batch) == seedBatchSize {
+				flush()
+			}
+		}
+	}
+	flush()
+}
+
+// authInterceptor puts the user ID and namespace of the request metadata in
+// the context, as the JWT interceptor does after validating a token.
+func authInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userIDs := md.Get("user-id"); len(userIDs) > 0 {
+			ctx = context.WithValue(ctx, serviceCommon.ContextKeyUserID, userIDs[0])
+		}
+		if namespaces := md.Get("namespace"); len(namespaces) > 0 {
+			ctx = context.WithValue(ctx, serviceCommon.ContextKeyNamespace, namespaces[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
+// userContext returns a context authenticated as userID for the client.
+func userContext(ctx context.Context, userID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "user-id", userID, "namespace", loadNamespace)
+}
+
+// serveBufconn serves challengeServer in process over an in-memory listener
+// and returns a client connected to it. Both are stopped when the test ends.
+func serveBufconn(t *testing.T, challengeServer pb.ServiceServer) pb.ServiceClient {
+	t.Helper()
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(authInterceptor))
+	pb.RegisterServiceServer(grpcServer, challengeServer)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	//nolint:staticcheck // SA1019: grpc.Dial is deprecated but required for bufconn
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial in-memory server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		grpcServer.Stop()
+		_ = listener.Close()
+	})
+
+	return pb.NewServiceClient(conn)
+}
+
+// discardLogger is the slog logger given to the goal cache.
+var discardLogger = slog.New(slog.DiscardHandler)
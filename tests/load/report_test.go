@@ -0,0 +1,95 @@
+//go:build load
+
+package load
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// claimLoadReport is the JSON report of a claim load run, written to
+// LOAD_REPORT whether or not the run passes.
+type claimLoadReport struct {
+	StartedAt     time.Time `json:"startedAt"`
+	Users         int       `json:"users"`
+	GoalsPerUser  int       `json:"goalsPerUser"`
+	ClaimsPerGoal int       `json:"claimsPerGoal"`
+	Concurrency   int       `json:"concurrency"`
+
+	// Claims is the number of ClaimGoalReward calls issued; every goal should be
+	// claimed once and every repeat rejected as already claimed.
+	Claims         int      `json:"claims"`
+	Succeeded      int      `json:"succeeded"`
+	AlreadyClaimed int      `json:"alreadyClaimed"`
+	Errors         int      `json:"errors"`
+	ErrorSamples   []string `json:"errorSamples,omitempty"`
+	DurationMs     float64  `json:"durationMs"`
+	ClaimsPerSec   float64  `json:"claimsPerSecond"`
+
+	// Latency is measured on successful claims, the path the budget covers;
+	// AGS is replaced by the mock reward client, so it is excluded.
+	Latency latencyReport `json:"latencyMs"`
+	Budget  budgetReport  `json:"budgetMs"`
+
+	// Grants counts GrantReward calls in the mock's ledger. DoubleGrants are
+	// calls beyond the first for a user and reward; MissingGrants are claimed
+	// goals without one.
+	Grants        int `json:"grants"`
+	DoubleGrants  int `json:"doubleGrants"`
+	MissingGrants int `json:"missingGrants"`
+
+	Passed bool `json:"passed"`
+}
+
+type latencyReport struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+type budgetReport struct {
+	P50 float64 `json:"p50"`
+	P99 float64 `json:"p99"`
+}
+
+// newLatencyReport summarizes latencies, which it sorts in place.
+func newLatencyReport(latencies []time.Duration) latencyReport {
+	if len(latencies) == 0 {
+		return latencyReport{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencyReport{
+		P50: milliseconds(percentile(latencies, 50)),
+		P90: milliseconds(percentile(latencies, 90)),
+		P99: milliseconds(percentile(latencies, 99)),
+		Max: milliseconds(latencies[len(latencies)-1]),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// write saves the report as indented JSON, creating the directory if needed.
+func (r *claimLoadReport) write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644) // #nosec G306 - report artifact, not secret
+}